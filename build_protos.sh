@@ -1 +1,2 @@
-protoc --go_out=. ./internal/wal/wal.proto
\ No newline at end of file
+protoc --go_out=. ./internal/wal/wal.proto
+protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ./dicepb/dice.proto
@@ -0,0 +1,108 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Command dicedb-benchmark measures the throughput and the latency of a running DiceDB through one of its
+// frontends, with flags following the ones of redis-benchmark:
+//
+//	dicedb-benchmark -p 7379 -c 50 -n 100000 -P 16 -d 64 -t set,get
+//	dicedb-benchmark --protocol http -p 8082 --mix set:1,get:9 -r 10000
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/bench"
+)
+
+// defaultPorts are the default ports of the frontends
+var defaultPorts = map[string]int{
+	bench.ProtocolRESP:      7379,
+	bench.ProtocolHTTP:      8082,
+	bench.ProtocolWebSocket: 8379,
+}
+
+func main() {
+	host := flag.String("h", "127.0.0.1", "host of the server")
+	port := flag.Int("p", 0, "port of the frontend, by default the default port of the protocol")
+	protocol := flag.String("protocol", bench.ProtocolRESP, "frontend benchmarked: resp, http or websocket")
+	clients := flag.Int("c", 50, "number of parallel connections")
+	requests := flag.Int("n", 100000, "total number of requests")
+	pipeline := flag.Int("P", 1, "number of requests pipelined by a connection, ignored over http")
+	dataSize := flag.Int("d", 3, "size in bytes of the values written")
+	keySpace := flag.Int("r", 0, "number of random keys used, 0 uses a single key")
+	tests := flag.String("t", "ping,set,get,incr,lpush,rpop,sadd,hset,zadd",
+		"comma separated list of the commands benchmarked one after the other, among "+strings.ToLower(strings.Join(bench.Commands(), ",")))
+	mix := flag.String("mix", "", "weighted mix of commands benchmarked together, e.g. set:1,get:9, instead of -t")
+	seed := flag.Int64("seed", 1, "seed of the choice of the keys and of the commands")
+	flag.Parse()
+
+	if *port == 0 {
+		*port = defaultPorts[*protocol]
+	}
+
+	var mixes [][]bench.Op
+	if *mix != "" {
+		ops, err := bench.ParseMix(*mix)
+		if err != nil {
+			fail(err)
+		}
+		mixes = append(mixes, ops)
+	} else {
+		for _, name := range strings.Split(*tests, ",") {
+			ops, err := bench.ParseMix(name)
+			if err != nil {
+				fail(err)
+			}
+			mixes = append(mixes, ops)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for _, ops := range mixes {
+		report, err := bench.Run(ctx, bench.Options{
+			Protocol: *protocol,
+			Addr:     net.JoinHostPort(*host, strconv.Itoa(*port)),
+			Clients:  *clients,
+			Requests: *requests,
+			Pipeline: *pipeline,
+			DataSize: *dataSize,
+			KeySpace: *keySpace,
+			Mix:      ops,
+			Seed:     *seed,
+		})
+		if report != nil {
+			report.Print(os.Stdout)
+			fmt.Println()
+		}
+		if err != nil {
+			fail(err)
+		}
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "dicedb-benchmark:", err)
+	os.Exit(1)
+}
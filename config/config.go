@@ -22,6 +22,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	"github.com/dicedb/dice/internal/server/utils"
@@ -38,8 +39,9 @@ const (
 	EvictAllKeysLFU    = "allkeys-lfu"
 	EvictBatchKeysLRU  = "batch_keys_lru"
 
-	DefaultKeysLimit     int     = 200000000
-	DefaultEvictionRatio float64 = 0.1
+	DefaultKeysLimit          int     = 200000000
+	DefaultEvictionRatio      float64 = 0.1
+	DefaultLRUClockResolution int64   = 1000
 
 	defaultConfigTemplate = `# Configuration file for Dicedb
 
@@ -52,16 +54,37 @@ async_server.port = 7379
 async_server.keepalive = 300
 async_server.timeout = 300
 async_server.max_conn = 0
+async_server.unixsocket = ""
+async_server.unixsocketperm = "0700"
+
+# TLS Configuration
+tls.port = 0
+tls.cert_file = ""
+tls.key_file = ""
+tls.ca_file = ""
+tls.auth_clients = "no"
 
 # HTTP Configuration
 http.enabled = false
+http.addr = "0.0.0.0"
 http.port = 8082
 
 # WebSocket Configuration
 websocket.enabled = false
+websocket.addr = "0.0.0.0"
 websocket.port = 8379
 websocket.max_write_response_retries = 3
 websocket.write_response_timeout = 10s
+websocket.fanout_workers = 16
+websocket.push_queue_size = 256
+websocket.allowed_commands = ""
+websocket.user_allowed_commands = ""
+websocket.denied_categories = ""
+
+# gRPC Configuration
+grpc.enabled = false
+grpc.addr = "0.0.0.0"
+grpc.port = 50051
 
 # Performance Configuration
 performance.watch_chan_buf_size = 20000
@@ -73,6 +96,11 @@ performance.adhoc_req_chan_buf_size = 20
 performance.enable_profiling = false
 performance.enable_watch = false
 performance.num_shards = -1
+performance.txn_lock_timeout = 10s
+performance.command_timeout = 0
+performance.max_shard_queue_depth = 0
+performance.max_frontend_inflight = 0
+performance.shard_workers = 0
 
 # Memory Configuration
 memory.max_memory = 0
@@ -80,6 +108,13 @@ memory.eviction_policy = "allkeys-lfu"
 memory.eviction_ratio = 0.9
 memory.keys_limit = 200000000
 memory.lfu_log_factor = 10
+memory.maxmemory_samples = 5
+memory.lru_clock_resolution = 1000
+memory.lazyfree_lazy_user_flush = false
+memory.key_prefix_interning = false
+memory.active_defrag = false
+memory.active_defrag_threshold = 50
+memory.active_defrag_cycle = 5ms
 
 # Persistence Configuration
 persistence.enabled = false
@@ -90,13 +125,166 @@ persistence.wal-dir = "./"
 persistence.restore-wal = false
 persistence.wal-engine = "aof"
 
+# Journal Configuration
+journal.enabled = false
+journal.dir = "/tmp/dicedb/journal"
+
+# Disk Tier Configuration
+disk_tier.enabled = false
+disk_tier.dir = "/tmp/dicedb/tier"
+disk_tier.hot_keys = 1000000
+
+# Bridge Configuration
+bridge.enabled = false
+bridge.addr = "localhost:6379"
+bridge.db = 0
+bridge.patterns = "*"
+bridge.mode = "notifications"
+bridge.read_after_timeout = 100ms
+
+# Warm-up Configuration
+warm.from = ""
+warm.db = 0
+warm.patterns = "*"
+
+# Cache Configuration
+cache.enabled = false
+cache.patterns = "*"
+cache.loader_url = ""
+cache.writer_url = ""
+cache.timeout = 2s
+cache.ttl = 0
+
+# Namespaces Configuration
+namespaces.enabled = false
+namespaces.tenants = ""
+namespaces.max_keys = 0
+namespaces.max_memory = 0
+namespaces.max_ops_per_sec = 0
+
+# Trash Configuration
+trash.enabled = false
+trash.retention = 300s
+trash.max_keys = 10000
+
+# Backup Configuration
+backup.enabled = false
+backup.schedule = "0 * * * *"
+backup.dir = "./backups"
+backup.keep_last = 24
+backup.keep_daily = 7
+backup.keep_weekly = 4
+backup.target = "dir"
+backup.s3_endpoint = ""
+backup.s3_region = "us-east-1"
+backup.s3_bucket = ""
+backup.s3_prefix = ""
+backup.s3_access_key = ""
+backup.s3_secret_key = ""
+backup.s3_path_style = false
+backup.s3_sse = ""
+backup.s3_kms_key_id = ""
+backup.s3_part_size_mb = 16
+backup.archive_wal = false
+backup.restore_from = ""
+backup.recovery_mode = "strict"
+
+# History Configuration
+history.enabled = false
+history.patterns = "*"
+history.retention = 600s
+history.max_versions = 100
+
+# Hot Keys Configuration
+hotkeys.enabled = false
+hotkeys.sample_rate = 0.01
+hotkeys.tracked_keys = 128
+hotkeys.replicate = false
+hotkeys.replicate_min_ops = 10000
+
+# Big Keys Configuration
+bigkeys.interval = 0
+bigkeys.top_keys = 10
+bigkeys.batch_size = 256
+
+# Upgrade Configuration
+upgrade.ready_timeout = 30s
+upgrade.drain_timeout = 30s
+
+# Sentinel Configuration
+sentinel.enabled = false
+sentinel.addr = "0.0.0.0"
+sentinel.port = 26379
+sentinel.name = "dicedb"
+sentinel.leader = "localhost:6379"
+sentinel.down_after = 5s
+sentinel.check_interval = 1s
+
+# Connectors Configuration
+connectors.enabled = false
+connectors.timeout = 5s
+connectors.retries = 5
+connectors.retry_backoff = 500ms
+connectors.max_failures = 10
+
+# Idempotency Configuration
+idempotency.enabled = false
+idempotency.window = 60s
+idempotency.max_requests = 100000
+
+# Modules Configuration
+modules.load = ""
+
 # Logging Configuration
 logging.log_level = "info"
 logging.log_dir = "/tmp/dicedb"
 
+# Slowlog Configuration
+slowlog.log_slower_than = 10000
+slowlog.max_len = 128
+
+# Latency Monitor Configuration
+latency.monitor_threshold = 0
+
+# Tracing Configuration
+tracing.enabled = false
+tracing.endpoint = "localhost:4318"
+tracing.insecure = true
+tracing.sample_ratio = 1.0
+tracing.service_name = "dicedb"
+
+# Metrics Configuration
+metrics.enabled = false
+metrics.addr = "0.0.0.0"
+metrics.port = 9379
+metrics.pprof_enabled = false
+metrics.block_profile_rate = 0
+metrics.mutex_profile_fraction = 0
+
 # Authentication Configuration
 auth.username = "dice"
 auth.password = ""
+# The password can instead be read from a file or an environment variable
+# auth.password_file = "/run/secrets/dicedb_password"
+# auth.password_env = "DICEDB_PASSWORD"
+
+# Security Configuration
+security.protected_mode = false
+security.disabled_commands = ""
+security.renamed_commands = ""
+
+# Access Log Configuration
+access_log.enabled = false
+access_log.sample_rate = 1.0
+access_log.log_args = true
+
+# Audit Configuration
+audit.enabled = false
+audit.categories = "admin,write"
+audit.output = "file"
+audit.file_path = "/tmp/dicedb/audit.log"
+audit.max_file_size_mb = 100
+audit.max_backups = 5
 
 # Network Configuration
 network.io_buffer_length = 512
@@ -108,7 +296,7 @@ Enabled = "true"
 WalMode = "buffered"
 WriteMode = "default"
 BufferSizeMB = 1
-RotationMode = "segemnt-size"
+RotationMode = "segment-size"
 MaxSegmentSizeMB = 16
 MaxSegmentRotationTime = 60s
 BufferSyncInterval = 200ms
@@ -128,19 +316,45 @@ type Config struct {
 	InstanceID  string      `config:"instance_id"`
 	Auth        auth        `config:"auth"`
 	RespServer  respServer  `config:"async_server"`
+	TLS         tlsConfig   `config:"tls"`
 	HTTP        http        `config:"http"`
 	WebSocket   websocket   `config:"websocket"`
+	GRPC        grpc        `config:"grpc"`
 	Performance performance `config:"performance"`
 	Memory      memory      `config:"memory"`
 	Persistence persistence `config:"persistence"`
+	Journal     journal     `config:"journal"`
+	DiskTier    diskTier    `config:"disk_tier"`
+	Bridge      bridge      `config:"bridge"`
+	Warm        warm        `config:"warm"`
+	Cache       cache       `config:"cache"`
+	Namespaces  namespaces  `config:"namespaces"`
+	Trash       trash       `config:"trash"`
+	History     history     `config:"history"`
+	Backup      backup      `config:"backup"`
+	HotKeys     hotKeys     `config:"hotkeys"`
+	BigKeys     bigKeys     `config:"bigkeys"`
+	Upgrade     upgrade     `config:"upgrade"`
+	Sentinel    sentinel    `config:"sentinel"`
+	Connectors  connectors  `config:"connectors"`
+	Idempotency idempotency `config:"idempotency"`
+	Modules     modules     `config:"modules"`
 	Logging     logging     `config:"logging"`
+	Slowlog     slowlog     `config:"slowlog"`
+	Latency     latency     `config:"latency"`
+	Tracing     tracing     `config:"tracing"`
+	Metrics     metrics     `config:"metrics"`
+	Security    security    `config:"security"`
+	Audit       audit       `config:"audit"`
+	AccessLog   accessLog   `config:"access_log"`
 	Network     network     `config:"network"`
 	WAL         WALConfig   `config:"WAL"`
 }
 
 type auth struct {
 	UserName string `config:"username" default:"dice"`
-	Password string `config:"password"`
+	// Password of the default user, also read from auth.password_file or auth.password_env
+	Password string `config:"password" secret:"true" hot:"true"`
 }
 
 type respServer struct {
@@ -149,18 +363,55 @@ type respServer struct {
 	KeepAlive int32  `config:"keepalive" default:"300"`
 	Timeout   int32  `config:"timeout" default:"300"`
 	MaxConn   int32  `config:"max_conn" default:"0"`
+	// Path of the Unix domain socket the RESP server also listens on, empty disables the listener
+	UnixSocket string `config:"unixsocket"`
+	// Octal file mode applied to the Unix domain socket, e.g. "0770" to allow a group of local users
+	UnixSocketPerm string `config:"unixsocketperm" default:"0700"`
+}
+
+type tlsConfig struct {
+	// Port on which the RESP server accepts TLS connections, 0 disables the TLS listener
+	Port int `config:"port" default:"0" validate:"number,gte=0,lte=65535"`
+	// PEM encoded certificate presented by the server
+	CertFile string `config:"cert_file" hot:"true"`
+	// PEM encoded private key matching CertFile
+	KeyFile string `config:"key_file" hot:"true"`
+	// PEM encoded CA bundle used to verify client certificates
+	CAFile string `config:"ca_file" hot:"true"`
+	// Client certificate verification mode: 'no', 'optional' (verify if presented) or 'yes' (mutual TLS)
+	AuthClients string `config:"auth_clients" default:"no" validate:"oneof=no optional yes" hot:"true"`
 }
 
 type http struct {
-	Enabled bool `config:"enabled" default:"true"`
-	Port    int  `config:"port" default:"8082" validate:"number,gte=0,lte=65535"`
+	Enabled bool   `config:"enabled" default:"true"`
+	Addr    string `config:"addr" default:"0.0.0.0" validate:"ip"`
+	Port    int    `config:"port" default:"8082" validate:"number,gte=0,lte=65535"`
+}
+
+type grpc struct {
+	// Whether the Dice gRPC service, executing and watching commands, is served
+	Enabled bool   `config:"enabled" default:"false"`
+	Addr    string `config:"addr" default:"0.0.0.0" validate:"ip"`
+	Port    int    `config:"port" default:"50051" validate:"number,gte=0,lte=65535"`
 }
 
 type websocket struct {
 	Enabled                 bool          `config:"enabled" default:"true"`
+	Addr                    string        `config:"addr" default:"0.0.0.0" validate:"ip"`
 	Port                    int           `config:"port" default:"8379" validate:"number,gte=0,lte=65535"`
-	MaxWriteResponseRetries int           `config:"max_write_response_retries" default:"3" validate:"min=0"`
-	WriteResponseTimeout    time.Duration `config:"write_response_timeout" default:"10s"`
+	MaxWriteResponseRetries int           `config:"max_write_response_retries" default:"3" validate:"min=0" hot:"true"`
+	WriteResponseTimeout    time.Duration `config:"write_response_timeout" default:"10s" hot:"true"`
+	// Number of workers writing the watch pushes, shared by every connection
+	FanoutWorkers int `config:"fanout_workers" default:"16" validate:"min=1"`
+	// Number of watch pushes queued for a connection before new ones are dropped
+	PushQueueSize int `config:"push_queue_size" default:"256" validate:"min=1"`
+	// Comma separated list of commands accepted over WebSocket, empty allows every command
+	AllowedCommands []string `config:"allowed_commands"`
+	// Comma separated list of USER:COMMAND|COMMAND... entries, the commands accepted over WebSocket from the
+	// connections authenticated as USER with AUTH, in place of allowed_commands
+	UserAllowedCommands []string `config:"user_allowed_commands"`
+	// Comma separated list of command categories rejected over WebSocket: 'admin', 'write' and 'read'
+	DeniedCategories []string `config:"denied_categories"`
 }
 
 type performance struct {
@@ -173,14 +424,57 @@ type performance struct {
 	EnableProfiling        bool          `config:"profiling" default:"false"`
 	EnableWatch            bool          `config:"enable_watch" default:"false"`
 	NumShards              int           `config:"num_shards" default:"-1" validate:"oneof=-1|min=1,lte=128"`
+
+	// TxnLockTimeout is the time after which a shard locked by a transaction that was not released unlocks itself
+	TxnLockTimeout time.Duration `config:"txn_lock_timeout" default:"10s"`
+	// CommandTimeout is the time in milliseconds after which a command still executed by a shard is cancelled, 0 disables it
+	CommandTimeout int64 `config:"command_timeout" default:"0" validate:"min=0"`
+	// MaxShardQueueDepth is the number of operations queued to a shard from which new commands are shed, 0 disables it
+	MaxShardQueueDepth int `config:"max_shard_queue_depth" default:"0" validate:"min=0,lte=1000" hot:"true"`
+	// MaxFrontendInflight is the number of commands a frontend executes at once from which new commands are shed, 0 disables it
+	MaxFrontendInflight int64 `config:"max_frontend_inflight" default:"0" validate:"min=0" hot:"true"`
+	// ShardWorkers is the number of workers the shards are scheduled onto, -1 for the number of CPUs. With 0 every
+	// shard runs its own goroutine
+	ShardWorkers int `config:"shard_workers" default:"0" validate:"min=-1"`
+	// SlowLaneBudget is the number of operations a shard executes ahead of an expensive command such as KEYS
+	// before executing it, 0 executes every command in the order it is received
+	SlowLaneBudget int `config:"slow_lane_budget" default:"0" validate:"min=0" hot:"true"`
+	// ShardCrashWindow is the time the server is reported degraded once a shard crashed and was restarted, by
+	// INFO and by /health replying 503, 0 reporting the crashes in the counters of INFO only
+	ShardCrashWindow time.Duration `config:"shard_crash_window" default:"30s" validate:"min=0" hot:"true"`
 }
 
 type memory struct {
-	MaxMemory      int64   `config:"max_memory" default:"0" validate:"min=0"`
-	EvictionPolicy string  `config:"eviction_policy" default:"allkeys-lfu" validate:"oneof=simple-first allkeys-random allkeys-lru allkeys-lfu"`
+	MaxMemory      int64   `config:"max_memory" default:"0" validate:"min=0" hot:"true"`
+	EvictionPolicy string  `config:"eviction_policy" default:"allkeys-lfu" validate:"oneof=simple-first allkeys-random allkeys-lru allkeys-lfu" hot:"true"`
 	EvictionRatio  float64 `config:"eviction_ratio" default:"0.9" validate:"min=0,lte=1"`
 	KeysLimit      int     `config:"keys_limit" default:"200000000" validate:"min=10"`
-	LFULogFactor   int     `config:"lfu_log_factor" default:"10" validate:"min=0"`
+	LFULogFactor   int     `config:"lfu_log_factor" default:"10" validate:"min=0" hot:"true"`
+
+	// MaxMemorySamples is the number of keys sampled for every key evicted, the candidates being kept in an eviction
+	// pool across the samples. More samples evict closer to an exact LRU at the cost of more CPU, 0 evicts the exact
+	// least recently used keys by scanning the whole keyspace
+	MaxMemorySamples int `config:"maxmemory_samples" default:"5" validate:"min=0,lte=64"`
+	// LRUClockResolution is the duration of a tick of the clock stamping the accesses to the keys, in milliseconds.
+	// The clock wraps around after 2^24 ticks, so that a finer resolution tells apart keys accessed closer in time
+	// but confuses keys left idle for longer
+	LRUClockResolution int64 `config:"lru_clock_resolution" default:"1000" validate:"min=1,lte=60000"`
+
+	// LazyFreeLazyUserFlush makes FLUSHDB and FLUSHALL without a SYNC or ASYNC option release the keys in the background
+	LazyFreeLazyUserFlush bool `config:"lazyfree_lazy_user_flush" default:"false" hot:"true"`
+
+	// KeyPrefixInterning stores the prefix of the keys, up to their last ':', once for all the keys sharing it
+	KeyPrefixInterning bool `config:"key_prefix_interning" default:"false"`
+
+	// ActiveDefrag makes the shards rebuild the tables of the keys and of their expiries once they hold memory for
+	// many more entries than they do, and drop the expiries scheduled for keys deleted or whose TTL changed
+	ActiveDefrag bool `config:"active_defrag" default:"false" hot:"true"`
+	// ActiveDefragThreshold is the percentage of the capacity of a table not used by its entries from which the
+	// table is rebuilt
+	ActiveDefragThreshold int `config:"active_defrag_threshold" default:"50" validate:"min=1,lte=99" hot:"true"`
+	// ActiveDefragCycle is the time a shard spends defragmenting at most every cron run, out of
+	// performance.shard_cron_frequency
+	ActiveDefragCycle time.Duration `config:"active_defrag_cycle" default:"5ms" validate:"min=100us" hot:"true"`
 }
 
 type persistence struct {
@@ -203,7 +497,7 @@ type WALConfig struct {
 	// Size of the write buffer in megabytes
 	BufferSizeMB int `config:"buffer_size_mb" default:"1" validate:"min=1"`
 	// How WAL rotation is triggered: 'segment-size' (based on file size) or 'time' (based on duration)
-	RotationMode string `config:"rotation_mode" default:"segemnt-size" validate:"oneof=segment-size time"`
+	RotationMode string `config:"rotation_mode" default:"segment-size" validate:"oneof=segment-size time"`
 	// Maximum size of a WAL segment file in megabytes before rotation
 	MaxSegmentSizeMB int `config:"max_segment_size_mb" default:"16" validate:"min=1"`
 	// Time interval in seconds after which WAL segment is rotated when using time-based rotation
@@ -221,17 +515,323 @@ type WALConfig struct {
 }
 
 type logging struct {
-	LogLevel string `config:"log_level" default:"info" validate:"oneof=debug info warn error"`
+	LogLevel string `config:"log_level" default:"info" validate:"oneof=debug info warn error" hot:"true"`
 	LogDir   string `config:"log_dir" default:"/tmp/dicedb" validate:"dirpath"`
 }
 
+type slowlog struct {
+	// Execution time in microseconds above which a command is recorded in the slow log,
+	// 0 records every command and a negative value disables the slow log
+	LogSlowerThan int64 `config:"log_slower_than" default:"10000" hot:"true"`
+	// Maximum number of entries kept in the slow log of every shard
+	MaxLen int `config:"max_len" default:"128" validate:"min=0" hot:"true"`
+}
+
+type journal struct {
+	// Whether the commands applied to every shard are recorded, with their time and client, in one journal file per shard
+	Enabled bool `config:"enabled" default:"false"`
+	// Directory of the journal files
+	Dir string `config:"dir" default:"/tmp/dicedb/journal"`
+}
+
+type diskTier struct {
+	// Whether the values of the keys accessed the least recently are spilled to disk, so that the dataset can outgrow the memory
+	Enabled bool `config:"enabled" default:"false"`
+	// Directory of the disk tier files, one per shard, truncated on start as the tier does not persist the dataset
+	Dir string `config:"dir" default:"/tmp/dicedb/tier"`
+	// Number of values every shard keeps in memory, the others are spilled to disk and loaded back on access
+	HotKeys int `config:"hot_keys" default:"1000000" validate:"min=1"`
+}
+
+type bridge struct {
+	// Whether the keys of an upstream Redis are mirrored, kept up to date from its keyspace notifications
+	Enabled bool `config:"enabled" default:"false"`
+	// Host and port of the upstream Redis, which must publish keyspace notifications, e.g. notify-keyspace-events KA
+	Addr string `config:"addr" default:"localhost:6379"`
+	// Password of the upstream Redis, also read from bridge.password_file or bridge.password_env
+	Password string `config:"password" secret:"true"`
+	// Database of the upstream Redis the keys are mirrored from
+	DB int `config:"db" default:"0" validate:"min=0"`
+	// Comma separated list of the glob-style patterns of the keys mirrored
+	Patterns []string `config:"patterns" default:"*"`
+	// How the keys are kept up to date: "notifications" from the keyspace notifications of the upstream, or
+	// "replication" following the upstream as one of its replicas, with PSYNC
+	Mode string `config:"mode" default:"notifications" validate:"oneof=notifications replication"`
+	// Time a read of a client requiring a replication offset with READAFTER waits for the offset to be applied,
+	// before the client is redirected to the upstream
+	ReadAfterTimeout time.Duration `config:"read_after_timeout" default:"100ms" validate:"min=0" hot:"true"`
+}
+
+type warm struct {
+	// Host and port of the Redis or DiceDB the keys are copied from on startup, before the clients are
+	// accepted, empty starts with an empty store
+	From string `config:"from"`
+	// Password of the source, also read from warm.password_file or warm.password_env
+	Password string `config:"password" secret:"true"`
+	// Database of the source the keys are copied from
+	DB int `config:"db" default:"0" validate:"min=0"`
+	// Comma separated list of the glob-style patterns of the keys copied
+	Patterns []string `config:"patterns" default:"*"`
+}
+
+type cache struct {
+	// Whether the keys matching the patterns are loaded from an origin on a miss of GET, and their writes
+	// propagated to the origin in the background
+	Enabled bool `config:"enabled" default:"false"`
+	// Comma separated list of the glob-style patterns of the keys cached
+	Patterns []string `config:"patterns" default:"*"`
+	// Base URL the keys are loaded from, with GET <url>/<key>, empty loads no key
+	LoaderURL string `config:"loader_url"`
+	// Base URL the writes are propagated to, with PUT and DELETE <url>/<key>, empty propagates no write
+	WriterURL string `config:"writer_url"`
+	// Time after which a load or a write to the origin is given up
+	Timeout time.Duration `config:"timeout" default:"2s" validate:"min=1ms"`
+	// Time to live in seconds of the keys loaded when the origin sets no Cache-Control max-age, 0 for no expiry
+	TTL int `config:"ttl" default:"0" validate:"min=0"`
+}
+
+type namespaces struct {
+	// Whether the clients can be confined to a namespace, a partition of the keyspace with its own quotas
+	Enabled bool `config:"enabled" default:"false"`
+	// Comma separated list of NAME:PASSWORD pairs, a client authenticated as NAME is confined to the namespace NAME
+	Tenants []string `config:"tenants" secret:"true"`
+	// Number of keys of a namespace once which its writes are refused, 0 for no limit
+	MaxKeys int64 `config:"max_keys" default:"0" validate:"min=0" hot:"true"`
+	// Estimated size in bytes of the keys of a namespace once which its writes are refused, 0 for no limit
+	MaxMemory int64 `config:"max_memory" default:"0" validate:"min=0" hot:"true"`
+	// Number of commands a namespace executes per second, the ones beyond are refused, 0 for no limit
+	MaxOpsPerSec int64 `config:"max_ops_per_sec" default:"0" validate:"min=0" hot:"true"`
+}
+
+type trash struct {
+	// Whether DEL moves the keys to the trash of their shard, from which UNDELETE restores them
+	Enabled bool `config:"enabled" default:"false" hot:"true"`
+	// Time the deleted keys are kept in the trash before they are purged
+	Retention time.Duration `config:"retention" default:"300s" validate:"min=1s" hot:"true"`
+	// Number of deleted keys kept in the trash of a shard, the oldest ones being purged first
+	MaxKeys int `config:"max_keys" default:"10000" validate:"min=1" hot:"true"`
+}
+
+type backup struct {
+	// Whether backups of the keyspace are taken on schedule, as JSON Lines of EXPORT
+	Enabled bool `config:"enabled" default:"false"`
+	// Cron-like schedule of the backups in UTC, "minute hour day-of-month month day-of-week", or one of
+	// @hourly, @daily, @weekly and @monthly
+	Schedule string `config:"schedule" default:"0 * * * *"`
+	// Directory the backups are written to
+	Dir string `config:"dir" default:"./backups"`
+	// Number of the last backups kept
+	KeepLast int `config:"keep_last" default:"24" validate:"min=1" hot:"true"`
+	// Number of the last days whose last backup is kept
+	KeepDaily int `config:"keep_daily" default:"7" validate:"min=0" hot:"true"`
+	// Number of the last weeks whose last backup is kept
+	KeepWeekly int `config:"keep_weekly" default:"4" validate:"min=0" hot:"true"`
+	// Where the backups are stored: "dir" for backup.dir, or "s3" for the S3-compatible bucket backup.s3_bucket
+	Target string `config:"target" default:"dir" validate:"oneof=dir s3"`
+	// URL of the S3-compatible object store, e.g. http://localhost:9000 for MinIO, empty for AWS S3
+	S3Endpoint string `config:"s3_endpoint"`
+	// Region of the bucket, which the requests are signed for
+	S3Region string `config:"s3_region" default:"us-east-1"`
+	// Bucket the backups are uploaded to
+	S3Bucket string `config:"s3_bucket"`
+	// Prefix of the names of the objects of the backups, e.g. "dicedb/"
+	S3Prefix string `config:"s3_prefix"`
+	// Access key signing the requests, the requests are anonymous when empty
+	S3AccessKey string `config:"s3_access_key"`
+	// Secret key signing the requests, also read from backup.s3_secret_key_file or backup.s3_secret_key_env
+	S3SecretKey string `config:"s3_secret_key" secret:"true"`
+	// Whether the bucket is addressed in the path of the URLs rather than in the host name, as MinIO requires
+	S3PathStyle bool `config:"s3_path_style" default:"false"`
+	// Server-side encryption of the objects: "AES256", "aws:kms", or empty for none
+	S3SSE string `config:"s3_sse" validate:"omitempty,oneof=AES256 aws:kms"`
+	// KMS key encrypting the objects with aws:kms, the default key of the bucket when empty
+	S3KMSKeyID string `config:"s3_kms_key_id"`
+	// Size of the parts of the backups uploaded in several parts, in megabytes
+	S3PartSizeMB int `config:"s3_part_size_mb" default:"16" validate:"min=5"`
+	// Whether the WAL segments are uploaded to the target once rotated
+	ArchiveWAL bool `config:"archive_wal" default:"false"`
+	// Backup the keys are restored from on startup, before the clients are accepted: an s3://bucket/key URL, an
+	// http:// or https:// URL, or the path of a file. Empty starts without restoring any.
+	RestoreFrom string `config:"restore_from"`
+	// How the corrupt records of the backup restored are handled: 'strict' (fail), 'truncate' (restore the keys up to
+	// the first corrupt record) or 'ignore' (skip the corrupt records)
+	RecoveryMode string `config:"recovery_mode" default:"strict" validate:"oneof=strict truncate ignore"`
+	// Comma separated list of the glob-style patterns of the keys restored from the backup, the other keys are skipped
+	RestorePatterns []string `config:"restore_patterns" default:"*"`
+	// Whether the clients are accepted while the backup is restored in the background once its keys are indexed,
+	// the commands on the keys of the backup not restored yet failing with LOADING until they are
+	RestoreLazy bool `config:"restore_lazy" default:"false"`
+}
+
+type history struct {
+	// Whether the previous values of the keys matching the patterns are kept, as read by GETAT and HISTORY
+	Enabled bool `config:"enabled" default:"false" hot:"true"`
+	// Comma separated list of the glob-style patterns of the keys whose previous values are kept
+	Patterns []string `config:"patterns" default:"*" hot:"true"`
+	// Time the values are kept once replaced, GETAT reads the values of the keys back to then
+	Retention time.Duration `config:"retention" default:"600s" validate:"min=1s" hot:"true"`
+	// Number of values kept per key, the oldest ones being dropped first
+	MaxVersions int `config:"max_versions" default:"100" validate:"min=1" hot:"true"`
+}
+
+type hotKeys struct {
+	// Whether the accesses to the keys are sampled to find the most accessed ones, as reported by HOTKEYS
+	Enabled bool `config:"enabled" default:"false" hot:"true"`
+	// Fraction of the accesses to the keys that are sampled
+	SampleRate float64 `config:"sample_rate" default:"0.01" validate:"gt=0,lte=1" hot:"true"`
+	// Number of keys whose access rate is estimated, the least accessed ones are replaced by the new ones
+	TrackedKeys int `config:"tracked_keys" default:"128" validate:"min=1,lte=10000" hot:"true"`
+	// Whether GET reads a copy of the hot keys held by the io-threads rather than the shard owning them
+	Replicate bool `config:"replicate" default:"false" hot:"true"`
+	// Estimated number of accesses per second from which a key is hot and copied
+	ReplicateMinOps int64 `config:"replicate_min_ops" default:"10000" validate:"min=1" hot:"true"`
+}
+
+type bigKeys struct {
+	// Time in seconds between two analyses of the largest keys, 0 to only analyze them on BIGKEYS SCAN
+	Interval int `config:"interval" default:"0" validate:"min=0"`
+	// Number of keys of every type reported by the analyses, the largest by size and by number of elements
+	TopKeys int `config:"top_keys" default:"10" validate:"min=1,lte=1000" hot:"true"`
+	// Number of keys a shard measures at once, the shards executing the other commands between two batches
+	BatchSize int `config:"batch_size" default:"256" validate:"min=1,lte=100000" hot:"true"`
+}
+
+type upgrade struct {
+	// Time the process started on SIGUSR2 is given to serve on the listeners handed over, before it is killed
+	ReadyTimeout time.Duration `config:"ready_timeout" default:"30s" validate:"min=1s" hot:"true"`
+	// Time the old process serves its clients once the listeners are handed over, before it exits
+	DrainTimeout time.Duration `config:"drain_timeout" default:"30s" validate:"min=1ms" hot:"true"`
+}
+
+type sentinel struct {
+	// Whether the process runs as a sentinel, monitoring a leader and its replicas in place of serving a dataset
+	Enabled bool `config:"enabled" default:"false"`
+	// Host and port the clients ask for the address of the leader on
+	Addr string `config:"addr" default:"0.0.0.0" validate:"ipv4"`
+	Port int    `config:"port" default:"26379" validate:"number,gte=0,lte=65535"`
+	// Name under which the clients ask for the address of the leader
+	Name string `config:"name" default:"dicedb" validate:"required"`
+	// Host and port of the leader when the sentinel starts, its replicas are discovered from its INFO replication
+	Leader string `config:"leader" default:"localhost:6379" validate:"required"`
+	// Password of the leader and its replicas, also read from sentinel.password_file or sentinel.password_env
+	Password string `config:"password" secret:"true"`
+	// Time after which a node not replying to PING is down, the leader being failed over once it is
+	DownAfter time.Duration `config:"down_after" default:"5s" validate:"min=1ms"`
+	// Interval at which the nodes are checked
+	CheckInterval time.Duration `config:"check_interval" default:"1s" validate:"min=1ms"`
+}
+
+type connectors struct {
+	// Whether SINK can create connectors, publishing the updates of watched commands to Kafka, NATS or webhooks
+	Enabled bool `config:"enabled" default:"false"`
+	// Time after which an attempt to publish an update to a sink is given up on
+	Timeout time.Duration `config:"timeout" default:"5s" validate:"min=1ms"`
+	// Number of times the publication of an update is attempted again after failing
+	Retries int `config:"retries" default:"5" validate:"min=0"`
+	// Wait before attempting again to publish an update, doubled after every attempt up to 30s
+	RetryBackoff time.Duration `config:"retry_backoff" default:"500ms" validate:"min=1ms"`
+	// Number of updates failing in a row after which a connector is disabled, 0 never disables them
+	MaxFailures int `config:"max_failures" default:"10" validate:"min=0"`
+}
+
+type idempotency struct {
+	// Whether the writes of the HTTP and WebSocket clients carrying a request id are executed once, their
+	// retries being replied the response of the first execution
+	Enabled bool `config:"enabled" default:"false" hot:"true"`
+	// Time the response of a request is kept for its retries
+	Window time.Duration `config:"window" default:"60s" validate:"min=1ms" hot:"true"`
+	// Number of responses kept, the oldest ones being dropped beyond
+	MaxRequests int `config:"max_requests" default:"100000" validate:"min=1" hot:"true"`
+}
+
+type modules struct {
+	// Comma separated list of the paths of the Go plugins exporting the modules loaded at startup
+	Load []string `config:"load"`
+}
+
+type latency struct {
+	// Latency in milliseconds at or above which an event is recorded by the latency monitor, 0 disables the monitor
+	MonitorThreshold int64 `config:"monitor_threshold" default:"0" validate:"min=0" hot:"true"`
+}
+
+type tracing struct {
+	// Whether request spans are exported to an OpenTelemetry collector over OTLP/HTTP
+	Enabled bool `config:"enabled" default:"false"`
+	// Host and port of the OTLP/HTTP collector
+	Endpoint string `config:"endpoint" default:"localhost:4318"`
+	// Whether the collector is reached over plain HTTP rather than HTTPS
+	Insecure bool `config:"insecure" default:"true"`
+	// Fraction of the requests traced; requests carrying a sampled traceparent are always traced
+	SampleRatio float64 `config:"sample_ratio" default:"1" validate:"min=0,lte=1"`
+	// Value of the service.name resource attribute of the spans
+	ServiceName string `config:"service_name" default:"dicedb"`
+}
+
+type metrics struct {
+	// Whether the Prometheus metrics are served on a dedicated listener at /metrics
+	Enabled bool   `config:"enabled" default:"false"`
+	Addr    string `config:"addr" default:"0.0.0.0" validate:"ip"`
+	Port    int    `config:"port" default:"9379" validate:"number,gte=0,lte=65535"`
+	// Whether the net/http/pprof profiling endpoints are served on the metrics listener at /debug/pprof/
+	PprofEnabled bool `config:"pprof_enabled" default:"false"`
+	// Average number of nanoseconds spent blocked per sample of the block profile, 0 disables it
+	BlockProfileRate int `config:"block_profile_rate" default:"0" validate:"min=0" hot:"true"`
+	// Inverse of the fraction of the mutex contention events sampled by the mutex profile, 0 disables it
+	MutexProfileFraction int `config:"mutex_profile_fraction" default:"0" validate:"min=0" hot:"true"`
+}
+
+type accessLog struct {
+	// Whether every command executed on behalf of a client is logged at info level
+	Enabled bool `config:"enabled" default:"false" hot:"true"`
+	// Fraction of the executed commands that are logged
+	SampleRate float64 `config:"sample_rate" default:"1" validate:"min=0,lte=1" hot:"true"`
+	// Whether the arguments of the commands are logged; values of write commands and credentials are redacted
+	LogArgs bool `config:"log_args" default:"true" hot:"true"`
+}
+
+type security struct {
+	// Whether connections from non-loopback addresses are refused while no password is set;
+	// clients presenting a verified TLS certificate are always accepted
+	ProtectedMode bool `config:"protected_mode" default:"false" hot:"true"`
+	// Comma separated list of commands that are rejected as unknown commands, e.g. "FLUSHDB,KEYS"
+	DisabledCommands []string `config:"disabled_commands"`
+	// Comma separated list of OLD:NEW pairs; the command is only reachable by its new name, e.g. "FLUSHDB:ADMIN_FLUSHDB"
+	RenamedCommands []string `config:"renamed_commands"`
+}
+
+type audit struct {
+	// Whether audit records are written
+	Enabled bool `config:"enabled" default:"false"`
+	// Comma separated list of command categories to audit: 'admin', 'write' and 'read'
+	Categories []string `config:"categories" default:"admin,write"`
+	// Where audit records are written: 'file' (rotating file at FilePath) or 'syslog' (local syslog daemon)
+	Output string `config:"output" default:"file" validate:"oneof=file syslog"`
+	// Path of the audit log file when using file output
+	FilePath string `config:"file_path" default:"/tmp/dicedb/audit.log"`
+	// Maximum size of the audit log file in megabytes before it is rotated
+	MaxFileSizeMB int `config:"max_file_size_mb" default:"100" validate:"min=1"`
+	// Maximum number of rotated audit log files to retain
+	MaxBackups int `config:"max_backups" default:"5" validate:"min=0"`
+}
+
 type network struct {
 	IOBufferLengthMAX int `config:"io_buffer_length_max" default:"51200" validate:"min=0,max=1048576"` // max is 1MB'
 	IOBufferLength    int `config:"io_buffer_length" default:"512" validate:"min=0"`
 }
 
-// DiceConfig is the global configuration object for dice
-var DiceConfig = &Config{}
+// current is the global configuration object for dice. The parameters changed at runtime are published as
+// a new configuration replacing it, so that the readers never see a parameter while it is written.
+var current atomic.Pointer[Config]
+
+func init() {
+	current.Store(&Config{})
+}
+
+// DiceConfig returns the global configuration object for dice. It is only modified in place while the server
+// starts, a reader holding it once the server runs sees the parameters of the moment it was returned.
+func DiceConfig() *Config {
+	return current.Load()
+}
 
 func CreateConfigFile(configFilePath string) error {
 	// Check if the config file already exists
@@ -283,51 +883,92 @@ func loadDiceConfig(configFilePath string) error {
 	parser := NewConfigParser()
 	if err := parser.ParseFromFile(configFilePath); err != nil {
 		slog.Warn("Failed to parse config file", slog.String("error", err.Error()), slog.String("message", "Loading default configurations"))
-		return parser.ParseDefaults(DiceConfig)
+		return parser.ParseDefaults(DiceConfig())
 	}
 
-	return parser.Loadconfig(DiceConfig)
+	ConfigFilePath = configFilePath
+	return parser.Loadconfig(DiceConfig())
 }
 
+// mergedFlags is the config of the command-line flags merged by MergeFlags, applied again on reload so that
+// the flags keep precedence over the config file
+var mergedFlags *Config
+
+// MergeFlags overrides the config with the command-line flags explicitly set by the user.
 func MergeFlags(flags *Config) {
+	mergedFlags = flags
+	applyFlags(DiceConfig(), flags)
+}
+
+func applyFlags(dst, flags *Config) {
 	flagset := flag.CommandLine
 	flagset.Visit(func(f *flag.Flag) {
 		// updating values for flags that were explicitly set by the user
 		switch f.Name {
 		case "host":
-			DiceConfig.RespServer.Addr = flags.RespServer.Addr
+			dst.RespServer.Addr = flags.RespServer.Addr
 		case "port":
-			DiceConfig.RespServer.Port = flags.RespServer.Port
+			dst.RespServer.Port = flags.RespServer.Port
+		case "unixsocket":
+			dst.RespServer.UnixSocket = flags.RespServer.UnixSocket
+		case "unixsocketperm":
+			dst.RespServer.UnixSocketPerm = flags.RespServer.UnixSocketPerm
+		case "tls-port":
+			dst.TLS.Port = flags.TLS.Port
+		case "tls-cert-file":
+			dst.TLS.CertFile = flags.TLS.CertFile
+		case "tls-key-file":
+			dst.TLS.KeyFile = flags.TLS.KeyFile
+		case "tls-ca-file":
+			dst.TLS.CAFile = flags.TLS.CAFile
+		case "tls-auth-clients":
+			dst.TLS.AuthClients = flags.TLS.AuthClients
 		case "enable-http":
-			DiceConfig.HTTP.Enabled = flags.HTTP.Enabled
+			dst.HTTP.Enabled = flags.HTTP.Enabled
+		case "http-host":
+			dst.HTTP.Addr = flags.HTTP.Addr
 		case "http-port":
-			DiceConfig.HTTP.Port = flags.HTTP.Port
+			dst.HTTP.Port = flags.HTTP.Port
 		case "enable-websocket":
-			DiceConfig.WebSocket.Enabled = flags.WebSocket.Enabled
+			dst.WebSocket.Enabled = flags.WebSocket.Enabled
+		case "websocket-host":
+			dst.WebSocket.Addr = flags.WebSocket.Addr
 		case "websocket-port":
-			DiceConfig.WebSocket.Port = flags.WebSocket.Port
+			dst.WebSocket.Port = flags.WebSocket.Port
+		case "enable-metrics":
+			dst.Metrics.Enabled = flags.Metrics.Enabled
+		case "metrics-host":
+			dst.Metrics.Addr = flags.Metrics.Addr
+		case "metrics-port":
+			dst.Metrics.Port = flags.Metrics.Port
 		case "num-shards":
-			DiceConfig.Performance.NumShards = flags.Performance.NumShards
+			dst.Performance.NumShards = flags.Performance.NumShards
 		case "enable-watch":
-			DiceConfig.Performance.EnableWatch = flags.Performance.EnableWatch
+			dst.Performance.EnableWatch = flags.Performance.EnableWatch
 		case "enable-profiling":
-			DiceConfig.Performance.EnableProfiling = flags.Performance.EnableProfiling
+			dst.Performance.EnableProfiling = flags.Performance.EnableProfiling
 		case "log-level":
-			DiceConfig.Logging.LogLevel = flags.Logging.LogLevel
+			dst.Logging.LogLevel = flags.Logging.LogLevel
 		case "log-dir":
-			DiceConfig.Logging.LogDir = flags.Logging.LogDir
+			dst.Logging.LogDir = flags.Logging.LogDir
 		case "enable-persistence":
-			DiceConfig.Persistence.Enabled = flags.Persistence.Enabled
+			dst.Persistence.Enabled = flags.Persistence.Enabled
 		case "restore-from-wal":
-			DiceConfig.Persistence.RestoreFromWAL = flags.Persistence.RestoreFromWAL
+			dst.Persistence.RestoreFromWAL = flags.Persistence.RestoreFromWAL
 		case "wal-engine":
-			DiceConfig.Persistence.WALEngine = flags.Persistence.WALEngine
-		case "require-pass":
-			DiceConfig.Auth.Password = flags.Auth.Password
+			dst.Persistence.WALEngine = flags.Persistence.WALEngine
+		case "protected-mode":
+			dst.Security.ProtectedMode = flags.Security.ProtectedMode
+		case "requirepass":
+			dst.Auth.Password = flags.Auth.Password
 		case "keys-limit":
-			DiceConfig.Memory.KeysLimit = flags.Memory.KeysLimit
+			dst.Memory.KeysLimit = flags.Memory.KeysLimit
 		case "eviction-ratio":
-			DiceConfig.Memory.EvictionRatio = flags.Memory.EvictionRatio
+			dst.Memory.EvictionRatio = flags.Memory.EvictionRatio
+		case "warm-from":
+			dst.Warm.From = flags.Warm.From
+		case "sentinel":
+			dst.Sentinel.Enabled = flags.Sentinel.Enabled
 		}
 	})
 }
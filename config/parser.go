@@ -89,7 +89,7 @@ func (p *ConfigParser) Loadconfig(cfg interface{}) error {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	if err := validateConfig(DiceConfig); err != nil {
+	if err := validateConfig(DiceConfig()); err != nil {
 		return fmt.Errorf("failed to validate config: %w", err)
 	}
 
@@ -158,6 +158,20 @@ func (p *ConfigParser) unmarshalStruct(val reflect.Value, prefix string) error {
 			continue
 		}
 
+		// Secrets may be referenced through a <key>_file or <key>_env entry instead of being inlined
+		if fieldType.Tag.Get("secret") == "true" {
+			secret, ok, err := p.secretValue(fullKey)
+			if err != nil {
+				return fmt.Errorf("error reading secret %s: %w", fullKey, err)
+			}
+			if ok {
+				if err := setField(field, secret); err != nil {
+					return fmt.Errorf("error setting field %s: %w", fullKey, err)
+				}
+				continue
+			}
+		}
+
 		// Fetch and set value for non-struct fields
 		value, exists := p.store[fullKey]
 		if !exists {
@@ -177,6 +191,47 @@ func (p *ConfigParser) unmarshalStruct(val reflect.Value, prefix string) error {
 	return nil
 }
 
+// secretValue resolves the value of a secret from the file named by <key>_file or, failing that,
+// from the environment variable named by <key>_env. It reports false if neither is configured.
+func (p *ConfigParser) secretValue(key string) (string, bool, error) {
+	if path := p.store[key+"_file"]; path != "" {
+		secret, err := ReadSecretFile(path)
+		if err != nil {
+			return "", false, err
+		}
+		return secret, true, nil
+	}
+
+	if name := p.store[key+"_env"]; name != "" {
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", false, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, true, nil
+	}
+
+	return "", false, nil
+}
+
+// ReadSecretFile reads a secret from a file, dropping the trailing newline most editors add.
+// A warning is logged if the file is readable by users other than its owner.
+func ReadSecretFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		slog.Warn("secret file is accessible by other users", slog.String("path", path), slog.String("mode", info.Mode().Perm().String()))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
 // setField sets the appropriate field value based on its type
 func setField(field reflect.Value, value string) error {
 	switch field.Kind() {
@@ -0,0 +1,120 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+const redactedValue = "(redacted)"
+
+// reloadHooks holds the functions run after every successful reload, whether or not a
+// parameter changed, so that files referenced by the config (e.g. TLS certificates) can be re-read.
+var reloadHooks []func()
+
+// OnReload registers a function run after the config file is reloaded.
+func OnReload(hook func()) {
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// Reload re-reads ConfigFilePath, overridden by the command-line flags merged at startup, and applies
+// the changed hot-reloadable parameters to the running server in one step. Changes to other parameters are reported but only take effect
+// after a restart. Nothing is applied if the file cannot be parsed or a hot-reloadable parameter is invalid.
+func Reload() error {
+	if ConfigFilePath == "" {
+		return ErrNoConfigFile
+	}
+
+	parser := NewConfigParser()
+	if err := parser.ParseFromFile(ConfigFilePath); err != nil {
+		return err
+	}
+
+	fresh := &Config{}
+	if err := parser.ParseDefaults(fresh); err != nil {
+		return err
+	}
+	// The flags override the file, they are not reverted by the reload
+	if mergedFlags != nil {
+		applyFlags(fresh, mergedFlags)
+	}
+
+	// Only the hot-reloadable parameters are applied, so only they need to be valid
+	var mutable []parameter
+	for _, p := range parameters() {
+		if p.mutable() {
+			mutable = append(mutable, p)
+		}
+	}
+	if err := checkParameters(fresh, mutable); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	runtimeMu.Lock()
+	hooks, err := applyReload(fresh)
+	runtimeMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		hook()
+	}
+	return nil
+}
+
+// applyReload sets the hot-reloadable parameters that differ in fresh on the running config and
+// returns the hooks to run. It must be called with runtimeMu held.
+func applyReload(fresh *Config) ([]func(), error) {
+	values := reflect.ValueOf(DiceConfig()).Elem()
+	freshValues := reflect.ValueOf(fresh).Elem()
+
+	var pairs []string
+	for _, p := range parameters() {
+		current := formatValue(values.FieldByIndex(p.index))
+		updated := formatValue(freshValues.FieldByIndex(p.index))
+		if current == updated {
+			continue
+		}
+
+		if p.secret() {
+			current, updated = redactedValue, redactedValue
+		}
+
+		if !p.mutable() {
+			slog.Warn("config change requires a restart", slog.String("parameter", p.name), slog.String("current", current), slog.String("new", updated))
+			continue
+		}
+
+		slog.Info("config changed", slog.String("parameter", p.name), slog.String("old", current), slog.String("new", updated))
+		pairs = append(pairs, p.name, formatValue(freshValues.FieldByIndex(p.index)))
+	}
+
+	var hooks []func()
+	if len(pairs) > 0 {
+		var err error
+		if hooks, err = setParameters(pairs); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(hooks, reloadHooks...), nil
+}
@@ -0,0 +1,413 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/regex"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrUnknownParameter   = errors.New("unknown parameter")
+	ErrImmutableParameter = errors.New("can't set immutable config")
+	ErrNoConfigFile       = errors.New("the server is running without a config file")
+)
+
+// ConfigFilePath is the path of the config file the server was started with, empty if
+// it was started from defaults or stdin. CONFIG REWRITE persists changes to this file.
+var ConfigFilePath = ""
+
+var (
+	// runtimeMu serializes changes made to the config while the server is running
+	runtimeMu sync.Mutex
+
+	// changeHooks holds the functions run after a parameter is changed at runtime, keyed by parameter name
+	changeHooks = make(map[string][]func())
+
+	// parameterAliases maps the Redis names of parameters onto their config keys
+	parameterAliases = map[string]string{
+		"maxmemory":                 "memory.max_memory",
+		"maxmemory-policy":          "memory.eviction_policy",
+		"lfu-log-factor":            "memory.lfu_log_factor",
+		"lazyfree-lazy-user-flush":  "memory.lazyfree_lazy_user_flush",
+		"requirepass":               "auth.password",
+		"unixsocket":                "async_server.unixsocket",
+		"unixsocketperm":            "async_server.unixsocketperm",
+		"loglevel":                  "logging.log_level",
+		"protected-mode":            "security.protected_mode",
+		"slowlog-log-slower-than":   "slowlog.log_slower_than",
+		"slowlog-max-len":           "slowlog.max_len",
+		"latency-monitor-threshold": "latency.monitor_threshold",
+	}
+)
+
+// parameter is a config setting addressed by its config key, e.g. "memory.max_memory".
+// Settings tagged `hot:"true"` can be changed while the server is running.
+type parameter struct {
+	name  string
+	index []int
+	field reflect.StructField
+}
+
+func (p *parameter) mutable() bool {
+	return p.field.Tag.Get("hot") == "true"
+}
+
+func (p *parameter) secret() bool {
+	return p.field.Tag.Get("secret") == "true"
+}
+
+// parameters lists the settings of the Config struct, named the same way the parser names them
+func parameters() []parameter {
+	var params []parameter
+	collectParameters(reflect.TypeOf(Config{}), "", nil, &params)
+	sort.Slice(params, func(i, j int) bool { return params[i].name < params[j].name })
+	return params
+}
+
+func collectParameters(typ reflect.Type, prefix string, index []int, params *[]parameter) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := field.Tag.Get("config")
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		if key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = fmt.Sprintf("%s.%s", prefix, key)
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			collectParameters(field.Type, key, fieldIndex, params)
+			continue
+		}
+
+		*params = append(*params, parameter{name: key, index: fieldIndex, field: field})
+	}
+}
+
+// lookupParameter finds a parameter by its config key or Redis alias, ignoring case
+func lookupParameter(name string) (parameter, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := parameterAliases[name]; ok {
+		name = alias
+	}
+
+	for _, p := range parameters() {
+		if strings.ToLower(p.name) == name {
+			return p, true
+		}
+	}
+	return parameter{}, false
+}
+
+// GetParameters returns the name and value of every parameter matching one of the glob
+// patterns, as a flat list of pairs. Redis aliases are only matched by their exact name.
+func GetParameters(patterns []string) []string {
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+
+	values := reflect.ValueOf(DiceConfig()).Elem()
+	seen := make(map[string]bool)
+	var result []string
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+
+		if alias, ok := parameterAliases[pattern]; ok && !seen[pattern] {
+			if p, ok := lookupParameter(alias); ok {
+				seen[pattern] = true
+				result = append(result, pattern, formatValue(values.FieldByIndex(p.index)))
+			}
+		}
+
+		for _, p := range parameters() {
+			if seen[p.name] || !regex.WildCardMatch(pattern, strings.ToLower(p.name)) {
+				continue
+			}
+			seen[p.name] = true
+			result = append(result, p.name, formatValue(values.FieldByIndex(p.index)))
+		}
+	}
+
+	return result
+}
+
+// SetParameters changes the given parameters, passed as name and value pairs, on the running server.
+// Either all of the parameters are changed or, if any of them is unknown, immutable or invalid, none.
+func SetParameters(pairs []string) error {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return fmt.Errorf("expected name and value pairs")
+	}
+
+	runtimeMu.Lock()
+	hooks, err := setParameters(pairs)
+	runtimeMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Hooks run without the lock held so that they can read the parameters back
+	for _, hook := range hooks {
+		hook()
+	}
+	return nil
+}
+
+// setParameters applies the changes and returns the hooks of the changed parameters.
+// It must be called with runtimeMu held.
+func setParameters(pairs []string) ([]func(), error) {
+	// Apply the changes to a copy so that an invalid value leaves the live config untouched
+	candidate := *DiceConfig()
+	candidateValues := reflect.ValueOf(&candidate).Elem()
+	changed := make([]parameter, 0, len(pairs)/2)
+
+	for i := 0; i < len(pairs); i += 2 {
+		p, ok := lookupParameter(pairs[i])
+		if !ok {
+			return nil, fmt.Errorf("%w '%s'", ErrUnknownParameter, pairs[i])
+		}
+		if !p.mutable() {
+			return nil, fmt.Errorf("%w '%s'", ErrImmutableParameter, p.name)
+		}
+		if err := setField(candidateValues.FieldByIndex(p.index), pairs[i+1]); err != nil {
+			return nil, fmt.Errorf("invalid value for '%s': %w", p.name, err)
+		}
+		changed = append(changed, p)
+	}
+
+	if err := checkParameters(&candidate, changed); err != nil {
+		return nil, err
+	}
+
+	// The candidate is published as a whole, the config read by the running server is never written
+	current.Store(&candidate)
+	var hooks []func()
+	for _, p := range changed {
+		hooks = append(hooks, changeHooks[p.name]...)
+	}
+
+	return hooks, nil
+}
+
+// checkParameters validates the candidate config and reports the first failure of a changed parameter
+func checkParameters(candidate *Config, changed []parameter) error {
+	err := newValidator().Struct(candidate)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	for _, p := range changed {
+		fieldName := fieldPath(p.index)
+		for _, validationErr := range validationErrors {
+			if strings.TrimPrefix(validationErr.Namespace(), "Config.") == fieldName {
+				return fmt.Errorf("invalid value for '%s': failed on '%s' validation", p.name, validationErr.Tag())
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldPath returns the Go field path of a parameter, e.g. "Memory.MaxMemory"
+func fieldPath(index []int) string {
+	typ := reflect.TypeOf(Config{})
+	names := make([]string, 0, len(index))
+	for _, i := range index {
+		field := typ.Field(i)
+		names = append(names, field.Name)
+		typ = field.Type
+	}
+	return strings.Join(names, ".")
+}
+
+// OnParameterChange registers a function run after the named parameter is changed at runtime.
+func OnParameterChange(name string, hook func()) {
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+	changeHooks[name] = append(changeHooks[name], hook)
+}
+
+// RewriteConfigFile persists the running configuration to ConfigFilePath. Existing lines are
+// updated in place, keeping comments and layout, and settings that differ from their defaults
+// but are missing from the file are appended. Secrets referenced through <key>_file or <key>_env
+// are never written inline.
+func RewriteConfigFile() error {
+	if ConfigFilePath == "" {
+		return ErrNoConfigFile
+	}
+
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+
+	content, err := os.ReadFile(ConfigFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	params := make(map[string]parameter)
+	for _, p := range parameters() {
+		params[p.name] = p
+	}
+
+	values := reflect.ValueOf(DiceConfig()).Elem()
+	written := make(map[string]bool)
+	var out bytes.Buffer
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			out.WriteString(line + "\n")
+			continue
+		}
+
+		key := strings.TrimSpace(strings.SplitN(trimmed, "=", 2)[0])
+		if p, ok := params[key]; ok {
+			written[key] = true
+			if p.secret() && hasSecretReference(content, key) {
+				out.WriteString(line + "\n")
+				continue
+			}
+			out.WriteString(formatLine(key, values.FieldByIndex(p.index)) + "\n")
+			continue
+		}
+		out.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	appended := false
+	for _, p := range parameters() {
+		if written[p.name] || (p.secret() && hasSecretReference(content, p.name)) {
+			continue
+		}
+
+		value := values.FieldByIndex(p.index)
+		defaultValue := reflect.New(p.field.Type).Elem()
+		if tag := p.field.Tag.Get("default"); tag != "" {
+			if err := setField(defaultValue, tag); err != nil {
+				return err
+			}
+		}
+		if formatValue(value) == formatValue(defaultValue) {
+			continue
+		}
+
+		if !appended {
+			out.WriteString("\n# Generated by CONFIG REWRITE\n")
+			appended = true
+		}
+		out.WriteString(formatLine(p.name, value) + "\n")
+	}
+
+	// Write to a temporary file first so that a failed rewrite never truncates the config
+	tmp, err := os.CreateTemp(filepath.Dir(ConfigFilePath), filepath.Base(ConfigFilePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(out.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), ConfigFilePath)
+}
+
+func hasSecretReference(content []byte, key string) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		trimmed := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		name := strings.TrimSpace(strings.SplitN(trimmed, "=", 2)[0])
+		if name == key+"_file" || name == key+"_env" {
+			return true
+		}
+	}
+	return false
+}
+
+// formatLine renders a setting the way the default config template does, quoting strings and lists
+func formatLine(key string, value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice:
+		return fmt.Sprintf("%s = %q", key, formatValue(value))
+	default:
+		return fmt.Sprintf("%s = %s", key, formatValue(value))
+	}
+}
+
+// formatValue renders a setting in the form accepted by the config parser
+func formatValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == reflect.TypeOf(time.Duration(0)) {
+			return time.Duration(value.Int()).String()
+		}
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		elems := make([]string, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			elems[i] = formatValue(value.Index(i))
+		}
+		return strings.Join(elems, ",")
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
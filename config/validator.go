@@ -19,18 +19,31 @@ package config
 import (
 	"fmt"
 	"log"
+	"os"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
 
-func validateConfig(config *Config) error {
+// newValidator returns a validator for the Config struct with the struct level validations registered
+func newValidator() *validator.Validate {
 	validate := validator.New()
 	validate.RegisterStructValidation(validateShardCount, Config{})
 	validate.RegisterStructValidation(validateWALConfig, Config{})
+	validate.RegisterStructValidation(validateTLSConfig, Config{})
+	validate.RegisterStructValidation(validateUnixSocketConfig, Config{})
+	return validate
+}
+
+// Check validates the config against the constraints of its fields, without correcting it.
+func Check(config *Config) error {
+	return newValidator().Struct(config)
+}
 
-	if err := validate.Struct(config); err != nil {
+func validateConfig(config *Config) error {
+	if err := newValidator().Struct(config); err != nil {
 		validationErrors, ok := err.(validator.ValidationErrors)
 		if !ok {
 			return fmt.Errorf("unexpected validation error type: %v", err)
@@ -145,3 +158,46 @@ func validateWALConfig(sl validator.StructLevel) {
 		sl.ReportError(config.WAL.WalMode, "WALMode", "WALMode", "incompatible", "walMode 'unbuffered' cannot have writeMode as 'default'")
 	}
 }
+
+func validateTLSConfig(sl validator.StructLevel) {
+	config := sl.Current().Interface().(Config)
+
+	if config.TLS.Port == 0 {
+		return
+	}
+
+	// A TLS listener cannot be started without the server's key pair
+	if config.TLS.CertFile == "" || config.TLS.KeyFile == "" {
+		sl.ReportError(config.TLS.Port, "TLS.Port", "Port", "required_with", "cert_file and key_file are required when tls port is set")
+	}
+
+	// Client certificates can only be verified against a CA bundle
+	if config.TLS.AuthClients != "no" && config.TLS.CAFile == "" {
+		sl.ReportError(config.TLS.AuthClients, "TLS.AuthClients", "AuthClients", "required_with", "ca_file is required when auth_clients is enabled")
+	}
+
+	if config.TLS.Port == config.RespServer.Port {
+		sl.ReportError(config.TLS.Port, "TLS.Port", "Port", "ne", "tls port must differ from the plaintext port")
+	}
+}
+
+func validateUnixSocketConfig(sl validator.StructLevel) {
+	config := sl.Current().Interface().(Config)
+
+	if config.RespServer.UnixSocket == "" {
+		return
+	}
+
+	if _, err := ParseUnixSocketPerm(config.RespServer.UnixSocketPerm); err != nil {
+		sl.ReportError(config.RespServer.UnixSocketPerm, "RespServer.UnixSocketPerm", "UnixSocketPerm", "octal", err.Error())
+	}
+}
+
+// ParseUnixSocketPerm parses the octal file mode of the Unix domain socket, e.g. "0770" or "770".
+func ParseUnixSocketPerm(perm string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(perm, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("unixsocketperm must be an octal file mode between 0 and 0777, got %q", perm)
+	}
+	return os.FileMode(mode), nil
+}
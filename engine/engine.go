@@ -0,0 +1,251 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package engine runs DiceDB inside another Go program: the shards, the store and the commands are the same
+// as the ones of the server, but no RESP, HTTP or WebSocket listener is started, the commands are executed
+// through Go calls instead.
+//
+// The engine is configured by config.DiceConfig(), which is filled with the defaults if the program did not load
+// it. Watching commands requires config.DiceConfig().Performance.EnableWatch to be set before New is called.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cache"
+	"github.com/dicedb/dice/internal/inproc"
+	"github.com/dicedb/dice/internal/iothread"
+	"github.com/dicedb/dice/internal/module"
+	"github.com/dicedb/dice/internal/namespace"
+	"github.com/dicedb/dice/internal/shard"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dice/internal/watchmanager"
+)
+
+var (
+	// ErrClosed is returned once the engine or the session is closed
+	ErrClosed = inproc.ErrClosed
+	// ErrWatchDisabled is returned by Watch when the watch of commands is not enabled in the config
+	ErrWatchDisabled = inproc.ErrWatchDisabled
+	// ErrNotFound is returned by a Loader when the origin does not have the key either
+	ErrNotFound = cache.ErrNotFound
+)
+
+type (
+	// Error is an error replied by a command, e.g. a wrong number of arguments or an operation against a key
+	// holding the wrong type, as opposed to the errors of the engine itself.
+	Error = inproc.Error
+
+	// Session is the equivalent of a client connection, the commands executed through it share the state of
+	// the connection. A session executes one command at a time, the calls of Execute are serialized.
+	Session = inproc.Session
+
+	// Subscription is a watched command. Its updates are received on C, which is closed once the subscription
+	// or the engine is closed.
+	Subscription = inproc.Subscription
+
+	// Update is a push of a watched command, sent once the command is subscribed and then every time its
+	// result may have changed.
+	Update = inproc.Update
+
+	// CacheOptions makes the engine a cache in front of an origin: a GET of a key matching the patterns and
+	// missing from the engine loads the key with the Loader, and the writes of the keys matching the patterns
+	// are propagated with the Writer in the background.
+	CacheOptions = cache.Options
+
+	// CacheEntry is a key loaded from the origin, with the TTL it is kept for.
+	CacheEntry = cache.Entry
+
+	// Loader loads the keys missing from the engine from the origin.
+	Loader = cache.Loader
+
+	// LoaderFunc adapts a function to a Loader.
+	LoaderFunc = cache.LoaderFunc
+
+	// Writer propagates the writes of the keys to the origin.
+	Writer = cache.Writer
+)
+
+// Options are the settings of an engine that are not part of the config.
+type Options struct {
+	// Shards is the number of shards, config.DiceConfig().Performance.NumShards or the number of CPUs when 0
+	Shards int
+	// Cache makes the engine a read-through and write-through cache of an origin, nil for none
+	Cache *CacheOptions
+}
+
+// Engine is a DiceDB instance running in the current process.
+type Engine struct {
+	shardManager *shard.ShardManager
+	watchManager *watchmanager.Manager
+	host         *inproc.Host
+	errChan      chan error
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	stopCache    func() // stopCache stops the cache and waits for its pending writes, nil without a cache
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// New starts an engine, it runs until Close is called.
+func New(opts Options) (*Engine, error) {
+	if config.DiceConfig().Version == "" {
+		if err := config.NewConfigParser().ParseDefaults(config.DiceConfig()); err != nil {
+			return nil, fmt.Errorf("could not load the default config: %w", err)
+		}
+	}
+
+	numShards := opts.Shards
+	if numShards <= 0 {
+		numShards = config.DiceConfig().Performance.NumShards
+	}
+	if numShards <= 0 {
+		numShards = runtime.NumCPU()
+	}
+	if numShards > 128 {
+		return nil, fmt.Errorf("invalid number of shards %d, at most 128 are supported", numShards)
+	}
+
+	if err := namespace.Load(); err != nil {
+		return nil, fmt.Errorf("invalid namespaces: %w", err)
+	}
+	if err := module.Load(); err != nil {
+		return nil, fmt.Errorf("could not load the modules: %w", err)
+	}
+
+	wl, err := wal.NewNullWAL()
+	if err != nil {
+		return nil, err
+	}
+
+	var cmdWatchChan chan dstore.CmdWatchEvent
+	if config.DiceConfig().Performance.EnableWatch {
+		cmdWatchChan = make(chan dstore.CmdWatchEvent, config.DiceConfig().Performance.WatchChanBufSize)
+	}
+
+	e := &Engine{
+		errChan: make(chan error, 1),
+	}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.shardManager = shard.NewShardManager(uint8(numShards), cmdWatchChan, e.errChan)
+	ioThreadManager := iothread.NewManager(config.DiceConfig().Performance.MaxClients, e.shardManager)
+
+	var cacheManager *cache.Manager
+	if opts.Cache != nil {
+		if cacheManager, err = cache.New(e.shardManager, *opts.Cache); err != nil {
+			e.cancel()
+			return nil, err
+		}
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.shardManager.Run(e.ctx)
+	}()
+
+	if cacheManager != nil {
+		// The cache is stopped before the shards, as its pending writes read the values from them
+		cacheCtx, cancelCache := context.WithCancel(e.ctx)
+		cacheDone := make(chan struct{})
+		go func() {
+			defer close(cacheDone)
+			_ = cacheManager.Run(cacheCtx)
+		}()
+		e.stopCache = func() {
+			cancelCache()
+			<-cacheDone
+		}
+	}
+
+	var cmdWatchSubscriptionChan chan watchmanager.WatchSubscription
+	if cmdWatchChan != nil {
+		cmdWatchSubscriptionChan = make(chan watchmanager.WatchSubscription)
+		e.watchManager = watchmanager.NewManager(cmdWatchSubscriptionChan, cmdWatchChan)
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			e.watchManager.Run(e.ctx)
+		}()
+	}
+	e.host = inproc.NewHost(e.ctx, "E", "embedded", e.shardManager, ioThreadManager, cmdWatchSubscriptionChan, e.errChan, wl)
+
+	// SHUTDOWN and ABORT request the shutdown of the server through the error channel, the program embedding
+	// the engine decides by itself when to close it
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-e.errChan:
+			}
+		}
+	}()
+
+	return e, nil
+}
+
+// Execute executes a command, e.g. Execute(ctx, "SET", "k", "v"), and returns its reply. The reply is nil,
+// a string, an int64 or a []interface{} of those. An error replied by the command is returned as an Error,
+// the errors nested in an array, e.g. in the reply of EXEC, are Error values of the array.
+//
+// The commands executed by Execute do not share a connection state: MULTI, AUTH or CLIENT SETNAME require
+// a Session.
+func (e *Engine) Execute(ctx context.Context, command string, args ...string) (interface{}, error) {
+	return e.host.Execute(ctx, command, args...)
+}
+
+// NewSession opens a session, the equivalent of a client connection: the commands executed through it share
+// the state of the connection, e.g. a transaction or the authenticated user.
+func (e *Engine) NewSession() (*Session, error) {
+	return e.host.NewSession()
+}
+
+// Watch subscribes to the result of a command, e.g. Watch(ctx, "GET", "k"). The first update is the current
+// result of the command. The updates are dropped rather than queued while the subscriber is not receiving
+// them, so that a slow subscriber does not hold up the engine.
+func (e *Engine) Watch(ctx context.Context, command string, args ...string) (*Subscription, error) {
+	return e.host.Watch(ctx, command, args...)
+}
+
+// Close stops the engine and waits for its goroutines. The sessions and the subscriptions are closed, and
+// the data is dropped.
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.mu.Unlock()
+
+	if e.stopCache != nil {
+		e.stopCache()
+	}
+	e.cancel()
+	e.host.Close()
+	e.wg.Wait()
+	return nil
+}
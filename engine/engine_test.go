@@ -0,0 +1,250 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEngine(t *testing.T) *Engine {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().Performance.EnableWatch = true
+
+	e, err := New(Options{Shards: 2})
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, e.Close()) })
+	return e
+}
+
+func TestExecute(t *testing.T) {
+	e := newTestEngine(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		args []string
+		want interface{}
+		err  string
+	}{
+		{args: []string{"GET", "k"}, want: nil},
+		{args: []string{"SET", "k", "line\r\nbreak"}, want: "OK"},
+		{args: []string{"GET", "k"}, want: "line\r\nbreak"},
+		{args: []string{"INCR", "n"}, want: int64(1)},
+		{args: []string{"RPUSH", "l", "a", "b"}, want: int64(2)},
+		{args: []string{"LRANGE", "l", "0", "-1"}, want: []interface{}{"a", "b"}},
+		{args: []string{"MGET", "k", "missing"}, want: []interface{}{"line\r\nbreak", nil}},
+		{args: []string{"INCR", "k"}, err: "ERR value is not an integer or out of range"},
+		{args: []string{"GET"}, err: "ERR wrong number of arguments for 'get' command"},
+	}
+	for _, tt := range tests {
+		got, err := e.Execute(ctx, tt.args[0], tt.args[1:]...)
+		if tt.err != "" {
+			var cmdErr Error
+			require.ErrorAs(t, err, &cmdErr, tt.args)
+			assert.Equal(t, tt.err, cmdErr.Error(), tt.args)
+			continue
+		}
+		require.NoError(t, err, tt.args)
+		assert.Equal(t, tt.want, got, tt.args)
+	}
+}
+
+func TestSession(t *testing.T) {
+	e := newTestEngine(t)
+	ctx := context.Background()
+
+	s, err := e.NewSession()
+	require.NoError(t, err)
+	defer s.Close()
+
+	for _, args := range [][]string{{"MULTI"}, {"SET", "k", "v"}, {"INCR", "k"}} {
+		_, err := s.Execute(ctx, args[0], args[1:]...)
+		require.NoError(t, err, args)
+	}
+	got, err := s.Execute(ctx, "EXEC")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "OK", got.([]interface{})[0])
+	assert.IsType(t, Error(""), got.([]interface{})[1])
+
+	require.NoError(t, s.Close())
+	_, err = s.Execute(ctx, "PING")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWatch(t *testing.T) {
+	e := newTestEngine(t)
+	ctx := context.Background()
+
+	_, err := e.Execute(ctx, "SET", "k", "v1")
+	require.NoError(t, err)
+
+	sub, err := e.Watch(ctx, "GET", "k")
+	require.NoError(t, err)
+
+	receive := func() Update {
+		select {
+		case u := <-sub.C:
+			return u
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no update received")
+			return Update{}
+		}
+	}
+
+	assert.Equal(t, Update{Seq: 0, Result: "v1"}, receive())
+	_, err = e.Execute(ctx, "SET", "k", "v2")
+	require.NoError(t, err)
+	assert.Equal(t, Update{Seq: 1, Result: "v2"}, receive())
+
+	require.NoError(t, sub.Close())
+	_, open := <-sub.C
+	assert.False(t, open)
+}
+
+func TestClose(t *testing.T) {
+	e := newTestEngine(t)
+
+	require.NoError(t, e.Close())
+	_, err := e.Execute(context.Background(), "PING")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+// chanWriter sends the writes propagated to the origin on a channel
+type chanWriter chan [2]string
+
+func (w chanWriter) Write(_ context.Context, key, value string) error {
+	w <- [2]string{key, value}
+	return nil
+}
+
+func (w chanWriter) Delete(_ context.Context, key string) error {
+	w <- [2]string{key, ""}
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	writes := make(chanWriter, 8)
+	e, err := New(Options{Shards: 2, Cache: &CacheOptions{
+		Patterns: []string{"user:*"},
+		Loader: LoaderFunc(func(_ context.Context, key string) (CacheEntry, error) {
+			if key == "user:1" {
+				return CacheEntry{Value: "alice"}, nil
+			}
+			return CacheEntry{}, ErrNotFound
+		}),
+		Writer: writes,
+	}})
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, e.Close()) })
+	ctx := context.Background()
+
+	// The misses are loaded from the origin, without being written back
+	got, err := e.Execute(ctx, "GET", "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got)
+	got, err = e.Execute(ctx, "GET", "user:2")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	// The writes are propagated to the origin
+	_, err = e.Execute(ctx, "SET", "user:2", "bob")
+	require.NoError(t, err)
+	select {
+	case w := <-writes:
+		assert.Equal(t, [2]string{"user:2", "bob"}, w)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the write was not propagated")
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().Namespaces.Enabled = true
+	config.DiceConfig().Namespaces.Tenants = []string{"tenant:secret"}
+	e, err := New(Options{Shards: 2})
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, e.Close()) })
+	ctx := context.Background()
+
+	tenant, err := e.NewSession()
+	require.NoError(t, err)
+	defer tenant.Close()
+	admin, err := e.NewSession()
+	require.NoError(t, err)
+	defer admin.Close()
+
+	tests := []struct {
+		session *Session
+		args    []string
+		want    interface{}
+		err     string
+	}{
+		{session: tenant, args: []string{"AUTH", "tenant", "wrong"}, err: "WRONGPASS invalid username-password pair or user is disabled"},
+		{session: tenant, args: []string{"AUTH", "tenant", "secret"}, want: "OK"},
+		{session: tenant, args: []string{"NAMESPACE"}, want: "tenant"},
+		{session: tenant, args: []string{"MSET", "a", "1", "b", "2"}, want: "OK"},
+		{session: tenant, args: []string{"GET", "a"}, want: int64(1)},
+		{session: tenant, args: []string{"KEYS", "a*"}, want: []interface{}{"a"}},
+		{session: tenant, args: []string{"DBSIZE"}, err: "NOPERM this command is not available in a namespace"},
+		{session: tenant, args: []string{"NAMESPACE", "USE", "default"}, err: "NOPERM a tenant can not leave its namespace"},
+		{session: admin, args: []string{"GET", "tenant:a"}, want: int64(1)},
+		{session: admin, args: []string{"GET", "a"}, want: nil},
+		{session: admin, args: []string{"NAMESPACE", "SETQUOTA", "tenant", "MAXKEYS", "2"}, want: "OK"},
+		{session: tenant, args: []string{"SET", "c", "x"}, err: "OOM command not allowed when the namespace reached its quota"},
+		{session: tenant, args: []string{"DEL", "a"}, want: int64(1)},
+		{session: tenant, args: []string{"SET", "c", "x"}, want: "OK"},
+		{session: admin, args: []string{"NAMESPACE", "USE", "other"}, want: "OK"},
+		{session: admin, args: []string{"SET", "c", "y"}, want: "OK"},
+		{session: admin, args: []string{"NAMESPACE", "USE", "default"}, want: "OK"},
+		{session: admin, args: []string{"MGET", "tenant:c", "other:c"}, want: []interface{}{"x", "y"}},
+	}
+	for _, tt := range tests {
+		got, err := tt.session.Execute(ctx, tt.args[0], tt.args[1:]...)
+		if tt.err != "" {
+			var cmdErr Error
+			require.ErrorAs(t, err, &cmdErr, tt.args)
+			assert.Equal(t, tt.err, cmdErr.Error(), tt.args)
+			continue
+		}
+		require.NoError(t, err, tt.args)
+		assert.Equal(t, tt.want, got, tt.args)
+	}
+
+	// The integers are measured as 8 bytes
+	got, err := tenant.Execute(ctx, "NAMESPACE", "INFO")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		"name", "tenant", "keys", int64(2), "memory", int64(len("tenant:b") + 8 + len("tenant:c") + 1),
+		"max_keys", int64(2), "max_memory", int64(0), "max_ops_per_sec", int64(0), "rejected", int64(1),
+	}, got)
+	// The default namespace reports the keys of no namespace, without quotas
+	_, err = admin.Execute(ctx, "SET", "plain", "v")
+	require.NoError(t, err)
+	got, err = admin.Execute(ctx, "NAMESPACE", "INFO")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		"name", "default", "keys", int64(1), "memory", int64(len("plain") + 1),
+		"max_keys", int64(0), "max_memory", int64(0), "max_ops_per_sec", int64(0), "rejected", int64(0),
+	}, got)
+}
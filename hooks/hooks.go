@@ -0,0 +1,179 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package hooks lets the programs embedding DiceDB react to the changes of the keys, e.g. to mirror them into
+// an external system, without changing the store.
+//
+// The hooks are called off the hot path: the shards only queue the changes, and a single goroutine calls the
+// hooks in the order the changes happened. A hook that is slower than the changes makes the queue fill up,
+// the changes that do not fit are then dropped rather than slowing the shards down, and counted by Dropped.
+package hooks
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// queueSize is the number of changes queued for the hooks before the changes are dropped
+const queueSize = 64 * 1024
+
+// EventType is the kind of change of a key.
+type EventType uint8
+
+const (
+	// Write is a key written or deleted by a command
+	Write EventType = iota + 1
+	// Expire is a key removed as its TTL elapsed
+	Expire
+	// Evict is a key evicted to keep the number of keys under the limit
+	Evict
+)
+
+// Event is a change of a key.
+type Event struct {
+	Type    EventType
+	Key     string
+	Cmd     string    // Cmd is the command that caused the change, e.g. SET, DEL, RENAME, EXPIRED or EVICT
+	Deleted bool      // Deleted is set when a write removed the key, e.g. DEL or the source key of RENAME
+	At      time.Time // At is the time at which the key was changed
+}
+
+// Hooks is notified of the changes of the keys. The value of a key is not part of the event, as it may have
+// changed again by the time the hook is called.
+type Hooks interface {
+	// OnWrite is called once a key is written or deleted by a command
+	OnWrite(e Event)
+	// OnExpire is called once a key is removed as its TTL elapsed, be it by the active expiry or on access
+	OnExpire(e Event)
+	// OnEvict is called once a key is evicted
+	OnEvict(e Event)
+}
+
+// Funcs adapts functions to Hooks, the nil ones being ignored.
+type Funcs struct {
+	Write  func(e Event)
+	Expire func(e Event)
+	Evict  func(e Event)
+}
+
+func (f Funcs) OnWrite(e Event) {
+	if f.Write != nil {
+		f.Write(e)
+	}
+}
+
+func (f Funcs) OnExpire(e Event) {
+	if f.Expire != nil {
+		f.Expire(e)
+	}
+}
+
+func (f Funcs) OnEvict(e Event) {
+	if f.Evict != nil {
+		f.Evict(e)
+	}
+}
+
+type registration struct {
+	hooks Hooks
+}
+
+var (
+	mu         sync.Mutex
+	registered atomic.Pointer[[]*registration] // registered is replaced on every change, the dispatcher reads it lock free
+	queue      = make(chan Event, queueSize)
+	startOnce  sync.Once
+	dropped    atomic.Uint64
+)
+
+// Register registers the hooks and returns the function unregistering them. The hooks must be registered
+// before the server is started: the stores created before the first hooks are registered do not report
+// their changes.
+func Register(h Hooks) (unregister func()) {
+	r := &registration{hooks: h}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var current []*registration
+	if p := registered.Load(); p != nil {
+		current = *p
+	}
+	next := append(current[:len(current):len(current)], r)
+	registered.Store(&next)
+	startOnce.Do(func() { go dispatch() })
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		current := *registered.Load()
+		next := make([]*registration, 0, len(current))
+		for _, other := range current {
+			if other != r {
+				next = append(next, other)
+			}
+		}
+		registered.Store(&next)
+	}
+}
+
+// Registered reports whether hooks were registered, the stores then report their changes.
+func Registered() bool {
+	return registered.Load() != nil
+}
+
+// Publish queues the change for the hooks. It is called by the shards and never blocks: the change is
+// dropped if the queue is full.
+func Publish(e Event) {
+	select {
+	case queue <- e:
+	default:
+		dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of changes dropped as the queue was full.
+func Dropped() uint64 {
+	return dropped.Load()
+}
+
+// dispatch calls the hooks with the changes queued, in order.
+func dispatch() {
+	for e := range queue {
+		for _, r := range *registered.Load() {
+			call(r.hooks, e)
+		}
+	}
+}
+
+// call calls the hook of the event. A hook that panics does not stop the delivery of the next changes.
+func call(h Hooks, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("hook panicked", slog.String("key", e.Key), slog.String("cmd", e.Cmd), slog.Any("panic", r))
+		}
+	}()
+
+	switch e.Type {
+	case Write:
+		h.OnWrite(e)
+	case Expire:
+		h.OnExpire(e)
+	case Evict:
+		h.OnEvict(e)
+	}
+}
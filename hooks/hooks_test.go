@@ -0,0 +1,80 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package hooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receive returns the next event received, or fails the test.
+func receive(t *testing.T, events chan Event) Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("the hooks were not called")
+		return Event{}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	writes := make(chan Event, 10)
+	expiries := make(chan Event, 10)
+	unregister := Register(Funcs{
+		Write:  func(e Event) { writes <- e },
+		Expire: func(e Event) { expiries <- e },
+	})
+	assert.True(t, Registered())
+
+	// A hook that panics does not stop the delivery to the others
+	unregisterPanic := Register(Funcs{Write: func(Event) { panic("hook") }})
+	defer unregisterPanic()
+
+	Publish(Event{Type: Write, Key: "a", Cmd: "SET"})
+	Publish(Event{Type: Evict, Key: "a", Cmd: "EVICT"})
+	Publish(Event{Type: Expire, Key: "b", Cmd: "EXPIRED"})
+	Publish(Event{Type: Write, Key: "c", Cmd: "DEL", Deleted: true})
+
+	assert.Equal(t, Event{Type: Write, Key: "a", Cmd: "SET"}, receive(t, writes))
+	assert.Equal(t, Event{Type: Write, Key: "c", Cmd: "DEL", Deleted: true}, receive(t, writes))
+	assert.Equal(t, Event{Type: Expire, Key: "b", Cmd: "EXPIRED"}, receive(t, expiries))
+
+	unregister()
+	Publish(Event{Type: Write, Key: "d", Cmd: "SET"})
+	select {
+	case e := <-writes:
+		t.Fatalf("the hooks unregistered were called for %v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPublishDoesNotBlock(t *testing.T) {
+	block := make(chan struct{})
+	unregister := Register(Funcs{Write: func(Event) { <-block }})
+	defer unregister()
+	defer close(block)
+
+	before := Dropped()
+	for i := 0; i < queueSize+10; i++ {
+		Publish(Event{Type: Write, Key: "k", Cmd: "SET"})
+	}
+	assert.GreaterOrEqual(t, Dropped()-before, uint64(9))
+}
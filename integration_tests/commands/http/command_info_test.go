@@ -31,21 +31,21 @@ func TestCommandInfo(t *testing.T) {
 			commands: []HTTPCommand{
 				{Command: "COMMAND/INFO", Body: map[string]interface{}{"key": "SET"}},
 			},
-			expected: []interface{}{[]interface{}{[]interface{}{"set", float64(-3), float64(1), float64(0), float64(0), interface{}(nil)}}},
+			expected: []interface{}{[]interface{}{[]interface{}{"set", float64(-3), float64(1), float64(0), float64(0), []interface{}{}}}},
 		},
 		{
 			name: "Get command",
 			commands: []HTTPCommand{
 				{Command: "COMMAND/INFO", Body: map[string]interface{}{"key": "GET"}},
 			},
-			expected: []interface{}{[]interface{}{[]interface{}{"get", float64(2), float64(1), float64(0), float64(0), interface{}(nil)}}},
+			expected: []interface{}{[]interface{}{[]interface{}{"get", float64(2), float64(1), float64(0), float64(0), []interface{}{}}}},
 		},
 		{
 			name: "PING command",
 			commands: []HTTPCommand{
 				{Command: "COMMAND/INFO", Body: map[string]interface{}{"key": "PING"}},
 			},
-			expected: []interface{}{[]interface{}{[]interface{}{"ping", float64(-1), float64(0), float64(0), float64(0), interface{}(nil)}}},
+			expected: []interface{}{[]interface{}{[]interface{}{"ping", float64(-1), float64(0), float64(0), float64(0), []interface{}{}}}},
 		},
 		{
 			name: "Combination of multiple valid commands",
@@ -53,8 +53,8 @@ func TestCommandInfo(t *testing.T) {
 				{Command: "COMMAND/INFO", Body: map[string]interface{}{"keys": []interface{}{"SET", "GET"}}},
 			},
 			expected: []interface{}{[]interface{}{
-				[]interface{}{"set", float64(-3), float64(1), float64(0), float64(0), interface{}(nil)},
-				[]interface{}{"get", float64(2), float64(1), float64(0), float64(0), interface{}(nil)},
+				[]interface{}{"set", float64(-3), float64(1), float64(0), float64(0), []interface{}{}},
+				[]interface{}{"get", float64(2), float64(1), float64(0), float64(0), []interface{}{}},
 			}},
 		},
 	}
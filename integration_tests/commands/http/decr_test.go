@@ -99,7 +99,7 @@ func TestDECRBY(t *testing.T) {
 				{Command: "GET", Body: map[string]interface{}{"key": "key1"}},
 				{Command: "GET", Body: map[string]interface{}{"key": "key4"}},
 			},
-			expected: []interface{}{"OK", "OK", float64(1), float64(0), float64(-1), float64(math.MinInt64), "ERR increment or decrement would overflow", "ERR value is not an integer or out of range", float64(0), float64(-1)},
+			expected: []interface{}{"OK", "OK", float64(1), float64(0), float64(-1), float64(math.MinInt64), "ERR decrement would overflow", "ERR value is not an integer or out of range", float64(0), float64(-1)},
 			delays:   []time.Duration{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
 		},
 	}
@@ -92,7 +92,7 @@ func TestHINCRBYFLOAT(t *testing.T) {
 				{Command: "SET", Body: map[string]interface{}{"key": "key", "value": "value"}},
 				{Command: "HINCRBYFLOAT", Body: map[string]interface{}{"key": "key", "field": "field-1", "value": "ten"}},
 			},
-			expected:      []interface{}{"OK", "ERR value is not an integer or a float"},
+			expected:      []interface{}{"OK", "ERR value is not a valid float"},
 			delay:         []time.Duration{0, 0},
 			errorExpected: true,
 		},
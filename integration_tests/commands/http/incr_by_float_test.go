@@ -28,7 +28,7 @@ func TestINCRBYFLOAT(t *testing.T) {
 
 	invalidArgMessage := "ERR wrong number of arguments for 'incrbyfloat' command"
 	invalidIncrTypeMessage := "ERR value is not a valid float"
-	valueOutOfRangeMessage := "ERR value is out of range"
+	valueOutOfRangeMessage := "ERR increment would produce NaN or Infinity"
 
 	testCases := []struct {
 		name     string
@@ -73,7 +73,7 @@ func TestINCRBYFLOAT(t *testing.T) {
 				{Command: "INCRBYFLOAT", Body: map[string]interface{}{"key": "foo", "value": -0.1}},
 				{Command: "GET", Body: map[string]interface{}{"key": "foo"}},
 			},
-			expected: []interface{}{"OK", "1.1", "1.1", "1", "1"},
+			expected: []interface{}{"OK", "1.1", "1.1", "1", float64(1)},
 			delays:   []time.Duration{0, 0, 0, 0, 0},
 		},
 		{
@@ -51,7 +51,7 @@ type HTTPCommandExecutor struct {
 
 func init() {
 	parser := config.NewConfigParser()
-	if err := parser.ParseDefaults(config.DiceConfig); err != nil {
+	if err := parser.ParseDefaults(config.DiceConfig()); err != nil {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
 }
@@ -123,17 +123,17 @@ func (e *HTTPCommandExecutor) Name() string {
 }
 
 func RunHTTPServer(ctx context.Context, wg *sync.WaitGroup, opt TestServerOptions) {
-	config.DiceConfig.Network.IOBufferLength = 16
-	config.DiceConfig.Persistence.WriteAOFOnCleanup = false
+	config.DiceConfig().Network.IOBufferLength = 16
+	config.DiceConfig().Persistence.WriteAOFOnCleanup = false
 
 	globalErrChannel := make(chan error)
 	shardManager := shard.NewShardManager(1, nil, globalErrChannel)
 
-	config.DiceConfig.HTTP.Port = opt.Port
+	config.DiceConfig().HTTP.Port = opt.Port
 	// Initialize the HTTPServer
 	testServer := httpws.NewHTTPServer(shardManager, nil)
 	// Inform the user that the server is starting
-	fmt.Println("Starting the test server on port", config.DiceConfig.HTTP.Port)
+	fmt.Println("Starting the test server on port", config.DiceConfig().HTTP.Port)
 	shardManagerCtx, cancelShardManager := context.WithCancel(ctx)
 	wg.Add(1)
 	go func() {
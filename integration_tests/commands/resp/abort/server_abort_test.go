@@ -35,8 +35,8 @@ var testServerOptions = resp.TestServerOptions{
 }
 
 func init() {
-	config.DiceConfig.RespServer.Port = testServerOptions.Port
-	log.Print("Setting port to ", config.DiceConfig.RespServer.Port)
+	config.DiceConfig().RespServer.Port = testServerOptions.Port
+	log.Print("Setting port to ", config.DiceConfig().RespServer.Port)
 }
 
 func TestAbortCommand(t *testing.T) {
@@ -51,7 +51,7 @@ func TestAbortCommand(t *testing.T) {
 
 	// Test 1: Ensure the server is running
 	t.Run("ServerIsRunning", func(t *testing.T) {
-		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig.RespServer.Port))
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig().RespServer.Port))
 		if err != nil {
 			t.Fatalf("Failed to connect to server: %v", err)
 		}
@@ -60,7 +60,7 @@ func TestAbortCommand(t *testing.T) {
 
 	//Test 2: Send ABORT command and check if the server shuts down
 	t.Run("AbortCommandShutdown", func(t *testing.T) {
-		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig.RespServer.Port))
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig().RespServer.Port))
 		if err != nil {
 			t.Fatalf("Failed to connect to server: %v", err)
 		}
@@ -76,7 +76,7 @@ func TestAbortCommand(t *testing.T) {
 		time.Sleep(1 * time.Second)
 
 		// Try to connect again, it should fail
-		_, err = net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig.RespServer.Port))
+		_, err = net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig().RespServer.Port))
 		if err == nil {
 			t.Fatal("Server did not shut down as expected")
 		}
@@ -85,7 +85,7 @@ func TestAbortCommand(t *testing.T) {
 	// Test 3: Ensure the server port is released
 	t.Run("PortIsReleased", func(t *testing.T) {
 		// Try to bind to the same port
-		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig.RespServer.Port))
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig().RespServer.Port))
 		if err != nil {
 			t.Fatalf("Port should be available after server shutdown: %v", err)
 		}
@@ -106,7 +106,7 @@ func TestServerRestartAfterAbort(t *testing.T) {
 
 	time.Sleep(1 * time.Second)
 
-	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig.RespServer.Port))
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig().RespServer.Port))
 	if err != nil {
 		t.Fatalf("Server should be running after restart: %v", err)
 	}
@@ -137,7 +137,7 @@ func TestServerRestartAfterAbort(t *testing.T) {
 	time.Sleep(2 * time.Second)
 
 	// Check if the server is running
-	conn2, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig.RespServer.Port))
+	conn2, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig().RespServer.Port))
 	if err != nil {
 		t.Fatalf("Server should be running after restart: %v", err)
 	}
@@ -123,6 +123,12 @@ func TestAPPEND(t *testing.T) {
 			expected: []interface{}{int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(3), "421"},
 			cleanup:  []string{"del bitkey"},
 		},
+		{
+			name:     "APPEND repeatedly to the same key",
+			commands: []string{"SET k ab", "APPEND k cd", "APPEND k ef", "APPEND k gh", "GET k", "GETRANGE k 2 5"},
+			expected: []interface{}{"OK", int64(4), int64(6), int64(8), "abcdefgh", "cdef"},
+			cleanup:  []string{"DEL k"},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
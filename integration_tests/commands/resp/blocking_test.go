@@ -0,0 +1,142 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingCommand fires the blocking command on a new connection in the background, and waits for the
+// client to be blocked.
+func blockingCommand(cmd string) chan interface{} {
+	reply := make(chan interface{}, 1)
+	go func() {
+		conn := getLocalConnection()
+		defer conn.Close()
+		reply <- FireCommand(conn, cmd)
+	}()
+
+	// Only the clients blocked before are in line before this one
+	time.Sleep(100 * time.Millisecond)
+	return reply
+}
+
+func TestBlockingPops(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		cleanup  []string
+	}{
+		{
+			name:     "BLPOP and BRPOP pop right away from the first non empty list",
+			commands: []string{"RPUSH l2 a b c", "BLPOP l1 l2 0", "BRPOP l1 l2 0"},
+			expected: []interface{}{int64(3), []interface{}{"l2", "a"}, []interface{}{"l2", "c"}},
+			cleanup:  []string{"DEL l2"},
+		},
+		{
+			name:     "BZPOPMIN and BZPOPMAX pop right away from the first non empty sorted set",
+			commands: []string{"ZADD z2 1 a 2 b 3 c", "BZPOPMIN z1 z2 0", "BZPOPMAX z1 z2 0"},
+			expected: []interface{}{int64(3), []interface{}{"z2", "a", "1"}, []interface{}{"z2", "c", "3"}},
+			cleanup:  []string{"DEL z2"},
+		},
+		{
+			name:     "BLPOP times out",
+			commands: []string{"BLPOP l1 0.1", "BZPOPMIN z1 0.1"},
+			expected: []interface{}{"(nil)", "(nil)"},
+		},
+		{
+			name:     "BLPOP on wrong key type",
+			commands: []string{"SET l1 v", "BLPOP l1 0"},
+			expected: []interface{}{"OK", "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			cleanup:  []string{"DEL l1"},
+		},
+		{
+			name:     "BLPOP with invalid arguments",
+			commands: []string{"BLPOP l1", "BLPOP l1 soon", "BLPOP l1 -1"},
+			expected: []interface{}{
+				"ERR wrong number of arguments for 'blpop' command",
+				"ERR timeout is not a float or out of range",
+				"ERR timeout is negative",
+			},
+		},
+		{
+			name:     "BLPOP is refused in a transaction",
+			commands: []string{"MULTI", "BLPOP l1 0", "DISCARD"},
+			expected: []interface{}{"OK", "ERR 'blpop' is not allowed inside a transaction", "OK"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+			for _, cmd := range tc.cleanup {
+				FireCommand(conn, cmd)
+			}
+		})
+	}
+}
+
+func TestBlockedClients(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	t.Run("a push serves the blocked client", func(t *testing.T) {
+		reply := blockingCommand("BLPOP waitlist 5")
+		assert.Equal(t, int64(1), FireCommand(conn, "LPUSH waitlist a"))
+		assert.Equal(t, []interface{}{"waitlist", "a"}, <-reply)
+		assert.Equal(t, int64(0), FireCommand(conn, "LLEN waitlist"))
+	})
+
+	t.Run("the clients are served in the order they blocked", func(t *testing.T) {
+		first := blockingCommand("BLPOP waitlist 5")
+		second := blockingCommand("BRPOP other waitlist 5")
+		assert.Equal(t, int64(2), FireCommand(conn, "RPUSH waitlist a b"))
+		assert.Equal(t, []interface{}{"waitlist", "a"}, <-first)
+		assert.Equal(t, []interface{}{"waitlist", "b"}, <-second)
+	})
+
+	t.Run("a ZADD serves the blocked client", func(t *testing.T) {
+		reply := blockingCommand("BZPOPMAX waitzset 5")
+		assert.Equal(t, int64(2), FireCommand(conn, "ZADD waitzset 1 a 2 b"))
+		assert.Equal(t, []interface{}{"waitzset", "b", "2"}, <-reply)
+		FireCommand(conn, "DEL waitzset")
+	})
+
+	t.Run("a disconnected client stops waiting", func(t *testing.T) {
+		gone := getLocalConnection()
+		// The reply is never read, the client disconnects while blocked
+		_, err := gone.Write([]byte("*3\r\n$5\r\nBLPOP\r\n$8\r\nwaitlist\r\n$1\r\n0\r\n"))
+		assert.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+		gone.Close()
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(t, int64(1), FireCommand(conn, "LPUSH waitlist a"))
+		assert.Equal(t, int64(1), FireCommand(conn, "LLEN waitlist"))
+		FireCommand(conn, "DEL waitlist")
+	})
+}
@@ -0,0 +1,144 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCAS(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		cleanup  []string
+	}{
+		{
+			name:     "CAS sets a key holding the expected value",
+			commands: []string{"SET k old EX 100", "CAS k old new", "GET k", "TTL k"},
+			expected: []interface{}{"OK", int64(1), "new", int64(-1)},
+			cleanup:  []string{"DEL k"},
+		},
+		{
+			name:     "CAS keeps a key holding another value",
+			commands: []string{"SET k other", "CAS k old new", "GET k"},
+			expected: []interface{}{"OK", int64(0), "other"},
+			cleanup:  []string{"DEL k"},
+		},
+		{
+			name:     "CAS on a missing key",
+			commands: []string{"CAS k old new", "EXISTS k"},
+			expected: []interface{}{int64(0), int64(0)},
+		},
+		{
+			name:     "CAS on wrong key type",
+			commands: []string{"LPUSH k old", "CAS k old new"},
+			expected: []interface{}{int64(1), "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			cleanup:  []string{"DEL k"},
+		},
+		{
+			name:     "CAS with wrong number of arguments",
+			commands: []string{"CAS k old"},
+			expected: []interface{}{"ERR wrong number of arguments for 'cas' command"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+
+			for _, cmd := range tc.cleanup {
+				FireCommand(conn, cmd)
+			}
+		})
+	}
+}
+
+func TestMSETCAS(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		cleanup  []string
+	}{
+		{
+			name:     "MSETCAS sets the keys when every checked key holds its value",
+			commands: []string{"MSET a x b y", "MSETCAS 2 a x b y a x2 c z2", "MGET a b c"},
+			expected: []interface{}{"OK", int64(1), []interface{}{"x2", "y", "z2"}},
+			cleanup:  []string{"DEL a b c"},
+		},
+		{
+			name:     "MSETCAS sets no key when a checked key holds another value",
+			commands: []string{"MSET a x b other", "MSETCAS 2 a x b y a x2 c z2", "MGET a b c"},
+			expected: []interface{}{"OK", int64(0), []interface{}{"x", "other", "(nil)"}},
+			cleanup:  []string{"DEL a b c"},
+		},
+		{
+			name:     "MSETCAS sets no key when a checked key is missing",
+			commands: []string{"SET a x", "MSETCAS 2 a x b y a x2", "GET a"},
+			expected: []interface{}{"OK", int64(0), "x"},
+			cleanup:  []string{"DEL a"},
+		},
+		{
+			name:     "MSETCAS on wrong key type",
+			commands: []string{"LPUSH a 1", "MSETCAS 1 a 1 b 2", "EXISTS b"},
+			expected: []interface{}{int64(1), "WRONGTYPE Operation against a key holding the wrong kind of value", int64(0)},
+			cleanup:  []string{"DEL a"},
+		},
+		{
+			name: "MSETCAS with invalid arguments",
+			commands: []string{"MSETCAS 1 a 1", "MSETCAS 1 a 1 b", "MSETCAS x a 1 b 2", "MSETCAS 0 a 1 b 2",
+				"MSETCAS 2 a 1 b 2"},
+			expected: []interface{}{
+				"ERR wrong number of arguments for 'msetcas' command",
+				"ERR wrong number of arguments for 'msetcas' command",
+				"ERR value is not an integer or out of range",
+				"ERR numchecks should be greater than 0",
+				"ERR numchecks leaves no key to set",
+			},
+		},
+		{
+			name:     "MSETCAS is refused in a transaction",
+			commands: []string{"MULTI", "MSETCAS 1 a 1 b 2", "DISCARD"},
+			expected: []interface{}{"OK", "ERR 'msetcas' is not allowed inside a transaction", "OK"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+
+			for _, cmd := range tc.cleanup {
+				FireCommand(conn, cmd)
+			}
+		})
+	}
+}
@@ -31,8 +31,7 @@ var getKeysTestCases = []struct {
 	{"Get command", "get key", []interface{}{"key"}},
 	{"TTL command", "ttl key", []interface{}{"key"}},
 	{"Del command", "del 1 2 3 4 5 6", []interface{}{"1", "2", "3", "4", "5", "6"}},
-	// TODO: Fix this for multi shard support
-	//{"MSET command", "MSET key1 val1 key2 val2", []interface{}{"key1", "key2"}},
+	{"MSET command", "MSET key1 val1 key2 val2", []interface{}{"key1", "key2"}},
 	{"Expire command", "expire key time extra", []interface{}{"key"}},
 	{"Ping command", "ping", "ERR the command has no key arguments"},
 	{"Invalid Get command", "get", "ERR invalid number of arguments specified for command"},
@@ -0,0 +1,83 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	defer FireCommand(conn, "CONFIG SET maxmemory 0")
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+	}{
+		{
+			name:     "CONFIG with invalid number of arguments",
+			commands: []string{"CONFIG"},
+			expected: []interface{}{"ERR wrong number of arguments for 'config' command"},
+		},
+		{
+			name:     "CONFIG with unknown subcommand",
+			commands: []string{"CONFIG FOO"},
+			expected: []interface{}{"ERR unknown subcommand 'FOO'. Try CONFIG HELP."},
+		},
+		{
+			name:     "CONFIG SET and GET by alias and config key",
+			commands: []string{"CONFIG SET maxmemory 1024", "CONFIG GET maxmemory", "CONFIG GET memory.max_memory"},
+			expected: []interface{}{"OK", []interface{}{"maxmemory", "1024"}, []interface{}{"memory.max_memory", "1024"}},
+		},
+		{
+			name:     "CONFIG GET with glob pattern",
+			commands: []string{"CONFIG GET websocket.max_write_*"},
+			expected: []interface{}{[]interface{}{"websocket.max_write_response_retries", "3"}},
+		},
+		{
+			name:     "CONFIG SET immutable parameter",
+			commands: []string{"CONFIG SET async_server.port 1234"},
+			expected: []interface{}{"ERR CONFIG SET failed - can't set immutable config 'async_server.port'"},
+		},
+		{
+			name:     "CONFIG SET unknown parameter",
+			commands: []string{"CONFIG SET foo bar"},
+			expected: []interface{}{"ERR CONFIG SET failed - unknown parameter 'foo'"},
+		},
+		{
+			name:     "CONFIG SET invalid value",
+			commands: []string{"CONFIG SET maxmemory -1", "CONFIG GET maxmemory"},
+			expected: []interface{}{
+				"ERR CONFIG SET failed - invalid value for 'memory.max_memory': failed on 'min' validation",
+				[]interface{}{"maxmemory", "1024"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAndPipelinedCommands(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	rp := clientio.NewRESPParser(conn)
+
+	t.Run("a command split across writes is executed once whole", func(t *testing.T) {
+		frame := "*3\r\n$3\r\nSET\r\n$5\r\nsplit\r\n$6\r\nvalue!\r\n"
+		for _, part := range []string{frame[:2], frame[2:13], frame[13:30], frame[30:]} {
+			_, err := conn.Write([]byte(part))
+			require.NoError(t, err)
+			time.Sleep(20 * time.Millisecond)
+		}
+		v, err := rp.DecodeOne()
+		require.NoError(t, err)
+		assert.Equal(t, "OK", v)
+		assert.Equal(t, "value!", FireCommand(conn, "GET split"))
+	})
+
+	t.Run("pipelined commands are all executed in order", func(t *testing.T) {
+		_, err := conn.Write([]byte("*3\r\n$3\r\nSET\r\n$4\r\npipe\r\n$1\r\n1\r\n*2\r\n$4\r\nINCR\r\n$4\r\npipe\r\n*2\r\n$3\r\nGET\r\n$4\r\npi"))
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		_, err = conn.Write([]byte("pe\r\n"))
+		require.NoError(t, err)
+
+		for _, expected := range []interface{}{"OK", int64(2), int64(2)} {
+			v, err := rp.DecodeOne()
+			require.NoError(t, err)
+			assert.Equal(t, expected, v)
+		}
+	})
+
+	FireCommand(conn, "DEL split pipe")
+}
+
+func TestQuit(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	rp := clientio.NewRESPParser(conn)
+
+	// The commands pipelined after QUIT are not executed
+	_, err := conn.Write([]byte("*3\r\n$3\r\nSET\r\n$4\r\nquit\r\n$1\r\n1\r\n*1\r\n$4\r\nQUIT\r\n*2\r\n$3\r\nDEL\r\n$4\r\nquit\r\n"))
+	require.NoError(t, err)
+	for _, expected := range []interface{}{"OK", "OK"} {
+		v, err := rp.DecodeOne()
+		require.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+	_, err = rp.DecodeOne()
+	assert.ErrorIs(t, err, io.EOF)
+
+	other := getLocalConnection()
+	defer other.Close()
+	assert.Equal(t, int64(1), FireCommand(other, "GET quit"))
+	FireCommand(other, "DEL quit")
+}
+
+func TestReset(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	assert.Equal(t, "OK", FireCommand(conn, "MULTI"))
+	assert.Equal(t, "QUEUED", FireCommand(conn, "SET reset 1"))
+	assert.Equal(t, "RESET", FireCommand(conn, "RESET"))
+	assert.Equal(t, "ERR EXEC without MULTI", FireCommand(conn, "EXEC"))
+	assert.Equal(t, "(nil)", FireCommand(conn, "GET reset"))
+	assert.Equal(t, "ERR wrong number of arguments for 'reset' command", FireCommand(conn, "RESET now"))
+}
+
+func TestProtocolError(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	rp := clientio.NewRESPParser(conn)
+
+	// The rest of an invalid stream is not executed, the connection is closed
+	_, err := conn.Write([]byte("*2\r\n$3\r\nGET\r\n$x\r\n*3\r\n$3\r\nSET\r\n$5\r\nproto\r\n$1\r\n1\r\n"))
+	require.NoError(t, err)
+	v, err := rp.DecodeOne()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(v.(string), "ERR Protocol error"), v)
+	_, err = rp.DecodeOne()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// A client closing in the middle of a command does not execute it
+	gone := getLocalConnection()
+	_, err = gone.Write([]byte("*3\r\n$3\r\nSET\r\n$5\r\nproto\r\n$1\r\n"))
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	gone.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	other := getLocalConnection()
+	defer other.Close()
+	assert.Equal(t, "(nil)", FireCommand(other, "GET proto"))
+}
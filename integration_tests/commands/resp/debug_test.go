@@ -0,0 +1,146 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugQuick(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	t.Run("DEBUG QUICK reports every check", func(t *testing.T) {
+		report, ok := FireCommand(conn, "DEBUG QUICK").([]interface{})
+		assert.True(t, ok)
+
+		checks := make([]interface{}, 0, len(report))
+		for _, entry := range report {
+			check := entry.([]interface{})
+			assert.Len(t, check, 3)
+			assert.Contains(t, []interface{}{"ok", "warn", "fail"}, check[1])
+			checks = append(checks, check[0])
+		}
+		assert.Equal(t, []interface{}{"config", "directories", "memory", "clock"}, checks)
+	})
+
+	t.Run("DEBUG with invalid arguments", func(t *testing.T) {
+		assert.Equal(t, "ERR wrong number of arguments for 'debug' command", FireCommand(conn, "DEBUG"))
+		assert.Equal(t, "ERR wrong number of arguments for 'debug|quick' command", FireCommand(conn, "DEBUG QUICK now"))
+		assert.Equal(t, "ERR unknown subcommand 'FOO'. Try DEBUG HELP.", FireCommand(conn, "DEBUG FOO"))
+	})
+}
+
+func TestDebugReload(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	FireCommand(conn, "SET reload:string hello")
+	FireCommand(conn, "SET reload:int 42")
+	FireCommand(conn, "SETBIT reload:bytearray 7 1")
+	FireCommand(conn, `JSON.SET reload:json $ {"a":1,"b":[1,2]}`)
+	FireCommand(conn, "SADD reload:set a b c d")
+	FireCommand(conn, "ZADD reload:zset 1 a 2 b 3 c")
+	FireCommand(conn, "RPUSH reload:list a b c")
+	FireCommand(conn, "BF.RESERVE reload:bloom 0.01 1000")
+	FireCommand(conn, "BF.ADD reload:bloom a")
+	FireCommand(conn, "CMS.INITBYDIM reload:cms 10 5")
+	FireCommand(conn, "SET reload:ttl value EX 100")
+
+	t.Run("DEBUG RELOAD VERIFY reports every type as round-tripped", func(t *testing.T) {
+		report, ok := FireCommand(conn, "DEBUG RELOAD VERIFY").([]interface{})
+		assert.True(t, ok)
+
+		types := make([]interface{}, 0, len(report))
+		for _, entry := range report {
+			check := entry.([]interface{})
+			assert.Equal(t, "ok", check[1], check)
+			types = append(types, check[0])
+		}
+		assert.Equal(t, []interface{}{"bloom", "bytearray", "cms", "int", "json", "list", "set", "string", "zset"}, types)
+	})
+
+	t.Run("DEBUG RELOAD keeps the values and the TTLs", func(t *testing.T) {
+		assert.Equal(t, "OK", FireCommand(conn, "DEBUG RELOAD"))
+		assert.Equal(t, "hello", FireCommand(conn, "GET reload:string"))
+		assert.Equal(t, int64(43), FireCommand(conn, "INCR reload:int"))
+		assert.Equal(t, []interface{}{"a", "b", "c"}, FireCommand(conn, "LRANGE reload:list 0 -1"))
+		assert.Equal(t, []interface{}{"a", "b", "c"}, FireCommand(conn, "ZRANGE reload:zset 0 -1"))
+		assert.Equal(t, int64(4), FireCommand(conn, "SCARD reload:set"))
+		assert.Equal(t, int64(1), FireCommand(conn, "BF.EXISTS reload:bloom a"))
+		ttl := FireCommand(conn, "TTL reload:ttl").(int64)
+		assert.True(t, ttl > 0 && ttl <= 100)
+		assert.Equal(t, int64(10), FireCommand(conn, "DBSIZE"))
+	})
+
+	t.Run("DEBUG RELOAD VERIFY reports the types that can't be persisted", func(t *testing.T) {
+		FireCommand(conn, "HSET reload:hash field value")
+		report := FireCommand(conn, "DEBUG RELOAD VERIFY").([]interface{})
+		assert.Contains(t, report, []interface{}{"hash", "unsupported", "1 keys kept in memory"})
+		assert.Equal(t, "value", FireCommand(conn, "HGET reload:hash field"))
+	})
+
+	t.Run("DEBUG RELOAD with invalid arguments", func(t *testing.T) {
+		assert.Equal(t, "ERR wrong number of arguments for 'debug|reload' command", FireCommand(conn, "DEBUG RELOAD VERIFY now"))
+		assert.Equal(t, "ERR wrong number of arguments for 'debug|reload' command", FireCommand(conn, "DEBUG RELOAD NOW"))
+	})
+}
+
+func TestDebugLatencyTest(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+	FireCommand(conn, "SET latency:kept value")
+
+	t.Run("DEBUG LATENCY-TEST reports the latencies of the mix", func(t *testing.T) {
+		report, ok := FireCommand(conn, "DEBUG LATENCY-TEST REQUESTS 500 KEYSPACE 20 MIX set:1,lpush:1").([]interface{})
+		assert.True(t, ok)
+		assert.Len(t, report, 12)
+		assert.Equal(t, []interface{}{"requests", int64(500), "errors", int64(0)}, report[:4])
+		assert.Equal(t, "latency_us", report[8])
+
+		summary := report[9].([]interface{})
+		assert.Equal(t, []interface{}{"min", "p50", "p90", "p99", "p99.9", "max"},
+			[]interface{}{summary[0], summary[2], summary[4], summary[6], summary[8], summary[10]})
+		assert.LessOrEqual(t, summary[1].(int64), summary[11].(int64))
+
+		commands := report[11].([]interface{})
+		assert.Len(t, commands, 2)
+		set, lpush := commands[0].([]interface{}), commands[1].([]interface{})
+		assert.Equal(t, "SET", set[0])
+		assert.Equal(t, "LPUSH", lpush[0])
+		assert.Equal(t, int64(500), set[1].(int64)+lpush[1].(int64))
+	})
+
+	t.Run("DEBUG LATENCY-TEST deletes the keys of the scratch namespace", func(t *testing.T) {
+		assert.Equal(t, []interface{}{"latency:kept"}, FireCommand(conn, "KEYS *"))
+	})
+
+	t.Run("DEBUG LATENCY-TEST with invalid arguments", func(t *testing.T) {
+		assert.Equal(t, "ERR syntax error", FireCommand(conn, "DEBUG LATENCY-TEST REQUESTS"))
+		assert.Equal(t, "ERR syntax error", FireCommand(conn, "DEBUG LATENCY-TEST ROUNDS 10"))
+		assert.Equal(t, "ERR value is not an integer or out of range", FireCommand(conn, "DEBUG LATENCY-TEST REQUESTS many"))
+		assert.Equal(t, "ERR REQUESTS must be between 1 and 1000000", FireCommand(conn, "DEBUG LATENCY-TEST REQUESTS 0"))
+		assert.Equal(t, "ERR MSET can not be part of the mix, the commands of the mix must have a single key",
+			FireCommand(conn, "DEBUG LATENCY-TEST MIX set,mset"))
+	})
+}
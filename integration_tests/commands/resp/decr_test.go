@@ -120,7 +120,7 @@ func TestDECRBY(t *testing.T) {
 				{"key1", int64(1), 0, utils.EmptyStr},
 				{"key4", int64(1), -1, utils.EmptyStr},
 				{"key3", int64(1), math.MinInt64, utils.EmptyStr},
-				{"key3", int64(math.MinInt64), 0, "ERR increment or decrement would overflow"},
+				{"key3", int64(math.MinInt64), 0, "ERR decrement would overflow"},
 				{"key5", "abc", 0, "ERR value is not an integer or out of range"},
 			},
 			getCommands: []GetCommand{
@@ -0,0 +1,142 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDELIFEQ(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		cleanup  []string
+	}{
+		{
+			name:     "DELIFEQ releases a lock held with the token",
+			commands: []string{"SET lock token NX PX 10000", "DELIFEQ lock token", "EXISTS lock"},
+			expected: []interface{}{"OK", int64(1), int64(0)},
+			cleanup:  []string{"DEL lock"},
+		},
+		{
+			name:     "DELIFEQ keeps a lock held with another token",
+			commands: []string{"SET lock other NX PX 10000", "DELIFEQ lock token", "GET lock"},
+			expected: []interface{}{"OK", int64(0), "other"},
+			cleanup:  []string{"DEL lock"},
+		},
+		{
+			name:     "DELIFEQ on a missing key",
+			commands: []string{"DELIFEQ lock token"},
+			expected: []interface{}{int64(0)},
+		},
+		{
+			name:     "DELIFEQ on an integer value",
+			commands: []string{"SET lock 42", "DELIFEQ lock 042", "DELIFEQ lock 42"},
+			expected: []interface{}{"OK", int64(0), int64(1)},
+			cleanup:  []string{"DEL lock"},
+		},
+		{
+			name:     "DELIFEQ on wrong key type",
+			commands: []string{"LPUSH lock token", "DELIFEQ lock token"},
+			expected: []interface{}{int64(1), "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			cleanup:  []string{"DEL lock"},
+		},
+		{
+			name:     "DELIFEQ with wrong number of arguments",
+			commands: []string{"DELIFEQ lock", "DELIFEQ lock token extra"},
+			expected: []interface{}{
+				"ERR wrong number of arguments for 'delifeq' command",
+				"ERR wrong number of arguments for 'delifeq' command",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+
+			for _, cmd := range tc.cleanup {
+				FireCommand(conn, cmd)
+			}
+		})
+	}
+}
+
+func TestPEXPIREIFEQ(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		cleanup  []string
+	}{
+		{
+			name:     "PEXPIREIFEQ extends a lock held with the token",
+			commands: []string{"SET lock token NX PX 100", "PEXPIREIFEQ lock token 100000", "PERSIST lock"},
+			expected: []interface{}{"OK", int64(1), int64(1)},
+			cleanup:  []string{"DEL lock"},
+		},
+		{
+			name:     "PEXPIREIFEQ leaves a lock held with another token",
+			commands: []string{"SET lock other", "PEXPIREIFEQ lock token 100000", "TTL lock"},
+			expected: []interface{}{"OK", int64(0), int64(-1)},
+			cleanup:  []string{"DEL lock"},
+		},
+		{
+			name:     "PEXPIREIFEQ on a missing key",
+			commands: []string{"PEXPIREIFEQ lock token 100000"},
+			expected: []interface{}{int64(0)},
+		},
+		{
+			name:     "PEXPIREIFEQ with an invalid expiry",
+			commands: []string{"PEXPIREIFEQ lock token ten", "PEXPIREIFEQ lock token -1"},
+			expected: []interface{}{
+				"ERR value is not an integer or out of range",
+				"ERR invalid expire time in 'pexpireifeq' command",
+			},
+		},
+		{
+			name:     "PEXPIREIFEQ with wrong number of arguments",
+			commands: []string{"PEXPIREIFEQ lock token"},
+			expected: []interface{}{"ERR wrong number of arguments for 'pexpireifeq' command"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+
+			for _, cmd := range tc.cleanup {
+				FireCommand(conn, cmd)
+			}
+		})
+	}
+}
@@ -258,6 +258,56 @@ func TestEXPIRE(t *testing.T) {
 	}
 }
 
+func TestPEXPIRE(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		delay    []time.Duration
+	}{
+		{
+			name:     "PEXPIRE expires the key after the milliseconds",
+			commands: []string{"SET test_key test_value", "PEXPIRE test_key 200", "GET test_key", "GET test_key"},
+			expected: []interface{}{"OK", int64(1), "test_value", "(nil)"},
+			delay:    []time.Duration{0, 0, 0, 300 * time.Millisecond},
+		},
+		{
+			name:     "PEXPIRE sets a TTL in milliseconds",
+			commands: []string{"SET test_key test_value", "PEXPIRE test_key 10500", "TTL test_key"},
+			expected: []interface{}{"OK", int64(1), int64(10)},
+			delay:    []time.Duration{0, 0, 0},
+		},
+		{
+			name:     "PEXPIRE non-existent key",
+			commands: []string{"PEXPIRE non_existent_key 1000"},
+			expected: []interface{}{int64(0)},
+			delay:    []time.Duration{0},
+		},
+		{
+			name:     "PEXPIRE with invalid expiry",
+			commands: []string{"SET test_key test_value", "PEXPIRE test_key -1", "PEXPIRE test_key ten", "PEXPIRE test_key"},
+			expected: []interface{}{"OK", "ERR invalid expire time in 'pexpire' command",
+				"ERR value is not an integer or out of range", "ERR wrong number of arguments for 'pexpire' command"},
+			delay: []time.Duration{0, 0, 0, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer FireCommand(conn, "DEL test_key")
+			for i, cmd := range tc.commands {
+				if tc.delay[i] > 0 {
+					time.Sleep(tc.delay[i])
+				}
+				assert.DeepEqual(t, tc.expected[i], FireCommand(conn, cmd))
+			}
+		})
+	}
+}
+
 func TestEXPIREAT(t *testing.T) {
 	conn := getLocalConnection()
 	defer conn.Close()
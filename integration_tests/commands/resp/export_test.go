@@ -0,0 +1,86 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEXPORT(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "DEL export:str export:list export:hash export:zset")
+	defer FireCommand(conn, "DEL export:str export:list export:hash export:zset")
+	FireCommand(conn, "SET export:str value")
+	FireCommand(conn, "RPUSH export:list a b")
+	FireCommand(conn, "HSET export:hash f v")
+	FireCommand(conn, "ZADD export:zset 1 m")
+
+	testCases := []struct {
+		name     string
+		command  string
+		expected interface{}
+	}{
+		{
+			name:    "EXPORT as JSON Lines filtered by type",
+			command: "EXPORT MATCH export:* TYPE list",
+			expected: []interface{}{
+				`{"key":"export:list","type":"list","ttl":-1,"value":["a","b"]}`,
+			},
+		},
+		{
+			name:    "EXPORT as CSV",
+			command: "EXPORT MATCH export:[hz]* FORMAT CSV",
+			expected: []interface{}{
+				"key,type,ttl,value",
+				`export:hash,hash,-1,"{""f"":""v""}"`,
+				`export:zset,zset,-1,"[{""member"":""m"",""score"":1}]"`,
+			},
+		},
+		{
+			name:     "EXPORT of no key",
+			command:  "EXPORT MATCH export:none:*",
+			expected: []interface{}{},
+		},
+		{
+			name:     "EXPORT with an unknown type",
+			command:  "EXPORT TYPE stream",
+			expected: "ERR unknown type stream",
+		},
+		{
+			name:     "EXPORT with an invalid option",
+			command:  "EXPORT MATCH",
+			expected: "ERR syntax error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := FireCommand(conn, tc.command)
+			if lines, ok := result.([]interface{}); ok && len(lines) > 1 && lines[0] == "key,type,ttl,value" {
+				// The shards are exported one after the other, the keys of different shards come in any order
+				assert.Equal(t, tc.expected.([]interface{})[0], lines[0])
+				assert.ElementsMatch(t, tc.expected.([]interface{})[1:], lines[1:])
+				return
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -43,6 +43,27 @@ func TestFLUSHDB(t *testing.T) {
 			delay:    []time.Duration{0, 0, 0},
 			cleanUp:  []string{"DEL k1 k2 k3"},
 		},
+		{
+			name:     "FLUSHDB ASYNC",
+			setup:    []string{"MSET k1 v1 k2 v2 k3 v3"},
+			commands: []string{"FLUSHDB ASYNC", "DBSIZE", "SET k1 v4", "GET k1"},
+			expected: []interface{}{"OK", int64(0), "OK", "v4"},
+			delay:    []time.Duration{0, 0, 0, 0},
+			cleanUp:  []string{"DEL k1"},
+		},
+		{
+			name:     "FLUSHALL SYNC",
+			setup:    []string{"MSET k1 v1 k2 v2 k3 v3"},
+			commands: []string{"FLUSHALL SYNC", "DBSIZE"},
+			expected: []interface{}{"OK", int64(0)},
+			delay:    []time.Duration{0, 0},
+		},
+		{
+			name:     "FLUSHDB with an invalid mode",
+			commands: []string{"FLUSHDB LATER", "FLUSHALL ASYNC SYNC"},
+			expected: []interface{}{"ERR syntax error", "ERR wrong number of arguments for 'flushall' command"},
+			delay:    []time.Duration{0, 0},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -72,7 +72,7 @@ func TestGETUNWATCH(t *testing.T) {
 		if !ok {
 			t.Errorf("Type assertion to []interface{} failed for value: %v", v)
 		}
-		assert.Equal(t, 3, len(castedValue))
+		assert.Equal(t, 4, len(castedValue))
 	}
 
 	//	Fire updates to the key using the publisher, then check if the subscribers receive the updates in the push-response form (i.e. array of three elements, with third element being the value)
@@ -88,7 +88,7 @@ func TestGETUNWATCH(t *testing.T) {
 				t.Errorf("Type assertion to []interface{} failed for value: %v", v)
 			}
 			fmt.Println(castedValue)
-			assert.Equal(t, 3, len(castedValue))
+			assert.Equal(t, 4, len(castedValue))
 			assert.Equal(t, "GET", castedValue[0])
 			assert.Equal(t, "426696421", castedValue[1])
 			assert.Equal(t, tc.val, castedValue[2])
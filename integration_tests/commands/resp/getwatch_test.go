@@ -74,11 +74,12 @@ func TestGETWATCH(t *testing.T) {
 		if !ok {
 			t.Errorf("Type assertion to []interface{} failed for value: %v", v)
 		}
-		assert.Equal(t, 3, len(castedValue))
+		assert.Equal(t, 4, len(castedValue))
+		assert.Equal(t, int64(0), castedValue[3]) // the initial result is push 0
 	}
 
 	//	Fire updates to the key using the publisher, then check if the subscribers receive the updates in the push-response form (i.e. array of three elements, with third element being the value)
-	for _, tc := range getWatchTestCases {
+	for i, tc := range getWatchTestCases {
 		res := FireCommand(publisher, fmt.Sprintf("SET %s %s", tc.key, tc.val))
 		assert.Equal(t, "OK", res)
 
@@ -89,10 +90,11 @@ func TestGETWATCH(t *testing.T) {
 			if !ok {
 				t.Errorf("Type assertion to []interface{} failed for value: %v", v)
 			}
-			assert.Equal(t, 3, len(castedValue))
+			assert.Equal(t, 4, len(castedValue))
 			assert.Equal(t, "GET", castedValue[0])
 			assert.Equal(t, "2714318480", castedValue[1])
 			assert.Equal(t, tc.val, castedValue[2])
+			assert.Equal(t, int64(i+1), castedValue[3]) // sequence number of the push
 		}
 	}
 
@@ -100,6 +102,35 @@ func TestGETWATCH(t *testing.T) {
 	unsubscribeFromWatchUpdates(t, subscribers, "GET", "2714318480")
 }
 
+func TestGETWATCHMaxLag(t *testing.T) {
+	publisher := getLocalConnection()
+	subscribers := []net.Conn{getLocalConnection()}
+
+	defer func() {
+		err := ClosePublisherSubscribers(publisher, subscribers)
+		assert.Nil(t, err)
+	}()
+
+	FireCommand(publisher, fmt.Sprintf("SET %s %s", getWatchKey, "value"))
+
+	assert.Equal(t, "ERR MAXLAG must be a positive number of milliseconds",
+		FireCommand(subscribers[0], fmt.Sprintf("GET.WATCH %s MAXLAG 0", getWatchKey)))
+
+	// The bound is not part of the command watched, a server that is not a replica never lags
+	rp := fireCommandAndGetRESPParser(subscribers[0], fmt.Sprintf("GET.WATCH %s MAXLAG 500", getWatchKey))
+	assert.True(t, rp != nil)
+	v, err := rp.DecodeOne()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"GET", "2714318480", "value", int64(0)}, v)
+
+	FireCommand(publisher, fmt.Sprintf("SET %s %s", getWatchKey, "value1"))
+	v, err = rp.DecodeOne()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"GET", "2714318480", "value1", int64(1)}, v)
+
+	unsubscribeFromWatchUpdates(t, subscribers, "GET", "2714318480")
+}
+
 func TestGETWATCHWithSDK(t *testing.T) {
 	publisher := getLocalSdk()
 	subscribers := []WatchSubscriber{{client: getLocalSdk()}, {client: getLocalSdk()}, {client: getLocalSdk()}}
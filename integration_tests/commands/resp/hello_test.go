@@ -17,7 +17,6 @@
 package resp
 
 import (
-	"fmt"
 	"testing"
 
 	"github.com/dicedb/dice/config"
@@ -28,16 +27,38 @@ func TestHello(t *testing.T) {
 	conn := getLocalConnection()
 	defer conn.Close()
 
-	expected := []interface{}{
-		"proto", int64(2),
-		"id", fmt.Sprintf("%s:%d", config.DiceConfig.RespServer.Addr, config.DiceConfig.RespServer.Port),
-		"mode", "standalone",
-		"role", "master",
-		"modules", []interface{}{},
-	}
-
 	t.Run("HELLO command response", func(t *testing.T) {
-		actual := FireCommand(conn, "HELLO")
-		assert.Equal(t, expected, actual)
+		actual, ok := FireCommand(conn, "HELLO").([]interface{})
+		assert.True(t, ok)
+		assert.Len(t, actual, 14)
+		assert.Equal(t, []interface{}{"server", "dicedb", "version", config.DiceDBVersion, "proto", int64(2)}, actual[:6])
+		assert.Equal(t, "id", actual[6])
+		assert.IsType(t, int64(0), actual[7])
+		assert.Equal(t, []interface{}{"mode", "standalone", "role", "master", "modules", []interface{}{}}, actual[8:])
+	})
+
+	t.Run("HELLO client ids are unique", func(t *testing.T) {
+		other := getLocalConnection()
+		defer other.Close()
+
+		first := FireCommand(conn, "HELLO 2").([]interface{})
+		second := FireCommand(other, "HELLO 2 SETNAME other").([]interface{})
+		assert.NotEqual(t, first[7], second[7])
+		assert.Equal(t, first[7], FireCommand(conn, "HELLO").([]interface{})[7])
+	})
+
+	t.Run("HELLO with an unsupported protocol", func(t *testing.T) {
+		assert.Equal(t, "NOPROTO sorry, this protocol version is not supported", FireCommand(conn, "HELLO 3"))
+		assert.Equal(t, "ERR Protocol version is not an integer or out of range", FireCommand(conn, "HELLO two"))
+		assert.Equal(t, "ERR Syntax error in HELLO option 'FOO'", FireCommand(conn, "HELLO 2 FOO"))
 	})
 }
+
+func TestLolwut(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	assert.Contains(t, FireCommand(conn, "LOLWUT"), "DiceDB ver. "+config.DiceDBVersion)
+	assert.Contains(t, FireCommand(conn, "LOLWUT VERSION 5"), "DiceDB ver. ")
+	assert.Equal(t, "ERR syntax error", FireCommand(conn, "LOLWUT 5"))
+}
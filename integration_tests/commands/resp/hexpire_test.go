@@ -0,0 +1,123 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashFieldExpiry(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		delay    []time.Duration
+		cleanup  []string
+	}{
+		{
+			name:     "HEXPIRE and HTTL",
+			commands: []string{"HSET session a 1 b 2", "HEXPIRE session 100 FIELDS 3 a b c", "HTTL session FIELDS 3 a b c"},
+			expected: []interface{}{int64(2), []interface{}{int64(1), int64(1), int64(-2)}, []interface{}{int64(100), int64(100), int64(-2)}},
+			cleanup:  []string{"DEL session"},
+		},
+		{
+			name:     "HPEXPIRE expires the fields",
+			commands: []string{"HSET session a 1 b 2", "HPEXPIRE session 100 FIELDS 1 a", "HGET session a", "HGETALL session"},
+			expected: []interface{}{int64(2), []interface{}{int64(1)}, "(nil)", []interface{}{"b", "2"}},
+			delay:    []time.Duration{0, 0, 200 * time.Millisecond, 0},
+			cleanup:  []string{"DEL session"},
+		},
+		{
+			name:     "The key is deleted with its last field",
+			commands: []string{"HSET session a 1", "HPEXPIRE session 100 FIELDS 1 a", "EXISTS session"},
+			expected: []interface{}{int64(1), []interface{}{int64(1)}, int64(0)},
+			delay:    []time.Duration{0, 0, 200 * time.Millisecond},
+		},
+		{
+			name: "HEXPIRE conditions",
+			commands: []string{"HSET session a 1 b 2", "HEXPIRE session 100 NX FIELDS 1 a",
+				"HEXPIRE session 200 NX FIELDS 2 a b", "HEXPIRE session 50 GT FIELDS 2 a b", "HEXPIRE session 50 LT FIELDS 1 a"},
+			expected: []interface{}{int64(2), []interface{}{int64(1)}, []interface{}{int64(0), int64(1)},
+				[]interface{}{int64(0), int64(0)}, []interface{}{int64(1)}},
+			cleanup: []string{"DEL session"},
+		},
+		{
+			name:     "HPERSIST",
+			commands: []string{"HSET session a 1 b 2", "HEXPIRE session 100 FIELDS 1 a", "HPERSIST session FIELDS 3 a b c", "HTTL session FIELDS 1 a"},
+			expected: []interface{}{int64(2), []interface{}{int64(1)}, []interface{}{int64(1), int64(-1), int64(-2)}, []interface{}{int64(-1)}},
+			cleanup:  []string{"DEL session"},
+		},
+		{
+			name:     "HSET removes the expiry of the fields",
+			commands: []string{"HSET session a 1", "HEXPIRE session 100 FIELDS 1 a", "HSET session a 2", "HTTL session FIELDS 1 a"},
+			expected: []interface{}{int64(1), []interface{}{int64(1)}, int64(0), []interface{}{int64(-1)}},
+			cleanup:  []string{"DEL session"},
+		},
+		{
+			name:     "HGETEX",
+			commands: []string{"HSET session a 1 b 2", "HGETEX session EX 100 FIELDS 2 a c", "HTTL session FIELDS 2 a b", "HGETEX session PERSIST FIELDS 1 a", "HTTL session FIELDS 1 a"},
+			expected: []interface{}{int64(2), []interface{}{"1", "(nil)"}, []interface{}{int64(100), int64(-1)}, []interface{}{"1"}, []interface{}{int64(-1)}},
+			cleanup:  []string{"DEL session"},
+		},
+		{
+			name:     "HGETDEL",
+			commands: []string{"HSET session a 1 b 2", "HGETDEL session FIELDS 2 a c", "HGETDEL session FIELDS 1 b", "EXISTS session"},
+			expected: []interface{}{int64(2), []interface{}{"1", "(nil)"}, []interface{}{"2"}, int64(0)},
+		},
+		{
+			name: "Invalid arguments",
+			commands: []string{"HEXPIRE session 10 FIELDS 2 a", "HTTL session FIELD 1 a", "HPERSIST session FIELDS 0 a",
+				"HEXPIRE session -1 FIELDS 1 a", "HGETEX session EX 0 FIELDS 1 a", "HGETDEL session FIELDS 1"},
+			expected: []interface{}{
+				"ERR The `numfields` parameter must match the number of arguments",
+				"ERR Mandatory argument FIELDS is missing or not at the right position",
+				"ERR Parameter `numFields` should be greater than 0",
+				"ERR invalid expire time in 'hexpire' command",
+				"ERR invalid expire time in 'hgetex' command",
+				"ERR wrong number of arguments for 'hgetdel' command",
+			},
+		},
+		{
+			name:     "Wrong key type",
+			commands: []string{"SET session v", "HTTL session FIELDS 1 a"},
+			expected: []interface{}{"OK", "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			cleanup:  []string{"DEL session"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				if len(tc.delay) > i {
+					time.Sleep(tc.delay[i])
+				}
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+
+			for _, cmd := range tc.cleanup {
+				FireCommand(conn, cmd)
+			}
+		})
+	}
+}
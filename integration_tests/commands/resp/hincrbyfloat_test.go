@@ -49,7 +49,7 @@ func TestHINCRBYFLOAT(t *testing.T) {
 		{
 			name:   "HINCRBYFLOAT on non-float or non-integer value",
 			cmds:   []string{"HSET keys field value", "HINCRBYFLOAT keys field 1.2"},
-			expect: []interface{}{int64(1), "ERR value is not an integer or a float"},
+			expect: []interface{}{int64(1), "ERR hash value is not a float"},
 			delays: []time.Duration{0, 0},
 		},
 		{
@@ -61,7 +61,7 @@ func TestHINCRBYFLOAT(t *testing.T) {
 		{
 			name:   "HINCRBYFLOAT using a non integer / non-float value",
 			cmds:   []string{"HINCRBYFLOAT key value new"},
-			expect: []interface{}{"ERR value is not an integer or a float"},
+			expect: []interface{}{"ERR value is not a valid float"},
 			delays: []time.Duration{0},
 		},
 	}
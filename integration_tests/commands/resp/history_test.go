@@ -0,0 +1,69 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHISTORY(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	t.Run("GETAT with the history disabled", func(t *testing.T) {
+		assert.Equal(t, "ERR history is disabled", FireCommand(conn, "GETAT versioned 0"))
+	})
+
+	assert.Equal(t, "OK", FireCommand(conn, "CONFIG SET history.enabled true"))
+	defer FireCommand(conn, "CONFIG SET history.enabled false")
+
+	t.Run("GETAT reads the previous values of a key", func(t *testing.T) {
+		defer FireCommand(conn, "DEL versioned")
+		FireCommand(conn, "SET versioned v1")
+		time.Sleep(10 * time.Millisecond)
+		before := time.Now().UnixMilli()
+		time.Sleep(10 * time.Millisecond)
+		FireCommand(conn, "SET versioned v2")
+		FireCommand(conn, "INCR counted")
+		defer FireCommand(conn, "DEL counted")
+
+		assert.Equal(t, "v1", FireCommand(conn, "GETAT versioned "+strconv.FormatInt(before, 10)))
+		assert.Equal(t, "v2", FireCommand(conn, "GETAT versioned "+strconv.FormatInt(time.Now().UnixMilli(), 10)))
+		assert.Equal(t, "(nil)", FireCommand(conn, "GETAT counted "+strconv.FormatInt(before, 10)))
+
+		history, ok := FireCommand(conn, "HISTORY versioned").([]interface{})
+		assert.True(t, ok)
+		assert.Len(t, history, 2)
+		assert.Equal(t, "v2", history[1].([]interface{})[1])
+	})
+
+	t.Run("GETAT outside the retention window", func(t *testing.T) {
+		assert.Equal(t, "ERR the timestamp is outside the history retention window", FireCommand(conn, "GETAT versioned 0"))
+	})
+
+	t.Run("GETAT with an invalid timestamp", func(t *testing.T) {
+		assert.Equal(t, "ERR value is not an integer or out of range", FireCommand(conn, "GETAT versioned soon"))
+	})
+
+	t.Run("HISTORY with wrong number of arguments", func(t *testing.T) {
+		assert.Equal(t, "ERR wrong number of arguments for 'history' command", FireCommand(conn, "HISTORY a b"))
+	})
+}
@@ -53,6 +53,18 @@ func TestHRANDFIELD(t *testing.T) {
 			expect: []interface{}{[]string{"field", "value", "field2", "value2", "field3", "value3"}},
 			delays: []time.Duration{0},
 		},
+		{
+			name:   "HRANDFIELD with negative count",
+			cmds:   []string{"HRANDFIELD key_hrandfield -5"},
+			expect: []interface{}{[]string{"field", "field2", "field3"}},
+			delays: []time.Duration{0},
+		},
+		{
+			name:   "HRANDFIELD with count on non-existent key",
+			cmds:   []string{"HRANDFIELD key_hrandfield_nonexistent 2"},
+			expect: []interface{}{[]interface{}{}},
+			delays: []time.Duration{0},
+		},
 		{
 			name:   "HRANDFIELD on non-existent key",
 			cmds:   []string{"HRANDFIELD key_hrandfield_nonexistent"},
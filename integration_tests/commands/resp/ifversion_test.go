@@ -0,0 +1,85 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIFVERSION(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	version := func(key string) int64 {
+		v, ok := FireCommand(conn, "OBJECT VERSION "+key).(int64)
+		require.True(t, ok, "OBJECT VERSION of %s", key)
+		return v
+	}
+
+	t.Run("OBJECT VERSION of a missing key", func(t *testing.T) {
+		FireCommand(conn, "DEL k")
+		assert.Equal(t, "(nil)", FireCommand(conn, "OBJECT VERSION k"))
+	})
+
+	t.Run("Every write gives a new version", func(t *testing.T) {
+		defer FireCommand(conn, "DEL k")
+		assert.Equal(t, "OK", FireCommand(conn, "SET k 1"))
+		first := version("k")
+		assert.Equal(t, int64(2), FireCommand(conn, "INCR k"))
+		second := version("k")
+		assert.Greater(t, second, first)
+		assert.Equal(t, int64(2), FireCommand(conn, "GET k"))
+		assert.Equal(t, second, version("k"), "a read keeps the version")
+	})
+
+	t.Run("SET IFVERSION", func(t *testing.T) {
+		defer FireCommand(conn, "DEL k")
+		assert.Equal(t, "OK", FireCommand(conn, "SET k a IFVERSION 0"))
+		v := version("k")
+		assert.Equal(t, "OK", FireCommand(conn, fmt.Sprintf("SET k b IFVERSION %d", v)))
+		assert.Equal(t, fmt.Sprintf("CONFLICT the key is at version %d, not %d", version("k"), v),
+			FireCommand(conn, fmt.Sprintf("SET k c IFVERSION %d", v)))
+		assert.Equal(t, "b", FireCommand(conn, "GET k"))
+	})
+
+	t.Run("HSET IFVERSION", func(t *testing.T) {
+		defer FireCommand(conn, "DEL h")
+		FireCommand(conn, "DEL h")
+		assert.Equal(t, int64(1), FireCommand(conn, "HSET h f a IFVERSION 0"))
+		v := version("h")
+		assert.Equal(t, int64(0), FireCommand(conn, fmt.Sprintf("HSET h f b IFVERSION %d", v)))
+		assert.Equal(t, fmt.Sprintf("CONFLICT the key is at version %d, not %d", version("h"), v),
+			FireCommand(conn, fmt.Sprintf("HSET h f c IFVERSION %d", v)))
+		assert.Equal(t, "b", FireCommand(conn, "HGET h f"))
+	})
+
+	t.Run("DEL IFVERSION", func(t *testing.T) {
+		defer FireCommand(conn, "DEL k k2")
+		assert.Equal(t, "OK", FireCommand(conn, "SET k a"))
+		v := version("k")
+		assert.Equal(t, "ERR IFVERSION can only be used with a single key",
+			FireCommand(conn, fmt.Sprintf("DEL k k2 IFVERSION %d", v)))
+		assert.Equal(t, fmt.Sprintf("CONFLICT the key is at version %d, not %d", v, v+1),
+			FireCommand(conn, fmt.Sprintf("DEL k IFVERSION %d", v+1)))
+		assert.Equal(t, int64(1), FireCommand(conn, fmt.Sprintf("DEL k IFVERSION %d", v)))
+		assert.Equal(t, int64(0), FireCommand(conn, "EXISTS k"))
+	})
+}
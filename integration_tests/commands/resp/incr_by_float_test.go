@@ -27,7 +27,7 @@ func TestINCRBYFLOAT(t *testing.T) {
 	defer conn.Close()
 	invalidArgMessage := "ERR wrong number of arguments for 'incrbyfloat' command"
 	invalidIncrTypeMessage := "ERR value is not a valid float"
-	valueOutOfRangeMessage := "ERR value is out of range"
+	valueOutOfRangeMessage := "ERR increment would produce NaN or Infinity"
 
 	testCases := []struct {
 		name      string
@@ -57,7 +57,7 @@ func TestINCRBYFLOAT(t *testing.T) {
 			name:      "Increment and then decrement a key with the same value",
 			setupData: "SET foo 1",
 			commands:  []string{"INCRBYFLOAT foo 0.1", "GET foo", "INCRBYFLOAT foo -0.1", "GET foo"},
-			expected:  []interface{}{"1.1", "1.1", "1", "1"},
+			expected:  []interface{}{"1.1", "1.1", "1", int64(1)},
 		},
 		{
 			name:      "Increment a non numeric value",
@@ -89,6 +89,18 @@ func TestINCRBYFLOAT(t *testing.T) {
 			commands:  []string{"INCRBYFLOAT foo -1e308", "INCRBYFLOAT foo 1e308"},
 			expected:  []interface{}{valueOutOfRangeMessage, "0"},
 		},
+		{
+			name:      "Increment to a large value without an exponent",
+			setupData: "",
+			commands:  []string{"INCRBYFLOAT foo 1e20", "INCRBYFLOAT foo 1.5e-5"},
+			expected:  []interface{}{"100000000000000000000", "100000000000000000000"},
+		},
+		{
+			name:      "Increment to a whole value and then INCR",
+			setupData: "SET foo 1.5",
+			commands:  []string{"INCRBYFLOAT foo 0.5", "INCR foo"},
+			expected:  []interface{}{"2", int64(3)},
+		},
 	}
 
 	for _, tc := range testCases {
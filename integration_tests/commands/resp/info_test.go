@@ -0,0 +1,122 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfo(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	testCases := []struct {
+		name        string
+		command     string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "INFO returns every section",
+			command:     "INFO",
+			contains:    []string{"# Server\r\n", "# Clients\r\n", "# Memory\r\n", "# Persistence\r\n", "# Stats\r\n", "# Replication\r\n", "# Qwatch\r\n", "# Keyspace\r\n"},
+			notContains: []string{"# Commandstats"},
+		},
+		{
+			name:     "INFO all includes the extra sections",
+			command:  "INFO all",
+			contains: []string{"# Server\r\n", "# Commandstats\r\n", "# Keyspace\r\n"},
+		},
+		{
+			name:        "INFO with a single section",
+			command:     "INFO memory",
+			contains:    []string{"# Memory\r\n", "used_memory:", "maxmemory_policy:"},
+			notContains: []string{"# Server", "# Keyspace"},
+		},
+		{
+			name:        "INFO with several sections",
+			command:     "INFO CLIENTS replication",
+			contains:    []string{"# Clients\r\n", "connected_clients:", "# Replication\r\n", "role:master\r\n"},
+			notContains: []string{"# Memory"},
+		},
+		{
+			name:        "INFO qwatch reports the health of the push pipeline",
+			command:     "INFO qwatch",
+			contains:    []string{"# Qwatch\r\n", "qwatch_subscriptions:", "qwatch_push_latency_avg_usec:", "qwatch_dropped_updates:"},
+			notContains: []string{"# Stats"},
+		},
+		{
+			name:        "INFO persistence reports the status of the backups",
+			command:     "INFO persistence",
+			contains:    []string{"# Persistence\r\n", "loading:0\r\n", "aof_enabled:", "backup_enabled:0\r\n", "backup_in_progress:0\r\n"},
+			notContains: []string{"# Memory", "backup_last_time:"},
+		},
+		{
+			name:    "INFO with an unknown section",
+			command: "INFO foo",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, ok := FireCommand(conn, tc.command).(string)
+			assert.True(t, ok, "INFO must return a bulk string")
+			if len(tc.contains) == 0 {
+				assert.Empty(t, result)
+			}
+			for _, s := range tc.contains {
+				assert.Contains(t, result, s)
+			}
+			for _, s := range tc.notContains {
+				assert.NotContains(t, result, s)
+			}
+		})
+	}
+
+	t.Run("INFO keyspace merges every shard", func(t *testing.T) {
+		assert.Equal(t, "# Keyspace\r\n", FireCommand(conn, "INFO keyspace"))
+
+		FireCommand(conn, "SET k1 v1")
+		FireCommand(conn, "SET k2 v2 EX 100")
+		FireCommand(conn, "SET k3 v3")
+		assert.Equal(t, "# Keyspace\r\ndb0:keys=3,expires=1\r\n", FireCommand(conn, "INFO keyspace"))
+	})
+
+	t.Run("INFO commandstats and CONFIG RESETSTAT", func(t *testing.T) {
+		assert.Equal(t, "OK", FireCommand(conn, "CONFIG RESETSTAT"))
+		FireCommand(conn, "SET k1 v1")
+		FireCommand(conn, "GET k1")
+		FireCommand(conn, "GET k4")
+		FireCommand(conn, "GET")
+
+		result := FireCommand(conn, "INFO commandstats stats").(string)
+		assert.Contains(t, result, "cmdstat_set:calls=1,")
+		assert.Contains(t, result, "cmdstat_get:calls=3,")
+		assert.Contains(t, result, "failed_calls=1\r\n")
+		assert.Contains(t, result, "keyspace_hits:1\r\n")
+		assert.Contains(t, result, "keyspace_misses:1\r\n")
+
+		assert.Equal(t, "OK", FireCommand(conn, "CONFIG RESETSTAT"))
+		assert.NotContains(t, FireCommand(conn, "INFO commandstats"), "cmdstat_get")
+		assert.Equal(t, "ERR wrong number of arguments for 'config|resetstat' command", FireCommand(conn, "CONFIG RESETSTAT now"))
+	})
+}
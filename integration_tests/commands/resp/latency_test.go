@@ -0,0 +1,65 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatency(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "LATENCY RESET")
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+	}{
+		{
+			name:     "LATENCY with invalid number of arguments",
+			commands: []string{"LATENCY", "LATENCY HISTORY", "LATENCY LATEST command"},
+			expected: []interface{}{
+				"ERR wrong number of arguments for 'latency' command",
+				"ERR wrong number of arguments for 'latency|history' command",
+				"ERR wrong number of arguments for 'latency|latest' command",
+			},
+		},
+		{
+			name:     "LATENCY with unknown subcommand",
+			commands: []string{"LATENCY FOO"},
+			expected: []interface{}{"ERR unknown subcommand 'FOO'. Try LATENCY HELP."},
+		},
+		{
+			name:     "LATENCY without spikes",
+			commands: []string{"LATENCY LATEST", "LATENCY HISTORY command", "LATENCY RESET command eviction"},
+			expected: []interface{}{[]interface{}{}, []interface{}{}, int64(0)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLOCK(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	defer FireCommand(conn, "DEL lock")
+
+	token, ok := FireCommand(conn, "LOCK lock 10000").(int64)
+	require.True(t, ok)
+	assert.Equal(t, "(nil)", FireCommand(conn, "LOCK lock 10000"), "a held lock is not acquired")
+	assert.Positive(t, FireCommand(conn, "PTTL lock"))
+
+	// The lease is extended with the token, and the lock released with it only
+	assert.Equal(t, int64(1), FireCommand(conn, fmt.Sprintf("PEXPIREIFEQ lock %d 20000", token)))
+	assert.Equal(t, int64(0), FireCommand(conn, fmt.Sprintf("UNLOCK lock %d", token-1)))
+	assert.Equal(t, int64(1), FireCommand(conn, fmt.Sprintf("UNLOCK lock %d", token)))
+	assert.Equal(t, int64(0), FireCommand(conn, fmt.Sprintf("UNLOCK lock %d", token)))
+
+	// The tokens keep increasing, also once a lease expired
+	next, ok := FireCommand(conn, "LOCK lock 50").(int64)
+	require.True(t, ok)
+	assert.Greater(t, next, token)
+	time.Sleep(100 * time.Millisecond)
+	last, ok := FireCommand(conn, "LOCK lock 10000").(int64)
+	require.True(t, ok)
+	assert.Greater(t, last, next)
+}
+
+func TestLOCKErrors(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		cleanup  []string
+	}{
+		{
+			name:     "LOCK with an invalid lease",
+			commands: []string{"LOCK lock ten", "LOCK lock 0"},
+			expected: []interface{}{
+				"ERR value is not an integer or out of range",
+				"ERR invalid expire time in 'lock' command",
+			},
+		},
+		{
+			name:     "UNLOCK with an invalid token",
+			commands: []string{"UNLOCK lock token"},
+			expected: []interface{}{"ERR value is not an integer or out of range"},
+		},
+		{
+			name:     "UNLOCK on wrong key type",
+			commands: []string{"LPUSH lock 42", "UNLOCK lock 42"},
+			expected: []interface{}{int64(1), "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			cleanup:  []string{"DEL lock"},
+		},
+		{
+			name:     "LOCK and UNLOCK with wrong number of arguments",
+			commands: []string{"LOCK lock", "UNLOCK lock"},
+			expected: []interface{}{
+				"ERR wrong number of arguments for 'lock' command",
+				"ERR wrong number of arguments for 'unlock' command",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+
+			for _, cmd := range tc.cleanup {
+				FireCommand(conn, cmd)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStats(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "SET memory_k1 v1")
+	defer FireCommand(conn, "DEL memory_k1")
+
+	t.Run("MEMORY STATS merges the stats of every shard", func(t *testing.T) {
+		reply, ok := FireCommand(conn, "MEMORY STATS").([]interface{})
+		assert.True(t, ok)
+		assert.Len(t, reply, 10)
+
+		stats := make(map[interface{}]interface{})
+		for i := 0; i+1 < len(reply); i += 2 {
+			stats[reply[i]] = reply[i+1]
+		}
+		assert.Greater(t, stats["total.allocated"], int64(0))
+		assert.GreaterOrEqual(t, stats["keys.count"], int64(1))
+		assert.Equal(t, "no", stats["keys.prefix-interning"])
+		assert.Equal(t, int64(0), stats["keys.interned-prefixes"])
+		assert.Equal(t, int64(0), stats["keys.interning-bytes-saved"])
+	})
+
+	t.Run("MEMORY with invalid arguments", func(t *testing.T) {
+		assert.Equal(t, "ERR wrong number of arguments for 'memory' command", FireCommand(conn, "MEMORY"))
+		assert.Equal(t, "ERR wrong number of arguments for 'memory|stats' command", FireCommand(conn, "MEMORY STATS now"))
+		assert.Equal(t, "ERR unknown subcommand 'FOO'.", FireCommand(conn, "MEMORY FOO"))
+	})
+}
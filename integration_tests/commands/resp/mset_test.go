@@ -17,6 +17,8 @@
 package resp
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -112,3 +114,26 @@ func TestMSETInconsistency(t *testing.T) {
 	}
 
 }
+
+func TestMSETManyKeys(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	// The keys are set by one MSET per shard, whatever their number
+	const count = 1000
+	args := make([]string, 0, 2*count)
+	keys := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("bulk:%d", i)
+		args = append(args, key, fmt.Sprintf("v%d", i))
+		keys = append(keys, key)
+	}
+
+	assert.Equal(t, "OK", FireCommand(conn, "MSET "+strings.Join(args, " ")))
+	assert.Equal(t, "v0", FireCommand(conn, "GET bulk:0"))
+	assert.Equal(t, fmt.Sprintf("v%d", count-1), FireCommand(conn, fmt.Sprintf("GET bulk:%d", count-1)))
+	assert.Equal(t, int64(count), FireCommand(conn, "EXISTS "+strings.Join(keys, " ")))
+	for _, key := range keys {
+		FireCommand(conn, "DEL "+key)
+	}
+}
@@ -0,0 +1,106 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiExec(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+	}{
+		{
+			name:     "EXEC and DISCARD without MULTI",
+			commands: []string{"EXEC", "DISCARD"},
+			expected: []interface{}{"ERR EXEC without MULTI", "ERR DISCARD without MULTI"},
+		},
+		{
+			name:     "MULTI with arguments",
+			commands: []string{"MULTI now"},
+			expected: []interface{}{"ERR wrong number of arguments for 'multi' command"},
+		},
+		{
+			name:     "EXEC of an empty transaction",
+			commands: []string{"MULTI", "EXEC"},
+			expected: []interface{}{"OK", []interface{}{}},
+		},
+		{
+			name:     "EXEC runs the queued commands in order",
+			commands: []string{"MULTI", "SET k1 v1", "INCR k2", "GET k1", "INCR k2", "EXEC", "GET k1"},
+			expected: []interface{}{"OK", "QUEUED", "QUEUED", "QUEUED", "QUEUED", []interface{}{"OK", int64(1), "v1", int64(2)}, "v1"},
+		},
+		{
+			name:     "nested MULTI keeps the transaction",
+			commands: []string{"MULTI", "SET k3 v3", "MULTI", "EXEC", "GET k3"},
+			expected: []interface{}{"OK", "QUEUED", "ERR MULTI calls can not be nested", []interface{}{"OK"}, "v3"},
+		},
+		{
+			name:     "DISCARD drops the queued commands",
+			commands: []string{"MULTI", "SET k4 v4", "DISCARD", "GET k4", "EXEC"},
+			expected: []interface{}{"OK", "QUEUED", "OK", "(nil)", "ERR EXEC without MULTI"},
+		},
+		{
+			name:     "refused command aborts the transaction",
+			commands: []string{"MULTI", "MGET k1 k3", "SET k5 v5", "EXEC", "GET k5"},
+			expected: []interface{}{
+				"OK",
+				"ERR 'mget' is not allowed inside a transaction",
+				"QUEUED",
+				"EXECABORT Transaction discarded because of previous errors.",
+				"(nil)",
+			},
+		},
+		{
+			name:     "unknown command aborts the transaction",
+			commands: []string{"MULTI", "NOPE k5", "SET k5 v5", "EXEC", "GET k5"},
+			expected: []interface{}{
+				"OK",
+				"ERR unknown command 'NOPE', with args beginning with: k5",
+				"QUEUED",
+				"EXECABORT Transaction discarded because of previous errors.",
+				"(nil)",
+			},
+		},
+		{
+			name:     "execution error does not abort the transaction",
+			commands: []string{"MULTI", "SET k6 v6", "LPUSH k6 v", "SET k7 v7", "EXEC", "GET k7"},
+			expected: []interface{}{
+				"OK", "QUEUED", "QUEUED", "QUEUED",
+				[]interface{}{"OK", "WRONGTYPE Operation against a key holding the wrong kind of value", "OK"},
+				"v7",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			FireCommand(conn, "DEL k1 k2 k3 k4 k5 k6 k7")
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+		})
+	}
+}
@@ -44,6 +44,15 @@ func TestObjectCommand(t *testing.T) {
 			delay:      []time.Duration{0, 2 * time.Second, 3 * time.Second, 0, 0},
 			cleanup:    []string{"DEL foo"},
 		},
+		{
+			name: "Object Encoding",
+			commands: []string{"SET foo 12", "OBJECT ENCODING foo", "APPEND foo bar", "OBJECT ENCODING foo",
+				"SET foo bar", "OBJECT ENCODING foo", "APPEND foo baz", "OBJECT ENCODING foo", "OBJECT ENCODING missing"},
+			expected:   []interface{}{"OK", "int", int64(5), "raw", "OK", "embstr", int64(6), "raw", "(nil)"},
+			assertType: []string{"equal", "equal", "equal", "equal", "equal", "equal", "equal", "equal", "equal"},
+			delay:      []time.Duration{0, 0, 0, 0, 0, 0, 0, 0, 0},
+			cleanup:    []string{"DEL foo"},
+		},
 	}
 
 	for _, tc := range testCases {
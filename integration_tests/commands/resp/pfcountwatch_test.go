@@ -112,7 +112,7 @@ func setUpRespParsers(t *testing.T, subscribers []net.Conn) []*clientio.RESPPars
 		if !ok {
 			t.Errorf("Type assertion to []interface{} failed for value: %v", v)
 		}
-		assert.Equal(t, 3, len(castedValue))
+		assert.Equal(t, 4, len(castedValue))
 	}
 	return respParsers
 }
@@ -145,7 +145,7 @@ func verifyWatchResults(t *testing.T, respParsers []*clientio.RESPParser, expect
 		if !ok {
 			t.Errorf("Type assertion to []interface{} failed for value: %v", v)
 		}
-		assert.Equal(t, 3, len(castedValue))
+		assert.Equal(t, 4, len(castedValue))
 		assert.Equal(t, pfcountCommand, castedValue[0])
 		assert.Equal(t, pfcountWatchFingerPrint, castedValue[1])
 		assert.Equal(t, int64(expected), castedValue[2])
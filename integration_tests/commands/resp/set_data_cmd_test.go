@@ -18,6 +18,8 @@ package resp
 
 import (
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,8 +31,14 @@ func CustomDeepEqual(t *testing.T, a, b interface{}) {
 		assert.DeepEqual(t, a, b)
 	}
 
-	switch a.(type) {
+	// The members of a set are sorted, the replies listing one entry per member are left as they are
+	switch v := a.(type) {
 	case []any:
+		if len(v) > 0 {
+			if _, ok := v[0].(string); !ok {
+				break
+			}
+		}
 		sort.Slice(a.([]any), func(i, j int) bool {
 			return a.([]any)[i].(string) < a.([]any)[j].(string)
 		})
@@ -162,12 +170,108 @@ func TestSetDataCommand(t *testing.T) {
 			assertType: []string{"equal", "equal", "equal", "equal"},
 			delay:      []time.Duration{0, 0, 0, 0},
 		},
+		// SMISMEMBER
+		{
+			name:       "SADD & SMISMEMBER",
+			cmd:        []string{"SADD foo bar baz", "SMISMEMBER foo bar bax baz"},
+			expected:   []interface{}{int64(2), []any{int64(1), int64(0), int64(1)}},
+			assertType: []string{"equal", "equal"},
+			delay:      []time.Duration{0, 0},
+		},
+		{
+			name:       "SMISMEMBER with non existing key",
+			cmd:        []string{"SMISMEMBER foo bar baz"},
+			expected:   []interface{}{[]any{int64(0), int64(0)}},
+			assertType: []string{"equal"},
+			delay:      []time.Duration{0},
+		},
+		{
+			name:       "SMISMEMBER with wrong key type",
+			cmd:        []string{"SET foo bar", "SMISMEMBER foo bar"},
+			expected:   []interface{}{"OK", "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			assertType: []string{"equal", "equal"},
+			delay:      []time.Duration{0, 0},
+		},
+		// SINTER
+		{
+			name:       "SADD & SINTER",
+			cmd:        []string{"SADD foo a b c d", "SADD foo2 b c", "SADD foo3 c d e", "SINTER foo foo2 foo3"},
+			expected:   []interface{}{int64(4), int64(2), int64(3), []any{"c"}},
+			assertType: []string{"equal", "equal", "equal", "equal"},
+			delay:      []time.Duration{0, 0, 0, 0},
+		},
+		{
+			name:       "SINTER with non existing key",
+			cmd:        []string{"SADD foo a b", "SINTER foo foo2"},
+			expected:   []interface{}{int64(2), []any{}},
+			assertType: []string{"equal", "equal"},
+			delay:      []time.Duration{0, 0},
+		},
+		{
+			name:       "SINTER with wrong key type after an empty set",
+			cmd:        []string{"SET foo2 bar", "SINTER foo foo2"},
+			expected:   []interface{}{"OK", "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			assertType: []string{"equal", "equal"},
+			delay:      []time.Duration{0, 0},
+		},
+		// SINTERCARD
+		{
+			name:       "SADD & SINTERCARD",
+			cmd:        []string{"SADD foo 1 2 3 4 5", "SADD foo2 2 3 4 5 6", "SINTERCARD 2 foo foo2", "SINTERCARD 2 foo foo2 LIMIT 2", "SINTERCARD 2 foo foo2 LIMIT 0"},
+			expected:   []interface{}{int64(5), int64(5), int64(4), int64(2), int64(4)},
+			assertType: []string{"equal", "equal", "equal", "equal", "equal"},
+			delay:      []time.Duration{0, 0, 0, 0, 0},
+		},
+		{
+			name: "SINTERCARD with invalid arguments",
+			cmd:  []string{"SINTERCARD 0 foo", "SINTERCARD 3 foo foo2", "SINTERCARD 1 foo LIMIT -1", "SINTERCARD 1 foo LIMIT", "SINTERCARD x foo"},
+			expected: []interface{}{
+				"ERR numkeys should be greater than 0",
+				"ERR Number of keys can't be greater than number of args",
+				"ERR LIMIT can't be negative",
+				"ERR syntax error",
+				"ERR value is not an integer or out of range",
+			},
+			assertType: []string{"equal", "equal", "equal", "equal", "equal"},
+			delay:      []time.Duration{0, 0, 0, 0, 0},
+		},
+		// SUNION
+		{
+			name:       "SADD & SUNION",
+			cmd:        []string{"SADD foo a b", "SADD foo2 b c", "SUNION foo foo2 foo3"},
+			expected:   []interface{}{int64(2), int64(2), []any{"a", "b", "c"}},
+			assertType: []string{"equal", "equal", "equal"},
+			delay:      []time.Duration{0, 0, 0},
+		},
+		{
+			name:       "SUNION with wrong key type",
+			cmd:        []string{"SET foo2 bar", "SUNION foo foo2"},
+			expected:   []interface{}{"OK", "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			assertType: []string{"equal", "equal"},
+			delay:      []time.Duration{0, 0},
+		},
+		// SDIFF
+		{
+			name:       "SADD & SDIFF",
+			cmd:        []string{"SADD foo a b c d", "SADD foo2 c", "SADD foo3 a e f g h", "SDIFF foo foo2 foo3"},
+			expected:   []interface{}{int64(4), int64(1), int64(5), []any{"b", "d"}},
+			assertType: []string{"equal", "equal", "equal", "equal"},
+			delay:      []time.Duration{0, 0, 0, 0},
+		},
+		{
+			name:       "SDIFF with non existing first key",
+			cmd:        []string{"SADD foo2 a", "SDIFF foo foo2"},
+			expected:   []interface{}{int64(1), []any{}},
+			assertType: []string{"equal", "equal"},
+			delay:      []time.Duration{0, 0},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			FireCommand(conn, "DEL foo")
 			FireCommand(conn, "DEL foo2")
+			FireCommand(conn, "DEL foo3")
 			for i, cmd := range tc.cmd {
 				if tc.delay[i] > 0 {
 					time.Sleep(tc.delay[i])
@@ -179,3 +283,26 @@ func TestSetDataCommand(t *testing.T) {
 	}
 
 }
+
+func TestSetAlgebraLargeSets(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	defer FireCommand(conn, "DEL big1 big2")
+
+	// The candidates are checked by batches of members, the sets span several batches
+	big1 := []string{"SADD", "big1"}
+	big2 := []string{"SADD", "big2"}
+	for i := 0; i < 3000; i++ {
+		big1 = append(big1, strconv.Itoa(i))
+		if i%2 == 0 {
+			big2 = append(big2, strconv.Itoa(i))
+		}
+	}
+	FireCommand(conn, strings.Join(big1, " "))
+	FireCommand(conn, strings.Join(big2, " "))
+
+	assert.Equal(t, int64(1500), FireCommand(conn, "SINTERCARD 2 big1 big2"))
+	assert.Equal(t, int64(1100), FireCommand(conn, "SINTERCARD 2 big1 big2 LIMIT 1100"))
+	assert.Equal(t, 1500, len(FireCommand(conn, "SDIFF big1 big2").([]interface{})))
+	assert.Equal(t, 3000, len(FireCommand(conn, "SUNION big1 big2").([]interface{})))
+}
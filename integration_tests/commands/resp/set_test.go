@@ -17,7 +17,9 @@
 package resp
 
 import (
+	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -136,6 +138,21 @@ func TestSetWithOptions(t *testing.T) {
 			commands: []string{"SET k v XX EX 1", "GET k", "SLEEP 2", "GET k", "SET k v XX EX 1", "GET k"},
 			expected: []interface{}{"(nil)", "(nil)", "OK", "(nil)", "(nil)", "(nil)"},
 		},
+		{
+			name:     "NX and XX together",
+			commands: []string{"SET k v NX XX", "SET k v XX NX", "GET k"},
+			expected: []interface{}{"ERR syntax error", "ERR syntax error", "(nil)"},
+		},
+		{
+			name:     "NX with invalid option",
+			commands: []string{"SET k v", "SET k v NX FOO"},
+			expected: []interface{}{"OK", "ERR syntax error"},
+		},
+		{
+			name:     "NX with GET on existing key",
+			commands: []string{"SET k v1", "SET k v2 NX GET", "GET k"},
+			expected: []interface{}{"OK", "v1", "v1"},
+		},
 		{
 			name:     "GET with Existing Value",
 			commands: []string{"SET k v", "SET k vv GET"},
@@ -232,3 +249,128 @@ func TestWithKeepTTLFlag(t *testing.T) {
 
 	assert.Equal(t, out, FireCommand(conn, cmd), "Value mismatch for cmd %s\n.", cmd)
 }
+
+func TestSetExAndSetNX(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	defer FireCommand(conn, "FLUSHDB")
+
+	testCases := []TestCase{
+		{
+			name:     "SETEX sets the value and its expiry",
+			commands: []string{"SETEX k 100 v", "GET k", "PERSIST k"},
+			expected: []interface{}{"OK", "v", int64(1)},
+		},
+		{
+			name:     "PSETEX sets the value and its expiry in milliseconds",
+			commands: []string{"PSETEX k 1500 v", "GET k", "SLEEP 2", "GET k"},
+			expected: []interface{}{"OK", "v", "OK", "(nil)"},
+		},
+		{
+			name:     "PSETEX with an invalid expiry",
+			commands: []string{"PSETEX k 0 v", "PSETEX k abc v", "PSETEX k 10"},
+			expected: []interface{}{
+				"ERR invalid expire time in 'psetex' command",
+				"ERR value is not an integer or out of range",
+				"ERR wrong number of arguments for 'psetex' command",
+			},
+		},
+		{
+			name:     "SETNX only sets a missing key",
+			commands: []string{"SETNX k v1", "SETNX k v2", "GET k", "TTL k"},
+			expected: []interface{}{int64(1), int64(0), "v1", int64(-1)},
+		},
+		{
+			name:     "SETNX with wrong number of arguments",
+			commands: []string{"SETNX k"},
+			expected: []interface{}{"ERR wrong number of arguments for 'setnx' command"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			FireCommand(conn, "DEL k")
+			for i, cmd := range tc.commands {
+				assert.Equal(t, tc.expected[i], FireCommand(conn, cmd), "Value mismatch for cmd %s", cmd)
+			}
+		})
+	}
+}
+
+// TestSetNXPXLock checks the lock pattern SET key token NX PX: out of many clients racing
+// for the lock, exactly one acquires it and the lock is released when it expires.
+func TestSetNXPXLock(t *testing.T) {
+	const clients = 20
+
+	conn := getLocalConnection()
+	defer conn.Close()
+	FireCommand(conn, "DEL lock")
+	defer FireCommand(conn, "DEL lock")
+
+	results := make([]interface{}, clients)
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := getLocalConnection()
+			defer c.Close()
+			results[i] = FireCommand(c, fmt.Sprintf("SET lock token-%d NX PX 1000", i))
+		}(i)
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, result := range results {
+		if result == "OK" {
+			assert.Equal(t, -1, winner, "the lock was acquired by more than one client")
+			winner = i
+		} else {
+			assert.Equal(t, "(nil)", result)
+		}
+	}
+	assert.NotEqual(t, -1, winner, "the lock was not acquired")
+	assert.Equal(t, fmt.Sprintf("token-%d", winner), FireCommand(conn, "GET lock"))
+	assert.Greater(t, FireCommand(conn, "PTTL lock"), int64(0))
+
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, "OK", FireCommand(conn, "SET lock token NX PX 1000"))
+}
+
+// TestSetExpiryIsAtomic checks that a key written along with its expiry is never observed without it
+// while other clients keep rewriting it.
+func TestSetExpiryIsAtomic(t *testing.T) {
+	writes := []string{"SET atomic v EX 100", "SETEX atomic 100 v", "PSETEX atomic 100000 v", "SET atomic v PX 100000 GET"}
+
+	conn := getLocalConnection()
+	defer conn.Close()
+	FireCommand(conn, "DEL atomic")
+	defer FireCommand(conn, "DEL atomic")
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, write := range writes {
+		wg.Add(1)
+		go func(write string) {
+			defer wg.Done()
+			c := getLocalConnection()
+			defer c.Close()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					FireCommand(c, write)
+					FireCommand(c, "DEL atomic")
+				}
+			}
+		}(write)
+	}
+
+	for i := 0; i < 500; i++ {
+		// -2 when the key is missing, its remaining time to live otherwise, but never -1
+		assert.NotEqual(t, int64(-1), FireCommand(conn, "TTL atomic"))
+	}
+	close(done)
+	wg.Wait()
+}
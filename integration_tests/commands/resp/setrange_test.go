@@ -0,0 +1,98 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSETRANGE(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	testCases := []struct {
+		name     string
+		commands []string
+		expected []interface{}
+		cleanup  []string
+	}{
+		{
+			name:     "SETRANGE overwrites part of a string",
+			commands: []string{"SET k1 Hello", "SETRANGE k1 1 ipp", "GET k1", "OBJECT ENCODING k1"},
+			expected: []interface{}{"OK", int64(5), "Hippo", "raw"},
+			cleanup:  []string{"DEL k1"},
+		},
+		{
+			name:     "SETRANGE pads a missing key with zero bytes",
+			commands: []string{"SETRANGE k2 2 ab", "GET k2", "GETRANGE k2 2 -1"},
+			expected: []interface{}{int64(4), "\x00\x00ab", "ab"},
+			cleanup:  []string{"DEL k2"},
+		},
+		{
+			name:     "SETRANGE with an empty value",
+			commands: []string{"SETRANGE k3 5 \"\"", "EXISTS k3", "SET k3 abc", "SETRANGE k3 10 \"\""},
+			expected: []interface{}{int64(0), int64(0), "OK", int64(3)},
+			cleanup:  []string{"DEL k3"},
+		},
+		{
+			name:     "SETRANGE keeps the expiry of the key",
+			commands: []string{"SET k4 value EX 100", "SETRANGE k4 0 V", "TTL k4"},
+			expected: []interface{}{"OK", int64(5), int64(100)},
+			cleanup:  []string{"DEL k4"},
+		},
+		{
+			name:     "SETRANGE with invalid offsets",
+			commands: []string{"SETRANGE k5 -1 a", "SETRANGE k5 a a", "SETRANGE k5 536870911 ab"},
+			expected: []interface{}{
+				"ERR offset is out of range",
+				"ERR value is not an integer or out of range",
+				"ERR string exceeds maximum allowed size (proto-max-bulk-len)",
+			},
+			cleanup: []string{"DEL k5"},
+		},
+		{
+			name:     "SETRANGE on wrong key type",
+			commands: []string{"LPUSH k6 a", "SETRANGE k6 0 b"},
+			expected: []interface{}{int64(1), "WRONGTYPE Operation against a key holding the wrong kind of value"},
+			cleanup:  []string{"DEL k6"},
+		},
+		{
+			name:     "SETRANGE with wrong number of arguments",
+			commands: []string{"SETRANGE k7 0"},
+			expected: []interface{}{"ERR wrong number of arguments for 'setrange' command"},
+			cleanup:  []string{"DEL k7"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, cmd := range tc.commands {
+				result := FireCommand(conn, cmd)
+				assert.Equal(t, tc.expected[i], result, "Value mismatch for cmd %s", cmd)
+			}
+
+			for _, cmd := range tc.cleanup {
+				FireCommand(conn, cmd)
+			}
+		})
+	}
+}
@@ -51,14 +51,14 @@ type TestServerOptions struct {
 
 func init() {
 	parser := config.NewConfigParser()
-	if err := parser.ParseDefaults(config.DiceConfig); err != nil {
+	if err := parser.ParseDefaults(config.DiceConfig()); err != nil {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
 }
 
 //nolint:unused
 func getLocalConnection() net.Conn {
-	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", config.DiceConfig.RespServer.Port))
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", config.DiceConfig().RespServer.Port))
 	if err != nil {
 		panic(err)
 	}
@@ -114,7 +114,7 @@ func deleteTestKeys(keysToDelete []string, store *dstore.Store) {
 //nolint:unused
 func getLocalSdk() *dicedb.Client {
 	return dicedb.NewClient(&dicedb.Options{
-		Addr: fmt.Sprintf(":%d", config.DiceConfig.RespServer.Port),
+		Addr: fmt.Sprintf(":%d", config.DiceConfig().RespServer.Port),
 
 		DialTimeout:           10 * time.Second,
 		ReadTimeout:           30 * time.Second,
@@ -196,18 +196,18 @@ func fireCommandAndGetRESPParser(conn net.Conn, cmd string) *clientio.RESPParser
 }
 
 func RunTestServer(wg *sync.WaitGroup, opt TestServerOptions) {
-	config.DiceConfig.Network.IOBufferLength = 16
-	config.DiceConfig.Persistence.WriteAOFOnCleanup = false
+	config.DiceConfig().Network.IOBufferLength = 16
+	config.DiceConfig().Persistence.WriteAOFOnCleanup = false
 
 	// #1261: Added here to prevent resp integration tests from failing on lower-spec machines
-	config.DiceConfig.Memory.KeysLimit = 2000
+	config.DiceConfig().Memory.KeysLimit = 2000
 	if opt.Port != 0 {
-		config.DiceConfig.RespServer.Port = opt.Port
+		config.DiceConfig().RespServer.Port = opt.Port
 	} else {
-		config.DiceConfig.RespServer.Port = 9739
+		config.DiceConfig().RespServer.Port = 9739
 	}
 
-	cmdWatchChan := make(chan dstore.CmdWatchEvent, config.DiceConfig.Performance.WatchChanBufSize)
+	cmdWatchChan := make(chan dstore.CmdWatchEvent, config.DiceConfig().Performance.WatchChanBufSize)
 	cmdWatchSubscriptionChan := make(chan watchmanager.WatchSubscription)
 	gec := make(chan error)
 	shardManager := shard.NewShardManager(1, cmdWatchChan, gec)
@@ -217,7 +217,7 @@ func RunTestServer(wg *sync.WaitGroup, opt TestServerOptions) {
 	testServer := resp.NewServer(shardManager, ioThreadManager, cmdWatchSubscriptionChan, cmdWatchChan, gec, wl)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	fmt.Println("Starting the test server on port", config.DiceConfig.RespServer.Port)
+	fmt.Println("Starting the test server on port", config.DiceConfig().RespServer.Port)
 
 	shardManagerCtx, cancelShardManager := context.WithCancel(ctx)
 	wg.Add(1)
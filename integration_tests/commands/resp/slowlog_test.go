@@ -0,0 +1,68 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowlog(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	assert.Equal(t, "OK", FireCommand(conn, "CONFIG SET slowlog-log-slower-than 0"))
+	defer FireCommand(conn, "CONFIG SET slowlog-log-slower-than 10000")
+	FireCommand(conn, "SLOWLOG RESET")
+	defer FireCommand(conn, "SLOWLOG RESET")
+
+	FireCommand(conn, "SET slowlog_k1 v1")
+	FireCommand(conn, "GET slowlog_k1")
+
+	t.Run("SLOWLOG GET merges the entries of every shard", func(t *testing.T) {
+		entries, ok := FireCommand(conn, "SLOWLOG GET 2").([]interface{})
+		assert.True(t, ok)
+		assert.Len(t, entries, 2)
+
+		latest := entries[0].([]interface{})
+		assert.Len(t, latest, 6)
+		assert.Equal(t, []interface{}{"GET", "slowlog_k1"}, latest[3])
+		assert.Contains(t, latest[4], "127.0.0.1:")
+
+		previous := entries[1].([]interface{})
+		assert.Equal(t, []interface{}{"SET", "slowlog_k1", "v1"}, previous[3])
+		assert.Greater(t, latest[0], previous[0])
+	})
+
+	t.Run("SLOWLOG LEN and RESET", func(t *testing.T) {
+		length, ok := FireCommand(conn, "SLOWLOG LEN").(int64)
+		assert.True(t, ok)
+		assert.GreaterOrEqual(t, length, int64(2))
+
+		assert.Equal(t, "OK", FireCommand(conn, "CONFIG SET slowlog-log-slower-than -1"))
+		assert.Equal(t, "OK", FireCommand(conn, "SLOWLOG RESET"))
+		assert.Equal(t, int64(0), FireCommand(conn, "SLOWLOG LEN"))
+		assert.Equal(t, []interface{}{}, FireCommand(conn, "SLOWLOG GET"))
+	})
+
+	t.Run("SLOWLOG with invalid arguments", func(t *testing.T) {
+		assert.Equal(t, "ERR wrong number of arguments for 'slowlog' command", FireCommand(conn, "SLOWLOG"))
+		assert.Equal(t, "ERR count should be greater than or equal to -1", FireCommand(conn, "SLOWLOG GET -2"))
+		assert.Equal(t, "ERR unknown subcommand 'FOO'. Try SLOWLOG HELP.", FireCommand(conn, "SLOWLOG FOO"))
+	})
+}
@@ -0,0 +1,63 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSRANDMEMBER(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	members := []interface{}{"a", "b", "c"}
+	assert.Equal(t, int64(3), FireCommand(conn, "SADD myset a b c"))
+
+	t.Run("SRANDMEMBER without count", func(t *testing.T) {
+		assert.Contains(t, members, FireCommand(conn, "SRANDMEMBER myset"))
+		assert.Equal(t, "(nil)", FireCommand(conn, "SRANDMEMBER missing"))
+	})
+
+	t.Run("SRANDMEMBER with a positive count", func(t *testing.T) {
+		result := FireCommand(conn, "SRANDMEMBER myset 2").([]interface{})
+		assert.Len(t, result, 2)
+		assert.NotEqual(t, result[0], result[1])
+		assert.Subset(t, members, result)
+
+		assert.ElementsMatch(t, members, FireCommand(conn, "SRANDMEMBER myset 10"))
+		assert.Equal(t, []interface{}{}, FireCommand(conn, "SRANDMEMBER myset 0"))
+		assert.Equal(t, []interface{}{}, FireCommand(conn, "SRANDMEMBER missing 2"))
+	})
+
+	t.Run("SRANDMEMBER with a negative count", func(t *testing.T) {
+		result := FireCommand(conn, "SRANDMEMBER myset -8").([]interface{})
+		assert.Len(t, result, 8)
+		assert.Subset(t, members, result)
+	})
+
+	t.Run("SRANDMEMBER errors", func(t *testing.T) {
+		FireCommand(conn, "SET mystring value")
+		assert.Equal(t, "WRONGTYPE Operation against a key holding the wrong kind of value", FireCommand(conn, "SRANDMEMBER mystring"))
+		assert.Equal(t, "ERR value is not an integer or out of range", FireCommand(conn, "SRANDMEMBER myset abc"))
+		assert.Equal(t, "ERR syntax error", FireCommand(conn, "SRANDMEMBER myset 1 2"))
+		assert.Equal(t, "ERR wrong number of arguments for 'srandmember' command", FireCommand(conn, "SRANDMEMBER"))
+	})
+}
@@ -0,0 +1,52 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTRACE(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	assert.Equal(t, "(nil)", FireCommand(conn, "TRACE LAST"))
+	assert.Equal(t, "OK", FireCommand(conn, "TRACE ON"))
+	assert.Equal(t, "OK", FireCommand(conn, "SET k v"))
+	assert.Equal(t, "v", FireCommand(conn, "GET k"))
+
+	stages, ok := FireCommand(conn, "TRACE LAST").([]interface{})
+	require.True(t, ok)
+	require.Len(t, stages, 10)
+	for i, name := range []string{"parse_usec", "queue_usec", "eval_usec", "encode_usec", "write_usec"} {
+		assert.Equal(t, name, stages[2*i])
+		assert.GreaterOrEqual(t, stages[2*i+1], int64(0))
+	}
+	// TRACE LAST is not recorded itself
+	assert.Equal(t, stages, FireCommand(conn, "TRACE LAST"))
+
+	// The stages of the last command recorded are kept once tracing stops
+	assert.Equal(t, "OK", FireCommand(conn, "TRACE OFF"))
+	FireCommand(conn, "DEL k")
+	assert.Equal(t, stages, FireCommand(conn, "TRACE LAST"))
+
+	assert.Equal(t, "ERR syntax error", FireCommand(conn, "TRACE MAYBE"))
+	assert.Equal(t, "ERR wrong number of arguments for 'trace' command", FireCommand(conn, "TRACE"))
+}
@@ -0,0 +1,60 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUNDELETE(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+
+	t.Run("UNDELETE with the trash disabled", func(t *testing.T) {
+		FireCommand(conn, "SET trashed v")
+		assert.Equal(t, int64(1), FireCommand(conn, "DEL trashed"))
+		assert.Equal(t, int64(0), FireCommand(conn, "UNDELETE trashed"))
+	})
+
+	assert.Equal(t, "OK", FireCommand(conn, "CONFIG SET trash.enabled true"))
+	defer FireCommand(conn, "CONFIG SET trash.enabled false")
+
+	t.Run("UNDELETE restores a key deleted by DEL", func(t *testing.T) {
+		defer FireCommand(conn, "DEL trashed")
+		commands := []string{"SET trashed v EX 100", "DEL trashed", "GET trashed", "UNDELETE trashed", "GET trashed", "UNDELETE trashed"}
+		expected := []interface{}{"OK", int64(1), "(nil)", int64(1), "v", int64(0)}
+		for i, cmd := range commands {
+			assert.Equal(t, expected[i], FireCommand(conn, cmd), "Value mismatch for cmd %s", cmd)
+		}
+		assert.Positive(t, FireCommand(conn, "TTL trashed"))
+	})
+
+	t.Run("UNDELETE does not overwrite a key written again", func(t *testing.T) {
+		defer FireCommand(conn, "DEL trashed")
+		commands := []string{"SET trashed v", "DEL trashed", "SET trashed w", "UNDELETE trashed", "GET trashed"}
+		expected := []interface{}{"OK", int64(1), "OK", int64(0), "w"}
+		for i, cmd := range commands {
+			assert.Equal(t, expected[i], FireCommand(conn, cmd), "Value mismatch for cmd %s", cmd)
+		}
+	})
+
+	t.Run("UNDELETE with wrong number of arguments", func(t *testing.T) {
+		assert.Equal(t, "ERR wrong number of arguments for 'undelete' command", FireCommand(conn, "UNDELETE a b"))
+	})
+}
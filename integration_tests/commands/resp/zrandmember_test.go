@@ -0,0 +1,67 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZRANDMEMBER(t *testing.T) {
+	conn := getLocalConnection()
+	defer conn.Close()
+	FireCommand(conn, "FLUSHDB")
+	defer FireCommand(conn, "FLUSHDB")
+
+	scores := map[interface{}]interface{}{"a": "1", "b": "2.5", "c": "3"}
+	assert.Equal(t, int64(3), FireCommand(conn, "ZADD myzset 1 a 2.5 b 3 c"))
+
+	t.Run("ZRANDMEMBER without count", func(t *testing.T) {
+		assert.Contains(t, scores, FireCommand(conn, "ZRANDMEMBER myzset"))
+		assert.Equal(t, "(nil)", FireCommand(conn, "ZRANDMEMBER missing"))
+	})
+
+	t.Run("ZRANDMEMBER with a positive count", func(t *testing.T) {
+		result := FireCommand(conn, "ZRANDMEMBER myzset 5 WITHSCORES").([]interface{})
+		assert.Len(t, result, 6)
+		seen := make(map[interface{}]struct{})
+		for i := 0; i < len(result); i += 2 {
+			assert.Equal(t, scores[result[i]], result[i+1])
+			seen[result[i]] = struct{}{}
+		}
+		assert.Len(t, seen, 3)
+
+		assert.Equal(t, []interface{}{}, FireCommand(conn, "ZRANDMEMBER missing 2"))
+	})
+
+	t.Run("ZRANDMEMBER with a negative count", func(t *testing.T) {
+		result := FireCommand(conn, "ZRANDMEMBER myzset -5").([]interface{})
+		assert.Len(t, result, 5)
+		for _, member := range result {
+			assert.Contains(t, scores, member)
+		}
+	})
+
+	t.Run("ZRANDMEMBER errors", func(t *testing.T) {
+		FireCommand(conn, "SET mystring value")
+		assert.Equal(t, "WRONGTYPE Operation against a key holding the wrong kind of value", FireCommand(conn, "ZRANDMEMBER mystring"))
+		assert.Equal(t, "ERR value is not an integer or out of range", FireCommand(conn, "ZRANDMEMBER myzset abc"))
+		assert.Equal(t, "ERR syntax error", FireCommand(conn, "ZRANDMEMBER myzset 1 WITHVALUES"))
+		assert.Equal(t, "ERR wrong number of arguments for 'zrandmember' command", FireCommand(conn, "ZRANDMEMBER"))
+	})
+}
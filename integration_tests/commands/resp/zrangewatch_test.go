@@ -68,7 +68,7 @@ func TestZRANGEWATCH(t *testing.T) {
 		if !ok {
 			t.Errorf("Type assertion to []interface{} failed for value: %v", v)
 		}
-		assert.Equal(t, 3, len(castedValue))
+		assert.Equal(t, 4, len(castedValue))
 	}
 
 	// Fire updates to the sorted set and check if the subscribers receive the updates in the push-response form
@@ -82,7 +82,7 @@ func TestZRANGEWATCH(t *testing.T) {
 			if !ok {
 				t.Errorf("Type assertion to []interface{} failed for value: %v", v)
 			}
-			assert.Equal(t, 3, len(castedValue))
+			assert.Equal(t, 4, len(castedValue))
 			assert.Equal(t, "ZRANGE", castedValue[0])
 			assert.Equal(t, "1178068413", castedValue[1])
 			assert.Equal(t, tc.result, castedValue[2])
@@ -0,0 +1,74 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package websocket
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAllowedCommands(t *testing.T) {
+	for _, name := range []string{"alice", "bob"} {
+		user, err := auth.UserStore.Add(name)
+		require.NoError(t, err)
+		require.NoError(t, user.SetPassword(name+"-secret"))
+	}
+
+	exec := NewWebsocketCommandExecutor()
+	alice := exec.ConnectToServer()
+	bob := exec.ConnectToServer()
+	require.NotNil(t, alice)
+	require.NotNil(t, bob)
+
+	// refused returns the reply of a command refused by the allowlist, written as a plain message
+	refused := func(conn *websocket.Conn, cmd string) string {
+		require.NoError(t, exec.FireCommand(conn, cmd))
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		return string(msg)
+	}
+
+	result, err := exec.FireCommandAndReadResponse(alice, "AUTH alice wrong")
+	assert.NoError(t, err)
+	assert.Equal(t, "WRONGPASS invalid username-password pair or user is disabled", result)
+
+	for conn, name := range map[*websocket.Conn]string{alice: "alice", bob: "bob"} {
+		result, err := exec.FireCommandAndReadResponse(conn, "AUTH "+name+" "+name+"-secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "OK", result, name)
+	}
+
+	result, err = exec.FireCommandAndReadResponse(alice, "SET allowlist:k v")
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", result)
+	assert.Equal(t, "NOPERM the 'set' command is not allowed over WebSocket", refused(bob, "SET allowlist:k w"))
+	result, err = exec.FireCommandAndReadResponse(bob, "GET allowlist:k")
+	assert.NoError(t, err)
+	assert.Equal(t, "v", result)
+	assert.Equal(t, "NOPERM the 'del' command is not allowed over WebSocket", refused(alice, "DEL allowlist:k"))
+
+	// The connections not authenticated accept every command
+	other := exec.ConnectToServer()
+	require.NotNil(t, other)
+	result, err = exec.FireCommandAndReadResponse(other, "DEL allowlist:k")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, result)
+}
@@ -71,7 +71,7 @@ func TestDECRBY(t *testing.T) {
 		{
 			name:   "Decrement multiple keys",
 			cmds:   []string{"SET key1 3", fmt.Sprintf("SET key3 %s", strconv.Itoa(math.MinInt64+1)), "DECRBY key1 2", "DECRBY key1 1", "DECRBY key4 1", "DECRBY key3 1", fmt.Sprintf("DECRBY key3 %s", strconv.Itoa(math.MinInt64)), "DECRBY key5 abc"},
-			expect: []interface{}{"OK", "OK", float64(1), float64(0), float64(-1), float64(math.MinInt64), "ERR increment or decrement would overflow", "ERR value is not an integer or out of range"},
+			expect: []interface{}{"OK", "OK", float64(1), float64(0), float64(-1), float64(math.MinInt64), "ERR decrement would overflow", "ERR value is not an integer or out of range"},
 			delays: []time.Duration{0, 0, 0, 0, 0, 0, 0, 0},
 		},
 	}
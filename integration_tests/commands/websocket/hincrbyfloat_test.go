@@ -51,7 +51,7 @@ func TestHINCRBYFLOAT(t *testing.T) {
 		{
 			name:   "HINCRBYFLOAT on non-float or non-integer value",
 			cmds:   []string{"HSET keys field value", "HINCRBYFLOAT keys field 1.2"},
-			expect: []interface{}{float64(1), "ERR value is not an integer or a float"},
+			expect: []interface{}{float64(1), "ERR hash value is not a float"},
 			delays: []time.Duration{0, 0},
 		},
 		{
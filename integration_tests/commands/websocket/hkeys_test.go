@@ -35,7 +35,7 @@ func TestHKeys(t *testing.T) {
 		{
 			name:     "WS No keys exist",
 			commands: []string{"HKEYS key"},
-			expected: []interface{}{"*0"},
+			expected: []interface{}{[]interface{}{}},
 			delays:   []time.Duration{0},
 		},
 		{
@@ -35,7 +35,7 @@ func TestHVals(t *testing.T) {
 		{
 			name:     "WS No values exist",
 			commands: []string{"HVALS key"},
-			expected: []interface{}{"*0"},
+			expected: []interface{}{[]interface{}{}},
 			delays:   []time.Duration{3 * time.Second},
 		},
 		{
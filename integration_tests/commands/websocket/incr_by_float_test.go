@@ -28,7 +28,7 @@ func TestINCRBYFLOAT(t *testing.T) {
 	conn := exec.ConnectToServer()
 	invalidArgMessage := "ERR wrong number of arguments for 'incrbyfloat' command"
 	invalidIncrTypeMessage := "ERR value is not a valid float"
-	valueOutOfRangeMessage := "ERR value is out of range"
+	valueOutOfRangeMessage := "ERR increment would produce NaN or Infinity"
 
 	defer func() {
 		exec.FireCommandAndReadResponse(conn, "DEL foo")
@@ -61,7 +61,7 @@ func TestINCRBYFLOAT(t *testing.T) {
 		{
 			name:   "Increment and then decrement a key with the same value",
 			cmds:   []string{"SET foo 1", "INCRBYFLOAT foo 0.1", "GET foo", "INCRBYFLOAT foo -0.1", "GET foo"},
-			expect: []interface{}{"OK", "1.1", "1.1", "1", "1"},
+			expect: []interface{}{"OK", "1.1", "1.1", "1", float64(1)},
 			delays: []time.Duration{0, 0, 0, 0, 0},
 		},
 		{
@@ -58,7 +58,7 @@ type WebsocketCommandExecutor struct {
 
 func init() {
 	parser := config.NewConfigParser()
-	if err := parser.ParseDefaults(config.DiceConfig); err != nil {
+	if err := parser.ParseDefaults(config.DiceConfig()); err != nil {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
 }
@@ -105,6 +105,12 @@ func (e *WebsocketCommandExecutor) FireCommandAndReadResponse(conn *websocket.Co
 		return nil, fmt.Errorf("error unmarshaling response")
 	}
 
+	// The errors are replied along with their code, the tests compare their messages
+	if wsErr, ok := respJSON.(map[string]interface{}); ok && len(wsErr) == 2 && wsErr["code"] != nil {
+		if message, ok := wsErr["error"].(string); ok {
+			return message, nil
+		}
+	}
 	return respJSON, nil
 }
 
@@ -123,14 +129,16 @@ func (e *WebsocketCommandExecutor) Name() string {
 }
 
 func RunWebsocketServer(ctx context.Context, wg *sync.WaitGroup, opt TestServerOptions) {
-	config.DiceConfig.Network.IOBufferLength = 16
-	config.DiceConfig.Persistence.WriteAOFOnCleanup = false
+	config.DiceConfig().Network.IOBufferLength = 16
+	config.DiceConfig().Persistence.WriteAOFOnCleanup = false
 
 	// Initialize WebsocketServer
 	globalErrChannel := make(chan error)
 	shardManager := shard.NewShardManager(1, nil, globalErrChannel)
-	config.DiceConfig.WebSocket.Port = opt.Port
-	testServer := httpws.NewWebSocketServer(shardManager, testPort1, nil)
+	config.DiceConfig().WebSocket.Port = opt.Port
+	// The users of the allowlist tests, the other connections accepting every command
+	config.DiceConfig().WebSocket.UserAllowedCommands = []string{"alice:GET|SET", "bob:GET"}
+	testServer := httpws.NewWebSocketServer(shardManager, nil, nil, nil, testPort1, nil)
 	shardManagerCtx, cancelShardManager := context.WithCancel(ctx)
 
 	// run shard manager
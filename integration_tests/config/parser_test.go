@@ -323,3 +323,74 @@ func TestLoadconfig(t *testing.T) {
 		})
 	}
 }
+
+type secretConfig struct {
+	Auth struct {
+		Password string `config:"password" secret:"true"`
+	} `config:"auth"`
+}
+
+func TestParseSecrets(t *testing.T) {
+	tempDir := t.TempDir()
+	secretFile := filepath.Join(tempDir, "password")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to create secret file: %v", err)
+	}
+	t.Setenv("DICEDB_TEST_PASSWORD", "from-env")
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "inline secret",
+			content:  `auth.password = "inline"`,
+			expected: "inline",
+		},
+		{
+			name:     "secret from file",
+			content:  "auth.password = \"inline\"\nauth.password_file = \"" + secretFile + "\"",
+			expected: "from-file",
+		},
+		{
+			name:     "secret from environment",
+			content:  `auth.password_env = "DICEDB_TEST_PASSWORD"`,
+			expected: "from-env",
+		},
+		{
+			name:    "missing secret file",
+			content: `auth.password_file = "` + filepath.Join(tempDir, "missing") + `"`,
+			wantErr: true,
+		},
+		{
+			name:    "unset environment variable",
+			content: `auth.password_env = "DICEDB_TEST_UNSET_PASSWORD"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "dicedb.conf")
+			if err := os.WriteFile(filename, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			parser := config.NewConfigParser()
+			if err := parser.ParseFromFile(filename); err != nil {
+				t.Fatalf("Failed to parse test file: %v", err)
+			}
+
+			cfg := &secretConfig{}
+			err := parser.ParseDefaults(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.Auth.Password != tt.expected {
+				t.Errorf("Password = %q, want %q", cfg.Auth.Password, tt.expected)
+			}
+		})
+	}
+}
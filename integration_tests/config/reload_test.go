@@ -0,0 +1,125 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+)
+
+func TestReload(t *testing.T) {
+	defer func(memory int64, port int, password, path string) {
+		config.DiceConfig().Memory.MaxMemory = memory
+		config.DiceConfig().RespServer.Port = port
+		config.DiceConfig().Auth.Password = password
+		config.ConfigFilePath = path
+	}(config.DiceConfig().Memory.MaxMemory, config.DiceConfig().RespServer.Port, config.DiceConfig().Auth.Password, config.ConfigFilePath)
+
+	tempDir := t.TempDir()
+	secretFile := filepath.Join(tempDir, "password")
+	if err := os.WriteFile(secretFile, []byte("rotated\n"), 0600); err != nil {
+		t.Fatalf("Failed to create secret file: %v", err)
+	}
+
+	filename := filepath.Join(tempDir, "dicedb.conf")
+	content := "memory.max_memory = 4096\nasync_server.port = 9999\nauth.password_file = \"" + secretFile + "\"\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	config.ConfigFilePath = filename
+	config.DiceConfig().RespServer.Port = 7379
+
+	reloaded := false
+	config.OnReload(func() { reloaded = true })
+
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if config.DiceConfig().Memory.MaxMemory != 4096 {
+		t.Errorf("MaxMemory = %d, want 4096", config.DiceConfig().Memory.MaxMemory)
+	}
+	if config.DiceConfig().Auth.Password != "rotated" {
+		t.Errorf("Password was not re-read from the secret file")
+	}
+	if config.DiceConfig().RespServer.Port != 7379 {
+		t.Errorf("Port = %d, immutable parameters must not change on reload", config.DiceConfig().RespServer.Port)
+	}
+	if !reloaded {
+		t.Error("reload hook was not run")
+	}
+}
+
+func TestReloadInvalidConfig(t *testing.T) {
+	defer func(memory int64, path string) {
+		config.DiceConfig().Memory.MaxMemory = memory
+		config.ConfigFilePath = path
+	}(config.DiceConfig().Memory.MaxMemory, config.ConfigFilePath)
+
+	filename := filepath.Join(t.TempDir(), "dicedb.conf")
+	content := "memory.max_memory = 8192\nmemory.eviction_policy = \"bogus\"\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	config.ConfigFilePath = filename
+	config.DiceConfig().Memory.MaxMemory = 0
+
+	if err := config.Reload(); err == nil {
+		t.Fatal("Reload() expected an error for an invalid config")
+	}
+	if config.DiceConfig().Memory.MaxMemory != 0 {
+		t.Errorf("MaxMemory = %d, an invalid config must not be applied", config.DiceConfig().Memory.MaxMemory)
+	}
+}
+
+func TestReloadKeepsFlags(t *testing.T) {
+	defer func(password string, protected bool, path string) {
+		config.DiceConfig().Auth.Password = password
+		config.DiceConfig().Security.ProtectedMode = protected
+		config.ConfigFilePath = path
+	}(config.DiceConfig().Auth.Password, config.DiceConfig().Security.ProtectedMode, config.ConfigFilePath)
+
+	// The server is started with -requirepass on a config file without a password
+	flags := &config.Config{}
+	flag.StringVar(&flags.Auth.Password, "requirepass", "", "")
+	if err := flag.Set("requirepass", "secret"); err != nil {
+		t.Fatalf("Failed to set the flag: %v", err)
+	}
+	config.MergeFlags(flags)
+
+	filename := filepath.Join(t.TempDir(), "dicedb.conf")
+	if err := os.WriteFile(filename, []byte("security.protected_mode = false\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	config.ConfigFilePath = filename
+	config.DiceConfig().Security.ProtectedMode = true
+
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if config.DiceConfig().Auth.Password != "secret" {
+		t.Errorf("Password = %q, the flags must not be reverted on reload", config.DiceConfig().Auth.Password)
+	}
+	if config.DiceConfig().Security.ProtectedMode {
+		t.Error("ProtectedMode was not reloaded from the file")
+	}
+}
@@ -0,0 +1,77 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+)
+
+func TestRewriteConfigFile(t *testing.T) {
+	defer func(memory int64, password, path string) {
+		config.DiceConfig().Memory.MaxMemory = memory
+		config.DiceConfig().Auth.Password = password
+		config.ConfigFilePath = path
+	}(config.DiceConfig().Memory.MaxMemory, config.DiceConfig().Auth.Password, config.ConfigFilePath)
+
+	filename := filepath.Join(t.TempDir(), "dicedb.conf")
+	content := `# Memory Configuration
+memory.max_memory = 0
+auth.password_file = "/run/secrets/dicedb"
+`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	config.ConfigFilePath = filename
+
+	defer func(level string) { config.DiceConfig().Logging.LogLevel = level }(config.DiceConfig().Logging.LogLevel)
+	if err := config.SetParameters([]string{"maxmemory", "2048", "requirepass", "secret", "loglevel", "debug"}); err != nil {
+		t.Fatalf("SetParameters() error = %v", err)
+	}
+
+	if err := config.RewriteConfigFile(); err != nil {
+		t.Fatalf("RewriteConfigFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read rewritten file: %v", err)
+	}
+	rewritten := string(data)
+
+	for _, want := range []string{"# Memory Configuration\n", "memory.max_memory = 2048\n", "logging.log_level = \"debug\"\n", "auth.password_file = \"/run/secrets/dicedb\"\n"} {
+		if !strings.Contains(rewritten, want) {
+			t.Errorf("rewritten config missing %q:\n%s", want, rewritten)
+		}
+	}
+	if strings.Contains(rewritten, "secret") && strings.Contains(rewritten, "auth.password =") {
+		t.Errorf("rewritten config must not inline a secret referenced by file:\n%s", rewritten)
+	}
+}
+
+func TestRewriteConfigFileWithoutFile(t *testing.T) {
+	defer func(path string) { config.ConfigFilePath = path }(config.ConfigFilePath)
+
+	config.ConfigFilePath = ""
+	if err := config.RewriteConfigFile(); err == nil {
+		t.Error("RewriteConfigFile() expected an error without a config file")
+	}
+}
@@ -24,7 +24,7 @@ import (
 
 func init() {
 	parser := config.NewConfigParser()
-	if err := parser.ParseDefaults(config.DiceConfig); err != nil {
+	if err := parser.ParseDefaults(config.DiceConfig()); err != nil {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
 }
@@ -0,0 +1,51 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	commands "github.com/dicedb/dice/integration_tests/commands/resp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownCommand(t *testing.T) {
+	var wg sync.WaitGroup
+	commands.RunTestServer(&wg, testServerOptions)
+
+	time.Sleep(2 * time.Second)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig().RespServer.Port))
+	if err != nil {
+		t.Fatalf("Failed to connect to server: %v", err)
+	}
+	defer conn.Close()
+
+	assert.Equal(t, "ERR syntax error", commands.FireCommand(conn, "SHUTDOWN NOW"))
+	assert.Equal(t, "ERR syntax error", commands.FireCommand(conn, "SHUTDOWN SAVE NOSAVE"))
+
+	assert.Equal(t, "OK", commands.FireCommand(conn, "SHUTDOWN NOSAVE"))
+	wg.Wait()
+
+	_, err = net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.DiceConfig().RespServer.Port))
+	assert.Error(t, err, "Server did not shut down as expected")
+}
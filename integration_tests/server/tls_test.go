@@ -0,0 +1,135 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dicedb/dice/config"
+	commands "github.com/dicedb/dice/integration_tests/commands/resp"
+)
+
+// writeSelfSignedCert generates a self-signed certificate for localhost and writes the
+// certificate and key as PEM files into dir.
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "server.crt")
+	keyFile = filepath.Join(dir, "server.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return certFile, keyFile, cert
+}
+
+func TestTLSConnection(t *testing.T) {
+	var wg sync.WaitGroup
+	tlsPort := 8744
+
+	certFile, keyFile, cert := writeSelfSignedCert(t, t.TempDir())
+	config.DiceConfig().TLS.Port = tlsPort
+	config.DiceConfig().TLS.CertFile = certFile
+	config.DiceConfig().TLS.KeyFile = keyFile
+	config.DiceConfig().TLS.AuthClients = "no"
+	defer func() {
+		config.DiceConfig().TLS.Port = 0
+		config.DiceConfig().TLS.CertFile = ""
+		config.DiceConfig().TLS.KeyFile = ""
+	}()
+
+	commands.RunTestServer(&wg, commands.TestServerOptions{Port: 8743})
+	time.Sleep(2 * time.Second)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	conn, err := tls.Dial("tcp", fmt.Sprintf("localhost:%d", tlsPort), &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12})
+	if err != nil {
+		t.Fatalf("unexpected error while dialing tls port: %v", err)
+	}
+	defer conn.Close()
+
+	assert.Equal(t, "PONG", commands.FireCommand(conn, "PING"))
+	assert.Equal(t, "OK", commands.FireCommand(conn, "SET tlskey tlsvalue"))
+	assert.Equal(t, "tlsvalue", commands.FireCommand(conn, "GET tlskey"))
+
+	// A plaintext client must not be able to talk RESP on the TLS port
+	plain, err := getConnection(tlsPort)
+	if err == nil {
+		_ = plain.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, writeErr := plain.Write([]byte("*1\r\n$4\r\nPING\r\n"))
+		buf := make([]byte, 16)
+		n, _ := plain.Read(buf)
+		assert.False(t, writeErr == nil && string(buf[:n]) == "+PONG\r\n", "plaintext PING must not succeed on the tls port")
+		plain.Close()
+	}
+
+	assert.Equal(t, "OK", commands.FireCommand(conn, "ABORT"))
+	wg.Wait()
+}
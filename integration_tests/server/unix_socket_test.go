@@ -0,0 +1,68 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dicedb/dice/config"
+	commands "github.com/dicedb/dice/integration_tests/commands/resp"
+)
+
+func TestUnixSocketConnection(t *testing.T) {
+	var wg sync.WaitGroup
+
+	socketPath := filepath.Join(t.TempDir(), "dice.sock")
+	config.DiceConfig().RespServer.UnixSocket = socketPath
+	config.DiceConfig().RespServer.UnixSocketPerm = "0770"
+	defer func() {
+		config.DiceConfig().RespServer.UnixSocket = ""
+		config.DiceConfig().RespServer.UnixSocketPerm = "0700"
+	}()
+
+	commands.RunTestServer(&wg, commands.TestServerOptions{Port: 8745})
+	time.Sleep(2 * time.Second)
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("unix socket was not created: %v", err)
+	}
+	assert.Equal(t, os.FileMode(0o770), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("unexpected error while dialing unix socket: %v", err)
+	}
+	defer conn.Close()
+
+	assert.Equal(t, "PONG", commands.FireCommand(conn, "PING"))
+	assert.Equal(t, "OK", commands.FireCommand(conn, "SET unixkey unixvalue"))
+	assert.Equal(t, "unixvalue", commands.FireCommand(conn, "GET unixkey"))
+
+	assert.Equal(t, "OK", commands.FireCommand(conn, "SHUTDOWN NOSAVE"))
+	wg.Wait()
+
+	_, err = os.Stat(socketPath)
+	assert.True(t, os.IsNotExist(err), "unix socket must be removed on shutdown")
+}
@@ -0,0 +1,96 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package accesslog logs the commands executed on behalf of clients as structured records.
+// Logging is opt-in and sampled, and the values written by commands are redacted so that
+// enabling it in production does not leak data.
+package accesslog
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	_ "github.com/dicedb/dice/internal/eval" // registers the key specifications of the commands
+)
+
+const redacted = "(redacted)"
+
+// credentialCommands have all of their arguments redacted as they may carry passwords.
+var credentialCommands = map[string]bool{
+	"AUTH":   true,
+	"CONFIG": true,
+	"HELLO":  true,
+}
+
+// Log records the execution of a command if the access log is enabled and the command is sampled.
+// response is the reply sent to the client, either a value or an error.
+func Log(client string, diceDBCmd *cmd.DiceDBCmd, elapsed time.Duration, response interface{}) {
+	if !config.DiceConfig().AccessLog.Enabled || !sampled(config.DiceConfig().AccessLog.SampleRate) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("client", client),
+		slog.String("command", diceDBCmd.Cmd),
+		slog.Duration("duration", elapsed),
+		slog.Int("result_size", clientio.EncodedSize(response, false)),
+	}
+	if config.DiceConfig().AccessLog.LogArgs {
+		attrs = append(attrs, slog.Any("args", redactArgs(diceDBCmd)))
+	}
+	if err := audit.ResponseError(response); err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	slog.LogAttrs(context.Background(), slog.LevelInfo, "command executed", attrs...)
+}
+
+func sampled(rate float64) bool {
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// redactArgs returns the arguments of the command safe to be logged: every argument of the
+// commands carrying credentials and every argument but the keys of write commands are redacted.
+func redactArgs(diceDBCmd *cmd.DiceDBCmd) []string {
+	args := make([]string, len(diceDBCmd.Args))
+	switch {
+	case credentialCommands[diceDBCmd.Cmd]:
+		for i := range args {
+			args[i] = redacted
+		}
+	case audit.Categorize(diceDBCmd.Cmd) == audit.CategoryWrite:
+		keys := make(map[string]bool)
+		for _, key := range cmd.Keys(diceDBCmd) {
+			keys[key] = true
+		}
+		for i, arg := range diceDBCmd.Args {
+			if keys[arg] {
+				args[i] = arg
+			} else {
+				args[i] = redacted
+			}
+		}
+	default:
+		copy(args, diceDBCmd.Args)
+	}
+	return args
+}
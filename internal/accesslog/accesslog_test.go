@@ -0,0 +1,64 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactArgs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cmd      *cmd.DiceDBCmd
+		expected []string
+	}{
+		{
+			name:     "read commands are logged as is",
+			cmd:      &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k1"}},
+			expected: []string{"k1"},
+		},
+		{
+			name:     "values of write commands are redacted",
+			cmd:      &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k1", "secret", "EX", "10"}},
+			expected: []string{"k1", redacted, redacted, redacted},
+		},
+		{
+			name:     "fields of hashes are redacted along with their values",
+			cmd:      &cmd.DiceDBCmd{Cmd: "HSET", Args: []string{"k1", "f1", "v1"}},
+			expected: []string{"k1", redacted, redacted},
+		},
+		{
+			name:     "credentials are redacted",
+			cmd:      &cmd.DiceDBCmd{Cmd: "AUTH", Args: []string{"dice", "password"}},
+			expected: []string{redacted, redacted},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, redactArgs(tc.cmd))
+		})
+	}
+}
+
+func TestSampled(t *testing.T) {
+	assert.True(t, sampled(1))
+	assert.False(t, sampled(0))
+}
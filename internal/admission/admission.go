@@ -0,0 +1,83 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package admission sheds the commands received while the server is overloaded, so that they are refused
+// right away rather than queued without bound in front of saturated shards.
+//
+// Two limits apply, both disabled by default:
+//   - performance.max_frontend_inflight bounds the number of commands every frontend executes at once.
+//   - performance.max_shard_queue_depth bounds the number of operations queued to a shard.
+//
+// A shed command gets diceerrors.ErrOverloaded, an HTTP 503 or a WebSocket close frame 1013 and is counted
+// by the requests_shed_total metric.
+package admission
+
+import (
+	"sync/atomic"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/metrics"
+)
+
+// Limiter bounds the number of commands a frontend executes at once.
+type Limiter struct {
+	transport string
+	inflight  atomic.Int64
+}
+
+// NewLimiter creates the limiter of the frontend serving the transport, one of the metrics transports.
+func NewLimiter(transport string) *Limiter {
+	return &Limiter{transport: transport}
+}
+
+// Acquire reserves a slot for a command, to be released with Release once it is executed. It returns false,
+// recording the command as shed, once the frontend already executes performance.max_frontend_inflight
+// commands. A nil Limiter admits every command.
+func (l *Limiter) Acquire() bool {
+	if l == nil {
+		return true
+	}
+
+	inflight := l.inflight.Add(1)
+	if limit := config.DiceConfig().Performance.MaxFrontendInflight; limit > 0 && inflight > limit {
+		l.inflight.Add(-1)
+		metrics.RequestShed(l.transport, metrics.ShedFrontendInflight)
+		return false
+	}
+	return true
+}
+
+// Release frees the slot of a command admitted by Acquire.
+func (l *Limiter) Release() {
+	if l != nil {
+		l.inflight.Add(-1)
+	}
+}
+
+// Inflight returns the number of commands being executed by the frontend.
+func (l *Limiter) Inflight() int64 {
+	return l.inflight.Load()
+}
+
+// Saturated reports whether a shard with queued operations pending is saturated, in which case the commands
+// it would execute are shed. A command routed over the transport is then recorded as shed.
+func Saturated(transport string, queued int) bool {
+	if limit := config.DiceConfig().Performance.MaxShardQueueDepth; limit > 0 && queued >= limit {
+		metrics.RequestShed(transport, metrics.ShedShardQueue)
+		return true
+	}
+	return false
+}
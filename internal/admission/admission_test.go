@@ -0,0 +1,69 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func withLimits(t *testing.T, shardQueueDepth int, frontendInflight int64) {
+	previous := config.DiceConfig().Performance
+	config.DiceConfig().Performance.MaxShardQueueDepth = shardQueueDepth
+	config.DiceConfig().Performance.MaxFrontendInflight = frontendInflight
+	t.Cleanup(func() { config.DiceConfig().Performance = previous })
+}
+
+func TestLimiter(t *testing.T) {
+	withLimits(t, 0, 2)
+	l := NewLimiter(metrics.TransportRESP)
+
+	assert.True(t, l.Acquire())
+	assert.True(t, l.Acquire())
+	assert.False(t, l.Acquire())
+	assert.Equal(t, int64(2), l.Inflight())
+
+	l.Release()
+	assert.True(t, l.Acquire())
+
+	// The limit is read on every command, it can be changed at runtime
+	config.DiceConfig().Performance.MaxFrontendInflight = 0
+	assert.True(t, l.Acquire())
+	assert.Equal(t, int64(3), l.Inflight())
+}
+
+func TestNilLimiter(t *testing.T) {
+	withLimits(t, 0, 1)
+	var l *Limiter
+
+	assert.True(t, l.Acquire())
+	assert.True(t, l.Acquire())
+	l.Release()
+}
+
+func TestSaturated(t *testing.T) {
+	withLimits(t, 10, 0)
+
+	assert.False(t, Saturated(metrics.TransportHTTP, 9))
+	assert.True(t, Saturated(metrics.TransportHTTP, 10))
+
+	config.DiceConfig().Performance.MaxShardQueueDepth = 0
+	assert.False(t, Saturated(metrics.TransportHTTP, 1000))
+}
@@ -0,0 +1,197 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	_ "github.com/dicedb/dice/internal/eval" // registers the key specifications of the commands
+)
+
+const (
+	OutputFile   = "file"
+	OutputSyslog = "syslog"
+
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Record is a single audit entry. Records are written as one JSON object per line.
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Client    string    `json:"client"`
+	User      string    `json:"user"`
+	Category  Category  `json:"category"`
+	Command   string    `json:"command"`
+	Keys      []string  `json:"keys,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Logger appends audit records for the configured command categories to its writer.
+// It is safe for concurrent use by multiple io-threads.
+type Logger struct {
+	mu         sync.Mutex
+	w          io.WriteCloser
+	categories map[Category]bool
+}
+
+// defaultLogger is the process wide audit logger, nil when auditing is disabled.
+var defaultLogger *Logger
+
+// NewLogger creates a Logger writing records of the given categories to w.
+func NewLogger(w io.WriteCloser, categories []string) (*Logger, error) {
+	l := &Logger{
+		w:          w,
+		categories: make(map[Category]bool),
+	}
+
+	for _, c := range categories {
+		category, err := ParseCategory(c)
+		if err != nil {
+			return nil, err
+		}
+		l.categories[category] = true
+	}
+
+	return l, nil
+}
+
+// Init sets up the process wide audit logger from the audit section of the config.
+// It is a no-op when auditing is disabled.
+func Init() error {
+	if !config.DiceConfig().Audit.Enabled {
+		return nil
+	}
+
+	var (
+		w   io.WriteCloser
+		err error
+	)
+
+	switch config.DiceConfig().Audit.Output {
+	case OutputSyslog:
+		w, err = openSyslog()
+	case OutputFile:
+		w, err = newRotatingFile(config.DiceConfig().Audit.FilePath,
+			int64(config.DiceConfig().Audit.MaxFileSizeMB)*1024*1024, config.DiceConfig().Audit.MaxBackups)
+	default:
+		err = fmt.Errorf("unsupported audit output: %s", config.DiceConfig().Audit.Output)
+	}
+	if err != nil {
+		return fmt.Errorf("could not open audit log: %w", err)
+	}
+
+	l, err := NewLogger(w, config.DiceConfig().Audit.Categories)
+	if err != nil {
+		w.Close()
+		return err
+	}
+
+	defaultLogger = l
+	return nil
+}
+
+// Close flushes and closes the process wide audit logger.
+func Close() {
+	if defaultLogger == nil {
+		return
+	}
+
+	if err := defaultLogger.Close(); err != nil {
+		slog.Warn("could not close audit log", slog.Any("error", err))
+	}
+	defaultLogger = nil
+}
+
+// Log records the execution of a command on the process wide audit logger.
+// outcome is the error returned to the client, nil if the command succeeded.
+func Log(client, user string, diceDBCmd *cmd.DiceDBCmd, outcome error) {
+	if defaultLogger == nil {
+		return
+	}
+	defaultLogger.Log(client, user, diceDBCmd, outcome)
+}
+
+// ResponseError extracts the error returned to the client from a command response,
+// which is either an error value or a RESP encoded error. It returns nil for successful responses.
+func ResponseError(response interface{}) error {
+	switch v := response.(type) {
+	case error:
+		return v
+	case []byte:
+		if len(v) > 0 && v[0] == '-' {
+			return errors.New(strings.TrimSpace(string(v[1:])))
+		}
+	}
+	return nil
+}
+
+// Log records the execution of a command if its category is audited.
+func (l *Logger) Log(client, user string, diceDBCmd *cmd.DiceDBCmd, outcome error) {
+	category := Categorize(diceDBCmd.Cmd)
+	if !l.categories[category] {
+		return
+	}
+
+	if user == "" {
+		user = config.DiceConfig().Auth.UserName
+	}
+
+	record := Record{
+		Timestamp: time.Now().UTC(),
+		Client:    client,
+		User:      user,
+		Category:  category,
+		Command:   diceDBCmd.Cmd,
+		Keys:      cmd.Keys(diceDBCmd),
+		Outcome:   OutcomeSuccess,
+	}
+	if outcome != nil {
+		record.Outcome = OutcomeFailure
+		record.Error = outcome.Error()
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		slog.Warn("could not encode audit record", slog.Any("error", err))
+		return
+	}
+	b = append(b, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(b); err != nil {
+		slog.Warn("could not write audit record", slog.Any("error", err))
+	}
+}
+
+// Close closes the underlying writer of the logger.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Close()
+}
@@ -0,0 +1,110 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type nopCloser struct {
+	bytes.Buffer
+}
+
+func (*nopCloser) Close() error { return nil }
+
+func readRecords(t *testing.T, b *bytes.Buffer) []Record {
+	var records []Record
+	scanner := bufio.NewScanner(b)
+	for scanner.Scan() {
+		var r Record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLoggerCategories(t *testing.T) {
+	buf := &nopCloser{}
+	l, err := NewLogger(buf, []string{"admin", "write"})
+	assert.NoError(t, err)
+
+	l.Log("127.0.0.1:5000", "alice", &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k1"}}, nil)
+	l.Log("127.0.0.1:5000", "alice", &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k1", "v1"}}, nil)
+	l.Log("127.0.0.1:5000", "alice", &cmd.DiceDBCmd{Cmd: "FLUSHDB"}, errors.New("ERR boom"))
+
+	records := readRecords(t, &buf.Buffer)
+	assert.Len(t, records, 2)
+
+	assert.Equal(t, "SET", records[0].Command)
+	assert.Equal(t, CategoryWrite, records[0].Category)
+	assert.Equal(t, []string{"k1"}, records[0].Keys)
+	assert.Equal(t, "alice", records[0].User)
+	assert.Equal(t, OutcomeSuccess, records[0].Outcome)
+
+	assert.Equal(t, "FLUSHDB", records[1].Command)
+	assert.Equal(t, CategoryAdmin, records[1].Category)
+	assert.Equal(t, OutcomeFailure, records[1].Outcome)
+	assert.Equal(t, "ERR boom", records[1].Error)
+}
+
+func TestNewLoggerInvalidCategory(t *testing.T) {
+	_, err := NewLogger(&nopCloser{}, []string{"admin", "everything"})
+	assert.Error(t, err)
+}
+
+func TestResponseError(t *testing.T) {
+	assert.Nil(t, ResponseError([]byte("+OK\r\n")))
+	assert.Nil(t, ResponseError("OK"))
+	assert.EqualError(t, ResponseError([]byte("-ERR wrong type\r\n")), "ERR wrong type")
+	assert.EqualError(t, ResponseError(errors.New("ERR syntax error")), "ERR syntax error")
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	f, err := newRotatingFile(path, 10, 2)
+	assert.NoError(t, err)
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		_, err := f.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, f.Close())
+
+	current, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "dddddddd\n", string(current))
+
+	first, err := os.ReadFile(path + ".1")
+	assert.NoError(t, err)
+	assert.Equal(t, "cccccccc\n", string(first))
+
+	second, err := os.ReadFile(path + ".2")
+	assert.NoError(t, err)
+	assert.Equal(t, "bbbbbbbb\n", string(second))
+
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,159 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Category groups commands for the purpose of auditing.
+type Category string
+
+const (
+	// CategoryAdmin covers commands that affect the server or the whole keyspace.
+	CategoryAdmin Category = "admin"
+	// CategoryWrite covers commands that modify keys.
+	CategoryWrite Category = "write"
+	// CategoryRead covers every other command.
+	CategoryRead Category = "read"
+)
+
+var adminCommands = map[string]bool{
+	"ABORT":    true,
+	"AUTH":     true,
+	"BIGKEYS":  true,
+	"CLIENT":   true,
+	"CONFIG":   true,
+	"DEBUG":    true,
+	"FLUSHALL": true,
+	"FLUSHDB":  true,
+	"SHUTDOWN": true,
+}
+
+var writeCommands = map[string]bool{
+	"APPEND":         true,
+	"BF.ADD":         true,
+	"BF.RESERVE":     true,
+	"BITFIELD":       true,
+	"BLPOP":          true,
+	"BRPOP":          true,
+	"BZPOPMAX":       true,
+	"BZPOPMIN":       true,
+	"CACHELOAD":      true,
+	"CAS":            true,
+	"CMS.INCRBY":     true,
+	"CMS.INITBYDIM":  true,
+	"CMS.INITBYPROB": true,
+	"CMS.MERGE":      true,
+	"COPY":           true,
+	"DECR":           true,
+	"DECRBY":         true,
+	"DEL":            true,
+	"DELIFEQ":        true,
+	"EXPIRE":         true,
+	"EXPIREAT":       true,
+	"GEOADD":         true,
+	"GETDEL":         true,
+	"GETEX":          true,
+	"GETSET":         true,
+	"HDEL":           true,
+	"HEXPIRE":        true,
+	"HGETDEL":        true,
+	"HGETEX":         true,
+	"HINCRBY":        true,
+	"HINCRBYFLOAT":   true,
+	"HMSET":          true,
+	"HPERSIST":       true,
+	"HPEXPIRE":       true,
+	"HSET":           true,
+	"HSETNX":         true,
+	"INCR":           true,
+	"INCRBY":         true,
+	"INCRBYFLOAT":    true,
+	"JSON.ARRAPPEND": true,
+	"JSON.ARRINSERT": true,
+	"JSON.ARRPOP":    true,
+	"JSON.ARRTRIM":   true,
+	"JSON.CLEAR":     true,
+	"JSON.DEL":       true,
+	"JSON.FORGET":    true,
+	"JSON.INGEST":    true,
+	"JSON.NUMINCRBY": true,
+	"JSON.NUMMULTBY": true,
+	"JSON.SET":       true,
+	"JSON.STRAPPEND": true,
+	"JSON.TOGGLE":    true,
+	"LINSERT":        true,
+	"LOCK":           true,
+	"LPOP":           true,
+	"LPUSH":          true,
+	"MSET":           true,
+	"MSETCAS":        true,
+	"OBJECTCOPY":     true,
+	"PERSIST":        true,
+	"PEXPIRE":        true,
+	"PEXPIREIFEQ":    true,
+	"PFADD":          true,
+	"PFMERGE":        true,
+	"PSETEX":         true,
+	"RENAME":         true,
+	"RESTORE":        true,
+	"RPOP":           true,
+	"RPUSH":          true,
+	"SADD":           true,
+	"SET":            true,
+	"SETBIT":         true,
+	"SETEX":          true,
+	"SETNX":          true,
+	"SETRANGE":       true,
+	"SREM":           true,
+	"UNDELETE":       true,
+	"UNLOCK":         true,
+	"ZADD":           true,
+	"ZPOPMAX":        true,
+	"ZPOPMIN":        true,
+	"ZREM":           true,
+}
+
+// ParseCategory converts a category name from the config into a Category.
+func ParseCategory(name string) (Category, error) {
+	switch c := Category(strings.ToLower(strings.TrimSpace(name))); c {
+	case CategoryAdmin, CategoryWrite, CategoryRead:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unknown audit category: %s", name)
+	}
+}
+
+// Categorize returns the audit category of a command.
+func Categorize(command string) Category {
+	switch {
+	case adminCommands[command]:
+		return CategoryAdmin
+	case writeCommands[command]:
+		return CategoryWrite
+	default:
+		return CategoryRead
+	}
+}
+
+// RegisterWrite categorizes a command added at startup, e.g. the command of a module, as a write. It must be
+// called before the commands are executed.
+func RegisterWrite(command string) {
+	writeCommands[command] = true
+}
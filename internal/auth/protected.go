@@ -0,0 +1,41 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"net"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// AllowConnection reports whether a client connecting from remoteAddr ("host:port" or a bare IP)
+// may use the server. In protected mode, when no password is configured, only clients on the
+// loopback interface and clients that presented a verified TLS certificate are accepted.
+func AllowConnection(remoteAddr string, certVerified bool) bool {
+	if !config.DiceConfig().Security.ProtectedMode || config.DiceConfig().Auth.Password != utils.EmptyStr || certVerified {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,45 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowConnection(t *testing.T) {
+	defer func(protectedMode bool, password string) {
+		config.DiceConfig().Security.ProtectedMode = protectedMode
+		config.DiceConfig().Auth.Password = password
+	}(config.DiceConfig().Security.ProtectedMode, config.DiceConfig().Auth.Password)
+
+	config.DiceConfig().Security.ProtectedMode = false
+	config.DiceConfig().Auth.Password = ""
+	assert.True(t, AllowConnection("10.0.0.5:5000", false))
+
+	config.DiceConfig().Security.ProtectedMode = true
+	assert.True(t, AllowConnection("127.0.0.1:5000", false))
+	assert.True(t, AllowConnection("[::1]:5000", false))
+	assert.False(t, AllowConnection("10.0.0.5:5000", false))
+	assert.False(t, AllowConnection("not-an-address", false))
+	assert.True(t, AllowConnection("10.0.0.5:5000", true))
+
+	config.DiceConfig().Auth.Password = "secret"
+	assert.True(t, AllowConnection("10.0.0.5:5000", false))
+}
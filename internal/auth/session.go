@@ -22,6 +22,7 @@ import (
 	"sync"
 	"time"
 
+	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/server/utils"
 
 	"github.com/dicedb/dice/config"
@@ -49,6 +50,10 @@ type (
 		CreatedAt      time.Time
 		LastAccessedAt time.Time
 
+		// PeerIdentity is the subject common name of the verified client
+		// certificate presented over TLS, empty for plaintext connections.
+		PeerIdentity string
+
 		Status SessionStatusT
 	}
 
@@ -121,7 +126,7 @@ func NewSession() (session *Session) {
 }
 
 func (session *Session) IsActive() (isActive bool) {
-	if config.DiceConfig.Auth.Password == utils.EmptyStr && session.Status != SessionStatusActive {
+	if config.DiceConfig().Auth.Password == utils.EmptyStr && session.Status != SessionStatusActive {
 		session.Activate(session.User)
 	}
 	isActive = session.Status == SessionStatusActive
@@ -146,7 +151,7 @@ func (session *Session) Validate(username, password string) error {
 	if user, err = UserStore.Get(username); err != nil {
 		return err
 	}
-	if username == config.DiceConfig.Auth.UserName && len(user.Passwords) == 0 {
+	if username == config.DiceConfig().Auth.UserName && len(user.Passwords) == 0 {
 		session.Activate(user)
 		return nil
 	}
@@ -157,7 +162,24 @@ func (session *Session) Validate(username, password string) error {
 		session.Activate(user)
 		return nil
 	}
-	return fmt.Errorf("WRONGPASS invalid username-password pair or user is disabled")
+	return diceerrors.ErrWrongPass
+}
+
+// ValidatePeerIdentity activates the session for the user whose username matches
+// the identity extracted from a verified client certificate.
+func (session *Session) ValidatePeerIdentity(identity string) error {
+	if identity == utils.EmptyStr {
+		return fmt.Errorf("ERR no verified client certificate presented")
+	}
+
+	user, err := UserStore.Get(identity)
+	if err != nil {
+		return err
+	}
+
+	session.PeerIdentity = identity
+	session.Activate(user)
+	return nil
 }
 
 func (session *Session) Expire() {
@@ -99,7 +99,7 @@ func TestSessionIsActive(t *testing.T) {
 	mockTime := &utils.MockClock{CurrTime: time.Now()}
 	utils.CurrentTime = mockTime
 
-	config.DiceConfig.Auth.Password = "testpassword"
+	config.DiceConfig().Auth.Password = "testpassword"
 	session := NewSession()
 	if session.IsActive() {
 		t.Error("New session should not be active")
@@ -117,12 +117,12 @@ func TestSessionIsActive(t *testing.T) {
 	if !session.LastAccessedAt.After(oldLastAccessed) {
 		t.Error("IsActive() should update LastAccessedAt")
 	}
-	config.DiceConfig.Auth.Password = utils.EmptyStr
+	config.DiceConfig().Auth.Password = utils.EmptyStr
 }
 
 func TestSessionActivate(t *testing.T) {
 	session := NewSession()
-	user := &User{Username: config.DiceConfig.Auth.UserName}
+	user := &User{Username: config.DiceConfig().Auth.UserName}
 
 	session.Activate(user)
 
@@ -135,7 +135,7 @@ func TestSessionActivate(t *testing.T) {
 }
 
 func TestSessionValidate(t *testing.T) {
-	username := config.DiceConfig.Auth.UserName
+	username := config.DiceConfig().Auth.UserName
 	password := "testpassword"
 
 	user, _ := UserStore.Add(username)
@@ -158,6 +158,30 @@ func TestSessionValidate(t *testing.T) {
 	}
 }
 
+func TestSessionValidatePeerIdentity(t *testing.T) {
+	identity := "cert-user"
+	user, _ := UserStore.Add(identity)
+
+	session := NewSession()
+	if err := session.ValidatePeerIdentity(identity); err != nil {
+		t.Errorf("Session.ValidatePeerIdentity() returned an error: %v", err)
+	}
+	if session.Status != SessionStatusActive {
+		t.Error("Session.ValidatePeerIdentity() did not activate the session")
+	}
+	if session.User != user || session.PeerIdentity != identity {
+		t.Error("Session.ValidatePeerIdentity() did not bind the certificate user")
+	}
+
+	session = NewSession()
+	if err := session.ValidatePeerIdentity("unknown-user"); err == nil {
+		t.Error("Session.ValidatePeerIdentity() did not return an error for an unknown identity")
+	}
+	if err := session.ValidatePeerIdentity(utils.EmptyStr); err == nil {
+		t.Error("Session.ValidatePeerIdentity() did not return an error for an empty identity")
+	}
+}
+
 func TestSessionExpire(t *testing.T) {
 	session := NewSession()
 	session.Expire()
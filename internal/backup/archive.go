@@ -0,0 +1,82 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// walPrefix is the prefix of the names of the WAL segments archived to the target
+const walPrefix = "wal/"
+
+// Archiver uploads the segments of the WAL to the target once they are rotated, so that the commands applied
+// since the last backup are kept off the host too.
+type Archiver struct {
+	target   Target
+	segments chan string
+}
+
+// NewArchiver returns an archiver of the WAL segments to the target.
+func NewArchiver(target Target) *Archiver {
+	return &Archiver{target: target, segments: make(chan string, 64)}
+}
+
+// Archive queues the upload of the segment at the path, rotated. It does not block the WAL: a segment is
+// dropped, and reported, if the uploads fall too far behind.
+func (a *Archiver) Archive(path string) {
+	select {
+	case a.segments <- path:
+	default:
+		slog.Warn("could not archive a WAL segment, the archival falls behind", slog.String("path", path))
+	}
+}
+
+// Run uploads the segments queued until the context is canceled.
+func (a *Archiver) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case path := <-a.segments:
+			if err := a.upload(ctx, path); err != nil && ctx.Err() == nil {
+				slog.Warn("could not archive a WAL segment", slog.String("path", path), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// upload uploads the segment, named after the time it is archived at as the segments are numbered from 0 again
+// when the server restarts.
+func (a *Archiver) upload(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	name := walPrefix + time.Now().UTC().Format(nameLayout) + "-" + filepath.Base(path)
+	size, err := a.target.Write(ctx, name, f)
+	if err != nil {
+		return err
+	}
+	slog.Debug("archived a WAL segment", slog.String("name", name), slog.Int64("size", size))
+	return nil
+}
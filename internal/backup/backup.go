@@ -0,0 +1,275 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package backup takes backups of the keyspace on a cron-like schedule, backup.schedule, and keeps the
+// recent ones: the last backup.keep_last backups, the last one of each of the last backup.keep_daily days and
+// the last one of each of the last backup.keep_weekly weeks, the older ones being deleted once a backup is
+// taken.
+//
+// A backup is an EXPORT of every key as JSON Lines, which IMPORT restores. The keys of a shard are exported in
+// batches, so that the shards keep serving the clients during a backup, and a backup is not a point in time
+// snapshot of the keyspace. The status of the last backup is reported by INFO persistence.
+//
+// The backups are stored in a directory, or in the bucket of an S3-compatible object store. The segments of the
+// WAL can be archived to the same target once rotated, and a backup restored on startup from its URL.
+package backup
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/export"
+)
+
+const (
+	// namePrefix and nameSuffix surround the time a backup was started at in its name
+	namePrefix = "dicedb-"
+	nameSuffix = ".jsonl"
+	// nameLayout is the layout of the time in the name of a backup, in UTC
+	nameLayout = "20060102T150405Z"
+)
+
+// Status is the status of the backups, as reported by INFO persistence.
+type Status struct {
+	Enabled    bool
+	InProgress bool
+	Next       time.Time // Next is the time of the next backup, zero while none is scheduled
+	Retained   int       // Retained is the number of backups kept by the target
+
+	// The fields of the last backup, zero before the first one
+	LastAt       time.Time // LastAt is the time the last backup was started at
+	LastErr      error     // LastErr is the error the last backup failed with, nil if it succeeded
+	LastDuration time.Duration
+	LastName     string
+	LastKeys     int64
+	LastSize     int64
+
+	// The fields of the restore of a backup running in the background while the clients are accepted
+	Loading    bool
+	LoadedKeys int64
+	LoadErr    error // LoadErr is the error the restore failed with, nil unless it failed
+}
+
+var (
+	statusMu sync.Mutex
+	status   Status
+)
+
+// Get returns the status of the backups.
+func Get() Status {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	return status
+}
+
+// SetLoading reports the progress of the restore of a backup running in the background, keys being the number
+// of keys restored so far.
+func SetLoading(loading bool, keys int64) {
+	updateStatus(func(s *Status) {
+		s.Loading = loading
+		s.LoadedKeys = keys
+	})
+}
+
+// LoadFailed reports that the restore of a backup running in the background failed with the error.
+func LoadFailed(err error) {
+	updateStatus(func(s *Status) {
+		s.LoadErr = err
+	})
+}
+
+func updateStatus(fn func(s *Status)) {
+	statusMu.Lock()
+	fn(&status)
+	statusMu.Unlock()
+}
+
+// Scheduler takes the backups of the keys of the shards, the commands being executed by exec.
+type Scheduler struct {
+	shards   int
+	exec     export.Exec
+	target   Target
+	schedule *Schedule
+}
+
+// New returns a scheduler of the backups of the keys of the shards to the target, on the schedule.
+func New(shards int, exec export.Exec, target Target, schedule string) (*Scheduler, error) {
+	s, err := ParseSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &Scheduler{shards: shards, exec: exec, target: target, schedule: s}, nil
+}
+
+// Run takes the backups on schedule until the context is canceled, a backup in progress being given up then.
+func (s *Scheduler) Run(ctx context.Context) error {
+	updateStatus(func(st *Status) { st.Enabled = true })
+	defer updateStatus(func(st *Status) { st.Enabled, st.Next = false, time.Time{} })
+
+	for {
+		next := s.schedule.Next(time.Now())
+		if next.IsZero() {
+			slog.Warn("the backup schedule has no next time, no backup is taken")
+			<-ctx.Done()
+			return nil
+		}
+		updateStatus(func(st *Status) { st.Next = next })
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		if err := s.Backup(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Warn("could not take a backup", slog.Any("error", err))
+		}
+	}
+}
+
+// Backup takes a backup, then deletes the backups beyond the retention.
+func (s *Scheduler) Backup(ctx context.Context) error {
+	start := time.Now()
+	name := Name(start)
+	updateStatus(func(st *Status) { st.InProgress = true })
+
+	keys, size, err := s.write(ctx, name)
+	updateStatus(func(st *Status) {
+		st.InProgress = false
+		st.LastAt, st.LastErr, st.LastDuration, st.LastName = start, err, time.Since(start), name
+		st.LastKeys, st.LastSize = keys, size
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("took a backup", slog.String("name", name), slog.Int64("keys", keys), slog.Int64("size", size),
+		slog.Duration("duration", time.Since(start)))
+
+	if err := s.prune(ctx); err != nil {
+		return fmt.Errorf("could not delete the backups beyond the retention: %w", err)
+	}
+	return nil
+}
+
+// write exports the keys to the backup of the name, and returns the number of keys and the size of the backup.
+func (s *Scheduler) write(ctx context.Context, name string) (keys, size int64, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r, w := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := export.Run(ctx, s.shards, s.exec, export.Options{Match: "*", Format: export.FormatJSON, Checksum: true}, func(lines []string) error {
+			keys += int64(len(lines))
+			_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+			return err
+		})
+		w.CloseWithError(err)
+	}()
+
+	size, err = s.target.Write(ctx, name, r)
+	// The export stops writing once the target gave up on the backup
+	_ = r.CloseWithError(io.ErrClosedPipe)
+	cancel()
+	<-done
+	return keys, size, err
+}
+
+// prune deletes the backups beyond the retention.
+func (s *Scheduler) prune(ctx context.Context) error {
+	names, err := s.target.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var backups []backupAt
+	for _, name := range names {
+		if at, ok := parseName(name); ok {
+			backups = append(backups, backupAt{name: name, at: at})
+		}
+	}
+	backup := config.DiceConfig().Backup
+	expired := expire(backups, backup.KeepLast, backup.KeepDaily, backup.KeepWeekly)
+	for _, name := range expired {
+		if err := s.target.Delete(ctx, name); err != nil {
+			return err
+		}
+	}
+	updateStatus(func(st *Status) { st.Retained = len(backups) - len(expired) })
+	return nil
+}
+
+// Name returns the name of the backup started at the time.
+func Name(at time.Time) string {
+	return namePrefix + at.UTC().Format(nameLayout) + nameSuffix
+}
+
+// parseName returns the time the backup of the name was started at, false for the names of other files.
+func parseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, namePrefix) || !strings.HasSuffix(name, nameSuffix) {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(nameLayout, strings.TrimSuffix(strings.TrimPrefix(name, namePrefix), nameSuffix))
+	return at, err == nil
+}
+
+type backupAt struct {
+	name string
+	at   time.Time
+}
+
+// expire returns the names of the backups beyond the retention: the ones that are neither one of the last
+// keepLast backups, nor the last one of one of the last keepDaily days or keepWeekly ISO weeks, in UTC.
+func expire(backups []backupAt, keepLast, keepDaily, keepWeekly int) []string {
+	slices.SortFunc(backups, func(a, b backupAt) int { return cmp.Compare(b.at.UnixNano(), a.at.UnixNano()) })
+
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	var expired []string
+	for i, b := range backups {
+		kept := i < keepLast
+
+		day := b.at.UTC().Format(time.DateOnly)
+		if !days[day] && len(days) < keepDaily {
+			days[day] = true
+			kept = true
+		}
+		year, week := b.at.UTC().ISOWeek()
+		if key := fmt.Sprintf("%d-%d", year, week); !weeks[key] && len(weeks) < keepWeekly {
+			weeks[key] = true
+			kept = true
+		}
+
+		if !kept {
+			expired = append(expired, b.name)
+		}
+	}
+	return expired
+}
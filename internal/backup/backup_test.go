@@ -0,0 +1,172 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/export"
+	"github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	from := time.Date(2026, time.October, 15, 10, 30, 20, 0, time.UTC)
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"@hourly", time.Date(2026, time.October, 15, 11, 0, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2026, time.October, 15, 10, 40, 0, 0, time.UTC)},
+		{"15 2,14 * * *", time.Date(2026, time.October, 15, 14, 15, 0, 0, time.UTC)},
+		{"0 3 * * 7", time.Date(2026, time.October, 18, 3, 0, 0, 0, time.UTC)},
+		{"0 0 1 1-3 *", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// The day of the month or the day of the week, both being restricted
+		{"0 0 20 * 5", time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.next, s.Next(from), tt.spec)
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	var backups []backupAt
+	// A backup every 6 hours over 3 weeks, the last one on Thursday 2026-10-15 18:00
+	last := time.Date(2026, time.October, 15, 18, 0, 0, 0, time.UTC)
+	for at := last.AddDate(0, 0, -21); !at.After(last); at = at.Add(6 * time.Hour) {
+		backups = append(backups, backupAt{name: Name(at), at: at})
+	}
+
+	expired := expire(slices.Clone(backups), 2, 3, 2)
+	var kept []string
+	for _, b := range backups {
+		if !slices.Contains(expired, b.name) {
+			kept = append(kept, b.name)
+		}
+	}
+	slices.Sort(kept)
+	assert.Equal(t, []string{
+		// The last backup of the week of Monday 2026-10-05
+		"dicedb-20261011T180000Z.jsonl",
+		// The last backup of the days before
+		"dicedb-20261013T180000Z.jsonl",
+		"dicedb-20261014T180000Z.jsonl",
+		// The last backups
+		"dicedb-20261015T120000Z.jsonl",
+		"dicedb-20261015T180000Z.jsonl",
+	}, kept)
+}
+
+func TestBackup(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().Backup.KeepLast = 1
+	config.DiceConfig().Backup.KeepDaily = 0
+	config.DiceConfig().Backup.KeepWeekly = 0
+
+	keys := [][]string{{"a", "b"}, {"c"}}
+	exec := func(_ context.Context, shardID uint8, c *cmd.DiceDBCmd) (interface{}, error) {
+		switch c.Cmd {
+		case store.SingleShardKeys:
+			return keys[shardID], nil
+		case store.SingleShardExport:
+			records := make([]export.Record, 0, len(c.Args))
+			for _, key := range c.Args {
+				records = append(records, export.Record{Key: key, Type: export.TypeString, TTL: -1, Value: "v"})
+			}
+			return records, nil
+		}
+		return nil, nil
+	}
+
+	dir := Dir(t.TempDir())
+	stale := Name(time.Now().Add(-time.Hour))
+	_, err := dir.Write(context.Background(), stale, strings.NewReader(""))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(string(dir), "notes.txt"), nil, 0o600))
+
+	s, err := New(2, exec, dir, "@daily")
+	require.NoError(t, err)
+	require.NoError(t, s.Backup(context.Background()))
+
+	status := Get()
+	assert.NoError(t, status.LastErr)
+	assert.EqualValues(t, 3, status.LastKeys)
+	assert.Equal(t, 1, status.Retained)
+
+	names, err := dir.List(context.Background())
+	require.NoError(t, err)
+	slices.Sort(names)
+	assert.Equal(t, []string{status.LastName, "notes.txt"}, names, "the backups beyond the retention are deleted, the other files are left")
+
+	data, err := os.ReadFile(filepath.Join(string(dir), status.LastName))
+	require.NoError(t, err)
+	assert.EqualValues(t, len(data), status.LastSize)
+	// The records are sealed with their checksum
+	record, err := export.Unseal([]byte(strings.SplitN(string(data), "\n", 2)[0]))
+	require.NoError(t, err)
+	assert.Equal(t, `{"key":"a","type":"string","ttl":-1,"value":"v"}`, string(record))
+	assert.Equal(t, 3, strings.Count(string(data), "\n"))
+}
+
+func TestArchiver(t *testing.T) {
+	segment := filepath.Join(t.TempDir(), "seg-0")
+	require.NoError(t, os.WriteFile(segment, []byte("commands"), 0o600))
+
+	dir := Dir(t.TempDir())
+	a := NewArchiver(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = a.Run(ctx)
+	}()
+	a.Archive(segment)
+
+	var archived []string
+	assert.Eventually(t, func() bool {
+		archived, _ = filepath.Glob(filepath.Join(string(dir), "wal", "*-seg-0"))
+		return len(archived) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	data, err := os.ReadFile(archived[0])
+	require.NoError(t, err)
+	assert.Equal(t, "commands", string(data))
+
+	// The segments archived are not listed as backups
+	names, err := dir.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
@@ -0,0 +1,61 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Open returns the content of the backup at the source, to be restored with IMPORT: an s3://bucket/key URL,
+// read from the object store of the config, an http:// or https:// URL, or the path of a file.
+func Open(ctx context.Context, source string) (io.ReadCloser, error) {
+	switch {
+	case strings.HasPrefix(source, "s3://"):
+		bucket, key, ok := strings.Cut(strings.TrimPrefix(source, "s3://"), "/")
+		if !ok || bucket == "" || key == "" {
+			return nil, fmt.Errorf("invalid source %q, expected s3://bucket/key", source)
+		}
+		s3, err := NewS3(s3Options(bucket, ""))
+		if err != nil {
+			return nil, err
+		}
+		return s3.Open(ctx, key)
+
+	case strings.HasPrefix(source, "http://"), strings.HasPrefix(source, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("could not download %s: %s", source, resp.Status)
+		}
+		return resp.Body, nil
+
+	default:
+		return os.Open(strings.TrimPrefix(source, "file://"))
+	}
+}
@@ -0,0 +1,394 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minPartSize is the smallest part of a multipart upload S3 accepts, but for the last one
+	minPartSize = 5 << 20
+	// emptyHash is the SHA-256 of an empty payload
+	emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Server-side encryptions of the objects.
+const (
+	SSEAES256 = "AES256"
+	SSEKMS    = "aws:kms"
+)
+
+// S3Options describes the bucket of an S3-compatible object store, e.g. AWS S3 or MinIO.
+type S3Options struct {
+	// Endpoint is the URL of the object store, the AWS S3 endpoint of the region when empty
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to the names of the objects, e.g. "dicedb/"
+	Prefix string
+	// AccessKey and SecretKey sign the requests, which are anonymous when AccessKey is empty
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket in the path of the URLs rather than in the host name, as MinIO requires
+	PathStyle bool
+	// SSE is the server-side encryption of the objects, SSEAES256 or SSEKMS, none when empty
+	SSE string
+	// KMSKeyID is the KMS key encrypting the objects with SSEKMS, the default key of the bucket when empty
+	KMSKeyID string
+	// PartSize is the size of the parts of the objects uploaded in several parts, at least 5 MiB
+	PartSize int64
+	// Client sends the requests, http.DefaultClient when nil
+	Client *http.Client
+}
+
+// S3 stores the backups as the objects of a bucket of an S3-compatible object store. The objects larger than a
+// part are uploaded with a multipart upload, a part at a time.
+type S3 struct {
+	opts S3Options
+	base *url.URL
+}
+
+// NewS3 returns the target storing the backups in the bucket.
+func NewS3(opts S3Options) (*S3, error) {
+	if opts.Bucket == "" {
+		return nil, errors.New("no S3 bucket")
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://s3." + opts.Region + ".amazonaws.com"
+	}
+	if opts.PartSize < minPartSize {
+		opts.PartSize = minPartSize
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	switch opts.SSE {
+	case "", SSEAES256, SSEKMS:
+	default:
+		return nil, fmt.Errorf("invalid server-side encryption %q, expected %s or %s", opts.SSE, SSEAES256, SSEKMS)
+	}
+
+	base, err := url.Parse(opts.Endpoint)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", opts.Endpoint)
+	}
+	return &S3{opts: opts, base: base}, nil
+}
+
+func (s *S3) Write(ctx context.Context, name string, r io.Reader) (int64, error) {
+	key := s.opts.Prefix + name
+	part := make([]byte, s.opts.PartSize)
+	n, err := io.ReadFull(r, part)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		// The backups smaller than a part are uploaded at once
+		resp, err := s.do(ctx, http.MethodPut, key, nil, s.encryption(), part[:n])
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return int64(n), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := s.doXML(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, s.encryption(), nil, &initiated); err != nil {
+		return 0, err
+	}
+	size, err := s.uploadParts(ctx, key, initiated.UploadID, part[:n], r)
+	if err != nil {
+		// The parts uploaded are dropped rather than billed until the upload expires
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if resp, abortErr := s.do(abortCtx, http.MethodDelete, key, url.Values{"uploadId": {initiated.UploadID}}, nil, nil); abortErr == nil {
+			resp.Body.Close()
+		}
+		return 0, err
+	}
+	return size, nil
+}
+
+// completedPart is a part of a multipart upload, as listed to complete the upload.
+type completedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// uploadParts uploads the first part and the ones read from r, then completes the upload.
+func (s *S3) uploadParts(ctx context.Context, key, uploadID string, first []byte, r io.Reader) (int64, error) {
+	var parts []completedPart
+	var size int64
+	part := first
+	for len(part) > 0 {
+		number := len(parts) + 1
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+		resp, err := s.do(ctx, http.MethodPut, key, query, nil, part)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		parts = append(parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+		size += int64(len(part))
+
+		// The buffer of the first part is reused for the next ones
+		n, err := io.ReadFull(r, first[:cap(first)])
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, err
+		}
+		part = first[:n]
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return 0, err
+	}
+	// The completion may fail after the response status is sent, the error is then in the body
+	var completed struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := s.doXML(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, nil, body, &completed); err != nil {
+		return 0, err
+	}
+	if completed.XMLName.Local == "Error" {
+		return 0, fmt.Errorf("could not complete the upload of %s: %s (%s)", key, completed.Message, completed.Code)
+	}
+	return size, nil
+}
+
+func (s *S3) List(ctx context.Context) ([]string, error) {
+	var names []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.opts.Prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		var listed struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if err := s.doXML(ctx, http.MethodGet, "", query, nil, nil, &listed); err != nil {
+			return nil, err
+		}
+		for _, c := range listed.Contents {
+			names = append(names, strings.TrimPrefix(c.Key, s.opts.Prefix))
+		}
+		if !listed.IsTruncated || listed.NextContinuationToken == "" {
+			return names, nil
+		}
+		token = listed.NextContinuationToken
+	}
+}
+
+func (s *S3) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.opts.Prefix+name, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Open returns the content of the object of the key, regardless of the prefix.
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// encryption returns the headers of the server-side encryption of the objects uploaded.
+func (s *S3) encryption() http.Header {
+	header := http.Header{}
+	if s.opts.SSE != "" {
+		header.Set("X-Amz-Server-Side-Encryption", s.opts.SSE)
+	}
+	if s.opts.SSE == SSEKMS && s.opts.KMSKeyID != "" {
+		header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", s.opts.KMSKeyID)
+	}
+	return header
+}
+
+// doXML sends the request and decodes the XML body of the response into v.
+func (s *S3) doXML(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte, v interface{}) error {
+	resp, err := s.do(ctx, method, key, query, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response to %s %s: %w", method, key, err)
+	}
+	return nil
+}
+
+// do sends a signed request on the object of the key, or on the bucket when key is empty. A response whose
+// status is not a success is returned as an error.
+func (s *S3) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	host, path := s.base.Host, strings.TrimSuffix(s.base.EscapedPath(), "/")
+	if s.opts.PathStyle {
+		path += "/" + uriEncode(s.opts.Bucket, false)
+	} else {
+		host = s.opts.Bucket + "." + host
+	}
+	if key != "" || !s.opts.PathStyle {
+		path += "/" + uriEncode(key, true)
+	}
+
+	rawQuery := canonicalQuery(query)
+	target := s.base.Scheme + "://" + host + path
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := emptyHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	if s.opts.AccessKey != "" {
+		s.sign(req, path, rawQuery, payloadHash, time.Now())
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var failure struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if xml.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&failure) != nil || failure.Code == "" {
+			return nil, fmt.Errorf("%s %s: %s", method, key, resp.Status)
+		}
+		return nil, fmt.Errorf("%s %s: %s: %s (%s)", method, key, resp.Status, failure.Message, failure.Code)
+	}
+	return resp, nil
+}
+
+// sign signs the request with AWS Signature Version 4, the signed headers being the host and the x-amz-* ones.
+func (s *S3) sign(req *http.Request, path, rawQuery, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scope := amzDate[:8] + "/" + s.opts.Region + "/s3/aws4_request"
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonical strings.Builder
+	canonical.WriteString(req.Method + "\n" + path + "\n" + rawQuery + "\n")
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical.WriteString("\n" + signedHeaders + "\n" + payloadHash)
+
+	requestHash := sha256.Sum256([]byte(canonical.String()))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + s.opts.SecretKey)
+	for _, part := range []string{amzDate[:8], s.opts.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.opts.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes the query with its parameters sorted, as signed.
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, uriEncode(name, false)+"="+uriEncode(value, false))
+		}
+	}
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+// uriEncode percent-encodes every byte but the unreserved characters, and the slashes if keepSlash is set.
+func uriEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+)
+
+// Targets of the backups, as set by backup.target.
+const (
+	TargetDir = "dir"
+	TargetS3  = "s3"
+)
+
+// Target stores the backups. It is called by a single goroutine.
+type Target interface {
+	// Write stores the backup of the name, read until EOF, and returns its size. A backup whose write fails
+	// is not listed.
+	Write(ctx context.Context, name string, r io.Reader) (int64, error)
+	// List returns the names of the backups stored, in any order
+	List(ctx context.Context) ([]string, error)
+	// Delete removes the backup of the name
+	Delete(ctx context.Context, name string) error
+}
+
+// NewTarget returns the target the config stores the backups to, backup.dir or the S3 bucket backup.s3_bucket.
+func NewTarget() (Target, error) {
+	b := config.DiceConfig().Backup
+	if b.Target == TargetS3 {
+		return NewS3(s3Options(b.S3Bucket, b.S3Prefix))
+	}
+	return Dir(b.Dir), nil
+}
+
+// s3Options returns the options of the S3 bucket of the config, with the bucket and the prefix given.
+func s3Options(bucket, prefix string) S3Options {
+	b := config.DiceConfig().Backup
+	return S3Options{
+		Endpoint:  b.S3Endpoint,
+		Region:    b.S3Region,
+		Bucket:    bucket,
+		Prefix:    prefix,
+		AccessKey: b.S3AccessKey,
+		SecretKey: b.S3SecretKey,
+		PathStyle: b.S3PathStyle,
+		SSE:       b.S3SSE,
+		KMSKeyID:  b.S3KMSKeyID,
+		PartSize:  int64(b.S3PartSizeMB) << 20,
+	}
+}
+
+// Dir stores the backups as files of a directory, created if it does not exist. The names with slashes are
+// stored in its subdirectories, which are not listed.
+type Dir string
+
+// tmpSuffix is the suffix of the file of a backup being written, renamed once complete
+const tmpSuffix = ".tmp"
+
+func (d Dir) Write(ctx context.Context, name string, r io.Reader) (int64, error) {
+	path := filepath.Join(string(d), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+	f, err := os.Create(path + tmpSuffix)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+tmpSuffix, path)
+	}
+	if err != nil {
+		_ = os.Remove(path + tmpSuffix)
+		return 0, err
+	}
+	return size, nil
+}
+
+func (d Dir) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasSuffix(e.Name(), tmpSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (d Dir) Delete(ctx context.Context, name string) error {
+	err := os.Remove(filepath.Join(string(d), name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
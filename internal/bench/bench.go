@@ -0,0 +1,365 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package bench drives a running DiceDB through one of its frontends, RESP, HTTP or WebSocket, with a given
+// number of clients, pipeline depth, size of values and mix of commands, and reports the throughput and the
+// latency percentiles of the requests, in the manner of redis-benchmark.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Protocols of the frontends
+const (
+	ProtocolRESP      = "resp"
+	ProtocolHTTP      = "http"
+	ProtocolWebSocket = "websocket"
+)
+
+// Options describes a benchmark.
+type Options struct {
+	Protocol string
+	// Addr is the host and port of the frontend
+	Addr string
+	// Clients is the number of concurrent connections
+	Clients int
+	// Requests is the total number of requests sent
+	Requests int
+	// Pipeline is the number of requests sent by a client before reading their replies. HTTP clients do not
+	// pipeline, every request is sent once the previous one is replied to.
+	Pipeline int
+	// DataSize is the size in bytes of the values written
+	DataSize int
+	// KeySpace is the number of distinct keys used, every request using a random one. 0 uses a single key.
+	KeySpace int
+	// Mix is the commands sent, picked at random by their weight
+	Mix []Op
+	// Seed seeds the choice of the keys and of the commands, so that a run can be repeated
+	Seed int64
+}
+
+// Op is a command of the mix of a benchmark.
+type Op struct {
+	Name   string
+	Weight int
+}
+
+// templates are the arguments of the commands that can be benchmarked, in which __key__ is replaced by the key
+// of the request and __value__ by the value
+var templates = map[string][]string{
+	"PING":   {"PING"},
+	"SET":    {"SET", "__key__", "__value__"},
+	"GET":    {"GET", "__key__"},
+	"INCR":   {"INCR", "counter:__key__"},
+	"DEL":    {"DEL", "__key__"},
+	"LPUSH":  {"LPUSH", "list:__key__", "__value__"},
+	"RPUSH":  {"RPUSH", "list:__key__", "__value__"},
+	"LPOP":   {"LPOP", "list:__key__"},
+	"RPOP":   {"RPOP", "list:__key__"},
+	"SADD":   {"SADD", "set:__key__", "__value__"},
+	"SPOP":   {"SPOP", "set:__key__"},
+	"HSET":   {"HSET", "hash:__key__", "field", "__value__"},
+	"HGET":   {"HGET", "hash:__key__", "field"},
+	"ZADD":   {"ZADD", "zset:__key__", "1", "__value__"},
+	"ZRANGE": {"ZRANGE", "zset:__key__", "0", "-1"},
+	"MSET":   {"MSET", "__key__", "__value__", "other:__key__", "__value__"},
+}
+
+// Commands returns the names of the commands that can be benchmarked, sorted.
+func Commands() []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseMix parses a mix of commands, e.g. "set:1,get:9". A command without weight has a weight of 1.
+func ParseMix(s string) ([]Op, error) {
+	var mix []Op
+	for _, part := range strings.Split(s, ",") {
+		name, weight, found := strings.Cut(strings.TrimSpace(part), ":")
+		op := Op{Name: strings.ToUpper(name), Weight: 1}
+		if _, ok := templates[op.Name]; !ok {
+			return nil, fmt.Errorf("unsupported command %q, the commands are %s", name, strings.Join(Commands(), ", "))
+		}
+		if found {
+			w, err := strconv.Atoi(weight)
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("invalid weight %q of %s", weight, op.Name)
+			}
+			op.Weight = w
+		}
+		mix = append(mix, op)
+	}
+	return mix, nil
+}
+
+// Report is the outcome of a benchmark.
+type Report struct {
+	Protocol string
+	Mix      []Op
+	Clients  int
+	Pipeline int
+	DataSize int
+	// Requests is the number of requests replied to, and Errors the number of them replied to with an error
+	Requests int
+	Errors   int
+	Elapsed  time.Duration
+	// Latencies are the latencies of the requests, sorted. The latency of a request is the time between the
+	// sending of its pipeline and the reading of its reply.
+	Latencies []time.Duration
+}
+
+// Throughput returns the number of requests replied to per second.
+func (r *Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// Percentile returns the latency under which the percentage p of the requests were replied to.
+func (r *Report) Percentile(p float64) time.Duration {
+	return Percentile(r.Latencies, p)
+}
+
+// Percentile returns the latency under which the percentage p of the sorted latencies fall.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p/100+0.5) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+// Print writes the report in the manner of redis-benchmark.
+func (r *Report) Print(w io.Writer) {
+	names := make([]string, 0, len(r.Mix))
+	for _, op := range r.Mix {
+		names = append(names, fmt.Sprintf("%s:%d", op.Name, op.Weight))
+	}
+
+	fmt.Fprintf(w, "====== %s (%s) ======\n", strings.Join(names, ","), r.Protocol)
+	fmt.Fprintf(w, "  %d requests completed in %.2f seconds\n", r.Requests, r.Elapsed.Seconds())
+	fmt.Fprintf(w, "  %d parallel clients\n", r.Clients)
+	fmt.Fprintf(w, "  %d bytes payload\n", r.DataSize)
+	fmt.Fprintf(w, "  pipeline depth %d\n", r.Pipeline)
+	if r.Errors > 0 {
+		fmt.Fprintf(w, "  %d requests replied to with an error\n", r.Errors)
+	}
+	fmt.Fprintf(w, "\nThroughput summary: %.2f requests per second\n", r.Throughput())
+	fmt.Fprintf(w, "Latency summary (msec):\n")
+	fmt.Fprintf(w, "  %10s %10s %10s %10s %10s %10s\n", "avg", "min", "p50", "p95", "p99", "max")
+	fmt.Fprintf(w, "  %10.3f %10.3f %10.3f %10.3f %10.3f %10.3f\n", msec(r.average()), msec(r.Percentile(0)),
+		msec(r.Percentile(50)), msec(r.Percentile(95)), msec(r.Percentile(99)), msec(r.Percentile(100)))
+}
+
+func (r *Report) average() time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, l := range r.Latencies {
+		total += l
+	}
+	return total / time.Duration(len(r.Latencies))
+}
+
+func msec(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// client is a connection to a frontend
+type client interface {
+	// do sends the commands and reads their replies, reporting for every one whether it is an error
+	do(ctx context.Context, cmds [][]string) ([]bool, error)
+	Close() error
+}
+
+func dial(ctx context.Context, opts *Options) (client, error) {
+	switch opts.Protocol {
+	case ProtocolRESP:
+		return dialRESP(ctx, opts.Addr)
+	case ProtocolHTTP:
+		return newHTTPClient(opts.Addr), nil
+	case ProtocolWebSocket:
+		return dialWebSocket(ctx, opts.Addr)
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q", opts.Protocol)
+	}
+}
+
+// Run runs the benchmark until every request is replied to, or until the context is canceled.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Clients <= 0 || opts.Requests <= 0 || len(opts.Mix) == 0 {
+		return nil, errors.New("the number of clients and requests must be positive and the mix not empty")
+	}
+	opts.Pipeline = max(opts.Pipeline, 1)
+	if opts.Protocol == ProtocolHTTP {
+		opts.Pipeline = 1
+	}
+
+	clients := make([]client, 0, opts.Clients)
+	defer func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}()
+	for i := 0; i < opts.Clients; i++ {
+		c, err := dial(ctx, &opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not connect to %s over %s: %w", opts.Addr, opts.Protocol, err)
+		}
+		clients = append(clients, c)
+	}
+
+	value := strings.Repeat("x", opts.DataSize)
+	var remaining atomic.Int64
+	remaining.Store(int64(opts.Requests))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	report := &Report{Protocol: opts.Protocol, Mix: opts.Mix, Clients: opts.Clients, Pipeline: opts.Pipeline, DataSize: opts.DataSize}
+	errs := make([]error, len(clients))
+
+	start := time.Now()
+	for i, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := &worker{opts: &opts, gen: NewGenerator(opts.Mix, opts.KeySpace, value, "", opts.Seed+int64(i))}
+			latencies, failed, err := w.run(ctx, c, &remaining)
+			errs[i] = err
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Latencies = append(report.Latencies, latencies...)
+			report.Errors += failed
+		}()
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+	report.Requests = len(report.Latencies)
+	slices.Sort(report.Latencies)
+
+	if err := errors.Join(errs...); err != nil {
+		return report, err
+	}
+	return report, ctx.Err()
+}
+
+// worker sends the requests of a client
+type worker struct {
+	opts *Options
+	gen  *Generator
+}
+
+func (w *worker) run(ctx context.Context, c client, remaining *atomic.Int64) (latencies []time.Duration, failed int, err error) {
+	for ctx.Err() == nil {
+		n := int(min(remaining.Add(-int64(w.opts.Pipeline))+int64(w.opts.Pipeline), int64(w.opts.Pipeline)))
+		if n <= 0 {
+			return latencies, failed, nil
+		}
+
+		cmds := make([][]string, n)
+		for i := range cmds {
+			cmds[i] = w.gen.Next()
+		}
+		start := time.Now()
+		errs, err := c.do(ctx, cmds)
+		if err != nil {
+			return latencies, failed, err
+		}
+		latency := time.Since(start)
+		for _, isErr := range errs {
+			latencies = append(latencies, latency)
+			if isErr {
+				failed++
+			}
+		}
+	}
+	return latencies, failed, nil
+}
+
+// Generator generates the commands of a mix, picked at random by their weight.
+type Generator struct {
+	mix      []Op
+	total    int
+	keySpace int
+	value    string
+	prefix   string
+	rnd      *rand.Rand
+}
+
+// NewGenerator returns a generator of the commands of the mix over keySpace distinct keys, 0 for a single key,
+// writing the value. The keys are prefixed with prefix.
+func NewGenerator(mix []Op, keySpace int, value, prefix string, seed int64) *Generator {
+	g := &Generator{mix: mix, keySpace: keySpace, value: value, prefix: prefix, rnd: rand.New(rand.NewSource(seed))}
+	for _, op := range mix {
+		g.total += op.Weight
+	}
+	return g
+}
+
+// Next returns the arguments of a command picked from the mix, the name of the command first.
+func (g *Generator) Next() []string {
+	pick := g.rnd.Intn(g.total)
+	var template []string
+	for _, op := range g.mix {
+		if pick < op.Weight {
+			template = templates[op.Name]
+			break
+		}
+		pick -= op.Weight
+	}
+
+	key := "key:0"
+	if g.keySpace > 0 {
+		key = "key:" + strconv.Itoa(g.rnd.Intn(g.keySpace))
+	}
+	args := make([]string, len(template))
+	for i, arg := range template {
+		if strings.Contains(arg, "__key__") {
+			arg = g.prefix + strings.ReplaceAll(arg, "__key__", key)
+		}
+		args[i] = strings.ReplaceAll(arg, "__value__", g.value)
+	}
+	return args
+}
+
+// KeyCount returns the number of keys of the command of the mix.
+func KeyCount(name string) int {
+	n := 0
+	for _, arg := range templates[name] {
+		if strings.Contains(arg, "__key__") {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,181 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveRESP replies to the commands it receives with an error for GET and OK otherwise
+func serveRESP(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+					var args []string
+					for i := 0; i < n; i++ {
+						_, _ = r.ReadString('\n')
+						arg, _ := r.ReadString('\n')
+						args = append(args, strings.TrimSpace(arg))
+					}
+					reply := "+OK\r\n"
+					if args[0] == "GET" {
+						reply = "-ERR failed\r\n"
+					}
+					if _, err := conn.Write([]byte(reply)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("set:1, get:9,ping")
+	require.NoError(t, err)
+	assert.Equal(t, []Op{{Name: "SET", Weight: 1}, {Name: "GET", Weight: 9}, {Name: "PING", Weight: 1}}, mix)
+
+	_, err = ParseMix("set:0")
+	assert.ErrorContains(t, err, "invalid weight")
+	_, err = ParseMix("flushall")
+	assert.ErrorContains(t, err, "unsupported command")
+}
+
+func TestGenerator(t *testing.T) {
+	g := NewGenerator([]Op{{Name: "LPUSH", Weight: 1}}, 0, "v", "scratch:", 1)
+	assert.Equal(t, []string{"LPUSH", "scratch:list:key:0", "v"}, g.Next())
+
+	assert.Equal(t, 1, KeyCount("LPUSH"))
+	assert.Equal(t, 2, KeyCount("MSET"))
+	assert.Equal(t, 0, KeyCount("PING"))
+}
+
+func TestPercentile(t *testing.T) {
+	r := &Report{Requests: 100, Elapsed: 2 * time.Second}
+	for i := 1; i <= 100; i++ {
+		r.Latencies = append(r.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, time.Millisecond, r.Percentile(0))
+	assert.Equal(t, 50*time.Millisecond, r.Percentile(50))
+	assert.Equal(t, 99*time.Millisecond, r.Percentile(99))
+	assert.Equal(t, 100*time.Millisecond, r.Percentile(100))
+	assert.InDelta(t, 50, r.Throughput(), 0.001)
+
+	var out bytes.Buffer
+	r.Print(&out)
+	assert.Contains(t, out.String(), "100 requests completed in 2.00 seconds")
+}
+
+func TestRunRESP(t *testing.T) {
+	addr := serveRESP(t)
+	mix, err := ParseMix("set:1,get:1")
+	require.NoError(t, err)
+
+	report, err := Run(context.Background(), Options{
+		Protocol: ProtocolRESP, Addr: addr, Clients: 4, Requests: 1003, Pipeline: 10, DataSize: 16, KeySpace: 100, Mix: mix,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1003, report.Requests)
+	assert.Len(t, report.Latencies, 1003)
+	// Half of the requests, the GETs, fail
+	assert.InDelta(t, 500, report.Errors, 100)
+}
+
+func TestRunHTTP(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		_, _ = w.Write([]byte(`{"status":"success","data":"OK"}`))
+	}))
+	defer srv.Close()
+
+	report, err := Run(context.Background(), Options{
+		Protocol: ProtocolHTTP, Addr: strings.TrimPrefix(srv.URL, "http://"), Clients: 1, Requests: 5, Pipeline: 4, DataSize: 2,
+		Mix: []Op{{Name: "SET", Weight: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Requests)
+	assert.Zero(t, report.Errors)
+	assert.Equal(t, 1, report.Pipeline)
+	assert.Equal(t, map[string]interface{}{"key": "key:0", "values": []interface{}{"xx"}}, bodies[0])
+}
+
+func TestRunWebSocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			reply := `"OK"`
+			if strings.HasPrefix(string(msg), "GET ") {
+				reply = `"ERR failed"`
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(reply)); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	report, err := Run(context.Background(), Options{
+		Protocol: ProtocolWebSocket, Addr: strings.TrimPrefix(srv.URL, "http://"), Clients: 2, Requests: 20, Pipeline: 3,
+		Mix: []Op{{Name: "GET", Weight: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 20, report.Requests)
+	assert.Equal(t, 20, report.Errors)
+}
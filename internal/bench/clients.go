@@ -0,0 +1,226 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// respClient sends the commands over a RESP connection, pipelined
+type respClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	buf  bytes.Buffer
+}
+
+func dialRESP(ctx context.Context, addr string) (*respClient, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &respClient{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+func (c *respClient) do(_ context.Context, cmds [][]string) ([]bool, error) {
+	c.buf.Reset()
+	for _, args := range cmds {
+		fmt.Fprintf(&c.buf, "*%d\r\n", len(args))
+		for _, arg := range args {
+			fmt.Fprintf(&c.buf, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if _, err := c.conn.Write(c.buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	errs := make([]bool, len(cmds))
+	for i := range cmds {
+		isErr, err := readReply(c.r)
+		if err != nil {
+			return nil, err
+		}
+		errs[i] = isErr
+	}
+	return errs, nil
+}
+
+func (c *respClient) Close() error {
+	return c.conn.Close()
+}
+
+// readReply reads a RESP2 or RESP3 reply and reports whether it is an error
+func readReply(r *bufio.Reader) (bool, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return false, fmt.Errorf("empty reply")
+	}
+
+	switch line[0] {
+	case '-':
+		return true, nil
+	case '+', ':', '_', ',', '#', '(':
+		return false, nil
+	case '$', '!', '=':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return false, fmt.Errorf("invalid reply %q", line)
+		}
+		if n < 0 {
+			return false, nil
+		}
+		_, err = io.CopyN(io.Discard, r, int64(n+2))
+		return line[0] == '!', err
+	case '*', '~', '>', '%', '|':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return false, fmt.Errorf("invalid reply %q", line)
+		}
+		if line[0] == '%' || line[0] == '|' {
+			n *= 2
+		}
+		for i := 0; i < n; i++ {
+			if _, err := readReply(r); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid reply %q", line)
+	}
+}
+
+// httpClient sends the commands as requests to the HTTP server, one at a time
+type httpClient struct {
+	base   string
+	client *http.Client
+}
+
+func newHTTPClient(addr string) *httpClient {
+	return &httpClient{
+		base: "http://" + addr,
+		// Every client has a connection of its own, as the connections of RESP and WebSocket clients
+		client: &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: 1}},
+	}
+}
+
+// httpResponse is the body of the responses of the HTTP server
+type httpResponse struct {
+	Status string `json:"status"`
+}
+
+func (c *httpClient) do(ctx context.Context, cmds [][]string) ([]bool, error) {
+	errs := make([]bool, len(cmds))
+	for i, args := range cmds {
+		// The first argument is sent as the key, the others as the values
+		body := map[string]interface{}{}
+		if len(args) > 1 {
+			body["key"] = args[1]
+		}
+		if len(args) > 2 {
+			body["values"] = args[2:]
+		}
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/"+url.PathEscape(args[0]), bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var decoded httpResponse
+		err = json.NewDecoder(resp.Body).Decode(&decoded)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		errs[i] = resp.StatusCode != http.StatusOK || decoded.Status != "success"
+	}
+	return errs, nil
+}
+
+func (c *httpClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// webSocketClient sends the commands as messages over a WebSocket connection, pipelined
+type webSocketClient struct {
+	conn *websocket.Conn
+}
+
+func dialWebSocket(ctx context.Context, addr string) (*webSocketClient, error) {
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, "ws://"+addr+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return &webSocketClient{conn: conn}, nil
+}
+
+func (c *webSocketClient) do(_ context.Context, cmds [][]string) ([]bool, error) {
+	for _, args := range cmds {
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			if strings.ContainsAny(arg, " \"'") || arg == "" {
+				arg = strconv.Quote(arg)
+			}
+			quoted[i] = arg
+		}
+		if err := c.conn.WriteMessage(websocket.TextMessage, []byte(strings.Join(quoted, " "))); err != nil {
+			return nil, err
+		}
+	}
+
+	errs := make([]bool, len(cmds))
+	for i := range cmds {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		// Errors are replied to as JSON strings, e.g. "ERR ...", or as plain text starting with error:
+		errs[i] = bytes.HasPrefix(msg, []byte("error:")) || bytes.HasPrefix(msg, []byte(`"ERR `)) ||
+			bytes.HasPrefix(msg, []byte(`"WRONGTYPE `))
+	}
+	return errs, nil
+}
+
+func (c *webSocketClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,258 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package bigkeys finds the largest keys of the keyspace, the memory hogs, without blocking the shards. An
+// analysis lists the keys of every shard, then has the shard measure them in batches of bigkeys.batch_size
+// keys, so that the shard executes the commands of the clients between two batches. The keys deleted during
+// an analysis are left out, like the keys created once their shard was listed.
+//
+// The size of a key is the length of its DUMP encoding, estimated for the types DUMP does not encode, and
+// its number of elements is the number of members of a collection or the length of a string. For every
+// type, an analysis keeps the bigkeys.top_keys largest keys by size and by number of elements, as reported
+// by BIGKEYS, INFO bigkeys and the /bigkeys endpoint of the metrics server.
+package bigkeys
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+)
+
+// MeasureCmd is the internal command measuring keys of a shard. It replies with a [type, size, elements]
+// entry per key, nil for the keys that do not exist.
+const MeasureCmd = "SINGLEKEYSIZES"
+
+var (
+	ErrNotRunning = errors.New("ERR the big keys analyzer is not running")
+	ErrScanning   = errors.New("ERR an analysis of the big keys is already in progress")
+	ErrNoReport   = errors.New("ERR no analysis of the big keys completed yet, start one with BIGKEYS SCAN")
+)
+
+// Key is a key measured by an analysis.
+type Key struct {
+	Key      string `json:"key"`
+	Size     int64  `json:"size"`
+	Elements int64  `json:"elements"`
+}
+
+// TypeReport is the total size of the keys of a type, and the largest ones.
+type TypeReport struct {
+	Type       string `json:"type"`
+	Keys       int64  `json:"keys"`
+	Size       int64  `json:"size"`
+	Elements   int64  `json:"elements"`
+	BySize     []Key  `json:"by_size"`     // BySize are the largest keys by size, the largest first
+	ByElements []Key  `json:"by_elements"` // ByElements are the largest keys by number of elements, the largest first
+}
+
+// Report is the result of an analysis.
+type Report struct {
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+	Keys       int64        `json:"keys"`  // Keys is the number of keys measured
+	Types      []TypeReport `json:"types"` // Types are the types of the keys measured, ordered by name
+}
+
+// Executor executes a command on a shard and returns its result.
+type Executor func(ctx context.Context, shardID uint8, c *cmd.DiceDBCmd) (interface{}, error)
+
+var (
+	// active is the running analyzer, the one BIGKEYS SCAN requests analyses from
+	active atomic.Pointer[Analyzer]
+	// latest is the report of the last analysis completed, nil before the first one
+	latest atomic.Pointer[Report]
+)
+
+// Analyzer analyzes the keys of the shards every bigkeys.interval, and whenever BIGKEYS SCAN requests it.
+type Analyzer struct {
+	shards   int
+	exec     Executor
+	requests chan struct{}
+	scanning atomic.Bool
+}
+
+// New returns an analyzer of the keys of the shards, the commands being executed by exec.
+func New(shards int, exec Executor) *Analyzer {
+	return &Analyzer{
+		shards:   shards,
+		exec:     exec,
+		requests: make(chan struct{}, 1),
+	}
+}
+
+// Run analyzes the keys until the context is canceled, an analysis in progress being given up then. The
+// analyzer serves BIGKEYS SCAN while it runs.
+func (a *Analyzer) Run(ctx context.Context) error {
+	active.Store(a)
+	defer active.CompareAndSwap(a, nil)
+
+	var tick <-chan time.Time
+	if interval := config.DiceConfig().BigKeys.Interval; interval > 0 {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+		case <-a.requests:
+		}
+
+		report, err := a.Analyze(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Warn("could not analyze the big keys", slog.Any("error", err))
+			continue
+		}
+		latest.Store(report)
+		slog.Debug("analyzed the big keys", slog.Int64("keys", report.Keys),
+			slog.Duration("duration", report.FinishedAt.Sub(report.StartedAt)))
+	}
+}
+
+// Scan requests an analysis from the running analyzer, it runs in the background.
+func Scan() error {
+	a := active.Load()
+	if a == nil {
+		return ErrNotRunning
+	}
+	if a.scanning.Load() {
+		return ErrScanning
+	}
+	select {
+	case a.requests <- struct{}{}:
+	default:
+		// An analysis is requested already
+	}
+	return nil
+}
+
+// Scanning reports whether an analysis is in progress.
+func Scanning() bool {
+	a := active.Load()
+	return a != nil && a.scanning.Load()
+}
+
+// Latest returns the report of the last analysis completed, nil before the first one.
+func Latest() *Report {
+	return latest.Load()
+}
+
+// Analyze measures the keys of every shard, one shard after the other, and returns the largest ones.
+func (a *Analyzer) Analyze(ctx context.Context) (*Report, error) {
+	a.scanning.Store(true)
+	defer a.scanning.Store(false)
+
+	top := config.DiceConfig().BigKeys.TopKeys
+	batchSize := config.DiceConfig().BigKeys.BatchSize
+
+	report := &Report{StartedAt: time.Now()}
+	types := make(map[string]*TypeReport)
+	for id := 0; id < a.shards; id++ {
+		result, err := a.exec(ctx, uint8(id), &cmd.DiceDBCmd{Cmd: "SINGLEKEYS", Args: []string{"*"}})
+		if err != nil {
+			return nil, err
+		}
+		keys, _ := result.([]string)
+
+		for len(keys) > 0 {
+			batch := keys[:min(batchSize, len(keys))]
+			keys = keys[len(batch):]
+
+			result, err := a.exec(ctx, uint8(id), &cmd.DiceDBCmd{Cmd: MeasureCmd, Args: batch})
+			if err != nil {
+				return nil, err
+			}
+			entries, _ := result.([]interface{})
+			for i, entry := range entries {
+				measured, ok := entry.([]interface{})
+				if !ok || len(measured) != 3 || i >= len(batch) {
+					// The key was deleted since its shard was listed
+					continue
+				}
+				name, _ := measured[0].(string)
+				size, _ := measured[1].(int64)
+				elements, _ := measured[2].(int64)
+
+				t, ok := types[name]
+				if !ok {
+					t = &TypeReport{Type: name}
+					types[name] = t
+				}
+				t.add(Key{Key: batch[i], Size: size, Elements: elements}, top)
+				report.Keys++
+			}
+		}
+	}
+
+	for _, t := range types {
+		report.Types = append(report.Types, *t)
+	}
+	slices.SortFunc(report.Types, func(a, b TypeReport) int { return strings.Compare(a.Type, b.Type) })
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// add counts the key in the totals of the type, and keeps it if it is one of the top largest
+func (t *TypeReport) add(k Key, top int) {
+	t.Keys++
+	t.Size += k.Size
+	t.Elements += k.Elements
+	t.BySize = insert(t.BySize, k, top, bySize)
+	t.ByElements = insert(t.ByElements, k, top, byElements)
+}
+
+// insert inserts the key in the keys ordered by compare, keeping the top first ones
+func insert(keys []Key, k Key, top int, compare func(a, b Key) int) []Key {
+	if len(keys) >= top && compare(k, keys[len(keys)-1]) >= 0 {
+		return keys
+	}
+	i, _ := slices.BinarySearchFunc(keys, k, compare)
+	keys = slices.Insert(keys, i, k)
+	if len(keys) > top {
+		keys = keys[:top]
+	}
+	return keys
+}
+
+// bySize orders the keys by size, the largest first, and then by name
+func bySize(a, b Key) int {
+	if c := cmp.Compare(b.Size, a.Size); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Key, b.Key)
+}
+
+// byElements orders the keys by number of elements, the largest first, and then by name
+func byElements(a, b Key) int {
+	if c := cmp.Compare(b.Elements, a.Elements); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Key, b.Key)
+}
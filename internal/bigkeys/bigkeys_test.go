@@ -0,0 +1,152 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bigkeys_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/bigkeys"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExecutor returns an executor of the commands on the stores, executing them as the shards do
+func newExecutor(stores []*dstore.Store, executed *[]string) bigkeys.Executor {
+	return func(_ context.Context, id uint8, c *cmd.DiceDBCmd) (interface{}, error) {
+		*executed = append(*executed, fmt.Sprintf("%d %s %d", id, c.Cmd, len(c.Args)))
+		resp := eval.DiceCmds[c.Cmd].NewEval(c.Args, stores[id])
+		return resp.Result, resp.Error
+	}
+}
+
+func execute(t *testing.T, store *dstore.Store, command string, args ...string) {
+	resp := eval.DiceCmds[command].NewEval(args, store)
+	require.NoError(t, resp.Error, strings.Join(append([]string{command}, args...), " "))
+}
+
+func TestAnalyze(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().BigKeys.TopKeys = 2
+	config.DiceConfig().BigKeys.BatchSize = 3
+
+	stores := []*dstore.Store{dstore.NewStore(nil, nil), dstore.NewStore(nil, nil)}
+	execute(t, stores[0], "SET", "small", "v")
+	execute(t, stores[0], "SET", "large", strings.Repeat("v", 1000))
+	execute(t, stores[1], "SET", "medium", strings.Repeat("v", 100))
+	execute(t, stores[1], "SET", "counter", "12345")
+	execute(t, stores[0], "SADD", "tags", "a", "b", "c", "d")
+	execute(t, stores[1], "SADD", "colors", strings.Repeat("red", 100), "blue")
+	execute(t, stores[1], "HSET", "user", "name", "alice", "age", "30")
+	execute(t, stores[0], "LPUSH", "queue", "1", "2", "3")
+
+	var executed []string
+	report, err := bigkeys.New(2, newExecutor(stores, &executed)).Analyze(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 8, report.Keys)
+	assert.False(t, report.FinishedAt.Before(report.StartedAt))
+	// The keys are measured in batches of 3
+	assert.Equal(t, []string{"0 SINGLEKEYS 1", "0 SINGLEKEYSIZES 3", "0 SINGLEKEYSIZES 1",
+		"1 SINGLEKEYS 1", "1 SINGLEKEYSIZES 3", "1 SINGLEKEYSIZES 1"}, executed)
+
+	types := make(map[string]bigkeys.TypeReport)
+	var names []string
+	for _, r := range report.Types {
+		types[r.Type] = r
+		names = append(names, r.Type)
+	}
+	assert.Equal(t, []string{"hash", "list", "set", "string"}, names)
+
+	strs := types["string"]
+	assert.EqualValues(t, 4, strs.Keys)
+	assert.EqualValues(t, 1+1000+100+5, strs.Elements)
+	require.Len(t, strs.BySize, 2)
+	assert.Equal(t, "large", strs.BySize[0].Key)
+	assert.Equal(t, "medium", strs.BySize[1].Key)
+	assert.Greater(t, strs.BySize[0].Size, int64(1000))
+	assert.Equal(t, []bigkeys.Key{strs.BySize[0], strs.BySize[1]}, strs.ByElements)
+
+	// The largest set by size is not the one with the most members
+	sets := types["set"]
+	assert.Equal(t, "colors", sets.BySize[0].Key)
+	assert.Equal(t, "tags", sets.ByElements[0].Key)
+	assert.EqualValues(t, 4, sets.ByElements[0].Elements)
+
+	assert.EqualValues(t, 2, types["hash"].Elements)
+	assert.Positive(t, types["hash"].Size, "the size of the types DUMP does not encode is estimated")
+	assert.EqualValues(t, 3, types["list"].ByElements[0].Elements)
+}
+
+func TestAnalyzeSkipsDeletedKeys(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+
+	store := dstore.NewStore(nil, nil)
+	execute(t, store, "SET", "kept", "v")
+	execute(t, store, "SET", "deleted", "v")
+
+	var executed []string
+	exec := newExecutor([]*dstore.Store{store}, &executed)
+	report, err := bigkeys.New(1, func(ctx context.Context, id uint8, c *cmd.DiceDBCmd) (interface{}, error) {
+		// The key is deleted once its shard is listed
+		if c.Cmd == bigkeys.MeasureCmd {
+			execute(t, store, "DEL", "deleted")
+		}
+		return exec(ctx, id, c)
+	}).Analyze(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 1, report.Keys)
+	require.Len(t, report.Types, 1)
+	assert.Equal(t, []bigkeys.Key{{Key: "kept", Size: report.Types[0].Size, Elements: 1}}, report.Types[0].BySize)
+}
+
+func TestScan(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	assert.ErrorIs(t, bigkeys.Scan(), bigkeys.ErrNotRunning)
+
+	store := dstore.NewStore(nil, nil)
+	execute(t, store, "SET", "k", "v")
+	var executed []string
+	a := bigkeys.New(1, newExecutor([]*dstore.Store{store}, &executed))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = a.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	previous := bigkeys.Latest()
+	// The analyzer serves BIGKEYS SCAN once it runs
+	require.Eventually(t, func() bool { return bigkeys.Scan() == nil }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		report := bigkeys.Latest()
+		return report != nil && report != previous && report.Keys == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,79 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package blocking
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// Command is a blocking command, served by the non-blocking command popping from a single key.
+type Command struct {
+	Pop string // Pop is the non-blocking command serving the client, e.g. LPOP for BLPOP
+}
+
+// Commands maps the name of the blocking commands to their description.
+var Commands = map[string]Command{
+	"BLPOP":    {Pop: "LPOP"},
+	"BRPOP":    {Pop: "RPOP"},
+	"BZPOPMIN": {Pop: "ZPOPMIN"},
+	"BZPOPMAX": {Pop: "ZPOPMAX"},
+}
+
+// ParseArgs parses the arguments of a blocking command, `key [key ...] timeout`. The timeout is in seconds
+// and may have a decimal part, 0 meaning forever.
+func ParseArgs(name string, args []string) (keys []string, timeout time.Duration, err error) {
+	if len(args) < 2 {
+		return nil, 0, diceerrors.ErrWrongArgumentCount(name)
+	}
+
+	seconds, err := strconv.ParseFloat(args[len(args)-1], 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return nil, 0, diceerrors.ErrGeneral("timeout is not a float or out of range")
+	}
+	if seconds < 0 {
+		return nil, 0, diceerrors.ErrGeneral("timeout is negative")
+	}
+	return args[:len(args)-1], time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Reply returns the reply of a blocking command from the result of its pop command on key, and whether
+// the pop command served the client: the key followed by the popped element, e.g. `key value` for BLPOP
+// or `key member score` for BZPOPMIN.
+func Reply(key string, popped interface{}) (reply []interface{}, served bool) {
+	switch v := popped.(type) {
+	case string:
+		return []interface{}{key, v}, true
+	case []string:
+		if len(v) == 0 {
+			return nil, false
+		}
+		reply = []interface{}{key}
+		for _, s := range v {
+			reply = append(reply, s)
+		}
+		return reply, true
+	case nil, clientio.RespType:
+		return nil, false
+	default:
+		return []interface{}{key, v}, true
+	}
+}
@@ -0,0 +1,32 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package blocking
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+// The blocking commands pop like the commands serving them, they are logged, deduplicated and read after as writes
+func TestCommandsAreWrites(t *testing.T) {
+	for name, c := range Commands {
+		assert.Equal(t, audit.CategoryWrite, audit.Categorize(name), name)
+		assert.Equal(t, audit.CategoryWrite, audit.Categorize(c.Pop), c.Pop)
+	}
+}
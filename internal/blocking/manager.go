@@ -0,0 +1,229 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package blocking implements the waits of the blocking commands, e.g. BLPOP or BZPOPMIN. The clients
+// blocked on a key are queued in the order they blocked and woken up one at a time, the first one
+// first, when the key is written, so that every write serves the longest waiting client.
+package blocking
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/dicedb/dice/internal/stats"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// ErrTimeout is returned by Block when the client was not served within its timeout.
+var ErrTimeout = errors.New("blocking timeout elapsed")
+
+// Waiter is a client blocked on keys.
+type Waiter struct {
+	keys    []string
+	elems   []*list.Element // elems is the position of the waiter in the queue of every key
+	woken   chan struct{}   // woken is signaled when a key the waiter is first in line for is written
+	expired chan struct{}   // expired is closed when the timeout of the waiter elapsed
+	slot    int             // slot is the slot of the timeout in the wheel, -1 without timeout
+	rounds  int             // rounds is the number of turns of the wheel left before the timeout
+}
+
+// Manager queues the clients blocked on keys. It is shared by the shards, which report the writes of their
+// keys to it, and by the frontends, whose clients block on it. It is safe for concurrent use.
+type Manager struct {
+	mu      sync.Mutex
+	queues  map[string]*list.List // queues holds the waiters blocked on every key, first blocked first
+	wheel   *wheel
+	blocked int // blocked is the number of blocked clients
+}
+
+func NewManager() *Manager {
+	m := &Manager{queues: make(map[string]*list.List)}
+	m.wheel = newWheel(utils.GetCurrentTime(), func(w *Waiter) {
+		close(w.expired)
+	})
+	return m
+}
+
+// Run expires the blocked clients whose timeout elapsed, until ctx is done.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(wheelTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Advance(utils.GetCurrentTime())
+		}
+	}
+}
+
+// Advance expires the blocked clients whose timeout elapsed by now. Run advances the manager every tick,
+// a scheduler driving the manager in place of Run advances it on its own clock.
+func (m *Manager) Advance(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.wheel.advance(now)
+}
+
+// OnKeyEvent wakes up the client first in line for a key that was written. It makes the manager a key
+// event subscriber of the stores of the shards.
+func (m *Manager) OnKeyEvent(e dstore.KeyEvent) {
+	if e.Type == dstore.KeyEventPut {
+		m.Signal(e.Key)
+	}
+}
+
+// OnKeyEvents wakes up the clients first in line for the keys written by a batch, under a single lock.
+func (m *Manager) OnKeyEvents(events []dstore.KeyEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range events {
+		if e.Type == dstore.KeyEventPut {
+			m.signal(e.Key)
+		}
+	}
+}
+
+// Signal wakes up the client first in line for the key, if any. The client is woken up only if it is not
+// already, a wake-up left pending is handed over to the next client when it stops waiting.
+func (m *Manager) Signal(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.signal(key)
+}
+
+func (m *Manager) signal(key string) {
+	q, ok := m.queues[key]
+	if !ok {
+		return
+	}
+	select {
+	case q.Front().Value.(*Waiter).woken <- struct{}{}:
+	default:
+	}
+}
+
+// Len returns the number of blocked clients.
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.blocked
+}
+
+// Block blocks the client on the keys until serve serves it from one of them. serve is called with the keys
+// the client is first in line for: right away, then every time one of them is written or the client gets
+// first in line for another key. It reports whether the client was served, in which case Block returns nil.
+// Block returns ErrTimeout once timeout elapsed, 0 meaning forever, and the error of ctx once it is done,
+// e.g. when the client disconnects. The client stops waiting on every key when Block returns.
+func (m *Manager) Block(ctx context.Context, keys []string, timeout time.Duration,
+	serve func(key string) (served bool, err error)) error {
+	w := m.register(keys, timeout)
+	defer m.unregister(w)
+
+	for {
+		for _, key := range m.firstInLine(w) {
+			served, err := serve(key)
+			if err != nil {
+				return err
+			}
+			if served {
+				return nil
+			}
+		}
+
+		select {
+		case <-w.woken:
+		case <-w.expired:
+			return ErrTimeout
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// register queues the waiter last in line for every key.
+func (m *Manager) register(keys []string, timeout time.Duration) *Waiter {
+	w := &Waiter{
+		keys:    keys,
+		elems:   make([]*list.Element, len(keys)),
+		woken:   make(chan struct{}, 1),
+		expired: make(chan struct{}),
+		slot:    -1,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, key := range keys {
+		q, ok := m.queues[key]
+		if !ok {
+			q = list.New()
+			m.queues[key] = q
+		}
+		w.elems[i] = q.PushBack(w)
+	}
+	if timeout > 0 {
+		m.wheel.schedule(w, timeout)
+	}
+	m.blocked++
+	stats.ClientBlocked()
+	return w
+}
+
+// unregister removes the waiter from the queues, waking up the clients it was in front of.
+func (m *Manager) unregister(w *Waiter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, key := range w.keys {
+		q := m.queues[key]
+		first := q.Front() == w.elems[i]
+		q.Remove(w.elems[i])
+		if q.Len() == 0 {
+			delete(m.queues, key)
+		} else if first {
+			// The key may still hold values for the next client
+			m.signal(key)
+		}
+	}
+	m.wheel.cancel(w)
+	m.blocked--
+	stats.ClientUnblocked()
+}
+
+// firstInLine returns the keys the waiter is first in line for, the ones it may be served from.
+func (m *Manager) firstInLine(w *Waiter) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(w.keys))
+	for i, key := range w.keys {
+		if m.queues[key].Front() == w.elems[i] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
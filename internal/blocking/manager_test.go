@@ -0,0 +1,161 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package blocking
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stock is the values of the keys popped from by the blocked clients of the tests.
+type stock struct {
+	mu     sync.Mutex
+	values map[string]int
+	served []string // served is the clients served, in order
+}
+
+func newStock() *stock {
+	return &stock{values: make(map[string]int)}
+}
+
+func (s *stock) add(key string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] += n
+}
+
+// pop returns the serve function of the client, popping a value of the key if there is one.
+func (s *stock) pop(client string) func(key string) (bool, error) {
+	return func(key string) (bool, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.values[key] == 0 {
+			return false, nil
+		}
+		s.values[key]--
+		s.served = append(s.served, client+":"+key)
+		return true, nil
+	}
+}
+
+func (s *stock) servedClients() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.served...)
+}
+
+// block blocks the client in the background, in line after the clients already blocked.
+func block(ctx context.Context, t *testing.T, m *Manager, s *stock, client string, keys []string, timeout time.Duration) chan error {
+	blocked := m.Len()
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Block(ctx, keys, timeout, s.pop(client))
+	}()
+	assert.Eventually(t, func() bool { return m.Len() == blocked+1 }, time.Second, time.Millisecond)
+	return done
+}
+
+func TestBlockServedRightAway(t *testing.T) {
+	m := NewManager()
+	s := newStock()
+	s.add("k", 1)
+
+	assert.NoError(t, m.Block(context.Background(), []string{"k"}, 0, s.pop("c1")))
+	assert.Equal(t, []string{"c1:k"}, s.servedClients())
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestBlockFIFO(t *testing.T) {
+	m := NewManager()
+	s := newStock()
+	ctx := context.Background()
+
+	c1 := block(ctx, t, m, s, "c1", []string{"k"}, 0)
+	c2 := block(ctx, t, m, s, "c2", []string{"other", "k"}, 0)
+	c3 := block(ctx, t, m, s, "c3", []string{"k"}, 0)
+
+	// Every write serves the client blocked the longest
+	for _, done := range []chan error{c1, c2, c3} {
+		s.add("k", 1)
+		m.Signal("k")
+		assert.NoError(t, <-done)
+	}
+	assert.Equal(t, []string{"c1:k", "c2:k", "c3:k"}, s.servedClients())
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestBlockHandsOverValues(t *testing.T) {
+	m := NewManager()
+	s := newStock()
+	ctx := context.Background()
+
+	c1 := block(ctx, t, m, s, "c1", []string{"k"}, 0)
+	c2 := block(ctx, t, m, s, "c2", []string{"k"}, 0)
+
+	// A single wake-up for two values, the client served wakes up the next one
+	s.add("k", 2)
+	m.Signal("k")
+	assert.NoError(t, <-c1)
+	assert.NoError(t, <-c2)
+	assert.Equal(t, []string{"c1:k", "c2:k"}, s.servedClients())
+}
+
+func TestBlockMultipleKeys(t *testing.T) {
+	m := NewManager()
+	s := newStock()
+	ctx := context.Background()
+
+	c1 := block(ctx, t, m, s, "c1", []string{"a", "b"}, 0)
+	s.add("b", 1)
+	m.Signal("b")
+	assert.NoError(t, <-c1)
+	assert.Equal(t, []string{"c1:b"}, s.servedClients())
+}
+
+func TestBlockTimeout(t *testing.T) {
+	m := NewManager()
+	s := newStock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	start := time.Now()
+	assert.ErrorIs(t, m.Block(ctx, []string{"k"}, 50*time.Millisecond, s.pop("c1")), ErrTimeout)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestBlockDisconnect(t *testing.T) {
+	m := NewManager()
+	s := newStock()
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	c1 := block(ctx, t, m, s, "c1", []string{"k"}, 0)
+	c2 := block(context.Background(), t, m, s, "c2", []string{"k"}, 0)
+
+	// The value left for the disconnected client goes to the next one
+	s.add("k", 1)
+	disconnect()
+	assert.ErrorIs(t, <-c1, context.Canceled)
+	assert.NoError(t, <-c2)
+	assert.Equal(t, []string{"c2:k"}, s.servedClients())
+	assert.Equal(t, 0, m.Len())
+}
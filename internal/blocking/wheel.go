@@ -0,0 +1,81 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package blocking
+
+import "time"
+
+const (
+	// wheelTick is the resolution of the timeouts of the blocked clients
+	wheelTick = 10 * time.Millisecond
+	// wheelSize is the number of slots of the wheel, it goes round every wheelSize*wheelTick
+	wheelSize = 512
+)
+
+// wheel is a hashed timing wheel holding the timeouts of the blocked clients. Scheduling and cancelling
+// a timeout are O(1) whatever the number of clients, and a tick only visits the clients of its slot.
+// The wheel is not safe for concurrent use, it is guarded by the mutex of its manager.
+type wheel struct {
+	slots   [wheelSize]map[*Waiter]struct{}
+	pos     int       // pos is the slot of the current tick
+	ticks   int64     // ticks is the number of ticks elapsed since start
+	start   time.Time // start is the time of tick 0
+	expired func(w *Waiter)
+}
+
+func newWheel(now time.Time, expired func(w *Waiter)) *wheel {
+	wh := &wheel{start: now, expired: expired}
+	for i := range wh.slots {
+		wh.slots[i] = make(map[*Waiter]struct{})
+	}
+	return wh
+}
+
+// schedule makes the waiter expire once timeout elapsed, rounded up to the next tick.
+func (wh *wheel) schedule(w *Waiter, timeout time.Duration) {
+	ticks := int((timeout + wheelTick - 1) / wheelTick)
+	if ticks < 1 {
+		ticks = 1
+	}
+	w.slot = (wh.pos + ticks) % wheelSize
+	w.rounds = (ticks - 1) / wheelSize
+	wh.slots[w.slot][w] = struct{}{}
+}
+
+// cancel removes the timeout of the waiter, if any.
+func (wh *wheel) cancel(w *Waiter) {
+	if w.slot < 0 {
+		return
+	}
+	delete(wh.slots[w.slot], w)
+	w.slot = -1
+}
+
+// advance moves the wheel up to now, expiring the waiters of the slots it goes through. The wheel
+// catches up with the ticks missed when it was not advanced in time.
+func (wh *wheel) advance(now time.Time) {
+	for target := int64(now.Sub(wh.start) / wheelTick); wh.ticks < target; wh.ticks++ {
+		wh.pos = (wh.pos + 1) % wheelSize
+		for w := range wh.slots[wh.pos] {
+			if w.rounds > 0 {
+				w.rounds--
+				continue
+			}
+			wh.cancel(w)
+			wh.expired(w)
+		}
+	}
+}
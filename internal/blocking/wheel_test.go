@@ -0,0 +1,57 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package blocking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWheel(t *testing.T) {
+	start := time.Now()
+	var expired []*Waiter
+	wh := newWheel(start, func(w *Waiter) {
+		expired = append(expired, w)
+	})
+
+	soon := &Waiter{slot: -1}
+	later := &Waiter{slot: -1}
+	cancelled := &Waiter{slot: -1}
+	// Beyond a turn of the wheel
+	last := &Waiter{slot: -1}
+	wh.schedule(soon, 25*time.Millisecond)
+	wh.schedule(later, 100*time.Millisecond)
+	wh.schedule(cancelled, 50*time.Millisecond)
+	wh.schedule(last, (wheelSize+2)*wheelTick)
+	wh.cancel(cancelled)
+
+	wh.advance(start.Add(20 * time.Millisecond))
+	assert.Empty(t, expired)
+
+	// Timeouts are rounded up to the next tick
+	wh.advance(start.Add(30 * time.Millisecond))
+	assert.Equal(t, []*Waiter{soon}, expired)
+
+	wh.advance(start.Add(wheelSize * wheelTick))
+	assert.Equal(t, []*Waiter{soon, later}, expired)
+
+	wh.advance(start.Add((wheelSize + 2) * wheelTick))
+	assert.Equal(t, []*Waiter{soon, later, last}, expired)
+	assert.Equal(t, -1, last.slot)
+}
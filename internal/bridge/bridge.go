@@ -0,0 +1,253 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package bridge mirrors the keys of an upstream Redis, so that DiceDB serves as a reactive read replica in
+// front of an existing Redis: the keys matching the patterns are copied once, then kept up to date from the
+// keyspace notifications of the upstream, and can be watched like any other key.
+//
+// The upstream must publish the keyspace notifications of the keys, e.g. with notify-keyspace-events set to KA.
+// The notifications published while the bridge is disconnected are lost, the keys are copied again every time
+// the bridge subscribes. The keys written locally are overwritten by the next change of the upstream.
+//
+// In replication mode, the bridge follows the upstream as one of its replicas instead, with PSYNC: the keys are
+// loaded from the RDB payload of a full resynchronization, which replaces every local key, then the writes are
+// applied from the replication stream, resumed after a disconnection when the upstream still has it. The
+// commands writing keys owned by several shards, other than DEL, UNLINK, MSET and MSETNX, are not applied.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/shard"
+)
+
+// Modes of a bridge
+const (
+	// ModeNotifications mirrors the keys from the keyspace notifications of the upstream
+	ModeNotifications = "notifications"
+	// ModeReplication mirrors the keys from the replication stream of the upstream
+	ModeReplication = "replication"
+)
+
+// Options describes the upstream and the keys mirrored.
+type Options struct {
+	Addr     string
+	Password string
+	DB       int
+	Patterns []string
+	Mode     string
+	// ListeningPort is the port announced to the upstream in replication mode, 0 announces none
+	ListeningPort int
+}
+
+// Shards applies the copies of the keys.
+type Shards interface {
+	// Route returns the shard owning the key
+	Route(key string) uint8
+	// All returns every shard
+	All() []uint8
+	// Exec executes the commands of every shard and returns their responses, in order
+	Exec(ctx context.Context, cmds map[uint8][]*cmd.DiceDBCmd) (map[uint8][]*eval.EvalResponse, error)
+}
+
+// managerShards applies the copies of the keys through the shard manager
+type managerShards struct {
+	manager *shard.ShardManager
+}
+
+func (s *managerShards) Route(key string) uint8 {
+	id, _ := s.manager.GetShardInfo(key)
+	return id
+}
+
+func (s *managerShards) All() []uint8 {
+	ids := make([]uint8, s.manager.GetShardCount())
+	for i := range ids {
+		ids[i] = uint8(i)
+	}
+	return ids
+}
+
+func (s *managerShards) Exec(ctx context.Context, cmds map[uint8][]*cmd.DiceDBCmd) (map[uint8][]*eval.EvalResponse, error) {
+	return s.manager.ExecBatch(ctx, &shard.Batch{ClientAddr: "bridge", Cmds: cmds})
+}
+
+// Types of the keys, as named by TYPE
+const (
+	typeNone   = "none"
+	typeString = "string"
+	typeList   = "list"
+	typeSet    = "set"
+	typeHash   = "hash"
+	typeZSet   = "zset"
+)
+
+// member is a member of a sorted set
+type member struct {
+	name  string
+	score float64
+}
+
+// snapshot is a key as read from the upstream
+type snapshot struct {
+	typ     string
+	str     string
+	list    []string // list holds the elements of a list or the members of a set
+	hash    map[string]string
+	members []member
+	ttl     time.Duration // ttl is 0 for a key without expiry
+}
+
+// upstream is the Redis the keys are mirrored from
+type upstream interface {
+	// scan calls fn with the keys matching the pattern
+	scan(ctx context.Context, pattern string, fn func(key string) error) error
+	// read returns the key, of type none if it does not exist
+	read(ctx context.Context, key string) (*snapshot, error)
+}
+
+// Bridge mirrors the keys of an upstream.
+type Bridge struct {
+	opts     Options
+	upstream upstream
+	shards   Shards
+
+	// mu serializes the copies of the keys, so that a key read before a change is never applied after the
+	// copy read once the change was notified
+	mu sync.Mutex
+}
+
+// mirror reads the key from the upstream and applies it, or deletes it if it does not exist anymore
+func (b *Bridge) mirror(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, err := b.upstream.read(ctx, key)
+	if err != nil {
+		return err
+	}
+	return b.apply(ctx, key, commands(key, s))
+}
+
+// remove deletes the key, removed from the upstream
+func (b *Bridge) remove(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.apply(ctx, key, []*cmd.DiceDBCmd{{Cmd: "DEL", Args: []string{key}}})
+}
+
+func (b *Bridge) apply(ctx context.Context, key string, cmds []*cmd.DiceDBCmd) error {
+	id := b.shards.Route(key)
+	resps, err := b.shards.Exec(ctx, map[uint8][]*cmd.DiceDBCmd{id: cmds})
+	if err != nil {
+		return err
+	}
+	for i, resp := range resps[id] {
+		if resp.Error != nil {
+			return fmt.Errorf("could not apply %s on %s: %w", cmds[i].Cmd, key, resp.Error)
+		}
+	}
+	return nil
+}
+
+// sync copies every key matching the pattern
+func (b *Bridge) sync(ctx context.Context, pattern string) error {
+	start := time.Now()
+	count := 0
+	err := b.upstream.scan(ctx, pattern, func(key string) error {
+		count++
+		if err := b.mirror(ctx, key); err != nil {
+			slog.Warn("could not mirror a key", slog.String("key", key), slog.Any("error", err))
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("mirrored the keys of the upstream", slog.String("pattern", pattern), slog.Int("keys", count),
+		slog.Duration("elapsed", time.Since(start)))
+	return nil
+}
+
+// notified applies the keyspace notification of an event of the key
+func (b *Bridge) notified(ctx context.Context, key, event string) error {
+	switch event {
+	case "del", "expired", "evicted", "rename_from", "move_from":
+		return b.remove(ctx, key)
+	default:
+		return b.mirror(ctx, key)
+	}
+}
+
+// commands returns the commands writing the key as read from the upstream
+func commands(key string, s *snapshot) []*cmd.DiceDBCmd {
+	var cmds []*cmd.DiceDBCmd
+	add := func(name string, args ...string) {
+		cmds = append(cmds, &cmd.DiceDBCmd{Cmd: name, Args: append([]string{key}, args...)})
+	}
+
+	switch s.typ {
+	case typeString:
+		// SET replaces the value whatever its type, without going through a missing key
+		add("SET", s.str)
+	case typeList:
+		add("DEL")
+		add("RPUSH", s.list...)
+	case typeSet:
+		add("DEL")
+		add("SADD", s.list...)
+	case typeHash:
+		add("DEL")
+		args := make([]string, 0, 2*len(s.hash))
+		for field, value := range s.hash {
+			args = append(args, field, value)
+		}
+		add("HSET", args...)
+	case typeZSet:
+		add("DEL")
+		args := make([]string, 0, 2*len(s.members))
+		for _, m := range s.members {
+			args = append(args, strconv.FormatFloat(m.score, 'g', -1, 64), m.name)
+		}
+		add("ZADD", args...)
+	default:
+		if s.typ != typeNone {
+			slog.Debug("the type of the key can't be mirrored", slog.String("key", key), slog.String("type", s.typ))
+		}
+		add("DEL")
+		return cmds
+	}
+
+	if s.ttl > 0 {
+		add("PEXPIRE", strconv.FormatInt(s.ttl.Milliseconds(), 10))
+	}
+	return cmds
+}
+
+// keyOf returns the key of a keyspace notification channel, __keyspace@<db>__:<key>
+func keyOf(channel string) (string, bool) {
+	_, key, ok := strings.Cut(channel, "__:")
+	return key, ok
+}
@@ -0,0 +1,190 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"path"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUpstream map[string]*snapshot
+
+func (u fakeUpstream) scan(_ context.Context, pattern string, fn func(key string) error) error {
+	keys := make([]string, 0, len(u))
+	for key := range u {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u fakeUpstream) read(_ context.Context, key string) (*snapshot, error) {
+	if s, ok := u[key]; ok {
+		return s, nil
+	}
+	return &snapshot{typ: typeNone}, nil
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *snapshot
+		want [][]string
+	}{
+		{"string", &snapshot{typ: typeString, str: "v", ttl: 1500 * time.Millisecond},
+			[][]string{{"SET", "k", "v"}, {"PEXPIRE", "k", "1500"}}},
+		{"list", &snapshot{typ: typeList, list: []string{"a", "b"}},
+			[][]string{{"DEL", "k"}, {"RPUSH", "k", "a", "b"}}},
+		{"set", &snapshot{typ: typeSet, list: []string{"a"}},
+			[][]string{{"DEL", "k"}, {"SADD", "k", "a"}}},
+		{"hash", &snapshot{typ: typeHash, hash: map[string]string{"f": "v"}},
+			[][]string{{"DEL", "k"}, {"HSET", "k", "f", "v"}}},
+		{"zset", &snapshot{typ: typeZSet, members: []member{{name: "m", score: 1.5}}},
+			[][]string{{"DEL", "k"}, {"ZADD", "k", "1.5", "m"}}},
+		{"missing", &snapshot{typ: typeNone}, [][]string{{"DEL", "k"}}},
+		{"unsupported", &snapshot{typ: "stream", ttl: time.Second}, [][]string{{"DEL", "k"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, c := range commands("k", tt.s) {
+				got = append(got, append([]string{c.Cmd}, c.Args...))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBridge(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+
+	manager := shard.NewShardManager(2, nil, make(chan error, 1))
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		manager.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	upstream := fakeUpstream{
+		"user:1":    {typ: typeString, str: "alice", ttl: time.Minute},
+		"user:list": {typ: typeList, list: []string{"a", "b"}},
+		"other":     {typ: typeString, str: "x"},
+	}
+	b := New(Options{}, manager)
+	b.upstream = upstream
+
+	local := func(name string, args ...string) interface{} {
+		s := b.shards.(*managerShards)
+		id := s.Route(args[0])
+		resps, err := s.Exec(ctx, map[uint8][]*cmd.DiceDBCmd{id: {{Cmd: name, Args: args}}})
+		require.NoError(t, err)
+		require.NoError(t, resps[id][0].Error)
+		return resps[id][0].Result
+	}
+
+	// The keys matching the pattern are copied
+	require.NoError(t, b.sync(ctx, "user:*"))
+	assert.Equal(t, "alice", local("GET", "user:1"))
+	assert.Equal(t, []string{"a", "b"}, local("LRANGE", "user:list", "0", "-1"))
+	assert.EqualValues(t, 0, local("EXISTS", "other"))
+	assert.Positive(t, local("PTTL", "user:1"))
+
+	// The notifications update the copies
+	upstream["user:1"] = &snapshot{typ: typeString, str: "bob"}
+	require.NoError(t, b.notified(ctx, "user:1", "set"))
+	assert.Equal(t, "bob", local("GET", "user:1"))
+	assert.Equal(t, clientio.IntegerNegativeOne, local("PTTL", "user:1"))
+
+	require.NoError(t, b.notified(ctx, "user:list", "del"))
+	assert.EqualValues(t, 0, local("EXISTS", "user:list"))
+
+	// A key gone by the time it is read is removed
+	delete(upstream, "user:1")
+	require.NoError(t, b.notified(ctx, "user:1", "set"))
+	assert.EqualValues(t, 0, local("EXISTS", "user:1"))
+}
+
+func TestWarm(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+
+	manager := shard.NewShardManager(2, nil, make(chan error, 1))
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		manager.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	b := New(Options{Patterns: []string{"user:*", "session:*"}}, manager)
+	b.upstream = fakeUpstream{
+		"user:1":    {typ: typeString, str: "alice"},
+		"session:1": {typ: typeHash, hash: map[string]string{"user": "1"}, ttl: time.Minute},
+		"other":     {typ: typeString, str: "x"},
+	}
+	require.NoError(t, b.warm(ctx))
+
+	s := b.shards.(*managerShards)
+	for key, want := range map[string]int64{"user:1": 1, "session:1": 1, "other": 0} {
+		id := s.Route(key)
+		resps, err := s.Exec(ctx, map[uint8][]*cmd.DiceDBCmd{id: {{Cmd: "EXISTS", Args: []string{key}}}})
+		require.NoError(t, err)
+		assert.EqualValues(t, want, resps[id][0].Result, key)
+	}
+
+	// The copy stops once the context is canceled
+	canceled, cancelWarm := context.WithCancel(ctx)
+	cancelWarm()
+	assert.ErrorIs(t, b.warm(canceled), context.Canceled)
+}
+
+func TestKeyOf(t *testing.T) {
+	key, ok := keyOf("__keyspace@0__:user:1")
+	assert.True(t, ok)
+	assert.Equal(t, "user:1", key)
+
+	_, ok = keyOf("news")
+	assert.False(t, ok)
+}
@@ -0,0 +1,717 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Opcodes of an RDB file
+const (
+	rdbOpSlotInfo     = 0xF4
+	rdbOpFunction2    = 0xF5
+	rdbOpFunction     = 0xF6
+	rdbOpModuleAux    = 0xF7
+	rdbOpIdle         = 0xF8
+	rdbOpFreq         = 0xF9
+	rdbOpAux          = 0xFA
+	rdbOpResizeDB     = 0xFB
+	rdbOpExpireTimeMS = 0xFC
+	rdbOpExpireTime   = 0xFD
+	rdbOpSelectDB     = 0xFE
+	rdbOpEOF          = 0xFF
+)
+
+// Types of the values of an RDB file
+const (
+	rdbTypeString          = 0
+	rdbTypeList            = 1
+	rdbTypeSet             = 2
+	rdbTypeZSet            = 3
+	rdbTypeHash            = 4
+	rdbTypeZSet2           = 5
+	rdbTypeListZiplist     = 10
+	rdbTypeSetIntset       = 11
+	rdbTypeZSetZiplist     = 12
+	rdbTypeHashZiplist     = 13
+	rdbTypeListQuicklist   = 14
+	rdbTypeHashListpack    = 16
+	rdbTypeZSetListpack    = 17
+	rdbTypeListQuicklist2  = 18
+	rdbTypeSetListpack     = 20
+	rdbMaxSupportedVersion = 12
+
+	// quicklistNodePlain is the container of a node of a quicklist holding a single large element
+	quicklistNodePlain = 1
+)
+
+// Encodings of the lengths and strings of an RDB file
+const (
+	rdbLen32    = 0x80
+	rdbLen64    = 0x81
+	rdbEncInt8  = 0
+	rdbEncInt16 = 1
+	rdbEncInt32 = 2
+	rdbEncLZF   = 3
+)
+
+var errRDBFormat = errors.New("malformed RDB payload")
+
+// rdbEntry is a key of an RDB file
+type rdbEntry struct {
+	db       int
+	key      string
+	value    *snapshot
+	expireAt int64 // expireAt is the expiry of the key in milliseconds since the epoch, 0 for a key without expiry
+}
+
+// rdbReader decodes an RDB file, as sent by a Redis to its replicas at a full resynchronization
+type rdbReader struct {
+	r *bufio.Reader
+}
+
+// parseRDB decodes the RDB file read from r, up to and including its checksum, and calls fn with every key.
+// Keys of types that can not be mirrored, e.g. streams or the ones of modules, fail the decoding since their
+// values can't be skipped.
+func parseRDB(r *bufio.Reader, fn func(e *rdbEntry) error) error {
+	rd := &rdbReader{r: r}
+
+	header := make([]byte, 9)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if string(header[:5]) != "REDIS" {
+		return fmt.Errorf("%w: missing magic string", errRDBFormat)
+	}
+	version, err := strconv.Atoi(string(header[5:]))
+	if err != nil {
+		return fmt.Errorf("%w: invalid version %q", errRDBFormat, header[5:])
+	}
+	if version > rdbMaxSupportedVersion {
+		return fmt.Errorf("unsupported RDB version %d", version)
+	}
+
+	db := 0
+	var expireAt int64
+	for {
+		op, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case rdbOpEOF:
+			if version >= 5 {
+				_, err = io.ReadFull(r, make([]byte, 8))
+			}
+			return err
+		case rdbOpSelectDB:
+			n, err := rd.length()
+			if err != nil {
+				return err
+			}
+			db = int(n)
+		case rdbOpResizeDB:
+			if _, err := rd.length(); err != nil {
+				return err
+			}
+			if _, err := rd.length(); err != nil {
+				return err
+			}
+		case rdbOpSlotInfo:
+			for i := 0; i < 3; i++ {
+				if _, err := rd.length(); err != nil {
+					return err
+				}
+			}
+		case rdbOpAux:
+			if _, err := rd.str(); err != nil {
+				return err
+			}
+			if _, err := rd.str(); err != nil {
+				return err
+			}
+		case rdbOpFunction, rdbOpFunction2:
+			if _, err := rd.str(); err != nil {
+				return err
+			}
+		case rdbOpModuleAux:
+			return errors.New("unsupported RDB payload: data of modules")
+		case rdbOpExpireTime:
+			b, err := rd.fixed(4)
+			if err != nil {
+				return err
+			}
+			expireAt = int64(binary.LittleEndian.Uint32(b)) * 1000
+		case rdbOpExpireTimeMS:
+			b, err := rd.fixed(8)
+			if err != nil {
+				return err
+			}
+			expireAt = int64(binary.LittleEndian.Uint64(b))
+		case rdbOpFreq:
+			if _, err := r.ReadByte(); err != nil {
+				return err
+			}
+		case rdbOpIdle:
+			if _, err := rd.length(); err != nil {
+				return err
+			}
+		default:
+			key, err := rd.str()
+			if err != nil {
+				return err
+			}
+			value, err := rd.value(op)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			if err := fn(&rdbEntry{db: db, key: key, value: value, expireAt: expireAt}); err != nil {
+				return err
+			}
+			expireAt = 0
+		}
+	}
+}
+
+// value decodes a value of the type
+func (rd *rdbReader) value(typ byte) (*snapshot, error) {
+	switch typ {
+	case rdbTypeString:
+		s, err := rd.str()
+		return &snapshot{typ: typeString, str: s}, err
+	case rdbTypeList, rdbTypeSet:
+		elems, err := rd.strs(1)
+		if typ == rdbTypeList {
+			return &snapshot{typ: typeList, list: elems}, err
+		}
+		return &snapshot{typ: typeSet, list: elems}, err
+	case rdbTypeHash:
+		pairs, err := rd.strs(2)
+		return &snapshot{typ: typeHash, hash: toHash(pairs)}, err
+	case rdbTypeZSet, rdbTypeZSet2:
+		n, err := rd.length()
+		if err != nil {
+			return nil, err
+		}
+		s := &snapshot{typ: typeZSet}
+		for i := uint64(0); i < n; i++ {
+			name, err := rd.str()
+			if err != nil {
+				return nil, err
+			}
+			var score float64
+			if typ == rdbTypeZSet2 {
+				b, err := rd.fixed(8)
+				if err != nil {
+					return nil, err
+				}
+				score = math.Float64frombits(binary.LittleEndian.Uint64(b))
+			} else if score, err = rd.oldScore(); err != nil {
+				return nil, err
+			}
+			s.members = append(s.members, member{name: name, score: score})
+		}
+		return s, nil
+	case rdbTypeSetIntset:
+		blob, err := rd.str()
+		if err != nil {
+			return nil, err
+		}
+		elems, err := intset([]byte(blob))
+		return &snapshot{typ: typeSet, list: elems}, err
+	case rdbTypeListZiplist, rdbTypeHashZiplist, rdbTypeZSetZiplist:
+		blob, err := rd.str()
+		if err != nil {
+			return nil, err
+		}
+		elems, err := ziplist([]byte(blob))
+		if err != nil {
+			return nil, err
+		}
+		return packed(typ, elems)
+	case rdbTypeSetListpack, rdbTypeHashListpack, rdbTypeZSetListpack:
+		blob, err := rd.str()
+		if err != nil {
+			return nil, err
+		}
+		elems, err := listpack([]byte(blob))
+		if err != nil {
+			return nil, err
+		}
+		return packed(typ, elems)
+	case rdbTypeListQuicklist, rdbTypeListQuicklist2:
+		n, err := rd.length()
+		if err != nil {
+			return nil, err
+		}
+		s := &snapshot{typ: typeList}
+		for i := uint64(0); i < n; i++ {
+			container := uint64(0)
+			if typ == rdbTypeListQuicklist2 {
+				if container, err = rd.length(); err != nil {
+					return nil, err
+				}
+			}
+			blob, err := rd.str()
+			if err != nil {
+				return nil, err
+			}
+
+			var elems []string
+			switch {
+			case container == quicklistNodePlain:
+				elems = []string{blob}
+			case typ == rdbTypeListQuicklist2:
+				elems, err = listpack([]byte(blob))
+			default:
+				elems, err = ziplist([]byte(blob))
+			}
+			if err != nil {
+				return nil, err
+			}
+			s.list = append(s.list, elems...)
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unsupported RDB value type %d", typ)
+	}
+}
+
+// packed returns the value of a type encoded as a ziplist or a listpack, of the elements of the encoding
+func packed(typ byte, elems []string) (*snapshot, error) {
+	switch typ {
+	case rdbTypeListZiplist:
+		return &snapshot{typ: typeList, list: elems}, nil
+	case rdbTypeSetListpack:
+		return &snapshot{typ: typeSet, list: elems}, nil
+	case rdbTypeHashZiplist, rdbTypeHashListpack:
+		if len(elems)%2 != 0 {
+			return nil, errRDBFormat
+		}
+		return &snapshot{typ: typeHash, hash: toHash(elems)}, nil
+	default:
+		if len(elems)%2 != 0 {
+			return nil, errRDBFormat
+		}
+		s := &snapshot{typ: typeZSet}
+		for i := 0; i < len(elems); i += 2 {
+			score, err := strconv.ParseFloat(elems[i+1], 64)
+			if err != nil {
+				return nil, errRDBFormat
+			}
+			s.members = append(s.members, member{name: elems[i], score: score})
+		}
+		return s, nil
+	}
+}
+
+func toHash(pairs []string) map[string]string {
+	hash := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		hash[pairs[i]] = pairs[i+1]
+	}
+	return hash
+}
+
+func (rd *rdbReader) fixed(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(rd.r, b)
+	return b, err
+}
+
+// lengthOrEncoding decodes a length, or the encoding of a string when encoded is true
+func (rd *rdbReader) lengthOrEncoding() (n uint64, encoded bool, err error) {
+	first, err := rd.r.ReadByte()
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch first >> 6 {
+	case 0:
+		return uint64(first & 0x3F), false, nil
+	case 1:
+		next, err := rd.r.ReadByte()
+		return uint64(first&0x3F)<<8 | uint64(next), false, err
+	case 2:
+		switch first {
+		case rdbLen32:
+			b, err := rd.fixed(4)
+			if err != nil {
+				return 0, false, err
+			}
+			return uint64(binary.BigEndian.Uint32(b)), false, nil
+		case rdbLen64:
+			b, err := rd.fixed(8)
+			if err != nil {
+				return 0, false, err
+			}
+			return binary.BigEndian.Uint64(b), false, nil
+		default:
+			return 0, false, errRDBFormat
+		}
+	default:
+		return uint64(first & 0x3F), true, nil
+	}
+}
+
+func (rd *rdbReader) length() (uint64, error) {
+	n, encoded, err := rd.lengthOrEncoding()
+	if err == nil && encoded {
+		err = errRDBFormat
+	}
+	return n, err
+}
+
+// str decodes a string, stored as is, as an integer or compressed with LZF
+func (rd *rdbReader) str() (string, error) {
+	n, encoded, err := rd.lengthOrEncoding()
+	if err != nil {
+		return "", err
+	}
+	if !encoded {
+		b, err := rd.fixed(int(n))
+		return string(b), err
+	}
+
+	switch n {
+	case rdbEncInt8:
+		b, err := rd.r.ReadByte()
+		return strconv.Itoa(int(int8(b))), err
+	case rdbEncInt16:
+		b, err := rd.fixed(2)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int16(binary.LittleEndian.Uint16(b)))), nil
+	case rdbEncInt32:
+		b, err := rd.fixed(4)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int32(binary.LittleEndian.Uint32(b)))), nil
+	case rdbEncLZF:
+		clen, err := rd.length()
+		if err != nil {
+			return "", err
+		}
+		ulen, err := rd.length()
+		if err != nil {
+			return "", err
+		}
+		compressed, err := rd.fixed(int(clen))
+		if err != nil {
+			return "", err
+		}
+		b, err := lzfDecompress(compressed, int(ulen))
+		return string(b), err
+	default:
+		return "", errRDBFormat
+	}
+}
+
+// strs decodes a length followed by n times as many strings
+func (rd *rdbReader) strs(n uint64) ([]string, error) {
+	count, err := rd.length()
+	if err != nil {
+		return nil, err
+	}
+	elems := make([]string, 0, count*n)
+	for i := uint64(0); i < count*n; i++ {
+		s, err := rd.str()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, s)
+	}
+	return elems, nil
+}
+
+// oldScore decodes a score of the first encoding of sorted sets, as a string prefixed by its length
+func (rd *rdbReader) oldScore() (float64, error) {
+	n, err := rd.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 253:
+		return math.NaN(), nil
+	case 254:
+		return math.Inf(1), nil
+	case 255:
+		return math.Inf(-1), nil
+	}
+	b, err := rd.fixed(int(n))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(b), 64)
+}
+
+// lzfDecompress decompresses data compressed with LZF into n bytes
+func lzfDecompress(in []byte, n int) ([]byte, error) {
+	out := make([]byte, 0, n)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 32 {
+			// A literal run of ctrl+1 bytes
+			if i+ctrl+1 > len(in) {
+				return nil, errRDBFormat
+			}
+			out = append(out, in[i:i+ctrl+1]...)
+			i += ctrl + 1
+			continue
+		}
+
+		// A back reference of length ctrl>>5 + 2, or more for the longest ones
+		length := ctrl >> 5
+		if length == 7 {
+			if i >= len(in) {
+				return nil, errRDBFormat
+			}
+			length += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, errRDBFormat
+		}
+		ref := len(out) - (ctrl&0x1F)<<8 - int(in[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, errRDBFormat
+		}
+		for j := 0; j < length+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != n {
+		return nil, errRDBFormat
+	}
+	return out, nil
+}
+
+// intset decodes the integers of an intset
+func intset(b []byte) ([]string, error) {
+	if len(b) < 8 {
+		return nil, errRDBFormat
+	}
+	width := int(binary.LittleEndian.Uint32(b))
+	count := int(binary.LittleEndian.Uint32(b[4:]))
+	b = b[8:]
+	if width != 2 && width != 4 && width != 8 || len(b) < width*count {
+		return nil, errRDBFormat
+	}
+
+	elems := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		var v int64
+		switch width {
+		case 2:
+			v = int64(int16(binary.LittleEndian.Uint16(b[i*2:])))
+		case 4:
+			v = int64(int32(binary.LittleEndian.Uint32(b[i*4:])))
+		default:
+			v = int64(binary.LittleEndian.Uint64(b[i*8:]))
+		}
+		elems = append(elems, strconv.FormatInt(v, 10))
+	}
+	return elems, nil
+}
+
+// ziplist decodes the entries of a ziplist
+func ziplist(b []byte) ([]string, error) {
+	if len(b) < 11 {
+		return nil, errRDBFormat
+	}
+	var elems []string
+	i := 10
+	for {
+		if i >= len(b) {
+			return nil, errRDBFormat
+		}
+		if b[i] == 0xFF {
+			return elems, nil
+		}
+
+		// The length of the previous entry, on 1 byte or on 4 bytes after 0xFE
+		if b[i] == 0xFE {
+			i += 5
+		} else {
+			i++
+		}
+		if i >= len(b) {
+			return nil, errRDBFormat
+		}
+
+		enc := b[i]
+		var size int
+		var value string
+		switch {
+		case enc>>6 == 0:
+			size, i = int(enc&0x3F), i+1
+		case enc>>6 == 1:
+			if i+2 > len(b) {
+				return nil, errRDBFormat
+			}
+			size, i = int(enc&0x3F)<<8|int(b[i+1]), i+2
+		case enc>>6 == 2:
+			if i+5 > len(b) {
+				return nil, errRDBFormat
+			}
+			size, i = int(binary.BigEndian.Uint32(b[i+1:])), i+5
+		default:
+			var v int64
+			var width int
+			switch enc {
+			case 0xC0:
+				width = 2
+			case 0xD0:
+				width = 4
+			case 0xE0:
+				width = 8
+			case 0xF0:
+				width = 3
+			case 0xFE:
+				width = 1
+			default:
+				if enc < 0xF1 || enc > 0xFD {
+					return nil, errRDBFormat
+				}
+				v = int64(enc&0x0F) - 1
+			}
+			i++
+			if i+width > len(b) {
+				return nil, errRDBFormat
+			}
+			switch width {
+			case 1:
+				v = int64(int8(b[i]))
+			case 2:
+				v = int64(int16(binary.LittleEndian.Uint16(b[i:])))
+			case 3:
+				v = int64(int32(uint32(b[i])<<8|uint32(b[i+1])<<16|uint32(b[i+2])<<24) >> 8)
+			case 4:
+				v = int64(int32(binary.LittleEndian.Uint32(b[i:])))
+			case 8:
+				v = int64(binary.LittleEndian.Uint64(b[i:]))
+			}
+			i += width
+			elems = append(elems, strconv.FormatInt(v, 10))
+			continue
+		}
+
+		if i+size > len(b) {
+			return nil, errRDBFormat
+		}
+		value, i = string(b[i:i+size]), i+size
+		elems = append(elems, value)
+	}
+}
+
+// listpack decodes the entries of a listpack
+func listpack(b []byte) ([]string, error) {
+	if len(b) < 7 {
+		return nil, errRDBFormat
+	}
+	var elems []string
+	i := 6
+	for {
+		if i >= len(b) {
+			return nil, errRDBFormat
+		}
+		enc := b[i]
+		if enc == 0xFF {
+			return elems, nil
+		}
+
+		start := i
+		var value string
+		var size, width int
+		var v int64
+		isInt := true
+		switch {
+		case enc&0x80 == 0:
+			v, i = int64(enc&0x7F), i+1
+		case enc&0xC0 == 0x80:
+			size, i, isInt = int(enc&0x3F), i+1, false
+		case enc&0xE0 == 0xC0:
+			if i+2 > len(b) {
+				return nil, errRDBFormat
+			}
+			v, i = int64(uint16(enc&0x1F)<<8|uint16(b[i+1])), i+2
+			if v >= 1<<12 {
+				v -= 1 << 13
+			}
+		case enc&0xF0 == 0xE0:
+			if i+2 > len(b) {
+				return nil, errRDBFormat
+			}
+			size, i, isInt = int(enc&0x0F)<<8|int(b[i+1]), i+2, false
+		case enc == 0xF0:
+			if i+5 > len(b) {
+				return nil, errRDBFormat
+			}
+			size, i, isInt = int(binary.LittleEndian.Uint32(b[i+1:])), i+5, false
+		case enc >= 0xF1 && enc <= 0xF4:
+			width = map[byte]int{0xF1: 2, 0xF2: 3, 0xF3: 4, 0xF4: 8}[enc]
+			i++
+			if i+width > len(b) {
+				return nil, errRDBFormat
+			}
+			var u uint64
+			for j := width - 1; j >= 0; j-- {
+				u = u<<8 | uint64(b[i+j])
+			}
+			// Sign extends the integer of width bytes
+			shift := 64 - 8*width
+			v, i = int64(u<<shift)>>shift, i+width
+		default:
+			return nil, errRDBFormat
+		}
+
+		if isInt {
+			value = strconv.FormatInt(v, 10)
+		} else {
+			if i+size > len(b) {
+				return nil, errRDBFormat
+			}
+			value, i = string(b[i:i+size]), i+size
+		}
+		elems = append(elems, value)
+
+		// The entry is followed by its length, stored backwards on 1 to 5 bytes
+		switch l := i - start; {
+		case l <= 127:
+			i++
+		case l < 16383:
+			i += 2
+		case l < 2097151:
+			i += 3
+		case l < 268435455:
+			i += 4
+		default:
+			i += 5
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rdbWriter encodes RDB files for the tests
+type rdbWriter struct {
+	bytes.Buffer
+}
+
+func newRDBWriter() *rdbWriter {
+	w := &rdbWriter{}
+	w.WriteString("REDIS0011")
+	w.aux("redis-ver", "7.2.4")
+	return w
+}
+
+func (w *rdbWriter) length(n int) {
+	switch {
+	case n < 64:
+		w.WriteByte(byte(n))
+	case n < 16384:
+		w.WriteByte(byte(0x40 | n>>8))
+		w.WriteByte(byte(n))
+	default:
+		w.WriteByte(rdbLen32)
+		_ = binary.Write(w, binary.BigEndian, uint32(n))
+	}
+}
+
+func (w *rdbWriter) str(s string) {
+	w.length(len(s))
+	w.WriteString(s)
+}
+
+func (w *rdbWriter) aux(key, value string) {
+	w.WriteByte(rdbOpAux)
+	w.str(key)
+	w.str(value)
+}
+
+func (w *rdbWriter) key(typ byte, key string) {
+	w.WriteByte(typ)
+	w.str(key)
+}
+
+func (w *rdbWriter) end() []byte {
+	w.WriteByte(rdbOpEOF)
+	w.Write(make([]byte, 8))
+	return w.Bytes()
+}
+
+// testListpack encodes small strings and integers as a listpack
+func testListpack(elems ...interface{}) string {
+	var b bytes.Buffer
+	b.Write(make([]byte, 6))
+	for _, elem := range elems {
+		switch v := elem.(type) {
+		case string:
+			b.WriteByte(0x80 | byte(len(v)))
+			b.WriteString(v)
+			b.WriteByte(byte(1 + len(v)))
+		case int:
+			if v >= 0 && v < 128 {
+				b.WriteByte(byte(v))
+				b.WriteByte(1)
+			} else {
+				b.WriteByte(0xF1)
+				_ = binary.Write(&b, binary.LittleEndian, int16(v))
+				b.WriteByte(3)
+			}
+		}
+	}
+	b.WriteByte(0xFF)
+	return b.String()
+}
+
+func parse(t *testing.T, payload []byte) map[string]*rdbEntry {
+	entries := make(map[string]*rdbEntry)
+	require.NoError(t, parseRDB(bufio.NewReader(bytes.NewReader(payload)), func(e *rdbEntry) error {
+		entries[e.key] = e
+		return nil
+	}))
+	return entries
+}
+
+func TestParseRDB(t *testing.T) {
+	w := newRDBWriter()
+	w.WriteByte(rdbOpSelectDB)
+	w.length(0)
+	w.WriteByte(rdbOpResizeDB)
+	w.length(8)
+	w.length(1)
+
+	w.WriteByte(rdbOpExpireTimeMS)
+	_ = binary.Write(w, binary.LittleEndian, uint64(1893456000000))
+	w.key(rdbTypeString, "str")
+	w.str("value")
+
+	w.key(rdbTypeString, "int")
+	w.WriteByte(0xC0 | rdbEncInt16)
+	_ = binary.Write(w, binary.LittleEndian, int16(-300))
+
+	// "aaaaaaaa" compressed as a literal "a" followed by a reference to it of 7 bytes
+	w.key(rdbTypeString, "lzf")
+	w.WriteByte(0xC0 | rdbEncLZF)
+	w.length(4)
+	w.length(8)
+	w.Write([]byte{0x00, 'a', 0xA0, 0x00})
+
+	w.key(rdbTypeListQuicklist2, "list")
+	w.length(2)
+	w.length(2)
+	w.str(testListpack("a", 7))
+	w.length(quicklistNodePlain)
+	w.str("large")
+
+	w.key(rdbTypeSetIntset, "intset")
+	intsetBlob := []byte{2, 0, 0, 0, 2, 0, 0, 0}
+	intsetBlob = binary.LittleEndian.AppendUint16(intsetBlob, uint16(1))
+	intsetBlob = binary.LittleEndian.AppendUint16(intsetBlob, uint16(0xFFFF))
+	w.str(string(intsetBlob))
+
+	w.key(rdbTypeSetListpack, "set")
+	w.str(testListpack("x", "y"))
+
+	w.key(rdbTypeHashListpack, "hash")
+	w.str(testListpack("f", -5))
+
+	w.key(rdbTypeZSetListpack, "zset")
+	w.str(testListpack("m", 2, "n", "1.5"))
+
+	w.key(rdbTypeZSet2, "zset2")
+	w.length(1)
+	w.str("m")
+	_ = binary.Write(w, binary.LittleEndian, math.Float64bits(3.25))
+
+	w.key(rdbTypeHash, "plainhash")
+	w.length(1)
+	w.str("f")
+	w.str("v")
+
+	w.WriteByte(rdbOpSelectDB)
+	w.length(3)
+	w.key(rdbTypeString, "other")
+	w.str("db3")
+
+	entries := parse(t, w.end())
+	require.Len(t, entries, 11)
+
+	assert.Equal(t, &snapshot{typ: typeString, str: "value"}, entries["str"].value)
+	assert.Equal(t, int64(1893456000000), entries["str"].expireAt)
+	assert.Equal(t, "-300", entries["int"].value.str)
+	assert.Zero(t, entries["int"].expireAt)
+	assert.Equal(t, "aaaaaaaa", entries["lzf"].value.str)
+	assert.Equal(t, &snapshot{typ: typeList, list: []string{"a", "7", "large"}}, entries["list"].value)
+	assert.Equal(t, &snapshot{typ: typeSet, list: []string{"1", "-1"}}, entries["intset"].value)
+	assert.Equal(t, &snapshot{typ: typeSet, list: []string{"x", "y"}}, entries["set"].value)
+	assert.Equal(t, &snapshot{typ: typeHash, hash: map[string]string{"f": "-5"}}, entries["hash"].value)
+	assert.Equal(t, []member{{name: "m", score: 2}, {name: "n", score: 1.5}}, entries["zset"].value.members)
+	assert.Equal(t, []member{{name: "m", score: 3.25}}, entries["zset2"].value.members)
+	assert.Equal(t, map[string]string{"f": "v"}, entries["plainhash"].value.hash)
+	assert.Equal(t, 3, entries["other"].db)
+	assert.Equal(t, 0, entries["str"].db)
+}
+
+func TestParseRDBErrors(t *testing.T) {
+	err := parseRDB(bufio.NewReader(bytes.NewReader([]byte("RDIS00011"))), nil)
+	assert.ErrorIs(t, err, errRDBFormat)
+
+	w := newRDBWriter()
+	w.key(21, "stream")
+	err = parseRDB(bufio.NewReader(bytes.NewReader(w.end())), func(*rdbEntry) error { return nil })
+	assert.ErrorContains(t, err, "unsupported RDB value type 21")
+}
+
+func TestZiplist(t *testing.T) {
+	// Entries "ab", 12 as an immediate integer, -2 on 1 byte and 1000 on 2 bytes
+	b := make([]byte, 10)
+	b = append(b, 0x00, 0x02, 'a', 'b')
+	b = append(b, 0x04, 0xF1+12)
+	b = append(b, 0x02, 0xFE, 0xFE)
+	b = append(b, 0x03, 0xC0, 0xE8, 0x03)
+	b = append(b, 0xFF)
+
+	elems, err := ziplist(b)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ab", "12", "-2", "1000"}, elems)
+}
@@ -0,0 +1,205 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dicedb-go"
+)
+
+// scanCount is the number of keys asked to the upstream by every SCAN
+const scanCount = 1000
+
+// New returns a bridge mirroring the keys of the upstream Redis into the shards of the manager.
+func New(opts Options, manager *shard.ShardManager) *Bridge {
+	return &Bridge{
+		opts:   opts,
+		shards: &managerShards{manager: manager},
+	}
+}
+
+// Run mirrors the keys until the context is canceled. The connection to the upstream is retried as long as
+// it fails, the keys are copied again every time the bridge subscribes to the notifications.
+func (b *Bridge) Run(ctx context.Context) error {
+	if b.opts.Mode == ModeReplication {
+		return b.replicate(ctx)
+	}
+
+	client := dicedb.NewClient(&dicedb.Options{
+		Addr:     b.opts.Addr,
+		Password: b.opts.Password,
+		DB:       b.opts.DB,
+	})
+	defer client.Close()
+	b.upstream = &redisUpstream{client: client}
+
+	if events, err := client.ConfigGet(ctx, "notify-keyspace-events").Result(); err == nil &&
+		!strings.Contains(events["notify-keyspace-events"], "K") {
+		slog.Warn("the upstream does not publish keyspace notifications, the keys will not be kept up to date",
+			slog.String("addr", b.opts.Addr), slog.String("notify-keyspace-events", events["notify-keyspace-events"]))
+	}
+
+	channels := make([]string, 0, len(b.opts.Patterns))
+	patterns := make(map[string]string, len(b.opts.Patterns))
+	for _, pattern := range b.opts.Patterns {
+		channel := fmt.Sprintf("__keyspace@%d__:%s", b.opts.DB, pattern)
+		channels = append(channels, channel)
+		patterns[channel] = pattern
+	}
+
+	// The subscription comes first, so that the changes made while the keys are copied are not missed
+	pubsub := client.PSubscribe(ctx, channels...)
+	defer pubsub.Close()
+
+	messages := pubsub.ChannelWithSubscriptions()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return errors.New("the subscription to the upstream was closed")
+			}
+			b.receive(ctx, message, patterns)
+		}
+	}
+}
+
+func (b *Bridge) receive(ctx context.Context, message interface{}, patterns map[string]string) {
+	switch m := message.(type) {
+	case *dicedb.Subscription:
+		if m.Kind != "psubscribe" {
+			return
+		}
+		slog.Info("subscribed to the upstream", slog.String("addr", b.opts.Addr), slog.String("channel", m.Channel))
+		if err := b.sync(ctx, patterns[m.Channel]); err != nil && ctx.Err() == nil {
+			slog.Error("could not mirror the keys of the upstream", slog.String("channel", m.Channel), slog.Any("error", err))
+		}
+	case *dicedb.Message:
+		key, ok := keyOf(m.Channel)
+		if !ok {
+			return
+		}
+		if err := b.notified(ctx, key, m.Payload); err != nil {
+			slog.Warn("could not mirror a key", slog.String("key", key), slog.String("event", m.Payload), slog.Any("error", err))
+		}
+	}
+}
+
+// redisUpstream reads the keys from a Redis
+type redisUpstream struct {
+	client *dicedb.Client
+}
+
+func (u *redisUpstream) scan(ctx context.Context, pattern string, fn func(key string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := u.client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			if cursor == 0 && strings.HasPrefix(err.Error(), "ERR unknown command") {
+				// A DiceDB upstream lists the keys with KEYS only
+				return u.keys(ctx, pattern, fn)
+			}
+			return err
+		}
+		for _, key := range keys {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (u *redisUpstream) keys(ctx context.Context, pattern string, fn func(key string) error) error {
+	keys, err := u.client.Keys(ctx, pattern).Result()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *redisUpstream) read(ctx context.Context, key string) (*snapshot, error) {
+	typ, err := u.client.Type(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &snapshot{typ: typ}
+	var value dicedb.Cmder
+	var ttl *dicedb.DurationCmd
+	// The value and its TTL are read together, a change of the type in between fails the read of the value
+	_, err = u.client.TxPipelined(ctx, func(pipe dicedb.Pipeliner) error {
+		switch typ {
+		case typeString:
+			value = pipe.Get(ctx, key)
+		case typeList:
+			value = pipe.LRange(ctx, key, 0, -1)
+		case typeSet:
+			value = pipe.SMembers(ctx, key)
+		case typeHash:
+			value = pipe.HGetAll(ctx, key)
+		case typeZSet:
+			value = pipe.ZRangeWithScores(ctx, key, 0, -1)
+		}
+		ttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if errors.Is(err, dicedb.Nil) {
+		// The key was removed since its type was read
+		return &snapshot{typ: typeNone}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case *dicedb.StringCmd:
+		s.str = v.Val()
+	case *dicedb.StringSliceCmd:
+		s.list = v.Val()
+	case *dicedb.MapStringStringCmd:
+		s.hash = v.Val()
+	case *dicedb.ZSliceCmd:
+		for _, z := range v.Val() {
+			s.members = append(s.members, member{name: fmt.Sprint(z.Member), score: z.Score})
+		}
+	}
+	// PTTL replies -1 for a key without expiry and -2 for a missing key
+	switch d := ttl.Val(); {
+	case d > 0:
+		s.ttl = d
+	case d == -2:
+		// The key expired since its type was read
+		s.typ = typeNone
+	}
+	return s, nil
+}
@@ -0,0 +1,614 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/gobwas/glob"
+)
+
+const (
+	// ackInterval is the interval at which the offset of the replication stream applied is acknowledged
+	ackInterval = time.Second
+	// loadBatchSize is the number of commands applied at once while loading the RDB payload
+	loadBatchSize = 1000
+	// eofMarkSize is the size of the mark ending an RDB payload sent without its length
+	eofMarkSize = 40
+
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// splitCommands are the commands writing several keys that are split into a command per key, with the number
+// of arguments of every key. MSETNX is replicated only when applied, and is applied as MSET.
+var splitCommands = map[string]struct {
+	cmd  string
+	step int
+}{
+	"DEL":    {cmd: "DEL", step: 1},
+	"UNLINK": {cmd: "UNLINK", step: 1},
+	"MSET":   {cmd: "SET", step: 2},
+	"MSETNX": {cmd: "SET", step: 2},
+}
+
+// replication is the position in the replication stream of the upstream. It is kept across the connections,
+// so that a reconnection resumes the stream with a partial resynchronization when the upstream still has it.
+type replication struct {
+	replID   string
+	upstream string // upstream is the address of the upstream followed
+	// db is the database selected by the stream, every write being preceded by SELECT once the database changed
+	db int
+	// offset is the offset in the stream of the last byte applied
+	offset atomic.Int64
+	// lostAt is the time the link with the upstream was lost, in Unix nanoseconds, 0 while the stream is followed
+	lostAt atomic.Int64
+
+	mu sync.Mutex
+	// advanced is closed once the offset advances, nil while no read waits for it
+	advanced chan struct{}
+}
+
+// following is the replication of the bridge following its upstream, nil unless a bridge runs in replication
+// mode
+var following atomic.Pointer[replication]
+
+// advance moves the offset applied to the offset given, waking up the reads waiting for it
+func (s *replication) advance(offset int64) {
+	s.offset.Store(offset)
+	s.mu.Lock()
+	if s.advanced != nil {
+		close(s.advanced)
+		s.advanced = nil
+	}
+	s.mu.Unlock()
+}
+
+// Offset returns the offset of the replication stream of the upstream applied and the address of the upstream,
+// false unless a bridge follows an upstream as one of its replicas. The offset is the one the upstream reports
+// as master_repl_offset once it replied to the writes applied.
+func Offset() (offset int64, upstream string, ok bool) {
+	state := following.Load()
+	if state == nil {
+		return 0, "", false
+	}
+	return state.offset.Load(), state.upstream, true
+}
+
+// Lag returns how far behind the upstream the keys may be and the address of the upstream, false unless a bridge
+// follows an upstream as one of its replicas. The stream is applied as it is received, so that the lag is 0 while
+// the link with the upstream is up, and the time elapsed since the link was lost otherwise, e.g. while the bridge
+// reconnects or loads the payload of a full resynchronization.
+func Lag() (lag time.Duration, upstream string, ok bool) {
+	state := following.Load()
+	if state == nil {
+		return 0, "", false
+	}
+	if lostAt := state.lostAt.Load(); lostAt != 0 {
+		lag = time.Since(time.Unix(0, lostAt))
+	}
+	return lag, state.upstream, true
+}
+
+// WaitOffset waits for the replication stream of the upstream to be applied up to the offset, and reports
+// whether it was before the context is done. It returns true at once unless a bridge follows an upstream.
+func WaitOffset(ctx context.Context, offset int64) bool {
+	state := following.Load()
+	if state == nil {
+		return true
+	}
+	for {
+		state.mu.Lock()
+		if state.offset.Load() >= offset {
+			state.mu.Unlock()
+			return true
+		}
+		if state.advanced == nil {
+			state.advanced = make(chan struct{})
+		}
+		advanced := state.advanced
+		state.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-advanced:
+		}
+	}
+}
+
+// replicate follows the upstream as one of its replicas: the dataset is loaded from the RDB payload of a full
+// resynchronization, then the writes of the upstream are applied from its replication stream.
+func (b *Bridge) replicate(ctx context.Context) error {
+	globs := make([]glob.Glob, 0, len(b.opts.Patterns))
+	for _, pattern := range b.opts.Patterns {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		globs = append(globs, g)
+	}
+
+	state := &replication{replID: "?", upstream: b.opts.Addr}
+	// The keys lag behind the upstream until the first synchronization
+	state.lostAt.Store(time.Now().UnixNano())
+	following.Store(state)
+	defer following.CompareAndSwap(state, nil)
+	backoff := minReconnectBackoff
+	for {
+		synced, err := b.follow(ctx, state, globs)
+		state.lostAt.CompareAndSwap(0, time.Now().UnixNano())
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if synced {
+			backoff = minReconnectBackoff
+		}
+		slog.Warn("replication from the upstream interrupted", slog.String("addr", b.opts.Addr),
+			slog.Any("error", err), slog.Duration("retry_in", backoff))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxReconnectBackoff)
+	}
+}
+
+// follow connects to the upstream, synchronizes with it and applies its replication stream until the
+// connection fails. synced reports whether the synchronization succeeded.
+func (b *Bridge) follow(ctx context.Context, state *replication, globs []glob.Glob) (synced bool, err error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", b.opts.Addr)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	l := &link{conn: conn, r: bufio.NewReader(conn)}
+	if err := l.handshake(b.opts); err != nil {
+		return false, err
+	}
+
+	// PSYNC asks for the stream from the byte following the last one applied, or for a full resynchronization
+	offset := "-1"
+	if state.replID != "?" {
+		offset = strconv.FormatInt(state.offset.Load()+1, 10)
+	}
+	reply, err := l.command("PSYNC", state.replID, offset)
+	if err != nil {
+		return false, err
+	}
+	fields := strings.Fields(reply)
+	switch {
+	case len(fields) == 3 && fields[0] == "FULLRESYNC":
+		offset, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid reply to PSYNC: %q", reply)
+		}
+		start := time.Now()
+		keys, err := b.load(ctx, l, globs)
+		if err != nil {
+			return false, fmt.Errorf("could not load the RDB payload: %w", err)
+		}
+		state.replID = fields[1]
+		state.advance(offset)
+		slog.Info("synchronized with the upstream", slog.String("addr", b.opts.Addr), slog.String("replid", state.replID),
+			slog.Int64("offset", offset), slog.Int("keys", keys), slog.Duration("elapsed", time.Since(start)))
+	case len(fields) >= 1 && fields[0] == "CONTINUE":
+		if len(fields) > 1 {
+			state.replID = fields[1]
+		}
+		slog.Info("resumed the replication from the upstream", slog.String("addr", b.opts.Addr),
+			slog.String("replid", state.replID), slog.Int64("offset", state.offset.Load()))
+	default:
+		return false, fmt.Errorf("unexpected reply to PSYNC: %q", reply)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(ackInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := l.ack(state.offset.Load()); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	state.lostAt.Store(0)
+	r := &replayer{bridge: b, link: l, state: state, globs: globs, warned: make(map[string]bool)}
+	return true, r.run(ctx)
+}
+
+// load replaces the local keys with the ones of the RDB payload sent by the upstream and returns the number of
+// keys loaded. The payload is sent either with its length, or followed by a random mark of 40 bytes.
+func (b *Bridge) load(ctx context.Context, l *link, globs []glob.Glob) (int, error) {
+	var header string
+	var err error
+	// Newlines are sent as keepalives while the upstream prepares the payload
+	for header == "" {
+		if header, err = l.readLine(); err != nil {
+			return 0, err
+		}
+	}
+	if header[0] != '$' {
+		return 0, fmt.Errorf("unexpected RDB payload header %q", header)
+	}
+
+	if err := b.flush(ctx); err != nil {
+		return 0, err
+	}
+
+	batch := make(map[uint8][]*cmd.DiceDBCmd)
+	pending, keys := 0, 0
+	applyBatch := func() error {
+		if pending == 0 {
+			return nil
+		}
+		err := b.execAll(ctx, batch)
+		batch, pending = make(map[uint8][]*cmd.DiceDBCmd), 0
+		return err
+	}
+	onKey := func(e *rdbEntry) error {
+		if e.db != b.opts.DB || !matches(globs, e.key) {
+			return nil
+		}
+		if e.expireAt > 0 {
+			if e.value.ttl = time.Until(time.UnixMilli(e.expireAt)); e.value.ttl <= 0 {
+				return nil
+			}
+		}
+
+		cmds := commands(e.key, e.value)
+		id := b.shards.Route(e.key)
+		batch[id] = append(batch[id], cmds...)
+		pending += len(cmds)
+		keys++
+		if pending >= loadBatchSize {
+			return applyBatch()
+		}
+		return nil
+	}
+
+	if mark, ok := strings.CutPrefix(header, "$EOF:"); ok {
+		if len(mark) != eofMarkSize {
+			return 0, fmt.Errorf("invalid RDB payload mark %q", mark)
+		}
+		if err := parseRDB(l.r, onKey); err != nil {
+			return 0, err
+		}
+		end := make([]byte, eofMarkSize)
+		if _, err := io.ReadFull(l.r, end); err != nil {
+			return 0, err
+		}
+		if string(end) != mark {
+			return 0, errors.New("the RDB payload is not followed by its mark")
+		}
+	} else {
+		size, err := strconv.ParseInt(header[1:], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected RDB payload header %q", header)
+		}
+		payload := bufio.NewReader(io.LimitReader(l.r, size))
+		if err := parseRDB(payload, onKey); err != nil {
+			return 0, err
+		}
+		// Anything following the end of the file is part of the payload
+		if _, err := io.Copy(io.Discard, payload); err != nil {
+			return 0, err
+		}
+	}
+	return keys, applyBatch()
+}
+
+// flush deletes the keys of every shard
+func (b *Bridge) flush(ctx context.Context) error {
+	cmds := make(map[uint8][]*cmd.DiceDBCmd)
+	for _, id := range b.shards.All() {
+		cmds[id] = []*cmd.DiceDBCmd{{Cmd: "FLUSHDB"}}
+	}
+	return b.execAll(ctx, cmds)
+}
+
+// execAll executes the commands and fails on the first one failing
+func (b *Bridge) execAll(ctx context.Context, cmds map[uint8][]*cmd.DiceDBCmd) error {
+	resps, err := b.shards.Exec(ctx, cmds)
+	if err != nil {
+		return err
+	}
+	for id, shardResps := range resps {
+		for i, resp := range shardResps {
+			if resp.Error != nil {
+				return fmt.Errorf("could not apply %s: %w", cmds[id][i].Cmd, resp.Error)
+			}
+		}
+	}
+	return nil
+}
+
+func matches(globs []glob.Glob, key string) bool {
+	for _, g := range globs {
+		if g.Match(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// replayer applies the replication stream of the upstream
+type replayer struct {
+	bridge *Bridge
+	link   *link
+	state  *replication
+	globs  []glob.Glob
+	// warned holds the commands already reported as not applied, so that they are reported once
+	warned map[string]bool
+}
+
+func (r *replayer) run(ctx context.Context) error {
+	for {
+		start := r.link.consumed
+		args, err := r.link.readCommand()
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 {
+			if err := r.replay(ctx, args); err != nil {
+				return err
+			}
+		}
+		r.state.advance(r.state.offset.Load() + r.link.consumed - start)
+	}
+}
+
+// replay applies a command of the stream. The commands that can't be applied are reported and skipped, an
+// error means the stream can't be followed anymore.
+func (r *replayer) replay(ctx context.Context, args []string) error {
+	name := strings.ToUpper(args[0])
+	args = args[1:]
+
+	switch name {
+	case "PING", "MULTI", "EXEC":
+		return nil
+	case "SELECT":
+		if len(args) == 1 {
+			r.state.db, _ = strconv.Atoi(args[0])
+		}
+		return nil
+	case "REPLCONF":
+		// The acknowledgment asked for does not include the request itself
+		if len(args) > 0 && strings.EqualFold(args[0], "GETACK") {
+			return r.link.ack(r.state.offset.Load())
+		}
+		return nil
+	case "FLUSHALL":
+		return r.bridge.flush(ctx)
+	}
+
+	if r.state.db != r.bridge.opts.DB {
+		return nil
+	}
+	if name == "FLUSHDB" {
+		return r.bridge.flush(ctx)
+	}
+
+	cmds, err := r.route(name, args)
+	if err != nil {
+		r.warn(name, err)
+		return nil
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	r.bridge.mu.Lock()
+	defer r.bridge.mu.Unlock()
+	resps, err := r.bridge.shards.Exec(ctx, cmds)
+	if err != nil {
+		return err
+	}
+	for _, shardResps := range resps {
+		for _, resp := range shardResps {
+			if resp.Error != nil {
+				r.warn(name, resp.Error)
+			} else if reply, ok := resp.Result.([]byte); ok && bytes.HasPrefix(reply, []byte("-")) {
+				// The commands unknown to the shards are replied to with an encoded error
+				r.warn(name, errors.New(strings.TrimSpace(string(reply[1:]))))
+			}
+		}
+	}
+	return nil
+}
+
+// route returns the commands applying the command to the shards owning the keys matching the patterns
+func (r *replayer) route(name string, args []string) (map[uint8][]*cmd.DiceDBCmd, error) {
+	if split, ok := splitCommands[name]; ok {
+		cmds := make(map[uint8][]*cmd.DiceDBCmd)
+		for i := 0; i+split.step <= len(args); i += split.step {
+			if !matches(r.globs, args[i]) {
+				continue
+			}
+			id := r.bridge.shards.Route(args[i])
+			cmds[id] = append(cmds[id], &cmd.DiceDBCmd{Cmd: split.cmd, Args: args[i : i+split.step]})
+		}
+		return cmds, nil
+	}
+
+	keys := cmd.Keys(&cmd.DiceDBCmd{Cmd: name, Args: args})
+	if len(keys) == 0 {
+		return nil, errors.New("the command is unknown or has no keys")
+	}
+	if !matches(r.globs, keys[0]) {
+		return nil, nil
+	}
+	id := r.bridge.shards.Route(keys[0])
+	for _, key := range keys[1:] {
+		if r.bridge.shards.Route(key) != id {
+			return nil, errors.New("the keys of the command are owned by several shards")
+		}
+	}
+	return map[uint8][]*cmd.DiceDBCmd{id: {{Cmd: name, Args: args}}}, nil
+}
+
+func (r *replayer) warn(name string, err error) {
+	if r.warned[name] {
+		return
+	}
+	r.warned[name] = true
+	slog.Warn("a command replicated from the upstream could not be applied, the later ones will not be reported",
+		slog.String("cmd", name), slog.Any("error", err))
+}
+
+// link is the connection to the upstream
+type link struct {
+	conn net.Conn
+	r    *bufio.Reader
+	// consumed is the number of bytes read from the replication stream
+	consumed int64
+	// mu serializes the writes, the acknowledgments being sent periodically and on request
+	mu sync.Mutex
+}
+
+// handshake authenticates and announces the capabilities of the replica
+func (l *link) handshake(opts Options) error {
+	if opts.Password != "" {
+		if _, err := l.command("AUTH", opts.Password); err != nil {
+			return fmt.Errorf("could not authenticate: %w", err)
+		}
+	}
+	if _, err := l.command("PING"); err != nil {
+		return err
+	}
+	if opts.ListeningPort > 0 {
+		if _, err := l.command("REPLCONF", "listening-port", strconv.Itoa(opts.ListeningPort)); err != nil {
+			return err
+		}
+	}
+	// Redis replies to PSYNC with a diskless payload, followed by a mark, only to the replicas announcing eof
+	_, err := l.command("REPLCONF", "capa", "eof", "capa", "psync2")
+	return err
+}
+
+// command sends the command and returns its simple string reply
+func (l *link) command(args ...string) (string, error) {
+	if err := l.write(args...); err != nil {
+		return "", err
+	}
+	reply, err := l.readLine()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case strings.HasPrefix(reply, "-"):
+		return "", fmt.Errorf("%s replied %s", args[0], reply[1:])
+	case strings.HasPrefix(reply, "+"):
+		return reply[1:], nil
+	default:
+		return "", fmt.Errorf("unexpected reply to %s: %q", args[0], reply)
+	}
+}
+
+// ack acknowledges the offset of the stream applied
+func (l *link) ack(offset int64) error {
+	return l.write("REPLCONF", "ACK", strconv.FormatInt(offset, 10))
+}
+
+func (l *link) write(args ...string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := io.WriteString(l.conn, sb.String())
+	return err
+}
+
+// readLine reads a line, without its terminating CRLF
+func (l *link) readLine() (string, error) {
+	line, err := l.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	l.consumed += int64(len(line))
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readCommand reads a command of the replication stream, an array of bulk strings or an inline command
+func (l *link) readCommand() ([]string, error) {
+	line, err := l.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid command header %q", line)
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		header, err := l.readLine()
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(header, "$"))
+		if err != nil || !strings.HasPrefix(header, "$") || size < 0 {
+			return nil, fmt.Errorf("invalid argument header %q", header)
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(l.r, arg); err != nil {
+			return nil, err
+		}
+		l.consumed += int64(size + 2)
+		args = append(args, string(arg[:size]))
+	}
+	return args, nil
+}
@@ -0,0 +1,213 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReplID = "8371b4fb1155b71f4a04d3e1bc3e18c4a990aeeb"
+
+func encodeCommands(cmds ...[]string) string {
+	var sb strings.Builder
+	for _, args := range cmds {
+		fmt.Fprintf(&sb, "*%d\r\n", len(args))
+		for _, arg := range args {
+			fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	return sb.String()
+}
+
+// expect reads the next command sent by the replica and replies to it
+func expect(t *testing.T, upstream *link, reply string, want ...string) {
+	t.Helper()
+	args, err := upstream.readCommand()
+	require.NoError(t, err)
+	require.Equal(t, want, args)
+	_, err = upstream.conn.Write([]byte(reply))
+	require.NoError(t, err)
+}
+
+// expectAck reads the acknowledgments of the replica until the offset is acknowledged
+func expectAck(t *testing.T, upstream *link, offset int) {
+	t.Helper()
+	require.NoError(t, upstream.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		args, err := upstream.readCommand()
+		require.NoError(t, err)
+		require.Len(t, args, 3)
+		require.Equal(t, []string{"REPLCONF", "ACK"}, args[:2])
+		if args[2] == strconv.Itoa(offset) {
+			return
+		}
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+
+	manager := shard.NewShardManager(2, nil, make(chan error, 1))
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		manager.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	b := New(Options{
+		Addr:          ln.Addr().String(),
+		Password:      "secret",
+		Patterns:      []string{"*"},
+		Mode:          ModeReplication,
+		ListeningPort: 7379,
+	}, manager)
+
+	local := func(name string, args ...string) interface{} {
+		s := b.shards.(*managerShards)
+		id := s.Route(args[0])
+		resps, err := s.Exec(ctx, map[uint8][]*cmd.DiceDBCmd{id: {{Cmd: name, Args: args}}})
+		require.NoError(t, err)
+		require.NoError(t, resps[id][0].Error)
+		return resps[id][0].Result
+	}
+	local("SET", "stale", "v")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = b.Run(ctx)
+	}()
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	upstream := &link{conn: conn, r: bufio.NewReader(conn)}
+	expect(t, upstream, "+OK\r\n", "AUTH", "secret")
+	expect(t, upstream, "+PONG\r\n", "PING")
+	expect(t, upstream, "+OK\r\n", "REPLCONF", "listening-port", "7379")
+	expect(t, upstream, "+OK\r\n", "REPLCONF", "capa", "eof", "capa", "psync2")
+
+	// A full resynchronization with a payload sent without its length
+	w := newRDBWriter()
+	w.key(rdbTypeString, "loaded")
+	w.str("rdb")
+	w.WriteByte(rdbOpExpireTimeMS)
+	w.Write([]byte{1, 0, 0, 0, 0, 0, 0, 0})
+	w.key(rdbTypeString, "expired")
+	w.str("v")
+	mark := strings.Repeat("m", eofMarkSize)
+	expect(t, upstream, "+FULLRESYNC "+testReplID+" 100\r\n\n$EOF:"+mark+"\r\n"+string(w.end())+mark, "PSYNC", "?", "-1")
+
+	stream := encodeCommands(
+		[]string{"SELECT", "0"},
+		[]string{"SET", "a", "1"},
+		[]string{"MULTI"},
+		[]string{"MSET", "b", "v2", "c", "v3"},
+		[]string{"EXEC"},
+		[]string{"DEL", "a", "loaded"},
+		[]string{"SELECT", "1"},
+		[]string{"SET", "ignored", "x"},
+		[]string{"SELECT", "0"},
+		// Commands that can't be applied are skipped
+		[]string{"XADD", "s", "*", "f", "v"},
+		[]string{"PING"},
+	)
+	getAck := encodeCommands([]string{"REPLCONF", "GETACK", "*"})
+	_, err = conn.Write([]byte(stream + getAck))
+	require.NoError(t, err)
+	expectAck(t, upstream, 100+len(stream))
+
+	assert.EqualValues(t, 0, local("EXISTS", "stale"))
+	assert.EqualValues(t, 0, local("EXISTS", "expired"))
+	assert.EqualValues(t, 0, local("EXISTS", "a"))
+	assert.EqualValues(t, 0, local("EXISTS", "loaded"))
+	assert.EqualValues(t, 0, local("EXISTS", "ignored"))
+	assert.Equal(t, "v2", local("GET", "b"))
+	assert.Equal(t, "v3", local("GET", "c"))
+
+	lag, _, ok := Lag()
+	assert.True(t, ok)
+	assert.Zero(t, lag)
+
+	// The replication is resumed after a disconnection, the keys lagging behind the upstream in between
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		lag, _, _ := Lag()
+		return lag > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	conn, err = ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	upstream = &link{conn: conn, r: bufio.NewReader(conn)}
+	expect(t, upstream, "+OK\r\n", "AUTH", "secret")
+	expect(t, upstream, "+PONG\r\n", "PING")
+	expect(t, upstream, "+OK\r\n", "REPLCONF", "listening-port", "7379")
+	expect(t, upstream, "+OK\r\n", "REPLCONF", "capa", "eof", "capa", "psync2")
+	offset := 100 + len(stream) + len(getAck)
+	expect(t, upstream, "+CONTINUE\r\n", "PSYNC", testReplID, strconv.Itoa(offset+1))
+
+	applied, addr, ok := Offset()
+	assert.True(t, ok)
+	assert.Equal(t, ln.Addr().String(), addr)
+	assert.EqualValues(t, offset, applied)
+	// The keys do not lag behind the upstream once the stream is followed again
+	assert.Eventually(t, func() bool {
+		lag, _, _ := Lag()
+		return lag == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A read requiring an offset waits for the stream to be applied up to it
+	stream = encodeCommands([]string{"SET", "d", "v"})
+	waitCtx, cancelWait := context.WithTimeout(ctx, 10*time.Millisecond)
+	assert.False(t, WaitOffset(waitCtx, int64(offset+len(stream))))
+	cancelWait()
+	waited := make(chan bool)
+	go func() {
+		waitCtx, cancelWait := context.WithTimeout(ctx, 5*time.Second)
+		defer cancelWait()
+		waited <- WaitOffset(waitCtx, int64(offset+len(stream)))
+	}()
+
+	_, err = conn.Write([]byte(stream + getAck))
+	require.NoError(t, err)
+	expectAck(t, upstream, offset+len(stream))
+	assert.True(t, <-waited)
+	assert.Equal(t, "v", local("GET", "d"))
+}
@@ -0,0 +1,54 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dicedb-go"
+)
+
+// Warm copies the keys of the source matching the patterns once, so that a node starting behind a load balancer
+// does not serve from an empty store. Unlike Run, the copies are not kept up to date. The source is a Redis or a
+// DiceDB, the keys of the types not mirrored by the bridge are skipped.
+func Warm(ctx context.Context, opts Options, manager *shard.ShardManager) error {
+	client := dicedb.NewClient(&dicedb.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	})
+	defer client.Close()
+
+	b := New(opts, manager)
+	b.upstream = &redisUpstream{client: client}
+	return b.warm(ctx)
+}
+
+// warm copies the keys matching every pattern
+func (b *Bridge) warm(ctx context.Context) error {
+	start := time.Now()
+	for _, pattern := range b.opts.Patterns {
+		if err := b.sync(ctx, pattern); err != nil {
+			return err
+		}
+	}
+	slog.Info("warmed the store up", slog.String("from", b.opts.Addr), slog.Duration("elapsed", time.Since(start)))
+	return nil
+}
@@ -0,0 +1,378 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package cache makes DiceDB a read-through and write-through cache in front of an origin, e.g. a service
+// backed by a database: a GET of a key matching the patterns and missing from the store loads the key from
+// the origin before GET runs, and the writes of the keys matching the patterns are propagated to the origin
+// in the background. The keys loaded are written like any other, so the commands watching them are notified.
+//
+// Only the string values are cached. The writes are coalesced by key: the origin receives the value of the
+// key at the time the write is propagated, not every intermediate value, and a write that fails is retried
+// until it succeeds or the key is written again. The expiries and the evictions are not propagated, the
+// origin keeps the keys the cache dropped.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/stats"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/gobwas/glob"
+)
+
+const (
+	// defaultTimeout bounds every call to the origin when the options set no timeout
+	defaultTimeout = 2 * time.Second
+	// retryBackoff is the wait before the writes that failed are propagated again
+	retryBackoff = time.Second
+)
+
+// ErrNotFound is returned by a Loader when the origin does not have the key either.
+var ErrNotFound = errors.New("key not found at the origin")
+
+// Entry is a key loaded from the origin.
+type Entry struct {
+	Value string
+	// TTL is the time to live of the key once loaded, 0 keeps the key without expiry
+	TTL time.Duration
+}
+
+// Loader loads the keys missing from the store from the origin. It is called concurrently for distinct keys.
+type Loader interface {
+	// Load returns the key as stored by the origin, ErrNotFound when the origin does not have it
+	Load(ctx context.Context, key string) (Entry, error)
+}
+
+// LoaderFunc adapts a function to a Loader.
+type LoaderFunc func(ctx context.Context, key string) (Entry, error)
+
+func (f LoaderFunc) Load(ctx context.Context, key string) (Entry, error) {
+	return f(ctx, key)
+}
+
+// Writer propagates the writes of the keys to the origin. It is called by a single goroutine.
+type Writer interface {
+	// Write stores the value of the key, written by a command
+	Write(ctx context.Context, key, value string) error
+	// Delete removes the key, deleted by a command
+	Delete(ctx context.Context, key string) error
+}
+
+// Options describes the keys cached and the origin.
+type Options struct {
+	// Patterns are the glob-style patterns of the keys cached
+	Patterns []string
+	// Loader loads the misses of GET, nil for no read-through
+	Loader Loader
+	// Writer propagates the writes, nil for no write-through
+	Writer Writer
+	// Timeout bounds every call to the origin, 2 seconds when 0
+	Timeout time.Duration
+}
+
+// executor executes the commands of every shard and returns their responses, in order
+type executor func(ctx context.Context, cmds map[uint8][]*cmd.DiceDBCmd) (map[uint8][]*eval.EvalResponse, error)
+
+// active is the manager serving the misses of GET, nil when no key is cached
+var active atomic.Pointer[Manager]
+
+// Manager loads the misses of the keys cached and propagates their writes.
+type Manager struct {
+	opts  Options
+	globs []glob.Glob
+	exec  executor
+	route func(key string) uint8
+
+	loadsMu sync.Mutex
+	loads   map[string]chan struct{} // loads are the keys being loaded, closed once the key is loaded
+
+	dirtyMu sync.Mutex
+	dirty   map[string]bool // dirty are the keys whose write is to be propagated, true when the key was deleted
+	wake    chan struct{}
+}
+
+// New returns a manager of the keys of the shard manager matching the patterns, serving the misses of GET from
+// then on. It must be called before the shard manager runs, as the manager subscribes to the writes of the keys.
+func New(manager *shard.ShardManager, opts Options) (*Manager, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	m := &Manager{
+		opts: opts,
+		exec: func(ctx context.Context, cmds map[uint8][]*cmd.DiceDBCmd) (map[uint8][]*eval.EvalResponse, error) {
+			return manager.ExecBatch(ctx, &shard.Batch{ClientAddr: "cache", Cmds: cmds})
+		},
+		route: func(key string) uint8 {
+			id, _ := manager.GetShardInfo(key)
+			return id
+		},
+		loads: make(map[string]chan struct{}),
+		dirty: make(map[string]bool),
+		wake:  make(chan struct{}, 1),
+	}
+	for _, pattern := range opts.Patterns {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		m.globs = append(m.globs, g)
+	}
+
+	if opts.Writer != nil {
+		manager.SubscribeKeyEvents(m)
+	}
+	active.Store(m)
+	return m, nil
+}
+
+// Run propagates the writes until the context is canceled, then stops serving the misses of GET. The writes
+// pending then are propagated before Run returns, the shards must still be running.
+func (m *Manager) Run(ctx context.Context) error {
+	defer active.CompareAndSwap(m, nil)
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.drain()
+			return nil
+		case <-m.wake:
+		}
+
+		if m.flush(ctx) == 0 {
+			continue
+		}
+		// The writes that failed are marked dirty again, they are retried after a while
+		select {
+		case <-ctx.Done():
+			m.drain()
+			return nil
+		case <-time.After(retryBackoff):
+		}
+	}
+}
+
+// drain propagates the writes pending once the manager is stopped, once
+func (m *Manager) drain() {
+	if m.opts.Writer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.opts.Timeout)
+	defer cancel()
+	if failed := m.flush(ctx); failed > 0 {
+		slog.Warn("could not propagate the writes of some keys to the origin", slog.Int("keys", failed))
+	}
+}
+
+// ReadThrough loads the key from the origin if it is cached and missing from the store, so that the GET run
+// next finds it. A key the origin does not have, or that could not be loaded, stays missing.
+func ReadThrough(ctx context.Context, key string) {
+	m := active.Load()
+	if m == nil || m.opts.Loader == nil || !m.matches(key) {
+		return
+	}
+	m.readThrough(ctx, key)
+}
+
+func (m *Manager) matches(key string) bool {
+	for _, g := range m.globs {
+		if g.Match(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// readThrough loads the key unless it exists. The concurrent misses of a key wait for a single load.
+func (m *Manager) readThrough(ctx context.Context, key string) {
+	m.loadsMu.Lock()
+	if loaded, ok := m.loads[key]; ok {
+		m.loadsMu.Unlock()
+		select {
+		case <-ctx.Done():
+		case <-loaded:
+		}
+		return
+	}
+	loaded := make(chan struct{})
+	m.loads[key] = loaded
+	m.loadsMu.Unlock()
+
+	defer func() {
+		m.loadsMu.Lock()
+		delete(m.loads, key)
+		m.loadsMu.Unlock()
+		close(loaded)
+	}()
+
+	// The load is not given up when the client waiting for it disconnects, the other misses wait for it too
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.opts.Timeout)
+	defer cancel()
+
+	id := m.route(key)
+	resps, err := m.exec(ctx, map[uint8][]*cmd.DiceDBCmd{id: {{Cmd: "EXISTS", Args: []string{key}}}})
+	if err != nil || resps[id][0].Result != int64(0) {
+		return
+	}
+
+	entry, err := m.opts.Loader.Load(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return
+	}
+	stats.CacheLoaded(err != nil)
+	if err != nil {
+		slog.Warn("could not load a key from the origin", slog.String("key", key), slog.Any("error", err))
+		return
+	}
+
+	// The key is only stored if no command wrote it during the load
+	args := []string{key, entry.Value, strconv.FormatInt(entry.TTL.Milliseconds(), 10)}
+	resps, err = m.exec(ctx, map[uint8][]*cmd.DiceDBCmd{id: {{Cmd: dstore.CacheLoad, Args: args}}})
+	if err == nil {
+		err = resps[id][0].Error
+	}
+	if err != nil {
+		slog.Warn("could not store a key loaded from the origin", slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+// OnKeyEvent marks the keys written by the commands for their write to be propagated. It is called by the
+// goroutines of the shards.
+func (m *Manager) OnKeyEvent(e dstore.KeyEvent) {
+	if m.propagates(e) {
+		m.mark(e.Key, e.Type == dstore.KeyEventDel, true)
+	}
+}
+
+// OnKeyEvents marks the keys written by a batch, waking up the propagation once.
+func (m *Manager) OnKeyEvents(events []dstore.KeyEvent) {
+	marked := false
+	m.dirtyMu.Lock()
+	for _, e := range events {
+		if m.propagates(e) {
+			m.dirty[e.Key] = e.Type == dstore.KeyEventDel
+			marked = true
+		}
+	}
+	m.dirtyMu.Unlock()
+
+	if marked {
+		select {
+		case m.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// propagates reports whether the change of the key is propagated to the origin, the keys loaded from it, expired
+// or evicted being left as they are at the origin
+func (m *Manager) propagates(e dstore.KeyEvent) bool {
+	switch e.Cmd {
+	case dstore.CacheLoad, dstore.Expired, dstore.Evict:
+		return false
+	}
+	return m.matches(e.Key)
+}
+
+// mark marks the key dirty. A retry does not override a change of the key marked since the write failed.
+func (m *Manager) mark(key string, deleted, override bool) {
+	m.dirtyMu.Lock()
+	if _, ok := m.dirty[key]; override || !ok {
+		m.dirty[key] = deleted
+	}
+	m.dirtyMu.Unlock()
+
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// flush propagates the writes of the keys marked dirty and returns the number of writes that failed
+func (m *Manager) flush(ctx context.Context) int {
+	m.dirtyMu.Lock()
+	dirty := m.dirty
+	m.dirty = make(map[string]bool)
+	m.dirtyMu.Unlock()
+	if len(dirty) == 0 {
+		return 0
+	}
+
+	// The values of the keys written are read in one batch per shard
+	cmds := make(map[uint8][]*cmd.DiceDBCmd)
+	keys := make(map[uint8][]string)
+	for key, deleted := range dirty {
+		if !deleted {
+			id := m.route(key)
+			cmds[id] = append(cmds[id], &cmd.DiceDBCmd{Cmd: "GET", Args: []string{key}})
+			keys[id] = append(keys[id], key)
+		}
+	}
+	values := make(map[string]string, len(dirty))
+	if len(cmds) > 0 {
+		resps, err := m.exec(ctx, cmds)
+		if err != nil {
+			for key, deleted := range dirty {
+				m.mark(key, deleted, false)
+			}
+			return len(dirty)
+		}
+		for id, shardResps := range resps {
+			for i, resp := range shardResps {
+				key := keys[id][i]
+				switch v := resp.Result.(type) {
+				case string:
+					values[key] = v
+				case int64:
+					values[key] = strconv.FormatInt(v, 10)
+				default:
+					// The key expired, was evicted or does not hold a string
+					delete(dirty, key)
+				}
+			}
+		}
+	}
+
+	failed := 0
+	for key, deleted := range dirty {
+		callCtx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+		var err error
+		if deleted {
+			err = m.opts.Writer.Delete(callCtx, key)
+		} else {
+			err = m.opts.Writer.Write(callCtx, key, values[key])
+		}
+		cancel()
+
+		stats.CacheWritten(err != nil)
+		if err != nil {
+			slog.Warn("could not propagate a write to the origin", slog.String("key", key), slog.Any("error", err))
+			m.mark(key, deleted, false)
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,245 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWriter records the writes propagated, failing the ones of the keys in fail
+type fakeWriter struct {
+	mu     sync.Mutex
+	writes map[string]string
+	fail   map[string]bool
+}
+
+func (w *fakeWriter) Write(_ context.Context, key, value string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.fail[key] {
+		return errors.New("unavailable")
+	}
+	w.writes[key] = value
+	return nil
+}
+
+func (w *fakeWriter) Delete(_ context.Context, key string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes[key] = "<deleted>"
+	return nil
+}
+
+func (w *fakeWriter) get(key string) (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	value, ok := w.writes[key]
+	return value, ok
+}
+
+func startShards(t *testing.T) (*shard.ShardManager, context.Context, func(func())) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+
+	manager := shard.NewShardManager(2, nil, make(chan error, 1))
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	run := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return manager, ctx, run
+}
+
+func execute(ctx context.Context, t *testing.T, m *Manager, command string, args ...string) interface{} {
+	id := m.route(args[0])
+	resps, err := m.exec(ctx, map[uint8][]*cmd.DiceDBCmd{id: {{Cmd: command, Args: args}}})
+	require.NoError(t, err)
+	require.NoError(t, resps[id][0].Error)
+	return resps[id][0].Result
+}
+
+func TestReadThrough(t *testing.T) {
+	manager, ctx, run := startShards(t)
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	m, err := New(manager, Options{
+		Patterns: []string{"user:*"},
+		Loader: LoaderFunc(func(_ context.Context, key string) (Entry, error) {
+			loads.Add(1)
+			<-release
+			if key == "user:1" {
+				return Entry{Value: "alice", TTL: time.Minute}, nil
+			}
+			return Entry{}, ErrNotFound
+		}),
+	})
+	require.NoError(t, err)
+	run(func() { manager.Run(ctx) })
+
+	// The concurrent misses of a key share a single load
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.readThrough(ctx, "user:1")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.EqualValues(t, 1, loads.Load())
+	assert.Equal(t, "alice", execute(ctx, t, m, "GET", "user:1"))
+	assert.Positive(t, execute(ctx, t, m, "PTTL", "user:1"))
+
+	// A key found in the store is not loaded again
+	m.readThrough(ctx, "user:1")
+	assert.EqualValues(t, 1, loads.Load())
+
+	// A key the origin does not have stays missing
+	m.readThrough(ctx, "user:2")
+	assert.Equal(t, clientio.NIL, execute(ctx, t, m, "GET", "user:2"))
+
+	assert.True(t, m.matches("user:3"))
+	assert.False(t, m.matches("session:1"))
+}
+
+func TestWriteThrough(t *testing.T) {
+	manager, ctx, run := startShards(t)
+
+	writer := &fakeWriter{writes: make(map[string]string), fail: map[string]bool{"user:3": true}}
+	m, err := New(manager, Options{Patterns: []string{"user:*"}, Writer: writer})
+	require.NoError(t, err)
+	run(func() { manager.Run(ctx) })
+	run(func() { _ = m.Run(ctx) })
+
+	execute(ctx, t, m, "SET", "user:1", "alice")
+	execute(ctx, t, m, "SET", "user:2", "bob")
+	execute(ctx, t, m, "DEL", "user:2")
+	execute(ctx, t, m, "SET", "session:1", "s")
+	execute(ctx, t, m, "SET", "user:3", "carol")
+	execute(ctx, t, m, "CACHELOAD", "user:4", "dave", "0")
+
+	assert.Eventually(t, func() bool {
+		value, _ := writer.get("user:1")
+		deleted, _ := writer.get("user:2")
+		return value == "alice" && deleted == "<deleted>"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, ok := writer.get("session:1")
+	assert.False(t, ok, "the keys not cached are not written")
+	_, ok = writer.get("user:4")
+	assert.False(t, ok, "the keys loaded from the origin are not written back")
+
+	// A write that failed is retried
+	writer.mu.Lock()
+	delete(writer.fail, "user:3")
+	writer.mu.Unlock()
+	assert.Eventually(t, func() bool {
+		value, _ := writer.get("user:3")
+		return value == "carol"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestHTTPOrigin(t *testing.T) {
+	var mu sync.Mutex
+	stored := map[string]string{"/user:1": "alice", "/user%2F2": "bob"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path := r.URL.EscapedPath()
+		switch r.Method {
+		case http.MethodGet:
+			value, ok := stored[path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			if path == "/user:1" {
+				w.Header().Set("Cache-Control", "public, max-age=60")
+			}
+			_, _ = io.WriteString(w, value)
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			stored[path] = string(body)
+		case http.MethodDelete:
+			if _, ok := stored[path]; !ok {
+				http.NotFound(w, r)
+				return
+			}
+			delete(stored, path)
+		}
+	}))
+	defer srv.Close()
+
+	loader := NewHTTPLoader(srv.URL+"/", 5*time.Second)
+	entry, err := loader.Load(context.Background(), "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, Entry{Value: "alice", TTL: time.Minute}, entry)
+
+	entry, err = loader.Load(context.Background(), "user/2")
+	require.NoError(t, err)
+	assert.Equal(t, Entry{Value: "bob", TTL: 5 * time.Second}, entry)
+
+	_, err = loader.Load(context.Background(), "user:3")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	writer := NewHTTPWriter(srv.URL)
+	require.NoError(t, writer.Write(context.Background(), "user:3", "carol"))
+	entry, err = loader.Load(context.Background(), "user:3")
+	require.NoError(t, err)
+	assert.Equal(t, "carol", entry.Value)
+
+	require.NoError(t, writer.Delete(context.Background(), "user:3"))
+	require.NoError(t, writer.Delete(context.Background(), "user:3"), "deleting a missing key succeeds")
+	_, err = loader.Load(context.Background(), "user:3")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestParseMaxAge(t *testing.T) {
+	maxAge, ok := parseMaxAge("no-cache, Max-Age=30")
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, maxAge)
+
+	for _, header := range []string{"", "no-store", "max-age=0", "max-age=soon"} {
+		_, ok := parseMaxAge(header)
+		assert.False(t, ok, header)
+	}
+}
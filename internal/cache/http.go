@@ -0,0 +1,140 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxValueSize is the size of the largest value loaded, as the largest bulk string of RESP
+const maxValueSize = 512 * 1024 * 1024
+
+// HTTPLoader loads the keys from an HTTP origin: GET <url>/<key> replies with the value of the key as its body,
+// or 404 when the origin does not have the key. The TTL of the key is the max-age of the Cache-Control header
+// of the reply, TTL when the reply has no positive max-age.
+type HTTPLoader struct {
+	URL    string
+	TTL    time.Duration
+	Client *http.Client
+}
+
+// NewHTTPLoader returns a loader of the keys from the origin at the base URL.
+func NewHTTPLoader(baseURL string, ttl time.Duration) *HTTPLoader {
+	return &HTTPLoader{URL: baseURL, TTL: ttl, Client: &http.Client{}}
+}
+
+func (l *HTTPLoader) Load(ctx context.Context, key string) (Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keyURL(l.URL, key), http.NoBody)
+	if err != nil {
+		return Entry{}, err
+	}
+	resp, err := l.Client.Do(req)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return Entry{}, ErrNotFound
+	case resp.StatusCode/100 != 2:
+		return Entry{}, fmt.Errorf("origin replied %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxValueSize+1))
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(body) > maxValueSize {
+		return Entry{}, fmt.Errorf("value larger than %d bytes", maxValueSize)
+	}
+
+	ttl := l.TTL
+	if maxAge, ok := parseMaxAge(resp.Header.Get("Cache-Control")); ok {
+		ttl = maxAge
+	}
+	return Entry{Value: string(body), TTL: ttl}, nil
+}
+
+// HTTPWriter propagates the writes to an HTTP origin: PUT <url>/<key> with the value as its body, and
+// DELETE <url>/<key>. Any status other than 2xx is a failed write, but for a 404 to a DELETE.
+type HTTPWriter struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPWriter returns a writer of the keys to the origin at the base URL.
+func NewHTTPWriter(baseURL string) *HTTPWriter {
+	return &HTTPWriter{URL: baseURL, Client: &http.Client{}}
+}
+
+func (w *HTTPWriter) Write(ctx context.Context, key, value string) error {
+	return w.do(ctx, http.MethodPut, key, strings.NewReader(value))
+}
+
+func (w *HTTPWriter) Delete(ctx context.Context, key string) error {
+	return w.do(ctx, http.MethodDelete, key, http.NoBody)
+}
+
+func (w *HTTPWriter) do(ctx context.Context, method, key string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, method, keyURL(w.URL, key), body)
+	if err != nil {
+		return err
+	}
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 == 2 || (method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		return nil
+	}
+	return fmt.Errorf("origin replied %s", resp.Status)
+}
+
+// keyURL returns the URL of the key at the origin, the key being escaped as a single path segment
+func keyURL(baseURL, key string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + url.PathEscape(key)
+}
+
+// parseMaxAge returns the positive max-age directive of a Cache-Control header
+func parseMaxAge(header string) (time.Duration, bool) {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+		if err != nil || seconds <= 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	return 0, false
+}
@@ -38,15 +38,27 @@ func printConfiguration() {
 	slog.Info("starting DiceDB", slog.String("version", config.DiceDBVersion))
 
 	// Add the port number on which DiceDB is running
-	slog.Info("running with", slog.Int("port", config.DiceConfig.RespServer.Port))
+	slog.Info("running with", slog.Int("port", config.DiceConfig().RespServer.Port))
+
+	if config.DiceConfig().RespServer.UnixSocket != "" {
+		slog.Info("running with", slog.String("unixsocket", config.DiceConfig().RespServer.UnixSocket), slog.String("unixsocketperm", config.DiceConfig().RespServer.UnixSocketPerm))
+	}
+
+	if config.DiceConfig().TLS.Port > 0 {
+		slog.Info("running with", slog.Int("tls-port", config.DiceConfig().TLS.Port), slog.String("tls-auth-clients", config.DiceConfig().TLS.AuthClients))
+	}
+
+	if config.DiceConfig().Security.ProtectedMode {
+		slog.Info("running in protected mode, non-loopback clients need a password or a client certificate")
+	}
 
 	//	 HTTP and WebSocket server configuration
-	if config.DiceConfig.HTTP.Enabled {
-		slog.Info("running with", slog.Int("http-port", config.DiceConfig.HTTP.Port))
+	if config.DiceConfig().HTTP.Enabled {
+		slog.Info("running with", slog.Int("http-port", config.DiceConfig().HTTP.Port))
 	}
 
-	if config.DiceConfig.WebSocket.Enabled {
-		slog.Info("running with", slog.Int("websocket-port", config.DiceConfig.WebSocket.Port))
+	if config.DiceConfig().WebSocket.Enabled {
+		slog.Info("running with", slog.Int("websocket-port", config.DiceConfig().WebSocket.Port))
 	}
 
 	// Add the number of CPU cores available on the machine
@@ -54,19 +66,19 @@ func printConfiguration() {
 
 	// Conditionally add the number of shards to be used for DiceDB
 	numShards := runtime.NumCPU()
-	if config.DiceConfig.Performance.NumShards > 0 {
-		numShards = config.DiceConfig.Performance.NumShards
+	if config.DiceConfig().Performance.NumShards > 0 {
+		numShards = config.DiceConfig().Performance.NumShards
 	}
 	slog.Info("running with", slog.Int("shards", numShards))
 
 	// Add whether the watch feature is enabled
-	slog.Info("running with", slog.Bool("watch", config.DiceConfig.Performance.EnableWatch))
+	slog.Info("running with", slog.Bool("watch", config.DiceConfig().Performance.EnableWatch))
 
 	// Add whether the watch feature is enabled
-	slog.Info("running with", slog.Bool("profiling", config.DiceConfig.Performance.EnableProfiling))
+	slog.Info("running with", slog.Bool("profiling", config.DiceConfig().Performance.EnableProfiling))
 
 	// Add whether the persistence feature is enabled
-	slog.Info("running with", slog.Bool("persistence", config.DiceConfig.Persistence.Enabled))
+	slog.Info("running with", slog.Bool("persistence", config.DiceConfig().Persistence.Enabled))
 }
 
 // printConfigTable prints key-value pairs in a vertical table format.
@@ -89,25 +101,43 @@ func Execute() {
 
 	flag.IntVar(&flagsConfig.RespServer.Port, "port", 7379, "port for the DiceDB server")
 
+	flag.StringVar(&flagsConfig.RespServer.UnixSocket, "unixsocket", utils.EmptyStr, "path of the Unix domain socket to also listen on, empty disables it")
+	flag.StringVar(&flagsConfig.RespServer.UnixSocketPerm, "unixsocketperm", "0700", "octal file mode of the Unix domain socket")
+
+	flag.IntVar(&flagsConfig.TLS.Port, "tls-port", 0, "port for accepting TLS connections, 0 disables TLS")
+	flag.StringVar(&flagsConfig.TLS.CertFile, "tls-cert-file", utils.EmptyStr, "path of the PEM encoded server certificate")
+	flag.StringVar(&flagsConfig.TLS.KeyFile, "tls-key-file", utils.EmptyStr, "path of the PEM encoded server private key")
+	flag.StringVar(&flagsConfig.TLS.CAFile, "tls-ca-file", utils.EmptyStr, "path of the PEM encoded CA bundle used to verify clients")
+	flag.StringVar(&flagsConfig.TLS.AuthClients, "tls-auth-clients", "no", "client certificate verification, values: no, optional, yes")
+
+	flag.StringVar(&flagsConfig.HTTP.Addr, "http-host", "0.0.0.0", "host for accepting requests over HTTP")
 	flag.IntVar(&flagsConfig.HTTP.Port, "http-port", 8082, "port for accepting requets over HTTP")
 	flag.BoolVar(&flagsConfig.HTTP.Enabled, "enable-http", false, "enable DiceDB to listen, accept, and process HTTP")
 
+	flag.StringVar(&flagsConfig.WebSocket.Addr, "websocket-host", "0.0.0.0", "host for accepting requests over WebSocket")
 	flag.IntVar(&flagsConfig.WebSocket.Port, "websocket-port", 8379, "port for accepting requets over WebSocket")
 	flag.BoolVar(&flagsConfig.WebSocket.Enabled, "enable-websocket", false, "enable DiceDB to listen, accept, and process WebSocket")
 
+	flag.StringVar(&flagsConfig.Metrics.Addr, "metrics-host", "0.0.0.0", "host for serving the Prometheus metrics")
+	flag.IntVar(&flagsConfig.Metrics.Port, "metrics-port", 9379, "port for serving the Prometheus metrics")
+	flag.BoolVar(&flagsConfig.Metrics.Enabled, "enable-metrics", false, "enable DiceDB to serve Prometheus metrics on /metrics")
+
 	flag.IntVar(&flagsConfig.Performance.NumShards, "num-shards", -1, "number shards to create. defaults to number of cores")
 
 	flag.BoolVar(&flagsConfig.Performance.EnableWatch, "enable-watch", false, "enable support for .WATCH commands and real-time reactivity")
 	flag.BoolVar(&flagsConfig.Performance.EnableProfiling, "enable-profiling", false, "enable profiling and capture critical metrics and traces in .prof files")
 
 	flag.StringVar(&flagsConfig.Logging.LogLevel, "log-level", "info", "log level, values: info, debug")
-	flag.StringVar(&config.DiceConfig.Logging.LogDir, "log-dir", "/tmp/dicedb", "log directory path")
+	flag.StringVar(&config.DiceConfig().Logging.LogDir, "log-dir", "/tmp/dicedb", "log directory path")
 
 	flag.BoolVar(&flagsConfig.Persistence.Enabled, "enable-persistence", false, "enable write-ahead logging")
 	flag.BoolVar(&flagsConfig.Persistence.RestoreFromWAL, "restore-wal", false, "restore the database from the WAL files")
 	flag.StringVar(&flagsConfig.Persistence.WALEngine, "wal-engine", "null", "wal engine to use, values: sqlite, aof")
 
+	flag.BoolVar(&flagsConfig.Security.ProtectedMode, "protected-mode", false, "refuse non-loopback connections while no password is set")
 	flag.StringVar(&flagsConfig.Auth.Password, "requirepass", utils.EmptyStr, "enable authentication for the default user")
+	var requirePassFile string
+	flag.StringVar(&requirePassFile, "requirepass-file", utils.EmptyStr, "file to read the password of the default user from")
 	flag.StringVar(&config.CustomConfigFilePath, "o", config.CustomConfigFilePath, "dir path to create the flagsConfig file")
 	flag.StringVar(&config.CustomConfigDirPath, "c", config.CustomConfigDirPath, "file path of the config file")
 
@@ -117,6 +147,9 @@ func Execute() {
 	flag.Float64Var(&flagsConfig.Memory.EvictionRatio, "eviction-ratio", 0.9, "ratio of keys to evict when the "+
 		"keys limit is reached")
 
+	flag.StringVar(&flagsConfig.Warm.From, "warm-from", utils.EmptyStr, "host:port of a Redis or DiceDB to copy the keys from before accepting clients")
+	flag.BoolVar(&flagsConfig.Sentinel.Enabled, "sentinel", false, "run as a sentinel monitoring a leader and its replicas")
+
 	flag.Usage = func() {
 		color.Set(color.FgYellow)
 		fmt.Println("Usage: ./dicedb [options] [config-file]")
@@ -131,10 +164,22 @@ func Execute() {
 		fmt.Println("  -h, --help             Show this help message")
 		fmt.Println("  -host                  Host for the DiceDB server (default: \"0.0.0.0\")")
 		fmt.Println("  -port                  Port for the DiceDB server (default: 7379)")
+		fmt.Println("  -unixsocket            Path of the Unix domain socket to also listen on (default: \"\")")
+		fmt.Println("  -unixsocketperm        Octal file mode of the Unix domain socket (default: \"0700\")")
+		fmt.Println("  -tls-port              Port for accepting TLS connections, 0 disables TLS (default: 0)")
+		fmt.Println("  -tls-cert-file         Path of the PEM encoded server certificate (default: \"\")")
+		fmt.Println("  -tls-key-file          Path of the PEM encoded server private key (default: \"\")")
+		fmt.Println("  -tls-ca-file           Path of the PEM encoded CA bundle used to verify clients (default: \"\")")
+		fmt.Println("  -tls-auth-clients      Client certificate verification, values: no, optional, yes (default: \"no\")")
+		fmt.Println("  -http-host             Host for accepting requests over HTTP (default: \"0.0.0.0\")")
 		fmt.Println("  -http-port             Port for accepting requests over HTTP (default: 8082)")
 		fmt.Println("  -enable-http           Enable DiceDB to listen, accept, and process HTTP (default: false)")
+		fmt.Println("  -websocket-host        Host for accepting requests over WebSocket (default: \"0.0.0.0\")")
 		fmt.Println("  -websocket-port        Port for accepting requests over WebSocket (default: 8379)")
 		fmt.Println("  -enable-websocket      Enable DiceDB to listen, accept, and process WebSocket (default: false)")
+		fmt.Println("  -metrics-host          Host for serving the Prometheus metrics (default: \"0.0.0.0\")")
+		fmt.Println("  -metrics-port          Port for serving the Prometheus metrics (default: 9379)")
+		fmt.Println("  -enable-metrics        Enable DiceDB to serve Prometheus metrics on /metrics (default: false)")
 		fmt.Println("  -num-shards            Number of shards to create. Defaults to number of cores (default: -1)")
 		fmt.Println("  -enable-watch          Enable support for .WATCH commands and real-time reactivity (default: false)")
 		fmt.Println("  -enable-profiling      Enable profiling and capture critical metrics and traces in .prof files (default: false)")
@@ -143,17 +188,31 @@ func Execute() {
 		fmt.Println("  -enable-persistence    Enable write-ahead logging (default: false)")
 		fmt.Println("  -restore-wal           Restore the database from the WAL files (default: false)")
 		fmt.Println("  -wal-engine            WAL engine to use, values: sqlite, aof (default: \"null\")")
+		fmt.Println("  -protected-mode        Refuse non-loopback connections while no password is set (default: false)")
 		fmt.Println("  -requirepass           Enable authentication for the default user (default: \"\")")
+		fmt.Println("  -requirepass-file      File to read the password of the default user from (default: \"\")")
 		fmt.Println("  -o                     Directory path to create the config file (default: \"\")")
 		fmt.Println("  -c                     File path of the config file (default: \"\")")
 		fmt.Println("  -keys-limit            Keys limit for the DiceDB server (default: 200000000)")
 		fmt.Println("  -eviction-ratio        Ratio of keys to evict when the keys limit is reached (default: 0.9)")
+		fmt.Println("  -warm-from             Host:port of a Redis or DiceDB to copy the keys from before accepting clients (default: \"\")")
+		fmt.Println("  -sentinel              Run as a sentinel monitoring a leader and its replicas (default: false)")
 		color.Unset()
 		os.Exit(0)
 	}
 
 	flag.Parse()
 
+	if requirePassFile != utils.EmptyStr {
+		password, err := config.ReadSecretFile(requirePassFile)
+		if err != nil {
+			log.Fatalf("Unable to read password file: %v", err)
+		}
+		if err := flag.Set("requirepass", password); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if len(os.Args) > 2 {
 		switch os.Args[1] {
 		case "-v", "--version":
@@ -165,10 +224,10 @@ func Execute() {
 			if err := parser.ParseFromStdin(); err != nil {
 				log.Fatal(err)
 			}
-			if err := parser.Loadconfig(config.DiceConfig); err != nil {
+			if err := parser.Loadconfig(config.DiceConfig()); err != nil {
 				log.Fatal(err)
 			}
-			fmt.Println(config.DiceConfig.Version)
+			fmt.Println(config.DiceConfig().Version)
 		case "-o", "--output":
 			if len(os.Args) < 3 {
 				log.Fatal("Output file path not provided")
@@ -227,9 +286,10 @@ func Execute() {
 				if err := parser.ParseFromFile(filePath); err != nil {
 					log.Fatal(err)
 				}
-				if err := parser.Loadconfig(config.DiceConfig); err != nil {
+				if err := parser.Loadconfig(config.DiceConfig()); err != nil {
 					log.Fatal(err)
 				}
+				config.ConfigFilePath = filePath
 
 				config.MergeFlags(&flagsConfig)
 				render()
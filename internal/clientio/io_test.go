@@ -134,7 +134,7 @@ func TestDecodeOneEmptyMessage(t *testing.T) {
 }
 
 func TestDecodeOneHighVolumeData(t *testing.T) {
-	largeString := bytes.Repeat([]byte("a"), 10*config.DiceConfig.Network.IOBufferLength)
+	largeString := bytes.Repeat([]byte("a"), 10*config.DiceConfig().Network.IOBufferLength)
 	mockRW := &MockReadWriter{
 		ReadChunks: [][]byte{
 			[]byte("$" + strconv.Itoa(len(largeString)) + "\r\n"),
@@ -191,7 +191,7 @@ func TestDecodeOnePartialMessages(t *testing.T) {
 }
 
 func TestDecodeOneVeryLargeMessage(t *testing.T) {
-	largeString := bytes.Repeat([]byte("a"), 10*config.DiceConfig.Network.IOBufferLength)
+	largeString := bytes.Repeat([]byte("a"), 10*config.DiceConfig().Network.IOBufferLength)
 	mockRW := &MockReadWriter{
 		ReadChunks: [][]byte{
 			[]byte("$" + strconv.Itoa(len(largeString)) + "\r\n"),
@@ -18,10 +18,18 @@ package iohandler
 
 import (
 	"context"
+	"time"
 )
 
 type IOHandler interface {
 	Read(ctx context.Context) ([]byte, error)
 	Write(ctx context.Context, response interface{}) error
+	RemoteAddr() string
 	Close() error
 }
+
+// TimedWriter is implemented by the IOHandlers reporting the time the last response took to encode and to write,
+// for the clients tracing their commands.
+type TimedWriter interface {
+	LastWrite() (encode, write time.Duration)
+}
@@ -31,6 +31,7 @@ import (
 
 	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/clientio/iohandler"
+	"github.com/dicedb/dice/internal/failpoint"
 )
 
 const (
@@ -49,20 +50,6 @@ var (
 	errReadRequest     = errors.New("error reading request")
 )
 
-// Pre-allocate the response array
-// WARN: Do not change the ordering of the array elements
-// It is strictly mapped to internal/eval/results.go enum.
-var respArray = [][]byte{
-	clientio.RespNIL,
-	clientio.RespOK,
-	clientio.RespQueued,
-	clientio.RespZero,
-	clientio.RespOne,
-	clientio.RespMinusOne,
-	clientio.RespMinusTwo,
-	clientio.RespEmptyArray,
-}
-
 // IOHandler handles I/O operations for a network connection
 type IOHandler struct {
 	fd       int
@@ -71,9 +58,15 @@ type IOHandler struct {
 	reader   *bufio.Reader
 	writer   *bufio.Writer
 	readPool *sync.Pool
+
+	lastEncode time.Duration // lastEncode is the time the last response took to encode, 0 when streamed
+	lastWrite  time.Duration // lastWrite is the time the last response took to write
 }
 
-var _ iohandler.IOHandler = (*IOHandler)(nil)
+var (
+	_ iohandler.IOHandler   = (*IOHandler)(nil)
+	_ iohandler.TimedWriter = (*IOHandler)(nil)
+)
 
 // NewIOHandler creates a new IOHandler from a file descriptor
 func NewIOHandler(clientFD int) (*IOHandler, error) {
@@ -195,7 +188,10 @@ func (h *IOHandler) handleReadError(err error, data []byte) ([]byte, error) {
 			return data, nil
 		}
 		return nil, io.EOF
-	case errors.Is(err, net.ErrClosed), errors.Is(err, syscall.EPIPE), errors.Is(err, syscall.ECONNRESET):
+	case errors.Is(err, net.ErrClosed):
+		// The connection was closed by the server, e.g. as the client sent QUIT
+		return nil, ErrorClosed
+	case errors.Is(err, syscall.EPIPE), errors.Is(err, syscall.ECONNRESET):
 		slog.Debug("Connection closed", slog.Any("error", err))
 		cErr := h.Close()
 		if cErr != nil {
@@ -216,23 +212,17 @@ func (h *IOHandler) handleReadError(err error, data []byte) ([]byte, error) {
 
 // WriteResponse writes the response back to the network connection
 func (h *IOHandler) Write(ctx context.Context, response interface{}) error {
-	// Process the incoming response by calling the handleResponse function.
-	// This function checks the response against known RESP formatted values
-	// and returns the corresponding byte array representation. The result
-	// is assigned to the resp variable.
-	resp := HandlePredefinedResponse(response)
-
-	// Check if the processed response (resp) is not nil.
-	// If it is not nil, this means incoming response was not
-	// matched to any predefined RESP responses,
-	// and we proceed to encode the original response using
-	// the clientio.Encode function. This function converts the
-	// response into the desired format based on the specified
-	// isBlkEnc encoding flag, which indicates whether the
-	// response should be encoded in a block format.
-	if resp == nil {
+	// Large array replies are streamed to the connection through the write buffer, so that
+	// they are never encoded in full in memory.
+	stream := clientio.Streamable(response)
+
+	start := time.Now()
+	var resp []byte
+	if !stream {
 		resp = clientio.Encode(response, true)
 	}
+	h.lastEncode = time.Since(start)
+	h.lastWrite = 0
 
 	deadline := time.Now().Add(writeTimeout)
 	if err := h.conn.SetWriteDeadline(deadline); err != nil {
@@ -244,7 +234,11 @@ func (h *IOHandler) Write(ctx context.Context, response interface{}) error {
 		defer close(errChan)
 
 		var err error
-		if _, err = h.writer.Write(resp); err == nil {
+		if arg, fail := failpoint.Eval(failpoint.SocketWriteError); fail {
+			err = failpoint.WriteError(arg)
+		} else if stream {
+			err = clientio.StreamArray(&clientio.RESPArrayWriter{W: h.writer, BeforeWrite: h.extendWriteDeadline}, response)
+		} else if _, err = h.writer.Write(resp); err == nil {
 			err = h.writer.Flush()
 		}
 
@@ -255,6 +249,7 @@ func (h *IOHandler) Write(ctx context.Context, response interface{}) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	case err, ok := <-errChan:
+		h.lastWrite = time.Since(start) - h.lastEncode
 		if !ok {
 			slog.Warn("write operation failed: error channel closed unexpectedly")
 		}
@@ -276,6 +271,28 @@ func (h *IOHandler) Write(ctx context.Context, response interface{}) error {
 	return nil
 }
 
+// LastWrite returns the time the last response took to encode and to write, its encoding being part of its
+// write when it was streamed.
+func (h *IOHandler) LastWrite() (encode, write time.Duration) {
+	return h.lastEncode, h.lastWrite
+}
+
+// extendWriteDeadline gives the next write of a streamed reply the full write timeout, so that
+// the deadline bounds every write rather than the whole reply.
+func (h *IOHandler) extendWriteDeadline() {
+	if err := h.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		slog.Warn("error setting write deadline", slog.Any("error", err))
+	}
+}
+
+// RemoteAddr returns the address of the client on the other end of the connection
+func (h *IOHandler) RemoteAddr() string {
+	if h.conn == nil || h.conn.RemoteAddr() == nil {
+		return ""
+	}
+	return h.conn.RemoteAddr().String()
+}
+
 // Close underlying network connection
 func (h *IOHandler) Close() error {
 	var err error
@@ -288,29 +305,3 @@ func (h *IOHandler) Close() error {
 
 	return err
 }
-
-// handleResponse processes the incoming response from a client and returns the corresponding
-// RESP (REdis Serialization Protocol) formatted byte array based on the response content.
-//
-// The function takes an interface{} as input, attempts to assert it as a byte slice. If successful,
-// it checks the content of the byte slice against predefined RESP responses using the `bytes.Contains`
-// function. If a match is found, it returns the associated byte array response. If no match is found
-// or if the input cannot be converted to a byte slice, the function returns nil.
-//
-// This function is designed to handle various response scenarios, such as:
-// - $-1: Represents a nil response.
-// - +OK: Indicates a successful command execution.
-// - +QUEUED: Signifies that a command has been queued.
-// - :0, :1, :-1, :-2: Represents integer values in RESP format.
-// - *0: Represents an empty array in RESP format.
-//
-// Note: The use of `bytes.Contains` is to check if the provided response matches any of the
-// predefined RESP responses, making it flexible in handling responses that might include
-// additional content beyond the expected response format.
-func HandlePredefinedResponse(response interface{}) []byte {
-	if val, ok := response.(clientio.RespType); ok {
-		return respArray[val]
-	}
-
-	return nil
-}
@@ -0,0 +1,259 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package clientio
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// ReplyKind is the type of the reply of a command, whatever the transport it is sent over.
+type ReplyKind uint8
+
+const (
+	ReplyNil          ReplyKind = iota // ReplyNil is the absence of a value, e.g. the reply of GET for a missing key
+	ReplySimpleString                  // ReplySimpleString is a status, e.g. OK or QUEUED
+	ReplyBulkString                    // ReplyBulkString is a value, which may hold any byte
+	ReplyInteger
+	ReplyDouble
+	ReplyBoolean
+	ReplyArray
+	ReplyMap
+	ReplyError
+)
+
+// Reply is the reply of a command in a form shared by the frontends. The commands reply with Go values,
+// normalized once into a Reply by NewReply, and every transport renders the Reply with its own encoder:
+// EncodeReply for RESP, JSON for HTTP and WebSocket, Go values for the embedded engine.
+type Reply struct {
+	Kind  ReplyKind
+	Str   string          // Str is the value of a string or the message of an error
+	Code  diceerrors.Code // Code is the code of an error
+	Int   int64           // Int is the value of an integer
+	Float float64         // Float is the value of a double
+	Bool  bool            // Bool is the value of a boolean
+	Elems []Reply         // Elems are the elements of an array, or the keys and the values of a map in turn
+}
+
+// NewReply normalizes the reply of a command. A string reply is a simple string, the strings nested in an
+// array being bulk strings, and a []byte is a bulk string holding the bytes as they are. A value of an
+// unsupported type is a nil reply.
+func NewReply(value interface{}) Reply {
+	return toReply(value, true)
+}
+
+// ErrorReply returns the reply of a command that failed with err.
+func ErrorReply(err error) Reply {
+	return Reply{Kind: ReplyError, Str: err.Error(), Code: diceerrors.CodeOf(err)}
+}
+
+// JSONValue renders the reply as the data of the JSON responses of HTTP and WebSocket: nil for a nil reply, a
+// string for the strings and the message of an error, a number, a boolean, an array or an object for a map.
+func (r Reply) JSONValue() interface{} {
+	switch r.Kind {
+	case ReplySimpleString, ReplyBulkString, ReplyError:
+		return r.Str
+	case ReplyInteger:
+		return r.Int
+	case ReplyDouble:
+		return r.Float
+	case ReplyBoolean:
+		return r.Bool
+	case ReplyArray:
+		elems := make([]interface{}, len(r.Elems))
+		for i, elem := range r.Elems {
+			elems[i] = elem.JSONValue()
+		}
+		return elems
+	case ReplyMap:
+		m := make(map[string]interface{}, len(r.Elems)/2)
+		for i := 0; i+1 < len(r.Elems); i += 2 {
+			m[r.Elems[i].Str] = r.Elems[i+1].JSONValue()
+		}
+		return m
+	default:
+		return nil
+	}
+}
+
+func toReply(value interface{}, simple bool) Reply {
+	switch v := value.(type) {
+	case nil:
+		return Reply{Kind: ReplyNil}
+	case Reply:
+		return v
+	case []byte:
+		return Reply{Kind: ReplyBulkString, Str: string(v)}
+	case RespType:
+		return predefinedReply(v)
+	case string:
+		// The opening brackets of the replies of JSON.RESP are simple strings, like in RESP
+		if simple || v == "[" || v == "{" {
+			return Reply{Kind: ReplySimpleString, Str: v}
+		}
+		return Reply{Kind: ReplyBulkString, Str: v}
+	case int:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case int8:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case int16:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case int32:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case int64:
+		return Reply{Kind: ReplyInteger, Int: v}
+	case uint:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case uint8:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case uint16:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case uint32:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case uint64:
+		return Reply{Kind: ReplyInteger, Int: int64(v)}
+	case float32:
+		return Reply{Kind: ReplyDouble, Float: float64(v)}
+	case float64:
+		return Reply{Kind: ReplyDouble, Float: v}
+	case bool:
+		return Reply{Kind: ReplyBoolean, Bool: v}
+	case error:
+		return ErrorReply(v)
+	case []string:
+		elems := make([]Reply, len(v))
+		for i, s := range v {
+			elems[i] = Reply{Kind: ReplyBulkString, Str: s}
+		}
+		return Reply{Kind: ReplyArray, Elems: elems}
+	case []int64:
+		elems := make([]Reply, len(v))
+		for i, n := range v {
+			elems[i] = Reply{Kind: ReplyInteger, Int: n}
+		}
+		return Reply{Kind: ReplyArray, Elems: elems}
+	case []uint64:
+		elems := make([]Reply, len(v))
+		for i, n := range v {
+			elems[i] = Reply{Kind: ReplyInteger, Int: int64(n)}
+		}
+		return Reply{Kind: ReplyArray, Elems: elems}
+	case []*object.Obj:
+		elems := make([]Reply, len(v))
+		for i, obj := range v {
+			elems[i] = toReply(obj.Value, false)
+		}
+		return Reply{Kind: ReplyArray, Elems: elems}
+	case []interface{}:
+		elems := make([]Reply, len(v))
+		for i, elem := range v {
+			elems[i] = toReply(elem, false)
+		}
+		return Reply{Kind: ReplyArray, Elems: elems}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		elems := make([]Reply, 0, 2*len(v))
+		for _, key := range keys {
+			elems = append(elems, Reply{Kind: ReplyBulkString, Str: key}, toReply(v[key], false))
+		}
+		return Reply{Kind: ReplyMap, Elems: elems}
+	case dstore.QueryWatchEvent:
+		return Reply{Kind: ReplyArray, Elems: []Reply{
+			{Kind: ReplyBulkString, Str: "key:" + v.Key},
+			{Kind: ReplyBulkString, Str: "op:" + v.Operation},
+		}}
+	default:
+		return Reply{Kind: ReplyNil}
+	}
+}
+
+// predefinedReply returns the reply a RespType stands for.
+func predefinedReply(t RespType) Reply {
+	switch t {
+	case OK:
+		return Reply{Kind: ReplySimpleString, Str: "OK"}
+	case CommandQueued:
+		return Reply{Kind: ReplySimpleString, Str: "QUEUED"}
+	case IntegerZero:
+		return Reply{Kind: ReplyInteger, Int: 0}
+	case IntegerOne:
+		return Reply{Kind: ReplyInteger, Int: 1}
+	case IntegerNegativeOne:
+		return Reply{Kind: ReplyInteger, Int: -1}
+	case IntegerNegativeTwo:
+		return Reply{Kind: ReplyInteger, Int: -2}
+	case EmptyArray:
+		return Reply{Kind: ReplyArray, Elems: []Reply{}}
+	default:
+		return Reply{Kind: ReplyNil}
+	}
+}
+
+// EncodeReply encodes the reply in RESP. The doubles are encoded as integers when they have no fractional
+// part and as bulk strings otherwise, the booleans as simple strings and the maps as arrays of their keys
+// and values in turn.
+func EncodeReply(r Reply) []byte {
+	return appendReply(nil, r)
+}
+
+func appendReply(b []byte, r Reply) []byte {
+	switch r.Kind {
+	case ReplySimpleString:
+		// A simple string can not hold a line break, such a string is sent as a bulk string instead
+		if strings.ContainsAny(r.Str, "\r\n") {
+			return appendBulkString(b, r.Str)
+		}
+		return append(append(append(b, '+'), r.Str...), "\r\n"...)
+	case ReplyBulkString:
+		return appendBulkString(b, r.Str)
+	case ReplyInteger:
+		return append(strconv.AppendInt(append(b, ':'), r.Int, 10), "\r\n"...)
+	case ReplyDouble:
+		// The numbers of the JSON values are decoded as doubles, even the integers
+		if i, ok := utils.IsFloatToIntPossible(r.Float); ok {
+			return append(strconv.AppendInt(append(b, ':'), int64(i), 10), "\r\n"...)
+		}
+		return appendBulkString(b, strconv.FormatFloat(r.Float, 'f', -1, 64))
+	case ReplyBoolean:
+		return append(strconv.AppendBool(append(b, '+'), r.Bool), "\r\n"...)
+	case ReplyArray, ReplyMap:
+		b = append(strconv.AppendInt(append(b, '*'), int64(len(r.Elems)), 10), "\r\n"...)
+		for _, elem := range r.Elems {
+			b = appendReply(b, elem)
+		}
+		return b
+	case ReplyError:
+		return append(append(append(b, '-'), r.Str...), "\r\n"...)
+	default:
+		return append(b, RespNIL...)
+	}
+}
+
+func appendBulkString(b []byte, s string) []byte {
+	b = append(strconv.AppendInt(append(b, '$'), int64(len(s)), 10), "\r\n"...)
+	return append(append(b, s...), "\r\n"...)
+}
@@ -0,0 +1,99 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package clientio_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReply(t *testing.T) {
+	tests := map[string]struct {
+		value    interface{}
+		expected clientio.Reply
+	}{
+		"nil":         {nil, clientio.Reply{Kind: clientio.ReplyNil}},
+		"predefined":  {clientio.IntegerNegativeTwo, clientio.Reply{Kind: clientio.ReplyInteger, Int: -2}},
+		"empty array": {clientio.EmptyArray, clientio.Reply{Kind: clientio.ReplyArray, Elems: []clientio.Reply{}}},
+		"status":      {"PONG", clientio.Reply{Kind: clientio.ReplySimpleString, Str: "PONG"}},
+		"unsigned":    {uint64(7), clientio.Reply{Kind: clientio.ReplyInteger, Int: 7}},
+		"double":      {1.5, clientio.Reply{Kind: clientio.ReplyDouble, Float: 1.5}},
+		"error":       {errors.New("ERR boom"), clientio.Reply{Kind: clientio.ReplyError, Str: "ERR boom", Code: diceerrors.CodeGeneric}},
+		"coded error": {diceerrors.ErrSyntax, clientio.Reply{Kind: clientio.ReplyError, Str: "ERR syntax error", Code: diceerrors.CodeSyntax}},
+		"wrong type":  {diceerrors.ErrWrongTypeOperation, clientio.Reply{Kind: clientio.ReplyError, Str: diceerrors.WrongTypeErr[1:], Code: diceerrors.CodeWrongType}},
+		"unsupported": {struct{}{}, clientio.Reply{Kind: clientio.ReplyNil}},
+		"bytes":       {[]byte("-ERR raw\r\n"), clientio.Reply{Kind: clientio.ReplyBulkString, Str: "-ERR raw\r\n"}},
+		"nested": {
+			[]interface{}{"v", clientio.NIL, int64(1), []string{"a"}},
+			clientio.Reply{Kind: clientio.ReplyArray, Elems: []clientio.Reply{
+				{Kind: clientio.ReplyBulkString, Str: "v"},
+				{Kind: clientio.ReplyNil},
+				{Kind: clientio.ReplyInteger, Int: 1},
+				{Kind: clientio.ReplyArray, Elems: []clientio.Reply{{Kind: clientio.ReplyBulkString, Str: "a"}}},
+			}},
+		},
+		"map": {
+			map[string]interface{}{"b": int64(2), "a": "1"},
+			clientio.Reply{Kind: clientio.ReplyMap, Elems: []clientio.Reply{
+				{Kind: clientio.ReplyBulkString, Str: "a"},
+				{Kind: clientio.ReplyBulkString, Str: "1"},
+				{Kind: clientio.ReplyBulkString, Str: "b"},
+				{Kind: clientio.ReplyInteger, Int: 2},
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, clientio.NewReply(tc.value))
+		})
+	}
+}
+
+func TestEncodeReply(t *testing.T) {
+	tests := map[string]struct {
+		reply    clientio.Reply
+		expected string
+	}{
+		"nil":              {clientio.Reply{Kind: clientio.ReplyNil}, "$-1\r\n"},
+		"simple string":    {clientio.Reply{Kind: clientio.ReplySimpleString, Str: "OK"}, "+OK\r\n"},
+		"multiline status": {clientio.Reply{Kind: clientio.ReplySimpleString, Str: "a\r\nb"}, "$4\r\na\r\nb\r\n"},
+		"bulk string":      {clientio.Reply{Kind: clientio.ReplyBulkString, Str: "v"}, "$1\r\nv\r\n"},
+		"integer":          {clientio.Reply{Kind: clientio.ReplyInteger, Int: -1}, ":-1\r\n"},
+		"integral double":  {clientio.Reply{Kind: clientio.ReplyDouble, Float: 3}, ":3\r\n"},
+		"double":           {clientio.Reply{Kind: clientio.ReplyDouble, Float: 2.5}, "$3\r\n2.5\r\n"},
+		"boolean":          {clientio.Reply{Kind: clientio.ReplyBoolean, Bool: true}, "+true\r\n"},
+		"error":            {clientio.Reply{Kind: clientio.ReplyError, Str: "ERR boom"}, "-ERR boom\r\n"},
+		"map": {
+			clientio.Reply{Kind: clientio.ReplyMap, Elems: []clientio.Reply{
+				{Kind: clientio.ReplyBulkString, Str: "k"},
+				{Kind: clientio.ReplyInteger, Int: 1},
+			}},
+			"*2\r\n$1\r\nk\r\n:1\r\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(clientio.EncodeReply(tc.reply)))
+		})
+	}
+}
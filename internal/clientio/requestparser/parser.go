@@ -22,4 +22,6 @@ import (
 
 type Parser interface {
 	Parse(data []byte) ([]*cmd.DiceDBCmd, error)
+	// ParseFrames parses the complete commands at the start of data, and returns the number of bytes they span
+	ParseFrames(data []byte) ([]*cmd.DiceDBCmd, int, error)
 }
@@ -47,6 +47,12 @@ var (
 // CRLF is the line delimiter in RESP
 var CRLF = []byte{'\r', '\n'}
 
+// maxBulkLength is the maximal length of a bulk string, as the proto-max-bulk-len default of Redis
+const maxBulkLength = 512 * 1024 * 1024
+
+// minElementSize is the size of the smallest element of an array, an empty simple string
+const minElementSize = len("+\r\n")
+
 // Parser is responsible for parsing RESP protocol data
 type Parser struct {
 	data []byte
@@ -82,6 +88,27 @@ func (p *Parser) Parse(data []byte) ([]*cmd.DiceDBCmd, error) {
 	return commands, nil
 }
 
+// ParseFrames parses the complete commands at the start of data and returns them along with the number of
+// bytes they span. A command cut at the end of data is not an error: it is left to be parsed again once the
+// rest of it is received, so that a command split across reads is never mistaken for an invalid one.
+func (p *Parser) ParseFrames(data []byte) (commands []*cmd.DiceDBCmd, n int, err error) {
+	p.SetData(data)
+	for p.pos < len(p.data) {
+		start := p.pos
+		c, err := p.parseCommand()
+		if errors.Is(err, ErrUnexpectedEOF) {
+			return commands, start, nil
+		}
+		if err != nil {
+			return commands, start, err
+		}
+
+		commands = append(commands, c)
+	}
+
+	return commands, p.pos, nil
+}
+
 func (p *Parser) parseCommand() (*cmd.DiceDBCmd, error) {
 	if p.pos >= len(p.data) {
 		return nil, ErrUnexpectedEOF
@@ -90,7 +117,10 @@ func (p *Parser) parseCommand() (*cmd.DiceDBCmd, error) {
 	// A Dice command should always be an array as it follows RESP2 specifications
 	elements, err := p.parse()
 	if err != nil {
-		slog.Error("error while parsing command", slog.Any("cmd", string(p.data)), slog.Any("error", err))
+		// A command cut by the end of the data is not an error of the client, the rest of it is not received yet
+		if !errors.Is(err, ErrUnexpectedEOF) {
+			slog.Error("error while parsing command", slog.Any("cmd", string(p.data)), slog.Any("error", err))
+		}
 		return nil, fmt.Errorf("error parsing command: %w", err)
 	}
 
@@ -114,8 +144,14 @@ func (p *Parser) parse() ([]string, error) {
 		}
 	}
 
-	result := make([]string, 0, count)
+	// The length is not trusted for the allocation, every element spans a few bytes at least
+	result := make([]string, 0, min(count, (len(p.data)-p.pos)/minElementSize))
 	for i := 0; i < count; i++ {
+		// The arguments of a command can not be arrays, which also bounds the depth of the parsing
+		if p.pos < len(p.data) && p.data[p.pos] == byte(Array) {
+			return nil, fmt.Errorf("%w: nested array in element %d", ErrProtocolError, i)
+		}
+
 		val, err := p.ParseOne()
 		if err != nil {
 			return nil, fmt.Errorf("parse array element %d: %w", i, err)
@@ -219,7 +255,7 @@ func (p *Parser) parseBulkString() (string, error) {
 		return "(nil)", nil // Null bulk string
 	}
 
-	if length < -1 {
+	if length < -1 || length > maxBulkLength {
 		return "", fmt.Errorf("invalid bulk string length: %d", length)
 	}
 
@@ -348,3 +348,125 @@ func TestParser_parseArray(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ParseFrames(t *testing.T) {
+	set := "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nvalue\r\n"
+	get := "*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []*cmd.DiceDBCmd
+		wantN   int
+		wantErr bool
+	}{
+		{
+			name:  "Complete commands",
+			input: set + get,
+			want:  []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{"key", "value"}}, {Cmd: "GET", Args: []string{"key"}}},
+			wantN: len(set + get),
+		},
+		{
+			name:  "Command cut in a bulk string",
+			input: set + get[:len(get)-3],
+			want:  []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{"key", "value"}}},
+			wantN: len(set),
+		},
+		{
+			name:  "Command cut in a length",
+			input: set + "*2\r\n$3",
+			want:  []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{"key", "value"}}},
+			wantN: len(set),
+		},
+		{
+			name:  "Command cut between CR and LF",
+			input: "*1\r",
+			wantN: 0,
+		},
+		{
+			name:    "Invalid command after a complete one",
+			input:   set + "NOT AN ARRAY\r\n",
+			want:    []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{"key", "value"}}},
+			wantN:   len(set),
+			wantErr: true,
+		},
+		{
+			name:    "Nested array",
+			input:   "*2\r\n$3\r\nGET\r\n*1\r\n$3\r\nkey\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "Bulk string too long",
+			input:   "*2\r\n$3\r\nGET\r\n$9223372036854775807\r\n",
+			wantErr: true,
+		},
+		{
+			name:  "Array length larger than the data",
+			input: "*2147483647\r\n$3\r\nGET\r\n",
+			wantN: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser()
+			got, n, err := p.ParseFrames([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.ParseFrames() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parser.ParseFrames() = %v, want %v", got, tt.want)
+			}
+			if n != tt.wantN {
+				t.Errorf("Parser.ParseFrames() n = %v, want %v", n, tt.wantN)
+			}
+		})
+	}
+}
+
+// FuzzParseFrames checks that the parser never panics, and that the commands parsed do not depend on the way
+// the stream is split across reads, as when the client or a proxy writes a command in several packets.
+func FuzzParseFrames(f *testing.F) {
+	f.Add([]byte("*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nvalue\r\n*1\r\n$4\r\nQUIT\r\n"), 7)
+	f.Add([]byte("*2\r\n$4\r\nECHO\r\n$5\r\nhe\r\nl\r\n*1\r\n$5\r\nRESET\r\n"), 20)
+	f.Add([]byte("*3\r\n$6\r\nEXPIRE\r\n$3\r\nkey\r\n:60\r\n*2\r\n+PING\r\n-ERR\r\n"), 1)
+	f.Add([]byte("*2\r\n$3\r\nGET\r\n$-1\r\n*-1\r\n"), 3)
+	f.Add([]byte("*1\r\n*1\r\n*1\r\n$1\r\na\r\n"), 5)
+
+	f.Fuzz(func(t *testing.T, data []byte, split int) {
+		whole, n, err := NewParser().ParseFrames(data)
+		if n < 0 || n > len(data) {
+			t.Fatalf("ParseFrames() n = %d, out of the %d bytes of data", n, len(data))
+		}
+
+		if split < 0 {
+			split = -split
+		}
+		if len(data) > 0 {
+			split %= len(data) + 1
+		} else {
+			split = 0
+		}
+
+		// The first read is parsed, then what is left of it is parsed along with the second read
+		p := NewParser()
+		first, firstN, firstErr := p.ParseFrames(data[:split])
+		if firstErr != nil {
+			if err == nil {
+				t.Fatalf("ParseFrames() failed on the first %d bytes only: %v", split, firstErr)
+			}
+			return
+		}
+		rest, restN, restErr := p.ParseFrames(data[firstN:])
+		if (restErr != nil) != (err != nil) {
+			t.Fatalf("ParseFrames() error = %v once split, %v at once", restErr, err)
+		}
+		if firstN+restN != n {
+			t.Fatalf("ParseFrames() n = %d once split, %d at once", firstN+restN, n)
+		}
+		if got := append(first, rest...); len(got) != len(whole) || (len(got) > 0 && !reflect.DeepEqual(got, whole)) {
+			t.Fatalf("ParseFrames() = %v once split, %v at once", got, whole)
+		}
+	})
+}
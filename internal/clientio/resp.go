@@ -18,21 +18,15 @@ package clientio
 
 import (
 	"bytes"
-	"fmt"
 	"io"
 	"strconv"
 
-	"github.com/dicedb/dice/internal/object"
-
 	"github.com/dicedb/dice/internal/server/utils"
-	dstore "github.com/dicedb/dice/internal/store"
 )
 
+// RespType is a reply shared by many commands, normalized by NewReply like the other replies.
 type RespType int
 
-// WARN: Do not change the ordering of the enum elements
-// It is strictly mapped to HandlePredefinedResponse func internal/clientio/iohandler/netconn/netconn.go
-
 const (
 	NIL                RespType = iota // Represents an empty or null response.
 	OK                                 // Represents a successful "OK" response.
@@ -197,112 +191,12 @@ func readArray(buf *bytes.Buffer, rp *RESPParser) (interface{}, error) {
 	return elems, nil
 }
 
-func encodeString(v string) []byte {
-	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(v), v))
-}
-
-// encodeBool encodes bool as simple strings
-func encodeBool(v bool) []byte {
-	return []byte(fmt.Sprintf("+%t\r\n", v))
-}
-
+// Encode encodes the reply of a command in RESP, a string being a simple string when isSimple is set and
+// a bulk string otherwise.
 func Encode(value interface{}, isSimple bool) []byte {
-	// Use a type switch to determine the type of the provided value and encode accordingly.
-	switch v := value.(type) {
-	// Temporary case to maintain backwards compatibility.
-	// This case handles byte slices ([]byte) directly, allowing existing functionality
-	// that relies on byte slice inputs to continue working without modifications.
-	// It serves as a transitional measure and should be revisited for removal
-	// once all commands are migrated.
-	case []byte:
-		return v // Return the byte slice as-is.
-
-	case string:
-		// encode as simple strings
-		if isSimple || v == "[" || v == "{" {
-			return []byte(fmt.Sprintf("+%s\r\n", v))
-		}
-		// encode as bulk strings
-		return encodeString(v)
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-		return []byte(fmt.Sprintf(":%d\r\n", v)) // Prefix with ':' for RESP integers.
-
-	// Handle floating-point types similarly to integers.
-	case float32, float64:
-		// In case the element being encoded was obtained after parsing a JSON value,
-		// it is possible for integers to have been encoded as floats
-		// (since encoding/json unmarshals numeric values as floats).
-		// Therefore, we need to check if value is an integer
-		intValue, isInteger := utils.IsFloatToIntPossible(v.(float64))
-		if isInteger {
-			return []byte(fmt.Sprintf(":%d\r\n", intValue))
-		}
-
-		// if it is a float, encode like a string
-		str := strconv.FormatFloat(v.(float64), 'f', -1, 64)
-		return encodeString(str)
-	case bool:
-		return encodeBool(v)
-	case []string:
-		var b []byte
-		buf := bytes.NewBuffer(b) // Create a buffer to accumulate encoded strings.
-		for _, b := range value.([]string) {
-			buf.Write(encodeString(b)) // Encode each string and write to the buffer.
-		}
-		return []byte(fmt.Sprintf("*%d\r\n%s", len(v), buf.Bytes())) // Return the encoded response.
-
-	// Handle slices of custom objects (Obj).
-	case []*object.Obj:
-		var b []byte
-		buf := bytes.NewBuffer(b) // Create a buffer to accumulate encoded objects.
-		for _, b := range value.([]*object.Obj) {
-			buf.Write(Encode(b.Value, false)) // Encode each object’s value and write to the buffer.
-		}
-		return []byte(fmt.Sprintf("*%d\r\n%s", len(v), buf.Bytes())) // Return the encoded response.
-
-	// Handle slices of interfaces.
-	case []interface{}:
-		var b []byte
-		buf := bytes.NewBuffer(b) // Create a buffer for accumulating encoded values.
-		for _, elem := range v {
-			buf.Write(Encode(elem, false)) // Encode each element and write to the buffer.
-		}
-		return []byte(fmt.Sprintf("*%d\r\n%s", len(v), buf.Bytes())) // Return the encoded response.
-
-	// Handle slices of int64.
-	case []int64:
-		var b []byte
-		buf := bytes.NewBuffer(b) // Create a buffer for accumulating encoded values.
-		for _, b := range value.([]int64) {
-			buf.Write(Encode(b, false)) // Encode each int64 and write to the buffer.
-		}
-		return []byte(fmt.Sprintf("*%d\r\n%s", len(v), buf.Bytes())) // Return the encoded response.
-
-	case []uint64:
-		var b []byte
-		buf := bytes.NewBuffer(b) // Create a buffer for accumulating encoded values.
-		for _, b := range value.([]uint64) {
-			buf.Write(Encode(b, false)) // Encode each uint64 and write to the buffer.
-		}
-		return []byte(fmt.Sprintf("*%d\r\n%s", len(v), buf.Bytes())) // Return the encoded response.
-
-	// Handle error type by formatting it as a RESP error.
-	case error:
-		return []byte(fmt.Sprintf("-%s\r\n", v))
-	case dstore.QueryWatchEvent:
-		var b []byte
-		buf := bytes.NewBuffer(b)
-		we := value.(dstore.QueryWatchEvent)
-		buf.Write(Encode(fmt.Sprintf("key:%s", we.Key), false))
-		buf.Write(Encode(fmt.Sprintf("op:%s", we.Operation), false))
-		return []byte(fmt.Sprintf("*2\r\n%s", buf.Bytes()))
-
-	// Handle map[string]bool and return a nil response indicating unsupported types.
-	case map[string]bool:
-		return RespNIL // Return nil response for unsupported type.
-
-	// For all other unsupported types, return a nil response.
-	default:
-		return RespNIL // Return nil response for unsupported types.
+	// The replies of the commands not migrated yet are already encoded
+	if b, ok := value.([]byte); ok {
+		return b
 	}
+	return EncodeReply(toReply(value, isSimple))
 }
@@ -0,0 +1,136 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package clientio
+
+import (
+	"bufio"
+	"strconv"
+)
+
+// StreamThreshold is the number of elements from which an array reply, e.g. the reply of LRANGE
+// over a long list or of KEYS *, is streamed to the client instead of being encoded in full first.
+const StreamThreshold = 1024
+
+// ArrayWriter writes an array reply to a client one element at a time. The implementations buffer
+// a bounded number of bytes before writing them to the connection, so the memory used to write a
+// reply does not grow with its number of elements.
+type ArrayWriter interface {
+	// Begin starts an array reply of n elements.
+	Begin(n int) error
+	// WriteElement writes the next element of the reply.
+	WriteElement(v interface{}) error
+	// End completes the reply and writes the bytes still buffered to the connection.
+	End() error
+}
+
+// Streamable reports whether value is an array reply long enough to be streamed with StreamArray.
+func Streamable(value interface{}) bool {
+	switch v := value.(type) {
+	case []string:
+		return len(v) >= StreamThreshold
+	case []interface{}:
+		return len(v) >= StreamThreshold
+	}
+	return false
+}
+
+// StreamArray writes the array reply value through w element by element. value must be Streamable.
+func StreamArray(w ArrayWriter, value interface{}) error {
+	switch v := value.(type) {
+	case []string:
+		if err := w.Begin(len(v)); err != nil {
+			return err
+		}
+		for _, elem := range v {
+			if err := w.WriteElement(elem); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if err := w.Begin(len(v)); err != nil {
+			return err
+		}
+		for _, elem := range v {
+			if err := w.WriteElement(elem); err != nil {
+				return err
+			}
+		}
+	}
+	return w.End()
+}
+
+// EncodedSize returns the length of the RESP encoding of value. Unlike len(Encode(value, isSimple)),
+// it does not encode streamable array replies in full to measure them.
+func EncodedSize(value interface{}, isSimple bool) int {
+	var size int
+	switch v := value.(type) {
+	case []string:
+		if !Streamable(v) {
+			break
+		}
+		for _, elem := range v {
+			size += len(Encode(elem, false))
+		}
+		return len(arrayHeader(len(v))) + size
+	case []interface{}:
+		if !Streamable(v) {
+			break
+		}
+		for _, elem := range v {
+			size += len(Encode(elem, false))
+		}
+		return len(arrayHeader(len(v))) + size
+	}
+	return len(Encode(value, isSimple))
+}
+
+// arrayHeader returns the RESP header of an array of n elements.
+func arrayHeader(n int) []byte {
+	return []byte("*" + strconv.Itoa(n) + "\r\n")
+}
+
+// RESPArrayWriter streams an array reply in RESP through a buffered writer, encoding the
+// elements like Encode does.
+type RESPArrayWriter struct {
+	W *bufio.Writer
+	// BeforeWrite is called, when set, before the buffered bytes are written to the connection,
+	// e.g. to extend the write deadline of the connection.
+	BeforeWrite func()
+}
+
+func (w *RESPArrayWriter) Begin(n int) error {
+	return w.write(arrayHeader(n))
+}
+
+func (w *RESPArrayWriter) WriteElement(v interface{}) error {
+	return w.write(Encode(v, false))
+}
+
+func (w *RESPArrayWriter) End() error {
+	if w.BeforeWrite != nil {
+		w.BeforeWrite()
+	}
+	return w.W.Flush()
+}
+
+func (w *RESPArrayWriter) write(b []byte) error {
+	if len(b) > w.W.Available() && w.BeforeWrite != nil {
+		w.BeforeWrite()
+	}
+	_, err := w.W.Write(b)
+	return err
+}
@@ -0,0 +1,62 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package clientio
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func streamableReplies() map[string]interface{} {
+	strs := make([]string, StreamThreshold)
+	values := make([]interface{}, StreamThreshold)
+	for i := range strs {
+		strs[i] = "element" + strconv.Itoa(i)
+		values[i] = int64(i)
+	}
+	values[0] = NIL
+	values[1] = []string{"nested"}
+	return map[string]interface{}{"strings": strs, "values": values}
+}
+
+func TestStreamArrayMatchesEncode(t *testing.T) {
+	for name, reply := range streamableReplies() {
+		t.Run(name, func(t *testing.T) {
+			require.True(t, Streamable(reply))
+
+			var buf bytes.Buffer
+			flushes := 0
+			w := &RESPArrayWriter{W: bufio.NewWriterSize(&buf, 64), BeforeWrite: func() { flushes++ }}
+			require.NoError(t, StreamArray(w, reply))
+
+			assert.Equal(t, Encode(reply, false), buf.Bytes())
+			assert.Equal(t, buf.Len(), EncodedSize(reply, false))
+			assert.Greater(t, flushes, 1)
+		})
+	}
+}
+
+func TestStreamable(t *testing.T) {
+	assert.False(t, Streamable([]string{"a"}))
+	assert.False(t, Streamable("a"))
+	assert.Equal(t, len(Encode([]string{"a"}, false)), EncodedSize([]string{"a"}, false))
+}
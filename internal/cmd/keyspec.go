@@ -0,0 +1,130 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// KeySpec locates the keys among the arguments of a command. The keys of every command are extracted from
+// its KeySpec, by the routing to the shards as well as by the audit log, COMMAND GETKEYS or the replication,
+// so that a command is routed correctly as soon as its KeySpec is registered. Like Redis, the indices count
+// the name of the command as 0.
+type KeySpec struct {
+	// BeginIndex is the index of the first key, 0 when the command has no key
+	BeginIndex int
+	// Step is the distance between two keys, e.g. 2 for `MSET key value [key value ...]`, 0 standing for 1
+	Step int
+	// LastKey is the index of the last key, 0 when the command has a single key. A negative index counts
+	// from the end, e.g. -1 for `DEL key [key ...]` or -2 for `BLPOP key [key ...] timeout`.
+	LastKey int
+	// KeyNumIndex is the index of the argument holding the number of keys, e.g. 1 for
+	// `SINTERCARD numkeys key [key ...] [LIMIT limit]`, 0 when the keys are located by LastKey
+	KeyNumIndex int
+	// Options are the options taking one argument that may follow the keys of a command whose LastKey counts
+	// from the end, e.g. IFVERSION for `DEL key [key ...] [IFVERSION version]`
+	Options []string
+}
+
+// keySpecs is the table of the KeySpecs of the commands with keys, filled in by the packages implementing
+// the commands when they are initialized and only read from then on.
+var keySpecs = make(map[string]KeySpec)
+
+// RegisterKeySpec registers the KeySpec of the command. Commands without keys, whose BeginIndex is 0,
+// are ignored.
+func RegisterKeySpec(name string, spec KeySpec) {
+	if spec.BeginIndex > 0 {
+		keySpecs[name] = spec
+	}
+}
+
+// LookupKeySpec returns the KeySpec of the command, false when it has no keys or is unknown.
+func LookupKeySpec(name string) (KeySpec, bool) {
+	spec, ok := keySpecs[name]
+	return spec, ok
+}
+
+// Keys returns the keys among the arguments of the command.
+func (s KeySpec) Keys(args []string) []string {
+	var keys []string
+	for _, i := range s.KeyIndices(args) {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+// KeyIndices returns the positions of the keys among the arguments of the command, counted from 0 for the
+// first argument, e.g. to rewrite the keys in place.
+func (s KeySpec) KeyIndices(args []string) []int {
+	if s.BeginIndex == 0 {
+		return nil
+	}
+
+	last := s.BeginIndex
+	if s.KeyNumIndex > 0 {
+		if s.KeyNumIndex > len(args) {
+			return nil
+		}
+		n, err := strconv.Atoi(args[s.KeyNumIndex-1])
+		if err != nil || n <= 0 {
+			return nil
+		}
+		last = s.BeginIndex + (n-1)*max(s.Step, 1)
+	} else if s.LastKey > 0 {
+		last = s.LastKey
+	} else if s.LastKey < 0 {
+		last = len(args) + 1 + s.LastKey - 2*s.trailingOptions(args)
+	}
+
+	var indices []int
+	for i := s.BeginIndex; i <= last && i <= len(args); i += max(s.Step, 1) {
+		indices = append(indices, i-1)
+	}
+	return indices
+}
+
+// trailingOptions returns the number of Options ending the arguments, the first key being kept.
+func (s KeySpec) trailingOptions(args []string) int {
+	n := 0
+	for end := len(args); end-2 >= s.BeginIndex; end -= 2 {
+		if !slices.ContainsFunc(s.Options, func(o string) bool { return strings.EqualFold(o, args[end-2]) }) {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// Keys returns the keys of the command, none when it has no keys or is unknown.
+func Keys(c *DiceDBCmd) []string {
+	spec, ok := LookupKeySpec(c.Cmd)
+	if !ok {
+		return nil
+	}
+	return spec.Keys(c.Args)
+}
+
+// FirstKey returns the first key of the command, false when it has no keys or is unknown.
+func FirstKey(c *DiceDBCmd) (string, bool) {
+	spec, ok := LookupKeySpec(c.Cmd)
+	if !ok || spec.BeginIndex > len(c.Args) {
+		return "", false
+	}
+	return c.Args[spec.BeginIndex-1], true
+}
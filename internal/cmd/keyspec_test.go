@@ -0,0 +1,69 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySpecKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     KeySpec
+		args     []string
+		expected []string
+	}{
+		{name: "no key", spec: KeySpec{}, args: []string{"a"}, expected: nil},
+		{name: "single key", spec: KeySpec{BeginIndex: 1}, args: []string{"k", "v"}, expected: []string{"k"}},
+		{name: "key after a subcommand", spec: KeySpec{BeginIndex: 2}, args: []string{"ENCODING", "k"}, expected: []string{"k"}},
+		{name: "fixed last key", spec: KeySpec{BeginIndex: 1, LastKey: 2}, args: []string{"src", "dst", "REPLACE"}, expected: []string{"src", "dst"}},
+		{name: "variadic keys", spec: KeySpec{BeginIndex: 1, LastKey: -1}, args: []string{"a", "b", "c"}, expected: []string{"a", "b", "c"}},
+		{name: "variadic keys before a timeout", spec: KeySpec{BeginIndex: 1, LastKey: -2}, args: []string{"a", "b", "0"}, expected: []string{"a", "b"}},
+		{name: "key value pairs", spec: KeySpec{BeginIndex: 1, Step: 2, LastKey: -1}, args: []string{"a", "1", "b", "2"}, expected: []string{"a", "b"}},
+		{name: "missing key", spec: KeySpec{BeginIndex: 2}, args: []string{"HELP"}, expected: nil},
+		{name: "number of keys", spec: KeySpec{BeginIndex: 2, KeyNumIndex: 1}, args: []string{"2", "a", "b", "LIMIT", "1"}, expected: []string{"a", "b"}},
+		{name: "invalid number of keys", spec: KeySpec{BeginIndex: 2, KeyNumIndex: 1}, args: []string{"x", "a"}, expected: nil},
+		{name: "variadic keys before an option", spec: KeySpec{BeginIndex: 1, LastKey: -1, Options: []string{"IFVERSION"}}, args: []string{"a", "ifversion", "3"}, expected: []string{"a"}},
+		{name: "option name as the only key", spec: KeySpec{BeginIndex: 1, LastKey: -1, Options: []string{"IFVERSION"}}, args: []string{"IFVERSION", "3"}, expected: []string{"IFVERSION", "3"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.spec.Keys(tc.args))
+		})
+	}
+}
+
+func TestKeySpecTable(t *testing.T) {
+	RegisterKeySpec("KEYSPEC.TEST", KeySpec{BeginIndex: 2, LastKey: -1})
+	RegisterKeySpec("KEYSPEC.NOKEY", KeySpec{})
+	t.Cleanup(func() { delete(keySpecs, "KEYSPEC.TEST") })
+
+	c := &DiceDBCmd{Cmd: "KEYSPEC.TEST", Args: []string{"opt", "a", "b"}}
+	assert.Equal(t, []string{"a", "b"}, Keys(c))
+	key, ok := FirstKey(c)
+	assert.True(t, ok)
+	assert.Equal(t, "a", key)
+
+	_, ok = FirstKey(&DiceDBCmd{Cmd: "KEYSPEC.TEST", Args: []string{"opt"}})
+	assert.False(t, ok)
+	_, ok = LookupKeySpec("KEYSPEC.NOKEY")
+	assert.False(t, ok)
+	assert.Nil(t, Keys(&DiceDBCmd{Cmd: "UNKNOWN", Args: []string{"k"}}))
+}
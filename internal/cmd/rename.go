@@ -0,0 +1,109 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+)
+
+// CommandRenames is the table of renamed and disabled commands consulted by the
+// frontends before a command is dispatched. It is empty until LoadCommandRenames is called.
+var CommandRenames = &Renames{
+	aliases: make(map[string]string),
+	hidden:  make(map[string]bool),
+}
+
+// Renames maps the command names exposed to clients onto the internal command names.
+// A renamed command is only reachable through its new name, and a disabled command
+// is not reachable at all.
+type Renames struct {
+	aliases map[string]string // aliases maps the new name of a renamed command to its internal name
+	hidden  map[string]bool   // hidden holds internal names that clients can no longer use directly
+}
+
+// NewRenames builds a Renames table from a list of disabled commands and a list of
+// OLD:NEW rename pairs. Empty entries are ignored.
+func NewRenames(disabled, renamed []string) (*Renames, error) {
+	r := &Renames{
+		aliases: make(map[string]string),
+		hidden:  make(map[string]bool),
+	}
+
+	for _, name := range disabled {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		r.hidden[name] = true
+	}
+
+	for _, pair := range renamed {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid command rename %q, expected OLD:NEW", pair)
+		}
+
+		oldName := strings.ToUpper(strings.TrimSpace(parts[0]))
+		newName := strings.ToUpper(strings.TrimSpace(parts[1]))
+
+		// Renaming a command to an empty name disables it
+		r.hidden[oldName] = true
+		if newName == "" {
+			continue
+		}
+
+		if _, exists := r.aliases[newName]; exists {
+			return nil, fmt.Errorf("command name %s is used by more than one rename", newName)
+		}
+		r.aliases[newName] = oldName
+	}
+
+	return r, nil
+}
+
+// Resolve translates the command name sent by a client into the internal command name.
+// The second return value is false if the command was disabled or renamed away.
+func (r *Renames) Resolve(name string) (string, bool) {
+	if internal, ok := r.aliases[name]; ok {
+		return internal, true
+	}
+
+	if r.hidden[name] {
+		return name, false
+	}
+
+	return name, true
+}
+
+// LoadCommandRenames builds the CommandRenames table from the security section of the config.
+func LoadCommandRenames() error {
+	r, err := NewRenames(config.DiceConfig().Security.DisabledCommands, config.DiceConfig().Security.RenamedCommands)
+	if err != nil {
+		return err
+	}
+
+	CommandRenames = r
+	return nil
+}
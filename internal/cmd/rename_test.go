@@ -0,0 +1,70 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenamesResolve(t *testing.T) {
+	r, err := NewRenames([]string{"keys", ""}, []string{"FLUSHDB:admin_flushdb", "DEBUG:", ""})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		ok       bool
+	}{
+		{name: "untouched command", input: "GET", expected: "GET", ok: true},
+		{name: "disabled command", input: "KEYS", expected: "KEYS", ok: false},
+		{name: "renamed command by old name", input: "FLUSHDB", expected: "FLUSHDB", ok: false},
+		{name: "renamed command by new name", input: "ADMIN_FLUSHDB", expected: "FLUSHDB", ok: true},
+		{name: "renamed to empty name", input: "DEBUG", expected: "DEBUG", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, ok := r.Resolve(tt.input)
+			assert.Equal(t, tt.expected, name)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestRenamesSwap(t *testing.T) {
+	r, err := NewRenames(nil, []string{"GET:SET", "SET:GET"})
+	assert.NoError(t, err)
+
+	name, ok := r.Resolve("GET")
+	assert.True(t, ok)
+	assert.Equal(t, "SET", name)
+
+	name, ok = r.Resolve("SET")
+	assert.True(t, ok)
+	assert.Equal(t, "GET", name)
+}
+
+func TestNewRenamesInvalid(t *testing.T) {
+	_, err := NewRenames(nil, []string{"FLUSHDB"})
+	assert.Error(t, err)
+
+	_, err = NewRenames(nil, []string{"FLUSHDB:X", "KEYS:X"})
+	assert.Error(t, err)
+}
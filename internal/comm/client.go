@@ -21,6 +21,7 @@ import (
 	"syscall"
 
 	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/cmd"
 )
 
@@ -34,6 +35,18 @@ type QwatchResponse struct {
 	ClientIdentifierID uint32
 	Result             interface{}
 	Error              error
+	Payload            *WatchPayload // Payload is the encoded result, shared by the responses of every subscriber of the update
+}
+
+// NewQwatchResponses returns the responses of a watch update for each of its subscribers, sharing
+// the payload of the update so that it is encoded only once per format.
+func NewQwatchResponses(result interface{}, clientIdentifierIDs ...uint32) []QwatchResponse {
+	payload := NewWatchPayload(clientio.NewReply(result))
+	responses := make([]QwatchResponse, len(clientIdentifierIDs))
+	for i, id := range clientIdentifierIDs {
+		responses[i] = QwatchResponse{ClientIdentifierID: id, Result: result, Payload: payload}
+	}
+	return responses
 }
 
 type Client struct {
@@ -47,11 +60,11 @@ type Client struct {
 }
 
 func (c *Client) Write(b []byte) (int, error) {
-	return syscall.Write(c.Fd, b)
+	return syscall.Write(handle(c.Fd), b)
 }
 
 func (c *Client) Read(b []byte) (int, error) {
-	return syscall.Read(c.Fd, b)
+	return syscall.Read(handle(c.Fd), b)
 }
 
 func (c *Client) TxnBegin() {
@@ -0,0 +1,64 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package comm
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/dicedb/dice/internal/clientio"
+)
+
+// WatchPayload is the result of a watch update, shared by the responses sent to every subscriber of
+// the update. Each wire format is encoded the first time a frontend asks for it and reused afterwards,
+// so that an update watched by many clients is encoded once per format rather than once per client.
+type WatchPayload struct {
+	reply clientio.Reply // reply is the result, as produced by the shard
+
+	respOnce sync.Once
+	resp     []byte
+
+	jsonOnce sync.Once
+	json     []byte
+	jsonErr  error
+}
+
+// NewWatchPayload returns the payload of the result of a watch update.
+func NewWatchPayload(reply clientio.Reply) *WatchPayload {
+	return &WatchPayload{reply: reply}
+}
+
+// RESP returns the payload encoded for RESP clients.
+func (p *WatchPayload) RESP() []byte {
+	p.respOnce.Do(func() {
+		p.resp = clientio.EncodeReply(p.reply)
+	})
+	return p.resp
+}
+
+// Value returns the payload as rendered in the JSON responses.
+func (p *WatchPayload) Value() interface{} {
+	return p.reply.JSONValue()
+}
+
+// JSON returns the payload encoded for HTTP and WebSocket clients.
+func (p *WatchPayload) JSON() ([]byte, error) {
+	p.jsonOnce.Do(func() {
+		p.json, p.jsonErr = json.Marshal(p.Value())
+	})
+	return p.json, p.jsonErr
+}
@@ -23,3 +23,15 @@ type ITable[K comparable, V any] interface {
 	Len() int
 	All(func(k K, obj V) bool)
 }
+
+// Compactable is implemented by the tables that can give back the memory of the entries they no longer hold,
+// by rebuilding themselves incrementally.
+type Compactable interface {
+	// Capacity returns the number of entries the table holds memory for, at least its length
+	Capacity() int
+	// Compacting reports whether a rebuild is in progress
+	Compacting() bool
+	// Compact moves up to n entries to a table sized for the live ones, starting a rebuild if none is in
+	// progress. It returns the number of entries moved and whether the rebuild is complete
+	Compact(n int) (moved int, done bool)
+}
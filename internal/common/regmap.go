@@ -16,31 +16,79 @@
 
 package common
 
+// RegMap is a table backed by a map. The maps never release the memory of the entries deleted, so that a
+// table that shrank after holding many entries is rebuilt with Compact, a few entries at a time: while a
+// rebuild is in progress, the entries not moved yet are held by old.
 type RegMap[K comparable, V any] struct {
 	M map[K]V
+
+	old     map[K]V // old holds the entries the rebuild in progress did not move yet, nil when none is
+	peak    int     // peak is the largest number of entries M held, the number its memory is sized for
+	oldPeak int     // oldPeak is the peak of old, whose memory is held until the rebuild completes
 }
 
+var _ Compactable = (*RegMap[string, int])(nil)
+
 func (t *RegMap[K, V]) Put(key K, value V) {
+	delete(t.old, key)
 	t.M[key] = value
+	t.peak = max(t.peak, len(t.M))
 }
 
 func (t *RegMap[K, V]) Get(key K) (V, bool) {
 	value, ok := t.M[key]
+	if !ok && t.old != nil {
+		value, ok = t.old[key]
+	}
 	return value, ok
 }
 
 func (t *RegMap[K, V]) Delete(key K) {
 	delete(t.M, key)
+	delete(t.old, key)
 }
 
 func (t *RegMap[K, V]) Len() int {
-	return len(t.M)
+	return len(t.M) + len(t.old)
 }
 
 func (t *RegMap[K, V]) All(f func(k K, obj V) bool) {
 	for k, v := range t.M {
 		if !f(k, v) {
-			break
+			return
+		}
+	}
+	for k, v := range t.old {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+func (t *RegMap[K, V]) Capacity() int {
+	return max(t.peak, len(t.M)) + t.oldPeak
+}
+
+func (t *RegMap[K, V]) Compacting() bool {
+	return t.old != nil
+}
+
+// Compact moves up to n entries to a map sized for the live ones. The entries moved are deleted from the old
+// map, so that the next calls only range over the entries left.
+func (t *RegMap[K, V]) Compact(n int) (moved int, done bool) {
+	if t.old == nil {
+		t.old, t.oldPeak = t.M, max(t.peak, len(t.M))
+		t.M, t.peak = make(map[K]V, len(t.old)), len(t.old)
+	}
+
+	for k, v := range t.old {
+		if moved == n {
+			return moved, false
 		}
+		t.M[k] = v
+		delete(t.old, k)
+		moved++
 	}
+	t.old, t.oldPeak = nil, 0
+	return moved, true
 }
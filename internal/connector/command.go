@@ -0,0 +1,177 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package connector
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// Command is the name of the command managing the connectors.
+const Command = "SINK"
+
+// watchable are the commands whose updates can be published, the ones a client can watch
+var watchable = map[string]bool{dstore.Get: true, dstore.ZRange: true, dstore.PFCOUNT: true}
+
+// Exec executes SINK on the running manager:
+//
+//	SINK CREATE name KIND nats|kafka|webhook URL url [TOPIC topic] [SECRET secret] [FORMAT json|resp] WATCH command [arg ...]
+//	SINK DROP name
+//	SINK LIST
+func Exec(args []string) interface{} {
+	if len(args) == 0 {
+		return diceerrors.ErrWrongArgumentCount(Command)
+	}
+
+	m := active.Load()
+	if m == nil {
+		return errDisabled
+	}
+
+	switch sub := strings.ToUpper(args[0]); sub {
+	case "CREATE":
+		spec, err := ParseArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if err := m.Create(spec); err != nil {
+			return err
+		}
+		return clientio.OK
+	case "DROP":
+		if len(args) != 2 {
+			return diceerrors.ErrWrongArgumentCount(Command + "|" + sub)
+		}
+		if err := m.Drop(args[1]); err != nil {
+			return err
+		}
+		return clientio.OK
+	case "LIST":
+		if len(args) != 1 {
+			return diceerrors.ErrWrongArgumentCount(Command + "|" + sub)
+		}
+		statuses := m.List()
+		reply := make([]interface{}, 0, len(statuses))
+		for i := range statuses {
+			reply = append(reply, describe(&statuses[i]))
+		}
+		return reply
+	default:
+		return diceerrors.ErrGeneral("unknown subcommand '" + args[0] + "'")
+	}
+}
+
+// ParseArgs parses the arguments of SINK CREATE, `name KIND kind URL url [TOPIC topic] [SECRET secret]
+// [FORMAT json|resp] WATCH command [arg ...]`. The updates are encoded as JSON by default. Kafka and NATS
+// require the topic, webhooks the secret the payloads are signed with.
+func ParseArgs(args []string) (Spec, error) {
+	if len(args) == 0 {
+		return Spec{}, diceerrors.ErrWrongArgumentCount(Command + "|CREATE")
+	}
+
+	spec := Spec{Name: args[0], Format: FormatJSON}
+	i := 1
+	for ; i < len(args) && !strings.EqualFold(args[i], "WATCH"); i += 2 {
+		if i+1 == len(args) {
+			return spec, diceerrors.ErrSyntax
+		}
+		value := args[i+1]
+		switch strings.ToUpper(args[i]) {
+		case "KIND":
+			switch kind := Kind(strings.ToLower(value)); kind {
+			case KindNATS, KindKafka, KindWebhook:
+				spec.Kind = kind
+			default:
+				return spec, diceerrors.ErrGeneral("unknown sink kind " + value)
+			}
+		case "URL":
+			if _, err := url.Parse(value); err != nil {
+				return spec, diceerrors.ErrGeneral("invalid sink URL " + value)
+			}
+			spec.URL = value
+		case "TOPIC":
+			spec.Topic = value
+		case "SECRET":
+			spec.Secret = value
+		case "FORMAT":
+			switch format := Format(strings.ToLower(value)); format {
+			case FormatJSON, FormatRESP:
+				spec.Format = format
+			default:
+				return spec, diceerrors.ErrGeneral("unknown format " + value)
+			}
+		default:
+			return spec, diceerrors.ErrSyntax
+		}
+	}
+
+	if spec.Kind == "" || spec.URL == "" {
+		return spec, diceerrors.ErrGeneral("KIND and URL are required")
+	}
+	if spec.Kind == KindWebhook {
+		if spec.Secret == "" {
+			return spec, diceerrors.ErrGeneral("SECRET is required by webhooks")
+		}
+	} else {
+		if spec.Topic == "" {
+			return spec, diceerrors.ErrGeneral("TOPIC is required by " + string(spec.Kind))
+		}
+		if spec.Secret != "" {
+			return spec, diceerrors.ErrGeneral("SECRET is only supported by webhooks")
+		}
+	}
+	// WATCH is followed by the command and its key at least
+	if len(args)-i < 3 {
+		return spec, diceerrors.ErrGeneral("WATCH must be followed by the watched command")
+	}
+	name := strings.ToUpper(args[i+1])
+	if !watchable[name] {
+		return spec, diceerrors.ErrGeneral("command " + args[i+1] + " can not be watched")
+	}
+	spec.Cmd = &cmd.DiceDBCmd{Cmd: name, Args: args[i+2:]}
+	return spec, nil
+}
+
+// describe returns the fields of a connector reported by SINK LIST, the password of its URL redacted and its
+// secret left out.
+func describe(s *Status) []interface{} {
+	sinkURL := s.URL
+	if u, err := url.Parse(s.URL); err == nil {
+		sinkURL = u.Redacted()
+	}
+	status := "active"
+	if s.Disabled {
+		status = "disabled"
+	}
+	return []interface{}{
+		"name", s.Name,
+		"kind", string(s.Kind),
+		"url", sinkURL,
+		"topic", s.Topic,
+		"format", string(s.Format),
+		"watch", strings.Join(append([]string{s.Cmd.Cmd}, s.Cmd.Args...), " "),
+		"published", s.Published,
+		"failed", s.Failed,
+		"last_error", s.LastError,
+		"status", status,
+	}
+}
@@ -0,0 +1,451 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package connector publishes the updates of watched commands to external systems, a Kafka topic, a NATS
+// subject or a webhook, so that downstream pipelines consume the results of reactive queries without holding
+// a connection to the server. A connector subscribes to the watch manager the way a client does, runs the
+// watched command again on every change of its key and publishes the result to its sink.
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/comm"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/querymanager"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/watchmanager"
+)
+
+// Kind is the type of system a connector publishes to.
+type Kind string
+
+const (
+	// KindNATS publishes every update as a message of a NATS subject
+	KindNATS Kind = "nats"
+	// KindKafka publishes every update as a record of a Kafka topic, through a Kafka REST Proxy
+	KindKafka Kind = "kafka"
+	// KindWebhook posts every update to an HTTPS endpoint, signed with the secret of the connector
+	KindWebhook Kind = "webhook"
+)
+
+// maxRetryBackoff caps the wait between two attempts to publish an update
+const maxRetryBackoff = 30 * time.Second
+
+// Format is the encoding of the published updates.
+type Format string
+
+const (
+	// FormatJSON encodes every update as an Update JSON object
+	FormatJSON Format = "json"
+	// FormatRESP encodes every update as the push received by the RESP clients watching the command
+	FormatRESP Format = "resp"
+)
+
+var (
+	errExists   = diceerrors.ErrGeneral("sink already exists")
+	errNotFound = diceerrors.ErrGeneral("no such sink")
+	errDisabled = diceerrors.ErrGeneral("connectors are disabled")
+)
+
+// Spec describes a connector.
+type Spec struct {
+	Name   string
+	Kind   Kind
+	URL    string // URL of the sink, nats://host:port for NATS, the base URL of the REST Proxy for Kafka
+	Topic  string // Topic is the Kafka topic or the NATS subject, unused by webhooks
+	Secret string // Secret is the key the webhook payloads are signed with
+	Format Format
+	Cmd    *cmd.DiceDBCmd // Cmd is the watched command
+}
+
+// Message is an update, encoded for a sink.
+type Message struct {
+	Key   string // Key is the key of the watched command, the key of the Kafka records
+	Value []byte
+}
+
+// Sink is a system the updates are published to.
+type Sink interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// newSink returns the sink of the connector. The sinks connect on their first publication.
+func newSink(spec Spec) (Sink, error) {
+	switch spec.Kind {
+	case KindNATS:
+		return newNATSSink(spec.URL, spec.Topic)
+	case KindKafka:
+		return newKafkaSink(spec.URL, spec.Topic)
+	case KindWebhook:
+		return newWebhookSink(spec)
+	default:
+		return nil, diceerrors.ErrGeneral("unknown sink kind " + string(spec.Kind))
+	}
+}
+
+// Update is an update of a watched command, as published in the JSON format.
+type Update struct {
+	Sink        string          `json:"sink"`
+	Command     string          `json:"command"`
+	Args        []string        `json:"args"`
+	Fingerprint uint32          `json:"fingerprint"`
+	Seq         uint64          `json:"seq"` // Seq is the sequence number of the update, 0 for the initial result
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// Status is a connector, as reported by SINK LIST.
+type Status struct {
+	Spec
+	Published uint64 // Published is the number of updates published
+	Failed    uint64 // Failed is the number of updates that could not be published
+	LastError string
+	Disabled  bool // Disabled is set once the connector stopped publishing after repeated failures
+}
+
+// Options configures the publication of the updates.
+type Options struct {
+	Timeout      time.Duration // Timeout is the time after which an attempt to publish an update is given up on
+	Retries      int           // Retries is the number of times the publication of an update is attempted again
+	RetryBackoff time.Duration // RetryBackoff is the wait before the first retry, doubled before every next one
+	MaxFailures  int           // MaxFailures is the number of updates failing in a row disabling the connector, 0 for never
+}
+
+// executor runs a command on the shard owning its key
+type executor func(ctx context.Context, c *cmd.DiceDBCmd) (*eval.EvalResponse, error)
+
+// active is the manager serving SINK, nil while no manager is running
+var active atomic.Pointer[Manager]
+
+// Manager runs the connectors created by SINK CREATE. The connectors are kept in memory only, they are lost
+// on restart.
+type Manager struct {
+	exec          executor
+	subscriptions chan watchmanager.WatchSubscription
+	opts          Options
+	newSink       func(spec Spec) (Sink, error)
+
+	mu         sync.Mutex
+	ctx        context.Context // ctx is the context of Run, nil until the manager runs
+	connectors map[string]*connector
+}
+
+// NewManager returns a manager of the connectors, subscribing through the subscription channel of the watch
+// manager and running the watched commands on the shards of the shard manager.
+func NewManager(manager *shard.ShardManager, subscriptions chan watchmanager.WatchSubscription, opts Options) *Manager {
+	return &Manager{
+		exec: func(ctx context.Context, c *cmd.DiceDBCmd) (*eval.EvalResponse, error) {
+			id, _ := manager.GetShardInfo(c.GetKey())
+			resps, err := manager.ExecBatch(ctx, &shard.Batch{ClientAddr: "connector", Cmds: map[uint8][]*cmd.DiceDBCmd{id: {c}}})
+			if err != nil {
+				return nil, err
+			}
+			return resps[id][0], nil
+		},
+		subscriptions: subscriptions,
+		opts:          opts,
+		newSink:       newSink,
+		connectors:    make(map[string]*connector),
+	}
+}
+
+// Run serves SINK until the context is canceled, then stops the connectors.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+	active.Store(m)
+
+	<-ctx.Done()
+
+	active.CompareAndSwap(m, nil)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, c := range m.connectors {
+		// The watch manager stops along, the subscriptions are not removed
+		c.stop()
+		delete(m.connectors, name)
+	}
+	return nil
+}
+
+// Create starts a connector publishing the updates of its command, starting with the current result.
+func (m *Manager) Create(spec Spec) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ctx == nil || m.ctx.Err() != nil {
+		return errDisabled
+	}
+	if _, ok := m.connectors[spec.Name]; ok {
+		return errExists
+	}
+
+	sink, err := m.newSink(spec)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	c := &connector{
+		spec:          spec,
+		sink:          sink,
+		exec:          m.exec,
+		opts:          m.opts,
+		subscriptions: m.subscriptions,
+		notifications: make(chan watchmanager.Notification),
+		changed:       make(chan struct{}, 1),
+		cancel:        cancel,
+		done:          make(chan struct{}),
+	}
+	m.connectors[spec.Name] = c
+
+	// The notifications are drained from the subscription on, so that the watch manager never waits for the sink
+	go c.drain(ctx)
+	m.subscriptions <- watchmanager.WatchSubscription{
+		Subscribe:    true,
+		WatchCmd:     spec.Cmd,
+		AdhocReqChan: c.notifications,
+	}
+	go c.run(ctx)
+	return nil
+}
+
+// Drop stops the connector and closes its sink.
+func (m *Manager) Drop(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.connectors[name]
+	if !ok {
+		return errNotFound
+	}
+	delete(m.connectors, name)
+
+	// The notifications are drained until the watch manager removes the subscription, as it may be sending one
+	m.subscriptions <- watchmanager.WatchSubscription{
+		Subscribe:    false,
+		AdhocReqChan: c.notifications,
+		Fingerprint:  c.spec.Cmd.GetFingerprint(),
+	}
+	c.stop()
+	return nil
+}
+
+// List returns the connectors, ordered by name.
+func (m *Manager) List() []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]Status, 0, len(m.connectors))
+	for _, c := range m.connectors {
+		statuses = append(statuses, c.status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// connector publishes the updates of a watched command to its sink
+type connector struct {
+	spec          Spec
+	sink          Sink
+	exec          executor
+	opts          Options
+	subscriptions chan watchmanager.WatchSubscription
+	notifications chan watchmanager.Notification // notifications are the changes of the key sent by the watch manager
+	changed       chan struct{}                  // changed holds a change not published yet, the changes are coalesced
+	cancel        context.CancelFunc
+	done          chan struct{} // done is closed once the connector stopped publishing
+	seq           uint64        // seq is the sequence number of the last update, only used by run
+	failures      int           // failures is the number of updates failing in a row, only used by run
+
+	published atomic.Uint64
+	failed    atomic.Uint64
+	disabled  atomic.Bool
+	mu        sync.Mutex
+	lastError string
+}
+
+// drain coalesces the notifications of the watch manager until the connector stops.
+func (c *connector) drain(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.notifications:
+			select {
+			case c.changed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// run publishes the current result of the command, then its result after every change.
+func (c *connector) run(ctx context.Context) {
+	defer close(c.done)
+
+	c.publish(ctx)
+	for !c.disabled.Load() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.changed:
+			// The sequence number is taken even if the publication fails, so that the consumers see the gap
+			c.seq++
+			c.publish(ctx)
+		}
+	}
+}
+
+// publish runs the command and publishes its result. The connector is disabled once too many updates failed
+// in a row.
+func (c *connector) publish(ctx context.Context) {
+	err := c.deliver(ctx)
+	if err == nil {
+		c.failures = 0
+		c.published.Add(1)
+		return
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// The connector is stopped
+		return
+	}
+
+	c.failures++
+	c.failed.Add(1)
+	c.mu.Lock()
+	c.lastError = err.Error()
+	c.mu.Unlock()
+	slog.Warn("could not publish an update to a sink", slog.String("sink", c.spec.Name), slog.Any("error", err))
+
+	if c.opts.MaxFailures > 0 && c.failures >= c.opts.MaxFailures {
+		c.disable(ctx)
+	}
+}
+
+// deliver publishes the current result of the command, attempting again with an exponential backoff as long
+// as the publication fails and retries are left.
+func (c *connector) deliver(ctx context.Context) error {
+	execCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	resp, err := c.exec(execCtx, c.spec.Cmd)
+	cancel()
+	if err != nil {
+		return err
+	}
+	value, err := encode(c.spec, c.seq, resp)
+	if err != nil {
+		return err
+	}
+	msg := Message{Key: c.spec.Cmd.GetKey(), Value: value}
+
+	backoff := c.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+		err = c.sink.Publish(attemptCtx, msg)
+		cancel()
+		if err == nil || attempt == c.opts.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// disable stops publishing, the connector staying listed until it is dropped.
+func (c *connector) disable(ctx context.Context) {
+	c.disabled.Store(true)
+	slog.Warn("sink disabled after repeated delivery failures", slog.String("sink", c.spec.Name), slog.Int("failures", c.failures))
+
+	select {
+	case <-ctx.Done():
+	case c.subscriptions <- watchmanager.WatchSubscription{
+		Subscribe:    false,
+		AdhocReqChan: c.notifications,
+		Fingerprint:  c.spec.Cmd.GetFingerprint(),
+	}:
+	}
+}
+
+// stop stops publishing and closes the sink.
+func (c *connector) stop() {
+	c.cancel()
+	<-c.done
+	if err := c.sink.Close(); err != nil {
+		slog.Warn("could not close a sink", slog.String("sink", c.spec.Name), slog.Any("error", err))
+	}
+}
+
+func (c *connector) status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Status{
+		Spec:      c.spec,
+		Published: c.published.Load(),
+		Failed:    c.failed.Load(),
+		LastError: c.lastError,
+		Disabled:  c.disabled.Load(),
+	}
+}
+
+// encode encodes the result of the command in the format of the connector.
+func encode(spec Spec, seq uint64, resp *eval.EvalResponse) ([]byte, error) {
+	result := resp.Result
+	if resp.Error != nil {
+		result = resp.Error
+	}
+
+	fingerprint := spec.Cmd.GetFingerprint()
+	if spec.Format == FormatRESP {
+		return clientio.Encode(querymanager.GenericWatchResponse(spec.Cmd.Cmd, strconv.FormatUint(uint64(fingerprint), 10), result, seq), false), nil
+	}
+
+	update := Update{
+		Sink:        spec.Name,
+		Command:     spec.Cmd.Cmd,
+		Args:        spec.Cmd.Args,
+		Fingerprint: fingerprint,
+		Seq:         seq,
+	}
+	if resp.Error != nil {
+		update.Error = resp.Error.Error()
+	} else {
+		value, err := comm.NewWatchPayload(clientio.NewReply(result)).JSON()
+		if err != nil {
+			return nil, err
+		}
+		update.Result = value
+	}
+	return json.Marshal(update)
+}
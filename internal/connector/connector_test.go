@@ -0,0 +1,357 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package connector
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/shard"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/watchmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    Spec
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			args: "s1 KIND nats URL nats://localhost TOPIC updates WATCH get k",
+			want: Spec{Name: "s1", Kind: KindNATS, URL: "nats://localhost", Topic: "updates", Format: FormatJSON,
+				Cmd: &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}},
+		},
+		{
+			name: "format",
+			args: "s1 kind KAFKA url http://proxy:8082 topic t format RESP watch ZRANGE z 0 -1",
+			want: Spec{Name: "s1", Kind: KindKafka, URL: "http://proxy:8082", Topic: "t", Format: FormatRESP,
+				Cmd: &cmd.DiceDBCmd{Cmd: "ZRANGE", Args: []string{"z", "0", "-1"}}},
+		},
+		{
+			name: "webhook",
+			args: "s1 KIND webhook URL https://example.com/hook SECRET s3cr3t WATCH PFCOUNT h",
+			want: Spec{Name: "s1", Kind: KindWebhook, URL: "https://example.com/hook", Secret: "s3cr3t", Format: FormatJSON,
+				Cmd: &cmd.DiceDBCmd{Cmd: "PFCOUNT", Args: []string{"h"}}},
+		},
+		{name: "missing URL", args: "s1 KIND nats TOPIC t WATCH GET k", wantErr: "ERR KIND and URL are required"},
+		{name: "missing topic", args: "s1 KIND nats URL nats://localhost WATCH GET k", wantErr: "ERR TOPIC is required by nats"},
+		{name: "missing secret", args: "s1 KIND webhook URL https://example.com WATCH GET k", wantErr: "ERR SECRET is required by webhooks"},
+		{name: "secret without webhook", args: "s1 KIND kafka URL http://proxy TOPIC t SECRET s WATCH GET k", wantErr: "ERR SECRET is only supported by webhooks"},
+		{name: "missing command", args: "s1 KIND nats URL nats://localhost TOPIC t WATCH GET", wantErr: "ERR WATCH must be followed by the watched command"},
+		{name: "unwatchable command", args: "s1 KIND nats URL nats://localhost TOPIC t WATCH SET k v", wantErr: "ERR command SET can not be watched"},
+		{name: "unknown kind", args: "s1 KIND amqp URL amqp://localhost TOPIC t WATCH GET k", wantErr: "ERR unknown sink kind amqp"},
+		{name: "unknown format", args: "s1 KIND nats URL nats://localhost TOPIC t FORMAT xml WATCH GET k", wantErr: "ERR unknown format xml"},
+		{name: "missing value", args: "s1 KIND", wantErr: "ERR syntax error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArgs(strings.Fields(tt.args))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fakeSink records the messages published, failing to publish them while err is set
+type fakeSink struct {
+	messages chan Message
+	err      error
+}
+
+func (s *fakeSink) Publish(_ context.Context, msg Message) error {
+	s.messages <- msg
+	return s.err
+}
+
+func (s *fakeSink) Close() error {
+	return nil
+}
+
+// startManager runs a manager publishing to the sink, along with the shards and the watch manager
+func startManager(t *testing.T, opts Options, sink Sink) (*Manager, *shard.ShardManager) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+
+	watchChan := make(chan dstore.CmdWatchEvent, 16)
+	subscriptions := make(chan watchmanager.WatchSubscription)
+	shards := shard.NewShardManager(2, watchChan, make(chan error, 1))
+	run(func() { shards.Run(ctx) })
+	run(func() { watchmanager.NewManager(subscriptions, watchChan).Run(ctx) })
+
+	m := NewManager(shards, subscriptions, opts)
+	m.newSink = func(Spec) (Sink, error) { return sink, nil }
+	run(func() { _ = m.Run(ctx) })
+	require.Eventually(t, func() bool { return active.Load() == m }, time.Second, time.Millisecond)
+	return m, shards
+}
+
+// set sets the key through the shards
+func set(t *testing.T, shards *shard.ShardManager, key, value string) {
+	id, _ := shards.GetShardInfo(key)
+	_, err := shards.ExecBatch(context.Background(), &shard.Batch{Cmds: map[uint8][]*cmd.DiceDBCmd{id: {{Cmd: "SET", Args: []string{key, value}}}}})
+	require.NoError(t, err)
+}
+
+func TestManager(t *testing.T) {
+	sink := &fakeSink{messages: make(chan Message, 16)}
+	_, shards := startManager(t, Options{Timeout: time.Second}, sink)
+
+	next := func() Update {
+		select {
+		case msg := <-sink.messages:
+			assert.Equal(t, "k", msg.Key)
+			var update Update
+			require.NoError(t, json.Unmarshal(msg.Value, &update))
+			return update
+		case <-time.After(5 * time.Second):
+			t.Fatal("no update published")
+			return Update{}
+		}
+	}
+
+	assert.Equal(t, clientio.OK, Exec(strings.Fields("CREATE s1 KIND nats URL nats://localhost TOPIC t WATCH GET k")))
+	assert.EqualError(t, Exec(strings.Fields("CREATE s1 KIND nats URL nats://localhost TOPIC t WATCH GET k")).(error), "ERR sink already exists")
+
+	// The current result is published first, then the result after every change
+	update := next()
+	assert.Equal(t, "s1", update.Sink)
+	assert.Equal(t, "GET", update.Command)
+	assert.Equal(t, []string{"k"}, update.Args)
+	assert.EqualValues(t, 0, update.Seq)
+	assert.JSONEq(t, "null", string(update.Result))
+
+	set(t, shards, "k", "v1")
+	update = next()
+	assert.EqualValues(t, 1, update.Seq)
+	assert.JSONEq(t, `"v1"`, string(update.Result))
+
+	list := Exec([]string{"LIST"}).([]interface{})
+	require.Len(t, list, 1)
+	fields := list[0].([]interface{})
+	assert.Equal(t, []interface{}{"name", "s1"}, fields[:2])
+	assert.Equal(t, "GET k", fields[11])
+
+	// Once dropped, the changes are not published anymore
+	assert.Equal(t, clientio.OK, Exec([]string{"DROP", "s1"}))
+	assert.EqualError(t, Exec([]string{"DROP", "s1"}).(error), "ERR no such sink")
+	set(t, shards, "k", "v2")
+	select {
+	case msg := <-sink.messages:
+		t.Fatalf("update published after the sink was dropped: %s", msg.Value)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDisable(t *testing.T) {
+	sink := &fakeSink{messages: make(chan Message, 16), err: errors.New("unreachable")}
+	_, shards := startManager(t, Options{Timeout: time.Second, Retries: 1, RetryBackoff: time.Millisecond, MaxFailures: 2}, sink)
+
+	attempts := func(n int) {
+		for i := 0; i < n; i++ {
+			select {
+			case <-sink.messages:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("attempt %d not made", i+1)
+			}
+		}
+	}
+
+	// Every update is attempted once, then retried once
+	assert.Equal(t, clientio.OK, Exec(strings.Fields("CREATE s1 KIND nats URL nats://localhost TOPIC t WATCH GET k")))
+	attempts(2)
+
+	// The second update failing in a row disables the connector
+	set(t, shards, "k", "v1")
+	attempts(2)
+	require.Eventually(t, func() bool {
+		fields := Exec([]string{"LIST"}).([]interface{})[0].([]interface{})
+		return fields[19] == "disabled"
+	}, 5*time.Second, time.Millisecond)
+
+	fields := Exec([]string{"LIST"}).([]interface{})[0].([]interface{})
+	assert.EqualValues(t, 0, fields[13])
+	assert.EqualValues(t, 2, fields[15])
+	assert.Equal(t, "unreachable", fields[17])
+
+	set(t, shards, "k", "v2")
+	select {
+	case <-sink.messages:
+		t.Fatal("update published after the sink was disabled")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, clientio.OK, Exec([]string{"DROP", "s1"}))
+}
+
+func TestEncodeRESP(t *testing.T) {
+	spec := Spec{Name: "s1", Format: FormatRESP, Cmd: &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}}
+	value, err := encode(spec, 3, &eval.EvalResponse{Result: "v"})
+	require.NoError(t, err)
+
+	fingerprint := spec.Cmd.GetFingerprint()
+	assert.Equal(t, string(clientio.Encode([]interface{}{"GET", strconv.FormatUint(uint64(fingerprint), 10), "v", uint64(3)}, false)), string(value))
+}
+
+func TestNATSSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	published := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "CONNECT "):
+				if !strings.Contains(line, `"user":"alice"`) {
+					_, _ = conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+					return
+				}
+			case strings.HasPrefix(line, "PUB "):
+				payload, _ := r.ReadString('\n')
+				published <- line + payload
+			case line == "PING\r\n":
+				_, _ = conn.Write([]byte("PONG\r\n"))
+			}
+		}
+	}()
+
+	s, err := newNATSSink("nats://alice:secret@"+ln.Addr().String(), "updates")
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, s.Publish(ctx, Message{Key: "k", Value: []byte("hello")}))
+	assert.Equal(t, "PUB updates 5\r\nhello\r\n", <-published)
+
+	_, err = newNATSSink("http://localhost", "updates")
+	assert.Error(t, err)
+}
+
+func TestKafkaSink(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/updates", r.URL.Path)
+		assert.Equal(t, kafkaRecordsContentType, r.Header.Get("Content-Type"))
+		body, _ = io.ReadAll(r.Body)
+		if strings.Contains(string(body), "ZmFpbA==") { // "fail", base64 encoded
+			_, _ = w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"leader not available"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":42}]}`))
+	}))
+	defer srv.Close()
+
+	s, err := newKafkaSink(srv.URL+"/", "updates")
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+	require.NoError(t, s.Publish(ctx, Message{Key: "k", Value: []byte("hello")}))
+	assert.JSONEq(t, `{"records":[{"key":"aw==","value":"aGVsbG8="}]}`, string(body))
+
+	assert.EqualError(t, s.Publish(ctx, Message{Key: "k", Value: []byte("fail")}),
+		"kafka could not produce the record: leader not available (error code 50002)")
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	var body []byte
+	status := http.StatusNoContent
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	s, err := newWebhookSink(Spec{Name: "s1", URL: srv.URL + "/hook", Secret: "s3cr3t", Format: FormatJSON})
+	require.NoError(t, err)
+	s.client = srv.Client()
+	defer s.Close()
+
+	ctx := context.Background()
+	require.NoError(t, s.Publish(ctx, Message{Key: "k", Value: []byte(`{"seq":0}`)}))
+	r := <-received
+	assert.Equal(t, "/hook", r.URL.Path)
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	assert.Equal(t, "s1", r.Header.Get(webhookSinkHeader))
+	assert.Equal(t, `{"seq":0}`, string(body))
+
+	// The signature is checked the way a receiver does
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte(r.Header.Get(webhookTimestampHeader) + "." + string(body)))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(webhookSignatureHeader))
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, s.Publish(ctx, Message{Key: "k", Value: []byte(`{"seq":1}`)}), "webhook replied 503 Service Unavailable")
+	<-received
+
+	_, err = newWebhookSink(Spec{Name: "s1", URL: "http://example.com/hook", Secret: "s3cr3t"})
+	assert.Error(t, err)
+}
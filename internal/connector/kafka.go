@@ -0,0 +1,118 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// Content types of the v2 API of the Kafka REST Proxy
+const (
+	kafkaRecordsContentType = "application/vnd.kafka.binary.v2+json"
+	kafkaAcceptContentType  = "application/vnd.kafka.v2+json"
+)
+
+// kafkaSink publishes the updates as records of a Kafka topic through the v2 API of a Kafka REST Proxy. The
+// records are keyed by the key of the watched command, so that the updates of a key land in the same partition
+// and keep their order.
+type kafkaSink struct {
+	endpoint string // endpoint is the URL the records of the topic are posted to
+	client   *http.Client
+}
+
+// kafkaRecord is a record of a produce request, its key and value base64 encoded
+type kafkaRecord struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// kafkaOffset is the outcome of the production of a record
+type kafkaOffset struct {
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+	ErrorCode int    `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+func newKafkaSink(rawURL, topic string) (*kafkaSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, diceerrors.ErrGeneral("invalid Kafka REST Proxy URL " + rawURL + ", expected http(s)://host:port")
+	}
+	return &kafkaSink{
+		endpoint: strings.TrimSuffix(u.String(), "/") + "/topics/" + url.PathEscape(topic),
+		client:   &http.Client{},
+	}, nil
+}
+
+func (s *kafkaSink) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: []byte(msg.Key), Value: msg.Value}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaRecordsContentType)
+	req.Header.Set("Accept", kafkaAcceptContentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka REST proxy replied %s: %s", resp.Status, bytes.TrimSpace(payload))
+	}
+
+	// The production of every record may fail on its own
+	var result struct {
+		Offsets []kafkaOffset `json:"offsets"`
+	}
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return fmt.Errorf("invalid reply from the kafka REST proxy: %w", err)
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != 0 || offset.Error != "" {
+			return fmt.Errorf("kafka could not produce the record: %s (error code %d)", offset.Error, offset.ErrorCode)
+		}
+	}
+	return nil
+}
+
+func (s *kafkaSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
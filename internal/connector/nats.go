@@ -0,0 +1,191 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package connector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// natsPort is the port of the NATS servers given without one
+const natsPort = "4222"
+
+// natsSink publishes the updates to a NATS subject over the text protocol of NATS. Every publication is
+// followed by a PING, the publication succeeding once the server answers the PONG, i.e. once it processed
+// the message.
+type natsSink struct {
+	addr     string
+	subject  string
+	user     string
+	password string
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newNATSSink(rawURL, subject string) (*natsSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "nats" || u.Host == "" {
+		return nil, diceerrors.ErrGeneral("invalid NATS URL " + rawURL + ", expected nats://host:port")
+	}
+	if strings.ContainsAny(subject, " \t\r\n") {
+		return nil, diceerrors.ErrGeneral("invalid NATS subject " + subject)
+	}
+
+	s := &natsSink{addr: u.Host, subject: subject}
+	if u.Port() == "" {
+		s.addr = net.JoinHostPort(u.Hostname(), natsPort)
+	}
+	if u.User != nil {
+		s.user = u.User.Username()
+		s.password, _ = u.User.Password()
+	}
+	return s, nil
+}
+
+// Publish publishes the message, connecting again once if the connection was lost.
+func (s *natsSink) Publish(ctx context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if err = s.connect(ctx); err != nil {
+				return err
+			}
+		}
+		if err = s.publish(ctx, msg.Value); err == nil {
+			return nil
+		}
+		s.disconnect()
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return err
+}
+
+func (s *natsSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disconnect()
+	return nil
+}
+
+// connect connects to the server and introduces the client.
+func (s *natsSink) connect(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.conn, s.r = conn, bufio.NewReader(conn)
+	s.setDeadline(ctx)
+
+	// The server introduces itself first
+	line, err := s.readLine()
+	if err == nil && !strings.HasPrefix(line, "INFO ") {
+		err = fmt.Errorf("unexpected greeting from the NATS server: %q", line)
+	}
+	if err != nil {
+		s.disconnect()
+		return err
+	}
+
+	options, err := json.Marshal(map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "dicedb",
+		"lang":     "go",
+		"user":     s.user,
+		"pass":     s.password,
+	})
+	if err != nil {
+		s.disconnect()
+		return err
+	}
+	if err := s.roundTrip("CONNECT " + string(options) + "\r\n"); err != nil {
+		s.disconnect()
+		return err
+	}
+	return nil
+}
+
+func (s *natsSink) publish(ctx context.Context, payload []byte) error {
+	s.setDeadline(ctx)
+	return s.roundTrip(fmt.Sprintf("PUB %s %d\r\n%s\r\n", s.subject, len(payload), payload))
+}
+
+// roundTrip sends the request followed by a PING and waits for the PONG, reporting the errors of the server.
+func (s *natsSink) roundTrip(request string) error {
+	if _, err := s.conn.Write([]byte(request + "PING\r\n")); err != nil {
+		return err
+	}
+
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("NATS server error: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK and the INFO updates of the server are ignored
+	}
+}
+
+func (s *natsSink) readLine() (string, error) {
+	line, err := s.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (s *natsSink) setDeadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	_ = s.conn.SetDeadline(deadline)
+}
+
+func (s *natsSink) disconnect() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn, s.r = nil, nil
+	}
+}
@@ -0,0 +1,109 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// Headers of the webhook requests. The signature is the hex encoded HMAC-SHA256, keyed by the secret of the
+// connector, of the timestamp, a dot and the body: receivers check it, and that the timestamp is recent, to
+// reject forged and replayed requests.
+const (
+	webhookSinkHeader      = "X-DiceDB-Sink"
+	webhookTimestampHeader = "X-DiceDB-Timestamp"
+	webhookSignatureHeader = "X-DiceDB-Signature"
+)
+
+// webhookSink posts the updates to an HTTPS endpoint. Any status other than 2xx is a failed delivery.
+type webhookSink struct {
+	name        string
+	url         string
+	secret      []byte
+	contentType string
+	client      *http.Client
+}
+
+func newWebhookSink(spec Spec) (*webhookSink, error) {
+	u, err := url.Parse(spec.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, diceerrors.ErrGeneral("invalid webhook URL " + spec.URL + ", expected https://host/path")
+	}
+
+	contentType := "application/json"
+	if spec.Format == FormatRESP {
+		contentType = "application/octet-stream"
+	}
+	return &webhookSink{
+		name:        spec.Name,
+		url:         spec.URL,
+		secret:      []byte(spec.Secret),
+		contentType: contentType,
+		client:      &http.Client{},
+	}, nil
+}
+
+func (s *webhookSink) Publish(ctx context.Context, msg Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(msg.Value))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", s.contentType)
+	req.Header.Set(webhookSinkHeader, s.name)
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	req.Header.Set(webhookSignatureHeader, "sha256="+sign(s.secret, timestamp, msg.Value))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The body is read so that the connection is reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook replied %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// sign returns the signature of the body sent at the timestamp.
+func sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
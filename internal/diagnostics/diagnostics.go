@@ -0,0 +1,294 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package diagnostics implements the self-check of the server environment run at startup and by
+// DEBUG QUICK: config consistency, availability of the listening ports, writability of the
+// persistence directories, memory limits against the cgroup limits and resolution of the clock.
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/go-playground/validator/v10"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Check is the outcome of one of the checks along with a human readable explanation.
+type Check struct {
+	Name   string
+	Status Status
+	Detail string
+}
+
+// Report is the outcome of every check, in the order they were run.
+type Report []Check
+
+// Options selects the checks to run.
+type Options struct {
+	// CheckPorts checks that the listening ports can be bound, which only makes sense
+	// before the server binds them itself.
+	CheckPorts bool
+}
+
+// cgroup files holding the memory limit of the process, for cgroup v2 and v1
+var cgroupMemoryLimitFiles = []string{
+	"/sys/fs/cgroup/memory.max",
+	"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+}
+
+// maxClockResolution is the coarsest clock resolution that does not skew expirations and latency measurements
+const maxClockResolution = time.Millisecond
+
+type listener struct {
+	name string
+	addr string
+	port int
+}
+
+// Run runs the checks selected by opts and returns their outcome.
+func Run(opts Options) Report {
+	report := Report{checkConfig()}
+	if opts.CheckPorts {
+		report = append(report, checkPorts())
+	}
+	return append(report,
+		checkDirectories(),
+		checkMemory(),
+		checkClock(),
+	)
+}
+
+// Failed reports whether any of the checks failed.
+func (r Report) Failed() bool {
+	for _, c := range r {
+		if c.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Log emits a structured record for every check, at a level matching its status.
+func (r Report) Log() {
+	for _, c := range r {
+		level := slog.LevelInfo
+		switch c.Status {
+		case StatusWarn:
+			level = slog.LevelWarn
+		case StatusFail:
+			level = slog.LevelError
+		}
+		slog.Log(context.Background(), level, "diagnostics",
+			slog.String("check", c.Name), slog.String("status", string(c.Status)), slog.String("detail", c.Detail))
+	}
+}
+
+// listeners returns the listeners enabled by the config.
+func listeners() []listener {
+	cfg := config.DiceConfig()
+	l := []listener{{name: "resp", addr: cfg.RespServer.Addr, port: cfg.RespServer.Port}}
+	if cfg.TLS.Port != 0 {
+		l = append(l, listener{name: "tls", addr: cfg.RespServer.Addr, port: cfg.TLS.Port})
+	}
+	if cfg.HTTP.Enabled {
+		l = append(l, listener{name: "http", addr: cfg.HTTP.Addr, port: cfg.HTTP.Port})
+	}
+	if cfg.WebSocket.Enabled {
+		l = append(l, listener{name: "websocket", addr: cfg.WebSocket.Addr, port: cfg.WebSocket.Port})
+	}
+	if cfg.Metrics.Enabled {
+		l = append(l, listener{name: "metrics", addr: cfg.Metrics.Addr, port: cfg.Metrics.Port})
+	}
+	return l
+}
+
+func checkConfig() Check {
+	c := Check{Name: "config", Status: StatusOK, Detail: "config is consistent"}
+
+	// Invalid values are replaced by their default when the config is loaded, so they are only worth a warning
+	var validationErrs validator.ValidationErrors
+	if err := config.Check(config.DiceConfig()); errors.As(err, &validationErrs) {
+		fields := make([]string, 0, len(validationErrs))
+		for _, e := range validationErrs {
+			fields = append(fields, fmt.Sprintf("%s (%s)", strings.TrimPrefix(e.Namespace(), "Config."), e.Tag()))
+		}
+		c = Check{Name: c.Name, Status: StatusWarn, Detail: "invalid fields: " + strings.Join(fields, ", ")}
+	} else if err != nil {
+		return Check{Name: c.Name, Status: StatusFail, Detail: err.Error()}
+	}
+
+	ports := make(map[int]string)
+	for _, l := range listeners() {
+		if other, ok := ports[l.port]; ok {
+			return Check{Name: c.Name, Status: StatusFail,
+				Detail: fmt.Sprintf("%s and %s listeners share port %d", other, l.name, l.port)}
+		}
+		ports[l.port] = l.name
+	}
+	return c
+}
+
+func checkPorts() Check {
+	var unavailable []string
+	for _, l := range listeners() {
+		ln, err := net.Listen("tcp", net.JoinHostPort(l.addr, strconv.Itoa(l.port)))
+		if err != nil {
+			unavailable = append(unavailable, fmt.Sprintf("%s port %d: %v", l.name, l.port, err))
+			continue
+		}
+		ln.Close()
+	}
+
+	if len(unavailable) > 0 {
+		return Check{Name: "ports", Status: StatusFail, Detail: strings.Join(unavailable, "; ")}
+	}
+	return Check{Name: "ports", Status: StatusOK, Detail: "every listening port is available"}
+}
+
+// directories returns the directories the server writes to as configured.
+func directories() []string {
+	var dirs []string
+	if config.DiceConfig().Persistence.Enabled {
+		dirs = append(dirs, config.DiceConfig().WAL.LogDir, filepath.Dir(config.DiceConfig().Persistence.AOFFile))
+	}
+	if config.DiceConfig().Audit.Enabled && config.DiceConfig().Audit.Output == audit.OutputFile {
+		dirs = append(dirs, filepath.Dir(config.DiceConfig().Audit.FilePath))
+	}
+	return dirs
+}
+
+func checkDirectories() Check {
+	dirs := directories()
+	if len(dirs) == 0 {
+		return Check{Name: "directories", Status: StatusOK, Detail: "nothing is persisted"}
+	}
+
+	var unwritable []string
+	for _, dir := range dirs {
+		if err := checkWritable(dir); err != nil {
+			unwritable = append(unwritable, fmt.Sprintf("%s: %v", dir, err))
+		}
+	}
+
+	if len(unwritable) > 0 {
+		return Check{Name: "directories", Status: StatusFail, Detail: strings.Join(unwritable, "; ")}
+	}
+	return Check{Name: "directories", Status: StatusOK, Detail: "writable: " + strings.Join(dirs, ", ")}
+}
+
+// checkWritable creates the directory if needed, as the server does, and writes a file in it.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".dicedb-diagnostics-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// cgroupMemoryLimit returns the memory limit of the cgroup of the process in bytes, 0 if there is none.
+func cgroupMemoryLimit() (uint64, error) {
+	for _, path := range cgroupMemoryLimitFiles {
+		b, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+
+		value := strings.TrimSpace(string(b))
+		if value == "max" {
+			return 0, nil
+		}
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse %s: %w", path, err)
+		}
+		// cgroup v1 reports the absence of limit as a huge page aligned value
+		if limit >= 1<<62 {
+			return 0, nil
+		}
+		return limit, nil
+	}
+	return 0, nil
+}
+
+func checkMemory() Check {
+	maxMemory := uint64(config.DiceConfig().Memory.MaxMemory)
+	limit, err := cgroupMemoryLimit()
+	switch {
+	case err != nil:
+		return Check{Name: "memory", Status: StatusWarn, Detail: err.Error()}
+	case limit == 0:
+		return Check{Name: "memory", Status: StatusOK, Detail: "no cgroup memory limit"}
+	case maxMemory > limit:
+		return Check{Name: "memory", Status: StatusFail,
+			Detail: fmt.Sprintf("max_memory of %d bytes exceeds the cgroup memory limit of %d bytes", maxMemory, limit)}
+	case maxMemory == 0:
+		return Check{Name: "memory", Status: StatusWarn,
+			Detail: fmt.Sprintf("max_memory is not set while the cgroup memory limit is %d bytes", limit)}
+	default:
+		return Check{Name: "memory", Status: StatusOK,
+			Detail: fmt.Sprintf("max_memory of %d bytes fits the cgroup memory limit of %d bytes", maxMemory, limit)}
+	}
+}
+
+// clockResolution returns the smallest step observed between successive readings of the clock.
+func clockResolution() time.Duration {
+	resolution := time.Duration(0)
+	for i := 0; i < 100; i++ {
+		start := time.Now()
+		next := time.Now()
+		for next.Equal(start) {
+			next = time.Now()
+		}
+		if step := next.Sub(start); resolution == 0 || step < resolution {
+			resolution = step
+		}
+	}
+	return resolution
+}
+
+func checkClock() Check {
+	resolution := clockResolution()
+	if resolution > maxClockResolution {
+		return Check{Name: "clock", Status: StatusWarn,
+			Detail: fmt.Sprintf("clock resolution of %s is coarser than %s", resolution, maxClockResolution)}
+	}
+	return Check{Name: "clock", Status: StatusOK, Detail: fmt.Sprintf("clock resolution is %s", resolution)}
+}
@@ -0,0 +1,56 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConfigPortConflict(t *testing.T) {
+	previous := config.DiceConfig().Metrics
+	t.Cleanup(func() { config.DiceConfig().Metrics = previous })
+
+	config.DiceConfig().Metrics.Enabled = true
+	config.DiceConfig().Metrics.Port = config.DiceConfig().RespServer.Port
+
+	c := checkConfig()
+	assert.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Detail, "resp and metrics listeners share port")
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "wal")
+	assert.NoError(t, checkWritable(dir))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, nil, 0o600))
+	assert.Error(t, checkWritable(file))
+}
+
+func TestReportFailed(t *testing.T) {
+	assert.False(t, Report{{Name: "clock", Status: StatusWarn}}.Failed())
+	assert.True(t, Report{{Name: "clock", Status: StatusOK}, {Name: "memory", Status: StatusFail}}.Failed())
+}
@@ -0,0 +1,121 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package errors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Code is the stable identifier of an error replied by a command, for the clients to tell the errors apart
+// without parsing their messages. RESP replies the error prefixed with its RESP error code, the codes DiceDB
+// refines the ERR errors of Redis with being replied as ERR to keep the Redis clients working, HTTP and
+// WebSocket reply the code along with the message.
+type Code string
+
+const (
+	CodeGeneric    Code = "ERR"        // CodeGeneric is an error without a more specific code
+	CodeSyntax     Code = "SYNTAX"     // CodeSyntax is a command whose arguments can not be parsed
+	CodeArity      Code = "ARITY"      // CodeArity is a command called with a wrong number of arguments
+	CodeUnknownCmd Code = "UNKNOWNCMD" // CodeUnknownCmd is a command that does not exist or is disabled
+	CodeWrongType  Code = "WRONGTYPE"  // CodeWrongType is an operation against a key holding the wrong kind of value
+	CodeOutOfRange Code = "OUTOFRANGE" // CodeOutOfRange is a value that is not a number, or out of the range allowed
+	CodeNoKey      Code = "NOKEY"      // CodeNoKey is an operation requiring a key that does not exist
+	CodeBusyKey    Code = "BUSYKEY"    // CodeBusyKey is an operation creating a key that already exists
+	CodeNoAuth     Code = "NOAUTH"     // CodeNoAuth is a command sent by a client that is not authenticated
+	CodeWrongPass  Code = "WRONGPASS"  // CodeWrongPass is an authentication with invalid credentials
+	CodeNoPerm     Code = "NOPERM"     // CodeNoPerm is a command the client is not allowed to execute
+	CodeNoScript   Code = "NOSCRIPT"   // CodeNoScript is a script that is not loaded
+	CodeExecAbort  Code = "EXECABORT"  // CodeExecAbort is a transaction discarded
+	CodeBusy       Code = "BUSY"       // CodeBusy is a command refused as the server is overloaded, to be retried
+	CodeOOM        Code = "OOM"        // CodeOOM is a write refused as a memory limit is reached
+	CodeDenied     Code = "DENIED"     // CodeDenied is a connection refused by the protected mode
+	CodeNoProto    Code = "NOPROTO"    // CodeNoProto is a protocol version that is not supported
+	CodeInvalidObj Code = "INVALIDOBJ" // CodeInvalidObj is a value found corrupted
+	CodeTimeout    Code = "TIMEOUT"    // CodeTimeout is a command that did not complete in time
+	CodeInternal   Code = "INTERNAL"   // CodeInternal is a failure of the server itself
+	CodeSchema     Code = "SCHEMA"     // CodeSchema is a result of a watched command not matching its schema
+	CodeLagging    Code = "LAGGING"    // CodeLagging is a read refused as the replica is behind the offset required
+	CodeConflict   Code = "CONFLICT"   // CodeConflict is a conditional write refused as the key is at another version
+	CodeLoading    Code = "LOADING"    // CodeLoading is a command on a key not loaded yet while the dataset is loaded
+)
+
+// respPrefixes are the RESP error codes of the codes refining ERR, the other codes being replied as they are
+var respPrefixes = map[Code]string{
+	CodeSyntax:     "ERR",
+	CodeArity:      "ERR",
+	CodeUnknownCmd: "ERR",
+	CodeOutOfRange: "ERR",
+	CodeNoKey:      "ERR",
+	CodeTimeout:    "ERR",
+	CodeInternal:   "ERR",
+	CodeSchema:     "ERR",
+}
+
+// respCodes are the codes replied over RESP as they are, by RESP error code
+var respCodes = map[string]Code{}
+
+func init() {
+	for _, code := range []Code{CodeGeneric, CodeWrongType, CodeBusyKey, CodeNoAuth, CodeWrongPass, CodeNoPerm,
+		CodeNoScript, CodeExecAbort, CodeBusy, CodeOOM, CodeDenied, CodeNoProto, CodeInvalidObj, CodeLagging,
+		CodeConflict, CodeLoading} {
+		respCodes[string(code)] = code
+	}
+}
+
+// RESPPrefix returns the RESP error code the errors of the code are replied with.
+func (c Code) RESPPrefix() string {
+	if prefix, ok := respPrefixes[c]; ok {
+		return prefix
+	}
+	return string(c)
+}
+
+// Error is an error replied by a command, with its code. Its message is prefixed with the RESP error code, as
+// replied over RESP.
+type Error struct {
+	Code    Code
+	Message string // Message is the error without its RESP error code
+}
+
+// New returns an error of the code.
+func New(code Code, message string) error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Code.RESPPrefix() + " " + e.Message
+}
+
+// CodeOf returns the code of an error replied by a command. The errors that are not an Error are classified
+// by the RESP error code their message starts with, CodeGeneric when it is not one of the codes.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeOfMessage(err.Error())
+}
+
+// CodeOfMessage returns the code of the message of an error, as replied over RESP.
+func CodeOfMessage(message string) Code {
+	prefix, _, _ := strings.Cut(strings.TrimPrefix(message, "-"), " ")
+	if code, ok := respCodes[prefix]; ok {
+		return code
+	}
+	return CodeGeneric
+}
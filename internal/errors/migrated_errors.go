@@ -29,69 +29,115 @@ import (
 
 // Standard error variables for various DiceDB-related error conditions.
 var (
-	ErrAuthFailed                 = errors.New("AUTH failed")                                                            // Indicates authentication failure.
-	ErrIntegerOutOfRange          = errors.New("ERR value is not an integer or out of range")                            // Represents a value that is either not an integer or is out of allowed range.
-	ErrInvalidNumberFormat        = errors.New("ERR value is not an integer or a float")                                 // Signals that a value provided is not in a valid integer or float format.
-	ErrValueOutOfRange            = errors.New("ERR value is out of range")                                              // Indicates that a value is beyond the permissible range.
-	ErrOverflow                   = errors.New("ERR increment or decrement would overflow")                              // Signifies that an increment or decrement operation would exceed the limits.
-	ErrSyntax                     = errors.New("ERR syntax error")                                                       // Represents a syntax error in a DiceDB command.
-	ErrKeyNotFound                = errors.New("ERR no such key")                                                        // Indicates that the specified key does not exist.
-	ErrWrongTypeOperation         = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")      // Signals an operation attempted on a key with an incompatible type.
-	ErrInvalidHyperLogLogKey      = errors.New("WRONGTYPE Key is not a valid HyperLogLog string value")                  // Indicates that a key is not a valid HyperLogLog value.
-	ErrCorruptedHyperLogLogObject = errors.New("INVALIDOBJ Corrupted HLL object detected")                               // Signals detection of a corrupted HyperLogLog object.
-	ErrInvalidJSONPathType        = errors.New("WRONGTYPE wrong type of path value - expected string but found integer") // Represents an invalid type for a JSON path.
-	ErrInvalidExpireTimeValue     = errors.New("ERR invalid expire time")                                                // Indicates that the provided expiration time is invalid.
-	ErrHashValueNotInteger        = errors.New("ERR hash value is not an integer")                                       // Signifies that a hash value is expected to be an integer.
-	ErrInternalServer             = errors.New("ERR Internal server error, unable to process command")                   // Represents a generic internal server error.
+	ErrAuthFailed                 = errors.New("AUTH failed")                                                          // Indicates authentication failure.
+	ErrIntegerOutOfRange          = New(CodeOutOfRange, "value is not an integer or out of range")                     // Represents a value that is either not an integer or is out of allowed range.
+	ErrInvalidNumberFormat        = New(CodeOutOfRange, "value is not an integer or a float")                          // Signals that a value provided is not in a valid integer or float format.
+	ErrValueOutOfRange            = New(CodeOutOfRange, "value is out of range")                                       // Indicates that a value is beyond the permissible range.
+	ErrOverflow                   = New(CodeOutOfRange, "increment or decrement would overflow")                       // Signifies that an increment or decrement operation would exceed the limits.
+	ErrDecrOverflow               = New(CodeOutOfRange, "decrement would overflow")                                    // Signifies that a decrement can't be negated into an increment.
+	ErrInvalidFloat               = New(CodeOutOfRange, "value is not a valid float")                                  // Signifies that a value can't be parsed as a float.
+	ErrHashValueNotFloat          = New(CodeOutOfRange, "hash value is not a float")                                   // Signifies that the field of a hash incremented by a float does not hold a float.
+	ErrIncrNaNOrInf               = New(CodeOutOfRange, "increment would produce NaN or Infinity")                     // Signifies that a float increment would produce a value that can't be stored.
+	ErrSyntax                     = New(CodeSyntax, "syntax error")                                                    // Represents a syntax error in a DiceDB command.
+	ErrKeyNotFound                = New(CodeNoKey, "no such key")                                                      // Indicates that the specified key does not exist.
+	ErrWrongTypeOperation         = New(CodeWrongType, "Operation against a key holding the wrong kind of value")      // Signals an operation attempted on a key with an incompatible type.
+	ErrInvalidHyperLogLogKey      = New(CodeWrongType, "Key is not a valid HyperLogLog string value")                  // Indicates that a key is not a valid HyperLogLog value.
+	ErrCorruptedHyperLogLogObject = New(CodeInvalidObj, "Corrupted HLL object detected")                               // Signals detection of a corrupted HyperLogLog object.
+	ErrInvalidJSONPathType        = New(CodeWrongType, "wrong type of path value - expected string but found integer") // Represents an invalid type for a JSON path.
+	ErrInvalidExpireTimeValue     = New(CodeOutOfRange, "invalid expire time")                                         // Indicates that the provided expiration time is invalid.
+	ErrHashValueNotInteger        = New(CodeOutOfRange, "hash value is not an integer")                                // Signifies that a hash value is expected to be an integer.
+	ErrInternalServer             = New(CodeInternal, "Internal server error, unable to process command")              // Represents a generic internal server error.
 	ErrAuth                       = errors.New("AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?")
 	ErrAborted                    = errors.New("server received ABORT command")
 	ErrEmptyCommand               = errors.New("empty command")
 	ErrInvalidIPAddress           = errors.New("invalid IP address")
 	ErrInvalidFingerprint         = errors.New("invalid fingerprint")
-	ErrKeyDoesNotExist            = errors.New("ERR could not perform this operation on a key that doesn't exist")
-	ErrKeyExists                  = errors.New("ERR key exists")
+	ErrKeyDoesNotExist            = New(CodeNoKey, "could not perform this operation on a key that doesn't exist")
+	ErrKeyExists                  = New(CodeBusyKey, "key exists")
+	ErrNoAuth                     = New(CodeNoAuth, "Authentication required")
+	ErrWrongPass                  = New(CodeWrongPass, "invalid username-password pair or user is disabled")
+	ErrNoProto                    = New(CodeNoProto, "sorry, this protocol version is not supported")
+	ErrMultiNested                = New(CodeGeneric, "MULTI calls can not be nested")
+	ErrExecWithoutMulti           = New(CodeGeneric, "EXEC without MULTI")
+	ErrDiscardWithoutMulti        = New(CodeGeneric, "DISCARD without MULTI")
+	ErrExecAbort                  = New(CodeExecAbort, "Transaction discarded because of previous errors.")
+	ErrTxnLockTimeout             = New(CodeExecAbort, "Transaction discarded because the shards could not be locked in time")
+	ErrTxnLockExpired             = New(CodeGeneric, "Transaction lock expired before the commit")
+	ErrCommandTimeout             = New(CodeTimeout, "Command cancelled after exceeding the command timeout")
+	ErrClientDisconnected         = New(CodeGeneric, "Command skipped as the client disconnected before it was executed")
+	ErrOverloaded                 = New(CodeBusy, "Server is overloaded, try again later")
+	ErrLoading                    = New(CodeLoading, "DiceDB is loading the dataset in memory")
+	ErrShardCrashed               = New(CodeInternal, "Command aborted as the shard executing it crashed, it may or may not have been applied")
+	ErrShardsTimedOut             = New(CodeTimeout, "Timed out waiting for the shards, the command may or may not have been applied")
+	ErrOffsetOutOfRange           = New(CodeOutOfRange, "offset is out of range")
+	ErrStringTooLong              = New(CodeOutOfRange, "string exceeds maximum allowed size (proto-max-bulk-len)")
+	ErrProtectedMode              = New(CodeDenied, "DiceDB is running in protected mode because protected mode is enabled and no password is set for the default user. In this mode connections are only accepted from the loopback interface. Set a password with 'requirepass', connect with a client certificate over TLS, or disable protected mode")
 
 	// Error generation functions for specific error messages with dynamic parameters.
 	ErrWrongArgumentCount = func(command string) error {
-		return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(command)) // Indicates an incorrect number of arguments for a given command.
+		return New(CodeArity, fmt.Sprintf("wrong number of arguments for '%s' command", strings.ToLower(command))) // Indicates an incorrect number of arguments for a given command.
 	}
 	ErrInvalidExpireTime = func(command string) error {
-		return fmt.Errorf("ERR invalid expire time in '%s' command", strings.ToLower(command)) // Represents an invalid expiration time for a specific command.
+		return New(CodeOutOfRange, fmt.Sprintf("invalid expire time in '%s' command", strings.ToLower(command))) // Represents an invalid expiration time for a specific command.
 	}
 
 	ErrInvalidElementPeekCount = func(max int) error {
-		return fmt.Errorf("ERR number of elements to peek should be a positive number less than %d", max) // Signals an invalid count for elements to peek.
+		return New(CodeOutOfRange, fmt.Sprintf("number of elements to peek should be a positive number less than %d", max)) // Signals an invalid count for elements to peek.
 	}
 
 	ErrGeneral = func(err string) error {
-		return fmt.Errorf("ERR %s", err) // General error format for various commands.
+		return New(CodeGeneric, err) // General error format for various commands.
 	}
 
 	ErrFormatted = func(errMsg string, opts ...any) error {
 		return ErrGeneral(fmt.Sprintf(errMsg, opts...))
 	}
 	ErrIOThreadNotFound = func(id string) error {
-		return fmt.Errorf("ERR io-thread with ID %s not found", id) // Indicates that an io-thread with the specified ID does not exist.
+		return New(CodeGeneric, fmt.Sprintf("io-thread with ID %s not found", id)) // Indicates that an io-thread with the specified ID does not exist.
 	}
 
 	ErrJSONPathNotFound = func(path string) error {
-		return fmt.Errorf("ERR Path '%s' does not exist", path) // Represents an error where the specified JSON path cannot be found.
+		return New(CodeGeneric, fmt.Sprintf("Path '%s' does not exist", path)) // Represents an error where the specified JSON path cannot be found.
 	}
 
 	ErrUnsupportedEncoding = func(encoding int) error {
-		return fmt.Errorf("ERR unsupported encoding: %d", encoding) // Indicates that an unsupported encoding type was provided.
+		return New(CodeGeneric, fmt.Sprintf("unsupported encoding: %d", encoding)) // Indicates that an unsupported encoding type was provided.
 	}
 
 	ErrUnexpectedType = func(expectedType string, actualType interface{}) error {
-		return fmt.Errorf("ERR expected %s but got another type: %s", expectedType, actualType) // Signals an unexpected type received when an integer was expected.
+		return New(CodeGeneric, fmt.Sprintf("expected %s but got another type: %s", expectedType, actualType)) // Signals an unexpected type received when an integer was expected.
 	}
 
 	ErrUnexpectedJSONPathType = func(expectedType string, actualType interface{}) error {
-		return fmt.Errorf("ERR wrong type of path value - expected %s but found %s", expectedType, actualType) // Signals an unexpected type received when an integer was expected.
+		return New(CodeGeneric, fmt.Sprintf("wrong type of path value - expected %s but found %s", expectedType, actualType)) // Signals an unexpected type received when an integer was expected.
 	}
 
 	ErrUnknownCmd = func(cmd string) error {
-		return fmt.Errorf("ERROR unknown command '%v'", cmd) // Indicates that an unsupported encoding type was provided.
+		return New(CodeUnknownCmd, fmt.Sprintf("unknown command '%v'", cmd)) // Indicates that the command does not exist.
+	}
+
+	ErrUnknownCmdWithArgs = func(cmd string, args []string) error {
+		return New(CodeUnknownCmd, fmt.Sprintf("unknown command '%s', with args beginning with: %s", cmd, strings.Join(args, " "))) // Indicates that the command does not exist or has been disabled.
+	}
+
+	ErrConfigSetFailed = func(err error) error {
+		return New(CodeGeneric, fmt.Sprintf("CONFIG SET failed - %s", err)) // Indicates that a parameter is unknown, immutable or given an invalid value.
+	}
+
+	ErrConfigRewriteFailed = func(err error) error {
+		return New(CodeGeneric, fmt.Sprintf("Rewriting config file: %s", err)) // Indicates that the running config could not be persisted.
+	}
+
+	ErrCmdNotAllowedInTxn = func(cmd string) error {
+		return New(CodeGeneric, fmt.Sprintf("'%s' is not allowed inside a transaction", strings.ToLower(cmd))) // Indicates that the command can not be queued by MULTI.
+	}
+
+	ErrVersionConflict = func(version, expected uint64) error {
+		return New(CodeConflict, fmt.Sprintf("the key is at version %d, not %d", version, expected)) // Indicates that the IFVERSION condition of a write does not hold.
+	}
+
+	ErrCommandNotAllowed = func(cmd, frontend string) error {
+		return New(CodeNoPerm, fmt.Sprintf("the '%s' command is not allowed over %s", strings.ToLower(cmd), frontend)) // Indicates that the frontend is configured to reject the command.
 	}
 )
 
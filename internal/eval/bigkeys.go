@@ -0,0 +1,86 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"strconv"
+
+	"github.com/dicedb/dice/internal/eval/sortedset"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// evalSingleKeySizes measures the keys of the shard for the analyses of the big keys, replying with a
+// [type, size, elements] entry per key, nil for the keys that do not exist. The keys are not touched, so
+// that an analysis does not change which keys are evicted.
+func evalSingleKeySizes(args []string, store *dstore.Store) *EvalResponse {
+	entries := make([]interface{}, len(args))
+	for i, key := range args {
+		obj := store.GetNoTouch(key)
+		if obj == nil {
+			continue
+		}
+		entries[i] = []interface{}{bigKeyTypeName(obj.Type), serializedSize(obj), elementCount(obj)}
+	}
+	return makeEvalResult(entries)
+}
+
+// bigKeyTypeName names the type of a key as TYPE does, the types TYPE does not know as DEBUG RELOAD does
+func bigKeyTypeName(t object.ObjectType) string {
+	switch t {
+	case object.ObjTypeString, object.ObjTypeInt, object.ObjTypeByteArray:
+		return "string"
+	default:
+		return reloadTypeNames[t]
+	}
+}
+
+// serializedSize is the length of the DUMP encoding of the value, estimated by ObjectSize for the types DUMP
+// does not encode
+func serializedSize(obj *object.Obj) int64 {
+	encoded, err := rdbSerialize(obj)
+	if err != nil {
+		return ObjectSize(obj)
+	}
+	return int64(len(encoded))
+}
+
+// elementCount is the number of members of a collection, the length of a string, and 1 for the other values
+func elementCount(obj *object.Obj) int64 {
+	switch v := obj.Value.(type) {
+	case string:
+		return int64(len(v))
+	case int64:
+		return int64(len(strconv.FormatInt(v, 10)))
+	case *ByteArray:
+		return int64(len(v.data))
+	case map[string]struct{}:
+		return int64(len(v))
+	case HashMap:
+		return int64(len(v))
+	case *sortedset.Set:
+		return int64(v.Len())
+	case *Deque:
+		return v.GetLength()
+	case map[string]interface{}:
+		return int64(len(v))
+	case []interface{}:
+		return int64(len(v))
+	default:
+		return 1
+	}
+}
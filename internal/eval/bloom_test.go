@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/object"
 	dstore "github.com/dicedb/dice/internal/store"
 	"github.com/stretchr/testify/assert"
 )
@@ -298,3 +299,27 @@ func TestBloomDeepCopy(t *testing.T) {
 	assert.True(t, original.opts.indexes[0] != copyBloom.opts.indexes[0], "Original and copy indexes should not be linked")
 	assert.True(t, original.bitset[0] != copyBloom.bitset[0], "Original and copy bitset should not be linked")
 }
+
+func TestBloomSerializeRoundTrip(t *testing.T) {
+	opts, err := newBloomOpts([]string{"0.01", "1000"})
+	assert.Nil(t, err)
+	original := NewBloomFilter(opts)
+	_, err = original.add("item1")
+	assert.Nil(t, err)
+
+	payload, err := rdbSerialize(&object.Obj{Type: object.ObjTypeBF, Value: original})
+	assert.Nil(t, err)
+	obj, err := rdbDeserialize(payload)
+	assert.Nil(t, err)
+
+	reloaded := obj.Value.(*Bloom)
+	assert.Equal(t, original.bitset, reloaded.bitset)
+	exists, err := reloaded.exists("item1")
+	assert.Nil(t, err)
+	assert.Equal(t, clientio.IntegerOne, exists)
+
+	// The bloom filter decoded is encoded the same as the original one
+	again, err := rdbSerialize(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, payload, again)
+}
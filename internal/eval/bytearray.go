@@ -180,6 +180,47 @@ func (b *ByteArray) IncreaseSize(increaseSizeTo int) *ByteArray {
 	return b
 }
 
+// reserve makes room for n more bytes. The capacity is doubled up to stringPreallocLimit and
+// grown by a quarter beyond it, so that a string built by repeated APPEND or SETRANGE calls
+// is copied a logarithmic number of times instead of on every call.
+func (b *ByteArray) reserve(n int) {
+	needed := len(b.data) + n
+	if needed <= cap(b.data) {
+		return
+	}
+
+	newCap := needed * 2
+	if needed > stringPreallocLimit {
+		newCap = needed + needed/4
+	}
+	data := make([]byte, len(b.data), newCap)
+	copy(data, b.data)
+	b.data = data
+}
+
+// Append adds value at the end of the byte array and returns its new length.
+func (b *ByteArray) Append(value string) int64 {
+	b.reserve(len(value))
+	b.data = append(b.data, value...)
+	b.Length = int64(len(b.data))
+	return b.Length
+}
+
+// SetRange overwrites the bytes starting at offset with value and returns the new length.
+// The byte array is padded with zero bytes when offset is past its end.
+func (b *ByteArray) SetRange(offset int, value string) int64 {
+	if end := offset + len(value); end > len(b.data) {
+		currentLen := len(b.data)
+		b.reserve(end - currentLen)
+		b.data = b.data[:end]
+		clear(b.data[currentLen:])
+		b.Length = int64(end)
+	}
+
+	copy(b.data[offset:], value)
+	return b.Length
+}
+
 func (b *ByteArray) ResizeIfNecessary() *ByteArray {
 	byteArrayLength := b.Length
 	decreaseLengthBy := 0
@@ -165,3 +165,35 @@ func TestDeepCopy(t *testing.T) {
 	original.data[1] = 8
 	assert.True(t, deepCopy.data[1] != original.data[1], "ByteArray DeepCopy did not create an independent deepCopy, original and deepCopy data are linked")
 }
+
+func TestByteArrayAppendPreallocates(t *testing.T) {
+	byteArray := NewByteArray(0)
+
+	reallocations := 0
+	for i := 0; i < 1000; i++ {
+		previousCap := cap(byteArray.data)
+		assert.Equal(t, int64(i+1)*5, byteArray.Append("hello"))
+		if cap(byteArray.data) != previousCap {
+			reallocations++
+		}
+	}
+
+	assert.Equal(t, int64(5000), byteArray.Length)
+	assert.Len(t, byteArray.data, 5000)
+	// The capacity doubles, so 5000 bytes are reached in a handful of reallocations
+	assert.LessOrEqual(t, reallocations, 12)
+}
+
+func TestByteArraySetRange(t *testing.T) {
+	byteArray := NewByteArray(0)
+	byteArray.Append("Hello World")
+
+	assert.Equal(t, int64(11), byteArray.SetRange(6, "Redis"))
+	assert.Equal(t, "Hello Redis", string(byteArray.data))
+
+	// Shrinking and growing again must not expose stale bytes in the spare capacity
+	byteArray.data = byteArray.data[:5]
+	byteArray.Length = 5
+	assert.Equal(t, int64(10), byteArray.SetRange(8, "!!"))
+	assert.Equal(t, "Hello\x00\x00\x00!!", string(byteArray.data))
+}
@@ -26,25 +26,14 @@ import (
 type DiceCmdMeta struct {
 	Name  string
 	Info  string
-	Eval  func([]string, *dstore.Store) []byte
 	Arity int // number of arguments, it is possible to use -N to say >= N
 	KeySpecs
 	SubCommands []string // list of sub-commands supported by the command
 
-	// IsMigrated indicates whether a command has been migrated to a new evaluation
-	// mechanism. If true, the command uses the newer evaluation logic represented by
-	// the NewEval function. This allows backward compatibility for commands that have
-	// not yet been migrated, ensuring they continue to use the older Eval function.
-	// As part of the transition process, commands can be flagged with IsMigrated to
-	// signal that they are using the updated execution path.
-	IsMigrated bool
-
-	// NewEval is the newer evaluation function for commands. It follows an updated
-	// execution model that returns an EvalResponse struct, offering more structured
-	// and detailed results, including metadata such as errors and additional info,
-	// instead of just raw bytes. Commands that have been migrated to this new model
-	// will utilize this function for evaluation, allowing for better handling of
-	// complex command execution scenarios and improved response consistency.
+	// NewEval is the evaluation function of the command. It returns an EvalResponse struct
+	// holding the structured result or the error of the command, which every frontend
+	// encodes in its own wire format. It is nil for the commands executed by the IO threads
+	// only, e.g. SHUTDOWN.
 	NewEval func([]string, *dstore.Store) *EvalResponse
 
 	// StoreObjectEval is a specialized evaluation function for commands that operate on an object.
@@ -57,11 +46,8 @@ type DiceCmdMeta struct {
 	StoreObjectEval func(*cmd.DiceDBCmd, *dstore.Store) *EvalResponse
 }
 
-type KeySpecs struct {
-	BeginIndex int
-	Step       int
-	LastKey    int
-}
+// KeySpecs locates the keys of a command, registered in the table of the key specifications by init.
+type KeySpecs = cmd.KeySpec
 
 var (
 	PreProcessing = map[string]func([]string, *dstore.Store) *EvalResponse{}
@@ -71,52 +57,158 @@ var (
 // Custom Commands:
 // This command type allows for flexibility in defining and executing specific,
 // non-standard operations. Each command has metadata that specifies its behavior
-// and execution logic (Eval function). While the RESP (Redis Serialization Protocol)
-// server supports custom logic for these commands and treats them as CUSTOM commands,
-// their implementation for HTTP and WebSocket protocols is still pending.
-// As a result, their Eval functions remain defined but not yet migrated.
+// and execution logic (NewEval function). The RESP (Redis Serialization Protocol)
+// server executes these commands in the IO threads and treats them as CUSTOM commands,
+// the evaluation functions defined here serve the HTTP and WebSocket requests.
 var (
 	echoCmdMeta = DiceCmdMeta{
-		Name:  "ECHO",
-		Info:  `ECHO returns the string given as argument.`,
-		Eval:  evalECHO,
-		Arity: 1,
+		Name:    "ECHO",
+		Info:    `ECHO returns the string given as argument.`,
+		NewEval: evalECHO,
+		Arity:   1,
 	}
 
 	pingCmdMeta = DiceCmdMeta{
-		Name:  "PING",
-		Info:  `PING returns with an encoded "PONG" If any message is added with the ping command,the message will be returned.`,
-		Arity: -1,
-		// TODO: Move this to true once compatible with HTTP server
-		IsMigrated: false,
-		Eval:       evalPING,
+		Name:    "PING",
+		Info:    `PING returns with an encoded "PONG" If any message is added with the ping command,the message will be returned.`,
+		Arity:   -1,
+		NewEval: evalPING,
 	}
 	helloCmdMeta = DiceCmdMeta{
-		Name:  "HELLO",
-		Info:  `HELLO always replies with a list of current server and connection properties, such as: versions, modules loaded, client ID, replication role and so forth`,
-		Eval:  evalHELLO,
-		Arity: -1,
+		Name: "HELLO",
+		Info: `HELLO [protover [AUTH username password] [SETNAME clientname]]
+		HELLO always replies with a list of current server and connection properties, such as: versions, modules loaded, client ID, replication role and so forth.
+		Only the RESP2 protocol is supported, any other protover is refused with a NOPROTO error.
+		AUTH authenticates the connection and SETNAME names it before replying.`,
+		NewEval: evalHELLO,
+		Arity:   -1,
+	}
+	lolwutCmdMeta = DiceCmdMeta{
+		Name:    "LOLWUT",
+		Info:    `LOLWUT [VERSION version] replies with a piece of generative art and the version of the server`,
+		NewEval: evalLOLWUT,
+		Arity:   -1,
 	}
 	authCmdMeta = DiceCmdMeta{
 		Name: "AUTH",
 		Info: `AUTH returns with an encoded "OK" if the user is authenticated.
 		If the user is not authenticated, it returns with an encoded error message`,
-		Eval: nil,
 	}
 	abortCmdMeta = DiceCmdMeta{
 		Name:  "ABORT",
-		Info:  "Quit the server",
-		Eval:  nil,
+		Info:  "Quit the server without saving the dataset. Deprecated, same as SHUTDOWN NOSAVE",
 		Arity: 1,
 	}
+	shutdownCmdMeta = DiceCmdMeta{
+		Name: "SHUTDOWN",
+		Info: `SHUTDOWN [NOSAVE|SAVE]
+		Stops the server: the listeners of every frontend are closed, the requests queued in the shards are
+		executed and the dataset is saved to the AOF file before exiting.
+		By default the dataset is saved only if persistence and persistence.write_aof_on_cleanup are enabled.
+		SAVE always saves it, NOSAVE never does.
+		Returns OK once the shutdown is initiated.`,
+		Arity: -1,
+	}
+	exportCmdMeta = DiceCmdMeta{
+		Name: "EXPORT",
+		Info: `EXPORT [MATCH pattern] [TYPE type] [FORMAT JSON|CSV]
+		Exports the keys matching the pattern, optionally restricted to a type, with their type, TTL and value.
+		Returns one line per key, as JSON Lines by default or as CSV after a key,type,ttl,value header.
+		The shards are read by small batches of keys and keep serving the other clients during the export.`,
+		Arity: -1,
+	}
+	sinkCmdMeta = DiceCmdMeta{
+		Name: "SINK",
+		Info: `SINK CREATE name KIND nats|kafka|webhook URL url [TOPIC topic] [SECRET secret] [FORMAT json|resp] WATCH command [arg ...]
+		Creates a connector publishing the result of the watched command to a NATS subject, a Kafka topic,
+		through a Kafka REST Proxy, or a signed HTTPS webhook every time it changes, starting with the current result.
+		Failed publications are retried with an exponential backoff, a connector failing repeatedly is disabled.
+		The updates are encoded as JSON objects, or as the pushes received by the RESP clients watching the command.
+		SINK DROP name stops a connector and SINK LIST returns the connectors with their publication counters.
+		The connectors are kept in memory only and are lost on restart.`,
+		Arity:       -2,
+		SubCommands: []string{"CREATE", "DROP", "LIST"},
+	}
+	namespaceCmdMeta = DiceCmdMeta{
+		Name: "NAMESPACE",
+		Info: `NAMESPACE USE name|default confines the connection to a namespace: the keys of its commands are
+		prefixed with the name of the namespace and a colon, the commands acting on the whole keyspace are refused.
+		NAMESPACE returns the namespace of the connection, NAMESPACE LIST the namespaces and NAMESPACE INFO [name]
+		the usage and the quotas of a namespace. NAMESPACE SETQUOTA name [MAXKEYS n] [MAXMEMORY bytes] [MAXOPS n]
+		sets the quotas of a namespace, the ones not given following the config.
+		The clients authenticated as a tenant are confined to its namespace.`,
+		Arity:       -1,
+		SubCommands: []string{"USE", "LIST", "INFO", "SETQUOTA"},
+	}
+	moduleCmdMeta = DiceCmdMeta{
+		Name: "MODULE",
+		Info: `MODULE LIST returns the modules loaded, compiled in or from the Go plugins of modules.load, with the
+		commands they added.`,
+		Arity:       -2,
+		SubCommands: []string{"LIST"},
+	}
+	readafterCmdMeta = DiceCmdMeta{
+		Name: "READAFTER",
+		Info: `READAFTER offset makes the following reads of the connection require the replication stream of the
+		upstream followed by the bridge to be applied up to the offset, the master_repl_offset of the upstream once
+		it replied to the writes of the client. A read waits up to bridge.read_after_timeout for the offset, then
+		fails with LAGGING and the address of the upstream to read from. READAFTER 0 reads whatever the offset.`,
+		Arity: 2,
+	}
+	traceCmdMeta = DiceCmdMeta{
+		Name: "TRACE",
+		Info: `TRACE ON | OFF | LAST records the time the commands of the connection spend parsed, queued on the
+		shards, evaluated, encoded and written, from TRACE ON until TRACE OFF. TRACE LAST returns the stages of the
+		last command recorded in microseconds, as a flat list of stage and value, NIL if none was recorded.`,
+		Arity:       2,
+		SubCommands: []string{"ON", "OFF", "LAST"},
+	}
+	hotkeysCmdMeta = DiceCmdMeta{
+		Name: "HOTKEYS",
+		Info: `HOTKEYS [COUNT count] returns the keys accessed the most, as [key, ops/sec] pairs estimated from the
+		accesses sampled at hotkeys.sample_rate, the hottest first. COUNT defaults to 10, -1 returns every tracked key.
+		HOTKEYS STATS returns the number of hot keys copied for their reads and the number of GETs served from
+		the copies, HOTKEYS RESET forgets the rates estimated so far. Requires hotkeys.enabled.`,
+		Arity:       -1,
+		SubCommands: []string{"COUNT", "STATS", "RESET"},
+	}
+	bigkeysCmdMeta = DiceCmdMeta{
+		Name: "BIGKEYS",
+		Info: `BIGKEYS returns the largest keys of every type found by the last analysis of the keyspace, by size and
+		by number of elements, as ["type", type, "keys", count, "size", bytes, "elements", count,
+		"by_size", [[key, bytes], ...], "by_elements", [[key, count], ...]] entries ordered by type.
+		The size of a key is the length of its DUMP encoding. BIGKEYS SCAN starts an analysis in the background,
+		the analyses also run every bigkeys.interval.`,
+		Arity:       -1,
+		SubCommands: []string{"SCAN"},
+	}
+	debugCmdMeta = DiceCmdMeta{
+		Name: "DEBUG",
+		Info: `DEBUG QUICK runs a self-check of the server environment: config consistency,
+		writability of the persistence directories, memory limits against the cgroup limits and clock resolution.
+		Returns one [check, status, detail] entry per check, the status being ok, warn or fail.
+		DEBUG FAILPOINT name term enables a failpoint of a server built with the failpoints tag, e.g.
+		DEBUG FAILPOINT shard/delay sleep(7s), the term off disabling it. DEBUG FAILPOINT LIST returns
+		the enabled failpoints.
+		DEBUG RELOAD encodes every key the way DUMP does and replaces the dataset with the keys decoded back.
+		DEBUG RELOAD VERIFY also compares the digests of the dataset before and after the reload, and returns
+		one [type, status, detail] entry per type, the status being ok, mismatch or unsupported.
+		DEBUG LATENCY-TEST [REQUESTS n] [DATASIZE n] [KEYSPACE n] [MIX mix] executes n requests, 10000 by default,
+		one after the other against the keys of the scratch namespace __latency_test__:, deleted once the test is done.
+		The mix is a list of commands with their weights, e.g. set:1,lpush:3, set:1,get:1,lpush:1,lpop:1 by default.
+		Returns the number of requests, of errors, the elapsed time and the throughput, the min, p50, p90, p99, p99.9
+		and max latencies in microseconds, and the count and latencies of every command of the mix.`,
+		Arity:       -2,
+		SubCommands: []string{"QUICK", "FAILPOINT", "RELOAD", "LATENCY-TEST"},
+	}
 	sleepCmdMeta = DiceCmdMeta{
 		Name: "SLEEP",
 		Info: `SLEEP sets db to sleep for the specified number of seconds.
 		The sleep time should be the only param in args.
 		Returns error response if the time param in args is not of integer format.
-		SLEEP returns RespOK after sleeping for mentioned seconds`,
-		Eval:  evalSLEEP,
-		Arity: 1,
+		SLEEP returns OK after sleeping for mentioned seconds`,
+		NewEval: evalSLEEP,
+		Arity:   1,
 	}
 )
 
@@ -125,15 +217,15 @@ var (
 // While the RESP server supports scatter-gather logic for these commands and
 // treats them as MultiShard or commands,
 // their implementation for HTTP and WebSocket protocols is still pending.
-// As a result, their Eval functions remained intact.
+// As a result, their store object evaluation functions remained intact.
 var (
 	//TODO: supports only http protocol, needs to be removed once http is migrated to multishard
 	objectCopyCmdMeta = DiceCmdMeta{
 		Name:            "OBJECTCOPY",
 		Info:            `COPY command copies the value stored at the source key to the destination key.`,
 		StoreObjectEval: evalCOPYObject,
-		IsMigrated:      true,
 		Arity:           -2,
+		KeySpecs:        KeySpecs{BeginIndex: 1},
 	}
 )
 
@@ -152,10 +244,18 @@ var (
 		Returns encoded error response if expiry tme value in not integer
 		Returns encoded OK RESP once new entry is added
 		If the key already exists then the value will be overwritten and expiry will be discarded`,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalSET,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalSET,
+	}
+	msetCmdMeta = DiceCmdMeta{
+		Name: "MSET",
+		Info: `MSET key value [key value ...]
+		Sets the keys to their values, discarding their expiry, in a single batch of writes of the shard.
+		Returns OK.`,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 2, LastKey: -1},
+		NewEval:  evalMSET,
 	}
 	getCmdMeta = DiceCmdMeta{
 		Name: "GET",
@@ -163,18 +263,17 @@ var (
 		The key should be the only param in args
 		The RESP value of the key is encoded and then returned
 		GET returns RespNIL if key is expired or it does not exist`,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalGET,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalGET,
 	}
 
 	getSetCmdMeta = DiceCmdMeta{
-		Name:       "GETSET",
-		Info:       `GETSET returns the previous string value of a key after setting it to a new value.`,
-		Arity:      2,
-		IsMigrated: true,
-		NewEval:    evalGETSET,
+		Name:     "GETSET",
+		Info:     `GETSET returns the previous string value of a key after setting it to a new value.`,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalGETSET,
 	}
 
 	getDelCmdMeta = DiceCmdMeta{
@@ -183,10 +282,9 @@ var (
 		The key should be the only param in args And If the key exists, it will be deleted before its value is returned.
 		The RESP value of the key is encoded and then returned
 		GETDEL returns RespNIL if key is expired or it does not exist`,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalGETDEL,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalGETDEL,
 	}
 	jsonsetCmdMeta = DiceCmdMeta{
 		Name: "JSON.SET",
@@ -194,10 +292,9 @@ var (
 		Sets a JSON value at the specified key.
 		Returns OK if successful.
 		Returns encoded error message if the number of arguments is incorrect or the JSON string is invalid.`,
-		NewEval:    evalJSONSET,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalJSONSET,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsongetCmdMeta = DiceCmdMeta{
 		Name: "JSON.GET",
@@ -205,10 +302,9 @@ var (
 		Returns the encoded RESP value of the key, if present
 		Null reply: If the key doesn't exist or has expired.
 		Error reply: If the number of arguments is incorrect or the stored value is not a JSON type.`,
-		NewEval:    evalJSONGET,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalJSONGET,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsontoggleCmdMeta = DiceCmdMeta{
 		Name: "JSON.TOGGLE",
@@ -222,10 +318,9 @@ var (
     	1.String ("true"/"false") that represents the resulting Boolean value.
     	2.NONEXISTENT if the document key does not exist.
     	3.WRONGTYPE error if the value at the path is not a Boolean value.`,
-		NewEval:    evalJSONTOGGLE,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalJSONTOGGLE,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsontypeCmdMeta = DiceCmdMeta{
 		Name: "JSON.TYPE",
@@ -233,10 +328,9 @@ var (
 		Returns string reply for each path, specified as the value's type.
 		Returns RespNIL If the key doesn't exist.
 		Error reply: If the number of arguments is incorrect.`,
-		NewEval:    evalJSONTYPE,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalJSONTYPE,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonclearCmdMeta = DiceCmdMeta{
 		Name: "JSON.CLEAR",
@@ -244,10 +338,9 @@ var (
 		Returns an integer reply specifying the number ofmatching JSON arrays and
 		objects cleared +number of matching JSON numerical values zeroed.
 		Error reply: If the number of arguments is incorrect the key doesn't exist.`,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalJSONCLEAR,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONCLEAR,
 	}
 	jsondelCmdMeta = DiceCmdMeta{
 		Name: "JSON.DEL",
@@ -255,19 +348,18 @@ var (
 		Returns an integer reply specified as the number of paths deleted (0 or more).
 		Returns RespZero if the key doesn't exist or key is expired.
 		Error reply: If the number of arguments is incorrect.`,
-		NewEval:    evalJSONDEL,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalJSONDEL,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonarrappendCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRAPPEND",
 		Info: `JSON.ARRAPPEND key [path] value [value ...]
         Returns an array of integer replies for each path, the array's new size,
         or nil, if the matching JSON value is not an array.`,
-		Arity:      -3,
-		IsMigrated: true,
-		NewEval:    evalJSONARRAPPEND,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONARRAPPEND,
 	}
 	jsonforgetCmdMeta = DiceCmdMeta{
 		Name: "JSON.FORGET",
@@ -275,10 +367,9 @@ var (
 		Returns an integer reply specified as the number of paths deleted (0 or more).
 		Returns RespZero if the key doesn't exist or key is expired.
 		Error reply: If the number of arguments is incorrect.`,
-		NewEval:    evalJSONFORGET,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalJSONFORGET,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonarrlenCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRLEN",
@@ -286,19 +377,17 @@ var (
 		Returns an array of integer replies.
 		Returns error response if the key doesn't exist or key is expired or the matching value is not an array.
 		Error reply: If the number of arguments is incorrect.`,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalJSONARRLEN,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONARRLEN,
 	}
 	jsonnummultbyCmdMeta = DiceCmdMeta{
 		Name: "JSON.NUMMULTBY",
 		Info: `JSON.NUMMULTBY key path value
 		Multiply the number value stored at the specified path by a value.`,
-		NewEval:    evalJSONNUMMULTBY,
-		Arity:      3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalJSONNUMMULTBY,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonobjlenCmdMeta = DiceCmdMeta{
 		Name: "JSON.OBJLEN",
@@ -306,10 +395,9 @@ var (
 		Report the number of keys in the JSON object at path in key
 		Returns error response if the key doesn't exist or key is expired or the matching value is not an array.
 		Error reply: If the number of arguments is incorrect.`,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalJSONOBJLEN,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONOBJLEN,
 	}
 	jsondebugCmdMeta = DiceCmdMeta{
 		Name: "JSON.DEBUG",
@@ -317,10 +405,9 @@ var (
 		JSON.DEBUG MEMORY returns memory usage by key in bytes
 		JSON.DEBUG HELP displays help message
 		`,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalJSONDebug,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONDebug,
 	}
 	jsonobjkeysCmdMeta = DiceCmdMeta{
 		Name: "JSON.OBJKEYS",
@@ -328,9 +415,9 @@ var (
 		Retrieves the keys of a JSON object stored at path specified.
 		Null reply: If the key doesn't exist or has expired.
 		Error reply: If the number of arguments is incorrect or the stored value is not a JSON type.`,
-		NewEval:    evalJSONOBJKEYS,
-		IsMigrated: true,
-		Arity:      2,
+		NewEval:  evalJSONOBJKEYS,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonarrpopCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRPOP",
@@ -340,9 +427,9 @@ var (
 		Return nil if array is empty or there is no array at the path.
 		It supports negative index and is out of bound safe.
 		`,
-		Arity:      -2,
-		IsMigrated: true,
-		NewEval:    evalJSONARRPOP,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONARRPOP,
 	}
 	jsoningestCmdMeta = DiceCmdMeta{
 		Name: "JSON.INGEST",
@@ -351,10 +438,9 @@ var (
 		the generated key is then used to store the provided JSON value at specified path.
 		Returns unique identifier if successful.
 		Returns encoded error message if the number of arguments is incorrect or the JSON string is invalid.`,
-		NewEval:    evalJSONINGEST,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalJSONINGEST,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonarrinsertCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRINSERT",
@@ -363,19 +449,17 @@ var (
 		Returns nil if the matching JSON value is not an array.
 		Returns error response if the key doesn't exist or key is expired or the matching value is not an array.
 		Error reply: If the number of arguments is incorrect.`,
-		NewEval:    evalJSONARRINSERT,
-		IsMigrated: true,
-		Arity:      -5,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONARRINSERT,
+		Arity:    -5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonrespCmdMeta = DiceCmdMeta{
 		Name: "JSON.RESP",
 		Info: `JSON.RESP key [path]
 		Return the JSON in key in Redis serialization protocol specification form`,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalJSONRESP,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONRESP,
 	}
 	jsonarrtrimCmdMeta = DiceCmdMeta{
 		Name: "JSON.ARRTRIM",
@@ -384,9 +468,9 @@ var (
 		Returns an array of integer replies for each path.
 		Returns error response if the key doesn't exist or key is expired.
 		Error reply: If the number of arguments is incorrect.`,
-		NewEval:    evalJSONARRTRIM,
-		IsMigrated: true,
-		Arity:      -5,
+		NewEval:  evalJSONARRTRIM,
+		Arity:    -5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	ttlCmdMeta = DiceCmdMeta{
 		Name: "TTL",
@@ -396,19 +480,17 @@ var (
 		RESP encoded time (in secs) remaining for the key to expire
 		RESP encoded -2 stating key doesn't exist or key is expired
 		RESP encoded -1 in case no expiration is set on the key`,
-		NewEval:    evalTTL,
-		IsMigrated: true,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalTTL,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	delCmdMeta = DiceCmdMeta{
 		Name: "DEL",
 		Info: `DEL deletes all the specified keys in args list
 		returns the count of total deleted keys after encoding`,
-		IsMigrated: true,
-		NewEval:    evalDEL,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1},
+		NewEval:  evalDEL,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1, LastKey: -1, Options: []string{IfVersion}},
 	}
 	expireCmdMeta = DiceCmdMeta{
 		Name: "EXPIRE",
@@ -417,19 +499,26 @@ var (
 		The expiry time should be in integer format; if not, it returns encoded error response
 		Returns RespOne if expiry was set on the key successfully.
 		Once the time is lapsed, the key will be deleted automatically`,
-		NewEval:    evalEXPIRE,
-		IsMigrated: true,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalEXPIRE,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
+	}
+	pexpireCmdMeta = DiceCmdMeta{
+		Name: "PEXPIRE",
+		Info: `PEXPIRE key milliseconds
+		Sets the expiry of the key in milliseconds.
+		Returns 1 if the expiry was set, 0 if the key does not exist.`,
+		NewEval:  evalPEXPIRE,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	expiretimeCmdMeta = DiceCmdMeta{
 		Name: "EXPIRETIME",
 		Info: `EXPIRETIME returns the absolute Unix timestamp (since January 1, 1970) in seconds
 		at which the given key will expire`,
-		NewEval:    evalEXPIRETIME,
-		IsMigrated: true,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalEXPIRETIME,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	expireatCmdMeta = DiceCmdMeta{
 		Name: "EXPIREAT",
@@ -438,10 +527,9 @@ var (
 		The expiry time should be in integer format; if not, it returns encoded error response
 		Returns RespOne if expiry was set on the key successfully.
 		Once the time is lapsed, the key will be deleted automatically`,
-		NewEval:    evalEXPIREAT,
-		IsMigrated: true,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalEXPIREAT,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	incrCmdMeta = DiceCmdMeta{
 		Name: "INCR",
@@ -453,10 +541,9 @@ var (
 		The value for the queried key should be of integer format,
 		if not INCR returns encoded error response.
 		evalINCR returns the incremented value for the key if there are no errors.`,
-		NewEval:    evalINCR,
-		IsMigrated: true,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalINCR,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	incrByFloatCmdMeta = DiceCmdMeta{
 		Name: "INCRBYFLOAT",
@@ -467,150 +554,157 @@ var (
 		If the value at the key is a string, it should be parsable to float64,
 		if not INCRBYFLOAT returns an  error response.
 		INCRBYFLOAT returns the incremented value for the key after applying the specified increment if there are no errors.`,
-		Arity:      2,
-		NewEval:    evalINCRBYFLOAT,
-		IsMigrated: true,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalINCRBYFLOAT,
 	}
 	clientCmdMeta = DiceCmdMeta{
-		Name:       "CLIENT",
-		Info:       `This is a container command for client connection commands.`,
-		NewEval:    evalCLIENT,
-		IsMigrated: true,
-		Arity:      -2,
+		Name:    "CLIENT",
+		Info:    `This is a container command for client connection commands.`,
+		NewEval: evalCLIENT,
+		Arity:   -2,
 	}
 	latencyCmdMeta = DiceCmdMeta{
-		Name:       "LATENCY",
-		Info:       `This is a container command for latency diagnostics commands.`,
-		NewEval:    evalLATENCY,
-		IsMigrated: true,
-		Arity:      -2,
+		Name: "LATENCY",
+		Info: `LATENCY LATEST | LATENCY HISTORY event | LATENCY RESET [event ...]
+		Reports the latency spikes of the command, expire-cycle, eviction and aof-fsync events
+		that reached latency.monitor_threshold milliseconds.`,
+		NewEval:     evalLATENCY,
+		Arity:       -2,
+		SubCommands: []string{Latest, History, Reset},
 	}
 	bfreserveCmdMeta = DiceCmdMeta{
 		Name: "BF.RESERVE",
 		Info: `BF.RESERVE command initializes a new bloom filter and allocation it's relevant parameters based on given inputs.
 		If no params are provided, it uses defaults.`,
-		IsMigrated: true,
-		NewEval:    evalBFRESERVE,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalBFRESERVE,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	bfaddCmdMeta = DiceCmdMeta{
 		Name: "BF.ADD",
 		Info: `BF.ADD adds an element to
 		a bloom filter. If the filter does not exists, it will create a new one
 		with default parameters.`,
-		IsMigrated: true,
-		NewEval:    evalBFADD,
-		Arity:      3,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalBFADD,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	bfexistsCmdMeta = DiceCmdMeta{
-		Name:       "BF.EXISTS",
-		Info:       `BF.EXISTS checks existence of an element in a bloom filter.`,
-		NewEval:    evalBFEXISTS,
-		IsMigrated: true,
-		Arity:      3,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		Name:     "BF.EXISTS",
+		Info:     `BF.EXISTS checks existence of an element in a bloom filter.`,
+		NewEval:  evalBFEXISTS,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	bfinfoCmdMeta = DiceCmdMeta{
-		Name:       "BF.INFO",
-		Info:       `BF.INFO returns the parameters and metadata of an existing bloom filter.`,
-		NewEval:    evalBFINFO,
-		IsMigrated: true,
-		Arity:      2,
+		Name:     "BF.INFO",
+		Info:     `BF.INFO returns the parameters and metadata of an existing bloom filter.`,
+		NewEval:  evalBFINFO,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	setBitCmdMeta = DiceCmdMeta{
-		Name:       "SETBIT",
-		Info:       "SETBIT sets or clears the bit at offset in the string value stored at key",
-		IsMigrated: true,
-		NewEval:    evalSETBIT,
+		Name:     "SETBIT",
+		Info:     "SETBIT sets or clears the bit at offset in the string value stored at key",
+		NewEval:  evalSETBIT,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	getBitCmdMeta = DiceCmdMeta{
-		Name:       "GETBIT",
-		Info:       "GETBIT returns the bit value at offset in the string value stored at key",
-		IsMigrated: true,
-		NewEval:    evalGETBIT,
+		Name:     "GETBIT",
+		Info:     "GETBIT returns the bit value at offset in the string value stored at key",
+		NewEval:  evalGETBIT,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	bitCountCmdMeta = DiceCmdMeta{
-		Name:       "BITCOUNT",
-		Info:       "BITCOUNT counts the number of set bits in the string value stored at key",
-		Arity:      -1,
-		IsMigrated: true,
-		NewEval:    evalBITCOUNT,
+		Name:     "BITCOUNT",
+		Info:     "BITCOUNT counts the number of set bits in the string value stored at key",
+		Arity:    -1,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalBITCOUNT,
 	}
 
 	persistCmdMeta = DiceCmdMeta{
-		Name:       "PERSIST",
-		Info:       "PERSIST removes the expiration from a key",
-		IsMigrated: true,
-		NewEval:    evalPERSIST,
+		Name:     "PERSIST",
+		Info:     "PERSIST removes the expiration from a key",
+		NewEval:  evalPERSIST,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 
 	commandCmdMeta = DiceCmdMeta{
 		Name:        "COMMAND",
 		Info:        "Evaluates COMMAND <subcommand> command based on subcommand",
 		NewEval:     evalCommand,
-		IsMigrated:  true,
 		Arity:       -1,
 		SubCommands: []string{Count, GetKeys, GetKeysandFlags, List, Help, Info, Docs},
 	}
+	configCmdMeta = DiceCmdMeta{
+		Name: "CONFIG",
+		Info: `CONFIG GET parameter [parameter ...] | CONFIG SET parameter value [parameter value ...] | CONFIG REWRITE | CONFIG RESETSTAT
+		CONFIG GET returns the parameters matching the glob patterns, CONFIG SET changes hot-reloadable
+		parameters at runtime, CONFIG REWRITE persists the running configuration to the config file and
+		CONFIG RESETSTAT resets the statistics reported by INFO.`,
+		NewEval:     evalCONFIG,
+		Arity:       -2,
+		SubCommands: []string{GET, SET, Rewrite, ResetStat},
+	}
 	commandCountCmdMeta = DiceCmdMeta{
-		Name:       "COMMAND|COUNT",
-		Info:       "Returns a count of commands.",
-		NewEval:    evalCommand,
-		IsMigrated: true,
-		Arity:      2,
+		Name:    "COMMAND|COUNT",
+		Info:    "Returns a count of commands.",
+		NewEval: evalCommand,
+		Arity:   2,
 	}
 	commandHelpCmdMeta = DiceCmdMeta{
-		Name:       "COMMAND|HELP",
-		Info:       "Returns helpful text about the different subcommands",
-		NewEval:    evalCommand,
-		IsMigrated: true,
-		Arity:      2,
+		Name:    "COMMAND|HELP",
+		Info:    "Returns helpful text about the different subcommands",
+		NewEval: evalCommand,
+		Arity:   2,
 	}
 	commandInfoCmdMeta = DiceCmdMeta{
-		Name:       "COMMAND|INFO",
-		Info:       "Returns information about one, multiple or all commands.",
-		NewEval:    evalCommand,
-		IsMigrated: true,
-		Arity:      -2,
+		Name:    "COMMAND|INFO",
+		Info:    "Returns information about one, multiple or all commands.",
+		NewEval: evalCommand,
+		Arity:   -2,
 	}
 	commandListCmdMeta = DiceCmdMeta{
-		Name:       "COMMAND|LIST",
-		Info:       "Returns a list of command names.",
-		NewEval:    evalCommand,
-		IsMigrated: true,
-		Arity:      -2,
+		Name:    "COMMAND|LIST",
+		Info:    "Returns a list of command names.",
+		NewEval: evalCommand,
+		Arity:   -2,
 	}
 	commandDocsCmdMeta = DiceCmdMeta{
-		Name:       "COMMAND|DOCS",
-		Info:       "Returns documentary information about one, multiple or all commands.",
-		NewEval:    evalCommand,
-		IsMigrated: true,
-		Arity:      -2,
+		Name:    "COMMAND|DOCS",
+		Info:    "Returns documentary information about one, multiple or all commands.",
+		NewEval: evalCommand,
+		Arity:   -2,
 	}
 	commandGetKeysCmdMeta = DiceCmdMeta{
-		Name:       "COMMAND|GETKEYS",
-		Info:       "Extracts the key names from an arbitrary command.",
-		NewEval:    evalCommand,
-		IsMigrated: true,
-		Arity:      -4,
+		Name:    "COMMAND|GETKEYS",
+		Info:    "Extracts the key names from an arbitrary command.",
+		NewEval: evalCommand,
+		Arity:   -4,
 	}
 	commandGetKeysAndFlagsCmdMeta = DiceCmdMeta{
-		Name:       "COMMAND|GETKEYSANDFLAGS",
-		Info:       "Returns a list of command names.",
-		NewEval:    evalCommand,
-		IsMigrated: true,
-		Arity:      -4,
+		Name:    "COMMAND|GETKEYSANDFLAGS",
+		Info:    "Returns a list of command names.",
+		NewEval: evalCommand,
+		Arity:   -4,
 	}
 
 	// Internal command used to spawn request across all shards (works internally with the KEYS command)
 	singleKeysCmdMeta = DiceCmdMeta{
-		Name:       "SINGLEKEYS",
-		Info:       "KEYS command is used to get all the keys in the database. Complexity is O(n) where n is the number of keys in the database.",
-		NewEval:    evalKEYS,
-		Arity:      1,
-		IsMigrated: true,
+		Name:    "SINGLEKEYS",
+		Info:    "KEYS command is used to get all the keys in the database. Complexity is O(n) where n is the number of keys in the database.",
+		NewEval: evalKEYS,
+		Arity:   1,
+	}
+
+	// Internal command used to read the values of a batch of keys of a shard (works internally with the EXPORT command)
+	singleExportCmdMeta = DiceCmdMeta{
+		Name:    "SINGLEEXPORT",
+		Info:    "EXPORT reads the type, TTL and value of a batch of keys of a shard.",
+		NewEval: evalSingleExport,
+		Arity:   -2,
 	}
 
 	decrCmdMeta = DiceCmdMeta{
@@ -623,10 +717,9 @@ var (
 		The value for the queried key should be of integer format,
 		if not DECR returns encoded error response.
 		evalDECR returns the decremented value for the key if there are no errors.`,
-		NewEval:    evalDECR,
-		IsMigrated: true,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalDECR,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	decrByCmdMeta = DiceCmdMeta{
 		Name: "DECRBY",
@@ -638,26 +731,24 @@ var (
 		The value for the queried key should be of integer format,
 		if not, DECRBY returns an encoded error response.
 		evalDECRBY returns the decremented value for the key after applying the specified decrement if there are no errors.`,
-		NewEval:    evalDECRBY,
-		IsMigrated: true,
-		Arity:      3,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalDECRBY,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	existsCmdMeta = DiceCmdMeta{
 		Name: "EXISTS",
 		Info: `EXISTS key1 key2 ... key_N
 		Return value is the number of keys existing.`,
-		IsMigrated: true,
-		NewEval:    evalEXISTS,
+		NewEval:  evalEXISTS,
+		KeySpecs: KeySpecs{BeginIndex: 1, LastKey: -1},
 	}
 	getexCmdMeta = DiceCmdMeta{
 		Name: "GETEX",
 		Info: `Get the value of key and optionally set its expiration.
 		GETEX is similar to GET, but is a write command with additional options.`,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalGETEX,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalGETEX,
 	}
 	pttlCmdMeta = DiceCmdMeta{
 		Name: "PTTL",
@@ -667,10 +758,9 @@ var (
 		RESP encoded time (in secs) remaining for the key to expire
 		RESP encoded -2 stating key doesn't exist or key is expired
 		RESP encoded -1 in case no expiration is set on the key`,
-		NewEval:    evalPTTL,
-		IsMigrated: true,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalPTTL,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hsetCmdMeta = DiceCmdMeta{
 		Name: "HSET",
@@ -680,10 +770,9 @@ var (
 		Returns
 		This command returns the number of keys that are stored at given key.
 		`,
-		NewEval:    evalHSET,
-		Arity:      -4,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalHSET,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hmsetCmdMeta = DiceCmdMeta{
 		Name: "HMSET",
@@ -693,81 +782,125 @@ var (
 		Returns
 		This command returns the number of keys that are stored at given key.
 		`,
-		NewEval:    evalHMSET,
-		Arity:      -4,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalHMSET,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hkeysCmdMeta = DiceCmdMeta{
-		Name:       "HKEYS",
-		Info:       `HKEYS command is used to retrieve all the keys(or field names) within a hash. Complexity is O(n) where n is the size of the hash.`,
-		NewEval:    evalHKEYS,
-		Arity:      1,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		Name:     "HKEYS",
+		Info:     `HKEYS command is used to retrieve all the keys(or field names) within a hash. Complexity is O(n) where n is the size of the hash.`,
+		NewEval:  evalHKEYS,
+		Arity:    1,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hsetnxCmdMeta = DiceCmdMeta{
 		Name: "HSETNX",
 		Info: `Sets field in the hash stored at key to value, only if field does not yet exist.
 		If key does not exist, a new key holding a hash is created. If field already exists,
 		this operation has no effect.`,
-		NewEval:    evalHSETNX,
-		Arity:      4,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalHSETNX,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hgetCmdMeta = DiceCmdMeta{
-		Name:       "HGET",
-		Info:       `Returns the value associated with field in the hash stored at key.`,
-		NewEval:    evalHGET,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		Name:     "HGET",
+		Info:     `Returns the value associated with field in the hash stored at key.`,
+		NewEval:  evalHGET,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hmgetCmdMeta = DiceCmdMeta{
-		Name:       "HMGET",
-		Info:       `Returns the values associated with the specified fields in the hash stored at key.`,
-		NewEval:    evalHMGET,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		Name:     "HMGET",
+		Info:     `Returns the values associated with the specified fields in the hash stored at key.`,
+		NewEval:  evalHMGET,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hgetAllCmdMeta = DiceCmdMeta{
 		Name: "HGETALL",
 		Info: `Returns all fields and values of the hash stored at key. In the returned value,
         every field name is followed by its value, so the length of the reply is twice the size of the hash.`,
-		NewEval:    evalHGETALL,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalHGETALL,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hValsCmdMeta = DiceCmdMeta{
-		Name:       "HVALS",
-		Info:       `Returns all values of the hash stored at key. The length of the reply is same as the size of the hash.`,
-		NewEval:    evalHVALS,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		Name:     "HVALS",
+		Info:     `Returns all values of the hash stored at key. The length of the reply is same as the size of the hash.`,
+		NewEval:  evalHVALS,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hincrbyCmdMeta = DiceCmdMeta{
 		Name: "HINCRBY",
 		Info: `Increments the number stored at field in the hash stored at key by increment.
 		If key does not exist, a new key holding a hash is created.
 		If field does not exist the value is set to 0 before the operation is performed.`,
-		Arity:      -4,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalHINCRBY,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalHINCRBY,
 	}
 	hstrLenCmdMeta = DiceCmdMeta{
-		Name:       "HSTRLEN",
-		Info:       `Returns the length of value associated with field in the hash stored at key.`,
-		NewEval:    evalHSTRLEN,
-		IsMigrated: true,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "HSTRLEN",
+		Info:     `Returns the length of value associated with field in the hash stored at key.`,
+		NewEval:  evalHSTRLEN,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 
+	hexpireCmdMeta = DiceCmdMeta{
+		Name: "HEXPIRE",
+		Info: `HEXPIRE key seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+		Sets the expiry of the fields of the hash in seconds.
+		Returns for every field -2 if it does not exist, 0 if the condition does not hold,
+		1 if its expiry was set and 2 if it was deleted as the expiry is 0.`,
+		NewEval:  evalHEXPIRE,
+		Arity:    -6,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	hpexpireCmdMeta = DiceCmdMeta{
+		Name: "HPEXPIRE",
+		Info: `HPEXPIRE key milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+		Works exactly like HEXPIRE with the expiry given in milliseconds.`,
+		NewEval:  evalHPEXPIRE,
+		Arity:    -6,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	hpersistCmdMeta = DiceCmdMeta{
+		Name: "HPERSIST",
+		Info: `HPERSIST key FIELDS numfields field [field ...]
+		Removes the expiry of the fields of the hash.
+		Returns for every field -2 if it does not exist, -1 if it has no expiry and 1 if its expiry was removed.`,
+		NewEval:  evalHPERSIST,
+		Arity:    -5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	httlCmdMeta = DiceCmdMeta{
+		Name: "HTTL",
+		Info: `HTTL key FIELDS numfields field [field ...]
+		Returns for every field the remaining time to live in seconds,
+		-2 if it does not exist and -1 if it has no expiry.`,
+		NewEval:  evalHTTL,
+		Arity:    -5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	hgetexCmdMeta = DiceCmdMeta{
+		Name: "HGETEX",
+		Info: `HGETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]
+		FIELDS numfields field [field ...]
+		Returns the values of the fields of the hash and sets or removes their expiry.`,
+		NewEval:  evalHGETEX,
+		Arity:    -5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	hgetdelCmdMeta = DiceCmdMeta{
+		Name: "HGETDEL",
+		Info: `HGETDEL key FIELDS numfields field [field ...]
+		Returns the values of the fields of the hash and deletes them. The key is deleted once the hash is empty.`,
+		NewEval:  evalHGETDEL,
+		Arity:    -5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
 	hdelCmdMeta = DiceCmdMeta{
 		Name: "HDEL",
 		Info: `HDEL removes the specified fields from the hash stored at key.
@@ -776,10 +909,9 @@ var (
 		If key does not exist, it is treated as an empty hash and this command returns 0.
 		Returns
 		The number of fields that were removed from the hash, not including specified but non-existing fields.`,
-		NewEval:    evalHDEL,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalHDEL,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hscanCmdMeta = DiceCmdMeta{
 		Name: "HSCAN",
@@ -787,28 +919,25 @@ var (
 		It returns a cursor and a list of key-value pairs.
 		The cursor is used to paginate through the hash.
 		The command returns a cursor value of 0 when all the elements are iterated.`,
-		NewEval:    evalHSCAN,
-		IsMigrated: true,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalHSCAN,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	hexistsCmdMeta = DiceCmdMeta{
-		Name:       "HEXISTS",
-		Info:       `Returns if field is an existing field in the hash stored at key.`,
-		NewEval:    evalHEXISTS,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		Name:     "HEXISTS",
+		Info:     `Returns if field is an existing field in the hash stored at key.`,
+		NewEval:  evalHEXISTS,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 
 	objectCmdMeta = DiceCmdMeta{
 		Name: "OBJECT",
 		Info: `OBJECT subcommand [arguments [arguments ...]]
 		OBJECT command is used to inspect the internals of the Redis objects.`,
-		NewEval:    evalOBJECT,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 2},
-		IsMigrated: true,
+		NewEval:  evalOBJECT,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 2},
 	}
 
 	// Internal command used to spawn request across all shards (works internally with Touch command)
@@ -818,39 +947,38 @@ var (
 		Alters the last access time of a key(s).
 		A key is ignored if it does not exist.
 		This is for one by one counting and for multisharding`,
-		NewEval:    evalTouch,
-		IsMigrated: true,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalTouch,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 
 	lpushCmdMeta = DiceCmdMeta{
-		Name:       "LPUSH",
-		Info:       "LPUSH pushes values into the left side of the deque",
-		NewEval:    evalLPUSH,
-		IsMigrated: true,
-		Arity:      -3,
+		Name:     "LPUSH",
+		Info:     "LPUSH pushes values into the left side of the deque",
+		NewEval:  evalLPUSH,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	rpushCmdMeta = DiceCmdMeta{
-		Name:       "RPUSH",
-		Info:       "RPUSH pushes values into the right side of the deque",
-		NewEval:    evalRPUSH,
-		IsMigrated: true,
-		Arity:      -3,
+		Name:     "RPUSH",
+		Info:     "RPUSH pushes values into the right side of the deque",
+		NewEval:  evalRPUSH,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	lpopCmdMeta = DiceCmdMeta{
-		Name:       "LPOP",
-		Info:       "LPOP pops a value from the left side of the deque",
-		NewEval:    evalLPOP,
-		IsMigrated: true,
-		Arity:      2,
+		Name:     "LPOP",
+		Info:     "LPOP pops a value from the left side of the deque",
+		NewEval:  evalLPOP,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	rpopCmdMeta = DiceCmdMeta{
-		Name:       "RPOP",
-		Info:       "RPOP pops a value from the right side of the deque",
-		NewEval:    evalRPOP,
-		IsMigrated: true,
-		Arity:      2,
+		Name:     "RPOP",
+		Info:     "RPOP pops a value from the right side of the deque",
+		NewEval:  evalRPOP,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	llenCmdMeta = DiceCmdMeta{
 		Name: "LLEN",
@@ -858,25 +986,101 @@ var (
 		Returns the length of the list stored at key. If key does not exist,
 		it is interpreted as an empty list and 0 is returned.
 		An error is returned when the value stored at key is not a list.`,
-		NewEval:    evalLLEN,
-		IsMigrated: true,
-		Arity:      1,
+		NewEval:  evalLLEN,
+		Arity:    1,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	// Internal command used to spawn request across all shards (works internally with DBSIZE command)
 	singleDBSizeCmdMeta = DiceCmdMeta{
-		Name:       "SINGLEDBSIZE",
-		Info:       `DBSIZE Return the number of keys in the database`,
-		NewEval:    evalDBSize,
-		IsMigrated: true,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "SINGLEDBSIZE",
+		Info:     `DBSIZE Return the number of keys in the database`,
+		NewEval:  evalDBSize,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	infoCmdMeta = DiceCmdMeta{
+		Name: "INFO",
+		Info: `INFO [section [section ...]]
+		Returns information and statistics about the server. The sections are server, clients,
+		memory, stats, replication and keyspace; all of them are returned when none is given.`,
+		NewEval: evalINFO,
+		Arity:   -1,
+	}
+	// Internal command used to spawn request across all shards (works internally with INFO command)
+	singleInfoCmdMeta = DiceCmdMeta{
+		Name:    "SINGLEINFO",
+		Info:    `INFO Return information and statistics about the server`,
+		NewEval: evalSingleInfo,
+		Arity:   -1,
+	}
+	slowlogCmdMeta = DiceCmdMeta{
+		Name: "SLOWLOG",
+		Info: `SLOWLOG GET [count] | SLOWLOG LEN | SLOWLOG RESET
+		SLOWLOG GET returns the count most recent commands (10 by default, -1 for all) whose execution
+		took longer than slowlog.log_slower_than, SLOWLOG LEN returns the number of recorded commands
+		and SLOWLOG RESET clears the slow log.`,
+		NewEval:     evalSLOWLOG,
+		Arity:       -2,
+		SubCommands: []string{GET, Len, Reset},
+	}
+	// Internal command used to spawn request across all shards (works internally with SLOWLOG command)
+	singleSlowlogCmdMeta = DiceCmdMeta{
+		Name:    "SINGLESLOWLOG",
+		Info:    `SLOWLOG Return or reset the slow log of a shard`,
+		NewEval: evalSingleSlowlog,
+		Arity:   -2,
+	}
+	memoryCmdMeta = DiceCmdMeta{
+		Name: "MEMORY",
+		Info: `MEMORY STATS
+		MEMORY STATS returns the memory used by the server, the number of keys and, when
+		memory.key_prefix_interning is set, the number of key prefixes interned and the bytes saved.`,
+		NewEval:     evalMEMORY,
+		Arity:       -2,
+		SubCommands: []string{Stats},
+	}
+	// Internal command used to spawn request across all shards (works internally with MEMORY command)
+	singleMemoryCmdMeta = DiceCmdMeta{
+		Name:    "SINGLEMEMORY",
+		Info:    `MEMORY Return the memory stats of a shard`,
+		NewEval: evalSingleMemory,
+		Arity:   -2,
+	}
+	singleReloadCmdMeta = DiceCmdMeta{
+		Name:    "SINGLERELOAD",
+		Info:    `DEBUG RELOAD Reload the dataset of a shard through the DUMP encoding`,
+		NewEval: evalSingleReload,
+		Arity:   1,
+	}
+	singleKeySizesCmdMeta = DiceCmdMeta{
+		Name:    "SINGLEKEYSIZES",
+		Info:    `BIGKEYS Measure keys of a shard for the analyses of the big keys`,
+		NewEval: evalSingleKeySizes,
+		Arity:   -1,
+	}
+	// Internal command used to store the keys loaded from the origin in cache mode
+	cacheLoadCmdMeta = DiceCmdMeta{
+		Name:     "CACHELOAD",
+		Info:     `CACHELOAD key value milliseconds Store a key loaded from the origin unless it exists`,
+		NewEval:  evalCacheLoad,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	flushdbCmdMeta = DiceCmdMeta{
-		Name:       "FLUSHDB",
-		Info:       `FLUSHDB deletes all the keys of the currently selected DB`,
-		NewEval:    evalFLUSHDB,
-		IsMigrated: true,
-		Arity:      -1,
+		Name: "FLUSHDB",
+		Info: `FLUSHDB [ASYNC|SYNC]
+		Deletes all the keys of the currently selected DB.
+		ASYNC releases the keys in the background so that the shards are not blocked while a large keyspace is freed,
+		SYNC drops them before replying. Without an option, memory.lazyfree_lazy_user_flush decides.`,
+		NewEval: evalFLUSHDB,
+		Arity:   -1,
+	}
+	flushallCmdMeta = DiceCmdMeta{
+		Name: "FLUSHALL",
+		Info: `FLUSHALL [ASYNC|SYNC]
+		Deletes all the keys of all the DBs, the same as FLUSHDB as there is a single DB.`,
+		NewEval: evalFLUSHALL,
+		Arity:   -1,
 	}
 	bitposCmdMeta = DiceCmdMeta{
 		Name: "BITPOS",
@@ -903,9 +1107,9 @@ var (
 		 RESP encoded -1 in case the bit argument is 1 and the string is empty or composed of just zero bytes.
 		 RESP encoded -1 if we look for set bits and the string is empty or composed of just zero bytes, -1 is returned.
 		 RESP encoded -1 if a clear bit isn't found in the specified range.`,
-		IsMigrated: true,
-		NewEval:    evalBITPOS,
-		Arity:      -2,
+		NewEval:  evalBITPOS,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	saddCmdMeta = DiceCmdMeta{
 		Name: "SADD",
@@ -914,19 +1118,17 @@ var (
 		Specified members that are already a member of this set are ignored
 		Non existing keys are treated as empty sets.
 		An error is returned when the value stored at key is not a set.`,
-		NewEval:    evalSADD,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		NewEval:  evalSADD,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	smembersCmdMeta = DiceCmdMeta{
 		Name: "SMEMBERS",
 		Info: `SMEMBERS key
 		Returns all the members of the set value stored at key.`,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalSMEMBERS,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalSMEMBERS,
 	}
 	sremCmdMeta = DiceCmdMeta{
 		Name: "SREM",
@@ -934,97 +1136,95 @@ var (
 		Removes the specified members from the set stored at key.
 		Non existing keys are treated as empty sets.
 		An error is returned when the value stored at key is not a set.`,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalSREM,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalSREM,
+	}
+	smismemberCmdMeta = DiceCmdMeta{
+		Name: "SMISMEMBER",
+		Info: `SMISMEMBER key member [member ...]
+		Returns, for each member, 1 if it is a member of the set stored at key and 0 otherwise.`,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalSMISMEMBER,
 	}
 	scardCmdMeta = DiceCmdMeta{
 		Name: "SCARD",
 		Info: `SCARD key
 		Returns the number of elements of the set stored at key.
 		An error is returned when the value stored at key is not a set.`,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalSCARD,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalSCARD,
 	}
 	pfAddCmdMeta = DiceCmdMeta{
 		Name: "PFADD",
 		Info: `PFADD key [element [element ...]]
 		Adds elements to a HyperLogLog key. Creates the key if it doesn't exist.`,
-		NewEval:    evalPFADD,
-		IsMigrated: true,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalPFADD,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	pfCountCmdMeta = DiceCmdMeta{
 		Name: "PFCOUNT",
 		Info: `PFCOUNT key [key ...]
 		Returns the approximated cardinality of the set(s) observed by the HyperLogLog key(s).`,
-		NewEval:    evalPFCOUNT,
-		IsMigrated: true,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalPFCOUNT,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	pfMergeCmdMeta = DiceCmdMeta{
 		Name: "PFMERGE",
 		Info: `PFMERGE destkey [sourcekey [sourcekey ...]]
 		Merges one or more HyperLogLog values into a single key.`,
-		NewEval:    evalPFMERGE,
-		IsMigrated: true,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalPFMERGE,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonStrlenCmdMeta = DiceCmdMeta{
 		Name: "JSON.STRLEN",
 		Info: `JSON.STRLEN key [path]
 		Report the length of the JSON String at path in key`,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalJSONSTRLEN,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONSTRLEN,
 	}
 	hlenCmdMeta = DiceCmdMeta{
 		Name: "HLEN",
 		Info: `HLEN key
 		Returns the number of fields contained in the hash stored at key.`,
-		NewEval:    evalHLEN,
-		IsMigrated: true,
-		Arity:      2,
+		NewEval:  evalHLEN,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonnumincrbyCmdMeta = DiceCmdMeta{
-		Name:       "JSON.NUMINCRBY",
-		Info:       `Increment the number value stored at path by number.`,
-		NewEval:    evalJSONNUMINCRBY,
-		Arity:      3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
+		Name:     "JSON.NUMINCRBY",
+		Info:     `Increment the number value stored at path by number.`,
+		NewEval:  evalJSONNUMINCRBY,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	dumpkeyCMmdMeta = DiceCmdMeta{
 		Name: "DUMP",
 		Info: `Serialize the value stored at key in a Redis-specific format and return it to the user.
 				The returned value can be synthesized back into a Redis key using the RESTORE command.`,
-		NewEval:    evalDUMP,
-		IsMigrated: true,
-		Arity:      1,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalDUMP,
+		Arity:    1,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	restorekeyCmdMeta = DiceCmdMeta{
 		Name: "RESTORE",
 		Info: `Serialize the value stored at key in a Redis-specific format and return it to the user.
 				The returned value can be synthesized back into a Redis key using the RESTORE command.`,
-		NewEval:    evalRestore,
-		IsMigrated: true,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalRestore,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	typeCmdMeta = DiceCmdMeta{
-		Name:       "TYPE",
-		Info:       `Returns the string representation of the type of the value stored at key. The different types that can be returned are: string, list, set, zset, hash and stream.`,
-		IsMigrated: true,
-		NewEval:    evalTYPE,
-		Arity:      1,
+		Name:    "TYPE",
+		Info:    `Returns the string representation of the type of the value stored at key. The different types that can be returned are: string, list, set, zset, hash and stream.`,
+		NewEval: evalTYPE,
+		Arity:   1,
 
 		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
@@ -1038,18 +1238,16 @@ var (
 		The value for the queried key should be of integer format,
 		if not INCRBY returns encoded error response.
 		evalINCRBY returns the incremented value for the key if there are no errors.`,
-		NewEval:    evalINCRBY,
-		IsMigrated: true,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1, Step: 1},
+		NewEval:  evalINCRBY,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1, Step: 1},
 	}
 	getRangeCmdMeta = DiceCmdMeta{
-		Name:       "GETRANGE",
-		Info:       `Returns a substring of the string stored at a key.`,
-		IsMigrated: true,
-		NewEval:    evalGETRANGE,
-		Arity:      3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "GETRANGE",
+		Info:     `Returns a substring of the string stored at a key.`,
+		NewEval:  evalGETRANGE,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	setexCmdMeta = DiceCmdMeta{
 		Name: "SETEX",
@@ -1059,25 +1257,149 @@ var (
 		Returns encoded error response if expiry time value in not integer
 		Returns encoded OK RESP once new entry is added
 		If the key already exists then the value and expiry will be overwritten`,
-		Arity:      3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalSETEX,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalSETEX,
+	}
+	psetexCmdMeta = DiceCmdMeta{
+		Name: "PSETEX",
+		Info: `PSETEX key milliseconds value
+		PSETEX works exactly like SETEX with the sole difference that the expire time is specified in milliseconds.
+		The value and its expiry are written atomically.`,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalPSETEX,
+	}
+	setnxCmdMeta = DiceCmdMeta{
+		Name: "SETNX",
+		Info: `SETNX key value
+		Sets key to hold value if key does not exist, in that case it is equal to SET.
+		Returns 1 if the key was set, 0 if it already existed.`,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalSETNX,
+	}
+	casCmdMeta = DiceCmdMeta{
+		Name: "CAS",
+		Info: `CAS key expected value
+		Sets the key to the value only if it holds the expected one, in a single atomic step.
+		The expiry of the key is discarded.
+		Returns 1 if the key was set, 0 otherwise.`,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalCAS,
+	}
+	delifeqCmdMeta = DiceCmdMeta{
+		Name: "DELIFEQ",
+		Info: `DELIFEQ key value
+		Deletes the key only if it holds the given value, in a single atomic step.
+		Used to release a lock only when the caller still owns it.
+		Returns 1 if the key was deleted, 0 otherwise.`,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalDELIFEQ,
+	}
+	pexpireifeqCmdMeta = DiceCmdMeta{
+		Name: "PEXPIREIFEQ",
+		Info: `PEXPIREIFEQ key value milliseconds
+		Sets the expiry of the key in milliseconds only if it holds the given value, in a single atomic step.
+		Used to extend a lock only when the caller still owns it.
+		Returns 1 if the expiry was set, 0 otherwise.`,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalPEXPIREIFEQ,
+	}
+	lockCmdMeta = DiceCmdMeta{
+		Name: "LOCK",
+		Info: `LOCK key milliseconds
+		Acquires the lock of the key for a lease of the given milliseconds, unless the key exists.
+		The key holds the fencing token of the owner, greater than every token issued before, until the lock
+		is released with UNLOCK or its lease expires. PEXPIREIFEQ key token milliseconds extends the lease.
+		Returns the fencing token if the lock was acquired, nil if it is held.`,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalLOCK,
+	}
+	unlockCmdMeta = DiceCmdMeta{
+		Name: "UNLOCK",
+		Info: `UNLOCK key token
+		Releases the lock of the key only if it is held with the given fencing token.
+		Returns 1 if the lock was released, 0 otherwise.`,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalUNLOCK,
+	}
+	undeleteCmdMeta = DiceCmdMeta{
+		Name: "UNDELETE",
+		Info: `UNDELETE key
+		Restores the key deleted by DEL from the trash, with the expiry it had, when trash.enabled is set.
+		The deleted keys are kept for trash.retention, the oldest ones being purged beyond trash.max_keys.
+		Returns 1 if the key was restored, 0 if it exists, is not in the trash or expired since it was deleted.`,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalUNDELETE,
+	}
+	getAtCmdMeta = DiceCmdMeta{
+		Name: "GETAT",
+		Info: `GETAT key unix-time-milliseconds
+		Returns the value the key held at the time, from its history kept when history.enabled is set and the
+		key matches history.patterns. The time must be within history.retention.
+		Returns NIL if the key did not exist then or held another type than a string.`,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalGETAT,
+	}
+	historyCmdMeta = DiceCmdMeta{
+		Name: "HISTORY",
+		Info: `HISTORY key
+		Returns the values the key held within history.retention, oldest first, as pairs of the time of the
+		change in unix milliseconds and the value, NIL once the key was deleted or held another type.`,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalHISTORY,
 	}
 	hrandfieldCmdMeta = DiceCmdMeta{
-		Name:       "HRANDFIELD",
-		Info:       `Returns one or more random fields from a hash.`,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalHRANDFIELD,
+		Name:     "HRANDFIELD",
+		Info:     `Returns one or more random fields from a hash.`,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalHRANDFIELD,
+	}
+	srandmemberCmdMeta = DiceCmdMeta{
+		Name: "SRANDMEMBER",
+		Info: `SRANDMEMBER key [count]
+		Returns one or more random members from the set stored at key.
+		A positive count returns distinct members, a negative count allows the same member to be returned multiple times.`,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalSRANDMEMBER,
+	}
+	zrandmemberCmdMeta = DiceCmdMeta{
+		Name: "ZRANDMEMBER",
+		Info: `ZRANDMEMBER key [count [WITHSCORES]]
+		Returns one or more random members from the sorted set stored at key.
+		A positive count returns distinct members, a negative count allows the same member to be returned multiple times.
+		The WITHSCORES option returns every member followed by its score.`,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZRANDMEMBER,
 	}
 	appendCmdMeta = DiceCmdMeta{
-		Name:       "APPEND",
-		Info:       `Appends a string to the value of a key. Creates the key if it doesn't exist.`,
-		IsMigrated: true,
-		NewEval:    evalAPPEND,
-		Arity:      2,
+		Name:     "APPEND",
+		Info:     `Appends a string to the value of a key. Creates the key if it doesn't exist.`,
+		NewEval:  evalAPPEND,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+	}
+	setRangeCmdMeta = DiceCmdMeta{
+		Name: "SETRANGE",
+		Info: `SETRANGE key offset value
+		Overwrites part of the string stored at key, starting at the specified offset, for the entire length of value.
+		The string is padded with zero bytes if the offset is larger than its current length.
+		Returns the length of the string after it was modified.`,
+		NewEval:  evalSETRANGE,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	zaddCmdMeta = DiceCmdMeta{
 		Name: "ZADD",
@@ -1085,19 +1407,18 @@ var (
 		Adds all the specified members with the specified scores to the sorted set stored at key.
 		Options: NX, XX, CH, INCR
 		Returns the number of elements added to the sorted set, not including elements already existing for which the score was updated.`,
-		Arity:      -4,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalZADD,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZADD,
 	}
 	zcountCmdMeta = DiceCmdMeta{
 		Name: "ZCOUNT",
 		Info: `ZCOUNT key min max
 		Counts the number of members in a sorted set with scores between min and max (inclusive).
 		Use -inf and +inf for unbounded ranges. Returns 0 if the key does not exist.`,
-		Arity:      4,
-		IsMigrated: true,
-		NewEval:    evalZCOUNT,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZCOUNT,
 	}
 	zrangeCmdMeta = DiceCmdMeta{
 		Name: "ZRANGE",
@@ -1107,10 +1428,9 @@ var (
 		Both start and stop are 0-based indexes, where 0 is the first element, 1 is the next element and so on.
 		These indexes can also be negative numbers indicating offsets from the end of the sorted set, with -1 being the last element of the sorted set, -2 the penultimate element and so on.
 		Returns the specified range of elements in the sorted set.`,
-		Arity:      -4,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalZRANGE,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZRANGE,
 	}
 	zpopmaxCmdMeta = DiceCmdMeta{
 		Name: "ZPOPMAX",
@@ -1119,10 +1439,9 @@ var (
 		If count is not provided '1' is considered by default.
 		The element with the highest score is removed first
 		if two elements have same score then the element which is lexicographically higher is popped first`,
-		Arity:      -1,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalZPOPMAX,
+		Arity:    -1,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZPOPMAX,
 	}
 	zpopminCmdMeta = DiceCmdMeta{
 		Name: "ZPOPMIN",
@@ -1131,10 +1450,9 @@ var (
 		If multiple members have the same score, the one that comes first alphabetically is returned.
 		You can also specify a count to remove and return multiple members at once.
 		If the set is empty, it returns an empty result.`,
-		Arity:      -1,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalZPOPMIN,
+		Arity:    -1,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZPOPMIN,
 	}
 	zrankCmdMeta = DiceCmdMeta{
 		Name: "ZRANK",
@@ -1142,29 +1460,26 @@ var (
 		Returns the rank of member in the sorted set stored at key, with the scores ordered from low to high.
 		The rank (or index) is 0-based, which means that the member with the lowest score has rank 0.
 		The optional WITHSCORE argument supplements the command's reply with the score of the element returned.`,
-		Arity:      -2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalZRANK,
+		Arity:    -2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZRANK,
 	}
 	zcardCmdMeta = DiceCmdMeta{
 		Name: "ZCARD",
 		Info: `ZCARD key
 		Returns the sorted set cardinality (number of elements) of the sorted set stored at key.`,
-		Arity:      2,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalZCARD,
+		Arity:    2,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZCARD,
 	}
 	zremCmdMeta = DiceCmdMeta{
 		Name: "ZREM",
 		Info: `ZREM key member [member ...]
 		Removes the specified members from the sorted set stored at key. Non existing members are ignored.
 		An error is returned when key exists and does not hold a sorted set.`,
-		Arity:      -3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalZREM,
+		Arity:    -3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalZREM,
 	}
 	bitfieldCmdMeta = DiceCmdMeta{
 		Name: "BITFIELD",
@@ -1186,19 +1501,17 @@ var (
 		There is another subcommand that only changes the behavior of successive
 		INCRBY and SET subcommands calls by setting the overflow behavior:
 		OVERFLOW [WRAP|SAT|FAIL]`,
-		Arity:      -1,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalBITFIELD,
+		Arity:    -1,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalBITFIELD,
 	}
 	bitfieldroCmdMeta = DiceCmdMeta{
 		Name: "BITFIELD_RO",
 		Info: `It is read-only variant of the BITFIELD command.
 		It is like the original BITFIELD but only accepts GET subcommand.`,
-		Arity:      -1,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalBITFIELDRO,
+		Arity:    -1,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalBITFIELDRO,
 	}
 	hincrbyFloatCmdMeta = DiceCmdMeta{
 		Name: "HINCRBYFLOAT",
@@ -1208,42 +1521,37 @@ var (
 		If the field contains a value of wrong type or specified increment
 		is not parsable as floating point number, then an error occurs.
 		`,
-		Arity:      -4,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
-		IsMigrated: true,
-		NewEval:    evalHINCRBYFLOAT,
+		Arity:    -4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
+		NewEval:  evalHINCRBYFLOAT,
 	}
 	geoAddCmdMeta = DiceCmdMeta{
-		Name:       "GEOADD",
-		Info:       `Adds one or more members to a geospatial index. The key is created if it doesn't exist.`,
-		Arity:      -5,
-		IsMigrated: true,
-		NewEval:    evalGEOADD,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "GEOADD",
+		Info:     `Adds one or more members to a geospatial index. The key is created if it doesn't exist.`,
+		Arity:    -5,
+		NewEval:  evalGEOADD,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	geoDistCmdMeta = DiceCmdMeta{
-		Name:       "GEODIST",
-		Info:       `Returns the distance between two members in the geospatial index.`,
-		Arity:      -4,
-		IsMigrated: true,
-		NewEval:    evalGEODIST,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "GEODIST",
+		Info:     `Returns the distance between two members in the geospatial index.`,
+		Arity:    -4,
+		NewEval:  evalGEODIST,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	geoPosCmdMeta = DiceCmdMeta{
-		Name:       "GEOPOS",
-		Info:       `Returns the latitude and longitude of the members identified by the particular index.`,
-		Arity:      -3,
-		NewEval:    evalGEOPOS,
-		IsMigrated: true,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "GEOPOS",
+		Info:     `Returns the latitude and longitude of the members identified by the particular index.`,
+		Arity:    -3,
+		NewEval:  evalGEOPOS,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	geoHashCmdMeta = DiceCmdMeta{
-		Name:       "GEOHASH",
-		Info:       `Return Geohash strings representing the position of one or more elements representing a geospatial index`,
-		Arity:      -2,
-		IsMigrated: true,
-		NewEval:    evalGEOHASH,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "GEOHASH",
+		Info:     `Return Geohash strings representing the position of one or more elements representing a geospatial index`,
+		Arity:    -2,
+		NewEval:  evalGEOHASH,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	jsonstrappendCmdMeta = DiceCmdMeta{
 		Name: "JSON.STRAPPEND",
@@ -1251,60 +1559,53 @@ var (
 		Append the JSON string values to the string at path
 		Returns an array of integer replies for each path, the string's new length, or nil, if the matching JSON value is not a string.
 		Error reply: If the value at path is not a string or if the key doesn't exist.`,
-		NewEval:    evalJSONSTRAPPEND,
-		IsMigrated: true,
-		Arity:      3,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalJSONSTRAPPEND,
+		Arity:    3,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	cmsInitByDimCmdMeta = DiceCmdMeta{
-		Name:       "CMS.INITBYDIM",
-		Info:       `Sets up count min sketch`,
-		Arity:      3,
-		IsMigrated: true,
-		NewEval:    evalCMSINITBYDIM,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "CMS.INITBYDIM",
+		Info:     `Sets up count min sketch`,
+		Arity:    3,
+		NewEval:  evalCMSINITBYDIM,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	cmsInitByProbCmdMeta = DiceCmdMeta{
-		Name:       "CMS.INITBYPROB",
-		Info:       `Sets up count min sketch with given error rate and probability`,
-		Arity:      3,
-		IsMigrated: true,
-		NewEval:    evalCMSINITBYPROB,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "CMS.INITBYPROB",
+		Info:     `Sets up count min sketch with given error rate and probability`,
+		Arity:    3,
+		NewEval:  evalCMSINITBYPROB,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	cmsInfoCmdMeta = DiceCmdMeta{
-		Name:       "CMS.INFO",
-		Info:       `Get info about count min sketch`,
-		Arity:      1,
-		IsMigrated: true,
-		NewEval:    evalCMSINFO,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "CMS.INFO",
+		Info:     `Get info about count min sketch`,
+		Arity:    1,
+		NewEval:  evalCMSINFO,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	cmsQueryCmdMeta = DiceCmdMeta{
-		Name:       "CMS.QUERY",
-		Info:       `Query count min sketch with for given list of keys`,
-		Arity:      -2,
-		IsMigrated: true,
-		NewEval:    evalCMSQuery,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "CMS.QUERY",
+		Info:     `Query count min sketch with for given list of keys`,
+		Arity:    -2,
+		NewEval:  evalCMSQuery,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	cmsIncrByCmdMeta = DiceCmdMeta{
-		Name:       "CMS.INCRBY",
-		Info:       `Increase count of the list of keys to count min sketch`,
-		Arity:      -3,
-		IsMigrated: true,
-		NewEval:    evalCMSIncrBy,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Name:     "CMS.INCRBY",
+		Info:     `Increase count of the list of keys to count min sketch`,
+		Arity:    -3,
+		NewEval:  evalCMSIncrBy,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	cmsMergeCmdMeta = DiceCmdMeta{
 		Name: "CMS.MERGE",
 		Info: `Merges several sketches into one sketch.
 				 All sketches must have identical width and depth.
 				 Weights can be used to multiply certain sketches. Default weight is 1.`,
-		Arity:      -3,
-		IsMigrated: true,
-		NewEval:    evalCMSMerge,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		Arity:    -3,
+		NewEval:  evalCMSMerge,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	linsertCmdMeta = DiceCmdMeta{
 		Name: "LINSERT",
@@ -1320,10 +1621,9 @@ var (
 			0 when the key doesn't exist.
 			-1 when the pivot wasn't found.
 		`,
-		NewEval:    evalLINSERT,
-		IsMigrated: true,
-		Arity:      5,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalLINSERT,
+		Arity:    5,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 	lrangeCmdMeta = DiceCmdMeta{
 		Name: "LRANGE",
@@ -1342,10 +1642,9 @@ var (
 		Returns:
 			Array reply: a list of elements in the specified range, or an empty array if the key doesn't exist.
 		`,
-		NewEval:    evalLRANGE,
-		IsMigrated: true,
-		Arity:      4,
-		KeySpecs:   KeySpecs{BeginIndex: 1},
+		NewEval:  evalLRANGE,
+		Arity:    4,
+		KeySpecs: KeySpecs{BeginIndex: 1},
 	}
 )
 
@@ -1354,6 +1653,7 @@ func init() {
 	PreProcessing["RENAME"] = evalGET
 
 	DiceCmds["ABORT"] = abortCmdMeta
+	DiceCmds["DEBUG"] = debugCmdMeta
 	DiceCmds["APPEND"] = appendCmdMeta
 	DiceCmds["AUTH"] = authCmdMeta
 	DiceCmds["BF.ADD"] = bfaddCmdMeta
@@ -1364,7 +1664,9 @@ func init() {
 	DiceCmds["BITFIELD"] = bitfieldCmdMeta
 	DiceCmds["BITFIELD_RO"] = bitfieldroCmdMeta
 	DiceCmds["BITPOS"] = bitposCmdMeta
+	DiceCmds["CAS"] = casCmdMeta
 	DiceCmds["CLIENT"] = clientCmdMeta
+	DiceCmds["CONFIG"] = configCmdMeta
 	DiceCmds["COMMAND"] = commandCmdMeta
 	DiceCmds["COMMAND|COUNT"] = commandCountCmdMeta
 	DiceCmds["COMMAND|GETKEYS"] = commandGetKeysCmdMeta
@@ -1377,12 +1679,16 @@ func init() {
 	DiceCmds["DECR"] = decrCmdMeta
 	DiceCmds["DECRBY"] = decrByCmdMeta
 	DiceCmds["DEL"] = delCmdMeta
+	DiceCmds["DELIFEQ"] = delifeqCmdMeta
 	DiceCmds["DUMP"] = dumpkeyCMmdMeta
 	DiceCmds["ECHO"] = echoCmdMeta
 	DiceCmds["EXISTS"] = existsCmdMeta
 	DiceCmds["EXPIRE"] = expireCmdMeta
 	DiceCmds["EXPIREAT"] = expireatCmdMeta
 	DiceCmds["EXPIRETIME"] = expiretimeCmdMeta
+	DiceCmds["PEXPIRE"] = pexpireCmdMeta
+	DiceCmds["EXPORT"] = exportCmdMeta
+	DiceCmds["FLUSHALL"] = flushallCmdMeta
 	DiceCmds["FLUSHDB"] = flushdbCmdMeta
 	DiceCmds["GEOADD"] = geoAddCmdMeta
 	DiceCmds["GEODIST"] = geoDistCmdMeta
@@ -1397,21 +1703,28 @@ func init() {
 	DiceCmds["HDEL"] = hdelCmdMeta
 	DiceCmds["HELLO"] = helloCmdMeta
 	DiceCmds["HEXISTS"] = hexistsCmdMeta
+	DiceCmds["HEXPIRE"] = hexpireCmdMeta
 	DiceCmds["HGET"] = hgetCmdMeta
 	DiceCmds["HGETALL"] = hgetAllCmdMeta
+	DiceCmds["HGETDEL"] = hgetdelCmdMeta
+	DiceCmds["HGETEX"] = hgetexCmdMeta
 	DiceCmds["HINCRBY"] = hincrbyCmdMeta
 	DiceCmds["HINCRBYFLOAT"] = hincrbyFloatCmdMeta
 	DiceCmds["HKEYS"] = hkeysCmdMeta
 	DiceCmds["HLEN"] = hlenCmdMeta
 	DiceCmds["HMGET"] = hmgetCmdMeta
 	DiceCmds["HMSET"] = hmsetCmdMeta
+	DiceCmds["HPERSIST"] = hpersistCmdMeta
+	DiceCmds["HPEXPIRE"] = hpexpireCmdMeta
 	DiceCmds["HRANDFIELD"] = hrandfieldCmdMeta
 	DiceCmds["HSCAN"] = hscanCmdMeta
 	DiceCmds["HSET"] = hsetCmdMeta
 	DiceCmds["HSETNX"] = hsetnxCmdMeta
 	DiceCmds["HSTRLEN"] = hstrLenCmdMeta
+	DiceCmds["HTTL"] = httlCmdMeta
 	DiceCmds["HVALS"] = hValsCmdMeta
 	DiceCmds["INCR"] = incrCmdMeta
+	DiceCmds["INFO"] = infoCmdMeta
 	DiceCmds["INCRBYFLOAT"] = incrByFloatCmdMeta
 	DiceCmds["INCRBY"] = incrbyCmdMeta
 	DiceCmds["JSON.ARRAPPEND"] = jsonarrappendCmdMeta
@@ -1435,15 +1748,20 @@ func init() {
 	DiceCmds["JSON.TOGGLE"] = jsontoggleCmdMeta
 	DiceCmds["JSON.TYPE"] = jsontypeCmdMeta
 	DiceCmds["LATENCY"] = latencyCmdMeta
+	DiceCmds["LOLWUT"] = lolwutCmdMeta
 	DiceCmds["LLEN"] = llenCmdMeta
 	DiceCmds["LPOP"] = lpopCmdMeta
+	DiceCmds["LOCK"] = lockCmdMeta
 	DiceCmds["LPUSH"] = lpushCmdMeta
+	DiceCmds["MSET"] = msetCmdMeta
 	DiceCmds["OBJECT"] = objectCmdMeta
 	DiceCmds["PERSIST"] = persistCmdMeta
+	DiceCmds["PEXPIREIFEQ"] = pexpireifeqCmdMeta
 	DiceCmds["PFADD"] = pfAddCmdMeta
 	DiceCmds["PFCOUNT"] = pfCountCmdMeta
 	DiceCmds["PFMERGE"] = pfMergeCmdMeta
 	DiceCmds["PING"] = pingCmdMeta
+	DiceCmds["PSETEX"] = psetexCmdMeta
 	DiceCmds["PTTL"] = pttlCmdMeta
 	DiceCmds["RESTORE"] = restorekeyCmdMeta
 	DiceCmds["RPOP"] = rpopCmdMeta
@@ -1453,17 +1771,36 @@ func init() {
 	DiceCmds["SET"] = setCmdMeta
 	DiceCmds["SETBIT"] = setBitCmdMeta
 	DiceCmds["SETEX"] = setexCmdMeta
+	DiceCmds["SETNX"] = setnxCmdMeta
+	DiceCmds["SETRANGE"] = setRangeCmdMeta
+	DiceCmds["SHUTDOWN"] = shutdownCmdMeta
+	DiceCmds["SINK"] = sinkCmdMeta
+	DiceCmds["NAMESPACE"] = namespaceCmdMeta
+	DiceCmds["MODULE"] = moduleCmdMeta
+	DiceCmds["READAFTER"] = readafterCmdMeta
+	DiceCmds["TRACE"] = traceCmdMeta
+	DiceCmds["HOTKEYS"] = hotkeysCmdMeta
+	DiceCmds["BIGKEYS"] = bigkeysCmdMeta
 	DiceCmds["SLEEP"] = sleepCmdMeta
+	DiceCmds["SLOWLOG"] = slowlogCmdMeta
+	DiceCmds["MEMORY"] = memoryCmdMeta
 	DiceCmds["SMEMBERS"] = smembersCmdMeta
+	DiceCmds["SMISMEMBER"] = smismemberCmdMeta
+	DiceCmds["SRANDMEMBER"] = srandmemberCmdMeta
 	DiceCmds["SREM"] = sremCmdMeta
 	DiceCmds["TTL"] = ttlCmdMeta
 	DiceCmds["TYPE"] = typeCmdMeta
+	DiceCmds["UNDELETE"] = undeleteCmdMeta
+	DiceCmds["GETAT"] = getAtCmdMeta
+	DiceCmds["HISTORY"] = historyCmdMeta
+	DiceCmds["UNLOCK"] = unlockCmdMeta
 	DiceCmds["ZADD"] = zaddCmdMeta
 	DiceCmds["ZCOUNT"] = zcountCmdMeta
 	DiceCmds["ZRANGE"] = zrangeCmdMeta
 	DiceCmds["ZPOPMAX"] = zpopmaxCmdMeta
 	DiceCmds["ZPOPMIN"] = zpopminCmdMeta
 	DiceCmds["ZRANK"] = zrankCmdMeta
+	DiceCmds["ZRANDMEMBER"] = zrandmemberCmdMeta
 	DiceCmds["ZCARD"] = zcardCmdMeta
 	DiceCmds["ZREM"] = zremCmdMeta
 	DiceCmds["JSON.STRAPPEND"] = jsonstrappendCmdMeta
@@ -1479,6 +1816,17 @@ func init() {
 	DiceCmds["SINGLETOUCH"] = singleTouchCmdMeta
 	DiceCmds["SINGLEDBSIZE"] = singleDBSizeCmdMeta
 	DiceCmds["SINGLEKEYS"] = singleKeysCmdMeta
+	DiceCmds["SINGLEEXPORT"] = singleExportCmdMeta
+	DiceCmds["SINGLEINFO"] = singleInfoCmdMeta
+	DiceCmds["SINGLESLOWLOG"] = singleSlowlogCmdMeta
+	DiceCmds["SINGLEMEMORY"] = singleMemoryCmdMeta
+	DiceCmds["SINGLERELOAD"] = singleReloadCmdMeta
+	DiceCmds["SINGLEKEYSIZES"] = singleKeySizesCmdMeta
+	DiceCmds["CACHELOAD"] = cacheLoadCmdMeta
+
+	for name, meta := range DiceCmds {
+		cmd.RegisterKeySpec(name, meta.KeySpecs)
+	}
 }
 
 // Function to convert DiceCmdMeta to []interface{}
@@ -37,6 +37,7 @@ const (
 	CH              string = "CH"
 	INCR            string = "INCR"
 	KeepTTL         string = "KEEPTTL"
+	IfVersion       string = "IFVERSION"
 	Sync            string = "SYNC"
 	Async           string = "ASYNC"
 	Help            string = "HELP"
@@ -47,6 +48,12 @@ const (
 	List            string = "LIST"
 	Info            string = "INFO"
 	Docs            string = "DOCS"
+	Rewrite         string = "REWRITE"
+	ResetStat       string = "RESETSTAT"
+	Len             string = "LEN"
+	Reset           string = "RESET"
+	Latest          string = "LATEST"
+	History         string = "HISTORY"
 	null            string = "null"
 	WithValues      string = "WITHVALUES"
 	WithScores      string = "WITHSCORES"
@@ -66,4 +73,6 @@ const (
 	FILTERS         string = "FILTER"
 	ITEMS           string = "ITEMS"
 	EXPANSION       string = "EXPANSION"
+	Fields          string = "FIELDS"
+	Stats           string = "STATS"
 )
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"errors"
 	"hash/crc64"
+	"sort"
 
 	"github.com/dicedb/dice/internal/eval/sortedset"
 	"github.com/dicedb/dice/internal/object"
@@ -230,7 +231,13 @@ func writeSet(buf *bytes.Buffer, setItems map[string]struct{}) error {
 	if err := binary.Write(buf, binary.BigEndian, setLen); err != nil {
 		return err
 	}
+	// The items are written in order, so that a set is always encoded the same
+	items := make([]string, 0, len(setItems))
 	for item := range setItems {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	for _, item := range items {
 		if err := writeString(buf, item); err != nil {
 			return err
 		}
@@ -243,3 +250,15 @@ func appendChecksum(data []byte) []byte {
 	binary.BigEndian.PutUint64(checksumBuf, checksum)
 	return append(data, checksumBuf...)
 }
+
+// ObjectCodec encodes the objects the way DUMP does. It is the codec of the disk tier of the store, the types
+// DUMP does not support stay in memory.
+type ObjectCodec struct{}
+
+func (ObjectCodec) Marshal(obj *object.Obj) ([]byte, error) {
+	return rdbSerialize(obj)
+}
+
+func (ObjectCodec) Unmarshal(data []byte) (*object.Obj, error) {
+	return rdbDeserialize(data)
+}
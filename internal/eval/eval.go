@@ -18,7 +18,9 @@ package eval
 
 import (
 	"fmt"
+	"math/rand"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dicedb/dice/config"
@@ -37,14 +39,13 @@ const (
 
 var (
 	TxnCommands       map[string]bool
-	serverID          string
 	diceCommandsCount int
 )
 
 // EvalResponse represents the response of an evaluation operation for a command from store.
 // It contains the sequence ID, the result of the store operation, and any error encountered during the operation.
 type EvalResponse struct {
-	Result interface{} // Result holds the outcome of the Store operation. Currently, it is expected to be of type []byte, but this may change in the future.
+	Result interface{} // Result holds the outcome of the Store operation, normalized by the frontends with clientio.NewReply.
 	Error  error       // Error holds any error that occurred during the operation. If no error, it will be nil.
 }
 
@@ -78,6 +79,14 @@ func makeEvalError(err error) *EvalResponse {
 	}
 }
 
+// Reply returns the response normalized into the reply rendered by the frontends.
+func (e *EvalResponse) Reply() clientio.Reply {
+	if e.Error != nil {
+		return clientio.ErrorReply(e.Error)
+	}
+	return clientio.NewReply(e.Result)
+}
+
 type jsonOperation string
 
 const (
@@ -96,45 +105,38 @@ func init() {
 	TxnCommands = map[string]bool{"EXEC": true, "DISCARD": true}
 }
 
-// evalPING returns with an encoded "PONG"
+// evalPING returns "PONG"
 // If any message is added with the ping command,
 // the message will be returned.
-func evalPING(args []string, store *dstore.Store) []byte {
-	var b []byte
-
+func evalPING(args []string, store *dstore.Store) *EvalResponse {
 	if len(args) >= 2 {
-		return diceerrors.NewErrArity("PING")
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("PING"))
 	}
 
 	if len(args) == 0 {
-		b = clientio.Encode("PONG", true)
-	} else {
-		b = clientio.Encode(args[0], false)
+		return makeEvalResult("PONG")
 	}
-
-	return b
+	return makeEvalResult(args[0])
 }
 
 // evalECHO returns the argument passed by the user
-func evalECHO(args []string, store *dstore.Store) []byte {
+func evalECHO(args []string, store *dstore.Store) *EvalResponse {
 	if len(args) != 1 {
-		return diceerrors.NewErrArity("ECHO")
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("ECHO"))
 	}
 
-	return clientio.Encode(args[0], false)
+	return makeEvalResult(args[0])
 }
 
-// EvalAUTH returns with an encoded "OK" if the user is authenticated
-// If the user is not authenticated, it returns with an encoded error message
+// EvalAUTH returns "OK" if the user is authenticated
+// If the user is not authenticated, it returns with an error
 // TODO: Needs to be removed after http and websocket migrated to the multithreading
-func EvalAUTH(args []string, c *comm.Client) []byte {
-	var err error
-
-	if config.DiceConfig.Auth.Password == "" {
-		return diceerrors.NewErrWithMessage("AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?")
+func EvalAUTH(args []string, c *comm.Client) *EvalResponse {
+	if config.DiceConfig().Auth.Password == "" {
+		return makeEvalError(diceerrors.ErrGeneral(diceerrors.ErrAuth.Error()))
 	}
 
-	username := config.DiceConfig.Auth.UserName
+	username := config.DiceConfig().Auth.UserName
 	var password string
 
 	if len(args) == 1 {
@@ -142,45 +144,141 @@ func EvalAUTH(args []string, c *comm.Client) []byte {
 	} else if len(args) == 2 {
 		username, password = args[0], args[1]
 	} else {
-		return diceerrors.NewErrArity("AUTH")
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("AUTH"))
 	}
 
-	if err = c.Session.Validate(username, password); err != nil {
-		return clientio.Encode(err, false)
+	if err := c.Session.Validate(username, password); err != nil {
+		return makeEvalError(err)
 	}
-	return clientio.RespOK
+	return makeEvalResult(clientio.OK)
 }
 
-func evalHELLO(args []string, store *dstore.Store) []byte {
-	if len(args) > 1 {
-		return diceerrors.NewErrArity("HELLO")
+// evalHELLO replies to HELLO sent over HTTP and WebSocket. These requests are not tied to a
+// connection, so their client id is 0 and the AUTH option is refused.
+func evalHELLO(args []string, store *dstore.Store) *EvalResponse {
+	opts, err := ParseHello(args)
+	if err != nil {
+		return makeEvalError(err)
+	}
+	if opts.Auth {
+		return makeEvalError(diceerrors.ErrGeneral("HELLO AUTH is only supported over RESP, use the AUTH command"))
+	}
+
+	return makeEvalResult(HelloReply(0))
+}
+
+// HelloOptions are the arguments of HELLO [protover [AUTH username password] [SETNAME clientname]].
+type HelloOptions struct {
+	Auth       bool
+	Username   string
+	Password   string
+	ClientName string
+}
+
+// ParseHello parses the arguments of HELLO. Only the RESP2 protocol is supported.
+func ParseHello(args []string) (HelloOptions, error) {
+	var opts HelloOptions
+	if len(args) == 0 {
+		return opts, nil
+	}
+
+	protover, err := strconv.Atoi(args[0])
+	if err != nil {
+		return opts, diceerrors.ErrGeneral("Protocol version is not an integer or out of range")
+	}
+	if protover != 2 {
+		return opts, diceerrors.ErrNoProto
 	}
 
-	var resp []interface{}
-	serverID = fmt.Sprintf("%s:%d", config.DiceConfig.RespServer.Addr, config.DiceConfig.RespServer.Port)
-	resp = append(resp,
+	for i := 1; i < len(args); i++ {
+		switch option := strings.ToUpper(args[i]); {
+		case option == "AUTH" && i+2 < len(args):
+			opts.Auth, opts.Username, opts.Password = true, args[i+1], args[i+2]
+			i += 2
+		case option == "SETNAME" && i+1 < len(args):
+			opts.ClientName = args[i+1]
+			i++
+		default:
+			return opts, diceerrors.ErrFormatted("Syntax error in HELLO option '%s'", args[i])
+		}
+	}
+	return opts, nil
+}
+
+// HelloReply returns the server and connection properties replied by HELLO.
+func HelloReply(clientID uint64) []interface{} {
+	return []interface{}{
+		"server", "dicedb",
+		"version", config.DiceDBVersion,
 		"proto", 2,
-		"id", serverID,
+		"id", clientID,
 		"mode", "standalone",
 		"role", "master",
-		"modules", []interface{}{})
+		"modules", []interface{}{},
+	}
+}
 
-	return clientio.Encode(resp, false)
+// evalLOLWUT replies with a piece of generative art and the version of the server.
+// The VERSION option is accepted for compatibility, there is a single piece of art.
+func evalLOLWUT(args []string, store *dstore.Store) *EvalResponse {
+	switch {
+	case len(args) == 0:
+	case len(args) == 2 && strings.EqualFold(args[0], "VERSION"):
+		if _, err := strconv.Atoi(args[1]); err != nil {
+			return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+		}
+	default:
+		return makeEvalError(diceerrors.ErrSyntax)
+	}
+
+	return makeEvalResult(lolwut(rand.Intn(6) + 1))
+}
+
+// lolwut draws the face of a die showing n pips.
+func lolwut(n int) string {
+	pips := map[int][]int{
+		1: {4},
+		2: {0, 8},
+		3: {0, 4, 8},
+		4: {0, 2, 6, 8},
+		5: {0, 2, 4, 6, 8},
+		6: {0, 2, 3, 5, 6, 8},
+	}
+	cells := []byte("         ")
+	for _, p := range pips[n] {
+		cells[p] = 'o'
+	}
+
+	var b strings.Builder
+	b.WriteString("+-------+\n")
+	for row := 0; row < 3; row++ {
+		fmt.Fprintf(&b, "| %c %c %c |\n", cells[row*3], cells[row*3+1], cells[row*3+2])
+	}
+	b.WriteString("+-------+\n")
+	fmt.Fprintf(&b, "DiceDB ver. %s\n", config.DiceDBVersion)
+	return b.String()
 }
 
 // evalSLEEP sets db to sleep for the specified number of seconds.
 // The sleep time should be the only param in args.
 // Returns error response if the time param in args is not of integer format.
-// evalSLEEP returns response.RespOK after sleeping for mentioned seconds
-func evalSLEEP(args []string, store *dstore.Store) []byte {
+// evalSLEEP returns OK after sleeping for mentioned seconds, or an error if the command
+// is cancelled before
+func evalSLEEP(args []string, store *dstore.Store) *EvalResponse {
 	if len(args) != 1 {
-		return diceerrors.NewErrArity("SLEEP")
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("SLEEP"))
 	}
 
 	durationSec, err := strconv.ParseInt(args[0], 10, 64)
 	if err != nil {
-		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+	timer := time.NewTimer(time.Duration(durationSec) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return makeEvalResult(clientio.OK)
+	case <-store.Context().Done():
+		return makeEvalError(diceerrors.ErrCommandTimeout)
 	}
-	time.Sleep(time.Duration(durationSec) * time.Second)
-	return clientio.RespOK
 }
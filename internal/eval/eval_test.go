@@ -18,6 +18,7 @@ package eval
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -31,9 +32,12 @@ import (
 	"github.com/axiomhq/hyperloglog"
 	"github.com/bytedance/sonic"
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/blocking"
 	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
 	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/eval/sortedset"
+	"github.com/dicedb/dice/internal/export"
 	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/server/utils"
 	dstore "github.com/dicedb/dice/internal/store"
@@ -100,6 +104,10 @@ func TestEval(t *testing.T) {
 	testEvalHSTRLEN(t, store)
 	testEvalHEXISTS(t, store)
 	testEvalHDEL(t, store)
+	testEvalHEXPIRE(t, store)
+	testEvalHPERSISTAndHTTL(t, store)
+	testEvalHGETEX(t, store)
+	testEvalHGETDEL(t, store)
 	testEvalHSCAN(t, store)
 	testEvalPFMERGE(t, store)
 	testEvalJSONSTRLEN(t, store)
@@ -116,6 +124,7 @@ func TestEval(t *testing.T) {
 	testEvalGETEX(t, store)
 	testEvalDUMP(t, store)
 	testEvalTYPE(t, store)
+	testEvalSINGLEEXPORT(t, store)
 	testEvalCOMMAND(t, store)
 	testEvalHINCRBY(t, store)
 	testEvalJSONOBJKEYS(t, store)
@@ -123,10 +132,24 @@ func TestEval(t *testing.T) {
 	testEvalHSETNX(t, store)
 	testEvalPING(t, store)
 	testEvalSETEX(t, store)
+	testEvalPSETEX(t, store)
+	testEvalSETNX(t, store)
+	testEvalDELIFEQ(t, store)
+	testEvalCAS(t, store)
+	testEvalIFVERSION(t, store)
+	testEvalPEXPIREIFEQ(t, store)
+	testEvalLOCK(t, store)
+	testEvalUNLOCK(t, store)
+	testEvalMSET(t, store)
+	testEvalPEXPIRE(t, store)
+	testEvalCACHELOAD(t, store)
 	testEvalFLUSHDB(t, store)
 	testEvalINCRBYFLOAT(t, store)
 	testEvalAPPEND(t, store)
+	testEvalSETRANGE(t, store)
 	testEvalHRANDFIELD(t, store)
+	testEvalSRANDMEMBER(t, store)
+	testEvalZRANDMEMBER(t, store)
 	testEvalSADD(t, store)
 	testEvalSREM(t, store)
 	testEvalSCARD(t, store)
@@ -161,31 +184,32 @@ func TestEval(t *testing.T) {
 
 func testEvalPING(t *testing.T, store *dstore.Store) {
 	tests := map[string]evalTestCase{
-		"nil value":            {input: nil, output: []byte("+PONG\r\n")},
-		"empty args":           {input: []string{}, output: []byte("+PONG\r\n")},
-		"one value":            {input: []string{"HEY"}, output: []byte("$3\r\nHEY\r\n")},
-		"more than one values": {input: []string{"HEY", "HELLO"}, output: []byte("-ERR wrong number of arguments for 'ping' command\r\n")},
+		"nil value":            {input: nil, migratedOutput: EvalResponse{Result: "PONG", Error: nil}},
+		"empty args":           {input: []string{}, migratedOutput: EvalResponse{Result: "PONG", Error: nil}},
+		"one value":            {input: []string{"HEY"}, migratedOutput: EvalResponse{Result: "HEY", Error: nil}},
+		"more than one values": {input: []string{"HEY", "HELLO"}, migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR wrong number of arguments for 'ping' command")}},
 	}
 
-	runEvalTests(t, tests, evalPING, store)
+	runMigratedEvalTests(t, tests, evalPING, store)
 }
 
 func testEvalECHO(t *testing.T, store *dstore.Store) {
 	tests := map[string]evalTestCase{
-		"nil value":            {input: nil, output: []byte("-ERR wrong number of arguments for 'echo' command\r\n")},
-		"empty args":           {input: []string{}, output: []byte("-ERR wrong number of arguments for 'echo' command\r\n")},
-		"one value":            {input: []string{"HEY"}, output: []byte("$3\r\nHEY\r\n")},
-		"more than one values": {input: []string{"HEY", "HELLO"}, output: []byte("-ERR wrong number of arguments for 'echo' command\r\n")},
+		"nil value":            {input: nil, migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR wrong number of arguments for 'echo' command")}},
+		"empty args":           {input: []string{}, migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR wrong number of arguments for 'echo' command")}},
+		"one value":            {input: []string{"HEY"}, migratedOutput: EvalResponse{Result: "HEY", Error: nil}},
+		"more than one values": {input: []string{"HEY", "HELLO"}, migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR wrong number of arguments for 'echo' command")}},
 	}
 
-	runEvalTests(t, tests, evalECHO, store)
+	runMigratedEvalTests(t, tests, evalECHO, store)
 }
 
 func testEvalHELLO(t *testing.T, store *dstore.Store) {
-	serverID = fmt.Sprintf("%s:%d", config.DiceConfig.RespServer.Addr, config.DiceConfig.RespServer.Port)
 	resp := []interface{}{
+		"server", "dicedb",
+		"version", config.DiceDBVersion,
 		"proto", 2,
-		"id", serverID,
+		"id", uint64(0),
 		"mode", "standalone",
 		"role", "master",
 		"modules",
@@ -193,13 +217,16 @@ func testEvalHELLO(t *testing.T, store *dstore.Store) {
 	}
 
 	tests := map[string]evalTestCase{
-		"nil value":            {input: nil, output: clientio.Encode(resp, false)},
-		"empty args":           {input: []string{}, output: clientio.Encode(resp, false)},
-		"one value":            {input: []string{"HEY"}, output: clientio.Encode(resp, false)},
-		"more than one values": {input: []string{"HEY", "HELLO"}, output: []byte("-ERR wrong number of arguments for 'hello' command\r\n")},
+		"nil value":              {input: nil, migratedOutput: EvalResponse{Result: resp, Error: nil}},
+		"empty args":             {input: []string{}, migratedOutput: EvalResponse{Result: resp, Error: nil}},
+		"protocol version 2":     {input: []string{"2", "SETNAME", "client"}, migratedOutput: EvalResponse{Result: resp, Error: nil}},
+		"protocol version 3":     {input: []string{"3"}, migratedOutput: EvalResponse{Result: nil, Error: errors.New("NOPROTO sorry, this protocol version is not supported")}},
+		"invalid version":        {input: []string{"HEY"}, migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR Protocol version is not an integer or out of range")}},
+		"incomplete AUTH option": {input: []string{"2", "AUTH", "user"}, migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR Syntax error in HELLO option 'AUTH'")}},
+		"AUTH over HTTP":         {input: []string{"2", "AUTH", "user", "pass"}, migratedOutput: EvalResponse{Result: nil, Error: errors.New("ERR HELLO AUTH is only supported over RESP, use the AUTH command")}},
 	}
 
-	runEvalTests(t, tests, evalHELLO, store)
+	runMigratedEvalTests(t, tests, evalHELLO, store)
 }
 
 func testEvalSET(t *testing.T, store *dstore.Store) {
@@ -3578,6 +3605,236 @@ func testEvalHEXISTS(t *testing.T, store *dstore.Store) {
 	}
 }
 
+func testEvalHEXPIRE(t *testing.T, store *dstore.Store) {
+	setupHash := func() {
+		store.Del("hash")
+		evalHSET([]string{"hash", "f1", "v1", "f2", "v2"}, store)
+	}
+
+	tests := map[string]evalTestCase{
+		"HEXPIRE with wrong number of args": {
+			input:          []string{"hash", "10", "FIELDS", "1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("HEXPIRE")},
+		},
+		"HEXPIRE without FIELDS": {
+			input: []string{"hash", "10", "NX", "1", "f1"},
+			migratedOutput: EvalResponse{Result: nil,
+				Error: diceerrors.ErrGeneral("Mandatory argument FIELDS is missing or not at the right position")},
+		},
+		"HEXPIRE with numfields not matching the fields": {
+			input: []string{"hash", "10", "FIELDS", "2", "f1"},
+			migratedOutput: EvalResponse{Result: nil,
+				Error: diceerrors.ErrGeneral("The `numfields` parameter must match the number of arguments")},
+		},
+		"HEXPIRE with invalid expiry": {
+			input:          []string{"hash", "-1", "FIELDS", "1", "f1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidExpireTime("HEXPIRE")},
+		},
+		"HEXPIRE on a missing key": {
+			setup:          func() { store.Del("hash") },
+			input:          []string{"hash", "10", "FIELDS", "2", "f1", "f2"},
+			migratedOutput: EvalResponse{Result: []int64{-2, -2}, Error: nil},
+		},
+		"HEXPIRE on wrong key type": {
+			setup: func() {
+				store.Del("hash")
+				evalSET([]string{"hash", "v"}, store)
+			},
+			input:          []string{"hash", "10", "FIELDS", "1", "f1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongTypeOperation},
+		},
+		"HEXPIRE sets the expiry of existing fields": {
+			setup: setupHash,
+			input: []string{"hash", "100", "FIELDS", "3", "f1", "f2", "f3"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []int64{1, 1, -2}, output)
+				assert.Equal(t, []int64{100, 100}, evalHTTL([]string{"hash", "FIELDS", "2", "f1", "f2"}, store).Result)
+			},
+		},
+		"HEXPIRE with conditions": {
+			setup: func() {
+				setupHash()
+				evalHEXPIRE([]string{"hash", "100", "FIELDS", "1", "f1"}, store)
+			},
+			input: []string{"hash", "50", "GT", "FIELDS", "2", "f1", "f2"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []int64{0, 0}, output)
+				assert.Equal(t, []int64{0, 1}, evalHEXPIRE([]string{"hash", "50", "NX", "FIELDS", "2", "f1", "f2"}, store).Result)
+				assert.Equal(t, []int64{1, 0}, evalHEXPIRE([]string{"hash", "60", "LT", "FIELDS", "2", "f1", "f2"}, store).Result)
+				assert.Equal(t, []int64{1}, evalHEXPIRE([]string{"hash", "70", "XX", "FIELDS", "1", "f2"}, store).Result)
+			},
+		},
+		"HEXPIRE with 0 deletes the fields": {
+			setup: setupHash,
+			input: []string{"hash", "0", "FIELDS", "1", "f1"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []int64{2}, output)
+				assert.Equal(t, []interface{}{nil, "v2"}, evalHMGET([]string{"hash", "f1", "f2"}, store).Result)
+				assert.Equal(t, []int64{2}, evalHEXPIRE([]string{"hash", "0", "FIELDS", "1", "f2"}, store).Result)
+				assert.Nil(t, store.Get("hash"))
+			},
+		},
+		"HPEXPIRE expires the fields lazily": {
+			setup: setupHash,
+			input: []string{"hash", "1", "FIELDS", "1", "f1"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []int64{1}, output)
+				time.Sleep(5 * time.Millisecond)
+				assert.Equal(t, clientio.NIL, evalHGET([]string{"hash", "f1"}, store).Result)
+				assert.EqualValues(t, 1, evalHLEN([]string{"hash"}, store).Result)
+				assert.Equal(t, 0, store.GetFieldExpiresCount())
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		if strings.HasPrefix(name, "HPEXPIRE") {
+			runMigratedEvalTests(t, map[string]evalTestCase{name: tc}, evalHPEXPIRE, store)
+			delete(tests, name)
+		}
+	}
+	runMigratedEvalTests(t, tests, evalHEXPIRE, store)
+}
+
+func testEvalHPERSISTAndHTTL(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"HPERSIST with wrong number of args": {
+			input:          []string{"hash", "FIELDS", "1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("HPERSIST")},
+		},
+		"HPERSIST with numfields of 0": {
+			input: []string{"hash", "FIELDS", "0", "f1"},
+			migratedOutput: EvalResponse{Result: nil,
+				Error: diceerrors.ErrGeneral("Parameter `numFields` should be greater than 0")},
+		},
+		"HPERSIST removes the expiry of the fields": {
+			setup: func() {
+				store.Del("hash")
+				evalHSET([]string{"hash", "f1", "v1", "f2", "v2"}, store)
+				evalHEXPIRE([]string{"hash", "100", "FIELDS", "1", "f1"}, store)
+			},
+			input: []string{"hash", "FIELDS", "3", "f1", "f2", "f3"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []int64{1, -1, -2}, output)
+				assert.Equal(t, []int64{-1, -1, -2}, evalHTTL([]string{"hash", "FIELDS", "3", "f1", "f2", "f3"}, store).Result)
+			},
+		},
+		"HSET removes the expiry of the fields it overwrites": {
+			setup: func() {
+				store.Del("hash")
+				evalHSET([]string{"hash", "f1", "v1", "f2", "v2"}, store)
+				evalHEXPIRE([]string{"hash", "100", "FIELDS", "2", "f1", "f2"}, store)
+				evalHSET([]string{"hash", "f1", "w1"}, store)
+				evalHINCRBY([]string{"hash", "f3", "1"}, store)
+			},
+			input: []string{"hash", "FIELDS", "3", "f1", "f2", "f3"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []int64{-1, 1, -1}, output)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalHPERSIST, store)
+
+	missing := evalHTTL([]string{"missing", "FIELDS", "1", "f1"}, store)
+	assert.Equal(t, []int64{-2}, missing.Result)
+}
+
+func testEvalHGETEX(t *testing.T, store *dstore.Store) {
+	setupHash := func() {
+		store.Del("hash")
+		evalHSET([]string{"hash", "f1", "v1", "f2", "v2"}, store)
+	}
+
+	tests := map[string]evalTestCase{
+		"HGETEX with wrong number of args": {
+			input:          []string{"hash", "FIELDS", "1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("HGETEX")},
+		},
+		"HGETEX with an unknown option": {
+			input:          []string{"hash", "KEEPTTL", "FIELDS", "1", "f1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrSyntax},
+		},
+		"HGETEX with invalid expiry": {
+			input:          []string{"hash", "EX", "0", "FIELDS", "1", "f1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidExpireTime("HGETEX")},
+		},
+		"HGETEX on a missing key": {
+			setup:          func() { store.Del("hash") },
+			input:          []string{"hash", "FIELDS", "1", "f1"},
+			migratedOutput: EvalResponse{Result: []interface{}{nil}, Error: nil},
+		},
+		"HGETEX sets the expiry of the fields": {
+			setup: setupHash,
+			input: []string{"hash", "EX", "100", "FIELDS", "2", "f1", "f3"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []interface{}{"v1", nil}, output)
+				assert.Equal(t, []int64{100, -1}, evalHTTL([]string{"hash", "FIELDS", "2", "f1", "f2"}, store).Result)
+			},
+		},
+		"HGETEX with PERSIST": {
+			setup: func() {
+				setupHash()
+				evalHEXPIRE([]string{"hash", "100", "FIELDS", "1", "f1"}, store)
+			},
+			input: []string{"hash", "PERSIST", "FIELDS", "1", "f1"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []interface{}{"v1"}, output)
+				assert.Equal(t, []int64{-1}, evalHTTL([]string{"hash", "FIELDS", "1", "f1"}, store).Result)
+			},
+		},
+		"HGETEX with an elapsed expiry deletes the fields": {
+			setup: setupHash,
+			input: []string{"hash", "PXAT", "1", "FIELDS", "2", "f1", "f2"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []interface{}{"v1", "v2"}, output)
+				assert.Nil(t, store.Get("hash"))
+			},
+		},
+		"HGETEX without option": {
+			setup:          setupHash,
+			input:          []string{"hash", "FIELDS", "1", "f2"},
+			migratedOutput: EvalResponse{Result: []interface{}{"v2"}, Error: nil},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalHGETEX, store)
+}
+
+func testEvalHGETDEL(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"HGETDEL with wrong number of args": {
+			input:          []string{"hash", "FIELDS", "1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("HGETDEL")},
+		},
+		"HGETDEL on wrong key type": {
+			setup: func() {
+				store.Del("hash")
+				evalSET([]string{"hash", "v"}, store)
+			},
+			input:          []string{"hash", "FIELDS", "1", "f1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongTypeOperation},
+		},
+		"HGETDEL deletes the fields": {
+			setup: func() {
+				store.Del("hash")
+				evalHSET([]string{"hash", "f1", "v1", "f2", "v2"}, store)
+				evalHEXPIRE([]string{"hash", "100", "FIELDS", "1", "f1"}, store)
+			},
+			input: []string{"hash", "FIELDS", "3", "f1", "f1", "f3"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, []interface{}{"v1", nil, nil}, output)
+				assert.Equal(t, []int64{-2}, evalHTTL([]string{"hash", "FIELDS", "1", "f1"}, store).Result)
+				assert.Equal(t, 0, store.GetFieldExpiresCount())
+				assert.Equal(t, []interface{}{"v2"}, evalHGETDEL([]string{"hash", "FIELDS", "1", "f2"}, store).Result)
+				assert.Nil(t, store.Get("hash"))
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalHGETDEL, store)
+}
+
 func testEvalHDEL(t *testing.T, store *dstore.Store) {
 	tests := map[string]evalTestCase{
 		"HDEL with wrong number of args": {
@@ -4285,26 +4542,6 @@ func testEvalJSONNUMINCRBY(t *testing.T, store *dstore.Store) {
 	runMigratedEvalTests(t, tests, evalJSONNUMINCRBY, store)
 }
 
-func runEvalTests(t *testing.T, tests map[string]evalTestCase, evalFunc func([]string, *dstore.Store) []byte, store *dstore.Store) {
-	for name, tc := range tests {
-		t.Run(name, func(t *testing.T) {
-			store = setupTest(store)
-
-			if tc.setup != nil {
-				tc.setup()
-			}
-
-			output := evalFunc(tc.input, store)
-
-			if tc.validator != nil {
-				tc.validator(output)
-			} else {
-				assert.Equal(t, string(tc.output), string(output))
-			}
-		})
-	}
-}
-
 func runMigratedEvalTests(t *testing.T, tests map[string]evalTestCase, evalFunc func([]string, *dstore.Store) *EvalResponse, store *dstore.Store) {
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -5357,6 +5594,65 @@ func BenchmarkEvalTYPE(b *testing.B) {
 	}
 }
 
+func testEvalSINGLEEXPORT(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"SINGLEEXPORT with wrong number of args": {
+			input:          []string{},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("SINGLEEXPORT")},
+		},
+		"SINGLEEXPORT skips the missing keys": {
+			setup: func() {
+				store.Del("missing")
+			},
+			input:          []string{"missing"},
+			migratedOutput: EvalResponse{Result: []export.Record{}, Error: nil},
+		},
+		"SINGLEEXPORT exports every type": {
+			setup: func() {
+				for _, key := range []string{"str", "int", "list", "set", "hash", "zset", "doc", "hll", "bf"} {
+					store.Del(key)
+				}
+				evalSET([]string{"str", "value", "EX", "100"}, store)
+				evalSET([]string{"int", "42"}, store)
+				evalRPUSH([]string{"list", "a", "b"}, store)
+				evalSADD([]string{"set", "y", "x"}, store)
+				evalHSET([]string{"hash", "f", "v"}, store)
+				evalZADD([]string{"zset", "2", "b", "1", "a"}, store)
+				evalJSONSET([]string{"doc", "$", `{"a":1}`}, store)
+				evalPFADD([]string{"hll", "a"}, store)
+				evalBFADD([]string{"bf", "a"}, store)
+			},
+			input: []string{"str", "int", "list", "set", "hash", "zset", "doc", "hll", "bf"},
+			newValidator: func(output interface{}) {
+				records := output.([]export.Record)
+				assert.Len(t, records, 9)
+
+				assert.Equal(t, export.Record{Key: "int", Type: "string", TTL: -1, Value: "42"}, records[1])
+				assert.Equal(t, export.Record{Key: "list", Type: "list", TTL: -1, Value: []string{"a", "b"}}, records[2])
+				assert.Equal(t, export.Record{Key: "set", Type: "set", TTL: -1, Value: []string{"x", "y"}}, records[3])
+				assert.Equal(t, export.Record{Key: "hash", Type: "hash", TTL: -1, Value: map[string]string{"f": "v"}}, records[4])
+				assert.Equal(t, export.Record{Key: "zset", Type: "zset", TTL: -1,
+					Value: []export.ZMember{{Member: "a", Score: 1}, {Member: "b", Score: 2}}}, records[5])
+
+				assert.Equal(t, "str", records[0].Key)
+				assert.Equal(t, "value", records[0].Value)
+				assert.InDelta(t, 100000, records[0].TTL, 1000)
+
+				line, err := export.Encode(export.FormatJSON, records[6])
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"key":"doc","type":"json","ttl":-1,"value":{"a":1}}`, line)
+
+				assert.Equal(t, "hyperloglog", records[7].Type)
+				assert.Equal(t, "bloomfilter", records[8].Type)
+				store.Del("restored")
+				assert.Equal(t, clientio.OK, evalRestore([]string{"restored", "0", records[8].Value.(string)}, store).Result)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalSingleExport, store)
+}
+
 func testEvalCOMMAND(t *testing.T, store *dstore.Store) {
 	tests := map[string]evalTestCase{
 		"command help": {
@@ -5404,14 +5700,14 @@ func testEvalCOMMAND(t *testing.T, store *dstore.Store) {
 		"command info invalid command": {
 			input: []string{"INFO", "INVALID_CMD"},
 			migratedOutput: EvalResponse{
-				Result: []interface{}([]interface{}{[]uint8{0x24, 0x2d, 0x31, 0xd, 0xa}}),
+				Result: []interface{}([]interface{}{clientio.NIL}),
 				Error:  nil,
 			},
 		},
 		"command info mixture of valid and invalid commands": {
 			input: []string{"INFO", "SET", "INVALID_CMD"},
 			migratedOutput: EvalResponse{
-				Result: []interface{}([]interface{}{[]interface{}{"set", -3, 1, 0, 0, []interface{}(nil)}, []uint8{0x24, 0x2d, 0x31, 0xd, 0xa}}),
+				Result: []interface{}([]interface{}{[]interface{}{"set", -3, 1, 0, 0, []interface{}(nil)}, clientio.NIL}),
 				Error:  nil,
 			},
 		},
@@ -5464,6 +5760,20 @@ func testEvalCOMMAND(t *testing.T, store *dstore.Store) {
 				Error:  diceerrors.ErrGeneral("invalid number of arguments specified for command"),
 			},
 		},
+		"command getkeys with variadic keys": {
+			input: []string{"GETKEYS", "DEL", "k1", "k2"},
+			migratedOutput: EvalResponse{
+				Result: []string{"k1", "k2"},
+				Error:  nil,
+			},
+		},
+		"command getkeys with the key after a subcommand": {
+			input: []string{"GETKEYS", "OBJECT", "ENCODING", "k1"},
+			migratedOutput: EvalResponse{
+				Result: []string{"k1"},
+				Error:  nil,
+			},
+		},
 		"command docs valid command SET": {
 			input: []string{"DOCS", "SET"},
 			migratedOutput: EvalResponse{
@@ -6296,6 +6606,534 @@ func testEvalSETEX(t *testing.T, store *dstore.Store) {
 	}
 }
 
+func testEvalPSETEX(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"KEY", "1000"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("PSETEX")},
+		},
+		"invalid expiry": {
+			input:          []string{"KEY", "0", "VAL"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidExpireTime("PSETEX")},
+		},
+		"not-int expiry": {
+			input:          []string{"KEY", "12a", "VAL"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIntegerOutOfRange},
+		},
+		"value with expiry in milliseconds": {
+			input: []string{"KEY", "1500", "VAL"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.OK, output)
+				assert.Equal(t, "VAL", evalGET([]string{"KEY"}, store).Result)
+				pttl := evalPTTL([]string{"KEY"}, store).Result.(uint64)
+				assert.True(t, pttl > 1000 && pttl <= 1500, "unexpected PTTL %d", pttl)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalPSETEX, store)
+}
+
+func testEvalSETNX(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"KEY"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("SETNX")},
+		},
+		"missing key": {
+			setup:          func() { store.Del("KEY") },
+			input:          []string{"KEY", "VAL"},
+			migratedOutput: EvalResponse{Result: clientio.IntegerOne, Error: nil},
+		},
+		"existing key": {
+			setup: func() {
+				store.Put("KEY", store.NewObj("OLD", -1, object.ObjTypeString))
+			},
+			input: []string{"KEY", "VAL"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerZero, output)
+				assert.Equal(t, "OLD", evalGET([]string{"KEY"}, store).Result)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalSETNX, store)
+}
+
+func testEvalDELIFEQ(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"LOCK"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("DELIFEQ")},
+		},
+		"missing key": {
+			setup:          func() { store.Del("LOCK") },
+			input:          []string{"LOCK", "token"},
+			migratedOutput: EvalResponse{Result: clientio.IntegerZero, Error: nil},
+		},
+		"value matches": {
+			setup: func() {
+				store.Put("LOCK", store.NewObj("token", 10000, object.ObjTypeString))
+			},
+			input: []string{"LOCK", "token"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerOne, output)
+				assert.Nil(t, store.Get("LOCK"))
+			},
+		},
+		"value differs": {
+			setup: func() {
+				store.Put("LOCK", store.NewObj("other", -1, object.ObjTypeString))
+			},
+			input: []string{"LOCK", "token"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerZero, output)
+				assert.Equal(t, "other", evalGET([]string{"LOCK"}, store).Result)
+			},
+		},
+		"integer value matches": {
+			setup: func() {
+				store.Put("LOCK", store.NewObj(int64(42), -1, object.ObjTypeInt))
+			},
+			input:          []string{"LOCK", "42"},
+			migratedOutput: EvalResponse{Result: clientio.IntegerOne, Error: nil},
+		},
+		"wrong type": {
+			setup: func() {
+				evalSADD([]string{"LOCK", "token"}, store)
+			},
+			input:          []string{"LOCK", "token"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongTypeOperation},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalDELIFEQ, store)
+}
+
+func testEvalCAS(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"KEY", "old"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("CAS")},
+		},
+		"missing key": {
+			setup: func() { store.Del("KEY") },
+			input: []string{"KEY", "old", "new"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerZero, output)
+				assert.Nil(t, store.Get("KEY"))
+			},
+		},
+		"value matches": {
+			setup: func() {
+				store.Put("KEY", store.NewObj("old", 10000, object.ObjTypeString))
+			},
+			input: []string{"KEY", "old", "new"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerOne, output)
+				assert.Equal(t, "new", evalGET([]string{"KEY"}, store).Result)
+				assert.Equal(t, clientio.IntegerNegativeOne, evalTTL([]string{"KEY"}, store).Result, "the expiry of the key was kept")
+			},
+		},
+		"value differs": {
+			setup: func() {
+				store.Put("KEY", store.NewObj("other", -1, object.ObjTypeString))
+			},
+			input: []string{"KEY", "old", "new"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerZero, output)
+				assert.Equal(t, "other", evalGET([]string{"KEY"}, store).Result)
+			},
+		},
+		"integer value matches": {
+			setup: func() {
+				store.Put("KEY", store.NewObj(int64(42), -1, object.ObjTypeInt))
+			},
+			input: []string{"KEY", "42", "43"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerOne, output)
+				assert.Equal(t, int64(43), evalGET([]string{"KEY"}, store).Result)
+			},
+		},
+		"wrong type": {
+			setup: func() {
+				store.Del("KEY")
+				evalSADD([]string{"KEY", "old"}, store)
+			},
+			input:          []string{"KEY", "old", "new"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongTypeOperation},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalCAS, store)
+}
+
+func testEvalIFVERSION(t *testing.T, store *dstore.Store) {
+	version := func(key string) string {
+		return strconv.FormatUint(store.Version(key), 10)
+	}
+
+	runMigratedEvalTests(t, map[string]evalTestCase{
+		"SET at the version": {
+			setup: func() { store.Put("KEY", store.NewObj("old", -1, object.ObjTypeString)) },
+			input: []string{"KEY", "new", IfVersion, "$version"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.OK, output)
+				assert.Equal(t, "new", evalGET([]string{"KEY"}, store).Result)
+			},
+		},
+		"SET of a missing key at version 0": {
+			setup: func() { store.Del("KEY") },
+			input: []string{"KEY", "new", "ifversion", "0"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.OK, output)
+				assert.Equal(t, "new", evalGET([]string{"KEY"}, store).Result)
+			},
+		},
+		"SET without a version": {
+			input:          []string{"KEY", "new", IfVersion},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrSyntax},
+		},
+		"SET with an invalid version": {
+			input:          []string{"KEY", "new", IfVersion, "-1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIntegerOutOfRange},
+		},
+	}, func(args []string, store *dstore.Store) *EvalResponse {
+		if args[len(args)-1] == "$version" {
+			args[len(args)-1] = version(args[0])
+		}
+		return evalSET(args, store)
+	}, store)
+
+	t.Run("SET at another version", func(t *testing.T) {
+		store.Put("KEY", store.NewObj("old", -1, object.ObjTypeString))
+		current := store.Version("KEY")
+		response := evalSET([]string{"KEY", "new", IfVersion, strconv.FormatUint(current-1, 10)}, store)
+		assert.EqualError(t, response.Error, diceerrors.ErrVersionConflict(current, current-1).Error())
+		assert.Equal(t, "old", evalGET([]string{"KEY"}, store).Result)
+		assert.Equal(t, current, store.Version("KEY"), "the version of the key is kept")
+	})
+
+	t.Run("DEL IFVERSION", func(t *testing.T) {
+		store.Put("KEY", store.NewObj("v", -1, object.ObjTypeString))
+		store.Put("OTHER", store.NewObj("v", -1, object.ObjTypeString))
+
+		response := evalDEL([]string{"KEY", "OTHER", IfVersion, version("KEY")}, store)
+		assert.EqualError(t, response.Error, "ERR IFVERSION can only be used with a single key")
+
+		response = evalDEL([]string{"KEY", IfVersion, "0"}, store)
+		assert.EqualError(t, response.Error, diceerrors.ErrVersionConflict(store.Version("KEY"), 0).Error())
+		assert.NotNil(t, store.Get("KEY"))
+
+		response = evalDEL([]string{"KEY", IfVersion, version("KEY")}, store)
+		assert.Nil(t, response.Error)
+		assert.Equal(t, int64(1), response.Result)
+		assert.Nil(t, store.Get("KEY"))
+
+		// A key named IFVERSION is deleted as any other key
+		store.Put(IfVersion, store.NewObj("v", -1, object.ObjTypeString))
+		response = evalDEL([]string{IfVersion}, store)
+		assert.Equal(t, int64(1), response.Result)
+	})
+
+	t.Run("HSET IFVERSION", func(t *testing.T) {
+		store.Del("KEY")
+		response := evalHSET([]string{"KEY", "field", "value", IfVersion, "0"}, store)
+		assert.Nil(t, response.Error)
+		assert.Equal(t, int64(1), response.Result)
+
+		response = evalHSET([]string{"KEY", "field", "other", IfVersion, "0"}, store)
+		assert.EqualError(t, response.Error, diceerrors.ErrVersionConflict(store.Version("KEY"), 0).Error())
+		assert.Equal(t, "value", evalHGET([]string{"KEY", "field"}, store).Result)
+
+		// Without a version, IFVERSION is the last field set
+		response = evalHSET([]string{"KEY", IfVersion, "0"}, store)
+		assert.Nil(t, response.Error)
+		assert.Equal(t, "0", evalHGET([]string{"KEY", IfVersion}, store).Result)
+	})
+
+	t.Run("OBJECT VERSION", func(t *testing.T) {
+		store.Del("KEY")
+		assert.Equal(t, clientio.NIL, evalOBJECT([]string{"VERSION", "KEY"}, store).Result)
+
+		store.Put("KEY", store.NewObj("v", -1, object.ObjTypeString))
+		first := evalOBJECT([]string{"VERSION", "KEY"}, store).Result
+		assert.Equal(t, int64(store.Version("KEY")), first)
+		store.Put("KEY", store.NewObj("v", -1, object.ObjTypeString))
+		assert.Greater(t, evalOBJECT([]string{"VERSION", "KEY"}, store).Result, first)
+	})
+}
+
+func testEvalPEXPIRE(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"KEY"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("PEXPIRE")},
+		},
+		"invalid expiry": {
+			input:          []string{"KEY", "ten"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIntegerOutOfRange},
+		},
+		"negative expiry": {
+			input:          []string{"KEY", "-1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidExpireTime("PEXPIRE")},
+		},
+		"missing key": {
+			setup:          func() { store.Del("KEY") },
+			input:          []string{"KEY", "10000"},
+			migratedOutput: EvalResponse{Result: clientio.IntegerZero, Error: nil},
+		},
+		"existing key": {
+			setup: func() {
+				store.Put("KEY", store.NewObj("value", -1, object.ObjTypeString))
+			},
+			input: []string{"KEY", "60500"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerOne, output)
+				expiry, ok := dstore.GetExpiry(store.Get("KEY"), store)
+				assert.True(t, ok)
+				assert.InDelta(t, utils.GetCurrentTime().Add(60500*time.Millisecond).UnixMilli(), int64(expiry), 1000)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalPEXPIRE, store)
+}
+
+func testEvalCACHELOAD(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"KEY", "value"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("CACHELOAD")},
+		},
+		"invalid expiry": {
+			input:          []string{"KEY", "value", "-1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidExpireTime("CACHELOAD")},
+		},
+		"missing key": {
+			setup: func() { store.Del("KEY") },
+			input: []string{"KEY", "value", "0"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerOne, output)
+				assert.Equal(t, "value", store.Get("KEY").Value)
+				_, ok := dstore.GetExpiry(store.Get("KEY"), store)
+				assert.False(t, ok)
+			},
+		},
+		"missing key with expiry": {
+			setup: func() { store.Del("KEY") },
+			input: []string{"KEY", "value", "60000"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerOne, output)
+				_, ok := dstore.GetExpiry(store.Get("KEY"), store)
+				assert.True(t, ok)
+			},
+		},
+		"existing key": {
+			setup: func() {
+				store.Put("KEY", store.NewObj("written", -1, object.ObjTypeString))
+			},
+			input: []string{"KEY", "loaded", "0"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerZero, output)
+				assert.Equal(t, "written", store.Get("KEY").Value)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalCacheLoad, store)
+}
+
+func testEvalPEXPIREIFEQ(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"LOCK", "token"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("PEXPIREIFEQ")},
+		},
+		"invalid expiry": {
+			input:          []string{"LOCK", "token", "ten"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIntegerOutOfRange},
+		},
+		"non positive expiry": {
+			input:          []string{"LOCK", "token", "0"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidExpireTime("PEXPIREIFEQ")},
+		},
+		"missing key": {
+			setup:          func() { store.Del("LOCK") },
+			input:          []string{"LOCK", "token", "10000"},
+			migratedOutput: EvalResponse{Result: clientio.IntegerZero, Error: nil},
+		},
+		"value matches": {
+			setup: func() {
+				store.Put("LOCK", store.NewObj("token", 1000, object.ObjTypeString))
+			},
+			input: []string{"LOCK", "token", "60000"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerOne, output)
+				expiry, ok := dstore.GetExpiry(store.Get("LOCK"), store)
+				assert.True(t, ok)
+				assert.Greater(t, int64(expiry), time.Now().Add(50*time.Second).UnixMilli())
+			},
+		},
+		"value differs": {
+			setup: func() {
+				store.Put("LOCK", store.NewObj("other", -1, object.ObjTypeString))
+			},
+			input: []string{"LOCK", "token", "60000"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerZero, output)
+				_, ok := dstore.GetExpiry(store.Get("LOCK"), store)
+				assert.False(t, ok)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalPEXPIREIFEQ, store)
+}
+
+func testEvalLOCK(t *testing.T, store *dstore.Store) {
+	var token int64
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"LOCK"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("LOCK")},
+		},
+		"invalid lease": {
+			input:          []string{"LOCK", "ten"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIntegerOutOfRange},
+		},
+		"non positive lease": {
+			input:          []string{"LOCK", "0"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidExpireTime("LOCK")},
+		},
+		"lock acquired": {
+			setup: func() {
+				store.Del("LOCK")
+				token = store.NextFencingToken()
+			},
+			input: []string{"LOCK", "10000"},
+			newValidator: func(output interface{}) {
+				assert.Greater(t, output, token)
+				assert.Equal(t, output, evalGET([]string{"LOCK"}, store).Result)
+				expiry, ok := dstore.GetExpiry(store.Get("LOCK"), store)
+				assert.True(t, ok)
+				assert.Greater(t, int64(expiry), time.Now().Add(5*time.Second).UnixMilli())
+			},
+		},
+		"lock held": {
+			setup: func() {
+				store.Put("LOCK", store.NewObj(int64(42), 10000, object.ObjTypeInt))
+			},
+			input: []string{"LOCK", "10000"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.NIL, output)
+				assert.Equal(t, int64(42), evalGET([]string{"LOCK"}, store).Result)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalLOCK, store)
+}
+
+func testEvalUNLOCK(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"LOCK"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("UNLOCK")},
+		},
+		"invalid token": {
+			input:          []string{"LOCK", "token"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIntegerOutOfRange},
+		},
+		"lock not held": {
+			setup:          func() { store.Del("LOCK") },
+			input:          []string{"LOCK", "42"},
+			migratedOutput: EvalResponse{Result: clientio.IntegerZero, Error: nil},
+		},
+		"token matches": {
+			setup: func() {
+				store.Put("LOCK", store.NewObj(int64(42), 10000, object.ObjTypeInt))
+			},
+			input: []string{"LOCK", "42"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerOne, output)
+				assert.Nil(t, store.Get("LOCK"))
+			},
+		},
+		"token differs": {
+			setup: func() {
+				store.Put("LOCK", store.NewObj(int64(43), 10000, object.ObjTypeInt))
+			},
+			input: []string{"LOCK", "42"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.IntegerZero, output)
+				assert.Equal(t, int64(43), evalGET([]string{"LOCK"}, store).Result)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalUNLOCK, store)
+}
+
+func testEvalMSET(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of args": {
+			input:          []string{"KEY1", "v1", "KEY2"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("MSET")},
+		},
+		"sets every key": {
+			setup: func() {
+				store.Put("KEY2", store.NewObj("old", 10000, object.ObjTypeString))
+			},
+			input: []string{"KEY1", "v1", "KEY2", "42"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.OK, output)
+				assert.Equal(t, "v1", evalGET([]string{"KEY1"}, store).Result)
+				assert.Equal(t, int64(42), evalGET([]string{"KEY2"}, store).Result)
+				assert.Equal(t, clientio.IntegerNegativeOne, evalTTL([]string{"KEY2"}, store).Result)
+			},
+		},
+		"last value of a key wins": {
+			input: []string{"KEY3", "v1", "KEY3", "v2"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, clientio.OK, output)
+				assert.Equal(t, "v2", evalGET([]string{"KEY3"}, store).Result)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalMSET, store)
+}
+
+// TestWritesWithExpiryAreAtomic checks that the key event of a write along with an expiry is reported
+// once the expiry is set, so that no subscriber observes the key without its expiry.
+func TestWritesWithExpiryAreAtomic(t *testing.T) {
+	store := dstore.NewStore(nil, nil)
+
+	var puts int
+	store.Subscribe(dstore.KeyEventFunc(func(e dstore.KeyEvent) {
+		if e.Type != dstore.KeyEventPut {
+			return
+		}
+		puts++
+		_, ok := dstore.GetExpiry(store.GetNoTouch(e.Key), store)
+		assert.True(t, ok, "key %s was put without its expiry", e.Key)
+	}))
+
+	pxat := strconv.FormatInt(time.Now().Add(time.Minute).UnixMilli(), 10)
+	evalSET([]string{"k1", "v", Ex, "10"}, store)
+	evalSET([]string{"k2", "v", Pxat, pxat, NX}, store)
+	evalSETEX([]string{"k3", "10", "v"}, store)
+	evalPSETEX([]string{"k4", "10000", "v"}, store)
+	evalCOPYObject(&cmd.DiceDBCmd{
+		Cmd:         "COPY",
+		Args:        []string{"k5"},
+		InternalObj: &object.InternalObj{Obj: store.NewObj("v", -1, object.ObjTypeString), ExDuration: 10000},
+	}, store)
+
+	assert.Equal(t, 5, puts)
+}
+
 func BenchmarkEvalSETEX(b *testing.B) {
 	store := dstore.NewStore(nil, nil)
 
@@ -6326,6 +7164,24 @@ func testEvalFLUSHDB(t *testing.T, store *dstore.Store) {
 			input:          nil,
 			migratedOutput: EvalResponse{Result: clientio.OK, Error: nil},
 		},
+		"flush asynchronously": {
+			setup: func() {
+				evalSET([]string{"key", "val"}, store)
+			},
+			input:          []string{"async"},
+			migratedOutput: EvalResponse{Result: clientio.OK, Error: nil},
+		},
+		"flush synchronously": {
+			setup: func() {
+				evalSET([]string{"key", "val"}, store)
+			},
+			input:          []string{"SYNC"},
+			migratedOutput: EvalResponse{Result: clientio.OK, Error: nil},
+		},
+		"invalid flush mode": {
+			input:          []string{"LATER"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrSyntax},
+		},
 	}
 	runMigratedEvalTests(t, tests, evalFLUSHDB, store)
 }
@@ -6445,7 +7301,7 @@ func testEvalINCRBYFLOAT(t *testing.T, store *dstore.Store) {
 				store.Put(key, obj)
 			},
 			input:          []string{"key", "1e308"},
-			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrValueOutOfRange},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIncrNaNOrInf},
 		},
 	}
 
@@ -6533,11 +7389,10 @@ func testEvalHRANDFIELD(t *testing.T, store *dstore.Store) {
 			input: []string{"KEY_MOCK"},
 			newValidator: func(output interface{}) {
 				assert.True(t, output != nil)
-				stringSlice, ok := output.([]string)
+				resultString, ok := output.(string)
 				if !ok {
-					assert.Error(t, diceerrors.ErrUnexpectedType("[]string", reflect.TypeOf(output)))
+					assert.Error(t, diceerrors.ErrUnexpectedType("string", reflect.TypeOf(output)))
 				}
-				resultString := strings.Join(stringSlice, " ")
 				assert.True(t,
 					resultString == "field1" || resultString == "field2",
 					"Unexpected field returned: %s", resultString)
@@ -6619,6 +7474,155 @@ func testEvalHRANDFIELD(t *testing.T, store *dstore.Store) {
 	runMigratedEvalTests(t, tests, evalHRANDFIELD, store)
 }
 
+func testEvalSRANDMEMBER(t *testing.T, store *dstore.Store) {
+	setupSet := func() {
+		store.Put("SET_KEY", store.NewObj(map[string]struct{}{"a": {}, "b": {}, "c": {}}, -1, object.ObjTypeSet))
+	}
+	members := []string{"a", "b", "c"}
+
+	tests := map[string]evalTestCase{
+		"wrong number of args passed": {
+			input:          nil,
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("SRANDMEMBER")},
+		},
+		"too many arguments": {
+			input:          []string{"SET_KEY", "1", "2"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrSyntax},
+		},
+		"key doesn't exist": {
+			input:          []string{"NON_EXISTENT_KEY"},
+			migratedOutput: EvalResponse{Result: clientio.NIL, Error: nil},
+		},
+		"key doesn't exist with count": {
+			input:          []string{"NON_EXISTENT_KEY", "3"},
+			migratedOutput: EvalResponse{Result: []string{}, Error: nil},
+		},
+		"wrong type": {
+			setup: func() {
+				store.Put("STRING_KEY", store.NewObj("value", -1, object.ObjTypeString))
+			},
+			input:          []string{"STRING_KEY"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongTypeOperation},
+		},
+		"invalid count": {
+			setup:          setupSet,
+			input:          []string{"SET_KEY", "abc"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIntegerOutOfRange},
+		},
+		"no count returns a single member": {
+			setup: setupSet,
+			input: []string{"SET_KEY"},
+			newValidator: func(output interface{}) {
+				assert.Contains(t, members, output)
+			},
+		},
+		"zero count": {
+			setup:          setupSet,
+			input:          []string{"SET_KEY", "0"},
+			migratedOutput: EvalResponse{Result: []string{}, Error: nil},
+		},
+		"positive count larger than the set returns every member once": {
+			setup:          setupSet,
+			input:          []string{"SET_KEY", "10"},
+			migratedOutput: EvalResponse{Result: members, Error: nil},
+		},
+		"positive count returns distinct members": {
+			setup: setupSet,
+			input: []string{"SET_KEY", "2"},
+			newValidator: func(output interface{}) {
+				result := output.([]string)
+				assert.Len(t, result, 2)
+				assert.NotEqual(t, result[0], result[1])
+				assert.Subset(t, members, result)
+			},
+		},
+		"negative count allows repetitions": {
+			setup: setupSet,
+			input: []string{"SET_KEY", "-10"},
+			newValidator: func(output interface{}) {
+				result := output.([]string)
+				assert.Len(t, result, 10)
+				assert.Subset(t, members, result)
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalSRANDMEMBER, store)
+}
+
+func testEvalZRANDMEMBER(t *testing.T, store *dstore.Store) {
+	setupSortedSet := func() {
+		evalZADD([]string{"ZSET_KEY", "1", "a", "2.5", "b", "3", "c"}, store)
+	}
+	scores := map[string]string{"a": "1", "b": "2.5", "c": "3"}
+
+	tests := map[string]evalTestCase{
+		"wrong number of args passed": {
+			input:          []string{"ZSET_KEY", "1", "WITHSCORES", "extra"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("ZRANDMEMBER")},
+		},
+		"key doesn't exist": {
+			input:          []string{"NON_EXISTENT_KEY"},
+			migratedOutput: EvalResponse{Result: clientio.NIL, Error: nil},
+		},
+		"key doesn't exist with count": {
+			input:          []string{"NON_EXISTENT_KEY", "-2", "WITHSCORES"},
+			migratedOutput: EvalResponse{Result: []string{}, Error: nil},
+		},
+		"wrong type": {
+			setup: func() {
+				store.Put("STRING_KEY", store.NewObj("value", -1, object.ObjTypeString))
+			},
+			input:          []string{"STRING_KEY", "1"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongTypeOperation},
+		},
+		"invalid option": {
+			setup:          setupSortedSet,
+			input:          []string{"ZSET_KEY", "1", "WITHVALUES"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrSyntax},
+		},
+		"negative count out of range": {
+			setup:          setupSortedSet,
+			input:          []string{"ZSET_KEY", "-9223372036854775807"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrValueOutOfRange},
+		},
+		"no count returns a single member": {
+			setup: setupSortedSet,
+			input: []string{"ZSET_KEY"},
+			newValidator: func(output interface{}) {
+				assert.Contains(t, scores, output)
+			},
+		},
+		"positive count with scores": {
+			setup: setupSortedSet,
+			input: []string{"ZSET_KEY", "5", "withscores"},
+			newValidator: func(output interface{}) {
+				result := output.([]string)
+				assert.Len(t, result, 6)
+				seen := make(map[string]struct{})
+				for i := 0; i < len(result); i += 2 {
+					assert.Equal(t, scores[result[i]], result[i+1])
+					seen[result[i]] = struct{}{}
+				}
+				assert.Len(t, seen, 3)
+			},
+		},
+		"negative count with scores": {
+			setup: setupSortedSet,
+			input: []string{"ZSET_KEY", "-7", "WITHSCORES"},
+			newValidator: func(output interface{}) {
+				result := output.([]string)
+				assert.Len(t, result, 14)
+				for i := 0; i < len(result); i += 2 {
+					assert.Equal(t, scores[result[i]], result[i+1])
+				}
+			},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalZRANDMEMBER, store)
+}
+
 func testEvalAPPEND(t *testing.T, store *dstore.Store) {
 	tests := map[string]evalTestCase{
 		"nil value": {
@@ -6714,11 +7718,23 @@ func testEvalAPPEND(t *testing.T, store *dstore.Store) {
 			validator: func(output []byte) {
 				obj := store.Get("key")
 				oType := obj.Type
-				if oType != object.ObjTypeString {
+				if oType != object.ObjTypeByteArray {
 					t.Errorf("unexpected encoding")
 				}
 			},
 		},
+		"append keeps the expiry of the key": {
+			setup: func() {
+				store.Del("key")
+				store.Put("key", store.NewObj("val", 10000, object.ObjTypeString))
+			},
+			input: []string{"key", "val"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, 6, output)
+				_, ok := dstore.GetExpiry(store.Get("key"), store)
+				assert.True(t, ok)
+			},
+		},
 		"append to key created using LPUSH": {
 			setup: func() {
 				key := "listKey"
@@ -6795,6 +7811,90 @@ func BenchmarkEvalAPPEND(b *testing.B) {
 	}
 }
 
+func testEvalSETRANGE(t *testing.T, store *dstore.Store) {
+	tests := map[string]evalTestCase{
+		"wrong number of arguments": {
+			input:          []string{"key", "0"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongArgumentCount("SETRANGE")},
+		},
+		"offset is not an integer": {
+			input:          []string{"key", "abc", "val"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIntegerOutOfRange},
+		},
+		"negative offset": {
+			input:          []string{"key", "-1", "val"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrOffsetOutOfRange},
+		},
+		"offset past the maximum string size": {
+			input:          []string{"key", "536870911", "ab"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrStringTooLong},
+		},
+		"empty value on a non-existing key": {
+			setup: func() { store.Del("key") },
+			input: []string{"key", "5", ""},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, int64(0), output)
+				assert.Nil(t, store.Get("key"))
+			},
+		},
+		"non-existing key is padded with zero bytes": {
+			setup: func() { store.Del("key") },
+			input: []string{"key", "3", "val"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, int64(6), output)
+				assert.Equal(t, "\x00\x00\x00val", evalGET([]string{"key"}, store).Result)
+			},
+		},
+		"overwrite part of a string": {
+			setup: func() {
+				store.Put("key", store.NewObj("Hello World", -1, object.ObjTypeString))
+			},
+			input: []string{"key", "6", "Redis"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, int64(11), output)
+				assert.Equal(t, "Hello Redis", evalGET([]string{"key"}, store).Result)
+				assert.Equal(t, object.ObjTypeByteArray, store.Get("key").Type)
+			},
+		},
+		"overwrite past the end of an integer": {
+			setup: func() {
+				store.Put("key", store.NewObj(int64(1234), -1, object.ObjTypeInt))
+			},
+			input: []string{"key", "2", "5678"},
+			newValidator: func(output interface{}) {
+				assert.Equal(t, int64(6), output)
+				assert.Equal(t, "125678", evalGET([]string{"key"}, store).Result)
+			},
+		},
+		"empty value returns the current length": {
+			setup: func() {
+				store.Put("key", store.NewObj("Hello", -1, object.ObjTypeString))
+			},
+			input:          []string{"key", "10", ""},
+			migratedOutput: EvalResponse{Result: int64(5), Error: nil},
+		},
+		"wrong type": {
+			setup: func() {
+				store.Put("key", store.NewObj(NewDeque(), -1, object.ObjTypeDequeue))
+			},
+			input:          []string{"key", "0", "val"},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrWrongTypeOperation},
+		},
+	}
+
+	runMigratedEvalTests(t, tests, evalSETRANGE, store)
+}
+
+func BenchmarkEvalAPPENDLargeString(b *testing.B) {
+	store := dstore.NewStore(nil, nil)
+	for i := 0; i < b.N; i++ {
+		store.Del("key")
+		for j := 0; j < 10000; j++ {
+			evalAPPEND([]string{"key", "0123456789"}, store)
+		}
+	}
+}
+
 func testEvalJSONRESP(t *testing.T, store *dstore.Store) {
 	tests := map[string]evalTestCase{
 		"wrong number of args passed": {
@@ -7908,8 +9008,8 @@ func testEvalHINCRBYFLOAT(t *testing.T, store *dstore.Store) {
 				store.Put(key, obj)
 			},
 			input:          []string{"key", "field", "a"},
-			output:         []byte("-ERR value is not an integer or a float\r\n"),
-			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidNumberFormat},
+			output:         []byte("-ERR value is not a valid float\r\n"),
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidFloat},
 		},
 		"HINCRBYFLOAT on a field with non-numeric value": {
 			setup: func() {
@@ -7925,7 +9025,7 @@ func testEvalHINCRBYFLOAT(t *testing.T, store *dstore.Store) {
 				store.Put(key, obj)
 			},
 			input:          []string{"key", "field", "0.1"},
-			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrInvalidNumberFormat},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrHashValueNotFloat},
 		},
 		"HINCRBYFLOAT by a value that would turn float64 to Inf": {
 			setup: func() {
@@ -7941,7 +9041,7 @@ func testEvalHINCRBYFLOAT(t *testing.T, store *dstore.Store) {
 				store.Put(key, obj)
 			},
 			input:          []string{"key", "field", "1e308"},
-			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrOverflow},
+			migratedOutput: EvalResponse{Result: nil, Error: diceerrors.ErrIncrNaNOrInf},
 		},
 		"HINCRBYFLOAT with scientific notation": {
 			setup: func() {
@@ -9263,3 +10363,34 @@ func testEvalLRANGE(t *testing.T, store *dstore.Store) {
 	}
 	runMigratedEvalTests(t, tests, evalLRANGE, store)
 }
+
+func TestPushWakesUpBlockedClients(t *testing.T) {
+	store := dstore.NewStore(nil, nil)
+	manager := blocking.NewManager()
+	store.Subscribe(manager)
+
+	for _, push := range []func([]string, *dstore.Store) *EvalResponse{evalLPUSH, evalRPUSH} {
+		store.Del("list")
+		done := make(chan error)
+		go func() {
+			attempts := 0
+			done <- manager.Block(context.Background(), []string{"list"}, 0, func(string) (bool, error) {
+				// Served once woken up by the push
+				attempts++
+				return attempts > 1, nil
+			})
+		}()
+		assert.Eventually(t, func() bool { return manager.Len() == 1 }, time.Second, time.Millisecond)
+
+		var length int64
+		cancel := store.Subscribe(dstore.KeyEventFunc(func(e dstore.KeyEvent) {
+			// The values are pushed by the time the blocked clients are woken up
+			length = store.Get(e.Key).Value.(*Deque).Length
+		}))
+		push([]string{"list", "a", "b"}, store)
+		cancel()
+
+		assert.NoError(t, <-done)
+		assert.Equal(t, store.Get("list").Value.(*Deque).Length, length)
+	}
+}
@@ -17,8 +17,6 @@
 package eval
 
 import (
-	"strings"
-
 	"github.com/dicedb/dice/internal/auth"
 	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/cmd"
@@ -57,40 +55,31 @@ func (e *Eval) PreProcessCommand() *EvalResponse {
 func (e *Eval) ExecuteCommand() *EvalResponse {
 	diceCmd, ok := DiceCmds[e.cmd.Cmd]
 	if !ok {
-		return &EvalResponse{Result: diceerrors.NewErrWithFormattedMessage("unknown command '%s', with args beginning with: %s", e.cmd.Cmd, strings.Join(e.cmd.Args, " ")), Error: nil}
+		return makeEvalError(diceerrors.ErrUnknownCmdWithArgs(e.cmd.Cmd, e.cmd.Args))
 	}
 
-	// Temporary logic till we move all commands to new eval logic.
-	// MigratedDiceCmds map contains refactored eval commands
-	// For any command we will first check in the existing map
-	// if command is NA then we will check in the new map
-	// Check if the dice command has been migrated
-	if diceCmd.IsMigrated {
-		// ===============================================================================
-		// dealing with store object is not recommended for all commands
-		// These operations are specialised for the commands which requires
-		// transferring data across multiple shards. e.g COPY, RENAME
-		// ===============================================================================
-		if e.cmd.InternalObj != nil {
-			// This involves handling object at store level, evaluating it, modifying it, and then storing it back.
-			return diceCmd.StoreObjectEval(e.cmd, e.store)
-		}
-
-		// If the 'Obj' field is nil, handle the command using the arguments.
-		// This path likely involves evaluating the command based on its provided arguments.
-		return diceCmd.NewEval(e.cmd.Args, e.store)
+	// ===============================================================================
+	// dealing with store object is not recommended for all commands
+	// These operations are specialised for the commands which requires
+	// transferring data across multiple shards. e.g COPY, RENAME
+	// ===============================================================================
+	if e.cmd.InternalObj != nil {
+		// This involves handling object at store level, evaluating it, modifying it, and then storing it back.
+		return diceCmd.StoreObjectEval(e.cmd, e.store)
 	}
 
 	// The following commands could be handled at the shard level, however, we can randomly let any shard handle them
 	// to reduce load on main server.
 	switch diceCmd.Name {
-	// Old implementation kept as it is, but we will be moving
-	// to the new implementation soon for all commands
 	case auth.Cmd:
-		return &EvalResponse{Result: EvalAUTH(e.cmd.Args, e.client), Error: nil}
+		return EvalAUTH(e.cmd.Args, e.client)
 	case "ABORT":
-		return &EvalResponse{Result: clientio.RespOK, Error: nil}
-	default:
-		return &EvalResponse{Result: diceCmd.Eval(e.cmd.Args, e.store), Error: nil}
+		return makeEvalResult(clientio.OK)
+	}
+
+	// The commands executed by the IO threads only have no evaluation function in the shards
+	if diceCmd.NewEval == nil {
+		return makeEvalError(diceerrors.ErrUnknownCmdWithArgs(e.cmd.Cmd, e.cmd.Args))
 	}
+	return diceCmd.NewEval(e.cmd.Args, e.store)
 }
@@ -0,0 +1,139 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/axiomhq/hyperloglog"
+	"github.com/bytedance/sonic"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval/sortedset"
+	"github.com/dicedb/dice/internal/export"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// evalSingleExport returns the export.Record of every key of args stored on the shard, in order.
+// Keys that do not exist, e.g. because they were deleted since EXPORT listed them, are skipped.
+// It is the command run by the batches of EXPORT.
+func evalSingleExport(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) == 0 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("SINGLEEXPORT"))
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	records := make([]export.Record, 0, len(args))
+	for _, key := range args {
+		obj := store.Get(key)
+		if obj == nil {
+			continue
+		}
+
+		typ, value, err := exportValue(obj)
+		if err != nil {
+			return makeEvalError(err)
+		}
+
+		ttl := int64(-1)
+		if exp, ok := dstore.GetExpiry(obj, store); ok {
+			ttl = max(int64(exp)-now, 0)
+		}
+		records = append(records, export.Record{Key: key, Type: typ, TTL: ttl, Value: value})
+	}
+	return makeEvalResult(records)
+}
+
+// exportValue returns the export type of obj and its value, as described by export.Record.
+// The value is a copy, it is encoded once the shard moved on to other commands.
+func exportValue(obj *object.Obj) (typ string, value interface{}, err error) {
+	switch obj.Type {
+	case object.ObjTypeString:
+		switch v := obj.Value.(type) {
+		case string:
+			return export.TypeString, v, nil
+		case *hyperloglog.Sketch:
+			data, err := v.MarshalBinary()
+			if err != nil {
+				return "", nil, err
+			}
+			return export.TypeHyperLogLog, base64.StdEncoding.EncodeToString(data), nil
+		}
+	case object.ObjTypeInt:
+		if v, ok := obj.Value.(int64); ok {
+			return export.TypeString, strconv.FormatInt(v, 10), nil
+		}
+	case object.ObjTypeByteArray:
+		if v, ok := obj.Value.(*ByteArray); ok {
+			return export.TypeString, string(v.data), nil
+		}
+	case object.ObjTypeDequeue:
+		if v, ok := obj.Value.(*Deque); ok {
+			elements, err := v.LRange(0, -1)
+			return export.TypeList, elements, err
+		}
+	case object.ObjTypeSet:
+		if v, ok := obj.Value.(map[string]struct{}); ok {
+			members := make([]string, 0, len(v))
+			for member := range v {
+				members = append(members, member)
+			}
+			sort.Strings(members)
+			return export.TypeSet, members, nil
+		}
+	case object.ObjTypeHashMap:
+		if v, ok := obj.Value.(HashMap); ok {
+			fields := make(map[string]string, len(v))
+			for field, fieldValue := range v {
+				fields[field] = fieldValue
+			}
+			return export.TypeHash, fields, nil
+		}
+	case object.ObjTypeSortedSet:
+		if v, ok := obj.Value.(*sortedset.Set); ok {
+			members := v.GetRange(0, -1, false, false)
+			zmembers := make([]export.ZMember, 0, len(members))
+			for _, member := range members {
+				score, _ := v.Get(member)
+				zmembers = append(zmembers, export.ZMember{Member: member, Score: score})
+			}
+			return export.TypeZSet, zmembers, nil
+		}
+	case object.ObjTypeJSON:
+		document, err := sonic.Marshal(obj.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return export.TypeJSON, json.RawMessage(document), nil
+	case object.ObjTypeBF, object.ObjTypeCountMinSketch:
+		typ = export.TypeBloomFilter
+		if obj.Type == object.ObjTypeCountMinSketch {
+			typ = export.TypeCMS
+		}
+		// Serialized as by DUMP, so that the value can be restored with RESTORE
+		data, err := rdbSerialize(obj)
+		if err != nil {
+			return "", nil, err
+		}
+		return typ, base64.StdEncoding.EncodeToString(data), nil
+	}
+	return "", nil, diceerrors.ErrWrongTypeOperation
+}
@@ -17,19 +17,28 @@
 package eval
 
 import (
-	"crypto/rand"
 	"fmt"
 	"math"
-	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/dicedb/dice/internal/clientio"
 	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
 	dstore "github.com/dicedb/dice/internal/store"
 )
 
 type HashMap map[string]string
 
+// Replies of the hash field expiry commands for every field given
+const (
+	fieldNotFound       int64 = -2 // the field or the key does not exist
+	fieldNoExpiry       int64 = -1 // the field exists but has no expiry
+	fieldConditionUnmet int64 = 0  // the expiry was not set because the NX, XX, GT or LT condition does not hold
+	fieldUpdated        int64 = 1  // the expiry of the field was set or removed
+	fieldDeleted        int64 = 2  // the field was deleted as the expiry given is already elapsed
+)
+
 func (h HashMap) Get(k string) (*string, bool) {
 	value, ok := h[k]
 	if !ok {
@@ -50,6 +59,16 @@ func (h HashMap) Set(k, v string) (*string, bool) {
 	return nil, false
 }
 
+// DeleteField removes the field from the hash, it is called by the store when the field expires.
+func (h HashMap) DeleteField(field string) {
+	delete(h, field)
+}
+
+// FieldCount returns the number of fields of the hash.
+func (h HashMap) FieldCount() int {
+	return len(h)
+}
+
 func hashMapBuilder(keyValuePairs []string, currentHashMap HashMap) (HashMap, int64, error) {
 	var hmap HashMap
 	var numKeysNewlySet int64
@@ -81,6 +100,50 @@ func hashMapBuilder(keyValuePairs []string, currentHashMap HashMap) (HashMap, in
 	return hmap, numKeysNewlySet, nil
 }
 
+// parseHashFields parses the FIELDS numfields field [field ...] block ending the arguments of the hash
+// field expiry commands and returns the fields.
+func parseHashFields(args []string) ([]string, error) {
+	if len(args) < 2 || !strings.EqualFold(args[0], Fields) {
+		return nil, diceerrors.ErrGeneral("Mandatory argument FIELDS is missing or not at the right position")
+	}
+
+	numFields, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || numFields <= 0 {
+		return nil, diceerrors.ErrGeneral("Parameter `numFields` should be greater than 0")
+	}
+	if numFields != int64(len(args)-2) {
+		return nil, diceerrors.ErrGeneral("The `numfields` parameter must match the number of arguments")
+	}
+	return args[2:], nil
+}
+
+// getHashMap returns the object and the hash stored at key, or nil if the key does not exist.
+func getHashMap(key string, store *dstore.Store) (*object.Obj, HashMap, error) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, nil, nil
+	}
+	if err := object.AssertType(obj.Type, object.ObjTypeHashMap); err != nil {
+		return nil, nil, diceerrors.ErrWrongTypeOperation
+	}
+	return obj, obj.Value.(HashMap), nil
+}
+
+// deleteHashFields removes the fields, along with their expiry, from the hash stored at key. The key is
+// deleted once its hash is empty.
+func deleteHashFields(key string, obj *object.Obj, hashMap HashMap, fields []string, store *dstore.Store) {
+	for _, field := range fields {
+		delete(hashMap, field)
+		store.DelFieldExpiry(obj, field)
+	}
+
+	if len(hashMap) == 0 {
+		store.Del(key)
+		return
+	}
+	store.Put(key, obj)
+}
+
 func getValueFromHashMap(key, field string, store *dstore.Store) *EvalResponse {
 	obj := store.Get(key)
 	if obj == nil {
@@ -134,71 +197,22 @@ func (h HashMap) incrementValue(field string, increment int64) (int64, error) {
 	return total, nil
 }
 
+// incrementFloatValue increments the field by incr, a field that does not exist being incremented from 0,
+// and returns the new value. The field holds the value as it is returned, see formatIncrFloat.
 func (h HashMap) incrementFloatValue(field string, incr float64) (string, error) {
-	val, ok := h[field]
-	if !ok {
-		h[field] = fmt.Sprintf("%v", incr)
-		strValue := formatFloat(incr, false)
-		return strValue, nil
-	}
-
-	i, err := strconv.ParseFloat(val, 64)
-	if err != nil {
-		return "-1", diceerrors.NewErr(diceerrors.IntOrFloatErr)
-	}
-
-	if math.IsInf(i+incr, 1) || math.IsInf(i+incr, -1) {
-		return "-1", diceerrors.NewErr(diceerrors.IncrDecrOverflowErr)
-	}
-
-	total := i + incr
-	strValue := formatFloat(total, false)
-	h[field] = fmt.Sprintf("%v", total)
-
-	return strValue, nil
-}
-
-// selectRandomFields returns random fields from a hashmap.
-func selectRandomFields(hashMap HashMap, count int, withValues bool) *EvalResponse {
-	keys := make([]string, 0, len(hashMap))
-	for k := range hashMap {
-		keys = append(keys, k)
-	}
-
-	var results []string
-	resultSet := make(map[string]struct{})
-
-	abs := func(x int) int {
-		if x < 0 {
-			return -x
+	var value float64
+	if val, ok := h[field]; ok {
+		if value, ok = parseIncrFloat(val); !ok {
+			return "", diceerrors.ErrHashValueNotFloat
 		}
-		return x
 	}
 
-	for i := 0; i < abs(count); i++ {
-		if count > 0 && len(resultSet) == len(keys) {
-			break
-		}
-
-		randomIndex, _ := rand.Int(rand.Reader, big.NewInt(int64(len(keys))))
-		randomField := keys[randomIndex.Int64()]
-
-		if count > 0 {
-			if _, exists := resultSet[randomField]; exists {
-				i--
-				continue
-			}
-			resultSet[randomField] = struct{}{}
-		}
-
-		results = append(results, randomField)
-		if withValues {
-			results = append(results, hashMap[randomField])
-		}
+	total := value + incr
+	if math.IsNaN(total) || math.IsInf(total, 0) {
+		return "", diceerrors.ErrIncrNaNOrInf
 	}
 
-	return &EvalResponse{
-		Result: results,
-		Error:  nil,
-	}
+	strValue := formatIncrFloat(total)
+	h[field] = strValue
+	return strValue, nil
 }
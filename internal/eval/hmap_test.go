@@ -133,15 +133,15 @@ func TestHashMapIncrementFloatValue(t *testing.T) {
 	hmap.Set("field2", "notAFloat")
 	val, err = hmap.incrementFloatValue("field2", 1.0)
 	assert.NotNil(t, err, "Expected error when incrementing a non-float value")
-	assert.Equal(t, errors.IntOrFloatErr, err.Error(), "Expected int or float error")
+	assert.Equal(t, errors.ErrHashValueNotFloat, err, "Expected hash value not a float error")
 
 	inf := math.MaxFloat64
 
 	val, err = hmap.incrementFloatValue("field1", inf+float64(1e308))
 	assert.NotNil(t, err, "Expected error when incrementing a overflowing value")
-	assert.Equal(t, errors.IncrDecrOverflowErr, err.Error(), "Expected overflow to be detected")
+	assert.Equal(t, errors.ErrIncrNaNOrInf, err, "Expected overflow to be detected")
 
 	val, err = hmap.incrementFloatValue("field1", -inf-float64(1e308))
 	assert.NotNil(t, err, "Expected error when incrementing a overflowing value")
-	assert.Equal(t, errors.IncrDecrOverflowErr, err.Error(), "Expected overflow to be detected")
+	assert.Equal(t, errors.ErrIncrNaNOrInf, err, "Expected overflow to be detected")
 }
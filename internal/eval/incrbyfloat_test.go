@@ -0,0 +1,227 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedNotation is the format of the results of INCRBYFLOAT and HINCRBYFLOAT: no exponent, no trailing zeros
+var fixedNotation = regexp.MustCompile(`^-?[0-9]+(\.[0-9]*[1-9])?$`)
+
+// refFormatIncrFloat is the reference formatting of the results of INCRBYFLOAT: the fewest significant
+// digits, up to 17, that read back as f are written out in fixed notation, and rounded to 17 decimals.
+func refFormatIncrFloat(f float64) string {
+	if f == 0 {
+		return "0"
+	}
+
+	var mantissa string
+	var exp int
+	for digits := 1; digits <= 17; digits++ {
+		e := strconv.FormatFloat(f, 'e', digits-1, 64)
+		if parsed, _ := strconv.ParseFloat(e, 64); parsed == f || digits == 17 {
+			parts := strings.SplitN(e, "e", 2)
+			mantissa = strings.Replace(strings.TrimPrefix(parts[0], "-"), ".", "", 1)
+			exp, _ = strconv.Atoi(parts[1])
+			break
+		}
+	}
+
+	// The digits are d.ddd x 10^exp, the point goes after the first exp+1 digits
+	var intPart, fracPart string
+	switch point := exp + 1; {
+	case point <= 0:
+		intPart, fracPart = "0", strings.Repeat("0", -point)+mantissa
+	case point >= len(mantissa):
+		intPart = mantissa + strings.Repeat("0", point-len(mantissa))
+	default:
+		intPart, fracPart = mantissa[:point], mantissa[point:]
+	}
+	if len(fracPart) > maxIncrFloatDecimals {
+		return formatTrimmed(strconv.FormatFloat(f, 'f', maxIncrFloatDecimals, 64))
+	}
+
+	s := intPart
+	if fracPart = strings.TrimRight(fracPart, "0"); fracPart != "" {
+		s += "." + fracPart
+	}
+	if f < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// formatTrimmed trims the trailing zeros of the decimals, and a negative zero
+func formatTrimmed(s string) string {
+	s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// refIncrByFloat is the reference model of INCRBYFLOAT and HINCRBYFLOAT: the value held and the increment
+// are parsed as Redis does, and the sum is formatted by refFormatIncrFloat.
+func refIncrByFloat(value, incr string) (string, error) {
+	parse := func(s string) (float64, bool) {
+		if s == "" || s[0] == ' ' || s[len(s)-1] == ' ' {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil && !math.IsNaN(f)
+	}
+
+	i, ok := parse(incr)
+	if !ok {
+		return "", diceerrors.ErrInvalidFloat
+	}
+	v := 0.0
+	if value != "" {
+		if v, ok = parse(value); !ok {
+			return "", diceerrors.ErrInvalidFloat
+		}
+	}
+	sum := v + i
+	if math.IsNaN(sum) || math.IsInf(sum, 0) {
+		return "", diceerrors.ErrIncrNaNOrInf
+	}
+	return refFormatIncrFloat(sum), nil
+}
+
+// randomFloat returns floats of every magnitude: whole numbers, short decimals, tiny and huge values and
+// arbitrary bit patterns.
+func randomFloat(r *rand.Rand) float64 {
+	switch r.Intn(5) {
+	case 0:
+		return float64(r.Int63n(1<<20) - 1<<19)
+	case 1:
+		return float64(r.Int63n(1_000_000)-500_000) / 1000
+	case 2:
+		return (r.Float64() - 0.5) * math.Pow10(-r.Intn(25))
+	case 3:
+		return (r.Float64() - 0.5) * math.Pow10(r.Intn(309))
+	default:
+		for {
+			if f := math.Float64frombits(r.Uint64()); !math.IsNaN(f) && !math.IsInf(f, 0) {
+				return f
+			}
+		}
+	}
+}
+
+// randomIncrement returns the increments given to INCRBYFLOAT, the invalid ones included.
+func randomIncrement(r *rand.Rand) string {
+	invalid := []string{"", " 1", "1 ", "abc", "nan", "1e400", "1.2.3", "0x"}
+	switch r.Intn(10) {
+	case 0:
+		return invalid[r.Intn(len(invalid))]
+	case 1:
+		return []string{"inf", "-inf", "1e308", "-1e308"}[r.Intn(4)]
+	case 2:
+		return strconv.FormatFloat(randomFloat(r), 'e', -1, 64)
+	default:
+		return strconv.FormatFloat(randomFloat(r), 'f', -1, 64)
+	}
+}
+
+func TestFormatIncrFloatProperties(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		f := randomFloat(r)
+		s := formatIncrFloat(f)
+
+		assert.Regexp(t, fixedNotation, s, "formatting %v", f)
+		assert.Equal(t, refFormatIncrFloat(f), s, "formatting %v", f)
+
+		// The value is kept whole unless it has more than 17 decimals
+		parsed, err := strconv.ParseFloat(s, 64)
+		assert.Nil(t, err)
+		if math.Abs(f) >= 1 {
+			assert.Equal(t, f, parsed, "formatting %v", f)
+		} else {
+			// Half of the last of the 17 decimals, plus the rounding of the parsing
+			assert.InDelta(t, f, parsed, 1e-17, "formatting %v", f)
+		}
+	}
+
+	assert.Equal(t, "0.1", formatIncrFloat(0.1))
+	a, b := 0.1, 0.2
+	assert.Equal(t, "0.30000000000000004", formatIncrFloat(a+b))
+	assert.Equal(t, "0", formatIncrFloat(math.Copysign(0, -1)))
+	assert.Equal(t, "0", formatIncrFloat(1e-20))
+	assert.Equal(t, "0.00000000012345679", formatIncrFloat(1.2345678901234567e-10))
+	assert.Equal(t, "100000000000000000000", formatIncrFloat(1e20))
+}
+
+func TestIncrByFloatProperties(t *testing.T) {
+	store := dstore.NewStore(nil, nil)
+	r := rand.New(rand.NewSource(1))
+
+	for run := 0; run < 200; run++ {
+		store.Del("key")
+		store.Del("hash")
+		model := ""
+		for step := 0; step < 50; step++ {
+			incr := randomIncrement(r)
+			expected, expectedErr := refIncrByFloat(model, incr)
+
+			resp := evalINCRBYFLOAT([]string{"key", incr}, store)
+			hresp := evalHINCRBYFLOAT([]string{"hash", "field", incr}, store)
+			if expectedErr != nil {
+				assert.Equal(t, expectedErr, resp.Error, "INCRBYFLOAT %q by %q", model, incr)
+				assert.Equal(t, expectedErr, hresp.Error, "HINCRBYFLOAT %q by %q", model, incr)
+				continue
+			}
+
+			assert.Nil(t, resp.Error, "INCRBYFLOAT %q by %q", model, incr)
+			assert.Equal(t, expected, resp.Result, "INCRBYFLOAT %q by %q", model, incr)
+			assert.Nil(t, hresp.Error, "HINCRBYFLOAT %q by %q", model, incr)
+			assert.Equal(t, expected, hresp.Result, "HINCRBYFLOAT %q by %q", model, incr)
+
+			// The value held reads back as the value returned, a whole value being held as an integer
+			assert.Equal(t, expected, fmt.Sprint(evalGET([]string{"key"}, store).Result), "GET after INCRBYFLOAT by %q", incr)
+			assert.Equal(t, expected, evalHGET([]string{"hash", "field"}, store).Result, "HGET after HINCRBYFLOAT by %q", incr)
+			model = expected
+		}
+	}
+}
+
+func TestDecrByMinInt64(t *testing.T) {
+	store := dstore.NewStore(nil, nil)
+
+	resp := evalDECRBY([]string{"key", strconv.FormatInt(math.MinInt64, 10)}, store)
+	assert.Equal(t, diceerrors.ErrDecrOverflow, resp.Error)
+	assert.Nil(t, store.Get("key"))
+
+	resp = evalDECRBY([]string{"key", strconv.FormatInt(math.MaxInt64, 10)}, store)
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, int64(-math.MaxInt64), resp.Result)
+
+	resp = evalDECRBY([]string{"key", "2"}, store)
+	assert.Equal(t, diceerrors.ErrOverflow, resp.Error)
+}
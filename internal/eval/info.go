@@ -0,0 +1,323 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/backup"
+	"github.com/dicedb/dice/internal/bigkeys"
+	"github.com/dicedb/dice/internal/stats"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// ShardInfo is the part of the INFO reply that only a shard can compute.
+// The io-thread merges the ShardInfo of every shard into a single reply.
+type ShardInfo struct {
+	// Sections requested by the client, passed through so the merged reply can be filtered
+	Sections []string
+	Keys     uint64
+	Expires  uint64
+	// Fragmentation is the memory held by the tables of the shard for entries they no longer have
+	Fragmentation dstore.FragmentationStats
+}
+
+// infoSection is a section of the INFO reply, written as a '# Title' header followed by 'field:value' lines.
+type infoSection struct {
+	name  string
+	title string
+	write func(b *strings.Builder, shards []ShardInfo)
+	// extra sections are only reported when requested by name, 'all' or 'everything'
+	extra bool
+}
+
+// infoSections lists the INFO sections in the order they are reported.
+var infoSections = []infoSection{
+	{name: "server", title: "Server", write: writeServerInfo},
+	{name: "clients", title: "Clients", write: writeClientsInfo},
+	{name: "memory", title: "Memory", write: writeMemoryInfo},
+	{name: "persistence", title: "Persistence", write: writePersistenceInfo},
+	{name: "stats", title: "Stats", write: writeStatsInfo},
+	{name: "replication", title: "Replication", write: writeReplicationInfo},
+	{name: "qwatch", title: "Qwatch", write: writeQwatchInfo},
+	{name: "commandstats", title: "Commandstats", write: writeCommandstatsInfo, extra: true},
+	{name: "keyspace", title: "Keyspace", write: writeKeyspaceInfo},
+	{name: "bigkeys", title: "Bigkeys", write: writeBigKeysInfo, extra: true},
+}
+
+func newShardInfo(sections []string, store *dstore.Store) ShardInfo {
+	// Expired keys are deleted first so that they are not reported, like DBSIZE does
+	dstore.DeleteExpiredKeys(store)
+
+	return ShardInfo{
+		Sections: sections,
+		Keys:     store.GetDBSize(),
+		Expires:  store.GetExpiresCount(),
+
+		Fragmentation: store.Fragmentation(),
+	}
+}
+
+// FormatInfo renders the INFO reply for the requested sections from the per-shard information.
+// No sections and 'default' select every section but the extra ones, 'all' and 'everything' select
+// every section; unknown sections are ignored.
+func FormatInfo(sections []string, shards []ShardInfo) string {
+	selected := make(map[string]bool, len(sections))
+	for _, section := range sections {
+		selected[strings.ToLower(section)] = true
+	}
+	defaults := len(sections) == 0 || selected["default"]
+	everything := selected["all"] || selected["everything"]
+
+	var b strings.Builder
+	for _, section := range infoSections {
+		if !everything && !selected[section.name] && (!defaults || section.extra) {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString("# " + section.title + "\r\n")
+		section.write(&b, shards)
+	}
+
+	return b.String()
+}
+
+func writeInfoField(b *strings.Builder, field string, value interface{}) {
+	fmt.Fprintf(b, "%s:%v\r\n", field, value)
+}
+
+// boolToInt reports a flag as 1 or 0, the way INFO does.
+func boolToInt(flag bool) int {
+	if flag {
+		return 1
+	}
+	return 0
+}
+
+func writeServerInfo(b *strings.Builder, shards []ShardInfo) {
+	uptime := time.Since(stats.Get().StartTime)
+
+	writeInfoField(b, "dicedb_version", config.DiceDBVersion)
+	writeInfoField(b, "os", runtime.GOOS+" "+runtime.GOARCH)
+	writeInfoField(b, "arch_bits", strconv.IntSize)
+	writeInfoField(b, "go_version", runtime.Version())
+	writeInfoField(b, "process_id", os.Getpid())
+	writeInfoField(b, "tcp_port", config.DiceConfig().RespServer.Port)
+	writeInfoField(b, "uptime_in_seconds", int64(uptime.Seconds()))
+	writeInfoField(b, "uptime_in_days", int64(uptime.Hours()/24))
+	writeInfoField(b, "num_shards", len(shards))
+	writeInfoField(b, "config_file", config.ConfigFilePath)
+}
+
+func writeClientsInfo(b *strings.Builder, _ []ShardInfo) {
+	writeInfoField(b, "connected_clients", stats.Get().ConnectedClients)
+	writeInfoField(b, "blocked_clients", stats.Get().BlockedClients)
+	writeInfoField(b, "maxclients", config.DiceConfig().Performance.MaxClients)
+}
+
+func writeMemoryInfo(b *strings.Builder, shards []ShardInfo) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	writeInfoField(b, "used_memory", m.HeapAlloc)
+	writeInfoField(b, "used_memory_human", bytesToHuman(m.HeapAlloc))
+	// Memory obtained from the OS by the Go runtime, the closest to the resident set size it exposes
+	writeInfoField(b, "used_memory_rss", m.Sys)
+	writeInfoField(b, "used_memory_rss_human", bytesToHuman(m.Sys))
+	writeInfoField(b, "maxmemory", config.DiceConfig().Memory.MaxMemory)
+	writeInfoField(b, "maxmemory_human", bytesToHuman(uint64(config.DiceConfig().Memory.MaxMemory)))
+	writeInfoField(b, "maxmemory_policy", config.DiceConfig().Memory.EvictionPolicy)
+	writeInfoField(b, "mem_allocator", "go")
+	writeInfoField(b, "lazyfree_pending_objects", stats.Get().LazyfreePendingObjects)
+	writeInfoField(b, "gc_cycles", m.NumGC)
+
+	var entries, capacity, scheduled, expires, rebuilding int
+	for i := range shards {
+		f := shards[i].Fragmentation
+		entries += f.Entries
+		capacity += f.Capacity
+		scheduled += f.Scheduled
+		expires += f.Expires
+		if f.Rebuilding {
+			rebuilding++
+		}
+	}
+	fragmentation := 0
+	if capacity > 0 {
+		fragmentation = (capacity - entries) * 100 / capacity
+	}
+	s := stats.Get()
+	writeInfoField(b, "table_entries", entries)
+	writeInfoField(b, "table_capacity", capacity)
+	writeInfoField(b, "table_fragmentation_percent", fragmentation)
+	writeInfoField(b, "expiries_scheduled", scheduled)
+	writeInfoField(b, "expiries_stale", max(scheduled-expires, 0))
+	writeInfoField(b, "active_defrag_running", rebuilding)
+	writeInfoField(b, "active_defrag_hits", s.DefragHits)
+	writeInfoField(b, "active_defrag_expiries_dropped", s.DefragExpiriesDropped)
+	writeInfoField(b, "active_defrag_rebuilds", s.DefragRebuilds)
+}
+
+func writePersistenceInfo(b *strings.Builder, _ []ShardInfo) {
+	s := backup.Get()
+	writeInfoField(b, "loading", boolToInt(s.Loading))
+	if s.Loading {
+		writeInfoField(b, "loading_loaded_keys", s.LoadedKeys)
+	}
+	if s.LoadErr != nil {
+		writeInfoField(b, "loading_last_error", strings.ReplaceAll(s.LoadErr.Error(), "\n", " "))
+	}
+	writeInfoField(b, "aof_enabled", boolToInt(config.DiceConfig().Persistence.Enabled))
+	writeInfoField(b, "backup_enabled", boolToInt(s.Enabled))
+	writeInfoField(b, "backup_in_progress", boolToInt(s.InProgress))
+	writeInfoField(b, "backup_retained", s.Retained)
+	if !s.Next.IsZero() {
+		writeInfoField(b, "backup_next_time", s.Next.Unix())
+	}
+	if s.LastAt.IsZero() {
+		return
+	}
+
+	lastStatus := "ok"
+	if s.LastErr != nil {
+		lastStatus = "err"
+	}
+	writeInfoField(b, "backup_last_time", s.LastAt.Unix())
+	writeInfoField(b, "backup_last_status", lastStatus)
+	if s.LastErr != nil {
+		writeInfoField(b, "backup_last_error", strings.ReplaceAll(s.LastErr.Error(), "\n", " "))
+	}
+	writeInfoField(b, "backup_last_duration_ms", s.LastDuration.Milliseconds())
+	writeInfoField(b, "backup_last_name", s.LastName)
+	writeInfoField(b, "backup_last_keys", s.LastKeys)
+	writeInfoField(b, "backup_last_size", s.LastSize)
+}
+
+func writeStatsInfo(b *strings.Builder, _ []ShardInfo) {
+	s := stats.Get()
+	writeInfoField(b, "total_connections_received", s.TotalConnectionsReceived)
+	writeInfoField(b, "total_commands_processed", s.TotalCommandsProcessed)
+	writeInfoField(b, "rejected_connections", s.RejectedConnections)
+	writeInfoField(b, "keyspace_hits", s.KeyspaceHits)
+	writeInfoField(b, "keyspace_misses", s.KeyspaceMisses)
+	writeInfoField(b, "lazyfreed_objects", s.LazyfreedObjects)
+	writeInfoField(b, "tier_spilled_values", s.TierSpilledValues)
+	writeInfoField(b, "tier_loaded_values", s.TierLoadedValues)
+	writeInfoField(b, "cache_loaded_keys", s.CacheLoadedKeys)
+	writeInfoField(b, "cache_load_failures", s.CacheLoadFailures)
+	writeInfoField(b, "cache_written_keys", s.CacheWrittenKeys)
+	writeInfoField(b, "cache_write_failures", s.CacheWriteFailures)
+	writeInfoField(b, "slow_lane_commands", s.SlowLaneCommands)
+	writeInfoField(b, "total_shard_crashes", s.ShardCrashes)
+	if !s.LastShardCrash.IsZero() {
+		writeInfoField(b, "last_shard_crash_time", s.LastShardCrash.Unix())
+	}
+	writeInfoField(b, "shard_health", stats.Health(config.DiceConfig().Performance.ShardCrashWindow))
+}
+
+func writeReplicationInfo(b *strings.Builder, _ []ShardInfo) {
+	writeInfoField(b, "role", "master")
+	writeInfoField(b, "connected_slaves", 0)
+}
+
+func writeQwatchInfo(b *strings.Builder, _ []ShardInfo) {
+	w := stats.Get().Watch
+	var latency, evaluation int64
+	if w.Pushes > 0 {
+		latency, evaluation = w.PushLatencyUsec/w.Pushes, w.EvaluationUsec/w.Pushes
+	}
+	writeInfoField(b, "qwatch_queries", w.Queries)
+	writeInfoField(b, "qwatch_subscriptions", w.Subscriptions)
+	writeInfoField(b, "qwatch_pushes", w.Pushes)
+	writeInfoField(b, "qwatch_push_latency_avg_usec", latency)
+	writeInfoField(b, "qwatch_evaluation_avg_usec", evaluation)
+	writeInfoField(b, "qwatch_coalesced_updates", w.CoalescedUpdates)
+	writeInfoField(b, "qwatch_dropped_updates", w.DroppedUpdates)
+	writeInfoField(b, "qwatch_lagging_subscriptions", w.Lagging)
+}
+
+func writeCommandstatsInfo(b *strings.Builder, _ []ShardInfo) {
+	for _, c := range stats.GetCommandStats() {
+		writeInfoField(b, "cmdstat_"+strings.ToLower(c.Command), fmt.Sprintf("calls=%d,usec=%d,usec_per_call=%.2f,failed_calls=%d",
+			c.Calls, c.Usec, float64(c.Usec)/float64(c.Calls), c.FailedCalls))
+	}
+}
+
+func writeKeyspaceInfo(b *strings.Builder, shards []ShardInfo) {
+	var keys, expires uint64
+	for _, shard := range shards {
+		keys += shard.Keys
+		expires += shard.Expires
+	}
+
+	// Like Redis, empty databases are not reported
+	if keys > 0 {
+		writeInfoField(b, "db0", fmt.Sprintf("keys=%d,expires=%d", keys, expires))
+	}
+}
+
+// writeBigKeysInfo reports the last analysis of the big keys: the totals of every type and its largest key by
+// size and by number of elements.
+func writeBigKeysInfo(b *strings.Builder, _ []ShardInfo) {
+	scanning := 0
+	if bigkeys.Scanning() {
+		scanning = 1
+	}
+	writeInfoField(b, "bigkeys_scan_in_progress", scanning)
+
+	report := bigkeys.Latest()
+	if report == nil {
+		return
+	}
+	writeInfoField(b, "bigkeys_last_scan_time", report.FinishedAt.Unix())
+	writeInfoField(b, "bigkeys_last_scan_duration_ms", report.FinishedAt.Sub(report.StartedAt).Milliseconds())
+	writeInfoField(b, "bigkeys_scanned_keys", report.Keys)
+	for _, t := range report.Types {
+		writeInfoField(b, "type_"+t.Type, fmt.Sprintf("keys=%d,size=%d,elements=%d", t.Keys, t.Size, t.Elements))
+		if len(t.BySize) > 0 {
+			writeInfoField(b, "largest_"+t.Type+"_by_size", fmt.Sprintf("key=%s,size=%d", t.BySize[0].Key, t.BySize[0].Size))
+		}
+		if len(t.ByElements) > 0 {
+			writeInfoField(b, "largest_"+t.Type+"_by_elements",
+				fmt.Sprintf("key=%s,elements=%d", t.ByElements[0].Key, t.ByElements[0].Elements))
+		}
+	}
+}
+
+// bytesToHuman formats a number of bytes the way INFO does, e.g. 1.50M.
+func bytesToHuman(n uint64) string {
+	units := []string{"B", "K", "M", "G", "T", "P"}
+	value := float64(n)
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%dB", n)
+	}
+	return fmt.Sprintf("%.2f%s", value, units[i])
+}
@@ -0,0 +1,176 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"runtime"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval/sortedset"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// ShardMemoryStats is the part of the MEMORY STATS reply that only a shard can compute.
+// The io-thread merges the ShardMemoryStats of every shard into a single reply.
+type ShardMemoryStats struct {
+	Keys      uint64
+	Interning dstore.InterningStats
+}
+
+func newShardMemoryStats(store *dstore.Store) ShardMemoryStats {
+	return ShardMemoryStats{
+		Keys:      store.GetDBSize(),
+		Interning: store.InterningStats(),
+	}
+}
+
+// FormatMemoryStats renders the MEMORY STATS reply from the per-shard stats, as a flat array of
+// field names and values.
+func FormatMemoryStats(shards []ShardMemoryStats) []interface{} {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	var total ShardMemoryStats
+	for _, shard := range shards {
+		total.Keys += shard.Keys
+		total.Interning.Prefixes += shard.Interning.Prefixes
+		total.Interning.SavedBytes += shard.Interning.SavedBytes
+	}
+
+	interning := "no"
+	if config.DiceConfig().Memory.KeyPrefixInterning {
+		interning = "yes"
+	}
+
+	return []interface{}{
+		"total.allocated", int64(m.HeapAlloc),
+		"keys.count", int64(total.Keys),
+		"keys.prefix-interning", interning,
+		"keys.interned-prefixes", int64(total.Interning.Prefixes),
+		"keys.interning-bytes-saved", total.Interning.SavedBytes,
+	}
+}
+
+// evalMEMORY evaluates MEMORY STATS on the shard the command is executed on.
+// The RESP io-threads merge the stats of every shard through SINGLEMEMORY instead.
+func evalMEMORY(args []string, store *dstore.Store) *EvalResponse {
+	resp := evalSingleMemory(args, store)
+	if stats, ok := resp.Result.(ShardMemoryStats); ok {
+		return makeEvalResult(FormatMemoryStats([]ShardMemoryStats{stats}))
+	}
+	return resp
+}
+
+// evalSingleMemory evaluates MEMORY on the shard. MEMORY STATS returns the ShardMemoryStats of the
+// shard rather than the reply so that the stats of every shard can be merged.
+func evalSingleMemory(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) == 0 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("MEMORY"))
+	}
+
+	subcommand := strings.ToUpper(args[0])
+	switch subcommand {
+	case Stats:
+		if len(args) != 1 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("MEMORY|STATS"))
+		}
+		return makeEvalResult(newShardMemoryStats(store))
+	default:
+		return makeEvalError(diceerrors.ErrGeneral(fmt.Sprintf("unknown subcommand '%s'.", subcommand)))
+	}
+}
+
+// sizeSamples is the number of members of a collection measured by ObjectSize, the size of the others being
+// extrapolated from them, like the default of MEMORY USAGE in Redis
+const sizeSamples = 5
+
+// ObjectSize estimates the number of bytes of the value of a key. The members of the sets, hashes and sorted
+// sets are sampled rather than all measured, so that a collection is measured in constant time.
+func ObjectSize(obj *object.Obj) int64 {
+	switch v := obj.Value.(type) {
+	case string:
+		return int64(len(v))
+	case int64:
+		return 8
+	case map[string]struct{}:
+		return sampleSize(len(v), func(yield func(int64) bool) {
+			for member := range v {
+				if !yield(int64(len(member))) {
+					return
+				}
+			}
+		})
+	case HashMap:
+		return sampleSize(len(v), func(yield func(int64) bool) {
+			for field, value := range v {
+				if !yield(int64(len(field) + len(value))) {
+					return
+				}
+			}
+		})
+	case *sortedset.Set:
+		return sampleSize(v.Len(), func(yield func(int64) bool) {
+			for member := range v.Members() {
+				// The score of every member is a float64
+				if !yield(int64(len(member)) + 8) {
+					return
+				}
+			}
+		})
+	case *Deque:
+		return v.list.size
+	case *ByteArray:
+		return int64(len(v.data))
+	case *Bloom:
+		return int64(len(v.bitset))
+	case *CountMinSketch:
+		var size int64
+		for _, row := range v.matrix {
+			size += int64(len(row)) * 8
+		}
+		return size
+	default:
+		// JSON documents, measured by their encoding
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return 0
+		}
+		return int64(len(encoded))
+	}
+}
+
+// sampleSize extrapolates the size of a collection of n members from the sizes of its first sizeSamples
+// members, which are random for the maps.
+func sampleSize(n int, sizes iter.Seq[int64]) int64 {
+	var sampled, total int64
+	for size := range sizes {
+		total += size
+		if sampled++; sampled == sizeSamples {
+			break
+		}
+	}
+	if sampled == 0 {
+		return 0
+	}
+	return total * int64(n) / sampled
+}
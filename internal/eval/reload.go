@@ -0,0 +1,100 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// reloadTypeNames names the object types in the DEBUG RELOAD VERIFY reply. The encodings of a string
+// are reported apart, as each of them is encoded differently.
+var reloadTypeNames = map[object.ObjectType]string{
+	object.ObjTypeString:         "string",
+	object.ObjTypeInt:            "int",
+	object.ObjTypeByteArray:      "bytearray",
+	object.ObjTypeJSON:           "json",
+	object.ObjTypeSet:            "set",
+	object.ObjTypeHashMap:        "hash",
+	object.ObjTypeSortedSet:      "zset",
+	object.ObjTypeCountMinSketch: "cms",
+	object.ObjTypeBF:             "bloom",
+	object.ObjTypeDequeue:        "list",
+}
+
+// evalSingleReload reloads the dataset of the shard through the DUMP encoding and returns the digests
+// of the keys of each type, so that the io-thread can merge the digests of every shard.
+func evalSingleReload(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 0 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("DEBUG|RELOAD"))
+	}
+	return makeEvalResult(store.Reload(ObjectCodec{}))
+}
+
+// FormatReload renders the DEBUG RELOAD reply from the digests of every shard. DEBUG RELOAD replies OK,
+// DEBUG RELOAD VERIFY returns one [type, status, detail] entry per type of the dataset, the status being
+// ok when the keys of the type round-tripped unchanged, mismatch when they did not and unsupported when
+// the type can't be persisted.
+func FormatReload(shards []map[object.ObjectType]*dstore.ReloadDigest, verify bool) interface{} {
+	if !verify {
+		return clientio.OK
+	}
+
+	total := make(map[object.ObjectType]*dstore.ReloadDigest)
+	for _, digests := range shards {
+		for t, d := range digests {
+			sum, ok := total[t]
+			if !ok {
+				sum = &dstore.ReloadDigest{}
+				total[t] = sum
+			}
+			sum.Keys += d.Keys
+			sum.Before += d.Before
+			sum.After += d.After
+			sum.Unsupported += d.Unsupported
+			sum.Failed += d.Failed
+		}
+	}
+
+	resp := make([]interface{}, 0, len(total))
+	for t, d := range total {
+		name, ok := reloadTypeNames[t]
+		if !ok {
+			name = fmt.Sprintf("type-%d", t)
+		}
+
+		status, detail := "ok", fmt.Sprintf("%d keys", d.Keys)
+		switch {
+		case d.Unsupported > 0:
+			status, detail = "unsupported", fmt.Sprintf("%d keys kept in memory", d.Unsupported)
+		case d.Failed > 0:
+			status, detail = "mismatch", fmt.Sprintf("%d of %d keys could not be decoded", d.Failed, d.Keys)
+		case d.Before != d.After:
+			status, detail = "mismatch", fmt.Sprintf("%d keys, digest %016x before, %016x after", d.Keys, d.Before, d.After)
+		}
+		resp = append(resp, []interface{}{name, status, detail})
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].([]interface{})[0].(string) < resp[j].([]interface{})[0].(string)
+	})
+	return resp
+}
@@ -0,0 +1,127 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"iter"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// maxSampleWithRepetition bounds the number of members a negative COUNT can ask for,
+// as every one of them is allocated before the reply is sent.
+const maxSampleWithRepetition = 1 << 24
+
+// sampleDistinct returns min(count, size) distinct members picked uniformly at random among the
+// size members of the collection. It uses reservoir sampling: the collection is visited once and
+// only the sampled members are held in memory, however large the collection is.
+func sampleDistinct(members iter.Seq[string], size, count int) []string {
+	count = min(count, size)
+	reservoir := make([]string, 0, count)
+	if count == 0 {
+		return reservoir
+	}
+
+	seen := 0
+	for member := range members {
+		if seen < count {
+			reservoir = append(reservoir, member)
+		} else if i := rand.IntN(seen + 1); i < count { //nolint:gosec
+			reservoir[i] = member
+		}
+		seen++
+	}
+
+	// The reservoir is filled in the order the members are visited
+	shuffle(reservoir)
+	return reservoir
+}
+
+// sampleWithRepetition returns count members picked uniformly at random among the size members
+// of the collection, the same member can be returned multiple times. The positions of the members
+// are drawn first so that they are all collected in a single visit of the collection.
+func sampleWithRepetition(members iter.Seq[string], size, count int) []string {
+	samples := make([]string, 0, count)
+	if size == 0 || count == 0 {
+		return samples
+	}
+
+	positions := make([]int, count)
+	for i := range positions {
+		positions[i] = rand.IntN(size) //nolint:gosec
+	}
+	slices.Sort(positions)
+
+	position, next := 0, 0
+	for member := range members {
+		for next < count && positions[next] == position {
+			samples = append(samples, member)
+			next++
+		}
+		if next == count {
+			break
+		}
+		position++
+	}
+
+	shuffle(samples)
+	return samples
+}
+
+// sampleMembers returns random members of a collection following the COUNT semantics of HRANDFIELD,
+// SRANDMEMBER and ZRANDMEMBER: a positive count returns distinct members, a negative count allows
+// the same member to be returned multiple times. When value is not nil every member is followed by
+// its value, as done with the WITHVALUES and WITHSCORES options.
+func sampleMembers(members iter.Seq[string], size, count int, value func(member string) string) []string {
+	var samples []string
+	if count >= 0 {
+		samples = sampleDistinct(members, size, count)
+	} else {
+		samples = sampleWithRepetition(members, size, -count)
+	}
+
+	if value == nil {
+		return samples
+	}
+
+	result := make([]string, 0, 2*len(samples))
+	for _, member := range samples {
+		result = append(result, member, value(member))
+	}
+	return result
+}
+
+// parseSampleCount parses the COUNT argument of the random member commands.
+func parseSampleCount(arg string) (int, error) {
+	count, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, diceerrors.ErrIntegerOutOfRange
+	}
+	if count < -maxSampleWithRepetition {
+		return 0, diceerrors.ErrValueOutOfRange
+	}
+	return count, nil
+}
+
+func shuffle(members []string) {
+	rand.Shuffle(len(members), func(i, j int) { //nolint:gosec
+		members[i], members[j] = members[j], members[i]
+	})
+}
@@ -0,0 +1,104 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"maps"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSampleCollection(size int) map[string]struct{} {
+	collection := make(map[string]struct{}, size)
+	for i := 0; i < size; i++ {
+		collection[strconv.Itoa(i)] = struct{}{}
+	}
+	return collection
+}
+
+func TestSampleDistinct(t *testing.T) {
+	collection := newSampleCollection(1000)
+
+	samples := sampleDistinct(maps.Keys(collection), len(collection), 10)
+	assert.Len(t, samples, 10)
+	// Only the sampled members are held in memory
+	assert.Equal(t, 10, cap(samples))
+
+	seen := make(map[string]struct{})
+	for _, member := range samples {
+		assert.Contains(t, collection, member)
+		seen[member] = struct{}{}
+	}
+	assert.Len(t, seen, 10)
+
+	assert.ElementsMatch(t, []string{"0", "1", "2"}, sampleDistinct(maps.Keys(newSampleCollection(3)), 3, 5))
+	assert.Empty(t, sampleDistinct(maps.Keys(collection), len(collection), 0))
+}
+
+func TestSampleDistinctIsUniform(t *testing.T) {
+	collection := newSampleCollection(10)
+
+	frequencies := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		for _, member := range sampleDistinct(maps.Keys(collection), len(collection), 3) {
+			frequencies[member]++
+		}
+	}
+
+	// Every member is expected 3000 times, the bounds are far outside of the statistical noise
+	assert.Len(t, frequencies, 10)
+	for member, frequency := range frequencies {
+		assert.InDelta(t, 3000, frequency, 500, "member %s", member)
+	}
+}
+
+func TestSampleWithRepetition(t *testing.T) {
+	collection := newSampleCollection(3)
+
+	samples := sampleWithRepetition(maps.Keys(collection), len(collection), 100)
+	assert.Len(t, samples, 100)
+	for _, member := range samples {
+		assert.Contains(t, collection, member)
+	}
+
+	assert.Empty(t, sampleWithRepetition(maps.Keys(map[string]struct{}{}), 0, 10))
+}
+
+func TestSampleMembersWithValues(t *testing.T) {
+	hashMap := HashMap{"f1": "v1", "f2": "v2"}
+	value := func(field string) string { return hashMap[field] }
+
+	result := sampleMembers(maps.Keys(hashMap), len(hashMap), -5, value)
+	assert.Len(t, result, 10)
+	for i := 0; i < len(result); i += 2 {
+		assert.Equal(t, hashMap[result[i]], result[i+1])
+	}
+}
+
+func TestParseSampleCount(t *testing.T) {
+	count, err := parseSampleCount("-3")
+	assert.NoError(t, err)
+	assert.Equal(t, -3, count)
+
+	_, err = parseSampleCount("three")
+	assert.Error(t, err)
+
+	_, err = parseSampleCount(strconv.Itoa(-maxSampleWithRepetition - 1))
+	assert.Error(t, err)
+}
@@ -19,6 +19,8 @@ package sortedset
 import (
 	"bytes"
 	"encoding/binary"
+	"iter"
+	"maps"
 	"strconv"
 	"strings"
 
@@ -165,8 +167,7 @@ func (ss *Set) GetRange(
 			result = append(result, ssi.Member)
 			if withScores {
 				// Use 'g' format to match Redis's float formatting
-				scoreStr := strings.ToLower(strconv.FormatFloat(ssi.Score, 'g', -1, 64))
-				result = append(result, scoreStr)
+				result = append(result, FormatScore(ssi.Score))
 			}
 		}
 		index++
@@ -182,6 +183,17 @@ func (ss *Set) GetRange(
 	return result
 }
 
+// Members returns an iterator over the members of the sorted set, in no particular order.
+func (ss *Set) Members() iter.Seq[string] {
+	return maps.Keys(ss.memberMap)
+}
+
+// FormatScore formats a score the way it is returned to the clients.
+func FormatScore(score float64) string {
+	// Use 'g' format to match Redis's float formatting
+	return strings.ToLower(strconv.FormatFloat(score, 'g', -1, 64))
+}
+
 // GetMin returns the first 'count' key-value pairs (member and score) with the minimum scores
 // and removes those items from the sorted set.
 func (ss *Set) GetMin(count int) []string {
@@ -277,20 +289,21 @@ func (ss *Set) Serialize(buf *bytes.Buffer) error {
 		return err
 	}
 
-	// Serialize each member and its score
-	for member, score := range ss.memberMap {
-		memberLen := uint64(len(member))
-		if err := binary.Write(buf, binary.BigEndian, memberLen); err != nil {
-			return err
-		}
-		if _, err := buf.WriteString(member); err != nil {
-			return err
+	// Serialize each member and its score, in the order of the tree so that a sorted set is always
+	// encoded the same
+	var err error
+	ss.tree.Ascend(func(i btree.Item) bool {
+		item := i.(*Item)
+		if err = binary.Write(buf, binary.BigEndian, uint64(len(item.Member))); err != nil {
+			return false
 		}
-		if err := binary.Write(buf, binary.BigEndian, score); err != nil {
-			return err
+		if _, err = buf.WriteString(item.Member); err != nil {
+			return false
 		}
-	}
-	return nil
+		err = binary.Write(buf, binary.BigEndian, item.Score)
+		return err == nil
+	})
+	return err
 }
 
 func DeserializeSortedSet(buf *bytes.Reader) (*Set, error) {
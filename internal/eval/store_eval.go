@@ -20,6 +20,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"math/bits"
 	"regexp"
@@ -31,13 +32,17 @@ import (
 
 	"github.com/axiomhq/hyperloglog"
 	"github.com/bytedance/sonic"
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/cmd"
 	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/eval/geo"
 	"github.com/dicedb/dice/internal/eval/sortedset"
+	"github.com/dicedb/dice/internal/latency"
 	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/dicedb/dice/internal/slowlog"
+	"github.com/dicedb/dice/internal/stats"
 	dstore "github.com/dicedb/dice/internal/store"
 	"github.com/gobwas/glob"
 	"github.com/ohler55/ojg/jp"
@@ -102,6 +107,31 @@ func evalEXPIRE(args []string, store *dstore.Store) *EvalResponse {
 	}
 }
 
+// evalPEXPIRE sets an expiry time(in milliseconds) on the specified key in args
+// args should contain 2 values, key and the expiry time to be set for the key
+// Returns clientio.IntegerOne if expiry was set on the key successfully, clientio.IntegerZero if the key
+// does not exist.
+func evalPEXPIRE(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 2 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("PEXPIRE"))
+	}
+
+	exDurationMs, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+	if exDurationMs < 0 || exDurationMs > maxExDuration {
+		return makeEvalError(diceerrors.ErrInvalidExpireTime("PEXPIRE"))
+	}
+
+	obj := store.Get(args[0])
+	if obj == nil {
+		return makeEvalResult(clientio.IntegerZero)
+	}
+	store.SetKeyExpiry(args[0], obj, exDurationMs)
+	return makeEvalResult(clientio.IntegerOne)
+}
+
 // evalEXPIREAT sets a expiry time(in unix-time-seconds) on the specified key in args
 // args should contain 2 values, key and the expiry time to be set for the key
 // The expiry time should be in integer format; if not, it returns encoded error response
@@ -210,114 +240,175 @@ func evalSET(args []string, store *dstore.Store) *EvalResponse {
 		return makeEvalError(diceerrors.ErrWrongArgumentCount("SET"))
 	}
 
-	var key, value string
-	var exDurationMs int64 = -1
-	var state exDurationState = Uninitialized
-	var keepttl bool = false
-	var oldVal *interface{}
+	opts, err := parseSetOptions(args[2:])
+	if err != nil {
+		return makeEvalError(err)
+	}
 
-	key, value = args[0], args[1]
-	storedValue, oType := getRawStringOrInt(value)
+	return setString(args[0], args[1], opts, store)
+}
 
-	if oType != object.ObjTypeInt && oType != object.ObjTypeString {
-		return makeEvalError(diceerrors.ErrUnsupportedEncoding(int(oType)))
-	}
+// setOptions are the options of the commands writing a string value along with its expiry.
+type setOptions struct {
+	exDurationMs int64 // -1 when the value does not expire
+	keepTTL      bool
+	nx           bool
+	xx           bool
+	get          bool
+	ifVersion    *uint64 // ifVersion is the version the key must be at, nil without IFVERSION
+}
 
-	for i := 2; i < len(args); i++ {
+// parseSetOptions parses the options of SET. Every option is validated before the store is looked at,
+// so that a syntax error is reported even when the NX or XX condition does not hold.
+func parseSetOptions(args []string) (*setOptions, error) {
+	opts := &setOptions{exDurationMs: -1}
+	var state exDurationState = Uninitialized
+
+	for i := 0; i < len(args); i++ {
 		arg := strings.ToUpper(args[i])
 		switch arg {
 		case Ex, Px:
 			if state != Uninitialized {
-				return makeEvalError(diceerrors.ErrSyntax)
+				return nil, diceerrors.ErrSyntax
 			}
-			if keepttl {
-				return makeEvalError(diceerrors.ErrSyntax)
+			if opts.keepTTL {
+				return nil, diceerrors.ErrSyntax
 			}
 			i++
 			if i == len(args) {
-				return makeEvalError(diceerrors.ErrSyntax)
+				return nil, diceerrors.ErrSyntax
 			}
 
 			exDuration, err := strconv.ParseInt(args[i], 10, 64)
 			if err != nil {
-				return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+				return nil, diceerrors.ErrIntegerOutOfRange
 			}
 
 			if exDuration <= 0 || exDuration >= maxExDuration {
-				return makeEvalError(diceerrors.ErrInvalidExpireTime("SET"))
+				return nil, diceerrors.ErrInvalidExpireTime("SET")
 			}
 
 			// converting seconds to milliseconds
 			if arg == Ex {
 				exDuration *= 1000
 			}
-			exDurationMs = exDuration
+			opts.exDurationMs = exDuration
 			state = Initialized
 
 		case Pxat, Exat:
 			if state != Uninitialized {
-				return makeEvalError(diceerrors.ErrSyntax)
+				return nil, diceerrors.ErrSyntax
 			}
-			if keepttl {
-				return makeEvalError(diceerrors.ErrSyntax)
+			if opts.keepTTL {
+				return nil, diceerrors.ErrSyntax
 			}
 			i++
 			if i == len(args) {
-				return makeEvalError(diceerrors.ErrSyntax)
+				return nil, diceerrors.ErrSyntax
 			}
 			exDuration, err := strconv.ParseInt(args[i], 10, 64)
 			if err != nil {
-				return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+				return nil, diceerrors.ErrIntegerOutOfRange
 			}
 
 			if exDuration < 0 {
-				return makeEvalError(diceerrors.ErrInvalidExpireTime("SET"))
+				return nil, diceerrors.ErrInvalidExpireTime("SET")
 			}
 
 			if arg == Exat {
 				exDuration *= 1000
 			}
-			exDurationMs = exDuration - utils.GetCurrentTime().UnixMilli()
-			// If the expiry time is in the past, set exDurationMs to 0
+			// If the expiry time is in the past, set the duration to 0
 			// This will be used to signal immediate expiration
-			if exDurationMs < 0 {
-				exDurationMs = 0
-			}
+			opts.exDurationMs = max(exDuration-utils.GetCurrentTime().UnixMilli(), 0)
 			state = Initialized
 
 		case XX:
-			// Get the key from the hash table
-			obj := store.Get(key)
-
-			// if key does not exist, return RESP encoded nil
-			if obj == nil {
-				return makeEvalResult(clientio.NIL)
+			if opts.nx {
+				return nil, diceerrors.ErrSyntax
 			}
+			opts.xx = true
 		case NX:
-			obj := store.Get(key)
-			if obj != nil {
-				return makeEvalResult(clientio.NIL)
+			if opts.xx {
+				return nil, diceerrors.ErrSyntax
 			}
+			opts.nx = true
 		case KeepTTL:
 			if state != Uninitialized {
-				return makeEvalError(diceerrors.ErrSyntax)
+				return nil, diceerrors.ErrSyntax
 			}
-			keepttl = true
+			opts.keepTTL = true
 		case GET:
-			getResult := evalGET([]string{key}, store)
-			if getResult.Error != nil {
-				return makeEvalError(diceerrors.ErrWrongTypeOperation)
+			opts.get = true
+		case IfVersion:
+			if opts.ifVersion != nil {
+				return nil, diceerrors.ErrSyntax
+			}
+			i++
+			if i == len(args) {
+				return nil, diceerrors.ErrSyntax
+			}
+			version, err := strconv.ParseUint(args[i], 10, 64)
+			if err != nil {
+				return nil, diceerrors.ErrIntegerOutOfRange
 			}
-			oldVal = &getResult.Result
+			opts.ifVersion = &version
 		default:
-			return makeEvalError(diceerrors.ErrSyntax)
+			return nil, diceerrors.ErrSyntax
+		}
+	}
+
+	return opts, nil
+}
+
+// setString writes the value of the key together with its expiry in a single store.Put: the expiry is
+// attached to the object before it is put, so the key is never visible without its expiry, neither to
+// the next commands of the shard nor to the key event subscribers notified by the put.
+// Returns NIL when the NX or XX condition does not hold, and the previous value with GET. A key that is not at
+// the version of IFVERSION is left untouched and a conflict error is returned.
+func setString(key, value string, opts *setOptions, store *dstore.Store) *EvalResponse {
+	if err := checkVersion(key, opts.ifVersion, store); err != nil {
+		return makeEvalError(err)
+	}
+
+	var oldVal interface{} = clientio.NIL
+	if opts.get {
+		getResult := evalGET([]string{key}, store)
+		if getResult.Error != nil {
+			return makeEvalError(diceerrors.ErrWrongTypeOperation)
+		}
+		oldVal = getResult.Result
+	}
+
+	if opts.nx || opts.xx {
+		exists := store.Get(key) != nil
+		if (opts.nx && exists) || (opts.xx && !exists) {
+			return makeEvalResult(oldVal)
 		}
 	}
 
-	// putting the k and value in a Hash Table
-	store.Put(key, store.NewObj(storedValue, exDurationMs, oType), dstore.WithKeepTTL(keepttl))
-	if oldVal != nil {
-		return makeEvalResult(*oldVal)
+	storedValue, oType := getRawStringOrInt(value)
+	store.Put(key, store.NewObj(storedValue, opts.exDurationMs, oType), dstore.WithKeepTTL(opts.keepTTL))
+	if opts.get {
+		return makeEvalResult(oldVal)
+	}
+	return makeEvalResult(clientio.OK)
+}
+
+// evalMSET sets the keys to their values, discarding their expiry, in a single batch of writes. The MSET
+// of a client is split by the io-thread into one MSET per shard owning some of its keys.
+//
+// Usage: MSET key value [key value ...]
+func evalMSET(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("MSET"))
+	}
+
+	batch := store.NewBatch()
+	defer batch.Commit()
+	for i := 0; i < len(args); i += 2 {
+		storedValue, oType := getRawStringOrInt(args[i+1])
+		batch.Put(args[i], store.NewObj(storedValue, -1, oType))
 	}
 	return makeEvalResult(clientio.OK)
 }
@@ -448,32 +539,226 @@ func evalGETSET(args []string, store *dstore.Store) *EvalResponse {
 // Returns encoded OK RESP once new entry is added
 // If the key already exists then the value and expiry will be overwritten
 func evalSETEX(args []string, store *dstore.Store) *EvalResponse {
+	return setStringWithExpiry("SETEX", args, 1000, store)
+}
+
+// evalPSETEX works exactly like evalSETEX, but the expiry of the key is given in milliseconds.
+func evalPSETEX(args []string, store *dstore.Store) *EvalResponse {
+	return setStringWithExpiry("PSETEX", args, 1, store)
+}
+
+// setStringWithExpiry implements SETEX and PSETEX, whose args are the key, the expiry in units of
+// unitMs milliseconds and the value.
+func setStringWithExpiry(command string, args []string, unitMs int64, store *dstore.Store) *EvalResponse {
 	if len(args) != 3 {
-		return &EvalResponse{
-			Result: nil,
-			Error:  diceerrors.ErrWrongArgumentCount("SETEX"),
-		}
+		return makeEvalError(diceerrors.ErrWrongArgumentCount(command))
 	}
 
-	var key, value string
-	key, value = args[0], args[2]
-
 	exDuration, err := strconv.ParseInt(args[1], 10, 64)
 	if err != nil {
-		return &EvalResponse{
-			Result: nil,
-			Error:  diceerrors.ErrIntegerOutOfRange,
-		}
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
 	}
 	if exDuration <= 0 || exDuration >= maxExDuration {
-		return &EvalResponse{
-			Result: nil,
-			Error:  diceerrors.ErrInvalidExpireTime("SETEX"),
-		}
+		return makeEvalError(diceerrors.ErrInvalidExpireTime(command))
+	}
+
+	return setString(args[0], args[2], &setOptions{exDurationMs: exDuration * unitMs}, store)
+}
+
+// evalSETNX sets the key to the value only if the key does not exist.
+// Returns 1 if the key was set, 0 if it already existed.
+func evalSETNX(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 2 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("SETNX"))
+	}
+
+	if setString(args[0], args[1], &setOptions{exDurationMs: -1, nx: true}, store).Result == clientio.OK {
+		return makeEvalResult(clientio.IntegerOne)
+	}
+	return makeEvalResult(clientio.IntegerZero)
+}
+
+// evalCacheLoad stores the value loaded from the origin of a key missing from the store, unless the key was
+// written in the meantime. The key is written with the CACHELOAD command, so that the write is not propagated
+// back to the origin.
+// Returns 1 if the key was set, 0 if it already existed.
+//
+// Usage: CACHELOAD key value milliseconds, an expiry of 0 keeps the key without expiry
+func evalCacheLoad(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 3 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("CACHELOAD"))
+	}
+
+	exDurationMs, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+	if exDurationMs < 0 || exDurationMs >= maxExDuration {
+		return makeEvalError(diceerrors.ErrInvalidExpireTime("CACHELOAD"))
+	}
+	if exDurationMs == 0 {
+		exDurationMs = -1
+	}
+
+	if store.Get(args[0]) != nil {
+		return makeEvalResult(clientio.IntegerZero)
+	}
+	storedValue, oType := getRawStringOrInt(args[1])
+	store.Put(args[0], store.NewObj(storedValue, exDurationMs, oType), dstore.WithPutCmd(dstore.CacheLoad))
+	return makeEvalResult(clientio.IntegerOne)
+}
+
+// getIfEqual returns the string object stored at key if its value is the given one, nil otherwise.
+// It is the compare step of the compare-and-delete and compare-and-expire commands used to release
+// and extend locks: the value holds the token of the owner of the lock.
+func getIfEqual(key, value string, store *dstore.Store) (*object.Obj, error) {
+	obj := store.Get(key)
+	if obj == nil {
+		return nil, nil
+	}
+
+	current, err := convertValueToString(obj, obj.Type)
+	if err != nil {
+		return nil, diceerrors.ErrWrongTypeOperation
+	}
+	if current != value {
+		return nil, nil
+	}
+	return obj, nil
+}
+
+// HoldsValue reports whether the response of a GET holds the value, the compare step of the MSETCAS keys
+// checked on another shard than the one executing the command. A missing key holds no value.
+func HoldsValue(resp *EvalResponse, value string) (bool, error) {
+	if resp.Error != nil {
+		return false, resp.Error
+	}
+	switch current := resp.Result.(type) {
+	case string:
+		return current == value, nil
+	case int64:
+		return strconv.FormatInt(current, 10) == value, nil
+	}
+	return false, nil
+}
+
+// evalCAS sets the key to the value only if it holds the expected one, discarding its expiry. Clients
+// read the key, compute its new value and write it back with CAS, retrying if another client wrote the
+// key in the meantime.
+// Returns 1 if the key was set, 0 if it does not exist or holds another value.
+//
+// Usage: CAS key expected value
+func evalCAS(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 3 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("CAS"))
+	}
+
+	obj, err := getIfEqual(args[0], args[1], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+	if obj == nil {
+		return makeEvalResult(clientio.IntegerZero)
+	}
+	if resp := setString(args[0], args[2], &setOptions{exDurationMs: -1}, store); resp.Error != nil {
+		return resp
+	}
+	return makeEvalResult(clientio.IntegerOne)
+}
+
+// evalDELIFEQ deletes the key only if it holds the given value.
+// Returns 1 if the key was deleted, 0 if it does not exist or holds another value.
+//
+// Usage: DELIFEQ key value
+func evalDELIFEQ(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 2 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("DELIFEQ"))
+	}
+
+	obj, err := getIfEqual(args[0], args[1], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+	if obj == nil || !store.Del(args[0]) {
+		return makeEvalResult(clientio.IntegerZero)
+	}
+	return makeEvalResult(clientio.IntegerOne)
+}
+
+// evalPEXPIREIFEQ sets the expiry of the key, in milliseconds, only if it holds the given value.
+// Returns 1 if the expiry was set, 0 if the key does not exist or holds another value.
+//
+// Usage: PEXPIREIFEQ key value milliseconds
+func evalPEXPIREIFEQ(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 3 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("PEXPIREIFEQ"))
+	}
+
+	exDurationMs, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+	if exDurationMs <= 0 || exDurationMs >= maxExDuration {
+		return makeEvalError(diceerrors.ErrInvalidExpireTime("PEXPIREIFEQ"))
 	}
-	newArgs := []string{key, value, Ex, args[1]}
 
-	return evalSET(newArgs, store)
+	obj, err := getIfEqual(args[0], args[1], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+	if obj == nil {
+		return makeEvalResult(clientio.IntegerZero)
+	}
+	store.SetKeyExpiry(args[0], obj, exDurationMs)
+	return makeEvalResult(clientio.IntegerOne)
+}
+
+// evalLOCK acquires the lock of the key for a lease, in milliseconds, unless the key exists. The key holds the
+// fencing token of the owner until the lock is released with UNLOCK or its lease expires.
+// Returns the fencing token if the lock was acquired, NIL if it is held.
+//
+// Usage: LOCK key milliseconds
+func evalLOCK(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 2 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("LOCK"))
+	}
+
+	leaseMs, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+	if leaseMs <= 0 || leaseMs >= maxExDuration {
+		return makeEvalError(diceerrors.ErrInvalidExpireTime("LOCK"))
+	}
+
+	if store.Get(args[0]) != nil {
+		return makeEvalResult(clientio.NIL)
+	}
+	token := store.NextFencingToken()
+	store.Put(args[0], store.NewObj(token, leaseMs, object.ObjTypeInt))
+	return makeEvalResult(token)
+}
+
+// evalUNLOCK releases the lock of the key only if it is held with the given fencing token.
+// Returns 1 if the lock was released, 0 if it is not held or held with another token.
+//
+// Usage: UNLOCK key token
+func evalUNLOCK(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 2 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("UNLOCK"))
+	}
+	if _, err := strconv.ParseInt(args[1], 10, 64); err != nil {
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+
+	obj, err := getIfEqual(args[0], args[1], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+	if obj == nil || !store.Del(args[0]) {
+		return makeEvalResult(clientio.IntegerZero)
+	}
+	return makeEvalResult(clientio.IntegerOne)
 }
 
 // evalHEXISTS returns if field is an existing field in the hash stored at key.
@@ -1077,6 +1362,8 @@ func evalZREM(args []string, store *dstore.Store) *EvalResponse {
 // evalAPPEND takes two arguments: the key and the value to append to the key's current value.
 // If the key does not exist, it creates a new key with the given value (so APPEND will be similar to SET in this special case)
 // If key already exists and is a string (or integers stored as strings), this command appends the value at the end of the string
+// The appended string is kept as a raw byte array with room reserved for further appends,
+// so that building a large string incrementally does not copy it on every call.
 func evalAPPEND(args []string, store *dstore.Store) *EvalResponse {
 	if len(args) != 2 {
 		return &EvalResponse{
@@ -1088,24 +1375,10 @@ func evalAPPEND(args []string, store *dstore.Store) *EvalResponse {
 	key, value := args[0], args[1]
 	obj := store.Get(key)
 
-	// Get the current expiry time
-	var exDurationMs int64 = -1 // -1 indicates no expiry time
-	expiryTStampMs, hasExpiry := dstore.GetExpiry(obj, store)
-
-	// Set the new expiry time
-	if hasExpiry {
-		// get the new expiry time in milliseconds
-		exDurationMs = int64(expiryTStampMs) - utils.GetCurrentTime().UnixMilli()
-		if exDurationMs < 0 {
-			// set expiry time to 0
-			exDurationMs = 0
-		}
-	}
-
 	// Key does not exist, create a new key
 	if obj == nil {
 		storedValue, oType := getRawStringOrInt(value)
-		store.Put(key, store.NewObj(storedValue, exDurationMs, oType))
+		store.Put(key, store.NewObj(storedValue, -1, oType))
 		return &EvalResponse{
 			Result: len(value),
 			Error:  nil,
@@ -1119,10 +1392,10 @@ func evalAPPEND(args []string, store *dstore.Store) *EvalResponse {
 			Error:  diceerrors.ErrWrongTypeOperation,
 		}
 	}
-	oType := obj.Type
 
-	// Transform the value based on the current encoding
-	currentValue, err := convertValueToString(obj, oType)
+	// Even if append is performed on integers, the result will be stored as a raw string
+	// This is consistent with the redis implementation as append is considered a string operation
+	byteArray, err := getRawByteArray(obj)
 	if err != nil {
 		// If the encoding is neither integer nor string, return a "wrong type" error
 		return &EvalResponse{
@@ -1131,19 +1404,66 @@ func evalAPPEND(args []string, store *dstore.Store) *EvalResponse {
 		}
 	}
 
-	// Append the value
-	newValue := currentValue + value
+	if int64(len(value))+byteArray.Length > maxStringSize {
+		return makeEvalError(diceerrors.ErrStringTooLong)
+	}
 
-	// We need to store the new appended value as a string
-	// Even if append is performed on integers, the result will be stored as a string
-	// This is consistent with the redis implementation as append is considered a string operation
-	store.Put(key, store.NewObj(newValue, exDurationMs, object.ObjTypeString))
+	newLength := byteArray.Append(value)
+	store.Put(key, store.NewObj(byteArray, -1, object.ObjTypeByteArray), dstore.WithKeepTTL(true))
 	return &EvalResponse{
-		Result: len(newValue),
+		Result: int(newLength),
 		Error:  nil,
 	}
 }
 
+// evalSETRANGE overwrites part of the string stored at key, starting at the specified offset,
+// for the entire length of value. The string is padded with zero bytes when the offset is past its end,
+// and a missing key is handled like an empty string.
+// Returns the length of the string after it was modified.
+func evalSETRANGE(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 3 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("SETRANGE"))
+	}
+
+	key, value := args[0], args[2]
+	offset, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+	if offset < 0 {
+		return makeEvalError(diceerrors.ErrOffsetOutOfRange)
+	}
+
+	obj := store.Get(key)
+	var byteArray *ByteArray
+	if obj == nil {
+		// An empty value does not create the key
+		if value == "" {
+			return makeEvalResult(int64(0))
+		}
+		byteArray = NewByteArray(0)
+	} else {
+		if _, ok := obj.Value.(*sortedset.Set); ok {
+			return makeEvalError(diceerrors.ErrWrongTypeOperation)
+		}
+		byteArray, err = getRawByteArray(obj)
+		if err != nil {
+			return makeEvalError(err)
+		}
+		if value == "" {
+			return makeEvalResult(byteArray.Length)
+		}
+	}
+
+	if offset+int64(len(value)) > maxStringSize {
+		return makeEvalError(diceerrors.ErrStringTooLong)
+	}
+
+	newLength := byteArray.SetRange(int(offset), value)
+	store.Put(key, store.NewObj(byteArray, -1, object.ObjTypeByteArray), dstore.WithKeepTTL(true))
+	return makeEvalResult(newLength)
+}
+
 // evalZRANK returns the rank of the member in the sorted set stored at key.
 // The rank (or index) is 0-based, which means that the member with the lowest score has rank 0.
 // If the 'WITHSCORE' option is specified, it returns both the rank and the score of the member.
@@ -2190,7 +2510,9 @@ func evalHINCRBY(args []string, store *dstore.Store) *EvalResponse {
 		}
 	}
 
-	obj = store.NewObj(hashmap, -1, object.ObjTypeHashMap)
+	if obj == nil {
+		obj = store.NewObj(hashmap, -1, object.ObjTypeHashMap)
+	}
 	store.Put(key, obj)
 
 	return &EvalResponse{
@@ -2208,18 +2530,18 @@ func evalHINCRBY(args []string, store *dstore.Store) *EvalResponse {
 //
 // Usage: HINCRBYFLOAT key field increment
 func evalHINCRBYFLOAT(args []string, store *dstore.Store) *EvalResponse {
-	if len(args) < 3 {
+	if len(args) != 3 {
 		return &EvalResponse{
 			Result: nil,
 			Error:  diceerrors.ErrWrongArgumentCount("HINCRBYFLOAT"),
 		}
 	}
 
-	increment, err := strconv.ParseFloat(strings.TrimSpace(args[2]), 64)
-	if err != nil {
+	increment, ok := parseIncrFloat(args[2])
+	if !ok {
 		return &EvalResponse{
 			Result: nil,
-			Error:  diceerrors.ErrInvalidNumberFormat,
+			Error:  diceerrors.ErrInvalidFloat,
 		}
 	}
 
@@ -2245,11 +2567,13 @@ func evalHINCRBYFLOAT(args []string, store *dstore.Store) *EvalResponse {
 	if err != nil {
 		return &EvalResponse{
 			Result: nil,
-			Error:  diceerrors.ErrGeneral(err.Error()),
+			Error:  err,
 		}
 	}
 
-	obj = store.NewObj(hashmap, -1, object.ObjTypeHashMap)
+	if obj == nil {
+		obj = store.NewObj(hashmap, -1, object.ObjTypeHashMap)
+	}
 	store.Put(key, obj)
 
 	return &EvalResponse{
@@ -2262,7 +2586,7 @@ func evalHINCRBYFLOAT(args []string, store *dstore.Store) *EvalResponse {
 // If only the key is provided, one random field is returned.
 // If count is provided, it returns that many unique random fields. A negative count allows repeated selections.
 // The "WITHVALUES" option returns both fields and values.
-// Returns nil if the key doesn't exist or the hash is empty.
+// Returns nil if the key doesn't exist and no count is provided, an empty array otherwise.
 // Errors: arity error, type error for non-hash, syntax error for "WITHVALUES", or count format error.
 func evalHRANDFIELD(args []string, store *dstore.Store) *EvalResponse {
 	if len(args) < 1 || len(args) > 3 {
@@ -2274,55 +2598,124 @@ func evalHRANDFIELD(args []string, store *dstore.Store) *EvalResponse {
 
 	key := args[0]
 	obj := store.Get(key)
-	if obj == nil {
-		return &EvalResponse{
-			Result: clientio.NIL,
-			Error:  nil,
+	if obj != nil {
+		if err := object.AssertType(obj.Type, object.ObjTypeHashMap); err != nil {
+			return &EvalResponse{
+				Result: nil,
+				Error:  diceerrors.ErrWrongTypeOperation,
+			}
 		}
 	}
 
-	if err := object.AssertType(obj.Type, object.ObjTypeHashMap); err != nil {
-		return &EvalResponse{
-			Result: nil,
-			Error:  diceerrors.ErrWrongTypeOperation,
+	var hashMap HashMap
+	if obj != nil {
+		hashMap = obj.Value.(HashMap)
+	}
+
+	if len(args) == 1 {
+		if len(hashMap) == 0 {
+			return makeEvalResult(clientio.NIL)
 		}
+		return makeEvalResult(sampleDistinct(maps.Keys(hashMap), len(hashMap), 1)[0])
 	}
 
-	hashMap := obj.Value.(HashMap)
-	if len(hashMap) == 0 {
-		return &EvalResponse{
-			Result: clientio.EmptyArray,
-			Error:  nil,
+	// The second argument is the count.
+	count, err := parseSampleCount(args[1])
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	// The third argument is the "WITHVALUES" option.
+	var value func(string) string
+	if len(args) == 3 {
+		if !strings.EqualFold(args[2], WithValues) {
+			return makeEvalError(diceerrors.ErrSyntax)
 		}
+		value = func(field string) string { return hashMap[field] }
 	}
 
-	count := 1
-	withValues := false
+	return makeEvalResult(sampleMembers(maps.Keys(hashMap), len(hashMap), count, value))
+}
 
-	if len(args) > 1 {
-		var err error
-		// The second argument is the count.
-		count, err = strconv.Atoi(args[1])
-		if err != nil {
-			return &EvalResponse{
-				Result: nil,
-				Error:  diceerrors.ErrIntegerOutOfRange,
-			}
+// evalSRANDMEMBER returns random members from the set stored at key.
+// If only the key is provided, one random member is returned, nil if the key doesn't exist.
+// With a positive count, up to count distinct members are returned. A negative count allows the same
+// member to be returned multiple times, exactly -count members are then returned.
+func evalSRANDMEMBER(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) < 1 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("SRANDMEMBER"))
+	}
+	if len(args) > 2 {
+		return makeEvalError(diceerrors.ErrSyntax)
+	}
+
+	obj := store.Get(args[0])
+	var set map[string]struct{}
+	if obj != nil {
+		if err := object.AssertType(obj.Type, object.ObjTypeSet); err != nil {
+			return makeEvalError(diceerrors.ErrWrongTypeOperation)
 		}
+		set = obj.Value.(map[string]struct{})
+	}
 
-		// The third argument is the "WITHVALUES" option.
-		if len(args) == 3 {
-			if !strings.EqualFold(args[2], WithValues) {
-				return &EvalResponse{
-					Result: nil,
-					Error:  diceerrors.ErrSyntax,
-				}
-			}
-			withValues = true
+	if len(args) == 1 {
+		if len(set) == 0 {
+			return makeEvalResult(clientio.NIL)
 		}
+		return makeEvalResult(sampleDistinct(maps.Keys(set), len(set), 1)[0])
 	}
 
-	return selectRandomFields(hashMap, count, withValues)
+	count, err := parseSampleCount(args[1])
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	return makeEvalResult(sampleMembers(maps.Keys(set), len(set), count, nil))
+}
+
+// evalZRANDMEMBER returns random members from the sorted set stored at key.
+// If only the key is provided, one random member is returned, nil if the key doesn't exist.
+// With a positive count, up to count distinct members are returned. A negative count allows the same
+// member to be returned multiple times. The WITHSCORES option returns every member followed by its score.
+func evalZRANDMEMBER(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) < 1 || len(args) > 3 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("ZRANDMEMBER"))
+	}
+
+	obj := store.Get(args[0])
+	sortedSet := sortedset.New()
+	if obj != nil {
+		var errMsg []byte
+		sortedSet, errMsg = sortedset.FromObject(obj)
+		if errMsg != nil {
+			return makeEvalError(diceerrors.ErrWrongTypeOperation)
+		}
+	}
+
+	if len(args) == 1 {
+		if sortedSet.Len() == 0 {
+			return makeEvalResult(clientio.NIL)
+		}
+		return makeEvalResult(sampleDistinct(sortedSet.Members(), sortedSet.Len(), 1)[0])
+	}
+
+	count, err := parseSampleCount(args[1])
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	var score func(string) string
+	if len(args) == 3 {
+		if !strings.EqualFold(args[2], WithScores) {
+			return makeEvalError(diceerrors.ErrSyntax)
+		}
+		score = func(member string) string {
+			value, _ := sortedSet.Get(member)
+			return sortedset.FormatScore(value)
+		}
+	}
+
+	return makeEvalResult(sampleMembers(sortedSet.Members(), sortedSet.Len(), count, score))
 }
 
 // evalINCR increments the value of the specified key in args by 1,
@@ -2410,6 +2803,13 @@ func evalDECRBY(args []string, store *dstore.Store) *EvalResponse {
 			Error:  diceerrors.ErrIntegerOutOfRange,
 		}
 	}
+	// The smallest integer has no opposite
+	if decrAmount == math.MinInt64 {
+		return &EvalResponse{
+			Result: nil,
+			Error:  diceerrors.ErrDecrOverflow,
+		}
+	}
 	return incrDecrCmd(args, -decrAmount, store)
 }
 
@@ -2426,7 +2826,7 @@ func incrDecrCmd(args []string, incr int64, store *dstore.Store) *EvalResponse {
 	}
 	// if the type is not KV : return wrong type error
 	// if the encoding or type is not int : return value is not an int error
-	if err := object.AssertTypeWithError(obj.Type, object.ObjTypeString); err == nil {
+	if obj.Type == object.ObjTypeString || obj.Type == object.ObjTypeByteArray {
 		return &EvalResponse{
 			Result: nil,
 			Error:  diceerrors.ErrIntegerOutOfRange,
@@ -2470,11 +2870,11 @@ func evalINCRBYFLOAT(args []string, store *dstore.Store) *EvalResponse {
 			Error:  diceerrors.ErrWrongArgumentCount("INCRBYFLOAT"),
 		}
 	}
-	incr, err := strconv.ParseFloat(strings.TrimSpace(args[1]), 64)
-	if err != nil {
+	incr, ok := parseIncrFloat(args[1])
+	if !ok {
 		return &EvalResponse{
 			Result: nil,
-			Error:  diceerrors.ErrGeneral("value is not a valid float"),
+			Error:  diceerrors.ErrInvalidFloat,
 		}
 	}
 	return incrByFloatCmd(args, incr, store)
@@ -2484,50 +2884,45 @@ func incrByFloatCmd(args []string, incr float64, store *dstore.Store) *EvalRespo
 	key := args[0]
 	obj := store.Get(key)
 
-	if obj == nil {
-		strValue := formatFloat(incr, false)
-		_, oType := getRawStringOrInt(strValue)
-		obj = store.NewObj(strValue, -1, oType)
-		store.Put(key, obj)
-		return &EvalResponse{
-			Result: strValue,
-			Error:  nil,
+	// A key that does not exist is incremented from 0
+	var value float64
+	if obj != nil {
+		errString := object.AssertType(obj.Type, object.ObjTypeString)
+		errInt := object.AssertType(obj.Type, object.ObjTypeInt)
+		if errString != nil && errInt != nil {
+			return &EvalResponse{
+				Result: nil,
+				Error:  diceerrors.ErrWrongTypeOperation,
+			}
 		}
-	}
 
-	errString := object.AssertType(obj.Type, object.ObjTypeString)
-	errInt := object.AssertType(obj.Type, object.ObjTypeInt)
-	if errString != nil && errInt != nil {
-		return &EvalResponse{
-			Result: nil,
-			Error:  diceerrors.ErrWrongTypeOperation,
+		var ok bool
+		if value, ok = floatValue(obj.Value); !ok {
+			return &EvalResponse{
+				Result: nil,
+				Error:  diceerrors.ErrInvalidFloat,
+			}
 		}
 	}
 
-	value, err := floatValue(obj.Value)
-	if err != nil {
-		return &EvalResponse{
-			Result: nil,
-			Error:  diceerrors.ErrGeneral("value is not a valid float"),
-		}
-	}
 	value += incr
-	if math.IsInf(value, 0) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
 		return &EvalResponse{
 			Result: nil,
-			Error:  diceerrors.ErrValueOutOfRange,
+			Error:  diceerrors.ErrIncrNaNOrInf,
 		}
 	}
-	strValue := formatFloat(value, true)
-
-	_, oType := getRawStringOrInt(strValue)
-
-	// Remove the trailing decimal for integer values
-	// to maintain consistency with redis
-	strValue = strings.TrimSuffix(strValue, ".0")
+	strValue := formatIncrFloat(value)
 
-	obj.Value = strValue
-	obj.Type = oType
+	// A whole result is stored as an integer, so that INCR can follow
+	rawValue, oType := getRawStringOrInt(strValue)
+	if obj == nil {
+		obj = store.NewObj(rawValue, -1, oType)
+		store.Put(key, obj)
+	} else {
+		obj.Value = rawValue
+		obj.Type = oType
+	}
 
 	return &EvalResponse{
 		Result: strValue,
@@ -2537,19 +2932,47 @@ func incrByFloatCmd(args []string, incr float64, store *dstore.Store) *EvalRespo
 
 // floatValue returns the float64 value for an interface which
 // contains either a string or an int.
-func floatValue(value interface{}) (float64, error) {
+func floatValue(value interface{}) (float64, bool) {
 	switch raw := value.(type) {
 	case string:
-		parsed, err := strconv.ParseFloat(raw, 64)
-		if err != nil {
-			return 0, err
-		}
-		return parsed, nil
+		return parseIncrFloat(raw)
 	case int64:
-		return float64(raw), nil
+		return float64(raw), true
 	}
 
-	return 0, fmt.Errorf(diceerrors.IntOrFloatErr)
+	return 0, false
+}
+
+// parseIncrFloat parses a float the way Redis does for INCRBYFLOAT and HINCRBYFLOAT: spaces around the
+// number, NaN and the values out of the range of a float64 are rejected, infinities are accepted.
+func parseIncrFloat(s string) (float64, bool) {
+	if s == "" || unicode.IsSpace(rune(s[0])) || unicode.IsSpace(rune(s[len(s)-1])) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, false
+	}
+	return f, true
+}
+
+// maxIncrFloatDecimals is the number of decimals of the results of INCRBYFLOAT and HINCRBYFLOAT, the
+// precision Redis formats them with
+const maxIncrFloatDecimals = 17
+
+// formatIncrFloat formats the result of INCRBYFLOAT and HINCRBYFLOAT the way Redis does: in fixed
+// notation, never with an exponent, rounded to 17 decimals and without trailing zeros. The shortest
+// decimals that read back as f are used, so that 0.1 is not formatted as 0.10000000000000001.
+func formatIncrFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if dot := strings.IndexByte(s, '.'); dot >= 0 && len(s)-dot-1 > maxIncrFloatDecimals {
+		s = strings.TrimRight(strconv.FormatFloat(f, 'f', maxIncrFloatDecimals, 64), "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
 }
 
 // ZPOPMIN Removes and returns the member with the lowest score from the sorted set at the specified key.
@@ -3079,7 +3502,7 @@ func evalJSONARRTRIM(args []string, store *dstore.Store) *EvalResponse {
 	results := expr.Get(jsonData)
 	if len(results) == 0 {
 		return &EvalResponse{
-			Result: clientio.RespEmptyArray,
+			Result: clientio.EmptyArray,
 			Error:  nil,
 		}
 	}
@@ -3140,10 +3563,11 @@ func evalLPUSH(args []string, store *dstore.Store) *EvalResponse {
 		}
 	}
 
-	store.Put(args[0], obj)
 	for i := 1; i < len(args); i++ {
 		obj.Value.(*Deque).LPush(args[i])
 	}
+	// Put once the values are pushed, so that the clients woken up by the write find them
+	store.Put(args[0], obj)
 
 	deq := obj.Value.(*Deque)
 
@@ -3178,10 +3602,10 @@ func evalRPUSH(args []string, store *dstore.Store) *EvalResponse {
 		}
 	}
 
-	store.Put(args[0], obj)
 	for i := 1; i < len(args); i++ {
 		obj.Value.(*Deque).RPush(args[i])
 	}
+	store.Put(args[0], obj)
 
 	deq := obj.Value.(*Deque)
 
@@ -3769,7 +4193,7 @@ func evalJSONARRINSERT(args []string, store *dstore.Store) *EvalResponse {
 	results := expr.Get(jsonData)
 	if len(results) == 0 {
 		return &EvalResponse{
-			Result: clientio.RespEmptyArray,
+			Result: clientio.EmptyArray,
 			Error:  nil,
 		}
 	}
@@ -3923,7 +4347,7 @@ func evalJSONOBJKEYS(args []string, store *dstore.Store) *EvalResponse {
 	results := expr.Get(jsonData)
 	if len(results) == 0 {
 		return &EvalResponse{
-			Result: clientio.RespEmptyArray,
+			Result: clientio.EmptyArray,
 			Error:  nil,
 		}
 	}
@@ -4387,7 +4811,7 @@ func evalGETEX(args []string, store *dstore.Store) *EvalResponse {
 		if persist {
 			dstore.DelExpiry(obj, store)
 		} else {
-			store.SetExpiry(obj, exDurationMs)
+			store.SetKeyExpiry(key, obj, exDurationMs)
 		}
 	}
 
@@ -4523,7 +4947,14 @@ func insertInHashMap(args []string, store *dstore.Store) (int64, error) {
 		return 0, err
 	}
 
-	obj = store.NewObj(hashMap, -1, object.ObjTypeHashMap)
+	if obj == nil {
+		obj = store.NewObj(hashMap, -1, object.ObjTypeHashMap)
+	} else {
+		// Overwritten fields lose their expiry
+		for i := 0; i < len(keyValuePairs); i += 2 {
+			store.DelFieldExpiry(obj, keyValuePairs[i])
+		}
+	}
 	store.Put(key, obj)
 
 	return numKeys, nil
@@ -4537,7 +4968,11 @@ func insertInHashMap(args []string, store *dstore.Store) (int64, error) {
 //
 // If key doesn't exist, a new key holding a hash is created.
 //
-// Usage: HSET key field value [field value ...]
+// With IFVERSION, the fields are only set if the key is at the version. A last
+// field-value pair with the field IFVERSION is the option, unless it is the
+// only pair.
+//
+// Usage: HSET key field value [field value ...] [IFVERSION version]
 func evalHSET(args []string, store *dstore.Store) *EvalResponse {
 	if len(args) < 3 {
 		return &EvalResponse{
@@ -4546,6 +4981,14 @@ func evalHSET(args []string, store *dstore.Store) *EvalResponse {
 		}
 	}
 
+	args, version, err := parseIfVersion(args, 3)
+	if err != nil {
+		return makeEvalError(err)
+	}
+	if err := checkVersion(args[0], version, store); err != nil {
+		return makeEvalError(err)
+	}
+
 	numKeys, err := insertInHashMap(args, store)
 	if err != nil {
 		return &EvalResponse{
@@ -4774,6 +5217,7 @@ func evalHDEL(args []string, store *dstore.Store) *EvalResponse {
 	for _, field := range fields {
 		if _, ok := hashMap[field]; ok {
 			delete(hashMap, field)
+			store.DelFieldExpiry(obj, field)
 			count++
 		}
 	}
@@ -4788,6 +5232,276 @@ func evalHDEL(args []string, store *dstore.Store) *EvalResponse {
 	}
 }
 
+// evalHEXPIRE sets the expiry, in seconds, of the given fields of the hash stored at key.
+// Returns for every field -2 if it does not exist, 0 if the NX, XX, GT or LT condition does not hold,
+// 1 if its expiry was set and 2 if it was deleted as the expiry is 0.
+//
+// Usage: HEXPIRE key seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+func evalHEXPIRE(args []string, store *dstore.Store) *EvalResponse {
+	return expireHashFields("HEXPIRE", args, 1000, store)
+}
+
+// evalHPEXPIRE works exactly like evalHEXPIRE with the expiry given in milliseconds.
+//
+// Usage: HPEXPIRE key milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+func evalHPEXPIRE(args []string, store *dstore.Store) *EvalResponse {
+	return expireHashFields("HPEXPIRE", args, 1, store)
+}
+
+// expireHashFields sets the expiry of the fields of a hash, the expiry being given in unitMs milliseconds.
+func expireHashFields(command string, args []string, unitMs int64, store *dstore.Store) *EvalResponse {
+	if len(args) < 5 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount(command))
+	}
+
+	exDuration, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+	if exDuration < 0 || exDuration >= maxExDuration {
+		return makeEvalError(diceerrors.ErrInvalidExpireTime(command))
+	}
+
+	condition := ""
+	fieldArgs := args[2:]
+	switch strings.ToUpper(args[2]) {
+	case NX, XX, GT, LT:
+		condition = strings.ToUpper(args[2])
+		fieldArgs = args[3:]
+	}
+	fields, err := parseHashFields(fieldArgs)
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	results := make([]int64, len(fields))
+	obj, hashMap, err := getHashMap(args[0], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	expiresAtMs := uint64(now + exDuration*unitMs)
+	var expired []string
+	for i, field := range fields {
+		if _, ok := hashMap[field]; !ok {
+			results[i] = fieldNotFound
+			continue
+		}
+
+		current, hasExpiry := store.GetFieldExpiry(obj, field)
+		// A field without expiry is considered to live forever by GT and LT
+		if (condition == NX && hasExpiry) || (condition == XX && !hasExpiry) ||
+			(condition == GT && (!hasExpiry || expiresAtMs <= current)) ||
+			(condition == LT && hasExpiry && expiresAtMs >= current) {
+			results[i] = fieldConditionUnmet
+			continue
+		}
+
+		if exDuration == 0 {
+			expired = append(expired, field)
+			results[i] = fieldDeleted
+			continue
+		}
+		store.SetFieldExpiry(obj, field, expiresAtMs)
+		results[i] = fieldUpdated
+	}
+
+	if len(expired) > 0 {
+		deleteHashFields(args[0], obj, hashMap, expired, store)
+	}
+	return makeEvalResult(results)
+}
+
+// evalHPERSIST removes the expiry of the given fields of the hash stored at key.
+// Returns for every field -2 if it does not exist, -1 if it has no expiry and 1 if its expiry was removed.
+//
+// Usage: HPERSIST key FIELDS numfields field [field ...]
+func evalHPERSIST(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) < 4 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("HPERSIST"))
+	}
+
+	fields, err := parseHashFields(args[1:])
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	results := make([]int64, len(fields))
+	obj, hashMap, err := getHashMap(args[0], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	for i, field := range fields {
+		switch _, ok := hashMap[field]; {
+		case !ok:
+			results[i] = fieldNotFound
+		case store.DelFieldExpiry(obj, field):
+			results[i] = fieldUpdated
+		default:
+			results[i] = fieldNoExpiry
+		}
+	}
+	return makeEvalResult(results)
+}
+
+// evalHTTL returns the remaining time to live, in seconds, of the given fields of the hash stored at key.
+// Returns for every field -2 if it does not exist and -1 if it has no expiry.
+//
+// Usage: HTTL key FIELDS numfields field [field ...]
+func evalHTTL(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) < 4 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("HTTL"))
+	}
+
+	fields, err := parseHashFields(args[1:])
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	results := make([]int64, len(fields))
+	obj, hashMap, err := getHashMap(args[0], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	for i, field := range fields {
+		if _, ok := hashMap[field]; !ok {
+			results[i] = fieldNotFound
+			continue
+		}
+		exp, ok := store.GetFieldExpiry(obj, field)
+		if !ok {
+			results[i] = fieldNoExpiry
+			continue
+		}
+		// The time to live is rounded up, so that a field is never reported with a TTL of 0 while it exists
+		results[i] = (int64(exp) - now + 999) / 1000
+	}
+	return makeEvalResult(results)
+}
+
+// evalHGETEX returns the values of the given fields of the hash stored at key and sets or removes their expiry.
+// Returns nil for the fields that do not exist. The fields whose new expiry is already elapsed are deleted.
+//
+// Usage: HGETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]
+// FIELDS numfields field [field ...]
+func evalHGETEX(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) < 4 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("HGETEX"))
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	expiresAtMs := int64(-1)
+	persist := false
+	fieldArgs := args[1:]
+	if option := strings.ToUpper(args[1]); option != Fields {
+		switch option {
+		case Ex, Px, Exat, Pxat:
+			if len(args) < 6 {
+				return makeEvalError(diceerrors.ErrSyntax)
+			}
+			exValue, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+			}
+			// Relative expiries must be positive, absolute ones may be in the past and delete the fields
+			if exValue < 0 || exValue >= maxExDuration || (exValue == 0 && (option == Ex || option == Px)) {
+				return makeEvalError(diceerrors.ErrInvalidExpireTime("HGETEX"))
+			}
+			if option == Ex || option == Exat {
+				exValue *= 1000
+			}
+			if option == Ex || option == Px {
+				exValue += now
+			}
+			expiresAtMs = exValue
+			fieldArgs = args[3:]
+		case Persist:
+			persist = true
+			fieldArgs = args[2:]
+		default:
+			return makeEvalError(diceerrors.ErrSyntax)
+		}
+	}
+
+	fields, err := parseHashFields(fieldArgs)
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	results := make([]interface{}, len(fields))
+	obj, hashMap, err := getHashMap(args[0], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	var expired []string
+	for i, field := range fields {
+		value, ok := hashMap[field]
+		if !ok {
+			results[i] = nil
+			continue
+		}
+		results[i] = value
+
+		switch {
+		case persist:
+			store.DelFieldExpiry(obj, field)
+		case expiresAtMs > now:
+			store.SetFieldExpiry(obj, field, uint64(expiresAtMs))
+		case expiresAtMs >= 0:
+			expired = append(expired, field)
+		}
+	}
+
+	if len(expired) > 0 {
+		deleteHashFields(args[0], obj, hashMap, expired, store)
+	}
+	return makeEvalResult(results)
+}
+
+// evalHGETDEL returns the values of the given fields of the hash stored at key and deletes them.
+// Returns nil for the fields that do not exist. The key is deleted once its hash is empty.
+//
+// Usage: HGETDEL key FIELDS numfields field [field ...]
+func evalHGETDEL(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) < 4 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("HGETDEL"))
+	}
+
+	fields, err := parseHashFields(args[1:])
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	results := make([]interface{}, len(fields))
+	obj, hashMap, err := getHashMap(args[0], store)
+	if err != nil {
+		return makeEvalError(err)
+	}
+
+	var deleted []string
+	for i, field := range fields {
+		value, ok := hashMap[field]
+		if !ok {
+			results[i] = nil
+			continue
+		}
+		results[i] = value
+		// A field given twice is only returned once
+		delete(hashMap, field)
+		deleted = append(deleted, field)
+	}
+
+	if len(deleted) > 0 {
+		deleteHashFields(args[0], obj, hashMap, deleted, store)
+	}
+	return makeEvalResult(results)
+}
+
 // evalSADD adds one or more members to a set
 // args must contain a key and one or more members to add the set
 // If the set does not exist, a new set is created and members are added to it
@@ -4973,6 +5687,48 @@ func evalSMEMBERS(args []string, store *dstore.Store) *EvalResponse {
 	}
 }
 
+// evalSMISMEMBER returns, for each member, 1 if it is a member of the set stored at key and 0 otherwise
+// An error response is returned if the command is used on a key that contains a non-set value(eg: string)
+// Every member is reported as 0 if no set exists for given key
+func evalSMISMEMBER(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) < 2 {
+		return &EvalResponse{
+			Result: nil,
+			Error:  diceerrors.ErrWrongArgumentCount("SMISMEMBER"),
+		}
+	}
+	key := args[0]
+	members := args[1:]
+
+	result := make([]int64, len(members))
+	obj := store.Get(key)
+	if obj == nil {
+		return &EvalResponse{
+			Result: result,
+			Error:  nil,
+		}
+	}
+
+	if err := object.AssertType(obj.Type, object.ObjTypeSet); err != nil {
+		return &EvalResponse{
+			Result: nil,
+			Error:  diceerrors.ErrWrongTypeOperation,
+		}
+	}
+
+	set := obj.Value.(map[string]struct{})
+	for i, member := range members {
+		if _, ok := set[member]; ok {
+			result[i] = 1
+		}
+	}
+
+	return &EvalResponse{
+		Result: result,
+		Error:  nil,
+	}
+}
+
 // evalLRANGE returns the specified elements of the list stored at key.
 //
 // Returns Array reply: a list of elements in the specified range, or an empty array if the key doesn't exist.
@@ -5579,7 +6335,7 @@ func evalJSONSTRAPPEND(args []string, store *dstore.Store) *EvalResponse {
 				resultsArray = append([]interface{}{len(newValue)}, resultsArray...)
 				return newValue, true
 			default:
-				resultsArray = append([]interface{}{clientio.RespNIL}, resultsArray...)
+				resultsArray = append([]interface{}{clientio.NIL}, resultsArray...)
 				return data, false
 			}
 		})
@@ -5926,13 +6682,11 @@ func evalCOPYObject(cd *cmd.DiceDBCmd, store *dstore.Store) *EvalResponse {
 		}
 	}
 
-	exDurationMs := cd.InternalObj.ExDuration
-
-	store.Put(key, copyObj)
-
-	if exDurationMs > 0 {
+	// The expiry is set before the object is put, so that the key is never visible without it
+	if exDurationMs := cd.InternalObj.ExDuration; exDurationMs > 0 {
 		store.SetExpiry(copyObj, exDurationMs)
 	}
+	store.Put(key, copyObj)
 
 	return &EvalResponse{
 		Result: clientio.IntegerOne,
@@ -6359,6 +7113,9 @@ func evalKEYS(args []string, store *dstore.Store) *EvalResponse {
 
 	pattern := args[0]
 	keys, err := store.Keys(pattern)
+	if store.Context().Err() != nil {
+		return makeEvalError(diceerrors.ErrCommandTimeout)
+	}
 	if err != nil {
 		return makeEvalError(diceerrors.ErrGeneral("bad pattern"))
 	}
@@ -6371,13 +7128,51 @@ func evalCLIENT(args []string, store *dstore.Store) *EvalResponse {
 	return makeEvalResult(clientio.OK)
 }
 
-// TODO: Placeholder to support monitoring
+// evalLATENCY evaluates the LATENCY subcommands on the process wide latency monitor
+// LATEST: returns the event name, the time of the latest spike, its latency and the highest latency of every event
+// HISTORY: returns the time and latency of the spikes of an event, oldest first
+// RESET: removes the spikes of the given events, or of every event, and returns the number of events reset
 func evalLATENCY(args []string, store *dstore.Store) *EvalResponse {
-	return makeEvalResult([]string{})
+	if len(args) == 0 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("LATENCY"))
+	}
+
+	subcommand := strings.ToUpper(args[0])
+	switch subcommand {
+	case Latest:
+		if len(args) != 1 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("LATENCY|LATEST"))
+		}
+		reply := []interface{}{}
+		for _, l := range latency.GetLatest() {
+			reply = append(reply, []interface{}{string(l.Event), l.Sample.Time.Unix(), l.Sample.Latency.Milliseconds(), l.Max.Milliseconds()})
+		}
+		return makeEvalResult(reply)
+	case History:
+		if len(args) != 2 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("LATENCY|HISTORY"))
+		}
+		reply := []interface{}{}
+		for _, sample := range latency.GetHistory(latency.Event(strings.ToLower(args[1]))) {
+			reply = append(reply, []interface{}{sample.Time.Unix(), sample.Latency.Milliseconds()})
+		}
+		return makeEvalResult(reply)
+	case Reset:
+		events := make([]latency.Event, 0, len(args)-1)
+		for _, event := range args[1:] {
+			events = append(events, latency.Event(strings.ToLower(event)))
+		}
+		return makeEvalResult(latency.Reset(events...))
+	default:
+		return makeEvalError(diceerrors.ErrGeneral(fmt.Sprintf("unknown subcommand '%s'. Try LATENCY HELP.", subcommand)))
+	}
 }
 
 // evalDEL deletes all the specified keys in args list
 // returns the count of total deleted keys
+// With IFVERSION, the single key given is only deleted if it is at the version.
+//
+// Usage: DEL key [key ...] [IFVERSION version]
 func evalDEL(args []string, store *dstore.Store) *EvalResponse {
 	if len(args) < 1 {
 		return &EvalResponse{
@@ -6386,9 +7181,25 @@ func evalDEL(args []string, store *dstore.Store) *EvalResponse {
 		}
 	}
 
+	args, version, err := parseIfVersion(args, 1)
+	if err != nil {
+		return makeEvalError(err)
+	}
+	if version != nil {
+		if len(args) != 1 {
+			return makeEvalError(diceerrors.ErrGeneral("IFVERSION can only be used with a single key"))
+		}
+		if err := checkVersion(args[0], version, store); err != nil {
+			return makeEvalError(err)
+		}
+	}
+
+	// The keys are moved to the trash when it is enabled, so that UNDELETE restores them
+	batch := store.NewBatch()
+	defer batch.Commit()
 	var count int64
 	for _, key := range args {
-		if ok := store.Del(key); ok {
+		if ok := batch.Trash(key); ok {
 			count++
 		}
 	}
@@ -6399,6 +7210,68 @@ func evalDEL(args []string, store *dstore.Store) *EvalResponse {
 	}
 }
 
+// evalUNDELETE restores the key deleted by DEL from the trash of the shard, with the expiry it had.
+// Returns 1 if the key was restored, 0 if it exists, is not in the trash or expired since it was deleted.
+//
+// Usage: UNDELETE key
+func evalUNDELETE(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 1 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("UNDELETE"))
+	}
+
+	if !store.Undelete(args[0]) {
+		return makeEvalResult(clientio.IntegerZero)
+	}
+	return makeEvalResult(clientio.IntegerOne)
+}
+
+// evalGETAT returns the value the key held at the time, in unix milliseconds, from its history kept when
+// history.enabled is set. Returns NIL if the key did not exist then or held another type than a string.
+//
+// Usage: GETAT key unix-time-milliseconds
+func evalGETAT(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 2 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("GETAT"))
+	}
+
+	at, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return makeEvalError(diceerrors.ErrIntegerOutOfRange)
+	}
+	value, err := store.GetAt(args[0], at)
+	if err != nil {
+		return makeEvalError(err)
+	}
+	if value == nil {
+		return makeEvalResult(clientio.NIL)
+	}
+	return makeEvalResult(value)
+}
+
+// evalHISTORY returns the values the key held within history.retention, oldest first, as pairs of the time
+// of the change in unix milliseconds and the value, NIL once the key was deleted or held another type.
+//
+// Usage: HISTORY key
+func evalHISTORY(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) != 1 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("HISTORY"))
+	}
+
+	revisions, err := store.History(args[0])
+	if err != nil {
+		return makeEvalError(err)
+	}
+	result := make([]interface{}, 0, len(revisions))
+	for _, r := range revisions {
+		var value interface{} = clientio.NIL
+		if r.Value != nil {
+			value = r.Value
+		}
+		result = append(result, []interface{}{r.ChangedAt, value})
+	}
+	return makeEvalResult(result)
+}
+
 // evalEXISTS returns the number of keys existing in the db
 // returns the count of total existing keys
 func evalEXISTS(args []string, store *dstore.Store) *EvalResponse {
@@ -6658,22 +7531,34 @@ func evalTYPE(args []string, store *dstore.Store) *EvalResponse {
 // }
 
 func evalFLUSHDB(args []string, store *dstore.Store) *EvalResponse {
+	return evalFlush("FLUSHDB", args, store)
+}
+
+// evalFLUSHALL is the same as FLUSHDB, as there is a single database
+func evalFLUSHALL(args []string, store *dstore.Store) *EvalResponse {
+	return evalFlush("FLUSHALL", args, store)
+}
+
+// evalFlush removes every key of the store. With ASYNC the keys are released in the background,
+// without an option memory.lazyfree_lazy_user_flush decides.
+func evalFlush(command string, args []string, store *dstore.Store) *EvalResponse {
 	if len(args) > 1 {
-		return makeEvalError(diceerrors.ErrWrongArgumentCount("FLUSHDB"))
+		return makeEvalError(diceerrors.ErrWrongArgumentCount(command))
 	}
 
-	flushType := Sync
+	async := config.DiceConfig().Memory.LazyFreeLazyUserFlush
 	if len(args) == 1 {
-		flushType = strings.ToUpper(args[0])
-	}
-
-	switch flushType {
-	case Sync, Async:
-		store.ResetStore()
-	default:
-		return makeEvalError(diceerrors.ErrSyntax)
+		switch strings.ToUpper(args[0]) {
+		case Sync:
+			async = false
+		case Async:
+			async = true
+		default:
+			return makeEvalError(diceerrors.ErrSyntax)
+		}
 	}
 
+	store.Flush(async)
 	return makeEvalResult(clientio.OK)
 }
 
@@ -6686,6 +7571,38 @@ func evalObjectIdleTime(key string, store *dstore.Store) *EvalResponse {
 	return makeEvalResult(int64(dstore.GetIdleTime(obj.LastAccessedAt)))
 }
 
+// evalObjectEncoding returns the internal representation of the value stored at key.
+// Strings are reported as int, embstr or raw, a string becomes raw once APPEND or SETRANGE modified it.
+func evalObjectEncoding(key string, store *dstore.Store) *EvalResponse {
+	obj := store.GetNoTouch(key)
+	if obj == nil {
+		return makeEvalResult(clientio.NIL)
+	}
+
+	switch obj.Type {
+	case object.ObjTypeString, object.ObjTypeInt, object.ObjTypeByteArray:
+		return makeEvalResult(getStringEncoding(obj))
+	case object.ObjTypeDequeue:
+		return makeEvalResult("quicklist")
+	case object.ObjTypeSet, object.ObjTypeHashMap:
+		return makeEvalResult("hashtable")
+	case object.ObjTypeSortedSet:
+		return makeEvalResult("skiplist")
+	default:
+		return makeEvalResult("raw")
+	}
+}
+
+// evalObjectVersion returns the version of the key, which changes with every write of the key.
+func evalObjectVersion(key string, store *dstore.Store) *EvalResponse {
+	version := store.Version(key)
+	if version == 0 {
+		return makeEvalResult(clientio.NIL)
+	}
+
+	return makeEvalResult(int64(version))
+}
+
 func evalOBJECT(args []string, store *dstore.Store) *EvalResponse {
 	if len(args) < 2 {
 		return makeEvalError(diceerrors.ErrWrongArgumentCount("OBJECT"))
@@ -6697,11 +7614,128 @@ func evalOBJECT(args []string, store *dstore.Store) *EvalResponse {
 	switch subcommand {
 	case "IDLETIME":
 		return evalObjectIdleTime(key, store)
+	case "ENCODING":
+		return evalObjectEncoding(key, store)
+	case "VERSION":
+		return evalObjectVersion(key, store)
 	default:
 		return makeEvalError(diceerrors.ErrSyntax)
 	}
 }
 
+// evalCONFIG evaluates CONFIG <subcommand> command based on subcommand
+// GET: returns the name and value of the parameters matching the given glob patterns.
+// SET: changes hot-reloadable parameters on the running server.
+// REWRITE: persists the running configuration to the config file the server was started with.
+func evalCONFIG(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) == 0 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("CONFIG"))
+	}
+
+	subcommand := strings.ToUpper(args[0])
+	switch subcommand {
+	case GET:
+		if len(args) < 2 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("CONFIG|GET"))
+		}
+		return makeEvalResult(config.GetParameters(args[1:]))
+	case SET:
+		if len(args) < 3 || len(args[1:])%2 != 0 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("CONFIG|SET"))
+		}
+		if err := config.SetParameters(args[1:]); err != nil {
+			return makeEvalError(diceerrors.ErrConfigSetFailed(err))
+		}
+		return makeEvalResult(clientio.OK)
+	case Rewrite:
+		if len(args) != 1 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("CONFIG|REWRITE"))
+		}
+		if err := config.RewriteConfigFile(); err != nil {
+			return makeEvalError(diceerrors.ErrConfigRewriteFailed(err))
+		}
+		return makeEvalResult(clientio.OK)
+	case ResetStat:
+		if len(args) != 1 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("CONFIG|RESETSTAT"))
+		}
+		stats.Reset()
+		return makeEvalResult(clientio.OK)
+	default:
+		return makeEvalError(diceerrors.ErrGeneral(fmt.Sprintf("unknown subcommand '%s'. Try CONFIG HELP.", subcommand)))
+	}
+}
+
+// evalINFO returns information and statistics about the server for the requested sections,
+// with the keyspace of the shard the command is executed on.
+// The RESP io-threads merge the keyspace of every shard through SINGLEINFO instead.
+func evalINFO(args []string, store *dstore.Store) *EvalResponse {
+	return makeEvalResult(FormatInfo(args, []ShardInfo{newShardInfo(args, store)}))
+}
+
+// evalSingleInfo returns the ShardInfo of the shard, which the io-thread merges into the INFO reply.
+func evalSingleInfo(args []string, store *dstore.Store) *EvalResponse {
+	return makeEvalResult(newShardInfo(args, store))
+}
+
+// defaultSlowlogCount is the number of entries returned by SLOWLOG GET without a count
+const defaultSlowlogCount = 10
+
+// SlowlogEntries is the reply of a shard to SLOWLOG GET, the io-thread merges
+// the entries of every shard and keeps the Count most recent ones.
+type SlowlogEntries struct {
+	Count   int
+	Entries []slowlog.Entry
+}
+
+// evalSLOWLOG evaluates SLOWLOG GET, LEN and RESET on the slow log of the shard the command is executed on.
+// The RESP io-threads combine the slow logs of every shard through SINGLESLOWLOG instead.
+func evalSLOWLOG(args []string, store *dstore.Store) *EvalResponse {
+	resp := evalSingleSlowlog(args, store)
+	if entries, ok := resp.Result.(SlowlogEntries); ok {
+		return makeEvalResult(slowlog.Reply(entries.Entries))
+	}
+	return resp
+}
+
+// evalSingleSlowlog evaluates SLOWLOG on the slow log of the shard. SLOWLOG GET returns
+// SlowlogEntries rather than the encoded reply so that the entries of every shard can be merged.
+func evalSingleSlowlog(args []string, store *dstore.Store) *EvalResponse {
+	if len(args) == 0 {
+		return makeEvalError(diceerrors.ErrWrongArgumentCount("SLOWLOG"))
+	}
+
+	subcommand := strings.ToUpper(args[0])
+	switch subcommand {
+	case GET:
+		if len(args) > 2 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("SLOWLOG|GET"))
+		}
+		count := defaultSlowlogCount
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < -1 {
+				return makeEvalError(diceerrors.ErrGeneral("count should be greater than or equal to -1"))
+			}
+			count = n
+		}
+		return makeEvalResult(SlowlogEntries{Count: count, Entries: store.SlowLog().Get(count)})
+	case Len:
+		if len(args) != 1 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("SLOWLOG|LEN"))
+		}
+		return makeEvalResult(store.SlowLog().Len())
+	case Reset:
+		if len(args) != 1 {
+			return makeEvalError(diceerrors.ErrWrongArgumentCount("SLOWLOG|RESET"))
+		}
+		store.SlowLog().Reset()
+		return makeEvalResult(clientio.OK)
+	default:
+		return makeEvalError(diceerrors.ErrGeneral(fmt.Sprintf("unknown subcommand '%s'. Try SLOWLOG HELP.", subcommand)))
+	}
+}
+
 // evalCommand evaluates COMMAND <subcommand> command based on subcommand
 // COUNT: return total count of commands in Dice.
 func evalCommand(args []string, store *dstore.Store) *EvalResponse {
@@ -6750,8 +7784,8 @@ func evalCommandGetKeys(args []string) *EvalResponse {
 		return makeEvalError(diceerrors.ErrGeneral("invalid command specified"))
 	}
 
-	keySpecs := diceCmd.KeySpecs
-	if keySpecs.BeginIndex == 0 {
+	keySpec, ok := cmd.LookupKeySpec(diceCmd.Name)
+	if !ok {
 		return makeEvalError(diceerrors.ErrGeneral("the command has no key arguments"))
 	}
 
@@ -6760,17 +7794,8 @@ func evalCommandGetKeys(args []string) *EvalResponse {
 		(arity >= 0 && len(args) != arity) {
 		return makeEvalError(diceerrors.ErrGeneral("invalid number of arguments specified for command"))
 	}
-	keys := make([]string, 0)
-	step := max(keySpecs.Step, 1)
-	lastIdx := keySpecs.BeginIndex
-	if keySpecs.LastKey != 0 {
-		lastIdx = len(args) + keySpecs.LastKey
-	}
-	for i := keySpecs.BeginIndex; i <= lastIdx; i += step {
-		keys = append(keys, args[i])
-	}
 
-	return makeEvalResult(keys)
+	return makeEvalResult(keySpec.Keys(args[1:]))
 }
 
 func evalCommandList(args []string) *EvalResponse {
@@ -6851,7 +7876,7 @@ func evalCommandInfo(args []string) *EvalResponse {
 		if cmdMeta, found := cmdMetaMap[arg]; found {
 			result = append(result, cmdMeta)
 		} else {
-			result = append(result, clientio.RespNIL)
+			result = append(result, clientio.NIL)
 		}
 	}
 
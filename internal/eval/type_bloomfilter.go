@@ -21,6 +21,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash"
+	"io"
 	"math"
 	"math/rand"
 	"strconv"
@@ -423,8 +424,9 @@ func DeserializeBloom(buf *bytes.Reader) (*Bloom, error) {
 	}
 
 	// Deserialize bitset
-	bloom.bitset = make([]byte, bloom.opts.bits)
-	if _, err := buf.Read(bloom.bitset); err != nil {
+	// bits is a multiple of 8, see NewBloomFilter
+	bloom.bitset = make([]byte, bloom.opts.bits/8)
+	if _, err := io.ReadFull(buf, bloom.bitset); err != nil {
 		return nil, err
 	}
 
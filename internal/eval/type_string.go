@@ -23,6 +23,18 @@ import (
 	"github.com/dicedb/dice/internal/object"
 )
 
+const (
+	// embstrSizeLimit is the length up to which a string is reported with the embstr encoding,
+	// like Redis. Longer strings and strings modified in place are reported as raw.
+	embstrSizeLimit = 44
+
+	// maxStringSize is the largest length a string can be grown to by APPEND or SETRANGE
+	maxStringSize = 512 * 1024 * 1024
+
+	// stringPreallocLimit is the length up to which the room reserved for a growing string is doubled
+	stringPreallocLimit = 1024 * 1024
+)
+
 type String struct {
 	value string
 }
@@ -72,3 +84,34 @@ func convertValueToString(obj *object.Obj, oType object.ObjectType) (string, err
 
 	return currentValueStr, nil
 }
+
+// getRawByteArray returns the value of a string object as a byte array that can be modified in place.
+// Strings and integers are converted, the byte array of a raw string is returned as is.
+func getRawByteArray(obj *object.Obj) (*ByteArray, error) {
+	if obj.Type == object.ObjTypeByteArray {
+		val, ok := obj.Value.(*ByteArray)
+		if !ok {
+			return nil, diceerrors.ErrWrongTypeOperation
+		}
+		return val, nil
+	}
+
+	str, err := convertValueToString(obj, obj.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &ByteArray{data: []byte(str), Length: int64(len(str))}, nil
+}
+
+// getStringEncoding returns the encoding OBJECT ENCODING reports for a string object.
+func getStringEncoding(obj *object.Obj) string {
+	switch obj.Type {
+	case object.ObjTypeInt:
+		return "int"
+	case object.ObjTypeString:
+		if val, ok := obj.Value.(string); ok && len(val) <= embstrSizeLimit {
+			return "embstr"
+		}
+	}
+	return "raw"
+}
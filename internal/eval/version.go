@@ -0,0 +1,51 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package eval
+
+import (
+	"strconv"
+	"strings"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// parseIfVersion splits the arguments of a write ending with the IFVERSION option from the option, keeping at
+// least minArgs arguments, and returns the version the key must be at, nil without the option.
+func parseIfVersion(args []string, minArgs int) ([]string, *uint64, error) {
+	n := len(args)
+	if n-2 < minArgs || !strings.EqualFold(args[n-2], IfVersion) {
+		return args, nil, nil
+	}
+	version, err := strconv.ParseUint(args[n-1], 10, 64)
+	if err != nil {
+		return nil, nil, diceerrors.ErrIntegerOutOfRange
+	}
+	return args[:n-2], &version, nil
+}
+
+// checkVersion returns a conflict error unless the key is at the version, 0 standing for a key that does not
+// exist. It is the condition of the writes with the IFVERSION option, checked before anything is written.
+func checkVersion(key string, expected *uint64, store *dstore.Store) error {
+	if expected == nil {
+		return nil
+	}
+	if version := store.Version(key); version != *expected {
+		return diceerrors.ErrVersionConflict(version, *expected)
+	}
+	return nil
+}
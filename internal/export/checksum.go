@@ -0,0 +1,74 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+)
+
+// checksumPrefix starts the JSON records sealed with their checksum.
+const checksumPrefix = `{"crc":"`
+
+// ErrChecksum is returned for a sealed record whose checksum does not match its content, e.g. a record
+// damaged on disk or the last record of a file cut short by a crash.
+var ErrChecksum = errors.New("the checksum of the record does not match")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Seal returns the JSON record with its checksum as its first field, e.g. `{"crc":"1a2b3c4d","key":...}`.
+// The checksum is the CRC-32C of the record as encoded without the field, so that it is checked against
+// the bytes read rather than against the record decoded and encoded again. A sealed record is still
+// a valid JSON record, the readers ignoring the checksum can read it.
+func Seal(line string) string {
+	sum := crc32.Checksum([]byte(line), castagnoli)
+	return fmt.Sprintf("%s%08x\",%s", checksumPrefix, sum, line[1:])
+}
+
+// IsSealed reports whether the JSON record starts with a checksum.
+func IsSealed(line []byte) bool {
+	return bytes.HasPrefix(line, []byte(checksumPrefix))
+}
+
+// Unseal checks the checksum of a record sealed by Seal and returns the record without it, ErrChecksum if
+// the checksum does not match. A record that is not sealed is returned as is.
+func Unseal(line []byte) ([]byte, error) {
+	if !IsSealed(line) {
+		return line, nil
+	}
+
+	// The checksum is 8 hexadecimal digits followed by the closing quote and the comma of the field
+	rest := line[len(checksumPrefix):]
+	if len(rest) < 10 || rest[8] != '"' || rest[9] != ',' {
+		return nil, ErrChecksum
+	}
+	want, err := strconv.ParseUint(string(rest[:8]), 16, 32)
+	if err != nil {
+		return nil, ErrChecksum
+	}
+
+	record := make([]byte, 0, len(rest)-9)
+	record = append(record, '{')
+	record = append(record, rest[10:]...)
+	if crc32.Checksum(record, castagnoli) != uint32(want) {
+		return nil, ErrChecksum
+	}
+	return record, nil
+}
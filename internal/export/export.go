@@ -0,0 +1,228 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package export implements EXPORT: it streams the keyspace, optionally filtered by pattern and type,
+// as JSON Lines or CSV. The keys of a shard are exported in small batches, each one a separate command
+// of the shard, so that a shard keeps serving the other clients in between.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/store"
+)
+
+// Command is the name of the command exporting the keyspace.
+const Command = "EXPORT"
+
+// batchSize is the number of keys exported by a single command of a shard.
+const batchSize = 100
+
+// Format is the encoding of the exported records.
+type Format string
+
+const (
+	// FormatJSON encodes every record as a JSON object on its own line, i.e. JSON Lines
+	FormatJSON Format = "json"
+	// FormatCSV encodes every record as a CSV row, after a `key,type,ttl,value` header
+	FormatCSV Format = "csv"
+)
+
+// Types of the exported values. They are the ones reported by TYPE, along with the types specific to DiceDB.
+const (
+	TypeString      = "string"
+	TypeList        = "list"
+	TypeSet         = "set"
+	TypeHash        = "hash"
+	TypeZSet        = "zset"
+	TypeJSON        = "json"
+	TypeBloomFilter = "bloomfilter"
+	TypeCMS         = "cms"
+	TypeHyperLogLog = "hyperloglog"
+)
+
+var types = map[string]bool{
+	TypeString: true, TypeList: true, TypeSet: true, TypeHash: true, TypeZSet: true,
+	TypeJSON: true, TypeBloomFilter: true, TypeCMS: true, TypeHyperLogLog: true,
+}
+
+var csvHeader = []string{"key", "type", "ttl", "value"}
+
+// Options selects the keys exported and their encoding.
+type Options struct {
+	Match  string // Match is the glob-style pattern of the exported keys
+	Type   string // Type restricts the export to the keys of a type, all of them if empty
+	Format Format
+	// Checksum seals every JSON record with its checksum, see Seal, e.g. for the backups
+	Checksum bool
+}
+
+// Record is an exported key.
+//
+// Value depends on the type: a string for strings, a list of strings for lists and sets, a map for hashes,
+// a list of ZMember for sorted sets and the document itself for JSON. The probabilistic types, Bloom
+// filters, count-min sketches and HyperLogLogs, are exported as their base64 encoded serialization.
+type Record struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	TTL   int64       `json:"ttl"` // TTL is the time to live of the key in milliseconds, -1 if it does not expire
+	Value interface{} `json:"value"`
+}
+
+// ZMember is a member of an exported sorted set.
+type ZMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// ParseArgs parses the arguments of EXPORT, `[MATCH pattern] [TYPE type] [FORMAT JSON|CSV]`.
+// By default every key is exported as JSON Lines.
+func ParseArgs(args []string) (Options, error) {
+	opts := Options{Match: "*", Format: FormatJSON}
+	if len(args)%2 != 0 {
+		return opts, diceerrors.ErrSyntax
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		value := args[i+1]
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			opts.Match = value
+		case "TYPE":
+			opts.Type = strings.ToLower(value)
+			if !types[opts.Type] {
+				return opts, diceerrors.ErrGeneral("unknown type " + value)
+			}
+		case "FORMAT":
+			switch format := Format(strings.ToLower(value)); format {
+			case FormatJSON, FormatCSV:
+				opts.Format = format
+			default:
+				return opts, diceerrors.ErrGeneral("unknown format " + value)
+			}
+		default:
+			return opts, diceerrors.ErrSyntax
+		}
+	}
+	return opts, nil
+}
+
+// ContentType returns the media type of an export in the format.
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// Encode returns the line encoding the record in the format, without its line terminator.
+// In CSV the value of the strings is written as is, the other values are written as JSON.
+func Encode(format Format, record Record) (string, error) {
+	if format == FormatJSON {
+		line, err := json.Marshal(record)
+		return string(line), err
+	}
+
+	value, ok := record.Value.(string)
+	if !ok {
+		encoded, err := json.Marshal(record.Value)
+		if err != nil {
+			return "", err
+		}
+		value = string(encoded)
+	}
+	return csvLine([]string{record.Key, record.Type, strconv.FormatInt(record.TTL, 10), value})
+}
+
+func csvLine(fields []string) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(fields); err != nil {
+		return "", err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// Exec executes a command on a shard and returns its result.
+type Exec func(ctx context.Context, shardID uint8, diceDBCmd *cmd.DiceDBCmd) (interface{}, error)
+
+// Run exports the keys of every shard selected by opts, handing the encoded lines to emit batch after batch.
+// The keys of a shard are listed first, then their values are read by batches of batchSize keys: the keys
+// deleted in between are skipped, and the export is not a point in time snapshot of the keyspace.
+func Run(ctx context.Context, shardCount int, exec Exec, opts Options, emit func(lines []string) error) error {
+	if opts.Format == FormatCSV {
+		header, err := csvLine(csvHeader)
+		if err != nil {
+			return err
+		}
+		if err := emit([]string{header}); err != nil {
+			return err
+		}
+	}
+
+	for shardID := 0; shardID < shardCount; shardID++ {
+		result, err := exec(ctx, uint8(shardID), &cmd.DiceDBCmd{Cmd: store.SingleShardKeys, Args: []string{opts.Match}})
+		if err != nil {
+			return err
+		}
+		keys, _ := result.([]string)
+
+		for start := 0; start < len(keys); start += batchSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			batch := keys[start:min(start+batchSize, len(keys))]
+			result, err := exec(ctx, uint8(shardID), &cmd.DiceDBCmd{Cmd: store.SingleShardExport, Args: batch})
+			if err != nil {
+				return err
+			}
+			records, _ := result.([]Record)
+
+			lines := make([]string, 0, len(records))
+			for _, record := range records {
+				if opts.Type != "" && record.Type != opts.Type {
+					continue
+				}
+				line, err := Encode(opts.Format, record)
+				if err != nil {
+					return err
+				}
+				if opts.Checksum && opts.Format == FormatJSON {
+					line = Seal(line)
+				}
+				lines = append(lines, line)
+			}
+			if len(lines) == 0 {
+				continue
+			}
+			if err := emit(lines); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
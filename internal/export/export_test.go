@@ -0,0 +1,179 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	opts, err := ParseArgs(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Match: "*", Format: FormatJSON}, opts)
+
+	opts, err = ParseArgs([]string{"format", "CSV", "TYPE", "Hash", "match", "user:*"})
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Match: "user:*", Type: TypeHash, Format: FormatCSV}, opts)
+
+	_, err = ParseArgs([]string{"MATCH"})
+	assert.Equal(t, diceerrors.ErrSyntax, err)
+	_, err = ParseArgs([]string{"LIMIT", "10"})
+	assert.Equal(t, diceerrors.ErrSyntax, err)
+	_, err = ParseArgs([]string{"TYPE", "stream"})
+	assert.EqualError(t, err, "ERR unknown type stream")
+	_, err = ParseArgs([]string{"FORMAT", "xml"})
+	assert.EqualError(t, err, "ERR unknown format xml")
+}
+
+func TestEncode(t *testing.T) {
+	line, err := Encode(FormatJSON, Record{Key: "k", Type: TypeList, TTL: -1, Value: []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"key":"k","type":"list","ttl":-1,"value":["a","b"]}`, line)
+
+	line, err = Encode(FormatCSV, Record{Key: "k", Type: TypeString, TTL: 1500, Value: "a,\"b\""})
+	assert.NoError(t, err)
+	assert.Equal(t, `k,string,1500,"a,""b"""`, line)
+
+	line, err = Encode(FormatCSV, Record{Key: "k", Type: TypeZSet, TTL: -1, Value: []ZMember{{Member: "m", Score: 1.5}}})
+	assert.NoError(t, err)
+	assert.Equal(t, `k,zset,-1,"[{""member"":""m"",""score"":1.5}]"`, line)
+}
+
+func TestSeal(t *testing.T) {
+	line := `{"key":"k","type":"string","ttl":-1,"value":"v"}`
+	sealed := Seal(line)
+	assert.Regexp(t, `^\{"crc":"[0-9a-f]{8}","key":"k",`, sealed)
+	assert.True(t, IsSealed([]byte(sealed)))
+
+	record, err := Unseal([]byte(sealed))
+	assert.NoError(t, err)
+	assert.Equal(t, line, string(record))
+
+	// A record that is not sealed is read as is
+	record, err = Unseal([]byte(line))
+	assert.NoError(t, err)
+	assert.Equal(t, line, string(record))
+
+	damaged := []byte(sealed)
+	damaged[len(damaged)-3] = 'w'
+	_, err = Unseal(damaged)
+	assert.ErrorIs(t, err, ErrChecksum)
+
+	_, err = Unseal([]byte(sealed[:len(sealed)-5]))
+	assert.ErrorIs(t, err, ErrChecksum, "a record cut short does not match its checksum")
+
+	_, err = Unseal([]byte(`{"crc":"zz","key":"k"}`))
+	assert.ErrorIs(t, err, ErrChecksum)
+}
+
+// shards is a fake keyspace, every shard mapping its keys to their type.
+type shards []map[string]string
+
+func (s shards) exec(_ context.Context, shardID uint8, diceDBCmd *cmd.DiceDBCmd) (interface{}, error) {
+	switch diceDBCmd.Cmd {
+	case store.SingleShardKeys:
+		keys := make([]string, 0)
+		for i := 0; i < len(s[shardID]); i++ {
+			keys = append(keys, fmt.Sprintf("s%d:%03d", shardID, i))
+		}
+		return keys, nil
+	case store.SingleShardExport:
+		records := make([]Record, 0, len(diceDBCmd.Args))
+		for _, key := range diceDBCmd.Args {
+			records = append(records, Record{Key: key, Type: s[shardID][key], TTL: -1, Value: "v"})
+		}
+		return records, nil
+	}
+	return nil, errors.New("unexpected command")
+}
+
+func newShards(sizes ...int) shards {
+	s := make(shards, len(sizes))
+	for shardID, size := range sizes {
+		s[shardID] = make(map[string]string, size)
+		for i := 0; i < size; i++ {
+			typ := TypeString
+			if i%2 == 1 {
+				typ = TypeHash
+			}
+			s[shardID][fmt.Sprintf("s%d:%03d", shardID, i)] = typ
+		}
+	}
+	return s
+}
+
+func TestRun(t *testing.T) {
+	s := newShards(250, 0, 3)
+
+	var batches [][]string
+	err := Run(context.Background(), len(s), s.exec, Options{Match: "*", Format: FormatCSV}, func(lines []string) error {
+		batches = append(batches, lines)
+		return nil
+	})
+	assert.NoError(t, err)
+	// The header, then 3 batches for the first shard and 1 for the last one
+	assert.Len(t, batches, 5)
+	assert.Equal(t, []string{"key,type,ttl,value"}, batches[0])
+	assert.Len(t, batches[1], batchSize)
+	assert.Equal(t, "s0:000,string,-1,v", batches[1][0])
+	assert.Len(t, batches[3], 50)
+	assert.Equal(t, []string{"s2:000,string,-1,v", "s2:001,hash,-1,v", "s2:002,string,-1,v"}, batches[4])
+}
+
+func TestRunFiltersByType(t *testing.T) {
+	s := newShards(4)
+
+	var lines []string
+	err := Run(context.Background(), len(s), s.exec, Options{Match: "*", Type: TypeHash, Format: FormatJSON}, func(batch []string) error {
+		lines = append(lines, batch...)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"key":"s0:001","type":"hash","ttl":-1,"value":"v"}`,
+		`{"key":"s0:003","type":"hash","ttl":-1,"value":"v"}`,
+	}, lines)
+}
+
+func TestRunStops(t *testing.T) {
+	s := newShards(250)
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := Run(context.Background(), len(s), s.exec, Options{Match: "*", Format: FormatJSON}, func([]string) error {
+		calls++
+		return errStop
+	})
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, 1, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Run(ctx, len(s), s.exec, Options{Match: "*", Format: FormatJSON}, func([]string) error {
+		t.Fatal("nothing is emitted once the context is canceled")
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,40 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build !failpoints
+
+package failpoint
+
+// Enabled reports whether the failpoints are compiled in.
+const Enabled = false
+
+// Enable returns ErrDisabled, the failpoints are not compiled in.
+func Enable(_, _ string) error {
+	return ErrDisabled
+}
+
+// Disable is a no-op, the failpoints are not compiled in.
+func Disable(string) {}
+
+// List returns no failpoint, the failpoints are not compiled in.
+func List() [][2]string {
+	return nil
+}
+
+// Eval never fires, the failpoints are not compiled in.
+func Eval(string) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,104 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build failpoints
+
+package failpoint
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Enabled reports whether the failpoints are compiled in.
+const Enabled = true
+
+var (
+	mu     sync.Mutex
+	active = make(map[string]*Term)
+)
+
+// Enable enables the failpoint with the term, "off" disabling it.
+func Enable(name, term string) error {
+	if !known(name) {
+		return fmt.Errorf("%w '%s'", ErrUnknown, name)
+	}
+	t, err := Parse(term)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if t.Action == "off" {
+		delete(active, name)
+		return nil
+	}
+	active[name] = &t
+	return nil
+}
+
+// Disable disables the failpoint.
+func Disable(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(active, name)
+}
+
+// List returns the enabled failpoints and their terms, sorted by name.
+func List() [][2]string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list := make([][2]string, 0, len(active))
+	for name, t := range active {
+		list = append(list, [2]string{name, t.String()})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i][0] < list[j][0] })
+	return list
+}
+
+// Eval evaluates the failpoint and reports whether it fired a return action, with its argument. A sleep
+// action sleeps before returning false.
+func Eval(name string) (string, bool) {
+	mu.Lock()
+	t, ok := active[name]
+	if !ok {
+		mu.Unlock()
+		return "", false
+	}
+	term := *t
+	if t.Count > 0 {
+		if t.Count--; t.Count == 0 {
+			delete(active, name)
+		}
+	}
+	mu.Unlock()
+
+	switch term.Action {
+	case "sleep":
+		time.Sleep(term.Sleep)
+		return "", false
+	case "panic":
+		panic(fmt.Sprintf("failpoint %s", name))
+	default:
+		return term.Arg, true
+	}
+}
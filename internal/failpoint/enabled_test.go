@@ -0,0 +1,63 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build failpoints
+
+package failpoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEval(t *testing.T) {
+	t.Cleanup(func() {
+		for _, name := range Names {
+			Disable(name)
+		}
+	})
+
+	_, fired := Eval(WatchDrop)
+	assert.False(t, fired)
+
+	assert.NoError(t, Enable(WatchDrop, "2*return(x)"))
+	assert.Equal(t, [][2]string{{WatchDrop, "2*return(x)"}}, List())
+	for i := 0; i < 2; i++ {
+		arg, fired := Eval(WatchDrop)
+		assert.True(t, fired)
+		assert.Equal(t, "x", arg)
+	}
+	// The count is exhausted, the failpoint is disabled
+	_, fired = Eval(WatchDrop)
+	assert.False(t, fired)
+	assert.Empty(t, List())
+
+	assert.NoError(t, Enable(ShardDelay, "sleep(20ms)"))
+	start := time.Now()
+	_, fired = Eval(ShardDelay)
+	assert.False(t, fired)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.NoError(t, Enable(ShardDelay, "off"))
+	assert.Empty(t, List())
+
+	assert.NoError(t, Enable(SocketWriteError, "panic"))
+	assert.Panics(t, func() { Eval(SocketWriteError) })
+
+	assert.ErrorIs(t, Enable("shard/nope", "return"), ErrUnknown)
+	assert.Error(t, Enable(WatchDrop, "explode"))
+}
@@ -0,0 +1,151 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package failpoint injects faults in the shards and the network layers, e.g. delayed shard responses or
+// socket write errors, so that the tests can exercise the timeouts and the retries of the server
+// deterministically. The failpoints are only compiled in with the failpoints build tag, Eval being a no-op
+// otherwise, and are enabled by the tests or with DEBUG FAILPOINT.
+//
+// A failpoint is enabled with a term of the form [<count>*]<action>[(<arg>)], e.g. "sleep(100ms)" or
+// "2*return(eagain)", the count limiting the number of times the failpoint fires. The actions are:
+//
+//	off              disables the failpoint
+//	return[(<arg>)]  makes Eval report that the failpoint fired, with the argument
+//	sleep(<duration>) makes Eval sleep for the duration
+//	panic            makes Eval panic
+package failpoint
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/internal/sockerr"
+)
+
+const (
+	// ShardDelay delays the operations received by the shards, e.g. with sleep(7s) to time out the requests
+	ShardDelay = "shard/delay"
+	// WatchDrop drops the updates of the watched commands instead of notifying the subscribers
+	WatchDrop = "watch/drop-update"
+	// SocketWriteError fails the writes to the client sockets, with the errno of its argument: epipe, the
+	// default, econnreset, enobufs or eagain
+	SocketWriteError = "net/write-error"
+)
+
+// Names lists the failpoints of the server.
+var Names = []string{ShardDelay, WatchDrop, SocketWriteError}
+
+var (
+	// ErrDisabled is returned when enabling a failpoint in a build without the failpoints build tag.
+	ErrDisabled = errors.New("failpoints are not compiled in, build with -tags failpoints")
+	// ErrUnknown is returned when enabling a failpoint the server does not have.
+	ErrUnknown = errors.New("unknown failpoint")
+)
+
+// Term is the behavior of an enabled failpoint.
+type Term struct {
+	Action string        // Action is one of off, return, sleep or panic
+	Arg    string        // Arg is the argument of return
+	Sleep  time.Duration // Sleep is the duration of sleep
+	Count  int           // Count is the number of times the failpoint fires, 0 meaning every time
+}
+
+// Parse parses a term, e.g. "3*return(eagain)".
+func Parse(term string) (Term, error) {
+	var t Term
+	s := strings.TrimSpace(term)
+	if i := strings.Index(s, "*"); i >= 0 {
+		count, err := strconv.Atoi(s[:i])
+		if err != nil || count <= 0 {
+			return t, fmt.Errorf("invalid count in failpoint term %q", term)
+		}
+		t.Count = count
+		s = s[i+1:]
+	}
+
+	t.Action = s
+	if i := strings.Index(s, "("); i >= 0 {
+		if !strings.HasSuffix(s, ")") {
+			return t, fmt.Errorf("unbalanced parenthesis in failpoint term %q", term)
+		}
+		t.Action, t.Arg = s[:i], s[i+1:len(s)-1]
+	}
+	t.Action = strings.ToLower(t.Action)
+
+	switch t.Action {
+	case "off", "panic":
+		if t.Arg != "" {
+			return t, fmt.Errorf("%s takes no argument in failpoint term %q", t.Action, term)
+		}
+	case "return":
+	case "sleep":
+		d, err := time.ParseDuration(t.Arg)
+		if err != nil || d < 0 {
+			return t, fmt.Errorf("invalid duration in failpoint term %q", term)
+		}
+		t.Sleep, t.Arg = d, ""
+	default:
+		return t, fmt.Errorf("unknown action in failpoint term %q", term)
+	}
+	return t, nil
+}
+
+// String returns the term as parsed by Parse.
+func (t Term) String() string {
+	var b strings.Builder
+	if t.Count > 0 {
+		b.WriteString(strconv.Itoa(t.Count))
+		b.WriteByte('*')
+	}
+	b.WriteString(t.Action)
+	switch {
+	case t.Action == "sleep":
+		b.WriteString("(" + t.Sleep.String() + ")")
+	case t.Arg != "":
+		b.WriteString("(" + t.Arg + ")")
+	}
+	return b.String()
+}
+
+func known(name string) bool {
+	for _, n := range Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteError returns the error of a socket write failing with the errno named by arg, as SocketWriteError
+// injects it: a *net.OpError wrapping the errno of the platform for the failure, like the ones of the net
+// package.
+func WriteError(arg string) error {
+	kind := sockerr.BrokenPipe
+	switch strings.ToLower(arg) {
+	case "econnreset":
+		kind = sockerr.ConnReset
+	case "enobufs":
+		kind = sockerr.NoBuffers
+	case "eagain":
+		kind = sockerr.WouldBlock
+	}
+	return &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", sockerr.Errno(kind))}
+}
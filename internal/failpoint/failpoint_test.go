@@ -0,0 +1,83 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package failpoint
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		term     string
+		expected Term
+		err      bool
+	}{
+		{term: "off", expected: Term{Action: "off"}},
+		{term: "return", expected: Term{Action: "return"}},
+		{term: "RETURN(eagain)", expected: Term{Action: "return", Arg: "eagain"}},
+		{term: "3*return(econnreset)", expected: Term{Action: "return", Arg: "econnreset", Count: 3}},
+		{term: " sleep(150ms) ", expected: Term{Action: "sleep", Sleep: 150 * time.Millisecond}},
+		{term: "2*panic", expected: Term{Action: "panic", Count: 2}},
+		{term: "sleep(forever)", err: true},
+		{term: "sleep", err: true},
+		{term: "0*return", err: true},
+		{term: "x*return", err: true},
+		{term: "return(eagain", err: true},
+		{term: "panic(now)", err: true},
+		{term: "explode", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			term, err := Parse(tt.term)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, term)
+
+			again, err := Parse(term.String())
+			assert.NoError(t, err)
+			assert.Equal(t, term, again)
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tests := map[string]syscall.Errno{
+		"":           syscall.EPIPE,
+		"epipe":      syscall.EPIPE,
+		"ECONNRESET": syscall.ECONNRESET,
+		"enobufs":    syscall.ENOBUFS,
+		"eagain":     syscall.EAGAIN,
+	}
+
+	for arg, errno := range tests {
+		err := WriteError(arg)
+		var opErr *net.OpError
+		assert.True(t, errors.As(err, &opErr))
+		assert.Equal(t, "write", opErr.Op)
+		assert.ErrorIs(t, err, errno)
+	}
+}
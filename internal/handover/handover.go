@@ -0,0 +1,469 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build unix
+
+// Package handover hands the listening sockets of the server over to a new process of the server, e.g. once
+// its binary is upgraded, so that no connection is refused while the server restarts. The old process executes
+// the binary again with the same arguments and passes its listening sockets to the new one over a Unix socket,
+// as SCM_RIGHTS control messages. Once the new process serves on them, the old one stops accepting connections
+// and drains the ones it has before exiting.
+//
+// The processes do not share their keys: the new process starts with the dataset it restores, e.g. from the
+// WAL, and the keys written through the old one while it drains are not seen by the new one.
+package handover
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// envFD is the environment variable telling the new process the descriptor of its socket to the old one
+const envFD = "DICEDB_HANDOVER_FD"
+
+// readyMsg is sent by the new process once it serves on the listeners handed over
+const readyMsg = "ready\n"
+
+// maxListeners bounds the number of listeners handed over at once
+const maxListeners = 64
+
+var (
+	ErrInProgress  = errors.New("the listeners are already handed over")
+	ErrNoListeners = errors.New("no listener to hand over")
+)
+
+var (
+	mu sync.Mutex
+	// listeners are the listeners of the process, handed over to the next one
+	listeners = make(map[*listener]bool)
+	// fds are the raw listening sockets of the process, by key, handed over to the next one
+	fds = make(map[int]string)
+	// inherited are the listening sockets handed over by the previous process, by key, until a server claims them
+	inherited map[string]int
+	// previous is the socket to the previous process, nil once it is told the process is ready
+	previous *net.UnixConn
+	// handedOver is set once the process received the listeners of a previous one
+	handedOver atomic.Bool
+
+	// started is set once a handover starts, draining once the new process serves on the listeners
+	started  atomic.Bool
+	draining atomic.Bool
+)
+
+// key identifies a listener across the processes, so that a listener whose address changed in the config of the
+// new process is not reused by it
+func key(network, address string) string {
+	return network + " " + address
+}
+
+// Listen returns the listener of the address handed over by the previous process, or listens on the address.
+func Listen(network, address string) (net.Listener, error) {
+	return ListenFunc(network, address, func() (net.Listener, error) {
+		return net.Listen(network, address)
+	})
+}
+
+// ListenFunc returns the listener of the address handed over by the previous process, or the one of listen.
+// The listener is handed over to the next process until it is closed.
+func ListenFunc(network, address string, listen func() (net.Listener, error)) (net.Listener, error) {
+	k := key(network, address)
+
+	var ln net.Listener
+	if fd, ok := claim(k); ok {
+		f := os.NewFile(uintptr(fd), k)
+		var err error
+		ln, err = net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid listener %s handed over: %w", k, err)
+		}
+		slog.Info("serving on the listener handed over", slog.String("listener", k))
+	} else {
+		var err error
+		if ln, err = listen(); err != nil {
+			return nil, err
+		}
+	}
+
+	l := &listener{Listener: ln, key: k, closed: make(chan struct{})}
+	mu.Lock()
+	listeners[l] = true
+	mu.Unlock()
+	return l, nil
+}
+
+// ListenFD returns the raw listening socket of the address handed over by the previous process, or the one of
+// listen. The socket is handed over to the next process until it is closed with CloseFD.
+func ListenFD(network, address string, listen func() (int, error)) (int, error) {
+	k := key(network, address)
+
+	fd, ok := claim(k)
+	if ok {
+		slog.Info("serving on the listener handed over", slog.String("listener", k))
+	} else {
+		var err error
+		if fd, err = listen(); err != nil {
+			return -1, err
+		}
+	}
+
+	mu.Lock()
+	fds[fd] = k
+	mu.Unlock()
+	return fd, nil
+}
+
+// CloseFD closes a raw listening socket of ListenFD.
+func CloseFD(fd int) error {
+	mu.Lock()
+	delete(fds, fd)
+	mu.Unlock()
+	return syscall.Close(fd)
+}
+
+// Draining reports whether the listeners are handed over to the next process, the servers accepting no more
+// connections on them.
+func Draining() bool {
+	return draining.Load()
+}
+
+// Inherited reports whether the process serves on listeners handed over by a previous one.
+func Inherited() bool {
+	return handedOver.Load()
+}
+
+func claim(k string) (int, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	fd, ok := inherited[k]
+	delete(inherited, k)
+	return fd, ok
+}
+
+// Init receives the listeners handed over by the previous process, if the process was started by a handover.
+// It is called before the servers listen.
+func Init() error {
+	value := os.Getenv(envFD)
+	if value == "" {
+		return nil
+	}
+	os.Unsetenv(envFD)
+
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", envFD, value)
+	}
+	f := os.NewFile(uintptr(fd), "handover")
+	conn, err := net.FileConn(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("invalid handover socket: %w", err)
+	}
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		conn.Close()
+		return fmt.Errorf("invalid handover socket: %T", conn)
+	}
+
+	received, err := receive(uc)
+	if err != nil {
+		uc.Close()
+		return err
+	}
+
+	mu.Lock()
+	inherited, previous = received, uc
+	mu.Unlock()
+	handedOver.Store(true)
+	return nil
+}
+
+// Ready tells the previous process that the servers serve on the listeners handed over, once every one of
+// them is claimed or the context is done. The listeners not claimed, e.g. of a server disabled in the config of
+// this process, are closed.
+func Ready(ctx context.Context) {
+	mu.Lock()
+	conn := previous
+	mu.Unlock()
+	if conn == nil {
+		return
+	}
+	waitClaimed(ctx)
+
+	mu.Lock()
+	for k, fd := range inherited {
+		slog.Warn("closing a listener handed over that no server serves on", slog.String("listener", k))
+		syscall.Close(fd)
+	}
+	inherited, previous = nil, nil
+	mu.Unlock()
+
+	if _, err := conn.Write([]byte(readyMsg)); err != nil {
+		slog.Warn("could not tell the previous process the listeners are served", slog.Any("error", err))
+	}
+	conn.Close()
+}
+
+// waitClaimed waits for the servers to claim the listeners handed over, or for the context to be done
+func waitClaimed(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		mu.Lock()
+		pending := len(inherited)
+		mu.Unlock()
+		if pending == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Start executes the binary of the server again and hands the listeners over to the new process. It returns
+// the pid of the new process once it serves on them, the listeners of this process then accepting no more
+// connections, or fails if the new process exits or is not ready before the timeout, this process serving on
+// as before.
+func Start(ctx context.Context, timeout time.Duration) (int, error) {
+	if !started.CompareAndSwap(false, true) {
+		return 0, ErrInProgress
+	}
+	pid, err := start(ctx, timeout)
+	if err != nil {
+		started.Store(false)
+		return 0, err
+	}
+
+	draining.Store(true)
+	mu.Lock()
+	for l := range listeners {
+		l.drain()
+	}
+	mu.Unlock()
+	return pid, nil
+}
+
+func start(ctx context.Context, timeout time.Duration) (int, error) {
+	path, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return 0, fmt.Errorf("could not find the binary of the server: %w", err)
+	}
+
+	keys, dups, err := dupListeners()
+	defer func() {
+		for _, fd := range dups {
+			syscall.Close(fd)
+		}
+	}()
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, ErrNoListeners
+	}
+
+	conn, peer, err := socketPair()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), envFD+"=3")
+	cmd.ExtraFiles = []*os.File{peer}
+	err = cmd.Start()
+	peer.Close()
+	if err != nil {
+		return 0, fmt.Errorf("could not start the new process: %w", err)
+	}
+	// The new process outlives this one once ready, it is only waited for if it fails
+	abort := func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}
+
+	if err := send(conn, keys, dups); err != nil {
+		abort()
+		return 0, err
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		_ = conn.SetReadDeadline(time.Now().Add(timeout))
+		msg, err := bufio.NewReader(conn).ReadString('\n')
+		if err == nil && msg != readyMsg {
+			err = fmt.Errorf("unexpected message %q", msg)
+		}
+		ready <- err
+	}()
+
+	select {
+	case err = <-ready:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		abort()
+		return 0, fmt.Errorf("the new process did not get ready: %w", err)
+	}
+	slog.Info("the new process serves on the listeners", slog.Int("pid", cmd.Process.Pid),
+		slog.String("listeners", strings.Join(keys, ", ")))
+	return cmd.Process.Pid, nil
+}
+
+// dupListeners returns copies of the listening sockets of the process, by key
+func dupListeners() ([]string, []int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var keys []string
+	var dups []int
+	for l := range listeners {
+		sc, ok := l.Listener.(syscall.Conn)
+		if !ok {
+			return keys, dups, fmt.Errorf("listener %s can not be handed over", l.key)
+		}
+		rc, err := sc.SyscallConn()
+		if err != nil {
+			return keys, dups, err
+		}
+		var fd int
+		var dupErr error
+		if err := rc.Control(func(s uintptr) { fd, dupErr = dup(int(s)) }); err != nil {
+			return keys, dups, err
+		}
+		if dupErr != nil {
+			return keys, dups, dupErr
+		}
+		keys, dups = append(keys, l.key), append(dups, fd)
+	}
+	for s, k := range fds {
+		fd, err := dup(s)
+		if err != nil {
+			return keys, dups, err
+		}
+		keys, dups = append(keys, k), append(dups, fd)
+	}
+
+	if len(keys) > maxListeners {
+		return keys, dups, fmt.Errorf("%d listeners, at most %d can be handed over", len(keys), maxListeners)
+	}
+	return keys, dups, nil
+}
+
+// dup returns a copy of the descriptor, closed on exec. The copy shares the flags of the open file, e.g. it is
+// non-blocking if the descriptor is, which is why the listeners are not copied with their File method.
+func dup(fd int) (int, error) {
+	syscall.ForkLock.RLock()
+	defer syscall.ForkLock.RUnlock()
+	copied, err := syscall.Dup(fd)
+	if err != nil {
+		return -1, err
+	}
+	syscall.CloseOnExec(copied)
+	return copied, nil
+}
+
+// socketPair returns the end of a Unix socket pair kept by this process, and the one passed to the new process
+func socketPair() (*net.UnixConn, *os.File, error) {
+	syscall.ForkLock.RLock()
+	pair, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if err == nil {
+		syscall.CloseOnExec(pair[0])
+		syscall.CloseOnExec(pair[1])
+	}
+	syscall.ForkLock.RUnlock()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create the handover socket: %w", err)
+	}
+
+	f := os.NewFile(uintptr(pair[0]), "handover")
+	conn, err := net.FileConn(f)
+	f.Close()
+	if err != nil {
+		syscall.Close(pair[1])
+		return nil, nil, err
+	}
+	return conn.(*net.UnixConn), os.NewFile(uintptr(pair[1]), "handover"), nil
+}
+
+// send sends the listening sockets with their keys, in a single message
+func send(conn *net.UnixConn, keys []string, sockets []int) error {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	if _, _, err := conn.WriteMsgUnix(payload, syscall.UnixRights(sockets...), nil); err != nil {
+		return fmt.Errorf("could not hand the listeners over: %w", err)
+	}
+	return nil
+}
+
+// receive receives the listening sockets sent by send, by key
+func receive(conn *net.UnixConn) (map[string]int, error) {
+	buf := make([]byte, 64*1024)
+	oob := make([]byte, syscall.CmsgSpace(maxListeners*4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, fmt.Errorf("could not receive the listeners handed over: %w", err)
+	}
+
+	var sockets []int
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, fmt.Errorf("invalid listeners handed over: %w", err)
+	}
+	for i := range msgs {
+		rights, err := syscall.ParseUnixRights(&msgs[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid listeners handed over: %w", err)
+		}
+		sockets = append(sockets, rights...)
+	}
+
+	var keys []string
+	if err := json.Unmarshal(buf[:n], &keys); err != nil || len(keys) != len(sockets) {
+		for _, fd := range sockets {
+			syscall.Close(fd)
+		}
+		return nil, fmt.Errorf("invalid listeners handed over: %d keys for %d sockets", len(keys), len(sockets))
+	}
+
+	received := make(map[string]int, len(keys))
+	for i, k := range keys {
+		syscall.CloseOnExec(sockets[i])
+		received[k] = sockets[i]
+	}
+	return received, nil
+}
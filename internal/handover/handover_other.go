@@ -0,0 +1,63 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build !unix
+
+package handover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// The listening sockets are passed over Unix sockets, so the listeners are not handed over on the other
+// platforms: the servers listen as usual and Start fails.
+
+// Init does nothing, no listener being handed over.
+func Init() error {
+	return nil
+}
+
+// Listen listens on the address.
+func Listen(network, address string) (net.Listener, error) {
+	return net.Listen(network, address)
+}
+
+// ListenFunc returns the listener created by listen.
+func ListenFunc(_, _ string, listen func() (net.Listener, error)) (net.Listener, error) {
+	return listen()
+}
+
+// Draining reports false, the listeners never being handed over.
+func Draining() bool {
+	return false
+}
+
+// Inherited reports false, no listener being handed over.
+func Inherited() bool {
+	return false
+}
+
+// Ready does nothing, the process not being started by a handover.
+func Ready(context.Context) {}
+
+// Start fails, the listeners can not be handed over on this platform.
+func Start(context.Context, time.Duration) (int, error) {
+	return 0, fmt.Errorf("could not hand the listeners over: %w", errors.ErrUnsupported)
+}
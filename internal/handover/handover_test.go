@@ -0,0 +1,126 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build unix
+
+package handover
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandOverListeners(t *testing.T) {
+	previous, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer previous.Close()
+	address := previous.Addr().String()
+
+	// The socket is handed over to the next process through the socket pair, as on a handover
+	fd, err := dup(int(mustFD(t, previous)))
+	require.NoError(t, err)
+	conn, peer, err := socketPair()
+	require.NoError(t, err)
+	defer conn.Close()
+	peerConn, err := net.FileConn(peer)
+	peer.Close()
+	require.NoError(t, err)
+	defer peerConn.Close()
+
+	require.NoError(t, send(conn, []string{key("tcp", address)}, []int{fd}))
+	syscall.Close(fd)
+	received, err := receive(peerConn.(*net.UnixConn))
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+
+	mu.Lock()
+	inherited = received
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		inherited = nil
+		mu.Unlock()
+	})
+
+	ln, err := Listen("tcp", address)
+	require.NoError(t, err)
+	defer ln.Close()
+	assert.Empty(t, inherited, "the listener handed over is claimed")
+
+	// The connections to the address are accepted on the listener handed over
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+	client, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	defer client.Close()
+	select {
+	case c := <-accepted:
+		c.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connection was not accepted on the listener handed over")
+	}
+}
+
+func TestDrainingListener(t *testing.T) {
+	ln, err := Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	draining.Store(true)
+	t.Cleanup(func() { draining.Store(false) })
+	ln.(*listener).drain()
+
+	// Accept waits for the server to close the listener, rather than failing once drained
+	done := make(chan error, 1)
+	go func() {
+		_, err := ln.Accept()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("Accept returned %v before the listener was closed", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, ln.Close())
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, net.ErrClosed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Accept did not return once the listener was closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Empty(t, listeners, "a closed listener is not handed over")
+}
+
+func mustFD(t *testing.T, ln net.Listener) uintptr {
+	rc, err := ln.(syscall.Conn).SyscallConn()
+	require.NoError(t, err)
+	var fd uintptr
+	require.NoError(t, rc.Control(func(s uintptr) { fd = s }))
+	return fd
+}
@@ -0,0 +1,66 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build unix
+
+package handover
+
+import (
+	"net"
+	"sync"
+)
+
+// listener is a listener handed over to the next process until it is closed. Once the listeners are handed
+// over, its Accept waits for the listener to be closed, so that the server stops accepting connections but
+// keeps serving the ones it has.
+type listener struct {
+	net.Listener
+	key       string
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil && Draining() {
+		<-l.closed
+		return nil, net.ErrClosed
+	}
+	return conn, err
+}
+
+func (l *listener) Close() error {
+	mu.Lock()
+	delete(listeners, l)
+	mu.Unlock()
+	l.closeOnce.Do(func() { close(l.closed) })
+
+	err := l.Listener.Close()
+	if Draining() {
+		// The socket is closed by drain already
+		return nil
+	}
+	return err
+}
+
+// drain closes the socket of the listener, the next process serving on its copy. The file of a Unix socket
+// is left in place for the next process.
+func (l *listener) drain() {
+	if ul, ok := l.Listener.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	_ = l.Listener.Close()
+}
@@ -0,0 +1,167 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package hotkeys finds the keys accessed the most, and optionally serves the reads of the hottest ones
+// without going through the shards owning them, so that a single key read by every client does not saturate
+// its shard.
+//
+// The accesses to the keys are sampled, at hotkeys.sample_rate, and the access rate of hotkeys.tracked_keys
+// keys is estimated with the space-saving algorithm: a key sampled while the tracked keys are all taken
+// replaces the least accessed one, inheriting its count. The counts decay exponentially, so that the rates
+// follow the recent accesses rather than the ones since the server started.
+//
+// With hotkeys.replicate, the value of a string key whose estimated rate reaches hotkeys.replicate_min_ops is
+// copied once its shard executes a GET of the key. The next GETs of the key are served from the copy by the
+// io-threads, until the key is written, expires, or the copy is a second old and refreshed by the shard. The
+// shard drops the copy before replying to a command writing the key, so that a client never reads a value
+// older than the one of a write it was replied to.
+package hotkeys
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+)
+
+// ErrDisabled is returned by HOTKEYS while the accesses to the keys are not sampled.
+var ErrDisabled = errors.New("ERR hot keys are not tracked, set hotkeys.enabled to track them")
+
+// decayTime is the time constant of the decay of the counts: an access counts for 1/e after decayTime, and the
+// count of a key accessed at a steady rate settles to the number of accesses of decayTime
+const decayTime = time.Second
+
+// Key is a tracked key and its estimated access rate.
+type Key struct {
+	Key       string
+	OpsPerSec float64
+}
+
+type counter struct {
+	count     float64 // count is the estimated number of accesses, decayed as of updatedAt
+	updatedAt time.Time
+}
+
+// decayed returns the count decayed as of now
+func (c *counter) decayed(now time.Time) float64 {
+	return c.count * math.Exp(-now.Sub(c.updatedAt).Seconds()/decayTime.Seconds())
+}
+
+var (
+	mu       sync.Mutex
+	counters = make(map[string]*counter) // counters are the tracked keys
+)
+
+// Record samples an access to the keys of the command, when the hot keys are tracked.
+func Record(c *cmd.DiceDBCmd) {
+	if !config.DiceConfig().HotKeys.Enabled {
+		return
+	}
+	rate := config.DiceConfig().HotKeys.SampleRate
+	if rate < 1 && rand.Float64() >= rate {
+		return
+	}
+	spec, ok := cmd.LookupKeySpec(c.Cmd)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	mu.Lock()
+	defer mu.Unlock()
+	for _, i := range spec.KeyIndices(c.Args) {
+		record(c.Args[i], 1/rate, now)
+	}
+}
+
+// RecordKey samples an access to the key, when the hot keys are tracked.
+func RecordKey(key string) {
+	if !config.DiceConfig().HotKeys.Enabled {
+		return
+	}
+	rate := config.DiceConfig().HotKeys.SampleRate
+	if rate < 1 && rand.Float64() >= rate {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	record(key, 1/rate, time.Now())
+}
+
+// record adds the weight of a sampled access to the count of the key. A key not tracked yet replaces the least
+// accessed key once hotkeys.tracked_keys are, with the count of the key replaced as the error of its estimate.
+func record(key string, weight float64, now time.Time) {
+	if c, ok := counters[key]; ok {
+		c.count = c.decayed(now) + weight
+		c.updatedAt = now
+		return
+	}
+
+	var base float64
+	for len(counters) >= config.DiceConfig().HotKeys.TrackedKeys {
+		coldest, coldestCount := "", math.Inf(1)
+		for k, c := range counters {
+			if count := c.decayed(now); count < coldestCount {
+				coldest, coldestCount = k, count
+			}
+		}
+		delete(counters, coldest)
+		base = coldestCount
+	}
+	counters[key] = &counter{count: base + weight, updatedAt: now}
+}
+
+// Top returns the count tracked keys with the highest estimated access rate, the hottest first.
+func Top(count int) []Key {
+	now := time.Now()
+	mu.Lock()
+	keys := make([]Key, 0, len(counters))
+	for k, c := range counters {
+		keys = append(keys, Key{Key: k, OpsPerSec: c.decayed(now) / decayTime.Seconds()})
+	}
+	mu.Unlock()
+
+	slices.SortFunc(keys, func(a, b Key) int { return cmp.Compare(b.OpsPerSec, a.OpsPerSec) })
+	if count >= 0 && count < len(keys) {
+		keys = keys[:count]
+	}
+	return keys
+}
+
+// Rate returns the estimated access rate of the key, 0 when it is not tracked.
+func Rate(key string) float64 {
+	mu.Lock()
+	defer mu.Unlock()
+	c, ok := counters[key]
+	if !ok {
+		return 0
+	}
+	return c.decayed(time.Now()) / decayTime.Seconds()
+}
+
+// Reset stops tracking the keys, their rates are estimated again from the next accesses.
+func Reset() {
+	mu.Lock()
+	clear(counters)
+	mu.Unlock()
+}
@@ -0,0 +1,166 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package hotkeys
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	_ "github.com/dicedb/dice/internal/eval" // registers the key specifications of the commands
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withHotKeysConfig(t *testing.T, trackedKeys int) {
+	previous := config.DiceConfig().HotKeys
+	config.DiceConfig().HotKeys.Enabled = true
+	config.DiceConfig().HotKeys.SampleRate = 1
+	config.DiceConfig().HotKeys.TrackedKeys = trackedKeys
+	config.DiceConfig().HotKeys.Replicate = true
+	config.DiceConfig().HotKeys.ReplicateMinOps = 50
+	t.Cleanup(func() {
+		config.DiceConfig().HotKeys = previous
+		Reset()
+		DropAll()
+	})
+}
+
+func TestTopKeys(t *testing.T) {
+	withHotKeysConfig(t, 3)
+
+	for i := 0; i < 100; i++ {
+		Record(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"celebrity"}})
+	}
+	for i := 0; i < 10; i++ {
+		Record(&cmd.DiceDBCmd{Cmd: "MSET", Args: []string{"a", "1", "b", "2"}})
+	}
+	Record(&cmd.DiceDBCmd{Cmd: "PING"})
+
+	top := Top(-1)
+	require.Len(t, top, 3)
+	assert.Equal(t, "celebrity", top[0].Key)
+	assert.InDelta(t, 100, top[0].OpsPerSec, 1)
+	assert.ElementsMatch(t, []string{"a", "b"}, []string{top[1].Key, top[2].Key})
+	assert.Len(t, Top(1), 1)
+
+	// A new key replaces the least accessed one, inheriting its count as the error of its estimate
+	RecordKey("c")
+	top = Top(-1)
+	require.Len(t, top, 3)
+	assert.Equal(t, "celebrity", top[0].Key)
+	assert.InDelta(t, 11, Rate("c"), 1)
+	assert.Zero(t, Rate("unknown"))
+
+	// The counts decay once the keys are no longer accessed
+	mu.Lock()
+	for _, c := range counters {
+		c.updatedAt = c.updatedAt.Add(-3 * decayTime)
+	}
+	mu.Unlock()
+	assert.Less(t, Rate("celebrity"), 5.1)
+
+	config.DiceConfig().HotKeys.Enabled = false
+	RecordKey("d")
+	assert.Zero(t, Rate("d"))
+}
+
+func TestSampling(t *testing.T) {
+	withHotKeysConfig(t, 10)
+	config.DiceConfig().HotKeys.SampleRate = 0.1
+
+	// Every sampled access counts for the accesses that were not, the estimate is close to the actual rate
+	for i := 0; i < 10000; i++ {
+		RecordKey("k")
+	}
+	assert.InDelta(t, 10000, Rate("k"), 1500)
+}
+
+func TestReplicas(t *testing.T) {
+	withHotKeysConfig(t, 10)
+
+	assert.False(t, Hot("k"))
+	for i := 0; i < 100; i++ {
+		RecordKey("k")
+		RecordKey(fmt.Sprintf("cold%d", i%5))
+	}
+	require.True(t, Hot("k"))
+	assert.False(t, Hot("cold0"))
+
+	_, hitsBefore := Replicas()
+	Replicate("k", "v", 0)
+	value, ok := Lookup("k")
+	require.True(t, ok)
+	assert.Equal(t, "v", value)
+	keys, hits := Replicas()
+	assert.EqualValues(t, 1, keys)
+	assert.Equal(t, hitsBefore+1, hits)
+
+	// A read does not drop the copy, a write of the key does
+	Invalidate(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}})
+	Invalidate(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"other", "v"}})
+	_, ok = Lookup("k")
+	assert.True(t, ok)
+	Invalidate(&cmd.DiceDBCmd{Cmd: "MSET", Args: []string{"other", "v", "k", "w"}})
+	_, ok = Lookup("k")
+	assert.False(t, ok)
+
+	// So does a COPY onto the key, received by its shard as OBJECTCOPY, and a key loaded in cache mode
+	Replicate("k", "v", 0)
+	Invalidate(&cmd.DiceDBCmd{Cmd: "OBJECTCOPY", Args: []string{"other"}})
+	_, ok = Lookup("k")
+	assert.True(t, ok)
+	Invalidate(&cmd.DiceDBCmd{Cmd: "OBJECTCOPY", Args: []string{"k", "REPLACE"}})
+	_, ok = Lookup("k")
+	assert.False(t, ok)
+	Replicate("k", "v", 0)
+	Invalidate(&cmd.DiceDBCmd{Cmd: "CACHELOAD", Args: []string{"k", "w", "1000"}})
+	_, ok = Lookup("k")
+	assert.False(t, ok)
+
+	// So does a change of the key in the store, and a write without keys
+	Replicate("k", int64(1), 0)
+	Invalidator{}.OnKeyEvent(dstore.KeyEvent{Type: dstore.KeyEventDel, Key: "k", Cmd: dstore.Expired})
+	_, ok = Lookup("k")
+	assert.False(t, ok)
+	Replicate("k", "v", 0)
+	Invalidate(&cmd.DiceDBCmd{Cmd: "FLUSHDB"})
+	_, ok = Lookup("k")
+	assert.False(t, ok)
+
+	// A copy is not served once the key expired, nor once it is too old
+	Replicate("k", "v", time.Now().Add(-time.Millisecond).UnixMilli())
+	_, ok = Lookup("k")
+	assert.False(t, ok)
+	Replicate("k", "v", 0)
+	replicasMu.Lock()
+	r := replicas["k"]
+	r.validUntil = time.Now().Add(-time.Millisecond)
+	replicas["k"] = r
+	replicasMu.Unlock()
+	_, ok = Lookup("k")
+	assert.False(t, ok)
+
+	// Only the strings are copied
+	DropAll()
+	Replicate("k", []string{"v"}, 0)
+	keys, _ = Replicas()
+	assert.Zero(t, keys)
+}
@@ -0,0 +1,145 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package hotkeys
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/cmd"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// replicaLifetime is the time a copy is served for, after which the next GET of the key is executed by its
+// shard, copying it again if it is still hot
+const replicaLifetime = time.Second
+
+type replica struct {
+	value      interface{}
+	expiresAt  int64 // expiresAt is the expiry of the key in Unix milliseconds, 0 without expiry
+	validUntil time.Time
+}
+
+var (
+	replicasMu sync.RWMutex
+	replicas   = make(map[string]replica) // replicas are the copies of the hot keys, by key
+	// replicated is the number of copies, read by the shards to skip the invalidation while there is none
+	replicated atomic.Int64
+	// served is the number of GETs served from the copies
+	served atomic.Int64
+)
+
+// Lookup returns the copy of the value of the key read by GET, if the key is hot and copied. The access is
+// sampled like the ones executed by the shards, so that the key stays hot while it is read from the copy.
+func Lookup(key string) (interface{}, bool) {
+	if replicated.Load() == 0 {
+		return nil, false
+	}
+
+	replicasMu.RLock()
+	r, ok := replicas[key]
+	replicasMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	now := time.Now()
+	if now.After(r.validUntil) || (r.expiresAt > 0 && now.UnixMilli() >= r.expiresAt) {
+		return nil, false
+	}
+
+	RecordKey(key)
+	served.Add(1)
+	return r.value, true
+}
+
+// Hot reports whether the key read by GET is to be copied, being hot while the copies are enabled.
+func Hot(key string) bool {
+	return config.DiceConfig().HotKeys.Replicate && config.DiceConfig().HotKeys.Enabled &&
+		Rate(key) >= float64(config.DiceConfig().HotKeys.ReplicateMinOps)
+}
+
+// Replicate copies the value of the hot key replied to a GET by its shard, the strings only. expiresAt is the
+// expiry of the key in Unix milliseconds, 0 without expiry. It is called by the goroutine of the shard owning
+// the key.
+func Replicate(key string, value interface{}, expiresAt int64) {
+	switch value.(type) {
+	case string, int64:
+	default:
+		return
+	}
+
+	replicasMu.Lock()
+	if _, ok := replicas[key]; !ok {
+		replicated.Add(1)
+	}
+	replicas[key] = replica{value: value, expiresAt: expiresAt, validUntil: time.Now().Add(replicaLifetime)}
+	replicasMu.Unlock()
+}
+
+// Invalidate drops the copies of the keys the command may write, every copy for the writes without keys, e.g.
+// FLUSHDB. It is called by the goroutine of the shard executing the command, before it replies.
+func Invalidate(c *cmd.DiceDBCmd) {
+	if replicated.Load() == 0 || audit.Categorize(c.Cmd) == audit.CategoryRead {
+		return
+	}
+
+	spec, ok := cmd.LookupKeySpec(c.Cmd)
+	if !ok {
+		DropAll()
+		return
+	}
+	replicasMu.Lock()
+	for _, i := range spec.KeyIndices(c.Args) {
+		drop(c.Args[i])
+	}
+	replicasMu.Unlock()
+}
+
+// Invalidator drops the copies of the keys changed in the stores it is subscribed to, e.g. expired or evicted.
+type Invalidator struct{}
+
+func (Invalidator) OnKeyEvent(e dstore.KeyEvent) {
+	if replicated.Load() == 0 {
+		return
+	}
+	replicasMu.Lock()
+	drop(e.Key)
+	replicasMu.Unlock()
+}
+
+func drop(key string) {
+	if _, ok := replicas[key]; ok {
+		delete(replicas, key)
+		replicated.Add(-1)
+	}
+}
+
+// DropAll drops every copy.
+func DropAll() {
+	replicasMu.Lock()
+	clear(replicas)
+	replicated.Store(0)
+	replicasMu.Unlock()
+}
+
+// Replicas returns the number of keys copied and the number of GETs served from the copies.
+func Replicas() (keys, hits int64) {
+	return replicated.Load(), served.Load()
+}
@@ -0,0 +1,175 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package idempotency makes the retries of the writes of the HTTP and WebSocket clients safe: a client attaches
+// a request id to a command, and the response of the first execution of the command is kept for the window of
+// idempotency.window, replied as it is to the retries of the request instead of executing the command again.
+// A client timing out on an INCR or an LPUSH can then retry it without knowing whether it was applied.
+//
+// Only the writes are deduplicated, the reads carrying a request id are executed every time. A retry received
+// while the request is still executed waits for its response. The request ids are shared by the clients, they
+// are expected to be unique, e.g. UUIDs, and are only ever used for a single command: the retry of a request id
+// with another command is refused. At most idempotency.max_requests responses are kept, the oldest ones being
+// dropped first.
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+)
+
+const (
+	// Header is the header of the HTTP requests holding their request id
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on the HTTP responses replied from the response of a previous request
+	ReplayedHeader = "Idempotent-Replayed"
+	// Prefix prefixes the WebSocket messages with their request id, e.g. IDEMPOTENT 3f2a INCR k
+	Prefix = "IDEMPOTENT"
+
+	maxIDLength = 128
+)
+
+var (
+	ErrInvalidID = errors.New("ERR invalid request id, expected 1 to 128 characters")
+	ErrIDReused  = errors.New("ERR the request id was used for another command")
+)
+
+// entry is a request, in progress until done is closed
+type entry struct {
+	fingerprint string
+	done        chan struct{}
+	resp        *eval.EvalResponse // resp is the response of the request, nil if it was abandoned
+	expiresAt   time.Time
+}
+
+var (
+	mu      sync.Mutex
+	entries = make(map[string]*entry)
+	// completed are the ids of the requests completed, oldest first
+	completed = list.New()
+)
+
+// Request is a request whose command is executed, to be completed with its response or abandoned.
+type Request struct {
+	id    string
+	entry *entry
+}
+
+// Begin looks the request id up before the command is executed. It returns the response of the request if it
+// completed within the window, waiting for it while it is in progress. Otherwise the command is to be executed,
+// and the Request returned completed with its response, or abandoned if the command was not executed. The
+// Request is nil when the command is not deduplicated: the id is empty, the command is not a write or the
+// idempotency is disabled.
+func Begin(ctx context.Context, id string, c *cmd.DiceDBCmd) (*eval.EvalResponse, *Request, error) {
+	if id == "" || !config.DiceConfig().Idempotency.Enabled || audit.Categorize(c.Cmd) != audit.CategoryWrite {
+		return nil, nil, nil
+	}
+	if len(id) > maxIDLength {
+		return nil, nil, ErrInvalidID
+	}
+	fingerprint := c.Cmd + "\x00" + strings.Join(c.Args, "\x00")
+
+	for {
+		mu.Lock()
+		purge(time.Now())
+		e, ok := entries[id]
+		if !ok {
+			e = &entry{fingerprint: fingerprint, done: make(chan struct{})}
+			entries[id] = e
+			mu.Unlock()
+			return nil, &Request{id: id, entry: e}, nil
+		}
+		mu.Unlock()
+
+		if e.fingerprint != fingerprint {
+			return nil, nil, ErrIDReused
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-e.done:
+		}
+		// The request abandoned is reserved again
+		if e.resp != nil {
+			return e.resp, nil, nil
+		}
+	}
+}
+
+// Complete keeps the response of the request for the window, for the retries of the request.
+func (r *Request) Complete(resp *eval.EvalResponse) {
+	if r == nil {
+		return
+	}
+	if resp == nil {
+		r.Abandon()
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	select {
+	case <-r.entry.done:
+		return
+	default:
+	}
+	r.entry.resp = resp
+	r.entry.expiresAt = time.Now().Add(config.DiceConfig().Idempotency.Window)
+	completed.PushBack(r.id)
+	close(r.entry.done)
+	purge(time.Now())
+}
+
+// Abandon releases the request id of a command that was not executed, e.g. refused as the server is
+// overloaded, so that a retry executes it. It does nothing once the request is completed.
+func (r *Request) Abandon() {
+	if r == nil {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	select {
+	case <-r.entry.done:
+		return
+	default:
+	}
+	delete(entries, r.id)
+	close(r.entry.done)
+}
+
+// purge drops the responses kept beyond the window, and the oldest ones beyond idempotency.max_requests
+func purge(now time.Time) {
+	limit := config.DiceConfig().Idempotency.MaxRequests
+	for elem := completed.Front(); elem != nil; elem = completed.Front() {
+		id := elem.Value.(string)
+		e := entries[id]
+		if now.Before(e.expiresAt) && completed.Len() <= limit {
+			return
+		}
+		completed.Remove(elem)
+		delete(entries, id)
+	}
+}
@@ -0,0 +1,119 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package idempotency
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().Idempotency.Enabled = true
+	t.Cleanup(func() {
+		config.DiceConfig().Idempotency.Enabled = false
+		mu.Lock()
+		entries = make(map[string]*entry)
+		completed.Init()
+		mu.Unlock()
+	})
+}
+
+func TestBegin(t *testing.T) {
+	setup(t)
+	ctx := context.Background()
+	incr := &cmd.DiceDBCmd{Cmd: "INCR", Args: []string{"k"}}
+
+	// The reads and the commands without a request id are not deduplicated
+	replay, req, err := Begin(ctx, "r1", &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}})
+	require.NoError(t, err)
+	assert.Nil(t, replay)
+	assert.Nil(t, req)
+	_, req, err = Begin(ctx, "", incr)
+	require.NoError(t, err)
+	assert.Nil(t, req)
+	_, _, err = Begin(ctx, strings.Repeat("x", maxIDLength+1), incr)
+	assert.ErrorIs(t, err, ErrInvalidID)
+
+	_, req, err = Begin(ctx, "r1", incr)
+	require.NoError(t, err)
+	require.NotNil(t, req)
+
+	// A retry received while the request is in progress waits for its response
+	retried := make(chan *eval.EvalResponse)
+	go func() {
+		replay, _, _ := Begin(ctx, "r1", incr)
+		retried <- replay
+	}()
+	time.Sleep(10 * time.Millisecond)
+	resp := &eval.EvalResponse{Result: int64(1)}
+	req.Complete(resp)
+	assert.Same(t, resp, <-retried)
+
+	replay, req, err = Begin(ctx, "r1", incr)
+	require.NoError(t, err)
+	assert.Nil(t, req)
+	assert.Same(t, resp, replay)
+
+	_, _, err = Begin(ctx, "r1", &cmd.DiceDBCmd{Cmd: "INCR", Args: []string{"other"}})
+	assert.ErrorIs(t, err, ErrIDReused)
+
+	// An abandoned request is executed by its retry
+	_, req, err = Begin(ctx, "r2", incr)
+	require.NoError(t, err)
+	req.Abandon()
+	replay, req, err = Begin(ctx, "r2", incr)
+	require.NoError(t, err)
+	assert.Nil(t, replay)
+	assert.NotNil(t, req)
+	req.Complete(resp)
+	req.Abandon()
+	replay, _, _ = Begin(ctx, "r2", incr)
+	assert.Same(t, resp, replay)
+}
+
+func TestPurge(t *testing.T) {
+	setup(t)
+	ctx := context.Background()
+	config.DiceConfig().Idempotency.MaxRequests = 2
+	incr := &cmd.DiceDBCmd{Cmd: "INCR", Args: []string{"k"}}
+
+	for _, id := range []string{"r1", "r2", "r3"} {
+		_, req, err := Begin(ctx, id, incr)
+		require.NoError(t, err)
+		req.Complete(&eval.EvalResponse{Result: id})
+	}
+	// The oldest response is dropped beyond max_requests
+	replay, _, _ := Begin(ctx, "r1", incr)
+	assert.Nil(t, replay)
+	replay, _, _ = Begin(ctx, "r3", incr)
+	assert.Equal(t, "r3", replay.Result)
+
+	// The responses are dropped once the window elapsed
+	purge(time.Now().Add(config.DiceConfig().Idempotency.Window))
+	mu.Lock()
+	assert.Zero(t, completed.Len())
+	mu.Unlock()
+}
@@ -0,0 +1,297 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/export"
+)
+
+// readerSize is the size of the buffer reading the imported stream.
+const readerSize = 1 << 20
+
+// errCorrupt is the error of the records whose checksum does not match, see export.Seal.
+var errCorrupt = diceerrors.ErrGeneral("corrupt record, its checksum does not match")
+
+// jsonDecoder decodes JSON Lines of export.Record, every record replacing its key.
+type jsonDecoder struct {
+	r *bufio.Reader
+	// sealed is set once a record sealed with its checksum is read: the records of a stream are all sealed,
+	// or none of them, so that a record whose checksum field is damaged is not read as an unsealed one
+	sealed bool
+}
+
+func newJSONDecoder(r io.Reader) *jsonDecoder {
+	return &jsonDecoder{r: bufio.NewReaderSize(r, readerSize)}
+}
+
+// record is an export.Record whose value is decoded once its type is known.
+type record struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	TTL   int64           `json:"ttl"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (d *jsonDecoder) next() (e entry, entryErr, err error) {
+	line, err := d.readLine()
+	if err != nil {
+		return e, nil, err
+	}
+
+	if export.IsSealed(line) {
+		d.sealed = true
+	} else if d.sealed {
+		return e, errCorrupt, nil
+	}
+	if line, err = export.Unseal(line); err != nil {
+		return e, errCorrupt, nil
+	}
+
+	rec := record{TTL: -1}
+	if err := sonic.Unmarshal(line, &rec); err != nil {
+		return e, diceerrors.ErrGeneral("invalid JSON record"), nil
+	}
+	if rec.Key == "" {
+		return e, diceerrors.ErrGeneral("the record has no key"), nil
+	}
+
+	cmds, err := rec.commands()
+	if err != nil {
+		return e, err, nil
+	}
+	return entry{keys: []string{rec.Key}, cmds: cmds}, nil, nil
+}
+
+func (d *jsonDecoder) skip() error {
+	line, err := d.readLine()
+	if err == nil && export.IsSealed(line) {
+		d.sealed = true
+	}
+	return err
+}
+
+// readLine returns the next line that is not blank.
+func (d *jsonDecoder) readLine() ([]byte, error) {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+			return nil, err
+		}
+		// Blank lines are not entries
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+	}
+}
+
+// commands returns the commands replacing the key of the record with its value and its TTL. The TTL of
+// the values other than strings and DUMP payloads is set with EXPIRE, rounded up to the second.
+func (rec *record) commands() ([]*cmd.DiceDBCmd, error) {
+	if rec.TTL == 0 {
+		// The key expired while it was exported
+		return []*cmd.DiceDBCmd{{Cmd: "DEL", Args: []string{rec.Key}}}, nil
+	}
+
+	var cmds []*cmd.DiceDBCmd
+	switch rec.Type {
+	case export.TypeString:
+		var value string
+		if err := sonic.Unmarshal(rec.Value, &value); err != nil {
+			return nil, invalidValue(rec.Type)
+		}
+		args := []string{rec.Key, value}
+		if rec.TTL > 0 {
+			args = append(args, "PX", strconv.FormatInt(rec.TTL, 10))
+		}
+		return []*cmd.DiceDBCmd{{Cmd: "SET", Args: args}}, nil
+
+	case export.TypeBloomFilter, export.TypeCMS:
+		var payload string
+		if err := sonic.Unmarshal(rec.Value, &payload); err != nil {
+			return nil, invalidValue(rec.Type)
+		}
+		return []*cmd.DiceDBCmd{{Cmd: "RESTORE", Args: []string{rec.Key, strconv.FormatInt(max(rec.TTL, 0), 10), payload}}}, nil
+
+	case export.TypeJSON:
+		cmds = []*cmd.DiceDBCmd{{Cmd: "JSON.SET", Args: []string{rec.Key, "$", string(rec.Value)}}}
+
+	case export.TypeList, export.TypeSet:
+		var elements []string
+		if err := sonic.Unmarshal(rec.Value, &elements); err != nil {
+			return nil, invalidValue(rec.Type)
+		}
+		cmds = []*cmd.DiceDBCmd{{Cmd: "DEL", Args: []string{rec.Key}}}
+		if len(elements) > 0 {
+			name := "RPUSH"
+			if rec.Type == export.TypeSet {
+				name = "SADD"
+			}
+			cmds = append(cmds, &cmd.DiceDBCmd{Cmd: name, Args: append([]string{rec.Key}, elements...)})
+		}
+
+	case export.TypeHash:
+		var fields map[string]string
+		if err := sonic.Unmarshal(rec.Value, &fields); err != nil {
+			return nil, invalidValue(rec.Type)
+		}
+		cmds = []*cmd.DiceDBCmd{{Cmd: "DEL", Args: []string{rec.Key}}}
+		if len(fields) > 0 {
+			args := make([]string, 0, 1+2*len(fields))
+			args = append(args, rec.Key)
+			for _, field := range slices.Sorted(maps.Keys(fields)) {
+				args = append(args, field, fields[field])
+			}
+			cmds = append(cmds, &cmd.DiceDBCmd{Cmd: "HSET", Args: args})
+		}
+
+	case export.TypeZSet:
+		var members []export.ZMember
+		if err := sonic.Unmarshal(rec.Value, &members); err != nil {
+			return nil, invalidValue(rec.Type)
+		}
+		cmds = []*cmd.DiceDBCmd{{Cmd: "DEL", Args: []string{rec.Key}}}
+		if len(members) > 0 {
+			args := make([]string, 0, 1+2*len(members))
+			args = append(args, rec.Key)
+			for _, member := range members {
+				args = append(args, strconv.FormatFloat(member.Score, 'g', -1, 64), member.Member)
+			}
+			cmds = append(cmds, &cmd.DiceDBCmd{Cmd: "ZADD", Args: args})
+		}
+
+	case export.TypeHyperLogLog:
+		return nil, diceerrors.ErrGeneral("hyperloglog values can not be imported")
+
+	default:
+		return nil, diceerrors.ErrGeneral("unknown type " + rec.Type)
+	}
+
+	if rec.TTL > 0 {
+		seconds := (rec.TTL + 999) / 1000
+		cmds = append(cmds, &cmd.DiceDBCmd{Cmd: "EXPIRE", Args: []string{rec.Key, strconv.FormatInt(seconds, 10)}})
+	}
+	return cmds, nil
+}
+
+func invalidValue(typ string) error {
+	return diceerrors.ErrGeneral("invalid value for type " + typ)
+}
+
+// respDecoder decodes commands encoded as RESP arrays of bulk strings.
+type respDecoder struct {
+	r *bufio.Reader
+}
+
+func newRESPDecoder(r io.Reader) *respDecoder {
+	return &respDecoder{r: bufio.NewReaderSize(r, readerSize)}
+}
+
+func (d *respDecoder) next() (e entry, entryErr, err error) {
+	args, err := d.readCommand()
+	if err != nil {
+		return e, nil, err
+	}
+
+	c := &cmd.DiceDBCmd{Cmd: strings.ToUpper(args[0]), Args: args[1:]}
+	// Only the commands executed by the shards can be imported, the others are split by the io-threads
+	keys := cmd.Keys(c)
+	if _, ok := eval.DiceCmds[c.Cmd]; !ok || len(keys) == 0 {
+		return e, fmt.Errorf("ERR '%s' does not write to keys and can not be imported", strings.ToLower(c.Cmd)), nil
+	}
+	return entry{keys: keys, cmds: []*cmd.DiceDBCmd{c}}, nil, nil
+}
+
+func (d *respDecoder) skip() error {
+	_, err := d.readCommand()
+	return err
+}
+
+// readCommand reads a RESP array of bulk strings. A stream that is not made of such arrays can not be
+// decoded any further, as the start of the next command can not be found.
+func (d *respDecoder) readCommand() ([]string, error) {
+	n, err := d.readLength('*')
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, errors.New("ERR invalid RESP stream: empty command")
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		size, err := d.readLength('$')
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if size < 0 {
+			return nil, errors.New("ERR invalid RESP stream: invalid bulk string length")
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if !bytes.HasSuffix(buf, []byte("\r\n")) {
+			return nil, errors.New("ERR invalid RESP stream: bulk string not terminated by CRLF")
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// readLength reads a line `<prefix><length>\r\n`.
+func (d *respDecoder) readLength(prefix byte) (int, error) {
+	line, err := d.r.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	if len(line) < 3 || line[0] != prefix || line[len(line)-2] != '\r' {
+		return 0, fmt.Errorf("ERR invalid RESP stream: expected '%c'", prefix)
+	}
+
+	n, err := strconv.Atoi(string(line[1 : len(line)-2]))
+	if err != nil {
+		return 0, fmt.Errorf("ERR invalid RESP stream: invalid length")
+	}
+	return n, nil
+}
+
+// unexpectedEOF reports the end of the stream in the middle of a command.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,538 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package importer implements the bulk import of keys, e.g. for the initial load of a dataset. The stream
+// imported is decoded into commands that are grouped by shard, every shard executing its commands by
+// batches of thousands in a single operation rather than going through the dispatch of every command.
+//
+// An import given an ID can be resumed: the position of the last entry applied is kept, and the entries
+// before it are skipped when the stream is sent again, so that an interrupted import is not applied twice.
+package importer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/gobwas/glob"
+)
+
+const (
+	// batchSize is the number of commands sent to the shards at once, across all of them
+	batchSize = 8192
+	// maxErrors is the number of errors reported by the progress of an import
+	maxErrors = 10
+	// progressInterval is the minimal time between two reports of the progress of an import
+	progressInterval = time.Second
+	// reportCheckInterval is the number of entries between two checks of the time elapsed since the last report
+	reportCheckInterval = 1024
+)
+
+// ErrInProgress is returned when an import is started with the ID of an import still running.
+var ErrInProgress = errors.New("ERR an import with this id is already running")
+
+// Format is the encoding of the imported stream.
+type Format string
+
+const (
+	// FormatJSON is a stream of JSON Lines, one record per line, as written by EXPORT
+	FormatJSON Format = "json"
+	// FormatRESP is a stream of commands encoded as RESP arrays of bulk strings, e.g. as sent by redis-cli --pipe
+	FormatRESP Format = "resp"
+)
+
+// Recovery is the handling of the corrupt records of a stream, the records sealed with a checksum that does
+// not match their content, as found in a backup damaged on disk or cut short by a crash.
+type Recovery string
+
+const (
+	// RecoveryIgnore skips the corrupt records, reported as failed, and imports the other ones
+	RecoveryIgnore Recovery = "ignore"
+	// RecoveryTruncate imports the records up to the first corrupt one, the records following it are discarded
+	RecoveryTruncate Recovery = "truncate"
+	// RecoveryStrict stops the import with ErrCorrupt at the first corrupt record, the records before it
+	// being imported
+	RecoveryStrict Recovery = "strict"
+)
+
+// ErrCorrupt is returned when an import in RecoveryStrict reads a corrupt record.
+var ErrCorrupt = errors.New("ERR the stream has a corrupt record")
+
+// ParseFormat returns the format named by s, JSON Lines if s is empty.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(strings.ToLower(s)); format {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatRESP:
+		return format, nil
+	default:
+		return "", diceerrors.ErrGeneral("unknown format " + s)
+	}
+}
+
+// ParseRecovery returns the recovery mode named by s, RecoveryIgnore if s is empty.
+func ParseRecovery(s string) (Recovery, error) {
+	switch recovery := Recovery(strings.ToLower(s)); recovery {
+	case "":
+		return RecoveryIgnore, nil
+	case RecoveryIgnore, RecoveryTruncate, RecoveryStrict:
+		return recovery, nil
+	default:
+		return "", diceerrors.ErrGeneral("unknown recovery mode " + s)
+	}
+}
+
+// Options describes an import.
+type Options struct {
+	// ID identifies the import so that it can be resumed, an import without ID can not be resumed
+	ID     string
+	Format Format
+	// Offset is the position in the whole stream of the first entry of the stream read, when the client
+	// resumes an import by sending the entries following the ones already applied only
+	Offset int64
+	// Recovery is the handling of the corrupt records, RecoveryIgnore when empty
+	Recovery Recovery
+	// Patterns are the glob-style patterns of the keys imported, the entries none of whose keys match any of
+	// them being skipped. All the entries are imported when empty.
+	Patterns []string
+}
+
+// Progress is the progress of an import. An entry is a line of JSON Lines or a command of RESP.
+type Progress struct {
+	ID        string   `json:"id,omitempty"`
+	Position  int64    `json:"position"`            // Position is the position in the whole stream of the first entry not applied yet
+	Applied   int64    `json:"applied"`             // Applied is the number of entries applied by this attempt
+	Skipped   int64    `json:"skipped"`             // Skipped is the number of entries skipped as applied by a previous attempt
+	Filtered  int64    `json:"filtered,omitempty"`  // Filtered is the number of entries skipped as their keys match none of the patterns
+	Failed    int64    `json:"failed"`              // Failed is the number of entries that could not be decoded or were refused by the shards
+	Corrupted int64    `json:"corrupted,omitempty"` // Corrupted is the number of entries failing their checksum, counted as failed too
+	Discarded int64    `json:"discarded,omitempty"` // Discarded is the number of entries following the first corrupt one in RecoveryTruncate
+	Errors    []string `json:"errors,omitempty"`
+	Elapsed   int64    `json:"elapsed_ms"`
+	Done      bool     `json:"done"`
+	Error     string   `json:"error,omitempty"` // Error is the error that stopped the import, if any
+}
+
+func (p *Progress) fail(position int64, err error) {
+	p.Failed++
+	if len(p.Errors) < maxErrors {
+		p.Errors = append(p.Errors, fmt.Sprintf("entry %d: %s", position, err))
+	}
+}
+
+// Shards executes the commands of an import.
+type Shards interface {
+	// Route returns the shard owning the key
+	Route(key string) uint8
+	// Exec executes the commands of every shard and returns their responses, in order
+	Exec(ctx context.Context, cmds map[uint8][]*cmd.DiceDBCmd) (map[uint8][]*eval.EvalResponse, error)
+}
+
+// entry is an entry of the imported stream decoded into the commands applying it, all on the same keys.
+type entry struct {
+	keys []string
+	cmds []*cmd.DiceDBCmd
+}
+
+// decoder reads the entries of an imported stream.
+type decoder interface {
+	// next returns the next entry of the stream and io.EOF at its end. An entry that can not be decoded
+	// is reported with entryErr, the error err means the stream can not be read any further.
+	next() (e entry, entryErr, err error)
+	// skip reads the next entry without decoding it, and returns io.EOF at the end of the stream
+	skip() error
+}
+
+// batch is the commands of a run of entries, grouped by shard.
+type batch struct {
+	cmds    map[uint8][]*cmd.DiceDBCmd
+	entries map[uint8][]int64 // entries[id][i] is the position of the entry of cmds[id][i]
+	size    int
+	end     int64 // end is the position following the last entry of the batch
+}
+
+func newBatch() *batch {
+	return &batch{cmds: make(map[uint8][]*cmd.DiceDBCmd), entries: make(map[uint8][]int64)}
+}
+
+// Run imports the stream read from r and returns the progress of the import once done. report is called
+// with the progress of the import every progressInterval, it stops the import if it returns an error.
+//
+// The batches are applied in order, the next one being decoded while the previous one is executed by the shards.
+// The position of an import with an ID is kept once every batch is applied.
+func Run(ctx context.Context, r io.Reader, shards Shards, opts Options, report func(Progress) error) (Progress, error) {
+	start := time.Now()
+	progress := Progress{ID: opts.ID, Position: opts.Offset}
+
+	globs, err := compilePatterns(opts.Patterns)
+	if err != nil {
+		return progress, err
+	}
+
+	resumeAt := int64(0)
+	if opts.ID != "" {
+		if resumeAt, err = begin(opts.ID); err != nil {
+			return progress, err
+		}
+		defer func() { end(progress) }()
+	}
+
+	dec := newDecoder(r, opts.Format)
+
+	// inflight receives the outcome of the batch executed by the shards, if any
+	var inflight chan outcome
+	wait := func() error {
+		if inflight == nil {
+			return nil
+		}
+		o := <-inflight
+		inflight = nil
+		if o.err != nil {
+			return o.err
+		}
+		progress.Applied += o.applied
+		for _, f := range o.failed {
+			progress.fail(f.position, f.err)
+		}
+		progress.Position = o.end
+		checkpoint(progress)
+		return nil
+	}
+	flush := func(b *batch) error {
+		if err := wait(); err != nil {
+			return err
+		}
+		inflight = make(chan outcome, 1)
+		go func() {
+			// The shards always execute a batch once sent, waiting for it keeps the position exact
+			inflight <- execBatch(context.WithoutCancel(ctx), shards, b)
+		}()
+		return nil
+	}
+
+	lastReport := start
+	stop := func(err error, pending *batch) (Progress, error) {
+		// The entries read before the stream was interrupted are applied, so that they are not sent again
+		if pending != nil {
+			if flushErr := flush(pending); flushErr != nil {
+				err = flushErr
+			}
+		}
+		// The batch in flight is applied whatever the error, it can not be withdrawn from the shards
+		if waitErr := wait(); err == nil {
+			err = waitErr
+		}
+		progress.Elapsed = time.Since(start).Milliseconds()
+		progress.Done = err == nil
+		if err != nil {
+			progress.Error = err.Error()
+		}
+		return progress, err
+	}
+
+	position := opts.Offset
+	b := newBatch()
+	for ; ; position++ {
+		if err := ctx.Err(); err != nil {
+			return stop(err, b)
+		}
+
+		if position < resumeAt {
+			err := dec.skip()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return stop(err, b)
+			}
+			progress.Skipped++
+		} else {
+			e, entryErr, err := dec.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return stop(err, b)
+			}
+
+			if entryErr == errCorrupt {
+				progress.Corrupted++
+				progress.fail(position, entryErr)
+				switch opts.Recovery {
+				case RecoveryStrict:
+					b.end = position
+					return stop(fmt.Errorf("%w at entry %d", ErrCorrupt, position), b)
+				case RecoveryTruncate:
+					// The position stays at the corrupt entry, the first one not imported
+					b.end = position
+					if progress.Discarded, err = discard(dec); err != nil {
+						return stop(err, b)
+					}
+					return stop(nil, b)
+				}
+			} else if entryErr == nil && !matches(globs, e.keys) {
+				progress.Filtered++
+			} else {
+				if entryErr == nil {
+					entryErr = b.add(shards, position, e)
+				}
+				if entryErr != nil {
+					progress.fail(position, entryErr)
+				}
+			}
+		}
+		b.end = position + 1
+
+		if b.size >= batchSize {
+			if err := flush(b); err != nil {
+				return stop(err, nil)
+			}
+			b = newBatch()
+		}
+
+		if position%reportCheckInterval == 0 && time.Since(lastReport) >= progressInterval {
+			lastReport = time.Now()
+			progress.Elapsed = time.Since(start).Milliseconds()
+			if err := report(progress); err != nil {
+				return stop(err, b)
+			}
+		}
+	}
+
+	return stop(nil, b)
+}
+
+// Keys returns the keys of the entries of the stream read from r that Run would import with the options, e.g. to
+// know which keys are still missing while the stream is imported. The entries that can not be decoded are skipped.
+func Keys(ctx context.Context, r io.Reader, opts Options) ([]string, error) {
+	globs, err := compilePatterns(opts.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := newDecoder(r, opts.Format)
+	var keys []string
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		e, entryErr, err := dec.next()
+		if errors.Is(err, io.EOF) {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entryErr == nil && matches(globs, e.keys) {
+			keys = append(keys, e.keys...)
+		}
+	}
+}
+
+func newDecoder(r io.Reader, format Format) decoder {
+	if format == FormatRESP {
+		return newRESPDecoder(r)
+	}
+	return newJSONDecoder(r)
+}
+
+// compilePatterns compiles the patterns of the keys imported, nil when all the keys are imported.
+func compilePatterns(patterns []string) ([]glob.Glob, error) {
+	var globs []glob.Glob
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return nil, nil
+		}
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, diceerrors.ErrGeneral(fmt.Sprintf("invalid pattern %q", pattern))
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// matches reports whether any of the keys matches any of the patterns, always when there are none.
+func matches(globs []glob.Glob, keys []string) bool {
+	if globs == nil {
+		return true
+	}
+	for _, key := range keys {
+		for _, g := range globs {
+			if g.Match(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// discard reads the remaining entries of the stream and returns their number.
+func discard(dec decoder) (int64, error) {
+	n := int64(0)
+	for {
+		err := dec.skip()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// add adds the commands of the entry to the batch, all of them being executed by the shard owning its keys.
+func (b *batch) add(shards Shards, position int64, e entry) error {
+	id := shards.Route(e.keys[0])
+	for _, key := range e.keys[1:] {
+		if shards.Route(key) != id {
+			return diceerrors.ErrGeneral("the keys of the command are owned by different shards")
+		}
+	}
+
+	for _, c := range e.cmds {
+		b.cmds[id] = append(b.cmds[id], c)
+		b.entries[id] = append(b.entries[id], position)
+	}
+	b.size += len(e.cmds)
+	return nil
+}
+
+// outcome is the outcome of the execution of a batch.
+type outcome struct {
+	applied int64
+	failed  []failure // failed are the entries refused by the shards, ordered by position
+	end     int64
+	err     error
+}
+
+type failure struct {
+	position int64
+	err      error
+}
+
+// execBatch executes the batch on the shards and returns its outcome.
+func execBatch(ctx context.Context, shards Shards, b *batch) outcome {
+	o := outcome{end: b.end}
+	if b.size == 0 {
+		return o
+	}
+
+	resps, err := shards.Exec(ctx, b.cmds)
+	if err != nil {
+		o.err = err
+		return o
+	}
+
+	// An entry fails if any of its commands fails
+	failed := make(map[int64]error)
+	entries := make(map[int64]struct{})
+	for id, shardResps := range resps {
+		for i, resp := range shardResps {
+			position := b.entries[id][i]
+			entries[position] = struct{}{}
+			if err := resp.Error; err != nil {
+				failed[position] = err
+			} else if err := audit.ResponseError(resp.Result); err != nil {
+				failed[position] = err
+			}
+		}
+	}
+
+	o.applied = int64(len(entries) - len(failed))
+	for position, err := range failed {
+		o.failed = append(o.failed, failure{position: position, err: err})
+	}
+	slices.SortFunc(o.failed, func(a, b failure) int {
+		return cmp.Compare(a.position, b.position)
+	})
+	return o
+}
+
+// The imports given an ID are kept for the process lifetime, so that they can be resumed and their progress queried.
+var (
+	mu      sync.Mutex
+	imports = make(map[string]*state)
+)
+
+type state struct {
+	progress Progress
+	running  bool
+}
+
+// begin marks the import as running and returns the position from which it is resumed.
+func begin(id string) (int64, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, ok := imports[id]
+	if !ok {
+		s = &state{progress: Progress{ID: id}}
+		imports[id] = s
+	}
+	if s.running {
+		return 0, ErrInProgress
+	}
+	s.running = true
+	return s.progress.Position, nil
+}
+
+// checkpoint records the progress of a running import.
+func checkpoint(progress Progress) {
+	if progress.ID == "" {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	s := imports[progress.ID]
+	// An attempt sending the stream from an earlier offset does not move the position back
+	progress.Position = max(progress.Position, s.progress.Position)
+	progress.Errors = append([]string(nil), progress.Errors...)
+	s.progress = progress
+}
+
+// end records the last progress of the import and marks it as stopped.
+func end(progress Progress) {
+	checkpoint(progress)
+
+	mu.Lock()
+	defer mu.Unlock()
+	imports[progress.ID].running = false
+}
+
+// Status returns the progress of the last attempt of the import with the ID, if any.
+func Status(id string) (Progress, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, ok := imports[id]
+	if !ok {
+		return Progress{}, false
+	}
+	return s.progress, true
+}
@@ -0,0 +1,285 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/export"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeShards routes the keys on two shards by their first byte, and records the commands executed.
+// The commands on the key "bad" fail.
+type fakeShards struct {
+	executed []string
+}
+
+func (s *fakeShards) Route(key string) uint8 {
+	if key == "" {
+		return 0
+	}
+	return key[0] % 2
+}
+
+func (s *fakeShards) Exec(_ context.Context, cmds map[uint8][]*cmd.DiceDBCmd) (map[uint8][]*eval.EvalResponse, error) {
+	resps := make(map[uint8][]*eval.EvalResponse)
+	for id := uint8(0); id < 2; id++ {
+		for _, c := range cmds[id] {
+			s.executed = append(s.executed, c.Repr())
+			resp := &eval.EvalResponse{Result: "OK"}
+			if len(c.Args) > 0 && c.Args[0] == "bad" {
+				resp = &eval.EvalResponse{Error: errors.New("ERR refused")}
+			}
+			resps[id] = append(resps[id], resp)
+		}
+	}
+	return resps, nil
+}
+
+func noReport(Progress) error { return nil }
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	format, err = ParseFormat("RESP")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatRESP, format)
+
+	_, err = ParseFormat("csv")
+	assert.EqualError(t, err, "ERR unknown format csv")
+}
+
+func TestParseRecovery(t *testing.T) {
+	recovery, err := ParseRecovery("")
+	assert.NoError(t, err)
+	assert.Equal(t, RecoveryIgnore, recovery)
+
+	recovery, err = ParseRecovery("Truncate")
+	assert.NoError(t, err)
+	assert.Equal(t, RecoveryTruncate, recovery)
+
+	_, err = ParseRecovery("repair")
+	assert.EqualError(t, err, "ERR unknown recovery mode repair")
+}
+
+func TestRecordCommands(t *testing.T) {
+	tests := map[string]struct {
+		line     string
+		expected []string
+		err      string
+	}{
+		"string with TTL":     {line: `{"key":"k","type":"string","ttl":1500,"value":"v"}`, expected: []string{"SET k v PX 1500"}},
+		"string without TTL":  {line: `{"key":"k","type":"string","value":"v"}`, expected: []string{"SET k v"}},
+		"expired key":         {line: `{"key":"k","type":"string","ttl":0,"value":"v"}`, expected: []string{"DEL k"}},
+		"list with TTL":       {line: `{"key":"k","type":"list","ttl":1001,"value":["a","b"]}`, expected: []string{"DEL k", "RPUSH k a b", "EXPIRE k 2"}},
+		"empty set":           {line: `{"key":"k","type":"set","ttl":-1,"value":[]}`, expected: []string{"DEL k"}},
+		"hash":                {line: `{"key":"k","type":"hash","ttl":-1,"value":{"b":"2","a":"1"}}`, expected: []string{"DEL k", "HSET k a 1 b 2"}},
+		"sorted set":          {line: `{"key":"k","type":"zset","ttl":-1,"value":[{"member":"m","score":1.5}]}`, expected: []string{"DEL k", "ZADD k 1.5 m"}},
+		"JSON":                {line: `{"key":"k","type":"json","ttl":-1,"value":{"a":[1,2]}}`, expected: []string{`JSON.SET k $ {"a":[1,2]}`}},
+		"bloom filter":        {line: `{"key":"k","type":"bloomfilter","ttl":2000,"value":"cGF5bG9hZA=="}`, expected: []string{"RESTORE k 2000 cGF5bG9hZA=="}},
+		"hyperloglog":         {line: `{"key":"k","type":"hyperloglog","ttl":-1,"value":"aGxs"}`, err: "ERR hyperloglog values can not be imported"},
+		"unknown type":        {line: `{"key":"k","type":"stream","ttl":-1,"value":[]}`, err: "ERR unknown type stream"},
+		"value of wrong type": {line: `{"key":"k","type":"list","ttl":-1,"value":"a"}`, err: "ERR invalid value for type list"},
+		"record without key":  {line: `{"type":"string","value":"v"}`, err: "ERR the record has no key"},
+		"record not JSON":     {line: `key,string,-1,v`, err: "ERR invalid JSON record"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, entryErr, err := newJSONDecoder(strings.NewReader(tc.line)).next()
+			assert.NoError(t, err)
+			if tc.err != "" {
+				assert.EqualError(t, entryErr, tc.err)
+				return
+			}
+			assert.NoError(t, entryErr)
+			assert.Equal(t, []string{"k"}, e.keys)
+
+			cmds := make([]string, 0, len(e.cmds))
+			for _, c := range e.cmds {
+				cmds = append(cmds, c.Repr())
+			}
+			assert.Equal(t, tc.expected, cmds)
+		})
+	}
+}
+
+func TestRESPDecoder(t *testing.T) {
+	dec := newRESPDecoder(strings.NewReader("*3\r\n$3\r\nset\r\n$1\r\nk\r\n$4\r\nv\r\nw\r\n*1\r\n$4\r\nPING\r\n*2\r\n$3\r\nGET\r\n"))
+
+	e, entryErr, err := dec.next()
+	assert.NoError(t, err)
+	assert.NoError(t, entryErr)
+	assert.Equal(t, []string{"k"}, e.keys)
+	assert.Equal(t, "SET", e.cmds[0].Cmd)
+	assert.Equal(t, []string{"k", "v\r\nw"}, e.cmds[0].Args)
+
+	_, entryErr, err = dec.next()
+	assert.NoError(t, err)
+	assert.EqualError(t, entryErr, "ERR 'ping' does not write to keys and can not be imported")
+
+	_, _, err = dec.next()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, _, err = newRESPDecoder(strings.NewReader("SET k v\r\n")).next()
+	assert.EqualError(t, err, "ERR invalid RESP stream: expected '*'")
+}
+
+func TestRun(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"key":"a","type":"string","ttl":-1,"value":"1"}`,
+		`{"key":"b","type":"list","ttl":-1,"value":["x"]}`,
+		``,
+		`{"key":"bad","type":"string","ttl":-1,"value":"2"}`,
+		`not json`,
+		`{"key":"c","type":"string","ttl":-1,"value":"3"}`,
+	}, "\n")
+
+	shards := &fakeShards{}
+	progress, err := Run(context.Background(), strings.NewReader(stream), shards, Options{Format: FormatJSON}, noReport)
+	assert.NoError(t, err)
+	assert.True(t, progress.Done)
+	assert.Equal(t, int64(5), progress.Position)
+	assert.Equal(t, int64(3), progress.Applied)
+	assert.Equal(t, int64(2), progress.Failed)
+	// The entries that can not be decoded are reported before the ones refused by the shards
+	assert.Equal(t, []string{"entry 3: ERR invalid JSON record", "entry 2: ERR refused"}, progress.Errors)
+	// b and bad are owned by shard 0, a and c by shard 1
+	assert.Equal(t, []string{"DEL b", "RPUSH b x", "SET bad 2", "SET a 1", "SET c 3"}, shards.executed)
+}
+
+func TestRunRecovery(t *testing.T) {
+	damaged := []byte(export.Seal(`{"key":"b","type":"string","ttl":-1,"value":"2"}`))
+	damaged[len(damaged)-3] = '9'
+	stream := strings.Join([]string{
+		export.Seal(`{"key":"a","type":"string","ttl":-1,"value":"1"}`),
+		string(damaged),
+		export.Seal(`{"key":"c","type":"string","ttl":-1,"value":"3"}`),
+		// A record whose checksum field is damaged is not read as an unsealed one
+		`{"crx":"00000000","key":"d","type":"string","ttl":-1,"value":"4"}`,
+		export.Seal(`{"key":"e","type":"string","ttl":-1,"value":"5"}`),
+	}, "\n")
+
+	shards := &fakeShards{}
+	progress, err := Run(context.Background(), strings.NewReader(stream), shards, Options{Format: FormatJSON}, noReport)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), progress.Applied)
+	assert.Equal(t, int64(2), progress.Corrupted)
+	assert.Equal(t, int64(2), progress.Failed)
+	assert.Equal(t, []string{"entry 1: ERR corrupt record, its checksum does not match",
+		"entry 3: ERR corrupt record, its checksum does not match"}, progress.Errors)
+	assert.Equal(t, []string{"SET a 1", "SET c 3", "SET e 5"}, shards.executed)
+
+	shards = &fakeShards{}
+	progress, err = Run(context.Background(), strings.NewReader(stream), shards, Options{Format: FormatJSON, Recovery: RecoveryTruncate}, noReport)
+	assert.NoError(t, err)
+	assert.True(t, progress.Done)
+	assert.Equal(t, int64(1), progress.Applied)
+	assert.Equal(t, int64(1), progress.Position, "the position is the one of the corrupt record")
+	assert.Equal(t, int64(1), progress.Corrupted)
+	assert.Equal(t, int64(3), progress.Discarded)
+	assert.Equal(t, []string{"SET a 1"}, shards.executed)
+
+	shards = &fakeShards{}
+	progress, err = Run(context.Background(), strings.NewReader(stream), shards, Options{Format: FormatJSON, Recovery: RecoveryStrict}, noReport)
+	assert.ErrorIs(t, err, ErrCorrupt)
+	assert.False(t, progress.Done)
+	assert.Equal(t, int64(1), progress.Applied)
+	assert.Equal(t, int64(1), progress.Position)
+	assert.Equal(t, []string{"SET a 1"}, shards.executed)
+}
+
+func TestRunPatterns(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"key":"user:1","type":"string","ttl":-1,"value":"1"}`,
+		`{"key":"cart:1","type":"string","ttl":-1,"value":"2"}`,
+		`{"key":"session:1","type":"string","ttl":-1,"value":"3"}`,
+	}, "\n")
+
+	shards := &fakeShards{}
+	opts := Options{Format: FormatJSON, Patterns: []string{"user:*", "session:*"}}
+	progress, err := Run(context.Background(), strings.NewReader(stream), shards, opts, noReport)
+	assert.NoError(t, err)
+	assert.True(t, progress.Done)
+	assert.Equal(t, int64(3), progress.Position)
+	assert.Equal(t, int64(2), progress.Applied)
+	assert.Equal(t, int64(1), progress.Filtered)
+	assert.ElementsMatch(t, []string{"SET user:1 1", "SET session:1 3"}, shards.executed)
+
+	_, err = Run(context.Background(), strings.NewReader(stream), &fakeShards{}, Options{Patterns: []string{"user:["}}, noReport)
+	assert.EqualError(t, err, `ERR invalid pattern "user:["`)
+
+	// The keys of the entries imported are known before importing them
+	keys, err := Keys(context.Background(), strings.NewReader(stream+"\nnot json"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1", "session:1"}, keys)
+}
+
+func TestRunResume(t *testing.T) {
+	first := "*3\r\n$3\r\nSET\r\n$1\r\na\r\n$1\r\n1\r\n"
+	second := "*3\r\n$3\r\nSET\r\n$1\r\nb\r\n$1\r\n2\r\n"
+	stream := first + second
+	cut := len(stream) - 5
+
+	// The first attempt is interrupted in the middle of the second command
+	shards := &fakeShards{}
+	progress, err := Run(context.Background(), strings.NewReader(stream[:cut]), shards, Options{ID: "resume", Format: FormatRESP}, noReport)
+	assert.Error(t, err)
+	assert.False(t, progress.Done)
+	assert.Equal(t, int64(1), progress.Position)
+	assert.Equal(t, []string{"SET a 1"}, shards.executed)
+
+	status, ok := Status("resume")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), status.Position)
+
+	// Sending the remaining commands only, from their offset, skips nothing
+	shards = &fakeShards{}
+	progress, err = Run(context.Background(), strings.NewReader(second), shards, Options{ID: "resume", Format: FormatRESP, Offset: 1}, noReport)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), progress.Skipped)
+	assert.Equal(t, int64(1), progress.Applied)
+	assert.Equal(t, int64(2), progress.Position)
+	assert.Equal(t, []string{"SET b 2"}, shards.executed)
+
+	// Sending the whole stream again skips the commands applied
+	shards = &fakeShards{}
+	progress, err = Run(context.Background(), strings.NewReader(stream), shards, Options{ID: "resume", Format: FormatRESP}, noReport)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), progress.Skipped)
+	assert.Equal(t, int64(0), progress.Applied)
+	assert.Equal(t, int64(2), progress.Position)
+	assert.Empty(t, shards.executed)
+}
+
+func TestRunInProgress(t *testing.T) {
+	_, err := begin("running")
+	assert.NoError(t, err)
+	defer end(Progress{ID: "running"})
+
+	_, err = Run(context.Background(), strings.NewReader(""), &fakeShards{}, Options{ID: "running"}, noReport)
+	assert.Equal(t, ErrInProgress, err)
+}
@@ -0,0 +1,186 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package inproc executes commands from Go calls rather than from network connections. Every session runs
+// an io-thread, exactly like a RESP connection, so that the commands executed in-process share the semantics
+// of the RESP clients: transactions, authentication and watch subscriptions. It backs the embedded engine and
+// the frontends that do not speak RESP, e.g. gRPC.
+package inproc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dicedb/dice/internal/iothread"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dice/internal/watchmanager"
+)
+
+// maxIdleSessions is the number of sessions kept by a host for Execute, the sessions beyond are closed
+const maxIdleSessions = 64
+
+var (
+	// ErrClosed is returned once the host or the session is closed
+	ErrClosed = errors.New("engine closed")
+	// ErrWatchDisabled is returned by Watch when the watch of commands is not enabled in the config
+	ErrWatchDisabled = errors.New("watch is disabled, set performance.enable_watch")
+)
+
+// Host runs the sessions on the shards of a shard manager.
+type Host struct {
+	prefix                   string // prefix of the ids of the io-threads of the sessions
+	addr                     string // addr is the address of the client reported by the sessions
+	shardManager             *shard.ShardManager
+	ioThreadManager          *iothread.Manager
+	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription
+	errChan                  chan error
+	wl                       wal.AbstractWAL
+	ctx                      context.Context
+	wg                       sync.WaitGroup
+	sessionCounter           atomic.Uint64
+
+	mu        sync.Mutex
+	idle      map[string][]*Session // idle are the sessions kept for Execute, by pool
+	idleCount int
+	closed    bool
+}
+
+// NewHost returns a host running its sessions until the context is canceled. The ids of the io-threads of
+// the sessions start with the prefix, and the sessions report addr as the address of their client. The
+// subscription channel of the watch manager is nil when the watch of commands is disabled.
+func NewHost(ctx context.Context, prefix, addr string, shardManager *shard.ShardManager, ioThreadManager *iothread.Manager,
+	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription, errChan chan error, wl wal.AbstractWAL) *Host {
+	return &Host{
+		prefix:                   prefix,
+		addr:                     addr,
+		shardManager:             shardManager,
+		ioThreadManager:          ioThreadManager,
+		cmdWatchSubscriptionChan: cmdWatchSubscriptionChan,
+		errChan:                  errChan,
+		wl:                       wl,
+		ctx:                      ctx,
+		idle:                     make(map[string][]*Session),
+	}
+}
+
+// Execute executes a command on a session of the default pool, see Session.Execute for the replies.
+func (h *Host) Execute(ctx context.Context, command string, args ...string) (interface{}, error) {
+	s, _, err := h.Acquire("")
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.Execute(ctx, command, args...)
+	h.Release("", s)
+	return reply, err
+}
+
+// NewSession opens a session, the equivalent of a client connection.
+func (h *Host) NewSession() (*Session, error) {
+	h.mu.Lock()
+	closed := h.closed
+	h.mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	id := fmt.Sprintf("%s-%d", h.prefix, h.sessionCounter.Add(1))
+	s := newSession(id, h)
+	if err := h.ioThreadManager.RegisterIOThread(s.thread); err != nil {
+		return nil, err
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		s.run(h.ctx, h)
+	}()
+	return s, nil
+}
+
+// Acquire returns an idle session of the pool, or a new one, fresh then being true. The sessions of a pool
+// share a connection state, e.g. the authenticated user, which is set up by the caller on fresh sessions.
+func (h *Host) Acquire(pool string) (s *Session, fresh bool, err error) {
+	h.mu.Lock()
+	if n := len(h.idle[pool]); n > 0 {
+		s = h.idle[pool][n-1]
+		h.idle[pool] = h.idle[pool][:n-1]
+		if n == 1 {
+			delete(h.idle, pool)
+		}
+		h.idleCount--
+		h.mu.Unlock()
+		return s, false, nil
+	}
+	h.mu.Unlock()
+
+	s, err = h.NewSession()
+	return s, true, err
+}
+
+// Release keeps the session in its pool for the next Acquire, unless it is no longer usable or enough
+// sessions are kept.
+func (h *Host) Release(pool string, s *Session) {
+	if !s.reusable() {
+		_ = s.Close()
+		return
+	}
+
+	h.mu.Lock()
+	if !h.closed && h.idleCount < maxIdleSessions {
+		h.idle[pool] = append(h.idle[pool], s)
+		h.idleCount++
+		h.mu.Unlock()
+		return
+	}
+	h.mu.Unlock()
+	_ = s.Close()
+}
+
+// Watch subscribes to the result of a command on a new session, see Session.Watch.
+func (h *Host) Watch(ctx context.Context, command string, args ...string) (*Subscription, error) {
+	s, err := h.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	return s.Watch(ctx, command, args...)
+}
+
+// Close refuses new sessions, closes the idle ones and waits for the others to stop, which they do once
+// closed by their users or once the context of the host is canceled.
+func (h *Host) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		h.wg.Wait()
+		return
+	}
+	h.closed = true
+	idle := h.idle
+	h.idle, h.idleCount = nil, 0
+	h.mu.Unlock()
+
+	for _, sessions := range idle {
+		for _, s := range sessions {
+			_ = s.Close()
+		}
+	}
+	h.wg.Wait()
+}
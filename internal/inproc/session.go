@@ -0,0 +1,245 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package inproc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/dicedb/dice/internal/clientio"
+	respparser "github.com/dicedb/dice/internal/clientio/requestparser/resp"
+	"github.com/dicedb/dice/internal/iothread"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// Error is an error replied by a command, e.g. a wrong number of arguments or an operation against a key
+// holding the wrong type, as opposed to the errors of the host itself.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Session is the equivalent of a client connection, the commands executed through it share the state of the
+// connection. A session executes one command at a time, the calls of Execute are serialized.
+type Session struct {
+	id      string
+	thread  *iothread.BaseIOThread
+	handler *pipe
+	watch   bool          // watch tells whether commands can be watched
+	done    chan struct{} // done is closed once the io-thread stopped
+
+	mu     sync.Mutex
+	broken bool // broken is set once a reply was abandoned, the following replies would be out of step
+}
+
+func newSession(id string, h *Host) *Session {
+	handler := newPipe(h.addr)
+	thread := iothread.NewIOThread(id, make(chan *ops.StoreResponse), make(chan *ops.StoreResponse),
+		h.cmdWatchSubscriptionChan, handler, respparser.NewParser(), h.shardManager, h.errChan, h.wl)
+	return &Session{
+		id:      id,
+		thread:  thread,
+		handler: handler,
+		watch:   h.cmdWatchSubscriptionChan != nil,
+		done:    make(chan struct{}),
+	}
+}
+
+// run runs the io-thread of the session until the session or the host is closed
+func (s *Session) run(ctx context.Context, h *Host) {
+	defer close(s.done)
+	defer func() {
+		if err := h.ioThreadManager.UnregisterIOThread(s.id); err != nil {
+			slog.Warn("Failed to unregister io-thread", slog.String("id", s.id), slog.Any("error", err))
+		}
+	}()
+
+	if err := s.thread.Start(ctx); err != nil {
+		slog.Debug("IOThread stopped", slog.String("id", s.id), slog.Any("error", err))
+	}
+}
+
+// Execute executes a command in the session, e.g. Execute(ctx, "SET", "k", "v"), and returns its reply. The
+// reply is nil, a string, an int64 or a []interface{} of those. An error replied by the command is returned
+// as an Error, the errors nested in an array, e.g. in the reply of EXEC, are Error values of the array.
+func (s *Session) Execute(ctx context.Context, command string, args ...string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken {
+		return nil, ErrClosed
+	}
+
+	reply, err := s.roundTrip(ctx, encodeCommand(command, args))
+	if err != nil {
+		return nil, err
+	}
+	return decodeReply(reply)
+}
+
+// roundTrip sends a command to the io-thread and waits for its reply, as written by the io-thread
+func (s *Session) roundTrip(ctx context.Context, request []byte) (interface{}, error) {
+	select {
+	case s.handler.requests <- request:
+	case <-s.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case reply := <-s.handler.replies:
+		return reply, nil
+	case <-s.done:
+		s.broken = true
+		return nil, ErrClosed
+	case <-ctx.Done():
+		// The reply may still come, the session can't be used anymore
+		s.broken = true
+		_ = s.handler.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// reusable tells whether the session can execute more commands
+func (s *Session) reusable() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return false
+	default:
+		return !s.broken
+	}
+}
+
+// Close closes the session and waits for its io-thread to stop.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	s.broken = true
+	s.mu.Unlock()
+
+	_ = s.handler.Close()
+	<-s.done
+	return nil
+}
+
+// pipe is the io handler of a session: it hands the commands to the io-thread and the replies back, without
+// encoding the replies, so that they are converted to Go values rather than parsed from RESP.
+type pipe struct {
+	addr      string
+	requests  chan []byte
+	replies   chan interface{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newPipe(addr string) *pipe {
+	return &pipe{
+		addr:     addr,
+		requests: make(chan []byte),
+		replies:  make(chan interface{}),
+		closed:   make(chan struct{}),
+	}
+}
+
+func (p *pipe) Read(ctx context.Context) ([]byte, error) {
+	select {
+	case request := <-p.requests:
+		return request, nil
+	case <-p.closed:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *pipe) Write(ctx context.Context, response interface{}) error {
+	select {
+	case p.replies <- response:
+		return nil
+	case <-p.closed:
+		return net.ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *pipe) RemoteAddr() string {
+	return p.addr
+}
+
+func (p *pipe) Close() error {
+	p.closeOnce.Do(func() { close(p.closed) })
+	return nil
+}
+
+// encodeCommand encodes a command as a RESP array of bulk strings, the way the clients send it
+func encodeCommand(command string, args []string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "*%d\r\n$%d\r\n%s\r\n", len(args)+1, len(command), command)
+	for _, arg := range args {
+		fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return buf.Bytes()
+}
+
+// decodeReply converts a reply written by an io-thread to a Go value, normalized like the other frontends
+// normalize the replies. An error reply is returned as an Error.
+func decodeReply(reply interface{}) (interface{}, error) {
+	r := clientio.NewReply(reply)
+	if r.Kind == clientio.ReplyError {
+		return nil, Error(r.Str)
+	}
+	return goValue(r), nil
+}
+
+// goValue renders a reply as nil, a string, an int64 or a []interface{} of those, the errors nested in an
+// array being Error values. The doubles and the booleans are rendered like RESP renders them.
+func goValue(r clientio.Reply) interface{} {
+	switch r.Kind {
+	case clientio.ReplySimpleString, clientio.ReplyBulkString:
+		return r.Str
+	case clientio.ReplyError:
+		return Error(r.Str)
+	case clientio.ReplyInteger:
+		return r.Int
+	case clientio.ReplyDouble:
+		if i, ok := utils.IsFloatToIntPossible(r.Float); ok {
+			return int64(i)
+		}
+		return strconv.FormatFloat(r.Float, 'f', -1, 64)
+	case clientio.ReplyBoolean:
+		return strconv.FormatBool(r.Bool)
+	case clientio.ReplyArray, clientio.ReplyMap:
+		elems := make([]interface{}, len(r.Elems))
+		for i, elem := range r.Elems {
+			elems[i] = goValue(elem)
+		}
+		return elems
+	default:
+		return nil
+	}
+}
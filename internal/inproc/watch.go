@@ -0,0 +1,168 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package inproc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Update is a push of a watched command, sent once the command is subscribed and then every time its result
+// may have changed.
+type Update struct {
+	// Seq is the sequence number of the push, starting at 0 with the initial result. A gap between the
+	// sequence numbers of two updates means that updates were dropped, as the subscriber was too slow.
+	Seq    uint64
+	Result interface{}
+	Err    error // Err is the error replied by the command, as an Error
+}
+
+// Subscription is a watched command. Its updates are received on C, which is closed once the subscription
+// or the host is closed.
+type Subscription struct {
+	C <-chan Update
+
+	session     *Session
+	command     string
+	fingerprint string
+	updates     chan Update
+	acks        chan interface{} // acks are the replies of the commands sent by the subscription after the watch
+	closing     chan struct{}    // closing is closed by Close, the pending updates are then dropped
+	closeOnce   sync.Once
+}
+
+// Watch subscribes to the result of a command, e.g. Watch(ctx, "GET", "k"). The first update is the current
+// result of the command. The updates are dropped rather than queued while the subscriber is not receiving
+// them, so that a slow subscriber does not hold up the server.
+//
+// The session is dedicated to the subscription from then on: it must not execute other commands, and it is
+// closed along with the subscription, or right away if the command can not be watched.
+func (s *Session) Watch(ctx context.Context, command string, args ...string) (*Subscription, error) {
+	if !s.watch {
+		_ = s.Close()
+		return nil, ErrWatchDisabled
+	}
+
+	s.mu.Lock()
+	reply, err := s.roundTrip(ctx, encodeCommand(command+".WATCH", args))
+	s.mu.Unlock()
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+
+	push, ok := reply.([]interface{})
+	if !ok || len(push) != 4 {
+		_ = s.Close()
+		if _, err := decodeReply(reply); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s can't be watched", command)
+	}
+
+	updates := make(chan Update, 1)
+	sub := &Subscription{
+		C:           updates,
+		session:     s,
+		command:     command,
+		fingerprint: fmt.Sprint(push[1]),
+		updates:     updates,
+		acks:        make(chan interface{}, 1),
+		closing:     make(chan struct{}),
+	}
+	updates <- newUpdate(push)
+	go sub.forward()
+
+	// The io-thread subscribes once the initial result is written, a PING executed after it makes sure that
+	// no change made once Watch returns is missed
+	if err := sub.ping(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ping executes a PING through the session of the subscription and waits for its reply
+func (sub *Subscription) ping(ctx context.Context) error {
+	select {
+	case sub.session.handler.requests <- encodeCommand("PING", nil):
+	case <-sub.session.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-sub.acks:
+		return nil
+	case <-sub.session.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// forward hands the pushes written by the io-thread of the subscription to C
+func (sub *Subscription) forward() {
+	defer close(sub.updates)
+
+	for {
+		select {
+		case reply := <-sub.session.handler.replies:
+			push, ok := reply.([]interface{})
+			if !ok || len(push) != 4 {
+				select {
+				case sub.acks <- reply:
+				default:
+				}
+				continue
+			}
+			select {
+			case sub.updates <- newUpdate(push):
+			case <-sub.closing:
+			case <-sub.session.done:
+				return
+			}
+		case <-sub.session.done:
+			return
+		}
+	}
+}
+
+// Close unsubscribes the command and waits for the subscription to stop.
+func (sub *Subscription) Close() error {
+	sub.closeOnce.Do(func() {
+		close(sub.closing)
+
+		select {
+		case sub.session.handler.requests <- encodeCommand(sub.command+".UNWATCH", []string{sub.fingerprint}):
+		case <-sub.session.done:
+		}
+		_ = sub.session.Close()
+	})
+	return nil
+}
+
+// newUpdate converts a push, made of the command, the fingerprint of the subscription, the result and the
+// sequence number, to an update
+func newUpdate(push []interface{}) Update {
+	u := Update{}
+	u.Seq, _ = push[3].(uint64)
+	u.Result, u.Err = decodeReply(push[2])
+	return u
+}
@@ -0,0 +1,74 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/internal/bigkeys"
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// RespBigKeys evaluates the BIGKEYS command, reporting the largest keys found by the last analysis:
+//
+//	BIGKEYS
+//	BIGKEYS SCAN
+//
+// BIGKEYS returns an entry per type, ordered by type, with the totals of the type and its largest keys by size
+// and by number of elements. BIGKEYS SCAN starts an analysis in the background, see bigkeys.
+func RespBigKeys(args []string) interface{} {
+	if len(args) > 0 {
+		switch sub := strings.ToUpper(args[0]); sub {
+		case "SCAN":
+			if len(args) != 1 {
+				return diceerrors.ErrWrongArgumentCount(CmdBigKeys + "|" + sub)
+			}
+			if err := bigkeys.Scan(); err != nil {
+				return err
+			}
+			return clientio.OK
+		default:
+			return diceerrors.ErrGeneral("unknown subcommand '" + args[0] + "'")
+		}
+	}
+
+	report := bigkeys.Latest()
+	if report == nil {
+		return bigkeys.ErrNoReport
+	}
+	reply := make([]interface{}, 0, len(report.Types))
+	for _, t := range report.Types {
+		bySize := make([]interface{}, 0, len(t.BySize))
+		for _, k := range t.BySize {
+			bySize = append(bySize, []interface{}{k.Key, k.Size})
+		}
+		byElements := make([]interface{}, 0, len(t.ByElements))
+		for _, k := range t.ByElements {
+			byElements = append(byElements, []interface{}{k.Key, k.Elements})
+		}
+		reply = append(reply, []interface{}{
+			"type", t.Type,
+			"keys", t.Keys,
+			"size", t.Size,
+			"elements", t.Elements,
+			"by_size", bySize,
+			"by_elements", byElements,
+		})
+	}
+	return reply
+}
@@ -0,0 +1,110 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dicedb/dice/internal/blocking"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+)
+
+// handleBlockingCommand serves a blocking command, e.g. BLPOP, blocking the client until one of its keys can
+// be popped from. Every attempt runs the non-blocking pop command, e.g. LPOP, on the shard owning the key, so
+// that a served client is logged to the WAL exactly as if it had sent the pop command itself.
+func (t *BaseIOThread) handleBlockingCommand(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) error {
+	keys, timeout, err := blocking.ParseArgs(diceDBCmd.Cmd, diceDBCmd.Args)
+	if err != nil {
+		t.logCommand(diceDBCmd, err)
+		return t.writeResponse(ctx, err)
+	}
+
+	pop := blocking.Commands[diceDBCmd.Cmd].Pop
+	var popped *cmd.DiceDBCmd
+	var reply interface{}
+	err = t.shardManager.Blocking().Block(ctx, keys, timeout, func(key string) (bool, error) {
+		popCmd := &cmd.DiceDBCmd{Cmd: pop, Args: []string{key}}
+		result, err := t.executeBlockingAttempt(ctx, popCmd)
+		if err != nil {
+			return false, err
+		}
+
+		var served bool
+		if reply, served = blocking.Reply(t.namespace.Unprefix(key), result); served {
+			popped = popCmd
+		}
+		return served, nil
+	})
+
+	switch {
+	case err == nil:
+	case errors.Is(err, blocking.ErrTimeout):
+		reply = clientio.NIL
+	case ctx.Err() != nil:
+		// The client disconnected, nobody is left to reply to
+		return nil
+	default:
+		reply = err
+	}
+
+	t.logCommand(diceDBCmd, reply)
+	if err := t.writeResponse(ctx, reply); err != nil {
+		return err
+	}
+	if popped != nil && t.wl != nil {
+		return t.wl.LogCommand([]byte(fmt.Sprintf("%s %s", popped.Cmd, strings.Join(popped.Args, " "))))
+	}
+	return nil
+}
+
+// untilDisconnected returns a copy of ctx canceled as soon as the client disconnects. The blocking commands
+// are canceled this way, and the shards skip the reads of a disconnected client: the writes complete even if
+// their client does not wait for them.
+func (t *BaseIOThread) untilDisconnected(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-t.disconnected:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// executeBlockingAttempt runs the pop command of a blocking command on the shard owning its key, and
+// returns its result.
+func (t *BaseIOThread) executeBlockingAttempt(ctx context.Context, popCmd *cmd.DiceDBCmd) (interface{}, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	defer cancel()
+
+	if err := t.scatter(attemptCtx, []*cmd.DiceDBCmd{popCmd}, SingleShard); err != nil {
+		return nil, err
+	}
+	resps, err := t.gatherResponses(attemptCtx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if resps[0].EvalResponse.Error != nil {
+		return nil, resps[0].EvalResponse.Error
+	}
+	return resps[0].EvalResponse.Result, nil
+}
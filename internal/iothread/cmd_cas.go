@@ -0,0 +1,128 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/tracing"
+)
+
+// errCASMismatch is returned by the guard of an MSETCAS whose keys do not all hold their expected value.
+var errCASMismatch = errors.New("a key does not hold its expected value")
+
+// handleMSetCAS serves MSETCAS and appends its writes to the WAL once applied.
+func (t *BaseIOThread) handleMSetCAS(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) error {
+	resp, committed := t.RespMSetCAS(ctx, diceDBCmd.Args)
+	t.logCommand(diceDBCmd, resp)
+	if err := t.writeResponse(ctx, resp); err != nil {
+		return err
+	}
+	return t.logTxnToWAL(committed)
+}
+
+// RespMSetCAS sets the keys to their values only if the checked keys all hold their expected value, giving
+// clients optimistic concurrency over several keys without a MULTI/EXEC round trip. The keys may live on
+// different shards: the shards are locked as for EXEC, then the checked keys are read and the keys are only
+// written if every one matches, so that no other client observes or writes the keys in between. The expiry
+// of the keys written is discarded, as with MSET. It returns 1 if the keys were set, 0 if a checked key does
+// not exist or holds another value, along with the commands executed, nil if the keys were not set.
+//
+// Usage: MSETCAS numchecks key expected [key expected ...] key value [key value ...]
+func (t *BaseIOThread) RespMSetCAS(ctx context.Context, args []string) (resp interface{}, committed []*cmd.DiceDBCmd) {
+	if len(args) < 5 || len(args)%2 == 0 {
+		return diceerrors.ErrWrongArgumentCount("MSETCAS"), nil
+	}
+	numChecks, err := strconv.Atoi(args[0])
+	if err != nil {
+		return diceerrors.ErrIntegerOutOfRange, nil
+	}
+	if numChecks <= 0 {
+		return diceerrors.ErrGeneral("numchecks should be greater than 0"), nil
+	}
+	if 2*numChecks > len(args)-3 {
+		return diceerrors.ErrGeneral("numchecks leaves no key to set"), nil
+	}
+	checks, writes := args[1:1+2*numChecks], args[1+2*numChecks:]
+
+	guard := &shard.Guard{
+		Cmds:   make([]*cmd.DiceDBCmd, 0, numChecks),
+		Shards: make([]shard.ShardID, 0, numChecks),
+		Check: func(resps []*eval.EvalResponse) error {
+			for i, r := range resps {
+				holds, err := eval.HoldsValue(r, checks[2*i+1])
+				if err != nil {
+					return err
+				}
+				if !holds {
+					return errCASMismatch
+				}
+			}
+			return nil
+		},
+	}
+	for i := 0; i < len(checks); i += 2 {
+		id, _ := t.shardManager.GetShardInfo(checks[i])
+		guard.Cmds = append(guard.Cmds, &cmd.DiceDBCmd{Cmd: CmdGet, Args: []string{checks[i]}})
+		guard.Shards = append(guard.Shards, id)
+	}
+
+	// The keys are written with one MSET per shard
+	msets := make(map[shard.ShardID]*cmd.DiceDBCmd)
+	txn := &shard.Txn{
+		ID:          GenerateUniqueRequestID(),
+		IOThreadID:  t.id,
+		ClientAddr:  t.ioHandler.RemoteAddr(),
+		ClientID:    t.clientID,
+		SpanContext: tracing.SpanContext(ctx),
+		Guard:       guard,
+	}
+	for i := 0; i < len(writes); i += 2 {
+		id, _ := t.shardManager.GetShardInfo(writes[i])
+		mset, ok := msets[id]
+		if !ok {
+			mset = &cmd.DiceDBCmd{Cmd: CmdMset}
+			msets[id] = mset
+			txn.Cmds = append(txn.Cmds, mset)
+			txn.Shards = append(txn.Shards, id)
+		}
+		mset.Args = append(mset.Args, writes[i], writes[i+1])
+	}
+
+	_, span := tracing.Start(ctx, tracing.SpanDispatch)
+	resps, err := t.shardManager.ExecTxn(ctx, txn)
+	span.End()
+	if errors.Is(err, errCASMismatch) {
+		return clientio.IntegerZero, nil
+	}
+	if err != nil {
+		return err, nil
+	}
+	for _, r := range resps {
+		if r.Error != nil {
+			return r.Error, txn.Cmds
+		}
+	}
+	return clientio.IntegerOne, txn.Cmds
+}
@@ -17,11 +17,12 @@
 package iothread
 
 import (
-	"math"
 	"sort"
 
 	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/eval"
 	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/slowlog"
 )
 
 // This file contains functions used by the IOThread to handle and process responses
@@ -101,100 +102,6 @@ func composeMGet(responses ...ops.StoreResponse) interface{} {
 	return results
 }
 
-func composeSInter(responses ...ops.StoreResponse) interface{} {
-	results := [][]string{}
-	minLen := math.MaxInt
-	minIdx := 0
-	for idx := range responses {
-		if responses[idx].EvalResponse.Error != nil {
-			return responses[idx].EvalResponse.Error
-		}
-
-		if len(responses[idx].EvalResponse.Result.([]string)) < minLen {
-			minLen = len(responses[idx].EvalResponse.Result.([]string))
-			minIdx = idx
-		}
-
-		results = append(results, responses[idx].EvalResponse.Result.([]string))
-	}
-
-	// Create the initial countMap from the smallest slice
-	countMap := make(map[string]int)
-	for _, str := range results[minIdx] {
-		countMap[str] = 1
-	}
-
-	// Iterate over the remaining slices, skipping the one used as countMap
-	for i, result := range results {
-		if i == minIdx {
-			continue
-		}
-
-		currentCount := make(map[string]bool)
-		for _, str := range result {
-			if _, exists := countMap[str]; exists {
-				currentCount[str] = true
-			}
-		}
-
-		// Remove elements from countMap that are not in currentCount
-		for key := range countMap {
-			if _, exists := currentCount[key]; !exists {
-				delete(countMap, key)
-			}
-		}
-	}
-
-	resp := make([]string, 0, len(countMap))
-	for str := range countMap {
-		resp = append(resp, str)
-	}
-
-	return resp
-}
-
-func composeSDiff(responses ...ops.StoreResponse) interface{} {
-	sort.Slice(responses, func(i, j int) bool {
-		return responses[i].SeqID < responses[j].SeqID
-	})
-
-	results := [][]string{}
-	minIdx := 0
-	for idx := range responses {
-		if responses[idx].EvalResponse.Error != nil {
-			return responses[idx].EvalResponse.Error
-		}
-		results = append(results, responses[idx].EvalResponse.Result.([]string))
-	}
-
-	// Create the initial countMap from the smallest slice
-	countMap := make(map[string]int)
-	for _, str := range results[0] {
-		countMap[str] = 1
-	}
-
-	// Iterate over the remaining slices, skipping the one used as countMap
-	for i, result := range results {
-		if i == minIdx {
-			continue
-		}
-		for _, str := range result {
-			if _, exists := countMap[str]; exists {
-				countMap[str]++
-			}
-		}
-	}
-
-	resp := make([]string, 0, len(countMap))
-	for str := range countMap {
-		if countMap[str] == 1 {
-			resp = append(resp, str)
-		}
-	}
-
-	return resp
-}
-
 func composeJSONMget(responses ...ops.StoreResponse) interface{} {
 	sort.Slice(responses, func(i, j int) bool {
 		return responses[i].SeqID < responses[j].SeqID
@@ -262,3 +169,60 @@ func composeFlushDB(responses ...ops.StoreResponse) interface{} {
 
 	return clientio.OK
 }
+
+// composeInfo merges the information of every shard into the INFO reply.
+// The reply is multi-line, so the frontends encode it as a bulk string.
+func composeInfo(responses ...ops.StoreResponse) interface{} {
+	shards := make([]eval.ShardInfo, 0, len(responses))
+	for idx := range responses {
+		if responses[idx].EvalResponse.Error != nil {
+			return responses[idx].EvalResponse.Error
+		}
+		shards = append(shards, responses[idx].EvalResponse.Result.(eval.ShardInfo))
+	}
+
+	return eval.FormatInfo(shards[0].Sections, shards)
+}
+
+// composeMemory merges the memory stats of every shard into the MEMORY STATS reply.
+func composeMemory(responses ...ops.StoreResponse) interface{} {
+	shards := make([]eval.ShardMemoryStats, 0, len(responses))
+	for idx := range responses {
+		if responses[idx].EvalResponse.Error != nil {
+			return responses[idx].EvalResponse.Error
+		}
+		shards = append(shards, responses[idx].EvalResponse.Result.(eval.ShardMemoryStats))
+	}
+
+	return eval.FormatMemoryStats(shards)
+}
+
+// composeSlowlog merges the replies of every shard to SLOWLOG: the most recent entries
+// of all slow logs for GET, the total number of entries for LEN and OK for RESET.
+func composeSlowlog(responses ...ops.StoreResponse) interface{} {
+	var (
+		count   int
+		length  int
+		entries [][]slowlog.Entry
+	)
+	for idx := range responses {
+		if responses[idx].EvalResponse.Error != nil {
+			return responses[idx].EvalResponse.Error
+		}
+
+		switch result := responses[idx].EvalResponse.Result.(type) {
+		case eval.SlowlogEntries:
+			count = result.Count
+			entries = append(entries, result.Entries)
+		case int:
+			length += result
+		default:
+			return result
+		}
+	}
+
+	if entries != nil {
+		return slowlog.Reply(slowlog.Merge(count, entries...))
+	}
+	return length
+}
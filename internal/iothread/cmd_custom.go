@@ -17,13 +17,22 @@
 package iothread
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/diagnostics"
 	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/failpoint"
+	"github.com/dicedb/dice/internal/namespace"
+	"github.com/dicedb/dice/internal/object"
+	dstore "github.com/dicedb/dice/internal/store"
 )
 
 // RespAuth returns with an encoded "OK" if the user is authenticated
@@ -34,11 +43,7 @@ func (t *BaseIOThread) RespAuth(args []string) interface{} {
 		return diceerrors.ErrWrongArgumentCount("AUTH")
 	}
 
-	if config.DiceConfig.Auth.Password == "" {
-		return diceerrors.ErrAuth
-	}
-
-	username := config.DiceConfig.Auth.UserName
+	username := config.DiceConfig().Auth.UserName
 	var password string
 
 	if len(args) == 1 {
@@ -47,13 +52,50 @@ func (t *BaseIOThread) RespAuth(args []string) interface{} {
 		username, password = args[0], args[1]
 	}
 
+	// The tenants of the namespaces authenticate even when the default user has no password
+	if config.DiceConfig().Auth.Password == "" && username == config.DiceConfig().Auth.UserName {
+		return diceerrors.ErrAuth
+	}
+
 	if err := t.Session.Validate(username, password); err != nil {
 		return err
 	}
 
+	// The tenants are confined to their namespace, the other users see the whole keyspace
+	t.namespace, t.confined = nil, false
+	t.trace, t.lastTiming = false, nil
+	t.readAfter = 0
+	if username != config.DiceConfig().Auth.UserName {
+		if ns := namespace.Get(username); ns != nil {
+			t.namespace, t.confined = ns, true
+		}
+	}
 	return clientio.OK
 }
 
+// RespReset resets the connection to the state it had once connected: the transaction in progress is discarded,
+// the watch subscriptions are removed, the name and the namespace of the client are cleared and the client must authenticate again,
+// unless it was authenticated by its TLS client certificate.
+func (t *BaseIOThread) RespReset(args []string) interface{} {
+	if len(args) != 0 {
+		return diceerrors.ErrWrongArgumentCount("RESET")
+	}
+
+	t.txn = nil
+	t.clientName = ""
+	t.namespace, t.confined = nil, false
+	t.unwatchAll(context.Background())
+
+	if identity := t.Session.PeerIdentity; identity != "" {
+		if err := t.Session.ValidatePeerIdentity(identity); err != nil {
+			t.Session.Expire()
+		}
+	} else {
+		t.Session.Expire()
+	}
+	return "RESET"
+}
+
 // RespPING evaluates the PING command and returns the appropriate response.
 // If no arguments are provided, it responds with "PONG" (standard behavior).
 // If an argument is provided, it returns the argument as the response.
@@ -80,32 +122,115 @@ func RespEcho(args []string) interface{} {
 	return args[0]
 }
 
-func RespHello(args []string) interface{} {
-	if len(args) > 1 {
-		return diceerrors.NewErrArity("HELLO")
+// RespHello evaluates the HELLO command: it authenticates and names the connection when asked to,
+// and returns the properties of the server and of the connection.
+func (t *BaseIOThread) RespHello(args []string) interface{} {
+	opts, err := eval.ParseHello(args)
+	if err != nil {
+		return err
 	}
 
-	var resp []interface{}
-	serverID := fmt.Sprintf("%s:%d", config.DiceConfig.RespServer.Addr, config.DiceConfig.RespServer.Port)
-	resp = append(resp,
-		"proto", 2,
-		"id", serverID,
-		"mode", "standalone",
-		"role", "master",
-		"modules", []interface{}{})
+	if opts.Auth {
+		if resp := t.RespAuth([]string{opts.Username, opts.Password}); resp != clientio.OK {
+			return resp
+		}
+	} else if !t.Session.IsActive() {
+		return diceerrors.New(diceerrors.CodeNoAuth, "HELLO must be called with the client already authenticated, otherwise the HELLO <proto> AUTH <user> <pass> option can be used to authenticate the client and select the RESP protocol version at the same time")
+	}
 
-	return resp
+	if opts.ClientName != "" {
+		t.clientName = opts.ClientName
+	}
+	return eval.HelloReply(t.clientID)
 }
 
 func RespSleep(args []string) interface{} {
 	if len(args) != 1 {
-		return diceerrors.NewErrArity("SLEEP")
+		return diceerrors.ErrWrongArgumentCount("SLEEP")
 	}
 
 	durationSec, err := strconv.ParseInt(args[0], 10, 64)
 	if err != nil {
-		return diceerrors.NewErrWithMessage(diceerrors.IntOrOutOfRangeErr)
+		return diceerrors.ErrIntegerOutOfRange
 	}
 	time.Sleep(time.Duration(durationSec) * time.Second)
 	return clientio.OK
 }
+
+// RespDebug evaluates the DEBUG command. DEBUG QUICK runs the diagnostics of the server environment
+// and returns one [check, status, detail] entry per check. DEBUG FAILPOINT enables the failpoints.
+// DEBUG RELOAD reloads the dataset of every shard. DEBUG LATENCY-TEST measures the latency of the shards.
+func (t *BaseIOThread) RespDebug(ctx context.Context, args []string) interface{} {
+	if len(args) == 0 {
+		return diceerrors.ErrWrongArgumentCount("DEBUG")
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "QUICK":
+		if len(args) != 1 {
+			return diceerrors.ErrWrongArgumentCount("DEBUG|QUICK")
+		}
+		report := diagnostics.Run(diagnostics.Options{})
+		resp := make([]interface{}, 0, len(report))
+		for _, c := range report {
+			resp = append(resp, []interface{}{c.Name, string(c.Status), c.Detail})
+		}
+		return resp
+	case "FAILPOINT":
+		return debugFailpoint(args[1:])
+	case "RELOAD":
+		return t.debugReload(ctx, args[1:])
+	case "LATENCY-TEST":
+		return t.debugLatencyTest(ctx, args[1:])
+	default:
+		return diceerrors.ErrGeneral(fmt.Sprintf("unknown subcommand '%s'. Try DEBUG HELP.", args[0]))
+	}
+}
+
+// debugReload evaluates DEBUG RELOAD [VERIFY], reloading the dataset of the shards one after the other.
+// The shards keep serving the other clients in between, so the dataset is not reloaded at a single
+// point in time.
+func (t *BaseIOThread) debugReload(ctx context.Context, args []string) interface{} {
+	verify := false
+	switch {
+	case len(args) == 1 && strings.EqualFold(args[0], "VERIFY"):
+		verify = true
+	case len(args) != 0:
+		return diceerrors.ErrWrongArgumentCount("DEBUG|RELOAD")
+	}
+
+	shards := make([]map[object.ObjectType]*dstore.ReloadDigest, 0, t.shardManager.GetShardCount())
+	for i := uint8(0); i < uint8(t.shardManager.GetShardCount()); i++ {
+		resp, err := t.executeOnShard(ctx, i, &cmd.DiceDBCmd{Cmd: dstore.SingleReload})
+		if err != nil {
+			return err
+		}
+		digests, ok := resp.(map[object.ObjectType]*dstore.ReloadDigest)
+		if !ok {
+			return diceerrors.ErrInternalServer
+		}
+		shards = append(shards, digests)
+	}
+	return eval.FormatReload(shards, verify)
+}
+
+// debugFailpoint evaluates DEBUG FAILPOINT <name> <term>, enabling the failpoint with the term or disabling it
+// with off, and DEBUG FAILPOINT LIST, returning one [name, term] entry per enabled failpoint.
+func debugFailpoint(args []string) interface{} {
+	if len(args) == 1 && strings.EqualFold(args[0], "LIST") {
+		list := failpoint.List()
+		resp := make([]interface{}, 0, len(list))
+		for _, fp := range list {
+			resp = append(resp, []interface{}{fp[0], fp[1]})
+		}
+		return resp
+	}
+	if len(args) != 2 {
+		return diceerrors.ErrWrongArgumentCount("DEBUG|FAILPOINT")
+	}
+
+	if err := failpoint.Enable(args[0], args[1]); err != nil {
+		return diceerrors.ErrGeneral(err.Error())
+	}
+	return clientio.OK
+}
@@ -121,26 +121,29 @@ func decomposeCopy(ctx context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd)
 	return decomposedCmds, nil
 }
 
-// decomposeMSet decomposes the MSET (Multi-set) command into individual SET commands.
-// It expects an even number of arguments (key-value pairs). For each pair, it creates
-// a separate SET command to store the value at the given key.
-func decomposeMSet(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
-	if len(cd.Args)%2 != 0 {
+// decomposeMSet decomposes the MSET (Multi-set) command into one MSET per shard owning some of the keys.
+// It expects an even number of arguments (key-value pairs). Each shard sets its keys in a single batch of
+// writes, rather than executing a separate SET for every key.
+func decomposeMSet(_ context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
+	if len(cd.Args) == 0 || len(cd.Args)%2 != 0 {
 		return nil, diceerrors.ErrWrongArgumentCount("MSET")
 	}
 
-	decomposedCmds := make([]*cmd.DiceDBCmd, 0, len(cd.Args)/2)
+	decomposedCmds := make([]*cmd.DiceDBCmd, 0)
+	shardCmds := make(map[uint8]*cmd.DiceDBCmd)
 
 	for i := 0; i < len(cd.Args)-1; i += 2 {
 		key := cd.Args[i]
 		val := cd.Args[i+1]
 
-		decomposedCmds = append(decomposedCmds,
-			&cmd.DiceDBCmd{
-				Cmd:  store.Set,
-				Args: []string{key, val},
-			},
-		)
+		shardID, _ := thread.shardManager.GetShardInfo(key)
+		shardCmd, ok := shardCmds[shardID]
+		if !ok {
+			shardCmd = &cmd.DiceDBCmd{Cmd: CmdMset}
+			shardCmds[shardID] = shardCmd
+			decomposedCmds = append(decomposedCmds, shardCmd)
+		}
+		shardCmd.Args = append(shardCmd.Args, key, val)
 	}
 	return decomposedCmds, nil
 }
@@ -164,31 +167,35 @@ func decomposeMGet(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cm
 	return decomposedCmds, nil
 }
 
-func decomposeSInter(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
-	if len(cd.Args) < 1 {
-		return nil, diceerrors.ErrWrongArgumentCount("SINTER")
+func decomposeJSONMget(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
+	if len(cd.Args) < 2 {
+		return nil, diceerrors.ErrWrongArgumentCount("JSON.MGET")
 	}
+
+	pattern := cd.Args[len(cd.Args)-1]
+
 	decomposedCmds := make([]*cmd.DiceDBCmd, 0, len(cd.Args))
-	for i := 0; i < len(cd.Args); i++ {
+	for i := 0; i < len(cd.Args)-1; i++ {
 		decomposedCmds = append(decomposedCmds,
 			&cmd.DiceDBCmd{
-				Cmd:  store.Smembers,
-				Args: []string{cd.Args[i]},
+				Cmd:  store.JSONGet,
+				Args: []string{cd.Args[i], pattern},
 			},
 		)
 	}
 	return decomposedCmds, nil
 }
 
-func decomposeSDiff(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
-	if len(cd.Args) < 1 {
-		return nil, diceerrors.ErrWrongArgumentCount("SDIFF")
+func decomposeTouch(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
+	if len(cd.Args) == 0 {
+		return nil, diceerrors.ErrWrongArgumentCount("TOUCH")
 	}
+
 	decomposedCmds := make([]*cmd.DiceDBCmd, 0, len(cd.Args))
 	for i := 0; i < len(cd.Args); i++ {
 		decomposedCmds = append(decomposedCmds,
 			&cmd.DiceDBCmd{
-				Cmd:  store.Smembers,
+				Cmd:  store.SingleShardTouch,
 				Args: []string{cd.Args[i]},
 			},
 		)
@@ -196,53 +203,64 @@ func decomposeSDiff(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*c
 	return decomposedCmds, nil
 }
 
-func decomposeJSONMget(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
-	if len(cd.Args) < 2 {
-		return nil, diceerrors.ErrWrongArgumentCount("JSON.MGET")
+func decomposeDBSize(_ context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
+	if len(cd.Args) > 0 {
+		return nil, diceerrors.ErrWrongArgumentCount("DBSIZE")
 	}
 
-	pattern := cd.Args[len(cd.Args)-1]
-
 	decomposedCmds := make([]*cmd.DiceDBCmd, 0, len(cd.Args))
-	for i := 0; i < len(cd.Args)-1; i++ {
+	for i := uint8(0); i < uint8(thread.shardManager.GetShardCount()); i++ {
 		decomposedCmds = append(decomposedCmds,
 			&cmd.DiceDBCmd{
-				Cmd:  store.JSONGet,
-				Args: []string{cd.Args[i], pattern},
+				Cmd:  store.SingleShardSize,
+				Args: []string{},
 			},
 		)
 	}
 	return decomposedCmds, nil
 }
 
-func decomposeTouch(_ context.Context, _ *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
+func decomposeInfo(_ context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
+	decomposedCmds := make([]*cmd.DiceDBCmd, 0, thread.shardManager.GetShardCount())
+	for i := uint8(0); i < uint8(thread.shardManager.GetShardCount()); i++ {
+		decomposedCmds = append(decomposedCmds,
+			&cmd.DiceDBCmd{
+				Cmd:  store.SingleShardInfo,
+				Args: cd.Args,
+			},
+		)
+	}
+	return decomposedCmds, nil
+}
+
+func decomposeMemory(_ context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
 	if len(cd.Args) == 0 {
-		return nil, diceerrors.ErrWrongArgumentCount("TOUCH")
+		return nil, diceerrors.ErrWrongArgumentCount("MEMORY")
 	}
 
-	decomposedCmds := make([]*cmd.DiceDBCmd, 0, len(cd.Args))
-	for i := 0; i < len(cd.Args); i++ {
+	decomposedCmds := make([]*cmd.DiceDBCmd, 0, thread.shardManager.GetShardCount())
+	for i := uint8(0); i < uint8(thread.shardManager.GetShardCount()); i++ {
 		decomposedCmds = append(decomposedCmds,
 			&cmd.DiceDBCmd{
-				Cmd:  store.SingleShardTouch,
-				Args: []string{cd.Args[i]},
+				Cmd:  store.SingleMemory,
+				Args: cd.Args,
 			},
 		)
 	}
 	return decomposedCmds, nil
 }
 
-func decomposeDBSize(_ context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
-	if len(cd.Args) > 0 {
-		return nil, diceerrors.ErrWrongArgumentCount("DBSIZE")
+func decomposeSlowlog(_ context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
+	if len(cd.Args) == 0 {
+		return nil, diceerrors.ErrWrongArgumentCount("SLOWLOG")
 	}
 
-	decomposedCmds := make([]*cmd.DiceDBCmd, 0, len(cd.Args))
+	decomposedCmds := make([]*cmd.DiceDBCmd, 0, thread.shardManager.GetShardCount())
 	for i := uint8(0); i < uint8(thread.shardManager.GetShardCount()); i++ {
 		decomposedCmds = append(decomposedCmds,
 			&cmd.DiceDBCmd{
-				Cmd:  store.SingleShardSize,
-				Args: []string{},
+				Cmd:  store.SingleSlowlog,
+				Args: cd.Args,
 			},
 		)
 	}
@@ -266,9 +284,10 @@ func decomposeKeys(_ context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd) (
 	return decomposedCmds, nil
 }
 
+// decomposeFlushDB decomposes FLUSHDB and FLUSHALL into a FLUSHDB of every shard.
 func decomposeFlushDB(_ context.Context, thread *BaseIOThread, cd *cmd.DiceDBCmd) ([]*cmd.DiceDBCmd, error) {
 	if len(cd.Args) > 1 {
-		return nil, diceerrors.ErrWrongArgumentCount("FLUSHDB")
+		return nil, diceerrors.ErrWrongArgumentCount(cd.Cmd)
 	}
 
 	decomposedCmds := make([]*cmd.DiceDBCmd, 0, len(cd.Args))
@@ -0,0 +1,82 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/export"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/tracing"
+)
+
+// handleExport serves EXPORT, replying with the array of the lines of the export. The keyspace is read
+// batch after batch, so the shards keep serving the other clients while the export is built.
+func (t *BaseIOThread) handleExport(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) error {
+	opts, err := export.ParseArgs(diceDBCmd.Args)
+	if err != nil {
+		t.logCommand(diceDBCmd, err)
+		return t.writeResponse(ctx, err)
+	}
+
+	lines := make([]string, 0)
+	err = export.Run(ctx, int(t.shardManager.GetShardCount()), t.executeOnShard, opts, func(batch []string) error {
+		lines = append(lines, batch...)
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			// The client disconnected, nobody is left to reply to
+			return nil
+		}
+		t.logCommand(diceDBCmd, err)
+		return t.writeResponse(ctx, err)
+	}
+
+	t.logCommand(diceDBCmd, lines)
+	return t.writeResponse(ctx, lines)
+}
+
+// executeOnShard runs a command on the given shard, whatever the shard owning its first argument, and
+// returns its result.
+func (t *BaseIOThread) executeOnShard(ctx context.Context, shardID uint8, diceDBCmd *cmd.DiceDBCmd) (interface{}, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	defer cancel()
+
+	err := t.shardManager.GetShard(shardID).SendContext(reqCtx, &ops.StoreOp{
+		RequestID:   t.newRequestID(),
+		Cmd:         diceDBCmd,
+		IOThreadID:  t.id,
+		ShardID:     shardID,
+		ClientAddr:  t.ioHandler.RemoteAddr(),
+		ClientID:    t.clientID,
+		SpanContext: tracing.SpanContext(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resps, err := t.gatherResponses(reqCtx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if resps[0].EvalResponse.Error != nil {
+		return nil, resps[0].EvalResponse.Error
+	}
+	return resps[0].EvalResponse.Result, nil
+}
@@ -0,0 +1,98 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/hotkeys"
+)
+
+// defaultHotKeysCount is the number of keys returned by HOTKEYS without a count
+const defaultHotKeysCount = 10
+
+// RespHotKeys evaluates the HOTKEYS command, reporting the keys accessed the most:
+//
+//	HOTKEYS [COUNT count]
+//	HOTKEYS STATS
+//	HOTKEYS RESET
+//
+// HOTKEYS returns the count hottest keys, 10 by default and -1 for all the tracked keys, as [key, ops/sec]
+// pairs, the hottest first. HOTKEYS STATS returns the number of keys copied for their reads and the number of
+// GETs served from the copies, and HOTKEYS RESET forgets the rates estimated so far.
+func RespHotKeys(args []string) interface{} {
+	if !config.DiceConfig().HotKeys.Enabled {
+		return hotkeys.ErrDisabled
+	}
+
+	count := defaultHotKeysCount
+	if len(args) > 0 {
+		switch sub := strings.ToUpper(args[0]); sub {
+		case "COUNT":
+			if len(args) != 2 {
+				return diceerrors.ErrWrongArgumentCount(CmdHotKeys + "|" + sub)
+			}
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < -1 {
+				return diceerrors.ErrIntegerOutOfRange
+			}
+			count = n
+		case "STATS":
+			if len(args) != 1 {
+				return diceerrors.ErrWrongArgumentCount(CmdHotKeys + "|" + sub)
+			}
+			keys, hits := hotkeys.Replicas()
+			return []interface{}{"replicated_keys", keys, "replica_hits", hits}
+		case "RESET":
+			if len(args) != 1 {
+				return diceerrors.ErrWrongArgumentCount(CmdHotKeys + "|" + sub)
+			}
+			hotkeys.Reset()
+			return clientio.OK
+		default:
+			return diceerrors.ErrGeneral("unknown subcommand '" + args[0] + "'")
+		}
+	}
+
+	top := hotkeys.Top(count)
+	reply := make([]interface{}, 0, len(top))
+	for _, k := range top {
+		reply = append(reply, []interface{}{k.Key, int64(math.Round(k.OpsPerSec))})
+	}
+	return reply
+}
+
+// serveReplica replies to a GET of a hot key with the copy of its value, if the key is copied, and reports
+// whether it did. The shard owning the key is left out, see hotkeys.
+func (t *BaseIOThread) serveReplica(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) (bool, error) {
+	if diceDBCmd.Cmd != CmdGet || len(diceDBCmd.Args) != 1 {
+		return false, nil
+	}
+	value, ok := hotkeys.Lookup(diceDBCmd.Args[0])
+	if !ok {
+		return false, nil
+	}
+	t.logCommand(diceDBCmd, value)
+	return true, t.writeResponse(ctx, value)
+}
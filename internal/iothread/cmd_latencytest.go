@@ -0,0 +1,181 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/internal/bench"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+const (
+	// latencyTestPrefix is the scratch namespace of the keys of DEBUG LATENCY-TEST, deleted once the test is done
+	latencyTestPrefix = "__latency_test__:"
+	// latencyTestMaxRequests bounds the requests of a test, the client waiting for the test to be done
+	latencyTestMaxRequests = 1_000_000
+)
+
+// latencyTestOptions are the options of DEBUG LATENCY-TEST
+type latencyTestOptions struct {
+	requests int
+	dataSize int
+	keySpace int
+	mix      []bench.Op
+}
+
+// parseLatencyTest parses the options of DEBUG LATENCY-TEST, [REQUESTS n] [DATASIZE n] [KEYSPACE n] [MIX mix]
+func parseLatencyTest(args []string) (*latencyTestOptions, error) {
+	opts := &latencyTestOptions{
+		requests: 10000,
+		dataSize: 16,
+		keySpace: 1000,
+		mix:      []bench.Op{{Name: "SET", Weight: 1}, {Name: "GET", Weight: 1}, {Name: "LPUSH", Weight: 1}, {Name: "LPOP", Weight: 1}},
+	}
+	if len(args)%2 != 0 {
+		return nil, diceerrors.ErrSyntax
+	}
+	for i := 0; i < len(args); i += 2 {
+		option, value := strings.ToUpper(args[i]), args[i+1]
+		if option == "MIX" {
+			mix, err := bench.ParseMix(value)
+			if err != nil {
+				return nil, diceerrors.ErrGeneral(err.Error())
+			}
+			for _, op := range mix {
+				if bench.KeyCount(op.Name) != 1 {
+					return nil, diceerrors.ErrFormatted("%s can not be part of the mix, the commands of the mix must have a single key", op.Name)
+				}
+			}
+			opts.mix = mix
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, diceerrors.ErrIntegerOutOfRange
+		}
+		switch option {
+		case "REQUESTS":
+			if n <= 0 || n > latencyTestMaxRequests {
+				return nil, diceerrors.ErrFormatted("REQUESTS must be between 1 and %d", latencyTestMaxRequests)
+			}
+			opts.requests = n
+		case "DATASIZE":
+			if n < 0 || n > 1024*1024 {
+				return nil, diceerrors.ErrFormatted("DATASIZE must be between 0 and %d", 1024*1024)
+			}
+			opts.dataSize = n
+		case "KEYSPACE":
+			if n <= 0 {
+				return nil, diceerrors.ErrValueOutOfRange
+			}
+			opts.keySpace = n
+		default:
+			return nil, diceerrors.ErrSyntax
+		}
+	}
+	return opts, nil
+}
+
+// debugLatencyTest evaluates DEBUG LATENCY-TEST, executing the requests of the mix one after the other against
+// the keys of the scratch namespace and reporting their latencies in microseconds. The latency of a request is
+// the time the shard owning its key took to reply to the io-thread, the network to the client not included.
+// The keys of the scratch namespace are deleted once the test is done, the keys written before by anyone else
+// included.
+func (t *BaseIOThread) debugLatencyTest(ctx context.Context, args []string) interface{} {
+	opts, err := parseLatencyTest(args)
+	if err != nil {
+		return err
+	}
+
+	gen := bench.NewGenerator(opts.mix, opts.keySpace, strings.Repeat("x", opts.dataSize), latencyTestPrefix, time.Now().UnixNano())
+	written := make(map[uint8]map[string]struct{})
+	defer t.deleteLatencyTestKeys(context.WithoutCancel(ctx), written)
+
+	latencies := make([]time.Duration, 0, opts.requests)
+	byCommand := make(map[string][]time.Duration, len(opts.mix))
+	failed := 0
+	start := time.Now()
+	for i := 0; i < opts.requests; i++ {
+		args := gen.Next()
+		key := args[1]
+		id, _ := t.shardManager.GetShardInfo(key)
+		if written[id] == nil {
+			written[id] = make(map[string]struct{})
+		}
+		written[id][key] = struct{}{}
+
+		sent := time.Now()
+		_, err := t.executeOnShard(ctx, id, &cmd.DiceDBCmd{Cmd: args[0], Args: args[1:]})
+		latency := time.Since(sent)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			failed++
+		}
+		latencies = append(latencies, latency)
+		byCommand[args[0]] = append(byCommand[args[0]], latency)
+	}
+	elapsed := time.Since(start)
+
+	commands := make([]interface{}, 0, len(byCommand))
+	for _, op := range opts.mix {
+		if l, ok := byCommand[op.Name]; ok {
+			commands = append(commands, append([]interface{}{op.Name, int64(len(l))}, latencySummary(l)...))
+		}
+	}
+	return []interface{}{
+		"requests", int64(len(latencies)),
+		"errors", int64(failed),
+		"elapsed_us", elapsed.Microseconds(),
+		"ops_per_sec", int64(float64(len(latencies)) / max(elapsed.Seconds(), 1e-9)),
+		"latency_us", latencySummary(latencies),
+		"by_command", commands,
+	}
+}
+
+// latencySummary returns the minimum, the percentiles and the maximum of the latencies in microseconds, as
+// ["min", n, "p50", n, "p90", n, "p99", n, "p99.9", n, "max", n], sorting the latencies in place
+func latencySummary(latencies []time.Duration) []interface{} {
+	slices.Sort(latencies)
+	summary := make([]interface{}, 0, 12)
+	for _, p := range []struct {
+		name    string
+		percent float64
+	}{{"min", 0}, {"p50", 50}, {"p90", 90}, {"p99", 99}, {"p99.9", 99.9}, {"max", 100}} {
+		summary = append(summary, p.name, bench.Percentile(latencies, p.percent).Microseconds())
+	}
+	return summary
+}
+
+// deleteLatencyTestKeys deletes the keys written by DEBUG LATENCY-TEST, with one DEL per shard
+func (t *BaseIOThread) deleteLatencyTestKeys(ctx context.Context, written map[uint8]map[string]struct{}) {
+	for id, keys := range written {
+		args := make([]string, 0, len(keys))
+		for key := range keys {
+			args = append(args, key)
+		}
+		_, _ = t.executeOnShard(ctx, id, &cmd.DiceDBCmd{Cmd: "DEL", Args: args})
+	}
+}
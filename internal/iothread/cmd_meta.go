@@ -55,16 +55,48 @@ const (
 	// Unwatch represents a command that is used to stop monitoring changes or events.
 	// This type of command stops listening for changes on specific keys or resources.
 	Unwatch
+
+	// Blocking represents a command that waits for one of its keys to be written when none can serve it.
+	// The client is blocked until it is served, the timeout of the command elapses or it disconnects.
+	Blocking
 )
 
 // Global commands
 const (
-	CmdPing  = "PING"
-	CmdAbort = "ABORT"
-	CmdAuth  = "AUTH"
-	CmdEcho  = "ECHO"
-	CmdHello = "HELLO"
-	CmdSleep = "SLEEP"
+	CmdPing      = "PING"
+	CmdAbort     = "ABORT"
+	CmdAuth      = "AUTH"
+	CmdEcho      = "ECHO"
+	CmdHello     = "HELLO"
+	CmdSleep     = "SLEEP"
+	CmdDebug     = "DEBUG"
+	CmdShutdown  = "SHUTDOWN"
+	CmdMulti     = "MULTI"
+	CmdExec      = "EXEC"
+	CmdDiscard   = "DISCARD"
+	CmdExport    = "EXPORT"
+	CmdSink      = "SINK"
+	CmdNamespace = "NAMESPACE"
+	CmdHotKeys   = "HOTKEYS"
+	CmdBigKeys   = "BIGKEYS"
+	CmdModule    = "MODULE"
+	CmdReadAfter = "READAFTER"
+	CmdTrace     = "TRACE"
+	CmdQuit      = "QUIT"
+	CmdReset     = "RESET"
+)
+
+// Set algebra commands, computed by the io-thread from the sets of the shards owning the keys
+const (
+	CmdSInter     = "SINTER"
+	CmdSInterCard = "SINTERCARD"
+	CmdSUnion     = "SUNION"
+	CmdSDiff      = "SDIFF"
+)
+
+// Compare-and-set of keys owned by several shards, applied as a transaction guarded by the values of the keys
+const (
+	CmdMSetCAS = "MSETCAS"
 )
 
 // Single-shard commands.
@@ -79,6 +111,7 @@ const (
 	CmdZCount              = "ZCOUNT"
 	CmdZRem                = "ZREM"
 	CmdZCard               = "ZCARD"
+	CmdZRandMember         = "ZRANDMEMBER"
 	CmdPFAdd               = "PFADD"
 	CmdPFCount             = "PFCOUNT"
 	CmdPFMerge             = "PFMERGE"
@@ -93,6 +126,7 @@ const (
 	CmdHIncrByFloat        = "HINCRBYFLOAT"
 	CmdHRandField          = "HRANDFIELD"
 	CmdGetRange            = "GETRANGE"
+	CmdSetRange            = "SETRANGE"
 	CmdAppend              = "APPEND"
 	CmdZPopMax             = "ZPOPMAX"
 	CmdHLen                = "HLEN"
@@ -114,6 +148,12 @@ const (
 	CmdHDel                = "HDEL"
 	CmdHMSet               = "HMSET"
 	CmdHMGet               = "HMGET"
+	CmdHExpire             = "HEXPIRE"
+	CmdHPExpire            = "HPEXPIRE"
+	CmdHPersist            = "HPERSIST"
+	CmdHTTL                = "HTTL"
+	CmdHGetEx              = "HGETEX"
+	CmdHGetDel             = "HGETDEL"
 	CmdSetBit              = "SETBIT"
 	CmdGetBit              = "GETBIT"
 	CmdBitCount            = "BITCOUNT"
@@ -124,6 +164,8 @@ const (
 	CmdSrem                = "SREM"
 	CmdScard               = "SCARD"
 	CmdSmembers            = "SMEMBERS"
+	CmdSMisMember          = "SMISMEMBER"
+	CmdSRandMember         = "SRANDMEMBER"
 	CmdDump                = "DUMP"
 	CmdRestore             = "RESTORE"
 	CmdGeoAdd              = "GEOADD"
@@ -133,14 +175,26 @@ const (
 	CmdClient              = "CLIENT"
 	CmdLatency             = "LATENCY"
 	CmdDel                 = "DEL"
+	CmdDelIfEq             = "DELIFEQ"
+	CmdCAS                 = "CAS"
 	CmdExists              = "EXISTS"
 	CmdPersist             = "PERSIST"
+	CmdPExpireIfEq         = "PEXPIREIFEQ"
+	CmdLock                = "LOCK"
+	CmdUnlock              = "UNLOCK"
+	CmdUndelete            = "UNDELETE"
+	CmdGetAt               = "GETAT"
+	CmdHistory             = "HISTORY"
+	CmdPExpire             = "PEXPIRE"
 	CmdTypeOf              = "TYPE"
 	CmdObject              = "OBJECT"
 	CmdExpire              = "EXPIRE"
 	CmdExpireAt            = "EXPIREAT"
 	CmdExpireTime          = "EXPIRETIME"
 	CmdSet                 = "SET"
+	CmdSetEx               = "SETEX"
+	CmdPSetEx              = "PSETEX"
+	CmdSetNX               = "SETNX"
 	CmdGet                 = "GET"
 	CmdGetSet              = "GETSET"
 	CmdGetEx               = "GETEX"
@@ -173,6 +227,7 @@ const (
 	CmdRPop                = "RPOP"
 	CmdLLEN                = "LLEN"
 	CmdCommand             = "COMMAND"
+	CmdConfig              = "CONFIG"
 	CmdCommandCount        = "COMMAND|COUNT"
 	CmdCommandHelp         = "COMMAND|HELP"
 	CmdCommandInfo         = "COMMAND|INFO"
@@ -186,13 +241,15 @@ const (
 const (
 	CmdMset     = "MSET"
 	CmdMget     = "MGET"
-	CmdSInter   = "SINTER"
-	CmdSDiff    = "SDIFF"
 	CmdJSONMget = "JSON.MGET"
 	CmdKeys     = "KEYS"
 	CmdTouch    = "TOUCH"
 	CmdDBSize   = "DBSIZE"
 	CmdFlushDB  = "FLUSHDB"
+	CmdFlushAll = "FLUSHALL"
+	CmdInfo     = "INFO"
+	CmdSlowlog  = "SLOWLOG"
+	CmdMemory   = "MEMORY"
 )
 
 // Multi-Step-Multi-Shard commands
@@ -201,6 +258,14 @@ const (
 	CmdCopy   = "COPY"
 )
 
+// Blocking commands
+const (
+	CmdBLPop    = "BLPOP"
+	CmdBRPop    = "BRPOP"
+	CmdBZPopMin = "BZPOPMIN"
+	CmdBZPopMax = "BZPOPMAX"
+)
+
 // Watch commands
 const (
 	CmdGetWatch       = "GET.WATCH"
@@ -237,6 +302,15 @@ type CmdMeta struct {
 	// ensures that any required information is retrieved and processed in advance. Use this when set
 	// preProcessingReq = true.
 	preProcessResponse func(thread *BaseIOThread, DiceDBCmd *cmd.DiceDBCmd) error
+
+	// longRunning indicates that the command may run for longer than defaultRequestTimeout, e.g. BLPOP
+	// waiting for its own timeout or EXPORT reading the whole keyspace. Such a command is not canceled
+	// after defaultRequestTimeout but as soon as its client disconnects.
+	longRunning bool
+
+	// keySpec locates the keys of the commands that are not executed as they are by the shards, registered
+	// along with the ones of the shards so that every key is extracted the same way, see cmd.KeySpec
+	keySpec cmd.KeySpec
 }
 
 var CommandsMeta = map[string]CmdMeta{
@@ -244,6 +318,15 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdSet: {
 		CmdType: SingleShard,
 	},
+	CmdSetEx: {
+		CmdType: SingleShard,
+	},
+	CmdPSetEx: {
+		CmdType: SingleShard,
+	},
+	CmdSetNX: {
+		CmdType: SingleShard,
+	},
 	CmdExpire: {
 		CmdType: SingleShard,
 	},
@@ -277,6 +360,12 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdSmembers: {
 		CmdType: SingleShard,
 	},
+	CmdSMisMember: {
+		CmdType: SingleShard,
+	},
+	CmdSRandMember: {
+		CmdType: SingleShard,
+	},
 	CmdHExists: {
 		CmdType: SingleShard,
 	},
@@ -316,12 +405,39 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdDel: {
 		CmdType: SingleShard,
 	},
+	CmdDelIfEq: {
+		CmdType: SingleShard,
+	},
+	CmdCAS: {
+		CmdType: SingleShard,
+	},
 	CmdExists: {
 		CmdType: SingleShard,
 	},
 	CmdPersist: {
 		CmdType: SingleShard,
 	},
+	CmdPExpireIfEq: {
+		CmdType: SingleShard,
+	},
+	CmdLock: {
+		CmdType: SingleShard,
+	},
+	CmdUnlock: {
+		CmdType: SingleShard,
+	},
+	CmdUndelete: {
+		CmdType: SingleShard,
+	},
+	CmdGetAt: {
+		CmdType: SingleShard,
+	},
+	CmdHistory: {
+		CmdType: SingleShard,
+	},
+	CmdPExpire: {
+		CmdType: SingleShard,
+	},
 	CmdTypeOf: {
 		CmdType: SingleShard,
 	},
@@ -358,6 +474,9 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdGetRange: {
 		CmdType: SingleShard,
 	},
+	CmdSetRange: {
+		CmdType: SingleShard,
+	},
 	CmdPFAdd: {
 		CmdType: SingleShard,
 	},
@@ -466,6 +585,24 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdHMGet: {
 		CmdType: SingleShard,
 	},
+	CmdHExpire: {
+		CmdType: SingleShard,
+	},
+	CmdHPExpire: {
+		CmdType: SingleShard,
+	},
+	CmdHPersist: {
+		CmdType: SingleShard,
+	},
+	CmdHTTL: {
+		CmdType: SingleShard,
+	},
+	CmdHGetEx: {
+		CmdType: SingleShard,
+	},
+	CmdHGetDel: {
+		CmdType: SingleShard,
+	},
 	// Sorted set commands
 	CmdZAdd: {
 		CmdType: SingleShard,
@@ -482,6 +619,9 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdZCard: {
 		CmdType: SingleShard,
 	},
+	CmdZRandMember: {
+		CmdType: SingleShard,
+	},
 	CmdZRem: {
 		CmdType: SingleShard,
 	},
@@ -553,6 +693,9 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdCommand: {
 		CmdType: SingleShard,
 	},
+	CmdConfig: {
+		CmdType: SingleShard,
+	},
 	CmdCommandCount: {
 		CmdType: SingleShard,
 	},
@@ -582,6 +725,7 @@ var CommandsMeta = map[string]CmdMeta{
 		preProcessResponse: preProcessRename,
 		decomposeCommand:   decomposeRename,
 		composeResponse:    composeRename,
+		keySpec:            cmd.KeySpec{BeginIndex: 1, LastKey: 2},
 	},
 
 	CmdCopy: {
@@ -590,41 +734,34 @@ var CommandsMeta = map[string]CmdMeta{
 		preProcessResponse: customProcessCopy,
 		decomposeCommand:   decomposeCopy,
 		composeResponse:    composeCopy,
+		keySpec:            cmd.KeySpec{BeginIndex: 1, LastKey: 2},
 	},
 
 	CmdMset: {
 		CmdType:          MultiShard,
 		decomposeCommand: decomposeMSet,
 		composeResponse:  composeMSet,
+		keySpec:          cmd.KeySpec{BeginIndex: 1, Step: 2, LastKey: -1},
 	},
 
 	CmdMget: {
 		CmdType:          MultiShard,
 		decomposeCommand: decomposeMGet,
 		composeResponse:  composeMGet,
-	},
-
-	CmdSInter: {
-		CmdType:          MultiShard,
-		decomposeCommand: decomposeSInter,
-		composeResponse:  composeSInter,
-	},
-
-	CmdSDiff: {
-		CmdType:          MultiShard,
-		decomposeCommand: decomposeSDiff,
-		composeResponse:  composeSDiff,
+		keySpec:          cmd.KeySpec{BeginIndex: 1, LastKey: -1},
 	},
 
 	CmdJSONMget: {
 		CmdType:          MultiShard,
 		decomposeCommand: decomposeJSONMget,
 		composeResponse:  composeJSONMget,
+		keySpec:          cmd.KeySpec{BeginIndex: 1, LastKey: -2},
 	},
 	CmdTouch: {
 		CmdType:          MultiShard,
 		decomposeCommand: decomposeTouch,
 		composeResponse:  composeTouch,
+		keySpec:          cmd.KeySpec{BeginIndex: 1, LastKey: -1},
 	},
 	CmdDBSize: {
 		CmdType:          AllShard,
@@ -641,6 +778,26 @@ var CommandsMeta = map[string]CmdMeta{
 		decomposeCommand: decomposeFlushDB,
 		composeResponse:  composeFlushDB,
 	},
+	CmdFlushAll: {
+		CmdType:          AllShard,
+		decomposeCommand: decomposeFlushDB,
+		composeResponse:  composeFlushDB,
+	},
+	CmdInfo: {
+		CmdType:          AllShard,
+		decomposeCommand: decomposeInfo,
+		composeResponse:  composeInfo,
+	},
+	CmdSlowlog: {
+		CmdType:          AllShard,
+		decomposeCommand: decomposeSlowlog,
+		composeResponse:  composeSlowlog,
+	},
+	CmdMemory: {
+		CmdType:          AllShard,
+		decomposeCommand: decomposeMemory,
+		composeResponse:  composeMemory,
+	},
 
 	// Custom commands.
 	CmdAbort: {
@@ -652,30 +809,127 @@ var CommandsMeta = map[string]CmdMeta{
 	CmdEcho: {
 		CmdType: Custom,
 	},
+	CmdHello: {
+		CmdType: Custom,
+	},
 	CmdPing: {
 		CmdType: Custom,
 	},
+	CmdDebug: {
+		CmdType: Custom,
+	},
+	CmdShutdown: {
+		CmdType: Custom,
+	},
+	CmdMulti: {
+		CmdType: Custom,
+	},
+	CmdExec: {
+		CmdType: Custom,
+	},
+	CmdDiscard: {
+		CmdType: Custom,
+	},
+	CmdExport: {
+		CmdType:     Custom,
+		longRunning: true,
+	},
+	CmdSink: {
+		CmdType: Custom,
+	},
+	CmdNamespace: {
+		CmdType: Custom,
+	},
+	CmdHotKeys: {
+		CmdType: Custom,
+	},
+	CmdBigKeys: {
+		CmdType: Custom,
+	},
+	CmdModule: {
+		CmdType: Custom,
+	},
+	CmdReadAfter: {
+		CmdType: Custom,
+	},
+	CmdTrace: {
+		CmdType: Custom,
+	},
+	CmdQuit: {
+		CmdType: Custom,
+	},
+	CmdReset: {
+		CmdType: Custom,
+	},
+	CmdSInter: {
+		CmdType: Custom,
+		keySpec: cmd.KeySpec{BeginIndex: 1, LastKey: -1},
+	},
+	CmdSInterCard: {
+		CmdType: Custom,
+		keySpec: cmd.KeySpec{BeginIndex: 2, KeyNumIndex: 1},
+	},
+	CmdSUnion: {
+		CmdType: Custom,
+		keySpec: cmd.KeySpec{BeginIndex: 1, LastKey: -1},
+	},
+	CmdSDiff: {
+		CmdType: Custom,
+		keySpec: cmd.KeySpec{BeginIndex: 1, LastKey: -1},
+	},
+	CmdMSetCAS: {
+		CmdType: Custom,
+		keySpec: cmd.KeySpec{BeginIndex: 2, Step: 2, LastKey: -2},
+	},
+
+	// Blocking commands
+	CmdBLPop: {
+		CmdType:     Blocking,
+		longRunning: true,
+		keySpec:     cmd.KeySpec{BeginIndex: 1, LastKey: -2},
+	},
+	CmdBRPop: {
+		CmdType:     Blocking,
+		longRunning: true,
+		keySpec:     cmd.KeySpec{BeginIndex: 1, LastKey: -2},
+	},
+	CmdBZPopMin: {
+		CmdType:     Blocking,
+		longRunning: true,
+		keySpec:     cmd.KeySpec{BeginIndex: 1, LastKey: -2},
+	},
+	CmdBZPopMax: {
+		CmdType:     Blocking,
+		longRunning: true,
+		keySpec:     cmd.KeySpec{BeginIndex: 1, LastKey: -2},
+	},
 
 	// Watch commands
 	CmdGetWatch: {
 		CmdType: Watch,
+		keySpec: cmd.KeySpec{BeginIndex: 1},
 	},
 	CmdZRangeWatch: {
 		CmdType: Watch,
+		keySpec: cmd.KeySpec{BeginIndex: 1},
 	},
 	CmdPFCountWatch: {
 		CmdType: Watch,
+		keySpec: cmd.KeySpec{BeginIndex: 1},
 	},
 
 	// Unwatch commands
 	CmdGetUnWatch: {
 		CmdType: Unwatch,
+		keySpec: cmd.KeySpec{BeginIndex: 1},
 	},
 	CmdZRangeUnWatch: {
 		CmdType: Unwatch,
+		keySpec: cmd.KeySpec{BeginIndex: 1},
 	},
 	CmdPFCountUnWatch: {
 		CmdType: Unwatch,
+		keySpec: cmd.KeySpec{BeginIndex: 1},
 	},
 }
 
@@ -684,6 +938,7 @@ func init() {
 		if err := validateCmdMeta(c, meta); err != nil {
 			slog.Error("error validating command metadata %s: %v", c, err)
 		}
+		cmd.RegisterKeySpec(c, meta.keySpec)
 	}
 }
 
@@ -698,7 +953,7 @@ func validateCmdMeta(c string, meta CmdMeta) error {
 		if meta.decomposeCommand == nil || meta.composeResponse == nil {
 			return fmt.Errorf("multi-shard command %s must have both decomposeCommand and composeResponse implemented", c)
 		}
-	case SingleShard, Watch, Unwatch, Custom:
+	case SingleShard, Watch, Unwatch, Custom, Blocking:
 		// No specific validations for these types currently
 	default:
 		return fmt.Errorf("unknown command type for %s", c)
@@ -0,0 +1,58 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/module"
+)
+
+// RespModule evaluates the MODULE command:
+//
+//	MODULE LIST
+//
+// MODULE LIST returns an entry per module loaded, in the order the modules were initialized, with its name, the
+// path of its plugin, empty for a compiled in module, and the names of its commands.
+func RespModule(args []string) interface{} {
+	if len(args) == 0 {
+		return diceerrors.ErrWrongArgumentCount(CmdModule)
+	}
+	switch sub := strings.ToUpper(args[0]); sub {
+	case "LIST":
+		if len(args) != 1 {
+			return diceerrors.ErrWrongArgumentCount(CmdModule + "|" + sub)
+		}
+		modules := module.Modules()
+		reply := make([]interface{}, 0, len(modules))
+		for _, m := range modules {
+			commands := make([]interface{}, len(m.Commands))
+			for i, c := range m.Commands {
+				commands[i] = c
+			}
+			reply = append(reply, []interface{}{"name", m.Name, "path", m.Path, "commands", commands})
+		}
+		if len(reply) == 0 {
+			return clientio.EmptyArray
+		}
+		return reply
+	default:
+		return diceerrors.ErrGeneral("unknown subcommand '" + args[0] + "'")
+	}
+}
@@ -0,0 +1,164 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/namespace"
+)
+
+var errConfined = errors.New("NOPERM a tenant can not leave its namespace")
+
+// RespNamespace evaluates the NAMESPACE command, managing the namespace of the connection:
+//
+//	NAMESPACE
+//	NAMESPACE USE name|default
+//	NAMESPACE LIST
+//	NAMESPACE INFO [name]
+//	NAMESPACE SETQUOTA name [MAXKEYS n] [MAXMEMORY bytes] [MAXOPS n]
+//
+// The clients authenticated as a tenant are confined to its namespace: they can not use another one, nor
+// list the namespaces or set their quotas.
+func (t *BaseIOThread) RespNamespace(args []string) interface{} {
+	if len(args) == 0 {
+		if t.namespace == nil {
+			return namespace.Default
+		}
+		return t.namespace.Name()
+	}
+	if !config.DiceConfig().Namespaces.Enabled {
+		return namespace.ErrDisabled
+	}
+
+	switch sub := strings.ToUpper(args[0]); sub {
+	case "USE":
+		if len(args) != 2 {
+			return diceerrors.ErrWrongArgumentCount(CmdNamespace + "|" + sub)
+		}
+		if t.confined {
+			return errConfined
+		}
+		if args[1] == namespace.Default {
+			t.namespace = nil
+			return clientio.OK
+		}
+		ns, err := namespace.Create(args[1])
+		if err != nil {
+			return err
+		}
+		t.namespace = ns
+		return clientio.OK
+	case "LIST":
+		if len(args) != 1 {
+			return diceerrors.ErrWrongArgumentCount(CmdNamespace + "|" + sub)
+		}
+		if t.confined {
+			return namespace.ErrNotAllowed
+		}
+		list := namespace.List()
+		names := make([]string, 0, len(list))
+		for _, ns := range list {
+			names = append(names, ns.Name())
+		}
+		return names
+	case "INFO":
+		if len(args) > 2 {
+			return diceerrors.ErrWrongArgumentCount(CmdNamespace + "|" + sub)
+		}
+		ns := t.namespace
+		if len(args) == 2 {
+			if t.confined && args[1] != ns.Name() {
+				return namespace.ErrNotAllowed
+			}
+			if args[1] == namespace.Default {
+				return describeNamespace(namespace.DefaultInfo())
+			}
+			ns = namespace.Get(args[1])
+			if ns == nil {
+				return namespace.ErrNoSuchName
+			}
+		}
+		// The default namespace is the whole keyspace, its usage is the one of the keys of no namespace
+		if ns == nil {
+			return describeNamespace(namespace.DefaultInfo())
+		}
+		return describeNamespace(ns.Info())
+	case "SETQUOTA":
+		if len(args) < 2 {
+			return diceerrors.ErrWrongArgumentCount(CmdNamespace + "|" + sub)
+		}
+		if t.confined {
+			return namespace.ErrNotAllowed
+		}
+		ns := namespace.Get(args[1])
+		if ns == nil {
+			return namespace.ErrNoSuchName
+		}
+		quota, err := parseQuota(args[2:])
+		if err != nil {
+			return err
+		}
+		ns.SetQuota(quota)
+		return clientio.OK
+	default:
+		return diceerrors.ErrGeneral("unknown subcommand '" + args[0] + "'")
+	}
+}
+
+// parseQuota parses the limits of NAMESPACE SETQUOTA, the limits not given following the config.
+func parseQuota(args []string) (namespace.Quota, error) {
+	quota := namespace.Quota{MaxKeys: -1, MaxMemory: -1, MaxOpsPerSec: -1}
+	if len(args)%2 != 0 {
+		return quota, diceerrors.ErrSyntax
+	}
+	for i := 0; i < len(args); i += 2 {
+		value, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil || value < 0 {
+			return quota, diceerrors.ErrIntegerOutOfRange
+		}
+		switch strings.ToUpper(args[i]) {
+		case "MAXKEYS":
+			quota.MaxKeys = value
+		case "MAXMEMORY":
+			quota.MaxMemory = value
+		case "MAXOPS":
+			quota.MaxOpsPerSec = value
+		default:
+			return quota, diceerrors.ErrSyntax
+		}
+	}
+	return quota, nil
+}
+
+// describeNamespace returns the usage and the quota of a namespace as the fields of NAMESPACE INFO
+func describeNamespace(info namespace.Info) []interface{} {
+	return []interface{}{
+		"name", info.Name,
+		"keys", info.Keys,
+		"memory", info.Memory,
+		"max_keys", info.MaxKeys,
+		"max_memory", info.MaxMemory,
+		"max_ops_per_sec", info.MaxOpsPerSec,
+		"rejected", info.Rejected,
+	}
+}
@@ -31,14 +31,14 @@ func preProcessRename(thread *BaseIOThread, diceDBCmd *cmd.DiceDBCmd) error {
 	}
 
 	key := diceDBCmd.Args[0]
-	sid, rc := thread.shardManager.GetShardInfo(key)
+	sid, _ := thread.shardManager.GetShardInfo(key)
 
 	preCmd := cmd.DiceDBCmd{
 		Cmd:  "RENAME",
 		Args: []string{key},
 	}
 
-	rc <- &ops.StoreOp{
+	thread.shardManager.GetShard(sid).Send(&ops.StoreOp{
 		SeqID:         0,
 		RequestID:     GenerateUniqueRequestID(),
 		Cmd:           &preCmd,
@@ -46,7 +46,7 @@ func preProcessRename(thread *BaseIOThread, diceDBCmd *cmd.DiceDBCmd) error {
 		ShardID:       sid,
 		Client:        nil,
 		PreProcessing: true,
-	}
+	})
 
 	return nil
 }
@@ -59,7 +59,7 @@ func customProcessCopy(thread *BaseIOThread, diceDBCmd *cmd.DiceDBCmd) error {
 		return diceerrors.ErrWrongArgumentCount("COPY")
 	}
 
-	sid, rc := thread.shardManager.GetShardInfo(diceDBCmd.Args[0])
+	sid, _ := thread.shardManager.GetShardInfo(diceDBCmd.Args[0])
 
 	preCmd := cmd.DiceDBCmd{
 		Cmd:  "COPY",
@@ -67,7 +67,7 @@ func customProcessCopy(thread *BaseIOThread, diceDBCmd *cmd.DiceDBCmd) error {
 	}
 
 	// Need to get response from both keys to handle Replace or not
-	rc <- &ops.StoreOp{
+	thread.shardManager.GetShard(sid).Send(&ops.StoreOp{
 		SeqID:         0,
 		RequestID:     GenerateUniqueRequestID(),
 		Cmd:           &preCmd,
@@ -75,7 +75,7 @@ func customProcessCopy(thread *BaseIOThread, diceDBCmd *cmd.DiceDBCmd) error {
 		ShardID:       sid,
 		Client:        nil,
 		PreProcessing: true,
-	}
+	})
 
 	return nil
 }
@@ -0,0 +1,75 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/bridge"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// RespReadAfter evaluates the READAFTER command, giving the reads of the connection read-your-writes
+// consistency when the server follows an upstream as one of its replicas:
+//
+//	READAFTER offset
+//
+// The offset is the session token of the client, the master_repl_offset of the upstream once it replied to
+// the writes of the client. The following reads of the connection wait for the replication stream to be
+// applied up to the offset, see awaitReadAfter. READAFTER 0 reads whatever the offset applied.
+func (t *BaseIOThread) RespReadAfter(args []string) interface{} {
+	if len(args) != 1 {
+		return diceerrors.ErrWrongArgumentCount(CmdReadAfter)
+	}
+	offset, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || offset < 0 {
+		return diceerrors.ErrIntegerOutOfRange
+	}
+	t.readAfter = offset
+	return clientio.OK
+}
+
+// awaitReadAfter waits for the replication offset required by the connection to be applied before a read of
+// keys is executed, for up to bridge.read_after_timeout. The read is refused with a LAGGING error holding the
+// address of the upstream if the offset is not applied by then, for the client to read from the upstream.
+func (t *BaseIOThread) awaitReadAfter(ctx context.Context, c *cmd.DiceDBCmd) error {
+	if t.readAfter == 0 || audit.Categorize(c.Cmd) != audit.CategoryRead {
+		return nil
+	}
+	if _, ok := cmd.LookupKeySpec(c.Cmd); !ok {
+		return nil
+	}
+	applied, upstream, ok := bridge.Offset()
+	if !ok || applied >= t.readAfter {
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, config.DiceConfig().Bridge.ReadAfterTimeout)
+	defer cancel()
+	if bridge.WaitOffset(waitCtx, t.readAfter) {
+		return nil
+	}
+	applied, _, _ = bridge.Offset()
+	return diceerrors.New(diceerrors.CodeLagging,
+		fmt.Sprintf("the replica applied the offset %d, not %d, read from %s", applied, t.readAfter, upstream))
+}
@@ -0,0 +1,322 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/store"
+)
+
+// setMembershipBatch is the number of members checked by a single SMISMEMBER
+const setMembershipBatch = 1024
+
+// setOperand is a key of a set algebra command along with the cardinality of its set
+type setOperand struct {
+	key  string
+	card int
+}
+
+// handleSetAlgebra serves SINTER, SINTERCARD, SUNION and SDIFF. Rather than reading every set, the
+// io-thread starts from the smallest candidate members and, for each of the other keys, either reads
+// the set when it is smaller than the candidates or asks its shard which candidates it holds, stopping
+// as soon as the result is decided. The keys are read one after the other, as with the multi-shard
+// commands the result is not computed at a single point in time.
+func (t *BaseIOThread) handleSetAlgebra(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) error {
+	var resp interface{}
+	var err error
+	switch diceDBCmd.Cmd {
+	case CmdSInter:
+		resp, err = t.sinter(ctx, diceDBCmd.Args)
+	case CmdSInterCard:
+		resp, err = t.sintercard(ctx, diceDBCmd.Args)
+	case CmdSUnion:
+		resp, err = t.sunion(ctx, diceDBCmd.Args)
+	case CmdSDiff:
+		resp, err = t.sdiff(ctx, diceDBCmd.Args)
+	}
+	if err != nil {
+		resp = err
+	}
+
+	t.logCommand(diceDBCmd, resp)
+	return t.writeResponse(ctx, resp)
+}
+
+func (t *BaseIOThread) sinter(ctx context.Context, args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, diceerrors.ErrWrongArgumentCount("SINTER")
+	}
+	return t.intersectSets(ctx, args, 0)
+}
+
+// sintercard evaluates SINTERCARD numkeys key [key ...] [LIMIT limit]. The intersection stops growing once
+// it reaches the limit, 0 standing for no limit.
+func (t *BaseIOThread) sintercard(ctx context.Context, args []string) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, diceerrors.ErrWrongArgumentCount("SINTERCARD")
+	}
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, diceerrors.ErrIntegerOutOfRange
+	}
+	if numKeys <= 0 {
+		return nil, diceerrors.ErrGeneral("numkeys should be greater than 0")
+	}
+	if numKeys > len(args)-1 {
+		return nil, diceerrors.ErrGeneral("Number of keys can't be greater than number of args")
+	}
+
+	limit := 0
+	rest := args[1+numKeys:]
+	switch {
+	case len(rest) == 0:
+	case len(rest) == 2 && strings.EqualFold(rest[0], "LIMIT"):
+		if limit, err = strconv.Atoi(rest[1]); err != nil {
+			return nil, diceerrors.ErrIntegerOutOfRange
+		}
+		if limit < 0 {
+			return nil, diceerrors.ErrGeneral("LIMIT can't be negative")
+		}
+	default:
+		return nil, diceerrors.ErrSyntax
+	}
+
+	members, err := t.intersectSets(ctx, args[1:1+numKeys], limit)
+	if err != nil {
+		return nil, err
+	}
+	return int64(len(members)), nil
+}
+
+func (t *BaseIOThread) sunion(ctx context.Context, args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, diceerrors.ErrWrongArgumentCount("SUNION")
+	}
+	operands, err := t.setOperands(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	union := make(map[string]struct{})
+	for _, op := range operands {
+		if op.card == 0 {
+			continue
+		}
+		members, err := t.setMembers(ctx, op.key)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			union[m] = struct{}{}
+		}
+	}
+
+	resp := make([]string, 0, len(union))
+	for m := range union {
+		resp = append(resp, m)
+	}
+	return resp, nil
+}
+
+func (t *BaseIOThread) sdiff(ctx context.Context, args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, diceerrors.ErrWrongArgumentCount("SDIFF")
+	}
+	operands, err := t.setOperands(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if operands[0].card == 0 {
+		return []string{}, nil
+	}
+
+	candidates, err := t.setMembers(ctx, operands[0].key)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range operands[1:] {
+		if len(candidates) == 0 {
+			break
+		}
+		if op.card == 0 {
+			continue
+		}
+		if op.card < len(candidates) {
+			members, err := t.setMembers(ctx, op.key)
+			if err != nil {
+				return nil, err
+			}
+			candidates = subtractMembers(candidates, members)
+		} else if candidates, err = t.filterMembers(ctx, op.key, candidates, false, 0); err != nil {
+			return nil, err
+		}
+	}
+	return candidates, nil
+}
+
+// intersectSets returns the intersection of the sets of the keys, or its first limit members when limit
+// is not 0. The sets are intersected from the smallest one on.
+func (t *BaseIOThread) intersectSets(ctx context.Context, keys []string, limit int) ([]string, error) {
+	operands, err := t.setOperands(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(operands, func(a, b setOperand) int {
+		return a.card - b.card
+	})
+	if operands[0].card == 0 {
+		return []string{}, nil
+	}
+
+	candidates, err := t.setMembers(ctx, operands[0].key)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range operands[1:] {
+		if len(candidates) == 0 {
+			break
+		}
+		// Only the last set can stop the intersection at the limit, the candidates may still be
+		// dropped by the sets before it
+		opLimit := 0
+		if i == len(operands)-2 {
+			opLimit = limit
+		}
+		if op.card < len(candidates) {
+			members, err := t.setMembers(ctx, op.key)
+			if err != nil {
+				return nil, err
+			}
+			candidates = intersectMembers(candidates, members, opLimit)
+		} else if candidates, err = t.filterMembers(ctx, op.key, candidates, true, opLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+// setOperands returns the cardinality of the set of each key. It also checks that every key holds a set
+// before any set is read.
+func (t *BaseIOThread) setOperands(ctx context.Context, keys []string) ([]setOperand, error) {
+	operands := make([]setOperand, 0, len(keys))
+	for _, key := range keys {
+		resp, err := t.executeOnKeyShard(ctx, &cmd.DiceDBCmd{Cmd: store.Scard, Args: []string{key}})
+		if err != nil {
+			return nil, err
+		}
+		card, ok := resp.(int)
+		if !ok {
+			return nil, diceerrors.ErrInternalServer
+		}
+		operands = append(operands, setOperand{key: key, card: card})
+	}
+	return operands, nil
+}
+
+// setMembers returns the members of the set of the key
+func (t *BaseIOThread) setMembers(ctx context.Context, key string) ([]string, error) {
+	resp, err := t.executeOnKeyShard(ctx, &cmd.DiceDBCmd{Cmd: store.Smembers, Args: []string{key}})
+	if err != nil {
+		return nil, err
+	}
+	members, ok := resp.([]string)
+	if !ok {
+		return nil, diceerrors.ErrInternalServer
+	}
+	return members, nil
+}
+
+// filterMembers returns the candidates that are members of the set of the key when keep is true, the
+// ones that are not when it is false. The candidates are checked by batches with SMISMEMBER, and no more
+// are checked once limit candidates are returned, limit 0 standing for no limit.
+func (t *BaseIOThread) filterMembers(ctx context.Context, key string, candidates []string, keep bool,
+	limit int) ([]string, error) {
+	filtered := make([]string, 0, len(candidates))
+	for start := 0; start < len(candidates); start += setMembershipBatch {
+		batch := candidates[start:min(start+setMembershipBatch, len(candidates))]
+		resp, err := t.executeOnKeyShard(ctx, &cmd.DiceDBCmd{
+			Cmd:  store.SMisMember,
+			Args: append([]string{key}, batch...),
+		})
+		if err != nil {
+			return nil, err
+		}
+		found, ok := resp.([]int64)
+		if !ok || len(found) != len(batch) {
+			return nil, diceerrors.ErrInternalServer
+		}
+
+		for i, member := range batch {
+			if (found[i] == 1) == keep {
+				filtered = append(filtered, member)
+				if limit > 0 && len(filtered) == limit {
+					return filtered, nil
+				}
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// executeOnKeyShard runs a command on the shard owning its first argument and returns its result.
+func (t *BaseIOThread) executeOnKeyShard(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) (interface{}, error) {
+	shardID, _ := t.shardManager.GetShardInfo(diceDBCmd.Args[0])
+	return t.executeOnShard(ctx, uint8(shardID), diceDBCmd)
+}
+
+// intersectMembers returns the candidates that are members too, at most limit of them when limit is not 0.
+func intersectMembers(candidates, members []string, limit int) []string {
+	index := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		index[m] = struct{}{}
+	}
+	result := make([]string, 0, min(len(candidates), len(members)))
+	for _, c := range candidates {
+		if _, ok := index[c]; ok {
+			result = append(result, c)
+			if limit > 0 && len(result) == limit {
+				break
+			}
+		}
+	}
+	return result
+}
+
+// subtractMembers returns the candidates that are not members.
+func subtractMembers(candidates, members []string) []string {
+	index := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		index[m] = struct{}{}
+	}
+	result := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if _, ok := index[c]; !ok {
+			result = append(result, c)
+		}
+	}
+	return result
+}
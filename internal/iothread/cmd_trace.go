@@ -0,0 +1,77 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/clientio/iohandler"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// RespTrace evaluates the TRACE command, recording the time the commands of the connection spend in every stage
+// of their execution, the RESP2 replies having no room for it:
+//
+//	TRACE ON | OFF | LAST
+//
+// TRACE ON records the stages of the following commands of the connection, until TRACE OFF. TRACE LAST returns
+// the stages of the last command recorded, TRACE itself excepted, as a flat list of stage and microseconds:
+// parse_usec, queue_usec, eval_usec, encode_usec and write_usec. It returns NIL when no command was recorded.
+func (t *BaseIOThread) RespTrace(args []string) interface{} {
+	if len(args) != 1 {
+		return diceerrors.ErrWrongArgumentCount(CmdTrace)
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "ON":
+		t.trace = true
+	case "OFF":
+		t.trace = false
+	case "LAST":
+		if t.lastTiming == nil {
+			return clientio.NIL
+		}
+		s := t.lastTiming.Stages()
+		return []interface{}{
+			"parse_usec", s.ParseUsec,
+			"queue_usec", s.QueueUsec,
+			"eval_usec", s.EvalUsec,
+			"encode_usec", s.EncodeUsec,
+			"write_usec", s.WriteUsec,
+		}
+	default:
+		return diceerrors.ErrSyntax
+	}
+	return clientio.OK
+}
+
+// traced records the stages of the command executed once it is replied to, unless it is TRACE itself. The
+// encoding and the write of the reply are the ones of the last response written to the connection.
+func (t *BaseIOThread) traced(name string) {
+	timing := t.timing
+	t.timing = nil
+	if timing == nil || name == CmdTrace {
+		return
+	}
+	if w, ok := t.ioHandler.(iohandler.TimedWriter); ok {
+		encode, write := w.LastWrite()
+		timing.Encoded(encode)
+		timing.Written(write)
+	}
+	t.lastTiming = timing
+}
@@ -0,0 +1,163 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/tracing"
+)
+
+// transaction holds the commands queued by a client between MULTI and EXEC.
+type transaction struct {
+	cmds   []*cmd.DiceDBCmd
+	failed bool // failed is set when a command could not be queued, EXEC then discards the transaction
+}
+
+// isTxnCommand reports whether the command controls the transaction rather than being queued by it.
+func isTxnCommand(name string) bool {
+	return name == CmdMulti || name == CmdExec || name == CmdDiscard
+}
+
+// isConnectionCommand reports whether the command acts on the connection itself. These commands are served
+// whatever the state of the connection, as proxies send them to recycle a connection of their pool.
+func isConnectionCommand(name string) bool {
+	return name == CmdQuit || name == CmdReset
+}
+
+// flagTxn marks the transaction in progress, if any, to be discarded by EXEC.
+func (t *BaseIOThread) flagTxn() {
+	if t.txn != nil {
+		t.txn.failed = true
+	}
+}
+
+// queueTxnCommand queues the command in the transaction and replies QUEUED. Only the commands executed by
+// a single shard can be queued: the others are refused and the transaction is flagged to be discarded.
+func (t *BaseIOThread) queueTxnCommand(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) error {
+	meta, ok := CommandsMeta[diceDBCmd.Cmd]
+	if !ok {
+		if _, ok := eval.DiceCmds[diceDBCmd.Cmd]; !ok {
+			t.flagTxn()
+			return t.writeResponse(ctx, diceerrors.ErrUnknownCmdWithArgs(diceDBCmd.Cmd, diceDBCmd.Args))
+		}
+	} else if meta.CmdType != SingleShard || meta.preProcessing {
+		t.flagTxn()
+		return t.writeResponse(ctx, diceerrors.ErrCmdNotAllowedInTxn(diceDBCmd.Cmd))
+	}
+
+	t.txn.cmds = append(t.txn.cmds, diceDBCmd)
+	return t.writeResponse(ctx, clientio.CommandQueued)
+}
+
+// RespMulti starts a transaction: the following commands are queued until EXEC or DISCARD.
+func (t *BaseIOThread) RespMulti(args []string) interface{} {
+	if len(args) != 0 {
+		return diceerrors.ErrWrongArgumentCount("MULTI")
+	}
+	if t.txn != nil {
+		return diceerrors.ErrMultiNested
+	}
+
+	t.txn = &transaction{}
+	return clientio.OK
+}
+
+// RespDiscard drops the commands queued since MULTI.
+func (t *BaseIOThread) RespDiscard(args []string) interface{} {
+	if len(args) != 0 {
+		return diceerrors.ErrWrongArgumentCount("DISCARD")
+	}
+	if t.txn == nil {
+		return diceerrors.ErrDiscardWithoutMulti
+	}
+
+	t.txn = nil
+	return clientio.OK
+}
+
+// RespExec executes the commands queued since MULTI atomically, even when their keys live on different shards,
+// and returns the array of their responses. It also returns the commands executed, nil if the transaction
+// was not applied.
+func (t *BaseIOThread) RespExec(ctx context.Context, args []string) (resp interface{}, committed []*cmd.DiceDBCmd) {
+	if len(args) != 0 {
+		return diceerrors.ErrWrongArgumentCount("EXEC"), nil
+	}
+	if t.txn == nil {
+		return diceerrors.ErrExecWithoutMulti, nil
+	}
+
+	txn := t.txn
+	t.txn = nil
+	if txn.failed {
+		return diceerrors.ErrExecAbort, nil
+	}
+	if len(txn.cmds) == 0 {
+		return []interface{}{}, nil
+	}
+
+	shards := make([]shard.ShardID, len(txn.cmds))
+	for i, c := range txn.cmds {
+		shards[i], _ = t.shardManager.GetShardInfo(getRoutingKeyFromCommand(c))
+	}
+
+	_, span := tracing.Start(ctx, tracing.SpanDispatch)
+	resps, err := t.shardManager.ExecTxn(ctx, &shard.Txn{
+		ID:          GenerateUniqueRequestID(),
+		IOThreadID:  t.id,
+		ClientAddr:  t.ioHandler.RemoteAddr(),
+		ClientID:    t.clientID,
+		SpanContext: tracing.SpanContext(ctx),
+		Cmds:        txn.cmds,
+		Shards:      shards,
+	})
+	span.End()
+	if err != nil {
+		return err, nil
+	}
+
+	results := make([]interface{}, len(resps))
+	for i, r := range resps {
+		if r.Error != nil {
+			results[i] = r.Error
+		} else {
+			results[i] = r.Result
+		}
+		t.logCommand(txn.cmds[i], results[i])
+	}
+	return results, txn.cmds
+}
+
+// logTxnToWAL appends the commands of a committed transaction to the WAL.
+func (t *BaseIOThread) logTxnToWAL(cmds []*cmd.DiceDBCmd) error {
+	if t.wl == nil {
+		return nil
+	}
+	for _, c := range cmds {
+		if err := t.wl.LogCommand([]byte(fmt.Sprintf("%s %s", c.Cmd, strings.Join(c.Args, " ")))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
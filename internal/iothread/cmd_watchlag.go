@@ -0,0 +1,76 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package iothread
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/internal/bridge"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/querymanager"
+	"github.com/dicedb/dice/internal/watchmanager"
+)
+
+// parseMaxLag removes the MAXLAG option from the arguments of a watched command and returns its bound, 0 if the
+// arguments do not end with it:
+//
+//	GET.WATCH key MAXLAG milliseconds
+//
+// When the server follows an upstream as one of its replicas, the results pushed to the subscription lag behind
+// the upstream by at most the bound, see checkWatchLag and pushLagging.
+func parseMaxLag(args []string) (time.Duration, []string, error) {
+	if len(args) < 2 || !strings.EqualFold(args[len(args)-2], "MAXLAG") {
+		return 0, args, nil
+	}
+	ms, err := strconv.ParseInt(args[len(args)-1], 10, 64)
+	if err != nil || ms <= 0 {
+		return 0, args, diceerrors.ErrGeneral("MAXLAG must be a positive number of milliseconds")
+	}
+	return time.Duration(ms) * time.Millisecond, args[:len(args)-2], nil
+}
+
+// checkWatchLag refuses a subscription whose bound the lag of the replica behind its upstream exceeds, with a
+// LAGGING error holding the address of the upstream for the client to watch the command there.
+func checkWatchLag(maxLag time.Duration) error {
+	if maxLag == 0 {
+		return nil
+	}
+	lag, upstream, ok := bridge.Lag()
+	if !ok || lag <= maxLag {
+		return nil
+	}
+	return laggingError(lag, upstream)
+}
+
+func laggingError(lag time.Duration, upstream string) error {
+	return diceerrors.New(diceerrors.CodeLagging,
+		fmt.Sprintf("the replica lags %d ms behind its upstream, beyond the bound of the subscription, watch from %s",
+			lag.Milliseconds(), upstream))
+}
+
+// pushLagging tells the subscriber that the results of its watched command are stale, as the lag of the replica
+// behind its upstream exceeds the bound of the subscription. The push holds a LAGGING error in place of the result,
+// the next result is pushed once the replica caught up.
+func (t *BaseIOThread) pushLagging(ctx context.Context, notification watchmanager.Notification) error {
+	fingerprint := notification.Cmd.GetFingerprint()
+	return t.writeResponse(ctx, querymanager.GenericWatchResponse(notification.Cmd.Cmd, strconv.FormatUint(uint64(fingerprint), 10),
+		laggingError(notification.Lag, notification.Upstream), t.watchSeqs[fingerprint]))
+}
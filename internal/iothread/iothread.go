@@ -29,24 +29,48 @@ import (
 	"time"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/accesslog"
+	"github.com/dicedb/dice/internal/admission"
+	"github.com/dicedb/dice/internal/audit"
 	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/cache"
 	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/clientio/iohandler"
 	"github.com/dicedb/dice/internal/clientio/requestparser"
 	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/connector"
 	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/metrics"
+	"github.com/dicedb/dice/internal/module"
+	"github.com/dicedb/dice/internal/namespace"
 	"github.com/dicedb/dice/internal/ops"
 	"github.com/dicedb/dice/internal/querymanager"
 	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/shutdown"
+	"github.com/dicedb/dice/internal/stats"
+	"github.com/dicedb/dice/internal/tracing"
 	"github.com/dicedb/dice/internal/wal"
 	"github.com/dicedb/dice/internal/watchmanager"
 	"github.com/google/uuid"
 )
 
-const defaultRequestTimeout = 6 * time.Second
+const (
+	defaultRequestTimeout = 6 * time.Second
+	// maxPendingSize is the maximal size of a command not entirely received, as the largest request read at once
+	maxPendingSize = 32 * 1024 * 1024
+)
+
+// errQuit is returned once the client asked to close the connection with QUIT.
+var errQuit = errors.New("client quit")
+
+// errShardsTimedOut is returned when the shards did not reply to a command within its timeout.
+var errShardsTimedOut = errors.New("timed out waiting for the shards")
 
 var requestCounter uint32
 
+// clientIDCounter hands out the ids of the clients, reported by HELLO
+var clientIDCounter atomic.Uint64
+
 // IOThread interface
 type IOThread interface {
 	ID() string
@@ -60,13 +84,30 @@ type BaseIOThread struct {
 	ioHandler                iohandler.IOHandler
 	parser                   requestparser.Parser
 	shardManager             *shard.ShardManager
-	adhocReqChan             chan *cmd.DiceDBCmd
+	adhocReqChan             chan watchmanager.Notification
 	Session                  *auth.Session
 	globalErrorChan          chan error
 	responseChan             chan *ops.StoreResponse
 	preprocessingChan        chan *ops.StoreResponse
 	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription
 	wl                       wal.AbstractWAL
+	commandStartedAt         time.Time            // time at which the command being executed was received
+	clientID                 uint64               // clientID identifies the connection, unique for the lifetime of the server
+	clientName               string               // clientName is the name set with HELLO SETNAME
+	watchSeqs                map[uint32]uint64    // watchSeqs is the sequence number of the last push of every subscription, by fingerprint
+	txn                      *transaction         // txn holds the commands queued since MULTI, nil outside of a transaction
+	disconnected             chan struct{}        // disconnected is closed once the client disconnects
+	connCtx                  context.Context      // connCtx is canceled once the client disconnects, it is the context of the operations sent to the shards
+	pending                  []byte               // pending is the start of a command whose end is not received yet
+	quitting                 bool                 // quitting is set by QUIT, the connection is closed once the reply is sent
+	inflight                 map[uint32]bool      // inflight is the request ids of the operations whose response is awaited
+	admission                *admission.Limiter   // admission bounds the commands executed at once by the frontend, nil for no bound
+	namespace                *namespace.Namespace // namespace is the namespace the client is confined to, nil for the default one
+	confined                 bool                 // confined is set once the client authenticates as a tenant, it can not leave its namespace
+	readAfter                int64                // readAfter is the replication offset the reads require, set with READAFTER, 0 for none
+	trace                    bool                 // trace is set with TRACE ON, the stages of the commands are recorded
+	timing                   *tracing.Timing      // timing records the stages of the command being executed when traced
+	lastTiming               *tracing.Timing      // lastTiming is the stages of the last command traced, returned by TRACE LAST
 }
 
 func NewIOThread(wid string, responseChan, preprocessingChan chan *ops.StoreResponse,
@@ -82,9 +123,13 @@ func NewIOThread(wid string, responseChan, preprocessingChan chan *ops.StoreResp
 		responseChan:             responseChan,
 		preprocessingChan:        preprocessingChan,
 		Session:                  auth.NewSession(),
-		adhocReqChan:             make(chan *cmd.DiceDBCmd, config.DiceConfig.Performance.AdhocReqChanBufSize),
+		adhocReqChan:             make(chan watchmanager.Notification, config.DiceConfig().Performance.AdhocReqChanBufSize),
 		cmdWatchSubscriptionChan: cmdWatchSubscriptionChan,
 		wl:                       wl,
+		clientID:                 clientIDCounter.Add(1),
+		watchSeqs:                make(map[uint32]uint64),
+		disconnected:             make(chan struct{}),
+		inflight:                 make(map[uint32]bool),
 	}
 }
 
@@ -100,6 +145,12 @@ func (t *BaseIOThread) Start(ctx context.Context) error {
 	runCtx, runCancel := context.WithCancel(ctx)
 	defer runCancel()
 
+	// The shards skip the reads of the operations of a disconnected client, nobody being left to reply to
+	connCtx, connCancel := t.untilDisconnected(ctx)
+	defer connCancel()
+	t.connCtx = connCtx
+	defer t.unwatchAll(ctx)
+
 	// This method is run in a separate goroutine to ensure that the main event loop in the Start method
 	// remains non-blocking and responsive to other events, such as adhoc requests or context cancellations.
 	go t.startInputReader(runCtx, incomingDataChan, readErrChan)
@@ -113,8 +164,8 @@ func (t *BaseIOThread) Start(ctx context.Context) error {
 			return ctx.Err()
 		case err := <-errChan:
 			return t.handleError(err)
-		case cmdReq := <-t.adhocReqChan:
-			t.handleCmdRequestWithTimeout(ctx, errChan, []*cmd.DiceDBCmd{cmdReq}, true, defaultRequestTimeout)
+		case notification := <-t.adhocReqChan:
+			t.handleWatchNotifications(ctx, errChan, notification)
 		case data := <-incomingDataChan:
 			if err := t.processIncomingData(ctx, &data, errChan); err != nil {
 				return err
@@ -127,6 +178,7 @@ func (t *BaseIOThread) Start(ctx context.Context) error {
 }
 
 // startInputReader continuously reads input data from the ioHandler and sends it to the incomingDataChan.
+// It closes the disconnected channel once reading fails, as the client disconnected.
 func (t *BaseIOThread) startInputReader(ctx context.Context, incomingDataChan chan []byte, readErrChan chan error) {
 	defer close(incomingDataChan)
 	defer close(readErrChan)
@@ -134,6 +186,7 @@ func (t *BaseIOThread) startInputReader(ctx context.Context, incomingDataChan ch
 	for {
 		data, err := t.ioHandler.Read(ctx)
 		if err != nil {
+			close(t.disconnected)
 			select {
 			case readErrChan <- err:
 			case <-ctx.Done():
@@ -159,11 +212,81 @@ func (t *BaseIOThread) handleError(err error) error {
 	return fmt.Errorf("error writing response: %v", err)
 }
 
+// processIncomingData executes the commands received, in order. The data read from the connection is not
+// aligned on commands: a command split across reads is kept until its end is received, so that the stream
+// stays in sync whatever the way the client or a proxy in front of the server writes it.
 func (t *BaseIOThread) processIncomingData(ctx context.Context, data *[]byte, errChan chan error) error {
-	commands, err := t.parser.Parse(*data)
+	if len(t.pending) == 0 {
+		t.pending = *data
+	} else {
+		t.pending = append(t.pending, *data...)
+	}
+
+	reqCtx, span := tracing.StartRequest(ctx, "resp", t.ioHandler.RemoteAddr())
+	defer func() { span.End() }()
+
+	parseStart := time.Now()
+	_, parseSpan := tracing.Start(reqCtx, tracing.SpanParse)
+	commands, n, err := t.parser.ParseFrames(t.pending)
+	parseSpan.End()
+	parse := time.Since(parseStart)
+
+	if t.pending = t.pending[n:]; len(t.pending) == 0 {
+		t.pending = nil
+	}
+	if err == nil && len(t.pending) > maxPendingSize {
+		err = errors.New("request too large")
+	}
 
+	for i, c := range commands {
+		if i > 0 {
+			span.End()
+			reqCtx, span = tracing.StartRequest(ctx, "resp", t.ioHandler.RemoteAddr())
+		}
+		// The commands read together share the time their parsing took
+		if t.trace {
+			t.timing = tracing.NewTiming(parse)
+		}
+		cmdErr := t.processCommand(reqCtx, c, errChan)
+		t.traced(c.Cmd)
+		if cmdErr != nil {
+			return cmdErr
+		}
+		if t.quitting {
+			return t.closeConnection(errQuit)
+		}
+	}
+
+	// The start of the next command can not be found once the stream is invalid, the connection is closed
+	// rather than executing the rest of the stream as commands
 	if err != nil {
-		err = t.ioHandler.Write(ctx, err)
+		if writeErr := t.ioHandler.Write(ctx, fmt.Errorf("ERR Protocol error: %v", err)); writeErr != nil {
+			slog.Debug("Write error, connection closed possibly", slog.String("id", t.id), slog.Any("error", writeErr))
+		}
+		return t.closeConnection(err)
+	}
+	return nil
+}
+
+// closeConnection closes the connection of the client, which stops the io-thread with the error.
+func (t *BaseIOThread) closeConnection(err error) error {
+	if closeErr := t.ioHandler.Close(); closeErr != nil {
+		slog.Debug("Error closing connection", slog.String("id", t.id), slog.Any("error", closeErr))
+	}
+	return err
+}
+
+// processCommand executes a command received from the client.
+func (t *BaseIOThread) processCommand(ctx context.Context, diceDBCmd *cmd.DiceDBCmd, errChan chan error) error {
+	stats.CommandProcessed()
+
+	// Disabled commands are reported exactly like commands that do not exist
+	if name, ok := cmd.CommandRenames.Resolve(diceDBCmd.Cmd); ok {
+		diceDBCmd.Cmd = name
+		tracing.SetCommand(ctx, name)
+	} else {
+		t.flagTxn()
+		err := t.ioHandler.Write(ctx, diceerrors.ErrUnknownCmdWithArgs(diceDBCmd.Cmd, diceDBCmd.Args))
 		if err != nil {
 			slog.Debug("Write error, connection closed possibly", slog.String("id", t.id), slog.Any("error", err))
 			return err
@@ -171,46 +294,133 @@ func (t *BaseIOThread) processIncomingData(ctx context.Context, data *[]byte, er
 		return nil
 	}
 
-	if len(commands) == 0 {
-		err = t.ioHandler.Write(ctx, fmt.Errorf("ERR: Invalid request"))
-		if err != nil {
+	err := t.isAuthenticated(diceDBCmd)
+	if err != nil {
+		writeErr := t.ioHandler.Write(ctx, err)
+		if writeErr != nil {
+			slog.Debug("Write error, connection closed possibly", slog.Any("error", errors.Join(err, writeErr)))
+			return errors.Join(err, writeErr)
+		}
+		return nil
+	}
+
+	if t.namespace != nil {
+		if err := t.namespace.Admit(diceDBCmd); err != nil {
+			t.flagTxn()
+			t.logCommand(diceDBCmd, err)
+			if err := t.ioHandler.Write(ctx, err); err != nil {
+				slog.Debug("Write error, connection closed possibly", slog.String("id", t.id), slog.Any("error", err))
+				return err
+			}
+			return nil
+		}
+	}
+
+	// The hooks of the modules see the keys of the command as stored, prefixed with the namespace
+	if err := module.Before(t.ioHandler.RemoteAddr(), t.username(), diceDBCmd); err != nil {
+		t.flagTxn()
+		t.logCommand(diceDBCmd, err)
+		if err := t.ioHandler.Write(ctx, err); err != nil {
 			slog.Debug("Write error, connection closed possibly", slog.String("id", t.id), slog.Any("error", err))
 			return err
 		}
 		return nil
 	}
 
-	// DiceDB supports clients to send only one request at a time
-	// We also need to ensure that the client is blocked until the response is received
-	if len(commands) > 1 {
-		err = t.ioHandler.Write(ctx, fmt.Errorf("ERR: Multiple commands not supported"))
-		if err != nil {
+	if err := t.awaitReadAfter(ctx, diceDBCmd); err != nil {
+		t.flagTxn()
+		t.logCommand(diceDBCmd, err)
+		if err := t.ioHandler.Write(ctx, err); err != nil {
 			slog.Debug("Write error, connection closed possibly", slog.String("id", t.id), slog.Any("error", err))
 			return err
 		}
+		return nil
 	}
 
-	err = t.isAuthenticated(commands[0])
-	if err != nil {
-		writeErr := t.ioHandler.Write(ctx, err)
-		if writeErr != nil {
-			slog.Debug("Write error, connection closed possibly", slog.Any("error", errors.Join(err, writeErr)))
-			return errors.Join(err, writeErr)
+	if t.txn != nil && !isTxnCommand(diceDBCmd.Cmd) && !isConnectionCommand(diceDBCmd.Cmd) {
+		return t.queueTxnCommand(ctx, diceDBCmd)
+	}
+
+	commands := []*cmd.DiceDBCmd{diceDBCmd}
+
+	// Long running commands, e.g. the blocking ones, run until they complete or the client disconnects
+	if meta, ok := CommandsMeta[diceDBCmd.Cmd]; ok && meta.longRunning {
+		blockCtx, cancel := t.untilDisconnected(ctx)
+		defer cancel()
+		_ = t.executeCommandHandler(blockCtx, errChan, commands, false)
+		return nil
+	}
+
+	// Long running commands are left out of the admission, they mostly wait rather than load the shards
+	if !t.admission.Acquire() {
+		if err := t.ioHandler.Write(ctx, diceerrors.ErrOverloaded); err != nil {
+			slog.Debug("Write error, connection closed possibly", slog.String("id", t.id), slog.Any("error", err))
+			return err
 		}
 		return nil
 	}
+	defer t.admission.Release()
 
-	t.handleCmdRequestWithTimeout(ctx, errChan, commands, false, defaultRequestTimeout)
+	_ = t.handleCmdRequestWithTimeout(ctx, errChan, commands, false, defaultRequestTimeout)
 	return nil
 }
 
-func (t *BaseIOThread) handleCmdRequestWithTimeout(ctx context.Context, errChan chan error, commands []*cmd.DiceDBCmd, isWatchNotification bool, timeout time.Duration) {
+// handleWatchNotifications runs the watched commands of the notification and of the ones already queued behind it.
+// Queued notifications of the same command are coalesced, as a single push carries the latest result anyway.
+func (t *BaseIOThread) handleWatchNotifications(ctx context.Context, errChan chan error, first watchmanager.Notification) {
+	notifications := []watchmanager.Notification{first}
+	pending := map[*cmd.DiceDBCmd]bool{first.Cmd: first.Lag == 0}
+	for queued := len(t.adhocReqChan); queued > 0; queued-- {
+		notification := <-t.adhocReqChan
+		// The notifications of a replica lagging behind its upstream are pushed as they are
+		if notification.Lag == 0 && pending[notification.Cmd] {
+			// The oldest change of the command is kept, to measure how late the push is
+			stats.WatchUpdateCoalesced()
+			continue
+		}
+		pending[notification.Cmd] = pending[notification.Cmd] || notification.Lag == 0
+		notifications = append(notifications, notification)
+	}
+
+	for _, notification := range notifications {
+		// The sequence number is taken even if the push is dropped, so that the client sees the gap
+		t.watchSeqs[notification.Cmd.GetFingerprint()]++
+
+		if notification.Lag > 0 {
+			if err := t.pushLagging(ctx, notification); err != nil {
+				stats.WatchUpdateDropped()
+			}
+			continue
+		}
+
+		start := time.Now()
+		if err := t.handleCmdRequestWithTimeout(ctx, errChan, []*cmd.DiceDBCmd{notification.Cmd}, true, defaultRequestTimeout); err != nil {
+			stats.WatchUpdateDropped()
+			continue
+		}
+		evaluation := time.Since(start)
+		stats.WatchUpdatePushed(evaluation, time.Since(notification.ChangedAt))
+		metrics.WatchUpdatePushed(notification.Cmd.Cmd, evaluation, time.Since(notification.ChangedAt))
+	}
+}
+
+func (t *BaseIOThread) handleCmdRequestWithTimeout(ctx context.Context, errChan chan error, commands []*cmd.DiceDBCmd, isWatchNotification bool, timeout time.Duration) error {
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	t.executeCommandHandler(execCtx, errChan, commands, isWatchNotification)
+
+	err := t.executeCommandHandler(execCtx, errChan, commands, isWatchNotification)
+	if errors.Is(err, errShardsTimedOut) && !isWatchNotification {
+		// The client gets an error rather than no reply at all
+		if werr := t.ioHandler.Write(ctx, diceerrors.ErrShardsTimedOut); werr != nil {
+			slog.Debug("Error sending timeout response to io-thread", slog.String("id", t.id), slog.Any("error", werr))
+		}
+	}
+	return err
 }
 
-func (t *BaseIOThread) executeCommandHandler(execCtx context.Context, errChan chan error, commands []*cmd.DiceDBCmd, isWatchNotification bool) {
+func (t *BaseIOThread) executeCommandHandler(execCtx context.Context, errChan chan error, commands []*cmd.DiceDBCmd, isWatchNotification bool) error {
+	t.commandStartedAt = time.Now()
+
 	// Retrieve metadata for the command to determine if multisharding is supported.
 	meta, ok := CommandsMeta[commands[0].Cmd]
 	if ok && meta.preProcessing {
@@ -230,6 +440,7 @@ func (t *BaseIOThread) executeCommandHandler(execCtx context.Context, errChan ch
 			errChan <- err
 		}
 	}
+	return err
 }
 
 func (t *BaseIOThread) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDBCmd, isWatchNotification bool) error {
@@ -237,6 +448,7 @@ func (t *BaseIOThread) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDB
 	// The length of cmdList helps determine how many shards to wait for responses.
 	cmdList := make([]*cmd.DiceDBCmd, 0)
 	var watchLabel string
+	var maxLag time.Duration
 
 	// Retrieve metadata for the command to determine if multisharding is supported.
 	meta, ok := CommandsMeta[diceDBCmd.Cmd]
@@ -253,8 +465,15 @@ func (t *BaseIOThread) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDB
 			return err
 
 		case SingleShard:
+			// The reads of the hot keys are served from their copies, when they are copied
+			if !isWatchNotification {
+				if served, err := t.serveReplica(ctx, diceDBCmd); served {
+					return err
+				}
+			}
 			// For single-shard or custom commands, process them without breaking up.
 			cmdList = append(cmdList, diceDBCmd)
+			readThrough(ctx, diceDBCmd)
 
 		case MultiShard, AllShard:
 			var err error
@@ -278,6 +497,9 @@ func (t *BaseIOThread) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDB
 		case Custom:
 			return t.handleCustomCommands(ctx, diceDBCmd)
 
+		case Blocking:
+			return t.handleBlockingCommand(ctx, diceDBCmd)
+
 		case Watch:
 			// Generate the Cmd being watched. All we need to do is remove the .WATCH suffix from the command and pass
 			// it along as is.
@@ -294,12 +516,25 @@ func (t *BaseIOThread) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDB
 				diceDBCmd.Args = diceDBCmd.Args[:len(diceDBCmd.Args)-1]
 			}
 
+			// The bound of the lag of the replica is not part of the command watched, nor of its fingerprint
+			var err error
+			if maxLag, diceDBCmd.Args, err = parseMaxLag(diceDBCmd.Args); err == nil {
+				err = checkWatchLag(maxLag)
+			}
+			if err != nil {
+				return t.ioHandler.Write(ctx, err)
+			}
+
 			watchCmd := &cmd.DiceDBCmd{
 				Cmd:  diceDBCmd.Cmd,
 				Args: diceDBCmd.Args,
 			}
 			cmdList = append(cmdList, watchCmd)
 			isWatchNotification = true
+			readThrough(ctx, watchCmd)
+
+			// The initial result of a subscription is its push 0, subscribing again starts over
+			t.watchSeqs[watchCmd.GetFingerprint()] = 0
 
 		case Unwatch:
 			// Generate the Cmd being unwatched. All we need to do is remove the .UNWATCH suffix from the command and pass
@@ -323,6 +558,9 @@ func (t *BaseIOThread) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDB
 
 	// Scatter the broken-down commands to the appropriate shards.
 	if err := t.scatter(ctx, cmdList, meta.CmdType); err != nil {
+		if errors.Is(err, diceerrors.ErrOverloaded) && !isWatchNotification {
+			return t.ioHandler.Write(ctx, err)
+		}
 		return err
 	}
 
@@ -333,7 +571,7 @@ func (t *BaseIOThread) executeCommand(ctx context.Context, diceDBCmd *cmd.DiceDB
 
 	if meta.CmdType == Watch {
 		// Proceed to subscribe after successful execution
-		t.handleCommandWatch(cmdList)
+		t.handleCommandWatch(cmdList, maxLag)
 	}
 
 	return nil
@@ -343,7 +581,9 @@ func (t *BaseIOThread) handleCustomCommands(ctx context.Context, diceDBCmd *cmd.
 	// if command is of type Custom, write a custom logic around it
 	switch diceDBCmd.Cmd {
 	case CmdAuth:
-		err := t.ioHandler.Write(ctx, t.RespAuth(diceDBCmd.Args))
+		resp := t.RespAuth(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
 		if err != nil {
 			slog.Error("Error sending auth response to io-thread", slog.String("id", t.id), slog.Any("error", err))
 		}
@@ -355,12 +595,39 @@ func (t *BaseIOThread) handleCustomCommands(ctx context.Context, diceDBCmd *cmd.
 		}
 		return err
 	case CmdAbort:
+		t.logCommand(diceDBCmd, nil)
 		err := t.ioHandler.Write(ctx, clientio.OK)
 		if err != nil {
 			slog.Error("Error sending abort response to io-thread", slog.String("id", t.id), slog.Any("error", err))
 		}
 		slog.Info("Received ABORT command, initiating server shutdown", slog.String("id", t.id))
-		t.globalErrorChan <- diceerrors.ErrAborted
+		t.globalErrorChan <- &shutdown.Request{Mode: shutdown.NoSave}
+		return err
+	case CmdShutdown:
+		req, err := shutdown.ParseArgs(diceDBCmd.Args)
+		if err != nil {
+			t.logCommand(diceDBCmd, err)
+			if err := t.ioHandler.Write(ctx, err); err != nil {
+				slog.Error("Error sending shutdown response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+				return err
+			}
+			return nil
+		}
+		t.logCommand(diceDBCmd, nil)
+		err = t.ioHandler.Write(ctx, clientio.OK)
+		if err != nil {
+			slog.Error("Error sending shutdown response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		slog.Info("Received SHUTDOWN command, initiating server shutdown", slog.String("id", t.id))
+		t.globalErrorChan <- req
+		return err
+	case CmdDebug:
+		resp := t.RespDebug(ctx, diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending debug response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
 		return err
 	case CmdPing:
 		err := t.ioHandler.Write(ctx, RespPING(diceDBCmd.Args))
@@ -369,7 +636,9 @@ func (t *BaseIOThread) handleCustomCommands(ctx context.Context, diceDBCmd *cmd.
 		}
 		return err
 	case CmdHello:
-		err := t.ioHandler.Write(ctx, RespHello(diceDBCmd.Args))
+		resp := t.RespHello(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
 		if err != nil {
 			slog.Error("Error sending ping response to io-thread", slog.String("id", t.id), slog.Any("error", err))
 		}
@@ -380,17 +649,122 @@ func (t *BaseIOThread) handleCustomCommands(ctx context.Context, diceDBCmd *cmd.
 			slog.Error("Error sending ping response to io-thread", slog.String("id", t.id), slog.Any("error", err))
 		}
 		return err
+	case CmdMulti:
+		resp := t.RespMulti(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending multi response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdExec:
+		resp, committed := t.RespExec(ctx, diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending exec response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+			return err
+		}
+		return t.logTxnToWAL(committed)
+	case CmdMSetCAS:
+		return t.handleMSetCAS(ctx, diceDBCmd)
+	case CmdExport:
+		return t.handleExport(ctx, diceDBCmd)
+	case CmdSInter, CmdSInterCard, CmdSUnion, CmdSDiff:
+		return t.handleSetAlgebra(ctx, diceDBCmd)
+	case CmdSink:
+		resp := connector.Exec(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending sink response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdHotKeys:
+		resp := RespHotKeys(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending hotkeys response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdBigKeys:
+		resp := RespBigKeys(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending bigkeys response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdTrace:
+		resp := t.RespTrace(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending trace response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdReadAfter:
+		resp := t.RespReadAfter(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending readafter response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdModule:
+		resp := RespModule(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending module response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdNamespace:
+		resp := t.RespNamespace(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending namespace response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdQuit:
+		t.logCommand(diceDBCmd, clientio.OK)
+		t.quitting = true
+		err := t.ioHandler.Write(ctx, clientio.OK)
+		if err != nil {
+			slog.Error("Error sending quit response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdReset:
+		resp := t.RespReset(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending reset response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
+	case CmdDiscard:
+		resp := t.RespDiscard(diceDBCmd.Args)
+		t.logCommand(diceDBCmd, resp)
+		err := t.ioHandler.Write(ctx, resp)
+		if err != nil {
+			slog.Error("Error sending discard response to io-thread", slog.String("id", t.id), slog.Any("error", err))
+		}
+		return err
 	default:
 		return diceerrors.ErrUnknownCmd(diceDBCmd.Cmd)
 	}
 }
 
-// handleCommandWatch sends a watch subscription request to the watch manager.
-func (t *BaseIOThread) handleCommandWatch(cmdList []*cmd.DiceDBCmd) {
+// handleCommandWatch sends a watch subscription request to the watch manager, with the bound of the lag of the
+// replica for the subscription, 0 for none.
+func (t *BaseIOThread) handleCommandWatch(cmdList []*cmd.DiceDBCmd, maxLag time.Duration) {
 	t.cmdWatchSubscriptionChan <- watchmanager.WatchSubscription{
 		Subscribe:    true,
 		WatchCmd:     cmdList[len(cmdList)-1],
 		AdhocReqChan: t.adhocReqChan,
+		MaxLag:       maxLag,
 	}
 }
 
@@ -408,6 +782,8 @@ func (t *BaseIOThread) handleCommandUnwatch(ctx context.Context, cmdList []*cmd.
 		return parseErr
 	}
 
+	delete(t.watchSeqs, uint32(fp))
+
 	// send the unsubscribe request
 	t.cmdWatchSubscriptionChan <- watchmanager.WatchSubscription{
 		Subscribe:    false,
@@ -415,13 +791,30 @@ func (t *BaseIOThread) handleCommandUnwatch(ctx context.Context, cmdList []*cmd.
 		Fingerprint:  uint32(fp),
 	}
 
-	err := t.ioHandler.Write(ctx, clientio.RespOK)
+	err := t.ioHandler.Write(ctx, clientio.OK)
 	if err != nil {
 		return fmt.Errorf("error sending push response to client: %v", err)
 	}
 	return nil
 }
 
+// unwatchAll removes the watch subscriptions of the client, once it resets the connection or disconnects.
+// The subscriptions not removed by the time ctx is canceled, e.g. as the server shuts down, are left as they are.
+func (t *BaseIOThread) unwatchAll(ctx context.Context) {
+	for fingerprint := range t.watchSeqs {
+		select {
+		case t.cmdWatchSubscriptionChan <- watchmanager.WatchSubscription{
+			Subscribe:    false,
+			AdhocReqChan: t.adhocReqChan,
+			Fingerprint:  fingerprint,
+		}:
+		case <-ctx.Done():
+			return
+		}
+		delete(t.watchSeqs, fingerprint)
+	}
+}
+
 // scatter distributes the DiceDB commands to the respective shards based on the key.
 // For each command, it calculates the shard ID and sends the command to the shard's request channel for processing.
 func (t *BaseIOThread) scatter(ctx context.Context, cmds []*cmd.DiceDBCmd, cmdType CmdType) error {
@@ -434,38 +827,52 @@ func (t *BaseIOThread) scatter(ctx context.Context, cmds []*cmd.DiceDBCmd, cmdTy
 		return ctx.Err()
 	default:
 		// Proceed with the default case when the context is not canceled.
+		_, span := tracing.Start(ctx, tracing.SpanDispatch)
+		defer span.End()
+
+		// The commands are shed rather than queued behind a saturated shard, none of them is sent
+		if t.shardsSaturated(cmds, cmdType) {
+			return diceerrors.ErrOverloaded
+		}
 
 		if cmdType == AllShard {
 			// If the command type is for all shards, iterate over all available shards.
 			for i := uint8(0); i < uint8(t.shardManager.GetShardCount()); i++ {
-				// Get the shard ID (i) and its associated request channel.
-				shardID, responseChan := i, t.shardManager.GetShard(i).ReqChan
-
-				// Send a StoreOp operation to the shard's request channel.
-				responseChan <- &ops.StoreOp{
-					SeqID:      i,                         // Sequence ID for this operation.
-					RequestID:  GenerateUniqueRequestID(), // Unique identifier for the request.
-					Cmd:        cmds[0],                   // Command to be executed, using the first command in cmds.
-					IOThreadID: t.id,                      // ID of the current io-thread.
-					ShardID:    shardID,                   // ID of the shard handling this operation.
-					Client:     nil,                       // Client information (if applicable).
-				}
+				// Send a StoreOp operation to the shard (i).
+				t.shardManager.GetShard(i).Send(&ops.StoreOp{
+					SeqID:       i,                        // Sequence ID for this operation.
+					RequestID:   t.newRequestID(),         // Unique identifier for the request.
+					Cmd:         cmds[0],                  // Command to be executed, using the first command in cmds.
+					IOThreadID:  t.id,                     // ID of the current io-thread.
+					ShardID:     i,                        // ID of the shard handling this operation.
+					Client:      nil,                      // Client information (if applicable).
+					ClientAddr:  t.ioHandler.RemoteAddr(), // Remote address of the client.
+					ClientID:    t.clientID,               // ID of the client.
+					SpanContext: tracing.SpanContext(ctx), // Span of the request, parent of the execution on the shard.
+					Ctx:         t.connCtx,                // Context of the connection, canceled once the client disconnects.
+					Timing:      t.timing,                 // Stages of the request, nil unless the client traces its commands.
+				})
 			}
 		} else {
 			// If the command type is specific to certain commands, process them individually.
 			for i := uint8(0); i < uint8(len(cmds)); i++ {
 				// Determine the appropriate shard for the current command using a routing key.
-				shardID, responseChan := t.shardManager.GetShardInfo(getRoutingKeyFromCommand(cmds[i]))
-
-				// Send a StoreOp operation to the shard's request channel.
-				responseChan <- &ops.StoreOp{
-					SeqID:      i,                         // Sequence ID for this operation.
-					RequestID:  GenerateUniqueRequestID(), // Unique identifier for the request.
-					Cmd:        cmds[i],                   // Command to be executed, using the current command in cmds.
-					IOThreadID: t.id,                      // ID of the current io-thread.
-					ShardID:    shardID,                   // ID of the shard handling this operation.
-					Client:     nil,                       // Client information (if applicable).
-				}
+				shardID, _ := t.shardManager.GetShardInfo(getRoutingKeyFromCommand(cmds[i]))
+
+				// Send a StoreOp operation to the shard.
+				t.shardManager.GetShard(shardID).Send(&ops.StoreOp{
+					SeqID:       i,                        // Sequence ID for this operation.
+					RequestID:   t.newRequestID(),         // Unique identifier for the request.
+					Cmd:         cmds[i],                  // Command to be executed, using the current command in cmds.
+					IOThreadID:  t.id,                     // ID of the current io-thread.
+					ShardID:     shardID,                  // ID of the shard handling this operation.
+					Client:      nil,                      // Client information (if applicable).
+					ClientAddr:  t.ioHandler.RemoteAddr(), // Remote address of the client.
+					ClientID:    t.clientID,               // ID of the client.
+					SpanContext: tracing.SpanContext(ctx), // Span of the request, parent of the execution on the shard.
+					Ctx:         t.connCtx,                // Context of the connection, canceled once the client disconnects.
+					Timing:      t.timing,                 // Stages of the request, nil unless the client traces its commands.
+				})
 			}
 		}
 	}
@@ -473,8 +880,39 @@ func (t *BaseIOThread) scatter(ctx context.Context, cmds []*cmd.DiceDBCmd, cmdTy
 	return nil
 }
 
-// getRoutingKeyFromCommand determines the key used for shard routing
+// shardsSaturated reports whether one of the shards the commands are sent to is saturated.
+func (t *BaseIOThread) shardsSaturated(cmds []*cmd.DiceDBCmd, cmdType CmdType) bool {
+	if cmdType == AllShard {
+		for i := uint8(0); i < uint8(t.shardManager.GetShardCount()); i++ {
+			if admission.Saturated(metrics.TransportRESP, len(t.shardManager.GetShard(i).ReqChan)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, c := range cmds {
+		_, reqChan := t.shardManager.GetShardInfo(getRoutingKeyFromCommand(c))
+		if admission.Saturated(metrics.TransportRESP, len(reqChan)) {
+			return true
+		}
+	}
+	return false
+}
+
+// getRoutingKeyFromCommand determines the key used for shard routing: the first key of the command, as
+// located by its key specification, or its first argument for the commands without one
+// readThrough loads the key read by GET from the origin if it is cached and missing, so that GET finds it
+func readThrough(ctx context.Context, diceDBCmd *cmd.DiceDBCmd) {
+	if diceDBCmd.Cmd == CmdGet && len(diceDBCmd.Args) == 1 {
+		cache.ReadThrough(ctx, diceDBCmd.Args[0])
+	}
+}
+
 func getRoutingKeyFromCommand(diceDBCmd *cmd.DiceDBCmd) string {
+	if key, ok := cmd.FirstKey(diceDBCmd); ok {
+		return key
+	}
 	if len(diceDBCmd.Args) > 0 {
 		return diceDBCmd.Args[0]
 	}
@@ -504,7 +942,7 @@ func (t *BaseIOThread) gather(ctx context.Context, diceDBCmd *cmd.DiceDBCmd, num
 	// Process command based on its type
 	cmdMeta, ok := CommandsMeta[diceDBCmd.Cmd]
 	if !ok {
-		return t.handleUnsupportedCommand(ctx, storeOp[0])
+		return t.handleUnsupportedCommand(ctx, diceDBCmd, storeOp[0])
 	}
 
 	return t.handleCommand(ctx, cmdMeta, diceDBCmd, storeOp)
@@ -520,10 +958,25 @@ func (t *BaseIOThread) gatherResponses(ctx context.Context, numCmds int) ([]ops.
 			slog.Error("Timed out waiting for response from shards",
 				slog.String("id", t.id),
 				slog.Any("error", ctx.Err()))
+			// The responses still awaited are dropped when they come in
+			clear(t.inflight)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w: %w", errShardsTimedOut, ctx.Err())
+			}
 			return nil, ctx.Err()
 
+		case <-t.disconnected:
+			// The responses still awaited are dropped when they come in, nobody is left to reply to
+			clear(t.inflight)
+			return nil, diceerrors.ErrClientDisconnected
+
 		case resp, ok := <-t.responseChan:
 			if ok {
+				if !t.inflight[resp.RequestID] {
+					// The late response of an operation given up on, e.g. after a timeout
+					continue
+				}
+				delete(t.inflight, resp.RequestID)
 				storeOp = append(storeOp, *resp)
 			}
 			numCmds--
@@ -533,6 +986,7 @@ func (t *BaseIOThread) gatherResponses(ctx context.Context, numCmds int) ([]ops.
 				slog.Error("Error from shard",
 					slog.String("id", t.id),
 					slog.Any("error", sError))
+				clear(t.inflight)
 				return nil, sError.Error
 			}
 		}
@@ -544,6 +998,7 @@ func (t *BaseIOThread) gatherResponses(ctx context.Context, numCmds int) ([]ops.
 // handleWatchNotification processes watch notification responses
 func (t *BaseIOThread) handleWatchNotification(ctx context.Context, diceDBCmd *cmd.DiceDBCmd, resp ops.StoreResponse, watchLabel string) error {
 	fingerprint := fmt.Sprintf("%d", diceDBCmd.GetFingerprint())
+	seq := t.watchSeqs[diceDBCmd.GetFingerprint()]
 
 	// if watch label is not empty, then this is the first response for the watch command
 	// hence, we will send the watch label as part of the response
@@ -553,17 +1008,19 @@ func (t *BaseIOThread) handleWatchNotification(ctx context.Context, diceDBCmd *c
 	}
 
 	if resp.EvalResponse.Error != nil {
-		return t.writeResponse(ctx, querymanager.GenericWatchResponse(firstRespElem, fingerprint, resp.EvalResponse.Error))
+		return t.writeResponse(ctx, querymanager.GenericWatchResponse(firstRespElem, fingerprint, resp.EvalResponse.Error, seq))
 	}
 
-	return t.writeResponse(ctx, querymanager.GenericWatchResponse(firstRespElem, fingerprint, resp.EvalResponse.Result))
+	return t.writeResponse(ctx, querymanager.GenericWatchResponse(firstRespElem, fingerprint, resp.EvalResponse.Result, seq))
 }
 
 // handleUnsupportedCommand processes commands not in CommandsMeta
-func (t *BaseIOThread) handleUnsupportedCommand(ctx context.Context, resp ops.StoreResponse) error {
+func (t *BaseIOThread) handleUnsupportedCommand(ctx context.Context, diceDBCmd *cmd.DiceDBCmd, resp ops.StoreResponse) error {
 	if resp.EvalResponse.Error != nil {
+		t.logCommand(diceDBCmd, resp.EvalResponse.Error)
 		return t.writeResponse(ctx, resp.EvalResponse.Error)
 	}
+	t.logCommand(diceDBCmd, resp.EvalResponse.Result)
 	return t.writeResponse(ctx, resp.EvalResponse.Result)
 }
 
@@ -574,8 +1031,10 @@ func (t *BaseIOThread) handleCommand(ctx context.Context, cmdMeta CmdMeta, diceD
 	switch cmdMeta.CmdType {
 	case SingleShard, Custom:
 		if storeOp[0].EvalResponse.Error != nil {
+			t.logCommand(diceDBCmd, storeOp[0].EvalResponse.Error)
 			err = t.writeResponse(ctx, storeOp[0].EvalResponse.Error)
 		} else {
+			t.logCommand(diceDBCmd, storeOp[0].EvalResponse.Result)
 			err = t.writeResponse(ctx, storeOp[0].EvalResponse.Result)
 		}
 
@@ -585,7 +1044,12 @@ func (t *BaseIOThread) handleCommand(ctx context.Context, cmdMeta CmdMeta, diceD
 			}
 		}
 	case MultiShard, AllShard:
-		err = t.writeResponse(ctx, cmdMeta.composeResponse(storeOp...))
+		response := cmdMeta.composeResponse(storeOp...)
+		if keys, ok := response.([]string); ok && diceDBCmd.Cmd == CmdKeys {
+			response = t.namespace.UnprefixKeys(keys)
+		}
+		t.logCommand(diceDBCmd, response)
+		err = t.writeResponse(ctx, response)
 
 		if err == nil && t.wl != nil {
 			if err := t.wl.LogCommand([]byte(fmt.Sprintf("%s %s", diceDBCmd.Cmd, strings.Join(diceDBCmd.Args, " ")))); err != nil {
@@ -604,6 +1068,9 @@ func (t *BaseIOThread) handleCommand(ctx context.Context, cmdMeta CmdMeta, diceD
 
 // writeResponse handles writing responses and logging errors
 func (t *BaseIOThread) writeResponse(ctx context.Context, response interface{}) error {
+	_, span := tracing.Start(ctx, tracing.SpanReply)
+	defer span.End()
+
 	err := t.ioHandler.Write(ctx, response)
 	if err != nil {
 		slog.Debug("Error sending response to client",
@@ -613,9 +1080,26 @@ func (t *BaseIOThread) writeResponse(ctx context.Context, response interface{})
 	return err
 }
 
+// logCommand records the command along with the response sent to the client in the audit log and the access log.
+func (t *BaseIOThread) logCommand(diceDBCmd *cmd.DiceDBCmd, response interface{}) {
+	elapsed := time.Since(t.commandStartedAt)
+	audit.Log(t.ioHandler.RemoteAddr(), t.username(), diceDBCmd, audit.ResponseError(response))
+	accesslog.Log(t.ioHandler.RemoteAddr(), diceDBCmd, elapsed, response)
+	module.After(t.ioHandler.RemoteAddr(), t.username(), diceDBCmd, response, elapsed)
+}
+
+// username returns the user the client is authenticated as, empty when it is not authenticated.
+func (t *BaseIOThread) username() string {
+	if t.Session.User == nil {
+		return ""
+	}
+	return t.Session.User.Username
+}
+
 func (t *BaseIOThread) isAuthenticated(diceDBCmd *cmd.DiceDBCmd) error {
-	if diceDBCmd.Cmd != auth.Cmd && !t.Session.IsActive() {
-		return errors.New("NOAUTH Authentication required")
+	// HELLO checks the authentication itself, as it can authenticate the connection
+	if diceDBCmd.Cmd != auth.Cmd && diceDBCmd.Cmd != CmdHello && !isConnectionCommand(diceDBCmd.Cmd) && !t.Session.IsActive() {
+		return diceerrors.ErrNoAuth
 	}
 
 	return nil
@@ -627,6 +1111,13 @@ func (t *BaseIOThread) Stop() error {
 	return nil
 }
 
+// newRequestID returns the id of an operation sent to the shards, whose response gatherResponses awaits.
+func (t *BaseIOThread) newRequestID() uint32 {
+	id := GenerateUniqueRequestID()
+	t.inflight[id] = true
+	return id
+}
+
 func GenerateUniqueRequestID() uint32 {
 	return atomic.AddUint32(&requestCounter, 1)
 }
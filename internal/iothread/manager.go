@@ -21,7 +21,10 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/dicedb/dice/internal/admission"
+	"github.com/dicedb/dice/internal/metrics"
 	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/stats"
 )
 
 type Manager struct {
@@ -29,6 +32,7 @@ type Manager struct {
 	numIOThreads     atomic.Int32
 	maxClients       int32
 	shardManager     *shard.ShardManager
+	admission        *admission.Limiter // admission bounds the commands executed at once by the io-threads
 	mu               sync.Mutex
 }
 
@@ -41,6 +45,7 @@ func NewManager(maxClients int32, sm *shard.ShardManager) *Manager {
 	return &Manager{
 		maxClients:   maxClients,
 		shardManager: sm,
+		admission:    admission.NewLimiter(metrics.TransportRESP),
 	}
 }
 
@@ -49,10 +54,12 @@ func (m *Manager) RegisterIOThread(ioThread IOThread) error {
 	defer m.mu.Unlock()
 
 	if m.IOThreadCount() >= m.maxClients {
+		stats.ConnectionRejected()
 		return ErrMaxClientsReached
 	}
 
 	m.connectedClients.Store(ioThread.ID(), ioThread)
+	ioThread.(*BaseIOThread).admission = m.admission
 	responseChan := ioThread.(*BaseIOThread).responseChan
 	preprocessingChan := ioThread.(*BaseIOThread).preprocessingChan
 
@@ -63,6 +70,8 @@ func (m *Manager) RegisterIOThread(ioThread IOThread) error {
 	}
 
 	m.numIOThreads.Add(1)
+	stats.ClientConnected()
+	metrics.ClientConnected(metrics.TransportRESP)
 	return nil
 }
 
@@ -90,6 +99,8 @@ func (m *Manager) UnregisterIOThread(id string) error {
 
 	m.shardManager.UnregisterIOThread(id)
 	m.numIOThreads.Add(-1)
+	stats.ClientDisconnected()
+	metrics.ClientDisconnected(metrics.TransportRESP)
 
 	return nil
 }
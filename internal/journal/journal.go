@@ -0,0 +1,159 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package journal records the exact stream of commands applied to every shard, with the time they
+// were applied at and the client that sent them, and replays it to rebuild a store. Replaying a
+// journal reproduces the store deterministically, which makes it both a building block for
+// persistence and a way to write regression tests of the eval logic from a recorded workload.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// Entry is a command applied to a shard.
+type Entry struct {
+	Time     time.Time `json:"time"`                // Time is the time of the store clock when the command was applied
+	ClientID uint64    `json:"client_id,omitempty"` // ClientID is the id of the RESP client that sent the command, 0 for the other frontends
+	Shard    uint8     `json:"shard"`               // Shard is the shard that applied the command
+	Cmd      string    `json:"cmd"`
+	Args     []string  `json:"args,omitempty"`
+}
+
+// Journal records the commands applied to a shard, in the order they are applied.
+// A journal is written by the goroutine of its shard only.
+type Journal interface {
+	Record(e *Entry) error
+	Close() error
+}
+
+// Path returns the path of the journal file of the shard in dir.
+func Path(dir string, shard uint8) string {
+	return filepath.Join(dir, fmt.Sprintf("shard-%d.journal", shard))
+}
+
+// File is a Journal appending its entries to a file, one JSON document per line.
+type File struct {
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// OpenFile opens the journal file at path, appending to it if it exists.
+func OpenFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	return &File{f: f, w: w, enc: json.NewEncoder(w)}, nil
+}
+
+// Record appends the entry to the file. The entry is handed to the OS before Record returns, so that the
+// journal of a crashed process holds every command it replied to.
+func (j *File) Record(e *Entry) error {
+	if err := j.enc.Encode(e); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+func (j *File) Close() error {
+	return errors.Join(j.w.Flush(), j.f.Close())
+}
+
+// Memory is a Journal keeping its entries in memory.
+type Memory struct {
+	mu      sync.Mutex
+	entries []*Entry
+}
+
+func (j *Memory) Record(e *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, e)
+	return nil
+}
+
+func (j *Memory) Close() error {
+	return nil
+}
+
+// Entries returns the entries recorded so far, in order.
+func (j *Memory) Entries() []*Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]*Entry(nil), j.entries...)
+}
+
+// Read calls fn for every entry of the journal read from r, in order, stopping at the first error.
+func Read(r io.Reader, fn func(*Entry) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		e := &Entry{}
+		if err := dec.Decode(e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("invalid journal entry: %w", err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
+
+// Replay applies the entries to the store in order and returns the response of every command.
+// The clock is set to the time each entry was recorded at while it is applied, so that expirations
+// are reproduced exactly. As the clock is process wide, Replay must not run alongside a serving store.
+func Replay(store *dstore.Store, entries ...*Entry) []*eval.EvalResponse {
+	clock := &utils.MockClock{}
+	previous := utils.CurrentTime
+	utils.CurrentTime = clock
+	defer func() { utils.CurrentTime = previous }()
+
+	resps := make([]*eval.EvalResponse, 0, len(entries))
+	for _, e := range entries {
+		clock.SetTime(e.Time)
+		c := &cmd.DiceDBCmd{Cmd: e.Cmd, Args: e.Args}
+		resps = append(resps, eval.NewEval(c, nil, store, false, false, false).ExecuteCommand())
+	}
+	return resps
+}
+
+// Rebuild replays the journal read from r into the store and returns the number of entries replayed.
+func Rebuild(store *dstore.Store, r io.Reader) (int, error) {
+	n := 0
+	err := Read(r, func(e *Entry) error {
+		Replay(store, e)
+		n++
+		return nil
+	})
+	return n, err
+}
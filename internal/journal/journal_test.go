@@ -0,0 +1,113 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package journal
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/eval"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRoundTrip(t *testing.T) {
+	path := Path(t.TempDir(), 3)
+	now := time.Now().UTC()
+	entries := []*Entry{
+		{Time: now, ClientID: 7, Shard: 3, Cmd: "SET", Args: []string{"k", "v"}},
+		{Time: now.Add(time.Second), Shard: 3, Cmd: "DBSIZE"},
+	}
+
+	j, err := OpenFile(path)
+	require.NoError(t, err)
+	for _, e := range entries {
+		require.NoError(t, j.Record(e))
+	}
+	require.NoError(t, j.Close())
+
+	// Reopening appends to the journal
+	j, err = OpenFile(path)
+	require.NoError(t, err)
+	require.NoError(t, j.Record(entries[0]))
+	require.NoError(t, j.Close())
+
+	var read []*Entry
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, Read(bytes.NewReader(data), func(e *Entry) error {
+		read = append(read, e)
+		return nil
+	}))
+	require.Len(t, read, 3)
+	for i, want := range append(entries, entries[0]) {
+		assert.True(t, want.Time.Equal(read[i].Time))
+		assert.Equal(t, want.ClientID, read[i].ClientID)
+		assert.Equal(t, want.Shard, read[i].Shard)
+		assert.Equal(t, want.Cmd, read[i].Cmd)
+		assert.Equal(t, want.Args, read[i].Args)
+	}
+
+	assert.Error(t, Read(bytes.NewReader([]byte("{\"cmd\":")), func(*Entry) error { return nil }))
+}
+
+func TestReplayReproducesExpirations(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*Entry{
+		{Time: start, Cmd: "SET", Args: []string{"k", "v", "EX", "10"}},
+		{Time: start.Add(5 * time.Second), Cmd: "GET", Args: []string{"k"}},
+		{Time: start.Add(11 * time.Second), Cmd: "GET", Args: []string{"k"}},
+		{Time: start.Add(12 * time.Second), Cmd: "INCR", Args: []string{"n"}},
+	}
+
+	replay := func() []*eval.EvalResponse {
+		return Replay(dstore.NewStore(nil, dstore.NewDefaultEviction()), entries...)
+	}
+
+	resps := replay()
+	require.Len(t, resps, 4)
+	assert.Equal(t, clientio.OK, resps[0].Result)
+	assert.Equal(t, "v", resps[1].Result)
+	assert.Equal(t, clientio.NIL, resps[2].Result)
+	assert.Equal(t, int64(1), resps[3].Result)
+
+	// Replaying the same journal gives the same responses
+	assert.Equal(t, resps, replay())
+}
+
+func TestRebuild(t *testing.T) {
+	path := Path(t.TempDir(), 0)
+	j, err := OpenFile(path)
+	require.NoError(t, err)
+	require.NoError(t, j.Record(&Entry{Time: time.Now(), Cmd: "SET", Args: []string{"a", "1"}}))
+	require.NoError(t, j.Record(&Entry{Time: time.Now(), Cmd: "INCRBY", Args: []string{"a", "41"}}))
+	require.NoError(t, j.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	store := dstore.NewStore(nil, dstore.NewDefaultEviction())
+	n, err := Rebuild(store, f)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, int64(42), Replay(store, &Entry{Time: time.Now(), Cmd: "GET", Args: []string{"a"}})[0].Result)
+}
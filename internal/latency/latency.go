@@ -0,0 +1,163 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package latency implements the latency monitor: it records the events that took
+// at least latency.monitor_threshold milliseconds so that stalls can be inspected with LATENCY.
+package latency
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/config"
+)
+
+// Event is the class of operation whose latency is monitored.
+type Event string
+
+const (
+	// EventCommand is the execution of a command by a shard
+	EventCommand Event = "command"
+	// EventExpireCycle is a run of the active expiry of a shard
+	EventExpireCycle Event = "expire-cycle"
+	// EventEviction is the eviction of keys when the store is full
+	EventEviction Event = "eviction"
+	// EventAOFFsync is a flush and fsync of the append only file
+	EventAOFFsync Event = "aof-fsync"
+	// EventCommandTimeout is the execution of a command cancelled after exceeding performance.command_timeout
+	EventCommandTimeout Event = "command-timeout"
+)
+
+// maxSamples is the number of samples kept for every event, like Redis.
+const maxSamples = 160
+
+// Sample is the highest latency of an event within a second.
+type Sample struct {
+	Time    time.Time
+	Latency time.Duration
+}
+
+// Latest summarizes the samples of an event: its most recent sample and the highest latency recorded.
+type Latest struct {
+	Event  Event
+	Sample Sample
+	Max    time.Duration
+}
+
+type history struct {
+	samples []Sample // oldest first
+	max     time.Duration
+}
+
+// The monitor is process wide as events are recorded by the shards as well as the persistence layer.
+var (
+	mu        sync.Mutex
+	histories = make(map[Event]*history)
+)
+
+// Record adds a sample for the event if the latency reaches latency.monitor_threshold.
+// Samples recorded within the same second are merged, keeping the highest latency.
+func Record(event Event, latency time.Duration) {
+	threshold := config.DiceConfig().Latency.MonitorThreshold
+	if threshold == 0 || latency < time.Duration(threshold)*time.Millisecond {
+		return
+	}
+	record(event, latency, time.Now())
+}
+
+func record(event Event, latency time.Duration, at time.Time) {
+	now := at.Truncate(time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	h, ok := histories[event]
+	if !ok {
+		h = &history{}
+		histories[event] = h
+	}
+	h.max = max(h.max, latency)
+
+	if n := len(h.samples); n > 0 && h.samples[n-1].Time.Equal(now) {
+		h.samples[n-1].Latency = max(h.samples[n-1].Latency, latency)
+		return
+	}
+
+	h.samples = append(h.samples, Sample{Time: now, Latency: latency})
+	if extra := len(h.samples) - maxSamples; extra > 0 {
+		h.samples = append(h.samples[:0], h.samples[extra:]...)
+	}
+}
+
+// Since records the time elapsed since start for the event.
+func Since(event Event, start time.Time) {
+	Record(event, time.Since(start))
+}
+
+// GetLatest returns the latest sample of every event that has samples, ordered by event name.
+func GetLatest() []Latest {
+	mu.Lock()
+	defer mu.Unlock()
+
+	latest := make([]Latest, 0, len(histories))
+	for event, h := range histories {
+		latest = append(latest, Latest{
+			Event:  event,
+			Sample: h.samples[len(h.samples)-1],
+			Max:    h.max,
+		})
+	}
+
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].Event < latest[j].Event
+	})
+	return latest
+}
+
+// GetHistory returns the samples of the event, oldest first.
+func GetHistory(event Event) []Sample {
+	mu.Lock()
+	defer mu.Unlock()
+
+	h, ok := histories[event]
+	if !ok {
+		return nil
+	}
+	return append([]Sample(nil), h.samples...)
+}
+
+// Reset removes the samples of the given events, or of every event if none is given.
+// It returns the number of events whose samples were removed.
+func Reset(events ...Event) int {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(events) == 0 {
+		n := len(histories)
+		histories = make(map[Event]*history)
+		return n
+	}
+
+	n := 0
+	for _, event := range events {
+		if _, ok := histories[event]; ok {
+			delete(histories, event)
+			n++
+		}
+	}
+	return n
+}
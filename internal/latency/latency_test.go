@@ -0,0 +1,87 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func withThreshold(t *testing.T, threshold int64) {
+	previous := config.DiceConfig().Latency.MonitorThreshold
+	config.DiceConfig().Latency.MonitorThreshold = threshold
+	t.Cleanup(func() {
+		config.DiceConfig().Latency.MonitorThreshold = previous
+		Reset()
+	})
+}
+
+func TestRecordThreshold(t *testing.T) {
+	withThreshold(t, 0)
+	Record(EventCommand, time.Second)
+	assert.Empty(t, GetLatest())
+
+	config.DiceConfig().Latency.MonitorThreshold = 100
+	Record(EventCommand, 50*time.Millisecond)
+	assert.Empty(t, GetLatest())
+
+	Record(EventCommand, 100*time.Millisecond)
+	assert.Len(t, GetHistory(EventCommand), 1)
+}
+
+func TestLatestAndHistory(t *testing.T) {
+	withThreshold(t, 10)
+
+	now := time.Now().Truncate(time.Second)
+	record(EventEviction, 20*time.Millisecond, now)
+	record(EventCommand, 60*time.Millisecond, now.Add(-time.Second))
+	record(EventCommand, 30*time.Millisecond, now)
+	record(EventCommand, 50*time.Millisecond, now.Add(100*time.Millisecond))
+	record(EventCommand, 40*time.Millisecond, now.Add(200*time.Millisecond))
+
+	// Spikes within the same second are merged into a single sample
+	history := GetHistory(EventCommand)
+	assert.Len(t, history, 2)
+	assert.Equal(t, 60*time.Millisecond, history[0].Latency)
+	assert.Equal(t, 50*time.Millisecond, history[1].Latency)
+
+	latest := GetLatest()
+	assert.Len(t, latest, 2)
+	assert.Equal(t, EventCommand, latest[0].Event)
+	assert.Equal(t, 50*time.Millisecond, latest[0].Sample.Latency)
+	assert.Equal(t, 60*time.Millisecond, latest[0].Max)
+	assert.Equal(t, EventEviction, latest[1].Event)
+	assert.Equal(t, 20*time.Millisecond, latest[1].Sample.Latency)
+
+	assert.Empty(t, GetHistory(EventAOFFsync))
+}
+
+func TestReset(t *testing.T) {
+	withThreshold(t, 10)
+
+	Record(EventCommand, 20*time.Millisecond)
+	Record(EventEviction, 20*time.Millisecond)
+	Record(EventExpireCycle, 20*time.Millisecond)
+
+	assert.Equal(t, 1, Reset(EventCommand, EventAOFFsync))
+	assert.Empty(t, GetHistory(EventCommand))
+	assert.Equal(t, 2, Reset())
+	assert.Empty(t, GetLatest())
+}
@@ -26,7 +26,7 @@ import (
 )
 
 func getSLogLevel() slog.Level {
-	switch config.DiceConfig.Logging.LogLevel {
+	switch config.DiceConfig().Logging.LogLevel {
 	case "debug":
 		return slog.LevelDebug
 	case "info":
@@ -0,0 +1,267 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package metrics exports the engine-wide metrics of the server in the Prometheus exposition format
+// on the dedicated listener configured by the metrics section of the config.
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dicedb/dice/internal/stats"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dicedb"
+
+// Transports reported by the connection metrics
+const (
+	TransportRESP      = "resp"
+	TransportHTTP      = "http"
+	TransportWebSocket = "websocket"
+)
+
+// Reasons reported by the metrics of the shed requests
+const (
+	ShedShardQueue       = "shard_queue"       // the queue of a shard the command targets is full
+	ShedFrontendInflight = "frontend_inflight" // the frontend already executes its maximum number of commands
+)
+
+// Values of the status label of the command metrics
+const (
+	statusOK    = "ok"
+	statusError = "error"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	commandsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_total",
+		Help:      "Number of commands executed by the shards, by command and status.",
+	}, []string{"command", "status"})
+
+	commandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time spent by the shards executing commands, by command.",
+		// 10µs to 2.6s
+		Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, []string{"command"})
+
+	// The keyspace counters are shared with INFO, they restart from zero on CONFIG RESETSTAT
+	keyspaceHits = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "keyspace_hits_total",
+		Help:      "Number of successful lookups of keys.",
+	}, func() float64 { return float64(stats.Get().KeyspaceHits) })
+
+	keyspaceMisses = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "keyspace_misses_total",
+		Help:      "Number of failed lookups of keys.",
+	}, func() float64 { return float64(stats.Get().KeyspaceMisses) })
+
+	expiredKeys = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "expired_keys_total",
+		Help:      "Number of keys deleted because their TTL elapsed.",
+	})
+
+	evictedKeys = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "evicted_keys_total",
+		Help:      "Number of keys evicted because of the memory limits.",
+	})
+
+	connectedClients = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connected_clients",
+		Help:      "Number of open client connections, by transport.",
+	}, []string{"transport"})
+
+	connectionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "connections_received_total",
+		Help:      "Number of client connections accepted, by transport.",
+	}, []string{"transport"})
+
+	requestsShed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_shed_total",
+		Help:      "Number of commands refused because the server is overloaded, by transport and reason.",
+	}, []string{"transport", "reason"})
+
+	replicationLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "replication_lag_seconds",
+		Help:      "Time elapsed since the last update received from the primary, 0 when the server is not a replica.",
+	})
+
+	// The qwatch counters are shared with INFO
+	watchQueries = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "qwatch_queries",
+		Help:      "Number of distinct queries watched by clients.",
+	}, func() float64 { return float64(stats.Get().Watch.Queries) })
+
+	watchSubscriptions = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "qwatch_subscriptions",
+		Help:      "Number of subscriptions of clients to watched queries.",
+	}, func() float64 { return float64(stats.Get().Watch.Subscriptions) })
+
+	watchCoalescedUpdates = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "qwatch_coalesced_updates_total",
+		Help:      "Number of change notifications merged with one already queued for the same query.",
+	}, func() float64 { return float64(stats.Get().Watch.CoalescedUpdates) })
+
+	watchDroppedUpdates = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "qwatch_dropped_updates_total",
+		Help:      "Number of change notifications whose new result could not be pushed to the subscriber.",
+	}, func() float64 { return float64(stats.Get().Watch.DroppedUpdates) })
+
+	watchEvaluationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "qwatch_evaluation_duration_seconds",
+		Help:      "Time spent running a watched query to push its new result, by command.",
+		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, []string{"command"})
+
+	watchPushLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "qwatch_push_latency_seconds",
+		Help:      "Time elapsed between the change of a key and the push of the new result of a query watching it.",
+		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+	})
+
+	watchQueueDepth = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "qwatch_subscriber_queue_depth",
+		Help:      "Number of change notifications already queued for a subscriber when a new one is queued.",
+		Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		commandsTotal,
+		commandDuration,
+		keyspaceHits,
+		keyspaceMisses,
+		expiredKeys,
+		evictedKeys,
+		connectedClients,
+		connectionsTotal,
+		requestsShed,
+		replicationLag,
+		watchQueries,
+		watchSubscriptions,
+		watchCoalescedUpdates,
+		watchDroppedUpdates,
+		watchEvaluationDuration,
+		watchPushLatency,
+		watchQueueDepth,
+	)
+
+	// Export the transports before their first connection so that dashboards show zeros rather than gaps
+	for _, transport := range []string{TransportRESP, TransportHTTP, TransportWebSocket} {
+		connectedClients.WithLabelValues(transport)
+		connectionsTotal.WithLabelValues(transport)
+		requestsShed.WithLabelValues(transport, ShedShardQueue)
+		requestsShed.WithLabelValues(transport, ShedFrontendInflight)
+	}
+}
+
+// CommandExecuted records the execution of a command by a shard.
+func CommandExecuted(command string, failed bool, elapsed time.Duration) {
+	status := statusOK
+	if failed {
+		status = statusError
+	}
+	commandsTotal.WithLabelValues(command, status).Inc()
+	commandDuration.WithLabelValues(command).Observe(elapsed.Seconds())
+}
+
+// KeysExpired records keys deleted because their TTL elapsed.
+func KeysExpired(count int) {
+	expiredKeys.Add(float64(count))
+}
+
+// KeysEvicted records keys evicted because of the memory limits.
+func KeysEvicted(count int) {
+	evictedKeys.Add(float64(count))
+}
+
+// ClientConnected records a client connection accepted over the transport.
+func ClientConnected(transport string) {
+	connectedClients.WithLabelValues(transport).Inc()
+	connectionsTotal.WithLabelValues(transport).Inc()
+}
+
+// ClientDisconnected records a client connection over the transport being closed.
+func ClientDisconnected(transport string) {
+	connectedClients.WithLabelValues(transport).Dec()
+}
+
+// TrackConnState returns an http.Server ConnState hook recording the connections of the transport.
+func TrackConnState(transport string) func(net.Conn, http.ConnState) {
+	return func(_ net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			ClientConnected(transport)
+		case http.StateHijacked, http.StateClosed:
+			// Hijacked connections are upgraded to WebSocket and tracked by the WebSocket handler from then on
+			ClientDisconnected(transport)
+		}
+	}
+}
+
+// RequestShed records a command received over the transport refused because the server is overloaded.
+func RequestShed(transport, reason string) {
+	requestsShed.WithLabelValues(transport, reason).Inc()
+}
+
+// SetReplicationLag records the time elapsed since the last update received from the primary.
+func SetReplicationLag(lag time.Duration) {
+	replicationLag.Set(lag.Seconds())
+}
+
+// WatchUpdatePushed records the new result of a watched query pushed to a subscriber. evaluation is the
+// time spent running the query and latency the time elapsed since the change of the key it depends on.
+func WatchUpdatePushed(command string, evaluation, latency time.Duration) {
+	watchEvaluationDuration.WithLabelValues(command).Observe(evaluation.Seconds())
+	watchPushLatency.Observe(latency.Seconds())
+}
+
+// WatchUpdateQueued records a change notification queued for a subscriber that had depth notifications pending.
+func WatchUpdateQueued(depth int) {
+	watchQueueDepth.Observe(float64(depth))
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
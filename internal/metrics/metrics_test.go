@@ -0,0 +1,59 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", http.NoBody))
+	body, err := io.ReadAll(recorder.Result().Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestHandler(t *testing.T) {
+	CommandExecuted("GET", false, 20*time.Microsecond)
+	CommandExecuted("GET", true, time.Millisecond)
+	stats.KeyspaceLookup(true)
+	stats.KeyspaceLookup(false)
+	KeysEvicted(3)
+	ClientConnected(TransportWebSocket)
+	stats.WatchSubscribed(1)
+
+	body := scrape(t)
+	assert.Contains(t, body, `dicedb_commands_total{command="GET",status="ok"} 1`)
+	assert.Contains(t, body, `dicedb_commands_total{command="GET",status="error"} 1`)
+	assert.Contains(t, body, `dicedb_command_duration_seconds_count{command="GET"} 2`)
+	assert.Contains(t, body, "dicedb_keyspace_hits_total 1")
+	assert.Contains(t, body, "dicedb_keyspace_misses_total 1")
+	assert.Contains(t, body, "dicedb_evicted_keys_total 3")
+	assert.Contains(t, body, `dicedb_connected_clients{transport="websocket"} 1`)
+	assert.Contains(t, body, `dicedb_connected_clients{transport="resp"} 0`)
+	assert.Contains(t, body, "dicedb_qwatch_subscriptions 1")
+	assert.Contains(t, body, "dicedb_replication_lag_seconds 0")
+	assert.Contains(t, body, "go_goroutines")
+}
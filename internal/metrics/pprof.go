@@ -0,0 +1,48 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"github.com/dicedb/dice/config"
+)
+
+// registerPprof serves the net/http/pprof endpoints on mux and applies the sampling rates of
+// the block and mutex profiles, which are updated whenever they are changed at runtime.
+func registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	setBlockProfileRate()
+	setMutexProfileFraction()
+	config.OnParameterChange("metrics.block_profile_rate", setBlockProfileRate)
+	config.OnParameterChange("metrics.mutex_profile_fraction", setMutexProfileFraction)
+}
+
+func setBlockProfileRate() {
+	runtime.SetBlockProfileRate(config.DiceConfig().Metrics.BlockProfileRate)
+}
+
+func setMutexProfileFraction() {
+	runtime.SetMutexProfileFraction(config.DiceConfig().Metrics.MutexProfileFraction)
+}
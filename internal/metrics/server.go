@@ -0,0 +1,92 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/bigkeys"
+	"github.com/dicedb/dice/internal/handover"
+)
+
+// Server is the dedicated listener serving the metrics on /metrics, the analyses of the big keys
+// on /bigkeys, and the profiling endpoints on /debug/pprof/ when enabled, kept apart from the
+// client facing listeners so that it can be exposed to the monitoring network only.
+type Server struct {
+	httpServer *http.Server
+	listening  chan struct{}
+}
+
+func NewServer() *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	mux.Handle("/bigkeys", bigkeys.Handler())
+	if config.DiceConfig().Metrics.PprofEnabled {
+		registerPprof(mux)
+	}
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              net.JoinHostPort(config.DiceConfig().Metrics.Addr, strconv.Itoa(config.DiceConfig().Metrics.Port)),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		listening: make(chan struct{}),
+	}
+}
+
+// Run serves the metrics until ctx is canceled.
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		ln, err := handover.Listen("tcp", s.httpServer.Addr)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		close(s.listening)
+		slog.Info("serving metrics", slog.String("addr", s.httpServer.Addr))
+		errCh <- s.httpServer.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return ctx.Err()
+	}
+}
+
+// Listening is closed once the server accepts connections.
+func (s *Server) Listening() <-chan struct{} {
+	return s.listening
+}
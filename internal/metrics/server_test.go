@@ -0,0 +1,53 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(s *Server, path string) int {
+	recorder := httptest.NewRecorder()
+	s.httpServer.Handler.ServeHTTP(recorder, httptest.NewRequest("GET", path, http.NoBody))
+	return recorder.Code
+}
+
+func TestServerPprof(t *testing.T) {
+	previous := config.DiceConfig().Metrics
+	t.Cleanup(func() {
+		config.DiceConfig().Metrics = previous
+		runtime.SetMutexProfileFraction(0)
+	})
+
+	config.DiceConfig().Metrics.PprofEnabled = false
+	s := NewServer()
+	assert.Equal(t, http.StatusOK, serve(s, "/metrics"))
+	assert.Equal(t, http.StatusNotFound, serve(s, "/debug/pprof/"))
+
+	config.DiceConfig().Metrics.PprofEnabled = true
+	config.DiceConfig().Metrics.MutexProfileFraction = 5
+	s = NewServer()
+	assert.Equal(t, http.StatusOK, serve(s, "/debug/pprof/"))
+	assert.Equal(t, http.StatusOK, serve(s, "/debug/pprof/goroutine?debug=1"))
+	assert.Equal(t, 5, runtime.SetMutexProfileFraction(-1))
+}
@@ -0,0 +1,70 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package module
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// Context is the access of a module command to the store, limited to the keys of the command.
+type Context struct {
+	command string
+	store   *dstore.Store
+	keys    []string
+}
+
+// Command returns the name of the module command executed.
+func (c *Context) Command() string {
+	return c.command
+}
+
+// Keys returns the keys of the module command, the only keys it can access.
+func (c *Context) Keys() []string {
+	return c.keys
+}
+
+// Call executes a command of the server, e.g. Call("HINCRBY", key, "count", "1"), and returns its reply like
+// Value. The command must be a command of the server with keys, all of them among the keys of the module
+// command: ErrNotAllowed is returned for the other commands, ErrKeyNotDeclared for the other keys.
+func (c *Context) Call(command string, args ...string) (interface{}, error) {
+	command = strings.ToUpper(command)
+	meta, ok := eval.DiceCmds[command]
+	if !ok || meta.NewEval == nil || commands[command] {
+		return nil, ErrNotAllowed
+	}
+	spec, ok := cmd.LookupKeySpec(command)
+	if !ok {
+		return nil, ErrNotAllowed
+	}
+	for _, key := range spec.Keys(args) {
+		if !slices.Contains(c.keys, key) {
+			return nil, ErrKeyNotDeclared
+		}
+	}
+
+	resp := eval.NewEval(&cmd.DiceDBCmd{Cmd: command, Args: args}, nil, c.store, false, false, false).ExecuteCommand()
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return Value(clientio.NewReply(resp.Result))
+}
@@ -0,0 +1,405 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package module extends the server with modules: Go code registering commands of its own and hooks around the
+// commands, without changing the commands of the server. A module is compiled in, by registering it from an
+// init function of a package imported by the program starting the server, or loaded from a Go plugin listed
+// in modules.load.
+//
+// The commands of a module run on the shard owning their key, like the commands of the server, and access the
+// store through a Context limited to their keys: a module command can execute the commands of the server on
+// its own keys, and nothing else.
+//
+// The hooks are called by the io-threads, for the commands of the RESP, gRPC and embedded clients:
+//   - a before hook is called once the command is authenticated and admitted, and may refuse it or rewrite
+//     its arguments,
+//   - an after hook is called once the command is replied, with its reply.
+//
+// The changes of the keys are reported to the modules through the hooks package.
+package module
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"plugin"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/hooks"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/server/utils"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+var (
+	ErrKeyNotDeclared = errors.New("ERR a module command can only access its own keys")
+	ErrNotAllowed     = errors.New("ERR this command can not be called by a module command")
+)
+
+// Symbol is the name of the symbol a Go plugin exports its module as, a Module variable or a function
+// returning the Module.
+const Symbol = "Module"
+
+// Module is a set of commands and hooks extending the server.
+type Module interface {
+	// Name identifies the module, e.g. in the logs and in the reply of MODULE LIST
+	Name() string
+	// Init registers the commands and the hooks of the module. It is called once, when the server starts.
+	Init(r *Registry) error
+}
+
+// Command is a command of a module.
+type Command struct {
+	// Name is the name of the command, e.g. COUNTER.INCR. It is conventionally prefixed with the name of the
+	// module, and must not be the name of a command of the server.
+	Name string
+	// Info describes the command in the reply of COMMAND DOCS
+	Info string
+	// Arity is the number of arguments of the command, the command name included, -N standing for N or more
+	Arity int
+	// Keys locates the keys among the arguments. The keys of a command must belong to a single shard, the
+	// commands of a module have at most one key unless their keys share their shard by design.
+	Keys cmd.KeySpec
+	// Write marks the commands writing their keys, e.g. for the audit log and the namespace quotas
+	Write bool
+	// Handler executes the command, returning its reply: nil, a string, an integer, a float, a bool or a
+	// []interface{} of those. It is called by the goroutine of the shard owning the keys and must not block.
+	Handler func(ctx *Context, args []string) (interface{}, error)
+}
+
+// Call is a command executed by a client, as seen by the hooks.
+type Call struct {
+	Command string
+	// Args are the arguments of the command, a before hook may rewrite them
+	Args   []string
+	Client string // Client is the address of the client
+	User   string // User is the user the client is authenticated as, empty when the client is not authenticated
+}
+
+// BeforeFunc is called before a command is executed. The command is refused with the error it returns.
+type BeforeFunc func(c *Call) error
+
+// AfterFunc is called once a command is replied, with its reply or its error, and the time it took.
+type AfterFunc func(c *Call, reply interface{}, err error, elapsed time.Duration)
+
+// Registry collects the commands and the hooks of a module while it is initialized.
+type Registry struct {
+	module   string
+	commands []Command
+	before   []BeforeFunc
+	after    []AfterFunc
+	changes  []hooks.Hooks
+}
+
+// Command registers a command of the module.
+func (r *Registry) Command(c Command) {
+	r.commands = append(r.commands, c)
+}
+
+// Before registers a hook called before every command.
+func (r *Registry) Before(f BeforeFunc) {
+	r.before = append(r.before, f)
+}
+
+// After registers a hook called once every command is replied.
+func (r *Registry) After(f AfterFunc) {
+	r.after = append(r.after, f)
+}
+
+// OnChange registers hooks notified of the changes of the keys, see the hooks package.
+func (r *Registry) OnChange(h hooks.Hooks) {
+	r.changes = append(r.changes, h)
+}
+
+// Info is a loaded module, as reported by MODULE LIST.
+type Info struct {
+	Name     string
+	Path     string   // Path is the path of the plugin of the module, empty for a compiled in module
+	Commands []string // Commands are the names of the commands of the module, ordered
+}
+
+type registration struct {
+	module Module
+	path   string
+}
+
+var (
+	mu         sync.Mutex
+	registered []registration
+	loaded     bool
+
+	// The hooks and the commands of the modules are set by Load, before the clients are served, and only read
+	// afterwards
+	infos    []Info
+	before   []BeforeFunc
+	after    []AfterFunc
+	commands = map[string]bool{}
+)
+
+// Register registers a compiled in module, to be initialized by Load. It is meant to be called from an init
+// function.
+func Register(m Module) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(registered, registration{module: m})
+}
+
+// Load loads the plugins of modules.load and initializes the modules registered, installing their commands and
+// their hooks. It is called once the config is loaded, before the shards are created so that the changes of
+// the keys are reported to the hooks of the modules.
+func Load() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if loaded {
+		return nil
+	}
+	loaded = true
+
+	modules := registered
+	for _, path := range config.DiceConfig().Modules.Load {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		m, err := open(path)
+		if err != nil {
+			return fmt.Errorf("could not load the module %s: %w", path, err)
+		}
+		modules = append(modules, registration{module: m, path: path})
+	}
+
+	names := make(map[string]bool, len(modules))
+	for _, reg := range modules {
+		name := reg.module.Name()
+		if name == "" || names[name] {
+			return fmt.Errorf("invalid module name %q, expected a unique name", name)
+		}
+		names[name] = true
+
+		r := &Registry{module: name}
+		if err := reg.module.Init(r); err != nil {
+			return fmt.Errorf("could not initialize the module %s: %w", name, err)
+		}
+		info := Info{Name: name, Path: reg.path}
+		for _, c := range r.commands {
+			if err := install(c); err != nil {
+				return fmt.Errorf("invalid command of the module %s: %w", name, err)
+			}
+			info.Commands = append(info.Commands, strings.ToUpper(c.Name))
+		}
+		sort.Strings(info.Commands)
+
+		before = append(before, r.before...)
+		after = append(after, r.after...)
+		for _, h := range r.changes {
+			hooks.Register(h)
+		}
+		infos = append(infos, info)
+		slog.Info("loaded module", slog.String("module", name), slog.Any("commands", info.Commands))
+	}
+	return nil
+}
+
+// open loads the module exported by a Go plugin
+func open(path string) (Module, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(Symbol)
+	if err != nil {
+		return nil, err
+	}
+	switch m := sym.(type) {
+	case *Module:
+		if *m != nil {
+			return *m, nil
+		}
+	case func() Module:
+		if module := m(); module != nil {
+			return module, nil
+		}
+	}
+	return nil, fmt.Errorf("the symbol %s of the plugin is not a module", Symbol)
+}
+
+// install adds the command to the commands executed by the shards
+func install(c Command) error {
+	name := strings.ToUpper(c.Name)
+	switch {
+	case name == "" || strings.ContainsFunc(name, unicode.IsSpace):
+		return fmt.Errorf("invalid command name %q", c.Name)
+	case c.Handler == nil:
+		return fmt.Errorf("command %s has no handler", name)
+	case c.Arity == 0:
+		return fmt.Errorf("command %s has no arity", name)
+	}
+	if _, exists := eval.DiceCmds[name]; exists {
+		return fmt.Errorf("command %s already exists", name)
+	}
+
+	handler := c.Handler
+	keys := c.Keys
+	eval.DiceCmds[name] = eval.DiceCmdMeta{
+		Name:     name,
+		Info:     c.Info,
+		Arity:    c.Arity,
+		KeySpecs: keys,
+		NewEval: func(args []string, store *dstore.Store) *eval.EvalResponse {
+			if (c.Arity > 0 && len(args)+1 != c.Arity) || (c.Arity < 0 && len(args)+1 < -c.Arity) {
+				return &eval.EvalResponse{Error: diceerrors.ErrWrongArgumentCount(name)}
+			}
+			ctx := &Context{command: name, store: store, keys: keys.Keys(args)}
+			return execute(ctx, handler, args)
+		},
+	}
+	cmd.RegisterKeySpec(name, keys)
+	if c.Write {
+		audit.RegisterWrite(name)
+	}
+	commands[name] = true
+	return nil
+}
+
+// execute runs the handler of a module command. A handler that panics fails the command rather than the shard.
+func execute(ctx *Context, handler func(*Context, []string) (interface{}, error), args []string) (resp *eval.EvalResponse) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("module command panicked", slog.String("command", ctx.command), slog.Any("panic", r))
+			resp = &eval.EvalResponse{Error: diceerrors.ErrGeneral("module command " + strings.ToLower(ctx.command) + " failed")}
+		}
+	}()
+
+	reply, err := handler(ctx, args)
+	if err != nil {
+		return &eval.EvalResponse{Error: replyError(err)}
+	}
+	if reply == nil {
+		return &eval.EvalResponse{Result: clientio.NIL}
+	}
+	return &eval.EvalResponse{Result: reply}
+}
+
+// replyError returns the error of a module command as replied to the client, prefixed with ERR unless its
+// message starts with an error code
+func replyError(err error) error {
+	var cmdErr *diceerrors.Error
+	if errors.As(err, &cmdErr) {
+		return err
+	}
+	prefix, _, _ := strings.Cut(err.Error(), " ")
+	if prefix != "" && strings.IndexFunc(prefix, func(r rune) bool { return !unicode.IsUpper(r) }) < 0 {
+		return err
+	}
+	return diceerrors.ErrGeneral(err.Error())
+}
+
+// Modules returns the modules loaded, in the order they were initialized.
+func Modules() []Info {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Info(nil), infos...)
+}
+
+// Before calls the before hooks of the modules with the command of a client, in the order they were registered,
+// and returns the error of the first hook refusing it. The arguments rewritten by the hooks replace the ones of
+// the command.
+func Before(client, user string, c *cmd.DiceDBCmd) error {
+	if len(before) == 0 {
+		return nil
+	}
+	call := &Call{Command: c.Cmd, Args: c.Args, Client: client, User: user}
+	defer func() { c.Args = call.Args }()
+	for _, f := range before {
+		if err := callBefore(f, call); err != nil {
+			return replyError(err)
+		}
+	}
+	return nil
+}
+
+func callBefore(f BeforeFunc, call *Call) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("module hook panicked", slog.String("command", call.Command), slog.Any("panic", r))
+			err = diceerrors.ErrInternalServer
+		}
+	}()
+	return f(call)
+}
+
+// After calls the after hooks of the modules with the command of a client and its response, as written to the
+// client.
+func After(client, user string, c *cmd.DiceDBCmd, response interface{}, elapsed time.Duration) {
+	if len(after) == 0 {
+		return
+	}
+	call := &Call{Command: c.Cmd, Args: c.Args, Client: client, User: user}
+	reply, err := Value(clientio.NewReply(response))
+	for _, f := range after {
+		callAfter(f, call, reply, err, elapsed)
+	}
+}
+
+func callAfter(f AfterFunc, call *Call, reply interface{}, err error, elapsed time.Duration) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("module hook panicked", slog.String("command", call.Command), slog.Any("panic", r))
+		}
+	}()
+	f(call, reply, err, elapsed)
+}
+
+// Value converts a reply to nil, a string, an int64 or a []interface{} of those, like the embedded engine
+// does. An error reply is returned as an error, the errors nested in an array being error values of the array.
+func Value(r clientio.Reply) (interface{}, error) {
+	switch r.Kind {
+	case clientio.ReplyError:
+		return nil, errors.New(r.Str)
+	case clientio.ReplySimpleString, clientio.ReplyBulkString:
+		return r.Str, nil
+	case clientio.ReplyInteger:
+		return r.Int, nil
+	case clientio.ReplyDouble:
+		if i, ok := utils.IsFloatToIntPossible(r.Float); ok {
+			return int64(i), nil
+		}
+		return strconv.FormatFloat(r.Float, 'f', -1, 64), nil
+	case clientio.ReplyBoolean:
+		return strconv.FormatBool(r.Bool), nil
+	case clientio.ReplyArray, clientio.ReplyMap:
+		elems := make([]interface{}, len(r.Elems))
+		for i, elem := range r.Elems {
+			v, err := Value(elem)
+			if err != nil {
+				v = err
+			}
+			elems[i] = v
+		}
+		return elems, nil
+	default:
+		return nil, nil
+	}
+}
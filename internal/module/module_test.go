@@ -0,0 +1,207 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package module
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testModule struct {
+	name string
+	init func(r *Registry) error
+}
+
+func (m testModule) Name() string { return m.name }
+
+func (m testModule) Init(r *Registry) error { return m.init(r) }
+
+// load loads the modules as the only ones registered, the commands they install being removed by the cleanup
+func load(t *testing.T, modules ...Module) error {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for name := range commands {
+			delete(eval.DiceCmds, name)
+		}
+		registered, loaded, infos, before, after, commands = nil, false, nil, nil, nil, map[string]bool{}
+	}
+	reset()
+	t.Cleanup(reset)
+
+	for _, m := range modules {
+		Register(m)
+	}
+	return Load()
+}
+
+func run(store *dstore.Store, command string, args ...string) (interface{}, error) {
+	resp := eval.NewEval(&cmd.DiceDBCmd{Cmd: command, Args: args}, nil, store, false, false, false).ExecuteCommand()
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return resp.Result, nil
+}
+
+func TestCommand(t *testing.T) {
+	require.NoError(t, load(t, testModule{name: "counter", init: func(r *Registry) error {
+		r.Command(Command{
+			Name:  "counter.incr",
+			Arity: -2,
+			Keys:  cmd.KeySpec{BeginIndex: 1},
+			Write: true,
+			Handler: func(ctx *Context, args []string) (interface{}, error) {
+				if len(args) > 1 {
+					// The other arguments are keys the command did not declare
+					return ctx.Call("INCR", args[1])
+				}
+				return ctx.Call("INCR", args[0])
+			},
+		})
+		r.Command(Command{
+			Name:  "COUNTER.FAIL",
+			Arity: 2,
+			Keys:  cmd.KeySpec{BeginIndex: 1},
+			Handler: func(ctx *Context, args []string) (interface{}, error) {
+				switch args[0] {
+				case "panic":
+					panic("boom")
+				case "coded":
+					return nil, errors.New("WRONGTYPE not a counter")
+				case "nested":
+					return ctx.Call("COUNTER.INCR", args[0])
+				case "nil":
+					return nil, nil
+				default:
+					return nil, errors.New("failed")
+				}
+			},
+		})
+		return nil
+	}}))
+
+	store := dstore.NewStore(nil, dstore.NewDefaultEviction())
+	for i := int64(1); i <= 2; i++ {
+		reply, err := run(store, "COUNTER.INCR", "c")
+		require.NoError(t, err)
+		assert.Equal(t, i, reply)
+	}
+	reply, err := run(store, "GET", "c")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), reply)
+
+	_, err = run(store, "COUNTER.INCR", "c", "other")
+	assert.ErrorIs(t, err, ErrKeyNotDeclared)
+	_, err = run(store, "COUNTER.INCR")
+	assert.EqualError(t, err, "ERR wrong number of arguments for 'counter.incr' command")
+
+	_, err = run(store, "COUNTER.FAIL", "panic")
+	assert.EqualError(t, err, "ERR module command counter.fail failed")
+	_, err = run(store, "COUNTER.FAIL", "coded")
+	assert.EqualError(t, err, "WRONGTYPE not a counter")
+	_, err = run(store, "COUNTER.FAIL", "other")
+	assert.EqualError(t, err, "ERR failed")
+	_, err = run(store, "COUNTER.FAIL", "nested")
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	reply, err = run(store, "COUNTER.FAIL", "nil")
+	require.NoError(t, err)
+	assert.Equal(t, clientio.NIL, reply)
+
+	spec, ok := cmd.LookupKeySpec("COUNTER.INCR")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"c"}, spec.Keys([]string{"c"}))
+	assert.Equal(t, audit.CategoryWrite, audit.Categorize("COUNTER.INCR"))
+	assert.Equal(t, audit.CategoryRead, audit.Categorize("COUNTER.FAIL"))
+	assert.Equal(t, []Info{{Name: "counter", Commands: []string{"COUNTER.FAIL", "COUNTER.INCR"}}}, Modules())
+}
+
+func TestLoadInvalid(t *testing.T) {
+	handler := func(*Context, []string) (interface{}, error) { return nil, nil }
+	for name, m := range map[string]Module{
+		"existing command": testModule{name: "m", init: func(r *Registry) error {
+			r.Command(Command{Name: "GET", Arity: 2, Handler: handler})
+			return nil
+		}},
+		"no handler": testModule{name: "m", init: func(r *Registry) error {
+			r.Command(Command{Name: "M.CMD", Arity: 2})
+			return nil
+		}},
+		"init failure": testModule{name: "m", init: func(*Registry) error { return errors.New("failed") }},
+		"no name":      testModule{init: func(*Registry) error { return nil }},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, load(t, m))
+		})
+	}
+
+	config.DiceConfig().Modules.Load = []string{"/nonexistent/module.so"}
+	t.Cleanup(func() { config.DiceConfig().Modules.Load = nil })
+	mu.Lock()
+	loaded = false
+	mu.Unlock()
+	assert.ErrorContains(t, Load(), "/nonexistent/module.so")
+}
+
+func TestHooks(t *testing.T) {
+	var calls []string
+	var replied interface{}
+	var replyErr error
+	require.NoError(t, load(t, testModule{name: "hooks", init: func(r *Registry) error {
+		r.Before(func(c *Call) error {
+			calls = append(calls, c.Client+" "+c.User+" "+c.Command)
+			switch c.Command {
+			case "DEL":
+				return errors.New("NOPERM deletes are disabled")
+			case "SET":
+				c.Args[1] = "rewritten"
+			case "PANIC":
+				panic("boom")
+			}
+			return nil
+		})
+		r.After(func(c *Call, reply interface{}, err error, _ time.Duration) {
+			replied, replyErr = reply, err
+		})
+		return nil
+	}}))
+
+	set := &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}
+	require.NoError(t, Before("127.0.0.1:1234", "alice", set))
+	assert.Equal(t, []string{"k", "rewritten"}, set.Args)
+	assert.EqualError(t, Before("127.0.0.1:1234", "alice", &cmd.DiceDBCmd{Cmd: "DEL", Args: []string{"k"}}),
+		"NOPERM deletes are disabled")
+	assert.Error(t, Before("127.0.0.1:1234", "", &cmd.DiceDBCmd{Cmd: "PANIC"}))
+	assert.Equal(t, []string{"127.0.0.1:1234 alice SET", "127.0.0.1:1234 alice DEL", "127.0.0.1:1234  PANIC"}, calls)
+
+	After("127.0.0.1:1234", "alice", set, []interface{}{"a", int64(1)}, time.Millisecond)
+	assert.Equal(t, []interface{}{"a", int64(1)}, replied)
+	assert.NoError(t, replyErr)
+	After("127.0.0.1:1234", "alice", set, errors.New("ERR failed"), time.Millisecond)
+	assert.Nil(t, replied)
+	assert.EqualError(t, replyErr, "ERR failed")
+}
@@ -0,0 +1,102 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package namespace
+
+import (
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	dstore "github.com/dicedb/dice/internal/store"
+)
+
+// Meter measures the usage of the namespaces by the keys of a store. The keys are measured when they are
+// written and once every command is executed, as most commands modify the values of the keys in place. It is
+// only called by the goroutine of the shard owning the store.
+type Meter struct {
+	store *dstore.Store
+	sizes map[string]measurement // sizes are the sizes of the keys measured last
+}
+
+// measurement is the size of a key and the namespace it was counted in, a namespace created afterwards
+// taking over the keys of its prefix once they are measured again
+type measurement struct {
+	ns   *Namespace
+	size int64
+}
+
+// NewMeter returns the meter of the store, subscribed to its key events. It must be called before the shard
+// owning the store is started.
+func NewMeter(store *dstore.Store) *Meter {
+	m := &Meter{store: store, sizes: make(map[string]measurement)}
+	store.Subscribe(m)
+	return m
+}
+
+func (m *Meter) OnKeyEvent(e dstore.KeyEvent) {
+	if e.Type == dstore.KeyEventDel {
+		m.release(e.Key)
+		return
+	}
+	m.measure(e.Key)
+}
+
+// Measure measures the keys of the command once it is executed. A nil Meter measures nothing.
+func (m *Meter) Measure(c *cmd.DiceDBCmd) {
+	if m == nil {
+		return
+	}
+
+	// A flush empties the store without reporting the keys removed
+	if m.store.GetKeyCount() == 0 && len(m.sizes) > 0 {
+		for key := range m.sizes {
+			m.release(key)
+		}
+	}
+	for _, key := range cmd.Keys(c) {
+		m.measure(key)
+	}
+}
+
+func (m *Meter) measure(key string) {
+	ns := lookup(key)
+	obj, ok := m.store.GetStore().Get(key)
+	if !ok || obj == nil {
+		m.release(key)
+		return
+	}
+
+	size := int64(len(key)) + eval.ObjectSize(obj)
+	prev, measured := m.sizes[key]
+	if measured && prev.ns != ns {
+		m.release(key)
+		prev, measured = measurement{}, false
+	}
+	if !measured {
+		ns.keys.Add(1)
+	}
+	ns.memory.Add(size - prev.size)
+	m.sizes[key] = measurement{ns: ns, size: size}
+}
+
+func (m *Meter) release(key string) {
+	prev, ok := m.sizes[key]
+	if !ok {
+		return
+	}
+	delete(m.sizes, key)
+	prev.ns.keys.Add(-1)
+	prev.ns.memory.Add(-prev.size)
+}
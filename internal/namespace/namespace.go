@@ -0,0 +1,403 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package namespace partitions the keyspace between the applications sharing a server. A client confined to
+// a namespace, by authenticating as one of the tenants of namespaces.tenants or with NAMESPACE USE, only sees
+// the keys of the namespace: the keys of its commands are prefixed with the name of the namespace and a colon,
+// so that the key k of the namespace app is stored as app:k. The clients of the default namespace see the
+// whole keyspace, the keys of every namespace included.
+//
+// Every namespace has quotas, the ones of the config unless set with NAMESPACE SETQUOTA:
+//   - max_keys bounds the number of keys of the namespace,
+//   - max_memory bounds the estimated size of its keys and their values, in bytes,
+//   - max_ops_per_sec bounds the number of commands its clients execute per second.
+//
+// Once a namespace holds max_keys keys or max_memory bytes, the commands writing keys are refused, but for the
+// ones removing keys or members. The usage is measured by the shards once the commands are executed, so that
+// a namespace may exceed its quotas by the writes of the commands admitted before they were reached.
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/cmd"
+)
+
+// Default is the name of the namespace of the clients that are not confined to one, seeing the whole keyspace.
+const Default = "default"
+
+var (
+	ErrDisabled       = errors.New("ERR namespaces are disabled")
+	ErrInvalidName    = errors.New("ERR invalid namespace name, expected 1 to 64 letters, digits, '-' or '_'")
+	ErrNotAllowed     = errors.New("NOPERM this command is not available in a namespace")
+	ErrNoSuchName     = errors.New("ERR no such namespace")
+	ErrQuotaExceeded  = errors.New("OOM command not allowed when the namespace reached its quota")
+	ErrRateExceeded   = errors.New("BUSY the namespace exceeded its command rate, try again later")
+	ErrConnectionless = errors.New("ERR namespaces are only available over RESP, " +
+		"the HTTP and WebSocket clients see the whole keyspace")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// keyless are the commands without keys available in a namespace, the other commands without keys act on
+// the whole keyspace or on the server
+var keyless = map[string]bool{
+	"AUTH":      true,
+	"COMMAND":   true,
+	"DISCARD":   true,
+	"ECHO":      true,
+	"EXEC":      true,
+	"HELLO":     true,
+	"LOLWUT":    true,
+	"MULTI":     true,
+	"NAMESPACE": true,
+	"PING":      true,
+	"QUIT":      true,
+	"RESET":     true,
+	"SLEEP":     true,
+}
+
+// removing are the commands writing keys that are admitted once the quotas are reached, as they remove keys,
+// members or make keys expire
+var removing = map[string]bool{
+	"BLPOP":        true,
+	"BRPOP":        true,
+	"BZPOPMAX":     true,
+	"BZPOPMIN":     true,
+	"DEL":          true,
+	"DELIFEQ":      true,
+	"EXPIRE":       true,
+	"EXPIREAT":     true,
+	"GETDEL":       true,
+	"HDEL":         true,
+	"HGETDEL":      true,
+	"JSON.ARRPOP":  true,
+	"JSON.ARRTRIM": true,
+	"JSON.CLEAR":   true,
+	"JSON.DEL":     true,
+	"JSON.FORGET":  true,
+	"LPOP":         true,
+	"PEXPIRE":      true,
+	"PEXPIREIFEQ":  true,
+	"RPOP":         true,
+	"SREM":         true,
+	"UNLOCK":       true,
+	"ZPOPMAX":      true,
+	"ZPOPMIN":      true,
+	"ZREM":         true,
+}
+
+// Quota bounds the usage of a namespace, a field being 0 for no limit.
+type Quota struct {
+	MaxKeys      int64
+	MaxMemory    int64
+	MaxOpsPerSec int64
+}
+
+// Info is the usage and the quota of a namespace, as reported by NAMESPACE INFO.
+type Info struct {
+	Name string
+	Quota
+	Keys     int64
+	Memory   int64
+	Rejected int64 // Rejected is the number of commands refused for exceeding the quotas
+}
+
+// Namespace is a partition of the keyspace. Its quota fields are -1 while they follow the config.
+type Namespace struct {
+	name   string
+	prefix string
+
+	maxKeys      atomic.Int64
+	maxMemory    atomic.Int64
+	maxOpsPerSec atomic.Int64
+
+	keys     atomic.Int64
+	memory   atomic.Int64
+	rejected atomic.Int64
+
+	rateMu     sync.Mutex
+	tokens     float64
+	refilledAt time.Time
+}
+
+// fallback is the default namespace, measuring the keys of no namespace. It has no quota.
+var fallback = &Namespace{name: Default}
+
+var (
+	createMu sync.Mutex
+	// namespaces are the namespaces by name, replaced as a whole when one is created so that the shards
+	// look them up without locking
+	namespaces atomic.Pointer[map[string]*Namespace]
+)
+
+// Load creates the namespaces of the tenants of the config, and the users authenticating the clients confined
+// to them. It is called once the config is loaded, before the clients are served.
+func Load() error {
+	if !config.DiceConfig().Namespaces.Enabled {
+		return nil
+	}
+
+	for _, tenant := range config.DiceConfig().Namespaces.Tenants {
+		name, password, ok := strings.Cut(strings.TrimSpace(tenant), ":")
+		if !ok || password == "" {
+			return fmt.Errorf("invalid tenant %q, expected NAME:PASSWORD", name)
+		}
+		if name == config.DiceConfig().Auth.UserName {
+			return fmt.Errorf("invalid tenant %q, the default user can not be confined to a namespace", name)
+		}
+		if _, err := Create(name); err != nil {
+			return fmt.Errorf("invalid tenant %q: %w", name, err)
+		}
+
+		user, err := auth.UserStore.Add(name)
+		if err != nil {
+			return err
+		}
+		if err := user.SetPassword(password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create returns the namespace of the name, created with the quotas of the config if it does not exist yet.
+func Create(name string) (*Namespace, error) {
+	if !validName.MatchString(name) || name == Default {
+		return nil, ErrInvalidName
+	}
+	if ns := Get(name); ns != nil {
+		return ns, nil
+	}
+
+	createMu.Lock()
+	defer createMu.Unlock()
+	current := namespaces.Load()
+	if current != nil {
+		if ns, ok := (*current)[name]; ok {
+			return ns, nil
+		}
+	}
+
+	ns := &Namespace{name: name, prefix: name + ":"}
+	ns.SetQuota(Quota{MaxKeys: -1, MaxMemory: -1, MaxOpsPerSec: -1})
+
+	next := make(map[string]*Namespace, 1)
+	if current != nil {
+		for n, existing := range *current {
+			next[n] = existing
+		}
+	}
+	next[name] = ns
+	namespaces.Store(&next)
+	return ns, nil
+}
+
+// Get returns the namespace of the name, nil if it does not exist.
+func Get(name string) *Namespace {
+	current := namespaces.Load()
+	if current == nil {
+		return nil
+	}
+	return (*current)[name]
+}
+
+// List returns the namespaces, ordered by name.
+func List() []*Namespace {
+	current := namespaces.Load()
+	if current == nil {
+		return nil
+	}
+
+	list := make([]*Namespace, 0, len(*current))
+	for _, ns := range *current {
+		list = append(list, ns)
+	}
+	slices.SortFunc(list, func(a, b *Namespace) int { return strings.Compare(a.name, b.name) })
+	return list
+}
+
+// DefaultInfo returns the usage of the default namespace, the keys of no namespace, as reported by NAMESPACE INFO.
+func DefaultInfo() Info {
+	return fallback.Info()
+}
+
+// lookup returns the namespace the key belongs to, the default one for the keys of no namespace.
+func lookup(key string) *Namespace {
+	name, _, ok := strings.Cut(key, ":")
+	if !ok {
+		return fallback
+	}
+	if current := namespaces.Load(); current != nil {
+		if ns, ok := (*current)[name]; ok {
+			return ns
+		}
+	}
+	return fallback
+}
+
+func (ns *Namespace) Name() string {
+	return ns.name
+}
+
+// Unprefix returns the key as seen by the clients of the namespace. A nil namespace, the default one, returns
+// the key as it is.
+func (ns *Namespace) Unprefix(key string) string {
+	if ns == nil {
+		return key
+	}
+	return strings.TrimPrefix(key, ns.prefix)
+}
+
+// UnprefixKeys returns the keys as seen by the clients of the namespace, in place.
+func (ns *Namespace) UnprefixKeys(keys []string) []string {
+	for i, key := range keys {
+		keys[i] = ns.Unprefix(key)
+	}
+	return keys
+}
+
+// Quota returns the quota of the namespace, the fields not set by SetQuota being the ones of the config.
+func (ns *Namespace) Quota() Quota {
+	q := Quota{MaxKeys: ns.maxKeys.Load(), MaxMemory: ns.maxMemory.Load(), MaxOpsPerSec: ns.maxOpsPerSec.Load()}
+	if q.MaxKeys < 0 {
+		q.MaxKeys = config.DiceConfig().Namespaces.MaxKeys
+	}
+	if q.MaxMemory < 0 {
+		q.MaxMemory = config.DiceConfig().Namespaces.MaxMemory
+	}
+	if q.MaxOpsPerSec < 0 {
+		q.MaxOpsPerSec = config.DiceConfig().Namespaces.MaxOpsPerSec
+	}
+	return q
+}
+
+// SetQuota sets the quota of the namespace, a negative field following the config.
+func (ns *Namespace) SetQuota(q Quota) {
+	ns.maxKeys.Store(q.MaxKeys)
+	ns.maxMemory.Store(q.MaxMemory)
+	ns.maxOpsPerSec.Store(q.MaxOpsPerSec)
+
+	ns.rateMu.Lock()
+	ns.refilledAt = time.Time{}
+	ns.rateMu.Unlock()
+}
+
+// Info returns the usage and the quota of the namespace.
+func (ns *Namespace) Info() Info {
+	return Info{
+		Name:     ns.name,
+		Quota:    ns.Quota(),
+		Keys:     ns.keys.Load(),
+		Memory:   ns.memory.Load(),
+		Rejected: ns.rejected.Load(),
+	}
+}
+
+// Admit checks that a client of the namespace may execute the command, and prefixes its keys with the name
+// of the namespace. The commands acting on the whole keyspace or on the server are refused, but for KEYS
+// whose pattern is prefixed like a key.
+func (ns *Namespace) Admit(c *cmd.DiceDBCmd) error {
+	if !ns.allow() {
+		ns.rejected.Add(1)
+		return ErrRateExceeded
+	}
+
+	switch {
+	case c.Cmd == "KEYS":
+		if len(c.Args) == 1 {
+			c.Args[0] = ns.prefix + c.Args[0]
+		}
+		return nil
+	case strings.HasSuffix(c.Cmd, ".UNWATCH"):
+		// The argument of the unwatch commands is the fingerprint of the watched command, not a key
+		return nil
+	}
+
+	spec, ok := cmd.LookupKeySpec(c.Cmd)
+	if !ok {
+		if keyless[c.Cmd] {
+			return nil
+		}
+		return ErrNotAllowed
+	}
+
+	if audit.Categorize(c.Cmd) == audit.CategoryWrite && !removing[c.Cmd] && ns.exhausted() {
+		ns.rejected.Add(1)
+		return ErrQuotaExceeded
+	}
+	for _, i := range spec.KeyIndices(c.Args) {
+		c.Args[i] = ns.prefix + c.Args[i]
+	}
+	return nil
+}
+
+// AdmitConnectionless checks that a command sent over HTTP or WebSocket does not confine its client to a
+// namespace. These requests are not tied to a connection, so that NAMESPACE and AUTH as a tenant are refused
+// rather than leaving the client in the whole keyspace without its quotas.
+func AdmitConnectionless(c *cmd.DiceDBCmd) error {
+	switch c.Cmd {
+	case "NAMESPACE":
+		return ErrConnectionless
+	case "AUTH":
+		if len(c.Args) == 2 && Get(c.Args[0]) != nil {
+			return ErrConnectionless
+		}
+	}
+	return nil
+}
+
+// exhausted reports whether the namespace reached its quota of keys or memory.
+func (ns *Namespace) exhausted() bool {
+	q := ns.Quota()
+	return (q.MaxKeys > 0 && ns.keys.Load() >= q.MaxKeys) || (q.MaxMemory > 0 && ns.memory.Load() >= q.MaxMemory)
+}
+
+// allow takes a token of the command rate of the namespace. The bucket holds the commands of one second, so
+// that a namespace idle for a while may execute a burst of max_ops_per_sec commands at once.
+func (ns *Namespace) allow() bool {
+	rate := ns.Quota().MaxOpsPerSec
+	if rate <= 0 {
+		return true
+	}
+
+	ns.rateMu.Lock()
+	defer ns.rateMu.Unlock()
+
+	now := time.Now()
+	if ns.refilledAt.IsZero() {
+		ns.tokens = float64(rate)
+	} else {
+		ns.tokens = min(float64(rate), ns.tokens+now.Sub(ns.refilledAt).Seconds()*float64(rate))
+	}
+	ns.refilledAt = now
+
+	if ns.tokens < 1 {
+		return false
+	}
+	ns.tokens--
+	return true
+}
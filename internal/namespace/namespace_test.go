@@ -0,0 +1,168 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package namespace
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNamespace(t *testing.T, name string) *Namespace {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().Namespaces.Enabled = true
+
+	ns, err := Create(name)
+	require.NoError(t, err)
+	ns.SetQuota(Quota{MaxKeys: -1, MaxMemory: -1, MaxOpsPerSec: -1})
+	return ns
+}
+
+func TestCreate(t *testing.T) {
+	ns := newTestNamespace(t, "create")
+	again, err := Create("create")
+	require.NoError(t, err)
+	assert.Same(t, ns, again)
+	assert.Same(t, ns, Get("create"))
+	assert.Contains(t, List(), ns)
+
+	for _, name := range []string{"", Default, "a:b", "a b"} {
+		_, err := Create(name)
+		assert.ErrorIs(t, err, ErrInvalidName, name)
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	ns := newTestNamespace(t, "app")
+
+	tests := []struct {
+		cmd  *cmd.DiceDBCmd
+		want []string
+		err  error
+	}{
+		{cmd: &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v", "EX", "10"}}, want: []string{"app:k", "v", "EX", "10"}},
+		{cmd: &cmd.DiceDBCmd{Cmd: "DEL", Args: []string{"a", "b"}}, want: []string{"app:a", "app:b"}},
+		{cmd: &cmd.DiceDBCmd{Cmd: "KEYS", Args: []string{"user:*"}}, want: []string{"app:user:*"}},
+		{cmd: &cmd.DiceDBCmd{Cmd: "GET.UNWATCH", Args: []string{"123"}}, want: []string{"123"}},
+		{cmd: &cmd.DiceDBCmd{Cmd: "PING", Args: []string{}}, want: []string{}},
+		{cmd: &cmd.DiceDBCmd{Cmd: "DBSIZE", Args: []string{}}, err: ErrNotAllowed},
+		{cmd: &cmd.DiceDBCmd{Cmd: "FLUSHDB", Args: []string{}}, err: ErrNotAllowed},
+	}
+	for _, tt := range tests {
+		err := ns.Admit(tt.cmd)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, tt.cmd.Cmd)
+			continue
+		}
+		require.NoError(t, err, tt.cmd.Cmd)
+		assert.Equal(t, tt.want, tt.cmd.Args, tt.cmd.Cmd)
+	}
+
+	assert.Equal(t, "k", ns.Unprefix("app:k"))
+	assert.Equal(t, "other:k", ns.Unprefix("other:k"))
+	assert.Equal(t, "k", (*Namespace)(nil).Unprefix("k"))
+}
+
+func TestAdmitRate(t *testing.T) {
+	ns := newTestNamespace(t, "rate")
+	ns.SetQuota(Quota{MaxKeys: -1, MaxMemory: -1, MaxOpsPerSec: 3})
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, ns.Admit(&cmd.DiceDBCmd{Cmd: "PING"}))
+	}
+	assert.ErrorIs(t, ns.Admit(&cmd.DiceDBCmd{Cmd: "PING"}), ErrRateExceeded)
+	assert.EqualValues(t, 1, ns.Info().Rejected)
+}
+
+func TestMeter(t *testing.T) {
+	ns := newTestNamespace(t, "meter")
+	ns.SetQuota(Quota{MaxKeys: 3, MaxMemory: -1, MaxOpsPerSec: -1})
+
+	store := dstore.NewStore(nil, dstore.NewDefaultEviction())
+	meter := NewMeter(store)
+	execute := func(args ...string) error {
+		c := &cmd.DiceDBCmd{Cmd: args[0], Args: args[1:]}
+		if err := ns.Admit(c); err != nil {
+			return err
+		}
+		eval.NewEval(c, nil, store, false, false, false).ExecuteCommand()
+		meter.Measure(c)
+		return nil
+	}
+
+	require.NoError(t, execute("SET", "k", "v"))
+	require.NoError(t, execute("SADD", "s", "a"))
+	info := ns.Info()
+	assert.EqualValues(t, 2, info.Keys)
+	assert.EqualValues(t, len("meter:k")+1+len("meter:s")+1, info.Memory)
+
+	// The members added in place are measured once the command is executed
+	require.NoError(t, execute("SADD", "s", "bcd"))
+	assert.EqualValues(t, info.Memory+3, ns.Info().Memory)
+
+	// The keys of the other namespaces are not measured, the ones of none are measured in the default namespace
+	defaultKeys := DefaultInfo().Keys
+	store.Put("other", store.NewObj("v", -1, 0))
+	assert.EqualValues(t, 2, ns.Info().Keys)
+	assert.EqualValues(t, defaultKeys+1, DefaultInfo().Keys)
+
+	// Once the quota is reached only the commands removing keys are admitted
+	require.NoError(t, execute("SET", "k2", "v"))
+	assert.ErrorIs(t, execute("SET", "k3", "v"), ErrQuotaExceeded)
+	require.NoError(t, execute("DEL", "k"))
+	assert.EqualValues(t, 2, ns.Info().Keys)
+	require.NoError(t, execute("SET", "k3", "v"))
+
+	store.Flush(false)
+	meter.Measure(&cmd.DiceDBCmd{Cmd: "PING"})
+	assert.Zero(t, ns.Info().Keys)
+	assert.Zero(t, ns.Info().Memory)
+	assert.Equal(t, defaultKeys, DefaultInfo().Keys)
+}
+
+func TestMeterCreatedNamespace(t *testing.T) {
+	store := dstore.NewStore(nil, dstore.NewDefaultEviction())
+	meter := NewMeter(store)
+	defaultKeys := DefaultInfo().Keys
+
+	// The keys measured in the default namespace move to the namespace of their prefix once it is created
+	store.Put("late:k", store.NewObj("v", -1, 0))
+	assert.EqualValues(t, defaultKeys+1, DefaultInfo().Keys)
+	ns := newTestNamespace(t, "late")
+	meter.Measure(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"late:k"}})
+	assert.EqualValues(t, defaultKeys, DefaultInfo().Keys)
+	assert.EqualValues(t, 1, ns.Info().Keys)
+
+	store.Del("late:k")
+	assert.Zero(t, ns.Info().Keys)
+	assert.Zero(t, ns.Info().Memory)
+}
+
+func TestAdmitConnectionless(t *testing.T) {
+	newTestNamespace(t, "remote")
+
+	assert.ErrorIs(t, AdmitConnectionless(&cmd.DiceDBCmd{Cmd: "NAMESPACE", Args: []string{"USE", "remote"}}), ErrConnectionless)
+	assert.ErrorIs(t, AdmitConnectionless(&cmd.DiceDBCmd{Cmd: "AUTH", Args: []string{"remote", "secret"}}), ErrConnectionless)
+	assert.NoError(t, AdmitConnectionless(&cmd.DiceDBCmd{Cmd: "AUTH", Args: []string{"secret"}}))
+	assert.NoError(t, AdmitConnectionless(&cmd.DiceDBCmd{Cmd: "AUTH", Args: []string{"nobody", "secret"}}))
+	assert.NoError(t, AdmitConnectionless(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"remote:k"}}))
+}
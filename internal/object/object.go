@@ -39,6 +39,9 @@ package object
 //   - Value: An `interface{}` type that holds the actual data of the object. This could
 //     represent any type of data, allowing flexibility to store different kinds of
 //     objects (e.g., strings, numbers, complex data structures like lists or maps).
+//
+//   - Version: A uint64 field that changes with every write of the key holding the object,
+//     reported by OBJECT VERSION and checked by the IFVERSION option of the writes.
 type Obj struct {
 	// Type holds the type of the object (e.g., string, int, complex structure)
 	Type ObjectType
@@ -50,6 +53,9 @@ type Obj struct {
 	// Value holds the actual content or data of the object, which can be of any type.
 	// This allows flexibility in storing various kinds of objects (simple or complex).
 	Value interface{}
+
+	// Version is the version of the key holding the object, given by the store on every write of the key.
+	Version uint64
 }
 
 // ExtendedObj is an extension of the `Obj` struct, designed to add extra
@@ -53,8 +53,8 @@ func Ping() {
 
 	payload := &PingPayload{
 		HardwareConfig: hwConfig,
-		InstanceID:     config.DiceConfig.InstanceID,
-		Version:        config.DiceConfig.Version,
+		InstanceID:     config.DiceConfig().InstanceID,
+		Version:        config.DiceConfig().Version,
 		Err:            err,
 		Date:           time.Now().UTC().Format("2006-01-02 15:04:05"),
 		DBConfig:       DBConfig{},
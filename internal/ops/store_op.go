@@ -17,21 +17,61 @@
 package ops
 
 import (
+	"context"
+	"time"
+
 	"github.com/dicedb/dice/internal/cmd"
 	"github.com/dicedb/dice/internal/comm"
 	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type StoreOp struct {
-	SeqID         uint8          // SeqID is the sequence id of the operation within a single request (optional, may be used for ordering)
-	RequestID     uint32         // RequestID identifies the request that this StoreOp belongs to
-	Cmd           *cmd.DiceDBCmd // Cmd is the atomic Store command (e.g., GET, SET)
-	ShardID       uint8          // ShardID of the shard on which the Store command will be executed
-	IOThreadID    string         // IOThreadID is the ID of the io-thread that sent this Store operation
-	Client        *comm.Client   // Client that sent this Store operation. TODO: This can potentially replace the IOThreadID in the future
-	HTTPOp        bool           // HTTPOp is true if this Store operation is an HTTP operation
-	WebsocketOp   bool           // WebsocketOp is true if this Store operation is a Websocket operation
-	PreProcessing bool           // PreProcessing indicates whether a comamnd operation requires preprocessing before execution. This is mainly used is multi-step-multi-shard commands
+	SeqID         uint8             // SeqID is the sequence id of the operation within a single request (optional, may be used for ordering)
+	RequestID     uint32            // RequestID identifies the request that this StoreOp belongs to
+	Cmd           *cmd.DiceDBCmd    // Cmd is the atomic Store command (e.g., GET, SET)
+	ShardID       uint8             // ShardID of the shard on which the Store command will be executed
+	IOThreadID    string            // IOThreadID is the ID of the io-thread that sent this Store operation
+	Client        *comm.Client      // Client that sent this Store operation. TODO: This can potentially replace the IOThreadID in the future
+	ClientAddr    string            // ClientAddr is the remote address of the client that sent this Store operation
+	ClientID      uint64            // ClientID is the id of the RESP client that sent this Store operation, 0 for the other frontends
+	HTTPOp        bool              // HTTPOp is true if this Store operation is an HTTP operation
+	WebsocketOp   bool              // WebsocketOp is true if this Store operation is a Websocket operation
+	SpanContext   trace.SpanContext // SpanContext of the request span, the shard traces its execution of the operation as a child of it
+	Ctx           context.Context   // Ctx is cancelled once the client disconnects, nil for the operations not tied to a client connection
+	PreProcessing bool              // PreProcessing indicates whether a comamnd operation requires preprocessing before execution. This is mainly used is multi-step-multi-shard commands
+	Txn           *TxnOp            // Txn is set when the operation is a step of the two-phase commit of a transaction, RequestID being the transaction id
+	Batch         *BatchOp          // Batch is set when the operation executes a batch of commands rather than Cmd, e.g. for IMPORT
+	Timing        *tracing.Timing   // Timing records the stages of the request when its client asked for them, nil otherwise
+	SentAt        time.Time         // SentAt is the time the operation was queued on the shard, set for the operations with a Timing only
+}
+
+// TxnPhase is the step of the two-phase commit of a MULTI/EXEC transaction carried by a StoreOp.
+type TxnPhase uint8
+
+const (
+	// TxnPrepare locks the shard for the transaction: the other operations are held back until it is released
+	TxnPrepare TxnPhase = iota + 1
+	// TxnCheck executes the commands of the guard of the transaction on the locked shard, before the commit
+	TxnCheck
+	// TxnCommit executes the commands of the transaction on the locked shard
+	TxnCommit
+	// TxnRelease unlocks the shard, dropping the transaction if it was not committed
+	TxnRelease
+)
+
+// TxnOp is the transaction step of a StoreOp.
+type TxnOp struct {
+	Phase        TxnPhase            // Phase of the two-phase commit
+	Cmds         []*cmd.DiceDBCmd    // Cmds are the commands the shard executes on commit or check, in the order they were queued
+	ResponseChan chan *StoreResponse // ResponseChan receives the responses of the shards to the steps of the transaction
+}
+
+// BatchOp is a batch of commands executed by a shard in a single operation, saving the dispatch of every command.
+type BatchOp struct {
+	Cmds         []*cmd.DiceDBCmd    // Cmds are the commands the shard executes, in order
+	ResponseChan chan *StoreResponse // ResponseChan receives the responses of the commands, as a []*eval.EvalResponse
 }
 
 // StoreResponse represents the response of a Store operation.
@@ -20,10 +20,13 @@ package querymanager
 // the client explicitly requesting them. These are typically seen in scenarios where the client has subscribed to some
 // kind of event or data feed and is notified in real-time when changes occur.
 // `key` is the unique key that identifies the push response.
-func GenericWatchResponse(cmd, key string, result interface{}) (response []interface{}) {
-	response = make([]interface{}, 3)
+// `seq` is the sequence number of the push within its subscription, starting at 0 with the initial result. A gap
+// between the sequence numbers of two pushes means that updates were dropped and the client should resync.
+func GenericWatchResponse(cmd, key string, result interface{}, seq uint64) (response []interface{}) {
+	response = make([]interface{}, 4)
 	response[0] = cmd
 	response[1] = key
 	response[2] = result
+	response[3] = seq
 	return
 }
@@ -0,0 +1,97 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package sdnotify tells the service manager the server runs under the state of the server, with the notify
+// protocol of systemd: the states are sent as newline separated assignments in a datagram to the Unix socket
+// named by $NOTIFY_SOCKET. Every notification is a no-op when the variable is not set, e.g. when the server is
+// not started by systemd or its unit is not of Type=notify.
+//
+// The variable is left in the environment, so that a new process started by a handover notifies the service
+// manager too. The service manager only accepts the notifications of the main process of the service unless
+// NotifyAccess=all is set in the unit.
+package sdnotify
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// envSocket is the environment variable naming the socket of the service manager
+const envSocket = "NOTIFY_SOCKET"
+
+const (
+	// Ready tells that the server accepts connections, once started or once reloaded
+	Ready = "READY=1"
+	// Reloading tells that the server reloads its config, until Ready is sent
+	Reloading = "RELOADING=1"
+	// Stopping tells that the server is shutting down and accepts no more connections
+	Stopping = "STOPPING=1"
+)
+
+// Status is a free-form description of the state of the server, shown by systemctl status.
+func Status(status string) string {
+	return "STATUS=" + status
+}
+
+// MainPID tells that the process of the pid is the main process of the service from then on.
+func MainPID(pid int) string {
+	return "MAINPID=" + strconv.Itoa(pid)
+}
+
+// Enabled reports whether the server runs under a service manager listening to the notifications.
+func Enabled() bool {
+	return os.Getenv(envSocket) != ""
+}
+
+// Notify sends the states to the service manager in a single notification. Reloading is sent along with the
+// time of the reload on the monotonic clock, as required by the units of Type=notify-reload.
+func Notify(states ...string) error {
+	path := os.Getenv(envSocket)
+	if path == "" {
+		return nil
+	}
+	// A leading @ names a socket of the abstract namespace
+	if path[0] == '@' {
+		path = "\x00" + path[1:]
+	}
+
+	for _, state := range states {
+		if state == Reloading {
+			var ts unix.Timespec
+			if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+				return fmt.Errorf("could not read the monotonic clock: %w", err)
+			}
+			states = append(states, "MONOTONIC_USEC="+strconv.FormatInt(ts.Nano()/1000, 10))
+			break
+		}
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("could not connect to the service manager: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(states, "\n") + "\n")); err != nil {
+		return fmt.Errorf("could not notify the service manager: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package sdnotify
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listen(t *testing.T, path string) *net.UnixConn {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func receive(t *testing.T, conn *net.UnixConn) []string {
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(buf[:n]), "\n"), "\n")
+}
+
+func TestNotify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn := listen(t, path)
+	t.Setenv(envSocket, path)
+
+	assert.True(t, Enabled())
+	require.NoError(t, Notify(Ready, Status("accepting connections")))
+	assert.Equal(t, []string{"READY=1", "STATUS=accepting connections"}, receive(t, conn))
+
+	// The reloads carry the time they started at
+	require.NoError(t, Notify(Reloading))
+	states := receive(t, conn)
+	require.Len(t, states, 2)
+	assert.Equal(t, "RELOADING=1", states[0])
+	assert.True(t, strings.HasPrefix(states[1], "MONOTONIC_USEC="), states[1])
+
+	require.NoError(t, Notify(MainPID(42), Ready))
+	assert.Equal(t, []string{"MAINPID=42", "READY=1"}, receive(t, conn))
+}
+
+func TestNotifyAbstractSocket(t *testing.T) {
+	name := "dicedb-sdnotify-" + filepath.Base(t.TempDir())
+	conn := listen(t, "@"+name)
+	t.Setenv(envSocket, "@"+name)
+
+	require.NoError(t, Notify(Stopping))
+	assert.Equal(t, []string{"STOPPING=1"}, receive(t, conn))
+}
+
+func TestNotifyWithoutServiceManager(t *testing.T) {
+	t.Setenv(envSocket, "")
+
+	assert.False(t, Enabled())
+	assert.NoError(t, Notify(Ready))
+}
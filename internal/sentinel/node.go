@@ -0,0 +1,212 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package sentinel
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Roles reported by INFO replication
+const (
+	roleLeader  = "master"
+	roleReplica = "slave"
+)
+
+// defaultPriority is the priority of a replica not reporting one
+const defaultPriority = 100
+
+// node is a leader or a replica monitored
+type node struct {
+	addr string
+	// conn is dialed on the first command and closed after any failure, it is only used by the monitor
+	conn net.Conn
+	r    *bufio.Reader
+
+	// lastOK is the time of the last valid reply to PING, or when the node was added
+	lastOK time.Time
+	info   replicationInfo
+	// stale is set on the replicas of a former leader, until they are pointed at the current one
+	stale bool
+}
+
+// replicationInfo is the state of a node reported by INFO replication
+type replicationInfo struct {
+	role string
+	// leader is the address of the leader followed by a replica
+	leader string
+	linkUp bool
+	// offset is the replication offset of a replica, or of the stream of a leader
+	offset int64
+	// priority of a replica, the lowest nonzero priority being promoted first and 0 never
+	priority int
+	// replicas are the addresses of the replicas of a leader
+	replicas []string
+}
+
+func newNode(addr string) *node {
+	return &node{addr: addr, lastOK: time.Now()}
+}
+
+// down reports whether the node did not reply to PING for longer than downAfter
+func (n *node) down(downAfter time.Duration) bool {
+	return time.Since(n.lastOK) > downAfter
+}
+
+// update keeps the replication info reported, unless it is the info of a node not yet replicating
+func (n *node) update(info replicationInfo) {
+	if info.role != "" {
+		n.info = info
+	}
+}
+
+// command sends the command and returns its reply, the connection being dialed and authenticated first if needed
+func (n *node) command(password string, timeout time.Duration, args ...string) (string, error) {
+	if n.conn == nil {
+		conn, err := net.DialTimeout("tcp", n.addr, timeout)
+		if err != nil {
+			return "", err
+		}
+		n.conn, n.r = conn, bufio.NewReader(conn)
+		if password != "" {
+			if _, err := n.command(password, timeout, "AUTH", password); err != nil {
+				n.close()
+				return "", fmt.Errorf("could not authenticate: %w", err)
+			}
+		}
+	}
+
+	reply, err := n.roundTrip(timeout, args)
+	if err != nil {
+		n.close()
+		return "", err
+	}
+	return reply, nil
+}
+
+func (n *node) roundTrip(timeout time.Duration, args []string) (string, error) {
+	if err := n.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(n.conn, sb.String()); err != nil {
+		return "", err
+	}
+
+	line, err := n.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("empty reply to %s", args[0])
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("%s replied %s", args[0], line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid reply header %q", line)
+		}
+		if size < 0 {
+			return "", nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(n.r, data); err != nil {
+			return "", err
+		}
+		return string(data[:size]), nil
+	default:
+		return "", fmt.Errorf("unexpected reply to %s: %q", args[0], line)
+	}
+}
+
+func (n *node) close() {
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn, n.r = nil, nil
+	}
+}
+
+// parseInfo parses the fields of INFO replication that matter to the sentinel
+func parseInfo(info string) replicationInfo {
+	r := replicationInfo{priority: defaultPriority}
+	var host, port string
+	for _, line := range strings.Split(info, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "role":
+			r.role = value
+		case "master_host":
+			host = value
+		case "master_port":
+			port = value
+		case "master_link_status":
+			r.linkUp = value == "up"
+		case "slave_repl_offset":
+			r.offset, _ = strconv.ParseInt(value, 10, 64)
+		case "master_repl_offset":
+			if r.offset == 0 {
+				r.offset, _ = strconv.ParseInt(value, 10, 64)
+			}
+		case "slave_priority", "replica_priority":
+			if priority, err := strconv.Atoi(value); err == nil {
+				r.priority = priority
+			}
+		default:
+			// A replica of a leader, e.g. slave0:ip=127.0.0.1,port=6380,state=online,offset=42,lag=0
+			if !strings.HasPrefix(key, "slave") {
+				continue
+			}
+			if _, err := strconv.Atoi(strings.TrimPrefix(key, "slave")); err != nil {
+				continue
+			}
+			var ip, replicaPort string
+			for _, field := range strings.Split(value, ",") {
+				k, v, _ := strings.Cut(field, "=")
+				switch k {
+				case "ip":
+					ip = v
+				case "port":
+					replicaPort = v
+				}
+			}
+			if ip != "" && replicaPort != "" {
+				r.replicas = append(r.replicas, net.JoinHostPort(ip, replicaPort))
+			}
+		}
+	}
+	if host != "" && port != "" {
+		r.leader = net.JoinHostPort(host, port)
+	}
+	return r
+}
@@ -0,0 +1,329 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package sentinel monitors a leader and its replicas, fails over to a replica when the leader is down, and
+// tells the clients the address of the current leader, for high availability without a cluster.
+//
+// The sentinel starts from the address of the leader, its replicas are discovered from INFO replication. Every
+// node is sent PING at every check, a node not replying for the down-after time is down. Once the leader is
+// down, the replica up with the lowest priority, then the largest replication offset, is promoted with
+// REPLICAOF NO ONE, and the other replicas are pointed at it. The former leader is pointed at the new one once
+// it is back. The nodes are expected to be Redis-compatible servers that follow a leader with REPLICAOF.
+//
+// A sentinel decides alone: there is no quorum and no election between several sentinels, so a sentinel cut
+// off from a leader that is up fails it over. The clients ask for the leader with SENTINEL
+// GET-MASTER-ADDR-BY-NAME, like from a Redis Sentinel, and poll it again when their connection fails.
+package sentinel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// errNoGoodReplica is returned when no replica can be promoted
+var errNoGoodReplica = errors.New("NOGOODSLAVE No suitable replica to promote")
+
+// Options describes the leader monitored.
+type Options struct {
+	// Addr is the address the clients ask for the leader on
+	Addr string
+	// Name is the name under which the clients ask for the leader
+	Name string
+	// Leader is the address of the leader when the sentinel starts
+	Leader   string
+	Password string
+	// DownAfter is the time after which a node not replying to PING is down
+	DownAfter time.Duration
+	// CheckInterval is the interval at which the nodes are checked
+	CheckInterval time.Duration
+}
+
+// Sentinel monitors a leader and its replicas.
+type Sentinel struct {
+	opts Options
+
+	// mu guards the nodes and the epoch, which are read by the clients while the monitor updates them
+	mu       sync.Mutex
+	leader   *node
+	replicas map[string]*node
+	// epoch is incremented by every failover
+	epoch int64
+
+	// failovers are the failovers asked by the clients, carried out by the monitor that owns the connections
+	failovers chan chan error
+	listener  net.Listener
+	listening chan struct{}
+}
+
+// New returns a sentinel monitoring the leader of the options.
+func New(opts Options) *Sentinel {
+	return &Sentinel{
+		opts:      opts,
+		leader:    newNode(opts.Leader),
+		replicas:  make(map[string]*node),
+		failovers: make(chan chan error),
+		listening: make(chan struct{}),
+	}
+}
+
+// Run monitors the nodes and serves the clients until the context is canceled.
+func (s *Sentinel) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.opts.Addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	close(s.listening)
+	slog.Info("monitoring the leader", slog.String("name", s.opts.Name), slog.String("leader", s.opts.Leader),
+		slog.String("addr", listener.Addr().String()))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.serve(ctx)
+	}()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	s.monitor(ctx)
+	wg.Wait()
+	s.closeNodes()
+	return ctx.Err()
+}
+
+// Addr returns the address the clients are served on, once the sentinel is listening.
+func (s *Sentinel) Addr() net.Addr {
+	<-s.listening
+	return s.listener.Addr()
+}
+
+// Leader returns the address of the current leader.
+func (s *Sentinel) Leader() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader.addr
+}
+
+// monitor checks the nodes at every interval, and carries out the failovers asked by the clients in between
+func (s *Sentinel) monitor(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.CheckInterval)
+	defer ticker.Stop()
+
+	s.check()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case done := <-s.failovers:
+			err := s.failover()
+			if err == nil {
+				s.reconfigure()
+			}
+			done <- err
+		case <-ticker.C:
+			s.check()
+		}
+	}
+}
+
+// check probes every node, then fails the leader over once it is down and points the replicas at it
+func (s *Sentinel) check() {
+	var wg sync.WaitGroup
+	for _, n := range s.nodes() {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			s.probe(n)
+		}(n)
+	}
+	wg.Wait()
+
+	s.discover()
+
+	s.mu.Lock()
+	down := s.leader.down(s.opts.DownAfter)
+	s.mu.Unlock()
+	if down {
+		slog.Warn("the leader is down", slog.String("name", s.opts.Name), slog.String("leader", s.Leader()))
+		if err := s.failover(); err != nil {
+			slog.Error("could not fail the leader over", slog.String("name", s.opts.Name), slog.Any("error", err))
+		}
+	}
+
+	s.reconfigure()
+}
+
+// probe sends PING and INFO replication to the node, the state of the node is updated with their replies
+func (s *Sentinel) probe(n *node) {
+	timeout := s.timeout()
+	pong, err := n.command(s.opts.Password, timeout, "PING")
+	if err != nil {
+		slog.Debug("could not ping a node", slog.String("addr", n.addr), slog.Any("error", err))
+		return
+	}
+	info, err := n.command(s.opts.Password, timeout, "INFO", "replication")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pong == "PONG" {
+		n.lastOK = time.Now()
+	}
+	if err == nil {
+		n.update(parseInfo(info))
+	}
+}
+
+// discover adds the replicas listed by the leader
+func (s *Sentinel) discover() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, addr := range s.leader.info.replicas {
+		if _, ok := s.replicas[addr]; ok || addr == s.leader.addr {
+			continue
+		}
+		slog.Info("discovered a replica", slog.String("name", s.opts.Name), slog.String("addr", addr))
+		s.replicas[addr] = newNode(addr)
+	}
+}
+
+// failover promotes the best replica up, the others being pointed at it by the next reconfiguration
+func (s *Sentinel) failover() error {
+	for _, candidate := range s.candidates() {
+		if _, err := candidate.command(s.opts.Password, s.timeout(), "REPLICAOF", "NO", "ONE"); err != nil {
+			slog.Warn("could not promote a replica", slog.String("addr", candidate.addr), slog.Any("error", err))
+			continue
+		}
+
+		s.mu.Lock()
+		former := s.leader
+		delete(s.replicas, candidate.addr)
+		s.replicas[former.addr] = former
+		s.leader = candidate
+		candidate.info.role = roleLeader
+		s.epoch++
+		for _, n := range s.replicas {
+			n.stale = true
+		}
+		epoch := s.epoch
+		s.mu.Unlock()
+
+		slog.Info("failed the leader over", slog.String("name", s.opts.Name), slog.String("from", former.addr),
+			slog.String("to", candidate.addr), slog.Int64("epoch", epoch))
+		return nil
+	}
+	return errNoGoodReplica
+}
+
+// candidates returns the replicas that can be promoted, the best first: the replicas up and with a nonzero
+// priority, by ascending priority, then descending replication offset, then address
+func (s *Sentinel) candidates() []*node {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var candidates []*node
+	for _, n := range s.replicas {
+		if n.down(s.opts.DownAfter) || n.info.role != roleReplica || n.info.priority == 0 {
+			continue
+		}
+		candidates = append(candidates, n)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].info, candidates[j].info
+		if a.priority != b.priority {
+			return a.priority < b.priority
+		}
+		if a.offset != b.offset {
+			return a.offset > b.offset
+		}
+		return candidates[i].addr < candidates[j].addr
+	})
+	return candidates
+}
+
+// reconfigure points at the leader the replicas up that follow a former leader or are leaders themselves
+func (s *Sentinel) reconfigure() {
+	s.mu.Lock()
+	host, port, err := net.SplitHostPort(s.leader.addr)
+	if err != nil {
+		s.mu.Unlock()
+		slog.Error("invalid address of the leader", slog.String("addr", s.leader.addr), slog.Any("error", err))
+		return
+	}
+	var stale []*node
+	for _, n := range s.replicas {
+		if !n.down(s.opts.DownAfter) && (n.stale || n.info.role == roleLeader) {
+			stale = append(stale, n)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, n := range stale {
+		if _, err := n.command(s.opts.Password, s.timeout(), "REPLICAOF", host, port); err != nil {
+			slog.Warn("could not point a replica at the leader", slog.String("addr", n.addr), slog.Any("error", err))
+			continue
+		}
+		slog.Info("pointed a replica at the leader", slog.String("addr", n.addr), slog.String("leader", net.JoinHostPort(host, port)))
+		s.mu.Lock()
+		n.stale = false
+		n.info.role = roleReplica
+		s.mu.Unlock()
+	}
+}
+
+// nodes returns the leader and its replicas
+func (s *Sentinel) nodes() []*node {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	nodes := []*node{s.leader}
+	for _, n := range s.replicas {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+func (s *Sentinel) closeNodes() {
+	for _, n := range s.nodes() {
+		n.close()
+	}
+}
+
+// timeout is the time a node is given to reply to a command, a node slower than a check being down
+func (s *Sentinel) timeout() time.Duration {
+	return min(s.opts.CheckInterval, s.opts.DownAfter)
+}
+
+// askFailover asks the monitor to fail the leader over
+func (s *Sentinel) askFailover(ctx context.Context) error {
+	done := make(chan error, 1)
+	select {
+	case s.failovers <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
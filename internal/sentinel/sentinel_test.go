@@ -0,0 +1,289 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package sentinel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// The fake nodes parse the commands with the buffer size of the config
+	if err := config.NewConfigParser().ParseDefaults(config.DiceConfig()); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// topology is a set of fake nodes, a leader listing the nodes up that follow it as its replicas
+type topology struct {
+	mu    sync.Mutex
+	nodes map[string]*fakeNode
+}
+
+// fakeNode replies to the commands sent by the sentinel like a Redis
+type fakeNode struct {
+	topo     *topology
+	addr     string
+	listener net.Listener
+	conns    []net.Conn
+
+	// Guarded by the mutex of the topology
+	leader   string
+	offset   int64
+	priority int
+	up       bool
+	// promotions is the number of REPLICAOF NO ONE received
+	promotions int
+}
+
+func (topo *topology) start(t *testing.T, leader string, offset int64, priority int) *fakeNode {
+	t.Helper()
+	n := &fakeNode{topo: topo, leader: leader, offset: offset, priority: priority}
+	n.listen(t, "127.0.0.1:0")
+	topo.mu.Lock()
+	topo.nodes[n.addr] = n
+	topo.mu.Unlock()
+	t.Cleanup(n.stop)
+	return n
+}
+
+func (n *fakeNode) listen(t *testing.T, addr string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	n.topo.mu.Lock()
+	n.listener, n.addr, n.up = listener, listener.Addr().String(), true
+	n.topo.mu.Unlock()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			n.topo.mu.Lock()
+			n.conns = append(n.conns, conn)
+			n.topo.mu.Unlock()
+			go n.serve(conn)
+		}
+	}()
+}
+
+func (n *fakeNode) stop() {
+	n.topo.mu.Lock()
+	defer n.topo.mu.Unlock()
+	if !n.up {
+		return
+	}
+	n.up = false
+	n.listener.Close()
+	for _, conn := range n.conns {
+		conn.Close()
+	}
+	n.conns = nil
+}
+
+func (n *fakeNode) serve(conn net.Conn) {
+	parser := clientio.NewRESPParser(conn)
+	for {
+		value, err := parser.DecodeOne()
+		if err != nil {
+			return
+		}
+		args, _ := toArgs(value)
+		if _, err := conn.Write([]byte(n.reply(args))); err != nil {
+			return
+		}
+	}
+}
+
+func (n *fakeNode) reply(args []string) string {
+	n.topo.mu.Lock()
+	defer n.topo.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "INFO":
+		info := n.info()
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(info), info)
+	case "REPLICAOF":
+		if strings.EqualFold(args[1], "NO") {
+			n.leader = ""
+			n.promotions++
+		} else {
+			n.leader = net.JoinHostPort(args[1], args[2])
+		}
+		return "+OK\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func (n *fakeNode) info() string {
+	var sb strings.Builder
+	if n.leader == "" {
+		sb.WriteString("# Replication\r\nrole:master\r\n")
+		i := 0
+		for _, r := range n.topo.nodes {
+			if r.up && r.leader == n.addr {
+				host, port, _ := net.SplitHostPort(r.addr)
+				fmt.Fprintf(&sb, "slave%d:ip=%s,port=%s,state=online,offset=%d,lag=0\r\n", i, host, port, r.offset)
+				i++
+			}
+		}
+		fmt.Fprintf(&sb, "master_repl_offset:%d\r\n", n.offset)
+		return sb.String()
+	}
+	host, port, _ := net.SplitHostPort(n.leader)
+	fmt.Fprintf(&sb, "# Replication\r\nrole:slave\r\nmaster_host:%s\r\nmaster_port:%s\r\nmaster_link_status:up\r\n", host, port)
+	fmt.Fprintf(&sb, "slave_repl_offset:%d\r\nslave_priority:%d\r\nmaster_repl_offset:%d\r\n", n.offset, n.priority, n.offset)
+	return sb.String()
+}
+
+// state returns the leader followed by the node and the number of times it was promoted
+func (n *fakeNode) state() (leader string, promotions int) {
+	n.topo.mu.Lock()
+	defer n.topo.mu.Unlock()
+	return n.leader, n.promotions
+}
+
+// query sends a command to the sentinel and returns its reply
+func query(t *testing.T, conn net.Conn, parser *clientio.RESPParser, args ...string) interface{} {
+	t.Helper()
+	_, err := conn.Write(clientio.Encode(args, false))
+	require.NoError(t, err)
+	reply, err := parser.DecodeOne()
+	require.NoError(t, err)
+	return reply
+}
+
+func startSentinel(t *testing.T, leader string) (*Sentinel, net.Conn, *clientio.RESPParser) {
+	t.Helper()
+	s := New(Options{
+		Addr:          "127.0.0.1:0",
+		Name:          "dicedb",
+		Leader:        leader,
+		DownAfter:     200 * time.Millisecond,
+		CheckInterval: 20 * time.Millisecond,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return s, conn, clientio.NewRESPParser(conn)
+}
+
+func TestFailover(t *testing.T) {
+	topo := &topology{nodes: make(map[string]*fakeNode)}
+	leader := topo.start(t, "", 300, 100)
+	behind := topo.start(t, leader.addr, 100, 100)
+	ahead := topo.start(t, leader.addr, 200, 100)
+	never := topo.start(t, leader.addr, 300, 0)
+
+	s, conn, parser := startSentinel(t, leader.addr)
+
+	host, port, _ := net.SplitHostPort(leader.addr)
+	assert.Equal(t, []interface{}{host, port}, query(t, conn, parser, "SENTINEL", "GET-MASTER-ADDR-BY-NAME", "dicedb"))
+	assert.Equal(t, "PONG", query(t, conn, parser, "PING"))
+	assert.Equal(t, "ERR No such master with that name", query(t, conn, parser, "SENTINEL", "MASTER", "other"))
+	assert.Equal(t, "(nil)", query(t, conn, parser, "SENTINEL", "GET-MASTER-ADDR-BY-NAME", "other"), "an unknown name replies nil")
+
+	require.Eventually(t, func() bool {
+		replicas, _ := query(t, conn, parser, "SENTINEL", "REPLICAS", "dicedb").([]interface{})
+		return len(replicas) == 3
+	}, 5*time.Second, 10*time.Millisecond, "the replicas are discovered from the leader")
+
+	// The replica with the largest offset is promoted, the replica with priority 0 never is
+	leader.stop()
+	require.Eventually(t, func() bool { return s.Leader() == ahead.addr }, 5*time.Second, 10*time.Millisecond)
+	host, port, _ = net.SplitHostPort(ahead.addr)
+	assert.Equal(t, []interface{}{host, port}, query(t, conn, parser, "SENTINEL", "GET-MASTER-ADDR-BY-NAME", "dicedb"))
+	_, promotions := ahead.state()
+	assert.Equal(t, 1, promotions)
+	for _, n := range []*fakeNode{behind, never} {
+		require.Eventually(t, func() bool {
+			following, _ := n.state()
+			return following == ahead.addr
+		}, 5*time.Second, 10*time.Millisecond, "the other replicas follow the new leader")
+	}
+
+	// The former leader follows the new one once it is back
+	leader.listen(t, leader.addr)
+	require.Eventually(t, func() bool {
+		following, _ := leader.state()
+		return following == ahead.addr
+	}, 5*time.Second, 10*time.Millisecond)
+
+	master := query(t, conn, parser, "SENTINEL", "MASTER", "dicedb").([]interface{})
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(master); i += 2 {
+		fields[master[i].(string)] = master[i+1]
+	}
+	assert.Equal(t, "1", fields["config-epoch"])
+	assert.Equal(t, "master", fields["flags"])
+}
+
+func TestForcedFailover(t *testing.T) {
+	topo := &topology{nodes: make(map[string]*fakeNode)}
+	leader := topo.start(t, "", 100, 100)
+	s, conn, parser := startSentinel(t, leader.addr)
+
+	assert.Equal(t, "NOGOODSLAVE No suitable replica to promote", query(t, conn, parser, "SENTINEL", "FAILOVER", "dicedb"))
+
+	replica := topo.start(t, leader.addr, 100, 100)
+	require.Eventually(t, func() bool {
+		replicas, _ := query(t, conn, parser, "SENTINEL", "REPLICAS", "dicedb").([]interface{})
+		return len(replicas) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return query(t, conn, parser, "SENTINEL", "FAILOVER", "dicedb") == "OK"
+	}, 5*time.Second, 10*time.Millisecond, "the replica is promoted once its info is known")
+
+	assert.Equal(t, replica.addr, s.Leader())
+	following, _ := leader.state()
+	assert.Equal(t, replica.addr, following, "the former leader, still up, follows the new one")
+}
+
+func TestParseInfo(t *testing.T) {
+	info := parseInfo("# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\nmaster_port:6379\r\n" +
+		"master_link_status:down\r\nslave_repl_offset:42\r\nslave_priority:10\r\nmaster_repl_offset:50\r\n")
+	assert.Equal(t, replicationInfo{role: roleReplica, leader: "10.0.0.1:6379", offset: 42, priority: 10}, info)
+
+	info = parseInfo("role:master\r\nconnected_slaves:2\r\nslave0:ip=10.0.0.2,port=6380,state=online,offset=7,lag=0\r\n" +
+		"slave1:ip=::1,port=6381,state=wait_bgsave,offset=0,lag=1\r\nslave_read_only:1\r\nmaster_repl_offset:7\r\n")
+	assert.Equal(t, replicationInfo{role: roleLeader, offset: 7, priority: defaultPriority,
+		replicas: []string{"10.0.0.2:6380", "[::1]:6381"}}, info)
+}
@@ -0,0 +1,220 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package sentinel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/internal/clientio"
+)
+
+// errNoSuchName is returned when a client asks for a leader not monitored
+var errNoSuchName = errors.New("ERR No such master with that name")
+
+var respPong = []byte("+PONG\r\n")
+
+// serve answers the clients until the listener is closed
+func (s *Sentinel) serve(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("could not accept a client", slog.Any("error", err))
+			}
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+func (s *Sentinel) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	parser := clientio.NewRESPParser(conn)
+	for {
+		value, err := parser.DecodeOne()
+		if err != nil {
+			return
+		}
+		args, ok := toArgs(value)
+		if !ok || len(args) == 0 {
+			if _, err := conn.Write(clientio.Encode(errors.New("ERR Protocol error: expected an array of strings"), false)); err != nil {
+				return
+			}
+			continue
+		}
+		if _, err := conn.Write(clientio.Encode(s.execute(ctx, args), false)); err != nil {
+			return
+		}
+	}
+}
+
+func toArgs(value interface{}) ([]string, bool) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	args := make([]string, len(values))
+	for i, v := range values {
+		if args[i], ok = v.(string); !ok {
+			return nil, false
+		}
+	}
+	return args, true
+}
+
+// execute runs a command of a client and returns its reply
+func (s *Sentinel) execute(ctx context.Context, args []string) interface{} {
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return respPong
+	case "ROLE":
+		return []interface{}{"sentinel", []string{s.opts.Name}}
+	case "SENTINEL":
+		if len(args) < 2 {
+			return wrongArity("sentinel")
+		}
+		return s.sentinel(ctx, strings.ToUpper(args[1]), args[2:])
+	default:
+		return fmt.Errorf("ERR unknown command '%s'", args[0])
+	}
+}
+
+// sentinel runs a subcommand of SENTINEL
+func (s *Sentinel) sentinel(ctx context.Context, sub string, args []string) interface{} {
+	switch sub {
+	case "MASTERS":
+		if len(args) != 0 {
+			return wrongArity("sentinel|masters")
+		}
+		return []interface{}{s.leaderInfo()}
+	case "GET-MASTER-ADDR-BY-NAME", "MASTER", "REPLICAS", "SLAVES", "FAILOVER":
+		if len(args) != 1 {
+			return wrongArity("sentinel|" + strings.ToLower(sub))
+		}
+	default:
+		return fmt.Errorf("ERR unknown subcommand '%s'", sub)
+	}
+
+	if args[0] != s.opts.Name {
+		if sub == "GET-MASTER-ADDR-BY-NAME" {
+			return clientio.RespType(clientio.NIL)
+		}
+		return errNoSuchName
+	}
+	switch sub {
+	case "GET-MASTER-ADDR-BY-NAME":
+		host, port, err := net.SplitHostPort(s.Leader())
+		if err != nil {
+			return fmt.Errorf("ERR %v", err)
+		}
+		return []string{host, port}
+	case "MASTER":
+		return s.leaderInfo()
+	case "REPLICAS", "SLAVES":
+		return s.replicasInfo()
+	default:
+		if err := s.askFailover(ctx); err != nil {
+			if errors.Is(err, errNoGoodReplica) {
+				return err
+			}
+			return fmt.Errorf("ERR %v", err)
+		}
+		return clientio.RespType(clientio.OK)
+	}
+}
+
+// leaderInfo describes the leader, with the fields of a Redis Sentinel
+func (s *Sentinel) leaderInfo() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	host, port, _ := net.SplitHostPort(s.leader.addr)
+	flags := roleLeader
+	if s.leader.down(s.opts.DownAfter) {
+		flags += ",s_down"
+	}
+	return []interface{}{
+		"name", s.opts.Name,
+		"ip", host,
+		"port", port,
+		"flags", flags,
+		"last-ok-ping-reply", strconv.FormatInt(time.Since(s.leader.lastOK).Milliseconds(), 10),
+		"down-after-milliseconds", strconv.FormatInt(s.opts.DownAfter.Milliseconds(), 10),
+		"num-slaves", strconv.Itoa(len(s.replicas)),
+		"config-epoch", strconv.FormatInt(s.epoch, 10),
+	}
+}
+
+// replicasInfo describes the replicas by address, with the fields of a Redis Sentinel
+func (s *Sentinel) replicasInfo() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	addrs := make([]string, 0, len(s.replicas))
+	for addr := range s.replicas {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	replicas := make([]interface{}, 0, len(addrs))
+	for _, addr := range addrs {
+		n := s.replicas[addr]
+		host, port, _ := net.SplitHostPort(addr)
+		flags := roleReplica
+		if n.down(s.opts.DownAfter) {
+			flags += ",s_down"
+		}
+		link := "err"
+		if n.info.linkUp {
+			link = "ok"
+		}
+		leaderHost, leaderPort, _ := net.SplitHostPort(n.info.leader)
+		replicas = append(replicas, []interface{}{
+			"name", addr,
+			"ip", host,
+			"port", port,
+			"flags", flags,
+			"last-ok-ping-reply", strconv.FormatInt(time.Since(n.lastOK).Milliseconds(), 10),
+			"master-link-status", link,
+			"master-host", leaderHost,
+			"master-port", leaderPort,
+			"slave-priority", strconv.Itoa(n.info.priority),
+			"slave-repl-offset", strconv.FormatInt(n.info.offset, 10),
+		})
+	}
+	return replicas
+}
+
+func wrongArity(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", name)
+}
@@ -21,3 +21,11 @@ import "context"
 type AbstractServer interface {
 	Run(ctx context.Context) error
 }
+
+// Listening is implemented by the servers accepting connections, so that the server is only reported ready to
+// the service manager once they accept them.
+type Listening interface {
+	// Listening is closed once the server accepts connections on every one of its listeners, and never if it
+	// fails to listen
+	Listening() <-chan struct{}
+}
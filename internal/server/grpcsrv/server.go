@@ -0,0 +1,357 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package grpcsrv serves the Dice gRPC service of dicepb: commands are executed by ExecuteCommand and watched
+// by the WatchQuery stream, so that services written in any language use generated clients, with deadlines
+// and streaming, rather than RESP or WebSocket clients. Every call is served by an in-process session, the
+// equivalent of a RESP connection.
+package grpcsrv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/dicepb"
+	"github.com/dicedb/dice/internal/auth"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/handover"
+	"github.com/dicedb/dice/internal/inproc"
+	"github.com/dicedb/dice/internal/iothread"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dice/internal/watchmanager"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys of the credentials of the calls
+const (
+	usernameKey = "username"
+	passwordKey = "password"
+)
+
+// statefulCommands change the state of the connection, which the calls of ExecuteCommand do not share
+var statefulCommands = map[string]bool{
+	iothread.CmdMulti:     true,
+	iothread.CmdExec:      true,
+	iothread.CmdDiscard:   true,
+	iothread.CmdAuth:      true,
+	iothread.CmdHello:     true,
+	iothread.CmdReset:     true,
+	iothread.CmdQuit:      true,
+	iothread.CmdReadAfter: true,
+	iothread.CmdTrace:     true,
+}
+
+var errShuttingDown = status.Error(codes.Unavailable, "server shutting down")
+
+type Server struct {
+	dicepb.UnimplementedDiceServer
+	addr                     string
+	shardManager             *shard.ShardManager
+	ioThreadManager          *iothread.Manager
+	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription
+	globalErrorChan          chan error
+	wl                       wal.AbstractWAL
+
+	host     *inproc.Host
+	stopping chan struct{} // stopping is closed once the server shuts down, ending the watch streams
+	stopOnce sync.Once
+
+	listening chan struct{}
+}
+
+func NewServer(shardManager *shard.ShardManager, ioThreadManager *iothread.Manager,
+	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription, globalErrChan chan error, wl wal.AbstractWAL) *Server {
+	return &Server{
+		addr:                     net.JoinHostPort(config.DiceConfig().GRPC.Addr, strconv.Itoa(config.DiceConfig().GRPC.Port)),
+		shardManager:             shardManager,
+		ioThreadManager:          ioThreadManager,
+		cmdWatchSubscriptionChan: cmdWatchSubscriptionChan,
+		globalErrorChan:          globalErrChan,
+		wl:                       wl,
+		stopping:                 make(chan struct{}),
+		listening:                make(chan struct{}),
+	}
+}
+
+// Run serves the gRPC service until the context is canceled. The watch streams are ended first, then the
+// calls in progress are waited for.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := handover.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	close(s.listening)
+	return s.serve(ctx, ln)
+}
+
+// Listening is closed once the server accepts connections.
+func (s *Server) Listening() <-chan struct{} {
+	return s.listening
+}
+
+func (s *Server) serve(ctx context.Context, ln net.Listener) error {
+	hostCtx, cancelHost := context.WithCancel(context.Background())
+	defer cancelHost()
+	s.host = inproc.NewHost(hostCtx, "grpc", "grpc", s.shardManager, s.ioThreadManager, s.cmdWatchSubscriptionChan, s.globalErrorChan, s.wl)
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(protectedModeUnaryInterceptor),
+		grpc.ChainStreamInterceptor(protectedModeStreamInterceptor),
+	)
+	dicepb.RegisterDiceServer(srv, s)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+	slog.Info("gRPC server listening", slog.String("addr", ln.Addr().String()))
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		s.stop()
+		cancelHost()
+		s.host.Close()
+		return err
+	}
+
+	s.stop()
+	srv.GracefulStop()
+	cancelHost()
+	s.host.Close()
+	return ctx.Err()
+}
+
+func (s *Server) stop() {
+	s.stopOnce.Do(func() { close(s.stopping) })
+}
+
+// ExecuteCommand executes the command on a session of the pool of the credentials of the call.
+func (s *Server) ExecuteCommand(ctx context.Context, req *dicepb.CommandRequest) (*dicepb.CommandResponse, error) {
+	command := strings.ToUpper(req.GetCommand())
+	if err := checkCommand(command, req.GetArgs()); err != nil {
+		return nil, err
+	}
+
+	pool, username, password := credentials(ctx)
+	session, fresh, err := s.host.Acquire(pool)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if fresh {
+		if err := authenticate(ctx, session, username, password); err != nil {
+			_ = session.Close()
+			return nil, err
+		}
+	}
+
+	reply, err := session.Execute(ctx, command, req.GetArgs()...)
+	s.host.Release(pool, session)
+
+	var cmdErr inproc.Error
+	if errors.As(err, &cmdErr) {
+		return &dicepb.CommandResponse{Error: string(cmdErr)}, nil
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &dicepb.CommandResponse{Result: toValue(reply)}, nil
+}
+
+// WatchQuery subscribes to the command on a session of its own and sends its updates until the client
+// cancels the call or the server shuts down.
+func (s *Server) WatchQuery(req *dicepb.CommandRequest, stream grpc.ServerStreamingServer[dicepb.WatchUpdate]) error {
+	ctx := stream.Context()
+	command := strings.TrimSuffix(strings.ToUpper(req.GetCommand()), ".WATCH")
+	if command == "" {
+		return status.Error(codes.InvalidArgument, "command is required")
+	}
+
+	session, err := s.host.NewSession()
+	if err != nil {
+		return toStatus(err)
+	}
+	_, username, password := credentials(ctx)
+	if err := authenticate(ctx, session, username, password); err != nil {
+		_ = session.Close()
+		return err
+	}
+
+	sub, err := session.Watch(ctx, command, req.GetArgs()...)
+	var cmdErr inproc.Error
+	switch {
+	case errors.As(err, &cmdErr):
+		return status.Error(codes.InvalidArgument, string(cmdErr))
+	case errors.Is(err, inproc.ErrWatchDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return toStatus(err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = sub.Close()
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.stopping:
+			// The watch manager may be stopped already, the session is closed without unsubscribing
+			_ = session.Close()
+			return errShuttingDown
+		case u, ok := <-sub.C:
+			if !ok {
+				_ = session.Close()
+				return errShuttingDown
+			}
+			update := &dicepb.WatchUpdate{Seq: u.Seq}
+			if u.Err != nil {
+				update.Error = u.Err.Error()
+			} else {
+				update.Result = toValue(u.Result)
+			}
+			if err := stream.Send(update); err != nil {
+				_ = sub.Close()
+				return err
+			}
+		}
+	}
+}
+
+// checkCommand refuses the commands that ExecuteCommand can not serve.
+func checkCommand(command string, args []string) error {
+	switch {
+	case command == "":
+		return status.Error(codes.InvalidArgument, "command is required")
+	case strings.HasSuffix(command, ".WATCH") || strings.HasSuffix(command, ".UNWATCH"):
+		return status.Error(codes.InvalidArgument, "watch commands are served by WatchQuery")
+	case statefulCommands[command] || (command == iothread.CmdClient && len(args) > 0 && strings.EqualFold(args[0], "SETNAME")):
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("%s is not supported over gRPC, the calls do not share a connection", command))
+	}
+	return nil
+}
+
+// credentials returns the credentials of the call, and the pool of the sessions authenticated with them.
+// The sessions are pooled by credentials so that a session is only ever used with the ones it was
+// authenticated with, and the password is only checked once per session.
+func credentials(ctx context.Context) (pool, username, password string) {
+	if config.DiceConfig().Auth.Password == "" {
+		return "", "", ""
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(usernameKey); len(values) > 0 {
+		username = values[0]
+	} else {
+		username = config.DiceConfig().Auth.UserName
+	}
+	if values := md.Get(passwordKey); len(values) > 0 {
+		password = values[0]
+	}
+
+	digest := sha256.Sum256([]byte(username + "\x00" + password))
+	return hex.EncodeToString(digest[:]), username, password
+}
+
+// authenticate authenticates a new session with the credentials, when a password is set.
+func authenticate(ctx context.Context, session *inproc.Session, username, password string) error {
+	if config.DiceConfig().Auth.Password == "" {
+		return nil
+	}
+
+	_, err := session.Execute(ctx, iothread.CmdAuth, username, password)
+	var cmdErr inproc.Error
+	if errors.As(err, &cmdErr) {
+		return status.Error(codes.Unauthenticated, string(cmdErr))
+	}
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// toStatus converts an error of the host to a gRPC status.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, inproc.ErrClosed):
+		return errShuttingDown
+	case errors.Is(err, iothread.ErrMaxClientsReached):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// toValue converts a reply of a session to a value.
+func toValue(reply interface{}) *dicepb.Value {
+	switch v := reply.(type) {
+	case nil:
+		return &dicepb.Value{Kind: &dicepb.Value_NullValue{}}
+	case string:
+		return &dicepb.Value{Kind: &dicepb.Value_StringValue{StringValue: v}}
+	case int64:
+		return &dicepb.Value{Kind: &dicepb.Value_IntValue{IntValue: v}}
+	case inproc.Error:
+		return &dicepb.Value{Kind: &dicepb.Value_ErrorValue{ErrorValue: string(v)}}
+	case []interface{}:
+		values := make([]*dicepb.Value, 0, len(v))
+		for _, elem := range v {
+			values = append(values, toValue(elem))
+		}
+		return &dicepb.Value{Kind: &dicepb.Value_ListValue{ListValue: &dicepb.ValueList{Values: values}}}
+	default:
+		return &dicepb.Value{Kind: &dicepb.Value_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
+
+// protectedModeUnaryInterceptor refuses the calls of the clients that protected mode does not allow.
+func protectedModeUnaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := allowPeer(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// protectedModeStreamInterceptor refuses the streams of the clients that protected mode does not allow.
+func protectedModeStreamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := allowPeer(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func allowPeer(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok || !auth.AllowConnection(p.Addr.String(), false) {
+		slog.Warn("refusing gRPC call in protected mode", slog.Any("peer", p))
+		return status.Error(codes.PermissionDenied, diceerrors.ErrProtectedMode.Error())
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package grpcsrv
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/dicepb"
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/iothread"
+	"github.com/dicedb/dice/internal/shard"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dice/internal/watchmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startServer serves the gRPC service on a loopback port and returns a client of it.
+func startServer(t *testing.T) dicepb.DiceClient {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().Performance.EnableWatch = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	errChan := make(chan error, 1)
+
+	cmdWatchChan := make(chan dstore.CmdWatchEvent, config.DiceConfig().Performance.WatchChanBufSize)
+	cmdWatchSubscriptionChan := make(chan watchmanager.WatchSubscription)
+	shardManager := shard.NewShardManager(1, cmdWatchChan, errChan)
+	watchManager := watchmanager.NewManager(cmdWatchSubscriptionChan, cmdWatchChan)
+	wl, err := wal.NewNullWAL()
+	require.NoError(t, err)
+
+	srv := NewServer(shardManager, iothread.NewManager(config.DiceConfig().Performance.MaxClients, shardManager),
+		cmdWatchSubscriptionChan, errChan, wl)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		shardManager.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		watchManager.Run(ctx)
+	}()
+	serverCtx, stopServer := context.WithCancel(ctx)
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		_ = srv.serve(serverCtx, ln)
+	}()
+
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		stopServer()
+		<-served
+		conn.Close()
+		cancel()
+		wg.Wait()
+	})
+	return dicepb.NewDiceClient(conn)
+}
+
+func stringValue(s string) *dicepb.Value {
+	return &dicepb.Value{Kind: &dicepb.Value_StringValue{StringValue: s}}
+}
+
+func TestExecuteCommand(t *testing.T) {
+	client := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		command string
+		args    []string
+		want    *dicepb.CommandResponse
+	}{
+		{command: "get", args: []string{"k"}, want: &dicepb.CommandResponse{Result: &dicepb.Value{Kind: &dicepb.Value_NullValue{}}}},
+		{command: "SET", args: []string{"k", "v"}, want: &dicepb.CommandResponse{Result: stringValue("OK")}},
+		{command: "RPUSH", args: []string{"l", "a", "b"}, want: &dicepb.CommandResponse{Result: &dicepb.Value{Kind: &dicepb.Value_IntValue{IntValue: 2}}}},
+		{command: "LRANGE", args: []string{"l", "0", "-1"}, want: &dicepb.CommandResponse{Result: &dicepb.Value{
+			Kind: &dicepb.Value_ListValue{ListValue: &dicepb.ValueList{Values: []*dicepb.Value{stringValue("a"), stringValue("b")}}}}}},
+		{command: "INCR", args: []string{"k"}, want: &dicepb.CommandResponse{Error: "ERR value is not an integer or out of range"}},
+	}
+	for _, tt := range tests {
+		got, err := client.ExecuteCommand(ctx, &dicepb.CommandRequest{Command: tt.command, Args: tt.args})
+		require.NoError(t, err, tt.command)
+		assert.Equal(t, tt.want.GetError(), got.GetError(), tt.command)
+		assert.Equal(t, tt.want.GetResult().String(), got.GetResult().String(), tt.command)
+	}
+
+	for _, req := range []*dicepb.CommandRequest{
+		{Command: "MULTI"},
+		{Command: "GET.WATCH", Args: []string{"k"}},
+		{Command: "client", Args: []string{"setname", "svc"}},
+		{},
+	} {
+		_, err := client.ExecuteCommand(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), req.Command)
+	}
+}
+
+func TestWatchQuery(t *testing.T) {
+	client := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchQuery(ctx, &dicepb.CommandRequest{Command: "GET", Args: []string{"k"}})
+	require.NoError(t, err)
+
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), update.GetSeq())
+	assert.NotNil(t, update.GetResult().GetNullValue())
+
+	_, err = client.ExecuteCommand(ctx, &dicepb.CommandRequest{Command: "SET", Args: []string{"k", "v1"}})
+	require.NoError(t, err)
+
+	update, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), update.GetSeq())
+	assert.Equal(t, "v1", update.GetResult().GetStringValue())
+
+	stream, err = client.WatchQuery(ctx, &dicepb.CommandRequest{Command: "SET", Args: []string{"k", "v"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAuthentication(t *testing.T) {
+	client := startServer(t)
+	config.DiceConfig().Auth.Password = "secret"
+	t.Cleanup(func() { config.DiceConfig().Auth.Password = "" })
+	user, _ := auth.UserStore.Add(config.DiceConfig().Auth.UserName)
+	require.NoError(t, user.SetPassword("secret"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := &dicepb.CommandRequest{Command: "PING"}
+
+	_, err := client.ExecuteCommand(ctx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	badCtx := metadata.AppendToOutgoingContext(ctx, passwordKey, "wrong")
+	_, err = client.ExecuteCommand(badCtx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, passwordKey, "secret")
+	for i := 0; i < 2; i++ {
+		resp, err := client.ExecuteCommand(authCtx, req)
+		require.NoError(t, err)
+		assert.Equal(t, "PONG", resp.GetResult().GetStringValue())
+	}
+
+	stream, err := client.WatchQuery(ctx, &dicepb.CommandRequest{Command: "GET", Args: []string{"k"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
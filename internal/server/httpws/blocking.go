@@ -0,0 +1,86 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dicedb/dice/internal/blocking"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/tracing"
+)
+
+// handleBlockingCommand serves a blocking command, e.g. BLPOP, blocking the request until one of its keys can
+// be popped from or its timeout elapses. The client stops waiting as soon as the request is canceled, e.g.
+// when the client disconnects. Every attempt runs the non-blocking pop command, e.g. LPOP, on the shard.
+func (s *HTTPServer) handleBlockingCommand(ctx context.Context, writer http.ResponseWriter, request *http.Request,
+	diceDBCmd *cmd.DiceDBCmd, receivedAt time.Time) {
+	resp := &ops.StoreResponse{EvalResponse: &eval.EvalResponse{}}
+
+	keys, timeout, err := blocking.ParseArgs(diceDBCmd.Cmd, diceDBCmd.Args)
+	if err != nil {
+		resp.EvalResponse.Error = err
+		logCommand(request.RemoteAddr, diceDBCmd, receivedAt, resp)
+		s.writeResponse(writer, resp)
+		return
+	}
+
+	pop := blocking.Commands[diceDBCmd.Cmd].Pop
+	err = s.shardManager.Blocking().Block(ctx, keys, timeout, func(key string) (bool, error) {
+		s.shardManager.GetShard(0).Send(&ops.StoreOp{
+			Cmd:         &cmd.DiceDBCmd{Cmd: pop, Args: []string{key}},
+			IOThreadID:  "httpServer",
+			ShardID:     0,
+			ClientAddr:  request.RemoteAddr,
+			HTTPOp:      true,
+			SpanContext: tracing.SpanContext(ctx),
+			Ctx:         ctx,
+		})
+		popped := <-s.ioChan
+		if popped.EvalResponse.Error != nil {
+			return false, popped.EvalResponse.Error
+		}
+
+		reply, served := blocking.Reply(key, popped.EvalResponse.Result)
+		if served {
+			resp.EvalResponse.Result = reply
+		}
+		return served, nil
+	})
+
+	switch {
+	case err == nil:
+	case errors.Is(err, blocking.ErrTimeout):
+		resp.EvalResponse.Result = clientio.NIL
+	case ctx.Err() != nil:
+		// The client went away, nobody is left to reply to
+		return
+	default:
+		resp.EvalResponse.Error = err
+	}
+
+	logCommand(request.RemoteAddr, diceDBCmd, receivedAt, resp)
+	_, replySpan := tracing.Start(ctx, tracing.SpanReply)
+	s.writeResponse(writer, resp)
+	replySpan.End()
+}
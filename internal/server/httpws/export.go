@@ -0,0 +1,103 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/export"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/tracing"
+)
+
+// handleExport serves EXPORT, e.g. `GET /export?match=user:*&type=hash&format=csv`. The options are taken
+// from the query parameters as well as from the JSON body. The export is streamed as JSON Lines or CSV,
+// flushed batch after batch, and stops as soon as the request is canceled, e.g. when the client disconnects.
+func (s *HTTPServer) handleExport(ctx context.Context, writer http.ResponseWriter, request *http.Request,
+	diceDBCmd *cmd.DiceDBCmd, receivedAt time.Time) {
+	for _, option := range []string{"match", "type", "format"} {
+		if value := request.URL.Query().Get(option); value != "" {
+			diceDBCmd.Args = append(diceDBCmd.Args, option, value)
+		}
+	}
+
+	resp := &ops.StoreResponse{EvalResponse: &eval.EvalResponse{}}
+	opts, err := export.ParseArgs(diceDBCmd.Args)
+	if err != nil {
+		resp.EvalResponse.Error = err
+		logCommand(request.RemoteAddr, diceDBCmd, receivedAt, resp)
+		writeErrorResponse(writer, http.StatusBadRequest, err, "")
+		return
+	}
+
+	flusher, _ := writer.(http.Flusher)
+	var written int
+	err = export.Run(ctx, int(s.shardManager.GetShardCount()), s.executeOnShard, opts, func(lines []string) error {
+		if written == 0 {
+			writer.Header().Set("Content-Type", opts.Format.ContentType())
+			writer.WriteHeader(http.StatusOK)
+		}
+		if _, err := writer.Write([]byte(strings.Join(lines, "\n") + "\n")); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		written += len(lines)
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		if written == 0 {
+			writer.Header().Set("Content-Type", opts.Format.ContentType())
+			writer.WriteHeader(http.StatusOK)
+		}
+		resp.EvalResponse.Result = written
+	case written == 0 && ctx.Err() == nil:
+		resp.EvalResponse.Error = err
+		writeErrorResponse(writer, http.StatusInternalServerError, err, "")
+	default:
+		// The status is already sent, the export is cut short
+		resp.EvalResponse.Error = err
+		slog.Debug("Export interrupted", slog.String("client", request.RemoteAddr), slog.Any("error", err))
+	}
+	logCommand(request.RemoteAddr, diceDBCmd, receivedAt, resp)
+}
+
+// executeOnShard runs a command on the given shard and returns its result.
+func (s *HTTPServer) executeOnShard(ctx context.Context, shardID uint8, diceDBCmd *cmd.DiceDBCmd) (interface{}, error) {
+	s.shardManager.GetShard(shardID).Send(&ops.StoreOp{
+		Cmd:         diceDBCmd,
+		IOThreadID:  "httpServer",
+		ShardID:     shardID,
+		HTTPOp:      true,
+		SpanContext: tracing.SpanContext(ctx),
+		Ctx:         ctx,
+	})
+	resp := <-s.ioChan
+	if resp.EvalResponse.Error != nil {
+		return nil, resp.EvalResponse.Error
+	}
+	return resp.EvalResponse.Result, nil
+}
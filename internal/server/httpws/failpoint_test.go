@@ -0,0 +1,92 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build failpoints
+
+package httpws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/failpoint"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// echoConn returns a connection to a WebSocket server reading the messages of the client.
+func echoConn(t *testing.T) *websocket.Conn {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, nil, 1024, 1024)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestWriteResponseWithRetriesFailpoint(t *testing.T) {
+	previous := config.DiceConfig().WebSocket.WriteResponseTimeout
+	config.DiceConfig().WebSocket.WriteResponseTimeout = time.Second
+	t.Cleanup(func() {
+		config.DiceConfig().WebSocket.WriteResponseTimeout = previous
+		failpoint.Disable(failpoint.SocketWriteError)
+	})
+
+	tests := []struct {
+		name string
+		term string
+		err  string
+	}{
+		{name: "eagain retried", term: "2*return(eagain)"},
+		{name: "eagain exhausting the retries", term: "3*return(eagain)", err: "max retries reached"},
+		{name: "broken pipe", term: "return(epipe)", err: "broken pipe"},
+		{name: "connection reset", term: "return(econnreset)", err: "connection reset by peer"},
+		{name: "no buffer space", term: "return(enobufs)", err: "no buffer space available"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, failpoint.Enable(failpoint.SocketWriteError, tt.term))
+			defer failpoint.Disable(failpoint.SocketWriteError)
+
+			err := WriteResponseWithRetries(echoConn(t), []byte("hello"), 3)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -16,12 +16,74 @@
 
 package httpws
 
+import (
+	"net/http"
+
+	"github.com/dicedb/dice/internal/clientio"
+	derrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/tracing"
+)
+
 const (
 	HTTPStatusSuccess string = "success"
 	HTTPStatusError   string = "error"
+
+	// TraceWriteTrailer is the trailer of the responses to the traced requests holding the time their write took,
+	// in microseconds, which is only known once the response is written
+	TraceWriteTrailer = "Dice-Trace-Write-Usec"
 )
 
 type HTTPResponse struct {
 	Status string      `json:"status"`
 	Data   interface{} `json:"data"`
+	// Code is the code of the error of a response whose status is HTTPStatusError
+	Code derrors.Code `json:"code,omitempty"`
+	// Timing holds the stages of a request asking for them with the Dice-Trace header, the write excepted
+	Timing *tracing.Stages `json:"timing,omitempty"`
+}
+
+// WSTracedResponse is the message replied over a WebSocket connection asking for the timing of the stages of its
+// commands, the reply being wrapped along with them.
+type WSTracedResponse struct {
+	Data   interface{}     `json:"data"`
+	Timing *tracing.Stages `json:"timing"`
+}
+
+// newHTTPResponse returns the response to a command replied the reply, and its HTTP status.
+func newHTTPResponse(reply clientio.Reply) (HTTPResponse, int) {
+	if reply.Kind == clientio.ReplyError {
+		return HTTPResponse{Status: HTTPStatusError, Data: reply.JSONValue(), Code: reply.Code}, errorStatus(reply.Code)
+	}
+	return HTTPResponse{Status: HTTPStatusSuccess, Data: reply.JSONValue()}, http.StatusOK
+}
+
+// WSError is the message replied over WebSocket to a command failing with an error, the other replies being
+// the JSON values of the replies.
+type WSError struct {
+	Error string       `json:"error"`
+	Code  derrors.Code `json:"code"`
+}
+
+// errorStatus returns the HTTP status of the responses to the commands failing with an error of the code.
+func errorStatus(code derrors.Code) int {
+	switch code {
+	case derrors.CodeNoAuth, derrors.CodeWrongPass:
+		return http.StatusUnauthorized
+	case derrors.CodeNoPerm, derrors.CodeDenied:
+		return http.StatusForbidden
+	case derrors.CodeNoKey, derrors.CodeNoScript:
+		return http.StatusNotFound
+	case derrors.CodeWrongType, derrors.CodeBusyKey, derrors.CodeExecAbort, derrors.CodeConflict:
+		return http.StatusConflict
+	case derrors.CodeOOM:
+		return http.StatusInsufficientStorage
+	case derrors.CodeBusy, derrors.CodeLoading:
+		return http.StatusServiceUnavailable
+	case derrors.CodeTimeout:
+		return http.StatusGatewayTimeout
+	case derrors.CodeInternal, derrors.CodeInvalidObj:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
 }
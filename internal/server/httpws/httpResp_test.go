@@ -0,0 +1,83 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteResponseError(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   diceerrors.Code
+		status int
+	}{
+		{err: diceerrors.ErrWrongTypeOperation, code: diceerrors.CodeWrongType, status: http.StatusConflict},
+		{err: diceerrors.ErrSyntax, code: diceerrors.CodeSyntax, status: http.StatusBadRequest},
+		{err: diceerrors.ErrWrongArgumentCount("GET"), code: diceerrors.CodeArity, status: http.StatusBadRequest},
+		{err: diceerrors.ErrNoAuth, code: diceerrors.CodeNoAuth, status: http.StatusUnauthorized},
+		{err: diceerrors.ErrOverloaded, code: diceerrors.CodeBusy, status: http.StatusServiceUnavailable},
+	}
+
+	s := &HTTPServer{}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.writeResponse(rec, &ops.StoreResponse{EvalResponse: &eval.EvalResponse{Error: tt.err}})
+
+		assert.Equal(t, tt.status, rec.Code, tt.err)
+		var resp HTTPResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, HTTPResponse{Status: HTTPStatusError, Data: tt.err.Error(), Code: tt.code}, resp)
+	}
+
+	// The messages of the errors are the ones replied over RESP
+	assert.Equal(t, "ERR syntax error", diceerrors.ErrSyntax.Error())
+	assert.Equal(t, diceerrors.CodeOutOfRange, diceerrors.CodeOf(diceerrors.ErrIntegerOutOfRange))
+	assert.Equal(t, diceerrors.CodeOOM, diceerrors.CodeOfMessage("OOM command not allowed"))
+	assert.Equal(t, diceerrors.CodeGeneric, diceerrors.CodeOfMessage("ERR no such import"))
+}
+
+func TestWriteTracedResponse(t *testing.T) {
+	s := &HTTPServer{}
+	timing := tracing.NewTiming(3 * time.Millisecond)
+	timing.Executed(2*time.Millisecond, 5*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	s.writeTracedResponse(rec, &ops.StoreResponse{EvalResponse: &eval.EvalResponse{Result: "v"}}, timing)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp HTTPResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "v", resp.Data)
+	require.NotNil(t, resp.Timing)
+	assert.Equal(t, int64(3000), resp.Timing.ParseUsec)
+	assert.Equal(t, int64(2000), resp.Timing.QueueUsec)
+	assert.Equal(t, int64(5000), resp.Timing.EvalUsec)
+	assert.Equal(t, TraceWriteTrailer, rec.Header().Get("Trailer"))
+	assert.NotEmpty(t, rec.Result().Trailer.Get(TraceWriteTrailer))
+}
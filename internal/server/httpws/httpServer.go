@@ -17,35 +17,48 @@
 package httpws
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/dicedb/dice/internal/idempotency"
 	"github.com/dicedb/dice/internal/iothread"
 
-	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/export"
 	"github.com/dicedb/dice/internal/server/abstractserver"
 	"github.com/dicedb/dice/internal/wal"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/accesslog"
+	"github.com/dicedb/dice/internal/admission"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/blocking"
 	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/cmd"
 	"github.com/dicedb/dice/internal/comm"
 	derrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/handover"
+	"github.com/dicedb/dice/internal/metrics"
+	"github.com/dicedb/dice/internal/namespace"
 	"github.com/dicedb/dice/internal/ops"
 	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/stats"
+	"github.com/dicedb/dice/internal/tracing"
 )
 
 const (
-	Abort     = "ABORT"
-	stringNil = "(nil)"
+	Abort    = "ABORT"
+	Shutdown = "SHUTDOWN"
 )
 
 var unimplementedCommands = map[string]bool{
@@ -58,7 +71,9 @@ type HTTPServer struct {
 	ioChan             chan *ops.StoreResponse
 	httpServer         *http.Server
 	qwatchResponseChan chan comm.QwatchResponse
-	shutdownChan       chan struct{}
+	shutdown           *shutdownTrigger
+	admission          *admission.Limiter
+	listening          chan struct{}
 }
 
 type HTTPQwatchResponse struct {
@@ -78,13 +93,26 @@ func (cim *CaseInsensitiveMux) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	cim.mux.ServeHTTP(w, r)
 }
 
+// protectedModeHandler refuses requests from clients that protected mode does not allow
+func protectedModeHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.AllowConnection(r.RemoteAddr, r.TLS != nil && len(r.TLS.VerifiedChains) > 0) {
+			slog.Warn("refusing request in protected mode", slog.String("remote-addr", r.RemoteAddr))
+			http.Error(w, derrors.ErrProtectedMode.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func NewHTTPServer(shardManager *shard.ShardManager, wl wal.AbstractWAL) *HTTPServer {
 	mux := http.NewServeMux()
 	caseInsensitiveMux := &CaseInsensitiveMux{mux: mux}
 	srv := &http.Server{
-		Addr:              fmt.Sprintf(":%d", config.DiceConfig.HTTP.Port),
-		Handler:           caseInsensitiveMux,
+		Addr:              net.JoinHostPort(config.DiceConfig().HTTP.Addr, strconv.Itoa(config.DiceConfig().HTTP.Port)),
+		Handler:           protectedModeHandler(caseInsensitiveMux),
 		ReadHeaderTimeout: 5 * time.Second,
+		ConnState:         metrics.TrackConnState(metrics.TransportHTTP),
 	}
 
 	httpServer := &HTTPServer{
@@ -92,12 +120,20 @@ func NewHTTPServer(shardManager *shard.ShardManager, wl wal.AbstractWAL) *HTTPSe
 		ioChan:             make(chan *ops.StoreResponse, 1000),
 		httpServer:         srv,
 		qwatchResponseChan: make(chan comm.QwatchResponse),
-		shutdownChan:       make(chan struct{}),
+		shutdown:           newShutdownTrigger(),
+		admission:          admission.NewLimiter(metrics.TransportHTTP),
+		listening:          make(chan struct{}),
 	}
 
 	mux.HandleFunc("/", httpServer.DiceHTTPHandler)
+	mux.HandleFunc("/import", httpServer.ImportHandler)
+	// A readiness probe takes the server out of rotation while it is degraded by a shard crashing
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		_, err := w.Write([]byte("ok"))
+		health := stats.Health(config.DiceConfig().Performance.ShardCrashWindow)
+		if health != stats.HealthOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, err := w.Write([]byte(health))
 		if err != nil {
 			return
 		}
@@ -120,8 +156,8 @@ func (s *HTTPServer) Run(ctx context.Context) error {
 		defer wg.Done()
 		select {
 		case <-ctx.Done():
-		case <-s.shutdownChan:
-			shutdownErr = derrors.ErrAborted
+		case <-s.shutdown.Done():
+			shutdownErr = s.shutdown.Request()
 			slog.Debug("Shutting down HTTP Server")
 		}
 
@@ -136,7 +172,11 @@ func (s *HTTPServer) Run(ctx context.Context) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		listenErr = s.httpServer.ListenAndServe()
+		var ln net.Listener
+		if ln, listenErr = handover.Listen("tcp", s.httpServer.Addr); listenErr == nil {
+			close(s.listening)
+			listenErr = s.httpServer.Serve(ln)
+		}
 	}()
 
 	wg.Wait()
@@ -148,47 +188,151 @@ func (s *HTTPServer) Run(ctx context.Context) error {
 	return listenErr
 }
 
+// Listening is closed once the server accepts connections.
+func (s *HTTPServer) Listening() <-chan struct{} {
+	return s.listening
+}
+
 func (s *HTTPServer) DiceHTTPHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx, span := tracing.StartRequest(tracing.Extract(request.Context(), request.Header), "http", request.RemoteAddr)
+	defer span.End()
+
 	// convert to REDIS cmd
+	parseStart := time.Now()
+	_, parseSpan := tracing.Start(ctx, tracing.SpanParse)
 	diceDBCmd, err := ParseHTTPRequest(request)
+	parseSpan.End()
 	if err != nil {
-		writeErrorResponse(writer, http.StatusBadRequest, "Invalid HTTP request format",
+		writeErrorResponse(writer, http.StatusBadRequest, errors.New("Invalid HTTP request format"),
 			"Error parsing HTTP request", slog.Any("error", err))
 		return
 	}
+	var timing *tracing.Timing
+	if tracing.Traced(request.Header) {
+		timing = tracing.NewTiming(time.Since(parseStart))
+	}
+
+	stats.CommandProcessed()
+	receivedAt := time.Now()
+
+	name, ok := cmd.CommandRenames.Resolve(diceDBCmd.Cmd)
+	if !ok {
+		writeErrorResponse(writer, http.StatusBadRequest, derrors.ErrUnknownCmdWithArgs(diceDBCmd.Cmd, diceDBCmd.Args),
+			"Disabled command received", slog.String("cmd", diceDBCmd.Cmd))
+		return
+	}
+	diceDBCmd.Cmd = name
+	tracing.SetCommand(ctx, diceDBCmd.Cmd)
+
+	if err := namespace.AdmitConnectionless(diceDBCmd); err != nil {
+		writeErrorResponse(writer, http.StatusBadRequest, err,
+			"Namespaced command refused", slog.String("cmd", diceDBCmd.Cmd))
+		return
+	}
 
 	if iothread.CommandsMeta[diceDBCmd.Cmd].CmdType == iothread.MultiShard {
-		writeErrorResponse(writer, http.StatusBadRequest, "unsupported command",
+		writeErrorResponse(writer, http.StatusBadRequest, errors.New("unsupported command"),
 			"Unsupported command received", slog.String("cmd", diceDBCmd.Cmd))
 		return
 	}
 
-	if diceDBCmd.Cmd == Abort {
-		slog.Debug("ABORT command received")
-		slog.Debug("Shutting down HTTP Server")
-		close(s.shutdownChan)
+	if isShutdownCommand(diceDBCmd) {
+		req, err := parseShutdownCommand(diceDBCmd)
+		audit.Log(request.RemoteAddr, "", diceDBCmd, err)
+		if err != nil {
+			writeErrorResponse(writer, http.StatusBadRequest, err,
+				"Invalid shutdown command received", slog.String("cmd", diceDBCmd.Cmd))
+			return
+		}
+		slog.Debug("Shutting down HTTP Server", slog.String("cmd", diceDBCmd.Cmd))
+		// Shutdown waits for the response to be written, the trigger can be pulled first
+		s.shutdown.Trigger(req)
+		writeJSONResponse(writer, HTTPResponse{Status: HTTPStatusSuccess, Data: "OK"}, http.StatusOK)
 		return
 	}
 
 	if unimplementedCommands[diceDBCmd.Cmd] {
 		writeErrorResponse(writer, http.StatusBadRequest,
-			fmt.Sprintf("Command %s is not implemented with HTTP", diceDBCmd.Cmd),
+			fmt.Errorf("Command %s is not implemented with HTTP", diceDBCmd.Cmd),
 			"Command is not implemented", slog.String("cmd", diceDBCmd.Cmd))
 		return
 	}
 
-	// send request to Shard Manager
-	s.shardManager.GetShard(0).ReqChan <- &ops.StoreOp{
-		Cmd:        diceDBCmd,
-		IOThreadID: "httpServer",
-		ShardID:    0,
-		HTTPOp:     true,
+	if diceDBCmd.Cmd == export.Command {
+		s.handleExport(ctx, writer, request, diceDBCmd, receivedAt)
+		return
+	}
+
+	if _, ok := blocking.Commands[diceDBCmd.Cmd]; ok {
+		s.handleBlockingCommand(ctx, writer, request, diceDBCmd, receivedAt)
+		return
+	}
+
+	// The retries of a request are replied the response of its first execution
+	replay, req, err := idempotency.Begin(request.Context(), request.Header.Get(idempotency.Header), diceDBCmd)
+	if err != nil {
+		writeErrorResponse(writer, errorStatus(derrors.CodeOf(err)), err,
+			"Idempotent request refused", slog.String("cmd", diceDBCmd.Cmd), slog.Any("error", err))
+		return
+	}
+	if replay != nil {
+		resp := &ops.StoreResponse{EvalResponse: replay}
+		logCommand(request.RemoteAddr, diceDBCmd, receivedAt, resp)
+		writer.Header().Set(idempotency.ReplayedHeader, "true")
+		s.writeResponse(writer, resp)
+		return
+	}
+	// The request is released unless completed, e.g. when the command is shed
+	defer req.Abandon()
+
+	// Overloaded servers shed the command rather than queue it, the client may retry later
+	if !s.admission.Acquire() {
+		writeOverloadedResponse(writer, diceDBCmd)
+		return
+	}
+	defer s.admission.Release()
+	if admission.Saturated(metrics.TransportHTTP, len(s.shardManager.GetShard(0).ReqChan)) {
+		writeOverloadedResponse(writer, diceDBCmd)
+		return
 	}
 
+	// send request to Shard Manager
+	_, dispatchSpan := tracing.Start(ctx, tracing.SpanDispatch)
+	s.shardManager.GetShard(0).Send(&ops.StoreOp{
+		Cmd:         diceDBCmd,
+		IOThreadID:  "httpServer",
+		ShardID:     0,
+		ClientAddr:  request.RemoteAddr,
+		HTTPOp:      true,
+		SpanContext: tracing.SpanContext(ctx),
+		Ctx:         request.Context(),
+		Timing:      timing,
+	})
+	dispatchSpan.End()
+
 	// Wait for response
 	resp := <-s.ioChan
+	req.Complete(resp.EvalResponse)
+	logCommand(request.RemoteAddr, diceDBCmd, receivedAt, resp)
 
-	s.writeResponse(writer, resp, diceDBCmd)
+	_, replySpan := tracing.Start(ctx, tracing.SpanReply)
+	if timing != nil {
+		s.writeTracedResponse(writer, resp, timing)
+	} else {
+		s.writeResponse(writer, resp)
+	}
+	replySpan.End()
+}
+
+// logCommand records a command executed on behalf of an HTTP or WebSocket client in the audit log and the access log.
+func logCommand(client string, diceDBCmd *cmd.DiceDBCmd, receivedAt time.Time, resp *ops.StoreResponse) {
+	if resp.EvalResponse.Error != nil {
+		audit.Log(client, "", diceDBCmd, resp.EvalResponse.Error)
+		accesslog.Log(client, diceDBCmd, time.Since(receivedAt), resp.EvalResponse.Error)
+		return
+	}
+	audit.Log(client, "", diceDBCmd, audit.ResponseError(resp.EvalResponse.Result))
+	accesslog.Log(client, diceDBCmd, time.Since(receivedAt), resp.EvalResponse.Result)
 }
 
 func (s *HTTPServer) DiceHTTPQwatchHandler(writer http.ResponseWriter, request *http.Request) {
@@ -235,11 +379,12 @@ func (s *HTTPServer) DiceHTTPQwatchHandler(writer http.ResponseWriter, request *
 		ShardID:    0,
 		Client:     qwatchClient,
 		HTTPOp:     true,
+		Ctx:        request.Context(),
 	}
 
 	slog.Info("Registered client for watching query", slog.Any("clientID", clientIdentifierID),
 		slog.Any("query", qwatchQuery))
-	s.shardManager.GetShard(0).ReqChan <- storeOp
+	s.shardManager.GetShard(0).Send(storeOp)
 
 	// Wait for 1st sync response from server for QWATCH and flush it to client
 	resp := <-s.ioChan
@@ -254,7 +399,7 @@ func (s *HTTPServer) DiceHTTPQwatchHandler(writer http.ResponseWriter, request *
 			if resp.ClientIdentifierID == clientIdentifierID {
 				s.writeQWatchResponse(writer, resp)
 			}
-		case <-s.shutdownChan:
+		case <-s.shutdown.Done():
 			return
 		case <-doneChan:
 			// Client disconnected or request finished
@@ -264,9 +409,9 @@ func (s *HTTPServer) DiceHTTPQwatchHandler(writer http.ResponseWriter, request *
 				Args: []string{qwatchQuery},
 			}
 			storeOp.Cmd = unWatchCmd
-			s.shardManager.GetShard(0).ReqChan <- storeOp
+			s.shardManager.GetShard(0).Send(storeOp)
 			resp := <-s.ioChan
-			s.writeResponse(writer, resp, diceDBCmd)
+			s.writeResponse(writer, resp)
 			return
 		}
 	}
@@ -275,12 +420,14 @@ func (s *HTTPServer) DiceHTTPQwatchHandler(writer http.ResponseWriter, request *
 func (s *HTTPServer) writeQWatchResponse(writer http.ResponseWriter, response interface{}) {
 	var result interface{}
 	var err error
+	var payload *comm.WatchPayload
 
 	// Use type assertion to handle both types of responses
 	switch resp := response.(type) {
 	case comm.QwatchResponse:
 		result = resp.Result
 		err = resp.Error
+		payload = resp.Payload
 	case *ops.StoreResponse:
 		result = resp.EvalResponse.Result
 		err = resp.EvalResponse.Error
@@ -290,19 +437,13 @@ func (s *HTTPServer) writeQWatchResponse(writer http.ResponseWriter, response in
 		return
 	}
 
-	var rp *clientio.RESPParser
-	if err != nil {
-		rp = clientio.NewRESPParser(bytes.NewBuffer([]byte(err.Error())))
-	} else {
-		rp = clientio.NewRESPParser(bytes.NewBuffer(result.([]byte)))
-	}
-
-	val, err := rp.DecodeOne()
+	// The payload of an update is shared by all of its subscribers
 	if err != nil {
-		slog.Error("Error decoding response: %v", slog.Any("error", err))
-		http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
-		return
+		payload = comm.NewWatchPayload(clientio.ErrorReply(err))
+	} else if payload == nil {
+		payload = comm.NewWatchPayload(clientio.NewReply(result))
 	}
+	val := payload.Value()
 
 	var responseJSON []byte
 	// Convert the decoded response to the HTTPQwatchResponse struct
@@ -344,45 +485,37 @@ func (s *HTTPServer) writeQWatchResponse(writer http.ResponseWriter, response in
 	flusher.Flush() // Flush the response to send it to the client
 }
 
-func (s *HTTPServer) writeResponse(writer http.ResponseWriter, result *ops.StoreResponse, diceDBCmd *cmd.DiceDBCmd) {
-	var (
-		responseValue interface{}
-		err           error
-		httpResponse  HTTPResponse
-		isDiceErr     bool
-	)
-
-	// Check if the command is migrated, if it is we use EvalResponse values
-	// else we use RESPParser to decode the response
-	_, ok := iothread.CommandsMeta[diceDBCmd.Cmd]
-	// TODO: Remove this conditional check and if (true) condition when all commands are migrated
-	if !ok || iothread.CommandsMeta[diceDBCmd.Cmd].CmdType == iothread.Custom {
-		responseValue, err = DecodeEvalResponse(result.EvalResponse)
-		if err != nil {
-			slog.Error("Error decoding response", "error", err)
-			httpResponse = HTTPResponse{Status: HTTPStatusError, Data: "Internal Server Error"}
-			writeJSONResponse(writer, httpResponse, http.StatusInternalServerError)
+func (s *HTTPServer) writeResponse(writer http.ResponseWriter, result *ops.StoreResponse) {
+	// Large array replies are sent in chunks as they are rendered
+	if result.EvalResponse.Error == nil && clientio.Streamable(result.EvalResponse.Result) {
+		if streamed, err := streamHTTPResponse(writer, result.EvalResponse.Result); streamed {
+			if err != nil {
+				slog.Error("Error writing response", "error", err)
+			}
 			return
 		}
-	} else {
-		if result.EvalResponse.Error != nil {
-			isDiceErr = true
-			responseValue = result.EvalResponse.Error.Error()
-		} else {
-			responseValue = result.EvalResponse.Result
-		}
 	}
 
 	// Create the HTTP response
-	httpResponse = HTTPResponse{Data: ResponseParser(responseValue)}
-	if isDiceErr {
-		httpResponse.Status = HTTPStatusError
-	} else {
-		httpResponse.Status = HTTPStatusSuccess
-	}
+	httpResponse, statusCode := newHTTPResponse(result.EvalResponse.Reply())
 
 	// Write the response back to the client
-	writeJSONResponse(writer, httpResponse, http.StatusOK)
+	writeJSONResponse(writer, httpResponse, statusCode)
+}
+
+// writeTracedResponse writes the response to a request asking for the timing of its stages, attached to the
+// response. The write of the response is reported in the TraceWriteTrailer trailer, the reply is never streamed.
+func (s *HTTPServer) writeTracedResponse(writer http.ResponseWriter, result *ops.StoreResponse, timing *tracing.Timing) {
+	start := time.Now()
+	httpResponse, statusCode := newHTTPResponse(result.EvalResponse.Reply())
+	timing.Encoded(time.Since(start))
+	stages := timing.Stages()
+	httpResponse.Timing = &stages
+
+	writer.Header().Set("Trailer", TraceWriteTrailer)
+	start = time.Now()
+	writeJSONResponse(writer, httpResponse, statusCode)
+	writer.Header().Set(TraceWriteTrailer, strconv.FormatInt(time.Since(start).Microseconds(), 10))
 }
 
 // Helper function to write the JSON response
@@ -403,8 +536,15 @@ func writeJSONResponse(writer http.ResponseWriter, response HTTPResponse, status
 	}
 }
 
-func writeErrorResponse(writer http.ResponseWriter, status int, message, logMessage string, logFields ...any) {
-	responseJSON, _ := json.Marshal(HTTPResponse{Status: HTTPStatusError, Data: message})
+// writeOverloadedResponse replies to a command shed because the server is overloaded.
+func writeOverloadedResponse(writer http.ResponseWriter, diceDBCmd *cmd.DiceDBCmd) {
+	writer.Header().Set("Retry-After", "1")
+	writeErrorResponse(writer, http.StatusServiceUnavailable, derrors.ErrOverloaded,
+		"Command shed, the server is overloaded", slog.String("cmd", diceDBCmd.Cmd))
+}
+
+func writeErrorResponse(writer http.ResponseWriter, status int, err error, logMessage string, logFields ...any) {
+	responseJSON, _ := json.Marshal(HTTPResponse{Status: HTTPStatusError, Data: err.Error(), Code: derrors.CodeOf(err)})
 	writer.Header().Set("Content-Type", "application/json")
 	writer.WriteHeader(status)
 	if _, err := writer.Write(responseJSON); err != nil {
@@ -415,48 +555,6 @@ func writeErrorResponse(writer http.ResponseWriter, status int, message, logMess
 	}
 }
 
-// ResponseParser parses the response value for both migrated and non-migrated cmds and
-// returns response to be rendered for HTTP/WS response
-func ResponseParser(responseValue interface{}) interface{} {
-	switch v := responseValue.(type) {
-	case []interface{}:
-		// Parses []interface{} as part of EvalResponse e.g. JSON.ARRPOP
-		// and adds to httpResponse. Also replaces "(nil)" with null JSON value
-		// in response array.
-		r := make([]interface{}, 0, len(v))
-		for _, resp := range v {
-			if val, ok := resp.(clientio.RespType); ok {
-				if stringNil == RespTypeToValue(val) {
-					r = append(r, nil)
-				} else {
-					r = append(r, RespTypeToValue(val))
-				}
-			} else {
-				r = append(r, resp)
-			}
-		}
-		return r
-
-	case []byte:
-		return string(v)
-
-	case clientio.RespType:
-		responseValue = RespTypeToValue(v)
-		if responseValue == stringNil {
-			responseValue = nil // in order to convert it in json null
-		}
-
-		return responseValue
-
-	case interface{}:
-		if val, ok := v.(clientio.RespType); ok {
-			return RespTypeToValue(val)
-		}
-	}
-
-	return responseValue
-}
-
 func generateUniqueInt32(r *http.Request) uint32 {
 	var sb strings.Builder
 	sb.WriteString(r.RemoteAddr)
@@ -467,73 +565,3 @@ func generateUniqueInt32(r *http.Request) uint32 {
 	// Hash the string using CRC32 and cast it to an int32
 	return crc32.ChecksumIEEE([]byte(sb.String()))
 }
-
-// DecodeEvalResponse Helper function to decode EvalResponse based on the error or result
-func DecodeEvalResponse(evalResp *eval.EvalResponse) (interface{}, error) {
-	var rp *clientio.RESPParser
-
-	if evalResp.Error != nil {
-		rp = clientio.NewRESPParser(bytes.NewBuffer([]byte(evalResp.Error.Error())))
-	} else {
-		rp = clientio.NewRESPParser(bytes.NewBuffer(evalResp.Result.([]byte)))
-	}
-
-	res, err := rp.DecodeOne()
-	if err != nil {
-		return nil, err
-	}
-
-	return replaceNilInInterface(res), nil
-}
-
-func replaceNilInInterface(data interface{}) interface{} {
-	switch v := data.(type) {
-	case string:
-		if v == stringNil {
-			return nil
-		}
-		return v
-	case []interface{}:
-		// Process each element in the slice
-		for i, elem := range v {
-			v[i] = replaceNilInInterface(elem)
-		}
-		return v
-	case map[string]interface{}:
-		// Process each value in the map
-		for key, value := range v {
-			v[key] = replaceNilInInterface(value)
-		}
-		return v
-	default:
-		// For other types, return as is
-		return data
-	}
-}
-
-func RespTypeToValue(respType clientio.RespType) interface{} {
-	var respArrString = map[clientio.RespType]string{
-		clientio.NIL:           "(nil)",  // Represents a RESP Nil Bulk String, which indicates a null value.
-		clientio.OK:            "OK",     // Represents a RESP Simple String with value "OK".
-		clientio.CommandQueued: "QUEUED", // Represents a Simple String indicating that a command has been queued.
-		clientio.EmptyArray:    "*0",     // Represents an empty RESP Array.
-	}
-
-	var respArrInt = map[clientio.RespType]float64{
-		clientio.IntegerZero:        0,  // Represents a RESP Integer with value 0.
-		clientio.IntegerOne:         1,  // Represents a RESP Integer with value 1.
-		clientio.IntegerNegativeOne: -1, // Represents a RESP Integer with value -1.
-		clientio.IntegerNegativeTwo: -2, // Represents a RESP Integer with value -2.
-	}
-
-	if val, exists := respArrString[respType]; exists {
-		return val
-	}
-	// Check if respType exists in respArrInt map
-	if val, exists := respArrInt[respType]; exists {
-		return val
-	}
-
-	// Default to nil if respType is not recognized
-	return nil
-}
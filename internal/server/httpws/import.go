@@ -0,0 +1,149 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	derrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/importer"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ImportHandler serves the bulk import of keys.
+//
+// `POST /import?format=json|resp&id=<id>&offset=<n>&recovery=ignore|truncate|strict` imports the request body,
+// JSON Lines of the records written by EXPORT or RESP encoded commands. The progress of the import is streamed back as JSON Lines, the last line
+// reporting its outcome. `GET /import?id=<id>` returns the progress of the last attempt of the import.
+func (s *HTTPServer) ImportHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx, span := tracing.StartRequest(tracing.Extract(request.Context(), request.Header), "http", request.RemoteAddr)
+	defer span.End()
+
+	query := request.URL.Query()
+	id := query.Get("id")
+
+	if request.Method == http.MethodGet {
+		progress, ok := importer.Status(id)
+		if !ok {
+			writeErrorResponse(writer, http.StatusNotFound, derrors.New(derrors.CodeNoKey, "no such import"), "")
+			return
+		}
+		writeJSONResponse(writer, HTTPResponse{Status: HTTPStatusSuccess, Data: progress}, http.StatusOK)
+		return
+	}
+	if request.Method != http.MethodPost {
+		writeErrorResponse(writer, http.StatusMethodNotAllowed, errors.New("Method not allowed"), "")
+		return
+	}
+
+	diceDBCmd := &cmd.DiceDBCmd{Cmd: "IMPORT", Args: []string{id}}
+	tracing.SetCommand(ctx, diceDBCmd.Cmd)
+	receivedAt := time.Now()
+	resp := &ops.StoreResponse{EvalResponse: &eval.EvalResponse{}}
+
+	opts, err := parseImportOptions(id, query.Get("format"), query.Get("offset"), query.Get("recovery"))
+	if err != nil {
+		resp.EvalResponse.Error = err
+		logCommand(request.RemoteAddr, diceDBCmd, receivedAt, resp)
+		writeErrorResponse(writer, http.StatusBadRequest, err, "")
+		return
+	}
+
+	shards := &importShards{manager: s.shardManager, clientAddr: request.RemoteAddr, spanContext: tracing.SpanContext(ctx)}
+	flusher, _ := writer.(http.Flusher)
+	started := false
+	writeProgress := func(progress importer.Progress) error {
+		if !started {
+			started = true
+			writer.Header().Set("Content-Type", "application/x-ndjson")
+			writer.WriteHeader(http.StatusOK)
+		}
+		line, err := json.Marshal(progress)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	progress, err := importer.Run(ctx, request.Body, shards, opts, writeProgress)
+	resp.EvalResponse.Result = progress
+	resp.EvalResponse.Error = err
+	logCommand(request.RemoteAddr, diceDBCmd, receivedAt, resp)
+
+	switch {
+	case errors.Is(err, importer.ErrInProgress):
+		writeErrorResponse(writer, http.StatusConflict, err, "")
+	case ctx.Err() != nil:
+		// The client went away, nobody is left to report to
+		slog.Debug("Import interrupted", slog.String("client", request.RemoteAddr), slog.Any("error", err))
+	default:
+		if err := writeProgress(progress); err != nil {
+			slog.Debug("Error writing the progress of the import", slog.Any("error", err))
+		}
+	}
+}
+
+func parseImportOptions(id, format, offset, recovery string) (importer.Options, error) {
+	opts := importer.Options{ID: id}
+
+	var err error
+	if opts.Format, err = importer.ParseFormat(format); err != nil {
+		return opts, err
+	}
+	if opts.Recovery, err = importer.ParseRecovery(recovery); err != nil {
+		return opts, err
+	}
+	if offset != "" {
+		if opts.Offset, err = strconv.ParseInt(offset, 10, 64); err != nil || opts.Offset < 0 {
+			return opts, errors.New("ERR offset must be a non negative integer")
+		}
+	}
+	return opts, nil
+}
+
+// importShards executes the commands of an import received by the HTTP server.
+type importShards struct {
+	manager     *shard.ShardManager
+	clientAddr  string
+	spanContext trace.SpanContext
+}
+
+func (s *importShards) Route(key string) uint8 {
+	id, _ := s.manager.GetShardInfo(key)
+	return id
+}
+
+func (s *importShards) Exec(ctx context.Context, cmds map[uint8][]*cmd.DiceDBCmd) (map[uint8][]*eval.EvalResponse, error) {
+	return s.manager.ExecBatch(ctx, &shard.Batch{ClientAddr: s.clientAddr, SpanContext: s.spanContext, Cmds: cmds})
+}
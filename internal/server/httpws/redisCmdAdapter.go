@@ -31,6 +31,7 @@ import (
 
 	"github.com/dicedb/dice/internal/cmd"
 	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/idempotency"
 )
 
 const (
@@ -54,6 +55,7 @@ const (
 	JSON             = "json"
 	QWatch           = "Q.WATCH"
 	ABORT            = "ABORT"
+	SHUTDOWN         = "SHUTDOWN"
 	IsByteEncodedVal = "isByteEncodedVal"
 )
 
@@ -97,7 +99,7 @@ func ParseHTTPRequest(r *http.Request) (*cmd.DiceDBCmd, error) {
 				return nil, err
 			}
 
-			if len(jsonBody) == 0 && command != ABORT {
+			if len(jsonBody) == 0 && command != ABORT && command != SHUTDOWN {
 				return nil, fmt.Errorf("empty JSON object")
 			}
 
@@ -200,6 +202,18 @@ func ParseWebsocketMessage(msg []byte) (*cmd.DiceDBCmd, error) {
 	}, nil
 }
 
+// splitRequestID splits the request id off a command prefixed with it, IDEMPOTENT id command [arg ...], see
+// idempotency. The request id of a command without the prefix is empty.
+func splitRequestID(c *cmd.DiceDBCmd) (string, *cmd.DiceDBCmd, error) {
+	if c.Cmd != idempotency.Prefix {
+		return "", c, nil
+	}
+	if len(c.Args) < 2 {
+		return "", nil, diceerrors.ErrWrongArgumentCount(idempotency.Prefix)
+	}
+	return c.Args[0], &cmd.DiceDBCmd{Cmd: strings.ToUpper(c.Args[1]), Args: c.Args[2:]}, nil
+}
+
 func processPriorityKeys(jsonBody map[string]interface{}, args *[]string) {
 	for _, key := range getPriorityKeys() {
 		if val, exists := jsonBody[key]; exists {
@@ -0,0 +1,67 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"sync"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/shutdown"
+)
+
+// shutdownTrigger lets the handlers of a server ask it to stop on ABORT or SHUTDOWN. Run
+// returns the request, so that main goes on stopping the rest of the server.
+type shutdownTrigger struct {
+	once sync.Once
+	done chan struct{}
+	req  *shutdown.Request
+}
+
+func newShutdownTrigger() *shutdownTrigger {
+	return &shutdownTrigger{done: make(chan struct{})}
+}
+
+// Trigger records the request and wakes up the server, the requests received afterwards are ignored.
+func (t *shutdownTrigger) Trigger(req *shutdown.Request) {
+	t.once.Do(func() {
+		t.req = req
+		close(t.done)
+	})
+}
+
+// Done is closed once a shutdown is triggered.
+func (t *shutdownTrigger) Done() <-chan struct{} {
+	return t.done
+}
+
+// Request returns the request that triggered the shutdown. It must only be called once Done is closed.
+func (t *shutdownTrigger) Request() *shutdown.Request {
+	return t.req
+}
+
+// isShutdownCommand reports whether the command stops the server.
+func isShutdownCommand(diceDBCmd *cmd.DiceDBCmd) bool {
+	return diceDBCmd.Cmd == Abort || diceDBCmd.Cmd == Shutdown
+}
+
+// parseShutdownCommand returns the request of a SHUTDOWN command, ABORT being SHUTDOWN NOSAVE.
+func parseShutdownCommand(diceDBCmd *cmd.DiceDBCmd) (*shutdown.Request, error) {
+	if diceDBCmd.Cmd == Abort {
+		return &shutdown.Request{Mode: shutdown.NoSave}, nil
+	}
+	return shutdown.ParseArgs(diceDBCmd.Args)
+}
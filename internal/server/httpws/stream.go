@@ -0,0 +1,130 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/gorilla/websocket"
+)
+
+// streamBufferSize bounds the bytes of a streamed reply buffered before they are written to the connection.
+const streamBufferSize = 16 * 1024
+
+// jsonArrayWriter streams an array reply as a JSON array, enclosed between prefix and suffix, with
+// the elements rendered like the elements of a reply by JSONValue.
+type jsonArrayWriter struct {
+	w        *bufio.Writer
+	prefix   string
+	suffix   string
+	elements int
+}
+
+var _ clientio.ArrayWriter = (*jsonArrayWriter)(nil)
+
+func newJSONArrayWriter(w io.Writer, prefix, suffix string) *jsonArrayWriter {
+	return &jsonArrayWriter{w: bufio.NewWriterSize(w, streamBufferSize), prefix: prefix, suffix: suffix}
+}
+
+func (w *jsonArrayWriter) Begin(int) error {
+	_, err := w.w.WriteString(w.prefix + "[")
+	return err
+}
+
+func (w *jsonArrayWriter) WriteElement(v interface{}) error {
+	elem, err := json.Marshal(clientio.NewReply(v).JSONValue())
+	if err != nil {
+		return err
+	}
+	if w.elements > 0 {
+		if err := w.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	w.elements++
+	_, err = w.w.Write(elem)
+	return err
+}
+
+func (w *jsonArrayWriter) End() error {
+	if _, err := w.w.WriteString("]" + w.suffix); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// flushWriter flushes every write to the HTTP client, so that a streamed reply is sent in chunks
+// as it is produced.
+type flushWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		f.flusher.Flush()
+	}
+	return n, err
+}
+
+// streamHTTPResponse writes a successful streamable reply with the chunked transfer encoding.
+// It reports false when the client can not be streamed to.
+func streamHTTPResponse(writer http.ResponseWriter, value interface{}) (bool, error) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		return false, nil
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	w := newJSONArrayWriter(flushWriter{w: writer, flusher: flusher}, `{"status":"`+HTTPStatusSuccess+`","data":`, "}")
+	return true, clientio.StreamArray(w, value)
+}
+
+// deadlineWriter extends the write deadline of a WebSocket connection before every fragment of a
+// streamed message, so that the deadline bounds every write rather than the whole message.
+type deadlineWriter struct {
+	w    io.Writer
+	conn *websocket.Conn
+}
+
+func (d deadlineWriter) Write(p []byte) (int, error) {
+	if err := d.conn.SetWriteDeadline(time.Now().Add(config.DiceConfig().WebSocket.WriteResponseTimeout)); err != nil {
+		return 0, err
+	}
+	return d.w.Write(p)
+}
+
+// streamWSMessage writes a streamable reply as a single text message sent in fragments. Unlike the
+// other replies, it is not retried, as a part of the message may have been sent already.
+func streamWSMessage(conn *websocket.Conn, value interface{}) error {
+	mw, err := conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+
+	err = clientio.StreamArray(newJSONArrayWriter(deadlineWriter{w: mw, conn: conn}, "", ""), value)
+	return errors.Join(err, mw.Close())
+}
@@ -17,7 +17,6 @@
 package httpws
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -25,9 +24,9 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/dicedb/dice/internal/iothread"
@@ -36,12 +35,28 @@ import (
 	"github.com/dicedb/dice/internal/wal"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/admission"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/blocking"
 	"github.com/dicedb/dice/internal/clientio"
 	"github.com/dicedb/dice/internal/cmd"
 	"github.com/dicedb/dice/internal/comm"
 	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/export"
+	"github.com/dicedb/dice/internal/failpoint"
+	"github.com/dicedb/dice/internal/handover"
+	"github.com/dicedb/dice/internal/idempotency"
+	"github.com/dicedb/dice/internal/inproc"
+	"github.com/dicedb/dice/internal/metrics"
+	"github.com/dicedb/dice/internal/namespace"
 	"github.com/dicedb/dice/internal/ops"
 	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/sockerr"
+	"github.com/dicedb/dice/internal/stats"
+	"github.com/dicedb/dice/internal/tracing"
+	"github.com/dicedb/dice/internal/watchmanager"
 	"github.com/gorilla/websocket"
 	"golang.org/x/exp/rand"
 )
@@ -51,7 +66,8 @@ const Qunwatch = "Q.UNWATCH"
 const Subscribe = "SUBSCRIBE"
 
 var unimplementedCommandsWebsocket = map[string]bool{
-	Qunwatch: true,
+	Qunwatch:       true,
+	export.Command: true,
 }
 
 type WebsocketServer struct {
@@ -61,13 +77,138 @@ type WebsocketServer struct {
 	websocketServer    *http.Server
 	upgrader           websocket.Upgrader
 	qwatchResponseChan chan comm.QwatchResponse
-	shutdownChan       chan struct{}
+	shutdown           *shutdownTrigger
+	commandFilters     *commandFilters
+	connections        *wsConnections
+	fanout             *wsFanout
+	admission          *admission.Limiter
+	listening          chan struct{}
+
+	ioThreadManager          *iothread.Manager
+	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription
+	globalErrorChan          chan error
+	wl                       wal.AbstractWAL
+	host                     *inproc.Host // host runs the sessions of the watched commands, nil without an io-thread manager
 }
 
-func NewWebSocketServer(shardManager *shard.ShardManager, port int, wl wal.AbstractWAL) *WebsocketServer {
+// commandFilter decides which commands the WebSocket frontend dispatches, so that
+// public facing deployments can expose only a subset of the commands.
+type commandFilter struct {
+	allowed          map[string]bool // allowed is empty when every command is allowed
+	deniedCategories map[audit.Category]bool
+}
+
+func newCommandFilter(allowedCommands, deniedCategories []string) *commandFilter {
+	f := &commandFilter{
+		allowed:          make(map[string]bool),
+		deniedCategories: make(map[audit.Category]bool),
+	}
+
+	for _, command := range allowedCommands {
+		command = strings.ToUpper(strings.TrimSpace(command))
+		if command != "" {
+			f.allowed[command] = true
+		}
+	}
+
+	for _, name := range deniedCategories {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		category, err := audit.ParseCategory(name)
+		if err != nil {
+			slog.Warn("ignoring denied websocket command category", slog.Any("error", err))
+			continue
+		}
+		f.deniedCategories[category] = true
+	}
+
+	return f
+}
+
+// Allows reports whether the command may be dispatched
+func (f *commandFilter) Allows(command string) bool {
+	if len(f.allowed) > 0 && !f.allowed[command] {
+		return false
+	}
+	return !f.deniedCategories[audit.Categorize(command)]
+}
+
+// commandFilters are the command filters of the WebSocket connections, by the user they authenticated as
+// with AUTH. The connections of the other users, and the ones not authenticated, are filtered by the
+// allowlist of the config.
+type commandFilters struct {
+	fallback *commandFilter
+	byUser   map[string]*commandFilter
+}
+
+func newCommandFilters(allowedCommands, userAllowedCommands, deniedCategories []string) *commandFilters {
+	filters := &commandFilters{
+		fallback: newCommandFilter(allowedCommands, deniedCategories),
+		byUser:   make(map[string]*commandFilter),
+	}
+
+	for _, entry := range userAllowedCommands {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		user, commands, ok := strings.Cut(entry, ":")
+		if !ok || user == "" {
+			slog.Warn("ignoring websocket user allowed commands, expected USER:COMMAND|COMMAND...", slog.String("entry", entry))
+			continue
+		}
+		filters.byUser[user] = newCommandFilter(strings.Split(commands, "|"), deniedCategories)
+	}
+
+	return filters
+}
+
+// forUser returns the filter of the connections authenticated as the user
+func (f *commandFilters) forUser(user string) *commandFilter {
+	if filter, ok := f.byUser[user]; ok {
+		return filter
+	}
+	return f.fallback
+}
+
+// wsClient is the state of a WebSocket connection: its session, authenticated with AUTH, and the filter
+// of its commands following the user it authenticated as.
+type wsClient struct {
+	session *auth.Session
+	filter  *commandFilter
+}
+
+// authenticate evaluates AUTH on the connection, the same as over RESP.
+func (s *WebsocketServer) authenticate(client *wsClient, args []string) error {
+	username := config.DiceConfig().Auth.UserName
+	var password string
+	switch len(args) {
+	case 1:
+		password = args[0]
+	case 2:
+		username, password = args[0], args[1]
+	default:
+		return diceerrors.ErrWrongArgumentCount(auth.Cmd)
+	}
+
+	if config.DiceConfig().Auth.Password == "" && username == config.DiceConfig().Auth.UserName {
+		return diceerrors.ErrAuth
+	}
+	if err := client.session.Validate(username, password); err != nil {
+		return err
+	}
+	client.filter = s.commandFilters.forUser(username)
+	return nil
+}
+
+// NewWebSocketServer returns a WebSocket frontend listening on the port. The commands are watched on
+// sessions run by the io-thread manager, a nil manager leaving the watch commands unavailable.
+func NewWebSocketServer(shardManager *shard.ShardManager, ioThreadManager *iothread.Manager,
+	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription, globalErrChan chan error, port int, wl wal.AbstractWAL) *WebsocketServer {
 	mux := http.NewServeMux()
 	srv := &http.Server{
-		Addr:              fmt.Sprintf(":%d", port),
+		Addr:              net.JoinHostPort(config.DiceConfig().WebSocket.Addr, strconv.Itoa(port)),
 		Handler:           mux,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
@@ -76,14 +217,25 @@ func NewWebSocketServer(shardManager *shard.ShardManager, port int, wl wal.Abstr
 		CheckOrigin: func(r *http.Request) bool { return true },
 	}
 
+	wsConfig := config.DiceConfig().WebSocket
 	websocketServer := &WebsocketServer{
 		shardManager:       shardManager,
 		ioChan:             make(chan *ops.StoreResponse, 1000),
 		websocketServer:    srv,
 		upgrader:           upgrader,
 		qwatchResponseChan: make(chan comm.QwatchResponse),
-		shutdownChan:       make(chan struct{}),
+		shutdown:           newShutdownTrigger(),
+		commandFilters:     newCommandFilters(wsConfig.AllowedCommands, wsConfig.UserAllowedCommands, wsConfig.DeniedCategories),
+		connections:        newWSConnections(),
+		admission:          admission.NewLimiter(metrics.TransportWebSocket),
+		listening:          make(chan struct{}),
+
+		ioThreadManager:          ioThreadManager,
+		cmdWatchSubscriptionChan: cmdWatchSubscriptionChan,
+		globalErrorChan:          globalErrChan,
+		wl:                       wl,
 	}
+	websocketServer.fanout = newWSFanout(websocketServer.connections, websocketServer.qwatchResponseChan)
 
 	mux.HandleFunc("/", websocketServer.WebsocketHandler)
 	return websocketServer
@@ -91,26 +243,42 @@ func NewWebSocketServer(shardManager *shard.ShardManager, port int, wl wal.Abstr
 
 func (s *WebsocketServer) Run(ctx context.Context) error {
 	var wg sync.WaitGroup
-	var err error
+	var shutdownErr, listenErr error
 
 	websocketCtx, cancelWebsocket := context.WithCancel(ctx)
 	defer cancelWebsocket()
 
 	s.shardManager.RegisterIOThread("wsServer", s.ioChan, nil)
 
+	// The fan-out stops with websocketCtx, once the server is done
+	go s.fanout.Run(websocketCtx)
+
+	// The sessions of the watched commands are stopped once the connections are closed
+	if s.ioThreadManager != nil {
+		hostCtx, cancelHost := context.WithCancel(context.Background())
+		s.host = inproc.NewHost(hostCtx, "ws", "websocket", s.shardManager, s.ioThreadManager, s.cmdWatchSubscriptionChan, s.globalErrorChan, s.wl)
+		defer func() {
+			cancelHost()
+			s.host.Close()
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		select {
 		case <-ctx.Done():
-		case <-s.shutdownChan:
-			err = diceerrors.ErrAborted
+		case <-s.shutdown.Done():
+			shutdownErr = s.shutdown.Request()
 			slog.Debug("Shutting down Websocket Server", slog.Any("time", time.Now()))
 		}
 
-		shutdownErr := s.websocketServer.Shutdown(websocketCtx)
-		if shutdownErr != nil {
+		// Hijacked connections are not closed by the HTTP server, they are told the server is going away
+		s.connections.closeAll(errServerShuttingDown)
+
+		if err := s.websocketServer.Shutdown(websocketCtx); err != nil {
 			slog.Error("Websocket Server shutdown failed:", slog.Any("error", err))
+			shutdownErr = err
 			return
 		}
 	}()
@@ -118,15 +286,27 @@ func (s *WebsocketServer) Run(ctx context.Context) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		slog.Info("also listenting WebSocket on", slog.String("port", s.websocketServer.Addr[1:]))
-		err = s.websocketServer.ListenAndServe()
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("error while listenting on WebSocket", slog.Any("error", err))
+		slog.Info("also listenting WebSocket on", slog.String("addr", s.websocketServer.Addr))
+		var ln net.Listener
+		if ln, listenErr = handover.Listen("tcp", s.websocketServer.Addr); listenErr == nil {
+			close(s.listening)
+			listenErr = s.websocketServer.Serve(ln)
+		}
+		if listenErr != nil && !errors.Is(listenErr, http.ErrServerClosed) {
+			slog.Error("error while listenting on WebSocket", slog.Any("error", listenErr))
 		}
 	}()
 
 	wg.Wait()
-	return err
+	if shutdownErr != nil {
+		return shutdownErr
+	}
+	return listenErr
+}
+
+// Listening is closed once the server accepts connections.
+func (s *WebsocketServer) Listening() <-chan struct{} {
+	return s.listening
 }
 
 func (s *WebsocketServer) WebsocketHandler(w http.ResponseWriter, r *http.Request) {
@@ -136,191 +316,277 @@ func (s *WebsocketServer) WebsocketHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// closing handshake
+	// The connection is upgraded before refusing it, so that the client is told why in the close frame
+	var closeErr error
+	if !auth.AllowConnection(r.RemoteAddr, r.TLS != nil && len(r.TLS.VerifiedChains) > 0) {
+		closeErr = diceerrors.ErrProtectedMode
+	} else {
+		closeErr = s.connections.add(conn)
+	}
+	if closeErr != nil {
+		slog.Warn("refusing websocket connection", slog.String("remote-addr", r.RemoteAddr), slog.Any("error", closeErr))
+		stats.ConnectionRejected()
+		closeConnection(conn, closeErr)
+		return
+	}
+
+	metrics.ClientConnected(metrics.TransportWebSocket)
+	defer metrics.ClientDisconnected(metrics.TransportWebSocket)
+
+	// closing handshake, with the close code matching the reason the connection ends
+	watches := newWSWatches()
 	defer func() {
-		closeErr := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "close 1000 (normal)"))
-		if closeErr != nil {
-			slog.Debug("Error during closing handshake", slog.Any("error", closeErr))
-		}
-		conn.Close()
+		watches.closeAll()
+		s.fanout.Unsubscribe(conn)
+		s.connections.close(conn, closeErr)
+		s.connections.remove(conn)
 	}()
 
-	maxRetries := config.DiceConfig.WebSocket.MaxWriteResponseRetries
+	// The traceparent header of the handshake is the parent of the spans of every command of the connection
+	traceCtx := tracing.Extract(r.Context(), r.Header)
+	client := &wsClient{session: auth.NewSession(), filter: s.commandFilters.fallback}
 	for {
 		// read incoming message
 		_, msg, err := conn.ReadMessage()
 		if err != nil {
 			// acceptable close errors
 			errs := []int{websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure}
-			if websocket.IsCloseError(err, errs...) {
+			if websocket.IsCloseError(err, errs...) || errors.Is(err, net.ErrClosed) {
 				break
 			}
 			slog.Error("Error reading message", slog.Any("error", err))
+			closeErr = err
 			break
 		}
 
-		// parse message to dice command
-		diceDBCmd, err := ParseWebsocketMessage(msg)
-		if errors.Is(err, diceerrors.ErrEmptyCommand) {
-			continue
-		} else if err != nil {
-			if err := WriteResponseWithRetries(conn, []byte("error: parsing failed"), maxRetries); err != nil {
-				slog.Debug(fmt.Sprintf("Error writing message: %v", err))
-			}
-			continue
+		if closeErr = s.handleMessage(traceCtx, conn, watches, client, r, msg); closeErr != nil {
+			break
 		}
+	}
+}
 
-		if iothread.CommandsMeta[diceDBCmd.Cmd].CmdType == iothread.MultiShard {
-			if err := WriteResponseWithRetries(conn, []byte("error: unsupported command"), maxRetries); err != nil {
-				slog.Debug(fmt.Sprintf("Error writing message: %v", err))
-			}
-			continue
+// handleMessage executes the command of a message received over the WebSocket connection and writes
+// the response back. It returns the reason the connection must be closed, nil to keep reading from it.
+func (s *WebsocketServer) handleMessage(ctx context.Context, conn *websocket.Conn, watches *wsWatches, client *wsClient,
+	r *http.Request, msg []byte) error {
+	maxRetries := config.DiceConfig().WebSocket.MaxWriteResponseRetries
+
+	ctx, span := tracing.StartRequest(ctx, "websocket", r.RemoteAddr)
+	defer span.End()
+
+	// parse message to dice command
+	parseStart := time.Now()
+	_, parseSpan := tracing.Start(ctx, tracing.SpanParse)
+	diceDBCmd, err := ParseWebsocketMessage(msg)
+	parseSpan.End()
+	parse := time.Since(parseStart)
+	if errors.Is(err, diceerrors.ErrEmptyCommand) {
+		return nil
+	} else if err != nil {
+		if err := s.connections.write(conn, []byte("error: parsing failed"), maxRetries); err != nil {
+			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 		}
+		return nil
+	}
 
-		// TODO - on abort, close client connection instead of closing server?
-		if diceDBCmd.Cmd == Abort {
-			close(s.shutdownChan)
-			break
+	stats.CommandProcessed()
+	receivedAt := time.Now()
+
+	requestID, diceDBCmd, err := splitRequestID(diceDBCmd)
+	if err != nil {
+		if err := s.connections.write(conn, []byte(err.Error()), maxRetries); err != nil {
+			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 		}
+		return nil
+	}
 
-		if unimplementedCommandsWebsocket[diceDBCmd.Cmd] {
-			if err := WriteResponseWithRetries(conn, []byte("Command is not implemented with Websocket"), maxRetries); err != nil {
-				slog.Debug(fmt.Sprintf("Error writing message: %v", err))
-			}
-			continue
+	name, ok := cmd.CommandRenames.Resolve(diceDBCmd.Cmd)
+	if !ok {
+		if err := s.connections.write(conn, []byte(diceerrors.ErrUnknownCmdWithArgs(diceDBCmd.Cmd, diceDBCmd.Args).Error()), maxRetries); err != nil {
+			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 		}
+		return nil
+	}
+	diceDBCmd.Cmd = name
+	tracing.SetCommand(ctx, diceDBCmd.Cmd)
 
-		// create request
-		sp := &ops.StoreOp{
-			Cmd:         diceDBCmd,
-			IOThreadID:  "wsServer",
-			ShardID:     0,
-			WebsocketOp: true,
+	if err := namespace.AdmitConnectionless(diceDBCmd); err != nil {
+		if err := s.connections.write(conn, []byte(err.Error()), maxRetries); err != nil {
+			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 		}
+		return nil
+	}
 
-		// handle q.watch commands
-		if diceDBCmd.Cmd == Qwatch || diceDBCmd.Cmd == Subscribe {
-			clientIdentifierID := generateUniqueInt32(r)
-			sp.Client = comm.NewHTTPQwatchClient(s.qwatchResponseChan, clientIdentifierID)
+	// AUTH is evaluated on the connection, whatever its filter, so that it can switch to the filter of the user
+	if diceDBCmd.Cmd == auth.Cmd {
+		resp := &ops.StoreResponse{EvalResponse: &eval.EvalResponse{Result: clientio.OK}}
+		if err := s.authenticate(client, diceDBCmd.Args); err != nil {
+			resp.EvalResponse = &eval.EvalResponse{Error: err}
+		}
+		logCommand(r.RemoteAddr, diceDBCmd, receivedAt, resp)
+		return s.processResponse(conn, resp)
+	}
 
-			// start a goroutine for subsequent updates
-			go s.processQwatchUpdates(clientIdentifierID, conn)
+	if !client.filter.Allows(diceDBCmd.Cmd) {
+		if err := s.connections.write(conn, []byte(diceerrors.ErrCommandNotAllowed(diceDBCmd.Cmd, "WebSocket").Error()), maxRetries); err != nil {
+			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 		}
+		return nil
+	}
 
-		s.shardManager.GetShard(0).ReqChan <- sp
-		resp := <-s.ioChan
-		if err := s.processResponse(conn, diceDBCmd, resp); err != nil {
-			break
+	if iothread.CommandsMeta[diceDBCmd.Cmd].CmdType == iothread.MultiShard {
+		if err := s.connections.write(conn, []byte("error: unsupported command"), maxRetries); err != nil {
+			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 		}
+		return nil
 	}
-}
 
-func (s *WebsocketServer) processQwatchUpdates(clientIdentifierID uint32, conn *websocket.Conn) {
-	for {
-		select {
-		case resp := <-s.qwatchResponseChan:
-			if resp.ClientIdentifierID == clientIdentifierID {
-				if err := s.processQwatchResponse(conn, resp); err != nil {
-					slog.Debug("Error writing response to client. Shutting down goroutine for q.watch updates", slog.Any("clientIdentifierID", clientIdentifierID), slog.Any("error", err))
-					return
-				}
+	if isShutdownCommand(diceDBCmd) {
+		req, err := parseShutdownCommand(diceDBCmd)
+		audit.Log(r.RemoteAddr, "", diceDBCmd, err)
+		if err != nil {
+			if err := s.connections.write(conn, []byte(err.Error()), maxRetries); err != nil {
+				slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 			}
-		case <-s.shutdownChan:
-			return
+			return nil
 		}
+		if err := s.connections.write(conn, []byte(`"OK"`), maxRetries); err != nil {
+			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
+		}
+		s.shutdown.Trigger(req)
+		return req
 	}
-}
 
-func (s *WebsocketServer) processQwatchResponse(conn *websocket.Conn, response interface{}) error {
-	var result interface{}
-	var err error
-	maxRetries := config.DiceConfig.WebSocket.MaxWriteResponseRetries
+	// The commands are watched on sessions of their own, their updates are pushed until they are unwatched
+	if isWatchCommand(diceDBCmd.Cmd) {
+		return s.handleWatch(ctx, conn, watches, r.RemoteAddr, diceDBCmd)
+	}
+	if isUnwatchCommand(diceDBCmd.Cmd) {
+		return s.handleUnwatch(conn, watches, r.RemoteAddr, diceDBCmd)
+	}
 
-	// check response type
-	switch resp := response.(type) {
-	case comm.QwatchResponse:
-		result = resp.Result
-		err = resp.Error
-	default:
-		slog.Debug("Unsupported response type")
-		if err := WriteResponseWithRetries(conn, []byte("error: 500 Internal Server Error"), maxRetries); err != nil {
+	// The messages of a connection are read one at a time, a blocked client could not be told apart
+	// from a disconnected one
+	if _, ok := blocking.Commands[diceDBCmd.Cmd]; ok || unimplementedCommandsWebsocket[diceDBCmd.Cmd] {
+		if err := s.connections.write(conn, []byte("Command is not implemented with Websocket"), maxRetries); err != nil {
 			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
-			return fmt.Errorf("error writing response: %v", err)
 		}
 		return nil
 	}
 
-	var responseValue interface{}
-	var rp *clientio.RESPParser
+	// The retries of a request are replied the response of its first execution
+	replay, req, err := idempotency.Begin(ctx, requestID, diceDBCmd)
 	if err != nil {
-		rp = clientio.NewRESPParser(bytes.NewBuffer([]byte(err.Error())))
-	} else {
-		rp = clientio.NewRESPParser(bytes.NewBuffer(result.([]byte)))
+		return s.processResponse(conn, &ops.StoreResponse{EvalResponse: &eval.EvalResponse{Error: err}})
+	}
+	if replay != nil {
+		resp := &ops.StoreResponse{EvalResponse: replay}
+		logCommand(r.RemoteAddr, diceDBCmd, receivedAt, resp)
+		return s.processResponse(conn, resp)
 	}
+	// The request is released unless completed, e.g. when the command is shed
+	defer req.Abandon()
 
-	responseValue, err = rp.DecodeOne()
-	if err != nil {
-		slog.Debug("Error decoding response", "error", err)
-		if err := WriteResponseWithRetries(conn, []byte("error: 500 Internal Server Error"), maxRetries); err != nil {
-			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
-			return fmt.Errorf("error writing response: %v", err)
-		}
-		return nil
+	// Overloaded servers shed the command rather than queue it, the connection is closed so that the
+	// client backs off before reconnecting
+	if !s.admission.Acquire() {
+		return diceerrors.ErrOverloaded
+	}
+	defer s.admission.Release()
+	if admission.Saturated(metrics.TransportWebSocket, len(s.shardManager.GetShard(0).ReqChan)) {
+		return diceerrors.ErrOverloaded
 	}
 
-	respBytes, err := json.Marshal(responseValue)
-	if err != nil {
-		slog.Debug("Error marshaling json", "error", err)
-		if err := WriteResponseWithRetries(conn, []byte("error: marshaling json"), maxRetries); err != nil {
-			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
-			return fmt.Errorf("error writing response: %v", err)
-		}
-		return nil
+	// create request
+	sp := &ops.StoreOp{
+		Cmd:         diceDBCmd,
+		IOThreadID:  "wsServer",
+		ShardID:     0,
+		ClientAddr:  r.RemoteAddr,
+		WebsocketOp: true,
+		SpanContext: tracing.SpanContext(ctx),
+		Ctx:         ctx,
+	}
+	if wsTraced(r) {
+		sp.Timing = tracing.NewTiming(parse)
 	}
 
-	// success
-	// Write response with retries for transient errors
-	if err := WriteResponseWithRetries(conn, respBytes, config.DiceConfig.WebSocket.MaxWriteResponseRetries); err != nil {
+	// handle q.watch commands
+	if diceDBCmd.Cmd == Qwatch || diceDBCmd.Cmd == Subscribe {
+		clientIdentifierID := generateUniqueInt32(r)
+		sp.Client = comm.NewHTTPQwatchClient(s.qwatchResponseChan, clientIdentifierID)
+
+		// subsequent updates are pushed by the fan-out workers
+		s.fanout.Subscribe(clientIdentifierID, conn)
+	}
+
+	_, dispatchSpan := tracing.Start(ctx, tracing.SpanDispatch)
+	s.shardManager.GetShard(0).Send(sp)
+	dispatchSpan.End()
+
+	resp := <-s.ioChan
+	req.Complete(resp.EvalResponse)
+	logCommand(r.RemoteAddr, diceDBCmd, receivedAt, resp)
+
+	_, replySpan := tracing.Start(ctx, tracing.SpanReply)
+	defer replySpan.End()
+	if sp.Timing != nil {
+		return s.processTracedResponse(conn, resp, sp.Timing)
+	}
+	return s.processResponse(conn, resp)
+}
+
+// wsTraced reports whether the WebSocket connection asked for the timing of the stages of its commands, with
+// the Dice-Trace header or the trace=1 query parameter of its handshake, the browsers not setting headers.
+func wsTraced(r *http.Request) bool {
+	return tracing.Traced(r.Header) || r.URL.Query().Get("trace") == "1"
+}
+
+// processTracedResponse writes the response to a command of a connection asking for the timing of the stages of
+// its commands, the reply being wrapped along with the stages. The write of the reply is not reported.
+func (s *WebsocketServer) processTracedResponse(conn *websocket.Conn, response *ops.StoreResponse, timing *tracing.Timing) error {
+	start := time.Now()
+	reply := response.EvalResponse.Reply()
+	var data interface{} = reply.JSONValue()
+	if reply.Kind == clientio.ReplyError {
+		data = WSError{Error: reply.Str, Code: reply.Code}
+	}
+	timing.Encoded(time.Since(start))
+	stages := timing.Stages()
+
+	respBytes, err := json.Marshal(WSTracedResponse{Data: data, Timing: &stages})
+	if err != nil {
+		return fmt.Errorf("error marshaling response: %v", err)
+	}
+	if err := s.connections.write(conn, respBytes, config.DiceConfig().WebSocket.MaxWriteResponseRetries); err != nil {
 		slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 		return fmt.Errorf("error writing response: %v", err)
 	}
-
 	return nil
 }
 
-func (s *WebsocketServer) processResponse(conn *websocket.Conn, diceDBCmd *cmd.DiceDBCmd, response *ops.StoreResponse) error {
-	var err error
-	maxRetries := config.DiceConfig.WebSocket.MaxWriteResponseRetries
+func (s *WebsocketServer) processResponse(conn *websocket.Conn, response *ops.StoreResponse) error {
+	maxRetries := config.DiceConfig().WebSocket.MaxWriteResponseRetries
 
-	var responseValue interface{}
-	// Check if the command is migrated, if it is we use EvalResponse values
-	// else we use RESPParser to decode the response
-	_, ok := iothread.CommandsMeta[diceDBCmd.Cmd]
-	// TODO: Remove this conditional check and if (true) condition when all commands are migrated
-	if !ok {
-		responseValue, err = DecodeEvalResponse(response.EvalResponse)
-		if err != nil {
-			slog.Debug("Error decoding response", "error", err)
-			if err := WriteResponseWithRetries(conn, []byte("error: 500 Internal Server Error"), maxRetries); err != nil {
-				slog.Debug(fmt.Sprintf("Error writing message: %v", err))
-				return fmt.Errorf("error writing response: %v", err)
-			}
-			return nil
-		}
-	} else {
-		if response.EvalResponse.Error != nil {
-			responseValue = response.EvalResponse.Error.Error()
-		} else {
-			responseValue = response.EvalResponse.Result
+	// Large array replies are sent in fragments as they are rendered
+	if response.EvalResponse.Error == nil && clientio.Streamable(response.EvalResponse.Result) {
+		if err := s.connections.stream(conn, response.EvalResponse.Result); err != nil {
+			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
+			return fmt.Errorf("error writing response: %v", err)
 		}
+		return nil
 	}
 
-	// Create websocket response
-	wsResponse := ResponseParser(responseValue)
+	// Create websocket response, the errors being replied with their code
+	reply := response.EvalResponse.Reply()
+	var wsResponse interface{} = reply.JSONValue()
+	if reply.Kind == clientio.ReplyError {
+		wsResponse = WSError{Error: reply.Str, Code: reply.Code}
+	}
 	respBytes, err := json.Marshal(wsResponse)
 	if err != nil {
 		slog.Debug("Error marshaling json", "error", err)
-		if err := WriteResponseWithRetries(conn, []byte("error: marshaling json"), maxRetries); err != nil {
+		if err := s.connections.write(conn, []byte("error: marshaling json"), maxRetries); err != nil {
 			slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 			return fmt.Errorf("error writing response: %v", err)
 		}
@@ -329,7 +595,7 @@ func (s *WebsocketServer) processResponse(conn *websocket.Conn, diceDBCmd *cmd.D
 
 	// success
 	// Write response with retries for transient errors
-	if err := WriteResponseWithRetries(conn, respBytes, config.DiceConfig.WebSocket.MaxWriteResponseRetries); err != nil {
+	if err := s.connections.write(conn, respBytes, config.DiceConfig().WebSocket.MaxWriteResponseRetries); err != nil {
 		slog.Debug(fmt.Sprintf("Error writing message: %v", err))
 		return fmt.Errorf("error writing response: %v", err)
 	}
@@ -338,17 +604,22 @@ func (s *WebsocketServer) processResponse(conn *websocket.Conn, diceDBCmd *cmd.D
 }
 
 func WriteResponseWithRetries(conn *websocket.Conn, text []byte, maxRetries int) error {
+	var err error
 	for attempts := 0; attempts < maxRetries; attempts++ {
 		// Set a write deadline
-		if err := conn.SetWriteDeadline(time.Now().Add(config.DiceConfig.WebSocket.WriteResponseTimeout)); err != nil {
+		if err := conn.SetWriteDeadline(time.Now().Add(config.DiceConfig().WebSocket.WriteResponseTimeout)); err != nil {
 			slog.Error(fmt.Sprintf("Error setting write deadline: %v", err))
 			return err
 		}
 
 		// Attempt to write message
-		err := conn.WriteMessage(websocket.TextMessage, text)
+		if arg, fail := failpoint.Eval(failpoint.SocketWriteError); fail {
+			err = failpoint.WriteError(arg)
+		} else {
+			err = conn.WriteMessage(websocket.TextMessage, text)
+		}
 		if err == nil {
-			break // Exit loop if write succeeds
+			return nil
 		}
 
 		// Handle network errors
@@ -357,37 +628,31 @@ func WriteResponseWithRetries(conn *websocket.Conn, text []byte, maxRetries int)
 			return fmt.Errorf("error writing message: %w", err)
 		}
 
-		opErr, ok := netErr.Err.(*os.SyscallError)
-		if !ok {
-			return fmt.Errorf("network operation error: %w", err)
-		}
-
-		if opErr.Syscall != "write" {
-			return fmt.Errorf("unexpected syscall error: %w", err)
-		}
-
-		switch opErr.Err {
-		case syscall.EPIPE:
+		// The errno values differ between the platforms, the classifier matches the ones of the platform
+		switch kind := sockerr.Classify(err); {
+		case kind == sockerr.BrokenPipe:
 			return fmt.Errorf("broken pipe: %w", err)
-		case syscall.ECONNRESET:
+		case kind == sockerr.ConnReset:
 			return fmt.Errorf("connection reset by peer: %w", err)
-		case syscall.ENOBUFS:
+		case kind == sockerr.NoBuffers:
 			return fmt.Errorf("no buffer space available: %w", err)
-		case syscall.EAGAIN:
-			// Exponential backoff with jitter
-			backoffDuration := time.Duration(attempts+1)*100*time.Millisecond + time.Duration(rand.Intn(50))*time.Millisecond
+		case !kind.Transient():
+			return fmt.Errorf("network operation error: %w", err)
+		}
 
-			slog.Warn(fmt.Sprintf(
-				"Temporary issue (EAGAIN) on attempt %d. Retrying in %v...",
-				attempts+1, backoffDuration,
-			))
+		// Exponential backoff with jitter
+		backoffDuration := time.Duration(attempts+1)*100*time.Millisecond + time.Duration(rand.Intn(50))*time.Millisecond
 
-			time.Sleep(backoffDuration)
-			continue
-		default:
-			return fmt.Errorf("write error: %w", err)
-		}
+		slog.Warn(fmt.Sprintf(
+			"Temporary issue (would block) on attempt %d. Retrying in %v...",
+			attempts+1, backoffDuration,
+		))
+
+		time.Sleep(backoffDuration)
 	}
 
+	if err != nil {
+		return fmt.Errorf("max retries reached: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,96 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"errors"
+	"testing"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/iothread"
+	"github.com/dicedb/dice/internal/shutdown"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandFilter(t *testing.T) {
+	tests := []struct {
+		name             string
+		allowed          []string
+		deniedCategories []string
+		command          string
+		expected         bool
+	}{
+		{name: "no restrictions", command: "FLUSHDB", expected: true},
+		{name: "allowed command", allowed: []string{"get", " Q.WATCH "}, command: "Q.WATCH", expected: true},
+		{name: "command missing from allowlist", allowed: []string{"GET"}, command: "SET", expected: false},
+		{name: "empty allowlist entries ignored", allowed: []string{""}, command: "SET", expected: true},
+		{name: "denied category", deniedCategories: []string{"write", "admin"}, command: "SET", expected: false},
+		{name: "category not denied", deniedCategories: []string{"write", "admin"}, command: "GET", expected: true},
+		{name: "unknown category ignored", deniedCategories: []string{"everything"}, command: "SET", expected: true},
+		{name: "allowed but denied by category", allowed: []string{"GET", "DEL"}, deniedCategories: []string{"write"}, command: "DEL", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCommandFilter(tt.allowed, tt.deniedCategories)
+			assert.Equal(t, tt.expected, f.Allows(tt.command))
+		})
+	}
+}
+
+func TestCommandFiltersByUser(t *testing.T) {
+	filters := newCommandFilters([]string{"GET"}, []string{"alice:GET|SET", " bob:DEL ", "malformed"}, []string{"admin"})
+
+	alice, bob := filters.forUser("alice"), filters.forUser("bob")
+	assert.True(t, alice.Allows("SET"))
+	assert.False(t, alice.Allows("DEL"))
+	assert.True(t, bob.Allows("DEL"))
+	assert.False(t, bob.Allows("SET"))
+
+	// The other users are filtered by the config, the denied categories applying to every user
+	for _, filter := range []*commandFilter{filters.fallback, filters.forUser("carol"), filters.forUser("malformed")} {
+		assert.True(t, filter.Allows("GET"))
+		assert.False(t, filter.Allows("SET"))
+	}
+	assert.False(t, newCommandFilters(nil, []string{"alice:FLUSHDB"}, []string{"admin"}).forUser("alice").Allows("FLUSHDB"))
+}
+
+func TestCloseFrameFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "closed by the client", err: nil, expected: websocket.CloseNormalClosure},
+		{name: "server shutdown", err: errServerShuttingDown, expected: websocket.CloseGoingAway},
+		{name: "shutdown command", err: &shutdown.Request{Mode: shutdown.NoSave}, expected: websocket.CloseGoingAway},
+		{name: "protected mode", err: diceerrors.ErrProtectedMode, expected: websocket.ClosePolicyViolation},
+		{name: "too many clients", err: iothread.ErrMaxClientsReached, expected: websocket.CloseTryAgainLater},
+		{name: "write failure", err: errors.New("broken pipe"), expected: websocket.CloseInternalServerErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := closeFrameFor(tt.err)
+			assert.Equal(t, tt.expected, frame.code)
+			assert.NotEmpty(t, frame.reason)
+			// Control frames carry at most 125 bytes, two of which hold the code
+			assert.LessOrEqual(t, len(frame.reason), 123)
+		})
+	}
+}
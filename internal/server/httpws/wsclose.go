@@ -0,0 +1,161 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/iothread"
+	"github.com/gorilla/websocket"
+)
+
+var errServerShuttingDown = errors.New("server shutting down")
+
+// closeFrame is the code and reason of the frame closing a WebSocket connection. The code lets
+// clients tell a connection they may re-establish right away from one they should back off from
+// or give up on.
+type closeFrame struct {
+	code   int
+	reason string
+}
+
+// closeFrameFor maps the reason the server terminates a connection onto its close frame, nil
+// standing for a connection closed by the client.
+func closeFrameFor(err error) closeFrame {
+	switch {
+	case err == nil:
+		return closeFrame{websocket.CloseNormalClosure, "normal closure"}
+	case errors.Is(err, errServerShuttingDown), errors.Is(err, diceerrors.ErrAborted):
+		return closeFrame{websocket.CloseGoingAway, "server shutting down"}
+	case errors.Is(err, diceerrors.ErrProtectedMode):
+		return closeFrame{websocket.ClosePolicyViolation, "protected mode, connect from the loopback interface or set a password"}
+	case errors.Is(err, iothread.ErrMaxClientsReached):
+		return closeFrame{websocket.CloseTryAgainLater, "max number of clients reached"}
+	case errors.Is(err, diceerrors.ErrOverloaded):
+		return closeFrame{websocket.CloseTryAgainLater, "server overloaded"}
+	default:
+		return closeFrame{websocket.CloseInternalServerErr, "internal server error"}
+	}
+}
+
+// wsConnections tracks the open connections so that they are told the server is going away on shutdown.
+// It also serializes the writes to every connection, as replies and watch pushes are written from
+// different goroutines.
+type wsConnections struct {
+	mu    sync.Mutex
+	conns map[*websocket.Conn]*wsConnState
+}
+
+type wsConnState struct {
+	closeOnce sync.Once
+	writeMu   sync.Mutex
+}
+
+func newWSConnections() *wsConnections {
+	return &wsConnections{conns: make(map[*websocket.Conn]*wsConnState)}
+}
+
+// add tracks the connection, refusing it once performance.max_clients connections are open.
+func (c *wsConnections) add(conn *websocket.Conn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if maxClients := config.DiceConfig().Performance.MaxClients; maxClients > 0 && len(c.conns) >= int(maxClients) {
+		return iothread.ErrMaxClientsReached
+	}
+	c.conns[conn] = &wsConnState{}
+	return nil
+}
+
+func (c *wsConnections) remove(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.conns, conn)
+}
+
+// write writes a text message to the connection, retrying transient errors.
+func (c *wsConnections) write(conn *websocket.Conn, text []byte, maxRetries int) error {
+	c.mu.Lock()
+	state, ok := c.conns[conn]
+	c.mu.Unlock()
+
+	if ok {
+		state.writeMu.Lock()
+		defer state.writeMu.Unlock()
+	}
+	return WriteResponseWithRetries(conn, text, maxRetries)
+}
+
+// stream writes a large array reply to the connection as a message sent in fragments.
+func (c *wsConnections) stream(conn *websocket.Conn, value interface{}) error {
+	c.mu.Lock()
+	state, ok := c.conns[conn]
+	c.mu.Unlock()
+
+	if ok {
+		state.writeMu.Lock()
+		defer state.writeMu.Unlock()
+	}
+	return streamWSMessage(conn, value)
+}
+
+// close sends the close frame matching err and closes the connection. Only the first
+// call for a tracked connection has an effect.
+func (c *wsConnections) close(conn *websocket.Conn, err error) {
+	c.mu.Lock()
+	state, ok := c.conns[conn]
+	c.mu.Unlock()
+
+	if !ok {
+		closeConnection(conn, err)
+		return
+	}
+	state.closeOnce.Do(func() { closeConnection(conn, err) })
+}
+
+// closeAll closes every open connection with the close frame matching err.
+func (c *wsConnections) closeAll(err error) {
+	c.mu.Lock()
+	conns := make([]*websocket.Conn, 0, len(c.conns))
+	for conn := range c.conns {
+		conns = append(conns, conn)
+	}
+	c.mu.Unlock()
+
+	for _, conn := range conns {
+		c.close(conn, err)
+	}
+}
+
+func closeConnection(conn *websocket.Conn, err error) {
+	frame := closeFrameFor(err)
+	deadline := time.Now().Add(config.DiceConfig().WebSocket.WriteResponseTimeout)
+
+	// A close frame received from the client was already answered by the connection
+	writeErr := conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(frame.code, frame.reason), deadline)
+	if writeErr != nil && !errors.Is(writeErr, websocket.ErrCloseSent) {
+		slog.Debug("Error during closing handshake", slog.Int("code", frame.code), slog.Any("error", writeErr))
+	}
+	if err := conn.Close(); err != nil {
+		slog.Debug("Error closing websocket connection", slog.Any("error", err))
+	}
+}
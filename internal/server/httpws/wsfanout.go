@@ -0,0 +1,207 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/comm"
+	"github.com/dicedb/dice/internal/stats"
+	"github.com/gorilla/websocket"
+)
+
+// wsOutbox queues the watch pushes of a connection until a fan-out worker writes them.
+type wsOutbox struct {
+	conn        *websocket.Conn
+	mu          sync.Mutex
+	frames      [][]byte
+	scheduled   bool     // scheduled is true while the outbox is queued for, or being drained by, a worker
+	closed      bool     // closed is true once the connection is gone or a write failed
+	subscribers []uint32 // subscribers are the client identifiers of the watches of the connection
+}
+
+// wsFanout writes the watch pushes of the WebSocket server with a bounded pool of workers. Every
+// connection has its own bounded queue, so that a slow client only delays, and drops, its own pushes.
+type wsFanout struct {
+	connections *wsConnections
+	responses   chan comm.QwatchResponse
+	ready       chan *wsOutbox
+
+	mu          sync.Mutex
+	subscribers map[uint32]*wsOutbox          // subscribers maps the client identifier of a watch to its connection's outbox
+	outboxes    map[*websocket.Conn]*wsOutbox // outboxes maps every connection with a watch to its outbox
+}
+
+func newWSFanout(connections *wsConnections, responses chan comm.QwatchResponse) *wsFanout {
+	return &wsFanout{
+		connections: connections,
+		responses:   responses,
+		ready:       make(chan *wsOutbox, config.DiceConfig().WebSocket.FanoutWorkers),
+		subscribers: make(map[uint32]*wsOutbox),
+		outboxes:    make(map[*websocket.Conn]*wsOutbox),
+	}
+}
+
+// Run dispatches the watch responses to the outboxes of their subscribers until ctx is done.
+func (f *wsFanout) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < config.DiceConfig().WebSocket.FanoutWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.work(ctx)
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case resp := <-f.responses:
+			f.dispatch(ctx, resp)
+		}
+	}
+}
+
+// Subscribe routes the responses of the watch identified by clientIdentifierID to the connection.
+func (f *wsFanout) Subscribe(clientIdentifierID uint32, conn *websocket.Conn) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	outbox, ok := f.outboxes[conn]
+	if !ok {
+		outbox = &wsOutbox{conn: conn}
+		f.outboxes[conn] = outbox
+	}
+	outbox.subscribers = append(outbox.subscribers, clientIdentifierID)
+	f.subscribers[clientIdentifierID] = outbox
+}
+
+// Unsubscribe removes every watch of the connection, discarding the pushes not written yet.
+func (f *wsFanout) Unsubscribe(conn *websocket.Conn) {
+	f.mu.Lock()
+	outbox, ok := f.outboxes[conn]
+	if ok {
+		delete(f.outboxes, conn)
+		for _, id := range outbox.subscribers {
+			delete(f.subscribers, id)
+		}
+	}
+	f.mu.Unlock()
+
+	if ok {
+		outbox.mu.Lock()
+		outbox.closed = true
+		outbox.frames = nil
+		outbox.mu.Unlock()
+	}
+}
+
+func (f *wsFanout) dispatch(ctx context.Context, resp comm.QwatchResponse) {
+	f.mu.Lock()
+	outbox, ok := f.subscribers[resp.ClientIdentifierID]
+	f.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	payload := watchPushPayload(resp)
+
+	outbox.mu.Lock()
+	if outbox.closed {
+		outbox.mu.Unlock()
+		return
+	}
+	if len(outbox.frames) >= config.DiceConfig().WebSocket.PushQueueSize {
+		outbox.mu.Unlock()
+		slog.Debug("Dropping watch push to slow websocket client", slog.Any("clientIdentifierID", resp.ClientIdentifierID))
+		stats.WatchUpdateDropped()
+		return
+	}
+	outbox.frames = append(outbox.frames, payload)
+	schedule := !outbox.scheduled
+	outbox.scheduled = true
+	outbox.mu.Unlock()
+
+	if schedule {
+		select {
+		case f.ready <- outbox:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// work writes the queued pushes of the outboxes handed to the worker.
+func (f *wsFanout) work(ctx context.Context) {
+	maxRetries := config.DiceConfig().WebSocket.MaxWriteResponseRetries
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case outbox := <-f.ready:
+			f.drain(outbox, maxRetries)
+		}
+	}
+}
+
+func (f *wsFanout) drain(outbox *wsOutbox, maxRetries int) {
+	for {
+		outbox.mu.Lock()
+		frames := outbox.frames
+		outbox.frames = nil
+		if len(frames) == 0 || outbox.closed {
+			outbox.scheduled = false
+			outbox.mu.Unlock()
+			return
+		}
+		outbox.mu.Unlock()
+
+		for i, frame := range frames {
+			if err := f.connections.write(outbox.conn, frame, maxRetries); err != nil {
+				slog.Debug("Error writing watch push, dropping the pushes of the connection", slog.Any("error", err))
+				for range frames[i:] {
+					stats.WatchUpdateDropped()
+				}
+				f.Unsubscribe(outbox.conn)
+				break
+			}
+		}
+	}
+}
+
+// watchPushPayload returns the JSON payload of the push of a watch response. The payload of an update
+// is shared by the responses of all of its subscribers, so it is only marshaled for the first one.
+func watchPushPayload(resp comm.QwatchResponse) []byte {
+	payload := resp.Payload
+	if resp.Error != nil {
+		payload = comm.NewWatchPayload(clientio.ErrorReply(resp.Error))
+	} else if payload == nil {
+		payload = comm.NewWatchPayload(clientio.NewReply(resp.Result))
+	}
+
+	encoded, err := payload.JSON()
+	if err != nil {
+		slog.Debug("Error encoding watch push", slog.Any("error", err))
+		return []byte("error: 500 Internal Server Error")
+	}
+	return encoded
+}
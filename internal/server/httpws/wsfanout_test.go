@@ -0,0 +1,146 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/comm"
+	"github.com/dicedb/dice/internal/stats"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newWSPair returns the server and client ends of a WebSocket connection.
+func newWSPair(t *testing.T) (server, client *websocket.Conn) {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(srv.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	server = <-conns
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return server, client
+}
+
+func withFanoutConfig(t *testing.T, workers, queueSize int) {
+	workersBefore, queueSizeBefore := config.DiceConfig().WebSocket.FanoutWorkers, config.DiceConfig().WebSocket.PushQueueSize
+	retriesBefore, timeoutBefore := config.DiceConfig().WebSocket.MaxWriteResponseRetries, config.DiceConfig().WebSocket.WriteResponseTimeout
+	bufferBefore := config.DiceConfig().Network.IOBufferLength
+	config.DiceConfig().Network.IOBufferLength = 512
+	config.DiceConfig().WebSocket.FanoutWorkers = workers
+	config.DiceConfig().WebSocket.PushQueueSize = queueSize
+	config.DiceConfig().WebSocket.MaxWriteResponseRetries = 3
+	config.DiceConfig().WebSocket.WriteResponseTimeout = time.Second
+	t.Cleanup(func() {
+		config.DiceConfig().WebSocket.FanoutWorkers = workersBefore
+		config.DiceConfig().WebSocket.PushQueueSize = queueSizeBefore
+		config.DiceConfig().WebSocket.MaxWriteResponseRetries = retriesBefore
+		config.DiceConfig().WebSocket.WriteResponseTimeout = timeoutBefore
+		config.DiceConfig().Network.IOBufferLength = bufferBefore
+	})
+}
+
+func TestFanoutWritesSharedPayload(t *testing.T) {
+	withFanoutConfig(t, 2, 8)
+
+	responses := make(chan comm.QwatchResponse)
+	fanout := newWSFanout(newWSConnections(), responses)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fanout.Run(ctx)
+
+	server1, client1 := newWSPair(t)
+	server2, client2 := newWSPair(t)
+	fanout.Subscribe(1, server1)
+	fanout.Subscribe(2, server2)
+
+	result := "value"
+	responses <- comm.QwatchResponse{ClientIdentifierID: 1, Result: result}
+	responses <- comm.QwatchResponse{ClientIdentifierID: 2, Result: result}
+	responses <- comm.QwatchResponse{ClientIdentifierID: 3, Result: result} // no subscriber
+
+	for _, client := range []*websocket.Conn{client1, client2} {
+		assert.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+		_, msg, err := client.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, `"value"`, string(msg))
+	}
+}
+
+func TestWatchPushPayloadEncodedOnce(t *testing.T) {
+	withFanoutConfig(t, 1, 1)
+
+	responses := comm.NewQwatchResponses("value", 1, 2)
+	first := watchPushPayload(responses[0])
+	second := watchPushPayload(responses[1])
+	assert.Equal(t, `"value"`, string(first))
+	assert.Same(t, &first[0], &second[0], "the payload of an update must be encoded once")
+
+	// Responses built without a shared payload are encoded on their own
+	assert.Equal(t, `"other"`, string(watchPushPayload(comm.QwatchResponse{ClientIdentifierID: 1, Result: "other"})))
+	assert.Equal(t, `"ERR failed"`, string(watchPushPayload(comm.QwatchResponse{ClientIdentifierID: 1, Error: errors.New("ERR failed")})))
+}
+
+func TestFanoutDropsPushesOfFullQueue(t *testing.T) {
+	withFanoutConfig(t, 1, 2)
+
+	fanout := newWSFanout(newWSConnections(), nil)
+	server, _ := newWSPair(t)
+	fanout.Subscribe(1, server)
+
+	// No worker is running, so the pushes stay queued
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dropped := stats.Get().Watch.DroppedUpdates
+	for i := 0; i < 3; i++ {
+		fanout.dispatch(ctx, comm.QwatchResponse{ClientIdentifierID: 1, Result: int64(1)})
+	}
+
+	outbox := fanout.subscribers[1]
+	assert.Len(t, outbox.frames, 2)
+	assert.Equal(t, dropped+1, stats.Get().Watch.DroppedUpdates)
+
+	fanout.Unsubscribe(server)
+	assert.Empty(t, fanout.subscribers)
+	assert.Empty(t, fanout.outboxes)
+	assert.True(t, outbox.closed)
+	assert.Empty(t, outbox.frames)
+}
@@ -0,0 +1,203 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// The types of the columns of a schema
+const (
+	columnString = "string"
+	columnInt    = "int"
+	columnFloat  = "float"
+	columnBool   = "bool"
+)
+
+// WSColumn is a column of the schema declared by a watch, e.g. age:int.
+type WSColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// wsSchema is the shape a watch expects the result of its command to have, declared by ending the watch
+// command with SCHEMA and its columns, e.g. ZRANGE.WATCH board 0 -1 WITHSCORES SCHEMA alice:int bob:int.
+// The pushes of the watch then hold the result as an object with a value of the type of each column, and a
+// result that does not match the schema is pushed as a SCHEMA error.
+type wsSchema []WSColumn
+
+// splitSchema separates the schema declared at the end of the arguments of a watch command. The arguments
+// following the last SCHEMA must all be columns, otherwise SCHEMA is an argument of the command.
+func splitSchema(args []string) ([]string, wsSchema, error) {
+	i := len(args) - 1
+	for i >= 0 && !strings.EqualFold(args[i], "SCHEMA") {
+		i--
+	}
+	if i < 0 || i == len(args)-1 {
+		return args, nil, nil
+	}
+	for _, column := range args[i+1:] {
+		if !strings.Contains(column, ":") {
+			return args, nil, nil
+		}
+	}
+
+	schema := make(wsSchema, 0, len(args)-i-1)
+	seen := make(map[string]bool, cap(schema))
+	for _, column := range args[i+1:] {
+		name, typ, _ := strings.Cut(column, ":")
+		typ = strings.ToLower(typ)
+		switch typ {
+		case columnString, columnInt, columnFloat, columnBool:
+		default:
+			return nil, nil, diceerrors.New(diceerrors.CodeSyntax, fmt.Sprintf("invalid type of column '%s', expected string, int, float or bool", name))
+		}
+		if name == "" || seen[name] {
+			return nil, nil, diceerrors.New(diceerrors.CodeSyntax, fmt.Sprintf("invalid column '%s' in the schema", column))
+		}
+		seen[name] = true
+		schema = append(schema, WSColumn{Name: name, Type: typ})
+	}
+	return args[:i], schema, nil
+}
+
+// apply returns the result as an object with a value of the type of each column, null for the columns the
+// result does not have. A single value is the value of a schema of one column, an array of field/value pairs,
+// as replied by ZRANGE WITHSCORES, holds the columns by name, and any other array holds one value per column
+// in order. A null result, e.g. of a key that does not exist, stays null.
+func (schema wsSchema) apply(result interface{}) (interface{}, error) {
+	if result == nil {
+		return nil, nil
+	}
+
+	values, ok := result.([]interface{})
+	if !ok {
+		if len(schema) != 1 {
+			return nil, schemaMismatch("expected %d columns, got a single value", len(schema))
+		}
+		values = []interface{}{result}
+	}
+
+	row := make(map[string]interface{}, len(schema))
+	switch {
+	case schema.isRecord(values):
+		for _, column := range schema {
+			row[column.Name] = nil
+		}
+		for i := 0; i < len(values); i += 2 {
+			column := schema.column(values[i].(string))
+			v, err := column.convert(values[i+1])
+			if err != nil {
+				return nil, err
+			}
+			row[column.Name] = v
+		}
+	case len(values) == len(schema):
+		for i := range schema {
+			v, err := schema[i].convert(values[i])
+			if err != nil {
+				return nil, err
+			}
+			row[schema[i].Name] = v
+		}
+	default:
+		return nil, schemaMismatch("expected %d columns, got %d values", len(schema), len(values))
+	}
+	return row, nil
+}
+
+// isRecord reports whether the values are field/value pairs whose fields are all columns of the schema
+func (schema wsSchema) isRecord(values []interface{}) bool {
+	if len(values)%2 != 0 || len(values) == 0 {
+		return false
+	}
+	for i := 0; i < len(values); i += 2 {
+		field, ok := values[i].(string)
+		if !ok || schema.column(field) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (schema wsSchema) column(name string) *WSColumn {
+	for i := range schema {
+		if schema[i].Name == name {
+			return &schema[i]
+		}
+	}
+	return nil
+}
+
+// convert returns the value as the type of the column. The values of the hashes and the strings being stored
+// as strings, a string holding a number matches an int or a float column.
+func (c *WSColumn) convert(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch c.Type {
+	case columnString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+	case columnInt:
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case string:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	case columnFloat:
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			if !math.IsInf(v, 0) && !math.IsNaN(v) {
+				return v, nil
+			}
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+				return f, nil
+			}
+		}
+	case columnBool:
+		switch value {
+		case int64(0), "0", "false":
+			return false, nil
+		case int64(1), "1", "true":
+			return true, nil
+		}
+	}
+	return nil, schemaMismatch("the value of column '%s' is not of type %s", c.Name, c.Type)
+}
+
+func schemaMismatch(format string, args ...interface{}) error {
+	return diceerrors.New(diceerrors.CodeSchema, "the result does not match the schema, "+fmt.Sprintf(format, args...))
+}
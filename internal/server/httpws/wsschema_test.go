@@ -0,0 +1,99 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/inproc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitSchema(t *testing.T) {
+	args, schema, err := splitSchema([]string{"user:1", "SCHEMA", "name:string", "age:INT"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, args)
+	assert.Equal(t, wsSchema{{Name: "name", Type: "string"}, {Name: "age", Type: "int"}}, schema)
+
+	// SCHEMA not followed by columns is an argument of the command
+	for _, in := range [][]string{{"schema"}, {"k", "schema", "v"}, {"k"}} {
+		args, schema, err = splitSchema(in)
+		require.NoError(t, err)
+		assert.Equal(t, in, args)
+		assert.Nil(t, schema)
+	}
+
+	for _, in := range [][]string{{"k", "SCHEMA", "age:integer"}, {"k", "SCHEMA", "a:int", "a:string"}, {"k", "SCHEMA", ":int"}} {
+		_, _, err = splitSchema(in)
+		assert.Equal(t, diceerrors.CodeSyntax, diceerrors.CodeOf(err), in)
+	}
+}
+
+func TestSchemaApply(t *testing.T) {
+	schema := wsSchema{{Name: "name", Type: "string"}, {Name: "age", Type: "int"}, {Name: "score", Type: "float"}, {Name: "admin", Type: "bool"}}
+
+	row, err := schema.apply([]interface{}{"age", "42", "name", "alice", "admin", "1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "alice", "age": int64(42), "score": nil, "admin": true}, row)
+
+	row, err = schema.apply([]interface{}{"bob", int64(7), "1.5", nil})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "bob", "age": int64(7), "score": 1.5, "admin": nil}, row)
+
+	row, err = schema.apply(nil)
+	require.NoError(t, err)
+	assert.Nil(t, row)
+
+	row, err = wsSchema{{Name: "count", Type: "int"}}.apply("12")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"count": int64(12)}, row)
+
+	for _, result := range []interface{}{
+		"alice",
+		[]interface{}{"name", "alice", "age", "old"},
+		[]interface{}{"name", "alice", "email", "a@b.c"},
+		[]interface{}{"alice", int64(7)},
+	} {
+		_, err = schema.apply(result)
+		assert.Equal(t, diceerrors.CodeSchema, diceerrors.CodeOf(err), result)
+	}
+}
+
+func TestWSPushPayloadSchema(t *testing.T) {
+	schema := wsSchema{{Name: "count", Type: "int"}}
+
+	var push WSPush
+	require.NoError(t, json.Unmarshal(wsPushPayload("1", inproc.Update{Result: "3"}, schema, true), &push))
+	assert.Equal(t, WSPush{Watch: "1", Data: map[string]interface{}{"count": float64(3)}, Schema: []WSColumn{{Name: "count", Type: "int"}}}, push)
+
+	push = WSPush{}
+	require.NoError(t, json.Unmarshal(wsPushPayload("1", inproc.Update{Seq: 1, Result: "three"}, schema, false), &push))
+	assert.Equal(t, "1", push.Watch)
+	assert.Nil(t, push.Data)
+	assert.Nil(t, push.Schema)
+	assert.Equal(t, diceerrors.CodeSchema, push.Code)
+	assert.Equal(t, "ERR the result does not match the schema, the value of column 'count' is not of type int", push.Error)
+
+	// The errors of the command are pushed as they are
+	push = WSPush{}
+	require.NoError(t, json.Unmarshal(wsPushPayload("1", inproc.Update{Seq: 2, Err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")}, schema, false), &push))
+	assert.Equal(t, diceerrors.CodeWrongType, push.Code)
+}
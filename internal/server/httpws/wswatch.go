@@ -0,0 +1,237 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package httpws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/inproc"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	errWatchUnavailable = diceerrors.New(diceerrors.CodeGeneric, "watching commands is not available on this server")
+	errNoSuchWatch      = diceerrors.New(diceerrors.CodeGeneric, "no such watch")
+)
+
+// WSPush is the message pushing an update of a command watched over WebSocket, e.g. with GET.WATCH k. The
+// reply to the watch command is its first push, with Seq 0 and the current result of the command. A gap
+// between the sequence numbers of two pushes means that updates were dropped, as the client was too slow.
+type WSPush struct {
+	// Watch identifies the watch on the connection, X.UNWATCH <watch> ending it
+	Watch string      `json:"watch"`
+	Seq   uint64      `json:"seq"`
+	Data  interface{} `json:"data"`
+	// Error and Code are set when the watched command replied with an error, Data being null
+	Error string          `json:"error,omitempty"`
+	Code  diceerrors.Code `json:"code,omitempty"`
+	// Schema is the schema declared by the watch, in its first push only
+	Schema []WSColumn `json:"schema,omitempty"`
+}
+
+// isWatchCommand reports whether the command subscribes to the result of a command, e.g. GET.WATCH
+func isWatchCommand(command string) bool {
+	return strings.HasSuffix(command, ".WATCH") && command != Qwatch
+}
+
+// isUnwatchCommand reports whether the command ends a watch, e.g. GET.UNWATCH
+func isUnwatchCommand(command string) bool {
+	return strings.HasSuffix(command, ".UNWATCH") && command != Qunwatch
+}
+
+// wsWatch is a command watched by a WebSocket connection, on an inproc session of its own.
+type wsWatch struct {
+	sub     *inproc.Subscription
+	session *inproc.Session
+}
+
+// end closes the session of the watch, which unsubscribes the command. The session is closed rather than the
+// command unwatched, as the watch manager may be stopped already when the server is stopping.
+func (w *wsWatch) end() {
+	_ = w.session.Close()
+}
+
+// wsWatches are the commands watched by a WebSocket connection, by the id of their watch.
+type wsWatches struct {
+	mu      sync.Mutex
+	next    uint64
+	watches map[string]*wsWatch
+	wg      sync.WaitGroup // wg waits for the goroutines forwarding the updates
+}
+
+func newWSWatches() *wsWatches {
+	return &wsWatches{watches: make(map[string]*wsWatch)}
+}
+
+func (w *wsWatches) add(watch *wsWatch) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.next++
+	id := strconv.FormatUint(w.next, 10)
+	w.watches[id] = watch
+	return id
+}
+
+func (w *wsWatches) remove(id string) *wsWatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	watch := w.watches[id]
+	delete(w.watches, id)
+	return watch
+}
+
+// closeAll ends every watch of the connection and waits for their updates to be forwarded.
+func (w *wsWatches) closeAll() {
+	w.mu.Lock()
+	watches := w.watches
+	w.watches = make(map[string]*wsWatch)
+	w.mu.Unlock()
+
+	for _, watch := range watches {
+		watch.end()
+	}
+	w.wg.Wait()
+}
+
+// handleWatch subscribes the connection to the result of the command, replying with the first push. The
+// following pushes are written as the result of the command changes, until the command is unwatched or the
+// connection closed.
+func (s *WebsocketServer) handleWatch(ctx context.Context, conn *websocket.Conn, watches *wsWatches, client string, diceDBCmd *cmd.DiceDBCmd) error {
+	maxRetries := config.DiceConfig().WebSocket.MaxWriteResponseRetries
+
+	args, schema, err := splitSchema(diceDBCmd.Args)
+	var watch *wsWatch
+	if err == nil {
+		watch, err = s.watch(ctx, strings.TrimSuffix(diceDBCmd.Cmd, ".WATCH"), args)
+	}
+	audit.Log(client, "", diceDBCmd, err)
+	if err != nil {
+		return s.writeWSError(conn, err)
+	}
+
+	id := watches.add(watch)
+	if err := s.connections.write(conn, wsPushPayload(id, <-watch.sub.C, schema, true), maxRetries); err != nil {
+		watches.remove(id).end()
+		return fmt.Errorf("error writing response: %v", err)
+	}
+
+	watches.wg.Add(1)
+	go func() {
+		defer watches.wg.Done()
+		for u := range watch.sub.C {
+			if err := s.connections.write(conn, wsPushPayload(id, u, schema, false), maxRetries); err != nil {
+				slog.Debug("Error writing watch push, ending the watch", slog.String("watch", id), slog.Any("error", err))
+				if watch := watches.remove(id); watch != nil {
+					watch.end()
+				}
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// watch subscribes to the command on a session of its own. The WebSocket frontend does not authenticate its
+// clients, the session is authenticated as the default user.
+func (s *WebsocketServer) watch(ctx context.Context, command string, args []string) (*wsWatch, error) {
+	if s.host == nil {
+		return nil, errWatchUnavailable
+	}
+	session, err := s.host.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	if password := config.DiceConfig().Auth.Password; password != "" {
+		if _, err := session.Execute(ctx, "AUTH", config.DiceConfig().Auth.UserName, password); err != nil {
+			_ = session.Close()
+			return nil, err
+		}
+	}
+	sub, err := session.Watch(ctx, command, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &wsWatch{sub: sub, session: session}, nil
+}
+
+// handleUnwatch ends the watch identified by the argument of the command, e.g. GET.UNWATCH 1.
+func (s *WebsocketServer) handleUnwatch(conn *websocket.Conn, watches *wsWatches, client string, diceDBCmd *cmd.DiceDBCmd) error {
+	if len(diceDBCmd.Args) != 1 {
+		return s.writeWSError(conn, diceerrors.ErrWrongArgumentCount(strings.ToLower(diceDBCmd.Cmd)))
+	}
+	watch := watches.remove(diceDBCmd.Args[0])
+	if watch == nil {
+		audit.Log(client, "", diceDBCmd, errNoSuchWatch)
+		return s.writeWSError(conn, errNoSuchWatch)
+	}
+	watch.end()
+	audit.Log(client, "", diceDBCmd, nil)
+
+	if err := s.connections.write(conn, []byte(`"OK"`), config.DiceConfig().WebSocket.MaxWriteResponseRetries); err != nil {
+		return fmt.Errorf("error writing response: %v", err)
+	}
+	return nil
+}
+
+// writeWSError replies with the error and its code
+func (s *WebsocketServer) writeWSError(conn *websocket.Conn, err error) error {
+	var cmdErr inproc.Error
+	if errors.Is(err, inproc.ErrWatchDisabled) {
+		err = diceerrors.ErrGeneral(err.Error())
+	} else if errors.As(err, &cmdErr) {
+		err = errors.New(string(cmdErr))
+	}
+	payload, _ := json.Marshal(WSError{Error: err.Error(), Code: diceerrors.CodeOf(err)})
+	if err := s.connections.write(conn, payload, config.DiceConfig().WebSocket.MaxWriteResponseRetries); err != nil {
+		return fmt.Errorf("error writing response: %v", err)
+	}
+	return nil
+}
+
+// wsPushPayload returns the JSON payload of the push of an update of the watch, its result shaped by the
+// schema of the watch if it declared one. The first push of the watch holds the schema.
+func wsPushPayload(id string, u inproc.Update, schema wsSchema, first bool) []byte {
+	push := WSPush{Watch: id, Seq: u.Seq, Data: u.Result}
+	if first {
+		push.Schema = schema
+	}
+	if u.Err == nil && schema != nil {
+		push.Data, u.Err = schema.apply(u.Result)
+	}
+	if u.Err != nil {
+		push.Data = nil
+		push.Error = u.Err.Error()
+		push.Code = diceerrors.CodeOf(u.Err)
+	}
+	payload, err := json.Marshal(push)
+	if err != nil {
+		slog.Debug("Error encoding watch push", slog.Any("error", err))
+		payload, _ = json.Marshal(WSPush{Watch: id, Seq: u.Seq, Error: diceerrors.ErrInternalServer.Error(), Code: diceerrors.CodeInternal})
+	}
+	return payload
+}
@@ -22,11 +22,13 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/dicedb/dice/internal/handover"
 	"github.com/dicedb/dice/internal/server/abstractserver"
 	"github.com/dicedb/dice/internal/wal"
 
@@ -34,11 +36,14 @@ import (
 	"github.com/dicedb/dice/internal/watchmanager"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/auth"
 	"github.com/dicedb/dice/internal/clientio/iohandler/netconn"
 	respparser "github.com/dicedb/dice/internal/clientio/requestparser/resp"
+	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/iothread"
 	"github.com/dicedb/dice/internal/ops"
 	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/stats"
 )
 
 var (
@@ -58,6 +63,9 @@ type Server struct {
 	abstractserver.AbstractServer
 	Host                     string
 	Port                     int
+	tlsPort                  int
+	unixSocket               string
+	unixSocketPerm           string
 	serverFD                 int
 	connBacklogSize          int
 	ioThreadManager          *iothread.Manager
@@ -66,13 +74,18 @@ type Server struct {
 	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription
 	globalErrorChan          chan error
 	wl                       wal.AbstractWAL
+	listening                chan struct{}
+	unbound                  atomic.Int32 // unbound is the number of listeners of the server not bound yet
 }
 
 func NewServer(shardManager *shard.ShardManager, ioThreadManager *iothread.Manager,
 	cmdWatchSubscriptionChan chan watchmanager.WatchSubscription, cmdWatchChan chan dstore.CmdWatchEvent, globalErrChan chan error, wl wal.AbstractWAL) *Server {
 	return &Server{
-		Host:                     config.DiceConfig.RespServer.Addr,
-		Port:                     config.DiceConfig.RespServer.Port,
+		Host:                     config.DiceConfig().RespServer.Addr,
+		Port:                     config.DiceConfig().RespServer.Port,
+		tlsPort:                  config.DiceConfig().TLS.Port,
+		unixSocket:               config.DiceConfig().RespServer.UnixSocket,
+		unixSocketPerm:           config.DiceConfig().RespServer.UnixSocketPerm,
 		connBacklogSize:          DefaultConnBacklogSize,
 		ioThreadManager:          ioThreadManager,
 		shardManager:             shardManager,
@@ -80,20 +93,30 @@ func NewServer(shardManager *shard.ShardManager, ioThreadManager *iothread.Manag
 		cmdWatchSubscriptionChan: cmdWatchSubscriptionChan,
 		globalErrorChan:          globalErrChan,
 		wl:                       wl,
+		listening:                make(chan struct{}),
 	}
 }
 
 func (s *Server) Run(ctx context.Context) (err error) {
+	s.unbound.Store(1)
+	if s.tlsPort > 0 {
+		s.unbound.Add(1)
+	}
+	if s.unixSocket != "" {
+		s.unbound.Add(1)
+	}
+
 	// BindAndListen the desired port to the server
 	if err = s.BindAndListen(); err != nil {
 		slog.Error("failed to bind server", slog.Any("error", err))
 		return err
 	}
+	s.bound()
 
 	defer s.ReleasePort()
 
 	// Start a go routine to accept connections
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 3)
 	wg := &sync.WaitGroup{}
 
 	if s.cmdWatchSubscriptionChan != nil {
@@ -112,6 +135,26 @@ func (s *Server) Run(ctx context.Context) (err error) {
 		}
 	}(wg)
 
+	if s.tlsPort > 0 {
+		wg.Add(1)
+		go func(wg *sync.WaitGroup) {
+			defer wg.Done()
+			if err := s.AcceptTLSConnectionRequests(ctx, wg); err != nil {
+				errChan <- fmt.Errorf("failed to accept tls connections %w", err)
+			}
+		}(wg)
+	}
+
+	if s.unixSocket != "" {
+		wg.Add(1)
+		go func(wg *sync.WaitGroup) {
+			defer wg.Done()
+			if err := s.AcceptUnixConnectionRequests(ctx, wg); err != nil {
+				errChan <- fmt.Errorf("failed to accept unix socket connections %w", err)
+			}
+		}(wg)
+	}
+
 	select {
 	case <-ctx.Done():
 		slog.Info("initiating shutdown")
@@ -127,10 +170,40 @@ func (s *Server) Run(ctx context.Context) (err error) {
 	return err
 }
 
+// Listening is closed once the server accepts connections on its port, its TLS port and its Unix socket.
+func (s *Server) Listening() <-chan struct{} {
+	return s.listening
+}
+
+// bound closes listening once every listener of the server is bound
+func (s *Server) bound() {
+	if s.unbound.Add(-1) == 0 {
+		close(s.listening)
+	}
+}
+
+// BindAndListen listens on the port of the server, or serves on the socket of the port handed over by the
+// previous process of the server.
 func (s *Server) BindAndListen() error {
+	serverFD, err := handover.ListenFD("tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.Port)), s.bind)
+	if err != nil {
+		return err
+	}
+	s.serverFD = serverFD
+	return nil
+}
+
+// bind creates the listening socket of the port. The socket, like the client connections accepted on it, is
+// closed on exec, so that a new process of the server started by a handover does not hold it.
+func (s *Server) bind() (int, error) {
+	syscall.ForkLock.RLock()
 	serverFD, socketErr := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if socketErr == nil {
+		syscall.CloseOnExec(serverFD)
+	}
+	syscall.ForkLock.RUnlock()
 	if socketErr != nil {
-		return fmt.Errorf("failed to create socket: %w", socketErr)
+		return -1, fmt.Errorf("failed to create socket: %w", socketErr)
 	}
 
 	// Close the socket on exit if an error occurs
@@ -147,16 +220,16 @@ func (s *Server) BindAndListen() error {
 	}()
 
 	if err = syscall.SetsockoptInt(serverFD, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); err != nil {
-		return fmt.Errorf("failed to set SO_REUSEADDR: %w", err)
+		return -1, fmt.Errorf("failed to set SO_REUSEADDR: %w", err)
 	}
 
 	if err = syscall.SetNonblock(serverFD, true); err != nil {
-		return fmt.Errorf("failed to set socket to non-blocking: %w", err)
+		return -1, fmt.Errorf("failed to set socket to non-blocking: %w", err)
 	}
 
 	ip4 := net.ParseIP(s.Host)
 	if ip4 == nil {
-		return ErrInvalidIPAddress
+		return -1, ErrInvalidIPAddress
 	}
 
 	sockAddr := &syscall.SockaddrInet4{
@@ -164,20 +237,19 @@ func (s *Server) BindAndListen() error {
 		Addr: [4]byte{ip4[0], ip4[1], ip4[2], ip4[3]},
 	}
 	if err = syscall.Bind(serverFD, sockAddr); err != nil {
-		return fmt.Errorf("failed to bind socket: %w", err)
+		return -1, fmt.Errorf("failed to bind socket: %w", err)
 	}
 
 	if err = syscall.Listen(serverFD, s.connBacklogSize); err != nil {
-		return fmt.Errorf("failed to listen on socket: %w", err)
+		return -1, fmt.Errorf("failed to listen on socket: %w", err)
 	}
 
-	s.serverFD = serverFD
-	return nil
+	return serverFD, nil
 }
 
 // ReleasePort closes the server socket.
 func (s *Server) ReleasePort() {
-	if err := syscall.Close(s.serverFD); err != nil {
+	if err := handover.CloseFD(s.serverFD); err != nil {
 		slog.Error("Failed to close server socket", slog.Any("error", err))
 	}
 }
@@ -191,7 +263,14 @@ func (s *Server) AcceptConnectionRequests(ctx context.Context, wg *sync.WaitGrou
 
 			return ctx.Err()
 		default:
-			clientFD, _, err := syscall.Accept(s.serverFD)
+			if handover.Draining() {
+				// The next process of the server accepts the connections, the ones of this process are served until it exits
+				<-ctx.Done()
+				slog.Info("no new connections will be accepted")
+				return ctx.Err()
+			}
+
+			clientFD, err := accept(s.serverFD)
 			if err != nil {
 				if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EWOULDBLOCK) {
 					continue // No more connections to accept at this time
@@ -207,16 +286,12 @@ func (s *Server) AcceptConnectionRequests(ctx context.Context, wg *sync.WaitGrou
 				return err
 			}
 
-			parser := respparser.NewParser()
-
-			responseChan := make(chan *ops.StoreResponse)      // responseChan is used for handling common responses from shards
-			preprocessingChan := make(chan *ops.StoreResponse) // preprocessingChan is specifically for handling responses from shards for commands that require preprocessing
-
-			ioThreadID := GenerateUniqueIOThreadID()
-			thread := iothread.NewIOThread(ioThreadID, responseChan, preprocessingChan, s.cmdWatchSubscriptionChan, ioHandler, parser, s.shardManager, s.globalErrorChan, s.wl)
+			if !auth.AllowConnection(ioHandler.RemoteAddr(), false) {
+				go denyConnection(ctx, ioHandler)
+				continue
+			}
 
-			// Register the io-thread with the manager
-			err = s.ioThreadManager.RegisterIOThread(thread)
+			thread, err := s.registerIOThread(ioHandler)
 			if err != nil {
 				return err
 			}
@@ -227,6 +302,49 @@ func (s *Server) AcceptConnectionRequests(ctx context.Context, wg *sync.WaitGrou
 	}
 }
 
+// accept accepts a connection on the listening socket, closed on exec like the socket.
+func accept(serverFD int) (int, error) {
+	syscall.ForkLock.RLock()
+	defer syscall.ForkLock.RUnlock()
+	clientFD, _, err := syscall.Accept(serverFD)
+	if err != nil {
+		return -1, err
+	}
+	syscall.CloseOnExec(clientFD)
+	return clientFD, nil
+}
+
+// denyConnection replies to a client refused by protected mode with the reason and closes the connection.
+func denyConnection(ctx context.Context, ioHandler *netconn.IOHandler) {
+	slog.Warn("refusing connection in protected mode", slog.String("remote-addr", ioHandler.RemoteAddr()))
+	stats.ConnectionRejected()
+	if err := ioHandler.Write(ctx, diceerrors.ErrProtectedMode); err != nil {
+		slog.Debug("Failed to notify refused client", slog.Any("error", err))
+	}
+	if err := ioHandler.Close(); err != nil {
+		slog.Debug("Failed to close refused connection", slog.Any("error", err))
+	}
+}
+
+// registerIOThread creates a new io-thread for the given connection handler and
+// registers it with the io-thread manager.
+func (s *Server) registerIOThread(ioHandler *netconn.IOHandler) (*iothread.BaseIOThread, error) {
+	parser := respparser.NewParser()
+
+	responseChan := make(chan *ops.StoreResponse)      // responseChan is used for handling common responses from shards
+	preprocessingChan := make(chan *ops.StoreResponse) // preprocessingChan is specifically for handling responses from shards for commands that require preprocessing
+
+	ioThreadID := GenerateUniqueIOThreadID()
+	thread := iothread.NewIOThread(ioThreadID, responseChan, preprocessingChan, s.cmdWatchSubscriptionChan, ioHandler, parser, s.shardManager, s.globalErrorChan, s.wl)
+
+	// Register the io-thread with the manager
+	if err := s.ioThreadManager.RegisterIOThread(thread); err != nil {
+		return nil, err
+	}
+
+	return thread, nil
+}
+
 func (s *Server) startIOThread(ctx context.Context, wg *sync.WaitGroup, thread *iothread.BaseIOThread) {
 	wg.Done()
 	defer func(wm *iothread.Manager, id string) {
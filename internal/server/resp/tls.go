@@ -0,0 +1,220 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/auth"
+	"github.com/dicedb/dice/internal/clientio/iohandler/netconn"
+	"github.com/dicedb/dice/internal/handover"
+)
+
+const (
+	TLSAuthClientsNo       = "no"
+	TLSAuthClientsOptional = "optional"
+	TLSAuthClientsYes      = "yes"
+
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
+var (
+	ErrInvalidCABundle = errors.New("no valid certificates found in CA bundle")
+)
+
+// NewTLSConfig builds the server side TLS configuration from the tls section of the config.
+// When client authentication is enabled, client certificates are verified against the CA bundle.
+func NewTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(config.DiceConfig().TLS.CertFile, config.DiceConfig().TLS.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.NoClientCert,
+	}
+
+	switch config.DiceConfig().TLS.AuthClients {
+	case TLSAuthClientsOptional:
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	case TLSAuthClientsYes:
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	if config.DiceConfig().TLS.CAFile != "" {
+		caBundle, err := os.ReadFile(config.DiceConfig().TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, ErrInvalidCABundle
+		}
+		tlsConfig.ClientCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// currentTLSConfig is the TLS configuration used for new handshakes. It is replaced when the
+// certificate, key or CA files are changed or the config is reloaded, so certificates can be
+// rotated without restarting the listener.
+var (
+	currentTLSConfig      atomic.Pointer[tls.Config]
+	registerTLSReloadOnce sync.Once
+)
+
+// reloadTLSConfig re-reads the TLS files, keeping the previous configuration if they are invalid
+func reloadTLSConfig() {
+	tlsConfig, err := NewTLSConfig()
+	if err != nil {
+		slog.Error("Failed to reload tls configuration, keeping the previous one", slog.Any("error", err))
+		return
+	}
+	currentTLSConfig.Store(tlsConfig)
+	slog.Info("tls configuration reloaded")
+}
+
+// AcceptTLSConnectionRequests accepts new client connections on the TLS port.
+func (s *Server) AcceptTLSConnectionRequests(ctx context.Context, wg *sync.WaitGroup) error {
+	tlsConfig, err := NewTLSConfig()
+	if err != nil {
+		return err
+	}
+	currentTLSConfig.Store(tlsConfig)
+
+	registerTLSReloadOnce.Do(func() {
+		config.OnReload(reloadTLSConfig)
+		for _, name := range []string{"tls.cert_file", "tls.key_file", "tls.ca_file", "tls.auth_clients"} {
+			config.OnParameterChange(name, reloadTLSConfig)
+		}
+	})
+
+	listenerConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return currentTLSConfig.Load(), nil
+		},
+	}
+
+	tcpListener, err := handover.Listen("tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.tlsPort)))
+	if err != nil {
+		return fmt.Errorf("failed to listen on tls port: %w", err)
+	}
+	listener := tls.NewListener(tcpListener, listenerConfig)
+	s.bound()
+
+	slog.Info("also listening TLS on", slog.Int("port", s.tlsPort))
+
+	go func() {
+		<-ctx.Done()
+		if err := listener.Close(); err != nil {
+			slog.Warn("Failed to close tls listener", slog.Any("error", err))
+		}
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				slog.Info("no new tls connections will be accepted")
+				return ctx.Err()
+			}
+			return fmt.Errorf("error accepting tls connection: %w", err)
+		}
+
+		// The handshake is completed off the accept loop so that a slow client
+		// cannot hold up other connections.
+		wg.Add(1)
+		go s.serveTLSConnection(ctx, wg, conn.(*tls.Conn))
+	}
+}
+
+// serveTLSConnection completes the TLS handshake and registers an io-thread for the connection.
+// The identity of a verified client certificate is bound to the session of the io-thread.
+func (s *Server) serveTLSConnection(ctx context.Context, wg *sync.WaitGroup, conn *tls.Conn) {
+	defer wg.Done()
+
+	identity, err := handshake(ctx, conn)
+	if err != nil {
+		slog.Debug("TLS handshake failed", slog.String("remote-addr", conn.RemoteAddr().String()), slog.Any("error", err))
+		closeTLSConnection(conn)
+		return
+	}
+
+	ioHandler := netconn.NewIOHandlerWithConn(conn)
+	if !auth.AllowConnection(ioHandler.RemoteAddr(), identity != "") {
+		denyConnection(ctx, ioHandler)
+		return
+	}
+
+	thread, err := s.registerIOThread(ioHandler)
+	if err != nil {
+		slog.Warn("Failed to register io-thread for tls connection", slog.Any("error", err))
+		closeTLSConnection(conn)
+		return
+	}
+
+	if identity != "" {
+		thread.Session.PeerIdentity = identity
+		if err := thread.Session.ValidatePeerIdentity(identity); err != nil {
+			slog.Debug("Client certificate does not map to a user", slog.String("identity", identity), slog.Any("error", err))
+		}
+	}
+
+	wg.Add(1)
+	s.startIOThread(ctx, wg, thread)
+}
+
+func closeTLSConnection(conn *tls.Conn) {
+	if err := conn.Close(); err != nil {
+		slog.Debug("Failed to close tls connection", slog.Any("error", err))
+	}
+}
+
+// handshake completes the TLS handshake for the connection and returns the common name
+// of the verified client certificate, if one was presented.
+func handshake(ctx context.Context, conn *tls.Conn) (string, error) {
+	handshakeCtx, cancel := context.WithTimeout(ctx, tlsHandshakeTimeout)
+	defer cancel()
+
+	if err := conn.HandshakeContext(handshakeCtx); err != nil {
+		return "", err
+	}
+
+	state := conn.ConnectionState()
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", nil
+	}
+
+	return state.VerifiedChains[0][0].Subject.CommonName, nil
+}
@@ -0,0 +1,107 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package resp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio/iohandler/netconn"
+	"github.com/dicedb/dice/internal/handover"
+)
+
+// AcceptUnixConnectionRequests accepts new client connections on the Unix domain socket.
+// Access to the socket is controlled by its file mode, so protected mode does not apply to it.
+func (s *Server) AcceptUnixConnectionRequests(ctx context.Context, wg *sync.WaitGroup) error {
+	perm, err := config.ParseUnixSocketPerm(s.unixSocketPerm)
+	if err != nil {
+		return err
+	}
+
+	listener, err := handover.ListenFunc("unix", s.unixSocket, func() (net.Listener, error) {
+		// A socket file left behind by a previous run that did not exit cleanly would make the bind fail
+		if err := removeStaleSocket(s.unixSocket); err != nil {
+			return nil, err
+		}
+		return net.Listen("unix", s.unixSocket)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to listen on unix socket: %w", err)
+	}
+	// The socket file is removed when the listener is closed
+	defer listener.Close()
+
+	if err := os.Chmod(s.unixSocket, perm); err != nil {
+		return fmt.Errorf("failed to set unix socket permissions: %w", err)
+	}
+	s.bound()
+
+	slog.Info("also listening on unix socket", slog.String("path", s.unixSocket), slog.String("perm", perm.String()))
+
+	go func() {
+		<-ctx.Done()
+		if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			slog.Warn("Failed to close unix socket listener", slog.Any("error", err))
+		}
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				slog.Info("no new unix socket connections will be accepted")
+				return ctx.Err()
+			}
+			return fmt.Errorf("error accepting unix socket connection: %w", err)
+		}
+
+		thread, err := s.registerIOThread(netconn.NewIOHandlerWithConn(conn))
+		if err != nil {
+			slog.Warn("Failed to register io-thread for unix socket connection", slog.Any("error", err))
+			if err := conn.Close(); err != nil {
+				slog.Debug("Failed to close unix socket connection", slog.Any("error", err))
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go s.startIOThread(ctx, wg, thread)
+	}
+}
+
+// removeStaleSocket removes the socket file at path, refusing to remove anything that is not a socket.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat unix socket: %w", err)
+	}
+
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("unix socket path %s exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
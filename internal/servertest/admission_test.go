@@ -0,0 +1,90 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package servertest
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withMaxFrontendInflight lets every frontend execute a single command at once.
+func withMaxFrontendInflight(t *testing.T) {
+	previous := config.DiceConfig().Performance.MaxFrontendInflight
+	config.DiceConfig().Performance.MaxFrontendInflight = 1
+	t.Cleanup(func() { config.DiceConfig().Performance.MaxFrontendInflight = previous })
+}
+
+func TestRESPLoadShedding(t *testing.T) {
+	s := Start(t, Options{})
+	withMaxFrontendInflight(t)
+	sleeper, c := s.NewRESPClient(t), s.NewRESPClient(t)
+
+	_, err := sleeper.Conn().Write([]byte("*2\r\n$5\r\nSLEEP\r\n$1\r\n1\r\n"))
+	require.NoError(t, err)
+	time.Sleep(300 * time.Millisecond)
+
+	assert.Equal(t, diceerrors.ErrOverloaded.Error(), c.FireCommand("GET k"))
+	assert.Equal(t, "OK", sleeper.Read())
+	assert.Equal(t, "(nil)", c.FireCommand("GET k"))
+}
+
+func TestHTTPLoadShedding(t *testing.T) {
+	s := Start(t, Options{HTTP: true})
+	withMaxFrontendInflight(t)
+
+	slept := make(chan struct{})
+	go func() {
+		defer close(slept)
+		_, _ = s.NewHTTPClient(t).FireCommand("SLEEP", map[string]interface{}{"key": "1"})
+	}()
+	time.Sleep(300 * time.Millisecond)
+
+	resp, err := http.Post(s.HTTPURL+"/GET", "application/json", bytes.NewReader([]byte(`{"key": "k"}`)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	<-slept
+	_, err = s.NewHTTPClient(t).FireCommand("GET", map[string]interface{}{"key": "k"})
+	assert.NoError(t, err)
+}
+
+func TestWebSocketLoadShedding(t *testing.T) {
+	s := Start(t, Options{WebSocket: true})
+	withMaxFrontendInflight(t)
+	sleeper, c := s.NewWebSocketClient(t), s.NewWebSocketClient(t)
+
+	require.NoError(t, sleeper.Conn().WriteMessage(websocket.TextMessage, []byte("SLEEP 1")))
+	time.Sleep(300 * time.Millisecond)
+
+	// The connection of the command shed is closed, telling the client to try again later
+	_, err := c.FireCommand("GET k")
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), err)
+
+	reply, err := sleeper.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", reply)
+}
@@ -0,0 +1,213 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package servertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/dicepb"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/server/httpws"
+	"github.com/dicedb/dice/testutils"
+	dicedb "github.com/dicedb/dicedb-go"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// RESPClient is a raw RESP connection to the server.
+type RESPClient struct {
+	t      testing.TB
+	conn   net.Conn
+	parser *clientio.RESPParser
+}
+
+// NewRESPClient connects to the RESP frontend of the server. The connection is closed once the test is done.
+func (s *Server) NewRESPClient(t testing.TB) *RESPClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", s.RESPAddr)
+	if err != nil {
+		t.Fatalf("servertest: could not connect to %s: %v", s.RESPAddr, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &RESPClient{t: t, conn: conn, parser: clientio.NewRESPParser(conn)}
+}
+
+// Conn returns the connection of the client.
+func (c *RESPClient) Conn() net.Conn {
+	return c.conn
+}
+
+// FireCommand sends the command, e.g. `SET k "a b"`, and returns its decoded reply. An error replied by the
+// server is returned as the reply, the test fails on a connection error.
+func (c *RESPClient) FireCommand(cmd string) interface{} {
+	c.t.Helper()
+
+	if _, err := c.conn.Write(clientio.Encode(testutils.ParseCommand(cmd), false)); err != nil {
+		c.t.Fatalf("servertest: could not send %q: %v", cmd, err)
+	}
+	return c.Read()
+}
+
+// Read reads the next reply pushed by the server, e.g. a watch update.
+func (c *RESPClient) Read() interface{} {
+	c.t.Helper()
+
+	v, err := c.parser.DecodeOne()
+	if err != nil {
+		c.t.Fatalf("servertest: could not read a reply: %v", err)
+	}
+	return v
+}
+
+// NewSDKClient returns a client of the Go SDK connected to the RESP frontend of the server. The client is
+// closed once the test is done.
+func (s *Server) NewSDKClient(t testing.TB) *dicedb.Client {
+	client := dicedb.NewClient(&dicedb.Options{
+		Addr:                  s.RESPAddr,
+		DialTimeout:           10 * time.Second,
+		ReadTimeout:           30 * time.Second,
+		WriteTimeout:          30 * time.Second,
+		ContextTimeoutEnabled: true,
+		MaxRetries:            -1,
+	})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// HTTPClient sends commands to the HTTP frontend of the server.
+type HTTPClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPClient returns a client of the HTTP frontend of the server, which must have been started with it.
+func (s *Server) NewHTTPClient(t testing.TB) *HTTPClient {
+	t.Helper()
+
+	if s.HTTPURL == "" {
+		t.Fatal("servertest: the HTTP frontend is not started")
+	}
+	client := &http.Client{Timeout: 100 * time.Second}
+	t.Cleanup(client.CloseIdleConnections)
+	return &HTTPClient{baseURL: s.HTTPURL, client: client}
+}
+
+// FireCommand posts the command with its JSON body, e.g. {"key": "k", "value": "v"}, and returns the data
+// of the reply.
+func (c *HTTPClient) FireCommand(command string, body map[string]interface{}) (interface{}, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost,
+		c.baseURL+"/"+strings.ToUpper(command), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result httpws.HTTPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// WebSocketClient is a connection to the WebSocket frontend of the server.
+type WebSocketClient struct {
+	conn *websocket.Conn
+}
+
+// NewWebSocketClient connects to the WebSocket frontend of the server, which must have been started with it.
+// The connection is closed once the test is done.
+func (s *Server) NewWebSocketClient(t testing.TB) *WebSocketClient {
+	t.Helper()
+
+	if s.WebSocketURL == "" {
+		t.Fatal("servertest: the WebSocket frontend is not started")
+	}
+	conn, resp, err := websocket.DefaultDialer.Dial(s.WebSocketURL, nil)
+	if err != nil {
+		t.Fatalf("servertest: could not connect to %s: %v", s.WebSocketURL, err)
+	}
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return &WebSocketClient{conn: conn}
+}
+
+// Conn returns the connection of the client.
+func (c *WebSocketClient) Conn() *websocket.Conn {
+	return c.conn
+}
+
+// FireCommand sends the command, e.g. "SET k v", and returns its JSON decoded reply.
+func (c *WebSocketClient) FireCommand(cmd string) (interface{}, error) {
+	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(cmd)); err != nil {
+		return nil, err
+	}
+	return c.Read()
+}
+
+// Read reads the next message pushed by the server, e.g. a watch update, JSON decoded.
+func (c *WebSocketClient) Read() (interface{}, error) {
+	_, msg, err := c.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(msg, &v); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return v, nil
+}
+
+// NewGRPCClient returns a client of the gRPC frontend of the server, which must have been started with it.
+// The connection is closed once the test is done.
+func (s *Server) NewGRPCClient(t testing.TB) dicepb.DiceClient {
+	t.Helper()
+
+	if s.GRPCAddr == "" {
+		t.Fatal("servertest: the gRPC frontend is not started")
+	}
+	conn, err := grpc.NewClient(s.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("servertest: could not connect to %s: %v", s.GRPCAddr, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return dicepb.NewDiceClient(conn)
+}
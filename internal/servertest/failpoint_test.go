@@ -0,0 +1,97 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build failpoints
+
+package servertest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/failpoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchDropFailpoint(t *testing.T) {
+	s := Start(t, Options{})
+	t.Cleanup(func() { failpoint.Disable(failpoint.WatchDrop) })
+	admin, watcher := s.NewRESPClient(t), s.NewRESPClient(t)
+
+	assert.Equal(t, []interface{}{"GET", "2894570460", "(nil)", int64(0)}, watcher.FireCommand("GET.WATCH k"))
+	assert.Equal(t, "OK", admin.FireCommand("DEBUG FAILPOINT watch/drop-update 1*return"))
+	assert.Equal(t, []interface{}{[]interface{}{failpoint.WatchDrop, "1*return"}}, admin.FireCommand("DEBUG FAILPOINT LIST"))
+
+	admin.FireCommand("SET k a")
+	admin.FireCommand("SET k b")
+	// The update of the first SET is dropped
+	assert.Equal(t, []interface{}{"GET", "2894570460", "b", int64(1)}, watcher.Read())
+	assert.Empty(t, admin.FireCommand("DEBUG FAILPOINT LIST"))
+}
+
+func TestShardDelayFailpoint(t *testing.T) {
+	s := Start(t, Options{})
+	t.Cleanup(func() { failpoint.Disable(failpoint.ShardDelay) })
+	c := s.NewRESPClient(t)
+
+	assert.Equal(t, "OK", c.FireCommand("SET k v"))
+	assert.Equal(t, "OK", c.FireCommand("DEBUG FAILPOINT shard/delay 1*sleep(7s)"))
+	assert.Equal(t, diceerrors.ErrShardsTimedOut.Error(), c.FireCommand("GET k"))
+	// The late response of the command timed out is not mistaken for the one of the next command
+	assert.Equal(t, "OK", c.FireCommand("SET k w"))
+	assert.Equal(t, "w", c.FireCommand("GET k"))
+}
+
+func TestSocketWriteErrorFailpoint(t *testing.T) {
+	s := Start(t, Options{})
+	t.Cleanup(func() { failpoint.Disable(failpoint.SocketWriteError) })
+	c := s.NewRESPClient(t)
+
+	assert.Equal(t, "PONG", c.FireCommand("PING"))
+	// Enabled from the test, the reply of DEBUG FAILPOINT would be the one failing
+	assert.NoError(t, failpoint.Enable(failpoint.SocketWriteError, "1*return(epipe)"))
+
+	// The reply fails with a broken pipe, closing the connection
+	_, err := c.Conn().Write([]byte("*1\r\n$4\r\nPING\r\n"))
+	assert.NoError(t, err)
+	_, err = c.parser.DecodeOne()
+	assert.Error(t, err)
+	assert.Equal(t, "PONG", s.NewRESPClient(t).FireCommand("PING"))
+}
+
+func TestShardPanicFailpoint(t *testing.T) {
+	s := Start(t, Options{HTTP: true})
+	t.Cleanup(func() { failpoint.Disable(failpoint.ShardDelay) })
+	c := s.NewRESPClient(t)
+
+	assert.Equal(t, "OK", c.FireCommand("SET k v"))
+	assert.Equal(t, "OK", c.FireCommand("DEBUG FAILPOINT shard/delay 1*panic"))
+	// The command the shard crashed on fails, the shard is restarted with its keys
+	assert.Equal(t, diceerrors.ErrShardCrashed.Error(), c.FireCommand("GET k"))
+	assert.Equal(t, "v", c.FireCommand("GET k"))
+	assert.Contains(t, c.FireCommand("INFO stats"), "shard_health:degraded\r\n")
+
+	resp, err := http.Get(s.HTTPURL + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "degraded", string(body))
+}
@@ -0,0 +1,273 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package servertest starts DiceDB servers in process for the tests. Every server has its own shards and
+// listens on ports picked by the kernel, so that the servers of a package, and the packages run in parallel
+// by go test, do not collide. A server is stopped when its test is done, by cancelling its frontends rather
+// than with the ABORT command.
+package servertest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/iothread"
+	"github.com/dicedb/dice/internal/server/abstractserver"
+	"github.com/dicedb/dice/internal/server/grpcsrv"
+	"github.com/dicedb/dice/internal/server/httpws"
+	"github.com/dicedb/dice/internal/server/resp"
+	"github.com/dicedb/dice/internal/shard"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/wal"
+	"github.com/dicedb/dice/internal/watchmanager"
+)
+
+const (
+	// host is the address the frontends listen on, the loopback one to stay out of the protected mode
+	host = "127.0.0.1"
+	// readyTimeout bounds the wait for a frontend to accept connections
+	readyTimeout = 10 * time.Second
+)
+
+// mu serializes the creation of the servers, which read their settings from the global configuration.
+var mu sync.Mutex
+
+// Options configures a Server. The RESP frontend is always started.
+type Options struct {
+	Shards     int   // Shards is the number of shards, 1 if unset
+	HTTP       bool  // HTTP starts the HTTP frontend
+	WebSocket  bool  // WebSocket starts the WebSocket frontend
+	GRPC       bool  // GRPC starts the gRPC frontend
+	MaxClients int32 // MaxClients is the maximum number of RESP clients, 20000 if unset
+	KeysLimit  int   // KeysLimit is the number of keys of the server before evicting, 2000 if unset
+}
+
+// Server is a DiceDB server running in the process of the test.
+type Server struct {
+	RESPAddr     string // RESPAddr is the address of the RESP frontend
+	HTTPURL      string // HTTPURL is the base URL of the HTTP frontend, empty unless started
+	WebSocketURL string // WebSocketURL is the URL of the WebSocket frontend, empty unless started
+	GRPCAddr     string // GRPCAddr is the address of the gRPC frontend, empty unless started
+
+	shardManager *shard.ShardManager
+	frontends    []*frontend // frontends are stopped in the order they are started
+	cancelShards context.CancelFunc
+	wg           sync.WaitGroup
+	closeOnce    sync.Once
+}
+
+// frontend is a server started by the Server, with its own context so that it can be stopped on its own.
+type frontend struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error // err is the error the frontend returned with, set once done is closed
+}
+
+// Start starts a server with the frontends of opts on free ports and waits until they accept connections.
+// The server is closed once the test is done.
+func Start(t testing.TB, opts Options) *Server {
+	t.Helper()
+
+	s, err := start(opts)
+	if err != nil {
+		t.Fatalf("servertest: %v", err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func start(opts Options) (*Server, error) {
+	if opts.Shards <= 0 {
+		opts.Shards = 1
+	}
+	if opts.MaxClients <= 0 {
+		opts.MaxClients = 20000
+	}
+	if opts.KeysLimit <= 0 {
+		opts.KeysLimit = 2000
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	loadDefaults()
+	config.DiceConfig().Network.IOBufferLength = 16
+	config.DiceConfig().Persistence.WriteAOFOnCleanup = false
+	config.DiceConfig().Memory.KeysLimit = opts.KeysLimit
+	config.DiceConfig().TLS.Port = 0
+	config.DiceConfig().RespServer.UnixSocket = ""
+
+	// Errors reported by the frontends are surfaced by waitReady, the ones reported later are dropped
+	errCh := make(chan error, 16)
+	cmdWatchChan := make(chan dstore.CmdWatchEvent, config.DiceConfig().Performance.WatchChanBufSize)
+	cmdWatchSubscriptionChan := make(chan watchmanager.WatchSubscription)
+	shardManager := shard.NewShardManager(uint8(opts.Shards), cmdWatchChan, errCh)
+	ioThreadManager := iothread.NewManager(opts.MaxClients, shardManager)
+	wl, _ := wal.NewNullWAL()
+
+	s := &Server{shardManager: shardManager}
+	shardCtx, cancelShards := context.WithCancel(context.Background())
+	s.cancelShards = cancelShards
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		shardManager.Run(shardCtx)
+	}()
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case <-shardCtx.Done():
+				return
+			case <-errCh:
+			}
+		}
+	}()
+
+	port, err := freePort()
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	config.DiceConfig().RespServer.Addr = host
+	config.DiceConfig().RespServer.Port = port
+	s.RESPAddr = net.JoinHostPort(host, strconv.Itoa(port))
+	// The RESP frontend runs the watch manager, it is started first and stopped first like in main
+	s.start("resp", resp.NewServer(shardManager, ioThreadManager, cmdWatchSubscriptionChan, cmdWatchChan, errCh, wl))
+	addrs := map[string]string{"resp": s.RESPAddr}
+
+	if opts.HTTP {
+		if port, err = freePort(); err != nil {
+			s.Close()
+			return nil, err
+		}
+		config.DiceConfig().HTTP.Addr = host
+		config.DiceConfig().HTTP.Port = port
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		s.HTTPURL = "http://" + addr
+		s.start("http", httpws.NewHTTPServer(shardManager, wl))
+		addrs["http"] = addr
+	}
+
+	if opts.WebSocket {
+		if port, err = freePort(); err != nil {
+			s.Close()
+			return nil, err
+		}
+		config.DiceConfig().WebSocket.Addr = host
+		config.DiceConfig().WebSocket.Port = port
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		s.WebSocketURL = "ws://" + addr
+		s.start("websocket", httpws.NewWebSocketServer(shardManager, ioThreadManager, cmdWatchSubscriptionChan, errCh, port, wl))
+		addrs["websocket"] = addr
+	}
+
+	if opts.GRPC {
+		if port, err = freePort(); err != nil {
+			s.Close()
+			return nil, err
+		}
+		config.DiceConfig().GRPC.Addr = host
+		config.DiceConfig().GRPC.Port = port
+		s.GRPCAddr = net.JoinHostPort(host, strconv.Itoa(port))
+		s.start("grpc", grpcsrv.NewServer(shardManager, ioThreadManager, cmdWatchSubscriptionChan, errCh, wl))
+		addrs["grpc"] = s.GRPCAddr
+	}
+
+	for _, f := range s.frontends {
+		if err := f.waitReady(addrs[f.name]); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// loadDefaults loads the default configuration, unless the test package loaded one already.
+func loadDefaults() {
+	if config.DiceConfig().Performance.WatchChanBufSize > 0 {
+		return
+	}
+	if err := config.NewConfigParser().ParseDefaults(config.DiceConfig()); err != nil {
+		log.Fatalf("failed to load configuration: %v", err)
+	}
+}
+
+// freePort returns a port free on the loopback interface, picked by the kernel.
+func freePort() (int, error) {
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return 0, fmt.Errorf("could not pick a free port: %w", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
+
+func (s *Server) start(name string, srv abstractserver.AbstractServer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &frontend{name: name, cancel: cancel, done: make(chan struct{})}
+	s.frontends = append(s.frontends, f)
+
+	go func() {
+		defer close(f.done)
+		f.err = srv.Run(ctx)
+	}()
+}
+
+// waitReady waits until the frontend accepts connections on addr.
+func (f *frontend) waitReady(addr string) error {
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+
+		select {
+		case <-f.done:
+			return fmt.Errorf("%s frontend stopped: %v", f.name, f.err)
+		case <-time.After(10 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s frontend not accepting connections on %s: %w", f.name, addr, err)
+		}
+	}
+}
+
+// Shards returns the shard manager of the server, e.g. to inspect the stores.
+func (s *Server) Shards() *shard.ShardManager {
+	return s.shardManager
+}
+
+// Close stops the frontends, in the order they were started, then the shards, and waits for them to return.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		for _, f := range s.frontends {
+			f.cancel()
+			<-f.done
+		}
+		s.cancelShards()
+		s.wg.Wait()
+	})
+}
@@ -0,0 +1,125 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package servertest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/dicepb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrontends(t *testing.T) {
+	s := Start(t, Options{HTTP: true, WebSocket: true, GRPC: true})
+
+	assert.Equal(t, "OK", s.NewRESPClient(t).FireCommand("SET k v"))
+
+	data, err := s.NewHTTPClient(t).FireCommand("GET", map[string]interface{}{"key": "k"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v", data)
+
+	reply, err := s.NewWebSocketClient(t).FireCommand("GET k")
+	assert.NoError(t, err)
+	assert.Equal(t, "v", reply)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := s.NewGRPCClient(t).ExecuteCommand(ctx, &dicepb.CommandRequest{Command: "GET", Args: []string{"k"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "v", resp.GetResult().GetStringValue())
+
+	assert.Equal(t, "v", s.NewSDKClient(t).Get(ctx, "k").Val())
+}
+
+func TestParallelServers(t *testing.T) {
+	for _, name := range []string{"a", "b"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := Start(t, Options{Shards: 2})
+			c := s.NewRESPClient(t)
+			assert.Equal(t, "OK", c.FireCommand("SET owner "+name))
+			assert.Equal(t, name, c.FireCommand("GET owner"))
+		})
+	}
+}
+
+func TestClose(t *testing.T) {
+	s := Start(t, Options{HTTP: true})
+	s.Close()
+
+	for _, addr := range []string{s.RESPAddr, s.HTTPURL[len("http://"):]} {
+		_, err := net.DialTimeout("tcp", addr, time.Second)
+		assert.Error(t, err, addr)
+	}
+	// Closing again, e.g. once the test is done, is a no-op
+	s.Close()
+}
+
+// The parameters changed by CONFIG SET are read by the shards and the frontends serving clients meanwhile, the
+// race detector reports the ones written in place.
+func TestConfigSetUnderTraffic(t *testing.T) {
+	s := Start(t, Options{Shards: 2, HTTP: true})
+
+	changes := [][]string{
+		{"CONFIG SET maxmemory 1000000 maxmemory-policy allkeys-lru", "CONFIG SET maxmemory 0 maxmemory-policy allkeys-lfu"},
+		{"CONFIG SET slowlog-log-slower-than 0 latency-monitor-threshold 1", "CONFIG SET slowlog-log-slower-than 10000 latency-monitor-threshold 0"},
+		{"CONFIG SET protected-mode true performance.slow_lane_budget 4", "CONFIG SET protected-mode false performance.slow_lane_budget 0"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		c := s.NewRESPClient(t)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; ctx.Err() == nil; n++ {
+				key := fmt.Sprintf("k%d", (i*1000+n)%64)
+				assert.Equal(t, "OK", c.FireCommand("SET "+key+" v"))
+				c.FireCommand("GET " + key)
+			}
+		}(i)
+	}
+	h := s.NewHTTPClient(t)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			_, err := h.FireCommand("GET", map[string]interface{}{"key": "k1"})
+			assert.NoError(t, err)
+		}
+	}()
+
+	admin := s.NewRESPClient(t)
+	for round := 0; round < 50; round++ {
+		for _, change := range changes {
+			assert.Equal(t, "OK", admin.FireCommand(change[round%2]))
+		}
+	}
+	for _, change := range changes {
+		assert.Equal(t, "OK", admin.FireCommand(change[1]))
+	}
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,87 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package servertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/server/httpws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushStreamableList pushes enough elements to the list l for the reply of LRANGE l 0 -1 to be streamed.
+func pushStreamableList(t *testing.T, s *Server) int {
+	n := clientio.StreamThreshold + 100
+	var rpush strings.Builder
+	rpush.WriteString("RPUSH l")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&rpush, " e%d", i)
+	}
+	require.Equal(t, int64(n), s.NewRESPClient(t).FireCommand(rpush.String()))
+	return n
+}
+
+func TestStreamedRESPReply(t *testing.T) {
+	s := Start(t, Options{Shards: 2})
+	n := pushStreamableList(t, s)
+	c := s.NewRESPClient(t)
+
+	elements, ok := c.FireCommand("LRANGE l 0 -1").([]interface{})
+	require.True(t, ok)
+	assert.Len(t, elements, n)
+	assert.Equal(t, "e0", elements[0])
+
+	// The keys are spread over the shards, the reply is composed from the replies of every shard
+	for i := 0; i < n; i++ {
+		require.Equal(t, "OK", c.FireCommand(fmt.Sprintf("SET k%d v%d", i, i)))
+	}
+	keys, ok := c.FireCommand("KEYS k*").([]interface{})
+	require.True(t, ok)
+	assert.Len(t, keys, n)
+}
+
+func TestStreamedHTTPReply(t *testing.T) {
+	s := Start(t, Options{HTTP: true})
+	n := pushStreamableList(t, s)
+
+	resp, err := http.Post(s.HTTPURL+"/LRANGE", "application/json", bytes.NewReader([]byte(`{"key": "l", "values": ["0", "-1"]}`)))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+
+	var reply httpws.HTTPResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+	assert.Equal(t, httpws.HTTPStatusSuccess, reply.Status)
+	assert.Len(t, reply.Data, n)
+}
+
+func TestStreamedWebSocketReply(t *testing.T) {
+	s := Start(t, Options{WebSocket: true})
+	n := pushStreamableList(t, s)
+
+	reply, err := s.NewWebSocketClient(t).FireCommand("LRANGE l 0 -1")
+	require.NoError(t, err)
+	assert.Len(t, reply, n)
+}
@@ -0,0 +1,83 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"context"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Batch is a batch of commands to execute on the shards, every shard executing its commands in a single
+// operation. Unlike a transaction a batch is not atomic: the other operations of a shard are executed
+// before or after its commands, but the shards do not wait on each other.
+type Batch struct {
+	ClientAddr  string                       // ClientAddr is the remote address of the client
+	SpanContext trace.SpanContext            // SpanContext of the request span
+	Cmds        map[ShardID][]*cmd.DiceDBCmd // Cmds are the commands of every shard, in the order they are executed
+}
+
+// ExecBatch executes the commands of the batch, the shards executing their commands concurrently, and returns
+// the responses of the commands of every shard in order. An error means the batch may be partially applied.
+func (manager *ShardManager) ExecBatch(ctx context.Context, batch *Batch) (map[ShardID][]*eval.EvalResponse, error) {
+	// Every shard replies once, so that a shard never blocks on the responses of a batch given up on
+	responseChan := make(chan *ops.StoreResponse, len(batch.Cmds))
+	for id, cmds := range batch.Cmds {
+		manager.shards[id].Send(&ops.StoreOp{
+			SeqID:       id,
+			ShardID:     id,
+			ClientAddr:  batch.ClientAddr,
+			SpanContext: batch.SpanContext,
+			Batch:       &ops.BatchOp{Cmds: cmds, ResponseChan: responseChan},
+		})
+	}
+
+	resps := make(map[ShardID][]*eval.EvalResponse, len(batch.Cmds))
+	for len(resps) < len(batch.Cmds) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case resp := <-responseChan:
+			resps[resp.SeqID] = resp.EvalResponse.Result.([]*eval.EvalResponse)
+		}
+	}
+	return resps, nil
+}
+
+// executeBatch executes the commands of the batch operation and replies with their responses.
+func (shard *ShardThread) executeBatch(op *ops.StoreOp) {
+	resps := make([]*eval.EvalResponse, 0, len(op.Batch.Cmds))
+	for _, c := range op.Batch.Cmds {
+		cmdOp := &ops.StoreOp{
+			RequestID:   op.RequestID,
+			Cmd:         c,
+			ShardID:     op.ShardID,
+			ClientAddr:  op.ClientAddr,
+			SpanContext: op.SpanContext,
+		}
+		resps = append(resps, shard.execute(cmdOp, eval.NewEval(c, nil, shard.store, false, false, false)))
+	}
+
+	op.Batch.ResponseChan <- &ops.StoreResponse{
+		RequestID:    op.RequestID,
+		SeqID:        op.SeqID,
+		EvalResponse: &eval.EvalResponse{Result: resps},
+	}
+}
@@ -0,0 +1,62 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecBatch(t *testing.T) {
+	withTxnConfig(t)
+
+	manager := NewShardManager(2, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	manager.start(ctx, &wg)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	batch := &Batch{ClientAddr: "test", Cmds: make(map[ShardID][]*cmd.DiceDBCmd)}
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("k%d", i)
+		id, _ := manager.GetShardInfo(key)
+		batch.Cmds[id] = append(batch.Cmds[id],
+			&cmd.DiceDBCmd{Cmd: "SET", Args: []string{key, "v"}},
+			&cmd.DiceDBCmd{Cmd: "LPUSH", Args: []string{key, "v"}})
+	}
+	require.Len(t, batch.Cmds, 2)
+
+	resps, err := manager.ExecBatch(context.Background(), batch)
+	require.NoError(t, err)
+	for id, cmds := range batch.Cmds {
+		require.Len(t, resps[id], len(cmds))
+		// The commands are executed in order: the SET succeeds, the LPUSH on the string fails
+		for i := 0; i < len(cmds); i += 2 {
+			assert.NoError(t, resps[id][i].Error)
+			assert.Error(t, resps[id][i+1].Error)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/stats"
+)
+
+// slowCommands are the commands whose cost grows with the size of the keyspace or of the value they read, rather
+// than being constant. They are executed from the slow lane of the shard when it is enabled.
+var slowCommands = map[string]bool{
+	"BITCOUNT":       true,
+	"BITPOS":         true,
+	"DUMP":           true,
+	"FLUSHDB":        true,
+	"HGETALL":        true,
+	"HKEYS":          true,
+	"HVALS":          true,
+	"JSON.GET":       true,
+	"LRANGE":         true,
+	"SINGLEEXPORT":   true,
+	"SINGLEKEYS":     true,
+	"SINGLEKEYSIZES": true,
+	"SINGLEMEMORY":   true,
+	"SINGLERELOAD":   true,
+	"SLEEP":          true,
+	"SMEMBERS":       true,
+	"ZRANGE":         true,
+}
+
+// slowLane holds the expensive commands received by a shard while the cheap ones queued behind them are executed
+// first, so that an occasional KEYS or large range does not add its latency to every GET and SET of the shard.
+// A command of the slow lane is executed once no operation is queued on the shard, or once performance.
+// slow_lane_budget operations were executed while it waited, so that the slow lane is never starved.
+//
+// The commands of a RESP client are sent one after the other, a command being sent once the previous one replied,
+// so holding back a command never reorders the commands of a client. The steps of the transactions, the batches
+// and the preprocessing are never held back, they depend on the order of the operations of the shard. Neither
+// are the commands of the HTTP and WebSocket servers, which share a response channel and pair the responses
+// with their requests by the order they arrive in.
+type slowLane struct {
+	queued []*ops.StoreOp
+	ran    int // ran is the number of operations executed since the oldest command of the slow lane could run
+}
+
+// slow reports whether the operation is held back in the slow lane.
+func slow(op *ops.StoreOp) bool {
+	return config.DiceConfig().Performance.SlowLaneBudget > 0 && op.Txn == nil && op.Batch == nil &&
+		!op.PreProcessing && !op.HTTPOp && !op.WebsocketOp && op.Cmd != nil && slowCommands[op.Cmd.Cmd]
+}
+
+// admit processes the operation received by the shard, unless it is held back in the slow lane. The oldest
+// command of the slow lane is executed once the budget of the operations executed ahead of it is spent.
+func (shard *ShardThread) admit(op *ops.StoreOp) {
+	if slow(op) {
+		shard.slowLane.queued = append(shard.slowLane.queued, op)
+		stats.SlowLaneQueued()
+		return
+	}
+
+	shard.receive(op)
+	if len(shard.slowLane.queued) == 0 {
+		return
+	}
+	shard.slowLane.ran++
+	if shard.slowLane.ran >= config.DiceConfig().Performance.SlowLaneBudget {
+		shard.runSlow()
+	}
+}
+
+// runSlow executes the oldest command of the slow lane and reports whether there was one.
+func (shard *ShardThread) runSlow() bool {
+	op := shard.popSlow()
+	if op == nil {
+		return false
+	}
+	shard.receive(op)
+	return true
+}
+
+// popSlow removes the oldest command of the slow lane, nil if the slow lane is empty.
+func (shard *ShardThread) popSlow() *ops.StoreOp {
+	lane := &shard.slowLane
+	if len(lane.queued) == 0 {
+		return nil
+	}
+	op := lane.queued[0]
+	lane.queued[0] = nil
+	lane.queued = lane.queued[1:]
+	lane.ran = 0
+	return op
+}
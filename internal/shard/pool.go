@@ -0,0 +1,200 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// stepBudget is the number of operations a worker executes on a shard before moving on to the next one, so
+// that a busy shard does not starve the shards queued behind it
+const stepBudget = 64
+
+// pool schedules the shards onto a fixed number of workers. Every shard is homed on a worker, which runs it
+// whenever it has operations queued; an idle worker steals the shards queued on the other workers, so that the
+// cores are kept busy when the load is skewed towards the shards of a few workers.
+//
+// A shard is queued at most once and run by a single worker at a time, so that its operations are executed
+// one after the other in the order they were received, as when the shard runs its own goroutine. The keys
+// being partitioned by the shards, the commands of a key are never executed concurrently.
+type pool struct {
+	workers       []*worker
+	shards        []*ShardThread
+	cronFrequency time.Duration
+
+	idleMu sync.Mutex
+	idle   []*worker // idle are the workers parked waiting for a shard to run
+}
+
+type worker struct {
+	id     int
+	wake   chan struct{} // wake is signaled once a shard is queued for the parked worker
+	mu     sync.Mutex
+	queued []*ShardThread // queued are the shards waiting to be run, the ones homed on the worker or submitted to it
+}
+
+// newPool returns a pool of the workers running the shards, at most one worker per shard.
+func newPool(workers int, shards []*ShardThread, cronFrequency time.Duration) *pool {
+	workers = max(1, min(workers, len(shards)))
+	p := &pool{shards: shards, cronFrequency: cronFrequency}
+	for i := 0; i < workers; i++ {
+		p.workers = append(p.workers, &worker{id: i, wake: make(chan struct{}, 1)})
+	}
+	for _, shard := range shards {
+		shard.pool = p
+	}
+	return p
+}
+
+// run runs the shards until the context is done, then executes the operations still queued on every shard
+// and cleans them up.
+func (p *pool) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, w := range p.workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			p.work(ctx, w)
+		}(w)
+	}
+
+	ticker := time.NewTicker(p.cronFrequency)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, shard := range p.shards {
+				shard.cronDue.Store(true)
+				shard.schedule()
+			}
+		case <-ctx.Done():
+			wg.Wait()
+			for _, shard := range p.shards {
+				shard.drain()
+				shard.cleanup()
+			}
+			return
+		}
+	}
+}
+
+// submit queues the shard on its home worker and wakes up a parked worker to run it, the home worker if
+// parked.
+func (p *pool) submit(shard *ShardThread) {
+	home := p.workers[int(shard.id)%len(p.workers)]
+	home.mu.Lock()
+	home.queued = append(home.queued, shard)
+	home.mu.Unlock()
+
+	p.idleMu.Lock()
+	defer p.idleMu.Unlock()
+	if len(p.idle) == 0 {
+		return
+	}
+	i := len(p.idle) - 1
+	for j, w := range p.idle {
+		if w == home {
+			i = j
+			break
+		}
+	}
+	w := p.idle[i]
+	p.idle = append(p.idle[:i], p.idle[i+1:]...)
+	w.wake <- struct{}{}
+}
+
+// work runs the shards queued on the worker, or stolen from the other workers, until the context is done.
+func (p *pool) work(ctx context.Context, w *worker) {
+	for ctx.Err() == nil {
+		if shard := p.next(w); shard != nil {
+			p.runShard(shard)
+			continue
+		}
+
+		// The worker registers as idle only if no shard was queued in the meantime, a shard queued afterwards
+		// wakes it up
+		p.idleMu.Lock()
+		if p.pending() {
+			p.idleMu.Unlock()
+			continue
+		}
+		p.idle = append(p.idle, w)
+		p.idleMu.Unlock()
+
+		select {
+		case <-w.wake:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// next returns the next shard queued on the worker, else a shard stolen from another worker, nil if none is queued.
+func (p *pool) next(w *worker) *ShardThread {
+	for i := range p.workers {
+		if shard := p.workers[(w.id+i)%len(p.workers)].pop(); shard != nil {
+			return shard
+		}
+	}
+	return nil
+}
+
+// pending reports whether a shard is queued on one of the workers.
+func (p *pool) pending() bool {
+	for _, w := range p.workers {
+		w.mu.Lock()
+		n := len(w.queued)
+		w.mu.Unlock()
+		if n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *worker) pop() *ShardThread {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.queued) == 0 {
+		return nil
+	}
+	shard := w.queued[0]
+	w.queued[0] = nil
+	w.queued = w.queued[1:]
+	return shard
+}
+
+// runShard runs the cron tasks of the shard if due, then executes up to stepBudget of its operations. The
+// shard is queued again if operations are left, or were received while it was running.
+func (p *pool) runShard(shard *ShardThread) {
+	// A shard crashing is recovered by the worker, which goes on with the operations left
+	shard.guard(func() {
+		if shard.cronDue.Swap(false) {
+			shard.RunCronTasks()
+		}
+		for i := 0; i < stepBudget && shard.Step(); i++ {
+		}
+	})
+
+	// The slow lane is only read by the worker running the shard
+	slowQueued := len(shard.slowLane.queued) > 0
+	shard.scheduled.Store(false)
+	if len(shard.ReqChan) > 0 || slowQueued || shard.cronDue.Load() {
+		shard.schedule()
+	}
+}
@@ -0,0 +1,164 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startPoolManager(t *testing.T, shards uint8, workers int) *ShardManager {
+	withTxnConfig(t)
+	config.DiceConfig().Performance.ShardWorkers = workers
+
+	manager := NewShardManager(shards, nil, nil)
+	require.NotNil(t, manager.pool)
+	require.Len(t, manager.pool.workers, min(workers, int(shards)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	manager.start(ctx, &wg)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return manager
+}
+
+func TestPoolKeepsTheOrderOfEveryShard(t *testing.T) {
+	manager := startPoolManager(t, 8, 3)
+	responses := make(chan *ops.StoreResponse, 8*100)
+	manager.RegisterIOThread("io", responses, nil)
+
+	// The increments of every key reply with the values in sequence, whichever worker runs the shard
+	for i := 0; i < 100; i++ {
+		for id := ShardID(0); id < 8; id++ {
+			manager.GetShard(id).Send(&ops.StoreOp{
+				SeqID:      id,
+				IOThreadID: "io",
+				ShardID:    id,
+				Cmd:        &cmd.DiceDBCmd{Cmd: "INCR", Args: []string{fmt.Sprintf("k%d", id)}},
+			})
+		}
+	}
+
+	last := make(map[ShardID]int64)
+	for i := 0; i < 8*100; i++ {
+		select {
+		case resp := <-responses:
+			require.NoError(t, resp.EvalResponse.Error)
+			require.Equal(t, last[resp.SeqID]+1, resp.EvalResponse.Result, "shard %d", resp.SeqID)
+			last[resp.SeqID]++
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d responses received", i)
+		}
+	}
+}
+
+func TestPoolStealsFromABlockedWorker(t *testing.T) {
+	manager := startPoolManager(t, 4, 2)
+
+	// A batch whose responses are not read blocks the worker running shard 0
+	blocked := make(chan *ops.StoreResponse)
+	manager.GetShard(0).Send(&ops.StoreOp{
+		ShardID: 0,
+		Batch:   &ops.BatchOp{Cmds: []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{"k", "v"}}}, ResponseChan: blocked},
+	})
+
+	// Shard 2 is homed on the same worker, it is run by the other one meanwhile
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resps, err := manager.ExecBatch(ctx, &Batch{Cmds: map[ShardID][]*cmd.DiceDBCmd{2: {{Cmd: "PING"}}}})
+	require.NoError(t, err)
+	assert.Equal(t, "PONG", resps[2][0].Result)
+
+	select {
+	case resp := <-blocked:
+		assert.Len(t, resp.EvalResponse.Result.([]*eval.EvalResponse), 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shard 0 did not reply")
+	}
+}
+
+func TestPoolRecoversACrashedShard(t *testing.T) {
+	manager := startPoolManager(t, 2, 1)
+	responses := make(chan *ops.StoreResponse, 2)
+	manager.RegisterIOThread("io", responses, nil)
+
+	// The operation without a command crashes shard 0, the worker goes on with the next operations
+	manager.GetShard(0).Send(&ops.StoreOp{SeqID: 0, IOThreadID: "io"})
+	manager.GetShard(0).Send(&ops.StoreOp{SeqID: 1, IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "PING"}})
+	for _, expected := range []*eval.EvalResponse{{Error: diceerrors.ErrShardCrashed}, {Result: "PONG"}} {
+		select {
+		case resp := <-responses:
+			assert.Equal(t, expected.Error, resp.EvalResponse.Error)
+			assert.Equal(t, expected.Result, resp.EvalResponse.Result)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the shard did not reply")
+		}
+	}
+}
+
+func TestPoolRunsTheCronTasksOnceDue(t *testing.T) {
+	withTxnConfig(t)
+	shard := newTxnTestShard()
+	p := newPool(4, []*ShardThread{shard}, time.Second)
+	require.Len(t, p.workers, 1)
+
+	ran := shard.lastCronExecTime
+	shard.scheduled.Store(true)
+	p.runShard(shard)
+	assert.Equal(t, ran, shard.lastCronExecTime)
+	assert.False(t, shard.scheduled.Load())
+
+	// The cron tasks made due while the shard is idle queue it, and run before its operations
+	time.Sleep(time.Millisecond)
+	shard.cronDue.Store(true)
+	shard.schedule()
+	require.Same(t, shard, p.next(p.workers[0]))
+	p.runShard(shard)
+	assert.True(t, shard.lastCronExecTime.After(ran))
+	assert.Nil(t, p.next(p.workers[0]))
+
+	// The operations left once the budget is spent queue the shard again
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+	for i := 0; i < stepBudget+1; i++ {
+		shard.ReqChan <- &ops.StoreOp{IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "PING"}}
+	}
+	shard.schedule()
+	require.Same(t, shard, p.next(p.workers[0]))
+	go p.runShard(shard)
+	for i := 0; i < stepBudget; i++ {
+		<-ioChan
+	}
+	assert.Eventually(t, func() bool { return p.pending() }, time.Second, time.Millisecond)
+	require.Same(t, shard, p.next(p.workers[0]))
+	p.runShard(shard)
+	<-ioChan
+	assert.Empty(t, shard.ReqChan)
+}
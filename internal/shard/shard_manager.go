@@ -20,12 +20,17 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"runtime"
 	"sync"
 	"syscall"
 
 	"github.com/dicedb/dice/config"
 
 	"github.com/cespare/xxhash/v2"
+	"github.com/dicedb/dice/internal/blocking"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/hotkeys"
+	"github.com/dicedb/dice/internal/journal"
 	"github.com/dicedb/dice/internal/ops"
 	dstore "github.com/dicedb/dice/internal/store"
 )
@@ -40,6 +45,8 @@ type ShardManager struct {
 	ShardErrorChan  chan *ShardError              // ShardErrorChan is the channel for sending shard-level errors
 	sigChan         chan os.Signal                // sigChan is the signal channel for the shard manager
 	shardCount      uint8                         // shardCount is the number of shards managed by this manager
+	blocking        *blocking.Manager             // blocking queues the clients blocked on the keys of the shards
+	pool            *pool                         // pool runs the shards when they share workers, nil when every shard runs its own goroutine
 }
 
 // NewShardManager creates a new ShardManager instance with the given number of Shards and a parent context.
@@ -47,16 +54,28 @@ func NewShardManager(shardCount uint8, cmdWatchChan chan dstore.CmdWatchEvent, g
 	shards := make([]*ShardThread, shardCount)
 	shardReqMap := make(map[ShardID]chan *ops.StoreOp)
 	shardErrorChan := make(chan *ShardError)
+	blockingManager := blocking.NewManager()
 
-	maxKeysPerShard := config.DiceConfig.Memory.KeysLimit / int(shardCount)
+	maxKeysPerShard := config.DiceConfig().Memory.KeysLimit / int(shardCount)
 	for i := uint8(0); i < shardCount; i++ {
-		evictionStrategy := dstore.NewBatchEvictionLRU(maxKeysPerShard, config.DiceConfig.Memory.EvictionRatio)
+		evictionStrategy := dstore.NewSampledEvictionLRU(maxKeysPerShard, config.DiceConfig().Memory.EvictionRatio,
+			config.DiceConfig().Memory.MaxMemorySamples)
 		// Shards are numbered from 0 to shardCount-1
 		shard := NewShardThread(i, globalErrorChan, shardErrorChan, cmdWatchChan, evictionStrategy)
+		shard.store.Subscribe(blockingManager)
+		shard.store.Subscribe(hotkeys.Invalidator{})
 		shards[i] = shard
 		shardReqMap[i] = shard.ReqChan
 	}
 
+	var shardPool *pool
+	if workers := config.DiceConfig().Performance.ShardWorkers; workers != 0 {
+		if workers < 0 {
+			workers = runtime.NumCPU()
+		}
+		shardPool = newPool(workers, shards, config.DiceConfig().Performance.ShardCronFrequency)
+	}
+
 	return &ShardManager{
 		shards:          shards,
 		shardReqMap:     shardReqMap,
@@ -64,6 +83,8 @@ func NewShardManager(shardCount uint8, cmdWatchChan chan dstore.CmdWatchEvent, g
 		ShardErrorChan:  shardErrorChan,
 		sigChan:         make(chan os.Signal, 1),
 		shardCount:      shardCount,
+		blocking:        blockingManager,
+		pool:            shardPool,
 	}
 }
 
@@ -88,8 +109,33 @@ func (manager *ShardManager) Run(ctx context.Context) {
 	wg.Wait()                     // Wait for all shard goroutines to exit.
 }
 
-// start initializes and starts the shard threads.
+// SaveSnapshot writes the keys of every shard to the AOF file. It must only be called once Run has
+// returned, as the stores of the shards are read without going through their request channels.
+func (manager *ShardManager) SaveSnapshot() error {
+	stores := make([]*dstore.Store, 0, len(manager.shards))
+	for _, shard := range manager.shards {
+		stores = append(stores, shard.store)
+	}
+	return dstore.DumpAllAOF(stores...)
+}
+
+// start initializes and starts the shard threads, or the workers running the shards when they share workers.
 func (manager *ShardManager) start(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		manager.blocking.Run(ctx)
+	}()
+
+	if manager.pool != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			manager.pool.run(ctx)
+		}()
+		return
+	}
+
 	for _, shard := range manager.shards {
 		shard := shard
 
@@ -101,12 +147,109 @@ func (manager *ShardManager) start(ctx context.Context, wg *sync.WaitGroup) {
 	}
 }
 
+// Blocking returns the manager of the clients blocked on the keys of the shards.
+func (manager *ShardManager) Blocking() *blocking.Manager {
+	return manager.blocking
+}
+
 func (manager *ShardManager) GetShardInfo(key string) (id ShardID, c chan *ops.StoreOp) {
 	hash := xxhash.Sum64String(key)
 	id = ShardID(hash % uint64(manager.GetShardCount()))
 	return id, manager.GetShard(id).ReqChan
 }
 
+// OpenJournals makes every shard record the commands it applies in its journal file in dir.
+// It must be called before Run.
+func (manager *ShardManager) OpenJournals(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, shard := range manager.shards {
+		j, err := journal.OpenFile(journal.Path(dir, shard.id))
+		if err != nil {
+			for _, opened := range manager.shards[:shard.id] {
+				opened.journal.Close()
+				opened.journal = nil
+			}
+			return err
+		}
+		shard.journal = j
+	}
+	return nil
+}
+
+// OpenDiskTiers makes every shard keep at most hotKeys values in memory, the others are spilled to the disk
+// tier file of the shard in dir. It must be called before Run.
+func (manager *ShardManager) OpenDiskTiers(dir string, hotKeys int) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, shard := range manager.shards {
+		tier, err := dstore.OpenDiskTier(dstore.DiskTierPath(dir, uint8(shard.id)))
+		if err != nil {
+			for _, opened := range manager.shards[:shard.id] {
+				opened.store.Tier().Close()
+			}
+			return err
+		}
+		shard.store.UseTier(tier, eval.ObjectCodec{}, hotKeys)
+	}
+	return nil
+}
+
+// SubscribeKeyEvents registers the subscriber for the key events of every shard. The subscriber is called by
+// the goroutines of the shards, concurrently, and must not block. It must be called before Run.
+func (manager *ShardManager) SubscribeKeyEvents(subscriber dstore.KeyEventSubscriber) {
+	for _, shard := range manager.shards {
+		shard.store.Subscribe(subscriber)
+	}
+}
+
+// StartLoading marks the shards as loading the keys of a backup restored while the clients are accepted. The
+// commands on the keys fail with LOADING until the keys are reported restored by Loaded, or StopLoading is called.
+func (manager *ShardManager) StartLoading(keys []string) {
+	pending := make([]map[string]struct{}, len(manager.shards))
+	for id := range pending {
+		pending[id] = make(map[string]struct{})
+	}
+	for _, key := range keys {
+		id, _ := manager.GetShardInfo(key)
+		pending[id][key] = struct{}{}
+	}
+	for id, shard := range manager.shards {
+		shard.pendingMu.Lock()
+		shard.pending = pending[id]
+		shard.pendingMu.Unlock()
+		shard.loading.Store(true)
+	}
+}
+
+// Loaded reports the keys restored, the commands on them being executed from then on.
+func (manager *ShardManager) Loaded(keys []string) {
+	for _, key := range keys {
+		id, _ := manager.GetShardInfo(key)
+		shard := manager.shards[id]
+		if !shard.loading.Load() {
+			continue
+		}
+		shard.pendingMu.Lock()
+		delete(shard.pending, key)
+		shard.pendingMu.Unlock()
+	}
+}
+
+// StopLoading marks the shards as done loading the backup, the keys not restored being executed as missing.
+func (manager *ShardManager) StopLoading() {
+	for _, shard := range manager.shards {
+		shard.loading.Store(false)
+		shard.pendingMu.Lock()
+		shard.pending = nil
+		shard.pendingMu.Unlock()
+	}
+}
+
 // GetShardCount returns the number of shards managed by this ShardManager.
 func (manager *ShardManager) GetShardCount() int8 {
 	return int8(len(manager.shards))
@@ -18,16 +18,29 @@ package shard
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/cmd"
 	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/hotkeys"
+	"github.com/dicedb/dice/internal/journal"
+	"github.com/dicedb/dice/internal/latency"
+	"github.com/dicedb/dice/internal/metrics"
+	"github.com/dicedb/dice/internal/namespace"
 	"github.com/dicedb/dice/internal/ops"
 	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/dicedb/dice/internal/stats"
 	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ShardID = uint8
@@ -54,12 +67,23 @@ type ShardThread struct {
 	shardErrorChan   chan *ShardError      // ShardErrorChan is the channel for sending shard-level errors.
 	lastCronExecTime time.Time             // lastCronExecTime is the last time the shard executed cron tasks.
 	cronFrequency    time.Duration         // cronFrequency is the frequency at which the shard executes cron tasks.
+	txn              *heldTxn              // txn is the transaction holding the lock of the shard, nil when unlocked.
+	journal          journal.Journal       // journal records the commands applied by the shard, nil when journaling is disabled.
+	meter            *namespace.Meter      // meter measures the usage of the namespaces, nil when namespaces are disabled.
+	pool             *pool                 // pool runs the shard when the shards share workers, nil when the shard runs its own goroutine.
+	scheduled        atomic.Bool           // scheduled is set while the shard is queued on or run by a worker of the pool.
+	cronDue          atomic.Bool           // cronDue is set once the cron tasks are due, the next worker running the shard runs them.
+	slowLane         slowLane              // slowLane holds the expensive commands executed after the cheap ones queued behind them.
+	inflight         *ops.StoreOp          // inflight is the operation being processed, failed if the shard crashes while processing it.
+	loading          atomic.Bool           // loading is set while a backup is restored, the commands on its keys not restored yet failing with LOADING.
+	pendingMu        sync.Mutex            // pendingMu is the mutex of pending, which the restore updates while the shard runs.
+	pending          map[string]struct{}   // pending are the keys of the backup restored that are not restored yet.
 }
 
 // NewShardThread creates a new ShardThread instance with the given shard id and error channel.
 func NewShardThread(id ShardID, gec chan error, sec chan *ShardError,
 	cmdWatchChan chan dstore.CmdWatchEvent, evictionStrategy dstore.EvictionStrategy) *ShardThread {
-	return &ShardThread{
+	shard := &ShardThread{
 		id:               id,
 		store:            dstore.NewStore(cmdWatchChan, evictionStrategy),
 		ReqChan:          make(chan *ops.StoreOp, 1000),
@@ -67,31 +91,111 @@ func NewShardThread(id ShardID, gec chan error, sec chan *ShardError,
 		globalErrorChan:  gec,
 		shardErrorChan:   sec,
 		lastCronExecTime: utils.GetCurrentTime(),
-		cronFrequency:    config.DiceConfig.Performance.ShardCronFrequency,
+		cronFrequency:    config.DiceConfig().Performance.ShardCronFrequency,
 	}
+	if config.DiceConfig().Namespaces.Enabled {
+		shard.meter = namespace.NewMeter(shard.store)
+	}
+	return shard
 }
 
-// Start starts the shard thread, listening for incoming requests.
+// Start starts the shard thread, listening for incoming requests. The shard is restarted with its store intact
+// if it crashes, or returns before the context is done.
 func (shard *ShardThread) Start(ctx context.Context) {
+	for {
+		shard.guard(func() { shard.run(ctx) })
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("restarting the shard", slog.Int("shard", int(shard.id)))
+	}
+}
+
+// run executes the operations received by the shard and its cron tasks until the context is done.
+func (shard *ShardThread) run(ctx context.Context) {
 	ticker := time.NewTicker(shard.cronFrequency)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case op := <-shard.ReqChan:
-			shard.processRequest(op)
+			shard.admit(op)
 		case <-ticker.C:
-			shard.runCronTasks()
+			shard.RunCronTasks()
 		case <-ctx.Done():
+			shard.drain()
 			shard.cleanup()
 			return
 		}
+
+		// The slow lane runs while no operation is queued
+		for len(shard.ReqChan) == 0 && shard.runSlow() {
+		}
 	}
 }
 
-// runCronTasks runs the cron tasks for the shard. This includes deleting expired keys.
-func (shard *ShardThread) runCronTasks() {
+// Step processes the next operation received by the shard, if any, else the next command of the slow lane,
+// without waiting for one, and reports whether it did. It lets a scheduler drive the shard in place of Start,
+// along with RunCronTasks.
+func (shard *ShardThread) Step() bool {
+	select {
+	case op := <-shard.ReqChan:
+		shard.admit(op)
+		return true
+	default:
+		return shard.runSlow()
+	}
+}
+
+// Send queues the operation on the shard, scheduling the shard onto a worker when the shards share workers.
+// The operations must be sent through Send rather than ReqChan, a shard run by the pool is not woken otherwise.
+func (shard *ShardThread) Send(op *ops.StoreOp) {
+	if op.Timing != nil {
+		op.SentAt = time.Now()
+	}
+	shard.ReqChan <- op
+	shard.schedule()
+}
+
+// SendContext queues the operation on the shard like Send, unless the context is done while the queue is full.
+func (shard *ShardThread) SendContext(ctx context.Context, op *ops.StoreOp) error {
+	if op.Timing != nil {
+		op.SentAt = time.Now()
+	}
+	select {
+	case shard.ReqChan <- op:
+		shard.schedule()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// schedule queues the shard on a worker of the pool unless it is queued or running already.
+func (shard *ShardThread) schedule() {
+	if shard.pool != nil && shard.scheduled.CompareAndSwap(false, true) {
+		shard.pool.submit(shard)
+	}
+}
+
+// RunCronTasks runs the cron tasks for the shard. This includes deleting expired keys, spilling the cold
+// values to the disk tier and defragmenting the store. Start runs them every cronFrequency.
+func (shard *ShardThread) RunCronTasks() {
+	// The keys of a locked shard are left untouched until the transaction is released
+	if shard.txn != nil {
+		shard.expireTxn()
+		return
+	}
+
+	start := time.Now()
 	dstore.DeleteExpiredKeys(shard.store)
+	latency.Since(latency.EventExpireCycle, start)
+	dstore.PurgeTrash(shard.store)
+	dstore.PurgeHistory(shard.store)
+	dstore.SpillColdValues(shard.store)
+	if config.DiceConfig().Memory.ActiveDefrag {
+		dstore.Defrag(shard.store, config.DiceConfig().Memory.ActiveDefragCycle)
+	}
 	shard.lastCronExecTime = utils.GetCurrentTime()
 }
 
@@ -113,6 +217,11 @@ func (shard *ShardThread) unregisterIOThread(id string) {
 
 // processRequest processes a Store operation for the shard.
 func (shard *ShardThread) processRequest(op *ops.StoreOp) {
+	if op.Batch != nil {
+		shard.executeBatch(op)
+		return
+	}
+
 	shard.mu.RLock()
 	ioChannels, ok := shard.ioThreadMap[op.IOThreadID]
 	shard.mu.RUnlock()
@@ -130,11 +239,27 @@ func (shard *ShardThread) processRequest(op *ops.StoreOp) {
 	if op.PreProcessing {
 		resp := e.PreProcessCommand()
 		sp.EvalResponse = resp
-		preProcessChan <- sp
+		respond(op, preProcessChan, sp)
 		return
 	}
 
-	resp := e.ExecuteCommand()
+	var resp *eval.EvalResponse
+	if skippable(op) {
+		// Nobody is left to read the result, the writes are applied all the same as the client may have
+		// sent them right before disconnecting
+		slog.Debug("skipping the command of a disconnected client", slog.Int("shard", int(shard.id)),
+			slog.String("cmd", op.Cmd.Cmd), slog.String("client", op.ClientAddr))
+		resp = &eval.EvalResponse{Error: diceerrors.ErrClientDisconnected}
+	} else if !shard.loaded(op.Cmd) {
+		resp = &eval.EvalResponse{Error: diceerrors.ErrLoading}
+	} else if op.Timing != nil {
+		start := time.Now()
+		resp = shard.execute(op, e)
+		op.Timing.Executed(start.Sub(op.SentAt), time.Since(start))
+	} else {
+		resp = shard.execute(op, e)
+	}
+
 	if ok {
 		sp.EvalResponse = resp
 	} else {
@@ -144,13 +269,235 @@ func (shard *ShardThread) processRequest(op *ops.StoreOp) {
 		}
 	}
 
-	ioThreadChan <- sp
+	respond(op, ioThreadChan, sp)
+}
+
+// skippable reports whether the command of the Store operation is a read of a client already disconnected.
+func skippable(op *ops.StoreOp) bool {
+	return op.Ctx != nil && op.Ctx.Err() != nil && audit.Categorize(op.Cmd.Cmd) == audit.CategoryRead
+}
+
+// loaded reports whether the keys of the command are loaded. While a backup is restored, the commands on its keys
+// not restored yet are refused rather than reading or writing a value the restore replaces. The keys missing from
+// the backup and the commands without keys are always executed.
+func (shard *ShardThread) loaded(c *cmd.DiceDBCmd) bool {
+	if !shard.loading.Load() {
+		return true
+	}
+	shard.pendingMu.Lock()
+	defer shard.pendingMu.Unlock()
+	for _, key := range cmd.Keys(c) {
+		if _, ok := shard.pending[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// respond sends the response of the Store operation to its io-thread. The response to a disconnected client of
+// an io-thread is dropped rather than blocking the shard, as the io-thread no longer receives the responses. The
+// HTTP and WebSocket servers share a response channel and consume one response per operation, so the responses
+// to their clients are always sent.
+func respond(op *ops.StoreOp, ch chan *ops.StoreResponse, sp *ops.StoreResponse) {
+	if op.Ctx == nil || op.HTTPOp || op.WebsocketOp {
+		ch <- sp
+		return
+	}
+
+	select {
+	case ch <- sp:
+		return
+	default:
+	}
+	select {
+	case ch <- sp:
+	case <-op.Ctx.Done():
+	}
+}
+
+// execute executes the command of the Store operation and records it in the slow log, the latency monitor and the metrics.
+// The command is cancelled once it runs for longer than performance.command_timeout, and the reads once the client disconnects.
+func (shard *ShardThread) execute(op *ops.StoreOp, e *eval.Eval) *eval.EvalResponse {
+	shard.record(op)
+
+	ctx, cancel := commandContext(op)
+	defer cancel()
+	shard.store.SetContext(ctx)
+	defer shard.store.SetContext(nil)
+
+	start := time.Now()
+	resp := shard.executeCommand(op, e)
+	elapsed := time.Since(start)
+	hotkeys.Record(op.Cmd)
+	hotkeys.Invalidate(op.Cmd)
+	shard.replicate(op.Cmd, resp)
+	shard.store.SlowLog().Record(op.Cmd, elapsed, op.ClientAddr)
+	latency.Record(latency.EventCommand, elapsed)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		latency.Record(latency.EventCommandTimeout, elapsed)
+		slog.Warn("command exceeded the command timeout", slog.Int("shard", int(shard.id)),
+			slog.String("cmd", op.Cmd.Cmd), slog.Duration("elapsed", elapsed))
+	}
+	// Commands not migrated yet reply with RESP encoded errors rather than resp.Error
+	failed := resp.Error != nil || audit.ResponseError(resp.Result) != nil
+	metrics.CommandExecuted(op.Cmd.Cmd, failed, elapsed)
+	stats.CommandExecuted(op.Cmd.Cmd, failed, elapsed)
+	return resp
+}
+
+// replicate copies the value of a hot key read by GET, for the io-threads to serve the next reads of the key.
+func (shard *ShardThread) replicate(c *cmd.DiceDBCmd, resp *eval.EvalResponse) {
+	if c.Cmd != "GET" || len(c.Args) != 1 || resp.Error != nil || !hotkeys.Hot(c.Args[0]) {
+		return
+	}
+
+	var expiresAt int64
+	if obj := shard.store.GetNoTouch(c.Args[0]); obj != nil {
+		if exp, ok := dstore.GetExpiry(obj, shard.store); ok {
+			expiresAt = int64(exp)
+		}
+	}
+	hotkeys.Replicate(c.Args[0], resp.Result, expiresAt)
+}
+
+// commandContext returns the context of the command of the Store operation, cancelled after
+// performance.command_timeout if set. The context of a read is cancelled as well once its client
+// disconnects, the writes run to completion.
+func commandContext(op *ops.StoreOp) (context.Context, context.CancelFunc) {
+	parent := context.Background()
+	if op.Ctx != nil && audit.Categorize(op.Cmd.Cmd) == audit.CategoryRead {
+		parent = op.Ctx
+	}
+	if timeout := config.DiceConfig().Performance.CommandTimeout; timeout > 0 {
+		return context.WithTimeout(parent, time.Duration(timeout)*time.Millisecond)
+	}
+	return parent, func() {}
+}
+
+// record appends the command of the Store operation to the journal of the shard, if any.
+func (shard *ShardThread) record(op *ops.StoreOp) {
+	if shard.journal == nil {
+		return
+	}
+
+	err := shard.journal.Record(&journal.Entry{
+		Time:     utils.GetCurrentTime(),
+		ClientID: op.ClientID,
+		Shard:    shard.id,
+		Cmd:      op.Cmd.Cmd,
+		Args:     op.Cmd.Args,
+	})
+	if err != nil {
+		slog.Warn("could not record the command in the journal", slog.Int("shard", int(shard.id)), slog.Any("error", err))
+	}
+}
+
+// executeCommand executes the command of the Store operation, traced as a child of the
+// request span when the request is traced. The keys of the command are measured once it is executed.
+// The keys of a write are given a new version, unless the store versioned them as they were put.
+func (shard *ShardThread) executeCommand(op *ops.StoreOp, e *eval.Eval) *eval.EvalResponse {
+	defer shard.meter.Measure(op.Cmd)
+
+	since := shard.store.VersionClock()
+	resp := shard.traceCommand(op, e)
+	if audit.Categorize(op.Cmd.Cmd) == audit.CategoryWrite && resp.Error == nil && audit.ResponseError(resp.Result) == nil {
+		shard.store.Written(cmd.Keys(op.Cmd), since)
+	}
+	return resp
+}
+
+// traceCommand executes the command of the Store operation, traced as a child of the request span when the
+// request is traced.
+func (shard *ShardThread) traceCommand(op *ops.StoreOp, e *eval.Eval) *eval.EvalResponse {
+	if !op.SpanContext.IsValid() {
+		return e.ExecuteCommand()
+	}
+
+	ctx, span := tracing.Start(tracing.ContextWithSpanContext(op.SpanContext), tracing.SpanEval,
+		attribute.String("db.operation.name", op.Cmd.Cmd), attribute.Int("dicedb.shard.id", int(shard.id)))
+	defer span.End()
+
+	resp := e.ExecuteCommand()
+	if resp.Error != nil {
+		tracing.RecordError(ctx, resp.Error)
+	}
+	return resp
+}
+
+// drain executes the requests still queued when the shard is stopped, so that the writes accepted before
+// a shutdown are applied before the dataset is saved. The frontends are already stopped by then, so the
+// responses are dropped. A transaction already committed or whose commit is queued is applied as well,
+// the operations held back by its lock are executed once it is done.
+func (shard *ShardThread) drain() {
+	for {
+		select {
+		case op := <-shard.ReqChan:
+			shard.drainOp(op)
+		default:
+			if op := shard.popSlow(); op != nil {
+				shard.drainOp(op)
+				continue
+			}
+			if shard.txn != nil {
+				deferred := shard.txn.deferred
+				shard.txn = nil
+				for _, op := range deferred {
+					shard.drainOp(op)
+				}
+			}
+			return
+		}
+	}
+}
+
+func (shard *ShardThread) drainOp(op *ops.StoreOp) {
+	if op.Txn != nil {
+		if shard.txn == nil || shard.txn.id != op.RequestID {
+			// Transactions that do not hold the lock yet are dropped, their coordinator is gone
+			return
+		}
+		switch op.Txn.Phase {
+		case ops.TxnCommit:
+			shard.commitTxn(op)
+		case ops.TxnRelease:
+			deferred := shard.txn.deferred
+			shard.txn = nil
+			for _, op := range deferred {
+				shard.drainOp(op)
+			}
+		}
+		return
+	}
+
+	if shard.txn != nil {
+		shard.txn.deferred = append(shard.txn.deferred, op)
+		return
+	}
+	if op.PreProcessing {
+		return
+	}
+	if op.Batch != nil {
+		shard.executeBatch(op)
+		return
+	}
+	shard.record(op)
+	shard.executeCommand(op, eval.NewEval(op.Cmd, op.Client, shard.store, op.HTTPOp, op.WebsocketOp, false))
 }
 
 // cleanup handles cleanup logic when the shard stops.
 func (shard *ShardThread) cleanup() {
 	close(shard.ReqChan)
-	if !config.DiceConfig.Persistence.Enabled || !config.DiceConfig.Persistence.WriteAOFOnCleanup {
+	if shard.journal != nil {
+		if err := shard.journal.Close(); err != nil {
+			slog.Warn("could not close the journal", slog.Int("shard", int(shard.id)), slog.Any("error", err))
+		}
+	}
+	if tier := shard.store.Tier(); tier != nil {
+		if err := tier.Close(); err != nil {
+			slog.Warn("could not close the disk tier", slog.Int("shard", int(shard.id)), slog.Any("error", err))
+		}
+	}
+	if !config.DiceConfig().Persistence.Enabled || !config.DiceConfig().Persistence.WriteAOFOnCleanup {
 		return
 	}
 }
@@ -0,0 +1,345 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/hotkeys"
+	"github.com/dicedb/dice/internal/journal"
+	"github.com/dicedb/dice/internal/latency"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/stats"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardRecordsJournal(t *testing.T) {
+	withTxnConfig(t)
+
+	shard := newTxnTestShard()
+	j := &journal.Memory{}
+	shard.journal = j
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+
+	commands := [][]string{{"SET", "k", "1"}, {"INCRBY", "k", "9"}, {"GET", "k"}}
+	for _, c := range commands {
+		shard.receive(&ops.StoreOp{IOThreadID: "io", ClientID: 4, Cmd: &cmd.DiceDBCmd{Cmd: c[0], Args: c[1:]}})
+		<-ioChan
+	}
+
+	// Commands applied by a transaction are recorded as well
+	txnChan := make(chan *ops.StoreResponse, 2)
+	shard.receive(newTxnTestOp(1, ops.TxnPrepare, txnChan))
+	shard.receive(newTxnTestOp(1, ops.TxnCommit, txnChan, &cmd.DiceDBCmd{Cmd: "DEL", Args: []string{"k"}}))
+	shard.receive(newTxnTestOp(1, ops.TxnRelease, nil))
+
+	entries := j.Entries()
+	require.Len(t, entries, 4)
+	for i, c := range commands {
+		assert.Equal(t, c[0], entries[i].Cmd)
+		assert.Equal(t, c[1:], entries[i].Args)
+		assert.Equal(t, uint64(4), entries[i].ClientID)
+	}
+	assert.Equal(t, "DEL", entries[3].Cmd)
+
+	// Replaying the journal rebuilds the same store
+	store := dstore.NewStore(nil, dstore.NewDefaultEviction())
+	journal.Replay(store, entries[:2]...)
+	assert.Equal(t, int64(10), journal.Replay(store, &journal.Entry{Cmd: "GET", Args: []string{"k"}})[0].Result)
+	journal.Replay(store, entries[2:]...)
+	assert.Equal(t, 0, store.GetKeyCount())
+}
+
+func TestShardCommandTimeout(t *testing.T) {
+	withTxnConfig(t)
+	previousSlowlog, previousLatency := config.DiceConfig().Slowlog, config.DiceConfig().Latency
+	config.DiceConfig().Performance.CommandTimeout = 50
+	config.DiceConfig().Slowlog.LogSlowerThan = 10000
+	config.DiceConfig().Slowlog.MaxLen = 128
+	config.DiceConfig().Latency.MonitorThreshold = 1
+	t.Cleanup(func() {
+		config.DiceConfig().Slowlog, config.DiceConfig().Latency = previousSlowlog, previousLatency
+		latency.Reset(latency.EventCommand, latency.EventCommandTimeout)
+	})
+
+	shard := newTxnTestShard()
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+
+	start := time.Now()
+	shard.receive(&ops.StoreOp{IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "SLEEP", Args: []string{"5"}}})
+	resp := <-ioChan
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, diceerrors.ErrCommandTimeout, resp.EvalResponse.Error)
+
+	assert.Len(t, latency.GetHistory(latency.EventCommandTimeout), 1)
+	entries := shard.store.SlowLog().Get(1)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"SLEEP", "5"}, entries[0].Args)
+
+	// The next commands get a context of their own
+	shard.receive(&ops.StoreOp{IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}})
+	assert.Equal(t, clientio.OK, (<-ioChan).EvalResponse.Result)
+	assert.NoError(t, shard.store.Context().Err())
+}
+
+func TestShardDisconnectedClient(t *testing.T) {
+	withTxnConfig(t)
+	previousTimeout := config.DiceConfig().Performance.CommandTimeout
+	config.DiceConfig().Performance.CommandTimeout = 0
+	t.Cleanup(func() { config.DiceConfig().Performance.CommandTimeout = previousTimeout })
+
+	shard := newTxnTestShard()
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The writes of a disconnected client are applied, its reads are skipped
+	shard.receive(&ops.StoreOp{IOThreadID: "io", Ctx: ctx, Cmd: &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}})
+	assert.Equal(t, clientio.OK, (<-ioChan).EvalResponse.Result)
+	shard.receive(&ops.StoreOp{IOThreadID: "io", Ctx: ctx, Cmd: &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}})
+	assert.Equal(t, diceerrors.ErrClientDisconnected, (<-ioChan).EvalResponse.Error)
+	shard.receive(&ops.StoreOp{IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}})
+	assert.Equal(t, "v", (<-ioChan).EvalResponse.Result)
+
+	// So is a COPY, received by the shard of the destination as OBJECTCOPY
+	copyObj := &object.InternalObj{Obj: shard.store.Get("k")}
+	shard.receive(&ops.StoreOp{IOThreadID: "io", Ctx: ctx, Cmd: &cmd.DiceDBCmd{Cmd: "OBJECTCOPY", Args: []string{"dst"}, InternalObj: copyObj}})
+	assert.Equal(t, clientio.IntegerOne, (<-ioChan).EvalResponse.Result)
+	assert.NotNil(t, shard.store.Get("dst"))
+
+	// A read in progress is aborted once its client disconnects
+	sleepCtx, sleepCancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, sleepCancel)
+	start := time.Now()
+	shard.receive(&ops.StoreOp{IOThreadID: "io", Ctx: sleepCtx, Cmd: &cmd.DiceDBCmd{Cmd: "SLEEP", Args: []string{"5"}}})
+	<-ioChan
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// The responses to a disconnected client nobody receives anymore are dropped
+	for i := 0; i < cap(ioChan)+1; i++ {
+		shard.receive(&ops.StoreOp{IOThreadID: "io", Ctx: ctx, Cmd: &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}})
+	}
+	assert.Len(t, ioChan, cap(ioChan))
+}
+
+func TestShardReplicatesHotKeys(t *testing.T) {
+	withTxnConfig(t)
+	previous := config.DiceConfig().HotKeys
+	config.DiceConfig().HotKeys.Enabled = true
+	config.DiceConfig().HotKeys.SampleRate = 1
+	config.DiceConfig().HotKeys.TrackedKeys = 16
+	config.DiceConfig().HotKeys.Replicate = true
+	config.DiceConfig().HotKeys.ReplicateMinOps = 10
+	t.Cleanup(func() {
+		config.DiceConfig().HotKeys = previous
+		hotkeys.Reset()
+		hotkeys.DropAll()
+	})
+
+	shard := newTxnTestShard()
+	shard.store.Subscribe(hotkeys.Invalidator{})
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+	execute := func(c ...string) interface{} {
+		shard.receive(&ops.StoreOp{IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: c[0], Args: c[1:]}})
+		return (<-ioChan).EvalResponse.Result
+	}
+
+	execute("SET", "celebrity", "v1", "EX", "100")
+	_, ok := hotkeys.Lookup("celebrity")
+	assert.False(t, ok, "a key is copied once hot only")
+	for i := 0; i < 20; i++ {
+		execute("GET", "celebrity")
+	}
+	value, ok := hotkeys.Lookup("celebrity")
+	require.True(t, ok)
+	assert.Equal(t, "v1", value)
+
+	// The copy is dropped before the write is replied to
+	execute("SET", "celebrity", "v2")
+	_, ok = hotkeys.Lookup("celebrity")
+	assert.False(t, ok)
+	assert.Equal(t, "v2", execute("GET", "celebrity"))
+	value, _ = hotkeys.Lookup("celebrity")
+	assert.Equal(t, "v2", value)
+
+	execute("DEL", "celebrity")
+	_, ok = hotkeys.Lookup("celebrity")
+	assert.False(t, ok)
+}
+
+func TestShardSlowLane(t *testing.T) {
+	withTxnConfig(t)
+	config.DiceConfig().Performance.SlowLaneBudget = 2
+
+	shard := newTxnTestShard()
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+	send := func(id uint32, c ...string) {
+		shard.Send(&ops.StoreOp{RequestID: id, IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: c[0], Args: c[1:]}})
+	}
+	executed := func() []uint32 {
+		for shard.Step() {
+		}
+		var ids []uint32
+		for len(ioChan) > 0 {
+			ids = append(ids, (<-ioChan).RequestID)
+		}
+		return ids
+	}
+
+	// The KEYS of the shard waits for the budget of the commands received after it
+	send(1, "SINGLEKEYS", "*")
+	send(2, "SET", "a", "1")
+	send(3, "SET", "b", "2")
+	send(4, "SET", "c", "3")
+	assert.Equal(t, []uint32{2, 3, 1, 4}, executed())
+
+	// A command of the slow lane is executed as soon as no other one is queued
+	send(5, "SET", "d", "4")
+	send(6, "SMEMBERS", "s")
+	assert.Equal(t, []uint32{5, 6}, executed())
+
+	// The commands of the HTTP and WebSocket servers are executed in the order they are received, their responses
+	// being paired with the requests by their order
+	shard.Send(&ops.StoreOp{RequestID: 10, IOThreadID: "io", HTTPOp: true, Cmd: &cmd.DiceDBCmd{Cmd: "SMEMBERS", Args: []string{"s"}}})
+	shard.Send(&ops.StoreOp{RequestID: 11, IOThreadID: "io", WebsocketOp: true, Cmd: &cmd.DiceDBCmd{Cmd: "HGETALL", Args: []string{"h"}}})
+	send(12, "GET", "a")
+	assert.Equal(t, []uint32{10, 11, 12}, executed())
+
+	// The slow lane is executed when the shard stops
+	send(7, "LRANGE", "l", "0", "-1")
+	send(8, "RPUSH", "l", "v")
+	assert.True(t, shard.Step())
+	assert.Len(t, shard.slowLane.queued, 1)
+	shard.drain()
+	assert.Empty(t, shard.slowLane.queued)
+	assert.Empty(t, shard.ReqChan)
+	assert.NotNil(t, shard.store.Get("l"))
+
+	// Without a budget the commands are executed in the order they are received
+	config.DiceConfig().Performance.SlowLaneBudget = 0
+	send(9, "SINGLEKEYS", "*")
+	send(10, "GET", "a")
+	assert.Equal(t, []uint32{9, 10}, executed())
+}
+
+func TestShardLoading(t *testing.T) {
+	withTxnConfig(t)
+
+	shard := newTxnTestShard()
+	manager := &ShardManager{shards: []*ShardThread{shard}}
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+	exec := func(c ...string) *ops.StoreResponse {
+		shard.receive(&ops.StoreOp{IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: c[0], Args: c[1:]}})
+		return <-ioChan
+	}
+
+	exec("SET", "restored", "v1")
+	manager.StartLoading([]string{"restored", "pending"})
+	manager.Loaded([]string{"restored"})
+
+	// The keys of the backup restored already are served, the other ones are refused until they are restored
+	assert.Equal(t, "v1", exec("GET", "restored").EvalResponse.Result)
+	assert.Equal(t, diceerrors.ErrLoading, exec("GET", "pending").EvalResponse.Error)
+	assert.Equal(t, diceerrors.ErrLoading, exec("SET", "pending", "2").EvalResponse.Error)
+	assert.Equal(t, diceerrors.ErrLoading, exec("MSET", "restored", "3", "pending", "3").EvalResponse.Error)
+	assert.Equal(t, clientio.OK, exec("SET", "restored", "4").EvalResponse.Result)
+
+	// The keys missing from the backup are served as well
+	assert.Equal(t, clientio.OK, exec("SET", "new", "v2").EvalResponse.Result)
+	assert.Equal(t, "v2", exec("GET", "new").EvalResponse.Result)
+
+	// The restore itself writes the keys pending
+	batchChan := make(chan *ops.StoreResponse, 1)
+	shard.receive(&ops.StoreOp{Batch: &ops.BatchOp{
+		Cmds:         []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{"pending", "v5"}}},
+		ResponseChan: batchChan,
+	}})
+	resps := (<-batchChan).EvalResponse.Result.([]*eval.EvalResponse)
+	assert.Equal(t, clientio.OK, resps[0].Result)
+	manager.Loaded([]string{"pending"})
+	assert.Equal(t, "v5", exec("GET", "pending").EvalResponse.Result)
+
+	manager.StartLoading([]string{"other"})
+	assert.Equal(t, diceerrors.ErrLoading, exec("GET", "other").EvalResponse.Error)
+	manager.StopLoading()
+	assert.NoError(t, exec("GET", "other").EvalResponse.Error)
+}
+
+func TestShardRecoversFromCrash(t *testing.T) {
+	withTxnConfig(t)
+	config.DiceConfig().Performance.ShardCrashWindow = time.Minute
+
+	shard := newTxnTestShard()
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		shard.Start(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+
+	exec := func(op *ops.StoreOp) *ops.StoreResponse {
+		shard.Send(op)
+		select {
+		case resp := <-ioChan:
+			return resp
+		case <-time.After(5 * time.Second):
+			t.Fatal("the shard did not reply")
+			return nil
+		}
+	}
+
+	assert.Equal(t, clientio.OK, exec(&ops.StoreOp{IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}}).EvalResponse.Result)
+
+	// An operation without a command crashes the shard, its sender is replied rather than left waiting
+	crashes := stats.Get().ShardCrashes
+	resp := exec(&ops.StoreOp{RequestID: 7, IOThreadID: "io"})
+	assert.Equal(t, uint32(7), resp.RequestID)
+	assert.Equal(t, diceerrors.ErrShardCrashed, resp.EvalResponse.Error)
+	assert.Equal(t, crashes+1, stats.Get().ShardCrashes)
+	assert.Equal(t, stats.HealthDegraded, stats.Health(config.DiceConfig().Performance.ShardCrashWindow))
+	assert.Equal(t, stats.HealthOK, stats.Health(0))
+
+	// The shard is restarted with its store intact
+	assert.Equal(t, "v", exec(&ops.StoreOp{IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}}).EvalResponse.Result)
+
+	// The commands of a batch the shard crashed on all fail
+	batchChan := make(chan *ops.StoreResponse, 1)
+	shard.Send(&ops.StoreOp{Batch: &ops.BatchOp{
+		Cmds:         []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{"a", "1"}}, nil},
+		ResponseChan: batchChan,
+	}})
+	resps := (<-batchChan).EvalResponse.Result.([]*eval.EvalResponse)
+	require.Len(t, resps, 2)
+	for _, r := range resps {
+		assert.Equal(t, diceerrors.ErrShardCrashed, r.Error)
+	}
+}
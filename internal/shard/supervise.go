@@ -0,0 +1,80 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"log/slog"
+	"runtime/debug"
+
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/stats"
+)
+
+// guard runs fn and recovers the shard if it panics. The operation in flight is failed with ErrShardCrashed and
+// the crash is counted, the store of the shard being kept as it is for the next operations.
+func (shard *ShardThread) guard(fn func()) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		stats.ShardCrashed()
+		slog.Error("the shard crashed", slog.Int("shard", int(shard.id)), slog.Any("panic", r),
+			slog.String("stack", string(debug.Stack())))
+		if op := shard.inflight; op != nil {
+			shard.inflight = nil
+			shard.fail(op)
+		}
+	}()
+	fn()
+}
+
+// fail replies to the operation that the shard crashed while processing it, so that its sender does not wait for
+// a response that never comes. Every command of a batch fails, as the shard may have crashed on any of them.
+func (shard *ShardThread) fail(op *ops.StoreOp) {
+	resp := &eval.EvalResponse{Error: diceerrors.ErrShardCrashed}
+	switch {
+	case op.Txn != nil:
+		if op.Txn.ResponseChan != nil {
+			shard.replyTxn(op, resp)
+		}
+	case op.Batch != nil:
+		resps := make([]*eval.EvalResponse, len(op.Batch.Cmds))
+		for i := range resps {
+			resps[i] = resp
+		}
+		op.Batch.ResponseChan <- &ops.StoreResponse{
+			RequestID:    op.RequestID,
+			SeqID:        op.SeqID,
+			EvalResponse: &eval.EvalResponse{Result: resps},
+		}
+	default:
+		shard.mu.RLock()
+		ioChannels, ok := shard.ioThreadMap[op.IOThreadID]
+		shard.mu.RUnlock()
+		if !ok {
+			return
+		}
+		ch := ioChannels.CommonResponseChan
+		if op.PreProcessing {
+			ch = ioChannels.PreProcessingResponseChan
+		}
+		respond(op, ch, &ops.StoreResponse{RequestID: op.RequestID, SeqID: op.SeqID, EvalResponse: resp})
+	}
+}
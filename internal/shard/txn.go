@@ -0,0 +1,326 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/failpoint"
+	"github.com/dicedb/dice/internal/ops"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transactions are executed with a two-phase commit coordinated by the io-thread of the client:
+//
+//  1. prepare: the shards holding keys of the transaction are locked one after the other, in ascending
+//     shard id order so that two transactions can not wait on each other. A locked shard holds back every
+//     other operation until the transaction is released.
+//  2. commit: once every shard is locked, each one executes its commands of the transaction. A transaction with a
+//     guard, e.g. MSETCAS, first has the locked shards execute the commands of the guard and is dropped unless
+//     the guard holds, so that no write can slip in between the check and the commit.
+//  3. release: once every shard has committed, the shards are unlocked and execute the operations they held back.
+//
+// As the shards are released only after all of them committed, no other client can observe the transaction
+// partially applied. A shard whose transaction is not released within performance.txn_lock_timeout unlocks
+// itself, dropping the transaction if it was not committed yet.
+
+// heldTxn is the transaction holding the lock of a shard, between its prepare and its release.
+type heldTxn struct {
+	id        uint32         // id is the RequestID of the operations of the transaction
+	lockedAt  time.Time      // lockedAt is the time at which the shard was locked
+	committed bool           // committed is true once the commands of the transaction are executed
+	deferred  []*ops.StoreOp // deferred are the operations received while locked, in the order they were received
+}
+
+// Txn is a transaction to execute atomically across the shards.
+type Txn struct {
+	ID          uint32            // ID is the RequestID of the transaction, identifying the transaction holding the lock of a shard
+	IOThreadID  string            // IOThreadID is the io-thread coordinating the transaction
+	ClientAddr  string            // ClientAddr is the remote address of the client
+	ClientID    uint64            // ClientID is the id of the client
+	SpanContext trace.SpanContext // SpanContext of the request span
+	Cmds        []*cmd.DiceDBCmd  // Cmds are the commands of the transaction, in the order they were queued
+	Shards      []ShardID         // Shards[i] is the shard executing Cmds[i]
+	Guard       *Guard            // Guard is the condition of the transaction, nil to apply it unconditionally
+}
+
+// Guard is the condition a transaction is applied on, checked once its shards are locked.
+type Guard struct {
+	Cmds   []*cmd.DiceDBCmd // Cmds are the read-only commands whose responses decide whether the transaction is applied
+	Shards []ShardID        // Shards[i] is the shard executing Cmds[i]
+	// Check is passed the responses of Cmds in their order, the transaction is dropped if it returns an error
+	Check func(resps []*eval.EvalResponse) error
+}
+
+// ExecTxn executes the commands of the transaction with a two-phase commit and returns their responses in
+// the order the commands were queued. An error means the transaction was not applied, unless ctx is done
+// once the commit is sent: the transaction is then applied but its responses are lost. A transaction whose
+// guard does not hold is not applied, the error returned by the guard being returned.
+func (manager *ShardManager) ExecTxn(ctx context.Context, txn *Txn) ([]*eval.EvalResponse, error) {
+	// Group the commands by shard, keeping the order in which they were queued
+	cmdsByShard := groupByShard(txn.Shards)
+	var checksByShard map[ShardID][]int
+	if txn.Guard != nil {
+		checksByShard = groupByShard(txn.Guard.Shards)
+	}
+	shardIDs := make([]ShardID, 0, len(cmdsByShard)+len(checksByShard))
+	for id := range cmdsByShard {
+		shardIDs = append(shardIDs, id)
+	}
+	for id := range checksByShard {
+		if _, ok := cmdsByShard[id]; !ok {
+			shardIDs = append(shardIDs, id)
+		}
+	}
+	slices.Sort(shardIDs)
+
+	// Every shard replies at most three times, so that a shard never blocks on the responses of a transaction given up on
+	responseChan := make(chan *ops.StoreResponse, 3*len(shardIDs))
+
+	// The shards are released whatever the outcome, which drops the transaction if it was not committed
+	locked := make([]ShardID, 0, len(shardIDs))
+	defer func() {
+		for _, id := range locked {
+			manager.shards[id].Send(txnOp(txn, id, ops.TxnRelease, nil, nil))
+		}
+	}()
+
+	// A shard locked first must not unlock itself while the next ones are being locked
+	lockCtx, cancel := context.WithTimeout(ctx, config.DiceConfig().Performance.TxnLockTimeout/2)
+	defer cancel()
+
+	for _, id := range shardIDs {
+		manager.shards[id].Send(txnOp(txn, id, ops.TxnPrepare, nil, responseChan))
+		// A prepare still waiting for the lock when giving up is cancelled by the release
+		locked = append(locked, id)
+		if _, err := awaitTxnResponses(lockCtx, responseChan, 1); err != nil {
+			return nil, diceerrors.ErrTxnLockTimeout
+		}
+	}
+
+	if txn.Guard != nil {
+		checks, err := manager.execTxnPhase(ctx, txn, ops.TxnCheck, txn.Guard.Cmds, checksByShard, responseChan)
+		if err != nil {
+			return nil, err
+		}
+		if err := txn.Guard.Check(checks); err != nil {
+			return nil, err
+		}
+	}
+
+	return manager.execTxnPhase(ctx, txn, ops.TxnCommit, txn.Cmds, cmdsByShard, responseChan)
+}
+
+// execTxnPhase has the locked shards execute their commands of the transaction in the phase, check or commit,
+// and returns the responses in the order of cmds.
+func (manager *ShardManager) execTxnPhase(ctx context.Context, txn *Txn, phase ops.TxnPhase, cmds []*cmd.DiceDBCmd,
+	cmdsByShard map[ShardID][]int, responseChan chan *ops.StoreResponse) ([]*eval.EvalResponse, error) {
+	for id, indexes := range cmdsByShard {
+		shardCmds := make([]*cmd.DiceDBCmd, 0, len(indexes))
+		for _, i := range indexes {
+			shardCmds = append(shardCmds, cmds[i])
+		}
+		manager.shards[id].Send(txnOp(txn, id, phase, shardCmds, responseChan))
+	}
+
+	resps, err := awaitTxnResponses(ctx, responseChan, len(cmdsByShard))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*eval.EvalResponse, len(cmds))
+	for _, resp := range resps {
+		if resp.EvalResponse.Error != nil {
+			return nil, resp.EvalResponse.Error
+		}
+		for j, i := range cmdsByShard[resp.SeqID] {
+			results[i] = resp.EvalResponse.Result.([]*eval.EvalResponse)[j]
+		}
+	}
+	return results, nil
+}
+
+// groupByShard returns the indexes of the commands executed by every shard, in ascending order.
+func groupByShard(shards []ShardID) map[ShardID][]int {
+	byShard := make(map[ShardID][]int)
+	for i, id := range shards {
+		byShard[id] = append(byShard[id], i)
+	}
+	return byShard
+}
+
+func txnOp(txn *Txn, id ShardID, phase ops.TxnPhase, cmds []*cmd.DiceDBCmd, responseChan chan *ops.StoreResponse) *ops.StoreOp {
+	return &ops.StoreOp{
+		SeqID:       id,
+		RequestID:   txn.ID,
+		ShardID:     id,
+		IOThreadID:  txn.IOThreadID,
+		ClientAddr:  txn.ClientAddr,
+		ClientID:    txn.ClientID,
+		SpanContext: txn.SpanContext,
+		Txn:         &ops.TxnOp{Phase: phase, Cmds: cmds, ResponseChan: responseChan},
+	}
+}
+
+// awaitTxnResponses waits for n responses of the shards to the transaction.
+func awaitTxnResponses(ctx context.Context, responseChan chan *ops.StoreResponse, n int) ([]*ops.StoreResponse, error) {
+	resps := make([]*ops.StoreResponse, 0, n)
+	for len(resps) < n {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case resp := <-responseChan:
+			resps = append(resps, resp)
+		}
+	}
+	return resps, nil
+}
+
+// receive processes an operation received by the shard. The operation is left in flight if processing it panics,
+// so that the shard fails it once recovered.
+func (shard *ShardThread) receive(op *ops.StoreOp) {
+	previous := shard.inflight
+	shard.inflight = op
+	shard.process(op)
+	shard.inflight = previous
+}
+
+// process processes the operation, holding it back if the shard is locked by a transaction.
+func (shard *ShardThread) process(op *ops.StoreOp) {
+	failpoint.Eval(failpoint.ShardDelay)
+
+	if op.Txn != nil {
+		shard.processTxnOp(op)
+		return
+	}
+
+	if shard.txn != nil {
+		shard.txn.deferred = append(shard.txn.deferred, op)
+		return
+	}
+	shard.processRequest(op)
+}
+
+// processTxnOp processes a step of the two-phase commit of a transaction.
+func (shard *ShardThread) processTxnOp(op *ops.StoreOp) {
+	switch op.Txn.Phase {
+	case ops.TxnPrepare:
+		if shard.txn != nil {
+			// The shard is locked by another transaction, the lock is taken once it is released
+			shard.txn.deferred = append(shard.txn.deferred, op)
+			return
+		}
+		shard.txn = &heldTxn{id: op.RequestID, lockedAt: time.Now()}
+		shard.replyTxn(op, &eval.EvalResponse{Result: clientio.OK})
+
+	case ops.TxnCheck:
+		if shard.txn == nil || shard.txn.id != op.RequestID || shard.txn.committed {
+			shard.replyTxn(op, &eval.EvalResponse{Error: diceerrors.ErrTxnLockExpired})
+			return
+		}
+		shard.replyTxn(op, &eval.EvalResponse{Result: shard.executeTxn(op)})
+
+	case ops.TxnCommit:
+		if shard.txn == nil || shard.txn.id != op.RequestID || shard.txn.committed {
+			shard.replyTxn(op, &eval.EvalResponse{Error: diceerrors.ErrTxnLockExpired})
+			return
+		}
+		shard.replyTxn(op, &eval.EvalResponse{Result: shard.commitTxn(op)})
+
+	case ops.TxnRelease:
+		shard.releaseTxn(op.RequestID)
+	}
+}
+
+// commitTxn executes the commands of the transaction holding the lock and returns their responses.
+func (shard *ShardThread) commitTxn(op *ops.StoreOp) []*eval.EvalResponse {
+	resps := shard.executeTxn(op)
+	shard.txn.committed = true
+	return resps
+}
+
+// executeTxn executes the commands of a step of the transaction holding the lock and returns their responses.
+func (shard *ShardThread) executeTxn(op *ops.StoreOp) []*eval.EvalResponse {
+	resps := make([]*eval.EvalResponse, 0, len(op.Txn.Cmds))
+	for _, c := range op.Txn.Cmds {
+		cmdOp := &ops.StoreOp{
+			RequestID:   op.RequestID,
+			Cmd:         c,
+			ShardID:     op.ShardID,
+			IOThreadID:  op.IOThreadID,
+			ClientAddr:  op.ClientAddr,
+			ClientID:    op.ClientID,
+			SpanContext: op.SpanContext,
+		}
+		resps = append(resps, shard.execute(cmdOp, eval.NewEval(c, nil, shard.store, false, false, false)))
+	}
+	return resps
+}
+
+// releaseTxn unlocks the shard if it is locked by the transaction and processes the operations held back.
+// If another transaction holds the lock, the prepare of the released one is withdrawn from the waiting operations.
+func (shard *ShardThread) releaseTxn(id uint32) {
+	if shard.txn == nil {
+		return
+	}
+
+	if shard.txn.id != id {
+		shard.txn.deferred = slices.DeleteFunc(shard.txn.deferred, func(op *ops.StoreOp) bool {
+			return op.Txn != nil && op.RequestID == id
+		})
+		return
+	}
+
+	deferred := shard.txn.deferred
+	shard.txn = nil
+	for _, op := range deferred {
+		// A deferred prepare locks the shard again, the operations following it are held back anew. An operation
+		// crashing the shard is failed on its own, the ones following it are processed all the same.
+		shard.guard(func() { shard.receive(op) })
+	}
+}
+
+// expireTxn releases the lock of a transaction held for longer than performance.txn_lock_timeout,
+// in case its io-thread never released it.
+func (shard *ShardThread) expireTxn() {
+	if time.Since(shard.txn.lockedAt) < config.DiceConfig().Performance.TxnLockTimeout {
+		return
+	}
+
+	slog.Warn("releasing the lock of a transaction that was not released in time",
+		slog.Int("shard", int(shard.id)),
+		slog.Any("txn", shard.txn.id),
+		slog.Bool("committed", shard.txn.committed))
+	shard.releaseTxn(shard.txn.id)
+}
+
+// replyTxn sends the response to a step of a transaction to the io-thread coordinating it.
+func (shard *ShardThread) replyTxn(op *ops.StoreOp, resp *eval.EvalResponse) {
+	op.Txn.ResponseChan <- &ops.StoreResponse{
+		RequestID:    op.RequestID,
+		SeqID:        op.SeqID,
+		EvalResponse: resp,
+	}
+}
@@ -0,0 +1,246 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/dicedb/dice/internal/cmd"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withTxnConfig(t *testing.T) {
+	previous := config.DiceConfig().Performance
+	previousKeysLimit := config.DiceConfig().Memory.KeysLimit
+	config.DiceConfig().Performance.ShardCronFrequency = time.Second
+	config.DiceConfig().Performance.TxnLockTimeout = 10 * time.Second
+	config.DiceConfig().Memory.KeysLimit = config.DefaultKeysLimit
+	t.Cleanup(func() {
+		config.DiceConfig().Performance = previous
+		config.DiceConfig().Memory.KeysLimit = previousKeysLimit
+	})
+}
+
+func newTxnTestShard() *ShardThread {
+	shard := NewShardThread(0, nil, nil, nil, dstore.NewDefaultEviction())
+	shard.registerIOThread("io", make(chan *ops.StoreResponse, 10), make(chan *ops.StoreResponse, 10))
+	return shard
+}
+
+func newTxnTestOp(id uint32, phase ops.TxnPhase, responseChan chan *ops.StoreResponse, cmds ...*cmd.DiceDBCmd) *ops.StoreOp {
+	return txnOp(&Txn{ID: id, IOThreadID: "io"}, 0, phase, cmds, responseChan)
+}
+
+func TestExecTxnAcrossShards(t *testing.T) {
+	withTxnConfig(t)
+
+	manager := NewShardManager(4, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	manager.start(ctx, &wg)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	// One key per shard
+	keys := make(map[ShardID]string)
+	for i := 0; len(keys) < 4; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if id, _ := manager.GetShardInfo(key); keys[id] == "" {
+			keys[id] = key
+		}
+	}
+
+	txn := &Txn{ID: 1, IOThreadID: "io"}
+	for _, id := range []ShardID{3, 0, 2, 1, 3} {
+		txn.Cmds = append(txn.Cmds, &cmd.DiceDBCmd{Cmd: "INCR", Args: []string{keys[id]}})
+		txn.Shards = append(txn.Shards, id)
+	}
+
+	resps, err := manager.ExecTxn(context.Background(), txn)
+	require.NoError(t, err)
+	require.Len(t, resps, 5)
+	for i, want := range []int64{1, 1, 1, 1, 2} {
+		assert.Equal(t, want, resps[i].Result, "result of command %d", i)
+	}
+}
+
+func TestExecTxnGuard(t *testing.T) {
+	withTxnConfig(t)
+
+	manager := NewShardManager(4, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	manager.start(ctx, &wg)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	// The guard reads a key of a shard the transaction does not write
+	checked, written := "k0", ""
+	checkedShard, _ := manager.GetShardInfo(checked)
+	var writtenShard ShardID
+	for i := 1; written == ""; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if id, _ := manager.GetShardInfo(key); id != checkedShard {
+			written, writtenShard = key, id
+		}
+	}
+
+	errMismatch := errors.New("mismatch")
+	exec := func(id uint32) error {
+		_, err := manager.ExecTxn(context.Background(), &Txn{
+			ID:         id,
+			IOThreadID: "io",
+			Cmds:       []*cmd.DiceDBCmd{{Cmd: "INCR", Args: []string{written}}},
+			Shards:     []ShardID{writtenShard},
+			Guard: &Guard{
+				Cmds:   []*cmd.DiceDBCmd{{Cmd: "GET", Args: []string{checked}}},
+				Shards: []ShardID{checkedShard},
+				Check: func(resps []*eval.EvalResponse) error {
+					if resps[0].Result != "go" {
+						return errMismatch
+					}
+					return nil
+				},
+			},
+		})
+		return err
+	}
+
+	require.ErrorIs(t, exec(1), errMismatch)
+
+	resps, err := manager.ExecTxn(context.Background(), &Txn{
+		ID:         2,
+		IOThreadID: "io",
+		Cmds:       []*cmd.DiceDBCmd{{Cmd: "SET", Args: []string{checked, "go"}}, {Cmd: "GET", Args: []string{written}}},
+		Shards:     []ShardID{checkedShard, writtenShard},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, clientio.NIL, resps[1].Result, "the transaction with a guard not holding was applied")
+
+	require.NoError(t, exec(3))
+	resps, err = manager.ExecTxn(context.Background(), &Txn{
+		ID:         4,
+		IOThreadID: "io",
+		Cmds:       []*cmd.DiceDBCmd{{Cmd: "GET", Args: []string{written}}},
+		Shards:     []ShardID{writtenShard},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resps[0].Result)
+}
+
+func TestTxnLockHoldsBackOtherOps(t *testing.T) {
+	withTxnConfig(t)
+
+	shard := newTxnTestShard()
+	txnChan := make(chan *ops.StoreResponse, 2)
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+
+	shard.receive(newTxnTestOp(1, ops.TxnPrepare, txnChan))
+	assert.Equal(t, clientio.OK, (<-txnChan).EvalResponse.Result)
+
+	// Operations received while locked are held back, including the prepare of another transaction
+	shard.receive(&ops.StoreOp{RequestID: 10, IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k"}}})
+	otherChan := make(chan *ops.StoreResponse, 2)
+	shard.receive(newTxnTestOp(2, ops.TxnPrepare, otherChan))
+	assert.Empty(t, ioChan)
+	assert.Empty(t, otherChan)
+
+	shard.receive(newTxnTestOp(1, ops.TxnCommit, txnChan, &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}))
+	resp := <-txnChan
+	require.NoError(t, resp.EvalResponse.Error)
+	assert.Len(t, resp.EvalResponse.Result, 1)
+	assert.Empty(t, ioChan)
+
+	// Releasing a transaction waiting for the lock withdraws its prepare
+	shard.receive(newTxnTestOp(2, ops.TxnRelease, nil))
+	shard.receive(newTxnTestOp(1, ops.TxnRelease, nil))
+	assert.Nil(t, shard.txn)
+	assert.Empty(t, otherChan)
+
+	get := <-ioChan
+	assert.Equal(t, uint32(10), get.RequestID)
+	assert.Equal(t, "v", get.EvalResponse.Result)
+}
+
+func TestTxnReleaseRecoversDeferredCrash(t *testing.T) {
+	withTxnConfig(t)
+
+	shard := newTxnTestShard()
+	txnChan := make(chan *ops.StoreResponse, 2)
+	ioChan := shard.ioThreadMap["io"].CommonResponseChan
+
+	shard.receive(newTxnTestOp(1, ops.TxnPrepare, txnChan))
+	assert.Equal(t, clientio.OK, (<-txnChan).EvalResponse.Result)
+
+	// The operation without a command crashes the shard once the lock is released
+	shard.receive(&ops.StoreOp{RequestID: 10, IOThreadID: "io"})
+	shard.receive(&ops.StoreOp{RequestID: 11, IOThreadID: "io", Cmd: &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}})
+	otherChan := make(chan *ops.StoreResponse, 2)
+	shard.receive(newTxnTestOp(2, ops.TxnPrepare, otherChan))
+	assert.Empty(t, ioChan)
+
+	release := newTxnTestOp(1, ops.TxnRelease, nil)
+	shard.guard(func() { shard.receive(release) })
+	assert.Nil(t, shard.inflight)
+
+	// The operations held back after the one crashing the shard are replied all the same
+	crashed := <-ioChan
+	assert.Equal(t, uint32(10), crashed.RequestID)
+	assert.Equal(t, diceerrors.ErrShardCrashed, crashed.EvalResponse.Error)
+	set := <-ioChan
+	assert.Equal(t, uint32(11), set.RequestID)
+	assert.Equal(t, clientio.OK, set.EvalResponse.Result)
+	assert.Equal(t, clientio.OK, (<-otherChan).EvalResponse.Result)
+	require.NotNil(t, shard.txn)
+	assert.Equal(t, uint32(2), shard.txn.id)
+}
+
+func TestTxnLockExpires(t *testing.T) {
+	withTxnConfig(t)
+
+	shard := newTxnTestShard()
+	txnChan := make(chan *ops.StoreResponse, 2)
+
+	shard.receive(newTxnTestOp(1, ops.TxnPrepare, txnChan))
+	<-txnChan
+
+	shard.RunCronTasks()
+	assert.NotNil(t, shard.txn)
+
+	shard.txn.lockedAt = time.Now().Add(-config.DiceConfig().Performance.TxnLockTimeout)
+	shard.RunCronTasks()
+	assert.Nil(t, shard.txn)
+
+	shard.receive(newTxnTestOp(1, ops.TxnCommit, txnChan, &cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k", "v"}}))
+	assert.ErrorIs(t, (<-txnChan).EvalResponse.Error, diceerrors.ErrTxnLockExpired)
+}
@@ -0,0 +1,91 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package shutdown describes a request to stop the server, made with the SHUTDOWN command
+// from any frontend or with a signal, and carried to main which stops the server in order.
+package shutdown
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+)
+
+// Mode decides whether a final snapshot of the dataset is written before exiting.
+type Mode int
+
+const (
+	// Default saves the dataset only if persistence is enabled with persistence.write_aof_on_cleanup
+	Default Mode = iota
+	// Save always saves the dataset, SHUTDOWN SAVE
+	Save
+	// NoSave never saves the dataset, SHUTDOWN NOSAVE
+	NoSave
+)
+
+// Exit codes of the server once it has stopped.
+const (
+	ExitOK         = 0
+	ExitSaveFailed = 1
+	// ExitForced is the exit code of a server that received a second SIGTERM or SIGINT while shutting down
+	ExitForced = 2
+)
+
+// Request asks the server to shut down. It is sent as an error on the global error channel and wraps
+// ErrAborted, so that anything stopping on ABORT stops on SHUTDOWN as well.
+type Request struct {
+	Mode Mode
+}
+
+func (r *Request) Error() string {
+	return "server received SHUTDOWN command"
+}
+
+func (r *Request) Unwrap() error {
+	return diceerrors.ErrAborted
+}
+
+// ShouldSave reports whether the dataset must be saved before exiting.
+func (r *Request) ShouldSave() bool {
+	switch r.Mode {
+	case Save:
+		return true
+	case NoSave:
+		return false
+	default:
+		return config.DiceConfig().Persistence.Enabled && config.DiceConfig().Persistence.WriteAOFOnCleanup
+	}
+}
+
+// ParseArgs parses the arguments of SHUTDOWN [NOSAVE|SAVE].
+func ParseArgs(args []string) (*Request, error) {
+	if len(args) > 1 {
+		return nil, diceerrors.ErrSyntax
+	}
+	if len(args) == 0 {
+		return &Request{Mode: Default}, nil
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "SAVE":
+		return &Request{Mode: Save}, nil
+	case "NOSAVE":
+		return &Request{Mode: NoSave}, nil
+	default:
+		return nil, diceerrors.ErrSyntax
+	}
+}
@@ -0,0 +1,67 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package shutdown
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		mode Mode
+		err  error
+	}{
+		{args: nil, mode: Default},
+		{args: []string{"save"}, mode: Save},
+		{args: []string{"NOSAVE"}, mode: NoSave},
+		{args: []string{"NOW"}, err: diceerrors.ErrSyntax},
+		{args: []string{"SAVE", "NOSAVE"}, err: diceerrors.ErrSyntax},
+	} {
+		req, err := ParseArgs(tc.args)
+		if tc.err != nil {
+			assert.Equal(t, tc.err, err, "args %v", tc.args)
+			continue
+		}
+		assert.NoError(t, err, "args %v", tc.args)
+		assert.Equal(t, tc.mode, req.Mode, "args %v", tc.args)
+	}
+}
+
+func TestShouldSave(t *testing.T) {
+	previous := config.DiceConfig().Persistence
+	defer func() { config.DiceConfig().Persistence = previous }()
+
+	config.DiceConfig().Persistence.Enabled = true
+	config.DiceConfig().Persistence.WriteAOFOnCleanup = false
+	assert.False(t, (&Request{Mode: Default}).ShouldSave())
+	assert.True(t, (&Request{Mode: Save}).ShouldSave())
+
+	config.DiceConfig().Persistence.WriteAOFOnCleanup = true
+	assert.True(t, (&Request{Mode: Default}).ShouldSave())
+	assert.False(t, (&Request{Mode: NoSave}).ShouldSave())
+}
+
+func TestRequestIsAnAbort(t *testing.T) {
+	var err error = &Request{Mode: NoSave}
+	assert.True(t, errors.Is(err, diceerrors.ErrAborted))
+}
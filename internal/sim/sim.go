@@ -0,0 +1,419 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package sim runs the shards, their expiry cycles, the clients blocked on keys and the watch manager on a
+// virtual clock, every step being taken by a scheduler in an order drawn from a seeded source. A test
+// driving the server through a Sim neither sleeps nor depends on the Go scheduler: the keys expire, the
+// blocked clients time out and the LRU ages only when the test advances the clock, and a seed replays the
+// same interleaving of the shards every run.
+//
+// The Sim installs its clock as the clock of the server for the duration of the test, the tests using a
+// Sim must therefore not run in parallel.
+package sim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/ops"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/dicedb/dice/internal/shard"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/watchmanager"
+)
+
+const (
+	// DefaultTick is the step of the clock when the Sim advances it, the resolution of the timeouts of the
+	// blocked clients
+	DefaultTick = 10 * time.Millisecond
+	// waitTimeout bounds the real time a test waits on a blocked client, so that a bug fails the test
+	// rather than hanging it
+	waitTimeout = 10 * time.Second
+)
+
+// DefaultStart is the time the virtual clock starts at unless told otherwise.
+var DefaultStart = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Options configures a Sim.
+type Options struct {
+	Shards    int           // Shards is the number of shards, 1 if unset
+	Seed      int64         // Seed seeds the order in which the scheduler steps the shards
+	Start     time.Time     // Start is the time the clock starts at, DefaultStart if unset
+	Tick      time.Duration // Tick is the step of the clock, DefaultTick if unset
+	KeysLimit int           // KeysLimit is the number of keys of a shard before evicting, the configured limit if unset
+}
+
+// Clock is a virtual clock, only moving when the Sim advances it. It implements utils.Clock.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now returns the time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
+
+// Sim drives shards, their blocked clients and a watch manager on a virtual clock. Its methods are safe for
+// concurrent use, the scheduler taking one step at a time, so that the serve function of a blocked client
+// may execute commands.
+type Sim struct {
+	t             testing.TB
+	mu            sync.Mutex
+	clock         *Clock
+	rnd           *rand.Rand
+	tick          time.Duration
+	cronFrequency time.Duration
+	nextCron      time.Time
+	shards        *shard.ShardManager
+	watch         *watchmanager.Manager
+	subscriptions chan watchmanager.WatchSubscription
+	watchers      []*Watcher
+	ctx           context.Context // ctx is canceled once the test is done, releasing the blocked clients
+}
+
+// New returns a Sim running on a clock started at opts.Start. The clock of the server and the keys limit are
+// restored once the test is done.
+func New(t testing.TB, opts Options) *Sim {
+	if opts.Shards <= 0 {
+		opts.Shards = 1
+	}
+	if opts.Start.IsZero() {
+		opts.Start = DefaultStart
+	}
+	if opts.Tick <= 0 {
+		opts.Tick = DefaultTick
+	}
+
+	clock := &Clock{now: opts.Start}
+	previousClock := utils.CurrentTime
+	utils.CurrentTime = clock
+	previousMemory, previousPerformance := config.DiceConfig().Memory, config.DiceConfig().Performance
+	configure(opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		utils.CurrentTime = previousClock
+		config.DiceConfig().Memory, config.DiceConfig().Performance = previousMemory, previousPerformance
+	})
+
+	cmdWatchChan := make(chan dstore.CmdWatchEvent, config.DiceConfig().Performance.WatchChanBufSize)
+	subscriptions := make(chan watchmanager.WatchSubscription, 64)
+	cronFrequency := config.DiceConfig().Performance.ShardCronFrequency
+	return &Sim{
+		t:             t,
+		clock:         clock,
+		rnd:           rand.New(rand.NewSource(opts.Seed)), //nolint:gosec // the source is seeded to be replayed
+		tick:          opts.Tick,
+		cronFrequency: cronFrequency,
+		nextCron:      opts.Start.Add(cronFrequency),
+		shards:        shard.NewShardManager(uint8(opts.Shards), cmdWatchChan, make(chan error, 1)),
+		watch:         watchmanager.NewManager(subscriptions, cmdWatchChan),
+		subscriptions: subscriptions,
+		ctx:           ctx,
+	}
+}
+
+// configure fills in the settings the shards depend on, left unset when the configuration is not loaded, e.g.
+// in unit tests.
+func configure(opts Options) {
+	if opts.KeysLimit > 0 {
+		config.DiceConfig().Memory.KeysLimit = opts.KeysLimit * opts.Shards
+	} else if config.DiceConfig().Memory.KeysLimit == 0 {
+		config.DiceConfig().Memory.KeysLimit = config.DefaultKeysLimit
+	}
+	if config.DiceConfig().Memory.EvictionRatio == 0 {
+		config.DiceConfig().Memory.EvictionRatio = config.DefaultEvictionRatio
+	}
+	if config.DiceConfig().Performance.ShardCronFrequency <= 0 {
+		config.DiceConfig().Performance.ShardCronFrequency = time.Second
+	}
+	if config.DiceConfig().Performance.WatchChanBufSize <= 0 {
+		config.DiceConfig().Performance.WatchChanBufSize = 20000
+	}
+}
+
+// Clock returns the virtual clock of the Sim.
+func (s *Sim) Clock() *Clock {
+	return s.clock
+}
+
+// Now returns the time of the virtual clock.
+func (s *Sim) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Call is a command submitted to the shards.
+type Call struct {
+	Cmd  *cmd.DiceDBCmd
+	done chan *ops.StoreResponse
+	resp *eval.EvalResponse
+}
+
+// Done reports whether the shard executed the command.
+func (c *Call) Done() bool {
+	if c.resp != nil {
+		return true
+	}
+	select {
+	case r := <-c.done:
+		c.resp = r.EvalResponse.Result.([]*eval.EvalResponse)[0]
+		return true
+	default:
+		return false
+	}
+}
+
+// Response returns the response of the command, nil while the shard has not executed it.
+func (c *Call) Response() *eval.EvalResponse {
+	c.Done()
+	return c.resp
+}
+
+// Submit submits the command to the shard of its key, its first argument, without executing it. The
+// commands submitted are executed by the next steps of the scheduler, the ones of a shard in the order they
+// were submitted and the shards interleaved in the order drawn from the seed.
+func (s *Sim) Submit(name string, args ...string) *Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.submit(&cmd.DiceDBCmd{Cmd: strings.ToUpper(name), Args: args})
+}
+
+func (s *Sim) submit(c *cmd.DiceDBCmd) *Call {
+	id := shard.ShardID(0)
+	if len(c.Args) > 0 {
+		id, _ = s.shards.GetShardInfo(c.Args[0])
+	}
+
+	call := &Call{Cmd: c, done: make(chan *ops.StoreResponse, 1)}
+	s.shards.GetShard(id).ReqChan <- &ops.StoreOp{
+		SeqID:   id,
+		ShardID: id,
+		Batch:   &ops.BatchOp{Cmds: []*cmd.DiceDBCmd{c}, ResponseChan: call.done},
+	}
+	return call
+}
+
+// Do executes the command on the shard of its key and returns its response, running the scheduler until
+// idle.
+func (s *Sim) Do(name string, args ...string) *eval.EvalResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	call := s.submit(&cmd.DiceDBCmd{Cmd: strings.ToUpper(name), Args: args})
+	s.runUntilIdle()
+	if !call.Done() {
+		s.t.Fatalf("sim: %s was not executed", call.Cmd.Repr())
+	}
+	return call.Response()
+}
+
+// Step takes a single step of the scheduler: it hands the pending watch subscriptions and events to the watch
+// manager, submits the commands of the notified watchers again and executes the next operation of one of the
+// shards having some, drawn from the seed. It reports whether there was anything to do.
+func (s *Sim) Step() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.step()
+}
+
+// RunUntilIdle steps the scheduler until there is nothing left to do, without advancing the clock.
+func (s *Sim) RunUntilIdle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runUntilIdle()
+}
+
+func (s *Sim) runUntilIdle() {
+	for s.step() {
+	}
+}
+
+func (s *Sim) step() bool {
+	progress := s.watch.Pump() > 0
+	for _, w := range s.watchers {
+		if w.notify() {
+			progress = true
+		}
+	}
+
+	count := int(s.shards.GetShardCount())
+	pending := make([]*shard.ShardThread, 0, count)
+	for i := 0; i < count; i++ {
+		if th := s.shards.GetShard(shard.ShardID(i)); len(th.ReqChan) > 0 {
+			pending = append(pending, th)
+		}
+	}
+	if len(pending) > 0 {
+		// A single operation per step, so that the seed decides how the shards interleave
+		pending[s.rnd.Intn(len(pending))].Step()
+		progress = true
+	}
+	return progress
+}
+
+// Advance moves the clock forward by d, one tick at a time. Every tick expires the blocked clients whose
+// timeout elapsed, runs the cron tasks of the shards, in the order drawn from the seed, when they are due
+// and runs the scheduler until idle.
+func (s *Sim) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runUntilIdle()
+	end := s.clock.Now().Add(d)
+	for now := s.clock.Now(); now.Before(end); {
+		now = now.Add(s.tick)
+		if now.After(end) {
+			now = end
+		}
+		s.clock.set(now)
+		s.shards.Blocking().Advance(now)
+		for !s.nextCron.After(now) {
+			for _, i := range s.rnd.Perm(int(s.shards.GetShardCount())) {
+				s.shards.GetShard(shard.ShardID(i)).RunCronTasks()
+			}
+			s.nextCron = s.nextCron.Add(s.cronFrequency)
+		}
+		s.runUntilIdle()
+	}
+}
+
+// Watcher is a watched command, executed again every time the watch manager notifies it of a change, as
+// the io-thread of a GET.WATCH client does.
+type Watcher struct {
+	sim           *Sim
+	cmd           *cmd.DiceDBCmd
+	notifications chan watchmanager.Notification
+	calls         []*Call
+}
+
+// Watch subscribes to the command, e.g. Watch("GET", "k") for GET.WATCH k, and executes it once.
+func (s *Sim) Watch(name string, args ...string) *Watcher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := &Watcher{
+		sim:           s,
+		cmd:           &cmd.DiceDBCmd{Cmd: strings.ToUpper(name), Args: args},
+		notifications: make(chan watchmanager.Notification, 1024),
+	}
+	s.subscriptions <- watchmanager.WatchSubscription{Subscribe: true, AdhocReqChan: w.notifications, WatchCmd: w.cmd}
+	s.watchers = append(s.watchers, w)
+	w.calls = append(w.calls, s.submit(w.cmd))
+	s.runUntilIdle()
+	return w
+}
+
+// notify submits the command again for every pending notification and reports whether there was any.
+func (w *Watcher) notify() bool {
+	notified := false
+	for {
+		select {
+		case <-w.notifications:
+			w.calls = append(w.calls, w.sim.submit(w.cmd))
+			notified = true
+		default:
+			return notified
+		}
+	}
+}
+
+// Responses returns the responses of the watched command executed so far, the first one being the one of
+// the subscription.
+func (w *Watcher) Responses() []*eval.EvalResponse {
+	w.sim.mu.Lock()
+	defer w.sim.mu.Unlock()
+
+	resps := make([]*eval.EvalResponse, 0, len(w.calls))
+	for _, call := range w.calls {
+		if call.Done() {
+			resps = append(resps, call.Response())
+		}
+	}
+	return resps
+}
+
+// Blocked is a client blocked on keys, e.g. by BLPOP.
+type Blocked struct {
+	done chan struct{}
+	err  error
+}
+
+// Block blocks a client on the keys, as blocking.Manager.Block does, and returns once it is queued. serve
+// runs on the goroutine of the client and may execute commands through the Sim. The client times out once
+// the clock advanced by timeout, 0 meaning never.
+func (s *Sim) Block(keys []string, timeout time.Duration, serve func(key string) (served bool, err error)) *Blocked {
+	m := s.shards.Blocking()
+	before := m.Len()
+	b := &Blocked{done: make(chan struct{})}
+	go func() {
+		defer close(b.done)
+		b.err = m.Block(s.ctx, keys, timeout, serve)
+	}()
+
+	for m.Len() == before {
+		select {
+		case <-b.done:
+			return b
+		default:
+			runtime.Gosched()
+		}
+	}
+	return b
+}
+
+// Done reports whether the client stopped waiting.
+func (b *Blocked) Done() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait waits for the client to stop waiting and returns the error of Block, nil when it was served. The client
+// must have been woken up by a write or timed out by Advance already, Wait failing otherwise.
+func (b *Blocked) Wait() error {
+	select {
+	case <-b.done:
+		return b.err
+	case <-time.After(waitTimeout):
+		return fmt.Errorf("sim: blocked client still waiting after %s", waitTimeout)
+	}
+}
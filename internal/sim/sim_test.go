@@ -0,0 +1,133 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package sim
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/blocking"
+	"github.com/dicedb/dice/internal/clientio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiry(t *testing.T) {
+	s := New(t, Options{})
+
+	assert.Equal(t, clientio.OK, s.Do("SET", "k", "v", "EX", "10").Result)
+	s.Advance(9999 * time.Millisecond)
+	assert.Equal(t, "v", s.Do("GET", "k").Result)
+	s.Advance(time.Millisecond)
+	assert.Equal(t, clientio.NIL, s.Do("GET", "k").Result)
+	assert.Equal(t, DefaultStart.Add(10*time.Second), s.Now())
+}
+
+func TestExpiryCycle(t *testing.T) {
+	s := New(t, Options{})
+	w := s.Watch("GET", "k")
+
+	s.Do("SET", "k", "v", "PX", "1500")
+	s.Advance(time.Second)
+	// The key is only deleted by the expiry cycle following its expiry, at 2s
+	s.Advance(999 * time.Millisecond)
+	assert.Len(t, w.Responses(), 2)
+	s.Advance(time.Millisecond)
+
+	results := make([]interface{}, 0, 3)
+	for _, resp := range w.Responses() {
+		results = append(results, resp.Result)
+	}
+	assert.Equal(t, []interface{}{clientio.NIL, "v", clientio.NIL}, results)
+}
+
+func TestBlockServed(t *testing.T) {
+	s := New(t, Options{Shards: 4, Seed: 7})
+
+	var popped interface{}
+	b := s.Block([]string{"q"}, 5*time.Second, func(key string) (bool, error) {
+		resp := s.Do("LPOP", key)
+		if resp.Result == clientio.NIL {
+			return false, nil
+		}
+		popped = resp.Result
+		return true, nil
+	})
+
+	s.Advance(4 * time.Second)
+	assert.False(t, b.Done())
+	s.Do("RPUSH", "q", "a")
+	assert.NoError(t, b.Wait())
+	assert.Equal(t, "a", popped)
+}
+
+func TestBlockTimeout(t *testing.T) {
+	s := New(t, Options{})
+
+	b := s.Block([]string{"q"}, 5*time.Second, func(string) (bool, error) {
+		return false, nil
+	})
+
+	s.Advance(4990 * time.Millisecond)
+	assert.False(t, b.Done())
+	s.Advance(10 * time.Millisecond)
+	assert.ErrorIs(t, b.Wait(), blocking.ErrTimeout)
+}
+
+func TestEviction(t *testing.T) {
+	s := New(t, Options{KeysLimit: 10})
+
+	for i := 0; i < 10; i++ {
+		s.Do("SET", "k"+strconv.Itoa(i), "v")
+		s.Advance(time.Second)
+	}
+	// k0 is accessed last, leaving k1 the least recently used key
+	s.Do("GET", "k0")
+	s.Advance(time.Second)
+	s.Do("SET", "k10", "v")
+
+	assert.Equal(t, "v", s.Do("GET", "k0").Result)
+	assert.Equal(t, clientio.NIL, s.Do("GET", "k1").Result)
+	assert.Equal(t, "v", s.Do("GET", "k10").Result)
+}
+
+func TestSeededInterleaving(t *testing.T) {
+	order := func(seed int64) []string {
+		s := New(t, Options{Shards: 4, Seed: seed})
+		keys := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+		calls := make([]*Call, 0, len(keys))
+		for _, key := range keys {
+			calls = append(calls, s.Submit("INCR", key))
+		}
+		executed := make([]string, 0, len(keys))
+		for s.Step() {
+			for i, call := range calls {
+				if call != nil && call.Done() {
+					executed = append(executed, keys[i])
+					calls[i] = nil
+				}
+			}
+		}
+		return executed
+	}
+
+	first := order(1)
+	assert.Len(t, first, 8)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, order(1))
+	}
+}
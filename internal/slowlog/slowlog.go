@@ -0,0 +1,165 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package slowlog records the commands whose execution exceeded the configured
+// slowlog.log_slower_than threshold, so they can be inspected with SLOWLOG GET.
+package slowlog
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+)
+
+const (
+	// maxArgs is the maximum number of arguments, including the command name, kept for an entry
+	maxArgs = 32
+	// maxArgLen is the maximum length of an argument kept for an entry
+	maxArgLen = 128
+)
+
+// nextID is shared by the logs of all shards so that entries can be merged in the order they were recorded.
+var nextID atomic.Uint64
+
+// Entry is a command recorded in the slow log.
+type Entry struct {
+	ID         uint64
+	Timestamp  time.Time
+	Duration   time.Duration
+	Args       []string
+	ClientAddr string
+}
+
+// Log is the bounded slow log of a shard, keeping the most recent slowlog.max_len entries.
+// It is not safe for concurrent use; it is only accessed by the shard thread owning it.
+type Log struct {
+	entries []Entry // oldest first
+}
+
+// New creates an empty slow log.
+func New() *Log {
+	return &Log{}
+}
+
+// Record adds the command to the log if its execution took longer than slowlog.log_slower_than.
+func (l *Log) Record(diceDBCmd *cmd.DiceDBCmd, duration time.Duration, clientAddr string) {
+	threshold := config.DiceConfig().Slowlog.LogSlowerThan
+	if threshold < 0 || duration < time.Duration(threshold)*time.Microsecond {
+		return
+	}
+
+	l.entries = append(l.entries, Entry{
+		ID:         nextID.Add(1) - 1,
+		Timestamp:  time.Now(),
+		Duration:   duration,
+		Args:       truncateArgs(diceDBCmd),
+		ClientAddr: clientAddr,
+	})
+	l.trim()
+}
+
+// trim drops the oldest entries beyond slowlog.max_len, which can be lowered at runtime.
+func (l *Log) trim() {
+	if extra := len(l.entries) - config.DiceConfig().Slowlog.MaxLen; extra > 0 {
+		l.entries = append(l.entries[:0], l.entries[extra:]...)
+	}
+}
+
+// Get returns up to count entries, most recent first. A negative count returns every entry.
+func (l *Log) Get(count int) []Entry {
+	l.trim()
+
+	if count < 0 || count > len(l.entries) {
+		count = len(l.entries)
+	}
+
+	entries := make([]Entry, 0, count)
+	for i := len(l.entries) - 1; i >= len(l.entries)-count; i-- {
+		entries = append(entries, l.entries[i])
+	}
+	return entries
+}
+
+// Len returns the number of entries in the log.
+func (l *Log) Len() int {
+	l.trim()
+	return len(l.entries)
+}
+
+// Reset removes every entry from the log.
+func (l *Log) Reset() {
+	l.entries = nil
+}
+
+// Merge combines the entries returned by several logs, most recent first, keeping up to count entries.
+// A negative count keeps every entry.
+func Merge(count int, logs ...[]Entry) []Entry {
+	var entries []Entry
+	for _, l := range logs {
+		entries = append(entries, l...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+
+	if count >= 0 && count < len(entries) {
+		entries = entries[:count]
+	}
+	return entries
+}
+
+// Reply returns the entries in the format of the SLOWLOG GET reply: the id, the unix timestamp,
+// the duration in microseconds, the arguments, the client address and the client name of every entry.
+func Reply(entries []Entry) []interface{} {
+	reply := make([]interface{}, 0, len(entries))
+	for i := range entries {
+		reply = append(reply, []interface{}{
+			entries[i].ID,
+			entries[i].Timestamp.Unix(),
+			entries[i].Duration.Microseconds(),
+			entries[i].Args,
+			entries[i].ClientAddr,
+			"",
+		})
+	}
+	return reply
+}
+
+// truncateArgs returns the command and its arguments, shortened like Redis does
+// so that large commands do not use up memory in the log.
+func truncateArgs(diceDBCmd *cmd.DiceDBCmd) []string {
+	argc := len(diceDBCmd.Args) + 1
+
+	n := min(argc, maxArgs)
+	args := make([]string, 0, n)
+	args = append(args, diceDBCmd.Cmd)
+	for _, arg := range diceDBCmd.Args[:n-1] {
+		if len(arg) > maxArgLen {
+			arg = fmt.Sprintf("%s... (%d more bytes)", arg[:maxArgLen], len(arg)-maxArgLen)
+		}
+		args = append(args, arg)
+	}
+
+	if argc > maxArgs {
+		args[n-1] = fmt.Sprintf("... (%d more arguments)", argc-maxArgs+1)
+	}
+	return args
+}
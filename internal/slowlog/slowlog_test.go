@@ -0,0 +1,99 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package slowlog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func withSlowlogConfig(t *testing.T, logSlowerThan int64, maxLen int) {
+	previous := config.DiceConfig().Slowlog
+	config.DiceConfig().Slowlog.LogSlowerThan = logSlowerThan
+	config.DiceConfig().Slowlog.MaxLen = maxLen
+	t.Cleanup(func() { config.DiceConfig().Slowlog = previous })
+}
+
+func TestRecordThreshold(t *testing.T) {
+	withSlowlogConfig(t, 1000, 10)
+
+	l := New()
+	l.Record(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k1"}}, 500*time.Microsecond, "127.0.0.1:5000")
+	l.Record(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k1", "v1"}}, 2*time.Millisecond, "127.0.0.1:5000")
+	assert.Equal(t, 1, l.Len())
+
+	entries := l.Get(-1)
+	assert.Equal(t, []string{"SET", "k1", "v1"}, entries[0].Args)
+	assert.Equal(t, "127.0.0.1:5000", entries[0].ClientAddr)
+
+	config.DiceConfig().Slowlog.LogSlowerThan = -1
+	l.Record(&cmd.DiceDBCmd{Cmd: "SET", Args: []string{"k1", "v1"}}, time.Second, "127.0.0.1:5000")
+	assert.Equal(t, 1, l.Len())
+}
+
+func TestBoundedLog(t *testing.T) {
+	withSlowlogConfig(t, 0, 3)
+
+	l := New()
+	for _, key := range []string{"k1", "k2", "k3", "k4", "k5"} {
+		l.Record(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{key}}, time.Millisecond, "")
+	}
+	assert.Equal(t, 3, l.Len())
+
+	entries := l.Get(2)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "k5", entries[0].Args[1])
+	assert.Equal(t, "k4", entries[1].Args[1])
+
+	config.DiceConfig().Slowlog.MaxLen = 1
+	assert.Equal(t, 1, l.Len())
+
+	l.Reset()
+	assert.Equal(t, 0, l.Len())
+}
+
+func TestMerge(t *testing.T) {
+	withSlowlogConfig(t, 0, 10)
+
+	first, second := New(), New()
+	first.Record(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k1"}}, time.Millisecond, "")
+	second.Record(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k2"}}, time.Millisecond, "")
+	first.Record(&cmd.DiceDBCmd{Cmd: "GET", Args: []string{"k3"}}, time.Millisecond, "")
+
+	merged := Merge(2, first.Get(2), second.Get(2))
+	assert.Len(t, merged, 2)
+	assert.Equal(t, "k3", merged[0].Args[1])
+	assert.Equal(t, "k2", merged[1].Args[1])
+}
+
+func TestTruncateArgs(t *testing.T) {
+	args := make([]string, 40)
+	for i := range args {
+		args[i] = "v"
+	}
+	args[0] = strings.Repeat("x", 130)
+
+	truncated := truncateArgs(&cmd.DiceDBCmd{Cmd: "SADD", Args: args})
+	assert.Len(t, truncated, maxArgs)
+	assert.Equal(t, strings.Repeat("x", 128)+"... (2 more bytes)", truncated[1])
+	assert.Equal(t, "... (10 more arguments)", truncated[maxArgs-1])
+}
@@ -0,0 +1,91 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package sockerr classifies the errors of the socket operations portably. The errno values reported for a
+// broken pipe, a connection reset or exhausted socket buffers differ between the operating systems, e.g. EPIPE
+// on Linux and macOS against WSAESHUTDOWN on Windows, so the errors are matched with errors.Is against the
+// errno values of the platform the server is built for.
+package sockerr
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+// Kind is the kind of failure of a socket operation.
+type Kind int
+
+const (
+	// Other is a failure of none of the kinds below
+	Other Kind = iota
+	// Closed is an operation on a socket closed by this process
+	Closed
+	// Timeout is an operation that did not complete before the deadline of the socket
+	Timeout
+	// BrokenPipe is a write to a connection the peer no longer reads from
+	BrokenPipe
+	// ConnReset is an operation on a connection reset or aborted by the peer
+	ConnReset
+	// NoBuffers is an operation failing for lack of buffer space in the kernel
+	NoBuffers
+	// WouldBlock is an operation on a non-blocking socket that would have blocked
+	WouldBlock
+)
+
+// errnoKinds are the kinds of failure matched by the errno values of the platform, in the order they are tried
+var errnoKinds = []Kind{BrokenPipe, ConnReset, NoBuffers, WouldBlock}
+
+// Classify returns the kind of failure of a socket operation, Other for a nil error.
+func Classify(err error) Kind {
+	switch {
+	case err == nil:
+		return Other
+	case errors.Is(err, net.ErrClosed):
+		return Closed
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return Timeout
+	}
+
+	for _, kind := range errnoKinds {
+		for _, errno := range errnos[kind] {
+			if errors.Is(err, errno) {
+				return kind
+			}
+		}
+	}
+	return Other
+}
+
+// Transient reports whether the operation may succeed if it is tried again as it is.
+func (k Kind) Transient() bool {
+	return k == WouldBlock
+}
+
+// Errno returns the error the platform reports for a failure of the kind, e.g. for the failpoints to inject
+// it, nil for Other.
+func Errno(kind Kind) error {
+	switch kind {
+	case Closed:
+		return net.ErrClosed
+	case Timeout:
+		return os.ErrDeadlineExceeded
+	}
+	if errnos := errnos[kind]; len(errnos) > 0 {
+		return errnos[0]
+	}
+	return nil
+}
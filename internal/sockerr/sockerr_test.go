@@ -0,0 +1,61 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package sockerr
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	// The errors of the platform are classified however deep they are wrapped, as the net package wraps them
+	for _, kind := range []Kind{Closed, Timeout, BrokenPipe, ConnReset, NoBuffers, WouldBlock} {
+		err := &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", Errno(kind))}
+		assert.Equal(t, kind, Classify(fmt.Errorf("writing: %w", err)))
+	}
+
+	assert.Equal(t, Other, Classify(nil))
+	assert.Equal(t, Other, Classify(errors.New("unexpected")))
+	assert.Nil(t, Errno(Other))
+
+	assert.True(t, WouldBlock.Transient())
+	assert.False(t, ConnReset.Transient())
+}
+
+func TestClassifyNetErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Millisecond)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Equal(t, Timeout, Classify(err))
+
+	conn.Close()
+	_, err = conn.Write([]byte("hello"))
+	assert.Equal(t, Closed, Classify(err))
+}
@@ -0,0 +1,29 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build unix
+
+package sockerr
+
+import "syscall"
+
+// errnos are the errno values of the kinds of failure, the first one of a kind being the one Errno returns
+var errnos = map[Kind][]syscall.Errno{
+	BrokenPipe: {syscall.EPIPE},
+	ConnReset:  {syscall.ECONNRESET, syscall.ECONNABORTED},
+	NoBuffers:  {syscall.ENOBUFS},
+	WouldBlock: {syscall.EAGAIN, syscall.EWOULDBLOCK},
+}
@@ -0,0 +1,33 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package sockerr
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// errnos are the errno values of the kinds of failure, the first one of a kind being the one Errno returns. The
+// writes to a connection shut down fail with WSAESHUTDOWN on Windows, and the ones to a pipe with
+// ERROR_BROKEN_PIPE or ERROR_NO_DATA.
+var errnos = map[Kind][]syscall.Errno{
+	BrokenPipe: {windows.WSAESHUTDOWN, windows.ERROR_BROKEN_PIPE, windows.ERROR_NO_DATA},
+	ConnReset:  {windows.WSAECONNRESET, windows.WSAECONNABORTED, windows.ERROR_NETNAME_DELETED},
+	NoBuffers:  {windows.WSAENOBUFS},
+	WouldBlock: {windows.WSAEWOULDBLOCK},
+}
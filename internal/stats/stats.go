@@ -0,0 +1,388 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package stats holds the process wide server counters reported by INFO and reset by CONFIG RESETSTAT.
+// The counters are updated by the io-threads and the frontends and are safe for concurrent use.
+package stats
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The health states of the server, as reported by Health.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
+var (
+	startTime = time.Now()
+
+	connectedClients         atomic.Int64
+	blockedClients           atomic.Int64
+	totalConnectionsReceived atomic.Int64
+	rejectedConnections      atomic.Int64
+	totalCommandsProcessed   atomic.Int64
+	keyspaceHits             atomic.Int64
+	keyspaceMisses           atomic.Int64
+	lazyfreePendingObjects   atomic.Int64
+	lazyfreedObjects         atomic.Int64
+	tierSpilledValues        atomic.Int64
+	tierLoadedValues         atomic.Int64
+	cacheLoadedKeys          atomic.Int64
+	cacheLoadFailures        atomic.Int64
+	cacheWrittenKeys         atomic.Int64
+	cacheWriteFailures       atomic.Int64
+	defragHits               atomic.Int64
+	defragExpiriesDropped    atomic.Int64
+	defragRebuilds           atomic.Int64
+	slowLaneCommands         atomic.Int64
+	shardCrashes             atomic.Int64
+	lastShardCrash           atomic.Int64 // lastShardCrash is the time of the last crash of a shard in nanoseconds, 0 before the first one
+	restoreFailed            atomic.Bool  // restoreFailed is set once the restore of a backup in the background failed
+
+	// commandStats maps the name of a command to its *commandCounters
+	commandStats sync.Map
+
+	watchQueries          atomic.Int64
+	watchSubscriptions    atomic.Int64
+	watchPushes           atomic.Int64
+	watchPushLatencyUsec  atomic.Int64
+	watchEvaluationUsec   atomic.Int64
+	watchCoalescedUpdates atomic.Int64
+	watchDroppedUpdates   atomic.Int64
+	watchLagging          atomic.Int64
+)
+
+type commandCounters struct {
+	calls  atomic.Int64
+	usec   atomic.Int64
+	failed atomic.Int64
+}
+
+// CommandStat is a point in time copy of the counters of a command.
+type CommandStat struct {
+	Command     string
+	Calls       int64
+	Usec        int64
+	FailedCalls int64
+}
+
+// Snapshot is a point in time copy of the server counters.
+type Snapshot struct {
+	StartTime                time.Time
+	ConnectedClients         int64
+	BlockedClients           int64
+	TotalConnectionsReceived int64
+	RejectedConnections      int64
+	TotalCommandsProcessed   int64
+	KeyspaceHits             int64
+	KeyspaceMisses           int64
+	LazyfreePendingObjects   int64
+	LazyfreedObjects         int64
+	TierSpilledValues        int64
+	TierLoadedValues         int64
+	CacheLoadedKeys          int64
+	CacheLoadFailures        int64
+	CacheWrittenKeys         int64
+	CacheWriteFailures       int64
+	DefragHits               int64 // DefragHits is the number of entries moved to the tables rebuilt
+	DefragExpiriesDropped    int64
+	DefragRebuilds           int64
+	SlowLaneCommands         int64 // SlowLaneCommands is the number of commands held back in the slow lane of a shard
+	ShardCrashes             int64 // ShardCrashes is the number of times a shard crashed and was restarted
+	LastShardCrash           time.Time
+	Watch                    WatchSnapshot
+}
+
+// WatchSnapshot is a point in time copy of the counters of the qwatch push pipeline.
+type WatchSnapshot struct {
+	Queries          int64
+	Subscriptions    int64
+	Pushes           int64
+	PushLatencyUsec  int64
+	EvaluationUsec   int64
+	CoalescedUpdates int64
+	DroppedUpdates   int64
+	Lagging          int64 // Lagging is the number of subscriptions whose bound the lag of the replica exceeds
+}
+
+// ClientConnected records a new client connection accepted by the server.
+func ClientConnected() {
+	connectedClients.Add(1)
+	totalConnectionsReceived.Add(1)
+}
+
+// ClientDisconnected records a client connection being closed.
+func ClientDisconnected() {
+	connectedClients.Add(-1)
+}
+
+// ClientBlocked records a client starting to wait in a blocking command, e.g. BLPOP.
+func ClientBlocked() {
+	blockedClients.Add(1)
+}
+
+// ClientUnblocked records a client done waiting in a blocking command, be it served, timed out or disconnected.
+func ClientUnblocked() {
+	blockedClients.Add(-1)
+}
+
+// ConnectionRejected records a client connection refused by the server,
+// e.g. because of protected mode or the max clients limit.
+func ConnectionRejected() {
+	rejectedConnections.Add(1)
+}
+
+// CommandProcessed records a command received from a client.
+func CommandProcessed() {
+	totalCommandsProcessed.Add(1)
+}
+
+// KeyspaceLookup records the lookup of a key, hit when the key exists.
+func KeyspaceLookup(hit bool) {
+	if hit {
+		keyspaceHits.Add(1)
+	} else {
+		keyspaceMisses.Add(1)
+	}
+}
+
+// LazyFreeQueued records keys of a flushed keyspace handed over to be released in the background.
+func LazyFreeQueued(keys int) {
+	lazyfreePendingObjects.Add(int64(keys))
+}
+
+// LazyFreed records keys released in the background.
+func LazyFreed(keys int) {
+	lazyfreePendingObjects.Add(-int64(keys))
+	lazyfreedObjects.Add(int64(keys))
+}
+
+// TierSpilled records values spilled from memory to the disk tier.
+func TierSpilled(values int) {
+	tierSpilledValues.Add(int64(values))
+}
+
+// TierLoaded records a value loaded back from the disk tier, as its key was accessed.
+func TierLoaded() {
+	tierLoadedValues.Add(1)
+}
+
+// CacheLoaded records a key missing from the store loaded from the origin, failed when the origin could not
+// be reached or replied with an error.
+func CacheLoaded(failed bool) {
+	if failed {
+		cacheLoadFailures.Add(1)
+		return
+	}
+	cacheLoadedKeys.Add(1)
+}
+
+// CacheWritten records a write or a deletion of a key propagated to the origin, failed when it is to be retried.
+func CacheWritten(failed bool) {
+	if failed {
+		cacheWriteFailures.Add(1)
+		return
+	}
+	cacheWrittenKeys.Add(1)
+}
+
+// Defragged records a step of the active defragmentation: the entries moved to the tables rebuilt, the stale
+// expiries dropped and the tables whose rebuild completed.
+func Defragged(moved, dropped, rebuilt int) {
+	defragHits.Add(int64(moved))
+	defragExpiriesDropped.Add(int64(dropped))
+	defragRebuilds.Add(int64(rebuilt))
+}
+
+// SlowLaneQueued records a command held back in the slow lane of a shard, behind the cheap commands received
+// after it.
+func SlowLaneQueued() {
+	slowLaneCommands.Add(1)
+}
+
+// ShardCrashed records the crash of a shard, restarted with its store intact.
+func ShardCrashed() {
+	shardCrashes.Add(1)
+	lastShardCrash.Store(time.Now().UnixNano())
+}
+
+// RestoreFailed records the failure of the restore of a backup in the background, the server running without
+// the keys it did not restore.
+func RestoreFailed() {
+	restoreFailed.Store(true)
+}
+
+// Health returns HealthDegraded if a shard crashed within the window, or the restore of a backup in the background
+// failed, HealthOK otherwise.
+func Health(window time.Duration) string {
+	if restoreFailed.Load() {
+		return HealthDegraded
+	}
+	last := lastShardCrash.Load()
+	if window > 0 && last != 0 && time.Since(unixNano(last)) < window {
+		return HealthDegraded
+	}
+	return HealthOK
+}
+
+// CommandExecuted records the execution of a command by a shard.
+func CommandExecuted(command string, failed bool, elapsed time.Duration) {
+	c, ok := commandStats.Load(command)
+	if !ok {
+		c, _ = commandStats.LoadOrStore(command, &commandCounters{})
+	}
+
+	counters := c.(*commandCounters)
+	counters.calls.Add(1)
+	counters.usec.Add(elapsed.Microseconds())
+	if failed {
+		counters.failed.Add(1)
+	}
+}
+
+// GetCommandStats returns the counters of every command executed since the last reset, ordered by command.
+func GetCommandStats() []CommandStat {
+	var commands []CommandStat
+	commandStats.Range(func(key, value any) bool {
+		counters := value.(*commandCounters)
+		commands = append(commands, CommandStat{
+			Command:     key.(string),
+			Calls:       counters.calls.Load(),
+			Usec:        counters.usec.Load(),
+			FailedCalls: counters.failed.Load(),
+		})
+		return true
+	})
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Command < commands[j].Command
+	})
+	return commands
+}
+
+// WatchSubscribed records a client subscribing to a watched query; queries is the number of distinct watched queries.
+func WatchSubscribed(queries int) {
+	watchSubscriptions.Add(1)
+	watchQueries.Store(int64(queries))
+}
+
+// WatchUnsubscribed records a client unsubscribing from a watched query; queries is the number of distinct watched queries.
+func WatchUnsubscribed(queries int) {
+	watchSubscriptions.Add(-1)
+	watchQueries.Store(int64(queries))
+}
+
+// WatchUpdatePushed records the new result of a watched query pushed to a subscriber. evaluation is the
+// time spent running the query and latency the time elapsed since the change of the key it depends on.
+func WatchUpdatePushed(evaluation, latency time.Duration) {
+	watchPushes.Add(1)
+	watchEvaluationUsec.Add(evaluation.Microseconds())
+	watchPushLatencyUsec.Add(latency.Microseconds())
+}
+
+// WatchUpdateCoalesced records a change notification merged with one already queued for the same query.
+func WatchUpdateCoalesced() {
+	watchCoalescedUpdates.Add(1)
+}
+
+// WatchUpdateDropped records a change notification whose new result could not be pushed to the subscriber.
+func WatchUpdateDropped() {
+	watchDroppedUpdates.Add(1)
+}
+
+// WatchLagging records the number of subscriptions whose bound the lag of the replica behind its upstream exceeds.
+func WatchLagging(subscriptions int) {
+	watchLagging.Store(int64(subscriptions))
+}
+
+// Reset resets the cumulative counters, as CONFIG RESETSTAT does. Gauges such as the number
+// of connected clients are left untouched.
+func Reset() {
+	totalConnectionsReceived.Store(0)
+	rejectedConnections.Store(0)
+	totalCommandsProcessed.Store(0)
+	keyspaceHits.Store(0)
+	keyspaceMisses.Store(0)
+	lazyfreedObjects.Store(0)
+	tierSpilledValues.Store(0)
+	tierLoadedValues.Store(0)
+	cacheLoadedKeys.Store(0)
+	cacheLoadFailures.Store(0)
+	cacheWrittenKeys.Store(0)
+	cacheWriteFailures.Store(0)
+	defragHits.Store(0)
+	defragExpiriesDropped.Store(0)
+	defragRebuilds.Store(0)
+	slowLaneCommands.Store(0)
+	shardCrashes.Store(0)
+	commandStats.Clear()
+	watchPushes.Store(0)
+	watchPushLatencyUsec.Store(0)
+	watchEvaluationUsec.Store(0)
+	watchCoalescedUpdates.Store(0)
+	watchDroppedUpdates.Store(0)
+}
+
+// unixNano returns the time of the nanoseconds since the epoch, the zero time for 0.
+func unixNano(nsec int64) time.Time {
+	if nsec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nsec)
+}
+
+// Get returns the current value of the server counters.
+func Get() Snapshot {
+	return Snapshot{
+		StartTime:                startTime,
+		ConnectedClients:         connectedClients.Load(),
+		BlockedClients:           blockedClients.Load(),
+		TotalConnectionsReceived: totalConnectionsReceived.Load(),
+		RejectedConnections:      rejectedConnections.Load(),
+		TotalCommandsProcessed:   totalCommandsProcessed.Load(),
+		KeyspaceHits:             keyspaceHits.Load(),
+		KeyspaceMisses:           keyspaceMisses.Load(),
+		LazyfreePendingObjects:   lazyfreePendingObjects.Load(),
+		LazyfreedObjects:         lazyfreedObjects.Load(),
+		TierSpilledValues:        tierSpilledValues.Load(),
+		TierLoadedValues:         tierLoadedValues.Load(),
+		CacheLoadedKeys:          cacheLoadedKeys.Load(),
+		CacheLoadFailures:        cacheLoadFailures.Load(),
+		CacheWrittenKeys:         cacheWrittenKeys.Load(),
+		CacheWriteFailures:       cacheWriteFailures.Load(),
+		DefragHits:               defragHits.Load(),
+		DefragExpiriesDropped:    defragExpiriesDropped.Load(),
+		DefragRebuilds:           defragRebuilds.Load(),
+		SlowLaneCommands:         slowLaneCommands.Load(),
+		ShardCrashes:             shardCrashes.Load(),
+		LastShardCrash:           unixNano(lastShardCrash.Load()),
+		Watch: WatchSnapshot{
+			Queries:          watchQueries.Load(),
+			Subscriptions:    watchSubscriptions.Load(),
+			Pushes:           watchPushes.Load(),
+			PushLatencyUsec:  watchPushLatencyUsec.Load(),
+			EvaluationUsec:   watchEvaluationUsec.Load(),
+			CoalescedUpdates: watchCoalescedUpdates.Load(),
+			DroppedUpdates:   watchDroppedUpdates.Load(),
+			Lagging:          watchLagging.Load(),
+		},
+	}
+}
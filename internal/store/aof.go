@@ -19,13 +19,15 @@ package store
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"os"
-	"strings"
 	"sync"
+	"time"
 
+	"github.com/dicedb/dice/internal/latency"
 	"github.com/dicedb/dice/internal/object"
 
 	"github.com/dicedb/dice/config"
@@ -62,6 +64,7 @@ func (a *AOF) Write(operation string) error {
 	if _, err := a.writer.WriteString(operation + "\n"); err != nil {
 		return err
 	}
+	defer latency.Since(latency.EventAOFFsync, time.Now())
 	if err := a.writer.Flush(); err != nil {
 		return err
 	}
@@ -115,30 +118,46 @@ func encode(strs []string) []byte {
 // TODO: Support non-kv data structures
 // TODO: Support sync write
 func dumpKey(aof *AOF, key string, obj *object.Obj) (err error) {
-	cmd := fmt.Sprintf("SET %s %s", key, obj.Value)
-	tokens := strings.Split(cmd, " ")
-	return aof.Write(string(encode(tokens)))
+	return aof.Write(string(encode([]string{"SET", key, fmt.Sprintf("%v", obj.Value)})))
 }
 
-// DumpAllAOF dumps all keys in the store to the AOF file
-func DumpAllAOF(store *Store) error {
-	var (
-		aof *AOF
-		err error
-	)
-	if aof, err = NewAOF(config.DiceConfig.Persistence.AOFFile); err != nil {
+// DumpAllAOF dumps all keys of the stores to the AOF file. The keys are written to a temporary
+// file first, which replaces the AOF file once every key is written.
+func DumpAllAOF(stores ...*Store) error {
+	path := config.DiceConfig().Persistence.AOFFile
+	tmpPath := path + ".tmp"
+	if err := os.Remove(tmpPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return err
 	}
-	defer aof.Close()
 
-	log.Println("rewriting AOF file at", config.DiceConfig.Persistence.AOFFile)
+	aof, err := NewAOF(tmpPath)
+	if err != nil {
+		return err
+	}
 
-	store.store.All(func(k string, obj *object.Obj) bool {
-		err = dumpKey(aof, k, obj)
-		// continue if no error
-		return err == nil
-	})
+	log.Println("rewriting AOF file at", path)
+
+	for _, store := range stores {
+		store.store.All(func(k string, obj *object.Obj) bool {
+			err = dumpKey(aof, k, obj)
+			// continue if no error
+			return err == nil
+		})
+		if err != nil {
+			break
+		}
+	}
+
+	if closeErr := aof.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
 
 	log.Println("AOF file rewrite complete")
-	return err
+	return nil
 }
@@ -19,10 +19,15 @@ package store
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
 	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAOF(t *testing.T) {
@@ -295,3 +300,33 @@ func BenchmarkAOFWithExat(b *testing.B) {
 		}
 	}
 }
+
+func TestDumpAllAOF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.aof")
+	previous := config.DiceConfig().Persistence.AOFFile
+	config.DiceConfig().Persistence.AOFFile = path
+	defer func() { config.DiceConfig().Persistence.AOFFile = previous }()
+
+	// A previous dump is replaced rather than appended to
+	if err := os.WriteFile(path, []byte("stale\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write stale dump: %v", err)
+	}
+
+	first, second := NewStore(nil, nil), NewStore(nil, nil)
+	first.Put("k1", first.NewObj("hello world", -1, object.ObjTypeString))
+	second.Put("k2", second.NewObj("v2", -1, object.ObjTypeString))
+
+	if err := DumpAllAOF(first, second); err != nil {
+		t.Fatalf("Failed to dump stores: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read dump: %v", err)
+	}
+	dump := string(data)
+	assert.NotContains(t, dump, "stale")
+	assert.Contains(t, dump, "*3\r\n$3\r\nSET\r\n$2\r\nk1\r\n$11\r\nhello world\r\n")
+	assert.Contains(t, dump, "$2\r\nk2\r\n$2\r\nv2\r\n")
+	assert.NoFileExists(t, path+".tmp")
+}
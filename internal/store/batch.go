@@ -0,0 +1,79 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"github.com/dicedb/dice/internal/object"
+)
+
+// Batch groups the writes of a command to many keys of the store, e.g. the ones of MSET or DEL, so that they
+// are applied in one pass: the writes are applied right away, so that the command reads them back, but their
+// key events are held back until the batch is committed, then delivered at once, in a single call to the
+// subscribers implementing KeyEventBatchSubscriber.
+type Batch struct {
+	store     *Store
+	committed bool
+}
+
+// NewBatch starts a batch of writes, to be committed by the command once its writes are applied. A batch
+// started while another one is open joins it, its key events are delivered once the outer batch is committed.
+func (store *Store) NewBatch() *Batch {
+	store.batchDepth++
+	return &Batch{store: store}
+}
+
+// Put writes the key like Store.Put.
+func (b *Batch) Put(k string, obj *object.Obj, opts ...PutOption) {
+	b.store.putHelper(k, obj, opts...)
+}
+
+// Del deletes the key like Store.Del, and returns true if it existed.
+func (b *Batch) Del(k string, opts ...DelOption) bool {
+	return b.store.Del(k, opts...)
+}
+
+// Trash deletes the key like Store.Trash, and returns true if it existed.
+func (b *Batch) Trash(k string) bool {
+	return b.store.Trash(k)
+}
+
+// Commit ends the batch and delivers the key events of its writes, in the order of the writes. Committing
+// a batch again does nothing.
+func (b *Batch) Commit() {
+	if b.committed {
+		return
+	}
+	b.committed = true
+
+	store := b.store
+	store.batchDepth--
+	if store.batchDepth > 0 || len(store.pendingEvents) == 0 {
+		return
+	}
+	events := store.pendingEvents
+	store.pendingEvents = nil
+
+	for _, s := range store.subscriptions {
+		if bs, ok := s.subscriber.(KeyEventBatchSubscriber); ok {
+			bs.OnKeyEvents(events)
+			continue
+		}
+		for _, e := range events {
+			s.subscriber.OnKeyEvent(e)
+		}
+	}
+}
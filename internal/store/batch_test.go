@@ -0,0 +1,108 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchRecorder records the key events delivered one at a time and the ones delivered by batch
+type batchRecorder struct {
+	events  []KeyEvent
+	batches [][]KeyEvent
+}
+
+func (r *batchRecorder) OnKeyEvent(e KeyEvent) {
+	r.events = append(r.events, e)
+}
+
+func (r *batchRecorder) OnKeyEvents(events []KeyEvent) {
+	r.batches = append(r.batches, append([]KeyEvent(nil), events...))
+}
+
+func TestBatch(t *testing.T) {
+	store := NewStore(nil, nil)
+	recorder := &batchRecorder{}
+	store.Subscribe(recorder)
+	var events []KeyEvent
+	store.Subscribe(KeyEventFunc(func(e KeyEvent) {
+		events = append(events, e)
+	}))
+
+	store.Put("c", store.NewObj("1", -1, object.ObjTypeString))
+	assert.Len(t, recorder.events, 1)
+
+	batch := store.NewBatch()
+	batch.Put("a", store.NewObj("1", -1, object.ObjTypeString))
+	batch.Put("b", store.NewObj("2", -1, object.ObjTypeString))
+	assert.True(t, batch.Del("c"))
+	assert.False(t, batch.Del("d"))
+
+	// The writes are applied right away, their events are held back until the batch is committed
+	assert.Equal(t, "2", store.Get("b").Value)
+	assert.Empty(t, recorder.batches)
+	assert.Len(t, events, 1)
+
+	// A batch started while one is open joins it
+	nested := store.NewBatch()
+	nested.Put("d", store.NewObj("3", -1, object.ObjTypeString))
+	nested.Commit()
+	assert.Empty(t, recorder.batches)
+
+	batch.Commit()
+	batch.Commit()
+	assert.Len(t, recorder.batches, 1)
+	assert.Len(t, recorder.events, 1)
+	keys := make([]string, 0, len(recorder.batches[0]))
+	for _, e := range recorder.batches[0] {
+		keys = append(keys, e.Key)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+	assert.Equal(t, KeyEventDel, recorder.batches[0][2].Type)
+	assert.Len(t, events, 5, "the subscribers not handling batches are notified of every event")
+
+	// The events of the writes following the batch are delivered right away
+	store.Put("e", store.NewObj("4", -1, object.ObjTypeString))
+	assert.Len(t, recorder.events, 2)
+	assert.Len(t, events, 6)
+}
+
+func TestCmdWatchForwarderBatch(t *testing.T) {
+	cmdWatchChan := make(chan CmdWatchEvent, 10)
+	store := NewStore(cmdWatchChan, nil)
+	store.Put("c", store.NewObj("1", -1, object.ObjTypeString))
+	<-cmdWatchChan
+
+	batch := store.NewBatch()
+	batch.Put("a", store.NewObj("1", -1, object.ObjTypeString))
+	batch.Put("b", store.NewObj("2", -1, object.ObjTypeString))
+	batch.Del("c")
+	batch.Commit()
+
+	// The consecutive events of the same command are forwarded as one
+	e := <-cmdWatchChan
+	assert.Equal(t, Set, e.Cmd)
+	assert.Equal(t, []string{"a", "b"}, e.AffectedKeys)
+	e = <-cmdWatchChan
+	assert.Equal(t, Del, e.Cmd)
+	assert.Equal(t, "c", e.AffectedKey)
+	assert.Nil(t, e.AffectedKeys)
+	assert.Empty(t, cmdWatchChan)
+}
@@ -23,14 +23,14 @@ import (
 	"github.com/dicedb/dice/internal/object"
 )
 
-// evictionItemHeap is a max-heap of evictionItems based on lastAccessed.
+// evictionItemHeap is a min-heap of evictionItems based on idle.
 type evictionItemHeap []evictionItem
 
 func (h *evictionItemHeap) Len() int { return len(*h) }
 
 func (h *evictionItemHeap) Less(i, j int) bool {
-	// For a max-heap, we want higher lastAccessed at the top.
-	return (*h)[i].lastAccessed > (*h)[j].lastAccessed
+	// For a min-heap, we want the lower idle time at the top.
+	return (*h)[i].idle < (*h)[j].idle
 }
 
 func (h *evictionItemHeap) Swap(i, j int) { (*h)[i], (*h)[j] = (*h)[j], (*h)[i] }
@@ -56,11 +56,14 @@ func (h *evictionItemHeap) pop() evictionItem {
 	return heap.Pop(h).(evictionItem)
 }
 
-// BatchEvictionLRU implements batch eviction of least recently used keys
+// BatchEvictionLRU implements batch eviction of least recently used keys. The victims are either the exact least
+// recently used keys, found by scanning the keyspace, or approximated by sampling it, see NewSampledEvictionLRU.
 type BatchEvictionLRU struct {
 	BaseEvictionStrategy
 	maxKeys       int
 	evictionRatio float64
+	samples       int           // samples is the number of keys sampled for every victim, 0 to scan the keyspace
+	pool          *evictionPool // pool is the best candidates sampled so far, nil when the keyspace is scanned
 }
 
 func NewBatchEvictionLRU(maxKeys int, evictionRatio float64) *BatchEvictionLRU {
@@ -70,6 +73,21 @@ func NewBatchEvictionLRU(maxKeys int, evictionRatio float64) *BatchEvictionLRU {
 	}
 }
 
+// NewSampledEvictionLRU returns a batch eviction of the keys approximating LRU, like Redis does: every victim is
+// the idlest of the candidates of the eviction pool, which is fed with samples keys of the keyspace for every
+// victim. The cost of an eviction depends on the number of samples rather than on the size of the keyspace.
+func NewSampledEvictionLRU(maxKeys int, evictionRatio float64, samples int) *BatchEvictionLRU {
+	if samples <= 0 {
+		return NewBatchEvictionLRU(maxKeys, evictionRatio)
+	}
+	return &BatchEvictionLRU{
+		maxKeys:       maxKeys,
+		evictionRatio: evictionRatio,
+		samples:       samples,
+		pool:          &evictionPool{},
+	}
+}
+
 func (e *BatchEvictionLRU) ShouldEvict(store *Store) int {
 	currentKeyCount := store.GetKeyCount()
 
@@ -95,21 +113,26 @@ func (e *BatchEvictionLRU) EvictVictims(store *Store, toEvict int) {
 	if toEvict <= 0 {
 		return
 	}
+	if e.pool != nil {
+		e.evictSampled(store, toEvict)
+		return
+	}
 
+	now := getCurrentClock()
 	h := make(evictionItemHeap, 0, toEvict)
 	heap.Init(&h)
 
-	store.GetStore().All(func(k string, obj *object.Obj) bool {
+	store.allKeys(func(k string, obj *object.Obj) bool {
 		item := evictionItem{
-			key:          k,
-			lastAccessed: obj.LastAccessedAt,
+			key:  k,
+			idle: lruIdle(obj.LastAccessedAt, now),
 		}
 		if h.Len() < toEvict {
 			h.push(item)
 			return true
 		}
 
-		if item.lastAccessed < h[0].lastAccessed {
+		if item.idle > h[0].idle {
 			h.pop()
 			h.push(item)
 		}
@@ -124,6 +147,27 @@ func (e *BatchEvictionLRU) EvictVictims(store *Store, toEvict int) {
 	e.stats.recordEviction(int64(toEvict))
 }
 
+// evictSampled deletes the idlest candidates of the eviction pool, sampling the keyspace for every victim.
+func (e *BatchEvictionLRU) evictSampled(store *Store, toEvict int) {
+	now := getCurrentClock()
+	evicted := 0
+	for evicted < toEvict {
+		e.pool.populate(store, e.samples, now)
+		key, ok := e.pool.pop(store)
+		if !ok {
+			if store.store.Len() == 0 {
+				break
+			}
+			// Every candidate changed since it was sampled, the pool is fed again
+			continue
+		}
+		store.Del(key, WithDelCmd(Evict))
+		evicted++
+	}
+
+	e.stats.recordEviction(int64(evicted))
+}
+
 func (e *BatchEvictionLRU) OnAccess(key string, obj *object.Obj, accessType AccessType) {
 	// Nothing to do for LRU batch eviction
 }
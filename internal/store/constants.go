@@ -17,22 +17,32 @@
 package store
 
 const (
-	Set              string = "SET"
-	Del              string = "DEL"
-	Get              string = "GET"
-	Rename           string = "RENAME"
-	ZAdd             string = "ZADD"
-	ZRange           string = "ZRANGE"
-	Replace          string = "REPLACE"
-	Smembers         string = "SMEMBERS"
-	JSONGet          string = "JSON.GET"
-	PFADD            string = "PFADD"
-	PFCOUNT          string = "PFCOUNT"
-	PFMERGE          string = "PFMERGE"
-	KEYSPERSHARD     string = "KEYSPERSHARD"
-	Evict            string = "EVICT"
-	SingleShardSize  string = "SINGLEDBSIZE"
-	SingleShardTouch string = "SINGLETOUCH"
-	SingleShardKeys  string = "SINGLEKEYS"
-	FlushDB          string = "FLUSHDB"
+	Set               string = "SET"
+	Del               string = "DEL"
+	Get               string = "GET"
+	Rename            string = "RENAME"
+	ZAdd              string = "ZADD"
+	ZRange            string = "ZRANGE"
+	Replace           string = "REPLACE"
+	Smembers          string = "SMEMBERS"
+	SMisMember        string = "SMISMEMBER"
+	Scard             string = "SCARD"
+	JSONGet           string = "JSON.GET"
+	PFADD             string = "PFADD"
+	PFCOUNT           string = "PFCOUNT"
+	PFMERGE           string = "PFMERGE"
+	KEYSPERSHARD      string = "KEYSPERSHARD"
+	Evict             string = "EVICT"
+	Expired           string = "EXPIRED"
+	SingleShardSize   string = "SINGLEDBSIZE"
+	SingleShardTouch  string = "SINGLETOUCH"
+	SingleShardKeys   string = "SINGLEKEYS"
+	SingleShardInfo   string = "SINGLEINFO"
+	SingleSlowlog     string = "SINGLESLOWLOG"
+	SingleMemory      string = "SINGLEMEMORY"
+	SingleReload      string = "SINGLERELOAD"
+	SingleShardExport string = "SINGLEEXPORT"
+	FlushDB           string = "FLUSHDB"
+	CacheLoad         string = "CACHELOAD"
+	Undelete          string = "UNDELETE"
 )
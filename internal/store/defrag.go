@@ -0,0 +1,145 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/common"
+	"github.com/dicedb/dice/internal/stats"
+)
+
+const (
+	// defragChunk is the number of entries moved or checked between two checks of the time budget
+	defragChunk = 256
+	// defragMinCapacity is the capacity under which a table is not worth rebuilding
+	defragMinCapacity = 1024
+)
+
+// defragCursor is the entry of the expiry wheel the active defragmentation checks next.
+type defragCursor struct {
+	slot  int // slot is the index of the slot, level*wheelSlots+slot
+	entry int
+	left  int // left is the number of slots the pass in progress has yet to check, 0 when none is in progress
+}
+
+// FragmentationStats reports the memory held by the tables of a store for entries they no longer have.
+type FragmentationStats struct {
+	Entries    int  // Entries is the number of entries of the tables of the keys and of the expiries
+	Capacity   int  // Capacity is the number of entries the tables hold memory for
+	Scheduled  int  // Scheduled is the number of expiries scheduled, including the ones of keys deleted or whose TTL changed
+	Expires    int  // Expires is the number of keys with a TTL, the expiries scheduled that are not stale
+	Rebuilding bool // Rebuilding is set while a table is being rebuilt
+}
+
+func fragmentation(store *Store) FragmentationStats {
+	f := FragmentationStats{
+		Scheduled: store.expiryWheel.Len(),
+		Expires:   store.expires.Len(),
+	}
+	for _, table := range []interface{ Len() int }{store.keysTable(), store.expires} {
+		f.Entries += table.Len()
+		if c, ok := table.(common.Compactable); ok {
+			f.Capacity += c.Capacity()
+			f.Rebuilding = f.Rebuilding || c.Compacting()
+		} else {
+			f.Capacity += table.Len()
+		}
+	}
+	return f
+}
+
+// keysTable returns the table of the keys, unwrapped from the tier if any, as its memory is the one of the
+// keys and their objects
+func (store *Store) keysTable() interface{ Len() int } {
+	if table, ok := store.store.(*tieredTable); ok {
+		return table.keys
+	}
+	return store.store
+}
+
+// fragmented reports whether the share of the capacity not used by the live entries reached the threshold,
+// in percent
+func fragmented(live, capacity, threshold int) bool {
+	return capacity >= defragMinCapacity && (capacity-live)*100 >= threshold*capacity
+}
+
+// Defrag gives back the memory the store holds for the entries it no longer has, until the budget elapses: the
+// tables of the keys and of the expiries are rebuilt once fragmented, and the expiries scheduled for keys
+// deleted or whose TTL changed are dropped. Every call resumes where the previous one stopped, so that the
+// shard defragments a little every cron run instead of pausing its commands. It is called between the commands.
+func Defrag(store *Store, budget time.Duration) {
+	deadline := time.Now().Add(budget)
+	threshold := config.DiceConfig().Memory.ActiveDefragThreshold
+	moved, dropped, rebuilt := 0, 0, 0
+	defer func() {
+		if moved > 0 || dropped > 0 || rebuilt > 0 {
+			stats.Defragged(moved, dropped, rebuilt)
+		}
+	}()
+
+	for _, table := range []interface{ Len() int }{store.keysTable(), store.expires} {
+		c, ok := table.(common.Compactable)
+		if !ok || (!c.Compacting() && !fragmented(table.Len(), c.Capacity(), threshold)) {
+			continue
+		}
+		for {
+			n, done := c.Compact(defragChunk)
+			moved += n
+			if done {
+				rebuilt++
+				break
+			}
+			if time.Now().After(deadline) {
+				return
+			}
+		}
+	}
+
+	// Every key with a TTL has one expiry scheduled, the others are stale. A pass checks every slot once.
+	cursor := &store.defrag
+	if cursor.left == 0 {
+		if !fragmented(store.expires.Len(), store.expiryWheel.Len(), threshold) {
+			return
+		}
+		cursor.left = wheelLevels * wheelSlots
+	}
+	for cursor.left > 0 {
+		next, n, done := store.expiryWheel.compact(cursor.slot, cursor.entry, defragChunk, store.scheduled)
+		dropped += n
+		cursor.entry = next
+		if done {
+			cursor.slot = (cursor.slot + 1) % (wheelLevels * wheelSlots)
+			cursor.left--
+		}
+		if time.Now().After(deadline) {
+			return
+		}
+	}
+}
+
+// scheduled reports whether the expiry scheduled is the one of the TTL of its key, the ones of the keys
+// deleted or whose TTL changed never expire their key
+func (store *Store) scheduled(e wheelEntry) bool {
+	obj, ok := store.peekKey(e.key)
+	if !ok {
+		return false
+	}
+	exp, ok := store.expires.Get(obj)
+	return ok && exp == e.at
+}
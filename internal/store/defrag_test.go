@@ -0,0 +1,154 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/common"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// churnedStore returns a store which held n keys with a TTL, of which only the ones whose index is a multiple
+// of 10 are left, every key left having had its TTL changed once
+func churnedStore(t *testing.T, n int) *Store {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	store := NewStore(nil, nil)
+	for i := 0; i < n; i++ {
+		k := "key:" + strconv.Itoa(i)
+		store.Put(k, store.NewObj(k, 60000, object.ObjTypeString))
+	}
+	for i := 0; i < n; i++ {
+		k := "key:" + strconv.Itoa(i)
+		if i%10 != 0 {
+			store.Del(k)
+			continue
+		}
+		store.SetKeyExpiry(k, store.Get(k), 120000)
+	}
+	return store
+}
+
+func TestDefrag(t *testing.T) {
+	store := churnedStore(t, 10000)
+
+	f := store.Fragmentation()
+	assert.Equal(t, 2000, f.Entries)
+	assert.Equal(t, 20000, f.Capacity)
+	assert.Equal(t, 11000, f.Scheduled)
+	assert.Equal(t, 1000, f.Expires)
+
+	before := stats.Get()
+	Defrag(store, time.Minute)
+
+	f = store.Fragmentation()
+	assert.Equal(t, FragmentationStats{Entries: 2000, Capacity: 2000, Scheduled: 1000, Expires: 1000}, f)
+	after := stats.Get()
+	assert.EqualValues(t, 2000, after.DefragHits-before.DefragHits)
+	assert.EqualValues(t, 10000, after.DefragExpiriesDropped-before.DefragExpiriesDropped)
+	assert.EqualValues(t, 2, after.DefragRebuilds-before.DefragRebuilds)
+
+	// The keys left keep their values and their TTL, which still expires them
+	for i := 0; i < 10000; i += 10 {
+		k := "key:" + strconv.Itoa(i)
+		obj := store.Get(k)
+		require.NotNil(t, obj, k)
+		assert.Equal(t, k, obj.Value)
+		exp, ok := GetExpiry(obj, store)
+		assert.True(t, ok)
+		assert.Greater(t, exp, uint64(time.Now().Add(time.Minute).UnixMilli()))
+	}
+
+	// A table no longer fragmented is left as it is
+	Defrag(store, time.Minute)
+	assert.EqualValues(t, after.DefragHits, stats.Get().DefragHits)
+}
+
+func TestDefragBudget(t *testing.T) {
+	store := churnedStore(t, 10000)
+
+	// Without budget, a step moves a chunk of entries: the commands executed between the steps see the
+	// entries of the tables being rebuilt
+	Defrag(store, 0)
+	require.True(t, store.Fragmentation().Rebuilding)
+
+	store.Put("key:new", store.NewObj("new", 60000, object.ObjTypeString))
+	store.Del("key:0")
+	store.Put("key:10", store.NewObj("updated", -1, object.ObjTypeString))
+
+	steps := 1
+	for store.Fragmentation().Rebuilding {
+		Defrag(store, 0)
+		steps++
+	}
+	assert.Greater(t, steps, 2)
+
+	assert.Nil(t, store.Get("key:0"))
+	assert.Equal(t, "new", store.Get("key:new").Value)
+	obj := store.Get("key:10")
+	assert.Equal(t, "updated", obj.Value)
+	_, ok := GetExpiry(obj, store)
+	assert.False(t, ok)
+	assert.Equal(t, 1000, store.GetKeyCount())
+	assert.Equal(t, 999, int(store.GetExpiresCount()))
+}
+
+func TestRegMapCompact(t *testing.T) {
+	table := &common.RegMap[int, int]{M: make(map[int]int)}
+	for i := 0; i < 100; i++ {
+		table.Put(i, i)
+	}
+	for i := 10; i < 100; i++ {
+		table.Delete(i)
+	}
+	assert.Equal(t, 100, table.Capacity())
+
+	moved, done := table.Compact(4)
+	assert.Equal(t, 4, moved)
+	assert.False(t, done)
+	assert.True(t, table.Compacting())
+	assert.Equal(t, 10, table.Len())
+
+	// The entries written during the rebuild replace the ones not moved yet
+	table.Put(9, -9)
+	table.Delete(8)
+	table.Put(200, 200)
+
+	n := 0
+	table.All(func(k, v int) bool {
+		n++
+		return true
+	})
+	assert.Equal(t, 10, n)
+
+	for !done {
+		_, done = table.Compact(4)
+	}
+	assert.False(t, table.Compacting())
+	assert.Equal(t, 10, table.Len())
+	assert.Equal(t, 10, table.Capacity())
+	v, _ := table.Get(9)
+	assert.Equal(t, -9, v)
+	_, ok := table.Get(8)
+	assert.False(t, ok)
+}
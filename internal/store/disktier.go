@@ -0,0 +1,166 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// compactMinGarbage is the number of bytes of deleted values above which the file of a disk tier is compacted,
+// once they also outweigh the live values
+const compactMinGarbage = 64 * 1024 * 1024
+
+// DiskTier is a Tier storing the values in a file. The values are appended to the file and indexed in memory,
+// the space of the deleted values is reclaimed by rewriting the file once it is mostly garbage.
+//
+// The file only extends the memory of the shard: it is truncated when opened, the durability of the dataset
+// is the business of the AOF and the WAL.
+type DiskTier struct {
+	path    string
+	file    *os.File
+	index   map[string]extent // index is the location of the value of every key in the file
+	size    int64             // size is the size of the file
+	garbage int64             // garbage is the number of bytes of the file held by deleted values
+}
+
+// extent is the location of a value in the file of a disk tier
+type extent struct {
+	offset int64
+	length int64
+}
+
+// DiskTierPath returns the path of the disk tier file of the shard in dir.
+func DiskTierPath(dir string, shard uint8) string {
+	return filepath.Join(dir, fmt.Sprintf("shard-%d.tier", shard))
+}
+
+// OpenDiskTier creates the file of a disk tier, truncating it if it exists.
+func OpenDiskTier(path string) (*DiskTier, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskTier{
+		path:  path,
+		file:  file,
+		index: make(map[string]extent),
+	}, nil
+}
+
+func (t *DiskTier) Put(key string, value []byte) error {
+	if err := t.Delete(key); err != nil {
+		return err
+	}
+
+	// The key is written before the value, so that the file can be inspected
+	record := binary.AppendUvarint(nil, uint64(len(key)))
+	record = append(record, key...)
+	record = binary.AppendUvarint(record, uint64(len(value)))
+	header := int64(len(record))
+	record = append(record, value...)
+
+	if _, err := t.file.WriteAt(record, t.size); err != nil {
+		return err
+	}
+	t.index[key] = extent{offset: t.size + header, length: int64(len(value))}
+	t.size += int64(len(record))
+	return nil
+}
+
+func (t *DiskTier) Get(key string) ([]byte, error) {
+	e, ok := t.index[key]
+	if !ok {
+		return nil, fmt.Errorf("key %q is not in the disk tier", key)
+	}
+
+	value := make([]byte, e.length)
+	if _, err := t.file.ReadAt(value, e.offset); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (t *DiskTier) Delete(key string) error {
+	e, ok := t.index[key]
+	if !ok {
+		return nil
+	}
+
+	delete(t.index, key)
+	t.garbage += e.length
+	if t.garbage > compactMinGarbage && t.garbage > t.size-t.garbage {
+		return t.compact()
+	}
+	return nil
+}
+
+// Len returns the number of values stored
+func (t *DiskTier) Len() int {
+	return len(t.index)
+}
+
+// Size returns the size of the file, deleted values included
+func (t *DiskTier) Size() int64 {
+	return t.size
+}
+
+func (t *DiskTier) Reset() error {
+	if err := t.file.Truncate(0); err != nil {
+		return err
+	}
+	t.index = make(map[string]extent)
+	t.size = 0
+	t.garbage = 0
+	return nil
+}
+
+func (t *DiskTier) Close() error {
+	return t.file.Close()
+}
+
+// compact rewrites the live values to a new file, which replaces the current one
+func (t *DiskTier) compact() error {
+	compacted, err := OpenDiskTier(t.path + ".compact")
+	if err != nil {
+		return err
+	}
+
+	for key := range t.index {
+		value, err := t.Get(key)
+		if err == nil {
+			err = compacted.Put(key, value)
+		}
+		if err != nil {
+			compacted.Close()
+			os.Remove(compacted.path)
+			return err
+		}
+	}
+
+	if err := os.Rename(compacted.path, t.path); err != nil {
+		compacted.Close()
+		os.Remove(compacted.path)
+		return err
+	}
+	t.file.Close()
+	compacted.path = t.path
+	*t = *compacted
+	return nil
+}
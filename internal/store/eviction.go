@@ -19,6 +19,7 @@ package store
 import (
 	"time"
 
+	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/server/utils"
 )
@@ -55,8 +56,8 @@ const (
 
 // evictionItem stores essential data needed for eviction decision
 type evictionItem struct {
-	key          string
-	lastAccessed uint32
+	key  string
+	idle uint32 // idle is the number of ticks of the LRU clock since the key was accessed
 }
 
 // EvictionStrategy defines the interface for different eviction strategies
@@ -90,15 +91,33 @@ func (b *BaseEvictionStrategy) GetStats() EvictionStats {
 	return b.stats
 }
 
+// lruClockMax is the largest value of the LRU clock, which wraps around once it is reached
+const lruClockMax = 0x00FFFFFF
+
+// lruClockResolution returns the duration of a tick of the LRU clock in milliseconds
+func lruClockResolution() int64 {
+	if resolution := config.DiceConfig().Memory.LRUClockResolution; resolution > 0 {
+		return resolution
+	}
+	return config.DefaultLRUClockResolution
+}
+
+// getCurrentClock returns the LRU clock, the number of ticks of memory.lru_clock_resolution since the epoch
+// modulo 2^24, which the objects are stamped with when they are accessed.
 func getCurrentClock() uint32 {
-	return uint32(utils.GetCurrentTime().Unix()) & 0x00FFFFFF
+	return uint32(utils.GetCurrentTime().UnixMilli()/lruClockResolution()) & lruClockMax
 }
 
-func GetIdleTime(lastAccessedAt uint32) uint32 {
-	c := getCurrentClock()
-	lastAccessedAt &= 0x00FFFFFF
-	if c >= lastAccessedAt {
-		return c - lastAccessedAt
+// lruIdle returns the number of ticks of the LRU clock since lastAccessedAt, now being the current clock
+func lruIdle(lastAccessedAt, now uint32) uint32 {
+	lastAccessedAt &= lruClockMax
+	if now >= lastAccessedAt {
+		return now - lastAccessedAt
 	}
-	return (0x00FFFFFF - lastAccessedAt) + c
+	return (lruClockMax - lastAccessedAt) + now
+}
+
+// GetIdleTime returns the number of seconds since lastAccessedAt, as measured by the LRU clock.
+func GetIdleTime(lastAccessedAt uint32) uint32 {
+	return uint32(int64(lruIdle(lastAccessedAt, getCurrentClock())) * lruClockResolution() / 1000)
 }
@@ -0,0 +1,82 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/dicedb/dice/internal/object"
+)
+
+// evictionPoolSize is the number of candidates kept by the eviction pool, as in Redis
+const evictionPoolSize = 16
+
+// evictionCandidate is a key of the eviction pool, with the object and the access stamp it was sampled with
+type evictionCandidate struct {
+	evictionItem
+	obj          *object.Obj
+	lastAccessed uint32
+}
+
+// evictionPool keeps the idlest keys sampled so far, the candidates for eviction. A key sampled for a victim
+// stays a candidate for the next ones until idler keys replace it, so that a few samples per victim evict
+// close to an exact LRU.
+type evictionPool struct {
+	candidates []evictionCandidate // candidates are ordered by idle time, the idlest last
+}
+
+// populate samples count keys of the store, keeping the ones idler than the candidates
+func (p *evictionPool) populate(store *Store, count int, now uint32) {
+	store.sampleKeys(count, func(key string, obj *object.Obj) {
+		p.offer(evictionCandidate{
+			evictionItem: evictionItem{key: key, idle: lruIdle(obj.LastAccessedAt, now)},
+			obj:          obj,
+			lastAccessed: obj.LastAccessedAt,
+		})
+	})
+}
+
+// offer adds the key to the candidates if the pool is not full or the key is idler than one of them
+func (p *evictionPool) offer(c evictionCandidate) {
+	if len(p.candidates) == evictionPoolSize && c.idle <= p.candidates[0].idle {
+		return
+	}
+	if i := slices.IndexFunc(p.candidates, func(other evictionCandidate) bool { return other.key == c.key }); i >= 0 {
+		p.candidates = slices.Delete(p.candidates, i, i+1)
+	}
+
+	i, _ := slices.BinarySearchFunc(p.candidates, c, func(a, b evictionCandidate) int { return cmp.Compare(a.idle, b.idle) })
+	p.candidates = slices.Insert(p.candidates, i, c)
+	if len(p.candidates) > evictionPoolSize {
+		p.candidates = slices.Delete(p.candidates, 0, 1)
+	}
+}
+
+// pop removes the idlest candidate still stored as it was sampled and returns its key. The candidates deleted,
+// overwritten or accessed since they were sampled are dropped.
+func (p *evictionPool) pop(store *Store) (string, bool) {
+	for len(p.candidates) > 0 {
+		c := p.candidates[len(p.candidates)-1]
+		p.candidates = p.candidates[:len(p.candidates)-1]
+
+		if obj, ok := store.peekKey(c.key); ok && obj == c.obj && obj.LastAccessedAt == c.lastAccessed {
+			return c.key, true
+		}
+	}
+	return "", false
+}
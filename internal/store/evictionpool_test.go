@@ -0,0 +1,122 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withMockClock(t *testing.T) *utils.MockClock {
+	previous := utils.CurrentTime
+	mockTime := &utils.MockClock{CurrTime: time.Now()}
+	utils.CurrentTime = mockTime
+	t.Cleanup(func() { utils.CurrentTime = previous })
+	return mockTime
+}
+
+// evictionPrecision fills a store with keys accessed one second apart, evicts a tenth of them and returns the
+// fraction of the victims that an exact LRU evicts too, the oldest tenth of the keys
+func evictionPrecision(t *testing.T, samples int) float64 {
+	const keys, victims = 10000, 1000
+
+	mockTime := withMockClock(t)
+	eviction := NewSampledEvictionLRU(keys+1, 0.1, samples)
+	s := NewStore(nil, eviction)
+	for i := 0; i < keys; i++ {
+		mockTime.SetTime(mockTime.GetTime().Add(time.Second))
+		s.Put(strconv.Itoa(i), &object.Obj{})
+	}
+
+	eviction.EvictVictims(s, victims)
+	require.Equal(t, keys-victims, s.GetKeyCount())
+
+	exact := 0
+	for i := 0; i < victims; i++ {
+		if s.GetNoTouch(strconv.Itoa(i)) == nil {
+			exact++
+		}
+	}
+	return float64(exact) / victims
+}
+
+func TestSampledEvictionPrecision(t *testing.T) {
+	// The exact LRU scans the keyspace
+	assert.InDelta(t, 1, evictionPrecision(t, 0), 0)
+
+	// An eviction sampling more keys is closer to the exact LRU, much closer than evicting random keys
+	few, many := evictionPrecision(t, 5), evictionPrecision(t, 32)
+	t.Logf("precision with 5 samples %.3f, with 32 samples %.3f", few, many)
+	assert.Greater(t, few, 0.3)
+	assert.Greater(t, many, 0.8)
+	assert.Greater(t, many, few)
+}
+
+func TestEvictionPool(t *testing.T) {
+	withMockClock(t)
+	s := NewStore(nil, NewBatchEvictionLRU(100, 0.1))
+	p := &evictionPool{}
+
+	objs := make(map[string]*object.Obj)
+	for i := 0; i < 2*evictionPoolSize; i++ {
+		key := strconv.Itoa(i)
+		objs[key] = &object.Obj{}
+		s.Put(key, objs[key])
+		p.offer(evictionCandidate{evictionItem: evictionItem{key: key, idle: uint32(i)}, obj: objs[key],
+			lastAccessed: objs[key].LastAccessedAt})
+	}
+	// The pool keeps the idlest keys, and a key offered again replaces its candidate
+	p.offer(evictionCandidate{evictionItem: evictionItem{key: "20", idle: 100}, obj: objs["20"],
+		lastAccessed: objs["20"].LastAccessedAt})
+	require.Len(t, p.candidates, evictionPoolSize)
+	assert.Equal(t, "20", p.candidates[evictionPoolSize-1].key)
+	assert.Equal(t, "16", p.candidates[0].key)
+
+	key, ok := p.pop(s)
+	assert.True(t, ok)
+	assert.Equal(t, "20", key)
+
+	// The candidates deleted, overwritten or accessed since they were sampled are dropped
+	s.Del("31")
+	s.Put("30", &object.Obj{})
+	objs["29"].LastAccessedAt++
+	key, ok = p.pop(s)
+	assert.True(t, ok)
+	assert.Equal(t, "28", key)
+}
+
+func TestLRUClockResolution(t *testing.T) {
+	previous := config.DiceConfig().Memory.LRUClockResolution
+	t.Cleanup(func() { config.DiceConfig().Memory.LRUClockResolution = previous })
+	mockTime := withMockClock(t)
+
+	config.DiceConfig().Memory.LRUClockResolution = 100
+	accessedAt := getCurrentClock()
+	mockTime.SetTime(mockTime.GetTime().Add(1500 * time.Millisecond))
+	assert.EqualValues(t, 15, lruIdle(accessedAt, getCurrentClock()))
+	assert.EqualValues(t, 1, GetIdleTime(accessedAt), "the idle time is reported in seconds")
+
+	// The clock wraps around after 2^24 ticks
+	assert.EqualValues(t, 10, lruIdle(lruClockMax-5, 5))
+}
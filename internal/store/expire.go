@@ -48,45 +48,52 @@ func DelExpiry(obj *object.Obj, store *Store) {
 	store.expires.Delete(obj)
 }
 
-// TODO: Optimize
-//   - Sampling
-//   - Unnecessary iteration
-func expireSample(store *Store) float32 {
-	var limit = 20
-	var expiredCount = 0
-	var keysToDelete []string
-
-	// Collect keys to be deleted
-	store.store.All(func(keyPtr string, obj *object.Obj) bool {
+// expireBatchLimit is the number of keys the active expiry deletes at most in a cycle, the keys left
+// are deleted by the next cycles or on access.
+const expireBatchLimit = 20000
+
+// fieldExpireSampleSize is the number of keys sampled in a cycle for the expired fields of their hashes.
+const fieldExpireSampleSize = 20
+
+// expireDueKey expires the key if its TTL elapsed, reporting whether it did.
+func (store *Store) expireDueKey(k string) bool {
+	obj, ok := store.store.Get(k)
+	if !ok || !hasExpired(obj, store) {
+		return false
+	}
+	store.expireKey(k, obj)
+	return true
+}
+
+// expireFieldsSample removes the expired fields of the hashes among a sample of the keys.
+func expireFieldsSample(store *Store) {
+	if len(store.fieldExpires) == 0 {
+		return
+	}
+
+	var keysWithExpiringFields []string
+	limit := fieldExpireSampleSize
+	store.allKeys(func(keyPtr string, obj *object.Obj) bool {
 		limit--
-		if hasExpired(obj, store) {
-			keysToDelete = append(keysToDelete, keyPtr)
-			expiredCount++
+		if _, ok := store.fieldExpires[obj]; ok {
+			keysWithExpiringFields = append(keysWithExpiringFields, keyPtr)
 		}
-		// once we iterated to 20 keys that have some expiration set
-		// we break the loop
-		return limit >= 0
+		return limit > 0
 	})
 
-	// Delete the keys outside the read lock
-	for _, keyPtr := range keysToDelete {
-		store.DelByPtr(keyPtr, WithDelCmd(Del))
+	for _, keyPtr := range keysWithExpiringFields {
+		if obj, ok := store.store.Get(keyPtr); ok {
+			store.expireFields(keyPtr, obj)
+		}
 	}
-
-	return float32(expiredCount) / float32(20.0)
 }
 
-// DeleteExpiredKeys deletes all the expired keys - the active way
-// Sampling approach: https://redis.io/commands/expire/
+// DeleteExpiredKeys deletes the expired keys - the active way. The keys with a TTL are scheduled on
+// a timing wheel, the keys due up to now are deleted as a batch.
 func DeleteExpiredKeys(store *Store) {
-	for {
-		frac := expireSample(store)
-		// if the sample had less than 25% keys expired
-		// we break the loop.
-		if frac < 0.25 {
-			break
-		}
-	}
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	store.expiryWheel.Advance(now, expireBatchLimit, store.expireDueKey)
+	expireFieldsSample(store)
 }
 
 // NX: Set the expiration only if the key does not already have an expiration time.
@@ -110,7 +117,7 @@ func EvaluateAndSetExpiry(subCommands []string, newExpiry int64, key string,
 	shouldSetExpiry = true
 	// if no condition exists
 	if len(subCommands) == 0 {
-		store.SetUnixTimeExpiry(obj, newExpiry)
+		store.setKeyUnixTimeExpiry(key, obj, newExpiry)
 		return shouldSetExpiry, nil
 	}
 
@@ -158,7 +165,7 @@ func EvaluateAndSetExpiry(subCommands []string, newExpiry int64, key string,
 	}
 
 	if shouldSetExpiry {
-		store.SetUnixTimeExpiry(obj, newExpiry)
+		store.setKeyUnixTimeExpiry(key, obj, newExpiry)
 	}
 	return shouldSetExpiry, nil
 }
@@ -0,0 +1,106 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// FieldMap is the value of an object whose fields can be given an expiry, e.g. a hash.
+type FieldMap interface {
+	// DeleteField removes the field from the value
+	DeleteField(field string)
+	// FieldCount returns the number of fields of the value
+	FieldCount() int
+}
+
+// SetFieldExpiry sets the expiry time, in unix milliseconds, of the field of the object.
+// The value of obj must be a FieldMap.
+func (store *Store) SetFieldExpiry(obj *object.Obj, field string, expiresAtMs uint64) {
+	fields, ok := store.fieldExpires[obj]
+	if !ok {
+		fields = make(map[string]uint64)
+		store.fieldExpires[obj] = fields
+	}
+	fields[field] = expiresAtMs
+}
+
+// GetFieldExpiry returns the expiry time, in unix milliseconds, of the field of the object.
+func (store *Store) GetFieldExpiry(obj *object.Obj, field string) (uint64, bool) {
+	exp, ok := store.fieldExpires[obj][field]
+	return exp, ok
+}
+
+// DelFieldExpiry removes the expiry of the field of the object and returns true if it had one.
+// It must be called whenever a field is deleted or overwritten, so that its expiry does not apply
+// to the next value of the field.
+func (store *Store) DelFieldExpiry(obj *object.Obj, field string) bool {
+	fields, ok := store.fieldExpires[obj]
+	if !ok {
+		return false
+	}
+	if _, ok = fields[field]; !ok {
+		return false
+	}
+
+	delete(fields, field)
+	if len(fields) == 0 {
+		delete(store.fieldExpires, obj)
+	}
+	return true
+}
+
+// GetFieldExpiresCount returns the number of fields with an expiry set
+func (store *Store) GetFieldExpiresCount() int {
+	count := 0
+	for _, fields := range store.fieldExpires {
+		count += len(fields)
+	}
+	return count
+}
+
+// expireFields removes the fields of the object stored at k whose expiry elapsed. The key is removed
+// with an EXPIRED key event when no field is left. Returns true if the key was removed.
+func (store *Store) expireFields(k string, obj *object.Obj) bool {
+	fields, ok := store.fieldExpires[obj]
+	if !ok {
+		return false
+	}
+	fieldMap, ok := obj.Value.(FieldMap)
+	if !ok {
+		delete(store.fieldExpires, obj)
+		return false
+	}
+
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	for field, exp := range fields {
+		if exp <= now {
+			fieldMap.DeleteField(field)
+			delete(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		delete(store.fieldExpires, obj)
+	}
+
+	if fieldMap.FieldCount() > 0 {
+		return false
+	}
+	store.expireKey(k, obj)
+	return true
+}
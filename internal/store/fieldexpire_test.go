@@ -0,0 +1,75 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFieldMap map[string]string
+
+func (m testFieldMap) DeleteField(field string) { delete(m, field) }
+
+func (m testFieldMap) FieldCount() int { return len(m) }
+
+func TestFieldExpiry(t *testing.T) {
+	store := NewStore(nil, nil)
+	var expired []string
+	store.SubscribeExpired(func(e KeyEvent) {
+		expired = append(expired, e.Key)
+	})
+
+	now := uint64(time.Now().UnixMilli())
+	obj := store.NewObj(testFieldMap{"a": "1", "b": "2", "c": "3"}, 60000, object.ObjTypeHashMap)
+	store.Put("hash", obj)
+	store.SetFieldExpiry(obj, "a", now-1)
+	store.SetFieldExpiry(obj, "b", now+60000)
+	assert.Equal(t, 2, store.GetFieldExpiresCount())
+
+	// Elapsed fields are removed on access
+	assert.Equal(t, testFieldMap{"b": "2", "c": "3"}, store.Get("hash").Value)
+	_, ok := store.GetFieldExpiry(obj, "a")
+	assert.False(t, ok)
+
+	// Putting the object back once modified in place keeps the expiry of the key and of its fields
+	store.Put("hash", obj)
+	_, ok = GetExpiry(obj, store)
+	assert.True(t, ok)
+	_, ok = store.GetFieldExpiry(obj, "b")
+	assert.True(t, ok)
+
+	// The key is removed by the active expiry once its last field expires
+	assert.True(t, store.DelFieldExpiry(obj, "b"))
+	assert.False(t, store.DelFieldExpiry(obj, "b"))
+	delete(obj.Value.(testFieldMap), "c")
+	store.SetFieldExpiry(obj, "b", now-1)
+	DeleteExpiredKeys(store)
+	assert.Nil(t, store.Get("hash"))
+	assert.Equal(t, []string{"hash"}, expired)
+	assert.Equal(t, 0, store.GetFieldExpiresCount())
+
+	// Replacing the object drops the expiry of its fields
+	obj = store.NewObj(testFieldMap{"a": "1"}, -1, object.ObjTypeHashMap)
+	store.Put("hash", obj)
+	store.SetFieldExpiry(obj, "a", now+60000)
+	store.Put("hash", store.NewObj("v", -1, object.ObjTypeString))
+	assert.Equal(t, 0, store.GetFieldExpiresCount())
+}
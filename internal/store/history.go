@@ -0,0 +1,225 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/config"
+	diceerrors "github.com/dicedb/dice/internal/errors"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/gobwas/glob"
+)
+
+var (
+	ErrHistoryDisabled  = diceerrors.New(diceerrors.CodeGeneric, "history is disabled")
+	ErrNoHistory        = diceerrors.New(diceerrors.CodeGeneric, "no history is kept for the key, it does not match history.patterns")
+	ErrOutsideRetention = diceerrors.New(diceerrors.CodeGeneric, "the timestamp is outside the history retention window")
+)
+
+// Revision is a value a key held from a time on.
+type Revision struct {
+	// Value is the string or the integer held by the key, nil once the key was deleted or held another type
+	Value interface{}
+	// ChangedAt is the time the key took the value in unix milliseconds
+	ChangedAt int64
+}
+
+// keyHistory is the revisions of a key, oldest first.
+type keyHistory struct {
+	revisions []Revision
+	since     int64 // since is the time the revisions are complete from, once the oldest were dropped for max_versions
+}
+
+// historyEntry is a revision of a key in the order the revisions were recorded.
+type historyEntry struct {
+	key       string
+	changedAt int64
+}
+
+// history holds the previous values of the keys matching history.patterns while history.enabled is set. The
+// revisions superseded for longer than history.retention are purged in the order they were recorded, and the
+// oldest ones beyond history.max_versions as the keys are written.
+type history struct {
+	keys     map[string]*keyHistory
+	order    []historyEntry // order is the revisions by time of change, including the ones dropped since
+	patterns string         // patterns are the history.patterns globs is compiled from
+	globs    []glob.Glob
+}
+
+// tracks reports whether the history of the key is kept.
+func (h *history) tracks(k string) bool {
+	patterns := strings.Join(config.DiceConfig().History.Patterns, ",")
+	if h.globs == nil || patterns != h.patterns {
+		h.patterns = patterns
+		h.globs = make([]glob.Glob, 0, len(config.DiceConfig().History.Patterns))
+		for _, pattern := range config.DiceConfig().History.Patterns {
+			// The patterns are validated when the config is loaded, an invalid one matches no key
+			if g, err := glob.Compile(pattern); err == nil {
+				h.globs = append(h.globs, g)
+			}
+		}
+	}
+	for _, g := range h.globs {
+		if g.Match(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRevision records the value of the key once changed, for GETAT and HISTORY: the value of obj, or none
+// when obj is nil as the key was deleted. prev is the value the key held before, if it was replaced by another
+// object rather than modified in place. Consecutive changes leaving the value as it was, e.g. an EXPIRE, are
+// not recorded.
+func (store *Store) recordRevision(k string, prev, obj *object.Obj) {
+	if !config.DiceConfig().History.Enabled || !store.history.tracks(k) {
+		return
+	}
+
+	value := revisionValue(obj)
+	h := store.history.keys[k]
+	if h == nil {
+		h = &keyHistory{}
+		// The value the key held before its first change recorded is known from time 0 on
+		if before := revisionValue(prev); before != nil {
+			h.revisions = append(h.revisions, Revision{Value: before})
+		} else if value == nil {
+			return
+		}
+		if store.history.keys == nil {
+			store.history.keys = make(map[string]*keyHistory)
+		}
+		store.history.keys[k] = h
+	}
+	if len(h.revisions) > 0 && h.revisions[len(h.revisions)-1].Value == value {
+		return
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	h.revisions = append(h.revisions, Revision{Value: value, ChangedAt: now})
+	if excess := len(h.revisions) - config.DiceConfig().History.MaxVersions; excess > 0 {
+		h.revisions = append(h.revisions[:0], h.revisions[excess:]...)
+		h.since = h.revisions[0].ChangedAt
+	}
+	store.history.order = append(store.history.order, historyEntry{key: k, changedAt: now})
+}
+
+// revisionValue returns the string or the integer held by the object, nil for another type or no object.
+func revisionValue(obj *object.Obj) interface{} {
+	if obj == nil || (obj.Type != object.ObjTypeString && obj.Type != object.ObjTypeInt) {
+		return nil
+	}
+	switch v := obj.Value.(type) {
+	case string, int64:
+		return v
+	}
+	return nil
+}
+
+// GetAt returns the value the key held at the time in unix milliseconds, nil if it did not exist or held
+// another type than a string. The time must be within history.retention.
+func (store *Store) GetAt(k string, at int64) (interface{}, error) {
+	if err := store.historyOf(k); err != nil {
+		return nil, err
+	}
+
+	now := utils.GetCurrentTime().UnixMilli()
+	h := store.history.keys[k]
+	if at < now-config.DiceConfig().History.Retention.Milliseconds() || (h != nil && at < h.since) {
+		return nil, ErrOutsideRetention
+	}
+
+	// A key without revisions did not change since the history is kept, it held its current value
+	if h == nil {
+		return revisionValue(store.GetNoTouch(k)), nil
+	}
+	for i := len(h.revisions) - 1; i >= 0; i-- {
+		if h.revisions[i].ChangedAt <= at {
+			return h.revisions[i].Value, nil
+		}
+	}
+	return nil, nil
+}
+
+// History returns the revisions of the key kept, oldest first: the ones changed within history.retention, and
+// the one the key held when the retention window starts. A key without revisions did not change since the
+// history is kept.
+func (store *Store) History(k string) ([]Revision, error) {
+	if err := store.historyOf(k); err != nil {
+		return nil, err
+	}
+
+	h := store.history.keys[k]
+	if h == nil {
+		return nil, nil
+	}
+	store.trimHistory(k, h, utils.GetCurrentTime().Add(-config.DiceConfig().History.Retention).UnixMilli())
+	if store.history.keys[k] == nil {
+		return nil, nil
+	}
+	return append([]Revision(nil), h.revisions...), nil
+}
+
+// historyOf checks that the history of the key is kept.
+func (store *Store) historyOf(k string) error {
+	if !config.DiceConfig().History.Enabled {
+		return ErrHistoryDisabled
+	}
+	if !store.history.tracks(k) {
+		return ErrNoHistory
+	}
+	return nil
+}
+
+// trimHistory drops the revisions of the key superseded before the deadline. The history of a key deleted
+// before the deadline is dropped as a whole, the one of a key existing keeps its current value.
+func (store *Store) trimHistory(k string, h *keyHistory, deadline int64) {
+	dropped := 0
+	for dropped < len(h.revisions)-1 && h.revisions[dropped+1].ChangedAt <= deadline {
+		dropped++
+	}
+	if last := h.revisions[len(h.revisions)-1]; last.Value == nil && last.ChangedAt <= deadline {
+		delete(store.history.keys, k)
+		return
+	}
+	h.revisions = append(h.revisions[:0], h.revisions[dropped:]...)
+}
+
+// PurgeHistory drops the revisions superseded for longer than history.retention. The whole history is purged
+// once history.enabled is unset.
+func PurgeHistory(store *Store) {
+	if !config.DiceConfig().History.Enabled {
+		store.history = history{}
+		return
+	}
+
+	deadline := utils.GetCurrentTime().Add(-config.DiceConfig().History.Retention).UnixMilli()
+	purged := 0
+	for _, e := range store.history.order {
+		if e.changedAt > deadline {
+			break
+		}
+		if h, ok := store.history.keys[e.key]; ok {
+			store.trimHistory(e.key, h, deadline)
+		}
+		purged++
+	}
+	clear(store.history.order[:purged])
+	store.history.order = store.history.order[purged:]
+}
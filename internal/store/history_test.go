@@ -0,0 +1,109 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().History.Enabled = true
+	config.DiceConfig().History.Patterns = []string{"user:*"}
+	config.DiceConfig().History.Retention = time.Minute
+	config.DiceConfig().History.MaxVersions = 3
+	start := time.Now()
+	mockTime := &utils.MockClock{CurrTime: start}
+	utils.CurrentTime = mockTime
+	t.Cleanup(func() {
+		config.DiceConfig().History.Enabled = false
+		utils.CurrentTime = utils.RealClock{}
+	})
+	at := func(d time.Duration) int64 {
+		return start.Add(d).UnixMilli()
+	}
+	tick := func() {
+		mockTime.SetTime(mockTime.CurrTime.Add(time.Second))
+	}
+
+	store := NewStore(nil, nil)
+	store.Put("user:1", store.NewObj("a", -1, object.ObjTypeString))
+	tick()
+	obj := store.NewObj(int64(1), -1, object.ObjTypeInt)
+	store.Put("user:1", obj)
+	tick()
+	// A value modified in place is recorded, putting it back unchanged is not
+	obj.Value = int64(2)
+	store.Put("user:1", obj)
+	store.Put("user:1", obj)
+	tick()
+	store.Del("user:1")
+
+	revisions, err := store.History("user:1")
+	require.NoError(t, err)
+	assert.Equal(t, []Revision{
+		{Value: int64(1), ChangedAt: at(time.Second)},
+		{Value: int64(2), ChangedAt: at(2 * time.Second)},
+		{Value: nil, ChangedAt: at(3 * time.Second)},
+	}, revisions, "the oldest revisions are dropped beyond max_versions")
+
+	for d, expected := range map[time.Duration]interface{}{time.Second: int64(1), 2500 * time.Millisecond: int64(2), 3 * time.Second: nil} {
+		value, err := store.GetAt("user:1", at(d))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, value, d)
+	}
+	_, err = store.GetAt("user:1", at(0))
+	assert.ErrorIs(t, err, ErrOutsideRetention)
+
+	// A key without revisions held its current value, the one it held before its first change is known
+	store.Put("user:2", store.NewObj("b", -1, object.ObjTypeString))
+	config.DiceConfig().History.Enabled = false
+	PurgeHistory(store)
+	config.DiceConfig().History.Enabled = true
+	value, err := store.GetAt("user:2", at(0))
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+	store.Put("user:2", store.NewObj("c", -1, object.ObjTypeString))
+	value, err = store.GetAt("user:2", at(2*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+
+	_, err = store.History("session:1")
+	assert.ErrorIs(t, err, ErrNoHistory)
+
+	// The revisions superseded before the retention window are purged, a deleted key's history as a whole
+	mockTime.SetTime(start.Add(2 * time.Minute))
+	_, err = store.GetAt("user:1", at(3*time.Second))
+	assert.ErrorIs(t, err, ErrOutsideRetention)
+	PurgeHistory(store)
+	assert.NotContains(t, store.history.keys, "user:1")
+	revisions, err = store.History("user:2")
+	require.NoError(t, err)
+	assert.Equal(t, []Revision{{Value: "c", ChangedAt: at(3 * time.Second)}}, revisions)
+	assert.Empty(t, store.history.order)
+
+	config.DiceConfig().History.Enabled = false
+	_, err = store.GetAt("user:2", at(0))
+	assert.ErrorIs(t, err, ErrHistoryDisabled)
+}
@@ -0,0 +1,202 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"strings"
+
+	"github.com/dicedb/dice/internal/common"
+	"github.com/dicedb/dice/internal/object"
+)
+
+const (
+	// keyPrefixDelimiter ends the prefix of a key shared with other keys, e.g. 'tenant:1234:session:'
+	keyPrefixDelimiter = ':'
+	// minInternedPrefix is the length from which the prefix of a key is interned, the shorter ones
+	// take less memory kept in the key than referenced
+	minInternedPrefix = 8
+)
+
+// internedKey is a key whose prefix, up to its last delimiter, is stored once for all the keys sharing it.
+type internedKey struct {
+	prefix uint32 // prefix is the identifier of the interned prefix, 0 for a key stored in full
+	suffix string
+}
+
+// internedTable is a table of keys storing every distinct key prefix once, so that the keyspaces with
+// long and repetitive key prefixes take less memory. Iterating it builds the keys back from their parts.
+type internedTable struct {
+	keys     common.RegMap[internedKey, *object.Obj]
+	prefixes map[string]uint32 // prefixes maps the interned prefixes to their identifiers
+	names    []string          // names are the interned prefixes by identifier, the first one is unused
+	refs     []int             // refs are the numbers of keys sharing every prefix
+	free     []uint32          // free are the identifiers of the prefixes no key shares anymore
+	saved    int64             // saved is the number of bytes of the prefixes not stored in every key sharing them
+}
+
+var (
+	_ common.ITable[string, *object.Obj] = (*internedTable)(nil)
+	_ common.Compactable                 = (*internedTable)(nil)
+)
+
+func newInternedTable() *internedTable {
+	return &internedTable{
+		keys:     common.RegMap[internedKey, *object.Obj]{M: make(map[internedKey]*object.Obj)},
+		prefixes: make(map[string]uint32),
+		names:    []string{""},
+		refs:     []int{0},
+	}
+}
+
+// splitKey returns the length of the prefix of the key to intern, 0 when it is stored in full.
+func splitKey(key string) int {
+	n := strings.LastIndexByte(key, keyPrefixDelimiter) + 1
+	if n < minInternedPrefix {
+		return 0
+	}
+	return n
+}
+
+// lookup returns the interned key of key, false when its prefix is not interned.
+func (t *internedTable) lookup(key string) (internedKey, bool) {
+	n := splitKey(key)
+	if n == 0 {
+		return internedKey{suffix: key}, true
+	}
+	id, ok := t.prefixes[key[:n]]
+	return internedKey{prefix: id, suffix: key[n:]}, ok
+}
+
+func (t *internedTable) Put(key string, value *object.Obj) {
+	if k, ok := t.lookup(key); ok {
+		if _, exists := t.keys.Get(k); exists {
+			t.keys.Put(k, value)
+			return
+		}
+	}
+
+	n := splitKey(key)
+	if n == 0 {
+		t.keys.Put(internedKey{suffix: key}, value)
+		return
+	}
+
+	id := t.intern(key[:n])
+
+	// The suffix is copied, so that the key it is sliced from is not kept in memory
+	t.keys.Put(internedKey{prefix: id, suffix: strings.Clone(key[n:])}, value)
+}
+
+// intern returns the identifier of the prefix, interning it if no key shares it yet, and counts one
+// more key sharing it.
+func (t *internedTable) intern(prefix string) uint32 {
+	id, ok := t.prefixes[prefix]
+	if !ok {
+		prefix = strings.Clone(prefix)
+		if len(t.free) > 0 {
+			id = t.free[len(t.free)-1]
+			t.free = t.free[:len(t.free)-1]
+			t.names[id] = prefix
+		} else {
+			id = uint32(len(t.names))
+			t.names = append(t.names, prefix)
+			t.refs = append(t.refs, 0)
+		}
+		t.prefixes[prefix] = id
+		t.saved -= int64(len(prefix))
+	}
+	t.refs[id]++
+	t.saved += int64(len(prefix))
+	return id
+}
+
+func (t *internedTable) Get(key string) (*object.Obj, bool) {
+	k, ok := t.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	return t.keys.Get(k)
+}
+
+func (t *internedTable) Delete(key string) {
+	k, ok := t.lookup(key)
+	if !ok {
+		return
+	}
+	if _, exists := t.keys.Get(k); !exists {
+		return
+	}
+	t.keys.Delete(k)
+	if k.prefix == 0 {
+		return
+	}
+
+	prefix := t.names[k.prefix]
+	t.saved -= int64(len(prefix))
+	if t.refs[k.prefix]--; t.refs[k.prefix] == 0 {
+		t.saved += int64(len(prefix))
+		delete(t.prefixes, prefix)
+		t.names[k.prefix] = ""
+		t.free = append(t.free, k.prefix)
+	}
+}
+
+func (t *internedTable) Len() int {
+	return t.keys.Len()
+}
+
+func (t *internedTable) All(f func(k string, obj *object.Obj) bool) {
+	t.keys.All(func(k internedKey, v *object.Obj) bool {
+		key := k.suffix
+		if k.prefix != 0 {
+			key = t.names[k.prefix] + k.suffix
+		}
+		return f(key, v)
+	})
+}
+
+func (t *internedTable) Capacity() int {
+	return t.keys.Capacity()
+}
+
+func (t *internedTable) Compacting() bool {
+	return t.keys.Compacting()
+}
+
+// Compact rebuilds the map of the keys, the interned prefixes are shared by the keys and left as they are.
+func (t *internedTable) Compact(n int) (moved int, done bool) {
+	return t.keys.Compact(n)
+}
+
+// InterningStats reports the number of key prefixes interned, and the bytes of the keys saved by
+// storing every prefix once.
+type InterningStats struct {
+	Prefixes   int
+	SavedBytes int64
+}
+
+// interningStats returns the interning stats of the table, zero when its keys are stored in full.
+func interningStats(table common.ITable[string, *object.Obj]) InterningStats {
+	if tiered, ok := table.(*tieredTable); ok {
+		table = tiered.keys
+	}
+	t, ok := table.(*internedTable)
+	if !ok {
+		return InterningStats{}
+	}
+	return InterningStats{Prefixes: len(t.prefixes), SavedBytes: t.saved}
+}
@@ -0,0 +1,93 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternedTable(t *testing.T) {
+	table := newInternedTable()
+	objs := map[string]*object.Obj{
+		"tenant:1234:session:a": {},
+		"tenant:1234:session:b": {},
+		"tenant:5678:session:a": {},
+		"short:a":               {},
+		"plain":                 {},
+	}
+	for k, obj := range objs {
+		table.Put(k, obj)
+	}
+	table.Put("plain", objs["plain"])
+
+	assert.Equal(t, len(objs), table.Len())
+	for k, obj := range objs {
+		got, ok := table.Get(k)
+		assert.True(t, ok, k)
+		assert.Same(t, obj, got)
+	}
+	_, ok := table.Get("tenant:9999:session:a")
+	assert.False(t, ok)
+
+	var keys []string
+	table.All(func(k string, _ *object.Obj) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+	assert.Equal(t, []string{"plain", "short:a", "tenant:1234:session:a", "tenant:1234:session:b", "tenant:5678:session:a"}, keys)
+
+	// The prefixes are stored once, the prefix shared by two keys saves its length once
+	assert.Equal(t, InterningStats{Prefixes: 2, SavedBytes: int64(len("tenant:1234:session:"))}, interningStats(table))
+
+	// A prefix is released with the last key sharing it, and its identifier reused
+	table.Delete("tenant:5678:session:a")
+	table.Delete("tenant:5678:session:a")
+	assert.Equal(t, InterningStats{Prefixes: 1, SavedBytes: int64(len("tenant:1234:session:"))}, interningStats(table))
+	table.Put("tenant:0000:session:a", &object.Obj{})
+	assert.Len(t, table.names, 3)
+
+	table.Delete("tenant:1234:session:a")
+	table.Delete("tenant:1234:session:b")
+	table.Delete("tenant:0000:session:a")
+	assert.Equal(t, InterningStats{}, interningStats(table))
+	assert.Equal(t, 2, table.Len())
+}
+
+func TestStoreKeyPrefixInterning(t *testing.T) {
+	config.DiceConfig().Memory.KeyPrefixInterning = true
+	defer func() { config.DiceConfig().Memory.KeyPrefixInterning = false }()
+
+	s := NewStore(nil, nil)
+	s.Put("tenant:1:session:a", s.NewObj("v", -1, object.ObjTypeString))
+	s.Put("tenant:1:session:b", s.NewObj("v", -1, object.ObjTypeString))
+	assert.NotNil(t, s.Get("tenant:1:session:a"))
+	assert.Equal(t, InterningStats{Prefixes: 1, SavedBytes: int64(len("tenant:1:session:"))}, s.InterningStats())
+
+	// A prefix only saves memory once shared
+	assert.True(t, s.Del("tenant:1:session:a"))
+	assert.Equal(t, InterningStats{Prefixes: 1}, s.InterningStats())
+
+	s.ResetStore()
+	assert.Equal(t, 0, s.GetKeyCount())
+	assert.Equal(t, InterningStats{}, s.InterningStats())
+}
@@ -0,0 +1,162 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"time"
+
+	"github.com/dicedb/dice/hooks"
+)
+
+// KeyEventType is the kind of change of a key reported to the key event subscribers.
+type KeyEventType uint8
+
+const (
+	// KeyEventPut is reported once a key is written, after the command writing it has updated its value
+	KeyEventPut KeyEventType = iota + 1
+	// KeyEventDel is reported once a key is removed, be it deleted, renamed, expired or evicted.
+	// The expiry of a key is reported with the EXPIRED command, whether the key was found expired
+	// by the active expiry or on access, and always before the next write to the key.
+	KeyEventDel
+)
+
+// KeyEvent is a change of a key of a store.
+type KeyEvent struct {
+	Type      KeyEventType
+	Key       string
+	Cmd       string    // Cmd is the store command that caused the change, e.g. SET, DEL, RENAME or EVICT
+	ChangedAt time.Time // ChangedAt is the time at which the key was changed
+}
+
+// KeyEventSubscriber is notified of the changes of the keys of a store. It is the single extension point
+// of the store for the features reacting to key changes, e.g. the command watches or the blocking commands.
+// Subscribers are called synchronously by the goroutine of the shard owning the store, right after the
+// change, and must not block.
+type KeyEventSubscriber interface {
+	OnKeyEvent(e KeyEvent)
+}
+
+// KeyEventBatchSubscriber is a KeyEventSubscriber notified of the key events of a Batch at once, e.g. to take
+// its locks or to forward the events once per batch rather than once per key.
+type KeyEventBatchSubscriber interface {
+	KeyEventSubscriber
+	// OnKeyEvents is called with the key events of a batch, in the order of the writes. The slice must not be
+	// retained once it returns.
+	OnKeyEvents(events []KeyEvent)
+}
+
+// KeyEventFunc adapts a function to a KeyEventSubscriber.
+type KeyEventFunc func(e KeyEvent)
+
+func (f KeyEventFunc) OnKeyEvent(e KeyEvent) {
+	f(e)
+}
+
+type keyEventSubscription struct {
+	id         uint64
+	subscriber KeyEventSubscriber
+}
+
+// Subscribe registers the subscriber for the key events of the store and returns the function unregistering it.
+// Like the other methods of the store, it must be called by the goroutine of the shard owning the store,
+// or before the shard is started.
+func (store *Store) Subscribe(subscriber KeyEventSubscriber) (unsubscribe func()) {
+	store.lastSubscriptionID++
+	id := store.lastSubscriptionID
+	store.subscriptions = append(store.subscriptions, keyEventSubscription{id: id, subscriber: subscriber})
+
+	return func() {
+		for i, s := range store.subscriptions {
+			if s.id == id {
+				store.subscriptions = append(store.subscriptions[:i:i], store.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SubscribeExpired registers fn for the expiries of the keys of the store only, and returns the function
+// unregistering it.
+func (store *Store) SubscribeExpired(fn func(e KeyEvent)) (unsubscribe func()) {
+	return store.Subscribe(KeyEventFunc(func(e KeyEvent) {
+		if e.Type == KeyEventDel && e.Cmd == Expired {
+			fn(e)
+		}
+	}))
+}
+
+// notify reports the change of the key to the subscribers, once the batch is committed while one is open.
+func (store *Store) notify(eventType KeyEventType, cmd, key string) {
+	if len(store.subscriptions) == 0 {
+		return
+	}
+
+	e := KeyEvent{Type: eventType, Key: key, Cmd: cmd, ChangedAt: time.Now()}
+	if store.batchDepth > 0 {
+		store.pendingEvents = append(store.pendingEvents, e)
+		return
+	}
+	for _, s := range store.subscriptions {
+		s.subscriber.OnKeyEvent(e)
+	}
+}
+
+// cmdWatchForwarder forwards the key events to the watch manager, which re-executes the watched commands
+// affected by them.
+type cmdWatchForwarder chan CmdWatchEvent
+
+func (f cmdWatchForwarder) OnKeyEvent(e KeyEvent) {
+	f <- CmdWatchEvent{Cmd: e.Cmd, AffectedKey: e.Key, ChangedAt: e.ChangedAt}
+}
+
+// OnKeyEvents forwards the consecutive events of a batch caused by the same command as a single event, e.g.
+// one for all the keys of an MSET.
+func (f cmdWatchForwarder) OnKeyEvents(events []KeyEvent) {
+	for i := 0; i < len(events); {
+		j := i + 1
+		for j < len(events) && events[j].Cmd == events[i].Cmd {
+			j++
+		}
+		if j == i+1 {
+			f.OnKeyEvent(events[i])
+		} else {
+			keys := make([]string, 0, j-i)
+			for _, e := range events[i:j] {
+				keys = append(keys, e.Key)
+			}
+			f <- CmdWatchEvent{Cmd: events[i].Cmd, AffectedKeys: keys, ChangedAt: events[i].ChangedAt}
+		}
+		i = j
+	}
+}
+
+// hooksForwarder publishes the key events to the hooks registered by the program embedding the server, which
+// are called by the goroutine of the hooks rather than by the one of the shard.
+func hooksForwarder() KeyEventFunc {
+	return func(e KeyEvent) {
+		event := hooks.Event{Type: hooks.Write, Key: e.Key, Cmd: e.Cmd, At: e.ChangedAt}
+		switch {
+		case e.Type == KeyEventDel && e.Cmd == Expired:
+			event.Type = hooks.Expire
+		case e.Type == KeyEventDel && e.Cmd == Evict:
+			event.Type = hooks.Evict
+		default:
+			event.Deleted = e.Type == KeyEventDel
+		}
+		hooks.Publish(event)
+	}
+}
@@ -0,0 +1,149 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/hooks"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyEventSubscription(t *testing.T) {
+	store := NewStore(nil, nil)
+
+	var events []KeyEvent
+	unsubscribe := store.Subscribe(KeyEventFunc(func(e KeyEvent) {
+		events = append(events, e)
+	}))
+
+	store.Put("a", store.NewObj("1", -1, object.ObjTypeString))
+	store.Rename("a", "b")
+	store.Del("b", WithDelCmd(Evict))
+
+	assert.Len(t, events, 4)
+	expected := []KeyEvent{
+		{Type: KeyEventPut, Key: "a", Cmd: Set},
+		{Type: KeyEventPut, Key: "b", Cmd: Set},
+		{Type: KeyEventDel, Key: "a", Cmd: Rename},
+		{Type: KeyEventDel, Key: "b", Cmd: Evict},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.Type, events[i].Type)
+		assert.Equal(t, e.Key, events[i].Key)
+		assert.Equal(t, e.Cmd, events[i].Cmd)
+	}
+
+	unsubscribe()
+	store.Put("c", store.NewObj("1", -1, object.ObjTypeString))
+	assert.Len(t, events, 4)
+}
+
+func TestExpiredKeyEvents(t *testing.T) {
+	store := NewStore(nil, nil)
+
+	var events []KeyEvent
+	store.Subscribe(KeyEventFunc(func(e KeyEvent) {
+		events = append(events, e)
+	}))
+	var expired []string
+	store.SubscribeExpired(func(e KeyEvent) {
+		expired = append(expired, e.Key)
+	})
+
+	// Keys are put with an expiry already elapsed
+	for _, k := range []string{"get", "put", "del", "rename", "active"} {
+		store.Put(k, store.NewObj("v", 0, object.ObjTypeString))
+	}
+	events = events[:0]
+
+	assert.Nil(t, store.Get("get"))
+	store.Put("put", store.NewObj("w", -1, object.ObjTypeString), WithKeepTTL(true))
+	assert.False(t, store.Del("del"))
+	assert.False(t, store.Rename("rename", "renamed"))
+	DeleteExpiredKeys(store)
+
+	expected := []KeyEvent{
+		{Type: KeyEventDel, Key: "get", Cmd: Expired},
+		{Type: KeyEventDel, Key: "put", Cmd: Expired},
+		{Type: KeyEventPut, Key: "put", Cmd: Set},
+		{Type: KeyEventDel, Key: "del", Cmd: Expired},
+		{Type: KeyEventDel, Key: "rename", Cmd: Expired},
+		{Type: KeyEventDel, Key: "active", Cmd: Expired},
+	}
+	assert.Len(t, events, len(expected))
+	for i, e := range expected {
+		assert.Equal(t, e.Type, events[i].Type)
+		assert.Equal(t, e.Key, events[i].Key)
+		assert.Equal(t, e.Cmd, events[i].Cmd)
+	}
+	assert.Equal(t, []string{"get", "put", "del", "rename", "active"}, expired)
+
+	// The TTL of the expired value is not kept by the write that follows its expiry
+	_, ok := GetExpiry(store.Get("put"), store)
+	assert.False(t, ok)
+	assert.Equal(t, 1, store.GetKeyCount())
+}
+
+func TestCmdWatchForwarder(t *testing.T) {
+	cmdWatchChan := make(chan CmdWatchEvent, 1)
+	store := NewStore(cmdWatchChan, nil)
+
+	store.Put("k", store.NewObj("v", -1, object.ObjTypeString), WithPutCmd(ZAdd))
+	e := <-cmdWatchChan
+	assert.Equal(t, ZAdd, e.Cmd)
+	assert.Equal(t, "k", e.AffectedKey)
+}
+
+func TestHooks(t *testing.T) {
+	events := make(chan hooks.Event, 10)
+	unregister := hooks.Register(hooks.Funcs{
+		Write:  func(e hooks.Event) { events <- e },
+		Expire: func(e hooks.Event) { events <- e },
+		Evict:  func(e hooks.Event) { events <- e },
+	})
+	defer unregister()
+
+	store := NewStore(nil, nil)
+	store.Put("a", store.NewObj("1", -1, object.ObjTypeString))
+	store.Del("a")
+	store.Put("b", store.NewObj("1", 0, object.ObjTypeString))
+	time.Sleep(time.Millisecond)
+	store.Get("b")
+	store.Put("c", store.NewObj("1", -1, object.ObjTypeString))
+	store.Del("c", WithDelCmd(Evict))
+
+	expected := []hooks.Event{
+		{Type: hooks.Write, Key: "a", Cmd: Set},
+		{Type: hooks.Write, Key: "a", Cmd: Del, Deleted: true},
+		{Type: hooks.Write, Key: "b", Cmd: Set},
+		{Type: hooks.Expire, Key: "b", Cmd: Expired},
+		{Type: hooks.Write, Key: "c", Cmd: Set},
+		{Type: hooks.Evict, Key: "c", Cmd: Evict},
+	}
+	for _, e := range expected {
+		select {
+		case got := <-events:
+			got.At = time.Time{}
+			assert.Equal(t, e, got)
+		case <-time.After(time.Second):
+			t.Fatalf("the hooks were not called for %v", e)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"github.com/dicedb/dice/internal/common"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/stats"
+)
+
+// flushedKeyspace holds the tables of a flushed store until their memory is released.
+type flushedKeyspace struct {
+	store   common.ITable[string, *object.Obj]
+	expires common.ITable[*object.Obj, uint64]
+	keys    int
+}
+
+// The keyspaces flushed asynchronously by every shard are released by a single goroutine,
+// so that concurrent flushes of all the shards are released by the same collection.
+var (
+	lazyFreeOnce sync.Once
+	lazyFreeChan chan flushedKeyspace
+)
+
+// Flush removes every key of the store by swapping its tables with empty ones. The old tables are
+// left to the collector, or released in the background when async is true so that the shard goes
+// on serving requests while a large keyspace is released. A synchronous flush does not force a
+// collection, as every shard flushing would stop the world once for each of them.
+func (store *Store) Flush(async bool) {
+	if !async {
+		store.ResetStore()
+		return
+	}
+
+	flushed := flushedKeyspace{store: store.store, expires: store.expires, keys: store.numKeys}
+	store.ResetStore()
+	lazyFree(flushed)
+}
+
+func lazyFree(flushed flushedKeyspace) {
+	lazyFreeOnce.Do(func() {
+		lazyFreeChan = make(chan flushedKeyspace, 128)
+		go lazyFreeLoop()
+	})
+
+	stats.LazyFreeQueued(flushed.keys)
+	lazyFreeChan <- flushed
+}
+
+func lazyFreeLoop() {
+	for flushed := range lazyFreeChan {
+		keys := flushed.keys
+		// The keyspaces queued meanwhile are released by the same collection
+		for queued := len(lazyFreeChan); queued > 0; queued-- {
+			keys += (<-lazyFreeChan).keys
+		}
+
+		debug.FreeOSMemory()
+		stats.LazyFreed(keys)
+	}
+}
@@ -0,0 +1,139 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"encoding/binary"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/dicedb/dice/internal/object"
+)
+
+// ReloadDigest reports the keys of a type reloaded by Reload. Before and After are the digests of the
+// keys, their values and their expiries before and after the reload, they are equal when the dataset
+// round-trips through the codec unchanged. The digests are sums, so that the digests of the shards add up.
+type ReloadDigest struct {
+	Keys   int
+	Before uint64
+	After  uint64
+	// Unsupported is the number of keys the codec can't encode, they are kept in place
+	Unsupported int
+	// Failed is the number of keys whose encoding can't be decoded back, they are kept in place
+	Failed int
+}
+
+// reloadedKey is a key of the store encoded by Reload
+type reloadedKey struct {
+	key     string
+	obj     *object.Obj
+	payload []byte
+	exp     uint64
+	hasExp  bool
+}
+
+// Reload encodes every key of the store with the codec, and replaces the dataset with the keys decoded
+// back, as if it was saved and loaded again. The keys already expired are dropped. It returns the digest
+// of the keys of each type. Reloading is not a change of the keys, it does not notify any key event.
+func (store *Store) Reload(codec Codec) map[object.ObjectType]*ReloadDigest {
+	digests := make(map[object.ObjectType]*ReloadDigest)
+	digestOf := func(t object.ObjectType) *ReloadDigest {
+		d, ok := digests[t]
+		if !ok {
+			d = &ReloadDigest{}
+			digests[t] = d
+		}
+		return d
+	}
+
+	encoded := make([]reloadedKey, 0, store.store.Len())
+	kept := make([]reloadedKey, 0)
+	store.store.All(func(k string, obj *object.Obj) bool {
+		if hasExpired(obj, store) {
+			return true
+		}
+		exp, hasExp := store.expires.Get(obj)
+		d := digestOf(obj.Type)
+		d.Keys++
+
+		payload, err := codec.Marshal(obj)
+		if err != nil {
+			d.Unsupported++
+			kept = append(kept, reloadedKey{key: k, obj: obj, exp: exp, hasExp: hasExp})
+			return true
+		}
+		d.Before += reloadDigest(k, payload, exp)
+		encoded = append(encoded, reloadedKey{key: k, obj: obj, payload: payload, exp: exp, hasExp: hasExp})
+		return true
+	})
+
+	fieldExpires := store.fieldExpires
+	store.numKeys = 0
+	store.store = store.newTable()
+	store.expires = NewExpireMap()
+	store.expiryWheel = newTimerWheel()
+	store.fieldExpires = make(map[*object.Obj]map[string]uint64)
+
+	for _, rk := range encoded {
+		obj, err := codec.Unmarshal(rk.payload)
+		if err != nil {
+			digestOf(rk.obj.Type).Failed++
+			kept = append(kept, rk)
+			continue
+		}
+		obj.LastAccessedAt = rk.obj.LastAccessedAt
+		obj.Version = rk.obj.Version
+		store.restoreKey(rk.key, obj, rk.exp, rk.hasExp)
+
+		// The key is encoded again to check the decoded value against the value reloaded
+		payload, err := codec.Marshal(obj)
+		if err != nil {
+			digestOf(obj.Type).Failed++
+			continue
+		}
+		digestOf(obj.Type).After += reloadDigest(rk.key, payload, rk.exp)
+	}
+
+	for _, rk := range kept {
+		store.restoreKey(rk.key, rk.obj, rk.exp, rk.hasExp)
+		if fields, ok := fieldExpires[rk.obj]; ok {
+			store.fieldExpires[rk.obj] = fields
+		}
+	}
+	return digests
+}
+
+// restoreKey puts a key reloaded in the emptied store, with its expiry
+func (store *Store) restoreKey(k string, obj *object.Obj, exp uint64, hasExp bool) {
+	store.store.Put(k, obj)
+	store.numKeys++
+	if hasExp {
+		store.expires.Put(obj, exp)
+		store.scheduleExpiry(k, exp)
+	}
+}
+
+// reloadDigest is the digest of a key, its encoded value and its expiry
+func reloadDigest(k string, payload []byte, exp uint64) uint64 {
+	h := xxhash.New()
+	_, _ = h.WriteString(k)
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(payload)
+	var expBuf [8]byte
+	binary.BigEndian.PutUint64(expBuf[:], exp)
+	_, _ = h.Write(expBuf[:])
+	return h.Sum64()
+}
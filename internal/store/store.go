@@ -17,13 +17,20 @@
 package store
 
 import (
+	"context"
 	"path"
+	"time"
 
 	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/hooks"
 
 	"github.com/dicedb/dice/internal/common"
+	"github.com/dicedb/dice/internal/latency"
+	"github.com/dicedb/dice/internal/metrics"
 	"github.com/dicedb/dice/internal/object"
 	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/dicedb/dice/internal/slowlog"
+	"github.com/dicedb/dice/internal/stats"
 )
 
 func NewStoreRegMap() common.ITable[string, *object.Obj] {
@@ -38,7 +45,11 @@ func NewExpireRegMap() common.ITable[*object.Obj, uint64] {
 	}
 }
 
+// NewStoreMap returns an empty table of keys, interning the key prefixes when memory.key_prefix_interning is set.
 func NewStoreMap() common.ITable[string, *object.Obj] {
+	if config.DiceConfig().Memory.KeyPrefixInterning {
+		return newInternedTable()
+	}
 	return NewStoreRegMap()
 }
 
@@ -61,36 +72,68 @@ type QueryWatchEvent struct {
 }
 
 type CmdWatchEvent struct {
-	Cmd         string
-	AffectedKey string
+	Cmd          string
+	AffectedKey  string
+	AffectedKeys []string  // AffectedKeys are the keys changed by a batch of writes, in place of AffectedKey
+	ChangedAt    time.Time // time at which the key was changed, to measure the latency of the pushes
 }
 
 type Store struct {
 	store            common.ITable[string, *object.Obj]
 	expires          common.ITable[*object.Obj, uint64] // Does not need to be thread-safe as it is only accessed by a single thread.
+	expiryWheel      *timerWheel                        // expiryWheel schedules the active expiry of the keys with a TTL
+	fieldExpires     map[*object.Obj]map[string]uint64  // fieldExpires is the expiry of the fields of the hashes, in unix milliseconds
 	numKeys          int
-	cmdWatchChan     chan CmdWatchEvent
 	evictionStrategy EvictionStrategy
+	slowLog          *slowlog.Log
+	tier             Tier         // tier holds the values spilled out of memory, nil when every value is kept in memory
+	codec            Codec        // codec encodes the values spilled to the tier
+	hotKeys          int          // hotKeys is the number of values kept in memory when the store has a tier
+	fencingToken     int64        // fencingToken is the last fencing token issued by LOCK
+	trash            trash        // trash holds the keys deleted by DEL while trash.enabled is set
+	history          history      // history holds the previous values of the keys while history.enabled is set
+	defrag           defragCursor // defrag is where the active defragmentation resumes in the expiry wheel
+	versionClock     uint64       // versionClock is the last version given to a key
+
+	subscriptions      []keyEventSubscription // subscriptions are the subscribers of the key events, in subscription order
+	lastSubscriptionID uint64
+	batchDepth         int        // batchDepth is the number of batches open, the key events are held back while one is
+	pendingEvents      []KeyEvent // pendingEvents are the key events held back until the open batch is committed
+
+	ctx context.Context // ctx is the context of the command being executed, nil between the commands
 }
 
 func NewStore(cmdWatchChan chan CmdWatchEvent, evictionStrategy EvictionStrategy) *Store {
 	store := &Store{
-		store:            NewStoreRegMap(),
+		store:            NewStoreMap(),
 		expires:          NewExpireRegMap(),
-		cmdWatchChan:     cmdWatchChan,
+		expiryWheel:      newTimerWheel(),
+		fieldExpires:     make(map[*object.Obj]map[string]uint64),
 		evictionStrategy: evictionStrategy,
+		slowLog:          slowlog.New(),
 	}
 	if evictionStrategy == nil {
 		store.evictionStrategy = NewDefaultEviction()
 	}
 
+	if cmdWatchChan != nil {
+		store.Subscribe(cmdWatchForwarder(cmdWatchChan))
+	}
+	if hooks.Registered() {
+		store.Subscribe(hooksForwarder())
+	}
+
 	return store
 }
 
 func ResetStore(store *Store) *Store {
 	store.numKeys = 0
-	store.store = NewStoreMap()
+	store.store = store.newTable()
 	store.expires = NewExpireMap()
+	store.expiryWheel = newTimerWheel()
+	store.fieldExpires = make(map[*object.Obj]map[string]uint64)
+	store.trash = trash{}
+	store.history = history{}
 
 	return store
 }
@@ -109,8 +152,12 @@ func (store *Store) NewObj(value interface{}, expDurationMs int64, oType object.
 
 func (store *Store) ResetStore() {
 	store.numKeys = 0
-	store.store = NewStoreMap()
+	store.store = store.newTable()
 	store.expires = NewExpireMap()
+	store.expiryWheel = newTimerWheel()
+	store.fieldExpires = make(map[*object.Obj]map[string]uint64)
+	store.trash = trash{}
+	store.history = history{}
 }
 
 func (store *Store) Put(k string, obj *object.Obj, opts ...PutOption) {
@@ -144,15 +191,26 @@ func (store *Store) putHelper(k string, obj *object.Obj, opts ...PutOption) {
 
 	obj.LastAccessedAt = getCurrentClock()
 	currentObject, ok := store.store.Get(k)
+	if ok && hasExpired(currentObject, store) {
+		// The expiry of the previous value is reported before the write, and its TTL is not kept
+		store.expireKey(k, currentObject)
+		ok = false
+	}
+	var prevExp uint64
 	if ok {
-		v, ok1 := store.expires.Get(currentObject)
-		if ok1 && options.KeepTTL && v > 0 {
-			v1, ok2 := store.expires.Get(currentObject)
-			if ok2 {
-				store.expires.Put(obj, v1)
+		prevExp, _ = store.expires.Get(currentObject)
+		// Putting the object already stored at the key back, once modified in place, keeps its expiries
+		if currentObject != obj {
+			v, ok1 := store.expires.Get(currentObject)
+			if ok1 && options.KeepTTL && v > 0 {
+				v1, ok2 := store.expires.Get(currentObject)
+				if ok2 {
+					store.expires.Put(obj, v1)
+				}
 			}
+			store.expires.Delete(currentObject)
+			delete(store.fieldExpires, currentObject)
 		}
-		store.expires.Delete(currentObject)
 	} else {
 		// TODO: Inform all the io-threads and shards about the eviction.
 		// TODO: Start the eviction only when all the io-thread and shards have acknowledged the eviction.
@@ -163,12 +221,19 @@ func (store *Store) putHelper(k string, obj *object.Obj, opts ...PutOption) {
 		store.numKeys++
 	}
 
+	store.bumpVersion(obj)
 	store.store.Put(k, obj)
 	store.evictionStrategy.OnAccess(k, obj, AccessSet)
+	// The expiry kept by the value put is already scheduled
+	if exp, hasExp := store.expires.Get(obj); hasExp && exp != prevExp {
+		store.scheduleExpiry(k, exp)
+	}
 
-	if store.cmdWatchChan != nil {
-		store.notifyWatchManager(options.PutCmd, k)
+	if !ok || currentObject == obj {
+		currentObject = nil
 	}
+	store.recordRevision(k, currentObject, obj)
+	store.notify(KeyEventPut, options.PutCmd, k)
 }
 
 // getHelper is a helper function to get the object from the store. It also updates the last accessed time if touch is true.
@@ -177,7 +242,9 @@ func (store *Store) getHelper(k string, touch bool) *object.Obj {
 	obj, _ = store.store.Get(k)
 	if obj != nil {
 		if hasExpired(obj, store) {
-			store.deleteKey(k, obj)
+			store.expireKey(k, obj)
+			obj = nil
+		} else if store.expireFields(k, obj) {
 			obj = nil
 		} else if touch {
 			obj.LastAccessedAt = getCurrentClock()
@@ -193,7 +260,9 @@ func (store *Store) GetAll(keys []string) []*object.Obj {
 		v, _ := store.store.Get(k)
 		if v != nil {
 			if hasExpired(v, store) {
-				store.deleteKey(k, v)
+				store.expireKey(k, v)
+				response = append(response, nil)
+			} else if store.expireFields(k, v) {
 				response = append(response, nil)
 			} else {
 				v.LastAccessedAt = getCurrentClock()
@@ -206,25 +275,57 @@ func (store *Store) GetAll(keys []string) []*object.Obj {
 	return response
 }
 
+// Del deletes the key and returns true if it existed. A key that has expired is reported
+// as expired rather than deleted, and false is returned.
 func (store *Store) Del(k string, opts ...DelOption) bool {
 	v, ok := store.store.Get(k)
-	if ok {
-		return store.deleteKey(k, v, opts...)
+	if !ok {
+		return false
 	}
-	return false
+	if hasExpired(v, store) {
+		store.expireKey(k, v)
+		return false
+	}
+	return store.deleteKey(k, v, opts...)
 }
 
 func (store *Store) DelByPtr(ptr string, opts ...DelOption) bool {
 	return store.delByPtr(ptr, opts...)
 }
 
+// SetContext sets the context of the command being executed, nil once it is done.
+func (store *Store) SetContext(ctx context.Context) {
+	store.ctx = ctx
+}
+
+// Context returns the context of the command being executed. Commands that may run for long check it
+// and stop early once it is cancelled.
+func (store *Store) Context() context.Context {
+	if store.ctx == nil {
+		return context.Background()
+	}
+	return store.ctx
+}
+
+// keysPerCancellationCheck is the number of keys iterated between two checks of the context of the command.
+const keysPerCancellationCheck = 1024
+
+// Keys returns the keys matching the pattern p. The iteration stops with the error of the context of the
+// command once it is cancelled.
 func (store *Store) Keys(p string) ([]string, error) {
 	var keys []string
 	var err error
 
 	keys = make([]string, 0, store.store.Len())
 
-	store.store.All(func(k string, _ *object.Obj) bool {
+	ctx := store.Context()
+	iterated := 0
+	store.allKeys(func(k string, _ *object.Obj) bool {
+		if iterated++; iterated%keysPerCancellationCheck == 0 {
+			if err = ctx.Err(); err != nil {
+				return false
+			}
+		}
 		if found, e := path.Match(p, k); e != nil {
 			err = e
 			// stop iteration if any error
@@ -244,6 +345,33 @@ func (store *Store) GetDBSize() uint64 {
 	return uint64(store.store.Len())
 }
 
+// NextFencingToken returns a fencing token greater than every token the store issued. The tokens are seeded
+// from the clock, in microseconds, so that they keep increasing across restarts of the server.
+func (store *Store) NextFencingToken() int64 {
+	store.fencingToken = max(store.fencingToken+1, utils.GetCurrentTime().UnixMicro())
+	return store.fencingToken
+}
+
+// SlowLog returns the slow log of the shard owning the store
+func (store *Store) SlowLog() *slowlog.Log {
+	return store.slowLog
+}
+
+// InterningStats returns the stats of the interning of the key prefixes, zero unless memory.key_prefix_interning is set
+func (store *Store) InterningStats() InterningStats {
+	return interningStats(store.store)
+}
+
+// Fragmentation returns the memory held by the tables of the store for entries they no longer have
+func (store *Store) Fragmentation() FragmentationStats {
+	return fragmentation(store)
+}
+
+// GetExpiresCount returns number of keys with an expiry set
+func (store *Store) GetExpiresCount() uint64 {
+	return uint64(store.expires.Len())
+}
+
 // Rename function to implement RENAME functionality using existing helpers
 func (store *Store) Rename(sourceKey, destKey string) bool {
 	// If source and destination are the same, do nothing and return true
@@ -252,10 +380,11 @@ func (store *Store) Rename(sourceKey, destKey string) bool {
 	}
 
 	sourceObj, _ := store.store.Get(sourceKey)
-	if sourceObj == nil || hasExpired(sourceObj, store) {
-		if sourceObj != nil {
-			store.deleteKey(sourceKey, sourceObj, WithDelCmd(Rename))
-		}
+	if sourceObj == nil {
+		return false
+	}
+	if hasExpired(sourceObj, store) {
+		store.expireKey(sourceKey, sourceObj)
 		return false
 	}
 
@@ -266,36 +395,53 @@ func (store *Store) Rename(sourceKey, destKey string) bool {
 	store.store.Delete(sourceKey)
 	store.numKeys--
 
-	if store.cmdWatchChan != nil {
-		store.notifyWatchManager(Rename, sourceKey)
-	}
+	store.recordRevision(sourceKey, sourceObj, nil)
+	store.notify(KeyEventDel, Rename, sourceKey)
 
 	return true
 }
 
 func (store *Store) Get(k string) *object.Obj {
-	return store.getHelper(k, true)
+	obj := store.getHelper(k, true)
+	stats.KeyspaceLookup(obj != nil)
+	return obj
 }
 
 func (store *Store) GetDel(k string, opts ...DelOption) *object.Obj {
 	var v *object.Obj
 	v, _ = store.store.Get(k)
-	if v != nil {
-		expired := hasExpired(v, store)
-		store.deleteKey(k, v, opts...)
-		if expired {
-			v = nil
-		}
+	if v == nil {
+		return nil
+	}
+	if hasExpired(v, store) {
+		store.expireKey(k, v)
+		return nil
 	}
+	store.deleteKey(k, v, opts...)
 	return v
 }
 
 // SetExpiry sets the expiry time for an object.
 // This method is not thread-safe. It should be called within a lock.
+// The key of the object is scheduled for active expiry once the object is put, the expiry of an
+// object already stored is set with SetKeyExpiry.
 func (store *Store) SetExpiry(obj *object.Obj, expDurationMs int64) {
 	store.expires.Put(obj, uint64(utils.GetCurrentTime().UnixMilli())+uint64(expDurationMs))
 }
 
+// SetKeyExpiry sets the expiry time of the object stored at the key k, and schedules the key for
+// active expiry.
+func (store *Store) SetKeyExpiry(k string, obj *object.Obj, expDurationMs int64) {
+	store.SetExpiry(obj, expDurationMs)
+	exp, _ := store.expires.Get(obj)
+	store.scheduleExpiry(k, exp)
+}
+
+// scheduleExpiry schedules the active expiry of the key k at the unix time exp, in milliseconds.
+func (store *Store) scheduleExpiry(k string, exp uint64) {
+	store.expiryWheel.Add(k, exp, uint64(utils.GetCurrentTime().UnixMilli()))
+}
+
 // SetUnixTimeExpiry sets the expiry time for an object.
 // This method is not thread-safe. It should be called within a lock.
 func (store *Store) SetUnixTimeExpiry(obj *object.Obj, exUnixTimeSec int64) {
@@ -303,6 +449,13 @@ func (store *Store) SetUnixTimeExpiry(obj *object.Obj, exUnixTimeSec int64) {
 	store.expires.Put(obj, uint64(exUnixTimeSec*1000))
 }
 
+// setKeyUnixTimeExpiry sets the expiry time of the object stored at the key k, and schedules the key
+// for active expiry.
+func (store *Store) setKeyUnixTimeExpiry(k string, obj *object.Obj, exUnixTimeSec int64) {
+	store.SetUnixTimeExpiry(obj, exUnixTimeSec)
+	store.scheduleExpiry(k, uint64(exUnixTimeSec*1000))
+}
+
 func (store *Store) deleteKey(k string, obj *object.Obj, opts ...DelOption) bool {
 	options := getDefaultDelOptions()
 
@@ -313,13 +466,13 @@ func (store *Store) deleteKey(k string, obj *object.Obj, opts ...DelOption) bool
 	if obj != nil {
 		store.store.Delete(k)
 		store.expires.Delete(obj)
+		delete(store.fieldExpires, obj)
 		store.numKeys--
 
 		store.evictionStrategy.OnAccess(k, obj, AccessDel)
 
-		if store.cmdWatchChan != nil {
-			store.notifyWatchManager(options.DelCmd, k)
-		}
+		store.recordRevision(k, obj, nil)
+		store.notify(KeyEventDel, options.DelCmd, k)
 
 		return true
 	}
@@ -330,13 +483,21 @@ func (store *Store) deleteKey(k string, obj *object.Obj, opts ...DelOption) bool
 func (store *Store) delByPtr(ptr string, opts ...DelOption) bool {
 	if obj, ok := store.store.Get(ptr); ok {
 		key := ptr
+		if hasExpired(obj, store) {
+			store.expireKey(key, obj)
+			return false
+		}
 		return store.deleteKey(key, obj, opts...)
 	}
 	return false
 }
 
-func (store *Store) notifyWatchManager(cmd, affectedKey string) {
-	store.cmdWatchChan <- CmdWatchEvent{cmd, affectedKey}
+// expireKey deletes the key whose TTL elapsed and reports it with an EXPIRED key event. Every expiry,
+// be it active or lazy on access, goes through it, so that the event of an expiry is always reported
+// before the events of the writes that follow it on the key.
+func (store *Store) expireKey(k string, obj *object.Obj) {
+	store.deleteKey(k, obj, WithDelCmd(Expired))
+	metrics.KeysExpired(1)
 }
 
 func (store *Store) GetStore() common.ITable[string, *object.Obj] {
@@ -344,6 +505,9 @@ func (store *Store) GetStore() common.ITable[string, *object.Obj] {
 }
 
 func (store *Store) evict(evictCount int) bool {
+	defer latency.Since(latency.EventEviction, time.Now())
+	keyCount := store.GetKeyCount()
 	store.evictionStrategy.EvictVictims(store, evictCount)
+	metrics.KeysEvicted(keyCount - store.GetKeyCount())
 	return true
 }
@@ -0,0 +1,249 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"log/slog"
+
+	"github.com/dicedb/dice/internal/common"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/stats"
+)
+
+// Tier stores the values spilled out of memory, so that the dataset of a shard can outgrow its memory.
+// A tier is only used by the goroutine of its shard.
+type Tier interface {
+	Put(key string, value []byte) error
+	// Get returns the value of the key, it is an error for the key not to be stored
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+	// Reset removes every value, the store is flushed
+	Reset() error
+	Close() error
+}
+
+// Codec encodes the values spilled to a tier. The store does not know the types of the values, the codec
+// is provided by the eval layer.
+type Codec interface {
+	Marshal(obj *object.Obj) ([]byte, error)
+	Unmarshal(data []byte) (*object.Obj, error)
+}
+
+// UseTier makes the store keep at most hotKeys values in memory, the values of the keys accessed the least
+// recently are spilled to the tier by SpillColdValues. The keys and their objects stay in memory, only the
+// values are spilled, so that the expiries and the key count are unchanged. It must be called while the
+// store is empty.
+func (store *Store) UseTier(tier Tier, codec Codec, hotKeys int) {
+	store.tier = tier
+	store.codec = codec
+	store.hotKeys = hotKeys
+	store.store = store.newTable()
+}
+
+// Tier returns the tier of the store, nil when every value is kept in memory
+func (store *Store) Tier() Tier {
+	return store.tier
+}
+
+// newTable returns an empty table of keys, backed by the tier of the store if any
+func (store *Store) newTable() common.ITable[string, *object.Obj] {
+	if store.tier == nil {
+		return NewStoreMap()
+	}
+
+	if err := store.tier.Reset(); err != nil {
+		slog.Error("could not reset the disk tier", slog.Any("error", err))
+	}
+	return &tieredTable{
+		keys:   NewStoreMap(),
+		cold:   make(map[string]struct{}),
+		pinned: make(map[string]struct{}),
+		tier:   store.tier,
+		codec:  store.codec,
+	}
+}
+
+// allKeys iterates over the keys and their objects without loading the cold values, for the callers that
+// only look at the keys, the expiries or the access times
+func (store *Store) allKeys(f func(key string, obj *object.Obj) bool) {
+	if table, ok := store.store.(*tieredTable); ok {
+		table.keys.All(f)
+		return
+	}
+	store.store.All(f)
+}
+
+// sampleKeys calls f with up to count keys of the store, the keys following a random one in the table: the
+// iteration of the keys starts at a random position. The cold values are not loaded.
+func (store *Store) sampleKeys(count int, f func(key string, obj *object.Obj)) {
+	sampled := 0
+	store.allKeys(func(key string, obj *object.Obj) bool {
+		f(key, obj)
+		sampled++
+		return sampled < count
+	})
+}
+
+// peekKey returns the object of the key, if it exists, without loading its value from the tier nor expiring it.
+func (store *Store) peekKey(key string) (*object.Obj, bool) {
+	if table, ok := store.store.(*tieredTable); ok {
+		return table.keys.Get(key)
+	}
+	return store.store.Get(key)
+}
+
+// SpillColdValues spills the values of the keys accessed the least recently to the tier, until no more than
+// the configured number of values is kept in memory. It is called between the commands, as the commands
+// hold the objects they access.
+func SpillColdValues(store *Store) {
+	table, ok := store.store.(*tieredTable)
+	if !ok {
+		return
+	}
+
+	if excess := table.loaded() - store.hotKeys; excess > 0 {
+		table.spill(excess)
+	}
+}
+
+// tieredTable is a table whose values are either in memory or in a tier. Every key is kept in memory with
+// its object, the value of a cold object is nil until it is loaded back on access.
+type tieredTable struct {
+	keys   common.ITable[string, *object.Obj]
+	cold   map[string]struct{} // cold are the keys whose value is in the tier
+	pinned map[string]struct{} // pinned are the keys whose value the codec can't encode, kept in memory
+	tier   Tier
+	codec  Codec
+}
+
+func (t *tieredTable) Put(key string, obj *object.Obj) {
+	if _, ok := t.cold[key]; ok {
+		t.drop(key)
+	}
+	delete(t.pinned, key)
+	t.keys.Put(key, obj)
+}
+
+func (t *tieredTable) Get(key string) (*object.Obj, bool) {
+	obj, ok := t.keys.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if _, cold := t.cold[key]; cold && !t.load(key, obj) {
+		return nil, false
+	}
+	return obj, true
+}
+
+func (t *tieredTable) Delete(key string) {
+	if _, ok := t.cold[key]; ok {
+		t.drop(key)
+	}
+	delete(t.pinned, key)
+	t.keys.Delete(key)
+}
+
+func (t *tieredTable) Len() int {
+	return t.keys.Len()
+}
+
+// All loads the cold values of the keys iterated, they are spilled again by the next SpillColdValues
+func (t *tieredTable) All(f func(key string, obj *object.Obj) bool) {
+	t.keys.All(func(key string, obj *object.Obj) bool {
+		if _, cold := t.cold[key]; cold && !t.load(key, obj) {
+			return true
+		}
+		return f(key, obj)
+	})
+}
+
+// loaded returns the number of values in memory
+func (t *tieredTable) loaded() int {
+	return t.keys.Len() - len(t.cold)
+}
+
+// load reads the value of a cold key back into its object
+func (t *tieredTable) load(key string, obj *object.Obj) bool {
+	data, err := t.tier.Get(key)
+	if err == nil {
+		var loaded *object.Obj
+		if loaded, err = t.codec.Unmarshal(data); err == nil {
+			obj.Value = loaded.Value
+		}
+	}
+	if err != nil {
+		slog.Error("could not load a value from the disk tier", slog.String("key", key), slog.Any("error", err))
+		return false
+	}
+
+	t.drop(key)
+	stats.TierLoaded()
+	return true
+}
+
+// drop removes the value of a cold key from the tier
+func (t *tieredTable) drop(key string) {
+	delete(t.cold, key)
+	if err := t.tier.Delete(key); err != nil {
+		slog.Warn("could not delete a value from the disk tier", slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+// spill moves the values of the count keys accessed the least recently to the tier
+func (t *tieredTable) spill(count int) {
+	now := getCurrentClock()
+	h := make(evictionItemHeap, 0, count)
+	t.keys.All(func(key string, obj *object.Obj) bool {
+		if _, cold := t.cold[key]; cold {
+			return true
+		}
+		if _, pinned := t.pinned[key]; pinned {
+			return true
+		}
+
+		item := evictionItem{key: key, idle: lruIdle(obj.LastAccessedAt, now)}
+		if h.Len() < count {
+			h.push(item)
+		} else if item.idle > h[0].idle {
+			h.pop()
+			h.push(item)
+		}
+		return true
+	})
+
+	spilled := 0
+	for h.Len() > 0 {
+		key := h.pop().key
+		obj, _ := t.keys.Get(key)
+
+		data, err := t.codec.Marshal(obj)
+		if err != nil {
+			// The type can't be spilled, the value stays in memory until the key is written again
+			t.pinned[key] = struct{}{}
+			continue
+		}
+		if err := t.tier.Put(key, data); err != nil {
+			slog.Error("could not spill a value to the disk tier", slog.String("key", key), slog.Any("error", err))
+			break
+		}
+
+		obj.Value = nil
+		t.cold[key] = struct{}{}
+		spilled++
+	}
+	stats.TierSpilled(spilled)
+}
@@ -0,0 +1,126 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCodec encodes the string values, the other types can't be spilled
+type testCodec struct{}
+
+func (testCodec) Marshal(obj *object.Obj) ([]byte, error) {
+	if s, ok := obj.Value.(string); ok {
+		return []byte(s), nil
+	}
+	return nil, errors.New("unsupported type")
+}
+
+func (testCodec) Unmarshal(data []byte) (*object.Obj, error) {
+	return &object.Obj{Type: object.ObjTypeString, Value: string(data)}, nil
+}
+
+func TestDiskTier(t *testing.T) {
+	tier, err := OpenDiskTier(filepath.Join(t.TempDir(), "tier"))
+	require.NoError(t, err)
+	defer tier.Close()
+
+	require.NoError(t, tier.Put("a", []byte("1")))
+	require.NoError(t, tier.Put("b", []byte("22")))
+	require.NoError(t, tier.Put("a", []byte("333")))
+	require.NoError(t, tier.Delete("b"))
+
+	value, err := tier.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("333"), value)
+	_, err = tier.Get("b")
+	assert.Error(t, err)
+
+	// Compacting keeps the live values only
+	size := tier.Size()
+	require.NoError(t, tier.compact())
+	assert.Less(t, tier.Size(), size)
+	value, err = tier.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("333"), value)
+
+	require.NoError(t, tier.Reset())
+	assert.Zero(t, tier.Len())
+	assert.Zero(t, tier.Size())
+}
+
+func TestTieredStore(t *testing.T) {
+	tier, err := OpenDiskTier(filepath.Join(t.TempDir(), "tier"))
+	require.NoError(t, err)
+	defer tier.Close()
+
+	store := NewStore(nil, nil)
+	store.UseTier(tier, testCodec{}, 2)
+
+	objs := make(map[string]*object.Obj)
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("k%d", i)
+		objs[key] = store.NewObj(fmt.Sprintf("v%d", i), -1, object.ObjTypeString)
+		objs[key].LastAccessedAt = uint32(i)
+		store.store.Put(key, objs[key])
+		store.numKeys++
+	}
+	store.SetExpiry(objs["k0"], 60000)
+	pinned := store.NewObj(map[string]string{}, -1, object.ObjTypeHashMap)
+	pinned.LastAccessedAt = 0
+	store.store.Put("hash", pinned)
+	store.numKeys++
+
+	// The least recently accessed values are spilled, but the one the codec can't encode
+	SpillColdValues(store)
+	assert.Equal(t, 3, tier.Len())
+	assert.Nil(t, objs["k0"].Value)
+	assert.Equal(t, "v3", objs["k3"].Value)
+	assert.NotNil(t, pinned.Value)
+
+	// The keys, the expiries and the key count do not depend on where the values are
+	keys, err := store.Keys("k*")
+	require.NoError(t, err)
+	assert.Len(t, keys, 5)
+	assert.Equal(t, 6, store.GetKeyCount())
+	_, ok := GetExpiry(objs["k0"], store)
+	assert.True(t, ok)
+
+	// A cold value is loaded back into its object on access
+	assert.Same(t, objs["k0"], store.Get("k0"))
+	assert.Equal(t, "v0", objs["k0"].Value)
+	assert.Equal(t, 2, tier.Len())
+
+	// Writing or deleting a cold key drops its value from the tier
+	store.Put("k1", store.NewObj("new", -1, object.ObjTypeString))
+	assert.True(t, store.Del("k2"))
+	assert.Zero(t, tier.Len())
+	assert.Equal(t, "new", store.Get("k1").Value)
+
+	SpillColdValues(store)
+	assert.NotZero(t, tier.Len())
+	store.ResetStore()
+	assert.Zero(t, tier.Len())
+	assert.Nil(t, store.Get("k3"))
+}
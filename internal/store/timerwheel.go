@@ -0,0 +1,193 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import "math/bits"
+
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelMask   = wheelSlots - 1
+	wheelLevels = 7 // the wheel spans 64^7 milliseconds, over a century, longer TTLs are rescheduled as it turns
+)
+
+// wheelEntry schedules the expiry check of a key at a unix time in milliseconds.
+type wheelEntry struct {
+	key string
+	at  uint64
+}
+
+// timerWheel is a hierarchical timing wheel of millisecond ticks scheduling the active expiry of the
+// keys with a TTL. Adding a key is O(1), and the keys due at the same tick are checked as a batch.
+// Level l has 64 slots of 64^l ticks each, whose keys move down to the lower levels once the wheel
+// reaches them.
+//
+// Entries are not removed when the TTL of a key changes or the key is deleted, every TTL set is
+// scheduled instead. An entry only expires its key if the key still holds a value whose TTL elapsed.
+type timerWheel struct {
+	slots  [wheelLevels][wheelSlots][]wheelEntry
+	counts [wheelLevels]int // counts is the number of entries of every level
+	tick   uint64           // tick is the next tick to process, the ticks before it are processed
+	len    int
+}
+
+func newTimerWheel() *timerWheel {
+	return &timerWheel{}
+}
+
+// Len returns the number of scheduled entries, including the ones of keys deleted or whose TTL changed.
+func (w *timerWheel) Len() int {
+	return w.len
+}
+
+// Add schedules the expiry check of key at the unix time at, in milliseconds.
+func (w *timerWheel) Add(key string, at, now uint64) {
+	if w.len == 0 && w.tick < now {
+		// Nothing is scheduled, the wheel catches up with the time at once
+		w.tick = now
+	}
+	w.add(wheelEntry{key: key, at: at})
+}
+
+func (w *timerWheel) add(e wheelEntry) {
+	at := max(e.at, w.tick)
+
+	// The entry goes to the lowest level whose slots tell at apart from the next tick
+	level := 0
+	if diff := at ^ w.tick; diff != 0 {
+		level = (bits.Len64(diff) - 1) / wheelBits
+	}
+
+	var slot uint64
+	if level < wheelLevels {
+		slot = (at >> (wheelBits * level)) & wheelMask
+	} else {
+		// Beyond the span of the wheel, the entry waits in the farthest slot to be rescheduled
+		level = wheelLevels - 1
+		slot = ((w.tick >> (wheelBits * level)) - 1) & wheelMask
+	}
+
+	w.slots[level][slot] = append(w.slots[level][slot], e)
+	w.counts[level]++
+	w.len++
+}
+
+// Advance processes the ticks up to now included, calling due with the keys scheduled at every tick.
+// It stops once due reported limit expired keys, leaving the next ticks to the next call.
+func (w *timerWheel) Advance(now uint64, limit int, due func(key string) bool) {
+	expired := 0
+	for w.tick <= now && expired < limit {
+		if w.skip(now) {
+			return
+		}
+		expired += w.process(due)
+		w.tick++
+	}
+}
+
+// skip moves the wheel past the ticks without entries to process, reporting true when no tick up to
+// now included has any.
+func (w *timerWheel) skip(now uint64) bool {
+	for level := 0; level < wheelLevels && w.counts[level] == 0; level++ {
+		if level == wheelLevels-1 {
+			w.tick = now + 1
+			return true
+		}
+
+		// Up to the next slot of the level above, the ticks only process the empty levels
+		span := uint64(1) << (wheelBits * (level + 1))
+		next := (w.tick + span - 1) / span * span
+		if next > now {
+			w.tick = now + 1
+			return true
+		}
+		w.tick = next
+	}
+	return false
+}
+
+// process moves the entries of the slots the tick reaches down the wheel, and checks the keys due at
+// the tick. It returns the number of keys expired.
+func (w *timerWheel) process(due func(key string) bool) int {
+	for level := wheelLevels - 1; level > 0; level-- {
+		if w.tick&(uint64(1)<<(wheelBits*level)-1) != 0 {
+			continue
+		}
+		slot := (w.tick >> (wheelBits * level)) & wheelMask
+		entries := w.slots[level][slot]
+		if len(entries) == 0 {
+			continue
+		}
+		w.slots[level][slot] = nil
+		w.counts[level] -= len(entries)
+		w.len -= len(entries)
+		for _, e := range entries {
+			w.add(e)
+		}
+	}
+
+	slot := w.tick & wheelMask
+	entries := w.slots[0][slot]
+	w.slots[0][slot] = nil
+	w.counts[0] -= len(entries)
+	w.len -= len(entries)
+
+	expired := 0
+	for _, e := range entries {
+		if e.at > w.tick {
+			// Only entries beyond the span of the wheel reach the first level early
+			w.add(e)
+		} else if due(e.key) {
+			expired++
+		}
+	}
+	return expired
+}
+
+// compact checks up to n entries of the slot index, level*wheelSlots+slot, starting at the entry from, and drops
+// the ones live reports stale. The order of the entries of a slot does not matter, the entries dropped are
+// replaced by the last ones. It returns the entry to resume from, the number of entries dropped and whether the
+// whole slot is checked, its memory being given back then if it holds memory for more than twice its entries.
+func (w *timerWheel) compact(index, from, n int, live func(e wheelEntry) bool) (next, dropped int, done bool) {
+	level, slot := index/wheelSlots, index%wheelSlots
+	entries := w.slots[level][slot]
+
+	i := from
+	for ; i < len(entries) && n > 0; n-- {
+		if live(entries[i]) {
+			i++
+			continue
+		}
+		last := len(entries) - 1
+		entries[i] = entries[last]
+		entries[last] = wheelEntry{}
+		entries = entries[:last]
+		dropped++
+	}
+	w.counts[level] -= dropped
+	w.len -= dropped
+
+	if i < len(entries) {
+		w.slots[level][slot] = entries
+		return i, dropped, false
+	}
+	if cap(entries) > 2*len(entries) {
+		entries = append([]wheelEntry(nil), entries...)
+	}
+	w.slots[level][slot] = entries
+	return 0, dropped, true
+}
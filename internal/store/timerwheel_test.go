@@ -0,0 +1,174 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimerWheel(t *testing.T) {
+	const now = uint64(1_700_000_000_000)
+	// The delays are in increasing order, as the wheel only moves forward
+	delays := []struct {
+		key   string
+		delay uint64
+	}{
+		{"elapsed", 0},
+		{"tick", 1},
+		{"slot", 63},
+		{"level1", 64},
+		{"seconds", 5_000},
+		{"hour", uint64(time.Hour.Milliseconds())},
+		{"month", uint64(30 * 24 * time.Hour.Milliseconds())},
+		{"centuries", uint64(200 * 365 * 24 * time.Hour.Milliseconds())},
+	}
+
+	w := newTimerWheel()
+	for _, d := range delays {
+		w.Add(d.key, now+d.delay, now)
+	}
+	assert.Equal(t, len(delays), w.Len())
+
+	for _, d := range delays {
+		var due []string
+		collect := func(k string) bool {
+			due = append(due, k)
+			return true
+		}
+
+		if d.delay > 0 {
+			w.Advance(now+d.delay-1, len(delays), collect)
+			assert.Empty(t, due, d.key)
+		}
+		w.Advance(now+d.delay, len(delays), collect)
+		assert.Equal(t, []string{d.key}, due)
+	}
+	assert.Equal(t, 0, w.Len())
+}
+
+func TestTimerWheelLimit(t *testing.T) {
+	const now = uint64(1_700_000_000_000)
+	w := newTimerWheel()
+	for i := 0; i < 10; i++ {
+		w.Add("k"+strconv.Itoa(i), now+uint64(i), now)
+	}
+
+	expired := 0
+	due := func(string) bool {
+		expired++
+		return true
+	}
+	w.Advance(now+10, 4, due)
+	assert.Equal(t, 4, expired)
+	w.Advance(now+10, 10, due)
+	assert.Equal(t, 10, expired)
+}
+
+func TestActiveExpiry(t *testing.T) {
+	mockTime := &utils.MockClock{CurrTime: time.Now()}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	s := NewStore(nil, nil)
+	for i := 1; i <= 100; i++ {
+		s.Put("k"+strconv.Itoa(i), s.NewObj("v", int64(i), object.ObjTypeString))
+	}
+	s.Put("persistent", s.NewObj("v", -1, object.ObjTypeString))
+
+	// Changing the TTL of a key reschedules it
+	s.SetKeyExpiry("k1", s.GetNoTouch("k1"), 1000)
+	s.Put("k2", s.NewObj("w", -1, object.ObjTypeString))
+
+	mockTime.SetTime(mockTime.GetTime().Add(50 * time.Millisecond))
+	DeleteExpiredKeys(s)
+	assert.Equal(t, 53, s.GetKeyCount())
+	assert.NotNil(t, s.GetNoTouch("k1"))
+	assert.NotNil(t, s.GetNoTouch("k2"))
+	assert.Nil(t, s.GetNoTouch("k50"))
+	assert.NotNil(t, s.GetNoTouch("k51"))
+
+	mockTime.SetTime(mockTime.GetTime().Add(time.Second))
+	DeleteExpiredKeys(s)
+	assert.Equal(t, 2, s.GetKeyCount())
+	assert.NotNil(t, s.GetNoTouch("k2"))
+	assert.NotNil(t, s.GetNoTouch("persistent"))
+}
+
+// sampleExpiredKeys deletes the expired keys by sampling the keyspace until less than a quarter of
+// a sample has expired, the way the keys were actively expired before the timing wheel.
+func sampleExpiredKeys(s *Store) {
+	for {
+		var expired []string
+		limit := fieldExpireSampleSize
+		s.allKeys(func(k string, obj *object.Obj) bool {
+			limit--
+			if hasExpired(obj, s) {
+				expired = append(expired, k)
+			}
+			return limit > 0
+		})
+		for _, k := range expired {
+			s.expireDueKey(k)
+		}
+		if len(expired)*4 < fieldExpireSampleSize {
+			return
+		}
+	}
+}
+
+// BenchmarkActiveExpiry measures the CPU of the active expiry cycles of a store whose keys expire
+// over ten seconds, with a cycle run every millisecond.
+func BenchmarkActiveExpiry(b *testing.B) {
+	mockTime := &utils.MockClock{CurrTime: time.Now()}
+	utils.CurrentTime = mockTime
+	defer func() { utils.CurrentTime = utils.RealClock{} }()
+
+	const window = 10_000
+	cycles := map[string]func(*Store){"wheel": DeleteExpiredKeys, "sampling": sampleExpiredKeys}
+	for _, keys := range []int{100_000, 1_000_000, 10_000_000} {
+		for name, cycle := range cycles {
+			b.Run(fmt.Sprintf("%s/keys=%d", name, keys), func(b *testing.B) {
+				start := mockTime.GetTime()
+				s := NewStore(nil, nil)
+				for i := 0; i < keys; i++ {
+					s.Put("k"+strconv.Itoa(i), s.NewObj("v", int64(i%window), object.ObjTypeString))
+				}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					mockTime.SetTime(start.Add(time.Duration(i%window) * time.Millisecond))
+					cycle(s)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkTimerWheelAdd(b *testing.B) {
+	const now = uint64(1_700_000_000_000)
+	w := newTimerWheel()
+	for i := 0; i < b.N; i++ {
+		w.Add("k", now+uint64(i%3_600_000), now)
+	}
+}
@@ -0,0 +1,122 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+)
+
+// trashed is a key moved to the trash by DEL, kept until UNDELETE restores it or it is purged.
+type trashed struct {
+	key          string
+	obj          *object.Obj
+	expiresAt    uint64            // expiresAt is the expiry of the key in unix milliseconds, 0 without expiry
+	fieldExpires map[string]uint64 // fieldExpires are the expiries of the fields of a hash
+	deletedAt    uint64            // deletedAt is the time of the deletion in unix milliseconds
+}
+
+// trash holds the keys deleted while trash.enabled is set. The keys are purged in the order they were
+// deleted, once trash.retention elapsed or trash.max_keys keys are held.
+type trash struct {
+	keys  map[string]*trashed
+	order []*trashed // order is the keys by time of deletion, including the ones deleted again or restored since
+}
+
+// Trash deletes the key like Del, but keeps its value in the trash when trash.enabled is set, so that
+// UNDELETE restores it. Returns true if the key existed.
+func (store *Store) Trash(k string) bool {
+	if !config.DiceConfig().Trash.Enabled {
+		return store.Del(k)
+	}
+
+	obj, ok := store.store.Get(k)
+	if !ok {
+		return false
+	}
+	if hasExpired(obj, store) {
+		store.expireKey(k, obj)
+		return false
+	}
+
+	t := &trashed{key: k, obj: obj, fieldExpires: store.fieldExpires[obj], deletedAt: uint64(utils.GetCurrentTime().UnixMilli())}
+	t.expiresAt, _ = store.expires.Get(obj)
+	if !store.deleteKey(k, obj) {
+		return false
+	}
+
+	if store.trash.keys == nil {
+		store.trash.keys = make(map[string]*trashed)
+	}
+	store.trash.keys[k] = t
+	store.trash.order = append(store.trash.order, t)
+	PurgeTrash(store)
+	return true
+}
+
+// Undelete restores the key from the trash, with the expiry it had, unless the key exists again or expired
+// since it was deleted. Returns true if the key was restored.
+func (store *Store) Undelete(k string) bool {
+	t, ok := store.trash.keys[k]
+	if !ok || store.GetNoTouch(k) != nil {
+		return false
+	}
+	delete(store.trash.keys, k)
+
+	now := uint64(utils.GetCurrentTime().UnixMilli())
+	if (t.expiresAt != 0 && t.expiresAt <= now) || t.deletedAt+uint64(config.DiceConfig().Trash.Retention.Milliseconds()) <= now {
+		return false
+	}
+
+	if t.expiresAt != 0 {
+		store.expires.Put(t.obj, t.expiresAt)
+	}
+	if t.fieldExpires != nil {
+		store.fieldExpires[t.obj] = t.fieldExpires
+	}
+	store.Put(k, t.obj, WithPutCmd(Undelete))
+	return true
+}
+
+// TrashLen returns the number of keys held in the trash.
+func (store *Store) TrashLen() int {
+	return len(store.trash.keys)
+}
+
+// PurgeTrash removes the keys held in the trash for longer than trash.retention, and the oldest ones beyond
+// trash.max_keys. The whole trash is purged once trash.enabled is unset.
+func PurgeTrash(store *Store) {
+	if !config.DiceConfig().Trash.Enabled {
+		store.trash = trash{}
+		return
+	}
+
+	deadline := uint64(utils.GetCurrentTime().Add(-config.DiceConfig().Trash.Retention).UnixMilli())
+	purged := 0
+	for _, t := range store.trash.order {
+		if store.trash.keys[t.key] == t {
+			if t.deletedAt > deadline && len(store.trash.keys) <= config.DiceConfig().Trash.MaxKeys {
+				break
+			}
+			delete(store.trash.keys, t.key)
+		}
+		purged++
+	}
+	clear(store.trash.order[:purged])
+	store.trash.order = store.trash.order[purged:]
+}
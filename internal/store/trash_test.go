@@ -0,0 +1,91 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/dicedb/dice/internal/server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	config.DiceConfig().Trash.Enabled = true
+	config.DiceConfig().Trash.Retention = time.Minute
+	config.DiceConfig().Trash.MaxKeys = 2
+	mockTime := &utils.MockClock{CurrTime: time.Now()}
+	utils.CurrentTime = mockTime
+	t.Cleanup(func() {
+		config.DiceConfig().Trash.Enabled = false
+		utils.CurrentTime = utils.RealClock{}
+	})
+
+	store := NewStore(nil, nil)
+	var events []string
+	store.Subscribe(KeyEventFunc(func(e KeyEvent) {
+		events = append(events, fmt.Sprintf("%d %s %s", e.Type, e.Cmd, e.Key))
+	}))
+
+	obj := store.NewObj("v", 30000, object.ObjTypeString)
+	store.Put("k", obj)
+	assert.True(t, store.Trash("k"))
+	assert.False(t, store.Trash("k"))
+	assert.Nil(t, store.Get("k"))
+	assert.Equal(t, 1, store.TrashLen())
+
+	// The key is restored with its expiry, unless it exists again
+	store.Put("k", store.NewObj("other", -1, object.ObjTypeString))
+	assert.False(t, store.Undelete("k"))
+	assert.True(t, store.Del("k"))
+	assert.True(t, store.Undelete("k"))
+	assert.Equal(t, "v", store.Get("k").Value)
+	exp, ok := GetExpiry(obj, store)
+	assert.True(t, ok)
+	assert.Greater(t, exp, uint64(mockTime.CurrTime.UnixMilli()))
+	assert.False(t, store.Undelete("k"))
+	assert.Zero(t, store.TrashLen())
+	assert.Equal(t, []string{"1 SET k", "2 DEL k", "1 SET k", "2 DEL k", "1 UNDELETE k"}, events)
+
+	// The keys that expired since they were deleted are not restored
+	assert.True(t, store.Trash("k"))
+	mockTime.SetTime(mockTime.CurrTime.Add(40 * time.Second))
+	assert.False(t, store.Undelete("k"))
+
+	// The keys are purged once the retention elapsed, or beyond max_keys
+	for _, k := range []string{"a", "b", "c"} {
+		store.Put(k, store.NewObj(k, -1, object.ObjTypeString))
+		assert.True(t, store.Trash(k))
+	}
+	assert.Equal(t, 2, store.TrashLen())
+	assert.False(t, store.Undelete("a"))
+	mockTime.SetTime(mockTime.CurrTime.Add(time.Minute))
+	PurgeTrash(store)
+	assert.Zero(t, store.TrashLen())
+	assert.False(t, store.Undelete("c"))
+
+	// DEL deletes the keys for good once the trash is disabled
+	config.DiceConfig().Trash.Enabled = false
+	store.Put("k", store.NewObj("v", -1, object.ObjTypeString))
+	assert.True(t, store.Trash("k"))
+	assert.Zero(t, store.TrashLen())
+}
@@ -0,0 +1,57 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import "github.com/dicedb/dice/internal/object"
+
+// Every write of a key gives it a new version, the next value of the version clock of the store, so that the
+// versions of a key only grow, even across its deletion and recreation, and are never taken twice by a key. The
+// versions of the keys are not contiguous: a write gives the key the version following the last one given to any
+// key of the shard. The versions live in memory only, the keys loaded from a snapshot or a backup take new ones.
+//
+// The store versions the keys it puts. The commands modifying a value in place without putting it back are
+// versioned by the shard executing them, with Written.
+
+// bumpVersion gives the object of the key a new version.
+func (store *Store) bumpVersion(obj *object.Obj) {
+	store.versionClock++
+	obj.Version = store.versionClock
+}
+
+// VersionClock returns the last version given to a key of the store.
+func (store *Store) VersionClock() uint64 {
+	return store.versionClock
+}
+
+// Version returns the version of the key, 0 if it does not exist.
+func (store *Store) Version(k string) uint64 {
+	obj := store.GetNoTouch(k)
+	if obj == nil {
+		return 0
+	}
+	return obj.Version
+}
+
+// Written gives a new version to the keys written by a command that were not versioned since the version clock
+// was at since, e.g. as the command modified their values in place. The keys that do not exist are ignored.
+func (store *Store) Written(keys []string, since uint64) {
+	for _, k := range keys {
+		if obj, ok := store.store.Get(k); ok && obj.Version <= since {
+			store.bumpVersion(obj)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersion(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	store := NewStore(nil, nil)
+
+	assert.Equal(t, uint64(0), store.Version("k"))
+	store.Put("k", store.NewObj("v1", -1, object.ObjTypeString))
+	first := store.Version("k")
+	assert.NotZero(t, first)
+
+	store.Put("other", store.NewObj("v", -1, object.ObjTypeString))
+	store.Put("k", store.NewObj("v2", -1, object.ObjTypeString))
+	second := store.Version("k")
+	assert.Greater(t, second, first)
+	assert.Equal(t, store.VersionClock(), second)
+
+	// A recreated key never takes a version it had before
+	store.Del("k")
+	assert.Equal(t, uint64(0), store.Version("k"))
+	store.Put("k", store.NewObj("v1", -1, object.ObjTypeString))
+	assert.Greater(t, store.Version("k"), second)
+
+	// Written only versions again the keys that were not versioned since
+	since := store.VersionClock()
+	store.Put("other", store.NewObj("w", -1, object.ObjTypeString))
+	other := store.Version("other")
+	k := store.Version("k")
+	store.Written([]string{"k", "other", "missing"}, since)
+	assert.Greater(t, store.Version("k"), k)
+	assert.Equal(t, other, store.Version("other"))
+	assert.Equal(t, uint64(0), store.Version("missing"))
+}
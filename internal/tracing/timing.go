@@ -0,0 +1,102 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceHeader is the header of the HTTP requests, and of the handshakes of the WebSocket connections, asking for
+// the timing of their stages to be attached to the replies
+const TraceHeader = "Dice-Trace"
+
+// Traced reports whether the header asks for the timing of the stages of the requests, with Dice-Trace set to 1
+// or true.
+func Traced(header http.Header) bool {
+	v := header.Get(TraceHeader)
+	return v == "1" || strings.EqualFold(v, "true")
+}
+
+// Timing is the time a request asking for it spent in every stage of its execution: the parsing of the command,
+// the wait in the queues of the shards, the evaluation of the command, the encoding of the reply and its write to
+// the client. The shards executing the request report their stages concurrently, a request executed by several
+// shards is given the longest wait and evaluation of its shards.
+type Timing struct {
+	mu     sync.Mutex
+	parse  time.Duration
+	queue  time.Duration
+	eval   time.Duration
+	encode time.Duration
+	write  time.Duration
+}
+
+// Stages are the durations of the stages of a request in microseconds, as replied to the client. The write is
+// left out of the stages attached to the reply being written.
+type Stages struct {
+	ParseUsec  int64 `json:"parse_usec"`
+	QueueUsec  int64 `json:"queue_usec"`
+	EvalUsec   int64 `json:"eval_usec"`
+	EncodeUsec int64 `json:"encode_usec"`
+	WriteUsec  int64 `json:"write_usec,omitempty"`
+}
+
+// NewTiming returns the timing of a request whose command was parsed in parse.
+func NewTiming(parse time.Duration) *Timing {
+	return &Timing{parse: parse}
+}
+
+// Executed records the execution of the request by a shard, after waiting queue in its queue. It is a no-op on a
+// nil Timing, the one of the requests not traced.
+func (t *Timing) Executed(queue, eval time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queue = max(t.queue, queue)
+	t.eval = max(t.eval, eval)
+}
+
+// Encoded records the encoding of the reply.
+func (t *Timing) Encoded(encode time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.encode = encode
+}
+
+// Written records the write of the reply.
+func (t *Timing) Written(write time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.write = write
+}
+
+// Stages returns the durations of the stages recorded so far.
+func (t *Timing) Stages() Stages {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Stages{
+		ParseUsec:  t.parse.Microseconds(),
+		QueueUsec:  t.queue.Microseconds(),
+		EvalUsec:   t.eval.Microseconds(),
+		EncodeUsec: t.encode.Microseconds(),
+		WriteUsec:  t.write.Microseconds(),
+	}
+}
@@ -0,0 +1,122 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package tracing sets up OpenTelemetry tracing of the request path. Until Init is called with
+// tracing enabled, spans are started on the no-op tracer provider and cost next to nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/dicedb/dice/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/dicedb/dice"
+
+	// Names of the spans of the request path
+	SpanParse    = "parse"
+	SpanDispatch = "dispatch"
+	SpanEval     = "eval"
+	SpanReply    = "reply"
+)
+
+// Init installs the OTLP/HTTP exporting tracer provider described by the tracing section of the config.
+// It returns a function flushing the pending spans and stopping the exporter, a no-op when tracing is disabled.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	// Incoming traceparent headers are honored even when spans are not exported, so that the
+	// trace context of a request is kept intact
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if !config.DiceConfig().Tracing.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.DiceConfig().Tracing.Endpoint)}
+	if config.DiceConfig().Tracing.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.DiceConfig().Tracing.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(config.DiceConfig().Tracing.ServiceName),
+			semconv.ServiceVersion(config.DiceDBVersion),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span of the request path as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartRequest starts the root span of a request received by a frontend, e.g. "resp.request".
+func StartRequest(ctx context.Context, frontend, clientAddr string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, frontend+".request",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.DBSystemKey.String("dicedb"),
+			semconv.ClientAddress(clientAddr),
+		))
+}
+
+// SetCommand records the name of the command being executed on the span of the request.
+func SetCommand(ctx context.Context, command string) {
+	trace.SpanFromContext(ctx).SetAttributes(semconv.DBOperationName(command))
+}
+
+// Extract returns ctx with the trace context of the traceparent header of an HTTP or WebSocket request.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// ContextWithSpanContext returns a context carrying sc, used to continue a trace
+// across the channel between the io-threads and the shards.
+func ContextWithSpanContext(sc trace.SpanContext) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+// SpanContext returns the span context of the span in ctx.
+func SpanContext(ctx context.Context) trace.SpanContext {
+	return trace.SpanContextFromContext(ctx)
+}
+
+// RecordError marks the span in ctx as failed with err.
+func RecordError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,76 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestSpans(t *testing.T) {
+	_, err := Init(context.Background())
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx, span := StartRequest(Extract(context.Background(), header), "http", "127.0.0.1:5000")
+	SetCommand(ctx, "GET")
+	_, eval := Start(ContextWithSpanContext(SpanContext(ctx)), SpanEval)
+	eval.End()
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, SpanEval, spans[0].Name())
+	assert.Equal(t, "http.request", spans[1].Name())
+
+	// The request continues the trace of the traceparent header and the execution on the shard is its child
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[1].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[1].Parent().SpanID().String())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestTiming(t *testing.T) {
+	header := http.Header{}
+	assert.False(t, Traced(header))
+	header.Set(TraceHeader, "true")
+	assert.True(t, Traced(header))
+
+	// The requests not traced have no timing to record
+	var untraced *Timing
+	untraced.Executed(time.Second, time.Second)
+
+	timing := NewTiming(time.Millisecond)
+	timing.Executed(2*time.Millisecond, 4*time.Millisecond)
+	timing.Executed(3*time.Millisecond, time.Millisecond)
+	timing.Encoded(5 * time.Microsecond)
+	timing.Written(7 * time.Microsecond)
+	assert.Equal(t, Stages{ParseUsec: 1000, QueueUsec: 3000, EvalUsec: 4000, EncodeUsec: 5, WriteUsec: 7}, timing.Stages())
+}
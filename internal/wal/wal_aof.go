@@ -18,20 +18,24 @@ package wal
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"encoding/binary"
 	"fmt"
-	"hash/crc32"
 	"io"
 	"log"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	sync "sync"
 	"time"
 
 	"github.com/dicedb/dice/config"
 	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/latency"
 )
 
 const (
@@ -40,6 +44,10 @@ const (
 	RotationModeTime  = "time"
 	RetentionModeTime = "time"
 	WALModeUnbuffered = "unbuffered"
+
+	RecoveryModeStrict   = "strict"
+	RecoveryModeTruncate = "truncate"
+	RecoveryModeIgnore   = "ignore"
 )
 
 type AOF struct {
@@ -64,6 +72,32 @@ type AOF struct {
 	mu                     sync.Mutex
 	ctx                    context.Context
 	cancel                 context.CancelFunc
+	onRotate               func(path string) // onRotate is called with the path of every segment once rotated
+	recovery               Recovery          // recovery is the report of the last replay of the segments
+}
+
+// Recovery reports the replay of the WAL: the entries replayed and the ones skipped as corrupt.
+type Recovery struct {
+	Replayed  int   // Replayed is the number of entries replayed
+	Corrupted int   // Corrupted is the number of entries failing their checksum or that could not be decoded
+	Skipped   int64 // Skipped is the number of bytes of the segments not replayed
+	// FirstCorrupt is the position of the first corrupt entry, e.g. "seg-2:4096", empty if none
+	FirstCorrupt string
+}
+
+// CorruptionError is returned by the replay of the WAL at the first corrupt entry in the strict recovery mode.
+type CorruptionError struct {
+	Segment string
+	Offset  int
+	Err     error
+}
+
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("corrupt WAL entry in %s at offset %d: %v", e.Segment, e.Offset, e.Err)
+}
+
+func (e *CorruptionError) Unwrap() error {
+	return e.Err
 }
 
 func NewAOFWAL(directory string) (*AOF, error) {
@@ -71,17 +105,17 @@ func NewAOFWAL(directory string) (*AOF, error) {
 
 	return &AOF{
 		logDir:                 directory,
-		walMode:                config.DiceConfig.WAL.WalMode,
-		bufferSyncTicker:       time.NewTicker(config.DiceConfig.WAL.BufferSyncInterval),
-		segmentRotationTicker:  time.NewTicker(config.DiceConfig.WAL.MaxSegmentRotationTime),
-		segmentRetentionTicker: time.NewTicker(config.DiceConfig.WAL.MaxSegmentRetentionDuration),
-		writeMode:              config.DiceConfig.WAL.WriteMode,
-		maxSegmentSize:         config.DiceConfig.WAL.MaxSegmentSizeMB * 1024 * 1024,
-		maxSegmentCount:        config.DiceConfig.WAL.MaxSegmentCount,
-		bufferSize:             config.DiceConfig.WAL.BufferSizeMB * 1024 * 1024,
-		retentionMode:          config.DiceConfig.WAL.RetentionMode,
-		recoveryMode:           config.DiceConfig.WAL.RecoveryMode,
-		rotationMode:           config.DiceConfig.WAL.RotationMode,
+		walMode:                config.DiceConfig().WAL.WalMode,
+		bufferSyncTicker:       time.NewTicker(config.DiceConfig().WAL.BufferSyncInterval),
+		segmentRotationTicker:  time.NewTicker(config.DiceConfig().WAL.MaxSegmentRotationTime),
+		segmentRetentionTicker: time.NewTicker(config.DiceConfig().WAL.MaxSegmentRetentionDuration),
+		writeMode:              config.DiceConfig().WAL.WriteMode,
+		maxSegmentSize:         config.DiceConfig().WAL.MaxSegmentSizeMB * 1024 * 1024,
+		maxSegmentCount:        config.DiceConfig().WAL.MaxSegmentCount,
+		bufferSize:             config.DiceConfig().WAL.BufferSizeMB * 1024 * 1024,
+		retentionMode:          config.DiceConfig().WAL.RetentionMode,
+		recoveryMode:           config.DiceConfig().WAL.RecoveryMode,
+		rotationMode:           config.DiceConfig().WAL.RotationMode,
 		ctx:                    ctx,
 		cancel:                 cancel,
 	}, nil
@@ -148,7 +182,7 @@ func (wal *AOF) writeEntry(data []byte) error {
 		Version:           defaultVersion,
 		LogSequenceNumber: wal.lastSequenceNo,
 		Data:              data,
-		Crc32:             crc32.ChecksumIEEE(append(data, byte(wal.lastSequenceNo))),
+		Crc32:             entryChecksum(data, wal.lastSequenceNo),
 		Timestamp:         time.Now().UnixNano(),
 	}
 
@@ -195,15 +229,25 @@ func (wal *AOF) rotateLogIfNeeded(entrySize int) error {
 	return nil
 }
 
+// OnRotate registers fn to be called with the path of every segment once rotated, e.g. to archive it. It must
+// be called before Init, fn is called with the lock of the WAL held and must not block.
+func (wal *AOF) OnRotate(fn func(path string)) {
+	wal.onRotate = fn
+}
+
 // rotateLog is not thread safe
 func (wal *AOF) rotateLog() error {
 	if err := wal.Sync(); err != nil {
 		return err
 	}
 
+	rotated := wal.currentSegmentFile.Name()
 	if err := wal.currentSegmentFile.Close(); err != nil {
 		return err
 	}
+	if wal.onRotate != nil {
+		wal.onRotate(rotated)
+	}
 
 	wal.currentSegmentIndex++
 
@@ -214,7 +258,7 @@ func (wal *AOF) rotateLog() error {
 		wal.oldestSegmentIndex++
 	}
 
-	newFile, err := os.OpenFile(filepath.Join(wal.logDir, segmentPrefix+fmt.Sprintf("-%d", wal.currentSegmentIndex)), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	newFile, err := os.OpenFile(filepath.Join(wal.logDir, segmentPrefix+fmt.Sprintf("%d", wal.currentSegmentIndex)), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Fatalf("failed opening file: %s", err)
 	}
@@ -256,6 +300,7 @@ func (wal *AOF) Sync() error {
 		return err
 	}
 	if wal.writeMode == "fsync" { //nolint:goconst
+		defer latency.Since(latency.EventAOFFsync, time.Now())
 		if err := wal.currentSegmentFile.Sync(); err != nil {
 			return err
 		}
@@ -318,7 +363,130 @@ func (wal *AOF) deleteSegmentPeriodically() {
 	}
 }
 
+// ForEachCommand replays the commands of the segments, oldest first. The corrupt entries, failing their checksum
+// or cut short by a crash, are handled according to the recovery mode:
+//   - strict stops the replay with a CorruptionError at the first corrupt entry,
+//   - truncate replays the entries up to the first corrupt one, and drops it along with the entries following it,
+//   - ignore skips the corrupt entries. An entry whose length prefix is damaged can not be skipped alone, the rest
+//     of its segment is skipped with it.
+//
+// The entries skipped are logged and reported by Recovery.
 func (wal *AOF) ForEachCommand(f func(c cmd.DiceDBCmd) error) error {
-	// TODO: implement this method
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+
+	// The entries still buffered are replayed too
+	if wal.bufWriter != nil {
+		if err := wal.bufWriter.Flush(); err != nil {
+			return err
+		}
+	}
+
+	files, err := wal.segments()
+	if err != nil {
+		return err
+	}
+
+	rec := Recovery{}
+	defer func() {
+		wal.recovery = rec
+		if rec.Corrupted > 0 {
+			slog.Warn("skipped corrupt WAL entries", slog.String("mode", wal.recoveryMode),
+				slog.String("first", rec.FirstCorrupt), slog.Int("corrupted", rec.Corrupted),
+				slog.Int64("skipped_bytes", rec.Skipped), slog.Int("replayed", rec.Replayed))
+		}
+	}()
+
+	for i, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		for offset := 0; offset < len(data); {
+			size, entry, err := decodeEntry(data[offset:])
+			if err == nil {
+				if c, ok := parseCommand(entry.Data); ok {
+					if err := f(c); err != nil {
+						return err
+					}
+				}
+				rec.Replayed++
+				offset += size
+				continue
+			}
+
+			rec.Corrupted++
+			if rec.FirstCorrupt == "" {
+				rec.FirstCorrupt = fmt.Sprintf("%s:%d", filepath.Base(path), offset)
+			}
+			switch wal.recoveryMode {
+			case RecoveryModeIgnore:
+				if size == 0 {
+					size = len(data) - offset
+				}
+				rec.Skipped += int64(size)
+				offset += size
+			case RecoveryModeTruncate:
+				rec.Skipped += int64(len(data) - offset)
+				return wal.truncate(files[i:], int64(offset), &rec)
+			default:
+				return &CorruptionError{Segment: filepath.Base(path), Offset: offset, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// Recovery returns the report of the last replay of the segments.
+func (wal *AOF) Recovery() Recovery {
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+	return wal.recovery
+}
+
+// segments returns the paths of the segments, ordered by index.
+func (wal *AOF) segments() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(wal.logDir, segmentPrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make(map[string]int, len(files))
+	segments := files[:0]
+	for _, path := range files {
+		index, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), segmentPrefix))
+		if err != nil || index < 0 {
+			continue
+		}
+		indices[path] = index
+		segments = append(segments, path)
+	}
+	slices.SortFunc(segments, func(a, b string) int { return cmp.Compare(indices[a], indices[b]) })
+	return segments, nil
+}
+
+// truncate cuts the first of the segments at the offset and removes the following ones, but for the segment
+// being written to which is emptied instead.
+func (wal *AOF) truncate(segments []string, offset int64, rec *Recovery) error {
+	if err := os.Truncate(segments[0], offset); err != nil {
+		return err
+	}
+	for _, path := range segments[1:] {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		rec.Skipped += info.Size()
+
+		if wal.currentSegmentFile != nil && path == wal.currentSegmentFile.Name() {
+			err = os.Truncate(path, 0)
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
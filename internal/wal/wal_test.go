@@ -17,11 +17,18 @@
 package wal_test
 
 import (
+	"bytes"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/cmd"
 	"github.com/dicedb/dice/internal/wal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func BenchmarkLogCommandAOF(b *testing.B) {
@@ -40,3 +47,105 @@ func BenchmarkLogCommandAOF(b *testing.B) {
 		wl.LogCommand([]byte("SET key value"))
 	}
 }
+
+// writeSegment logs the commands to a WAL in dir and closes it.
+func writeSegment(t *testing.T, dir string, commands ...string) {
+	wl, err := wal.NewAOFWAL(dir)
+	require.NoError(t, err)
+	require.NoError(t, wl.Init(time.Now()))
+	for _, c := range commands {
+		require.NoError(t, wl.LogCommand([]byte(c)))
+	}
+	require.NoError(t, wl.Close())
+}
+
+func replay(t *testing.T, dir, mode string) ([]string, wal.Recovery, error) {
+	config.DiceConfig().WAL.RecoveryMode = mode
+	wl, err := wal.NewAOFWAL(dir)
+	require.NoError(t, err)
+
+	var replayed []string
+	err = wl.ForEachCommand(func(c cmd.DiceDBCmd) error {
+		replayed = append(replayed, c.Repr())
+		return nil
+	})
+	return replayed, wl.Recovery(), err
+}
+
+func TestForEachCommand(t *testing.T) {
+	require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	defer func() {
+		require.NoError(t, config.NewConfigParser().ParseDefaults(config.DiceConfig()))
+	}()
+
+	// corrupt returns a WAL of 3 entries whose second one is damaged
+	corrupt := func(t *testing.T) (string, string) {
+		dir := t.TempDir()
+		writeSegment(t, dir, "SET a 1", "SET b 2", "SET c 3")
+		path := filepath.Join(dir, "seg-0")
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		i := bytes.Index(data, []byte("SET b 2"))
+		data[i+4] = 'x'
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		return dir, path
+	}
+
+	t.Run("intact", func(t *testing.T) {
+		dir := t.TempDir()
+		writeSegment(t, dir, "SET a 1", "DEL a")
+		replayed, rec, err := replay(t, dir, wal.RecoveryModeStrict)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SET a 1", "DEL a"}, replayed)
+		assert.Equal(t, wal.Recovery{Replayed: 2}, rec)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		dir, _ := corrupt(t)
+		replayed, rec, err := replay(t, dir, wal.RecoveryModeStrict)
+		var corruption *wal.CorruptionError
+		require.ErrorAs(t, err, &corruption)
+		assert.Equal(t, "seg-0", corruption.Segment)
+		assert.Equal(t, []string{"SET a 1"}, replayed)
+		assert.Equal(t, 1, rec.Corrupted)
+	})
+
+	t.Run("ignore", func(t *testing.T) {
+		dir, _ := corrupt(t)
+		replayed, rec, err := replay(t, dir, wal.RecoveryModeIgnore)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SET a 1", "SET c 3"}, replayed)
+		assert.Equal(t, 2, rec.Replayed)
+		assert.Equal(t, 1, rec.Corrupted)
+		assert.Positive(t, rec.Skipped)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		dir, _ := corrupt(t)
+		replayed, rec, err := replay(t, dir, wal.RecoveryModeTruncate)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SET a 1"}, replayed)
+		assert.Equal(t, 1, rec.Corrupted)
+		assert.Regexp(t, `^seg-0:\d+$`, rec.FirstCorrupt)
+
+		// The corrupt entry and the ones following it are dropped
+		replayed, rec, err = replay(t, dir, wal.RecoveryModeStrict)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SET a 1"}, replayed)
+		assert.Equal(t, wal.Recovery{Replayed: 1}, rec)
+	})
+
+	t.Run("cut short", func(t *testing.T) {
+		dir := t.TempDir()
+		writeSegment(t, dir, "SET a 1", "SET b 2")
+		path := filepath.Join(dir, "seg-0")
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data[:len(data)-3], 0644))
+
+		replayed, rec, err := replay(t, dir, wal.RecoveryModeIgnore)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SET a 1"}, replayed)
+		assert.Equal(t, 1, rec.Corrupted)
+	})
+}
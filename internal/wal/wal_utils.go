@@ -1,8 +1,14 @@
 package wal
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
 
+	"github.com/dicedb/dice/internal/cmd"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -33,6 +39,53 @@ func MustUnmarshal(data []byte, entry *WALEntry) {
 	}
 }
 
+// entryChecksum is the checksum of the data of an entry, along with the low byte of its sequence number.
+func entryChecksum(data []byte, lsn uint64) uint32 {
+	h := crc32.NewIEEE()
+	h.Write(data)
+	h.Write([]byte{byte(lsn)})
+	return h.Sum32()
+}
+
+var (
+	errEntrySize        = errors.New("invalid entry size")
+	errChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// decodeEntry decodes the entry at the start of b, as written by writeEntryToBuffer, and returns its size.
+// The size of a corrupt entry is 0 if its length prefix is damaged or the entry is cut short, so that the
+// entries following it can not be found.
+func decodeEntry(b []byte) (int, *WALEntry, error) {
+	if len(b) < 4 {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	n := int(int32(binary.LittleEndian.Uint32(b)))
+	if n <= 0 {
+		return 0, nil, errEntrySize
+	}
+	if n > len(b)-4 {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+
+	entry := &WALEntry{}
+	if err := proto.Unmarshal(b[4:4+n], entry); err != nil {
+		return 4 + n, nil, err
+	}
+	if entry.Crc32 != entryChecksum(entry.Data, entry.LogSequenceNumber) {
+		return 4 + n, nil, errChecksumMismatch
+	}
+	return 4 + n, entry, nil
+}
+
+// parseCommand returns the command logged as the data of an entry.
+func parseCommand(data []byte) (cmd.DiceDBCmd, bool) {
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return cmd.DiceDBCmd{}, false
+	}
+	return cmd.DiceDBCmd{Cmd: fields[0], Args: fields[1:]}, true
+}
+
 func getEntrySize(data []byte) int {
 	return versionTagSize + versionLengthPrefixSize + versionSize + // Version field
 		logSequenceNumberSize + // Log Sequence Number field
@@ -20,28 +20,56 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"time"
 
+	"github.com/dicedb/dice/internal/bridge"
 	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/failpoint"
+	"github.com/dicedb/dice/internal/metrics"
+	"github.com/dicedb/dice/internal/stats"
 	dstore "github.com/dicedb/dice/internal/store"
 )
 
 type (
 	WatchSubscription struct {
-		Subscribe    bool                // Subscribe is true for subscribe, false for unsubscribe. Required.
-		AdhocReqChan chan *cmd.DiceDBCmd // AdhocReqChan is the channel to send adhoc requests to the io-thread. Required.
-		WatchCmd     *cmd.DiceDBCmd      // WatchCmd Represents a unique key for each watch artifact, only populated for subscriptions.
-		Fingerprint  uint32              // Fingerprint is a unique identifier for each watch artifact, only populated for unsubscriptions.
+		Subscribe    bool              // Subscribe is true for subscribe, false for unsubscribe. Required.
+		AdhocReqChan chan Notification // AdhocReqChan is the channel to send adhoc requests to the io-thread. Required.
+		WatchCmd     *cmd.DiceDBCmd    // WatchCmd Represents a unique key for each watch artifact, only populated for subscriptions.
+		Fingerprint  uint32            // Fingerprint is a unique identifier for each watch artifact, only populated for unsubscriptions.
+		MaxLag       time.Duration     // MaxLag bounds the lag of the replica behind its upstream for the subscription, 0 for no bound.
+	}
+
+	// Notification asks the io-thread of a subscriber to run a watched command again and push its new result.
+	Notification struct {
+		Cmd       *cmd.DiceDBCmd // Cmd is the watched command, shared by every notification of the same fingerprint.
+		ChangedAt time.Time      // ChangedAt is the time at which the key the command depends on was changed.
+		// Lag is set once the lag of the replica behind its upstream exceeds the bound of the subscription, the
+		// results of the command being stale until a notification without lag follows.
+		Lag      time.Duration
+		Upstream string // Upstream is the address of the upstream the replica lags behind, set along with Lag.
+	}
+
+	// bound is the lag bound of a subscription.
+	bound struct {
+		maxLag  time.Duration
+		lagging bool // lagging is set while the lag exceeds maxLag, the subscriber is not notified of the changes then
 	}
 
 	Manager struct {
-		querySubscriptionMap     map[string]map[uint32]struct{}              // querySubscriptionMap is a map of Key -> [fingerprint1, fingerprint2, ...]
-		tcpSubscriptionMap       map[uint32]map[chan *cmd.DiceDBCmd]struct{} // tcpSubscriptionMap is a map of fingerprint -> [client1Chan, client2Chan, ...]
-		fingerprintCmdMap        map[uint32]*cmd.DiceDBCmd                   // fingerprintCmdMap is a map of fingerprint -> DiceDBCmd
-		cmdWatchSubscriptionChan chan WatchSubscription                      // cmdWatchSubscriptionChan is the channel to send/receive watch subscription requests.
-		cmdWatchChan             chan dstore.CmdWatchEvent                   // cmdWatchChan is the channel to send/receive watch events.
+		querySubscriptionMap     map[string]map[uint32]struct{}            // querySubscriptionMap is a map of Key -> [fingerprint1, fingerprint2, ...]
+		tcpSubscriptionMap       map[uint32]map[chan Notification]struct{} // tcpSubscriptionMap is a map of fingerprint -> [client1Chan, client2Chan, ...]
+		fingerprintCmdMap        map[uint32]*cmd.DiceDBCmd                 // fingerprintCmdMap is a map of fingerprint -> DiceDBCmd
+		cmdWatchSubscriptionChan chan WatchSubscription                    // cmdWatchSubscriptionChan is the channel to send/receive watch subscription requests.
+		cmdWatchChan             chan dstore.CmdWatchEvent                 // cmdWatchChan is the channel to send/receive watch events.
+		bounds                   map[uint32]map[chan Notification]*bound   // bounds is a map of fingerprint -> client -> lag bound, for the bounded subscriptions
+		lagging                  int                                       // lagging is the number of bounded subscriptions whose bound the lag exceeds
+		lag                      func() (time.Duration, string, bool)      // lag returns the lag of the replica behind its upstream, see bridge.Lag
 	}
 )
 
+// lagCheckInterval is the interval at which the lag of the replica is checked against the bounds of the subscriptions
+const lagCheckInterval = 100 * time.Millisecond
+
 var (
 	affectedCmdMap = map[string]map[string]struct{}{
 		dstore.Set:     {dstore.Get: struct{}{}},
@@ -50,16 +78,19 @@ var (
 		dstore.ZAdd:    {dstore.ZRange: struct{}{}},
 		dstore.PFADD:   {dstore.PFCOUNT: struct{}{}},
 		dstore.PFMERGE: {dstore.PFCOUNT: struct{}{}},
+		dstore.Expired: {dstore.Get: struct{}{}, dstore.ZRange: struct{}{}, dstore.PFCOUNT: struct{}{}},
 	}
 )
 
 func NewManager(cmdWatchSubscriptionChan chan WatchSubscription, cmdWatchChan chan dstore.CmdWatchEvent) *Manager {
 	return &Manager{
 		querySubscriptionMap:     make(map[string]map[uint32]struct{}),
-		tcpSubscriptionMap:       make(map[uint32]map[chan *cmd.DiceDBCmd]struct{}),
+		tcpSubscriptionMap:       make(map[uint32]map[chan Notification]struct{}),
 		fingerprintCmdMap:        make(map[uint32]*cmd.DiceDBCmd),
 		cmdWatchSubscriptionChan: cmdWatchSubscriptionChan,
 		cmdWatchChan:             cmdWatchChan,
+		bounds:                   make(map[uint32]map[chan Notification]*bound),
+		lag:                      bridge.Lag,
 	}
 }
 
@@ -79,10 +110,15 @@ func (m *Manager) Run(ctx context.Context) {
 }
 
 func (m *Manager) listenForEvents(ctx context.Context) {
+	ticker := time.NewTicker(lagCheckInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-ticker.C:
+			m.checkLags()
 		case sub := <-m.cmdWatchSubscriptionChan:
 			if sub.Subscribe {
 				m.handleSubscription(sub)
@@ -95,6 +131,25 @@ func (m *Manager) listenForEvents(ctx context.Context) {
 	}
 }
 
+// Pump handles the subscription requests and the events pending, without waiting for more, and returns
+// how many it handled. It lets a scheduler drive the manager in place of Run.
+func (m *Manager) Pump() int {
+	for n := 0; ; n++ {
+		select {
+		case sub := <-m.cmdWatchSubscriptionChan:
+			if sub.Subscribe {
+				m.handleSubscription(sub)
+			} else {
+				m.handleUnsubscription(sub)
+			}
+		case watchEvent := <-m.cmdWatchChan:
+			m.handleWatchEvent(watchEvent)
+		default:
+			return n
+		}
+	}
+}
+
 // handleSubscription processes a new subscription request
 func (m *Manager) handleSubscription(sub WatchSubscription) {
 	fingerprint := sub.WatchCmd.GetFingerprint()
@@ -111,18 +166,52 @@ func (m *Manager) handleSubscription(sub WatchSubscription) {
 
 	// Add client channel to tcpSubscriptionMap
 	if _, exists := m.tcpSubscriptionMap[fingerprint]; !exists {
-		m.tcpSubscriptionMap[fingerprint] = make(map[chan *cmd.DiceDBCmd]struct{})
+		m.tcpSubscriptionMap[fingerprint] = make(map[chan Notification]struct{})
+	}
+	if _, exists := m.tcpSubscriptionMap[fingerprint][sub.AdhocReqChan]; !exists {
+		m.tcpSubscriptionMap[fingerprint][sub.AdhocReqChan] = struct{}{}
+		stats.WatchSubscribed(len(m.fingerprintCmdMap))
+	}
+
+	// Subscribing again replaces the bound of the subscription
+	m.unbound(fingerprint, sub.AdhocReqChan)
+	if sub.MaxLag > 0 {
+		if _, exists := m.bounds[fingerprint]; !exists {
+			m.bounds[fingerprint] = make(map[chan Notification]*bound)
+		}
+		m.bounds[fingerprint][sub.AdhocReqChan] = &bound{maxLag: sub.MaxLag}
+	}
+}
+
+// unbound removes the lag bound of the subscription of the client, if any.
+func (m *Manager) unbound(fingerprint uint32, client chan Notification) {
+	clients, ok := m.bounds[fingerprint]
+	if !ok {
+		return
+	}
+	if b, ok := clients[client]; ok {
+		if b.lagging {
+			m.lagging--
+			stats.WatchLagging(m.lagging)
+		}
+		delete(clients, client)
+	}
+	if len(clients) == 0 {
+		delete(m.bounds, fingerprint)
 	}
-	m.tcpSubscriptionMap[fingerprint][sub.AdhocReqChan] = struct{}{}
 }
 
 // handleUnsubscription processes an unsubscription request
 func (m *Manager) handleUnsubscription(sub WatchSubscription) {
 	fingerprint := sub.Fingerprint
+	m.unbound(fingerprint, sub.AdhocReqChan)
 
 	// Remove clientID from tcpSubscriptionMap
 	if clients, ok := m.tcpSubscriptionMap[fingerprint]; ok {
-		delete(clients, sub.AdhocReqChan)
+		if _, subscribed := clients[sub.AdhocReqChan]; subscribed {
+			delete(clients, sub.AdhocReqChan)
+			defer func() { stats.WatchUnsubscribed(len(m.fingerprintCmdMap)) }()
+		}
 		// If there are no more clients listening to this fingerprint, remove it from the map
 		if len(clients) == 0 {
 			// Remove the fingerprint from tcpSubscriptionMap
@@ -150,8 +239,20 @@ func (m *Manager) handleUnsubscription(sub WatchSubscription) {
 }
 
 func (m *Manager) handleWatchEvent(event dstore.CmdWatchEvent) {
+	if event.AffectedKeys == nil {
+		m.handleKeyChange(event, event.AffectedKey)
+		return
+	}
+	// The keys changed by a batch of writes are handled in turn
+	for _, key := range event.AffectedKeys {
+		m.handleKeyChange(event, key)
+	}
+}
+
+// handleKeyChange notifies the clients watching the commands affected by the change of the key.
+func (m *Manager) handleKeyChange(event dstore.CmdWatchEvent, key string) {
 	// Check if any watch commands are listening to updates on this key.
-	fingerprints, exists := m.querySubscriptionMap[event.AffectedKey]
+	fingerprints, exists := m.querySubscriptionMap[key]
 	if !exists {
 		return
 	}
@@ -171,13 +272,13 @@ func (m *Manager) handleWatchEvent(event dstore.CmdWatchEvent) {
 		// helps us handle cases where a key might get updated by an unrelated command which makes it
 		// incompatible with the watched command.
 		if _, affected := affectedCommands[cmdToExecute.Cmd]; affected {
-			m.notifyClients(fingerprint, cmdToExecute)
+			m.notifyClients(fingerprint, Notification{Cmd: cmdToExecute, ChangedAt: event.ChangedAt})
 		}
 	}
 }
 
-// notifyClients sends cmd to all clients listening to this fingerprint, so that they can execute it.
-func (m *Manager) notifyClients(fingerprint uint32, diceDBCmd *cmd.DiceDBCmd) {
+// notifyClients sends the notification to all clients listening to this fingerprint, so that they can execute its command.
+func (m *Manager) notifyClients(fingerprint uint32, notification Notification) {
 	clients, exists := m.tcpSubscriptionMap[fingerprint]
 	if !exists {
 		slog.Warn("No clients found for fingerprint",
@@ -186,6 +287,45 @@ func (m *Manager) notifyClients(fingerprint uint32, diceDBCmd *cmd.DiceDBCmd) {
 	}
 
 	for clientChan := range clients {
-		clientChan <- diceDBCmd
+		if _, drop := failpoint.Eval(failpoint.WatchDrop); drop {
+			continue
+		}
+		// The subscribers whose results are stale run their command again once the replica catches up
+		if b, ok := m.bounds[fingerprint][clientChan]; ok && b.lagging {
+			continue
+		}
+		metrics.WatchUpdateQueued(len(clientChan))
+		clientChan <- notification
+	}
+}
+
+// checkLags checks the lag of the replica behind its upstream against the bounds of the subscriptions. The
+// subscribers whose bound the lag exceeds are told that their results are stale, and the ones whose bound the
+// lag is back within run their command again, as the changes were not notified in between.
+func (m *Manager) checkLags() {
+	if len(m.bounds) == 0 {
+		return
+	}
+	lag, upstream, _ := m.lag()
+
+	for fingerprint, clients := range m.bounds {
+		for clientChan, b := range clients {
+			lagging := lag > b.maxLag
+			if lagging == b.lagging {
+				continue
+			}
+			b.lagging = lagging
+
+			notification := Notification{Cmd: m.fingerprintCmdMap[fingerprint], ChangedAt: time.Now()}
+			if lagging {
+				m.lagging++
+				notification.Lag = lag
+				notification.Upstream = upstream
+			} else {
+				m.lagging--
+			}
+			stats.WatchLagging(m.lagging)
+			clientChan <- notification
+		}
 	}
 }
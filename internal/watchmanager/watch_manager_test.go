@@ -0,0 +1,75 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package watchmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/stats"
+	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLagBound(t *testing.T) {
+	m := NewManager(make(chan WatchSubscription), make(chan dstore.CmdWatchEvent))
+	lag := time.Duration(0)
+	m.lag = func() (time.Duration, string, bool) { return lag, "upstream:6379", true }
+
+	watched := &cmd.DiceDBCmd{Cmd: dstore.Get, Args: []string{"k"}}
+	bounded := make(chan Notification, 10)
+	unbounded := make(chan Notification, 10)
+	m.handleSubscription(WatchSubscription{Subscribe: true, WatchCmd: watched, AdhocReqChan: bounded, MaxLag: time.Second})
+	m.handleSubscription(WatchSubscription{Subscribe: true, WatchCmd: watched, AdhocReqChan: unbounded})
+	change := dstore.CmdWatchEvent{Cmd: dstore.Set, AffectedKey: "k", ChangedAt: time.Now()}
+
+	// Within the bound, both subscribers are notified of the changes
+	m.checkLags()
+	m.handleWatchEvent(change)
+	require.Len(t, bounded, 1)
+	require.Len(t, unbounded, 1)
+	assert.Zero(t, (<-bounded).Lag)
+	<-unbounded
+
+	// Beyond the bound, the bounded subscriber is told once that its results are stale, and is not notified of
+	// the changes anymore
+	lag = 2 * time.Second
+	m.checkLags()
+	m.checkLags()
+	m.handleWatchEvent(change)
+	require.Len(t, bounded, 1)
+	notification := <-bounded
+	assert.Equal(t, 2*time.Second, notification.Lag)
+	assert.Equal(t, "upstream:6379", notification.Upstream)
+	assert.Same(t, watched, notification.Cmd)
+	assert.Len(t, unbounded, 1)
+	<-unbounded
+	assert.EqualValues(t, 1, stats.Get().Watch.Lagging)
+
+	// Once the replica caught up, the bounded subscriber runs its command again
+	lag = 0
+	m.checkLags()
+	require.Len(t, bounded, 1)
+	assert.Zero(t, (<-bounded).Lag)
+	assert.EqualValues(t, 0, stats.Get().Watch.Lagging)
+
+	// Unsubscribing removes the bound
+	m.handleUnsubscription(WatchSubscription{AdhocReqChan: bounded, Fingerprint: watched.GetFingerprint()})
+	assert.Empty(t, m.bounds)
+}
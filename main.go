@@ -21,20 +21,36 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
 	"runtime/pprof"
 	"runtime/trace"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/dicedb/dice/internal/server/grpcsrv"
 	"github.com/dicedb/dice/internal/server/httpws"
 
+	"github.com/dicedb/dice/internal/audit"
+	"github.com/dicedb/dice/internal/backup"
+	"github.com/dicedb/dice/internal/bigkeys"
+	"github.com/dicedb/dice/internal/bridge"
+	"github.com/dicedb/dice/internal/cache"
 	"github.com/dicedb/dice/internal/cli"
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/connector"
+	"github.com/dicedb/dice/internal/diagnostics"
+	"github.com/dicedb/dice/internal/eval"
+	"github.com/dicedb/dice/internal/handover"
+	"github.com/dicedb/dice/internal/importer"
 	"github.com/dicedb/dice/internal/logger"
+	"github.com/dicedb/dice/internal/module"
+	"github.com/dicedb/dice/internal/namespace"
 	"github.com/dicedb/dice/internal/server/abstractserver"
 	"github.com/dicedb/dice/internal/wal"
 	"github.com/dicedb/dice/internal/watchmanager"
@@ -42,26 +58,94 @@ import (
 	"github.com/dicedb/dice/config"
 	diceerrors "github.com/dicedb/dice/internal/errors"
 	"github.com/dicedb/dice/internal/iothread"
+	"github.com/dicedb/dice/internal/metrics"
 	"github.com/dicedb/dice/internal/observability"
+	"github.com/dicedb/dice/internal/sdnotify"
+	"github.com/dicedb/dice/internal/sentinel"
 	"github.com/dicedb/dice/internal/server/resp"
 	"github.com/dicedb/dice/internal/shard"
+	"github.com/dicedb/dice/internal/shutdown"
+	"github.com/dicedb/dice/internal/stats"
 	dstore "github.com/dicedb/dice/internal/store"
+	"github.com/dicedb/dice/internal/tracing"
 )
 
 const (
 	WALEngineAOF = "aof"
 )
 
+// statusReady is the status of the server reported to the service manager while it accepts connections
+const statusReady = "accepting connections"
+
 func main() {
+	// Deferred first so that it runs last, once every other deferred cleanup is done
+	exitCode := shutdown.ExitOK
+	defer func() {
+		if exitCode != shutdown.ExitOK {
+			os.Exit(exitCode)
+		}
+	}()
+
 	iid := observability.GetOrCreateInstanceID()
-	config.DiceConfig.InstanceID = iid
+	config.DiceConfig().InstanceID = iid
 	slog.SetDefault(logger.New())
 	cli.Execute()
+	if err := cmd.LoadCommandRenames(); err != nil {
+		slog.Error("invalid command renames", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if err := audit.Init(); err != nil {
+		slog.Error("could not initialize audit log", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer audit.Close()
+	if err := namespace.Load(); err != nil {
+		slog.Error("invalid namespaces", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if err := module.Load(); err != nil {
+		slog.Error("could not load the modules", slog.Any("error", err))
+		os.Exit(1)
+	}
+	config.OnParameterChange("logging.log_level", func() { slog.SetDefault(logger.New()) })
+
+	// A sentinel monitors the servers in place of being one
+	if config.DiceConfig().Sentinel.Enabled {
+		if err := runSentinel(); err != nil {
+			slog.Error("the sentinel stopped", slog.Any("error", err))
+			exitCode = 1
+		}
+		return
+	}
+
+	// A process started by a handover serves on the listeners of the previous one
+	if err := handover.Init(); err != nil {
+		slog.Error("could not receive the listeners handed over", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// Report misconfigurations of the environment before they surface as obscure runtime failures. The ports
+	// handed over are still held by the previous process.
+	diagnostics.Run(diagnostics.Options{CheckPorts: !handover.Inherited()}).Log()
 	go observability.Ping()
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Handle SIGTERM and SIGINT
+	shutdownTracing, err := tracing.Init(ctx)
+	if err != nil {
+		slog.Error("could not initialize tracing", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer func() {
+		// Flush the spans still buffered by the exporter
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelShutdown()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Warn("could not flush pending spans", slog.Any("error", err))
+		}
+	}()
+
+	// Handle SIGTERM and SIGINT, a second one exiting at once in place of waiting for the shutdown
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
 
@@ -70,20 +154,29 @@ func main() {
 		serverErrCh              = make(chan error, 2)
 		cmdWatchSubscriptionChan = make(chan watchmanager.WatchSubscription)
 		wl                       wal.AbstractWAL
+		archiver                 *backup.Archiver
 	)
 
 	wl, _ = wal.NewNullWAL()
-	if config.DiceConfig.Persistence.Enabled {
-		if config.DiceConfig.Persistence.WALEngine == WALEngineAOF {
-			_wl, err := wal.NewAOFWAL(config.DiceConfig.WAL.LogDir)
+	if config.DiceConfig().Persistence.Enabled {
+		if config.DiceConfig().Persistence.WALEngine == WALEngineAOF {
+			_wl, err := wal.NewAOFWAL(config.DiceConfig().WAL.LogDir)
 			if err != nil {
-				slog.Warn("could not create WAL with", slog.String("wal-engine", config.DiceConfig.Persistence.WALEngine), slog.Any("error", err))
+				slog.Warn("could not create WAL with", slog.String("wal-engine", config.DiceConfig().Persistence.WALEngine), slog.Any("error", err))
 				sigs <- syscall.SIGKILL
 				return
 			}
+			// The rotated segments are queued for their upload, which starts once the backups are scheduled
+			if config.DiceConfig().Backup.ArchiveWAL {
+				if archiver, err = newArchiver(); err != nil {
+					slog.Error("could not archive the WAL", slog.Any("error", err))
+					os.Exit(1)
+				}
+				_wl.OnRotate(archiver.Archive)
+			}
 			wl = _wl
 		} else {
-			slog.Error("unsupported WAL engine", slog.String("engine", config.DiceConfig.Persistence.WALEngine))
+			slog.Error("unsupported WAL engine", slog.String("engine", config.DiceConfig().Persistence.WALEngine))
 			sigs <- syscall.SIGKILL
 			return
 		}
@@ -96,15 +189,15 @@ func main() {
 
 		slog.Debug("WAL initialization complete")
 
-		if config.DiceConfig.Persistence.RestoreFromWAL {
+		if config.DiceConfig().Persistence.RestoreFromWAL {
 			slog.Info("restoring database from WAL")
 			wal.ReplayWAL(wl)
 			slog.Info("database restored from WAL")
 		}
 	}
 
-	if config.DiceConfig.Performance.EnableWatch {
-		bufSize := config.DiceConfig.Performance.WatchChanBufSize
+	if config.DiceConfig().Performance.EnableWatch {
+		bufSize := config.DiceConfig().Performance.WatchChanBufSize
 		cmdWatchChan = make(chan dstore.CmdWatchEvent, bufSize)
 	}
 
@@ -114,8 +207,8 @@ func main() {
 	// core count ensures the application can make full use of all available hardware.
 	var numShards int
 	numShards = runtime.NumCPU()
-	if config.DiceConfig.Performance.NumShards > 0 {
-		numShards = config.DiceConfig.Performance.NumShards
+	if config.DiceConfig().Performance.NumShards > 0 {
+		numShards = config.DiceConfig().Performance.NumShards
 	}
 
 	// The runtime.GOMAXPROCS(numShards) call limits the number of operating system
@@ -126,18 +219,84 @@ func main() {
 
 	// Initialize the ShardManager
 	shardManager := shard.NewShardManager(uint8(numShards), cmdWatchChan, serverErrCh)
+	if config.DiceConfig().Journal.Enabled {
+		if err := shardManager.OpenJournals(config.DiceConfig().Journal.Dir); err != nil {
+			slog.Error("could not open the journals", slog.String("dir", config.DiceConfig().Journal.Dir), slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	if config.DiceConfig().DiskTier.Enabled {
+		if err := shardManager.OpenDiskTiers(config.DiceConfig().DiskTier.Dir, config.DiceConfig().DiskTier.HotKeys); err != nil {
+			slog.Error("could not open the disk tiers", slog.String("dir", config.DiceConfig().DiskTier.Dir), slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	// The cache subscribes to the writes of the keys before the shards run
+	var cacheManager *cache.Manager
+	if config.DiceConfig().Cache.Enabled {
+		opts := cache.Options{Patterns: config.DiceConfig().Cache.Patterns, Timeout: config.DiceConfig().Cache.Timeout}
+		if config.DiceConfig().Cache.LoaderURL != "" {
+			opts.Loader = cache.NewHTTPLoader(config.DiceConfig().Cache.LoaderURL,
+				time.Duration(config.DiceConfig().Cache.TTL)*time.Second)
+		}
+		if config.DiceConfig().Cache.WriterURL != "" {
+			opts.Writer = cache.NewHTTPWriter(config.DiceConfig().Cache.WriterURL)
+		}
+		if cacheManager, err = cache.New(shardManager, opts); err != nil {
+			slog.Error("could not start the cache", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	// The shards are stopped only once every frontend is, so that they can drain their queues
+	shardCtx, cancelShards := context.WithCancel(ctx)
+	defer cancelShards()
 
 	wg := sync.WaitGroup{}
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		shardManager.Run(ctx)
+		shardManager.Run(shardCtx)
 	}()
 
+	// A node restoring a backup accepts no clients until its keys are imported, unless it restores them in the
+	// background, the commands on the keys not restored yet failing with LOADING meanwhile
+	stopRestore := func() {}
+	if source := config.DiceConfig().Backup.RestoreFrom; source != "" {
+		if config.DiceConfig().Backup.RestoreLazy {
+			stopRestore = restoreBackupLazily(ctx, source, shardManager)
+		} else {
+			notify(sdnotify.Status("restoring the backup " + source))
+			if err := restoreBackup(ctx, source, shardManager); err != nil {
+				slog.Error("could not restore the backup", slog.String("from", source), slog.Any("error", err))
+				os.Exit(1)
+			}
+		}
+	}
+
+	// A node warming up accepts no clients until the keys of the source are copied
+	if config.DiceConfig().Warm.From != "" {
+		notify(sdnotify.Status("warming up from " + config.DiceConfig().Warm.From))
+		warmCtx, stopWarm := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+		err := bridge.Warm(warmCtx, bridge.Options{
+			Addr:     config.DiceConfig().Warm.From,
+			Password: config.DiceConfig().Warm.Password,
+			DB:       config.DiceConfig().Warm.DB,
+			Patterns: config.DiceConfig().Warm.Patterns,
+		}, shardManager)
+		stopWarm()
+		if err != nil {
+			slog.Error("could not warm the store up, starting with the keys copied so far",
+				slog.String("from", config.DiceConfig().Warm.From), slog.Any("error", err))
+		}
+	}
+
 	var serverWg sync.WaitGroup
 
-	if config.DiceConfig.Performance.EnableProfiling {
+	if config.DiceConfig().Performance.EnableProfiling {
 		stopProfiling, err := startProfiling()
 		if err != nil {
 			slog.Error("Profiling could not be started", slog.Any("error", err))
@@ -145,52 +304,457 @@ func main() {
 		}
 		defer stopProfiling()
 	}
-	ioThreadManager := iothread.NewManager(config.DiceConfig.Performance.MaxClients, shardManager)
+
+	// Frontends are stopped in the order they are started
+	var frontends []*frontend
+	ioThreadManager := iothread.NewManager(config.DiceConfig().Performance.MaxClients, shardManager)
 	respServer := resp.NewServer(shardManager, ioThreadManager, cmdWatchSubscriptionChan, cmdWatchChan, serverErrCh, wl)
-	serverWg.Add(1)
-	go runServer(ctx, &serverWg, respServer, serverErrCh)
+	frontends = append(frontends, startFrontend(ctx, &serverWg, respServer, serverErrCh))
 
-	if config.DiceConfig.HTTP.Enabled {
+	if config.DiceConfig().HTTP.Enabled {
 		httpServer := httpws.NewHTTPServer(shardManager, wl)
-		serverWg.Add(1)
-		go runServer(ctx, &serverWg, httpServer, serverErrCh)
+		frontends = append(frontends, startFrontend(ctx, &serverWg, httpServer, serverErrCh))
 	}
 
-	if config.DiceConfig.WebSocket.Enabled {
-		websocketServer := httpws.NewWebSocketServer(shardManager, config.DiceConfig.WebSocket.Port, wl)
-		serverWg.Add(1)
-		go runServer(ctx, &serverWg, websocketServer, serverErrCh)
+	if config.DiceConfig().WebSocket.Enabled {
+		websocketServer := httpws.NewWebSocketServer(shardManager, ioThreadManager, cmdWatchSubscriptionChan, serverErrCh, config.DiceConfig().WebSocket.Port, wl)
+		frontends = append(frontends, startFrontend(ctx, &serverWg, websocketServer, serverErrCh))
 	}
 
-	wg.Add(1)
+	if config.DiceConfig().GRPC.Enabled {
+		grpcServer := grpcsrv.NewServer(shardManager, ioThreadManager, cmdWatchSubscriptionChan, serverErrCh, wl)
+		frontends = append(frontends, startFrontend(ctx, &serverWg, grpcServer, serverErrCh))
+	}
+
+	if config.DiceConfig().Metrics.Enabled {
+		metricsServer := metrics.NewServer()
+		frontends = append(frontends, startFrontend(ctx, &serverWg, metricsServer, serverErrCh))
+	}
+
+	if cacheManager != nil {
+		frontends = append(frontends, startFrontend(ctx, &serverWg, cacheManager, serverErrCh))
+	}
+
+	// execOnShard executes a command of a background task on a shard, the client being the name of the task
+	execOnShard := func(client string) func(ctx context.Context, id uint8, c *cmd.DiceDBCmd) (interface{}, error) {
+		return func(ctx context.Context, id uint8, c *cmd.DiceDBCmd) (interface{}, error) {
+			resps, err := shardManager.ExecBatch(ctx, &shard.Batch{ClientAddr: client, Cmds: map[shard.ShardID][]*cmd.DiceDBCmd{id: {c}}})
+			if err != nil {
+				return nil, err
+			}
+			return resps[id][0].Result, resps[id][0].Error
+		}
+	}
+
+	// The big keys are analyzed on BIGKEYS SCAN, and every bigkeys.interval when set
+	analyzer := bigkeys.New(numShards, execOnShard("bigkeys"))
+	frontends = append(frontends, startFrontend(ctx, &serverWg, analyzer, serverErrCh))
+
+	if config.DiceConfig().Backup.Enabled {
+		target, err := backup.NewTarget()
+		if err == nil {
+			var scheduler *backup.Scheduler
+			if scheduler, err = backup.New(numShards, execOnShard("backup"), target, config.DiceConfig().Backup.Schedule); err == nil {
+				frontends = append(frontends, startFrontend(ctx, &serverWg, scheduler, serverErrCh))
+			}
+		}
+		if err != nil {
+			slog.Error("could not schedule the backups", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+	if archiver != nil {
+		frontends = append(frontends, startFrontend(ctx, &serverWg, archiver, serverErrCh))
+	}
+
+	if config.DiceConfig().Bridge.Enabled {
+		b := bridge.New(bridge.Options{
+			Addr:     config.DiceConfig().Bridge.Addr,
+			Password: config.DiceConfig().Bridge.Password,
+			DB:       config.DiceConfig().Bridge.DB,
+			Patterns: config.DiceConfig().Bridge.Patterns,
+			Mode:     config.DiceConfig().Bridge.Mode,
+			// The upstream lists its replicas with the port they serve clients on
+			ListeningPort: config.DiceConfig().RespServer.Port,
+		}, shardManager)
+		frontends = append(frontends, startFrontend(ctx, &serverWg, b, serverErrCh))
+	}
+
+	if config.DiceConfig().Connectors.Enabled {
+		if config.DiceConfig().Performance.EnableWatch {
+			connectors := connector.NewManager(shardManager, cmdWatchSubscriptionChan, connector.Options{
+				Timeout:      config.DiceConfig().Connectors.Timeout,
+				Retries:      config.DiceConfig().Connectors.Retries,
+				RetryBackoff: config.DiceConfig().Connectors.RetryBackoff,
+				MaxFailures:  config.DiceConfig().Connectors.MaxFailures,
+			})
+			frontends = append(frontends, startFrontend(ctx, &serverWg, connectors, serverErrCh))
+		} else {
+			slog.Warn("connectors publish the updates of watched commands, they are disabled as watch is")
+		}
+	}
+
+	// Tell the previous process, if any, that the servers serve on the listeners it handed over, and the service
+	// manager that the server accepts connections. The signals reloading the server or handing it over are only
+	// handled from then on.
+	serving := make(chan struct{})
 	go func() {
-		defer wg.Done()
-		<-sigs
-		cancel()
+		if !waitListening(ctx, frontends) {
+			return
+		}
+		readyCtx, cancelReady := context.WithTimeout(ctx, config.DiceConfig().Upgrade.ReadyTimeout)
+		defer cancelReady()
+		handover.Ready(readyCtx)
+		notify(sdnotify.Ready, sdnotify.Status(statusReady))
+		close(serving)
+	}()
+
+	// Hand the listeners over to a new process of the server on SIGUSR2, e.g. once the binary is upgraded.
+	// This process then serves its clients until they disconnect or the drain timeout elapses, and exits.
+	upgraded := make(chan struct{})
+	usr2 := make(chan os.Signal, 1)
+	signal.Notify(usr2, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(usr2)
+		select {
+		case <-ctx.Done():
+			return
+		case <-serving:
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-usr2:
+				slog.Info("received SIGUSR2, handing the listeners over to a new process")
+				notify(sdnotify.Reloading, sdnotify.Status("handing the listeners over to a new process"))
+				pid, err := handover.Start(ctx, config.DiceConfig().Upgrade.ReadyTimeout)
+				if err != nil {
+					slog.Error("could not hand the listeners over", slog.Any("error", err))
+					notify(sdnotify.Ready, sdnotify.Status(statusReady))
+					continue
+				}
+				// The new process is the main process of the service from then on, this one only drains its clients
+				notify(sdnotify.MainPID(pid), sdnotify.Ready, sdnotify.Status(statusReady))
+				drainClients(ctx, ioThreadManager, config.DiceConfig().Upgrade.DrainTimeout)
+				close(upgraded)
+				return
+			}
+		}
+	}()
+
+	// Reload the hot-reloadable settings from the config file on SIGHUP
+	hups := make(chan os.Signal, 1)
+	signal.Notify(hups, syscall.SIGHUP)
+	go func() {
+		select {
+		case <-ctx.Done():
+			signal.Stop(hups)
+			return
+		case <-serving:
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				signal.Stop(hups)
+				return
+			case <-hups:
+				slog.Info("received SIGHUP, reloading config", slog.String("path", config.ConfigFilePath))
+				notify(sdnotify.Reloading, sdnotify.Status("reloading the config"))
+				if err := config.Reload(); err != nil {
+					slog.Error("could not reload config", slog.Any("error", err))
+				}
+				notify(sdnotify.Ready, sdnotify.Status(statusReady))
+			}
+		}
 	}()
 
 	go func() {
 		serverWg.Wait()
-		close(serverErrCh) // Close the channel when both servers are done
+		close(serverErrCh) // Close the channel when all servers are done
+	}()
+
+	req := waitForShutdown(sigs, serverErrCh, upgraded)
+	slog.Info("shutting down", slog.Bool("save", req.ShouldSave()))
+	// Once the listeners are handed over, the service is the new process, which is not stopping
+	if !handover.Draining() {
+		notify(sdnotify.Stopping, sdnotify.Status("shutting down"))
+	}
+	go func() {
+		sig := <-sigs
+		slog.Warn("received a second signal, exiting without waiting for the shutdown", slog.String("signal", sig.String()))
+		os.Exit(shutdown.ExitForced)
 	}()
 
-	for err := range serverErrCh {
-		if err != nil && errors.Is(err, diceerrors.ErrAborted) {
-			// if either the AsyncServer/RESPServer or the HTTPServer received an abort command,
-			// cancel the context, helping gracefully exiting all servers
-			cancel()
+	// Errors reported while stopping are only logged by runServer
+	go func() {
+		for range serverErrCh {
 		}
+	}()
+
+	for _, f := range frontends {
+		f.stop()
 	}
 
-	close(sigs)
+	stopRestore()
+	cancelShards()
+	wg.Wait()
+
+	if req.ShouldSave() {
+		if err := shardManager.SaveSnapshot(); err != nil {
+			slog.Error("could not save the dataset", slog.Any("error", err))
+			exitCode = shutdown.ExitSaveFailed
+		}
+	}
 
-	if config.DiceConfig.Persistence.Enabled {
+	if config.DiceConfig().Persistence.Enabled {
 		wal.ShutdownBG()
+		if err := wl.Close(); err != nil {
+			slog.Warn("could not close the WAL", slog.Any("error", err))
+		}
 	}
 
+	signal.Stop(sigs)
 	cancel()
+}
 
-	wg.Wait()
+// runSentinel monitors the leader of the sentinel configuration until SIGTERM or SIGINT is received.
+func runSentinel() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	s := sentinel.New(sentinel.Options{
+		Addr:          net.JoinHostPort(config.DiceConfig().Sentinel.Addr, strconv.Itoa(config.DiceConfig().Sentinel.Port)),
+		Name:          config.DiceConfig().Sentinel.Name,
+		Leader:        config.DiceConfig().Sentinel.Leader,
+		Password:      config.DiceConfig().Sentinel.Password,
+		DownAfter:     config.DiceConfig().Sentinel.DownAfter,
+		CheckInterval: config.DiceConfig().Sentinel.CheckInterval,
+	})
+	if err := s.Run(ctx); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// waitForShutdown blocks until the server is asked to shut down, with SHUTDOWN or ABORT from any
+// frontend or with a signal, until every frontend has stopped on its own, or until the listeners are
+// handed over to a new process and drained. The dataset is not saved once handed over, as the new
+// process writes the persistence files from then on.
+func waitForShutdown(sigs <-chan os.Signal, serverErrCh <-chan error, upgraded <-chan struct{}) *shutdown.Request {
+	for {
+		select {
+		case <-upgraded:
+			slog.Info("the listeners are handed over to the new process")
+			return &shutdown.Request{Mode: shutdown.NoSave}
+		case sig := <-sigs:
+			slog.Info("received signal", slog.String("signal", sig.String()))
+			return &shutdown.Request{Mode: shutdown.Default}
+		case err, ok := <-serverErrCh:
+			if !ok {
+				return &shutdown.Request{Mode: shutdown.Default}
+			}
+			var req *shutdown.Request
+			if errors.As(err, &req) {
+				return req
+			}
+			if errors.Is(err, diceerrors.ErrAborted) {
+				return &shutdown.Request{Mode: shutdown.NoSave}
+			}
+		}
+	}
+}
+
+// waitListening waits for the frontends accepting connections to listen, a frontend that stops in place of
+// listening not being waited for. It returns false if the context is canceled first.
+func waitListening(ctx context.Context, frontends []*frontend) bool {
+	for _, f := range frontends {
+		l, ok := f.srv.(abstractserver.Listening)
+		if !ok {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-l.Listening():
+		case <-f.done:
+		}
+	}
+	return true
+}
+
+// newArchiver returns the archiver of the WAL segments to the target of the backups.
+func newArchiver() (*backup.Archiver, error) {
+	target, err := backup.NewTarget()
+	if err != nil {
+		return nil, err
+	}
+	return backup.NewArchiver(target), nil
+}
+
+// restoreBackup imports the keys of the backup at the source into the shards, which must be running.
+func restoreBackup(ctx context.Context, source string, manager *shard.ShardManager) error {
+	r, err := backup.Open(ctx, source)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	backup.SetLoading(true, 0)
+	defer backup.SetLoading(false, 0)
+
+	progress, err := importer.Run(ctx, r, &restoreShards{manager: manager}, restoreOptions(),
+		func(p importer.Progress) error {
+			slog.Info("restoring the backup", slog.Int64("keys", p.Applied))
+			backup.SetLoading(true, p.Applied)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	if progress.Filtered > 0 {
+		slog.Info("skipped the keys of the backup matching none of the restore patterns", slog.Int64("skipped", progress.Filtered))
+	}
+	if progress.Failed > 0 {
+		slog.Warn("some keys of the backup could not be restored", slog.Int64("failed", progress.Failed),
+			slog.Int64("corrupted", progress.Corrupted), slog.Any("errors", progress.Errors))
+	}
+	if progress.Discarded > 0 {
+		slog.Warn("the backup was restored up to its first corrupt record", slog.Int64("record", progress.Position),
+			slog.Int64("discarded", progress.Discarded))
+	}
+	slog.Info("restored the backup", slog.String("from", source), slog.Int64("keys", progress.Applied))
+	return nil
+}
+
+// restoreOptions returns the options of the import of the backup restored.
+func restoreOptions() importer.Options {
+	return importer.Options{
+		Format:   importer.FormatJSON,
+		Recovery: importer.Recovery(config.DiceConfig().Backup.RecoveryMode),
+		Patterns: config.DiceConfig().Backup.RestorePatterns,
+	}
+}
+
+// restoreBackupLazily indexes the keys of the backup at the source, then restores them in the background while the
+// clients are accepted, and returns the function interrupting the restore and waiting for it to stop. Until it is
+// restored, a key of the backup is refused with LOADING. A restore failing leaves the server running with the keys
+// restored so far, reported degraded by INFO and /health.
+func restoreBackupLazily(ctx context.Context, source string, manager *shard.ShardManager) func() {
+	keys, err := indexBackup(ctx, source)
+	if err != nil {
+		restoreFailed(source, err)
+		return func() {}
+	}
+	slog.Info("restoring the backup in the background", slog.String("from", source), slog.Int("keys", len(keys)))
+	manager.StartLoading(keys)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := restoreBackup(ctx, source, manager)
+		manager.StopLoading()
+		if err != nil && ctx.Err() == nil {
+			restoreFailed(source, err)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// indexBackup returns the keys of the backup at the source that are restored.
+func indexBackup(ctx context.Context, source string) ([]string, error) {
+	r, err := backup.Open(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return importer.Keys(ctx, r, restoreOptions())
+}
+
+// restoreFailed reports the failure of the restore of a backup in the background, the clients being accepted already.
+func restoreFailed(source string, err error) {
+	slog.Error("could not restore the backup, the keys it did not restore are missing",
+		slog.String("from", source), slog.Any("error", err))
+	backup.LoadFailed(err)
+	stats.RestoreFailed()
+}
+
+// restoreShards executes the commands restoring a backup.
+type restoreShards struct {
+	manager *shard.ShardManager
+}
+
+func (s *restoreShards) Route(key string) uint8 {
+	id, _ := s.manager.GetShardInfo(key)
+	return id
+}
+
+func (s *restoreShards) Exec(ctx context.Context, cmds map[uint8][]*cmd.DiceDBCmd) (map[uint8][]*eval.EvalResponse, error) {
+	resps, err := s.manager.ExecBatch(ctx, &shard.Batch{ClientAddr: "restore", Cmds: cmds})
+	if err != nil {
+		return nil, err
+	}
+	for _, shardCmds := range cmds {
+		for _, c := range shardCmds {
+			s.manager.Loaded(cmd.Keys(c))
+		}
+	}
+	return resps, nil
+}
+
+// notify tells the service manager the state of the server, if it runs under one.
+func notify(states ...string) {
+	if err := sdnotify.Notify(states...); err != nil {
+		slog.Warn("could not notify the service manager", slog.Any("error", err))
+	}
+}
+
+// drainClients waits for the clients connected to the io-threads to disconnect, at most for the timeout.
+func drainClients(ctx context.Context, m *iothread.Manager, timeout time.Duration) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for m.IOThreadCount() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline.C:
+			slog.Warn("closing the connections of the clients still connected", slog.Int("clients", int(m.IOThreadCount())))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// frontend is a server started by main, with its own context so that it can be stopped on its own.
+type frontend struct {
+	srv    abstractserver.AbstractServer
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func startFrontend(ctx context.Context, wg *sync.WaitGroup, srv abstractserver.AbstractServer, errCh chan<- error) *frontend {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &frontend{srv: srv, cancel: cancel, done: make(chan struct{})}
+
+	wg.Add(1)
+	go func() {
+		defer close(f.done)
+		runServer(ctx, wg, srv, errCh)
+	}()
+	return f
+}
+
+// stop stops the server and waits for it to return.
+func (f *frontend) stop() {
+	f.cancel()
+	<-f.done
 }
 
 func runServer(ctx context.Context, wg *sync.WaitGroup, srv abstractserver.AbstractServer, errCh chan<- error) {
@@ -0,0 +1,79 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package modules extends DiceDB with commands of its own and hooks around the commands, without changing the
+// server. A module is compiled in, by registering it from an init function:
+//
+//	func init() {
+//		modules.Register(counter{})
+//	}
+//
+// or built as a Go plugin exporting it as the symbol Module, and listed in modules.load:
+//
+//	var Module modules.Module = counter{}
+//
+// A plugin must be built with the same Go version and the same version of this package as the server. Only
+// Go plugins are supported, WebAssembly modules are not.
+//
+// The commands of a module run on the shard owning their keys and can only access these keys, by executing
+// the commands of the server through the Context. The hooks are called for the commands of the RESP, gRPC
+// and embedded clients, the commands of HTTP and WebSocket are not hooked.
+package modules
+
+import (
+	"github.com/dicedb/dice/internal/cmd"
+	"github.com/dicedb/dice/internal/module"
+)
+
+var (
+	// ErrKeyNotDeclared is returned by Context.Call for a key that is not a key of the module command
+	ErrKeyNotDeclared = module.ErrKeyNotDeclared
+	// ErrNotAllowed is returned by Context.Call for a command that a module command can not execute
+	ErrNotAllowed = module.ErrNotAllowed
+)
+
+type (
+	// Module is a set of commands and hooks, initialized once when the server or the engine starts.
+	Module = module.Module
+
+	// Registry collects the commands and the hooks of a module while it is initialized.
+	Registry = module.Registry
+
+	// Command is a command of a module, executed by the shard owning its keys.
+	Command = module.Command
+
+	// Context is the access of a module command to the store, limited to the keys of the command.
+	Context = module.Context
+
+	// KeySpec locates the keys among the arguments of a command, the indices counting from the first argument
+	// after the name of the command, starting at 1.
+	KeySpec = cmd.KeySpec
+
+	// Call is a command executed by a client, as seen by the hooks.
+	Call = module.Call
+
+	// BeforeFunc is called before a command is executed, the command being refused with the error it returns.
+	BeforeFunc = module.BeforeFunc
+
+	// AfterFunc is called once a command is replied.
+	AfterFunc = module.AfterFunc
+)
+
+// Register registers a compiled in module, initialized when the server or the engine starts. It is meant to be
+// called from an init function.
+func Register(m Module) {
+	module.Register(m)
+}
@@ -0,0 +1,164 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package client is the Go client of the WebSocket and HTTP frontends of DiceDB. It executes commands over
+// either protocol, and watches commands over WebSocket, e.g. GET.WATCH k, calling back a handler with every
+// update of their result.
+//
+// A WebSocket client reconnects once its connection is lost, with an exponential backoff, and resumes its
+// watches: the commands are watched again and the first update after the reconnection, holding the current
+// result of the command, is flagged as Resumed. The commands in flight when the connection is lost fail with
+// ErrConnectionLost, they may or may not have been executed.
+//
+//	c, err := client.New("ws://localhost:8379", client.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//
+//	if err := c.Set(ctx, "k", "v", 0); err != nil {
+//		return err
+//	}
+//	sub, err := c.Watch(ctx, "GET", []string{"k"}, func(u client.Update) {
+//		fmt.Println(u.Seq, u.Result, u.Err)
+//	})
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDialTimeout         = 5 * time.Second
+	defaultReconnectBackoff    = 100 * time.Millisecond
+	defaultMaxReconnectBackoff = 5 * time.Second
+)
+
+var (
+	// ErrClosed is returned once the client or the subscription is closed
+	ErrClosed = errors.New("dicedb: client closed")
+	// ErrConnectionLost is returned by the commands in flight when the connection is lost, they may or may not
+	// have been executed
+	ErrConnectionLost = errors.New("dicedb: connection lost")
+	// ErrWatchUnsupported is returned by Watch over HTTP, the commands are only watched over WebSocket
+	ErrWatchUnsupported = errors.New("dicedb: commands are only watched over WebSocket")
+	// ErrNil is returned by the helpers reading a key that does not exist
+	ErrNil = errors.New("dicedb: nil")
+)
+
+// Error is an error replied by the server to a command, as opposed to the errors of the connection.
+type Error struct {
+	// Message is the error as replied over RESP, e.g. ERR syntax error
+	Message string
+	// Code is the stable code of the error, e.g. SYNTAX or WRONGTYPE, ERR when the server replied none
+	Code string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Options are the settings of a client.
+type Options struct {
+	// DialTimeout bounds the connection to the server, 5 seconds when 0
+	DialTimeout time.Duration
+	// ReconnectBackoff is the wait before the first attempt to reconnect, doubled after every failed attempt
+	// up to MaxReconnectBackoff. 100 milliseconds and 5 seconds when 0.
+	ReconnectBackoff    time.Duration
+	MaxReconnectBackoff time.Duration
+	// UpdateBuffer is the number of updates of a subscription queued for its handler, 128 when 0. The
+	// updates beyond are dropped, leaving a gap between the sequence numbers of the updates handled.
+	UpdateBuffer int
+}
+
+// transport executes the commands over a protocol
+type transport interface {
+	do(ctx context.Context, args []string) (interface{}, error)
+	// doIdempotent executes the command with a request id, the retries of the request not executing it again
+	doIdempotent(ctx context.Context, requestID string, args []string) (interface{}, error)
+	watch(ctx context.Context, sub *Subscription) error
+	unwatch(ctx context.Context, sub *Subscription) error
+	close() error
+}
+
+// Client executes commands on a DiceDB server. It is safe for concurrent use.
+type Client struct {
+	opts Options
+	t    transport
+}
+
+// New returns a client of the server at the URL: ws:// or wss:// for the WebSocket frontend, http:// or
+// https:// for the HTTP one. A WebSocket client connects right away, failing if the server is not reachable.
+func New(rawURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = defaultDialTimeout
+	}
+	if opts.ReconnectBackoff <= 0 {
+		opts.ReconnectBackoff = defaultReconnectBackoff
+	}
+	if opts.MaxReconnectBackoff <= 0 {
+		opts.MaxReconnectBackoff = defaultMaxReconnectBackoff
+	}
+	if opts.UpdateBuffer <= 0 {
+		opts.UpdateBuffer = 128
+	}
+
+	c := &Client{opts: opts}
+	switch u.Scheme {
+	case "ws", "wss":
+		if c.t, err = dialWebSocket(u.String(), &c.opts); err != nil {
+			return nil, err
+		}
+	case "http", "https":
+		c.t = newHTTPTransport(u.String(), &c.opts)
+	default:
+		return nil, fmt.Errorf("invalid URL %q, expected a ws, wss, http or https URL", rawURL)
+	}
+	return c, nil
+}
+
+// Do executes a command, e.g. Do(ctx, "SET", "k", "v"), and returns its reply: nil, a string, an int64, a
+// float64, a bool, a []interface{} or a map[string]interface{} of those. An error replied by the command is
+// returned as an *Error.
+func (c *Client) Do(ctx context.Context, command string, args ...string) (interface{}, error) {
+	return c.t.do(ctx, append([]string{strings.ToUpper(command)}, args...))
+}
+
+// DoIdempotent executes a command like Do, with a request id making it safe to retry: once the server executed
+// the command, the retries with the same request id are replied its response instead of executing it again,
+// for the window of idempotency.window. A write failing with ErrConnectionLost or a timeout is then retried
+// with the same request id, e.g. a new UUID per command, without being applied twice. It requires
+// idempotency.enabled on the server, the commands being executed on every retry otherwise.
+func (c *Client) DoIdempotent(ctx context.Context, requestID, command string, args ...string) (interface{}, error) {
+	if requestID == "" {
+		return nil, errors.New("dicedb: the request id is empty")
+	}
+	return c.t.doIdempotent(ctx, requestID, append([]string{strings.ToUpper(command)}, args...))
+}
+
+// Close closes the connection, the subscriptions are closed along with it.
+func (c *Client) Close() error {
+	return c.t.close()
+}
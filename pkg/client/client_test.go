@@ -0,0 +1,301 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/config"
+	"github.com/dicedb/dice/internal/servertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updates collects the updates of a subscription
+type updates struct {
+	mu      sync.Mutex
+	updates []Update
+}
+
+func (u *updates) handle(update Update) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.updates = append(u.updates, update)
+}
+
+func (u *updates) last() (Update, int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.updates) == 0 {
+		return Update{}, 0
+	}
+	return u.updates[len(u.updates)-1], len(u.updates)
+}
+
+// proxy forwards the connections to addr, until they are dropped
+type proxy struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func startProxy(t *testing.T, addr string) *proxy {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &proxy{ln: ln}
+	t.Cleanup(func() {
+		ln.Close()
+		p.drop()
+	})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			upstream, err := net.Dial("tcp", addr)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			p.mu.Lock()
+			p.conns = append(p.conns, conn, upstream)
+			p.mu.Unlock()
+			go func() { _, _ = io.Copy(upstream, conn) }()
+			go func() { _, _ = io.Copy(conn, upstream) }()
+		}
+	}()
+	return p
+}
+
+func (p *proxy) drop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+	p.conns = nil
+}
+
+func TestWebSocket(t *testing.T) {
+	s := servertest.Start(t, servertest.Options{WebSocket: true})
+	c, err := New(s.WebSocketURL, Options{})
+	require.NoError(t, err)
+	defer c.Close()
+	ctx := context.Background()
+
+	require.NoError(t, c.Ping(ctx))
+	require.NoError(t, c.Set(ctx, "k", "a 'quoted' value", time.Minute))
+	value, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "a 'quoted' value", value)
+
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNil)
+
+	n, err := c.Incr(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	value, err = c.Get(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, "1", value)
+
+	_, err = c.Incr(ctx, "k")
+	var cmdErr *Error
+	require.ErrorAs(t, err, &cmdErr)
+	assert.Equal(t, "OUTOFRANGE", cmdErr.Code)
+	assert.Equal(t, "ERR value is not an integer or out of range", cmdErr.Message)
+
+	n, err = c.LPush(ctx, "l", "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	reply, err := c.Do(ctx, "LRANGE", "l", "0", "-1")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"b", "a"}, reply)
+
+	n, err = c.Del(ctx, "k", "l", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	require.NoError(t, c.Close())
+	_, err = c.Do(ctx, "PING")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWebSocketWatch(t *testing.T) {
+	s := servertest.Start(t, servertest.Options{WebSocket: true})
+	c, err := New(s.WebSocketURL, Options{})
+	require.NoError(t, err)
+	defer c.Close()
+	ctx := context.Background()
+
+	var got updates
+	sub, err := c.Watch(ctx, "GET", []string{"k"}, got.handle)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		u, n := got.last()
+		return n == 1 && u.Seq == 0 && u.Result == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The commands executed once subscribed are replied to along with the pushes
+	require.NoError(t, c.Set(ctx, "k", "v1", 0))
+	assert.Eventually(t, func() bool {
+		u, _ := got.last()
+		return u.Seq == 1 && u.Result == "v1"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, sub.Close())
+	require.NoError(t, c.Set(ctx, "k", "v2", 0))
+	value, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+	_, n := got.last()
+	assert.Equal(t, 2, n, "no update is handled once the subscription is closed")
+
+	_, err = c.Do(ctx, "GET.UNWATCH", "42")
+	assert.EqualError(t, err, "ERR no such watch")
+}
+
+func TestWebSocketReconnect(t *testing.T) {
+	s := servertest.Start(t, servertest.Options{WebSocket: true})
+	p := startProxy(t, strings.TrimPrefix(s.WebSocketURL, "ws://"))
+	c, err := New("ws://"+p.ln.Addr().String(), Options{ReconnectBackoff: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer c.Close()
+	ctx := context.Background()
+
+	var got updates
+	_, err = c.Watch(ctx, "GET", []string{"k"}, got.handle)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "k", "v1", 0))
+	assert.Eventually(t, func() bool {
+		u, _ := got.last()
+		return u.Result == "v1"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The watch is resumed once reconnected, with the current result of the command
+	p.drop()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	assert.Eventually(t, func() bool {
+		return c.Set(ctx, "k", "v2", 0) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		u, _ := got.last()
+		return u.Result == "v2"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	resumed := false
+	got.mu.Lock()
+	for _, u := range got.updates {
+		resumed = resumed || (u.Resumed && u.Seq == 0)
+	}
+	got.mu.Unlock()
+	assert.True(t, resumed)
+
+	require.NoError(t, c.Set(ctx, "k", "v3", 0))
+	assert.Eventually(t, func() bool {
+		u, _ := got.last()
+		return u.Result == "v3" && !u.Resumed
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestHTTP(t *testing.T) {
+	s := servertest.Start(t, servertest.Options{HTTP: true})
+	c, err := New(s.HTTPURL, Options{})
+	require.NoError(t, err)
+	defer c.Close()
+	ctx := context.Background()
+
+	require.NoError(t, c.Ping(ctx))
+	require.NoError(t, c.Set(ctx, "k", "a value", 0))
+	value, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "a value", value)
+
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNil)
+
+	_, err = c.Do(ctx, "LPUSH", "k", "a")
+	var cmdErr *Error
+	require.ErrorAs(t, err, &cmdErr)
+	assert.Equal(t, "WRONGTYPE", cmdErr.Code)
+
+	_, err = c.Watch(ctx, "GET", []string{"k"}, func(Update) {})
+	assert.ErrorIs(t, err, ErrWatchUnsupported)
+}
+
+func TestDoIdempotent(t *testing.T) {
+	s := servertest.Start(t, servertest.Options{HTTP: true, WebSocket: true})
+	config.DiceConfig().Idempotency.Enabled = true
+	t.Cleanup(func() { config.DiceConfig().Idempotency.Enabled = false })
+	ctx := context.Background()
+
+	for name, url := range map[string]string{"http": s.HTTPURL, "websocket": s.WebSocketURL} {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(url, Options{})
+			require.NoError(t, err)
+			defer c.Close()
+
+			// The retries of a request are replied the response of its first execution
+			for i := 0; i < 3; i++ {
+				reply, err := c.DoIdempotent(ctx, name+"-1", "INCR", name)
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), reply)
+			}
+			reply, err := c.DoIdempotent(ctx, name+"-2", "INCR", name)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), reply)
+
+			_, err = c.DoIdempotent(ctx, name+"-1", "INCR", "other")
+			var cmdErr *Error
+			require.ErrorAs(t, err, &cmdErr)
+			assert.Equal(t, "ERR the request id was used for another command", cmdErr.Message)
+		})
+	}
+}
+
+func TestEncodeMessage(t *testing.T) {
+	msg, err := encodeMessage([]string{"SET", "k", "a b", `{"a": 1}`, "", "'q", `x"y`})
+	require.NoError(t, err)
+	assert.Equal(t, `SET k "a b" '{"a": 1}' "" "'q" x"y`, string(msg))
+
+	_, err = encodeMessage([]string{"SET", "k", `'a' "b"`})
+	assert.Error(t, err)
+
+	_, err = New("redis://localhost:7379", Options{})
+	assert.ErrorContains(t, err, "expected a ws, wss, http or https URL")
+}
+
+func TestDecodeMessage(t *testing.T) {
+	value, err := decodeMessage([]byte(`[1, 1.5, "a", null, {"n": 2}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(1), 1.5, "a", nil, map[string]interface{}{"n": int64(2)}}, value)
+
+	_, err = decodeMessage([]byte(`{"error": "WRONGTYPE Operation against a key holding the wrong kind of value", "code": "WRONGTYPE"}`))
+	assert.Equal(t, &Error{Message: "WRONGTYPE Operation against a key holding the wrong kind of value", Code: "WRONGTYPE"}, err)
+
+	_, err = decodeMessage([]byte("Command is not implemented with Websocket"))
+	assert.Equal(t, &Error{Message: "Command is not implemented with Websocket", Code: "ERR"}, err)
+}
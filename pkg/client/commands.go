@@ -0,0 +1,98 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Ping checks that the server replies.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Get returns the value of the key, ErrNil if the key does not exist.
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return "", err
+	}
+	if reply == nil {
+		return "", ErrNil
+	}
+	return toString(reply)
+}
+
+// Set sets the value of the key, expiring it after ttl unless ttl is 0.
+func (c *Client) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	args := []string{key, value}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := c.Do(ctx, "SET", args...)
+	return err
+}
+
+// Del deletes the keys and returns the number of keys deleted.
+func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
+	return toInt64(c.Do(ctx, "DEL", keys...))
+}
+
+// Incr increments the integer value of the key by one and returns the value incremented.
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return toInt64(c.Do(ctx, "INCR", key))
+}
+
+// Expire makes the key expire after ttl, reporting whether the key exists.
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	n, err := toInt64(c.Do(ctx, "PEXPIRE", key, strconv.FormatInt(ttl.Milliseconds(), 10)))
+	return n == 1, err
+}
+
+// LPush inserts the values at the head of the list and returns the length of the list.
+func (c *Client) LPush(ctx context.Context, key string, values ...string) (int64, error) {
+	return toInt64(c.Do(ctx, "LPUSH", append([]string{key}, values...)...))
+}
+
+// toString returns a reply holding a string, the values holding integers being replied as numbers
+func toString(reply interface{}) (string, error) {
+	switch v := reply.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("dicedb: unexpected reply %v", reply)
+	}
+}
+
+func toInt64(reply interface{}, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("dicedb: unexpected reply %v", reply)
+	}
+	return n, nil
+}
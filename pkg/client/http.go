@@ -0,0 +1,113 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// httpTransport executes the commands over HTTP, every command with a request of its own: POST <url>/<command>
+// with the arguments as the values of the JSON body.
+type httpTransport struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPTransport(baseURL string, opts *Options) *httpTransport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: opts.DialTimeout}).DialContext
+	return &httpTransport{url: strings.TrimSuffix(baseURL, "/"), client: &http.Client{Transport: transport}}
+}
+
+func (t *httpTransport) do(ctx context.Context, args []string) (interface{}, error) {
+	return t.doIdempotent(ctx, "", args)
+}
+
+// doIdempotent sends the request id in the Idempotency-Key header, none when it is empty
+func (t *httpTransport) doIdempotent(ctx context.Context, requestID string, args []string) (interface{}, error) {
+	var body io.Reader = http.NoBody
+	if len(args) > 1 {
+		payload, err := json.Marshal(map[string][]string{"values": args[1:]})
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+"/"+url.PathEscape(args[0]), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if requestID != "" {
+		req.Header.Set("Idempotency-Key", requestID)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("dicedb: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("dicedb: %w", err)
+	}
+	return decodeHTTPResponse(data)
+}
+
+// decodeHTTPResponse decodes the response to a command, {"status": "success", "data": reply} or
+// {"status": "error", "data": message, "code": code}
+func decodeHTTPResponse(data []byte) (interface{}, error) {
+	value, err := decodeJSON(data)
+	response, ok := value.(map[string]interface{})
+	if err != nil || !ok {
+		// The requests refused before being parsed are replied as plain text, e.g. in protected mode
+		message := strings.TrimSpace(string(data))
+		return nil, &Error{Message: message, Code: codeOf(message)}
+	}
+
+	if response["status"] == "error" {
+		message := fmt.Sprint(response["data"])
+		code, ok := response["code"].(string)
+		if !ok {
+			code = codeOf(message)
+		}
+		return nil, &Error{Message: message, Code: code}
+	}
+	return response["data"], nil
+}
+
+func (t *httpTransport) watch(context.Context, *Subscription) error {
+	return ErrWatchUnsupported
+}
+
+func (t *httpTransport) unwatch(context.Context, *Subscription) error {
+	return nil
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,325 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The types of the columns of a schema
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+)
+
+// Column is a column of a Schema, a field of the result of a watched command.
+type Column struct {
+	Name string
+	Type string // Type is TypeString, TypeInt, TypeFloat or TypeBool
+}
+
+// Schema is the shape of the result of a watched command. The server validates the results against the schema
+// of a watch and pushes them as a map holding a value of the type of each column, nil for the columns missing,
+// or an *Error with the code SCHEMA when a result does not match it. The result of a command replying a single
+// value, e.g. GET, is a map of the one column of its schema.
+type Schema []Column
+
+// ParseSchema parses a schema written as space-separated columns, e.g. "name:string age:int".
+func ParseSchema(s string) (Schema, error) {
+	var schema Schema
+	for _, column := range strings.Fields(s) {
+		name, typ, ok := strings.Cut(column, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("dicedb: invalid column %q, expected NAME:TYPE", column)
+		}
+		schema = append(schema, Column{Name: name, Type: strings.ToLower(typ)})
+	}
+	return schema, schema.validate()
+}
+
+// SchemaOf returns the schema of a struct, or of a pointer to a struct, whose fields are decoded by Decode: a
+// column per exported field, named as the field unless its dice tag names it, e.g. `dice:"created_at"`. The
+// fields tagged `dice:"-"` are skipped.
+func SchemaOf(v interface{}) (Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dicedb: schema of %T, expected a struct", v)
+	}
+
+	var schema Schema
+	for _, f := range structFields(t) {
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		var typ string
+		switch ft.Kind() {
+		case reflect.String:
+			typ = TypeString
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			typ = TypeInt
+		case reflect.Float32, reflect.Float64:
+			typ = TypeFloat
+		case reflect.Bool:
+			typ = TypeBool
+		default:
+			return nil, fmt.Errorf("dicedb: field %s of type %s has no column type", f.Name, f.Type)
+		}
+		schema = append(schema, Column{Name: f.column, Type: typ})
+	}
+	return schema, schema.validate()
+}
+
+func (schema Schema) validate() error {
+	if len(schema) == 0 {
+		return fmt.Errorf("dicedb: empty schema")
+	}
+	seen := make(map[string]bool, len(schema))
+	for _, c := range schema {
+		switch c.Type {
+		case TypeString, TypeInt, TypeFloat, TypeBool:
+		default:
+			return fmt.Errorf("dicedb: invalid type %q of column %s", c.Type, c.Name)
+		}
+		if c.Name == "" || strings.ContainsAny(c.Name, " \t\n'\"") || seen[c.Name] {
+			return fmt.Errorf("dicedb: invalid column name %q", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
+
+// args returns the arguments declaring the schema at the end of a watch command
+func (schema Schema) args() []string {
+	args := make([]string, 0, len(schema)+1)
+	args = append(args, "SCHEMA")
+	for _, c := range schema {
+		args = append(args, c.Name+":"+c.Type)
+	}
+	return args
+}
+
+// parseSchema returns the schema held by the first push of a watch
+func parseSchema(v interface{}) Schema {
+	columns, _ := v.([]interface{})
+	var schema Schema
+	for _, column := range columns {
+		m, _ := column.(map[string]interface{})
+		name, _ := m["name"].(string)
+		typ, _ := m["type"].(string)
+		schema = append(schema, Column{Name: name, Type: typ})
+	}
+	return schema
+}
+
+// Decode stores a reply, or the result of an update, in the value pointed to by v, returning ErrNil for a
+// nil reply. A map, e.g. the result of a watch with a schema, or an array of field/value pairs, e.g. the reply
+// of HGETALL, is decoded into a struct or a map with string keys; the fields of a struct are matched with the
+// names of the columns as described by SchemaOf, ignoring the case. The numbers held by strings are decoded
+// into numeric fields, as the values of the hashes are strings.
+func Decode(reply, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("dicedb: decode into %T, expected a non-nil pointer", v)
+	}
+	if reply == nil {
+		return ErrNil
+	}
+	return decodeValue(reply, rv.Elem())
+}
+
+// Decode stores the result of the update in the value pointed to by v, as Decode does. The error of the
+// update is returned as it is.
+func (u Update) Decode(v interface{}) error {
+	if u.Err != nil {
+		return u.Err
+	}
+	return Decode(u.Result, v)
+}
+
+func decodeValue(reply interface{}, v reflect.Value) error {
+	if reply == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(reply, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(reply))
+			return nil
+		}
+	case reflect.String:
+		if s, err := toString(reply); err == nil {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := parseInt(reply); ok && !v.OverflowInt(n) {
+			v.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := parseInt(reply); ok && n >= 0 && !v.OverflowUint(uint64(n)) {
+			v.SetUint(uint64(n))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := parseFloat(reply); ok {
+			v.SetFloat(f)
+			return nil
+		}
+	case reflect.Bool:
+		switch reply {
+		case true, int64(1), "1", "true":
+			v.SetBool(true)
+			return nil
+		case false, int64(0), "0", "false":
+			v.SetBool(false)
+			return nil
+		}
+	case reflect.Slice:
+		if values, ok := reply.([]interface{}); ok {
+			s := reflect.MakeSlice(v.Type(), len(values), len(values))
+			for i, value := range values {
+				if err := decodeValue(value, s.Index(i)); err != nil {
+					return err
+				}
+			}
+			v.Set(s)
+			return nil
+		}
+	case reflect.Map:
+		if fields, ok := toRecord(reply); ok && v.Type().Key().Kind() == reflect.String {
+			m := reflect.MakeMapWithSize(v.Type(), len(fields))
+			for name, value := range fields {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if err := decodeValue(value, elem); err != nil {
+					return err
+				}
+				m.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), elem)
+			}
+			v.Set(m)
+			return nil
+		}
+	case reflect.Struct:
+		if fields, ok := toRecord(reply); ok {
+			for _, f := range structFields(v.Type()) {
+				value, found := fields[f.column]
+				if !found {
+					for name, fv := range fields {
+						if strings.EqualFold(name, f.column) {
+							value, found = fv, true
+							break
+						}
+					}
+				}
+				if !found {
+					continue
+				}
+				if err := decodeValue(value, v.FieldByIndex(f.Index)); err != nil {
+					return fmt.Errorf("dicedb: field %s: %w", f.Name, err)
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("dicedb: cannot decode %v into %s", reply, v.Type())
+}
+
+// toRecord returns the fields of a map or of an array of field/value pairs
+func toRecord(reply interface{}) (map[string]interface{}, bool) {
+	switch v := reply.(type) {
+	case map[string]interface{}:
+		return v, true
+	case []interface{}:
+		if len(v)%2 != 0 {
+			return nil, false
+		}
+		fields := make(map[string]interface{}, len(v)/2)
+		for i := 0; i < len(v); i += 2 {
+			name, ok := v[i].(string)
+			if !ok {
+				return nil, false
+			}
+			fields[name] = v[i+1]
+		}
+		return fields, true
+	}
+	return nil, false
+}
+
+func parseInt(reply interface{}) (int64, bool) {
+	switch v := reply.(type) {
+	case int64:
+		return v, true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func parseFloat(reply interface{}) (float64, bool) {
+	switch v := reply.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// field is an exported field of a struct decoded by Decode, with the name of its column
+type field struct {
+	reflect.StructField
+	column string
+}
+
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("dice")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		column := f.Name
+		if tag != "" {
+			column = tag
+		}
+		fields = append(fields, field{StructField: f, column: column})
+	}
+	return fields
+}
@@ -0,0 +1,144 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dicedb/dice/internal/servertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Name    string
+	Age     int     `dice:"age"`
+	Score   float64 `dice:"score"`
+	Admin   *bool   `dice:"admin"`
+	Ignored string  `dice:"-"`
+}
+
+func TestSchema(t *testing.T) {
+	schema, err := SchemaOf(&user{})
+	require.NoError(t, err)
+	assert.Equal(t, Schema{{"Name", TypeString}, {"age", TypeInt}, {"score", TypeFloat}, {"admin", TypeBool}}, schema)
+	assert.Equal(t, []string{"SCHEMA", "Name:string", "age:int", "score:float", "admin:bool"}, schema.args())
+
+	parsed, err := ParseSchema("Name:string age:INT score:float admin:bool")
+	require.NoError(t, err)
+	assert.Equal(t, schema, parsed)
+
+	for _, s := range []string{"", "name", "name:text", "a:int a:int"} {
+		_, err := ParseSchema(s)
+		assert.Error(t, err, s)
+	}
+	_, err = SchemaOf(struct{ Tags []string }{})
+	assert.Error(t, err)
+	_, err = SchemaOf("user")
+	assert.Error(t, err)
+}
+
+func TestDecode(t *testing.T) {
+	var u user
+	require.NoError(t, Decode(map[string]interface{}{"name": "alice", "age": int64(42), "score": int64(3), "admin": true}, &u))
+	assert.Equal(t, "alice", u.Name)
+	assert.Equal(t, 42, u.Age)
+	assert.Equal(t, 3.0, u.Score)
+	require.NotNil(t, u.Admin)
+	assert.True(t, *u.Admin)
+
+	// The field/value pairs of HGETALL hold the numbers as strings
+	u = user{}
+	require.NoError(t, Decode([]interface{}{"Name", "bob", "age", "7", "score", "1.5", "Ignored", "x"}, &u))
+	assert.Equal(t, user{Name: "bob", Age: 7, Score: 1.5}, u)
+
+	var m map[string]int64
+	require.NoError(t, Decode([]interface{}{"a", "1", "b", int64(2)}, &m))
+	assert.Equal(t, map[string]int64{"a": 1, "b": 2}, m)
+
+	var list []string
+	require.NoError(t, Decode([]interface{}{"x", int64(1)}, &list))
+	assert.Equal(t, []string{"x", "1"}, list)
+
+	var n uint8
+	assert.Error(t, Decode(int64(300), &n))
+	assert.Error(t, Decode([]interface{}{"age", "old"}, &u))
+	assert.ErrorIs(t, Decode(nil, &u), ErrNil)
+	assert.Error(t, Decode("v", u))
+
+	err := Update{Err: &Error{Message: "ERR failed", Code: "ERR"}}.Decode(&u)
+	assert.EqualError(t, err, "ERR failed")
+}
+
+func TestWebSocketWatchSchema(t *testing.T) {
+	s := servertest.Start(t, servertest.Options{WebSocket: true})
+	c, err := New(s.WebSocketURL, Options{})
+	require.NoError(t, err)
+	defer c.Close()
+	ctx := context.Background()
+
+	type scores struct {
+		Alice int `dice:"alice"`
+		Bob   int `dice:"bob"`
+	}
+	_, err = c.Do(ctx, "ZADD", "board", "10", "alice", "20", "bob")
+	require.NoError(t, err)
+
+	schema, err := SchemaOf(scores{})
+	require.NoError(t, err)
+	var got updates
+	sub, err := c.WatchSchema(ctx, "ZRANGE", []string{"board", "0", "-1", "WITHSCORES"}, schema, got.handle)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	assert.Eventually(t, func() bool {
+		_, n := got.last()
+		return n == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	first, _ := got.last()
+	assert.Equal(t, schema, first.Schema)
+	var board scores
+	require.NoError(t, first.Decode(&board))
+	assert.Equal(t, scores{Alice: 10, Bob: 20}, board)
+
+	// A result that does not match the schema is pushed as an error
+	_, err = c.Do(ctx, "ZADD", "board", "30", "carol")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		u, _ := got.last()
+		cmdErr, ok := u.Err.(*Error)
+		return u.Seq == 1 && ok && cmdErr.Code == "SCHEMA" && u.Schema == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A single value is the value of the one column of the schema
+	counter, err := c.WatchSchema(ctx, "GET", []string{"visits"}, Schema{{Name: "count", Type: TypeInt}}, got.handle)
+	require.NoError(t, err)
+	defer counter.Close()
+	require.NoError(t, c.Set(ctx, "visits", "12", 0))
+	assert.Eventually(t, func() bool {
+		u, _ := got.last()
+		var v struct{ Count int64 }
+		return u.Decode(&v) == nil && v.Count == 12
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = c.Do(ctx, "GET.WATCH", "visits", "SCHEMA", "count:integer")
+	var cmdErr *Error
+	require.ErrorAs(t, err, &cmdErr)
+	assert.Equal(t, "SYNTAX", cmdErr.Code)
+}
@@ -0,0 +1,144 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Update is an update of the result of a watched command.
+type Update struct {
+	// Seq is the sequence number of the update, starting at 0 with the current result of the command. A gap
+	// between the sequence numbers of two updates means that updates were dropped.
+	Seq    uint64
+	Result interface{}
+	// Err is the error replied by the command, as an *Error
+	Err error
+	// Resumed is set on the first update once the client reconnected and watched the command again, Seq
+	// starting at 0 again. The updates made while the client was disconnected are not replayed, Result holds
+	// the current result of the command.
+	Resumed bool
+	// Schema is the schema of the watch as acknowledged by the server, on the first update of a watch declaring
+	// one, Seq being 0
+	Schema Schema
+}
+
+// Subscription is a watched command. Its updates are handed to its handler one at a time, in order.
+type Subscription struct {
+	command string // command is the command watched, e.g. GET for GET.WATCH
+	args    []string
+	schema  Schema // schema is the schema declared by the subscription, nil for none
+	handler func(Update)
+	t       transport
+
+	updates   chan Update
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	id string // id is the id of the watch on the current connection, guarded by the transport
+}
+
+// Watch watches a command, e.g. Watch(ctx, "GET", []string{"k"}, handler), calling the handler with the
+// current result of the command, then every time it changes, until the subscription is closed. The handler is
+// called from a goroutine of the subscription, a slow handler makes the updates beyond Options.UpdateBuffer
+// be dropped.
+func (c *Client) Watch(ctx context.Context, command string, args []string, handler func(Update)) (*Subscription, error) {
+	return c.watch(ctx, command, args, nil, handler)
+}
+
+// WatchSchema watches a command like Watch, declaring the schema of its result, e.g. the one of the struct
+// the updates are decoded into:
+//
+//	type Scores struct {
+//		Alice int `dice:"alice"`
+//		Bob   int `dice:"bob"`
+//	}
+//	schema, err := client.SchemaOf(Scores{})
+//	...
+//	sub, err := c.WatchSchema(ctx, "ZRANGE", []string{"board", "0", "-1", "WITHSCORES"}, schema, func(u client.Update) {
+//		var scores Scores
+//		if err := u.Decode(&scores); err != nil {
+//			...
+//		}
+//	})
+//
+// The result of the command is validated by the server, a result that does not match the schema being an
+// update whose Err is an *Error with the code SCHEMA.
+func (c *Client) WatchSchema(ctx context.Context, command string, args []string, schema Schema, handler func(Update)) (*Subscription, error) {
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	return c.watch(ctx, command, args, schema, handler)
+}
+
+func (c *Client) watch(ctx context.Context, command string, args []string, schema Schema, handler func(Update)) (*Subscription, error) {
+	sub := &Subscription{
+		command: strings.TrimSuffix(strings.ToUpper(command), ".WATCH"),
+		args:    args,
+		schema:  schema,
+		handler: handler,
+		t:       c.t,
+		updates: make(chan Update, c.opts.UpdateBuffer),
+		closing: make(chan struct{}),
+	}
+	if err := c.t.watch(ctx, sub); err != nil {
+		return nil, err
+	}
+	go sub.run()
+	return sub, nil
+}
+
+// Command returns the command watched and its arguments.
+func (sub *Subscription) Command() (string, []string) {
+	return sub.command, sub.args
+}
+
+// Close unwatches the command. The updates not handled yet are dropped.
+func (sub *Subscription) Close() error {
+	var err error
+	sub.closeOnce.Do(func() {
+		close(sub.closing)
+		err = sub.t.unwatch(context.Background(), sub)
+	})
+	return err
+}
+
+// deliver queues the update for the handler, dropping it if the handler is too far behind
+func (sub *Subscription) deliver(u Update) {
+	select {
+	case <-sub.closing:
+		return
+	default:
+	}
+	select {
+	case sub.updates <- u:
+	default:
+	}
+}
+
+func (sub *Subscription) run() {
+	for {
+		select {
+		case <-sub.closing:
+			return
+		case u := <-sub.updates:
+			sub.handler(u)
+		}
+	}
+}
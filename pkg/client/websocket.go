@@ -0,0 +1,503 @@
+// This file is part of DiceDB.
+// Copyright (C) 2024 DiceDB (dicedb.io).
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsCall is a command waiting for its reply. The replies of a connection come in the order of the commands.
+type wsCall struct {
+	// sub is the subscription of a watch command, whose reply is the first push of the watch
+	sub     *Subscription
+	resumed bool
+	reply   chan wsResult
+}
+
+type wsResult struct {
+	value interface{}
+	err   error
+}
+
+// wsTransport executes the commands over a WebSocket connection, reconnecting once it is lost.
+type wsTransport struct {
+	url    string
+	opts   *Options
+	dialer *websocket.Dialer
+	done   chan struct{} // done is closed once the client is closed
+
+	// writeMu serializes the writes, the commands being queued in pending in the order they are written
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	conn    *websocket.Conn // conn is nil while reconnecting
+	ready   chan struct{}   // ready is closed once connected, and replaced once the connection is lost
+	pending []*wsCall
+	watches map[string]*Subscription   // watches are the subscriptions by the id of their watch on conn
+	lastID  uint64                     // lastID is the id of the latest watch on conn, the pushes of lower ids being stale
+	subs    map[*Subscription]struct{} // subs are the open subscriptions, watched again once reconnected
+	closed  bool
+	err     error // err is the reason the client is closed, ErrClosed or the refusal of the server
+}
+
+func dialWebSocket(url string, opts *Options) (*wsTransport, error) {
+	t := &wsTransport{
+		url:     url,
+		opts:    opts,
+		dialer:  &websocket.Dialer{HandshakeTimeout: opts.DialTimeout},
+		done:    make(chan struct{}),
+		ready:   make(chan struct{}),
+		watches: make(map[string]*Subscription),
+		subs:    make(map[*Subscription]struct{}),
+	}
+	conn, err := t.dial()
+	if err != nil {
+		return nil, err
+	}
+	t.conn = conn
+	close(t.ready)
+	go t.run(conn)
+	return t, nil
+}
+
+func (t *wsTransport) dial() (*websocket.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.opts.DialTimeout)
+	defer cancel()
+	conn, resp, err := t.dialer.DialContext(ctx, t.url, nil)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dicedb: could not connect to %s: %w", t.url, err)
+	}
+	return conn, nil
+}
+
+func (t *wsTransport) do(ctx context.Context, args []string) (interface{}, error) {
+	call := &wsCall{reply: make(chan wsResult, 1)}
+	if err := t.send(ctx, args, call); err != nil {
+		return nil, err
+	}
+	select {
+	case r := <-call.reply:
+		return r.value, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// doIdempotent prefixes the command with its request id, IDEMPOTENT id command [arg ...]
+func (t *wsTransport) doIdempotent(ctx context.Context, requestID string, args []string) (interface{}, error) {
+	return t.do(ctx, append([]string{"IDEMPOTENT", requestID}, args...))
+}
+
+// send writes the command, waiting for the connection while the client reconnects
+func (t *wsTransport) send(ctx context.Context, args []string, call *wsCall) error {
+	msg, err := encodeMessage(args)
+	if err != nil {
+		return err
+	}
+
+	for {
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			return t.err
+		}
+		conn, ready := t.conn, t.ready
+		t.mu.Unlock()
+
+		if conn == nil {
+			select {
+			case <-ready:
+				continue
+			case <-t.done:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		t.writeMu.Lock()
+		t.mu.Lock()
+		if t.conn != conn {
+			t.mu.Unlock()
+			t.writeMu.Unlock()
+			continue
+		}
+		t.pending = append(t.pending, call)
+		t.mu.Unlock()
+
+		deadline, _ := ctx.Deadline()
+		_ = conn.SetWriteDeadline(deadline)
+		if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			// The call is failed along with the others once the connection is closed
+			_ = conn.Close()
+		}
+		t.writeMu.Unlock()
+		return nil
+	}
+}
+
+func (t *wsTransport) watch(ctx context.Context, sub *Subscription) error {
+	if err := t.watchCommand(ctx, sub, false); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return t.err
+	}
+	t.subs[sub] = struct{}{}
+	return nil
+}
+
+// watchCommand sends the watch command of the subscription. Its reply, the first push of the watch, is
+// delivered to the subscription by the reader of the connection.
+func (t *wsTransport) watchCommand(ctx context.Context, sub *Subscription, resumed bool) error {
+	call := &wsCall{sub: sub, resumed: resumed, reply: make(chan wsResult, 1)}
+	args := append([]string{sub.command + ".WATCH"}, sub.args...)
+	if sub.schema != nil {
+		args = append(args, sub.schema.args()...)
+	}
+	if err := t.send(ctx, args, call); err != nil {
+		return err
+	}
+	select {
+	case r := <-call.reply:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *wsTransport) unwatch(ctx context.Context, sub *Subscription) error {
+	t.mu.Lock()
+	delete(t.subs, sub)
+	id := sub.id
+	delete(t.watches, id)
+	sub.id = ""
+	closed := t.closed
+	t.mu.Unlock()
+
+	// The watches end along with the connection, the ones of a closed client or of a lost connection are gone
+	if id == "" || closed {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.opts.DialTimeout)
+	defer cancel()
+	_, err := t.do(ctx, []string{sub.command + ".UNWATCH", id})
+	if errors.Is(err, ErrConnectionLost) || errors.Is(err, ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (t *wsTransport) close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed, t.err = true, ErrClosed
+	conn := t.conn
+	subs := t.subs
+	t.subs = make(map[*Subscription]struct{})
+	t.mu.Unlock()
+	close(t.done)
+
+	for sub := range subs {
+		_ = sub.Close()
+	}
+	if conn == nil {
+		return nil
+	}
+	t.writeMu.Lock()
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	t.writeMu.Unlock()
+	return conn.Close()
+}
+
+// run reads the messages of the connection, reconnecting once it is lost, until the client is closed
+func (t *wsTransport) run(conn *websocket.Conn) {
+	for {
+		err := t.read(conn)
+		_ = conn.Close()
+
+		// The servers refusing the client by policy, e.g. in protected mode, refuse it again
+		var closeErr *websocket.CloseError
+		refused := errors.As(err, &closeErr) && closeErr.Code == websocket.ClosePolicyViolation
+
+		t.mu.Lock()
+		pending := t.pending
+		t.pending = nil
+		t.watches = make(map[string]*Subscription)
+		t.lastID = 0
+		for sub := range t.subs {
+			sub.id = ""
+		}
+		if refused && !t.closed {
+			t.closed, t.err = true, fmt.Errorf("dicedb: connection refused by the server: %s", closeErr.Text)
+			close(t.done)
+		}
+		closed := t.closed
+		if !closed {
+			t.conn, t.ready = nil, make(chan struct{})
+		}
+		t.mu.Unlock()
+
+		for _, call := range pending {
+			call.reply <- wsResult{err: ErrConnectionLost}
+		}
+		if closed {
+			return
+		}
+		if conn = t.reconnect(); conn == nil {
+			return
+		}
+		go t.resume()
+	}
+}
+
+// reconnect connects again, backing off exponentially, until it succeeds or the client is closed
+func (t *wsTransport) reconnect() *websocket.Conn {
+	backoff := t.opts.ReconnectBackoff
+	for {
+		select {
+		case <-t.done:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		conn, err := t.dial()
+		if err != nil {
+			backoff = min(2*backoff, t.opts.MaxReconnectBackoff)
+			continue
+		}
+
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			_ = conn.Close()
+			return nil
+		}
+		t.conn = conn
+		close(t.ready)
+		t.mu.Unlock()
+		return conn
+	}
+}
+
+// resume watches the commands of the subscriptions again once reconnected. The subscriptions that could not be
+// watched again, as the connection was lost again, are resumed by the next reconnection.
+func (t *wsTransport) resume() {
+	t.mu.Lock()
+	subs := make([]*Subscription, 0, len(t.subs))
+	for sub := range t.subs {
+		subs = append(subs, sub)
+	}
+	t.mu.Unlock()
+
+	for _, sub := range subs {
+		ctx, cancel := context.WithTimeout(context.Background(), t.opts.DialTimeout)
+		err := t.watchCommand(ctx, sub, true)
+		cancel()
+
+		var cmdErr *Error
+		switch {
+		case errors.As(err, &cmdErr):
+			sub.deliver(Update{Err: err, Resumed: true})
+		case err != nil:
+			return
+		}
+	}
+}
+
+// read dispatches the messages of the connection until it fails
+func (t *wsTransport) read(conn *websocket.Conn) error {
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		t.dispatch(msg)
+	}
+}
+
+// dispatch hands a push to its subscription, and a reply to the oldest command waiting for one
+func (t *wsTransport) dispatch(msg []byte) {
+	value, err := decodeMessage(msg)
+	if push, ok := value.(map[string]interface{}); ok && err == nil && isPush(push) {
+		t.dispatchPush(push)
+		return
+	}
+
+	t.mu.Lock()
+	if len(t.pending) == 0 {
+		t.mu.Unlock()
+		return
+	}
+	call := t.pending[0]
+	t.pending = t.pending[1:]
+	t.mu.Unlock()
+	call.reply <- wsResult{value: value, err: err}
+}
+
+func (t *wsTransport) dispatchPush(push map[string]interface{}) {
+	id := push["watch"].(string)
+	u := newUpdate(push)
+
+	t.mu.Lock()
+	if sub, ok := t.watches[id]; ok {
+		t.mu.Unlock()
+		sub.deliver(u)
+		return
+	}
+
+	// The first push of a watch is the reply to its command, the pushes of the watches ended are stale
+	n, _ := strconv.ParseUint(id, 10, 64)
+	if n <= t.lastID || len(t.pending) == 0 || t.pending[0].sub == nil {
+		t.mu.Unlock()
+		return
+	}
+	call := t.pending[0]
+	t.pending = t.pending[1:]
+	t.lastID = n
+	if _, open := t.subs[call.sub]; open || !call.resumed {
+		t.watches[id] = call.sub
+		call.sub.id = id
+	}
+	t.mu.Unlock()
+
+	u.Resumed = call.resumed
+	call.sub.deliver(u)
+	call.reply <- wsResult{}
+}
+
+// isPush reports whether the message is a push of a watch rather than the reply to a command
+func isPush(m map[string]interface{}) bool {
+	_, ok := m["watch"].(string)
+	_, hasSeq := m["seq"]
+	return ok && hasSeq
+}
+
+func newUpdate(push map[string]interface{}) Update {
+	u := Update{Result: push["data"]}
+	if seq, ok := push["seq"].(int64); ok {
+		u.Seq = uint64(seq)
+	}
+	if schema, ok := push["schema"]; ok {
+		u.Schema = parseSchema(schema)
+	}
+	if message, ok := push["error"].(string); ok && message != "" {
+		code, _ := push["code"].(string)
+		u.Result, u.Err = nil, &Error{Message: message, Code: code}
+	}
+	return u
+}
+
+// decodeMessage decodes the reply to a command, the errors being returned as an *Error
+func decodeMessage(msg []byte) (interface{}, error) {
+	value, err := decodeJSON(msg)
+	if err != nil {
+		// The server replies some errors as plain text, e.g. the commands not implemented over WebSocket
+		message := strings.TrimSpace(string(msg))
+		return nil, &Error{Message: message, Code: codeOf(message)}
+	}
+	if m, ok := value.(map[string]interface{}); ok && len(m) == 2 {
+		message, isErr := m["error"].(string)
+		code, hasCode := m["code"].(string)
+		if isErr && hasCode {
+			return nil, &Error{Message: message, Code: code}
+		}
+	}
+	return value, nil
+}
+
+// decodeJSON decodes a JSON value, the integers as int64 and the other numbers as float64
+func decodeJSON(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return convertNumbers(v), nil
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = convertNumbers(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = convertNumbers(v[k])
+		}
+	}
+	return v
+}
+
+// codeOf returns the code of an error replied as plain text, its first word when in upper case
+func codeOf(message string) string {
+	prefix, _, _ := strings.Cut(message, " ")
+	if prefix == "" || strings.IndexFunc(prefix, func(r rune) bool { return !unicode.IsUpper(r) }) >= 0 {
+		return "ERR"
+	}
+	return prefix
+}
+
+// encodeMessage encodes the command as the text of a WebSocket message, the arguments with spaces being
+// quoted with the quotes they do not hold
+func encodeMessage(args []string) ([]byte, error) {
+	var b strings.Builder
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		switch {
+		case arg != "" && strings.IndexFunc(arg, unicode.IsSpace) < 0 && arg[0] != '"' && arg[0] != '\'':
+			b.WriteString(arg)
+		case !strings.Contains(arg, `"`):
+			b.WriteString(`"` + arg + `"`)
+		case !strings.Contains(arg, "'"):
+			b.WriteString("'" + arg + "'")
+		default:
+			return nil, fmt.Errorf("dicedb: argument %q can not be sent over WebSocket, it holds spaces and both kinds of quotes", arg)
+		}
+	}
+	return []byte(b.String()), nil
+}